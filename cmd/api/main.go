@@ -13,12 +13,18 @@ import (
 	"github.com/lspecian/ovncp/internal/api"
 	"github.com/lspecian/ovncp/internal/config"
 	"github.com/lspecian/ovncp/internal/db"
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/secrets"
 	"github.com/lspecian/ovncp/internal/services"
-	"github.com/lspecian/ovncp/pkg/ovn"
 	"go.uber.org/zap"
 )
 
 func main() {
+	// `ovncp-api migrate` runs the database migrations instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrateMain(os.Args[2:])
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -26,43 +32,110 @@ func main() {
 	}
 
 	// Initialize logger
-	logger, err := initLogger(cfg)
+	logger, logLevel, err := initLogger(cfg)
 	if err != nil {
 		log.Fatal("Failed to initialize logger:", err)
 	}
 	defer logger.Sync()
 
+	// Secret settings may reference environment variables, mounted files or
+	// Vault. The database password and JWT secret are read again to follow
+	// rotations; other secrets are read once.
+	rotator := initSecrets(cfg, logger)
+	ctx := context.Background()
+	dbPassword, err := rotator.Add(ctx, "DB_PASSWORD", cfg.Database.Password)
+	if err != nil {
+		logger.Fatal("Failed to read secrets", zap.Error(err))
+	}
+	jwtSecret, err := rotator.Add(ctx, "JWT_SECRET", cfg.Auth.JWTSecret)
+	if err != nil {
+		logger.Fatal("Failed to read secrets", zap.Error(err))
+	}
+	if err := cfg.ResolveSecrets(func(name, setting string) (string, error) {
+		return rotator.Resolver().Resolve(ctx, setting)
+	}); err != nil {
+		logger.Fatal("Failed to read secrets", zap.Error(err))
+	}
+
 	// Initialize database
-	database, err := db.New(&cfg.Database)
+	database, err := db.NewWithPassword(&cfg.Database, dbPassword.Value)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 	defer database.Close()
+	metrics.RegisterDBPool("main", database.DB().Stats)
 
-	// Run migrations
-	if err := database.Migrate(); err != nil {
-		logger.Fatal("Failed to run database migrations", zap.Error(err))
+	// Run migrations, unless they're run separately with `ovncp-api migrate`
+	if cfg.Database.AutoMigrate {
+		applied, err := database.MigrateUp()
+		if err != nil {
+			logger.Fatal("Failed to run database migrations", zap.Error(err))
+		}
+		if len(applied) > 0 {
+			logger.Info("Applied database migrations", zap.Strings("versions", applied))
+		}
+	} else {
+		pending, err := database.PendingMigrations()
+		if err != nil {
+			logger.Fatal("Failed to read database migrations", zap.Error(err))
+		}
+		if len(pending) > 0 {
+			logger.Fatal("Database migrations are pending; apply them with `ovncp-api migrate up`",
+				zap.Strings("versions", pending))
+		}
 	}
 
-	// Initialize OVN client
-	ovnClient, err := ovn.NewClient(&cfg.OVN)
-	if err != nil {
-		logger.Fatal("Failed to create OVN client", zap.Error(err))
+	// Usage history, audit and report queries run on the read replica if
+	// there's one
+	var repository db.Repository = database
+	if cfg.Database.ReplicaHost != "" {
+		replicated := db.NewReplicated(database, &cfg.Database, dbPassword.Value)
+		defer replicated.Replica().Close()
+		metrics.RegisterDBPool("replica", replicated.Replica().Stats)
+		logger.Info("Reading reports from the database replica", zap.String("replica", replicated.ReplicaEndpoint()))
+		repository = replicated
 	}
 
-	// Connect to OVN
-	ctx := context.Background()
-	if err := ovnClient.Connect(ctx); err != nil {
-		logger.Warn("Failed to connect to OVN", zap.Error(err))
-		logger.Info("API will start but OVN operations will not be available")
+	// Initialize OVN clusters. The first is the default served at the top
+	// level of the API; unreachable clusters are retried in the background.
+	// The sandbox backend serves an in-memory OVN kept in the database
+	// instead, without clusters.
+	clusters := services.NewOVNClusterManager(logger)
+	defer clusters.Close()
+	var ovnService services.OVNServiceInterface
+	if cfg.OVN.Backend == config.OVNBackendSandbox {
+		sandbox, err := services.NewSandboxOVNService(context.Background(), database, cfg.OVN.ClusterName, cfg.OVN.SandboxSeed, logger)
+		if err != nil {
+			logger.Fatal("Failed to create sandbox OVN backend", zap.Error(err))
+		}
+		logger.Warn("Serving the sandbox OVN backend, changes don't reach any OVN deployment")
+		ovnService = sandbox
+	} else {
+		if err := clusters.Add(&cfg.OVN); err != nil {
+			logger.Fatal("Failed to create OVN client", zap.Error(err))
+		}
+		for i := range cfg.OVNClusters {
+			if err := clusters.Add(&cfg.OVNClusters[i]); err != nil {
+				logger.Fatal("Failed to create OVN client", zap.Error(err))
+			}
+		}
+		ovnService = services.NewClusterOVNService(clusters)
 	}
-	defer ovnClient.Close()
 
-	// Initialize services
-	ovnService := services.NewOVNService(ovnClient)
-
-	// Set up router
-	router := api.NewRouter(ovnService, cfg, database, logger)
+	// Set up router and its background jobs
+	router := api.NewRouter(ovnService, clusters, cfg, repository, jwtSecret, logger)
+	router.ConfigReloader().OnReload(func(cfg *config.Config) {
+		if err := logLevel.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+			logger.Error("Failed to change log level", zap.Error(err))
+		}
+	})
+	jobs, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		router.Run(jobs)
+		close(jobsDone)
+	}()
+	go rotator.Run(jobs)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -88,6 +161,20 @@ func main() {
 		}
 	}()
 
+	// Reload OVN TLS certificates and the configuration on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			router.ConfigReloader().Reload()
+			if err := clusters.ReloadTLS(); err != nil {
+				logger.Error("Failed to reload OVN TLS certificates", zap.Error(err))
+				continue
+			}
+			logger.Info("Reloaded OVN TLS certificates")
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -103,10 +190,22 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Let background jobs finish, e.g. exporting the last metering period
+	stopJobs()
+	<-jobsDone
+
 	logger.Info("Server exited")
 }
 
-func initLogger(cfg *config.Config) (*zap.Logger, error) {
+// initSecrets creates the rotator of secret settings, resolving vault:
+// references when Vault is configured
+func initSecrets(cfg *config.Config, logger *zap.Logger) *secrets.Rotator {
+	return secrets.NewRotator(secrets.NewConfiguredResolver(&cfg.Secrets), cfg.Secrets.RefreshInterval, logger)
+}
+
+// initLogger builds the logger, returning the level it logs at, which
+// changes when the configuration is reloaded
+func initLogger(cfg *config.Config) (*zap.Logger, zap.AtomicLevel, error) {
 	var zapCfg zap.Config
 
 	if cfg.Environment == "production" {
@@ -134,5 +233,6 @@ func initLogger(cfg *config.Config) (*zap.Logger, error) {
 		zapCfg.Encoding = "console"
 	}
 
-	return zapCfg.Build()
+	logger, err := zapCfg.Build()
+	return logger, zapCfg.Level, err
 }
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/db"
+	"github.com/lspecian/ovncp/internal/secrets"
+)
+
+const migrateUsage = `Usage: ovncp-api migrate <command>
+
+Applies, reverts and inspects the database migrations, which the server
+otherwise applies on startup unless DB_AUTO_MIGRATE=false. The database is
+configured as for the server, by the DB_ variables or CONFIG_FILE.
+
+Commands:
+  up                 Apply the pending migrations
+  down [steps]       Revert the last applied migrations, 1 by default
+  status             List the migrations and whether they're applied
+  baseline <version> Record the migrations up to version as applied without
+                     running them, for a schema created otherwise
+`
+
+// runMigrate runs `ovncp-api migrate` with args, writing what it did to out
+func runMigrate(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n\n%s", migrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	var steps int
+	switch {
+	case command == "up" || command == "status":
+		if len(args) != 0 {
+			return fmt.Errorf("%s takes no arguments", command)
+		}
+	case command == "down":
+		steps = 1
+		if len(args) > 1 {
+			return fmt.Errorf("down takes at most one argument")
+		}
+		if len(args) == 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q, expected a positive number", args[0])
+			}
+		}
+	case command == "baseline":
+		if len(args) != 1 {
+			return fmt.Errorf("baseline takes the version to record up to")
+		}
+	case command == "help" || command == "-h" || command == "--help":
+		fmt.Fprint(out, migrateUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, migrateUsage)
+	}
+
+	database, err := openDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var versions []string
+	switch command {
+	case "up":
+		versions, err = database.MigrateUp()
+		if len(versions) == 0 && err == nil {
+			fmt.Fprintln(out, "No pending migrations")
+		}
+		printMigrations(out, "Applied", versions)
+	case "down":
+		versions, err = database.MigrateDown(steps)
+		printMigrations(out, "Reverted", versions)
+	case "baseline":
+		versions, err = database.Baseline(args[0])
+		printMigrations(out, "Recorded", versions)
+	case "status":
+		var statuses []db.MigrationStatus
+		if statuses, err = database.MigrationStatus(); err == nil {
+			printMigrationStatus(out, statuses)
+		}
+	}
+	return err
+}
+
+// openDatabase connects to the database the server is configured with
+func openDatabase(ctx context.Context) (*db.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	password, err := secrets.NewConfiguredResolver(&cfg.Secrets).Resolve(ctx, cfg.Database.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DB_PASSWORD: %w", err)
+	}
+	cfg.Database.Password = password
+	return db.New(&cfg.Database)
+}
+
+func printMigrations(out io.Writer, action string, versions []string) {
+	for _, version := range versions {
+		fmt.Fprintf(out, "%s %s\n", action, version)
+	}
+}
+
+func printMigrationStatus(out io.Writer, statuses []db.MigrationStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", status.Version, state, appliedAt)
+	}
+	w.Flush()
+}
+
+// migrateMain runs `ovncp-api migrate` and exits
+func migrateMain(args []string) {
+	if err := runMigrate(context.Background(), args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
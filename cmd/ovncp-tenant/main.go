@@ -86,6 +86,7 @@ func main() {
 		Args:  cobra.ExactArgs(1),
 		RunE:  deleteTenant,
 	}
+	deleteCmd.Flags().Bool("force", false, "Also delete all of the tenant's resources")
 
 	// Usage command
 	usageCmd := &cobra.Command{
@@ -219,6 +220,7 @@ func makeRequest(method, endpoint string, body interface{}, headers map[string]s
 
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-OVNCP-Source", "cli")
 	
 	for key, value := range headers {
 		req.Header.Set(key, value)
@@ -385,7 +387,12 @@ func updateTenant(cmd *cobra.Command, args []string) error {
 }
 
 func deleteTenant(cmd *cobra.Command, args []string) error {
-	_, err := makeRequest("DELETE", "/api/v1/tenants/"+args[0], nil, nil)
+	endpoint := "/api/v1/tenants/" + args[0]
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		endpoint += "?force=true"
+	}
+
+	_, err := makeRequest("DELETE", endpoint, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lspecian/ovncp/pkg/client"
+)
+
+func newApplyCmd() *cobra.Command {
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply a desired state document",
+		Long: `Converge switches, routers, ports and ACLs to a YAML or JSON document.
+Use --dry-run to print the plan without changing anything.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			prune, _ := cmd.Flags().GetBool("prune")
+
+			document, err := readInput(file)
+			if err != nil {
+				return err
+			}
+
+			result, err := newClient().Apply(cmd.Context(), document, client.ApplyOptions{
+				DryRun: dryRun,
+				Prune:  prune,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printResult(result, func() {
+				printPlan(result)
+			})
+		},
+	}
+	applyCmd.Flags().StringP("file", "f", "", `Desired state file, or "-" for stdin (required)`)
+	applyCmd.Flags().Bool("dry-run", false, "Print the plan without applying it")
+	applyCmd.Flags().Bool("prune", false, "Delete resources missing from the document")
+	applyCmd.MarkFlagRequired("file")
+
+	return applyCmd
+}
+
+func printPlan(result *client.ApplyResult) {
+	symbols := map[string]string{"create": "+", "update": "~", "delete": "-"}
+
+	for _, change := range result.Plan.Changes {
+		name := change.Name
+		if change.Switch != "" {
+			name = change.Switch + "/" + name
+		}
+		line := fmt.Sprintf("%s %s %s", symbols[change.Action], change.ResourceType, name)
+		if len(change.Fields) > 0 {
+			line += " (" + strings.Join(change.Fields, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+
+	summary := result.Plan.Summary
+	verb := "Applied"
+	if result.DryRun {
+		verb = "Plan"
+	}
+	if len(result.Plan.Changes) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("%s: %d to create, %d to update, %d to delete, %d unchanged\n",
+		verb, summary.Create, summary.Update, summary.Delete, summary.Unchanged)
+}
+
+// readInput reads a file, or stdin when path is "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all resources with their IDs as json, yaml or hcl",
+		Long: `Export switches, ports, ACLs, routers and load balancers with their IDs and
+ETags. The hcl format emits Terraform resources with import blocks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			file, _ := cmd.Flags().GetString("file")
+
+			data, err := newClient().ExportResources(cmd.Context(), format)
+			if err != nil {
+				return err
+			}
+			return writeOutput(file, data)
+		},
+	}
+	exportCmd.Flags().String("format", "yaml", "Export format (json, yaml, hcl)")
+	exportCmd.Flags().StringP("file", "f", "", "Write to file instead of stdout")
+
+	return exportCmd
+}
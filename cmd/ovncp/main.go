@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lspecian/ovncp/pkg/client"
+)
+
+var (
+	apiURL   string
+	token    string
+	output   string
+	cluster  string
+	tenant   string
+	watch    bool
+	interval time.Duration
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "ovncp",
+		Short: "OVN Control Platform CLI",
+		Long:  `A command-line tool for managing OVN logical networks through the OVN Control Platform API`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case "table", "json", "yaml", "ndjson":
+				return nil
+			}
+			return fmt.Errorf("invalid output format %q (table, json, yaml, ndjson)", output)
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	// Global flags
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", getEnvOrDefault("OVNCP_URL", "http://localhost:8080"), "API URL")
+	rootCmd.PersistentFlags().StringVar(&token, "token", os.Getenv("OVNCP_TOKEN"), "API token")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "table", "Output format (table, json, yaml, ndjson); ndjson streams lists an item at a time")
+	rootCmd.PersistentFlags().StringVar(&cluster, "cluster", os.Getenv("OVNCP_CLUSTER"), "OVN cluster (defaults to the server's default cluster)")
+	rootCmd.PersistentFlags().StringVar(&tenant, "tenant", os.Getenv("OVNCP_TENANT"), "Tenant ID")
+
+	rootCmd.AddCommand(
+		newSwitchCmd(),
+		newRouterCmd(),
+		newPortCmd(),
+		newACLCmd(),
+		newLoadBalancerCmd(),
+		newFloatingIPCmd(),
+		newProviderNetworkCmd(),
+		newVPNCmd(),
+		newServiceCatalogCmd(),
+		newAccessCmd(),
+		newTopologyCmd(),
+		newBackupCmd(),
+		newDriftCmd(),
+		newMaintenanceCmd(),
+		newTraceCmd(),
+		newApplyCmd(),
+		newExportCmd(),
+		newDiagnosticsCmd(),
+		newCapabilitiesCmd(),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Helper functions
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func newClient() *client.Client {
+	opts := []client.Option{client.WithToken(token)}
+	if cluster != "" {
+		opts = append(opts, client.WithCluster(cluster))
+	}
+	if tenant != "" {
+		opts = append(opts, client.WithTenant(tenant))
+	}
+	return client.New(apiURL, opts...)
+}
+
+// addWatchFlags adds --watch and --interval to a read command
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Re-run the command every interval until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval for --watch")
+}
+
+// runWatched runs fn once, or repeatedly while --watch is set
+func runWatched(cmd *cobra.Command, fn func(ctx context.Context) error) error {
+	ctx := cmd.Context()
+	if !watch {
+		return fn(ctx)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Clear the screen so each refresh replaces the previous one
+		if output == "table" {
+			fmt.Print("\033[H\033[2J")
+		}
+		fmt.Printf("Every %s: %s\n\n", interval, time.Now().Format(time.RFC3339))
+
+		if err := fn(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printResult prints v as JSON, YAML or NDJSON, a line per item of a
+// list, or calls table for table output
+func printResult(v interface{}, table func()) error {
+	switch output {
+	case "ndjson":
+		if list := reflect.ValueOf(v); list.Kind() == reflect.Slice {
+			for i := 0; i < list.Len(); i++ {
+				if err := printNDJSON(list.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return printNDJSON(v)
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "yaml":
+		// Round-trip through JSON so YAML keys match the API field names
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+	default:
+		table()
+	}
+	return nil
+}
+
+// printNDJSON prints item as a line of JSON
+func printNDJSON[T any](item T) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func printTable(headers []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	// Print headers
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	// Print separator
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = strings.Repeat("-", len(headers[i]))
+	}
+	fmt.Fprintln(w, strings.Join(separators, "\t"))
+
+	// Print rows
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	w.Flush()
+}
+
+// printFields prints label/value pairs for a single resource
+func printFields(fields [][2]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", field[0], field[1])
+	}
+	w.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// parseKeyValues parses key=value flag values into a map
+func parseKeyValues(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(values))
+	for _, kv := range values {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid value %q, expected key=value", kv)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
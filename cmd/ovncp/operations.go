@@ -0,0 +1,801 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lspecian/ovncp/pkg/client"
+)
+
+// Topology
+
+func newTopologyCmd() *cobra.Command {
+	topologyCmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Inspect the logical network topology",
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Summarize the logical network topology",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				topology, err := newClient().GetTopology(ctx)
+				if err != nil {
+					return err
+				}
+				return printResult(topology, func() {
+					printTable([]string{"RESOURCE", "COUNT"}, [][]string{
+						{"switches", strconv.Itoa(len(topology.Switches))},
+						{"routers", strconv.Itoa(len(topology.Routers))},
+						{"switch ports", strconv.Itoa(len(topology.Ports))},
+						{"router ports", strconv.Itoa(len(topology.RouterPorts))},
+						{"acls", strconv.Itoa(len(topology.ACLs))},
+						{"connections", strconv.Itoa(len(topology.Connections))},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(showCmd)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the topology as json, dot, cytoscape, d3 or mermaid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			file, _ := cmd.Flags().GetString("file")
+
+			data, err := newClient().ExportTopology(cmd.Context(), format)
+			if err != nil {
+				return err
+			}
+			return writeOutput(file, data)
+		},
+	}
+	exportCmd.Flags().String("format", "json", "Export format (json, dot, cytoscape, d3, mermaid)")
+	exportCmd.Flags().StringP("file", "f", "", "Write to file instead of stdout")
+
+	topologyCmd.AddCommand(showCmd, exportCmd)
+	return topologyCmd
+}
+
+// Backups
+
+func newBackupCmd() *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:     "backup",
+		Aliases: []string{"backups"},
+		Short:   "Manage backups",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List backups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				backups, err := newClient().ListBackups(ctx)
+				if err != nil {
+					return err
+				}
+				return printResult(backups, func() {
+					rows := [][]string{}
+					for _, b := range backups {
+						rows = append(rows, []string{
+							b.ID,
+							b.Name,
+							b.Type,
+							strconv.FormatInt(b.Size, 10),
+							formatTime(b.CreatedAt),
+						})
+					}
+					printTable([]string{"ID", "NAME", "TYPE", "SIZE", "CREATED"}, rows)
+				})
+			})
+		},
+	}
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [backup-id]",
+		Short: "Get backup details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := newClient().GetBackup(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(b, func() {
+				printFields([][2]string{
+					{"ID", b.ID},
+					{"Name", b.Name},
+					{"Description", b.Description},
+					{"Type", b.Type},
+					{"Format", b.Format},
+					{"Size", strconv.FormatInt(b.Size, 10)},
+					{"Checksum", b.Checksum},
+					{"Created", formatTime(b.CreatedAt)},
+					{"Created by", b.CreatedBy},
+				})
+			})
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			description, _ := cmd.Flags().GetString("description")
+			compress, _ := cmd.Flags().GetBool("compress")
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+
+			b, err := newClient().CreateBackup(cmd.Context(), &client.CreateBackupRequest{
+				Name:        name,
+				Description: description,
+				Compress:    compress,
+				Tags:        tags,
+			})
+			if err != nil {
+				return err
+			}
+			return printResult(b, func() {
+				fmt.Printf("Backup %s created (%s)\n", b.Name, b.ID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Backup name (required)")
+	createCmd.Flags().String("description", "", "Description")
+	createCmd.Flags().Bool("compress", true, "Compress the backup")
+	createCmd.Flags().StringSlice("tag", nil, "Backup tags")
+	createCmd.MarkFlagRequired("name")
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [backup-id]",
+		Short: "Restore a backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			force, _ := cmd.Flags().GetBool("force")
+			conflictPolicy, _ := cmd.Flags().GetString("conflict-policy")
+			parallelism, _ := cmd.Flags().GetInt("parallelism")
+
+			result, err := newClient().RestoreBackup(cmd.Context(), args[0], &client.RestoreBackupRequest{
+				DryRun:         dryRun,
+				Force:          force,
+				ConflictPolicy: conflictPolicy,
+				Parallelism:    parallelism,
+			})
+			if err != nil {
+				return err
+			}
+			if err := printResult(result, func() {
+				printFields([][2]string{
+					{"Success", strconv.FormatBool(result.Success)},
+					{"Restored", strconv.Itoa(result.RestoredCount)},
+					{"Skipped", strconv.Itoa(result.SkippedCount)},
+					{"Errors", strconv.Itoa(result.ErrorCount)},
+				})
+				for _, e := range result.Errors {
+					fmt.Println("error:", e)
+				}
+				for _, w := range result.Warnings {
+					fmt.Println("warning:", w)
+				}
+			}); err != nil {
+				return err
+			}
+			if !result.Success {
+				return fmt.Errorf("restore completed with %d errors", result.ErrorCount)
+			}
+			return nil
+		},
+	}
+	restoreCmd.Flags().Bool("dry-run", false, "Validate the restore without applying it")
+	restoreCmd.Flags().Bool("force", false, "Restore even if validation fails")
+	restoreCmd.Flags().String("conflict-policy", "skip", "Conflict policy (skip, overwrite, rename, error)")
+	restoreCmd.Flags().Int("parallelism", 0, "Resources restored at a time, 0 for the server's default of 8")
+
+	diffCmd := &cobra.Command{
+		Use:   "diff [backup-id] [other-backup-id|live]",
+		Short: "Compare a backup with another or the live configuration",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			diff, err := newClient().DiffBackups(cmd.Context(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return printResult(diff, func() {
+				printDiff(diff.Added, diff.Removed, diff.Modified)
+			})
+		},
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [backup-id]",
+		Short: "Verify a backup restores, against the DR staging deployment",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reportFile, _ := cmd.Flags().GetString("report")
+
+			report, err := newClient().VerifyRestore(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if reportFile != "" {
+				if err := writeOutput(reportFile, report.Raw); err != nil {
+					return err
+				}
+			}
+			if err := printResult(report, func() {
+				printFields([][2]string{
+					{"Report", report.ID},
+					{"Backup", report.BackupID},
+					{"Staging", report.Staging},
+					{"Passed", strconv.FormatBool(report.Passed)},
+				})
+				var rows [][]string
+				for _, check := range report.Checks {
+					rows = append(rows, []string{check.Kind, strconv.Itoa(check.ExpectedCount), strconv.Itoa(check.ActualCount),
+						strconv.FormatBool(check.ExpectedChecksum == check.ActualChecksum), strconv.FormatBool(check.Passed)})
+				}
+				printTable([]string{"KIND", "EXPECTED", "ACTUAL", "CHECKSUM MATCH", "PASSED"}, rows)
+				if report.Restore != nil {
+					for _, e := range report.Restore.Errors {
+						fmt.Println("error:", e)
+					}
+				}
+			}); err != nil {
+				return err
+			}
+			if !report.Passed {
+				return fmt.Errorf("backup %s failed verification", args[0])
+			}
+			return nil
+		},
+	}
+	verifyCmd.Flags().String("report", "", "Write the signed report, as returned by the server, to a file")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [backup-id]",
+		Short: "Delete a backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteBackup(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Backup %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export [backup-id]",
+		Short: "Download a backup",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			file, _ := cmd.Flags().GetString("file")
+
+			data, err := newClient().ExportBackup(cmd.Context(), args[0], format)
+			if err != nil {
+				return err
+			}
+			return writeOutput(file, data)
+		},
+	}
+	exportCmd.Flags().String("format", "json", "Export format (json, yaml)")
+	exportCmd.Flags().StringP("file", "f", "", "Write to file instead of stdout")
+
+	backupCmd.AddCommand(listCmd, getCmd, createCmd, restoreCmd, diffCmd, verifyCmd, deleteCmd, exportCmd)
+	return backupCmd
+}
+
+// printDiff prints the objects added, removed and modified between two
+// configurations
+func printDiff(added, removed []client.BackupDiffObject, modified []client.BackupObjectChange) {
+	var rows [][]string
+	for _, o := range added {
+		rows = append(rows, []string{"added", o.Type, o.Name, o.ID, ""})
+	}
+	for _, o := range removed {
+		rows = append(rows, []string{"removed", o.Type, o.Name, o.ID, ""})
+	}
+	for _, o := range modified {
+		rows = append(rows, []string{"modified", o.Type, o.Name, o.ID, fieldNames(o.Fields)})
+	}
+	printTable([]string{"CHANGE", "TYPE", "NAME", "ID", "FIELDS"}, rows)
+}
+
+func fieldNames(changes []client.BackupFieldChange) string {
+	var fields []string
+	for _, f := range changes {
+		fields = append(fields, f.Field)
+	}
+	return strings.Join(fields, ",")
+}
+
+// Configuration drift
+
+// scopeName names the scope --tenant selects
+func scopeName() string {
+	if tenant != "" {
+		return "tenant " + tenant
+	}
+	return "the cluster"
+}
+
+func newDriftCmd() *cobra.Command {
+	driftCmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Compare the live configuration with golden backups",
+		Long: "Compare the live configuration with golden backups. Commands apply to the\n" +
+			"tenant selected with --tenant, or to the whole cluster without.",
+	}
+
+	goldenCmd := &cobra.Command{
+		Use:   "golden",
+		Short: "List the golden backups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			goldens, err := newClient().ListGoldenBackups(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printResult(goldens, func() {
+				var rows [][]string
+				for _, g := range goldens {
+					scope := g.TenantID
+					if scope == "" {
+						scope = "(cluster)"
+					}
+					rows = append(rows, []string{scope, g.BackupID, g.BackupName, g.MarkedBy, formatTime(g.MarkedAt)})
+				}
+				printTable([]string{"TENANT", "BACKUP", "NAME", "MARKED BY", "MARKED AT"}, rows)
+			})
+		},
+	}
+
+	markCmd := &cobra.Command{
+		Use:   "mark [backup-id]",
+		Short: "Mark a backup as the golden configuration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			golden, err := newClient().MarkGoldenBackup(cmd.Context(), tenant, args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(golden, func() {
+				fmt.Printf("Backup %s marked golden for %s\n", golden.BackupID, scopeName())
+			})
+		},
+	}
+
+	unmarkCmd := &cobra.Command{
+		Use:   "unmark",
+		Short: "Stop checking drift from the golden backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().UnmarkGoldenBackup(cmd.Context(), tenant); err != nil {
+				return err
+			}
+			fmt.Printf("Golden backup of %s unmarked\n", scopeName())
+			return nil
+		},
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the drift from the golden backup now",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := newClient().CheckDrift(cmd.Context(), tenant)
+			if err != nil {
+				return err
+			}
+			return printResult(report, func() {
+				if !report.Drifted {
+					fmt.Printf("No drift: %s matches its golden backup\n", scopeName())
+					return
+				}
+				printDiff(report.Added, report.Removed, report.Modified)
+			})
+		},
+	}
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the steps reconciling the live configuration with the golden backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := newClient().PlanReconcile(cmd.Context(), tenant)
+			if err != nil {
+				return err
+			}
+			return printResult(plan, func() { printReconcileSteps(plan.Steps) })
+		},
+	}
+
+	reconcileCmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile the live configuration with the golden backup",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := newClient().Reconcile(cmd.Context(), tenant)
+			if err != nil {
+				return err
+			}
+			if err := printResult(result, func() {
+				printFields([][2]string{
+					{"Success", strconv.FormatBool(result.Success)},
+					{"Applied", strconv.Itoa(result.Applied)},
+					{"Failed", strconv.Itoa(result.Failed)},
+				})
+				for _, e := range result.Errors {
+					fmt.Println("error:", e)
+				}
+			}); err != nil {
+				return err
+			}
+			if !result.Success {
+				return fmt.Errorf("reconciliation completed with %d errors", result.Failed)
+			}
+			return nil
+		},
+	}
+
+	driftCmd.AddCommand(goldenCmd, markCmd, unmarkCmd, checkCmd, planCmd, reconcileCmd)
+	return driftCmd
+}
+
+func printReconcileSteps(steps []client.ReconcileStep) {
+	var rows [][]string
+	for _, step := range steps {
+		rows = append(rows, []string{step.Action, step.Type, step.Name, step.Parent, fieldNames(step.Fields)})
+	}
+	printTable([]string{"ACTION", "TYPE", "NAME", "PARENT", "FIELDS"}, rows)
+}
+
+// Maintenance mode
+
+func newMaintenanceCmd() *cobra.Command {
+	maintenanceCmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Freeze writes to the API during maintenance",
+		Long: "Freeze writes to the API during maintenance. Commands apply to the tenant\n" +
+			"selected with --tenant, or to the whole cluster without.",
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "List the maintenance modes set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modes, err := newClient().ListMaintenanceModes(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printResult(modes, func() {
+				var rows [][]string
+				for _, m := range modes {
+					scope := m.TenantID
+					if scope == "" {
+						scope = "(cluster)"
+					}
+					eta := ""
+					if m.ETA != nil {
+						eta = formatTime(*m.ETA)
+					}
+					rows = append(rows, []string{scope, strconv.FormatBool(m.Frozen), m.Reason, eta, m.SetBy})
+				}
+				printTable([]string{"TENANT", "FROZEN", "REASON", "ETA", "SET BY"}, rows)
+			})
+		},
+	}
+
+	onCmd := &cobra.Command{
+		Use:   "on",
+		Short: "Enter maintenance mode, refusing writes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reason, _ := cmd.Flags().GetString("reason")
+			etaFlag, _ := cmd.Flags().GetString("eta")
+			req := &client.SetMaintenanceRequest{TenantID: tenant, Frozen: true, Reason: reason}
+			if etaFlag != "" {
+				eta, err := parseETA(etaFlag)
+				if err != nil {
+					return err
+				}
+				req.ETA = &eta
+			}
+
+			mode, err := newClient().SetMaintenance(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(mode, func() {
+				fmt.Printf("Writes to %s are frozen\n", scopeName())
+			})
+		},
+	}
+	onCmd.Flags().String("reason", "", "Reason given to refused writes")
+	onCmd.Flags().String("eta", "", "When writes are expected to be allowed again, as a duration from now (e.g. 30m) or an RFC 3339 time")
+
+	exemptCmd := &cobra.Command{
+		Use:   "exempt",
+		Short: "Exempt the tenant from a cluster-wide freeze",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tenant == "" {
+				return fmt.Errorf("--tenant is required")
+			}
+			mode, err := newClient().SetMaintenance(cmd.Context(), &client.SetMaintenanceRequest{TenantID: tenant})
+			if err != nil {
+				return err
+			}
+			return printResult(mode, func() {
+				fmt.Printf("Writes to %s are allowed during maintenance\n", scopeName())
+			})
+		},
+	}
+
+	offCmd := &cobra.Command{
+		Use:   "off",
+		Short: "Leave maintenance mode, or remove a tenant's exemption",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().ClearMaintenance(cmd.Context(), tenant); err != nil {
+				return err
+			}
+			fmt.Printf("Maintenance mode of %s cleared\n", scopeName())
+			return nil
+		},
+	}
+
+	maintenanceCmd.AddCommand(statusCmd, onCmd, exemptCmd, offCmd)
+	return maintenanceCmd
+}
+
+// parseETA reads a duration from now or an RFC 3339 time
+func parseETA(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(d), nil
+	}
+	eta, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --eta %q: expected a duration or an RFC 3339 time", value)
+	}
+	return eta, nil
+}
+
+// Flow traces
+
+func newTraceCmd() *cobra.Command {
+	traceCmd := &cobra.Command{
+		Use:   "trace",
+		Short: "Trace a packet through the logical network",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			simulate, _ := cmd.Flags().GetBool("simulate")
+			req := &client.FlowTraceRequest{}
+			req.SourcePort, _ = cmd.Flags().GetString("src-port")
+			req.SourceMAC, _ = cmd.Flags().GetString("src-mac")
+			req.SourceIP, _ = cmd.Flags().GetString("src-ip")
+			req.DestinationMAC, _ = cmd.Flags().GetString("dst-mac")
+			req.DestinationIP, _ = cmd.Flags().GetString("dst-ip")
+			req.Protocol, _ = cmd.Flags().GetString("protocol")
+			req.SourcePortNum, _ = cmd.Flags().GetInt("src-port-num")
+			req.DestinationPort, _ = cmd.Flags().GetInt("dst-port-num")
+			req.Verbose, _ = cmd.Flags().GetBool("verbose")
+
+			c := newClient()
+			if probe, _ := cmd.Flags().GetBool("probe"); probe {
+				probeReq := &client.ReachabilityProbeRequest{FlowTraceRequest: *req}
+				probeReq.Chassis, _ = cmd.Flags().GetString("chassis")
+				probeReq.Namespace, _ = cmd.Flags().GetString("namespace")
+				probeReq.Count, _ = cmd.Flags().GetInt("count")
+				result, err := c.ProbeReachability(cmd.Context(), probeReq)
+				if err != nil {
+					return err
+				}
+				return printResult(result, func() {
+					if result.Trace != nil {
+						printTraceHops(result.Trace)
+					} else {
+						fmt.Println("Logical trace failed:", result.TraceError)
+					}
+					fmt.Println()
+					printFields([][2]string{
+						{"Chassis", result.Chassis},
+						{"Answered", fmt.Sprintf("%d/%d", result.Probe.Received, result.Probe.Sent)},
+						{"Loss", fmt.Sprintf("%.1f%%", result.Probe.LossPercent)},
+						{"RTT min/avg/max", fmt.Sprintf("%.2f/%.2f/%.2f ms", result.Probe.RTTMinMS, result.Probe.RTTAvgMS, result.Probe.RTTMaxMS)},
+						{"Verdict", result.Verdict},
+					})
+					fmt.Println()
+					fmt.Println(result.Summary)
+				})
+			}
+
+			trace := c.TraceFlow
+			if simulate {
+				trace = c.SimulateFlowTrace
+			}
+
+			result, err := trace(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(result, func() {
+				printTraceHops(result)
+			})
+		},
+	}
+	traceCmd.Flags().String("src-port", "", "Source logical port (required)")
+	traceCmd.Flags().String("src-mac", "", "Source MAC address (required)")
+	traceCmd.Flags().String("src-ip", "", "Source IP address (required)")
+	traceCmd.Flags().String("dst-mac", "", "Destination MAC address")
+	traceCmd.Flags().String("dst-ip", "", "Destination IP address (required)")
+	traceCmd.Flags().String("protocol", "icmp", "Protocol (tcp, udp, icmp, icmp6)")
+	traceCmd.Flags().Int("src-port-num", 0, "Source L4 port")
+	traceCmd.Flags().Int("dst-port-num", 0, "Destination L4 port")
+	traceCmd.Flags().Bool("verbose", false, "Include raw ovn-trace output")
+	traceCmd.Flags().Bool("simulate", false, "Simulate the trace instead of running ovn-trace")
+	traceCmd.Flags().Bool("probe", false, "Also send probes from the source port's chassis (icmp, icmp6 or tcp)")
+	traceCmd.Flags().String("chassis", "", "Chassis to probe from, by default the source port's")
+	traceCmd.Flags().String("namespace", "", "Network namespace to probe from, by default the source port's")
+	traceCmd.Flags().Int("count", 0, "Probes sent, 0 for the server's default of 5")
+	traceCmd.MarkFlagRequired("src-port")
+	traceCmd.MarkFlagRequired("src-mac")
+	traceCmd.MarkFlagRequired("src-ip")
+	traceCmd.MarkFlagRequired("dst-ip")
+
+	return traceCmd
+}
+
+// printTraceHops prints the hops of a trace and how it ended
+func printTraceHops(result *client.FlowTraceResult) {
+	rows := [][]string{}
+	for _, hop := range result.Hops {
+		rows = append(rows, []string{
+			strconv.Itoa(hop.Index),
+			hop.Type,
+			hop.Component,
+			hop.Action,
+			hop.Description,
+		})
+	}
+	printTable([]string{"HOP", "TYPE", "COMPONENT", "ACTION", "DESCRIPTION"}, rows)
+	fmt.Println()
+	fmt.Println(result.Summary)
+	if result.DropReason != "" {
+		fmt.Println("Drop reason:", result.DropReason)
+	}
+}
+
+// Diagnostics
+
+func newDiagnosticsCmd() *cobra.Command {
+	diagnosticsCmd := &cobra.Command{
+		Use:   "diagnostics",
+		Short: "Download the diagnostics bundle to attach to support cases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			file, _ := cmd.Flags().GetString("file")
+
+			data, err := newClient().Diagnostics(cmd.Context(), format)
+			if err != nil {
+				return err
+			}
+			return writeOutput(file, data)
+		},
+	}
+	diagnosticsCmd.Flags().String("format", "json", "Bundle format (json, or tar for a .tar.gz also holding the configuration and a goroutine dump)")
+	diagnosticsCmd.Flags().StringP("file", "f", "", "Write to file instead of stdout")
+
+	diagnosticsCmd.AddCommand(newNorthdConsistencyCmd())
+	return diagnosticsCmd
+}
+
+func newNorthdConsistencyCmd() *cobra.Command {
+	northdCmd := &cobra.Command{
+		Use:   "northd",
+		Short: "Check that ovn-northd realized the northbound switches, routers and ports",
+		Long: "Check that ovn-northd created the southbound datapaths and port bindings of\n" +
+			"the northbound switches, routers and ports, and for how long those it didn't\n" +
+			"have been pending. Exits with an error when ovn-northd looks stuck.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			threshold, _ := cmd.Flags().GetDuration("threshold")
+
+			report, err := newClient().CheckNorthdConsistency(cmd.Context(), threshold)
+			if err != nil {
+				return err
+			}
+			err = printResult(report, func() {
+				fmt.Printf("Status: %s (%s)\n", report.Status, report.Summary)
+				if report.Northd != nil {
+					fmt.Printf("nb_cfg: %d, sb_cfg: %d, hv_cfg: %d\n", report.Northd.NbCfg, report.Northd.SbCfg, report.Northd.HvCfg)
+				}
+				if len(report.Pending) == 0 {
+					return
+				}
+				fmt.Println()
+				rows := [][]string{}
+				for _, object := range report.Pending {
+					rows = append(rows, []string{
+						object.Type,
+						object.Name,
+						object.UUID,
+						time.Duration(object.PendingSeconds * float64(time.Second)).Round(time.Second).String(),
+						strconv.FormatBool(object.Stuck),
+					})
+				}
+				printTable([]string{"TYPE", "NAME", "UUID", "PENDING", "STUCK"}, rows)
+			})
+			if err != nil {
+				return err
+			}
+			if report.Status == "stuck" {
+				return fmt.Errorf("ovn-northd looks stuck")
+			}
+			return nil
+		},
+	}
+	northdCmd.Flags().Duration("threshold", 0, "How long objects may stay pending before ovn-northd is reported stuck (30s by default)")
+	return northdCmd
+}
+
+func newCapabilitiesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "capabilities",
+		Short: "Show which features the OVN northbound schema of the cluster supports",
+		Long: "Show which features the OVN northbound schema of the cluster supports. The\n" +
+			"routes of features it lacks the tables or columns of answer 404 with the\n" +
+			"capability_unavailable code.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			capabilities, err := newClient().GetCapabilities(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printResult(capabilities, func() {
+				fmt.Printf("Schema version: %s\n\n", capabilities.SchemaVersion)
+				rows := [][]string{}
+				for _, feature := range capabilities.Features {
+					rows = append(rows, []string{
+						feature.Name,
+						strconv.FormatBool(feature.Available),
+						strings.Join(feature.Missing, ","),
+					})
+				}
+				printTable([]string{"FEATURE", "AVAILABLE", "MISSING"}, rows)
+			})
+		},
+	}
+}
+
+// writeOutput writes data to file, or to stdout when file is empty
+func writeOutput(file string, data []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", file)
+	return nil
+}
@@ -0,0 +1,1729 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lspecian/ovncp/pkg/client"
+)
+
+// Logical switches
+
+func newSwitchCmd() *cobra.Command {
+	switchCmd := &cobra.Command{
+		Use:     "switch",
+		Aliases: []string{"switches", "ls"},
+		Short:   "Manage logical switches",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List logical switches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				// Lists are streamed rather than held whole
+				if output == "ndjson" {
+					return newClient().StreamSwitches(ctx, printNDJSON[*client.LogicalSwitch])
+				}
+				switches, err := newClient().ListSwitches(ctx)
+				if err != nil {
+					return err
+				}
+				return printResult(switches, func() {
+					rows := [][]string{}
+					for _, ls := range switches {
+						rows = append(rows, []string{
+							ls.UUID,
+							ls.Name,
+							strconv.Itoa(len(ls.Ports)),
+							strconv.Itoa(len(ls.ACLs)),
+							ls.Description,
+						})
+					}
+					printTable([]string{"UUID", "NAME", "PORTS", "ACLS", "DESCRIPTION"}, rows)
+				})
+			})
+		},
+	}
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [switch-id]",
+		Short: "Get logical switch details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				ls, err := newClient().GetSwitch(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				return printResult(ls, func() {
+					printFields([][2]string{
+						{"UUID", ls.UUID},
+						{"Name", ls.Name},
+						{"Description", ls.Description},
+						{"Ports", strings.Join(ls.Ports, " ")},
+						{"ACLs", strings.Join(ls.ACLs, " ")},
+						{"Other config", formatMap(ls.OtherConfig)},
+						{"Created", formatTime(ls.CreatedAt)},
+						{"Updated", formatTime(ls.UpdatedAt)},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(getCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a logical switch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			description, _ := cmd.Flags().GetString("description")
+			otherConfig, _ := cmd.Flags().GetStringArray("other-config")
+
+			config, err := parseKeyValues(otherConfig)
+			if err != nil {
+				return err
+			}
+
+			created, err := newClient().CreateSwitch(cmd.Context(), &client.LogicalSwitch{
+				Name:        name,
+				Description: description,
+				OtherConfig: config,
+			})
+			if err != nil {
+				return err
+			}
+			return printResult(created, func() {
+				fmt.Printf("Switch %s created (%s)\n", created.Name, created.UUID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Switch name (required)")
+	createCmd.Flags().String("description", "", "Description")
+	createCmd.Flags().StringArray("other-config", nil, "Other config as key=value (repeatable)")
+	createCmd.MarkFlagRequired("name")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [switch-id]",
+		Short: "Delete a logical switch",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteSwitch(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Switch %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	switchCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd)
+	return switchCmd
+}
+
+// Logical routers
+
+func newRouterCmd() *cobra.Command {
+	routerCmd := &cobra.Command{
+		Use:     "router",
+		Aliases: []string{"routers", "lr"},
+		Short:   "Manage logical routers",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List logical routers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				if output == "ndjson" {
+					return newClient().StreamRouters(ctx, printNDJSON[*client.LogicalRouter])
+				}
+				routers, err := newClient().ListRouters(ctx)
+				if err != nil {
+					return err
+				}
+				return printResult(routers, func() {
+					rows := [][]string{}
+					for _, lr := range routers {
+						rows = append(rows, []string{
+							lr.UUID,
+							lr.Name,
+							strconv.Itoa(len(lr.Ports)),
+							strconv.Itoa(len(lr.StaticRoutes)),
+							strconv.Itoa(len(lr.NAT)),
+						})
+					}
+					printTable([]string{"UUID", "NAME", "PORTS", "ROUTES", "NAT"}, rows)
+				})
+			})
+		},
+	}
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [router-id]",
+		Short: "Get logical router details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				lr, err := newClient().GetRouter(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				return printResult(lr, func() {
+					routes := make([]string, 0, len(lr.StaticRoutes))
+					for _, route := range lr.StaticRoutes {
+						routes = append(routes, route.IPPrefix+" via "+route.Nexthop)
+					}
+					printFields([][2]string{
+						{"UUID", lr.UUID},
+						{"Name", lr.Name},
+						{"Description", lr.Description},
+						{"Ports", strings.Join(lr.Ports, " ")},
+						{"Static routes", strings.Join(routes, ", ")},
+						{"Options", formatMap(lr.Options)},
+						{"Created", formatTime(lr.CreatedAt)},
+						{"Updated", formatTime(lr.UpdatedAt)},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(getCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a logical router",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			description, _ := cmd.Flags().GetString("description")
+			options, _ := cmd.Flags().GetStringArray("option")
+
+			opts, err := parseKeyValues(options)
+			if err != nil {
+				return err
+			}
+
+			created, err := newClient().CreateRouter(cmd.Context(), &client.LogicalRouter{
+				Name:        name,
+				Description: description,
+				Options:     opts,
+			})
+			if err != nil {
+				return err
+			}
+			return printResult(created, func() {
+				fmt.Printf("Router %s created (%s)\n", created.Name, created.UUID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Router name (required)")
+	createCmd.Flags().String("description", "", "Description")
+	createCmd.Flags().StringArray("option", nil, "Router option as key=value (repeatable)")
+	createCmd.MarkFlagRequired("name")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [router-id]",
+		Short: "Delete a logical router",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteRouter(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Router %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	routerCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd)
+	return routerCmd
+}
+
+// Logical switch ports
+
+func newPortCmd() *cobra.Command {
+	portCmd := &cobra.Command{
+		Use:     "port",
+		Aliases: []string{"ports", "lsp"},
+		Short:   "Manage logical switch ports",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ports of a logical switch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switchID, _ := cmd.Flags().GetString("switch")
+			return runWatched(cmd, func(ctx context.Context) error {
+				if output == "ndjson" {
+					return newClient().StreamPorts(ctx, switchID, printNDJSON[*client.LogicalSwitchPort])
+				}
+				ports, err := newClient().ListPorts(ctx, switchID)
+				if err != nil {
+					return err
+				}
+				return printResult(ports, func() {
+					rows := [][]string{}
+					for _, port := range ports {
+						rows = append(rows, []string{
+							port.UUID,
+							port.Name,
+							port.Type,
+							strings.Join(port.Addresses, ", "),
+							formatBool(port.Up),
+						})
+					}
+					printTable([]string{"UUID", "NAME", "TYPE", "ADDRESSES", "UP"}, rows)
+				})
+			})
+		},
+	}
+	listCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	listCmd.MarkFlagRequired("switch")
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [port-id]",
+		Short: "Get logical switch port details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				port, err := newClient().GetPort(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				return printResult(port, func() {
+					printFields([][2]string{
+						{"UUID", port.UUID},
+						{"Name", port.Name},
+						{"Type", port.Type},
+						{"Switch", port.SwitchID},
+						{"Addresses", strings.Join(port.Addresses, ", ")},
+						{"Port security", strings.Join(port.PortSecurity, ", ")},
+						{"Up", formatBool(port.Up)},
+						{"Enabled", formatBool(port.Enabled)},
+						{"Options", formatMap(port.Options)},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(getCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a port on a logical switch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switchID, _ := cmd.Flags().GetString("switch")
+			name, _ := cmd.Flags().GetString("name")
+			portType, _ := cmd.Flags().GetString("type")
+			addresses, _ := cmd.Flags().GetStringArray("address")
+			portSecurity, _ := cmd.Flags().GetStringArray("port-security")
+
+			created, err := newClient().CreatePort(cmd.Context(), switchID, &client.LogicalSwitchPort{
+				Name:         name,
+				Type:         portType,
+				Addresses:    addresses,
+				PortSecurity: portSecurity,
+			})
+			if err != nil {
+				return err
+			}
+			return printResult(created, func() {
+				fmt.Printf("Port %s created (%s)\n", created.Name, created.UUID)
+			})
+		},
+	}
+	createCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	createCmd.Flags().String("name", "", "Port name (required)")
+	createCmd.Flags().String("type", "", "Port type (empty for a VIF port, router, localnet, ...)")
+	createCmd.Flags().StringArray("address", nil, `Port address such as "00:00:00:00:00:01 10.0.0.5" (repeatable)`)
+	createCmd.Flags().StringArray("port-security", nil, "Port security address (repeatable)")
+	createCmd.MarkFlagRequired("switch")
+	createCmd.MarkFlagRequired("name")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [port-id]",
+		Short: "Delete a logical switch port",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeletePort(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Port %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	portCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd)
+	return portCmd
+}
+
+// ACLs
+
+func newACLCmd() *cobra.Command {
+	aclCmd := &cobra.Command{
+		Use:     "acl",
+		Aliases: []string{"acls"},
+		Short:   "Manage ACLs",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ACLs of a logical switch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switchID, _ := cmd.Flags().GetString("switch")
+			return runWatched(cmd, func(ctx context.Context) error {
+				if output == "ndjson" {
+					return newClient().StreamACLs(ctx, switchID, printNDJSON[*client.ACL])
+				}
+				acls, err := newClient().ListACLs(ctx, switchID)
+				if err != nil {
+					return err
+				}
+				return printResult(acls, func() {
+					rows := [][]string{}
+					for _, acl := range acls {
+						rows = append(rows, []string{
+							acl.UUID,
+							acl.Name,
+							acl.Direction,
+							strconv.Itoa(acl.Priority),
+							acl.Match,
+							acl.Action,
+						})
+					}
+					printTable([]string{"UUID", "NAME", "DIRECTION", "PRIORITY", "MATCH", "ACTION"}, rows)
+				})
+			})
+		},
+	}
+	listCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	listCmd.MarkFlagRequired("switch")
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [acl-id]",
+		Short: "Get ACL details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				acl, err := newClient().GetACL(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				return printResult(acl, func() {
+					printFields([][2]string{
+						{"UUID", acl.UUID},
+						{"Name", acl.Name},
+						{"Direction", acl.Direction},
+						{"Priority", strconv.Itoa(acl.Priority)},
+						{"Match", acl.Match},
+						{"Action", acl.Action},
+						{"Log", strconv.FormatBool(acl.Log)},
+						{"Severity", acl.Severity},
+						{"Owner", acl.Owner},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(getCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an ACL on a logical switch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switchID, _ := cmd.Flags().GetString("switch")
+			name, _ := cmd.Flags().GetString("name")
+			direction, _ := cmd.Flags().GetString("direction")
+			priority, _ := cmd.Flags().GetInt("priority")
+			match, _ := cmd.Flags().GetString("match")
+			action, _ := cmd.Flags().GetString("action")
+			log, _ := cmd.Flags().GetBool("log")
+
+			created, err := newClient().CreateACL(cmd.Context(), switchID, &client.ACL{
+				Name:      name,
+				Direction: direction,
+				Priority:  priority,
+				Match:     match,
+				Action:    action,
+				Log:       log,
+			})
+			if err != nil {
+				return err
+			}
+			return printResult(created, func() {
+				fmt.Printf("ACL created (%s)\n", created.UUID)
+			})
+		},
+	}
+	createCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	createCmd.Flags().String("name", "", "ACL name")
+	createCmd.Flags().String("direction", "from-lport", "Direction (from-lport, to-lport)")
+	createCmd.Flags().Int("priority", 1000, "Priority (0-32767)")
+	createCmd.Flags().String("match", "", "OVN match expression (required)")
+	createCmd.Flags().String("action", "", "Action (allow, allow-related, drop, reject) (required)")
+	createCmd.Flags().Bool("log", false, "Log packets matching the ACL")
+	createCmd.MarkFlagRequired("switch")
+	createCmd.MarkFlagRequired("match")
+	createCmd.MarkFlagRequired("action")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [acl-id]",
+		Short: "Delete an ACL",
+		Long: "Delete an ACL. ACLs owned by a template or an operator, such as those of\n" +
+			"Kubernetes NetworkPolicies, can only be deleted with --force, and may be\n" +
+			"created again by their owner.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := newClient()
+			deleteACL := c.DeleteACL
+			if force, _ := cmd.Flags().GetBool("force"); force {
+				deleteACL = c.ForceDeleteACL
+			}
+			if err := deleteACL(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("ACL %s deleted\n", args[0])
+			return nil
+		},
+	}
+	deleteCmd.Flags().Bool("force", false, "Delete the ACL even if a template or an operator owns it")
+
+	aclCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd, newACLRolloutCmd(), newACLScheduleCmd())
+	return aclCmd
+}
+
+func newACLRolloutCmd() *cobra.Command {
+	rolloutCmd := &cobra.Command{
+		Use:     "rollout",
+		Aliases: []string{"rollouts"},
+		Short:   "Observe ACLs before enforcing them",
+		Long: "Roll ACLs out in observe mode: they're created allowing and logging the\n" +
+			"traffic they match, then enforced once the bake period ends, or rolled back\n" +
+			"when they match more traffic than the thresholds allow.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the ACL rollouts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, _ := cmd.Flags().GetString("status")
+			rollouts, err := newClient().ListACLRollouts(cmd.Context(), status)
+			if err != nil {
+				return err
+			}
+			return printResult(rollouts, func() {
+				var rows [][]string
+				for _, r := range rollouts {
+					rows = append(rows, []string{r.ID, r.SwitchID, strconv.Itoa(len(r.Rules)), r.Status, formatTime(r.BakeUntil), r.Reason})
+				}
+				printTable([]string{"ID", "SWITCH", "RULES", "STATUS", "BAKE UNTIL", "REASON"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().String("status", "", "Only list rollouts with this status (observing, promoted, rolled_back)")
+
+	getCmd := &cobra.Command{
+		Use:   "get [rollout-id]",
+		Short: "Show a rollout and the traffic its rules matched",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rollout, err := newClient().GetACLRollout(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(rollout, func() { printACLRollout(rollout) })
+		},
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start observing ACLs read from a JSON file",
+		Long: "Start observing the ACLs of a JSON file, or of stdin with -f -. The file\n" +
+			"holds a list of ACLs with the action they're enforced with once promoted.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switchID, _ := cmd.Flags().GetString("switch")
+			file, _ := cmd.Flags().GetString("file")
+			bake, _ := cmd.Flags().GetString("bake")
+			data, err := readInput(file)
+			if err != nil {
+				return err
+			}
+			req := &client.StartACLRolloutRequest{SwitchID: switchID, BakePeriod: bake}
+			if err := json.Unmarshal(data, &req.ACLs); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			if cmd.Flags().Changed("max-hits") {
+				maxHits, _ := cmd.Flags().GetUint64("max-hits")
+				req.Thresholds.MaxHits = &maxHits
+			}
+			if cmd.Flags().Changed("max-logged-flows") {
+				maxFlows, _ := cmd.Flags().GetInt("max-logged-flows")
+				req.Thresholds.MaxLoggedFlows = &maxFlows
+			}
+
+			rollout, err := newClient().StartACLRollout(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(rollout, func() {
+				fmt.Printf("ACL rollout %s started, observing %d rules until %s\n",
+					rollout.ID, len(rollout.Rules), formatTime(rollout.BakeUntil))
+			})
+		},
+	}
+	startCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	startCmd.Flags().StringP("file", "f", "", "JSON file of the ACLs, - for stdin (required)")
+	startCmd.Flags().String("bake", "", "How long the ACLs are observed (e.g. 2h); the server's default when empty")
+	startCmd.Flags().Uint64("max-hits", 0, "Roll back when the ACLs match more packets")
+	startCmd.Flags().Int("max-logged-flows", 0, "Roll back when the ACLs log more flows")
+	startCmd.MarkFlagRequired("switch")
+	startCmd.MarkFlagRequired("file")
+
+	promoteCmd := &cobra.Command{
+		Use:   "promote [rollout-id]",
+		Short: "Enforce the ACLs of a rollout now",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rollout, err := newClient().PromoteACLRollout(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(rollout, func() {
+				fmt.Printf("ACL rollout %s promoted\n", rollout.ID)
+			})
+		},
+	}
+
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback [rollout-id]",
+		Short: "Delete the ACLs of a rollout",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reason, _ := cmd.Flags().GetString("reason")
+			rollout, err := newClient().RollbackACLRollout(cmd.Context(), args[0], reason)
+			if err != nil {
+				return err
+			}
+			return printResult(rollout, func() {
+				fmt.Printf("ACL rollout %s rolled back\n", rollout.ID)
+			})
+		},
+	}
+	rollbackCmd.Flags().String("reason", "", "Why the rollout is rolled back")
+
+	rolloutCmd.AddCommand(listCmd, getCmd, startCmd, promoteCmd, rollbackCmd)
+	return rolloutCmd
+}
+
+func newACLScheduleCmd() *cobra.Command {
+	scheduleCmd := &cobra.Command{
+		Use:     "schedule",
+		Aliases: []string{"schedules"},
+		Short:   "Activate ACLs within time windows only",
+		Long: "Schedule ACLs to be enforced within daily time windows only, e.g. to allow\n" +
+			"backups from 02:00 to 04:00. Outside its windows, an ACL is kept with a\n" +
+			"match that never matches. Each toggle is recorded.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the scheduled ACLs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var active *bool
+			if cmd.Flags().Changed("active") {
+				value, _ := cmd.Flags().GetBool("active")
+				active = &value
+			}
+			schedules, err := newClient().ListACLSchedules(cmd.Context(), active)
+			if err != nil {
+				return err
+			}
+			return printResult(schedules, func() {
+				var rows [][]string
+				for _, schedule := range schedules {
+					rows = append(rows, []string{schedule.ACLID, formatScheduleWindows(schedule.Windows),
+						schedule.Timezone, strconv.FormatBool(schedule.Active)})
+				}
+				printTable([]string{"ACL", "WINDOWS", "TIMEZONE", "ACTIVE"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().Bool("active", false, "Only list ACLs that are enabled (true) or disabled (false)")
+
+	getCmd := &cobra.Command{
+		Use:   "get [acl-id]",
+		Short: "Show the schedule of an ACL",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedule, err := newClient().GetACLSchedule(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(schedule, func() {
+				printFields([][2]string{
+					{"ACL", schedule.ACLID},
+					{"Windows", formatScheduleWindows(schedule.Windows)},
+					{"Timezone", schedule.Timezone},
+					{"Match", schedule.Match},
+					{"Active", strconv.FormatBool(schedule.Active)},
+					{"Created By", schedule.CreatedBy},
+					{"Updated", formatTime(schedule.UpdatedAt)},
+				})
+			})
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set [acl-id]",
+		Short: "Set the windows an ACL is active in",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, _ := cmd.Flags().GetStringArray("window")
+			days, _ := cmd.Flags().GetStringSlice("days")
+			req := &client.SetACLScheduleRequest{}
+			req.Timezone, _ = cmd.Flags().GetString("timezone")
+			for _, value := range values {
+				start, end, ok := strings.Cut(value, "-")
+				if !ok {
+					return fmt.Errorf("invalid window %q: expected HH:MM-HH:MM", value)
+				}
+				req.Windows = append(req.Windows, client.ACLScheduleWindow{Start: start, End: end, Days: days})
+			}
+			schedule, err := newClient().SetACLSchedule(cmd.Context(), args[0], req)
+			if err != nil {
+				return err
+			}
+			return printResult(schedule, func() {
+				state := "disabled until its next window"
+				if schedule.Active {
+					state = "enabled"
+				}
+				fmt.Printf("ACL %s scheduled, %s\n", schedule.ACLID, state)
+			})
+		},
+	}
+	setCmd.Flags().StringArray("window", nil, `Window as HH:MM-HH:MM, e.g. "02:00-04:00" (repeatable, required)`)
+	setCmd.Flags().StringSlice("days", nil, "Days the windows start on, e.g. mon,wed,fri; every day when empty")
+	setCmd.Flags().String("timezone", "", "IANA time zone of the windows, e.g. Europe/Berlin; UTC when empty")
+	setCmd.MarkFlagRequired("window")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove [acl-id]",
+		Short: "Remove the schedule of an ACL, leaving it enabled",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteACLSchedule(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Schedule of ACL %s removed\n", args[0])
+			return nil
+		},
+	}
+
+	togglesCmd := &cobra.Command{
+		Use:   "toggles [acl-id]",
+		Short: "List when an ACL was enabled and disabled",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			toggles, err := newClient().ListACLScheduleToggles(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(toggles, func() {
+				var rows [][]string
+				for _, toggle := range toggles {
+					rows = append(rows, []string{formatTime(toggle.OccurredAt), toggle.Action, toggle.Reason, toggle.UserID})
+				}
+				printTable([]string{"TIME", "ACTION", "REASON", "USER"}, rows)
+			})
+		},
+	}
+
+	scheduleCmd.AddCommand(listCmd, getCmd, setCmd, removeCmd, togglesCmd)
+	return scheduleCmd
+}
+
+func formatScheduleWindows(windows []client.ACLScheduleWindow) string {
+	formatted := make([]string, 0, len(windows))
+	for _, window := range windows {
+		value := window.Start + "-" + window.End
+		if len(window.Days) > 0 {
+			value += " " + strings.Join(window.Days, ",")
+		}
+		formatted = append(formatted, value)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func printACLRollout(rollout *client.ACLRollout) {
+	fields := [][2]string{
+		{"ID", rollout.ID},
+		{"Switch", rollout.SwitchID},
+		{"Status", rollout.Status},
+		{"Started", formatTime(rollout.StartedAt)},
+		{"Bake Until", formatTime(rollout.BakeUntil)},
+	}
+	if rollout.Reason != "" {
+		fields = append(fields, [2]string{"Reason", rollout.Reason})
+	}
+	if o := rollout.Observation; o != nil {
+		if o.HitsRecorded {
+			fields = append(fields, [2]string{"Hits", strconv.FormatUint(o.Hits, 10)})
+		}
+		if o.LogsIngested {
+			fields = append(fields, [2]string{"Logged Flows", strconv.Itoa(o.LoggedFlows)})
+		}
+	}
+	printFields(fields)
+
+	var rows [][]string
+	for _, acl := range rollout.Rules {
+		rows = append(rows, []string{acl.UUID, acl.Name, acl.Direction, strconv.Itoa(acl.Priority), acl.Match, acl.Action})
+	}
+	fmt.Println()
+	printTable([]string{"UUID", "NAME", "DIRECTION", "PRIORITY", "MATCH", "ACTION"}, rows)
+}
+
+// Load balancers
+
+func newLoadBalancerCmd() *cobra.Command {
+	lbCmd := &cobra.Command{
+		Use:     "lb",
+		Aliases: []string{"load-balancer", "load-balancers"},
+		Short:   "Manage load balancers",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List load balancers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				if output == "ndjson" {
+					return newClient().StreamLoadBalancers(ctx, printNDJSON[*client.LoadBalancer])
+				}
+				lbs, err := newClient().ListLoadBalancers(ctx)
+				if err != nil {
+					return err
+				}
+				return printResult(lbs, func() {
+					rows := [][]string{}
+					for _, lb := range lbs {
+						rows = append(rows, []string{
+							lb.UUID,
+							lb.Name,
+							formatString(lb.Protocol),
+							formatMap(lb.VIPs),
+						})
+					}
+					printTable([]string{"UUID", "NAME", "PROTOCOL", "VIPS"}, rows)
+				})
+			})
+		},
+	}
+	addWatchFlags(listCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get [lb-id]",
+		Short: "Get load balancer details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatched(cmd, func(ctx context.Context) error {
+				lb, err := newClient().GetLoadBalancer(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				return printResult(lb, func() {
+					printFields([][2]string{
+						{"UUID", lb.UUID},
+						{"Name", lb.Name},
+						{"Protocol", formatString(lb.Protocol)},
+						{"VIPs", formatMap(lb.VIPs)},
+						{"Selection fields", strings.Join(lb.SelectionFields, ", ")},
+						{"Options", formatMap(lb.Options)},
+						{"Created", formatTime(lb.CreatedAt)},
+						{"Updated", formatTime(lb.UpdatedAt)},
+					})
+				})
+			})
+		},
+	}
+	addWatchFlags(getCmd)
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a load balancer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			protocol, _ := cmd.Flags().GetString("protocol")
+			vipFlags, _ := cmd.Flags().GetStringArray("vip")
+
+			vips, err := parseKeyValues(vipFlags)
+			if err != nil {
+				return err
+			}
+
+			lb := &client.LoadBalancer{
+				Name: name,
+				VIPs: vips,
+			}
+			if protocol != "" {
+				lb.Protocol = &protocol
+			}
+
+			created, err := newClient().CreateLoadBalancer(cmd.Context(), lb)
+			if err != nil {
+				return err
+			}
+			return printResult(created, func() {
+				fmt.Printf("Load balancer %s created (%s)\n", created.Name, created.UUID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Load balancer name (required)")
+	createCmd.Flags().String("protocol", "", "Protocol (tcp, udp, sctp)")
+	createCmd.Flags().StringArray("vip", nil, `VIP mapping such as "10.0.0.10:80=192.168.1.10:8080,192.168.1.11:8080" (repeatable, required)`)
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("vip")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [lb-id]",
+		Short: "Delete a load balancer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteLoadBalancer(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Load balancer %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	lbCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd)
+	return lbCmd
+}
+
+// Floating IPs
+
+func newFloatingIPCmd() *cobra.Command {
+	fipCmd := &cobra.Command{
+		Use:     "floating-ip",
+		Aliases: []string{"floating-ips", "fip"},
+		Short:   "Manage floating IPs",
+		Long: "Allocate external addresses from the configured pools and bind them to\n" +
+			"ports, translated by a dnat_and_snat rule on the pool's router.",
+	}
+
+	poolsCmd := &cobra.Command{
+		Use:   "pools",
+		Short: "List the floating IP pools",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pools, err := newClient().ListFloatingIPPools(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printResult(pools, func() {
+				var rows [][]string
+				for _, p := range pools {
+					rows = append(rows, []string{p.Name, p.CIDR, p.First + "-" + p.Last, p.Router, strconv.Itoa(p.Allocated)})
+				}
+				printTable([]string{"NAME", "CIDR", "RANGE", "ROUTER", "ALLOCATED"}, rows)
+			})
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List floating IPs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var bound *bool
+			if cmd.Flags().Changed("bound") {
+				b, _ := cmd.Flags().GetBool("bound")
+				bound = &b
+			}
+			fips, err := newClient().ListFloatingIPs(cmd.Context(), bound)
+			if err != nil {
+				return err
+			}
+			return printResult(fips, func() {
+				var rows [][]string
+				for _, f := range fips {
+					rows = append(rows, []string{f.ID, f.IP, f.Pool, f.PortID, f.LogicalIP, f.Description})
+				}
+				printTable([]string{"ID", "IP", "POOL", "PORT", "LOGICAL IP", "DESCRIPTION"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().Bool("bound", false, "Only list bound floating IPs, or unbound ones with --bound=false")
+
+	getCmd := &cobra.Command{
+		Use:   "get [floating-ip-id]",
+		Short: "Show a floating IP",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fip, err := newClient().GetFloatingIP(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(fip, func() { printFloatingIP(fip) })
+		},
+	}
+
+	allocateCmd := &cobra.Command{
+		Use:   "allocate",
+		Short: "Allocate a floating IP from a pool",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pool, _ := cmd.Flags().GetString("pool")
+			description, _ := cmd.Flags().GetString("description")
+			fip, err := newClient().AllocateFloatingIP(cmd.Context(), pool, description)
+			if err != nil {
+				return err
+			}
+			return printResult(fip, func() {
+				fmt.Printf("Floating IP %s allocated from pool %s (%s)\n", fip.IP, fip.Pool, fip.ID)
+			})
+		},
+	}
+	allocateCmd.Flags().String("pool", "", "Pool to allocate from; the first pool when empty")
+	allocateCmd.Flags().String("description", "", "What the floating IP is for")
+
+	bindCmd := &cobra.Command{
+		Use:   "bind [floating-ip-id]",
+		Short: "Bind a floating IP to a port",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.BindFloatingIPRequest{}
+			req.PortID, _ = cmd.Flags().GetString("port")
+			req.LogicalIP, _ = cmd.Flags().GetString("logical-ip")
+			req.Distributed, _ = cmd.Flags().GetBool("distributed")
+			req.GratuitousARP, _ = cmd.Flags().GetBool("garp")
+			fip, err := newClient().BindFloatingIP(cmd.Context(), args[0], req)
+			if err != nil {
+				return err
+			}
+			return printResult(fip, func() {
+				fmt.Printf("Floating IP %s bound to %s (%s)\n", fip.IP, fip.PortID, fip.LogicalIP)
+			})
+		},
+	}
+	bindCmd.Flags().String("port", "", "Port ID (required)")
+	bindCmd.Flags().String("logical-ip", "", "Address of the port to translate to; its first of the floating IP's family when empty")
+	bindCmd.Flags().Bool("distributed", false, "NAT on the port's chassis instead of the gateway")
+	bindCmd.Flags().Bool("garp", false, "Announce the address with gratuitous ARPs from the gateway")
+	bindCmd.MarkFlagRequired("port")
+
+	unbindCmd := &cobra.Command{
+		Use:   "unbind [floating-ip-id]",
+		Short: "Unbind a floating IP, keeping it allocated",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fip, err := newClient().UnbindFloatingIP(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(fip, func() {
+				fmt.Printf("Floating IP %s unbound\n", fip.IP)
+			})
+		},
+	}
+
+	releaseCmd := &cobra.Command{
+		Use:   "release [floating-ip-id]",
+		Short: "Return a floating IP to its pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().ReleaseFloatingIP(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Floating IP %s released\n", args[0])
+			return nil
+		},
+	}
+
+	fipCmd.AddCommand(poolsCmd, listCmd, getCmd, allocateCmd, bindCmd, unbindCmd, releaseCmd)
+	return fipCmd
+}
+
+func printFloatingIP(fip *client.FloatingIP) {
+	fields := [][2]string{
+		{"ID", fip.ID},
+		{"IP", fip.IP},
+		{"Pool", fip.Pool},
+		{"Router", fip.RouterID},
+		{"Description", fip.Description},
+		{"Allocated", formatTime(fip.AllocatedAt)},
+	}
+	if fip.PortID != "" {
+		fields = append(fields,
+			[2]string{"Port", fip.PortID},
+			[2]string{"Logical IP", fip.LogicalIP},
+			[2]string{"Distributed", strconv.FormatBool(fip.Distributed)},
+			[2]string{"Gratuitous ARP", strconv.FormatBool(fip.GratuitousARP)},
+		)
+		if fip.BoundAt != nil {
+			fields = append(fields, [2]string{"Bound", formatTime(*fip.BoundAt)})
+		}
+	}
+	printFields(fields)
+}
+
+// Provider networks
+
+func newProviderNetworkCmd() *cobra.Command {
+	pnCmd := &cobra.Command{
+		Use:     "provider-network",
+		Aliases: []string{"provider-networks", "pn"},
+		Short:   "Manage provider networks",
+		Long: "Bridge switches to physical networks with localnet ports, and attach\n" +
+			"routers to them through gateway ports on chassis mapping the network.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List provider networks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			networks, err := newClient().ListProviderNetworks(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return printResult(networks, func() {
+				var rows [][]string
+				for _, n := range networks {
+					rows = append(rows, []string{n.ID, n.Name, n.PhysicalNetwork, formatVLAN(n.VLAN), n.CIDR,
+						strconv.Itoa(len(n.Routers)), strconv.Itoa(len(n.Chassis))})
+				}
+				printTable([]string{"ID", "NAME", "PHYSICAL NETWORK", "VLAN", "CIDR", "ROUTERS", "CHASSIS"}, rows)
+			})
+		},
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get [network]",
+		Short: "Show a provider network and the routers attached to it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			network, err := newClient().GetProviderNetwork(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(network, func() { printProviderNetwork(network) })
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a provider network",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.CreateProviderNetworkRequest{}
+			req.Name, _ = cmd.Flags().GetString("name")
+			req.PhysicalNetwork, _ = cmd.Flags().GetString("physical-network")
+			req.VLAN, _ = cmd.Flags().GetInt("vlan")
+			req.CIDR, _ = cmd.Flags().GetString("cidr")
+			network, err := newClient().CreateProviderNetwork(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(network, func() {
+				fmt.Printf("Provider network %s created on %s (%s)\n", network.Name, network.PhysicalNetwork, network.ID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Network name (required)")
+	createCmd.Flags().String("physical-network", "", "Physical network, as named in the chassis' ovn-bridge-mappings (required)")
+	createCmd.Flags().Int("vlan", 0, "VLAN tag; untagged when 0")
+	createCmd.Flags().String("cidr", "", "Subnet of the network, which router addresses must lie in")
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("physical-network")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [network]",
+		Short: "Delete a provider network without routers attached",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteProviderNetwork(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Provider network %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	attachCmd := &cobra.Command{
+		Use:   "attach [network]",
+		Short: "Attach a router to a provider network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.AttachRouterRequest{}
+			req.RouterID, _ = cmd.Flags().GetString("router")
+			req.Networks, _ = cmd.Flags().GetStringArray("network")
+			req.MAC, _ = cmd.Flags().GetString("mac")
+			req.Chassis, _ = cmd.Flags().GetStringArray("chassis")
+			req.HAChassisGroup, _ = cmd.Flags().GetString("ha-chassis-group")
+			network, err := newClient().AttachRouterToProviderNetwork(cmd.Context(), args[0], req)
+			if err != nil {
+				return err
+			}
+			return printResult(network, func() {
+				fmt.Printf("Router %s attached to provider network %s\n", req.RouterID, network.Name)
+			})
+		},
+	}
+	attachCmd.Flags().String("router", "", "Router ID or name (required)")
+	attachCmd.Flags().StringArray("network", nil, `Address of the router's gateway port such as "203.0.113.2/24" (repeatable, required)`)
+	attachCmd.Flags().String("mac", "", "MAC of the gateway port; generated from the MAC pools when empty")
+	attachCmd.Flags().StringArray("chassis", nil, "Gateway chassis, highest priority first (repeatable); every gateway chassis mapping the network when empty")
+	attachCmd.Flags().String("ha-chassis-group", "", "HA chassis group to place the gateway port with")
+	attachCmd.MarkFlagRequired("router")
+	attachCmd.MarkFlagRequired("network")
+
+	detachCmd := &cobra.Command{
+		Use:   "detach [network]",
+		Short: "Detach a router from a provider network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			routerID, _ := cmd.Flags().GetString("router")
+			network, err := newClient().DetachRouterFromProviderNetwork(cmd.Context(), args[0], routerID)
+			if err != nil {
+				return err
+			}
+			return printResult(network, func() {
+				fmt.Printf("Router %s detached from provider network %s\n", routerID, network.Name)
+			})
+		},
+	}
+	detachCmd.Flags().String("router", "", "Router ID or name (required)")
+	detachCmd.MarkFlagRequired("router")
+
+	pnCmd.AddCommand(listCmd, getCmd, createCmd, deleteCmd, attachCmd, detachCmd)
+	return pnCmd
+}
+
+func printProviderNetwork(network *client.ProviderNetwork) {
+	printFields([][2]string{
+		{"ID", network.ID},
+		{"Name", network.Name},
+		{"Physical Network", network.PhysicalNetwork},
+		{"VLAN", formatVLAN(network.VLAN)},
+		{"CIDR", network.CIDR},
+		{"Localnet Port", network.LocalnetPortID},
+		{"Chassis", strings.Join(network.Chassis, ", ")},
+	})
+
+	var rows [][]string
+	for _, r := range network.Routers {
+		chassis := make([]string, 0, len(r.Chassis))
+		for _, ch := range r.Chassis {
+			chassis = append(chassis, fmt.Sprintf("%s:%d", ch.Name, ch.Priority))
+		}
+		rows = append(rows, []string{r.RouterName, r.RouterPortName, r.MAC, strings.Join(r.Networks, ", "), strings.Join(chassis, ", ")})
+	}
+	fmt.Println()
+	printTable([]string{"ROUTER", "PORT", "MAC", "NETWORKS", "CHASSIS"}, rows)
+}
+
+func formatVLAN(vlan int) string {
+	if vlan == 0 {
+		return "untagged"
+	}
+	return strconv.Itoa(vlan)
+}
+
+func newVPNCmd() *cobra.Command {
+	vpnCmd := &cobra.Command{
+		Use:     "vpn",
+		Aliases: []string{"vpn-connection", "vpn-connections"},
+		Short:   "Manage VPN connections",
+		Long: "Connect tenant routers to on-premises networks through IPsec gateways.\n" +
+			"A connection routes its remote subnets via the gateway and only lets\n" +
+			"its local subnets reach them.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List VPN connections and the state of their tunnels",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			routerID, _ := cmd.Flags().GetString("router")
+			state, _ := cmd.Flags().GetString("state")
+			conns, err := newClient().ListVPNConnections(cmd.Context(), routerID, state)
+			if err != nil {
+				return err
+			}
+			return printResult(conns, func() {
+				var rows [][]string
+				for _, conn := range conns {
+					rows = append(rows, []string{conn.ID, conn.Name, conn.RouterID, conn.PeerAddress,
+						strings.Join(conn.RemoteSubnets, ", "), formatTunnelState(conn.Status)})
+				}
+				printTable([]string{"ID", "NAME", "ROUTER", "PEER", "REMOTE SUBNETS", "TUNNEL"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().String("router", "", "Only list connections of this router")
+	listCmd.Flags().String("state", "", "Only list connections whose tunnel is up, connecting, down or unknown")
+
+	getCmd := &cobra.Command{
+		Use:   "get [connection]",
+		Short: "Show a VPN connection and the state of its tunnel",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conn, err := newClient().GetVPNConnection(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(conn, func() {
+				fields := [][2]string{
+					{"ID", conn.ID},
+					{"Name", conn.Name},
+					{"Description", conn.Description},
+					{"Router", conn.RouterID},
+					{"Gateway IP", conn.GatewayIP},
+					{"Peer", conn.PeerAddress},
+					{"PSK", conn.PSKRef},
+					{"Local Subnets", strings.Join(conn.LocalSubnets, ", ")},
+					{"Remote Subnets", strings.Join(conn.RemoteSubnets, ", ")},
+					{"Tunnel", formatTunnelState(conn.Status)},
+				}
+				if conn.Status != nil {
+					if conn.Status.EstablishedAt != nil {
+						fields = append(fields, [2]string{"Established", formatTime(*conn.Status.EstablishedAt)})
+					}
+					fields = append(fields,
+						[2]string{"Bytes In/Out", fmt.Sprintf("%d/%d", conn.Status.BytesIn, conn.Status.BytesOut)},
+						[2]string{"Detail", conn.Status.Detail},
+						[2]string{"Checked", formatTime(conn.Status.CheckedAt)})
+				}
+				printFields(fields)
+			})
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a VPN connection",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.CreateVPNConnectionRequest{}
+			req.Name, _ = cmd.Flags().GetString("name")
+			req.Description, _ = cmd.Flags().GetString("description")
+			req.RouterID, _ = cmd.Flags().GetString("router")
+			req.GatewayIP, _ = cmd.Flags().GetString("gateway-ip")
+			req.PeerAddress, _ = cmd.Flags().GetString("peer")
+			req.PSKRef, _ = cmd.Flags().GetString("psk-ref")
+			req.LocalSubnets, _ = cmd.Flags().GetStringArray("local-subnet")
+			req.RemoteSubnets, _ = cmd.Flags().GetStringArray("remote-subnet")
+			conn, err := newClient().CreateVPNConnection(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(conn, func() {
+				fmt.Printf("VPN connection %s to %s created (%s)\n", conn.Name, conn.PeerAddress, conn.ID)
+			})
+		},
+	}
+	createCmd.Flags().String("name", "", "Connection name (required)")
+	createCmd.Flags().String("description", "", "Connection description")
+	createCmd.Flags().String("router", "", "Router ID or name (required)")
+	createCmd.Flags().String("gateway-ip", "", "Address of the IPsec gateway on a network of the router (required)")
+	createCmd.Flags().String("peer", "", "Address of the on-premises peer (required)")
+	createCmd.Flags().String("psk-ref", "", `Reference to the pre-shared key, such as "vault:secret/data/vpn#dc1" (required)`)
+	createCmd.Flags().StringArray("local-subnet", nil, "Subnet behind the router (repeatable, required)")
+	createCmd.Flags().StringArray("remote-subnet", nil, "Subnet behind the peer (repeatable, required)")
+	for _, flag := range []string{"name", "router", "gateway-ip", "peer", "psk-ref", "local-subnet", "remote-subnet"} {
+		createCmd.MarkFlagRequired(flag)
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update [connection]",
+		Short: "Change a VPN connection; its routes and policies are rendered again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.UpdateVPNConnectionRequest{}
+			for flag, field := range map[string]**string{
+				"description": &req.Description,
+				"gateway-ip":  &req.GatewayIP,
+				"peer":        &req.PeerAddress,
+				"psk-ref":     &req.PSKRef,
+			} {
+				if cmd.Flags().Changed(flag) {
+					value, _ := cmd.Flags().GetString(flag)
+					*field = &value
+				}
+			}
+			req.LocalSubnets, _ = cmd.Flags().GetStringArray("local-subnet")
+			req.RemoteSubnets, _ = cmd.Flags().GetStringArray("remote-subnet")
+			conn, err := newClient().UpdateVPNConnection(cmd.Context(), args[0], req)
+			if err != nil {
+				return err
+			}
+			return printResult(conn, func() {
+				fmt.Printf("VPN connection %s updated\n", conn.Name)
+			})
+		},
+	}
+	updateCmd.Flags().String("description", "", "Connection description")
+	updateCmd.Flags().String("gateway-ip", "", "Address of the IPsec gateway")
+	updateCmd.Flags().String("peer", "", "Address of the on-premises peer")
+	updateCmd.Flags().String("psk-ref", "", "Reference to the pre-shared key")
+	updateCmd.Flags().StringArray("local-subnet", nil, "Subnet behind the router, replacing the current ones (repeatable)")
+	updateCmd.Flags().StringArray("remote-subnet", nil, "Subnet behind the peer, replacing the current ones (repeatable)")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [connection]",
+		Short: "Delete a VPN connection and its routes and policies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteVPNConnection(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("VPN connection %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	vpnCmd.AddCommand(listCmd, getCmd, createCmd, updateCmd, deleteCmd)
+	return vpnCmd
+}
+
+func newServiceCatalogCmd() *cobra.Command {
+	serviceCmd := &cobra.Command{
+		Use:     "service",
+		Aliases: []string{"services", "service-catalog", "svc"},
+		Short:   "Manage the service catalog",
+		Long: "Name L4 services once and reference them in ACL matches and templates\n" +
+			"as service(name), e.g. \"ip4 && service(postgresql)\". References are\n" +
+			"expanded into protocols and ports when the ACLs are created.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the built-in and custom services",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			protocol, _ := cmd.Flags().GetString("protocol")
+			entries, err := newClient().ListServices(cmd.Context(), protocol)
+			if err != nil {
+				return err
+			}
+			return printResult(entries, func() {
+				var rows [][]string
+				for _, entry := range entries {
+					rows = append(rows, []string{entry.Name, formatServicePorts(entry.Ports),
+						strconv.FormatBool(entry.BuiltIn), entry.Description})
+				}
+				printTable([]string{"NAME", "PORTS", "BUILT-IN", "DESCRIPTION"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().String("protocol", "", "Only list services with ports of this protocol")
+
+	getCmd := &cobra.Command{
+		Use:   "get [name]",
+		Short: "Show a service",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entry, err := newClient().GetService(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(entry, func() {
+				printFields([][2]string{
+					{"Name", entry.Name},
+					{"Description", entry.Description},
+					{"Ports", formatServicePorts(entry.Ports)},
+					{"Built-in", strconv.FormatBool(entry.BuiltIn)},
+					{"Tenant", entry.TenantID},
+					{"Created By", entry.CreatedBy},
+				})
+			})
+		},
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a custom service",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := serviceEntryRequest(cmd)
+			if err != nil {
+				return err
+			}
+			req.Name = args[0]
+			entry, err := newClient().CreateService(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(entry, func() {
+				fmt.Printf("Service %s created (%s)\n", entry.Name, formatServicePorts(entry.Ports))
+			})
+		},
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Replace the ports of a custom service; existing ACLs keep theirs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := serviceEntryRequest(cmd)
+			if err != nil {
+				return err
+			}
+			entry, err := newClient().UpdateService(cmd.Context(), args[0], req)
+			if err != nil {
+				return err
+			}
+			return printResult(entry, func() {
+				fmt.Printf("Service %s updated (%s)\n", entry.Name, formatServicePorts(entry.Ports))
+			})
+		},
+	}
+
+	for _, c := range []*cobra.Command{createCmd, updateCmd} {
+		c.Flags().String("description", "", "Service description")
+		c.Flags().StringArray("port", nil, `Port as protocol/port or protocol/first-last, e.g. "tcp/443" or "udp/5000-5010" (repeatable, required)`)
+		c.MarkFlagRequired("port")
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a custom service; existing ACLs keep their ports",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := newClient().DeleteService(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Service %s deleted\n", args[0])
+			return nil
+		},
+	}
+
+	expandCmd := &cobra.Command{
+		Use:   "expand [match]",
+		Short: "Show an ACL match with its service references expanded",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expanded, err := newClient().ExpandMatch(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(expanded)
+			return nil
+		},
+	}
+
+	serviceCmd.AddCommand(listCmd, getCmd, createCmd, updateCmd, deleteCmd, expandCmd)
+	return serviceCmd
+}
+
+// serviceEntryRequest reads a service entry from the --description and
+// --port flags
+func serviceEntryRequest(cmd *cobra.Command) (*client.ServiceEntryRequest, error) {
+	req := &client.ServiceEntryRequest{}
+	req.Description, _ = cmd.Flags().GetString("description")
+	ports, _ := cmd.Flags().GetStringArray("port")
+	for _, value := range ports {
+		protocol, portRange, ok := strings.Cut(value, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid port %q: expected protocol/port", value)
+		}
+		first, last, isRange := strings.Cut(portRange, "-")
+		port := client.ServicePort{Protocol: protocol}
+		var err error
+		if port.Port, err = strconv.Atoi(first); err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", value, err)
+		}
+		if isRange {
+			if port.EndPort, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid port %q: %w", value, err)
+			}
+		}
+		req.Ports = append(req.Ports, port)
+	}
+	return req, nil
+}
+
+func formatServicePorts(ports []client.ServicePort) string {
+	formatted := make([]string, 0, len(ports))
+	for _, port := range ports {
+		if port.EndPort != 0 {
+			formatted = append(formatted, fmt.Sprintf("%s/%d-%d", port.Protocol, port.Port, port.EndPort))
+		} else {
+			formatted = append(formatted, fmt.Sprintf("%s/%d", port.Protocol, port.Port))
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatTunnelState(status *client.VPNTunnelStatus) string {
+	if status == nil {
+		return "unknown"
+	}
+	return status.State
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func newAccessCmd() *cobra.Command {
+	accessCmd := &cobra.Command{
+		Use:     "access",
+		Aliases: []string{"access-grant", "access-grants"},
+		Short:   "Grant temporary access to ports and services",
+		Long: "Grant a source CIDR just-in-time access to a port or a service of a\n" +
+			"switch for a few hours. The access is removed when the grant expires.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List access grants, latest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, _ := cmd.Flags().GetString("status")
+			switchID, _ := cmd.Flags().GetString("switch")
+			grants, err := newClient().ListAccessGrants(cmd.Context(), status, switchID)
+			if err != nil {
+				return err
+			}
+			return printResult(grants, func() {
+				var rows [][]string
+				for _, grant := range grants {
+					rows = append(rows, []string{grant.ID, grant.SourceCIDR, formatAccessTarget(grant), grant.Status,
+						grant.GrantedBy, formatTime(grant.ExpiresAt)})
+				}
+				printTable([]string{"ID", "SOURCE", "TARGET", "STATUS", "GRANTED BY", "EXPIRES"}, rows)
+			})
+		},
+	}
+	listCmd.Flags().String("status", "", "Only list grants that are active, expired or revoked")
+	listCmd.Flags().String("switch", "", "Only list grants on this switch")
+
+	getCmd := &cobra.Command{
+		Use:   "get [grant]",
+		Short: "Show an access grant",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grant, err := newClient().GetAccessGrant(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(grant, func() {
+				fields := [][2]string{
+					{"ID", grant.ID},
+					{"Source", grant.SourceCIDR},
+					{"Switch", grant.SwitchID},
+					{"Port", grant.PortName},
+					{"Service", grant.Service},
+					{"Reason", grant.Reason},
+					{"ACL", grant.ACLID},
+					{"Status", grant.Status},
+					{"Granted By", grant.GrantedBy},
+					{"Granted", formatTime(grant.GrantedAt)},
+					{"Expires", formatTime(grant.ExpiresAt)},
+				}
+				if grant.EndedAt != nil {
+					fields = append(fields, [2]string{"Ended", formatTime(*grant.EndedAt)})
+				}
+				if grant.RevokedBy != "" {
+					fields = append(fields, [2]string{"Revoked By", grant.RevokedBy})
+				}
+				printFields(fields)
+			})
+		},
+	}
+
+	grantCmd := &cobra.Command{
+		Use:   "grant",
+		Short: "Grant a source CIDR access to a port or a service",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &client.GrantAccessRequest{}
+			req.SwitchID, _ = cmd.Flags().GetString("switch")
+			req.PortID, _ = cmd.Flags().GetString("port")
+			req.Service, _ = cmd.Flags().GetString("service")
+			req.SourceCIDR, _ = cmd.Flags().GetString("source")
+			req.Hours, _ = cmd.Flags().GetInt("hours")
+			req.Reason, _ = cmd.Flags().GetString("reason")
+			grant, err := newClient().GrantAccess(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return printResult(grant, func() {
+				fmt.Printf("Access granted to %s until %s (%s)\n", grant.SourceCIDR, formatTime(grant.ExpiresAt), grant.ID)
+			})
+		},
+	}
+	grantCmd.Flags().String("switch", "", "Switch ID or name (required)")
+	grantCmd.Flags().String("port", "", "Port ID or name on the switch")
+	grantCmd.Flags().String("service", "", "Service of the catalog, such as ssh")
+	grantCmd.Flags().String("source", "", "Source CIDR granted access (required)")
+	grantCmd.Flags().Int("hours", 1, "Hours until the grant expires")
+	grantCmd.Flags().String("reason", "", "Why access is granted")
+	grantCmd.MarkFlagRequired("switch")
+	grantCmd.MarkFlagRequired("source")
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke [grant]",
+		Short: "Remove the access of a grant before it expires",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grant, err := newClient().RevokeAccessGrant(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return printResult(grant, func() {
+				fmt.Printf("Access grant %s revoked\n", grant.ID)
+			})
+		},
+	}
+
+	accessCmd.AddCommand(listCmd, getCmd, grantCmd, revokeCmd)
+	return accessCmd
+}
+
+// formatAccessTarget describes the port and service a grant is to
+func formatAccessTarget(grant *client.AccessGrant) string {
+	switch {
+	case grant.PortName != "" && grant.Service != "":
+		return grant.Service + " on " + grant.PortName
+	case grant.PortName != "":
+		return grant.PortName
+	default:
+		return grant.Service + " on " + grant.SwitchID
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"path/filepath"
+
 	"github.com/gin-gonic/gin"
 	"github.com/lspecian/ovncp/internal/api/handlers"
 	"github.com/lspecian/ovncp/internal/backup"
@@ -10,18 +12,32 @@ import (
 	"go.uber.org/zap"
 )
 
-// RegisterBackupRoutes registers backup and restore routes
-func RegisterBackupRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInterface, cfg *config.Config, logger *zap.Logger) error {
+// RegisterBackupRoutes registers backup, restore and drift routes. It
+// returns the drift detector, which the leader runs every interval.
+func RegisterBackupRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInterface, drStaging *services.OVNClusterManager, cfg *config.Config, events services.EventPublisher, logger *zap.Logger) (*backup.DriftDetector, error) {
 	// Create backup storage
 	storagePath := cfg.GetBackupPath()
 	storage, err := backup.NewFileStorage(storagePath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// Golden backups are marked in a subdirectory, which isn't read as
+	// backups
+	golden, err := backup.NewFileGoldenStore(filepath.Join(storagePath, "drift", "golden.json"))
+	if err != nil {
+		return nil, err
 	}
 
 	// Create backup service and handler
 	backupService := backup.NewBackupService(ovnService, storage, logger)
+	backupService.SetEvents(events)
+	if drStaging != nil {
+		backupService.SetVerification(drStaging.Default().Service, cfg.DR.Staging.NorthboundDB, cfg.DR.SigningSecret)
+	}
 	backupHandler := handlers.NewBackupHandler(backupService, logger)
+	detector := backup.NewDriftDetector(backupService, golden, cfg.Drift.Interval, logger)
+	detector.SetEvents(events)
+	driftHandler := handlers.NewDriftHandler(detector, logger)
 
 	// Backup routes
 	backups := v1.Group("/backups")
@@ -49,6 +65,13 @@ func RegisterBackupRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInt
 			middleware.EndpointRateLimit(1, 5), // 1 req/s, burst 5
 			backupHandler.RestoreBackup)
 
+		// Verify a backup restores, against the DR staging deployment
+		// rather than the live one
+		backups.POST("/:id/verify",
+			middleware.RequirePermission("backups:restore"),
+			middleware.EndpointRateLimit(1, 5),
+			backupHandler.VerifyRestore)
+
 		// Delete backup (delete permission)
 		backups.DELETE("/:id",
 			middleware.RequirePermission("backups:delete"),
@@ -60,6 +83,12 @@ func RegisterBackupRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInt
 			middleware.RequirePermission("backups:read"),
 			backupHandler.ExportBackup)
 
+		// Compare a backup with another or the live configuration (read
+		// permission)
+		backups.GET("/:id/diff/:other",
+			middleware.RequirePermission("backups:read"),
+			backupHandler.DiffBackups)
+
 		// Import backup (write permission)
 		backups.POST("/import",
 			middleware.RequirePermission("backups:write"),
@@ -72,5 +101,45 @@ func RegisterBackupRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInt
 			backupHandler.ValidateBackup)
 	}
 
-	return nil
+	// Drift routes: the scope is a tenant with ?tenant_id=, the cluster
+	// without
+	drift := v1.Group("/drift")
+	{
+		// List the golden backups (read permission)
+		drift.GET("/golden",
+			middleware.RequirePermission("backups:read"),
+			driftHandler.ListGolden)
+
+		// Mark or unmark a golden backup (admin permission)
+		drift.PUT("/golden",
+			middleware.RequirePermission("admin"),
+			driftHandler.MarkGolden)
+		drift.DELETE("/golden",
+			middleware.RequirePermission("admin"),
+			driftHandler.UnmarkGolden)
+
+		// Reports of the last checks, and a check now (read permission)
+		drift.GET("",
+			middleware.RequirePermission("backups:read"),
+			driftHandler.Latest)
+		drift.POST("/check",
+			middleware.RequirePermission("backups:read"),
+			middleware.EndpointRateLimit(1, 5),
+			driftHandler.Check)
+
+		// Plan the reconciliation with the golden backup (read permission)
+		drift.GET("/plan",
+			middleware.RequirePermission("backups:read"),
+			middleware.EndpointRateLimit(1, 5),
+			driftHandler.Plan)
+
+		// Reconcile with the golden backup (admin permission, as restores)
+		drift.POST("/reconcile",
+			middleware.RequirePermission("backups:restore"),
+			middleware.RequirePermission("admin"),
+			middleware.EndpointRateLimit(1, 5),
+			driftHandler.Reconcile)
+	}
+
+	return detector, nil
 }
\ No newline at end of file
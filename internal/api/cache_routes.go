@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/handlers"
+	"github.com/lspecian/ovncp/internal/middleware"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// RegisterCacheRoutes registers the admin-only cache management routes
+func RegisterCacheRoutes(v1 *gin.RouterGroup, cache handlers.CacheManager, clusters *services.OVNClusterManager, logger *zap.Logger) {
+	cacheHandler := handlers.NewCacheHandler(cache, clusters, logger)
+
+	admin := v1.Group("/admin/cache")
+	admin.Use(middleware.RequirePermission("admin"))
+	{
+		admin.GET("/stats", cacheHandler.Stats)
+		admin.POST("/warm", cacheHandler.Warm)
+		admin.POST("/clear", cacheHandler.Clear)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/handlers"
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/middleware"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// RegisterChangesetRoutes registers saved transaction (changeset) routes
+func RegisterChangesetRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInterface, cfg *config.Config, logger *zap.Logger) error {
+	store, err := services.NewFileChangesetStore(cfg.GetChangesetPath())
+	if err != nil {
+		return err
+	}
+
+	changesetService := services.NewChangesetService(store, ovnService, logger)
+	changesetHandler := handlers.NewChangesetHandler(changesetService)
+
+	changesets := v1.Group("/changesets")
+	changesets.Use(middleware.RequirePermission("changesets:read"))
+	{
+		changesets.GET("", changesetHandler.List)
+		changesets.GET("/:id", changesetHandler.Get)
+
+		// Plan renders the changeset without changing anything
+		changesets.POST("/:id/plan", changesetHandler.Plan)
+
+		changesets.POST("",
+			middleware.RequirePermission("changesets:write"),
+			changesetHandler.Create)
+		changesets.PUT("/:id",
+			middleware.RequirePermission("changesets:write"),
+			changesetHandler.Update)
+		changesets.DELETE("/:id",
+			middleware.RequirePermission("changesets:write"),
+			changesetHandler.Delete)
+		changesets.POST("/:id/submit",
+			middleware.RequirePermission("changesets:write"),
+			changesetHandler.Submit)
+
+		// Reviewing is kept separate from writing so that authors can't
+		// approve their own changes
+		changesets.POST("/:id/approve",
+			middleware.RequirePermission("changesets:approve"),
+			changesetHandler.Approve)
+		changesets.POST("/:id/reject",
+			middleware.RequirePermission("changesets:approve"),
+			changesetHandler.Reject)
+
+		changesets.POST("/:id/execute",
+			middleware.RequirePermission("changesets:execute"),
+			middleware.EndpointRateLimit(1, 5),
+			changesetHandler.Execute)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/cluster"
 )
 
@@ -69,9 +70,7 @@ func (h *ClusterHandler) getNode(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{
-		"error": "node not found",
-	})
+	problem.Respond(c, problem.New(http.StatusNotFound, "node not found"))
 }
 
 // getLeader returns the current cluster leader
@@ -129,9 +128,7 @@ func (h *ClusterHandler) getSession(c *gin.Context) {
 	
 	session, err := h.sessionStore.GetSession(c.Request.Context(), sessionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "session not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "session not found"))
 		return
 	}
 
@@ -144,9 +141,7 @@ func (h *ClusterHandler) getUserSessions(c *gin.Context) {
 	
 	sessions, err := h.sessionStore.GetSessionsForUser(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -166,9 +161,7 @@ func (h *ClusterHandler) migrateSession(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").WithError(err))
 		return
 	}
 
@@ -182,17 +175,13 @@ func (h *ClusterHandler) migrateSession(c *gin.Context) {
 	}
 	
 	if !found {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "target node not found or not active",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "target node not found or not active"))
 		return
 	}
 
 	err := h.sessionStore.MigrateSession(c.Request.Context(), sessionID, req.TargetNodeID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -220,14 +209,10 @@ func (h *ClusterHandler) releaseLock(c *gin.Context) {
 	err := h.lockManager.ReleaseLock(c.Request.Context(), key)
 	if err != nil {
 		if err == cluster.ErrLockNotHeld {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "lock not held by this node",
-			})
+			problem.Respond(c, problem.New(http.StatusNotFound, "lock not held by this node"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
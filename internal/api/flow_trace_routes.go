@@ -1,10 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/services"
 	"github.com/lspecian/ovncp/pkg/ovn"
 	"go.uber.org/zap"
@@ -14,6 +17,7 @@ import (
 type FlowTraceHandler struct {
 	traceService *services.FlowTraceService
 	ovnService   *services.OVNService
+	prober       *services.ReachabilityProber
 	logger       *zap.Logger
 }
 
@@ -27,6 +31,12 @@ func NewFlowTraceHandler(ovnClient *ovn.Client, ovnService *services.OVNService,
 	}
 }
 
+// SetProbeAgent enables reachability probes, sent by agent from the chassis
+// source ports are bound to, which bindings tells
+func (h *FlowTraceHandler) SetProbeAgent(agent services.ProbeAgent, bindings services.PortBindingReader, timeout time.Duration) {
+	h.prober = services.NewReachabilityProber(h.traceService, bindings, agent, timeout, h.logger)
+}
+
 // RegisterFlowTraceRoutes registers flow trace routes
 func (h *FlowTraceHandler) RegisterFlowTraceRoutes(router *gin.RouterGroup) {
 	trace := router.Group("/trace")
@@ -36,6 +46,7 @@ func (h *FlowTraceHandler) RegisterFlowTraceRoutes(router *gin.RouterGroup) {
 		trace.POST("/connectivity", h.analyzeConnectivity)
 		trace.GET("/ports/:port/addresses", h.getPortAddresses)
 		trace.POST("/simulate", h.simulateFlow)
+		trace.POST("/probe", h.probe)
 	}
 }
 
@@ -43,9 +54,7 @@ func (h *FlowTraceHandler) RegisterFlowTraceRoutes(router *gin.RouterGroup) {
 func (h *FlowTraceHandler) traceFlow(c *gin.Context) {
 	var req ovn.FlowTraceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request: "+err.Error()))
 		return
 	}
 
@@ -55,9 +64,7 @@ func (h *FlowTraceHandler) traceFlow(c *gin.Context) {
 	if req.SourcePort != "" {
 		port, err := h.ovnService.GetPort(ctx, req.SourcePort)
 		if err != nil || port == nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Source port not found",
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Source port not found"))
 			return
 		}
 		
@@ -71,9 +78,7 @@ func (h *FlowTraceHandler) traceFlow(c *gin.Context) {
 	result, err := h.traceService.TraceFlow(ctx, &req)
 	if err != nil {
 		h.logger.Error("Flow trace failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Flow trace failed: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Flow trace failed: "+err.Error()))
 		return
 	}
 
@@ -84,9 +89,7 @@ func (h *FlowTraceHandler) traceFlow(c *gin.Context) {
 func (h *FlowTraceHandler) traceMultiplePaths(c *gin.Context) {
 	var req services.MultiPathTraceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request: "+err.Error()))
 		return
 	}
 
@@ -96,9 +99,7 @@ func (h *FlowTraceHandler) traceMultiplePaths(c *gin.Context) {
 	result, err := h.traceService.TraceMultiplePaths(ctx, &req)
 	if err != nil {
 		h.logger.Error("Multi-path trace failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Multi-path trace failed: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Multi-path trace failed: "+err.Error()))
 		return
 	}
 
@@ -109,9 +110,7 @@ func (h *FlowTraceHandler) traceMultiplePaths(c *gin.Context) {
 func (h *FlowTraceHandler) analyzeConnectivity(c *gin.Context) {
 	var req services.ConnectivityAnalysisRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request: "+err.Error()))
 		return
 	}
 
@@ -121,9 +120,7 @@ func (h *FlowTraceHandler) analyzeConnectivity(c *gin.Context) {
 	result, err := h.traceService.AnalyzeConnectivity(ctx, &req)
 	if err != nil {
 		h.logger.Error("Connectivity analysis failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Connectivity analysis failed: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Connectivity analysis failed: "+err.Error()))
 		return
 	}
 
@@ -138,9 +135,7 @@ func (h *FlowTraceHandler) getPortAddresses(c *gin.Context) {
 	// Get port details
 	port, err := h.ovnService.GetPort(ctx, portID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Port not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Port not found"))
 		return
 	}
 
@@ -174,9 +169,7 @@ func (h *FlowTraceHandler) getPortAddresses(c *gin.Context) {
 func (h *FlowTraceHandler) simulateFlow(c *gin.Context) {
 	var req ovn.FlowTraceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request: "+err.Error()))
 		return
 	}
 
@@ -186,9 +179,7 @@ func (h *FlowTraceHandler) simulateFlow(c *gin.Context) {
 	result, err := h.ovnService.GetOVNClient().SimulateFlowTrace(ctx, &req)
 	if err != nil {
 		h.logger.Error("Flow simulation failed", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Flow simulation failed: " + err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Flow simulation failed: "+err.Error()))
 		return
 	}
 
@@ -196,4 +187,37 @@ func (h *FlowTraceHandler) simulateFlow(c *gin.Context) {
 	result.Summary = "[SIMULATION] " + result.Summary
 
 	c.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+}
+
+// probe traces a flow and probes it from the source port's chassis, to
+// tell whether a failure is policy or the dataplane
+func (h *FlowTraceHandler) probe(c *gin.Context) {
+	if h.prober == nil {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "Reachability probes unavailable").
+			WithDetail("PROBE_AGENT_COMMAND is not configured"))
+		return
+	}
+
+	var req services.ReachabilityProbeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request: "+err.Error()))
+		return
+	}
+
+	result, err := h.prober.Probe(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidProbe):
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").WithError(err))
+		case strings.Contains(err.Error(), "not connected"):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+		default:
+			h.logger.Error("Reachability probe failed", zap.Error(err))
+			problem.Respond(c, problem.New(http.StatusBadGateway, "Reachability probe failed").WithError(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// AccessGrants grants temporary access to ports and services.
+// *services.AccessGrantService implements it.
+type AccessGrants interface {
+	Grant(ctx context.Context, req *services.AccessGrantRequest, user string) (*models.AccessGrant, error)
+	Get(ctx context.Context, id string) (*models.AccessGrant, error)
+	List(ctx context.Context) ([]*models.AccessGrant, error)
+	Revoke(ctx context.Context, id, user string) (*models.AccessGrant, error)
+}
+
+// AccessGrantHandler serves access grants at /api/v1/access-grants
+type AccessGrantHandler struct {
+	grants AccessGrants
+}
+
+func NewAccessGrantHandler(grants AccessGrants) *AccessGrantHandler {
+	return &AccessGrantHandler{grants: grants}
+}
+
+// List handles GET /access-grants, listing the tenant's grants, or every
+// tenant's without one, latest first. ?status= and ?switch_id= filter
+// them.
+func (h *AccessGrantHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	grants, err := h.grants.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	status, switchID := c.Query("status"), c.Query("switch_id")
+	if status != "" || switchID != "" {
+		filtered := []*models.AccessGrant{}
+		for _, grant := range grants {
+			if (status == "" || grant.Status == status) && (switchID == "" || grant.SwitchID == switchID) {
+				filtered = append(filtered, grant)
+			}
+		}
+		grants = filtered
+	}
+
+	pageItems := pagination.Slice(grants, page)
+	c.JSON(http.StatusOK, gin.H{
+		"access_grants": pageItems,
+		"count":         len(pageItems),
+		"pagination":    pagination.Response(c, page, len(grants)),
+	})
+}
+
+// Get handles GET /access-grants/:id
+func (h *AccessGrantHandler) Get(c *gin.Context) {
+	grant, err := h.grants.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// Grant handles POST /access-grants
+func (h *AccessGrantHandler) Grant(c *gin.Context) {
+	var req services.AccessGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	grant, err := h.grants.Grant(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, grant)
+}
+
+// Revoke handles POST /access-grants/:id/revoke, removing the access
+// before the grant expires. The grant is kept as its record.
+func (h *AccessGrantHandler) Revoke(c *gin.Context) {
+	grant, err := h.grants.Revoke(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+func (h *AccessGrantHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrAccessGrantNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "access grant not found"))
+	case errors.Is(err, services.ErrInvalidAccessGrant):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid access grant").
+			WithError(err))
+	case errors.Is(err, services.ErrAccessGrantEnded):
+		problem.Respond(c, problem.New(http.StatusConflict, "access grant ended").
+			WithError(err))
+	case errors.Is(err, services.ErrResourceNotInTenant):
+		problem.Respond(c, problem.New(http.StatusNotFound, "switch not found").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
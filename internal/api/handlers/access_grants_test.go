@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeAccessGrants grants access on switch ls-1 only
+type fakeAccessGrants struct {
+	grants map[string]*models.AccessGrant
+}
+
+func (f *fakeAccessGrants) Grant(ctx context.Context, req *services.AccessGrantRequest, user string) (*models.AccessGrant, error) {
+	if req.SwitchID != "ls-1" {
+		return nil, services.ErrResourceNotInTenant
+	}
+	if req.PortID == "" && req.Service == "" {
+		return nil, services.ErrInvalidAccessGrant
+	}
+	grant := &models.AccessGrant{ID: "grant-1", SwitchID: req.SwitchID, SourceCIDR: req.SourceCIDR,
+		Status: models.AccessGrantActive, GrantedBy: user}
+	f.grants[grant.ID] = grant
+	return grant, nil
+}
+
+func (f *fakeAccessGrants) Get(ctx context.Context, id string) (*models.AccessGrant, error) {
+	grant, ok := f.grants[id]
+	if !ok {
+		return nil, services.ErrAccessGrantNotFound
+	}
+	return grant, nil
+}
+
+func (f *fakeAccessGrants) List(ctx context.Context) ([]*models.AccessGrant, error) {
+	grants := []*models.AccessGrant{}
+	for _, grant := range f.grants {
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+func (f *fakeAccessGrants) Revoke(ctx context.Context, id, user string) (*models.AccessGrant, error) {
+	grant, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != models.AccessGrantActive {
+		return nil, services.ErrAccessGrantEnded
+	}
+	grant.Status, grant.RevokedBy = models.AccessGrantRevoked, user
+	return grant, nil
+}
+
+func TestAccessGrantHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewAccessGrantHandler(&fakeAccessGrants{grants: map[string]*models.AccessGrant{}})
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/access-grants", handler.List)
+	router.GET("/access-grants/:id", handler.Get)
+	router.POST("/access-grants", handler.Grant)
+	router.POST("/access-grants/:id/revoke", handler.Revoke)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/access-grants", `{"switch_id": "ls-1", "service": "ssh", "source_cidr": "10.0.0.0/24"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "hours are required")
+	w = serve(http.MethodPost, "/access-grants", `{"switch_id": "ls-1", "source_cidr": "10.0.0.0/24", "hours": 2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodPost, "/access-grants", `{"switch_id": "ls-other", "service": "ssh", "source_cidr": "10.0.0.0/24", "hours": 2}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(http.MethodPost, "/access-grants", `{"switch_id": "ls-1", "service": "ssh", "source_cidr": "10.0.0.0/24", "hours": 2}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var grant models.AccessGrant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &grant))
+	assert.Equal(t, "alice", grant.GrantedBy)
+
+	w = serve(http.MethodGet, "/access-grants?status=active", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	w = serve(http.MethodGet, "/access-grants?switch_id=ls-2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+
+	w = serve(http.MethodPost, "/access-grants/grant-1/revoke", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"revoked_by":"alice"`)
+	w = serve(http.MethodPost, "/access-grants/grant-1/revoke", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(http.MethodGet, "/access-grants/grant-2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ACLLogReader returns stored ACL log entries. ACLLogIngester implements it.
+type ACLLogReader interface {
+	Entries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error)
+}
+
+// ACLLogHandler serves the packets ACLs with logging enabled matched
+type ACLLogHandler struct {
+	ovnService services.OVNServiceInterface
+	logs       ACLLogReader
+}
+
+// NewACLLogHandler creates a handler. logs is nil when ACL logs aren't
+// ingested.
+func NewACLLogHandler(ovnService services.OVNServiceInterface, logs ACLLogReader) *ACLLogHandler {
+	return &ACLLogHandler{
+		ovnService: ovnService,
+		logs:       logs,
+	}
+}
+
+// List handles GET /api/v1/acls/:id/logs, returning the most recent log
+// entries of the ACL between the from and to times, by default the last
+// day. They may be filtered by verdict, protocol, src_ip, dst_ip, src_port
+// and dst_port, and limit sets how many are returned.
+func (h *ACLLogHandler) List(c *gin.Context) {
+	if h.logs == nil {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "ACL logs unavailable").
+			WithDetail("neither ACL_LOG_FILE nor ACL_LOG_SYSLOG_ADDR is configured"))
+		return
+	}
+
+	filter := &models.ACLLogFilter{
+		To:       time.Now().UTC(),
+		Verdict:  c.Query("verdict"),
+		Protocol: c.Query("protocol"),
+		SrcIP:    c.Query("src_ip"),
+		DstIP:    c.Query("dst_ip"),
+	}
+	filter.From = filter.To.Add(-24 * time.Hour)
+	for param, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param+" time").
+					WithDetail("expected RFC 3339, e.g. 2024-01-02T15:04:05Z"))
+				return
+			}
+			*t = parsed
+		}
+	}
+	switch filter.Verdict {
+	case "", "allow", "drop", "reject":
+	default:
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid verdict").
+			WithDetail("expected allow, drop or reject"))
+		return
+	}
+	for param, ip := range map[string]string{"src_ip": filter.SrcIP, "dst_ip": filter.DstIP} {
+		if ip != "" && net.ParseIP(ip) == nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param).
+				WithDetail("expected an IPv4 or IPv6 address"))
+			return
+		}
+	}
+	for param, n := range map[string]*int{"src_port": &filter.SrcPort, "dst_port": &filter.DstPort, "limit": &filter.Limit} {
+		if value := c.Query(param); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || (param != "limit" && parsed > 65535) {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param).
+					WithDetail("expected a positive number"))
+				return
+			}
+			*n = parsed
+		}
+	}
+
+	// The ACL must exist and be visible to the caller's tenant
+	ctx := c.Request.Context()
+	acl, err := h.ovnService.GetACL(ctx, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	// ovn-controller logs ACLs by name only
+	if acl.Name == "" {
+		problem.Respond(c, problem.New(http.StatusConflict, "ACL has no name").
+			WithDetail("ovn-controller logs ACLs by name; name the ACL to tell its logs apart"))
+		return
+	}
+	filter.ACLName = acl.Name
+
+	entries, err := h.logs.Entries(ctx, filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"acl_id":  acl.UUID,
+		"logging": acl.Log,
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+func (h *ACLLogHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
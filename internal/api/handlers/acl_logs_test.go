@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// fakeACLLogs records the filter it's asked for
+type fakeACLLogs struct {
+	filter *models.ACLLogFilter
+}
+
+func (f *fakeACLLogs) Entries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	f.filter = filter
+	return []*models.ACLLogEntry{{ACLName: filter.ACLName, Verdict: "drop"}}, nil
+}
+
+func serveACLLogs(handler *ACLLogHandler, id, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/acls/"+id+"/logs"+query, nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	handler.List(c)
+	return w
+}
+
+func TestACLLogHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetACL", mock.Anything, "acl-1").Return(&models.ACL{UUID: "acl-1", Name: "allow-ssh", Log: true}, nil)
+	mockService.On("GetACL", mock.Anything, "unnamed").Return(&models.ACL{UUID: "unnamed", Log: true}, nil)
+	mockService.On("GetACL", mock.Anything, "missing").Return((*models.ACL)(nil), errors.New("ACL not found"))
+	logs := &fakeACLLogs{}
+	handler := NewACLLogHandler(mockService, logs)
+
+	w := serveACLLogs(handler, "acl-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Entries []models.ACLLogEntry `json:"entries"`
+		Count   int                  `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Count)
+	assert.Equal(t, "allow-ssh", logs.filter.ACLName, "logs are looked up by the ACL's name")
+	assert.Equal(t, 24*time.Hour, logs.filter.To.Sub(logs.filter.From), "the range defaults to the last day")
+
+	w = serveACLLogs(handler, "acl-1", "?from=2024-01-01T00:00:00Z&verdict=drop&protocol=tcp&src_ip=10.0.0.1&dst_port=22&limit=10")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), logs.filter.From)
+	assert.Equal(t, "drop", logs.filter.Verdict)
+	assert.Equal(t, "10.0.0.1", logs.filter.SrcIP)
+	assert.Equal(t, 22, logs.filter.DstPort)
+	assert.Equal(t, 10, logs.filter.Limit)
+
+	assert.Equal(t, http.StatusBadRequest, serveACLLogs(handler, "acl-1", "?verdict=deny").Code)
+	assert.Equal(t, http.StatusBadRequest, serveACLLogs(handler, "acl-1", "?src_ip=host").Code)
+	assert.Equal(t, http.StatusBadRequest, serveACLLogs(handler, "acl-1", "?dst_port=70000").Code)
+	assert.Equal(t, http.StatusNotFound, serveACLLogs(handler, "missing", "").Code)
+	assert.Equal(t, http.StatusConflict, serveACLLogs(handler, "unnamed", "").Code)
+
+	// Logs that aren't ingested are unavailable
+	assert.Equal(t, http.StatusServiceUnavailable, serveACLLogs(NewACLLogHandler(mockService, nil), "acl-1", "").Code)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ACLRollouts stages ACL changes. *services.ACLRolloutService implements
+// it.
+type ACLRollouts interface {
+	Start(ctx context.Context, spec *services.ACLRolloutSpec, user string) (*models.ACLRollout, error)
+	Get(ctx context.Context, id string) (*models.ACLRollout, error)
+	List(ctx context.Context) ([]*models.ACLRollout, error)
+	Promote(ctx context.Context, id, user string) (*models.ACLRollout, error)
+	Rollback(ctx context.Context, id, user, reason string) (*models.ACLRollout, error)
+}
+
+// ACLRolloutHandler serves the staged rollouts of ACLs at
+// /api/v1/acl-rollouts
+type ACLRolloutHandler struct {
+	rollouts ACLRollouts
+}
+
+func NewACLRolloutHandler(rollouts ACLRollouts) *ACLRolloutHandler {
+	return &ACLRolloutHandler{rollouts: rollouts}
+}
+
+// ACLRolloutRequest rolls ACLs out to a switch
+type ACLRolloutRequest struct {
+	SwitchID string `json:"switch_id" binding:"required"`
+	// ACLs are the rules as they're enforced once promoted
+	ACLs []*models.ACL `json:"acls" binding:"required"`
+	// BakePeriod is how long the rules are observed, e.g. "2h"; the
+	// server's default when empty
+	BakePeriod string                      `json:"bake_period"`
+	Thresholds models.ACLRolloutThresholds `json:"thresholds"`
+}
+
+// ACLRollbackRequest rolls a rollout back
+type ACLRollbackRequest struct {
+	Reason string `json:"reason"`
+}
+
+// List handles GET /acl-rollouts, listing the rollouts latest first
+func (h *ACLRolloutHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	rollouts, err := h.rollouts.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if status := c.Query("status"); status != "" {
+		filtered := []*models.ACLRollout{}
+		for _, rollout := range rollouts {
+			if rollout.Status == status {
+				filtered = append(filtered, rollout)
+			}
+		}
+		rollouts = filtered
+	}
+
+	pageItems := pagination.Slice(rollouts, page)
+	c.JSON(http.StatusOK, gin.H{
+		"rollouts":   pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(rollouts)),
+	})
+}
+
+// Get handles GET /acl-rollouts/:id, returning a rollout with the traffic
+// its rules matched so far
+func (h *ACLRolloutHandler) Get(c *gin.Context) {
+	rollout, err := h.rollouts.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+// Start handles POST /acl-rollouts, creating the ACLs in observe mode
+func (h *ACLRolloutHandler) Start(c *gin.Context) {
+	var req ACLRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	for i, acl := range req.ACLs {
+		if details := validateACLRequest(acl); details != "" {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithDetail(fmt.Sprintf("acls[%d]: %s", i, details)))
+			return
+		}
+	}
+	var bakePeriod time.Duration
+	if req.BakePeriod != "" {
+		var err error
+		if bakePeriod, err = time.ParseDuration(req.BakePeriod); err != nil || bakePeriod <= 0 {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithDetail("bake_period must be a positive duration, e.g. 2h"))
+			return
+		}
+	}
+
+	rollout, err := h.rollouts.Start(c.Request.Context(), &services.ACLRolloutSpec{
+		SwitchID:   req.SwitchID,
+		ACLs:       req.ACLs,
+		BakePeriod: bakePeriod,
+		Thresholds: req.Thresholds,
+	}, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rollout)
+}
+
+// Promote handles POST /acl-rollouts/:id/promote, enforcing the rules
+// before the bake period ends
+func (h *ACLRolloutHandler) Promote(c *gin.Context) {
+	rollout, err := h.rollouts.Promote(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+// Rollback handles POST /acl-rollouts/:id/rollback, deleting the rules
+func (h *ACLRolloutHandler) Rollback(c *gin.Context) {
+	var req ACLRollbackRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithError(err))
+			return
+		}
+	}
+
+	rollout, err := h.rollouts.Rollback(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req.Reason)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+func (h *ACLRolloutHandler) handleError(c *gin.Context, err error) {
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrACLRolloutNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "ACL rollout not found"))
+	case errors.Is(err, services.ErrInvalidACLRollout):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid ACL rollout").
+			WithError(err))
+	case errors.Is(err, services.ErrACLRolloutStatus):
+		problem.Respond(c, problem.New(http.StatusConflict, "ACL rollout already ended").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
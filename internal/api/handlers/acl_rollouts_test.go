@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeACLRollouts keeps rollouts in a map, recording the spec they were
+// started with
+type fakeACLRollouts struct {
+	rollouts map[string]*models.ACLRollout
+	spec     *services.ACLRolloutSpec
+}
+
+func (f *fakeACLRollouts) Start(ctx context.Context, spec *services.ACLRolloutSpec, user string) (*models.ACLRollout, error) {
+	f.spec = spec
+	rollout := &models.ACLRollout{ID: "r1", SwitchID: spec.SwitchID, Rules: spec.ACLs, Status: models.ACLRolloutObserving, CreatedBy: user}
+	f.rollouts[rollout.ID] = rollout
+	return rollout, nil
+}
+
+func (f *fakeACLRollouts) Get(ctx context.Context, id string) (*models.ACLRollout, error) {
+	rollout, ok := f.rollouts[id]
+	if !ok {
+		return nil, services.ErrACLRolloutNotFound
+	}
+	return rollout, nil
+}
+
+func (f *fakeACLRollouts) List(ctx context.Context) ([]*models.ACLRollout, error) {
+	rollouts := []*models.ACLRollout{}
+	for _, rollout := range f.rollouts {
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, nil
+}
+
+func (f *fakeACLRollouts) Promote(ctx context.Context, id, user string) (*models.ACLRollout, error) {
+	return f.end(id, models.ACLRolloutPromoted, user, "")
+}
+
+func (f *fakeACLRollouts) Rollback(ctx context.Context, id, user, reason string) (*models.ACLRollout, error) {
+	return f.end(id, models.ACLRolloutRolledBack, user, reason)
+}
+
+func (f *fakeACLRollouts) end(id, status, user, reason string) (*models.ACLRollout, error) {
+	rollout, err := f.Get(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status != models.ACLRolloutObserving {
+		return nil, services.ErrACLRolloutStatus
+	}
+	rollout.Status, rollout.EndedBy, rollout.Reason = status, user, reason
+	return rollout, nil
+}
+
+func TestACLRolloutHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rollouts := &fakeACLRollouts{rollouts: map[string]*models.ACLRollout{}}
+	handler := NewACLRolloutHandler(rollouts)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/acl-rollouts", handler.List)
+	router.GET("/acl-rollouts/:id", handler.Get)
+	router.POST("/acl-rollouts", handler.Start)
+	router.POST("/acl-rollouts/:id/promote", handler.Promote)
+	router.POST("/acl-rollouts/:id/rollback", handler.Rollback)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	// Each rule is validated as a single ACL would be
+	w := serve(http.MethodPost, "/acl-rollouts", `{"switch_id": "sw-web", "acls": [{"priority": 2000, "direction": "to-lport", "match": "tcp.dst == 23", "action": "reject-all"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "acls[0]")
+	w = serve(http.MethodPost, "/acl-rollouts", `{"switch_id": "sw-web", "acls": [{"priority": 2000, "direction": "to-lport", "match": "tcp.dst == 23", "action": "drop"}], "bake_period": "soon"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodPost, "/acl-rollouts", `{"switch_id": "sw-web", "acls": [{"priority": 2000, "direction": "to-lport", "match": "tcp.dst == 23", "action": "drop"}], "bake_period": "2h", "thresholds": {"max_hits": 0}}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2*time.Hour, rollouts.spec.BakePeriod)
+	require.NotNil(t, rollouts.spec.Thresholds.MaxHits)
+	assert.Equal(t, uint64(0), *rollouts.spec.Thresholds.MaxHits, "a zero threshold allows no traffic at all")
+	assert.Nil(t, rollouts.spec.Thresholds.MaxLoggedFlows)
+
+	w = serve(http.MethodGet, "/acl-rollouts?status=promoted", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+
+	w = serve(http.MethodPost, "/acl-rollouts/r1/rollback", `{"reason": "blocks the backup job"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var rollout models.ACLRollout
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rollout))
+	assert.Equal(t, models.ACLRolloutRolledBack, rollout.Status)
+	assert.Equal(t, "alice", rollout.EndedBy)
+	assert.Equal(t, "blocks the backup job", rollout.Reason)
+
+	w = serve(http.MethodPost, "/acl-rollouts/r1/promote", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(http.MethodGet, "/acl-rollouts/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ACLSchedules manages the activation windows of ACLs.
+// *services.ACLScheduleService implements it.
+type ACLSchedules interface {
+	Set(ctx context.Context, aclID string, req *services.ACLScheduleRequest, user string) (*models.ACLSchedule, error)
+	Get(ctx context.Context, aclID string) (*models.ACLSchedule, error)
+	List(ctx context.Context) ([]*models.ACLSchedule, error)
+	Delete(ctx context.Context, aclID, user string) error
+	Toggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error)
+}
+
+// ACLScheduleHandler serves the schedules of ACLs at
+// /api/v1/acls/:id/schedule and /api/v1/acl-schedules
+type ACLScheduleHandler struct {
+	schedules ACLSchedules
+}
+
+func NewACLScheduleHandler(schedules ACLSchedules) *ACLScheduleHandler {
+	return &ACLScheduleHandler{schedules: schedules}
+}
+
+// List handles GET /acl-schedules, listing the tenant's scheduled ACLs.
+// ?active= filters them by whether they're enabled.
+func (h *ACLScheduleHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	schedules, err := h.schedules.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if active := c.Query("active"); active != "" {
+		filtered := []*models.ACLSchedule{}
+		for _, schedule := range schedules {
+			if (active == "true") == schedule.Active {
+				filtered = append(filtered, schedule)
+			}
+		}
+		schedules = filtered
+	}
+
+	pageItems := pagination.Slice(schedules, page)
+	c.JSON(http.StatusOK, gin.H{
+		"schedules":  pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(schedules)),
+	})
+}
+
+// Get handles GET /acls/:id/schedule
+func (h *ACLScheduleHandler) Get(c *gin.Context) {
+	schedule, err := h.schedules.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// Set handles PUT /acls/:id/schedule, replacing the windows of the ACL's
+// schedule
+func (h *ACLScheduleHandler) Set(c *gin.Context) {
+	var req services.ACLScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	schedule, err := h.schedules.Set(c.Request.Context(), c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// Delete handles DELETE /acls/:id/schedule, leaving the ACL enabled
+func (h *ACLScheduleHandler) Delete(c *gin.Context) {
+	if err := h.schedules.Delete(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Toggles handles GET /acls/:id/schedule/toggles, listing when the ACL
+// was enabled and disabled, oldest first
+func (h *ACLScheduleHandler) Toggles(c *gin.Context) {
+	toggles, err := h.schedules.Toggles(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"toggles": toggles,
+		"count":   len(toggles),
+	})
+}
+
+func (h *ACLScheduleHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrACLScheduleNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "ACL schedule not found"))
+	case errors.Is(err, services.ErrInvalidACLSchedule):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid ACL schedule").
+			WithError(err))
+	case errors.Is(err, services.ErrResourceNotInTenant):
+		problem.Respond(c, problem.New(http.StatusNotFound, "ACL not found").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeACLSchedules schedules ACL acl-1 only, disabling it
+type fakeACLSchedules struct {
+	schedules map[string]*models.ACLSchedule
+	toggles   []*models.ACLScheduleToggle
+}
+
+func (f *fakeACLSchedules) Set(ctx context.Context, aclID string, req *services.ACLScheduleRequest, user string) (*models.ACLSchedule, error) {
+	if aclID != "acl-1" {
+		return nil, services.ErrResourceNotInTenant
+	}
+	if len(req.Windows) == 0 {
+		return nil, services.ErrInvalidACLSchedule
+	}
+	schedule := &models.ACLSchedule{ACLID: aclID, Windows: req.Windows, Timezone: "UTC", CreatedBy: user}
+	f.schedules[aclID] = schedule
+	f.toggles = append(f.toggles, &models.ACLScheduleToggle{ACLID: aclID, Action: models.ACLScheduleDisabled, UserID: user})
+	return schedule, nil
+}
+
+func (f *fakeACLSchedules) Get(ctx context.Context, aclID string) (*models.ACLSchedule, error) {
+	schedule, ok := f.schedules[aclID]
+	if !ok {
+		return nil, services.ErrACLScheduleNotFound
+	}
+	return schedule, nil
+}
+
+func (f *fakeACLSchedules) List(ctx context.Context) ([]*models.ACLSchedule, error) {
+	schedules := []*models.ACLSchedule{}
+	for _, schedule := range f.schedules {
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (f *fakeACLSchedules) Delete(ctx context.Context, aclID, user string) error {
+	if _, err := f.Get(ctx, aclID); err != nil {
+		return err
+	}
+	delete(f.schedules, aclID)
+	return nil
+}
+
+func (f *fakeACLSchedules) Toggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error) {
+	return f.toggles, nil
+}
+
+func TestACLScheduleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewACLScheduleHandler(&fakeACLSchedules{schedules: map[string]*models.ACLSchedule{}})
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/acl-schedules", handler.List)
+	router.GET("/acls/:id/schedule", handler.Get)
+	router.PUT("/acls/:id/schedule", handler.Set)
+	router.DELETE("/acls/:id/schedule", handler.Delete)
+	router.GET("/acls/:id/schedule/toggles", handler.Toggles)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+	backups := `{"windows": [{"start": "02:00", "end": "04:00"}]}`
+
+	w := serve(http.MethodPut, "/acls/acl-1/schedule", `{"timezone": "UTC"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "windows are required")
+	w = serve(http.MethodPut, "/acls/acl-1/schedule", `{"windows": []}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodPut, "/acls/acl-other/schedule", backups)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(http.MethodPut, "/acls/acl-1/schedule", backups)
+	require.Equal(t, http.StatusOK, w.Code)
+	var schedule models.ACLSchedule
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schedule))
+	assert.Equal(t, "alice", schedule.CreatedBy)
+	assert.Equal(t, "02:00", schedule.Windows[0].Start)
+
+	w = serve(http.MethodGet, "/acl-schedules?active=false", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	w = serve(http.MethodGet, "/acl-schedules?active=true", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+
+	w = serve(http.MethodGet, "/acls/acl-1/schedule/toggles", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"disabled"`)
+
+	w = serve(http.MethodDelete, "/acls/acl-1/schedule", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodGet, "/acls/acl-1/schedule", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ACLSimulator simulates ACL changes on a switch. services.ACLSimulator
+// implements it.
+type ACLSimulator interface {
+	Simulate(ctx context.Context, switchID string, req *services.ACLSimulationRequest) (*services.ACLSimulation, error)
+}
+
+// ACLSimulationHandler serves what-if simulations of ACL changes
+type ACLSimulationHandler struct {
+	simulator ACLSimulator
+}
+
+// NewACLSimulationHandler creates a handler
+func NewACLSimulationHandler(simulator ACLSimulator) *ACLSimulationHandler {
+	return &ACLSimulationHandler{simulator: simulator}
+}
+
+// Simulate handles POST /api/v1/switches/:id/acls/simulate, reporting the
+// flows a proposed set of ACLs would newly block or allow. Nothing is
+// changed.
+func (h *ACLSimulationHandler) Simulate(c *gin.Context) {
+	var req services.ACLSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	simulation, err := h.simulator.Simulate(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
+func (h *ACLSimulationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSimulation):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeACLSimulator records the switch it's asked about
+type fakeACLSimulator struct {
+	switchID string
+	err      error
+}
+
+func (f *fakeACLSimulator) Simulate(ctx context.Context, switchID string, req *services.ACLSimulationRequest) (*services.ACLSimulation, error) {
+	f.switchID = switchID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &services.ACLSimulation{SwitchID: switchID, Summary: map[string]int{services.ChangeNewlyBlocked: len(req.Flows)}}, nil
+}
+
+func serveACLSimulation(handler *ACLSimulationHandler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/switches/sw-1/acls/simulate", bytes.NewReader([]byte(body)))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: "sw-1"}}
+	handler.Simulate(c)
+	return w
+}
+
+func TestACLSimulationHandler_Simulate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	simulator := &fakeACLSimulator{}
+	handler := NewACLSimulationHandler(simulator)
+
+	w := serveACLSimulation(handler, `{"acls":[],"flows":[{"src_ip":"10.0.0.1","dst_ip":"10.0.0.2"}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "sw-1", simulator.switchID)
+	assert.Contains(t, w.Body.String(), `"newly_blocked":1`)
+
+	assert.Equal(t, http.StatusBadRequest, serveACLSimulation(handler, `{"acls":`).Code)
+
+	simulator.err = fmt.Errorf("%w: flows are required unless observed flows are simulated", services.ErrInvalidSimulation)
+	assert.Equal(t, http.StatusBadRequest, serveACLSimulation(handler, `{"acls":[]}`).Code)
+
+	simulator.err = errors.New("switch not found")
+	assert.Equal(t, http.StatusNotFound, serveACLSimulation(handler, `{"acls":[]}`).Code)
+}
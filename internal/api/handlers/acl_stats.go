@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ACLStatsReader returns the traffic an ACL matched over a time range.
+// ACLStatsRecorder implements it.
+type ACLStatsReader interface {
+	Stats(ctx context.Context, aclID string, from, to time.Time) (*services.ACLStats, error)
+}
+
+// ACLStatsHandler serves the hit counters of ACLs, for finding unused or
+// hot rules
+type ACLStatsHandler struct {
+	ovnService services.OVNServiceInterface
+	stats      ACLStatsReader
+}
+
+// NewACLStatsHandler creates a handler. stats is nil when ACL statistics
+// aren't collected.
+func NewACLStatsHandler(ovnService services.OVNServiceInterface, stats ACLStatsReader) *ACLStatsHandler {
+	return &ACLStatsHandler{
+		ovnService: ovnService,
+		stats:      stats,
+	}
+}
+
+// Get handles GET /api/v1/acls/:id/stats?from=&to=, returning the traffic
+// the ACL matched between the from and to times, by default the last day
+func (h *ACLStatsHandler) Get(c *gin.Context) {
+	if h.stats == nil {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "ACL statistics unavailable").
+			WithDetail("ACL_STATS_FLOW_DUMP_COMMAND is not configured"))
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param+" time").
+					WithDetail("expected RFC 3339, e.g. 2024-01-02T15:04:05Z"))
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	// The ACL must exist and be visible to the caller's tenant
+	ctx := c.Request.Context()
+	acl, err := h.ovnService.GetACL(ctx, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	stats, err := h.stats.Stats(ctx, acl.UUID, from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *ACLStatsHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
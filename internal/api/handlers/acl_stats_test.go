@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeACLStats records the range it's asked for
+type fakeACLStats struct {
+	aclID    string
+	from, to time.Time
+}
+
+func (f *fakeACLStats) Stats(ctx context.Context, aclID string, from, to time.Time) (*services.ACLStats, error) {
+	f.aclID, f.from, f.to = aclID, from, to
+	return &services.ACLStats{ACLID: aclID, From: from, To: to, PacketsInRange: 42}, nil
+}
+
+func serveACLStats(handler *ACLStatsHandler, id, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/acls/"+id+"/stats"+query, nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	handler.Get(c)
+	return w
+}
+
+func TestACLStatsHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetACL", mock.Anything, "acl-1").Return(&models.ACL{UUID: "acl-1"}, nil)
+	mockService.On("GetACL", mock.Anything, "missing").Return((*models.ACL)(nil), errors.New("ACL not found"))
+	stats := &fakeACLStats{}
+	handler := NewACLStatsHandler(mockService, stats)
+
+	w := serveACLStats(handler, "acl-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body services.ACLStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, uint64(42), body.PacketsInRange)
+	assert.Equal(t, 24*time.Hour, stats.to.Sub(stats.from), "the range defaults to the last day")
+
+	w = serveACLStats(handler, "acl-1", "?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), stats.from)
+
+	assert.Equal(t, http.StatusBadRequest, serveACLStats(handler, "acl-1", "?from=yesterday").Code)
+	assert.Equal(t, http.StatusNotFound, serveACLStats(handler, "missing", "").Code)
+
+	// Statistics that aren't collected are unavailable
+	assert.Equal(t, http.StatusServiceUnavailable, serveACLStats(NewACLStatsHandler(mockService, nil), "acl-1", "").Code)
+}
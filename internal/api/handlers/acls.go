@@ -1,17 +1,32 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
 )
 
+// maxBulkACLs is the most ACLs a bulk request may create
+const maxBulkACLs = 1000
+
+// MatchExpander expands the service references of ACL matches, as
+// *services.ServiceCatalog does
+type MatchExpander interface {
+	ExpandMatch(ctx context.Context, match string) (string, error)
+}
+
 type ACLHandler struct {
 	ovnService services.OVNServiceInterface
+	trash      ResourceTrash // nil deletes resources for good
+	catalog    MatchExpander // nil leaves matches as they are written
 }
 
 func NewACLHandler(ovnService services.OVNServiceInterface) *ACLHandler {
@@ -20,221 +35,238 @@ func NewACLHandler(ovnService services.OVNServiceInterface) *ACLHandler {
 	}
 }
 
+// SetRecycleBin soft-deletes resources into trash
+func (h *ACLHandler) SetRecycleBin(trash ResourceTrash) {
+	h.trash = trash
+}
+
+// SetServiceCatalog expands service(name) references in the matches of
+// ACLs created or updated with catalog
+func (h *ACLHandler) SetServiceCatalog(catalog MatchExpander) {
+	h.catalog = catalog
+}
+
 func (h *ACLHandler) List(c *gin.Context) {
 	switchID := c.Query("switch_id")
 	if switchID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch_id query parameter is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch_id query parameter is required"))
 		return
 	}
 
-	// Pagination parameters
-	page := 1
-	limit := 20 // Default limit
-	
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	opts, page, err := parseListOptions(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
 	}
 
-	acls, err := h.ovnService.ListACLs(c.Request.Context(), switchID)
+	stream := streamOptions(c, opts)
+	acls, total, err := h.ovnService.ListACLsPage(c.Request.Context(), switchID, opts)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "switch not found"})
+			problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
+			return
+		}
+		if strings.Contains(err.Error(), "unsupported sort field") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithError(err))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	// Apply pagination
-	totalCount := len(acls)
-	start := (page - 1) * limit
-	end := start + limit
-	
-	if start >= totalCount {
-		c.JSON(http.StatusOK, gin.H{
-			"acls":       []*models.ACL{},
-			"pagination": gin.H{
-				"page":        page,
-				"limit":       limit,
-				"total_count": totalCount,
-				"total_pages": (totalCount + limit - 1) / limit,
-			},
+	if stream {
+		streamNDJSON(c, acls, total, opts, func(opts *models.ListOptions) ([]*models.ACL, error) {
+			acls, _, err := h.ovnService.ListACLsPage(c.Request.Context(), switchID, opts)
+			return acls, err
 		})
 		return
 	}
-	
-	if end > totalCount {
-		end = totalCount
+
+	items, ok := selectFields(c, acls)
+	if !ok {
+		return
 	}
-	
-	paginatedACLs := acls[start:end]
 
 	c.JSON(http.StatusOK, gin.H{
-		"acls": paginatedACLs,
-		"pagination": gin.H{
-			"page":        page,
-			"limit":       limit,
-			"total_count": totalCount,
-			"total_pages": (totalCount + limit - 1) / limit,
-		},
+		"acls":       items,
+		"count":      len(acls),
+		"pagination": pagination.Response(c, page, total),
 	})
 }
 
 func (h *ACLHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ACL ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "ACL ID is required"))
 		return
 	}
 	
 	acl, err := h.ovnService.GetACL(c.Request.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, acl)
+	respondWithETag(c, http.StatusOK, acl)
 }
 
 func (h *ACLHandler) Create(c *gin.Context) {
 	switchID := c.Query("switch_id")
 	if switchID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch_id query parameter is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch_id query parameter is required"))
 		return
 	}
 
 	var acl models.ACL
 	if err := c.ShouldBindJSON(&acl); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
-	// Validate required fields
-	if acl.Match == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "match expression is required",
-		})
+	if details := validateACLRequest(&acl); details != "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail(details))
 		return
 	}
 
-	if acl.Action == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "action is required",
-		})
+	// TODO: Add match expression syntax validation
+
+	if err := h.expandMatch(c.Request.Context(), &acl, false); err != nil {
+		h.respondExpandError(c, err)
 		return
 	}
 
-	if acl.Direction == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "direction is required",
-		})
+	created, err := h.ovnService.CreateACL(c.Request.Context(), switchID, &acl)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
+			return
+		}
+		h.handleError(c, err)
 		return
 	}
 
-	// Validate action
-	validActions := []string{"allow", "allow-related", "allow-stateless", "drop", "reject", "pass"}
-	isValidAction := false
-	for _, valid := range validActions {
-		if acl.Action == valid {
-			isValidAction = true
-			break
-		}
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+// BulkCreate creates many ACLs on a switch in one OVSDB transaction. Invalid
+// ACLs are reported in the per-item results and the valid ones are created,
+// unless the request asks for all or nothing.
+func (h *ACLHandler) BulkCreate(c *gin.Context) {
+	// gin can't escape the colon in acls:bulk, so the route ends in a
+	// wildcard that must hold exactly ":bulk"
+	if c.Param("bulk") != ":bulk" {
+		problem.Respond(c, problem.New(http.StatusNotFound, "not found"))
+		return
 	}
-	if !isValidAction {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "action must be one of: allow, allow-related, allow-stateless, drop, reject, pass",
-		})
+
+	switchID := c.Param("id")
+
+	var req models.BulkACLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
-	// Validate direction
-	if acl.Direction != "from-lport" && acl.Direction != "to-lport" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "direction must be 'from-lport' or 'to-lport'",
-		})
+	if len(req.ACLs) == 0 {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "at least one ACL is required"))
 		return
 	}
 
-	// Validate priority
-	if acl.Priority < 0 || acl.Priority > 65535 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "priority must be between 0 and 65535",
-		})
+	if len(req.ACLs) > maxBulkACLs {
+		problem.Respond(c, problem.New(http.StatusBadRequest, fmt.Sprintf("maximum %d ACLs per request", maxBulkACLs)))
 		return
 	}
 
-	// Validate severity if provided
-	if acl.Severity != "" {
-		validSeverities := []string{"alert", "warning", "notice", "info", "debug"}
-		isValidSeverity := false
-		for _, valid := range validSeverities {
-			if acl.Severity == valid {
-				isValidSeverity = true
-				break
+	response := &models.BulkACLResponse{
+		SwitchID: switchID,
+		Results:  make([]models.BulkACLResult, len(req.ACLs)),
+	}
+
+	// Validate every ACL, keeping the request index of the valid ones
+	var valid []*models.ACL
+	var indexes []int
+	for i, acl := range req.ACLs {
+		response.Results[i].Index = i
+
+		details := "ACL is required"
+		if acl != nil {
+			details = validateACLRequest(acl)
+		}
+		if details == "" {
+			if err := h.expandMatch(c.Request.Context(), acl, false); err != nil {
+				details = err.Error()
 			}
 		}
-		if !isValidSeverity {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "severity must be one of: alert, warning, notice, info, debug",
-			})
-			return
+		if details != "" {
+			response.Results[i].Error = details
+			response.Failed++
+			continue
 		}
+
+		valid = append(valid, acl)
+		indexes = append(indexes, i)
 	}
 
-	// TODO: Add match expression syntax validation
+	if len(valid) == 0 || (req.AllOrNothing && response.Failed > 0) {
+		for _, i := range indexes {
+			response.Results[i].Error = "not created due to invalid ACLs in the request"
+			response.Failed++
+		}
+		response.Error = "validation failed"
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 
-	created, err := h.ovnService.CreateACL(c.Request.Context(), switchID, &acl)
+	created, err := h.ovnService.CreateACLs(c.Request.Context(), switchID, valid)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "switch not found"})
+			problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, created)
+	for j, acl := range created {
+		i := indexes[j]
+		response.Results[i].Success = true
+		response.Results[i].ACL = acl
+		response.Created++
+	}
+
+	if response.Failed > 0 {
+		c.JSON(http.StatusMultiStatus, response)
+		return
+	}
+
+	response.Success = true
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *ACLHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ACL ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "ACL ID is required"))
 		return
 	}
 	
 	var acl models.ACL
 	if err := c.ShouldBindJSON(&acl); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
@@ -249,29 +281,23 @@ func (h *ACLHandler) Update(c *gin.Context) {
 			}
 		}
 		if !isValidAction {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "action must be one of: allow, allow-related, allow-stateless, drop, reject, pass",
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("action", "oneof", "action must be one of: allow, allow-related, allow-stateless, drop, reject, pass"))
 			return
 		}
 	}
 
 	// Validate direction if provided
 	if acl.Direction != "" && acl.Direction != "from-lport" && acl.Direction != "to-lport" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "direction must be 'from-lport' or 'to-lport'",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("direction", "oneof", "direction must be 'from-lport' or 'to-lport'"))
 		return
 	}
 
 	// Validate priority if provided
 	if acl.Priority != 0 && (acl.Priority < 0 || acl.Priority > 65535) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "priority must be between 0 and 65535",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("priority", "range", "priority must be between 0 and 65535"))
 		return
 	}
 
@@ -286,61 +312,239 @@ func (h *ACLHandler) Update(c *gin.Context) {
 			}
 		}
 		if !isValidSeverity {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "severity must be one of: alert, warning, notice, info, debug",
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("severity", "oneof", "severity must be one of: alert, warning, notice, info, debug"))
 			return
 		}
 	}
 
-	updated, err := h.ovnService.UpdateACL(c.Request.Context(), id, &acl)
+	if acl.Owner != "" && !validACLOwner(acl.Owner) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("owner", "oneof", "owner must be one of: template, operator, user"))
+		return
+	}
+
+	if err := h.expandMatch(c.Request.Context(), &acl, true); err != nil {
+		h.respondExpandError(c, err)
+		return
+	}
+
+	if !h.checkOwner(c, id) {
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetACL(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateACL(ctx, id, &acl)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	respondWithETag(c, http.StatusOK, updated)
 }
 
 func (h *ACLHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ACL ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "ACL ID is required"))
+		return
+	}
+
+	if !h.checkOwner(c, id) {
 		return
 	}
 	
-	err := h.ovnService.DeleteACL(c.Request.Context(), id)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetACL(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	trashed, err := deleteResource(ctx, h.trash, models.ResourceACL, id, h.ovnService.DeleteACL)
 	if err != nil {
+		if respondNotRecyclable(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	respondDeleted(c, trashed)
 }
 
 // handleError handles generic errors
+// validateACLRequest checks the fields of an ACL to be created, returning
+// what is wrong with it or "" if it is valid
+func validateACLRequest(acl *models.ACL) string {
+	// Validate required fields
+	if acl.Match == "" {
+		return "match expression is required"
+	}
+
+	if acl.Action == "" {
+		return "action is required"
+	}
+
+	if acl.Direction == "" {
+		return "direction is required"
+	}
+
+	// Validate action
+	validActions := []string{"allow", "allow-related", "allow-stateless", "drop", "reject", "pass"}
+	isValidAction := false
+	for _, valid := range validActions {
+		if acl.Action == valid {
+			isValidAction = true
+			break
+		}
+	}
+	if !isValidAction {
+		return "action must be one of: allow, allow-related, allow-stateless, drop, reject, pass"
+	}
+
+	// Validate direction
+	if acl.Direction != "from-lport" && acl.Direction != "to-lport" {
+		return "direction must be 'from-lport' or 'to-lport'"
+	}
+
+	// Validate priority
+	if acl.Priority < 0 || acl.Priority > 65535 {
+		return "priority must be between 0 and 65535"
+	}
+
+	// Validate severity if provided
+	if acl.Severity != "" {
+		validSeverities := []string{"alert", "warning", "notice", "info", "debug"}
+		isValidSeverity := false
+		for _, valid := range validSeverities {
+			if acl.Severity == valid {
+				isValidSeverity = true
+				break
+			}
+		}
+		if !isValidSeverity {
+			return "severity must be one of: alert, warning, notice, info, debug"
+		}
+	}
+
+	if acl.Owner != "" && !validACLOwner(acl.Owner) {
+		return "owner must be one of: template, operator, user"
+	}
+
+	return ""
+}
+
+func validACLOwner(owner string) bool {
+	for _, valid := range models.ACLOwners {
+		if owner == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOwner refuses to change or delete an ACL owned by a template or an
+// operator, which would drift from its owner, unless forced with
+// ?force=true. It answers the request when it refuses.
+func (h *ACLHandler) checkOwner(c *gin.Context, id string) bool {
+	if c.Query("force") == "true" {
+		return true
+	}
+
+	acl, err := h.ovnService.GetACL(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return false
+		}
+		h.handleError(c, err)
+		return false
+	}
+	owner := models.ACLOwnerFromExternalIDs(acl.ExternalIDs)
+	if owner == models.ACLOwnerUser {
+		return true
+	}
+
+	managedBy := owner
+	if template := acl.ExternalIDs["template"]; owner == models.ACLOwnerTemplate && template != "" {
+		managedBy = "template " + template
+	} else if policy := acl.ExternalIDs[services.NetworkPolicyOwnerKey]; owner == models.ACLOwnerOperator && policy != "" {
+		managedBy = "NetworkPolicy " + policy
+	}
+	problem.Respond(c, problem.New(http.StatusConflict, "ACL is owned by a "+owner).
+		WithCode(problem.CodeResourceOwned).
+		WithDetail(fmt.Sprintf("ACL %s is managed by %s; change it there, or use ?force=true", acl.UUID, managedBy)).
+		With("owner", owner))
+	return false
+}
+
+// expandMatch expands the service references of acl's match, keeping the
+// match as written in its external IDs when it had any. Updates keep it
+// whenever the match changes, so it's never left stale.
+func (h *ACLHandler) expandMatch(ctx context.Context, acl *models.ACL, update bool) error {
+	if h.catalog == nil || acl.Match == "" {
+		return nil
+	}
+	expanded, err := h.catalog.ExpandMatch(ctx, acl.Match)
+	if err != nil {
+		return err
+	}
+	if expanded == acl.Match && !update {
+		return nil
+	}
+	if acl.ExternalIDs == nil {
+		acl.ExternalIDs = make(map[string]string)
+	}
+	acl.ExternalIDs[models.ACLMatchSourceKey] = acl.Match
+	acl.Match = expanded
+	return nil
+}
+
+// respondExpandError responds to a failure to expand a match
+func (h *ACLHandler) respondExpandError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrUnknownService) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("match", "service", err.Error()))
+		return
+	}
+	h.handleError(c, err)
+}
+
 func (h *ACLHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
 	// Check if client is not connected
 	if strings.Contains(err.Error(), "not connected") {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "OVN service unavailable",
-			"details": "unable to connect to OVN northbound database",
-		})
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
 		return
 	}
 
 	// Default error response
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error": "internal server error",
-		"details": err.Error(),
-	})
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
 }
\ No newline at end of file
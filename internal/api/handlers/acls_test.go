@@ -2,18 +2,24 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
 )
 
 func TestACLHandler_List(t *testing.T) {
@@ -130,7 +136,7 @@ func TestACLHandler_List(t *testing.T) {
 			handler := NewACLHandler(mockService)
 
 			if tt.switchID != "" {
-				mockService.On("ListACLs", mock.Anything, tt.switchID).Return(tt.mockReturn, tt.mockError)
+				mockService.On("ListACLsPage", mock.Anything, tt.switchID, mock.Anything).Return(tt.mockReturn, len(tt.mockReturn), tt.mockError)
 			}
 
 			w := httptest.NewRecorder()
@@ -574,8 +580,11 @@ func TestACLHandler_Update(t *testing.T) {
 			body, _ := json.Marshal(tt.requestBody)
 
 			// Only set up mock if we expect the service to be called
-			if tt.expectedStatus == http.StatusOK || tt.expectedStatus == http.StatusNotFound {
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("GetACL", mock.Anything, tt.aclID).Return(&models.ACL{UUID: tt.aclID}, nil)
 				mockService.On("UpdateACL", mock.Anything, tt.aclID, mock.Anything).Return(tt.mockReturn, tt.mockError)
+			} else if tt.expectedStatus == http.StatusNotFound {
+				mockService.On("GetACL", mock.Anything, tt.aclID).Return(nil, tt.mockError)
 			}
 
 			w := httptest.NewRecorder()
@@ -635,7 +644,10 @@ func TestACLHandler_Delete(t *testing.T) {
 			mockService := new(MockOVNService)
 			handler := NewACLHandler(mockService)
 
-			if tt.aclID != "" {
+			if tt.expectedStatus == http.StatusNotFound {
+				mockService.On("GetACL", mock.Anything, tt.aclID).Return(nil, tt.mockError)
+			} else if tt.aclID != "" {
+				mockService.On("GetACL", mock.Anything, tt.aclID).Return(&models.ACL{UUID: tt.aclID}, nil)
 				mockService.On("DeleteACL", mock.Anything, tt.aclID).Return(tt.mockError)
 			}
 
@@ -653,4 +665,216 @@ func TestACLHandler_Delete(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestACLHandler_OwnedACLs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owned := map[string]*models.ACL{
+		"acl-template": {UUID: "acl-template", ExternalIDs: map[string]string{"template": "web-tier"}},
+		"acl-policy":   {UUID: "acl-policy", ExternalIDs: map[string]string{services.NetworkPolicyOwnerKey: "shop/allow-web"}},
+		"acl-operator": {UUID: "acl-operator", ExternalIDs: map[string]string{models.ACLOwnerKey: models.ACLOwnerOperator}},
+	}
+	for id, acl := range owned {
+		t.Run(id, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			mockService.On("GetACL", mock.Anything, id).Return(acl, nil)
+			handler := NewACLHandler(mockService)
+			router := gin.New()
+			router.PUT("/acls/:id", handler.Update)
+			router.DELETE("/acls/:id", handler.Delete)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/acls/"+id, strings.NewReader(`{"action": "drop"}`)))
+			require.Equal(t, http.StatusConflict, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, problem.CodeResourceOwned, body["code"])
+			assert.Equal(t, models.ACLOwnerFromExternalIDs(acl.ExternalIDs), body["owner"])
+
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/acls/"+id, nil))
+			assert.Equal(t, http.StatusConflict, w.Code)
+			mockService.AssertNotCalled(t, "UpdateACL", mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "DeleteACL", mock.Anything, mock.Anything)
+
+			// Forcing skips the check
+			mockService.On("UpdateACL", mock.Anything, id, mock.Anything).Return(acl, nil)
+			mockService.On("DeleteACL", mock.Anything, id).Return(nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/acls/"+id+"?force=true", strings.NewReader(`{"action": "drop"}`)))
+			assert.Equal(t, http.StatusOK, w.Code)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/acls/"+id+"?force=true", nil))
+			assert.Equal(t, http.StatusNoContent, w.Code)
+		})
+	}
+}
+
+func TestACLHandler_BulkCreate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	allow := map[string]interface{}{
+		"priority":  100,
+		"direction": "from-lport",
+		"match":     "ip4.src == 10.0.0.1",
+		"action":    "allow",
+	}
+	invalid := map[string]interface{}{
+		"priority":  100,
+		"direction": "sideways",
+		"match":     "ip4",
+		"action":    "drop",
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    interface{}
+		expectCreate   int
+		mockError      error
+		expectedStatus int
+		expectedFailed int
+	}{
+		{
+			name:           "all valid",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow, allow}},
+			expectCreate:   2,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "partial",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow, invalid}},
+			expectCreate:   1,
+			expectedStatus: http.StatusMultiStatus,
+			expectedFailed: 1,
+		},
+		{
+			name:           "all or nothing",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow, invalid}, "all_or_nothing": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedFailed: 2,
+		},
+		{
+			name:           "switch not found",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow}},
+			expectCreate:   1,
+			mockError:      errors.New("failed to get logical switch switch-uuid: object not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "transaction rejected",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow}},
+			expectCreate:   1,
+			mockError:      errors.New("transaction failed on ACL 0: constraint violation"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "no acls",
+			requestBody:    map[string]interface{}{"acls": []interface{}{}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "not the bulk route",
+			path:           "/switches/switch-uuid/aclsbulk",
+			requestBody:    map[string]interface{}{"acls": []interface{}{allow}},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewACLHandler(mockService)
+
+			if tt.expectCreate > 0 {
+				var created []*models.ACL
+				if tt.mockError == nil {
+					for i := 0; i < tt.expectCreate; i++ {
+						created = append(created, &models.ACL{UUID: "new-uuid", Priority: 100})
+					}
+				}
+				mockService.On("CreateACLs", mock.Anything, "switch-uuid", mock.MatchedBy(func(acls []*models.ACL) bool {
+					return len(acls) == tt.expectCreate
+				})).Return(created, tt.mockError)
+			}
+
+			router := gin.New()
+			router.POST("/switches/:id/acls:bulk", handler.BulkCreate)
+
+			path := tt.path
+			if path == "" {
+				path = "/switches/switch-uuid/acls:bulk"
+			}
+
+			body, _ := json.Marshal(tt.requestBody)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusCreated || tt.expectedStatus == http.StatusMultiStatus || tt.expectedFailed > 0 {
+				var response models.BulkACLResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedFailed, response.Failed)
+				assert.Equal(t, tt.expectCreate, response.Created)
+				assert.Equal(t, tt.expectedStatus == http.StatusCreated, response.Success)
+				if tt.expectedStatus == http.StatusMultiStatus {
+					assert.True(t, response.Results[0].Success)
+					assert.Contains(t, response.Results[1].Error, "direction")
+				}
+			}
+		})
+	}
+}
+
+// fakeMatchExpander expands service(https) only
+type fakeMatchExpander struct{}
+
+func (fakeMatchExpander) ExpandMatch(ctx context.Context, match string) (string, error) {
+	if strings.Contains(match, "service(gopher)") {
+		return "", fmt.Errorf("%w: %q", services.ErrUnknownService, "gopher")
+	}
+	return strings.ReplaceAll(match, "service(https)", "tcp.dst == 443"), nil
+}
+
+func TestACLHandler_ExpandsServices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewACLHandler(mockService)
+	handler.SetServiceCatalog(fakeMatchExpander{})
+	router := gin.New()
+	router.POST("/acls", handler.Create)
+	router.PUT("/acls/:id", handler.Update)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	mockService.On("CreateACL", mock.Anything, "switch-uuid", mock.MatchedBy(func(acl *models.ACL) bool {
+		return acl.Match == "ip4 && tcp.dst == 443" && acl.ExternalIDs[models.ACLMatchSourceKey] == "ip4 && service(https)"
+	})).Return(&models.ACL{UUID: "acl-uuid"}, nil).Once()
+	w := serve("POST", "/acls?switch_id=switch-uuid", `{"direction": "to-lport", "priority": 1000, "match": "ip4 && service(https)", "action": "allow"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = serve("POST", "/acls?switch_id=switch-uuid", `{"direction": "to-lport", "priority": 1000, "match": "ip4 && service(gopher)", "action": "allow"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "gopher")
+
+	// An updated match replaces the one recorded, expanded or not
+	mockService.On("GetACL", mock.Anything, "acl-uuid").Return(&models.ACL{UUID: "acl-uuid"}, nil)
+	mockService.On("UpdateACL", mock.Anything, "acl-uuid", mock.MatchedBy(func(acl *models.ACL) bool {
+		return acl.Match == "ip4 && tcp.dst == 22" && acl.ExternalIDs[models.ACLMatchSourceKey] == "ip4 && tcp.dst == 22"
+	})).Return(&models.ACL{UUID: "acl-uuid"}, nil).Once()
+	w = serve("PUT", "/acls/acl-uuid", `{"match": "ip4 && tcp.dst == 22"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// maxApplyBodySize limits the size of desired state documents
+const maxApplyBodySize = 10 << 20
+
+type ApplyHandler struct {
+	applyService *services.ApplyService
+}
+
+func NewApplyHandler(applyService *services.ApplyService) *ApplyHandler {
+	return &ApplyHandler{
+		applyService: applyService,
+	}
+}
+
+// Apply handles POST /api/v1/apply. The body is a YAML or JSON desired state
+// document; ?dry_run=true returns the plan without applying it and
+// ?prune=true deletes resources missing from the document.
+func (h *ApplyHandler) Apply(c *gin.Context) {
+	dryRun, err := parseBoolQuery(c, "dry_run")
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid dry_run parameter"))
+		return
+	}
+	// Dry runs change nothing, so publish no resource events
+	c.Set("dry_run", dryRun)
+	prune, err := parseBoolQuery(c, "prune")
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid prune parameter"))
+		return
+	}
+
+	body, ok := readBody(c, maxApplyBodySize)
+	if !ok {
+		return
+	}
+
+	// YAML is a superset of JSON, so one decoder accepts both
+	var state services.DesiredState
+	decoder := yaml.NewDecoder(bytes.NewReader(body))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&state); err != nil {
+		details := err.Error()
+		if errors.Is(err, io.EOF) {
+			details = "empty document"
+		}
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid desired state document").
+			WithDetail(details))
+		return
+	}
+
+	result, err := h.applyService.Apply(c.Request.Context(), &state, services.ApplyOptions{
+		DryRun: dryRun,
+		Prune:  prune,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid desired state") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithError(err))
+			return
+		}
+		if strings.Contains(err.Error(), "not connected") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithDetail("unable to connect to OVN northbound database"))
+			return
+		}
+
+		response := problem.New(http.StatusInternalServerError, "apply failed").WithError(err)
+		// Report the changes that were made before the failure
+		if result != nil {
+			response.With("result", result)
+		}
+		problem.Respond(c, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// readBody reads a request body of at most limit bytes. Larger bodies are
+// refused with 413 rather than truncated; it answers the request and
+// returns false when the body can't be read.
+func readBody(c *gin.Context, limit int64) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		problem.Respond(c, problem.New(http.StatusRequestEntityTooLarge, "request body too large").
+			WithDetail(fmt.Sprintf("the body must be at most %d bytes", limit)))
+		return nil, false
+	case err != nil:
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return nil, false
+	}
+	return body, true
+}
+
+func parseBoolQuery(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
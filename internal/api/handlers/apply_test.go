@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestApplyHandler_Apply(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		setupMock      func(*MockOVNService)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:  "yaml dry run",
+			query: "?dry_run=true",
+			body: `
+switches:
+  - name: web
+    ports:
+      - name: web-1
+        addresses: ["00:00:00:00:01:01 10.0.1.11"]
+`,
+			setupMock: func(m *MockOVNService) {
+				m.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+				m.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"dry_run": true,
+				"applied": float64(0),
+			},
+		},
+		{
+			name: "json document",
+			body: `{"routers": [{"name": "edge"}]}`,
+			setupMock: func(m *MockOVNService) {
+				m.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+				m.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+				m.On("CreateLogicalRouter", mock.Anything, mock.Anything).Return(&models.LogicalRouter{UUID: "lr-uuid", Name: "edge"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"dry_run": false,
+				"applied": float64(1),
+			},
+		},
+		{
+			name:           "unknown field",
+			body:           "switchs:\n  - name: web\n",
+			setupMock:      func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "invalid desired state document",
+			},
+		},
+		{
+			name:           "empty document",
+			body:           "",
+			setupMock:      func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"details": "empty document",
+			},
+		},
+		{
+			name:           "body too large",
+			body:           "# " + strings.Repeat("x", maxApplyBodySize),
+			setupMock:      func(m *MockOVNService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody: map[string]interface{}{
+				"error": "request body too large",
+			},
+		},
+		{
+			name:           "invalid dry_run",
+			query:          "?dry_run=maybe",
+			body:           "switches: []\n",
+			setupMock:      func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "invalid dry_run parameter",
+			},
+		},
+		{
+			name:           "validation failure",
+			body:           "switches:\n  - name: web\n  - name: web\n",
+			setupMock:      func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "validation failed",
+			},
+		},
+		{
+			name: "not connected",
+			body: "switches:\n  - name: web\n",
+			setupMock: func(m *MockOVNService) {
+				m.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch(nil), errors.New("client not connected"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: map[string]interface{}{
+				"error": "OVN service unavailable",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			tt.setupMock(mockService)
+			handler := NewApplyHandler(services.NewApplyService(mockService, zap.NewNop()))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/apply"+tt.query, strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/yaml")
+
+			handler.Apply(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
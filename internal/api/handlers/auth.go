@@ -7,6 +7,8 @@ import (
 	"github.com/google/uuid"
 	
 	"github.com/lspecian/ovncp/internal/api/middleware"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/auth"
 	"github.com/lspecian/ovncp/internal/models"
 )
@@ -51,9 +53,11 @@ type TokenResponse struct {
 	User         *models.User `json:"user"`
 }
 
-// RefreshRequest contains the refresh token
+// RefreshRequest contains the refresh token. When Provider is set the token is
+// refreshed at that identity provider instead of against a local session.
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	Provider     string `json:"provider,omitempty"`
 }
 
 // UpdateRoleRequest contains the new role for a user
@@ -65,7 +69,7 @@ type UpdateRoleRequest struct {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").WithError(err))
 		return
 	}
 	
@@ -77,7 +81,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	
 	authURL, err := h.authService.GetAuthURL(req.Provider, state)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 	
@@ -92,7 +96,7 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	
 	var req CallbackRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").WithError(err))
 		return
 	}
 	
@@ -101,7 +105,7 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 	// Exchange code for token
 	session, err := h.authService.ExchangeCode(c.Request.Context(), provider, req.Code)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusUnauthorized, err.Error()))
 		return
 	}
 	
@@ -117,13 +121,19 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").WithError(err))
 		return
 	}
 	
-	session, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	var session *models.Session
+	var err error
+	if req.Provider != "" {
+		session, err = h.authService.RefreshProviderToken(c.Request.Context(), req.Provider, req.RefreshToken)
+	} else {
+		session, err = h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusUnauthorized, err.Error()))
 		return
 	}
 	
@@ -139,7 +149,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 func (h *AuthHandler) Logout(c *gin.Context) {
 	user, ok := middleware.GetAuthUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		problem.Respond(c, problem.New(http.StatusUnauthorized, "Not authenticated"))
 		return
 	}
 	
@@ -148,7 +158,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	if len(authHeader) > 7 {
 		token := authHeader[7:] // Remove "Bearer " prefix
 		if err := h.authService.Logout(c.Request.Context(), token); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 			return
 		}
 	}
@@ -160,7 +170,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	user, ok := middleware.GetAuthUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		problem.Respond(c, problem.New(http.StatusUnauthorized, "Not authenticated"))
 		return
 	}
 	
@@ -169,20 +179,22 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 // ListUsers returns a paginated list of users (admin only)
 func (h *AuthHandler) ListUsers(c *gin.Context) {
-	// Parse pagination
-	limit, offset := parsePagination(c)
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").WithError(err))
+		return
+	}
 	
-	users, total, err := h.authService.ListUsers(c.Request.Context(), limit, offset)
+	users, total, err := h.authService.ListUsers(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"total": total,
-		"limit": limit,
-		"offset": offset,
+		"users":      users,
+		"count":      len(users),
+		"pagination": pagination.Response(c, page, total),
 	})
 }
 
@@ -192,7 +204,7 @@ func (h *AuthHandler) GetUser(c *gin.Context) {
 	
 	user, err := h.authService.GetUser(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 		return
 	}
 	
@@ -205,20 +217,20 @@ func (h *AuthHandler) UpdateUserRole(c *gin.Context) {
 	
 	var req UpdateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").WithError(err))
 		return
 	}
 	
 	// Prevent self role change
 	authUser, _ := middleware.GetAuthUser(c)
 	if authUser.ID == userID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change your own role"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Cannot change your own role"))
 		return
 	}
 	
 	role := models.UserRole(req.Role)
 	if err := h.authService.UpdateUserRole(c.Request.Context(), userID, role); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 	
@@ -232,12 +244,12 @@ func (h *AuthHandler) DeactivateUser(c *gin.Context) {
 	// Prevent self deactivation
 	authUser, _ := middleware.GetAuthUser(c)
 	if authUser.ID == userID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot deactivate your own account"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Cannot deactivate your own account"))
 		return
 	}
 	
 	if err := h.authService.DeactivateUser(c.Request.Context(), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 	
@@ -248,13 +260,13 @@ func (h *AuthHandler) DeactivateUser(c *gin.Context) {
 func (h *AuthHandler) LocalLogin(c *gin.Context) {
 	var req LocalLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").WithError(err))
 		return
 	}
 
 	session, err := h.authService.LocalLogin(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		problem.Respond(c, problem.New(http.StatusUnauthorized, err.Error()))
 		return
 	}
 
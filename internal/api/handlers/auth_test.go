@@ -91,6 +91,22 @@ func (m *mockAuthService) LocalLogin(ctx context.Context, username, password str
 	return args.Get(0).(*models.Session), args.Error(1)
 }
 
+func (m *mockAuthService) ValidateProviderToken(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockAuthService) RefreshProviderToken(ctx context.Context, provider string, refreshToken string) (*models.Session, error) {
+	args := m.Called(ctx, provider, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
 func TestLogin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -106,7 +122,9 @@ func TestLogin(t *testing.T) {
 			requestBody:    `{"invalid": "json"`,
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"error": "unexpected EOF",
+				"error":   "invalid request body",
+				"details": "unexpected EOF",
+				"code":    "invalid_request",
 			},
 		},
 		{
@@ -114,7 +132,11 @@ func TestLogin(t *testing.T) {
 			requestBody:    map[string]string{},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"error": "Key: 'LoginRequest.Provider' Error:Field validation for 'Provider' failed on the 'required' tag",
+				"error": "invalid request body",
+				"code":  "validation_failed",
+				"errors": []interface{}{
+					map[string]interface{}{"field": "provider", "code": "required", "message": "is required"},
+				},
 			},
 		},
 		{
@@ -175,7 +197,7 @@ func TestLogin(t *testing.T) {
 			
 			var actualBody map[string]interface{}
 			json.Unmarshal(w.Body.Bytes(), &actualBody)
-			assert.Equal(t, tt.expectedBody, actualBody)
+			assertBodyMembers(t, tt.expectedBody, actualBody)
 			
 			mockAuth.AssertExpectations(t)
 		})
@@ -200,7 +222,7 @@ func TestCallback(t *testing.T) {
 			queryParams:    "state=test-state",
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				assert.Contains(t, resp["error"], "required")
+				assert.Contains(t, resp["details"], "required")
 			},
 		},
 		{
@@ -209,7 +231,7 @@ func TestCallback(t *testing.T) {
 			queryParams:    "code=test-code",
 			expectedStatus: http.StatusBadRequest,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				assert.Contains(t, resp["error"], "required")
+				assert.Contains(t, resp["details"], "required")
 			},
 		},
 		{
@@ -308,7 +330,11 @@ func TestUpdateUserRole(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"error": "Key: 'UpdateRoleRequest.Role' Error:Field validation for 'Role' failed on the 'oneof' tag",
+				"error": "invalid request body",
+				"code":  "validation_failed",
+				"errors": []interface{}{
+					map[string]interface{}{"field": "role", "code": "oneof", "message": "must be one of: admin, operator, viewer"},
+				},
 			},
 		},
 		{
@@ -395,7 +421,7 @@ func TestUpdateUserRole(t *testing.T) {
 			
 			var actualBody map[string]interface{}
 			json.Unmarshal(w.Body.Bytes(), &actualBody)
-			assert.Equal(t, tt.expectedBody, actualBody)
+			assertBodyMembers(t, tt.expectedBody, actualBody)
 			
 			mockAuth.AssertExpectations(t)
 		})
@@ -436,14 +462,16 @@ func TestListUsers(t *testing.T) {
 			name:        "Default pagination",
 			queryParams: "",
 			setupMock: func(m *mockAuthService) {
-				m.On("ListUsers", mock.Anything, 10, 0).
+				m.On("ListUsers", mock.Anything, 100, 0).
 					Return(users, 2, nil)
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				assert.Equal(t, float64(2), resp["total"])
-				assert.Equal(t, float64(10), resp["limit"])
-				assert.Equal(t, float64(0), resp["offset"])
+				page := resp["pagination"].(map[string]interface{})
+				assert.Equal(t, float64(2), page["total_count"])
+				assert.Equal(t, float64(100), page["limit"])
+				assert.Equal(t, float64(0), page["offset"])
+				assert.NotContains(t, page, "next_cursor")
 				usersList := resp["users"].([]interface{})
 				assert.Len(t, usersList, 2)
 			},
@@ -457,9 +485,11 @@ func TestListUsers(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, resp map[string]interface{}) {
-				assert.Equal(t, float64(2), resp["total"])
-				assert.Equal(t, float64(5), resp["limit"])
-				assert.Equal(t, float64(10), resp["offset"])
+				page := resp["pagination"].(map[string]interface{})
+				assert.Equal(t, float64(2), page["total_count"])
+				assert.Equal(t, float64(5), page["limit"])
+				assert.Equal(t, float64(10), page["offset"])
+				assert.Contains(t, page, "prev_cursor")
 				usersList := resp["users"].([]interface{})
 				assert.Len(t, usersList, 0)
 			},
@@ -468,7 +498,7 @@ func TestListUsers(t *testing.T) {
 			name:        "Service error",
 			queryParams: "",
 			setupMock: func(m *mockAuthService) {
-				m.On("ListUsers", mock.Anything, 10, 0).
+				m.On("ListUsers", mock.Anything, 100, 0).
 					Return(nil, 0, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -503,4 +533,18 @@ func TestListUsers(t *testing.T) {
 			mockAuth.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// assertBodyMembers checks the members of a response body that expected
+// names; problem details responses carry more, such as their type and
+// instance
+func assertBodyMembers(t *testing.T, expected, actual map[string]interface{}) {
+	t.Helper()
+	if _, isProblem := actual["type"]; !isProblem {
+		assert.Equal(t, expected, actual)
+		return
+	}
+	for key, value := range expected {
+		assert.Equal(t, value, actual[key], key)
+	}
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/backup"
 	"go.uber.org/zap"
 )
@@ -39,9 +42,7 @@ type CreateBackupRequest struct {
 func (h *BackupHandler) CreateBackup(c *gin.Context) {
 	var req CreateBackupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -70,9 +71,7 @@ func (h *BackupHandler) CreateBackup(c *gin.Context) {
 	metadata, err := h.backupService.CreateBackup(c.Request.Context(), options)
 	if err != nil {
 		h.logger.Error("Failed to create backup", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to create backup: %v", err),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, fmt.Sprintf("Failed to create backup: %v", err)))
 		return
 	}
 
@@ -87,9 +86,7 @@ func (h *BackupHandler) ListBackups(c *gin.Context) {
 	backups, err := h.backupService.ListBackups()
 	if err != nil {
 		h.logger.Error("Failed to list backups", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list backups",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to list backups"))
 		return
 	}
 
@@ -123,9 +120,7 @@ func (h *BackupHandler) GetBackup(c *gin.Context) {
 	backup, err := h.backupService.GetBackup(backupID)
 	if err != nil {
 		h.logger.Error("Failed to get backup", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Backup not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Backup not found"))
 		return
 	}
 
@@ -141,6 +136,7 @@ type RestoreBackupRequest struct {
 	ResourceMapping map[string]string         `json:"resource_mapping,omitempty"`
 	RestoreFilter   *backup.ResourceFilter    `json:"restore_filter,omitempty"`
 	DecryptionKey   string                    `json:"decryption_key,omitempty"`
+	Parallelism     int                       `json:"parallelism,omitempty"`
 }
 
 // RestoreBackup restores from a backup
@@ -149,9 +145,13 @@ func (h *BackupHandler) RestoreBackup(c *gin.Context) {
 
 	var req RestoreBackupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if req.Parallelism < 0 || req.Parallelism > backup.MaxRestoreParallelism {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid parallelism").
+			WithDetail(fmt.Sprintf("parallelism must be between 1 and %d", backup.MaxRestoreParallelism)))
 		return
 	}
 
@@ -169,15 +169,14 @@ func (h *BackupHandler) RestoreBackup(c *gin.Context) {
 		ResourceMapping: req.ResourceMapping,
 		RestoreFilter:   req.RestoreFilter,
 		DecryptionKey:   req.DecryptionKey,
+		Parallelism:     req.Parallelism,
 	}
 
 	// Perform restore
 	result, err := h.backupService.RestoreBackup(c.Request.Context(), backupID, options)
 	if err != nil {
 		h.logger.Error("Failed to restore backup", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to restore backup: %v", err),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, fmt.Sprintf("Failed to restore backup: %v", err)))
 		return
 	}
 
@@ -195,9 +194,7 @@ func (h *BackupHandler) DeleteBackup(c *gin.Context) {
 
 	if err := h.backupService.DeleteBackup(backupID); err != nil {
 		h.logger.Error("Failed to delete backup", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete backup",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to delete backup"))
 		return
 	}
 
@@ -215,9 +212,7 @@ func (h *BackupHandler) ExportBackup(c *gin.Context) {
 	metadata, err := h.backupService.GetBackup(backupID)
 	if err != nil {
 		h.logger.Error("Failed to get backup", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Backup not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Backup not found"))
 		return
 	}
 
@@ -229,9 +224,7 @@ func (h *BackupHandler) ExportBackup(c *gin.Context) {
 	case "json", "":
 		exportFormat = backup.BackupFormatJSON
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid format. Supported formats: json, yaml",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid format. Supported formats: json, yaml"))
 		return
 	}
 
@@ -253,6 +246,53 @@ func (h *BackupHandler) ExportBackup(c *gin.Context) {
 	}
 }
 
+// DiffBackups returns the objects added, removed and modified between a
+// backup and another, or the live configuration when the other is "live"
+func (h *BackupHandler) DiffBackups(c *gin.Context) {
+	diff, err := h.backupService.DiffBackups(c.Request.Context(), c.Param("id"), c.Param("other"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			problem.Respond(c, problem.New(http.StatusNotFound, "Backup not found").WithError(err))
+		case strings.Contains(err.Error(), "not connected"):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+		default:
+			h.logger.Error("Failed to compare backups", zap.Error(err))
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to compare backups").WithError(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// VerifyRestore replays a backup against the DR staging deployment and
+// returns the signed verification report, whether the backup passed or not
+func (h *BackupHandler) VerifyRestore(c *gin.Context) {
+	report, err := h.backupService.VerifyRestore(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, backup.ErrVerificationDisabled):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "DR verification unavailable").
+				WithDetail("DR_STAGING_NORTHBOUND_DB is not configured"))
+		case errors.Is(err, backup.ErrVerificationInProgress):
+			problem.Respond(c, problem.New(http.StatusConflict, "DR verification in progress").WithError(err))
+		case strings.Contains(err.Error(), "not found"):
+			problem.Respond(c, problem.New(http.StatusNotFound, "Backup not found").WithError(err))
+		case strings.Contains(err.Error(), "not connected"):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "DR staging OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+		default:
+			h.logger.Error("Failed to verify restore", zap.Error(err))
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to verify restore").WithError(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // ImportBackupRequest represents an import request
 type ImportBackupRequest struct {
 	Format backup.BackupFormat `json:"format,omitempty"`
@@ -276,9 +316,7 @@ func (h *BackupHandler) ImportBackup(c *gin.Context) {
 		metadata, err := h.backupService.ImportBackup(file, format)
 		if err != nil {
 			h.logger.Error("Failed to import backup", zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Failed to import backup: %v", err),
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, fmt.Sprintf("Failed to import backup: %v", err)))
 			return
 		}
 
@@ -292,9 +330,7 @@ func (h *BackupHandler) ImportBackup(c *gin.Context) {
 	// Otherwise try JSON body
 	var req ImportBackupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request. Provide either a file upload or JSON data",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request. Provide either a file upload or JSON data"))
 		return
 	}
 
@@ -308,9 +344,7 @@ func (h *BackupHandler) ImportBackup(c *gin.Context) {
 	metadata, err := h.backupService.ImportBackup(reader, req.Format)
 	if err != nil {
 		h.logger.Error("Failed to import backup", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Failed to import backup: %v", err),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, fmt.Sprintf("Failed to import backup: %v", err)))
 		return
 	}
 
@@ -330,9 +364,7 @@ type ValidateBackupRequest struct {
 func (h *BackupHandler) ValidateBackup(c *gin.Context) {
 	var req ValidateBackupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// CacheManager manages the cache of OVN reads. CachedOVNService implements
+// it.
+type CacheManager interface {
+	WarmCache(ctx context.Context) error
+	ClearCache(ctx context.Context, pattern string) error
+	GetCacheStats() cache.CacheStats
+}
+
+// CacheHandler lets operators inspect, warm and clear the cache, e.g. to
+// recover from stale data without restarting the API
+type CacheHandler struct {
+	cache    CacheManager
+	clusters *services.OVNClusterManager
+	logger   *zap.Logger
+}
+
+func NewCacheHandler(cache CacheManager, clusters *services.OVNClusterManager, logger *zap.Logger) *CacheHandler {
+	return &CacheHandler{
+		cache:    cache,
+		clusters: clusters,
+		logger:   logger,
+	}
+}
+
+// Stats returns the cache's statistics
+func (h *CacheHandler) Stats(c *gin.Context) {
+	stats := h.cache.GetCacheStats()
+
+	hitRatio := 0.0
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		hitRatio = float64(stats.Hits) / float64(lookups)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hits":      stats.Hits,
+		"misses":    stats.Misses,
+		"sets":      stats.Sets,
+		"deletes":   stats.Deletes,
+		"errors":    stats.Errors,
+		"evictions": stats.Evictions,
+		"hit_ratio": hitRatio,
+	})
+}
+
+// Warm loads the topology and the switch and router lists of a cluster into
+// the cache. The cluster query parameter selects the cluster, the default
+// cluster otherwise.
+func (h *CacheHandler) Warm(c *gin.Context) {
+	ctx := c.Request.Context()
+	cluster := c.Query("cluster")
+	if cluster != "" {
+		if _, ok := h.clusters.Get(cluster); !ok {
+			problem.Respond(c, problem.New(http.StatusNotFound, "cluster not found"))
+			return
+		}
+		ctx = services.ContextWithOVNCluster(ctx, cluster)
+	}
+
+	if err := h.cache.WarmCache(ctx); err != nil {
+		if strings.Contains(err.Error(), "not connected") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+			return
+		}
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to warm cache").
+			WithError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cache warmed"})
+}
+
+// Clear removes cached data matching the pattern query parameter, e.g.
+// "switch:*", in every cluster, or all cached data without one
+func (h *CacheHandler) Clear(c *gin.Context) {
+	pattern := c.Query("pattern")
+
+	if err := h.cache.ClearCache(c.Request.Context(), pattern); err != nil {
+		if errors.Is(err, services.ErrInvalidCachePattern) {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid cache pattern").
+				WithDetail("patterns must start with switch:, router:, port:, acl:, topology:, lb: or nat:"))
+			return
+		}
+		h.logger.Error("Failed to clear cache", zap.String("pattern", pattern), zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to clear cache").
+			WithError(err))
+		return
+	}
+
+	h.logger.Info("Cache cleared by operator",
+		zap.String("pattern", pattern),
+		zap.String("user_id", c.GetString("user_id")))
+	c.JSON(http.StatusOK, gin.H{"message": "Cache cleared"})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeCacheManager records the calls made to it
+type fakeCacheManager struct {
+	warmErr     error
+	warmedFor   string
+	clearedWith []string
+	stats       cache.CacheStats
+}
+
+func (f *fakeCacheManager) WarmCache(ctx context.Context) error {
+	f.warmedFor = fmt.Sprint(ctx.Value("ovn_cluster"))
+	return f.warmErr
+}
+
+func (f *fakeCacheManager) ClearCache(ctx context.Context, pattern string) error {
+	if pattern == "users:*" {
+		return fmt.Errorf("%w: %q", services.ErrInvalidCachePattern, pattern)
+	}
+	f.clearedWith = append(f.clearedWith, pattern)
+	return nil
+}
+
+func (f *fakeCacheManager) GetCacheStats() cache.CacheStats {
+	return f.stats
+}
+
+func serveCache(t *testing.T, handler gin.HandlerFunc, method, target string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+	handler(c)
+	return w
+}
+
+func TestCacheHandler_Stats(t *testing.T) {
+	manager := &fakeCacheManager{stats: cache.CacheStats{Hits: 3, Misses: 1, Evictions: 2}}
+	handler := NewCacheHandler(manager, newTestClusterManager(t), zap.NewNop())
+
+	w := serveCache(t, handler.Stats, "GET", "/api/v1/admin/cache/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stats map[string]float64
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 3.0, stats["hits"])
+	assert.Equal(t, 2.0, stats["evictions"])
+	assert.Equal(t, 0.75, stats["hit_ratio"])
+}
+
+func TestCacheHandler_Warm(t *testing.T) {
+	manager := &fakeCacheManager{}
+	handler := NewCacheHandler(manager, newTestClusterManager(t), zap.NewNop())
+
+	w := serveCache(t, handler.Warm, "POST", "/api/v1/admin/cache/warm?cluster=eu-west")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "eu-west", manager.warmedFor)
+
+	w = serveCache(t, handler.Warm, "POST", "/api/v1/admin/cache/warm?cluster=ap-south")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	manager.warmErr = errors.New("topology: OVN client not connected")
+	w = serveCache(t, handler.Warm, "POST", "/api/v1/admin/cache/warm")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestCacheHandler_Clear(t *testing.T) {
+	manager := &fakeCacheManager{}
+	handler := NewCacheHandler(manager, newTestClusterManager(t), zap.NewNop())
+
+	w := serveCache(t, handler.Clear, "POST", "/api/v1/admin/cache/clear?pattern=switch:*")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serveCache(t, handler.Clear, "POST", "/api/v1/admin/cache/clear")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"switch:*", ""}, manager.clearedWith)
+
+	w = serveCache(t, handler.Clear, "POST", "/api/v1/admin/cache/clear?pattern=users:*")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// OVNCapabilities returns the capability matrix of the northbound schema of
+// the OVN cluster selected in the context. *services.OVNClusterManager
+// implements it.
+type OVNCapabilities interface {
+	Capabilities(ctx context.Context) (*ovn.Capabilities, error)
+}
+
+type CapabilityHandler struct {
+	capabilities OVNCapabilities
+}
+
+func NewCapabilityHandler(capabilities OVNCapabilities) *CapabilityHandler {
+	return &CapabilityHandler{
+		capabilities: capabilities,
+	}
+}
+
+// Get returns which features the northbound schema of the cluster
+// supports, detected when ovncp connected to it
+func (h *CapabilityHandler) Get(c *gin.Context) {
+	capabilities, err := h.capabilities.Capabilities(c.Request.Context())
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not connected"):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithDetail("the capabilities are known once ovncp connected to the OVN northbound database"))
+		case strings.Contains(err.Error(), "not found"):
+			problem.Respond(c, problem.New(http.StatusNotFound, "cluster not found"))
+		default:
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+				WithError(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, capabilities)
+}
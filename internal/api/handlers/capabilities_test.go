@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+type fakeCapabilities struct {
+	capabilities *ovn.Capabilities
+	err          error
+}
+
+func (f fakeCapabilities) Capabilities(ctx context.Context) (*ovn.Capabilities, error) {
+	return f.capabilities, f.err
+}
+
+func TestCapabilityHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	capabilities := &ovn.Capabilities{
+		SchemaVersion: "6.1.0",
+		Features: []ovn.Capability{
+			{Name: ovn.CapabilityDNS, Available: true, Requires: []string{"DNS"}},
+			{Name: ovn.CapabilitySampling, Requires: []string{"Sample"}, Missing: []string{"Sample"}},
+		},
+		UnsupportedTables: []string{"Sample"},
+	}
+
+	tests := []struct {
+		name           string
+		capabilities   fakeCapabilities
+		expectedStatus int
+	}{
+		{
+			name:           "detected",
+			capabilities:   fakeCapabilities{capabilities: capabilities},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not connected yet",
+			capabilities:   fakeCapabilities{err: errors.New("OVN cluster default: client not connected")},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "unknown cluster",
+			capabilities:   fakeCapabilities{err: errors.New("OVN cluster ap-south not found")},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCapabilityHandler(tt.capabilities)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/capabilities", nil)
+
+			handler.Get(c)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response ovn.Capabilities
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "6.1.0", response.SchemaVersion)
+				assert.True(t, response.Available(ovn.CapabilityDNS))
+				assert.False(t, response.Available(ovn.CapabilitySampling))
+				assert.Equal(t, []string{"Sample"}, response.UnsupportedTables)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/internal/templates"
+)
+
+type ChangesetHandler struct {
+	changesetService *services.ChangesetService
+}
+
+func NewChangesetHandler(changesetService *services.ChangesetService) *ChangesetHandler {
+	return &ChangesetHandler{
+		changesetService: changesetService,
+	}
+}
+
+// ChangesetRequest is the body of changeset create and update requests
+type ChangesetRequest struct {
+	Name        string                        `json:"name"`
+	Description string                        `json:"description,omitempty"`
+	Parameters  []templates.TemplateVariable  `json:"parameters,omitempty"`
+	Operations  []models.TransactionOperation `json:"operations"`
+}
+
+// ChangesetParametersRequest is the body of plan and execute requests
+type ChangesetParametersRequest struct {
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ChangesetReviewRequest is the body of approve and reject requests
+type ChangesetReviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+func (h *ChangesetHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	changesets, err := h.changesetService.ListChangesets(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	pageItems := pagination.Slice(changesets, page)
+	c.JSON(http.StatusOK, gin.H{
+		"changesets": pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(changesets)),
+	})
+}
+
+func (h *ChangesetHandler) Get(c *gin.Context) {
+	cs, err := h.changesetService.GetChangeset(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cs)
+}
+
+func (h *ChangesetHandler) Create(c *gin.Context) {
+	var req ChangesetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	cs, err := h.changesetService.CreateChangeset(c.Request.Context(), req.changeset(), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, cs)
+}
+
+func (h *ChangesetHandler) Update(c *gin.Context) {
+	var req ChangesetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	cs, err := h.changesetService.UpdateChangeset(c.Request.Context(), c.Param("id"), req.changeset(), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cs)
+}
+
+func (h *ChangesetHandler) Delete(c *gin.Context) {
+	if err := h.changesetService.DeleteChangeset(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Plan handles POST /changesets/:id/plan, rendering the changeset with the
+// given parameters and listing the changes it would make
+func (h *ChangesetHandler) Plan(c *gin.Context) {
+	var req ChangesetParametersRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	plan, err := h.changesetService.PlanChangeset(c.Request.Context(), c.Param("id"), req.Parameters)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *ChangesetHandler) Submit(c *gin.Context) {
+	cs, err := h.changesetService.SubmitChangeset(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cs)
+}
+
+func (h *ChangesetHandler) Approve(c *gin.Context) {
+	h.review(c, true)
+}
+
+func (h *ChangesetHandler) Reject(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *ChangesetHandler) review(c *gin.Context, approve bool) {
+	var req ChangesetReviewRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	cs, err := h.changesetService.ReviewChangeset(c.Request.Context(), c.Param("id"), c.GetString("user_id"), approve, req.Comment)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cs)
+}
+
+// Execute handles POST /changesets/:id/execute, running an approved
+// changeset with the given parameters in one transaction
+func (h *ChangesetHandler) Execute(c *gin.Context) {
+	var req ChangesetParametersRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	response, err := h.changesetService.ExecuteChangeset(c.Request.Context(), c.Param("id"), req.Parameters, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if response.Success {
+		c.JSON(http.StatusOK, response)
+	} else {
+		c.JSON(http.StatusBadRequest, response)
+	}
+}
+
+func (req *ChangesetRequest) changeset() *services.Changeset {
+	return &services.Changeset{
+		Name:        req.Name,
+		Description: req.Description,
+		Parameters:  req.Parameters,
+		Operations:  req.Operations,
+	}
+}
+
+// bindOptionalJSON binds a request body that may be left out
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(obj); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return false
+	}
+	return true
+}
+
+func (h *ChangesetHandler) handleError(c *gin.Context, err error) {
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrChangesetNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "changeset not found"))
+	case errors.Is(err, services.ErrInvalidChangeset):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+	case errors.Is(err, services.ErrChangesetStatus):
+		problem.Respond(c, problem.New(http.StatusConflict, "invalid changeset status").
+			WithError(err))
+	case errors.Is(err, services.ErrChangesetSelfReview):
+		problem.Respond(c, problem.New(http.StatusForbidden, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		// A resource the changeset updates or deletes is missing
+		problem.Respond(c, problem.New(http.StatusUnprocessableEntity, "plan failed").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
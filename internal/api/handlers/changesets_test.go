@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func setupChangesetRouter(t *testing.T, mockService *MockOVNService) *gin.Engine {
+	store, err := services.NewFileChangesetStore(t.TempDir())
+	require.NoError(t, err)
+	handler := NewChangesetHandler(services.NewChangesetService(store, mockService, zap.NewNop()))
+
+	router := gin.New()
+	// Each request acts as the user named in X-User
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+	})
+	router.GET("/changesets", handler.List)
+	router.GET("/changesets/:id", handler.Get)
+	router.POST("/changesets", handler.Create)
+	router.PUT("/changesets/:id", handler.Update)
+	router.DELETE("/changesets/:id", handler.Delete)
+	router.POST("/changesets/:id/plan", handler.Plan)
+	router.POST("/changesets/:id/submit", handler.Submit)
+	router.POST("/changesets/:id/approve", handler.Approve)
+	router.POST("/changesets/:id/reject", handler.Reject)
+	router.POST("/changesets/:id/execute", handler.Execute)
+	return router
+}
+
+func changesetRequest(router *gin.Engine, method, path, user, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", user)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestChangesetHandler_Workflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	router := setupChangesetRouter(t, mockService)
+
+	w, created := changesetRequest(router, "POST", "/changesets", "alice", `{
+		"name": "add switch",
+		"parameters": [{"name": "switch", "type": "string", "required": true}],
+		"operations": [{"id": "sw", "type": "create", "resource": "switch", "data": {"name": "{{.switch}}"}}]
+	}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "draft", created["status"])
+	path := "/changesets/" + created["id"].(string)
+
+	w, plan := changesetRequest(router, "POST", path+"/plan", "alice", `{"parameters": {"switch": "web"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]interface{}{"create": float64(1), "update": float64(0), "delete": float64(0)}, plan["summary"])
+
+	w, _ = changesetRequest(router, "POST", path+"/execute", "alice", `{"parameters": {"switch": "web"}}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, _ = changesetRequest(router, "POST", path+"/submit", "alice", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, _ = changesetRequest(router, "POST", path+"/approve", "alice", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, reviewed := changesetRequest(router, "POST", path+"/approve", "bob", `{"comment": "ok"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "approved", reviewed["status"])
+
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]services.TransactionOp)
+		ops[0].ResourceID = "switch-uuid"
+	}).Return(nil)
+
+	w, executed := changesetRequest(router, "POST", path+"/execute", "alice", `{"parameters": {"switch": "web"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, executed["success"].(bool))
+
+	w, list := changesetRequest(router, "GET", "/changesets?status=approved", "alice", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), list["count"])
+
+	w, _ = changesetRequest(router, "DELETE", path, "alice", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w, _ = changesetRequest(router, "GET", path, "alice", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestChangesetHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "invalid json",
+			method:         "POST",
+			path:           "/changesets",
+			body:           "{",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body",
+		},
+		{
+			name:           "invalid operation",
+			method:         "POST",
+			path:           "/changesets",
+			body:           `{"name": "bad", "operations": [{"id": "op1", "type": "create", "resource": "port", "data": {"name": "p"}}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation failed",
+		},
+		{
+			name:           "unknown changeset",
+			method:         "POST",
+			path:           "/changesets/missing/plan",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "changeset not found",
+		},
+		{
+			name:           "update unknown changeset",
+			method:         "PUT",
+			path:           "/changesets/missing",
+			body:           `{"name": "x", "operations": [{"id": "op1", "type": "delete", "resource": "switch", "resource_id": "sw"}]}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "changeset not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupChangesetRouter(t, new(MockOVNService))
+
+			w, response := changesetRequest(router, tt.method, tt.path, "alice", tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedError, response["error"])
+		})
+	}
+}
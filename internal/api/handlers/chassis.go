@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ChassisHandler serves the inventory of the chassis running ovn-controller
+type ChassisHandler struct {
+	chassis services.ChassisSource
+}
+
+// NewChassisHandler creates a handler
+func NewChassisHandler(chassis services.ChassisSource) *ChassisHandler {
+	return &ChassisHandler{chassis: chassis}
+}
+
+// List handles GET /api/v1/chassis, listing each chassis with its
+// encapsulations, supported features and the ports bound to it, relative to
+// the other chassis. ?gateway=true limits the list to chassis eligible to
+// host gateways and ?overloaded=true to overloaded chassis; loads are
+// always relative to every chassis.
+func (h *ChassisHandler) List(c *gin.Context) {
+	report, ok := h.report(c)
+	if !ok {
+		return
+	}
+
+	gateway, overloaded := c.Query("gateway") == "true", c.Query("overloaded") == "true"
+	if gateway || overloaded {
+		filtered := make([]services.ChassisCapacity, 0, len(report.Chassis))
+		for _, ch := range report.Chassis {
+			if (gateway && !ch.Gateway) || (overloaded && !ch.Overloaded) {
+				continue
+			}
+			filtered = append(filtered, ch)
+		}
+		report.Chassis = filtered
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Get handles GET /api/v1/chassis/:name, by chassis name or hostname
+func (h *ChassisHandler) Get(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "chassis name is required"))
+		return
+	}
+
+	report, ok := h.report(c)
+	if !ok {
+		return
+	}
+
+	for _, ch := range report.Chassis {
+		if ch.Name == name || ch.Hostname == name {
+			c.JSON(http.StatusOK, ch)
+			return
+		}
+	}
+	problem.Respond(c, problem.New(http.StatusNotFound, "chassis "+name+" not found"))
+}
+
+// report builds the chassis report. It returns false, with the response
+// written, if the southbound database can't be read.
+func (h *ChassisHandler) report(c *gin.Context) (*services.ChassisReport, bool) {
+	chassis, err := h.chassis.ListChassis(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return nil, false
+	}
+	return services.BuildChassisReport(chassis), true
+}
+
+// handleError handles generic errors
+func (h *ChassisHandler) handleError(c *gin.Context, err error) {
+	// The southbound database is unreachable
+	if strings.Contains(err.Error(), "failed to connect") || strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN southbound database"))
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
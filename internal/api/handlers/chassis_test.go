@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+type fakeChassisSource struct {
+	chassis []*models.Chassis
+	err     error
+}
+
+func (f *fakeChassisSource) ListChassis(ctx context.Context) ([]*models.Chassis, error) {
+	return f.chassis, f.err
+}
+
+func TestChassisHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	chassis := []*models.Chassis{
+		{Name: "gw-1", Hostname: "gw-1.example.com", Gateway: true, Ports: 2, GatewayPorts: 2,
+			Encaps: []models.ChassisEncap{{Type: "geneve", IP: "192.0.2.1"}}},
+		{Name: "hv-1", Hostname: "hv-1.example.com", Ports: 4},
+		{Name: "hv-2", Hostname: "hv-2.example.com", Ports: 30},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		err            error
+		expectedStatus int
+		expectedNames  []string
+	}{
+		{name: "all chassis", expectedStatus: http.StatusOK, expectedNames: []string{"gw-1", "hv-1", "hv-2"}},
+		{name: "gateway chassis", query: "?gateway=true", expectedStatus: http.StatusOK, expectedNames: []string{"gw-1"}},
+		{name: "overloaded chassis", query: "?overloaded=true", expectedStatus: http.StatusOK, expectedNames: []string{"hv-2"}},
+		{name: "southbound unreachable", err: errors.New("failed to connect to OVN southbound DB: connection refused"), expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChassisHandler(&fakeChassisSource{chassis: chassis, err: tt.err})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/chassis"+tt.query, nil)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var report services.ChassisReport
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				var names []string
+				for _, ch := range report.Chassis {
+					names = append(names, ch.Name)
+				}
+				assert.Equal(t, tt.expectedNames, names)
+				assert.Equal(t, 3, report.Count)
+				assert.Equal(t, 1, report.Overloaded)
+			}
+		})
+	}
+}
+
+func TestChassisHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewChassisHandler(&fakeChassisSource{chassis: []*models.Chassis{
+		{Name: "3f2b-uuid-like-name", Hostname: "hv-1.example.com", Ports: 4},
+	}})
+
+	tests := []struct {
+		name           string
+		param          string
+		expectedStatus int
+	}{
+		{name: "by name", param: "3f2b-uuid-like-name", expectedStatus: http.StatusOK},
+		{name: "by hostname", param: "hv-1.example.com", expectedStatus: http.StatusOK},
+		{name: "unknown", param: "hv-9", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "name", Value: tt.param}}
+			c.Request = httptest.NewRequest("GET", "/api/v1/chassis/"+tt.param, nil)
+
+			handler.Get(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
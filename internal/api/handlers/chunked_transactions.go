@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ChunkedTransactionHandler serves the transactions too large for one OVSDB
+// transaction at /api/v1/transactions/chunked
+type ChunkedTransactionHandler struct {
+	transactions *services.ChunkedTransactionService
+}
+
+// NewChunkedTransactionHandler creates a handler
+func NewChunkedTransactionHandler(transactions *services.ChunkedTransactionService) *ChunkedTransactionHandler {
+	return &ChunkedTransactionHandler{
+		transactions: transactions,
+	}
+}
+
+// Start handles POST /transactions/chunked, answering 202 Accepted while the
+// chunks are applied in the background
+func (h *ChunkedTransactionHandler) Start(c *gin.Context) {
+	var req models.ChunkedTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	// If dry run, return validation success
+	if req.DryRun {
+		chunkSize, err := services.ValidateChunkedTransaction(&req)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+		c.Set("dry_run", true)
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "validation successful",
+			"operations": len(req.Operations),
+			"chunk_size": chunkSize,
+			"chunks":     (len(req.Operations) + chunkSize - 1) / chunkSize,
+		})
+		return
+	}
+
+	tx, err := h.transactions.Start(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("Location", c.FullPath()+"/"+tx.ID)
+	c.JSON(http.StatusAccepted, tx)
+}
+
+// List handles GET /transactions/chunked
+func (h *ChunkedTransactionHandler) List(c *gin.Context) {
+	transactions, err := h.transactions.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": transactions,
+		"count":        len(transactions),
+	})
+}
+
+// Get handles GET /transactions/chunked/:id, reporting the progress of the
+// transaction
+func (h *ChunkedTransactionHandler) Get(c *gin.Context) {
+	tx, err := h.transactions.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tx)
+}
+
+// Resume handles POST /transactions/chunked/:id/resume, applying a failed
+// transaction from its last completed chunk
+func (h *ChunkedTransactionHandler) Resume(c *gin.Context) {
+	tx, err := h.transactions.Resume(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, tx)
+}
+
+func (h *ChunkedTransactionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrChunkedTransactionNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "chunked transaction not found"))
+	case errors.Is(err, services.ErrInvalidChunkedTransaction):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+	case errors.Is(err, services.ErrChunkedTransactionStatus):
+		problem.Respond(c, problem.New(http.StatusConflict, "invalid chunked transaction status").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestChunkedTransactionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("client not connected")).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	store, err := services.NewFileChunkedTransactionStore(t.TempDir())
+	require.NoError(t, err)
+	service, err := services.NewChunkedTransactionService(store, mockService, zap.NewNop())
+	require.NoError(t, err)
+	handler := NewChunkedTransactionHandler(service)
+
+	router := gin.New()
+	router.GET("/transactions/chunked", handler.List)
+	router.GET("/transactions/chunked/:id", handler.Get)
+	router.POST("/transactions/chunked", handler.Start)
+	router.POST("/transactions/chunked/:id/resume", handler.Resume)
+
+	operations := make([]string, 150)
+	for i := range operations {
+		operations[i] = fmt.Sprintf(`{"id": "sw%d", "type": "update", "resource": "switch", "resource_id": "uuid-%d", "data": {"description": "imported"}}`, i, i)
+	}
+	body := `{"operations": [` + strings.Join(operations, ",") + `]}`
+
+	w, response := changesetRequest(router, "POST", "/transactions/chunked", "alice", `{"dry_run": true, "operations": [`+strings.Join(operations, ",")+`]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(2), response["chunks"])
+
+	w, _ = changesetRequest(router, "POST", "/transactions/chunked", "alice", `{"chunk_size": 500, "operations": [`+operations[0]+`]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, response = changesetRequest(router, "POST", "/transactions/chunked", "alice", body)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	id := response["id"].(string)
+	assert.Equal(t, "/transactions/chunked/"+id, w.Header().Get("Location"))
+	service.Wait()
+
+	// The lost connection fails the first chunk; resuming applies both
+	w, response = changesetRequest(router, "GET", "/transactions/chunked/"+id, "alice", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.ChunkedTransactionStatusFailed, response["status"])
+	assert.Equal(t, float64(0), response["completed_chunks"])
+
+	w, _ = changesetRequest(router, "POST", "/transactions/chunked/"+id+"/resume", "alice", "")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	service.Wait()
+
+	w, response = changesetRequest(router, "GET", "/transactions/chunked/"+id, "alice", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.ChunkedTransactionStatusCompleted, response["status"])
+	assert.Equal(t, float64(2), response["completed_chunks"])
+
+	w, _ = changesetRequest(router, "POST", "/transactions/chunked/"+id+"/resume", "alice", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w, _ = changesetRequest(router, "GET", "/transactions/chunked/missing", "alice", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, response = changesetRequest(router, "GET", "/transactions/chunked", "alice", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+}
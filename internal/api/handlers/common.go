@@ -1,9 +1,16 @@
 package handlers
 
 import (
-	"strconv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
 )
 
 // isValidName checks if the name contains only valid characters
@@ -24,22 +31,89 @@ func isValidName(name string) bool {
 	return true
 }
 
-// parsePagination parses limit and offset from query parameters
-func parsePagination(c *gin.Context) (limit, offset int) {
-	limit = 10
-	offset = 0
-	
-	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
+// parseListOptions parses the filter, sort and paging query parameters of
+// resource lists:
+//
+//	limit, cursor       page size and start; see pagination.Parse
+//	name                exact name match
+//	external_ids        comma-separated key=value pairs, or bare keys that only
+//	                    have to be present
+//	sort                field to order by, prefixed with - for descending
+func parseListOptions(c *gin.Context) (*models.ListOptions, pagination.Page, error) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		return nil, page, err
+	}
+
+	opts := &models.ListOptions{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		Name:   c.Query("name"),
+	}
+
+	if ids := c.Query("external_ids"); ids != "" {
+		if opts.ExternalIDs, err = parseExternalIDs(ids); err != nil {
+			return nil, page, fmt.Errorf("invalid external_ids filter %w", err)
 		}
 	}
-	
-	if o := c.Query("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
+
+	if labels := c.Query("labels"); labels != "" {
+		if opts.Labels, err = models.ParseLabelSelector(labels); err != nil {
+			return nil, page, fmt.Errorf("invalid labels selector: %w", err)
 		}
 	}
-	
-	return limit, offset
-}
\ No newline at end of file
+
+	if sort := c.Query("sort"); sort != "" {
+		opts.SortBy = strings.TrimPrefix(sort, "-")
+		opts.SortDesc = strings.HasPrefix(sort, "-")
+	}
+
+	return opts, page, nil
+}
+
+// parseExternalIDs parses comma-separated key=value pairs, or bare keys
+// that only have to be present
+func parseExternalIDs(value string) (map[string]string, error) {
+	ids := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if key == "" {
+			return nil, fmt.Errorf("%q", pair)
+		}
+		ids[key] = v
+	}
+	return ids, nil
+}
+
+// respondQuotaExceeded answers err if it is an exceeded tenant quota: 403
+// when the tenant may not have the resource at all and 429 when it has used
+// up its quota. It reports whether it answered.
+func respondQuotaExceeded(c *gin.Context, err error) bool {
+	var quotaErr *services.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	status := http.StatusTooManyRequests
+	if quotaErr.Limit == 0 {
+		status = http.StatusForbidden
+	}
+	problem.Respond(c, problem.New(status, "quota exceeded").
+		WithCode(problem.CodeQuotaExceeded).
+		WithError(quotaErr).
+		With("quota", quotaErr))
+	return true
+}
+
+// respondBackpressure answers err with 429 if it is a full batch queue,
+// asking the client to retry shortly. It reports whether it answered.
+func respondBackpressure(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrBatchQueueFull) {
+		return false
+	}
+
+	c.Header("Retry-After", "1")
+	problem.Respond(c, problem.New(http.StatusTooManyRequests, "too many pending operations").
+		WithError(err))
+	return true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ComplianceEvaluator evaluates the configuration against rule packs.
+// services.ComplianceEngine implements it.
+type ComplianceEvaluator interface {
+	Packs() []services.CompliancePack
+	Evaluate(ctx context.Context) (*services.ComplianceReport, error)
+	Latest() *services.ComplianceReport
+}
+
+// ComplianceHandler serves compliance rule packs and their outcome
+type ComplianceHandler struct {
+	compliance ComplianceEvaluator
+}
+
+// NewComplianceHandler creates a handler
+func NewComplianceHandler(compliance ComplianceEvaluator) *ComplianceHandler {
+	return &ComplianceHandler{compliance: compliance}
+}
+
+// Packs handles GET /api/v1/compliance/packs, listing the enabled rule
+// packs and their rules
+func (h *ComplianceHandler) Packs(c *gin.Context) {
+	packs := h.compliance.Packs()
+	c.JSON(http.StatusOK, gin.H{
+		"packs": packs,
+		"count": len(packs),
+	})
+}
+
+// Report handles GET /api/v1/compliance/report, returning the outcome of
+// the last evaluation, evaluating the rules if none ran yet
+func (h *ComplianceHandler) Report(c *gin.Context) {
+	report := h.compliance.Latest()
+	if report == nil {
+		var err error
+		if report, err = h.compliance.Evaluate(c.Request.Context()); err != nil {
+			h.handleError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Evaluate handles POST /api/v1/compliance/evaluate, evaluating the rules
+// now, alerting on changes like scheduled evaluations do
+func (h *ComplianceHandler) Evaluate(c *gin.Context) {
+	report, err := h.compliance.Evaluate(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ComplianceHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeComplianceEvaluator counts evaluations
+type fakeComplianceEvaluator struct {
+	latest      *services.ComplianceReport
+	evaluations int
+	err         error
+}
+
+func (f *fakeComplianceEvaluator) Packs() []services.CompliancePack {
+	return services.BuiltinCompliancePacks()[:1]
+}
+
+func (f *fakeComplianceEvaluator) Evaluate(ctx context.Context) (*services.ComplianceReport, error) {
+	f.evaluations++
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.latest = &services.ComplianceReport{Passed: f.evaluations}
+	return f.latest, nil
+}
+
+func (f *fakeComplianceEvaluator) Latest() *services.ComplianceReport {
+	return f.latest
+}
+
+func serveCompliance(handler gin.HandlerFunc, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, nil)
+	handler(c)
+	return w
+}
+
+func TestComplianceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	evaluator := &fakeComplianceEvaluator{}
+	handler := NewComplianceHandler(evaluator)
+
+	w := serveCompliance(handler.Packs, "GET", "/api/v1/compliance/packs")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"baseline"`)
+
+	// The first report is evaluated on request, later ones are the latest
+	var report services.ComplianceReport
+	w = serveCompliance(handler.Report, "GET", "/api/v1/compliance/report")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Passed)
+	serveCompliance(handler.Report, "GET", "/api/v1/compliance/report")
+	assert.Equal(t, 1, evaluator.evaluations)
+
+	w = serveCompliance(handler.Evaluate, "POST", "/api/v1/compliance/evaluate")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Passed)
+
+	evaluator.err = errors.New("client not connected")
+	assert.Equal(t, http.StatusServiceUnavailable, serveCompliance(handler.Evaluate, "POST", "/api/v1/compliance/evaluate").Code)
+	evaluator.err = errors.New("boom")
+	assert.Equal(t, http.StatusInternalServerError, serveCompliance(handler.Evaluate, "POST", "/api/v1/compliance/evaluate").Code)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// ConfigReloader holds the configuration in effect and reloads it.
+// *services.ConfigReloader implements it.
+type ConfigReloader interface {
+	Effective() (cfg *config.Config, loadedAt time.Time, pendingRestart []string)
+	Reload() (*services.ConfigReload, error)
+}
+
+// ConfigHandler lets operators see the configuration in effect and reload
+// it, as SIGHUP does
+type ConfigHandler struct {
+	reloader ConfigReloader
+	logger   *zap.Logger
+}
+
+func NewConfigHandler(reloader ConfigReloader, logger *zap.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		reloader: reloader,
+		logger:   logger,
+	}
+}
+
+// Get returns the settings in effect with where each came from, secrets
+// redacted, and the changed settings that wait for a restart
+func (h *ConfigHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, effectiveConfig(h.reloader))
+}
+
+// effectiveConfig describes the configuration in effect, as Get returns it
+func effectiveConfig(reloader ConfigReloader) gin.H {
+	cfg, loadedAt, pending := reloader.Effective()
+	if pending == nil {
+		pending = []string{}
+	}
+
+	return gin.H{
+		"file":            cfg.Reload.File,
+		"loaded_at":       loadedAt,
+		"settings":        cfg.Settings(),
+		"pending_restart": pending,
+	}
+}
+
+// Reload loads the configuration again and applies the settings that can
+// change while the server runs. An invalid configuration is rejected and
+// the one in effect kept.
+func (h *ConfigHandler) Reload(c *gin.Context) {
+	result, err := h.reloader.Reload()
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusUnprocessableEntity, "Invalid configuration").
+			WithError(err))
+		return
+	}
+
+	h.logger.Info("Configuration reloaded by operator",
+		zap.Strings("applied", result.Applied),
+		zap.String("user_id", c.GetString("user_id")))
+	if result.Applied == nil {
+		result.Applied = []string{}
+	}
+	if result.PendingRestart == nil {
+		result.PendingRestart = []string{}
+	}
+	c.JSON(http.StatusOK, result)
+}
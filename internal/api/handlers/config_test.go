@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeConfigReloader serves a fixed configuration and reload outcome
+type fakeConfigReloader struct {
+	cfg       *config.Config
+	reload    *services.ConfigReload
+	reloadErr error
+}
+
+func (f *fakeConfigReloader) Effective() (*config.Config, time.Time, []string) {
+	return f.cfg, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), []string{"API_PORT"}
+}
+
+func (f *fakeConfigReloader) Reload() (*services.ConfigReload, error) {
+	return f.reload, f.reloadErr
+}
+
+func TestConfigHandler_Get(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("JWT_SECRET", "s3cret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	handler := NewConfigHandler(&fakeConfigReloader{cfg: cfg}, zap.NewNop())
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/config")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cret")
+
+	var body struct {
+		LoadedAt       time.Time        `json:"loaded_at"`
+		Settings       []config.Setting `json:"settings"`
+		PendingRestart []string         `json:"pending_restart"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []string{"API_PORT"}, body.PendingRestart)
+	assert.Contains(t, body.Settings, config.Setting{Name: "LOG_LEVEL", Value: "debug", Source: config.SourceEnv, Reloadable: true})
+	assert.Contains(t, body.Settings, config.Setting{Name: "JWT_SECRET", Value: "REDACTED", Source: config.SourceEnv})
+}
+
+func TestConfigHandler_Reload(t *testing.T) {
+	reloader := &fakeConfigReloader{reload: &services.ConfigReload{Applied: []string{"LOG_LEVEL"}}}
+	handler := NewConfigHandler(reloader, zap.NewNop())
+
+	w := serveCache(t, handler.Reload, "POST", "/api/v1/admin/config/reload")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"applied": ["LOG_LEVEL"], "pending_restart": []}`, w.Body.String())
+
+	reloader.reloadErr = errors.New(`invalid configuration: invalid LOG_LEVEL "loud", must be debug, info, warn or error`)
+	w = serveCache(t, handler.Reload, "POST", "/api/v1/admin/config/reload")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `invalid LOG_LEVEL`)
+}
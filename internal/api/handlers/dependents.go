@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// respondHasDependents answers the delete of a switch or router refused
+// because of its dependents, listing them
+func respondHasDependents(c *gin.Context, resource string, deps *models.Dependents) {
+	problem.Respond(c, problem.New(http.StatusConflict, fmt.Sprintf("cannot delete %s", resource)).
+		WithCode(problem.CodeHasDependents).
+		WithDetail(fmt.Sprintf("%s has %d dependents; delete with ?cascade=true to delete them too", resource, deps.Count)).
+		With("dependents", deps))
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestDeleteWithDependents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sw := &models.LogicalSwitch{UUID: "sw-web", Name: "web", Ports: []string{"p-rtr"}}
+	lr := &models.LogicalRouter{UUID: "lr-edge", Name: "edge", Ports: []string{"lrp-web"}}
+	port := &models.LogicalSwitchPort{UUID: "p-rtr", Name: "web-to-edge", Type: "router", Options: map[string]string{"router-port": "edge-web"}}
+	topology := &services.Topology{
+		Switches:    []*models.LogicalSwitch{sw},
+		Routers:     []*models.LogicalRouter{lr},
+		Ports:       []*models.LogicalSwitchPort{port},
+		RouterPorts: []*models.LogicalRouterPort{{UUID: "lrp-web", Name: "edge-web"}},
+	}
+
+	mockService := new(MockOVNService)
+	mockService.On("GetLogicalSwitch", mock.Anything, "sw-web").Return(sw, nil)
+	mockService.On("ListPorts", mock.Anything, "sw-web").Return([]*models.LogicalSwitchPort{port}, nil)
+	mockService.On("GetLogicalRouter", mock.Anything, "lr-edge").Return(lr, nil)
+	mockService.On("GetTopology", mock.Anything).Return(topology, nil)
+
+	switchHandler := NewSwitchHandler(mockService)
+	routerHandler := NewRouterHandler(mockService)
+	router := gin.New()
+	router.GET("/switches/:id/dependents", switchHandler.Dependents)
+	router.DELETE("/switches/:id", switchHandler.Delete)
+	router.GET("/routers/:id/dependents", routerHandler.Dependents)
+	router.DELETE("/routers/:id", routerHandler.Delete)
+	request := func(method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := request("GET", "/switches/sw-web/dependents")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+	connections := response["router_connections"].([]interface{})
+	require.Len(t, connections, 1)
+	assert.Equal(t, "lr-edge", connections[0].(map[string]interface{})["router_id"])
+
+	// Deletes are refused with the dependents listed
+	w, response = request("DELETE", "/switches/sw-web")
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "cannot delete switch", response["error"])
+	assert.Equal(t, "sw-web", response["dependents"].(map[string]interface{})["resource_id"])
+
+	w, response = request("DELETE", "/routers/lr-edge")
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, float64(1), response["dependents"].(map[string]interface{})["count"])
+	mockService.AssertNotCalled(t, "DeleteLogicalSwitch", mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "DeleteLogicalRouter", mock.Anything, mock.Anything)
+
+	w, response = request("GET", "/routers/lr-edge/dependents")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "edge-web", response["ports"].([]interface{})[0].(map[string]interface{})["name"])
+
+	// Cascading deletes the dependents with the resource, in a single
+	// call to OVN
+	mockService.On("DeleteLogicalRouter", mock.Anything, "lr-edge").Return(nil)
+	w, _ = request("DELETE", "/routers/lr-edge?cascade=true")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertNotCalled(t, "DeletePort", mock.Anything, mock.Anything)
+
+	mockService.On("DeleteLogicalSwitch", mock.Anything, "sw-web").Return(nil)
+	w, _ = request("DELETE", "/switches/sw-web?cascade=true")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	mockService.On("GetLogicalRouter", mock.Anything, "missing").Return(nil, errors.New("logical router missing not found"))
+	w, _ = request("GET", "/routers/missing/dependents")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
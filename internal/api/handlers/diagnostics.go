@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// DiagnosticsCollector gathers diagnostics reports.
+// *services.DiagnosticsCollector implements it.
+type DiagnosticsCollector interface {
+	Collect(ctx context.Context) *services.DiagnosticsReport
+}
+
+// DiagnosticsHandler serves the diagnostics bundle attached to support
+// cases
+type DiagnosticsHandler struct {
+	collector DiagnosticsCollector
+	config    ConfigReloader
+	logger    *zap.Logger
+}
+
+func NewDiagnosticsHandler(collector DiagnosticsCollector, config ConfigReloader, logger *zap.Logger) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		collector: collector,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Get returns the diagnostics report as JSON, or with ?format=tar a gzipped
+// tarball of the report, the configuration in effect with secrets redacted
+// and a goroutine dump
+func (h *DiagnosticsHandler) Get(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "tar" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid format").
+			WithDetail("format must be json or tar"))
+		return
+	}
+
+	report := h.collector.Collect(c.Request.Context())
+	h.logger.Info("Diagnostics collected",
+		zap.String("format", format),
+		zap.String("user_id", c.GetString("user_id")))
+	name := "ovncp-diagnostics-" + report.GeneratedAt.Format("20060102T150405Z")
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	bundle, err := h.bundle(report)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to build diagnostics bundle").
+			WithError(err))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar.gz"))
+	c.Data(http.StatusOK, "application/gzip", bundle)
+}
+
+// bundle builds the gzipped tarball of a report
+func (h *DiagnosticsHandler) bundle(report *services.DiagnosticsReport) ([]byte, error) {
+	files := []struct {
+		name    string
+		content func() ([]byte, error)
+	}{
+		{"diagnostics.json", func() ([]byte, error) {
+			return json.MarshalIndent(report, "", "  ")
+		}},
+		{"config.json", func() ([]byte, error) {
+			return json.MarshalIndent(effectiveConfig(h.config), "", "  ")
+		}},
+		{"goroutines.txt", func() ([]byte, error) {
+			var dump bytes.Buffer
+			if err := pprof.Lookup("goroutine").WriteTo(&dump, 1); err != nil {
+				return nil, err
+			}
+			return dump.Bytes(), nil
+		}},
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		content, err := file.content()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: report.GeneratedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+type fakeDiagnosticsCollector struct{}
+
+func (fakeDiagnosticsCollector) Collect(ctx context.Context) *services.DiagnosticsReport {
+	return &services.DiagnosticsReport{
+		GeneratedAt: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		Version:     "0.2.0",
+		Clusters:    []services.ClusterDiagnostics{{Name: "default", Default: true}},
+	}
+}
+
+func TestDiagnosticsHandler_JSON(t *testing.T) {
+	handler := NewDiagnosticsHandler(fakeDiagnosticsCollector{}, &fakeConfigReloader{}, zap.NewNop())
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/diagnostics")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="ovncp-diagnostics-20261016T093000Z.json"`, w.Header().Get("Content-Disposition"))
+
+	var report services.DiagnosticsReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, "default", report.Clusters[0].Name)
+}
+
+func TestDiagnosticsHandler_Tar(t *testing.T) {
+	t.Setenv("JWT_SECRET", "s3cret")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	handler := NewDiagnosticsHandler(fakeDiagnosticsCollector{}, &fakeConfigReloader{cfg: cfg}, zap.NewNop())
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/diagnostics?format=tar")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="ovncp-diagnostics-20261016T093000Z.tar.gz"`, w.Header().Get("Content-Disposition"))
+
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+
+	require.Contains(t, files, "diagnostics.json")
+	assert.Contains(t, files["diagnostics.json"], `"version": "0.2.0"`)
+	// Secrets are redacted from the configuration
+	require.Contains(t, files, "config.json")
+	assert.Contains(t, files["config.json"], "JWT_SECRET")
+	assert.NotContains(t, files["config.json"], "s3cret")
+	assert.Contains(t, files["goroutines.txt"], "goroutine profile")
+}
+
+func TestDiagnosticsHandler_InvalidFormat(t *testing.T) {
+	handler := NewDiagnosticsHandler(fakeDiagnosticsCollector{}, &fakeConfigReloader{}, zap.NewNop())
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/diagnostics?format=zip")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
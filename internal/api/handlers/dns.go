@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// DNSHandler manages DNS record sets and the switches they're attached to
+type DNSHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewDNSHandler creates a handler
+func NewDNSHandler(ovnService services.OVNServiceInterface) *DNSHandler {
+	return &DNSHandler{
+		ovnService: ovnService,
+	}
+}
+
+func (h *DNSHandler) List(c *gin.Context) {
+	records, err := h.ovnService.ListDNS(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	items, ok := selectFields(c, records)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dns":   items,
+		"count": len(records),
+	})
+}
+
+func (h *DNSHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "DNS record set ID is required"))
+		return
+	}
+
+	dns, err := h.ovnService.GetDNS(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, dns)
+}
+
+func (h *DNSHandler) Create(c *gin.Context) {
+	var dns models.DNS
+	if err := c.ShouldBindJSON(&dns); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if len(dns.Records) == 0 {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("records", "required", "at least one DNS record is required"))
+		return
+	}
+
+	if err := validateDNSRecords(dns.Records); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	created, err := h.ovnService.CreateDNS(c.Request.Context(), &dns)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+func (h *DNSHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "DNS record set ID is required"))
+		return
+	}
+
+	var dns models.DNS
+	if err := c.ShouldBindJSON(&dns); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	// Records are only replaced if given, and then may not be emptied
+	if dns.Records != nil {
+		if len(dns.Records) == 0 {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("records", "required", "at least one DNS record is required"))
+			return
+		}
+		if err := validateDNSRecords(dns.Records); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithError(err))
+			return
+		}
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetDNS(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateDNS(ctx, id, &dns)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+func (h *DNSHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "DNS record set ID is required"))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetDNS(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	err := h.ovnService.DeleteDNS(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// handleError handles generic errors
+func (h *DNSHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
+
+// validateDNSRecords checks that each record maps a hostname to one or more
+// IP addresses, or a reverse lookup name under .arpa to a hostname
+func validateDNSRecords(records map[string]string) error {
+	for name, value := range records {
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("invalid DNS record name %q", name)
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return fmt.Errorf("DNS record %s has no value", name)
+		}
+		if strings.HasSuffix(strings.ToLower(name), ".arpa") {
+			continue
+		}
+		for _, field := range fields {
+			if net.ParseIP(field) == nil {
+				return fmt.Errorf("DNS record %s has invalid IP address %q", name, field)
+			}
+		}
+	}
+	return nil
+}
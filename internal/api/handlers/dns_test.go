@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestDNSHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     *models.DNS
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "successful create",
+			requestBody: map[string]interface{}{
+				"records":  map[string]string{"vm1": "10.0.0.5 fd00::5", "5.0.0.10.in-addr.arpa": "vm1"},
+				"switches": []string{"sw1"},
+			},
+			mockReturn:     &models.DNS{UUID: "new-uuid", Switches: []string{"sw1"}},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "unknown switch",
+			requestBody: map[string]interface{}{
+				"records":  map[string]string{"vm1": "10.0.0.5"},
+				"switches": []string{"missing"},
+			},
+			mockError:      errors.New("logical switch missing not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "missing records",
+			requestBody:    map[string]interface{}{"switches": []string{"sw1"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid address",
+			requestBody: map[string]interface{}{
+				"records": map[string]string{"vm1": "not-an-ip"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewDNSHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockService.On("CreateDNS", mock.Anything, mock.Anything).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/dns", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDNSHandler_Update(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewDNSHandler(mockService)
+
+	updated := &models.DNS{UUID: "dns1", Records: map[string]string{"vm1": "10.0.0.5"}, Switches: []string{}}
+	mockService.On("UpdateDNS", mock.Anything, "dns1", mock.MatchedBy(func(dns *models.DNS) bool {
+		return dns.Records == nil && dns.Switches != nil && len(dns.Switches) == 0
+	})).Return(updated, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "dns1"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/dns/dns1", bytes.NewReader([]byte(`{"switches":[]}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Update(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+
+	// Records may be replaced but not emptied
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "dns1"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/dns/dns1", bytes.NewReader([]byte(`{"records":{}}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Update(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDNSHandler_Delete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "successful delete", expectedStatus: http.StatusNoContent},
+		{name: "not found", mockError: errors.New("DNS record set dns1 not found"), expectedStatus: http.StatusNotFound},
+		{name: "not connected", mockError: errors.New("client not connected"), expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewDNSHandler(mockService)
+
+			mockService.On("DeleteDNS", mock.Anything, "dns1").Return(tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "dns1"}}
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/dns/dns1", nil)
+
+			handler.Delete(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/backup"
+	"go.uber.org/zap"
+)
+
+// DriftHandler manages golden backups and the drift of the live
+// configuration from them. The scope is a tenant with ?tenant_id=, the
+// whole cluster without.
+type DriftHandler struct {
+	detector *backup.DriftDetector
+	logger   *zap.Logger
+}
+
+func NewDriftHandler(detector *backup.DriftDetector, logger *zap.Logger) *DriftHandler {
+	return &DriftHandler{
+		detector: detector,
+		logger:   logger,
+	}
+}
+
+// MarkGoldenRequest marks a backup as the golden configuration of a tenant,
+// or of the cluster without a tenant
+type MarkGoldenRequest struct {
+	BackupID string `json:"backup_id" binding:"required"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// ListGolden lists the golden backups
+func (h *DriftHandler) ListGolden(c *gin.Context) {
+	goldens, err := h.detector.GoldenConfigs()
+	if err != nil {
+		h.logger.Error("Failed to list golden backups", zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to list golden backups").WithError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"golden": goldens,
+		"total":  len(goldens),
+	})
+}
+
+// MarkGolden marks a backup as golden, replacing the scope's previous one
+func (h *DriftHandler) MarkGolden(c *gin.Context) {
+	var req MarkGoldenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body").WithError(err))
+		return
+	}
+
+	golden, err := h.detector.MarkGolden(req.TenantID, req.BackupID, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to mark golden backup")
+		return
+	}
+
+	c.JSON(http.StatusOK, golden)
+}
+
+// UnmarkGolden stops checking the drift of a scope
+func (h *DriftHandler) UnmarkGolden(c *gin.Context) {
+	if err := h.detector.UnmarkGolden(c.Query("tenant_id")); err != nil {
+		h.respondError(c, err, "Failed to unmark golden backup")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Latest returns the report of the last check of each scope
+func (h *DriftHandler) Latest(c *gin.Context) {
+	reports, err := h.detector.Latest()
+	if err != nil {
+		h.respondError(c, err, "Failed to list drift reports")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reports": reports,
+		"total":   len(reports),
+	})
+}
+
+// Check compares the live configuration of a scope with its golden backup
+func (h *DriftHandler) Check(c *gin.Context) {
+	report, err := h.detector.Check(c.Request.Context(), c.Query("tenant_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to check configuration drift")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Plan returns the steps that would reconcile a scope with its golden
+// backup, without applying them
+func (h *DriftHandler) Plan(c *gin.Context) {
+	plan, err := h.detector.Plan(c.Request.Context(), c.Query("tenant_id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to plan reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// Reconcile applies the plan of a scope. Partial success is reported with
+// 206, as restores are.
+func (h *DriftHandler) Reconcile(c *gin.Context) {
+	tenantID := c.Query("tenant_id")
+	result, err := h.detector.Reconcile(c.Request.Context(), tenantID)
+	if err != nil {
+		h.respondError(c, err, "Failed to reconcile configuration")
+		return
+	}
+
+	h.logger.Info("Configuration reconciled",
+		zap.String("tenant_id", tenantID),
+		zap.String("user_id", c.GetString("user_id")),
+		zap.Int("applied", result.Applied),
+		zap.Int("failed", result.Failed))
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusPartialContent
+	}
+	c.JSON(status, result)
+}
+
+func (h *DriftHandler) respondError(c *gin.Context, err error, title string) {
+	switch {
+	case errors.Is(err, backup.ErrGoldenNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "Golden backup not found").WithError(err))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "Backup not found").WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		h.logger.Error(title, zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, title).WithError(err))
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// respondWithETag writes a resource along with its ETag. A GET whose
+// If-None-Match already names the current tag gets 304 Not Modified. GETs
+// honour ?fields=; the ETag is always that of the whole resource.
+func respondWithETag(c *gin.Context, status int, resource interface{}) {
+	body := resource
+	if c.Request.Method == http.MethodGet {
+		var ok bool
+		if body, ok = selectFields(c, resource); !ok {
+			return
+		}
+	}
+
+	etag, err := services.ResourceETag(resource)
+	if err != nil {
+		c.JSON(status, body)
+		return
+	}
+
+	c.Header("ETag", etag)
+	if c.Request.Method == http.MethodGet && etagMatches(c.GetHeader("If-None-Match"), etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(status, body)
+}
+
+// checkIfMatch enforces an If-Match precondition before an update or delete
+// so that clients don't overwrite changes they haven't seen. get loads the
+// current resource and is only called when the request carries If-Match. It
+// returns false, with the response written, when the change must not
+// proceed.
+//
+// The returned context carries the precondition on to the OVN client, which
+// checks it again against the row it changes and makes the transaction fail
+// if the row is modified before it commits.
+func checkIfMatch(c *gin.Context, get func() (interface{}, error), handleError func(*gin.Context, error)) (context.Context, bool) {
+	ctx := c.Request.Context()
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return ctx, true
+	}
+
+	current, err := get()
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return nil, false
+		}
+		handleError(c, err)
+		return nil, false
+	}
+
+	etag, err := services.ResourceETag(current)
+	if err != nil {
+		handleError(c, err)
+		return nil, false
+	}
+
+	if !etagMatches(ifMatch, etag, false) {
+		respondPreconditionFailed(c, etag)
+		return nil, false
+	}
+
+	return ovn.WithPrecondition(ctx, func(current interface{}) error {
+		etag, err := services.ResourceETag(current)
+		if err != nil {
+			return err
+		}
+		if !etagMatches(ifMatch, etag, false) {
+			return ovn.ErrPreconditionFailed
+		}
+		return nil
+	}), true
+}
+
+// respondPreconditionFailed writes a 412 for a resource modified since the
+// client read it, with the current ETag when it is known
+func respondPreconditionFailed(c *gin.Context, etag string) {
+	body := problem.New(http.StatusPreconditionFailed, "precondition failed").
+		WithDetail("resource has been modified since it was read")
+	if etag != "" {
+		c.Header("ETag", etag)
+		body.With("etag", etag)
+	}
+	problem.Respond(c, body)
+}
+
+// etagMatches reports whether a comma-separated If-Match or If-None-Match
+// header names etag. If-Match uses strong comparison, so weak tags never
+// match; If-None-Match uses weak comparison.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
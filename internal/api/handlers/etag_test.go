@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+func TestSwitchHandler_ConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	current := &models.LogicalSwitch{
+		UUID:        "uuid1",
+		Name:        "switch1",
+		OtherConfig: map[string]string{"subnet": "10.0.1.0/24"},
+	}
+	currentETag, err := services.ResourceETag(current)
+	require.NoError(t, err)
+
+	setupRouter := func(mockService *MockOVNService) *gin.Engine {
+		handler := NewSwitchHandler(mockService)
+		router := gin.New()
+		router.GET("/switches/:id", handler.Get)
+		router.PUT("/switches/:id", handler.Update)
+		router.DELETE("/switches/:id", handler.Delete)
+		return router
+	}
+
+	t.Run("get returns etag", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, httptest.NewRequest("GET", "/switches/uuid1", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, currentETag, w.Header().Get("ETag"))
+	})
+
+	t.Run("get with matching If-None-Match", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+
+		req := httptest.NewRequest("GET", "/switches/uuid1", nil)
+		req.Header.Set("If-None-Match", "W/"+currentETag)
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	tests := []struct {
+		name           string
+		ifMatch        string
+		expectUpdate   bool
+		expectedStatus int
+	}{
+		{
+			name:           "matching If-Match",
+			ifMatch:        currentETag,
+			expectUpdate:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wildcard If-Match",
+			ifMatch:        "*",
+			expectUpdate:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "stale If-Match",
+			ifMatch:        `"0123456789abcdef0123456789abcdef"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "weak If-Match never matches",
+			ifMatch:        "W/" + currentETag,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+			if tt.expectUpdate {
+				mockService.On("UpdateLogicalSwitch", mock.Anything, "uuid1", mock.Anything).Return(current, nil)
+			}
+
+			body, _ := json.Marshal(map[string]interface{}{"other_config": current.OtherConfig})
+			req := httptest.NewRequest("PUT", "/switches/uuid1", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", tt.ifMatch)
+			w := httptest.NewRecorder()
+			setupRouter(mockService).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			// The response carries the current tag either way
+			assert.Equal(t, currentETag, w.Header().Get("ETag"))
+			if !tt.expectUpdate {
+				mockService.AssertNotCalled(t, "UpdateLogicalSwitch", mock.Anything, mock.Anything, mock.Anything)
+
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "precondition failed", response["error"])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("update losing a race after the check", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+		mockService.On("UpdateLogicalSwitch", mock.Anything, "uuid1", mock.Anything).Return(nil, ovn.ErrPreconditionFailed)
+
+		body, _ := json.Marshal(map[string]interface{}{"other_config": current.OtherConfig})
+		req := httptest.NewRequest("PUT", "/switches/uuid1", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", currentETag)
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "precondition failed", response["error"])
+		// The tag the row changed to isn't known
+		assert.NotContains(t, response, "etag")
+	})
+
+	t.Run("delete with stale If-Match", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+
+		req := httptest.NewRequest("DELETE", "/switches/uuid1", nil)
+		req.Header.Set("If-Match", `"0123456789abcdef0123456789abcdef"`)
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, currentETag, w.Header().Get("ETag"))
+		mockService.AssertNotCalled(t, "DeleteLogicalSwitch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete with matching If-Match", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(current, nil)
+		mockService.On("DeleteLogicalSwitch", mock.Anything, "uuid1").Return(nil)
+
+		req := httptest.NewRequest("DELETE", "/switches/uuid1", nil)
+		req.Header.Set("If-Match", currentETag)
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("delete without If-Match skips the check", func(t *testing.T) {
+		mockService := new(MockOVNService)
+		mockService.On("DeleteLogicalSwitch", mock.Anything, "uuid1").Return(nil)
+
+		// Cascading deletes don't read the switch for its dependents either
+		w := httptest.NewRecorder()
+		setupRouter(mockService).ServeHTTP(w, httptest.NewRequest("DELETE", "/switches/uuid1?cascade=true", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertNotCalled(t, "GetLogicalSwitch", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// Export handles GET /api/v1/export?format=json|yaml|hcl. The JSON and YAML
+// forms are the same ID-annotated document; hcl renders Terraform resources
+// with import blocks.
+func (h *ExportHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" && format != "hcl" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid format. Supported formats: json, yaml, hcl"))
+		return
+	}
+
+	doc, err := h.exportService.Export(c.Request.Context())
+	if err != nil {
+		if strings.Contains(err.Error(), "not connected") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithDetail("unable to connect to OVN northbound database"))
+			return
+		}
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return
+	}
+
+	switch format {
+	case "yaml":
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+				WithError(err))
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml", data)
+	case "hcl":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", services.RenderHCL(doc))
+	default:
+		c.JSON(http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestExportHandler_Export(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                string
+		query               string
+		listError           error
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "json by default",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `"id":"sw-uuid"`,
+		},
+		{
+			name:                "yaml",
+			query:               "?format=yaml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/yaml",
+			expectedBody:        "id: sw-uuid",
+		},
+		{
+			name:                "hcl",
+			query:               "?format=hcl",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        `resource "ovncp_logical_switch" "web"`,
+		},
+		{
+			name:           "invalid format",
+			query:          "?format=xml",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid format",
+		},
+		{
+			name:           "not connected",
+			listError:      errors.New("client not connected"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "OVN service unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			if tt.listError != nil {
+				mockService.On("ListLogicalSwitches", mock.Anything).Return(nil, tt.listError)
+			} else {
+				mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{{UUID: "sw-uuid", Name: "web"}}, nil)
+				mockService.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+				mockService.On("ListLoadBalancers", mock.Anything).Return([]*models.LoadBalancer{}, nil)
+				mockService.On("ListPorts", mock.Anything, "sw-uuid").Return([]*models.LogicalSwitchPort{}, nil)
+				mockService.On("ListACLs", mock.Anything, "sw-uuid").Return([]*models.ACL{}, nil)
+			}
+			handler := NewExportHandler(services.NewExportService(mockService, zap.NewNop()))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/export"+tt.query, nil)
+
+			handler.Export(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+)
+
+// fieldTree is a parsed ?fields= selection. A nil subtree keeps the whole
+// value of a field.
+type fieldTree map[string]fieldTree
+
+// parseFields parses a comma-separated ?fields= list. Dots select nested
+// fields, as in external_ids.owner. It returns nil when no fields were
+// requested.
+func parseFields(c *gin.Context) fieldTree {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil
+	}
+
+	tree := fieldTree{}
+	for _, path := range strings.Split(raw, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		node := tree
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			sub, seen := node[part]
+			if i == len(parts)-1 {
+				// Selecting a field whole overrides selections within it
+				node[part] = nil
+				break
+			}
+			if seen && sub == nil {
+				break
+			}
+			if sub == nil {
+				sub = fieldTree{}
+				node[part] = sub
+			}
+			node = sub
+		}
+	}
+
+	if len(tree) == 0 {
+		return nil
+	}
+	return tree
+}
+
+// selectFields projects resource, a resource or slice of resources, to the
+// fields requested with ?fields=. Without the parameter the resource is
+// returned unchanged. Unknown fields get a 400 response, in which case ok
+// is false.
+func selectFields(c *gin.Context, resource interface{}) (interface{}, bool) {
+	fields := parseFields(c)
+	if fields == nil {
+		return resource, true
+	}
+
+	if err := fields.validate(reflect.TypeOf(resource)); err != nil {
+		respondInvalidFields(c, err)
+		return nil, false
+	}
+
+	projected, err := fields.project(resource)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return nil, false
+	}
+	return projected, true
+}
+
+func respondInvalidFields(c *gin.Context, err error) {
+	problem.Respond(c, problem.New(http.StatusBadRequest, "invalid fields parameter").
+		WithError(err))
+}
+
+// project marshals resource and keeps only the selected fields
+func (t fieldTree) project(resource interface{}) (interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return t.apply(value), nil
+}
+
+func (t fieldTree) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = t.apply(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		projected := make(map[string]interface{}, len(t))
+		for name, sub := range t {
+			field, ok := v[name]
+			if !ok {
+				continue
+			}
+			if sub != nil {
+				field = sub.apply(field)
+			}
+			projected[name] = field
+		}
+		return projected
+	default:
+		return value
+	}
+}
+
+// validate checks the selection against the JSON fields of typ. Fields of
+// maps, such as external_ids keys, can't be checked and are accepted.
+func (t fieldTree) validate(typ reflect.Type) error {
+	return t.validatePath(typ, "")
+}
+
+// validateAny accepts fields that any of types has, for responses mixing
+// several kinds of resource
+func (t fieldTree) validateAny(types ...reflect.Type) error {
+	for name, sub := range t {
+		single := fieldTree{name: sub}
+		var err error
+		for _, typ := range types {
+			if err = single.validate(typ); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t fieldTree) validatePath(typ reflect.Type, prefix string) error {
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := jsonFields(typ)
+	for name, sub := range t {
+		fieldType, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q", prefix+name)
+		}
+		if sub != nil {
+			if err := sub.validatePath(fieldType, prefix+name+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the JSON names of a struct's fields, including those of
+// embedded structs, to their types
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, t := range jsonFields(embedded) {
+					fields[n] = t
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestSelectFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	switches := []*models.LogicalSwitch{
+		{
+			UUID:        "uuid1",
+			Name:        "web",
+			Description: "web tier",
+			ExternalIDs: map[string]string{"owner": "team-a", "env": "prod"},
+		},
+		{UUID: "uuid2", Name: "db"},
+	}
+
+	tests := []struct {
+		name           string
+		fields         string
+		expected       interface{}
+		expectedStatus int
+	}{
+		{
+			name:   "top-level fields",
+			fields: "uuid,name",
+			expected: []interface{}{
+				map[string]interface{}{"uuid": "uuid1", "name": "web"},
+				map[string]interface{}{"uuid": "uuid2", "name": "db"},
+			},
+		},
+		{
+			name:   "nested map key",
+			fields: "name,+external_ids.owner",
+			expected: []interface{}{
+				map[string]interface{}{"name": "web", "external_ids": map[string]interface{}{"owner": "team-a"}},
+				map[string]interface{}{"name": "db"},
+			},
+		},
+		{
+			name:   "whole field overrides nested selection",
+			fields: "external_ids.owner,external_ids,uuid",
+			expected: []interface{}{
+				map[string]interface{}{"uuid": "uuid1", "external_ids": map[string]interface{}{"owner": "team-a", "env": "prod"}},
+				map[string]interface{}{"uuid": "uuid2"},
+			},
+		},
+		{
+			name:           "unknown field",
+			fields:         "uuid,subnet",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown nested field",
+			fields:         "created_at.zone",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/switches?fields="+tt.fields, nil)
+
+			projected, ok := selectFields(c, switches)
+			if tt.expectedStatus != 0 {
+				assert.False(t, ok)
+				assert.Equal(t, tt.expectedStatus, w.Code)
+				assert.Contains(t, w.Body.String(), "invalid fields parameter")
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, projected)
+		})
+	}
+}
+
+func TestSelectFields_NoSelection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/switches?fields=,", nil)
+
+	sw := &models.LogicalSwitch{UUID: "uuid1"}
+	projected, ok := selectFields(c, sw)
+	require.True(t, ok)
+	assert.Same(t, sw, projected)
+}
+
+func TestFieldSelectionInHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	sw := &models.LogicalSwitch{UUID: "uuid1", Name: "web", Description: "web tier"}
+	mockService.On("ListLogicalSwitchesPage", mock.Anything, mock.Anything).Return([]*models.LogicalSwitch{sw}, 1, nil)
+	mockService.On("GetLogicalSwitch", mock.Anything, "uuid1").Return(sw, nil)
+	mockService.On("GetTopology", mock.Anything).Return(&services.Topology{
+		Switches: []*models.LogicalSwitch{sw},
+		Routers:  []*models.LogicalRouter{{UUID: "r1", Name: "edge", Description: "edge router"}},
+		Connections: []services.Connection{
+			{From: "uuid1", To: "r1", Type: "switch-router"},
+		},
+	}, nil)
+
+	router := gin.New()
+	switchHandler := NewSwitchHandler(mockService)
+	router.GET("/switches", switchHandler.List)
+	router.GET("/switches/:id", switchHandler.Get)
+	router.GET("/topology", NewTopologyHandler(mockService, nil).GetTopology)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/switches?fields=uuid,name", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, []interface{}{map[string]interface{}{"uuid": "uuid1", "name": "web"}}, list["switches"])
+	assert.Equal(t, float64(1), list["count"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/switches/uuid1?fields=name", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"web"}`, w.Body.String())
+	// The ETag still identifies the whole resource
+	etag, err := services.ResourceETag(sw)
+	require.NoError(t, err)
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology?fields=uuid,name", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var topology map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &topology))
+	assert.Equal(t, []interface{}{map[string]interface{}{"uuid": "r1", "name": "edge"}}, topology["Routers"])
+	assert.Len(t, topology["Connections"], 1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology?fields=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
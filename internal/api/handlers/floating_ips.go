@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// FloatingIPs allocates and binds floating IPs. *services.FloatingIPService
+// implements it.
+type FloatingIPs interface {
+	Pools(ctx context.Context) ([]*services.FloatingIPPool, error)
+	Allocate(ctx context.Context, pool, description, user string) (*models.FloatingIP, error)
+	Get(ctx context.Context, id string) (*models.FloatingIP, error)
+	List(ctx context.Context) ([]*models.FloatingIP, error)
+	Bind(ctx context.Context, id string, binding *services.FloatingIPBinding) (*models.FloatingIP, error)
+	Unbind(ctx context.Context, id string) (*models.FloatingIP, error)
+	Release(ctx context.Context, id string) error
+}
+
+// FloatingIPHandler serves floating IPs at /api/v1/floating-ips
+type FloatingIPHandler struct {
+	fips FloatingIPs
+}
+
+func NewFloatingIPHandler(fips FloatingIPs) *FloatingIPHandler {
+	return &FloatingIPHandler{fips: fips}
+}
+
+// AllocateFloatingIPRequest allocates a floating IP from a pool, the first
+// one when Pool is empty
+type AllocateFloatingIPRequest struct {
+	Pool        string `json:"pool"`
+	Description string `json:"description"`
+}
+
+// Pools handles GET /floating-ips/pools
+func (h *FloatingIPHandler) Pools(c *gin.Context) {
+	pools, err := h.fips.Pools(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pools": pools,
+		"count": len(pools),
+	})
+}
+
+// List handles GET /floating-ips, listing the tenant's floating IPs, or
+// every tenant's without one. ?bound=true or false filters them.
+func (h *FloatingIPHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	fips, err := h.fips.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if bound := c.Query("bound"); bound != "" {
+		filtered := []*models.FloatingIP{}
+		for _, fip := range fips {
+			if fip.Bound() == (bound == "true") {
+				filtered = append(filtered, fip)
+			}
+		}
+		fips = filtered
+	}
+
+	pageItems := pagination.Slice(fips, page)
+	c.JSON(http.StatusOK, gin.H{
+		"floating_ips": pageItems,
+		"count":        len(pageItems),
+		"pagination":   pagination.Response(c, page, len(fips)),
+	})
+}
+
+// Get handles GET /floating-ips/:id
+func (h *FloatingIPHandler) Get(c *gin.Context) {
+	fip, err := h.fips.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fip)
+}
+
+// Allocate handles POST /floating-ips
+func (h *FloatingIPHandler) Allocate(c *gin.Context) {
+	var req AllocateFloatingIPRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithError(err))
+			return
+		}
+	}
+
+	fip, err := h.fips.Allocate(c.Request.Context(), req.Pool, req.Description, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, fip)
+}
+
+// Release handles DELETE /floating-ips/:id, unbinding the floating IP
+// first if it's bound
+func (h *FloatingIPHandler) Release(c *gin.Context) {
+	if err := h.fips.Release(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Bind handles POST /floating-ips/:id/bind
+func (h *FloatingIPHandler) Bind(c *gin.Context) {
+	var req services.FloatingIPBinding
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	fip, err := h.fips.Bind(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fip)
+}
+
+// Unbind handles POST /floating-ips/:id/unbind, keeping the floating IP
+// allocated
+func (h *FloatingIPHandler) Unbind(c *gin.Context) {
+	fip, err := h.fips.Unbind(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fip)
+}
+
+func (h *FloatingIPHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrFloatingIPNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "floating IP not found"))
+	case errors.Is(err, services.ErrInvalidFloatingIP), errors.Is(err, services.ErrNoFloatingIPPool):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid floating IP request").
+			WithError(err))
+	case errors.Is(err, services.ErrFloatingIPBinding):
+		problem.Respond(c, problem.New(http.StatusConflict, "floating IP binding conflict").
+			WithError(err))
+	case errors.Is(err, services.ErrFloatingIPPoolExhausted):
+		problem.Respond(c, problem.New(http.StatusConflict, "floating IP pool exhausted").
+			WithError(err))
+	case errors.Is(err, services.ErrResourceNotInTenant):
+		problem.Respond(c, problem.New(http.StatusNotFound, "port not found").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
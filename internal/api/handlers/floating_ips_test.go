@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeFloatingIPs hands out 203.0.113.10 from pool public once
+type fakeFloatingIPs struct {
+	fips map[string]*models.FloatingIP
+}
+
+func (f *fakeFloatingIPs) Pools(ctx context.Context) ([]*services.FloatingIPPool, error) {
+	return []*services.FloatingIPPool{{Name: "public", CIDR: "203.0.113.0/24", Allocated: len(f.fips)}}, nil
+}
+
+func (f *fakeFloatingIPs) Allocate(ctx context.Context, pool, description, user string) (*models.FloatingIP, error) {
+	if pool != "" && pool != "public" {
+		return nil, services.ErrNoFloatingIPPool
+	}
+	if len(f.fips) > 0 {
+		return nil, services.ErrFloatingIPPoolExhausted
+	}
+	fip := &models.FloatingIP{ID: "fip-1", Pool: "public", IP: "203.0.113.10", Description: description, AllocatedBy: user}
+	f.fips[fip.ID] = fip
+	return fip, nil
+}
+
+func (f *fakeFloatingIPs) Get(ctx context.Context, id string) (*models.FloatingIP, error) {
+	fip, ok := f.fips[id]
+	if !ok {
+		return nil, services.ErrFloatingIPNotFound
+	}
+	return fip, nil
+}
+
+func (f *fakeFloatingIPs) List(ctx context.Context) ([]*models.FloatingIP, error) {
+	fips := []*models.FloatingIP{}
+	for _, fip := range f.fips {
+		fips = append(fips, fip)
+	}
+	return fips, nil
+}
+
+func (f *fakeFloatingIPs) Bind(ctx context.Context, id string, binding *services.FloatingIPBinding) (*models.FloatingIP, error) {
+	fip, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if fip.Bound() {
+		return nil, services.ErrFloatingIPBinding
+	}
+	if binding.PortID != "lsp-web1" {
+		return nil, services.ErrResourceNotInTenant
+	}
+	fip.PortID, fip.LogicalIP, fip.GratuitousARP = binding.PortID, "10.0.0.11", binding.GratuitousARP
+	return fip, nil
+}
+
+func (f *fakeFloatingIPs) Unbind(ctx context.Context, id string) (*models.FloatingIP, error) {
+	fip, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	fip.PortID, fip.LogicalIP = "", ""
+	return fip, nil
+}
+
+func (f *fakeFloatingIPs) Release(ctx context.Context, id string) error {
+	if _, err := f.Get(ctx, id); err != nil {
+		return err
+	}
+	delete(f.fips, id)
+	return nil
+}
+
+func TestFloatingIPHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewFloatingIPHandler(&fakeFloatingIPs{fips: map[string]*models.FloatingIP{}})
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/floating-ips/pools", handler.Pools)
+	router.GET("/floating-ips", handler.List)
+	router.GET("/floating-ips/:id", handler.Get)
+	router.POST("/floating-ips", handler.Allocate)
+	router.DELETE("/floating-ips/:id", handler.Release)
+	router.POST("/floating-ips/:id/bind", handler.Bind)
+	router.POST("/floating-ips/:id/unbind", handler.Unbind)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/floating-ips", `{"pool": "private"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Without a body, from the first pool
+	w = serve(http.MethodPost, "/floating-ips", "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var fip models.FloatingIP
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fip))
+	assert.Equal(t, "203.0.113.10", fip.IP)
+	assert.Equal(t, "alice", fip.AllocatedBy)
+
+	w = serve(http.MethodPost, "/floating-ips", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serve(http.MethodPost, "/floating-ips/fip-1/bind", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "port_id is required")
+	w = serve(http.MethodPost, "/floating-ips/fip-1/bind", `{"port_id": "lsp-other"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(http.MethodPost, "/floating-ips/fip-1/bind", `{"port_id": "lsp-web1", "gratuitous_arp": true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"logical_ip":"10.0.0.11"`)
+	w = serve(http.MethodPost, "/floating-ips/fip-1/bind", `{"port_id": "lsp-web1"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serve(http.MethodGet, "/floating-ips?bound=false", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+	w = serve(http.MethodGet, "/floating-ips?bound=true", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+
+	w = serve(http.MethodPost, "/floating-ips/fip-1/unbind", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(http.MethodDelete, "/floating-ips/fip-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodGet, "/floating-ips/fip-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
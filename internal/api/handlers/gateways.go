@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// GatewayManager reports the health and placement of the gateways and
+// places them on chassis. services.GatewayMonitor implements it.
+type GatewayManager interface {
+	Status(ctx context.Context) (*services.GatewayReport, error)
+	Placement(ctx context.Context) (*services.GatewayPlacement, error)
+	Rebalance(ctx context.Context, dryRun bool) (*services.GatewayRebalance, error)
+	SetBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error)
+}
+
+// GatewayHandler serves the gateways connecting logical routers to the
+// external network
+type GatewayHandler struct {
+	gateways GatewayManager
+}
+
+// NewGatewayHandler creates a handler
+func NewGatewayHandler(gateways GatewayManager) *GatewayHandler {
+	return &GatewayHandler{gateways: gateways}
+}
+
+// List handles GET /api/v1/gateways, reporting each gateway's chassis
+// bindings, health and, when BGP state is collected, its sessions and
+// advertised prefixes. ?health= limits the gateways to one health.
+func (h *GatewayHandler) List(c *gin.Context) {
+	health := c.Query("health")
+	switch health {
+	case "", services.GatewayHealthy, services.GatewayDegraded, services.GatewayDown, services.GatewayUnknown:
+	default:
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("health", "oneof", "health must be one of: healthy, degraded, down, unknown"))
+		return
+	}
+
+	report, err := h.gateways.Status(c.Request.Context())
+	if err != nil {
+		if strings.Contains(err.Error(), "not connected") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+			return
+		}
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return
+	}
+
+	if health != "" {
+		filtered := make([]services.GatewayStatus, 0, len(report.Gateways))
+		for _, gateway := range report.Gateways {
+			if gateway.Health == health {
+				filtered = append(filtered, gateway)
+			}
+		}
+		report.Gateways = filtered
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Placement handles GET /api/v1/gateways/placement, listing the gateways
+// each chassis hosts, active or standby
+func (h *GatewayHandler) Placement(c *gin.Context) {
+	placement, err := h.gateways.Placement(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, placement)
+}
+
+// SetChassis handles PUT /api/v1/gateways/:portId/chassis, binding a router
+// port to gateway chassis, or to an HA chassis group when the body names
+// one, with the given priorities
+func (h *GatewayHandler) SetChassis(c *gin.Context) {
+	portID := c.Param("portId")
+	if portID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router port ID is required"))
+		return
+	}
+
+	var binding models.GatewayBinding
+	if err := c.ShouldBindJSON(&binding); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	gateway, err := h.gateways.SetBinding(c.Request.Context(), portID, &binding)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gateway)
+}
+
+// Rebalance handles POST /api/v1/gateways:rebalance, moving the active
+// gateways so the chassis host them evenly by changing chassis priorities.
+// With ?dry_run=true the moves are only planned.
+func (h *GatewayHandler) Rebalance(c *gin.Context) {
+	// gin can't escape the colon in gateways:rebalance, so the route ends in
+	// a wildcard that must hold exactly ":rebalance"
+	if c.Param("rebalance") != ":rebalance" {
+		problem.Respond(c, problem.New(http.StatusNotFound, "not found"))
+		return
+	}
+
+	plan, err := h.gateways.Rebalance(c.Request.Context(), c.Query("dry_run") == "true")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// handleError handles generic errors
+func (h *GatewayHandler) handleError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "invalid gateway binding") {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	if strings.Contains(err.Error(), "not found") {
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
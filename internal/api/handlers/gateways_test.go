@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestGatewayHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	gateways := []*models.Gateway{
+		{Type: "distributed", RouterName: "edge-1", Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 20}}, HostingChassis: "gw-1"},
+		{Type: "distributed", RouterName: "edge-2", Chassis: []models.GatewayChassis{}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockError      error
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "all gateways", expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "down gateways", query: "?health=down", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "invalid health", query: "?health=broken", expectedStatus: http.StatusBadRequest},
+		{name: "OVN unavailable", mockError: errors.New("client not connected"), expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewGatewayHandler(services.NewGatewayMonitor(mockService, nil))
+
+			if tt.expectedStatus != http.StatusBadRequest {
+				if tt.mockError != nil {
+					mockService.On("ListGateways", mock.Anything).Return(nil, tt.mockError)
+				} else {
+					mockService.On("ListGateways", mock.Anything).Return(gateways, nil)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/gateways"+tt.query, nil)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var report services.GatewayReport
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				assert.Len(t, report.Gateways, tt.expectedCount)
+				assert.Equal(t, 1, report.Summary[services.GatewayDown])
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGatewayHandler_SetChassis(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockError      error
+		expectSet      bool
+		expectedStatus int
+	}{
+		{
+			name:           "HA chassis group",
+			body:           `{"ha_chassis_group":"ha-edge","chassis":[{"name":"gw-1","priority":20},{"name":"gw-2","priority":10}]}`,
+			expectSet:      true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown port",
+			body:           `{"chassis":[{"name":"gw-1","priority":20}]}`,
+			mockError:      errors.New("logical router port lrp-9 not found"),
+			expectSet:      true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{name: "no chassis", body: `{"chassis":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "malformed body", body: `{"chassis":`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewGatewayHandler(services.NewGatewayMonitor(mockService, nil))
+
+			if tt.expectSet {
+				if tt.mockError != nil {
+					mockService.On("SetGatewayBinding", mock.Anything, "lrp-1", mock.Anything).Return(nil, tt.mockError)
+				} else {
+					mockService.On("SetGatewayBinding", mock.Anything, "lrp-1", mock.Anything).Return(&models.Gateway{
+						Type: "distributed", PortName: "lrp-1", HAChassisGroup: "ha-edge",
+						Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 20}, {Name: "gw-2", Priority: 10}},
+					}, nil)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "portId", Value: "lrp-1"}}
+			c.Request = httptest.NewRequest("PUT", "/api/v1/gateways/lrp-1/chassis", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.SetChassis(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGatewayHandler_Rebalance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	gateways := []*models.Gateway{
+		{Type: "distributed", PortID: "p1", PortName: "lrp-1", Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 20}, {Name: "gw-2", Priority: 10}}},
+		{Type: "distributed", PortID: "p2", PortName: "lrp-2", Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 20}, {Name: "gw-2", Priority: 10}}},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectApply    bool
+		expectedStatus int
+	}{
+		{name: "dry run", path: "/gateways:rebalance?dry_run=true", expectedStatus: http.StatusOK},
+		{name: "applied", path: "/gateways:rebalance", expectApply: true, expectedStatus: http.StatusOK},
+		{name: "unknown action", path: "/gateways:drain", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewGatewayHandler(services.NewGatewayMonitor(mockService, nil))
+
+			router := gin.New()
+			router.GET("/gateways", handler.List)
+			router.POST("/gateways:rebalance", handler.Rebalance)
+
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("ListGateways", mock.Anything).Return(gateways, nil)
+			}
+			if tt.expectApply {
+				mockService.On("SetGatewayPriorities", mock.Anything, map[string][]models.GatewayChassis{
+					"p2": {{Name: "gw-2", Priority: 20}, {Name: "gw-1", Priority: 10}},
+				}).Return(nil)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var plan services.GatewayRebalance
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plan))
+				require.Len(t, plan.Moves, 1)
+				assert.Equal(t, tt.expectApply, plan.Applied)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// maxImportBodySize limits the size of import documents
+const maxImportBodySize = 50 << 20
+
+type ImportHandler struct {
+	importService *services.ImportService
+}
+
+func NewImportHandler(importService *services.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// Import handles POST /api/v1/import. The body is an NB database JSON export
+// or "ovn-nbctl show" output, detected unless ?format= names it;
+// ?dry_run=true returns the plan without applying it. Resources are created
+// in the tenant of the request.
+func (h *ImportHandler) Import(c *gin.Context) {
+	dryRun, err := parseBoolQuery(c, "dry_run")
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid dry_run parameter"))
+		return
+	}
+	// Dry runs change nothing, so publish no resource events
+	c.Set("dry_run", dryRun)
+
+	body, ok := readBody(c, maxImportBodySize)
+	if !ok {
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = services.DetectImportFormat(body)
+	}
+
+	result, err := h.importService.Import(c.Request.Context(), format, body, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidImport):
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid import document").
+				WithError(err))
+		case strings.Contains(err.Error(), "invalid desired state"):
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithError(err))
+		case strings.Contains(err.Error(), "not connected"):
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithDetail("unable to connect to OVN northbound database"))
+		default:
+			response := problem.New(http.StatusInternalServerError, "import failed").WithError(err)
+			// Report the changes that were made before the failure
+			if result != nil {
+				response.With("result", result)
+			}
+			problem.Respond(c, response)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
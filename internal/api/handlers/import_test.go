@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestImportHandler_Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	mockService.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+	mockService.On("CreateLogicalRouter", mock.Anything, mock.Anything).Return(&models.LogicalRouter{UUID: "lr-uuid", Name: "edge"}, nil)
+
+	applyService := services.NewApplyService(mockService, zap.NewNop())
+	handler := NewImportHandler(services.NewImportService(applyService, zap.NewNop()))
+	router := gin.New()
+	router.POST("/import", handler.Import)
+	serve := func(query, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/import"+query, strings.NewReader(body)))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	show := "switch 4d0b3c1a (web)\n    port web-1\n        addresses: [\"dynamic\"]\n"
+	w, response := serve("?dry_run=true", show)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.ImportFormatNBShow, response["format"])
+	assert.Equal(t, true, response["dry_run"])
+	assert.Equal(t, float64(2), response["plan"].(map[string]interface{})["summary"].(map[string]interface{})["create"])
+
+	w, response = serve("", `{"caption": "Logical_Router table", "headings": ["_uuid", "name"], "data": [[["uuid", "r1"], "edge"]]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.ImportFormatNBJSON, response["format"])
+	assert.Equal(t, float64(1), response["applied"])
+
+	w, _ = serve("?format=nbctl-show", `{"Logical_Switch": {}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = serve("?format=csv", show)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Exports over the limit are refused rather than cut off
+	w, _ = serve("?dry_run=true", show+strings.Repeat(" ", maxImportBodySize))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// LabelHandler serves the labels of switches, routers, ports and ACLs. The
+// routes of each resource are bound to it with the resource type.
+type LabelHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewLabelHandler creates a handler
+func NewLabelHandler(ovnService services.OVNServiceInterface) *LabelHandler {
+	return &LabelHandler{ovnService: ovnService}
+}
+
+// labelsRequest is the body of label changes. Patches remove the labels set
+// to null.
+type labelsRequest struct {
+	Labels map[string]*string `json:"labels" binding:"required"`
+}
+
+// Get handles GET /api/v1/{switches,routers,ports,acls}/:id/labels
+func (h *LabelHandler) Get(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, externalIDs, err := h.lookup(c.Request.Context(), resource, c.Param("id"))
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"labels": models.LabelsFromExternalIDs(externalIDs)})
+	}
+}
+
+// Replace handles PUT /api/v1/{switches,routers,ports,acls}/:id/labels,
+// replacing every label of the resource
+func (h *LabelHandler) Replace(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set, remove, ok := h.bind(c, false)
+		if !ok {
+			return
+		}
+		h.update(c, resource, set, remove, true)
+	}
+}
+
+// Update handles PATCH /api/v1/{switches,routers,ports,acls}/:id/labels,
+// setting the given labels and removing those set to null; other labels
+// are kept
+func (h *LabelHandler) Update(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set, remove, ok := h.bind(c, true)
+		if !ok {
+			return
+		}
+		h.update(c, resource, set, remove, false)
+	}
+}
+
+// bind reads and validates the labels of a request. It returns false, with
+// the response written, if they are invalid.
+func (h *LabelHandler) bind(c *gin.Context, allowNull bool) (map[string]string, []string, bool) {
+	var req labelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return nil, nil, false
+	}
+
+	set := make(map[string]string)
+	var remove []string
+	for key, value := range req.Labels {
+		err := models.ValidateLabelKey(key)
+		if err == nil && value == nil && !allowNull {
+			err = fmt.Errorf("label %s has no value", key)
+		}
+		if err == nil && value != nil {
+			err = models.ValidateLabelValue(*value)
+		}
+		if err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithError(err))
+			return nil, nil, false
+		}
+
+		if value == nil {
+			remove = append(remove, key)
+		} else {
+			set[key] = *value
+		}
+	}
+	return set, remove, true
+}
+
+// update applies a label change to the resource identified by the request.
+// With replace, the labels not set are removed.
+func (h *LabelHandler) update(c *gin.Context, resource string, set map[string]string, remove []string, replace bool) {
+	ctx := c.Request.Context()
+	uuid, externalIDs, err := h.lookup(ctx, resource, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if replace {
+		for key := range models.LabelsFromExternalIDs(externalIDs) {
+			if _, ok := set[key]; !ok {
+				remove = append(remove, key)
+			}
+		}
+	}
+
+	labels, err := h.ovnService.SetLabels(ctx, resource, uuid, set, remove)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+// lookup returns the UUID and external IDs of a resource, given by UUID or,
+// for switches and routers, name
+func (h *LabelHandler) lookup(ctx context.Context, resource, id string) (string, map[string]string, error) {
+	switch resource {
+	case models.ResourceSwitch:
+		sw, err := h.ovnService.GetLogicalSwitch(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		return sw.UUID, sw.ExternalIDs, nil
+	case models.ResourceRouter:
+		lr, err := h.ovnService.GetLogicalRouter(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		return lr.UUID, lr.ExternalIDs, nil
+	case models.ResourcePort:
+		port, err := h.ovnService.GetPort(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		return port.UUID, port.ExternalIDs, nil
+	case models.ResourceACL:
+		acl, err := h.ovnService.GetACL(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		return acl.UUID, acl.ExternalIDs, nil
+	}
+	return "", nil, fmt.Errorf("resource %s has no labels", resource)
+}
+
+func (h *LabelHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "precondition failed"):
+		respondPreconditionFailed(c, "")
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestLabelHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetLogicalSwitch", mock.Anything, "web").Return(&models.LogicalSwitch{
+		UUID:        "sw-uuid",
+		Name:        "web",
+		ExternalIDs: map[string]string{"tenant_id": "acme", "ovncp.io/app": "web", "ovncp.io/env": "prod"},
+	}, nil)
+	mockService.On("GetLogicalSwitch", mock.Anything, "missing").Return(nil, fmt.Errorf("logical switch missing not found"))
+	mockService.On("GetACL", mock.Anything, "acl-uuid").Return(&models.ACL{UUID: "acl-uuid"}, nil)
+
+	handler := NewLabelHandler(mockService)
+	router := gin.New()
+	router.GET("/switches/:id/labels", handler.Get(models.ResourceSwitch))
+	router.PUT("/switches/:id/labels", handler.Replace(models.ResourceSwitch))
+	router.PATCH("/switches/:id/labels", handler.Update(models.ResourceSwitch))
+	router.PATCH("/acls/:id/labels", handler.Update(models.ResourceACL))
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	// Only the prefixed external IDs are labels
+	w, response := serve("GET", "/switches/web/labels", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]interface{}{"app": "web", "env": "prod"}, response["labels"])
+
+	// Patches keep the other labels; replacing removes them
+	mockService.On("SetLabels", mock.Anything, models.ResourceSwitch, "sw-uuid", map[string]string{"tier": "frontend"}, []string{"env"}).
+		Return(map[string]string{"app": "web", "tier": "frontend"}, nil).Once()
+	w, response = serve("PATCH", "/switches/web/labels", `{"labels": {"tier": "frontend", "env": null}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]interface{}{"app": "web", "tier": "frontend"}, response["labels"])
+
+	mockService.On("SetLabels", mock.Anything, models.ResourceSwitch, "sw-uuid", map[string]string{"app": "api"}, []string{"env"}).
+		Return(map[string]string{"app": "api"}, nil).Once()
+	w, _ = serve("PUT", "/switches/web/labels", `{"labels": {"app": "api"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockService.On("SetLabels", mock.Anything, models.ResourceACL, "acl-uuid", map[string]string{"team": "netops"}, []string(nil)).
+		Return(map[string]string{"team": "netops"}, nil).Once()
+	w, _ = serve("PATCH", "/acls/acl-uuid/labels", `{"labels": {"team": "netops"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for _, body := range []string{
+		`{"labels": {"-app": "web"}}`,
+		`{"labels": {"app": "web server"}}`,
+		`{"labels": {"app": null}}`, // replacing needs values
+		`{}`,
+	} {
+		w, _ = serve("PUT", "/switches/web/labels", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w, _ = serve("GET", "/switches/missing/labels", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
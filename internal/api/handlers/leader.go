@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/cluster"
+)
+
+// LeaderStatusReader reports the election of the replica running the
+// background jobs. *cluster.LeaderElector implements it.
+type LeaderStatusReader interface {
+	Status(ctx context.Context) (*cluster.LeaderStatus, error)
+}
+
+// LeaderHandler serves the state of the leader election
+type LeaderHandler struct {
+	leader LeaderStatusReader
+}
+
+func NewLeaderHandler(leader LeaderStatusReader) *LeaderHandler {
+	return &LeaderHandler{leader: leader}
+}
+
+// Get returns whether the replica serving the request is the leader, and
+// which replica is
+func (h *LeaderHandler) Get(c *gin.Context) {
+	status, err := h.leader.Status(c.Request.Context())
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "Leader unknown").
+			WithError(err))
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
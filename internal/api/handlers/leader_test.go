@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/cluster"
+)
+
+// fakeLeaderStatus reports a fixed election state
+type fakeLeaderStatus struct {
+	status *cluster.LeaderStatus
+	err    error
+}
+
+func (f *fakeLeaderStatus) Status(ctx context.Context) (*cluster.LeaderStatus, error) {
+	return f.status, f.err
+}
+
+func TestLeaderHandler_Get(t *testing.T) {
+	handler := NewLeaderHandler(&fakeLeaderStatus{status: &cluster.LeaderStatus{
+		NodeID: "ovncp-api-0", Backend: "postgres", LeaderID: "ovncp-api-1",
+	}})
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/leader")
+	require.Equal(t, http.StatusOK, w.Code)
+	var status cluster.LeaderStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.False(t, status.Leader)
+	assert.Equal(t, "ovncp-api-1", status.LeaderID)
+
+	handler = NewLeaderHandler(&fakeLeaderStatus{err: errors.New("connection refused")})
+	w = serveCache(t, handler.Get, "GET", "/api/v1/admin/leader")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
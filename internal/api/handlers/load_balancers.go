@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+type LoadBalancerHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+func NewLoadBalancerHandler(ovnService services.OVNServiceInterface) *LoadBalancerHandler {
+	return &LoadBalancerHandler{
+		ovnService: ovnService,
+	}
+}
+
+func (h *LoadBalancerHandler) List(c *gin.Context) {
+	opts, page, err := parseListOptions(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	stream := streamOptions(c, opts)
+	lbs, total, err := h.ovnService.ListLoadBalancersPage(c.Request.Context(), opts)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported sort field") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithError(err))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	if stream {
+		streamNDJSON(c, lbs, total, opts, func(opts *models.ListOptions) ([]*models.LoadBalancer, error) {
+			lbs, _, err := h.ovnService.ListLoadBalancersPage(c.Request.Context(), opts)
+			return lbs, err
+		})
+		return
+	}
+
+	items, ok := selectFields(c, lbs)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"load_balancers": items,
+		"count":          len(lbs),
+		"pagination":     pagination.Response(c, page, total),
+	})
+}
+
+func (h *LoadBalancerHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "load balancer ID is required"))
+		return
+	}
+
+	lb, err := h.ovnService.GetLoadBalancer(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, lb)
+}
+
+func (h *LoadBalancerHandler) Create(c *gin.Context) {
+	var lb models.LoadBalancer
+	if err := c.ShouldBindJSON(&lb); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	// Validate required fields
+	if lb.Name == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "required", "name is required"))
+		return
+	}
+
+	if !isValidName(lb.Name) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
+		return
+	}
+
+	if len(lb.VIPs) == 0 {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("vips", "required", "at least one VIP is required"))
+		return
+	}
+
+	if !isValidLBProtocol(lb.Protocol) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("protocol", "oneof", "protocol must be 'tcp', 'udp' or 'sctp'"))
+		return
+	}
+
+	created, err := h.ovnService.CreateLoadBalancer(c.Request.Context(), &lb)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+func (h *LoadBalancerHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "load balancer ID is required"))
+		return
+	}
+
+	var lb models.LoadBalancer
+	if err := c.ShouldBindJSON(&lb); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	// Validate name if provided
+	if lb.Name != "" && !isValidName(lb.Name) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
+		return
+	}
+
+	if !isValidLBProtocol(lb.Protocol) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("protocol", "oneof", "protocol must be 'tcp', 'udp' or 'sctp'"))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLoadBalancer(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateLoadBalancer(ctx, id, &lb)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+func (h *LoadBalancerHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "load balancer ID is required"))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLoadBalancer(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	err := h.ovnService.DeleteLoadBalancer(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// handleError handles generic errors
+func (h *LoadBalancerHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
+
+// isValidLBProtocol checks the optional load balancer protocol
+func isValidLBProtocol(protocol *string) bool {
+	if protocol == nil || *protocol == "" {
+		return true
+	}
+
+	switch *protocol {
+	case "tcp", "udp", "sctp":
+		return true
+	}
+	return false
+}
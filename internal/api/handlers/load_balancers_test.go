@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestLoadBalancerHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockReturn     []*models.LoadBalancer
+		mockError      error
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name: "successful list",
+			mockReturn: []*models.LoadBalancer{
+				{
+					UUID:      "uuid1",
+					Name:      "lb1",
+					VIPs:      map[string]string{"10.0.0.10:80": "192.168.1.10:8080"},
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"count": float64(1),
+			},
+		},
+		{
+			name:           "not connected error",
+			mockError:      errors.New("client not connected"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: map[string]interface{}{
+				"error": "OVN service unavailable",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewLoadBalancerHandler(mockService)
+
+			mockService.On("ListLoadBalancersPage", mock.Anything, mock.Anything).Return(tt.mockReturn, len(tt.mockReturn), tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/load-balancers", nil)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoadBalancerHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		lbID           string
+		mockReturn     *models.LoadBalancer
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful get",
+			lbID:           "uuid1",
+			mockReturn:     &models.LoadBalancer{UUID: "uuid1", Name: "lb1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not found",
+			lbID:           "nonexistent",
+			mockError:      errors.New("load balancer nonexistent not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "empty id",
+			lbID:           "",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewLoadBalancerHandler(mockService)
+
+			if tt.lbID != "" {
+				mockService.On("GetLoadBalancer", mock.Anything, tt.lbID).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/load-balancers/"+tt.lbID, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.lbID}}
+
+			handler.Get(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoadBalancerHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     *models.LoadBalancer
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "successful create",
+			requestBody: map[string]interface{}{
+				"name":     "web-lb",
+				"protocol": "tcp",
+				"vips": map[string]string{
+					"10.0.0.10:80": "192.168.1.10:8080,192.168.1.11:8080",
+				},
+			},
+			mockReturn:     &models.LoadBalancer{UUID: "new-uuid", Name: "web-lb"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "missing vips",
+			requestBody: map[string]interface{}{
+				"name": "web-lb",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid protocol",
+			requestBody: map[string]interface{}{
+				"name":     "web-lb",
+				"protocol": "icmp",
+				"vips":     map[string]string{"10.0.0.10:80": "192.168.1.10:8080"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid name",
+			requestBody: map[string]interface{}{
+				"name": "web lb",
+				"vips": map[string]string{"10.0.0.10:80": "192.168.1.10:8080"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewLoadBalancerHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.expectedStatus == http.StatusCreated {
+				mockService.On("CreateLoadBalancer", mock.Anything, mock.Anything).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/load-balancers", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoadBalancerHandler_Delete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		lbID           string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful delete",
+			lbID:           "uuid1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "not found",
+			lbID:           "nonexistent",
+			mockError:      errors.New("load balancer nonexistent not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewLoadBalancerHandler(mockService)
+
+			mockService.On("DeleteLoadBalancer", mock.Anything, tt.lbID).Return(tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/load-balancers/"+tt.lbID, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.lbID}}
+
+			handler.Delete(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
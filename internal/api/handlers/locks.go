@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/middleware"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ResourceLocks locks resources against writes by others.
+// *services.ResourceLockService implements it.
+type ResourceLocks interface {
+	Acquire(ctx context.Context, resourceType, resourceID, holder, reason string, ttl time.Duration) (*models.ResourceLock, bool, error)
+	Get(ctx context.Context, resourceType, resourceID string) (*models.ResourceLock, error)
+	List(ctx context.Context) ([]*models.ResourceLock, error)
+	Release(ctx context.Context, resourceType, resourceID, holder string, force bool) error
+}
+
+// lockWritePermissions are the permissions needed to lock resources of each
+// type: those needed to write them
+var lockWritePermissions = map[string]string{
+	models.ResourceSwitch:       "switches:write",
+	models.ResourceRouter:       "routers:write",
+	models.ResourcePort:         "ports:write",
+	models.ResourceACL:          "acls:write",
+	models.ResourceLoadBalancer: "load_balancers:write",
+}
+
+// LockHandler serves resource locks at /api/v1/locks
+type LockHandler struct {
+	locks ResourceLocks
+}
+
+// NewLockHandler creates a handler
+func NewLockHandler(locks ResourceLocks) *LockHandler {
+	return &LockHandler{locks: locks}
+}
+
+// AcquireLockRequest locks a resource
+type AcquireLockRequest struct {
+	// TTL is how long the lock lasts, e.g. "10m"; the server's default
+	// when empty
+	TTL    string `json:"ttl"`
+	Reason string `json:"reason"`
+}
+
+// List handles GET /locks, listing the locks held
+func (h *LockHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	locks, err := h.locks.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	pageItems := pagination.Slice(locks, page)
+	c.JSON(http.StatusOK, gin.H{
+		"locks":      pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(locks)),
+	})
+}
+
+// Get handles GET /locks/:type/:id, returning the lock of a resource
+func (h *LockHandler) Get(c *gin.Context) {
+	lock, err := h.locks.Get(c.Request.Context(), c.Param("type"), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lock)
+}
+
+// Acquire handles PUT /locks/:type/:id, locking a resource for the user,
+// or extending the user's lock. It answers 201 Created for a new lock and
+// 423 Locked, with the lock, when someone else holds one.
+func (h *LockHandler) Acquire(c *gin.Context) {
+	if !h.canLock(c) {
+		return
+	}
+
+	var req AcquireLockRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithError(err))
+			return
+		}
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithDetail("ttl must be a positive duration, e.g. 10m"))
+			return
+		}
+	}
+
+	lock, created, err := h.locks.Acquire(c.Request.Context(), c.Param("type"), c.Param("id"),
+		middleware.LockHolder(c), req.Reason, ttl)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	middleware.SetLockHeaders(c, lock)
+	if created {
+		c.JSON(http.StatusCreated, lock)
+		return
+	}
+	c.JSON(http.StatusOK, lock)
+}
+
+// Release handles DELETE /locks/:type/:id, unlocking a resource. Only the
+// holder releases a lock, unless the user has the admin permission.
+func (h *LockHandler) Release(c *gin.Context) {
+	if !h.canLock(c) {
+		return
+	}
+
+	err := h.locks.Release(c.Request.Context(), c.Param("type"), c.Param("id"),
+		middleware.LockHolder(c), middleware.HasPermission(c, "admin"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// canLock checks that the user may write the resource, answering the
+// request if not
+func (h *LockHandler) canLock(c *gin.Context) bool {
+	permission, ok := lockWritePermissions[c.Param("type")]
+	if !ok {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid resource type").
+			WithDetail("resources of type "+c.Param("type")+" can't be locked"))
+		return false
+	}
+	if !middleware.HasPermission(c, permission) {
+		problem.Respond(c, problem.New(http.StatusForbidden, "Insufficient permissions").
+			WithDetail("locking requires the "+permission+" permission"))
+		return false
+	}
+	return true
+}
+
+func (h *LockHandler) handleError(c *gin.Context, err error) {
+	var locked *services.ResourceLockedError
+	switch {
+	case errors.As(err, &locked):
+		middleware.SetLockHeaders(c, locked.Lock)
+		problem.Respond(c, problem.New(http.StatusLocked, "Resource locked").
+			WithDetail(locked.Error()).
+			With("lock", locked.Lock))
+	case errors.Is(err, services.ErrResourceLockNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource lock not found"))
+	case errors.Is(err, services.ErrInvalidResourceLock):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid resource lock").
+			WithError(err))
+	case errors.Is(err, services.ErrResourceLockHolder):
+		problem.Respond(c, problem.New(http.StatusForbidden, "cannot release resource lock").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeResourceLocks keeps locks in a map, without expiry
+type fakeResourceLocks struct {
+	locks map[string]*models.ResourceLock
+}
+
+func (f *fakeResourceLocks) Acquire(ctx context.Context, resourceType, resourceID, holder, reason string, ttl time.Duration) (*models.ResourceLock, bool, error) {
+	if ttl == 0 {
+		ttl = 5 * time.Minute
+	}
+	if ttl > time.Hour {
+		return nil, false, services.ErrInvalidResourceLock
+	}
+	key := resourceType + "/" + resourceID
+	if lock, ok := f.locks[key]; ok {
+		if lock.Holder != holder {
+			return nil, false, &services.ResourceLockedError{Lock: lock}
+		}
+		lock.Reason = reason
+		return lock, false, nil
+	}
+	lock := &models.ResourceLock{ID: key, ResourceType: resourceType, ResourceID: resourceID,
+		Holder: holder, Reason: reason, ExpiresAt: time.Now().Add(ttl)}
+	f.locks[key] = lock
+	return lock, true, nil
+}
+
+func (f *fakeResourceLocks) Get(ctx context.Context, resourceType, resourceID string) (*models.ResourceLock, error) {
+	lock, ok := f.locks[resourceType+"/"+resourceID]
+	if !ok {
+		return nil, services.ErrResourceLockNotFound
+	}
+	return lock, nil
+}
+
+func (f *fakeResourceLocks) List(ctx context.Context) ([]*models.ResourceLock, error) {
+	var locks []*models.ResourceLock
+	for _, lock := range f.locks {
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+func (f *fakeResourceLocks) Release(ctx context.Context, resourceType, resourceID, holder string, force bool) error {
+	lock, err := f.Get(ctx, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if lock.Holder != holder && !force {
+		return services.ErrResourceLockHolder
+	}
+	delete(f.locks, resourceType+"/"+resourceID)
+	return nil
+}
+
+func TestLockHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewLockHandler(&fakeResourceLocks{locks: make(map[string]*models.ResourceLock)})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		user, role, _ := strings.Cut(c.GetHeader("X-User"), ":")
+		c.Set("user_id", user)
+		c.Set("user_roles", []string{role})
+	})
+	router.GET("/locks", handler.List)
+	router.GET("/locks/:type/:id", handler.Get)
+	router.PUT("/locks/:type/:id", handler.Acquire)
+	router.DELETE("/locks/:type/:id", handler.Release)
+	request := func(method, path, user, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("X-User", user)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := request("PUT", "/locks/switch/sw-1", "alice:operator", `{"ttl":"10m","reason":"editing ACLs"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "alice", response["holder"])
+	assert.Equal(t, "alice", w.Header().Get("X-OVNCP-Lock-Holder"))
+
+	// The holder extends the lock; a body is optional
+	w, _ = request("PUT", "/locks/switch/sw-1", "alice:operator", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Others are told who holds it
+	w, response = request("PUT", "/locks/switch/sw-1", "bob:operator", "")
+	require.Equal(t, http.StatusLocked, w.Code)
+	assert.Equal(t, "alice", response["lock"].(map[string]interface{})["holder"])
+
+	w, _ = request("GET", "/locks/switch/sw-1", "bob:viewer", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, response = request("GET", "/locks", "bob:viewer", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+
+	// Locking takes the permission to write the resource
+	w, _ = request("PUT", "/locks/router/lr-1", "bob:viewer", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w, _ = request("PUT", "/locks/dns_record/r-1", "bob:operator", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = request("PUT", "/locks/router/lr-1", "bob:operator", `{"ttl":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = request("PUT", "/locks/router/lr-1", "bob:operator", `{"ttl":"2h"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Only the holder or an admin releases a lock
+	w, _ = request("DELETE", "/locks/switch/sw-1", "bob:operator", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w, _ = request("DELETE", "/locks/switch/sw-1", "carol:admin", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w, _ = request("GET", "/locks/switch/sw-1", "bob:viewer", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// MaintenanceModes sets and clears maintenance modes.
+// *services.MaintenanceService implements it.
+type MaintenanceModes interface {
+	Status(ctx context.Context) ([]*models.MaintenanceMode, error)
+	Set(ctx context.Context, mode *models.MaintenanceMode) error
+	Clear(ctx context.Context, tenantID string) error
+}
+
+// MaintenanceHandler serves the maintenance modes at
+// /api/v1/admin/maintenance
+type MaintenanceHandler struct {
+	modes MaintenanceModes
+}
+
+func NewMaintenanceHandler(modes MaintenanceModes) *MaintenanceHandler {
+	return &MaintenanceHandler{modes: modes}
+}
+
+// SetMaintenanceRequest sets the maintenance mode of a tenant, or of the
+// cluster without one
+type SetMaintenanceRequest struct {
+	TenantID string `json:"tenant_id"`
+	// Frozen defaults to true; false exempts the tenant from a
+	// cluster-wide freeze
+	Frozen *bool      `json:"frozen"`
+	Reason string     `json:"reason"`
+	ETA    *time.Time `json:"eta"`
+}
+
+// Get handles GET /admin/maintenance, listing the maintenance modes set
+func (h *MaintenanceHandler) Get(c *gin.Context) {
+	modes, err := h.modes.Status(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"modes": modes,
+		"total": len(modes),
+	})
+}
+
+// Set handles PUT /admin/maintenance, entering maintenance mode or
+// changing its reason or ETA
+func (h *MaintenanceHandler) Set(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithError(err))
+			return
+		}
+	}
+
+	mode := &models.MaintenanceMode{
+		TenantID: req.TenantID,
+		Frozen:   req.Frozen == nil || *req.Frozen,
+		Reason:   req.Reason,
+		ETA:      req.ETA,
+		SetBy:    c.GetString("user_id"),
+	}
+	if err := h.modes.Set(c.Request.Context(), mode); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mode)
+}
+
+// Clear handles DELETE /admin/maintenance, leaving the maintenance mode of
+// ?tenant_id=, or of the cluster without it
+func (h *MaintenanceHandler) Clear(c *gin.Context) {
+	if err := h.modes.Clear(c.Request.Context(), c.Query("tenant_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MaintenanceHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMaintenanceNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "maintenance mode not set"))
+	case errors.Is(err, services.ErrInvalidMaintenance):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid maintenance mode").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeMaintenanceModes keeps maintenance modes in a map
+type fakeMaintenanceModes map[string]*models.MaintenanceMode
+
+func (f fakeMaintenanceModes) Status(ctx context.Context) ([]*models.MaintenanceMode, error) {
+	modes := []*models.MaintenanceMode{}
+	for _, mode := range f {
+		modes = append(modes, mode)
+	}
+	return modes, nil
+}
+
+func (f fakeMaintenanceModes) Set(ctx context.Context, mode *models.MaintenanceMode) error {
+	if mode.ETA != nil && mode.ETA.Year() < 2000 {
+		return services.ErrInvalidMaintenance
+	}
+	f[mode.TenantID] = mode
+	return nil
+}
+
+func (f fakeMaintenanceModes) Clear(ctx context.Context, tenantID string) error {
+	if _, ok := f[tenantID]; !ok {
+		return services.ErrMaintenanceNotFound
+	}
+	delete(f, tenantID)
+	return nil
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	modes := fakeMaintenanceModes{}
+	handler := NewMaintenanceHandler(modes)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "admin") })
+	router.GET("/admin/maintenance", handler.Get)
+	router.PUT("/admin/maintenance", handler.Set)
+	router.DELETE("/admin/maintenance", handler.Clear)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPut, "/admin/maintenance", `{"reason": "OVN upgrade", "eta": "2030-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var mode models.MaintenanceMode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mode))
+	assert.True(t, mode.Frozen, "frozen by default")
+	assert.Equal(t, "admin", mode.SetBy)
+
+	w = serve(http.MethodPut, "/admin/maintenance", `{"tenant_id": "acme", "frozen": false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, modes["acme"].Frozen)
+
+	w = serve(http.MethodPut, "/admin/maintenance", `{"eta": "1999-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodGet, "/admin/maintenance", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":2`)
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/admin/maintenance?tenant_id=acme", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/maintenance?tenant_id=acme", "").Code)
+}
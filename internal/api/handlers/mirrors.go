@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// MirrorHandler manages port mirroring sessions and the ports they mirror
+type MirrorHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewMirrorHandler creates a handler
+func NewMirrorHandler(ovnService services.OVNServiceInterface) *MirrorHandler {
+	return &MirrorHandler{
+		ovnService: ovnService,
+	}
+}
+
+// List handles GET /api/v1/mirrors. ?active=true limits the list to mirrors
+// of at least one port and ?port= to the mirrors of a port.
+func (h *MirrorHandler) List(c *gin.Context) {
+	mirrors, err := h.ovnService.ListMirrors(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	active, port := c.Query("active") == "true", c.Query("port")
+	if active || port != "" {
+		filtered := make([]*models.Mirror, 0, len(mirrors))
+		for _, mirror := range mirrors {
+			if (active && !mirror.Active) || (port != "" && !containsPort(mirror.Ports, port)) {
+				continue
+			}
+			filtered = append(filtered, mirror)
+		}
+		mirrors = filtered
+	}
+
+	items, ok := selectFields(c, mirrors)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mirrors": items,
+		"count":   len(mirrors),
+	})
+}
+
+func (h *MirrorHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "mirror ID is required"))
+		return
+	}
+
+	mirror, err := h.ovnService.GetMirror(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, mirror)
+}
+
+func (h *MirrorHandler) Create(c *gin.Context) {
+	var mirror models.Mirror
+	if err := c.ShouldBindJSON(&mirror); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if mirror.Name == "" || mirror.Type == "" || mirror.Sink == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail("name, type and sink are required"))
+		return
+	}
+	// Mirror both directions unless told otherwise
+	if mirror.Filter == "" {
+		mirror.Filter = "both"
+	}
+
+	created, err := h.ovnService.CreateMirror(c.Request.Context(), &mirror)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+func (h *MirrorHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "mirror ID is required"))
+		return
+	}
+
+	var mirror models.Mirror
+	if err := c.ShouldBindJSON(&mirror); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetMirror(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateMirror(ctx, id, &mirror)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+func (h *MirrorHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "mirror ID is required"))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetMirror(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	err := h.ovnService.DeleteMirror(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AttachPort handles PUT /api/v1/mirrors/:id/ports/:portId, mirroring the
+// port's traffic. Attaching a port already mirrored changes nothing.
+func (h *MirrorHandler) AttachPort(c *gin.Context) {
+	mirror, portID, ok := h.mirrorAndPort(c)
+	if !ok {
+		return
+	}
+	if containsPort(mirror.Ports, portID) {
+		respondWithETag(c, http.StatusOK, mirror)
+		return
+	}
+
+	ports := append(append([]string{}, mirror.Ports...), portID)
+	updated, err := h.ovnService.UpdateMirror(c.Request.Context(), mirror.UUID, &models.Mirror{Ports: ports})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+// DetachPort handles DELETE /api/v1/mirrors/:id/ports/:portId, no longer
+// mirroring the port's traffic
+func (h *MirrorHandler) DetachPort(c *gin.Context) {
+	mirror, portID, ok := h.mirrorAndPort(c)
+	if !ok {
+		return
+	}
+	if !containsPort(mirror.Ports, portID) {
+		problem.Respond(c, problem.New(http.StatusNotFound, "port "+portID+" is not mirrored by mirror "+mirror.Name))
+		return
+	}
+
+	ports := []string{}
+	for _, p := range mirror.Ports {
+		if p != portID {
+			ports = append(ports, p)
+		}
+	}
+	_, err := h.ovnService.UpdateMirror(c.Request.Context(), mirror.UUID, &models.Mirror{Ports: ports})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// mirrorAndPort loads the mirror :id along with the :portId parameter. It
+// returns false, with the response written, if there is no such mirror.
+func (h *MirrorHandler) mirrorAndPort(c *gin.Context) (*models.Mirror, string, bool) {
+	id, portID := c.Param("id"), c.Param("portId")
+	if id == "" || portID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "mirror ID and port ID are required"))
+		return nil, "", false
+	}
+
+	mirror, err := h.ovnService.GetMirror(c.Request.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return nil, "", false
+		}
+		h.handleError(c, err)
+		return nil, "", false
+	}
+	return mirror, portID, true
+}
+
+// containsPort reports whether ports holds portID
+func containsPort(ports []string, portID string) bool {
+	for _, p := range ports {
+		if p == portID {
+			return true
+		}
+	}
+	return false
+}
+
+// handleError handles generic errors
+func (h *MirrorHandler) handleError(c *gin.Context, err error) {
+	// Mirrors OVN can't set up, such as a GRE mirror to a hostname
+	if strings.Contains(err.Error(), "invalid mirror") {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	// Another mirror has the same name
+	if strings.Contains(err.Error(), "already exists") {
+		problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+		return
+	}
+
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestMirrorHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mirrors := []*models.Mirror{
+		{UUID: "m1", Name: "span-web", Type: "gre", Sink: "192.0.2.10", Ports: []string{"web1", "web2"}, Active: true},
+		{UUID: "m2", Name: "span-db", Type: "local", Sink: "tap0", Ports: []string{"db1"}, Active: true},
+		{UUID: "m3", Name: "spare", Type: "erspan", Sink: "192.0.2.11", Ports: []string{}},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedNames []string
+	}{
+		{name: "all mirrors", expectedNames: []string{"span-web", "span-db", "spare"}},
+		{name: "active mirrors", query: "?active=true", expectedNames: []string{"span-web", "span-db"}},
+		{name: "mirrors of a port", query: "?port=db1", expectedNames: []string{"span-db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewMirrorHandler(mockService)
+			mockService.On("ListMirrors", mock.Anything).Return(mirrors, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/mirrors"+tt.query, nil)
+
+			handler.List(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Mirrors []models.Mirror `json:"mirrors"`
+				Count   int             `json:"count"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var names []string
+			for _, mirror := range response.Mirrors {
+				names = append(names, mirror.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+			assert.Equal(t, len(tt.expectedNames), response.Count)
+		})
+	}
+}
+
+func TestMirrorHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     *models.Mirror
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "successful create",
+			requestBody: map[string]interface{}{
+				"name": "span-web", "type": "gre", "sink": "192.0.2.10", "index": 7, "ports": []string{"web1"},
+			},
+			mockReturn:     &models.Mirror{UUID: "new-uuid", Name: "span-web", Filter: "both", Ports: []string{"web1"}, Active: true},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing sink",
+			requestBody:    map[string]interface{}{"name": "span-web", "type": "gre"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "sink not an address",
+			requestBody:    map[string]interface{}{"name": "span-web", "type": "gre", "sink": "collector"},
+			mockError:      errors.New("invalid mirror: sink of a gre mirror must be an IP address"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown port",
+			requestBody:    map[string]interface{}{"name": "span-web", "type": "local", "sink": "tap0", "ports": []string{"missing"}},
+			mockError:      errors.New("logical switch port missing not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "duplicate name",
+			requestBody:    map[string]interface{}{"name": "span-web", "type": "local", "sink": "tap0"},
+			mockError:      errors.New("mirror span-web already exists"),
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewMirrorHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.mockReturn != nil || tt.mockError != nil {
+				mockService.On("CreateMirror", mock.Anything, mock.MatchedBy(func(mirror *models.Mirror) bool {
+					return mirror.Filter == "both"
+				})).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/mirrors", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMirrorHandler_AttachPort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewMirrorHandler(mockService)
+
+	mirror := &models.Mirror{UUID: "m1", Name: "span-web", Ports: []string{"web1"}, Active: true}
+	mockService.On("GetMirror", mock.Anything, "span-web").Return(mirror, nil)
+	mockService.On("UpdateMirror", mock.Anything, "m1", mock.MatchedBy(func(update *models.Mirror) bool {
+		return assert.ObjectsAreEqual([]string{"web1", "web2"}, update.Ports)
+	})).Return(&models.Mirror{UUID: "m1", Name: "span-web", Ports: []string{"web1", "web2"}, Active: true}, nil).Once()
+
+	attach := func(port string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "span-web"}, {Key: "portId", Value: port}}
+		c.Request = httptest.NewRequest("PUT", "/api/v1/mirrors/span-web/ports/"+port, nil)
+		handler.AttachPort(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, attach("web2").Code)
+	// Attaching a port already mirrored doesn't write
+	assert.Equal(t, http.StatusOK, attach("web1").Code)
+	assert.Equal(t, "m1", mirror.UUID)
+	assert.Equal(t, []string{"web1"}, mirror.Ports, "the mirror read must not be modified")
+	mockService.AssertExpectations(t)
+}
+
+func TestMirrorHandler_DetachPort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		port           string
+		expectedStatus int
+	}{
+		{name: "mirrored port", port: "web1", expectedStatus: http.StatusNoContent},
+		{name: "port not mirrored", port: "db1", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewMirrorHandler(mockService)
+
+			mockService.On("GetMirror", mock.Anything, "m1").Return(&models.Mirror{UUID: "m1", Name: "span-web", Ports: []string{"web1"}}, nil)
+			if tt.expectedStatus == http.StatusNoContent {
+				// The last port detached leaves an empty, not absent, list
+				mockService.On("UpdateMirror", mock.Anything, "m1", mock.MatchedBy(func(update *models.Mirror) bool {
+					return update.Ports != nil && len(update.Ports) == 0
+				})).Return(&models.Mirror{UUID: "m1", Ports: []string{}}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "m1"}, {Key: "portId", Value: tt.port}}
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/mirrors/m1/ports/"+tt.port, nil)
+
+			handler.DetachPort(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMirrorHandler_Delete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "successful delete", expectedStatus: http.StatusNoContent},
+		{name: "not found", mockError: errors.New("mirror m1 not found"), expectedStatus: http.StatusNotFound},
+		{name: "not connected", mockError: errors.New("client not connected"), expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewMirrorHandler(mockService)
+
+			mockService.On("DeleteMirror", mock.Anything, "m1").Return(tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "m1"}}
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/mirrors/m1", nil)
+
+			handler.Delete(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
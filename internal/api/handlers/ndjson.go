@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// MIMENDJSON is the content type of lists streamed one JSON object per
+// line
+const MIMENDJSON = "application/x-ndjson"
+
+// ndjsonPageSize is how many items a streamed list reads at a time
+const ndjsonPageSize = pagination.MaxLimit
+
+// wantsNDJSON reports whether a list request asks with its Accept header
+// for the list streamed as NDJSON rather than a JSON document
+func wantsNDJSON(c *gin.Context) bool {
+	return c.NegotiateFormat(binding.MIMEJSON, MIMENDJSON) == MIMENDJSON
+}
+
+// streamOptions reports whether a list request asks for NDJSON, in which
+// case opts are set to read the list a page of ndjsonPageSize at a time
+func streamOptions(c *gin.Context, opts *models.ListOptions) bool {
+	if !wantsNDJSON(c) {
+		return false
+	}
+	opts.Limit = ndjsonPageSize
+	return true
+}
+
+// streamNDJSON answers a list request asking for NDJSON with the items of
+// first, the page read at opts, then those of the pages next reads after
+// it, one JSON object per line. Only a page is held at a time, and each is
+// flushed once written. Items are projected to ?fields= as in JSON
+// responses, and X-Total-Count is the length of the whole list.
+//
+// The stream runs from the cursor or offset of the request to the end of
+// the list: opts.Limit is the page size, ndjsonPageSize. Its status is sent
+// with the first page, so a page that can't be read ends the stream with
+// an {"error": problem} line.
+func streamNDJSON[T any](c *gin.Context, first []T, total int, opts *models.ListOptions, next func(opts *models.ListOptions) ([]T, error)) {
+	fields := parseFields(c)
+	if fields != nil {
+		if err := fields.validate(reflect.TypeOf(first)); err != nil {
+			respondInvalidFields(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Type", MIMENDJSON)
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	items := first
+	for {
+		for _, item := range items {
+			var value interface{} = item
+			if fields != nil {
+				projected, err := fields.project(item)
+				if err != nil {
+					endNDJSON(c, encoder, err)
+					return
+				}
+				value = projected
+			}
+			if err := encoder.Encode(value); err != nil {
+				// The client went away
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		if len(items) < opts.Limit {
+			return
+		}
+		opts.Offset += len(items)
+		var err error
+		if items, err = next(opts); err != nil {
+			endNDJSON(c, encoder, err)
+			return
+		}
+	}
+}
+
+// endNDJSON ends a stream that failed after its status was sent
+func endNDJSON(c *gin.Context, encoder *json.Encoder, err error) {
+	p := problem.New(http.StatusInternalServerError, "failed to read the list").WithError(err)
+	p.Instance = c.Request.URL.Path
+	_ = encoder.Encode(gin.H{"error": p})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func ndjsonLines(t *testing.T, body string) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	switches := []*models.LogicalSwitch{
+		{UUID: "1", Name: "a"}, {UUID: "2", Name: "b"}, {UUID: "3", Name: "c"},
+	}
+
+	stream := func(target string, next func(opts *models.ListOptions) ([]*models.LogicalSwitch, error)) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		streamNDJSON(c, switches[:2], len(switches), &models.ListOptions{Limit: 2}, next)
+		return w
+	}
+
+	var offsets []int
+	w := stream("/switches?fields=name", func(opts *models.ListOptions) ([]*models.LogicalSwitch, error) {
+		offsets = append(offsets, opts.Offset)
+		return switches[opts.Offset:], nil
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMENDJSON, w.Header().Get("Content-Type"))
+	assert.Equal(t, "3", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, []int{2}, offsets, "a short page ends the list")
+	assert.Equal(t, []map[string]interface{}{{"name": "a"}, {"name": "b"}, {"name": "c"}}, ndjsonLines(t, w.Body.String()))
+
+	w = stream("/switches", func(opts *models.ListOptions) ([]*models.LogicalSwitch, error) {
+		return nil, errors.New("connection lost")
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	lines := ndjsonLines(t, w.Body.String())
+	require.Len(t, lines, 3)
+	assert.Equal(t, "a", lines[0]["name"])
+	problem := lines[2]["error"].(map[string]interface{})
+	assert.Equal(t, float64(http.StatusInternalServerError), problem["status"])
+	assert.Equal(t, "connection lost", problem["detail"])
+
+	w = stream("/switches?fields=nope", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "fields are checked before the stream starts")
+}
+
+func TestListNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	switches := []*models.LogicalSwitch{{UUID: "1", Name: "a"}, {UUID: "2", Name: "b"}}
+	mockService.On("ListLogicalSwitchesPage", mock.Anything, &models.ListOptions{Limit: ndjsonPageSize, Offset: 0}).
+		Return(switches, 2, nil)
+
+	router := gin.New()
+	router.GET("/switches", NewSwitchHandler(mockService).List)
+
+	req := httptest.NewRequest(http.MethodGet, "/switches", nil)
+	req.Header.Set("Accept", MIMENDJSON)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMENDJSON, w.Header().Get("Content-Type"))
+	lines := ndjsonLines(t, w.Body.String())
+	require.Len(t, lines, 2)
+	assert.Equal(t, "a", lines[0]["name"])
+	assert.Equal(t, "b", lines[1]["name"])
+	mockService.AssertExpectations(t)
+
+	req = httptest.NewRequest(http.MethodGet, "/switches", nil)
+	req.Header.Set("Accept", "application/json")
+	mockService.On("ListLogicalSwitchesPage", mock.Anything, &models.ListOptions{Limit: 100}).Return(switches, 2, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
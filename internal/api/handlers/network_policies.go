@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+	"gopkg.in/yaml.v3"
+)
+
+// maxNetworkPolicyBodySize limits the size of network policy requests,
+// which may carry a pod inventory
+const maxNetworkPolicyBodySize = 10 << 20
+
+type NetworkPolicyHandler struct {
+	policyService *services.NetworkPolicyService
+}
+
+func NewNetworkPolicyHandler(policyService *services.NetworkPolicyService) *NetworkPolicyHandler {
+	return &NetworkPolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// Apply handles POST /api/v1/network-policies. The body is either a
+// NetworkPolicy object or {"policy": ..., "pods": [...], "namespaces": [...]},
+// as JSON or YAML. ?dry_run=true returns the translation without applying it.
+func (h *NetworkPolicyHandler) Apply(c *gin.Context) {
+	dryRun, err := parseBoolQuery(c, "dry_run")
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid dry_run parameter"))
+		return
+	}
+
+	body, ok := readBody(c, maxNetworkPolicyBodySize)
+	if !ok {
+		return
+	}
+	req, err := decodeNetworkPolicyRequest(body)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	objects, err := h.policyService.Apply(c.Request.Context(), req, dryRun)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid network policy") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithError(err))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, objects)
+}
+
+// List returns the OVN objects of every translated policy
+func (h *NetworkPolicyHandler) List(c *gin.Context) {
+	policies, err := h.policyService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"network_policies": policies,
+		"count":            len(policies),
+	})
+}
+
+// Get returns the OVN objects a policy produced
+func (h *NetworkPolicyHandler) Get(c *gin.Context) {
+	objects, err := h.policyService.Get(c.Request.Context(), c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, objects)
+}
+
+// Delete removes the OVN objects a policy produced
+func (h *NetworkPolicyHandler) Delete(c *gin.Context) {
+	err := h.policyService.Delete(c.Request.Context(), c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// handleError handles generic errors
+func (h *NetworkPolicyHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+	if strings.Contains(err.Error(), "quota exceeded") {
+		problem.Respond(c, problem.New(http.StatusForbidden, "quota exceeded").
+			WithError(err))
+		return
+	}
+
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
+
+// decodeNetworkPolicyRequest accepts JSON or YAML, either a bare
+// NetworkPolicy or a request wrapping one. Fields Kubernetes adds to stored
+// objects, such as status and managedFields, are ignored.
+func decodeNetworkPolicyRequest(data []byte) (*services.NetworkPolicyRequest, error) {
+	// YAML is a superset of JSON; round-trip through JSON so the Kubernetes
+	// field names in the json tags apply
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Policy json.RawMessage `json:"policy"`
+	}
+	if err := json.Unmarshal(jsonData, &probe); err != nil {
+		return nil, err
+	}
+
+	req := &services.NetworkPolicyRequest{}
+	if probe.Policy != nil {
+		err = json.Unmarshal(jsonData, req)
+	} else {
+		err = json.Unmarshal(jsonData, &req.Policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestNetworkPolicyHandler_Apply(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		listError      error
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:  "yaml network policy dry run",
+			query: "?dry_run=true",
+			body: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: web
+  namespace: prod
+  creationTimestamp: "2024-01-01T00:00:00Z"
+spec:
+  podSelector:
+    matchLabels:
+      app: web
+  ingress:
+    - ports:
+        - port: 80
+status: {}
+`,
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"policy": "prod/web",
+			},
+		},
+		{
+			name:           "wrapped json request",
+			query:          "?dry_run=true",
+			body:           `{"policy": {"metadata": {"name": "web", "namespace": "prod"}, "spec": {"podSelector": {}}}, "pods": [{"name": "web-1", "namespace": "prod"}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"selected_pods": []interface{}{"prod/web-1"},
+			},
+		},
+		{
+			name:           "invalid policy",
+			body:           `{"metadata": {"name": "web"}, "spec": {"podSelector": {}}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "validation failed",
+			},
+		},
+		{
+			name:           "empty body",
+			body:           "",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"error": "invalid request body",
+			},
+		},
+		{
+			name:           "body too large",
+			body:           "# " + strings.Repeat("x", maxNetworkPolicyBodySize),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody: map[string]interface{}{
+				"error": "request body too large",
+			},
+		},
+		{
+			name:           "not connected",
+			body:           `{"metadata": {"name": "web", "namespace": "prod"}, "spec": {"podSelector": {}}}`,
+			listError:      errors.New("client not connected"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: map[string]interface{}{
+				"error": "OVN service unavailable",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			if tt.listError != nil {
+				mockService.On("ListLogicalSwitches", mock.Anything).Return(nil, tt.listError)
+			} else {
+				mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+			}
+			handler := NewNetworkPolicyHandler(services.NewNetworkPolicyService(mockService, zap.NewNop()))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/network-policies"+tt.query, strings.NewReader(tt.body))
+
+			handler.Apply(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			for key, value := range tt.expectedBody {
+				assert.Equal(t, value, response[key])
+			}
+		})
+	}
+}
+
+func TestNetworkPolicyHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	owner := map[string]string{services.NetworkPolicyOwnerKey: "prod/web"}
+	mockService.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{{UUID: "pg-uuid", Name: "np_web", ExternalIDs: owner}}, nil)
+	mockService.On("ListAddressSets", mock.Anything).Return([]*models.AddressSet{}, nil)
+	mockService.On("ListPortGroupACLs", mock.Anything, "pg-uuid").Return([]*models.ACL{}, nil)
+
+	router := gin.New()
+	handler := NewNetworkPolicyHandler(services.NewNetworkPolicyService(mockService, zap.NewNop()))
+	router.GET("/network-policies/:namespace/:name", handler.Get)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/network-policies/prod/web", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"uuid":"pg-uuid"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/network-policies/prod/missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
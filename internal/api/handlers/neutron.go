@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// NeutronMapping maps OVN objects to the Neutron resources they implement.
+// services.NeutronMapper implements it.
+type NeutronMapping interface {
+	Networks(ctx context.Context) ([]services.NeutronNetwork, error)
+	Network(ctx context.Context, id string) (*services.NeutronNetwork, error)
+	Routers(ctx context.Context) ([]services.NeutronRouter, error)
+	Port(ctx context.Context, id string) (*services.NeutronPort, error)
+}
+
+// NeutronHandler serves the OVN objects created by OpenStack Neutron, by
+// Neutron ID
+type NeutronHandler struct {
+	neutron NeutronMapping
+}
+
+// NewNeutronHandler creates a handler
+func NewNeutronHandler(neutron NeutronMapping) *NeutronHandler {
+	return &NeutronHandler{neutron: neutron}
+}
+
+// ListNetworks handles GET /api/v1/integrations/neutron/networks, listing
+// the Neutron networks with their logical switches and ports.
+// ?project_id= limits the ports to one project.
+func (h *NeutronHandler) ListNetworks(c *gin.Context) {
+	networks, err := h.neutron.Networks(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if projectID := c.Query("project_id"); projectID != "" {
+		filtered := make([]services.NeutronNetwork, 0, len(networks))
+		for _, network := range networks {
+			ports := make([]services.NeutronPort, 0, len(network.Ports))
+			for _, port := range network.Ports {
+				if port.ProjectID == projectID {
+					ports = append(ports, port)
+				}
+			}
+			if len(ports) > 0 {
+				network.Ports = ports
+				filtered = append(filtered, network)
+			}
+		}
+		networks = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"networks": networks,
+		"count":    len(networks),
+	})
+}
+
+// GetNetwork handles GET /api/v1/integrations/neutron/networks/:id
+func (h *NeutronHandler) GetNetwork(c *gin.Context) {
+	network, err := h.neutron.Network(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, network)
+}
+
+// ListRouters handles GET /api/v1/integrations/neutron/routers, listing the
+// Neutron routers with their logical routers and interfaces
+func (h *NeutronHandler) ListRouters(c *gin.Context) {
+	routers, err := h.neutron.Routers(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routers": routers,
+		"count":   len(routers),
+	})
+}
+
+// GetPort handles GET /api/v1/integrations/neutron/ports/:id
+func (h *NeutronHandler) GetPort(c *gin.Context) {
+	port, err := h.neutron.Port(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, port)
+}
+
+func (h *NeutronHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNeutronResourceNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestNeutronHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(&services.Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "ls-1", Name: "neutron-net-1", Ports: []string{"lsp-1", "lsp-2"},
+				ExternalIDs: map[string]string{"neutron:network_name": "private"}},
+			{UUID: "ls-2", Name: "manual"},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "lsp-1", Name: "port-1", ExternalIDs: map[string]string{"neutron:project_id": "proj-a"}},
+			{UUID: "lsp-2", Name: "port-2", ExternalIDs: map[string]string{"neutron:project_id": "proj-b"}},
+		},
+		Routers: []*models.LogicalRouter{{UUID: "lr-1", Name: "neutron-router-1"}},
+	}, nil)
+
+	handler := NewNeutronHandler(services.NewNeutronMapper(mockService))
+	router := gin.New()
+	router.GET("/integrations/neutron/networks", handler.ListNetworks)
+	router.GET("/integrations/neutron/networks/:id", handler.GetNetwork)
+	router.GET("/integrations/neutron/routers", handler.ListRouters)
+	router.GET("/integrations/neutron/ports/:id", handler.GetPort)
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := get("/integrations/neutron/networks")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+	network := response["networks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "net-1", network["id"])
+	assert.Len(t, network["ports"], 2)
+
+	w, response = get("/integrations/neutron/networks?project_id=proj-b")
+	require.Equal(t, http.StatusOK, w.Code)
+	network = response["networks"].([]interface{})[0].(map[string]interface{})
+	assert.Len(t, network["ports"], 1)
+	w, response = get("/integrations/neutron/networks?project_id=proj-c")
+	assert.Equal(t, float64(0), response["count"])
+
+	w, response = get("/integrations/neutron/networks/net-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "private", response["name"])
+	w, _ = get("/integrations/neutron/networks/manual")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, response = get("/integrations/neutron/routers")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+
+	w, response = get("/integrations/neutron/ports/port-2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "net-1", response["network_id"])
+	w, _ = get("/integrations/neutron/ports/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// NorthdConsistencyChecker compares the northbound intent to what
+// ovn-northd realized in the southbound database.
+// *services.NorthdConsistencyChecker implements it.
+type NorthdConsistencyChecker interface {
+	Check(ctx context.Context, threshold time.Duration) (*services.NorthdConsistencyReport, error)
+}
+
+type NorthdConsistencyHandler struct {
+	checker NorthdConsistencyChecker
+}
+
+func NewNorthdConsistencyHandler(checker NorthdConsistencyChecker) *NorthdConsistencyHandler {
+	return &NorthdConsistencyHandler{
+		checker: checker,
+	}
+}
+
+// Get reports the switches, routers and ports ovn-northd hasn't realized
+// yet and for how long, and whether it looks stuck: ?threshold= is how long
+// objects may stay pending, 30s by default
+func (h *NorthdConsistencyHandler) Get(c *gin.Context) {
+	threshold := services.DefaultNorthdStuckThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid threshold").
+				WithDetail("threshold must be a positive duration, such as 30s or 5m"))
+			return
+		}
+		threshold = parsed
+	}
+
+	report, err := h.checker.Check(c.Request.Context(), threshold)
+	if err != nil {
+		if strings.Contains(err.Error(), "not connected") || strings.Contains(err.Error(), "southbound") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").
+				WithCode(problem.CodeOVNUnavailable).WithError(err))
+			return
+		}
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
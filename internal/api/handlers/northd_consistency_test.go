@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+type fakeNorthdChecker struct {
+	threshold time.Duration
+	err       error
+}
+
+func (f *fakeNorthdChecker) Check(ctx context.Context, threshold time.Duration) (*services.NorthdConsistencyReport, error) {
+	f.threshold = threshold
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &services.NorthdConsistencyReport{Status: services.NorthdConsistent, ThresholdSeconds: threshold.Seconds()}, nil
+}
+
+func TestNorthdConsistencyHandler_Get(t *testing.T) {
+	checker := &fakeNorthdChecker{}
+	handler := NewNorthdConsistencyHandler(checker)
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/northd-consistency")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.DefaultNorthdStuckThreshold, checker.threshold)
+
+	w = serveCache(t, handler.Get, "GET", "/api/v1/admin/northd-consistency?threshold=2m")
+	require.Equal(t, http.StatusOK, w.Code)
+	var report services.NorthdConsistencyReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, services.NorthdConsistent, report.Status)
+	assert.Equal(t, float64(120), report.ThresholdSeconds)
+
+	w = serveCache(t, handler.Get, "GET", "/api/v1/admin/northd-consistency?threshold=soon")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNorthdConsistencyHandler_SouthboundUnavailable(t *testing.T) {
+	handler := NewNorthdConsistencyHandler(&fakeNorthdChecker{
+		err: errors.New("failed to read the southbound database: connection refused"),
+	})
+
+	w := serveCache(t, handler.Get, "GET", "/api/v1/admin/northd-consistency")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, problem.CodeOVNUnavailable, body["code"])
+}
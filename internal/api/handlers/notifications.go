@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// NotificationSender sends notifications to channels.
+// services.NotificationService implements it.
+type NotificationSender interface {
+	Channels() []*services.NotificationChannel
+	Test(ctx context.Context, name string) error
+}
+
+// NotificationHandler serves the notification channels at
+// /api/v1/notifications
+type NotificationHandler struct {
+	notifications NotificationSender
+}
+
+// NewNotificationHandler creates a handler
+func NewNotificationHandler(notifications NotificationSender) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// Channels handles GET /notifications/channels. Webhook URLs are left out,
+// as they authorize posting to the channel.
+func (h *NotificationHandler) Channels(c *gin.Context) {
+	channels := []gin.H{}
+	for _, channel := range h.notifications.Channels() {
+		channels = append(channels, gin.H{
+			"name":    channel.Name,
+			"type":    channel.Type,
+			"to":      channel.To,
+			"events":  channel.Events,
+			"tenants": channel.Tenants,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"channels": channels,
+		"count":    len(channels),
+	})
+}
+
+// Test handles POST /notifications/channels/:name/test, sending a test
+// message to the channel
+func (h *NotificationHandler) Test(c *gin.Context) {
+	err := h.notifications.Test(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, services.ErrNotificationChannelNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case err != nil:
+		problem.Respond(c, problem.New(http.StatusBadGateway, "failed to send notification").
+			WithError(err))
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+	}
+}
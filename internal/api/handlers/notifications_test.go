@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+type fakeNotificationSender struct {
+	tested []string
+}
+
+func (f *fakeNotificationSender) Channels() []*services.NotificationChannel {
+	return []*services.NotificationChannel{
+		{Name: "ops", Type: services.NotificationSlack, URL: "https://hooks.slack.com/services/secret"},
+		{Name: "broken", Type: services.NotificationTeams, URL: "https://example.com"},
+	}
+}
+
+func (f *fakeNotificationSender) Test(ctx context.Context, name string) error {
+	switch name {
+	case "ops":
+		f.tested = append(f.tested, name)
+		return nil
+	case "broken":
+		return errors.New("unexpected status 403 Forbidden")
+	}
+	return services.ErrNotificationChannelNotFound
+}
+
+func TestNotificationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sender := &fakeNotificationSender{}
+	handler := NewNotificationHandler(sender)
+	engine := gin.New()
+	engine.GET("/notifications/channels", handler.Channels)
+	engine.POST("/notifications/channels/:name/test", handler.Test)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := serve("GET", "/notifications/channels")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":2`)
+	assert.NotContains(t, w.Body.String(), "secret", "webhook URLs are left out")
+
+	assert.Equal(t, http.StatusOK, serve("POST", "/notifications/channels/ops/test").Code)
+	assert.Equal(t, []string{"ops"}, sender.tested)
+	assert.Equal(t, http.StatusBadGateway, serve("POST", "/notifications/channels/broken/test").Code)
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/notifications/channels/missing/test").Code)
+}
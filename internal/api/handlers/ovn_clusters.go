@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// OVNClusterHeader selects the OVN cluster for routes outside /clusters/:name
+const OVNClusterHeader = "X-OVN-Cluster"
+
+type OVNClusterHandler struct {
+	clusters *services.OVNClusterManager
+}
+
+func NewOVNClusterHandler(clusters *services.OVNClusterManager) *OVNClusterHandler {
+	return &OVNClusterHandler{
+		clusters: clusters,
+	}
+}
+
+func (h *OVNClusterHandler) List(c *gin.Context) {
+	clusters := h.clusters.List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"clusters": clusters,
+		"count":    len(clusters),
+	})
+}
+
+func (h *OVNClusterHandler) Get(c *gin.Context) {
+	info, ok := h.clusters.Info(c.Param("name"))
+	if !ok {
+		problem.Respond(c, problem.New(http.StatusNotFound, "cluster not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// Health reports whether the cluster's northbound connection is up, returning
+// 503 while it is down so load balancers can route around the region
+func (h *OVNClusterHandler) Health(c *gin.Context) {
+	info, ok := h.clusters.Info(c.Param("name"))
+	if !ok {
+		problem.Respond(c, problem.New(http.StatusNotFound, "cluster not found"))
+		return
+	}
+
+	status := http.StatusOK
+	health := "healthy"
+	if !info.Status.Connected {
+		status = http.StatusServiceUnavailable
+		health = "unhealthy"
+	}
+
+	c.JSON(status, gin.H{
+		"cluster": info.Name,
+		"status":  health,
+		"ovn":     info.Status,
+	})
+}
+
+// SelectCluster stores the cluster named in the path or the X-OVN-Cluster
+// header in the request context, so OVN calls go to that cluster
+func (h *OVNClusterHandler) SelectCluster(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		name = c.GetHeader(OVNClusterHeader)
+	}
+	if name == "" {
+		c.Next()
+		return
+	}
+
+	if _, ok := h.clusters.Get(name); !ok {
+		problem.Respond(c, problem.New(http.StatusNotFound, "cluster not found"))
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(services.ContextWithOVNCluster(c.Request.Context(), name))
+	c.Next()
+}
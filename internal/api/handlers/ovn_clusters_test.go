@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// newTestClusterManager returns a manager with two clusters whose endpoints
+// refuse connections
+func newTestClusterManager(t *testing.T) *services.OVNClusterManager {
+	manager := services.NewOVNClusterManager(zap.NewNop())
+	t.Cleanup(manager.Close)
+
+	for _, name := range []string{"us-east", "eu-west"} {
+		cfg := &config.OVNConfig{
+			ClusterName:  name,
+			NorthboundDB: "tcp:127.0.0.1:1",
+			Timeout:      100 * time.Millisecond,
+		}
+		require.NoError(t, manager.Add(cfg))
+	}
+
+	return manager
+}
+
+func TestOVNClusterHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOVNClusterHandler(newTestClusterManager(t))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/clusters", nil)
+
+	handler.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Clusters []services.OVNClusterInfo `json:"clusters"`
+		Count    int                       `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "us-east", response.Clusters[0].Name)
+	assert.True(t, response.Clusters[0].Default)
+	assert.Equal(t, "eu-west", response.Clusters[1].Name)
+	assert.False(t, response.Clusters[1].Default)
+}
+
+func TestOVNClusterHandler_Health(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOVNClusterHandler(newTestClusterManager(t))
+
+	tests := []struct {
+		name           string
+		cluster        string
+		expectedStatus int
+	}{
+		{
+			name:           "disconnected cluster",
+			cluster:        "eu-west",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "unknown cluster",
+			cluster:        "ap-south",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/clusters/"+tt.cluster+"/health", nil)
+			c.Params = gin.Params{{Key: "name", Value: tt.cluster}}
+
+			handler.Health(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestOVNClusterHandler_SelectCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOVNClusterHandler(newTestClusterManager(t))
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		expectedStatus int
+	}{
+		{
+			name:           "path selector",
+			path:           "/clusters/eu-west/switches",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "header selector",
+			path:           "/switches",
+			header:         "eu-west",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no selector uses default",
+			path:           "/switches",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown cluster in path",
+			path:           "/clusters/ap-south/switches",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown cluster in header",
+			path:           "/switches",
+			header:         "ap-south",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/clusters/:name/switches", handler.SelectCluster, ok)
+			router.GET("/switches", handler.SelectCluster, ok)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(OVNClusterHeader, tt.header)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
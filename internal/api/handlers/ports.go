@@ -1,241 +1,487 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
 )
 
+// AddressConflictChecker finds the objects a port's addresses are already
+// assigned to. services.AddressValidator implements it.
+type AddressConflictChecker interface {
+	CheckPort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) ([]services.AddressConflict, error)
+}
+
+// MACAssigner generates the MACs of "auto" port addresses.
+// services.MACAllocator implements it.
+type MACAssigner interface {
+	AssignMACs(ctx context.Context, switchID string, port *models.LogicalSwitchPort) error
+}
+
+// PortBindingReader reads the southbound state of logical ports, keyed by
+// port name. ovn.ChassisInventory implements it.
+type PortBindingReader interface {
+	PortBindings(ctx context.Context, ports []string) (map[string]*models.PortBinding, error)
+}
+
 type PortHandler struct {
 	ovnService services.OVNServiceInterface
+	addresses  AddressConflictChecker
+	macs       MACAssigner
+	bindings   PortBindingReader
+	trash      ResourceTrash // nil deletes resources for good
 }
 
-func NewPortHandler(ovnService services.OVNServiceInterface) *PortHandler {
+// NewPortHandler creates a handler. addresses may be nil, which skips
+// checking new addresses for duplicates, macs may be nil, which rejects
+// "auto" addresses, and bindings may be nil, which rejects
+// ?include=binding.
+func NewPortHandler(ovnService services.OVNServiceInterface, addresses AddressConflictChecker, macs MACAssigner, bindings PortBindingReader) *PortHandler {
 	return &PortHandler{
 		ovnService: ovnService,
+		addresses:  addresses,
+		macs:       macs,
+		bindings:   bindings,
 	}
 }
 
+// SetRecycleBin soft-deletes resources into trash
+func (h *PortHandler) SetRecycleBin(trash ResourceTrash) {
+	h.trash = trash
+}
+
+// List handles GET /api/v1/switches/:id/ports. With ?include=binding each
+// port carries its southbound binding.
 func (h *PortHandler) List(c *gin.Context) {
-	switchID := c.Param("switchId")
+	switchID := switchIDParam(c)
 	if switchID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch ID is required"))
 		return
 	}
 
-	ports, err := h.ovnService.ListPorts(c.Request.Context(), switchID)
+	withBindings, ok := h.parseInclude(c)
+	if !ok {
+		return
+	}
+
+	opts, page, err := parseListOptions(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	stream := streamOptions(c, opts)
+	ports, total, err := h.ovnService.ListPortsPage(c.Request.Context(), switchID, opts)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "switch not found"})
+			problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
+			return
+		}
+		if strings.Contains(err.Error(), "unsupported sort field") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithError(err))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
+	if withBindings {
+		if ports, ok = h.withBindings(c, ports); !ok {
+			return
+		}
+	}
+
+	if stream {
+		streamNDJSON(c, ports, total, opts, func(opts *models.ListOptions) ([]*models.LogicalSwitchPort, error) {
+			ports, _, err := h.ovnService.ListPortsPage(c.Request.Context(), switchID, opts)
+			if err != nil || !withBindings {
+				return ports, err
+			}
+			return h.bindPorts(c.Request.Context(), ports)
+		})
+		return
+	}
+
+	items, ok := selectFields(c, ports)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"ports": ports,
-		"count": len(ports),
+		"ports":      items,
+		"count":      len(ports),
+		"pagination": pagination.Response(c, page, total),
 	})
 }
 
+// Get handles GET /api/v1/ports/:id. With ?include=binding the port
+// carries its southbound binding, which doesn't change its ETag.
 func (h *PortHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "port ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "port ID is required"))
+		return
+	}
+
+	withBindings, ok := h.parseInclude(c)
+	if !ok {
 		return
 	}
 	
 	port, err := h.ovnService.GetPort(c.Request.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, port)
+	if withBindings {
+		ports, ok := h.withBindings(c, []*models.LogicalSwitchPort{port})
+		if !ok {
+			return
+		}
+		port = ports[0]
+	}
+
+	respondWithETag(c, http.StatusOK, port)
 }
 
 func (h *PortHandler) Create(c *gin.Context) {
-	switchID := c.Param("switchId")
+	switchID := switchIDParam(c)
 	if switchID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch ID is required"))
 		return
 	}
 
 	var port models.LogicalSwitchPort
 	if err := c.ShouldBindJSON(&port); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate required fields
 	if port.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name is required",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "required", "name is required"))
 		return
 	}
 
 	// Validate name format
 	if !isValidName(port.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
 	// Validate addresses if provided
 	if len(port.Addresses) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "at least one address is required",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("addresses", "required", "at least one address is required"))
 		return
 	}
 
 	// Validate addresses format
 	for _, addr := range port.Addresses {
 		if !isValidAddress(addr) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "invalid address format: " + addr,
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("addresses", "format", "invalid address format: " + addr))
 			return
 		}
 	}
 
 	// Validate port type if provided
 	if port.Type != "" && !isValidPortType(port.Type) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "invalid port type: " + port.Type,
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("type", "oneof", "invalid port type: " + port.Type))
+		return
+	}
+
+	if !h.assignMACs(c, switchID, &port) {
+		return
+	}
+
+	if !h.checkAddresses(c.Request.Context(), c, switchID, &port) {
 		return
 	}
 
 	created, err := h.ovnService.CreatePort(c.Request.Context(), switchID, &port)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
 			return
 		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "switch not found"})
+			problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, created)
+	respondWithETag(c, http.StatusCreated, created)
 }
 
 func (h *PortHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "port ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "port ID is required"))
 		return
 	}
 	
 	var port models.LogicalSwitchPort
 	if err := c.ShouldBindJSON(&port); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate name if provided
 	if port.Name != "" && !isValidName(port.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
 	// Validate addresses if provided
 	for _, addr := range port.Addresses {
 		if !isValidAddress(addr) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "invalid address format: " + addr,
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("addresses", "format", "invalid address format: " + addr))
+			return
+		}
+		if isAutoAddress(addr) {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithFieldError("addresses", "format", "auto addresses are only assigned when ports are created"))
 			return
 		}
 	}
 
 	// Validate port type if provided
 	if port.Type != "" && !isValidPortType(port.Type) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "invalid port type: " + port.Type,
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("type", "oneof", "invalid port type: " + port.Type))
 		return
 	}
 
-	updated, err := h.ovnService.UpdatePort(c.Request.Context(), id, &port)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetPort(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	// Addresses are only replaced when given
+	if len(port.Addresses) > 0 {
+		candidate := port
+		candidate.UUID = id
+		if !h.checkAddresses(ctx, c, "", &candidate) {
+			return
+		}
+	}
+
+	updated, err := h.ovnService.UpdatePort(ctx, id, &port)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	respondWithETag(c, http.StatusOK, updated)
 }
 
 func (h *PortHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "port ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "port ID is required"))
 		return
 	}
 	
-	err := h.ovnService.DeletePort(c.Request.Context(), id)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetPort(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	trashed, err := deleteResource(ctx, h.trash, models.ResourcePort, id, h.ovnService.DeletePort)
 	if err != nil {
+		if respondNotRecyclable(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	respondDeleted(c, trashed)
+}
+
+// assignMACs replaces "auto" MACs in the port's addresses with generated
+// ones, responding with an error if they can't be, and reports whether the
+// port may be created
+func (h *PortHandler) assignMACs(c *gin.Context, switchID string, port *models.LogicalSwitchPort) bool {
+	auto := false
+	for _, addr := range port.Addresses {
+		auto = auto || isAutoAddress(addr)
+	}
+	if !auto {
+		return true
+	}
+
+	var err error
+	if h.macs == nil {
+		err = fmt.Errorf("%w: MAC generation is not enabled", services.ErrNoMACPool)
+	} else {
+		err = h.macs.AssignMACs(c.Request.Context(), switchID, port)
+	}
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrNoMACPool):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+	case errors.Is(err, services.ErrMACPoolExhausted):
+		problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "switch not found"))
+	default:
+		h.handleError(c, err)
+	}
+	return false
+}
+
+// checkAddresses responds with a conflict if the port's addresses are
+// assigned to other objects, reporting whether it may be written
+func (h *PortHandler) checkAddresses(ctx context.Context, c *gin.Context, switchID string, port *models.LogicalSwitchPort) bool {
+	if h.addresses == nil {
+		return true
+	}
+
+	conflicts, err := h.addresses.CheckPort(ctx, switchID, port)
+	if err != nil {
+		h.handleError(c, err)
+		return false
+	}
+	if len(conflicts) > 0 {
+		problem.Respond(c, problem.New(http.StatusConflict, "address conflict").
+			WithDetail("addresses are already assigned to other objects").
+			With("conflicts", conflicts))
+		return false
+	}
+	return true
+}
+
+// parseInclude reads ?include=, a comma-separated list of what to add to
+// ports; only "binding" is supported. It returns false, with the response
+// written, if the list is invalid.
+func (h *PortHandler) parseInclude(c *gin.Context) (bool, bool) {
+	include := c.Query("include")
+	if include == "" {
+		return false, true
+	}
+
+	for _, item := range strings.Split(include, ",") {
+		if strings.TrimSpace(item) != "binding" {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithDetail(fmt.Sprintf("unsupported include %q; supported: binding", strings.TrimSpace(item))))
+			return false, false
+		}
+	}
+	if h.bindings == nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithDetail("port bindings are not available"))
+		return false, false
+	}
+	return true, true
+}
+
+// withBindings returns copies of the ports carrying their southbound
+// bindings, leaving the ports, which may be shared with a cache, as they
+// are. It returns false, with the response written, if the bindings can't
+// be read.
+func (h *PortHandler) withBindings(c *gin.Context, ports []*models.LogicalSwitchPort) ([]*models.LogicalSwitchPort, bool) {
+	bound, err := h.bindPorts(c.Request.Context(), ports)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to connect") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithDetail("unable to connect to OVN southbound database"))
+			return nil, false
+		}
+		h.handleError(c, err)
+		return nil, false
+	}
+	return bound, true
+}
+
+// bindPorts returns copies of ports carrying their southbound bindings
+func (h *PortHandler) bindPorts(ctx context.Context, ports []*models.LogicalSwitchPort) ([]*models.LogicalSwitchPort, error) {
+	names := make([]string, len(ports))
+	for i, port := range ports {
+		names[i] = port.Name
+	}
+
+	bindings, err := h.bindings.PortBindings(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.LogicalSwitchPort, len(ports))
+	for i, port := range ports {
+		bound := *port
+		bound.Binding = bindings[port.Name]
+		result[i] = &bound
+	}
+	return result, nil
 }
 
 // handleError handles generic errors
 func (h *PortHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	// Too many operations are already queued for OVN
+	if respondBackpressure(c, err) {
+		return
+	}
+
 	// Check if client is not connected
 	if strings.Contains(err.Error(), "not connected") {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "OVN service unavailable",
-			"details": "unable to connect to OVN northbound database",
-		})
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
 		return
 	}
 
 	// Default error response
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error": "internal server error",
-		"details": err.Error(),
-	})
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
 }
 
 // isValidAddress validates address formats (MAC or "dynamic")
@@ -245,6 +491,11 @@ func isValidAddress(addr string) bool {
 		return true
 	}
 
+	// Allow "auto", whose MAC is generated from a MAC pool
+	if addr == services.AutoAddress {
+		return true
+	}
+
 	// Allow "unknown" as a special address
 	if addr == "unknown" {
 		return true
@@ -272,6 +523,12 @@ func isValidAddress(addr string) bool {
 	return strings.Contains(addr, ".") || strings.Contains(addr, ":")
 }
 
+// isAutoAddress reports whether an address's MAC is to be generated
+func isAutoAddress(addr string) bool {
+	fields := strings.Fields(addr)
+	return len(fields) > 0 && fields[0] == services.AutoAddress
+}
+
 // isValidPortType validates OVN port types
 func isValidPortType(portType string) bool {
 	validTypes := []string{
@@ -290,4 +547,13 @@ func isValidPortType(portType string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// switchIDParam returns the parent switch ID. Port routes are nested under
+// /switches/:id, sharing the wildcard name with the switch routes.
+func switchIDParam(c *gin.Context) string {
+	if id := c.Param("switchId"); id != "" {
+		return id
+	}
+	return c.Param("id")
+}
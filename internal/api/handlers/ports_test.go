@@ -2,18 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
 )
 
 func TestPortHandler_List(t *testing.T) {
@@ -87,10 +91,10 @@ func TestPortHandler_List(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockOVNService)
-			handler := NewPortHandler(mockService)
+			handler := NewPortHandler(mockService, nil, nil, nil)
 
 			if tt.switchID != "" {
-				mockService.On("ListPorts", mock.Anything, tt.switchID).Return(tt.mockReturn, tt.mockError)
+				mockService.On("ListPortsPage", mock.Anything, tt.switchID, mock.Anything).Return(tt.mockReturn, len(tt.mockReturn), tt.mockError)
 			}
 
 			w := httptest.NewRecorder()
@@ -159,7 +163,7 @@ func TestPortHandler_Get(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockOVNService)
-			handler := NewPortHandler(mockService)
+			handler := NewPortHandler(mockService, nil, nil, nil)
 
 			if tt.portID != "" {
 				mockService.On("GetPort", mock.Anything, tt.portID).Return(tt.mockReturn, tt.mockError)
@@ -309,7 +313,7 @@ func TestPortHandler_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockOVNService)
-			handler := NewPortHandler(mockService)
+			handler := NewPortHandler(mockService, nil, nil, nil)
 
 			body, _ := json.Marshal(tt.requestBody)
 			
@@ -420,7 +424,7 @@ func TestPortHandler_Update(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockOVNService)
-			handler := NewPortHandler(mockService)
+			handler := NewPortHandler(mockService, nil, nil, nil)
 
 			body, _ := json.Marshal(tt.requestBody)
 
@@ -484,7 +488,7 @@ func TestPortHandler_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := new(MockOVNService)
-			handler := NewPortHandler(mockService)
+			handler := NewPortHandler(mockService, nil, nil, nil)
 
 			if tt.portID != "" {
 				mockService.On("DeletePort", mock.Anything, tt.portID).Return(tt.mockError)
@@ -504,4 +508,148 @@ func TestPortHandler_Delete(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestPortHandler_CreateAddressConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(&services.Topology{
+		Switches: []*models.LogicalSwitch{{UUID: "switch-uuid", Ports: []string{"port-1"}}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "port-1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.10"}},
+		},
+	}, nil)
+	handler := NewPortHandler(mockService, services.NewAddressValidator(mockService), nil, nil)
+
+	create := func(address string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"name": "web-2", "addresses": []string{address}})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/switches/switch-uuid/ports", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "switchId", Value: "switch-uuid"}}
+		handler.Create(c)
+		return w
+	}
+
+	w := create("00:00:00:00:00:02 10.0.0.10")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response struct {
+		Conflicts []services.AddressConflict `json:"conflicts"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Conflicts, 1) {
+		assert.Equal(t, "10.0.0.10", response.Conflicts[0].Address)
+		assert.Equal(t, "port-1", response.Conflicts[0].Objects[1].UUID)
+	}
+	mockService.AssertNotCalled(t, "CreatePort", mock.Anything, mock.Anything, mock.Anything)
+
+	mockService.On("CreatePort", mock.Anything, "switch-uuid", mock.Anything).
+		Return(&models.LogicalSwitchPort{UUID: "port-2", Name: "web-2"}, nil)
+	assert.Equal(t, http.StatusCreated, create("00:00:00:00:00:02 10.0.0.11").Code)
+}
+
+// fakeMACAssigner assigns the same MAC to every "auto" address
+type fakeMACAssigner struct{}
+
+func (fakeMACAssigner) AssignMACs(ctx context.Context, switchID string, port *models.LogicalSwitchPort) error {
+	for i, addr := range port.Addresses {
+		port.Addresses[i] = strings.Replace(addr, services.AutoAddress, "0a:58:a9:00:00:01", 1)
+	}
+	return nil
+}
+
+func TestPortHandler_CreateAutoAddress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	create := func(handler *PortHandler) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"name": "web-1", "addresses": []string{"auto 10.0.0.10"}})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/switches/switch-uuid/ports", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "switchId", Value: "switch-uuid"}}
+		handler.Create(c)
+		return w
+	}
+
+	// Without MAC pools, auto addresses are rejected
+	assert.Equal(t, http.StatusBadRequest, create(NewPortHandler(new(MockOVNService), nil, nil, nil)).Code)
+
+	mockService := new(MockOVNService)
+	mockService.On("CreatePort", mock.Anything, "switch-uuid", mock.MatchedBy(func(port *models.LogicalSwitchPort) bool {
+		return len(port.Addresses) == 1 && port.Addresses[0] == "0a:58:a9:00:00:01 10.0.0.10"
+	})).Return(&models.LogicalSwitchPort{UUID: "port-1", Name: "web-1"}, nil)
+	assert.Equal(t, http.StatusCreated, create(NewPortHandler(mockService, nil, fakeMACAssigner{}, nil)).Code)
+	mockService.AssertExpectations(t)
+}
+
+type fakePortBindingReader struct {
+	bindings map[string]*models.PortBinding
+	err      error
+}
+
+func (f *fakePortBindingReader) PortBindings(ctx context.Context, ports []string) (map[string]*models.PortBinding, error) {
+	return f.bindings, f.err
+}
+
+func TestPortHandler_IncludeBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	port := &models.LogicalSwitchPort{UUID: "port-uuid", Name: "vm-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.10"}}
+	bound := map[string]*models.PortBinding{
+		"vm-1": {Status: models.PortBindingUp, Chassis: "hv-1", ChassisHostname: "hv-1.example.com", Up: true, TunnelKey: 3},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		bindings       PortBindingReader
+		expectedStatus int
+		expectBinding  bool
+	}{
+		{name: "without include", bindings: &fakePortBindingReader{bindings: bound}, expectedStatus: http.StatusOK},
+		{name: "with binding", query: "?include=binding", bindings: &fakePortBindingReader{bindings: bound}, expectedStatus: http.StatusOK, expectBinding: true},
+		{name: "unsupported include", query: "?include=stats", bindings: &fakePortBindingReader{bindings: bound}, expectedStatus: http.StatusBadRequest},
+		{name: "bindings not available", query: "?include=binding", expectedStatus: http.StatusBadRequest},
+		{
+			name:           "southbound unreachable",
+			query:          "?include=binding",
+			bindings:       &fakePortBindingReader{err: errors.New("failed to connect to OVN southbound DB: connection refused")},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewPortHandler(mockService, nil, nil, tt.bindings)
+			mockService.On("GetPort", mock.Anything, "port-uuid").Return(port, nil).Maybe()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/ports/port-uuid"+tt.query, nil)
+			c.Params = gin.Params{{Key: "id", Value: "port-uuid"}}
+
+			handler.Get(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var response models.LogicalSwitchPort
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectBinding {
+				require.NotNil(t, response.Binding)
+				assert.Equal(t, "hv-1", response.Binding.Chassis)
+				assert.Equal(t, 3, response.Binding.TunnelKey)
+			} else {
+				assert.Nil(t, response.Binding)
+			}
+			// The binding isn't part of the port's configuration
+			etag, _ := services.ResourceETag(port)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Nil(t, port.Binding)
+		})
+	}
+}
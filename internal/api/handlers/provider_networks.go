@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ProviderNetworks manages provider networks and the routers attached to
+// them. *services.ProviderNetworkService implements it.
+type ProviderNetworks interface {
+	Create(ctx context.Context, req *services.CreateProviderNetworkRequest) (*models.ProviderNetwork, error)
+	List(ctx context.Context) ([]*models.ProviderNetwork, error)
+	Get(ctx context.Context, id string) (*models.ProviderNetwork, error)
+	Delete(ctx context.Context, id string) error
+	AttachRouter(ctx context.Context, id string, req *services.AttachRouterRequest) (*models.ProviderNetwork, error)
+	DetachRouter(ctx context.Context, id, routerID string) (*models.ProviderNetwork, error)
+}
+
+// ProviderNetworkHandler serves provider networks at
+// /api/v1/provider-networks
+type ProviderNetworkHandler struct {
+	networks ProviderNetworks
+}
+
+func NewProviderNetworkHandler(networks ProviderNetworks) *ProviderNetworkHandler {
+	return &ProviderNetworkHandler{networks: networks}
+}
+
+// List handles GET /provider-networks
+func (h *ProviderNetworkHandler) List(c *gin.Context) {
+	networks, err := h.networks.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider_networks": networks,
+		"count":             len(networks),
+	})
+}
+
+// Get handles GET /provider-networks/:id
+func (h *ProviderNetworkHandler) Get(c *gin.Context) {
+	network, err := h.networks.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, network)
+}
+
+// Create handles POST /provider-networks
+func (h *ProviderNetworkHandler) Create(c *gin.Context) {
+	var req services.CreateProviderNetworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	network, err := h.networks.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, network)
+}
+
+// Delete handles DELETE /provider-networks/:id
+func (h *ProviderNetworkHandler) Delete(c *gin.Context) {
+	if err := h.networks.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AttachRouter handles POST /provider-networks/:id/routers
+func (h *ProviderNetworkHandler) AttachRouter(c *gin.Context) {
+	var req services.AttachRouterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	network, err := h.networks.AttachRouter(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, network)
+}
+
+// DetachRouter handles DELETE /provider-networks/:id/routers/:router_id
+func (h *ProviderNetworkHandler) DetachRouter(c *gin.Context) {
+	network, err := h.networks.DetachRouter(c.Request.Context(), c.Param("id"), c.Param("router_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, network)
+}
+
+func (h *ProviderNetworkHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrProviderNetworkNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "provider network not found").
+			WithError(err))
+	case errors.Is(err, services.ErrInvalidProviderNetwork):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid provider network").
+			WithError(err))
+	case errors.Is(err, services.ErrBridgeMappingMissing):
+		problem.Respond(c, problem.New(http.StatusUnprocessableEntity, "bridge mapping missing").
+			WithError(err))
+	case errors.Is(err, services.ErrProviderNetworkConflict), strings.Contains(err.Error(), "already exists"):
+		problem.Respond(c, problem.New(http.StatusConflict, "provider network conflict").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeProviderNetworks keeps provider networks by name, with chassis
+// mapping physnet1 only
+type fakeProviderNetworks struct {
+	networks map[string]*models.ProviderNetwork
+}
+
+func (f *fakeProviderNetworks) Create(ctx context.Context, req *services.CreateProviderNetworkRequest) (*models.ProviderNetwork, error) {
+	if req.PhysicalNetwork != "physnet1" {
+		return nil, services.ErrBridgeMappingMissing
+	}
+	network := &models.ProviderNetwork{ID: "sw-" + req.Name, Name: req.Name, PhysicalNetwork: req.PhysicalNetwork, VLAN: req.VLAN}
+	f.networks[req.Name] = network
+	return network, nil
+}
+
+func (f *fakeProviderNetworks) List(ctx context.Context) ([]*models.ProviderNetwork, error) {
+	networks := []*models.ProviderNetwork{}
+	for _, network := range f.networks {
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (f *fakeProviderNetworks) Get(ctx context.Context, id string) (*models.ProviderNetwork, error) {
+	network, ok := f.networks[id]
+	if !ok {
+		return nil, services.ErrProviderNetworkNotFound
+	}
+	return network, nil
+}
+
+func (f *fakeProviderNetworks) Delete(ctx context.Context, id string) error {
+	network, err := f.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(network.Routers) > 0 {
+		return services.ErrProviderNetworkConflict
+	}
+	delete(f.networks, id)
+	return nil
+}
+
+func (f *fakeProviderNetworks) AttachRouter(ctx context.Context, id string, req *services.AttachRouterRequest) (*models.ProviderNetwork, error) {
+	network, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	network.Routers = append(network.Routers, &models.ProviderNetworkRouter{RouterID: req.RouterID, Networks: req.Networks})
+	return network, nil
+}
+
+func (f *fakeProviderNetworks) DetachRouter(ctx context.Context, id, routerID string) (*models.ProviderNetwork, error) {
+	network, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	network.Routers = nil
+	return network, nil
+}
+
+func TestProviderNetworkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewProviderNetworkHandler(&fakeProviderNetworks{networks: map[string]*models.ProviderNetwork{}})
+	router := gin.New()
+	router.GET("/provider-networks", handler.List)
+	router.GET("/provider-networks/:id", handler.Get)
+	router.POST("/provider-networks", handler.Create)
+	router.DELETE("/provider-networks/:id", handler.Delete)
+	router.POST("/provider-networks/:id/routers", handler.AttachRouter)
+	router.DELETE("/provider-networks/:id/routers/:router_id", handler.DetachRouter)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/provider-networks", `{"name": "public"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "physical_network is required")
+	w = serve(http.MethodPost, "/provider-networks", `{"name": "public", "physical_network": "physnet2"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(http.MethodPost, "/provider-networks", `{"name": "public", "physical_network": "physnet1", "vlan": 100}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"vlan":100`)
+
+	w = serve(http.MethodPost, "/provider-networks/public/routers", `{"router_id": "edge"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "networks are required")
+	w = serve(http.MethodPost, "/provider-networks/public/routers", `{"router_id": "edge", "networks": ["203.0.113.2/24"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"router_id":"edge"`)
+
+	w = serve(http.MethodDelete, "/provider-networks/public", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(http.MethodDelete, "/provider-networks/public/routers/edge", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(http.MethodDelete, "/provider-networks/public", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodGet, "/provider-networks/public", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
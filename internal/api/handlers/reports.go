@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// defaultIdleDays is how long resources must have been unused to be
+// reported by default
+const defaultIdleDays = 30
+
+// UnusedResourceReporter reports unused resources. services.UnusedResourceReporter
+// implements it.
+type UnusedResourceReporter interface {
+	Report(ctx context.Context, idleDays int, cleanup bool) (*services.UnusedResourceReport, error)
+}
+
+// SecurityReporter scores the security posture of switches.
+// services.SecurityReporter implements it.
+type SecurityReporter interface {
+	Report(ctx context.Context) (*services.SecurityReport, error)
+}
+
+// ReportHandler serves analysis reports over OVN resources
+type ReportHandler struct {
+	unused   UnusedResourceReporter
+	security SecurityReporter
+}
+
+// NewReportHandler creates a handler
+func NewReportHandler(unused UnusedResourceReporter, security SecurityReporter) *ReportHandler {
+	return &ReportHandler{unused: unused, security: security}
+}
+
+// Unused handles GET /api/v1/reports/unused?days=30&cleanup=true, listing
+// switches without ports, ports unbound for days, ACLs matching no traffic
+// for days and address sets no ACL refers to. With cleanup, transactions
+// deleting them are included for review; nothing is deleted.
+func (h *ReportHandler) Unused(c *gin.Context) {
+	days := defaultIdleDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "days must be an integer"))
+			return
+		}
+		days = parsed
+	}
+
+	report, err := h.unused.Report(c.Request.Context(), days, c.Query("cleanup") == "true")
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Security handles GET /api/v1/reports/security, scoring each switch and
+// tenant out of 100 on missing default-deny ACLs, allows from any address,
+// cleartext management ports exposed and unlogged denies, with
+// recommendations
+func (h *ReportHandler) Security(c *gin.Context) {
+	report, err := h.security.Report(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ReportHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidReportQuery):
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeUnusedReporter records the query it's asked for
+type fakeUnusedReporter struct {
+	days    int
+	cleanup bool
+	err     error
+}
+
+func (f *fakeUnusedReporter) Report(ctx context.Context, idleDays int, cleanup bool) (*services.UnusedResourceReport, error) {
+	f.days, f.cleanup = idleDays, cleanup
+	if f.err != nil {
+		return nil, f.err
+	}
+	if idleDays < 1 {
+		return nil, fmt.Errorf("%w: days must be at least 1", services.ErrInvalidReportQuery)
+	}
+	return &services.UnusedResourceReport{IdleDays: idleDays}, nil
+}
+
+func serveUnusedReport(handler *ReportHandler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/reports/unused"+query, nil)
+	handler.Unused(c)
+	return w
+}
+
+func TestReportHandler_Unused(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reporter := &fakeUnusedReporter{}
+	handler := NewReportHandler(reporter, &fakeSecurityReporter{})
+
+	assert.Equal(t, http.StatusOK, serveUnusedReport(handler, "").Code)
+	assert.Equal(t, defaultIdleDays, reporter.days)
+	assert.False(t, reporter.cleanup)
+
+	assert.Equal(t, http.StatusOK, serveUnusedReport(handler, "?days=7&cleanup=true").Code)
+	assert.Equal(t, 7, reporter.days)
+	assert.True(t, reporter.cleanup)
+
+	assert.Equal(t, http.StatusBadRequest, serveUnusedReport(handler, "?days=week").Code)
+	assert.Equal(t, http.StatusBadRequest, serveUnusedReport(handler, "?days=0").Code)
+
+	reporter.err = errors.New("client not connected")
+	assert.Equal(t, http.StatusServiceUnavailable, serveUnusedReport(handler, "").Code)
+}
+
+// fakeSecurityReporter returns a report with one switch, or its error
+type fakeSecurityReporter struct {
+	err error
+}
+
+func (f *fakeSecurityReporter) Report(ctx context.Context) (*services.SecurityReport, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &services.SecurityReport{
+		Score:    75,
+		Switches: []services.SwitchSecurity{{SwitchID: "sw-1", Score: 75}},
+	}, nil
+}
+
+func TestReportHandler_Security(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reporter := &fakeSecurityReporter{}
+	handler := NewReportHandler(&fakeUnusedReporter{}, reporter)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/reports/security", nil)
+		handler.Security(c)
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"score":75`)
+
+	reporter.err = errors.New("client not connected")
+	assert.Equal(t, http.StatusServiceUnavailable, serve().Code)
+}
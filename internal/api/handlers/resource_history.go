@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ResourceChanges lists the recorded changes of resources.
+// services.ResourceHistory implements it.
+type ResourceChanges interface {
+	History(ctx context.Context, resourceID string) ([]*models.ResourceChange, error)
+}
+
+// ResourceHistoryHandler serves the change history of resources
+type ResourceHistoryHandler struct {
+	history ResourceChanges
+}
+
+// NewResourceHistoryHandler creates a handler
+func NewResourceHistoryHandler(history ResourceChanges) *ResourceHistoryHandler {
+	return &ResourceHistoryHandler{history: history}
+}
+
+// History handles GET /api/v1/resources/:uuid/history, listing who created,
+// updated and deleted the resource, when and through what client, oldest
+// first
+func (h *ResourceHistoryHandler) History(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	resourceID := c.Param("uuid")
+	changes, err := h.history.History(c.Request.Context(), resourceID)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return
+	}
+
+	pageItems := pagination.Slice(changes, page)
+	c.JSON(http.StatusOK, gin.H{
+		"resource_id": resourceID,
+		"changes":     pageItems,
+		"count":       len(pageItems),
+		"pagination":  pagination.Response(c, page, len(changes)),
+	})
+}
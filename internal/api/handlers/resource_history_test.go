@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+type fakeResourceChanges struct {
+	changes map[string][]*models.ResourceChange
+	err     error
+}
+
+func (f *fakeResourceChanges) History(ctx context.Context, resourceID string) ([]*models.ResourceChange, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	changes := f.changes[resourceID]
+	if changes == nil {
+		changes = []*models.ResourceChange{}
+	}
+	return changes, nil
+}
+
+func TestResourceHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	history := &fakeResourceChanges{changes: map[string][]*models.ResourceChange{
+		"sw-1": {
+			{ID: "1", ResourceID: "sw-1", Resource: "switches", Action: "created", UserID: "alice", Source: "terraform", OccurredAt: at},
+			{ID: "2", ResourceID: "sw-1", Resource: "switches", Action: "updated", UserID: "bob", Source: "api", OccurredAt: at.Add(time.Hour)},
+		},
+	}}
+	router := gin.New()
+	router.GET("/resources/:uuid/history", NewResourceHistoryHandler(history).History)
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := get("/resources/sw-1/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "sw-1", response["resource_id"])
+	assert.Equal(t, float64(2), response["count"])
+	changes := response["changes"].([]interface{})
+	first := changes[0].(map[string]interface{})
+	assert.Equal(t, "created", first["action"])
+	assert.Equal(t, "alice", first["user_id"])
+	assert.Equal(t, "terraform", first["source"])
+
+	w, response = get("/resources/unknown/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(0), response["count"])
+	assert.Empty(t, response["changes"])
+
+	history.err = errors.New("database down")
+	w, _ = get("/resources/sw-1/history")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// RouterPolicyHandler manages the policy-based routing rules of logical
+// routers
+type RouterPolicyHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewRouterPolicyHandler creates a handler
+func NewRouterPolicyHandler(ovnService services.OVNServiceInterface) *RouterPolicyHandler {
+	return &RouterPolicyHandler{
+		ovnService: ovnService,
+	}
+}
+
+// List handles GET /api/v1/routers/:id/policies, highest priority first
+func (h *RouterPolicyHandler) List(c *gin.Context) {
+	routerID := c.Param("id")
+	if routerID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
+		return
+	}
+
+	policies, err := h.ovnService.ListRouterPolicies(c.Request.Context(), routerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	items, ok := selectFields(c, policies)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": items,
+		"count":    len(policies),
+	})
+}
+
+func (h *RouterPolicyHandler) Get(c *gin.Context) {
+	policy, ok := h.policyOfRouter(c)
+	if !ok {
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, policy)
+}
+
+func (h *RouterPolicyHandler) Create(c *gin.Context) {
+	routerID := c.Param("id")
+	if routerID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
+		return
+	}
+
+	var policy models.RouterPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if policy.Match == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("match", "required", "match expression is required"))
+		return
+	}
+
+	if policy.Action == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("action", "required", "action is required"))
+		return
+	}
+
+	if msg := validateRouterPolicyRequest(&policy); msg != "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail(msg))
+		return
+	}
+
+	if policy.Action == "reroute" && len(policy.Nexthops) == 0 {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("nexthops", "required", "reroute requires at least one nexthop"))
+		return
+	}
+
+	created, err := h.ovnService.CreateRouterPolicy(c.Request.Context(), routerID, &policy)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+func (h *RouterPolicyHandler) Update(c *gin.Context) {
+	var updates models.RouterPolicy
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if msg := validateRouterPolicyRequest(&updates); msg != "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail(msg))
+		return
+	}
+
+	policy, ok := h.policyOfRouter(c)
+	if !ok {
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return policy, nil
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateRouterPolicy(ctx, policy.UUID, &updates)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+func (h *RouterPolicyHandler) Delete(c *gin.Context) {
+	policy, ok := h.policyOfRouter(c)
+	if !ok {
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return policy, nil
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	err := h.ovnService.DeleteRouterPolicy(ctx, policy.UUID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// policyOfRouter loads the policy :policyId of the router :id. It returns
+// false, with the response written, if there is no such policy.
+func (h *RouterPolicyHandler) policyOfRouter(c *gin.Context) (*models.RouterPolicy, bool) {
+	routerID, id := c.Param("id"), c.Param("policyId")
+	if routerID == "" || id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID and policy ID are required"))
+		return nil, false
+	}
+
+	policy, err := h.routerPolicy(c.Request.Context(), routerID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return nil, false
+		}
+		h.handleError(c, err)
+		return nil, false
+	}
+	return policy, true
+}
+
+// routerPolicy returns a policy if it belongs to the router, which may be
+// given by name
+func (h *RouterPolicyHandler) routerPolicy(ctx context.Context, routerID, id string) (*models.RouterPolicy, error) {
+	policy, err := h.ovnService.GetRouterPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if policy.RouterID == routerID {
+		return policy, nil
+	}
+
+	router, err := h.ovnService.GetLogicalRouter(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.RouterID != router.UUID {
+		return nil, fmt.Errorf("router policy %s not found on router %s", id, routerID)
+	}
+	return policy, nil
+}
+
+// handleError handles generic errors
+func (h *RouterPolicyHandler) handleError(c *gin.Context, err error) {
+	// Policies OVN would reject, such as a reroute without nexthops
+	if strings.Contains(err.Error(), "invalid router policy") ||
+		strings.Contains(err.Error(), "invalid match expression") {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	// Another policy has the same priority and match
+	if strings.Contains(err.Error(), "already exists") {
+		problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
+
+// validateRouterPolicyRequest checks the fields given for a router policy,
+// returning what is wrong with them or "" if they are valid. Whether a
+// reroute has nexthops is checked once an update is merged into the policy.
+func validateRouterPolicyRequest(policy *models.RouterPolicy) string {
+	if policy.Priority < 0 || policy.Priority > 32767 {
+		return "priority must be between 0 and 32767"
+	}
+
+	if policy.Match != "" {
+		if err := ovn.ValidateMatch(policy.Match); err != nil {
+			return err.Error()
+		}
+	}
+
+	switch policy.Action {
+	case "", "allow", "drop", "reroute":
+	default:
+		return "action must be one of: allow, drop, reroute"
+	}
+
+	if len(policy.Nexthops) > 0 && policy.Action != "" && policy.Action != "reroute" {
+		return "nexthops only apply to the reroute action"
+	}
+	for _, nexthop := range policy.Nexthops {
+		if net.ParseIP(nexthop) == nil {
+			return fmt.Sprintf("nexthop %q is not an IP address", nexthop)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestRouterPolicyHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     *models.RouterPolicy
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name: "successful reroute",
+			requestBody: map[string]interface{}{
+				"priority": 100,
+				"match":    "ip4.src == 10.0.0.0/24 && tcp.dst == {80, 443}",
+				"action":   "reroute",
+				"nexthops": []string{"172.16.0.1"},
+			},
+			mockReturn:     &models.RouterPolicy{UUID: "pol1", RouterID: "r1"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "duplicate priority and match",
+			requestBody: map[string]interface{}{
+				"priority": 100,
+				"match":    "ip4",
+				"action":   "drop",
+			},
+			mockError:      errors.New(`router policy with priority 100 and match "ip4" already exists`),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "invalid match",
+			requestBody: map[string]interface{}{
+				"priority": 100,
+				"match":    "ip4.src == 10.0.0.0/24 &&",
+				"action":   "allow",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "reroute without nexthops",
+			requestBody: map[string]interface{}{
+				"priority": 100,
+				"match":    "ip4",
+				"action":   "reroute",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "nexthops on drop",
+			requestBody: map[string]interface{}{
+				"priority": 100,
+				"match":    "ip4",
+				"action":   "drop",
+				"nexthops": []string{"172.16.0.1"},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "priority out of range",
+			requestBody: map[string]interface{}{
+				"priority": 40000,
+				"match":    "ip4",
+				"action":   "allow",
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewRouterPolicyHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockService.On("CreateRouterPolicy", mock.Anything, "r1", mock.Anything).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "r1"}}
+			c.Request = httptest.NewRequest("POST", "/api/v1/routers/r1/policies", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouterPolicyHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := &models.RouterPolicy{UUID: "pol1", RouterID: "r1-uuid", Priority: 100, Match: "ip4", Action: "allow"}
+
+	tests := []struct {
+		name           string
+		routerID       string
+		router         *models.LogicalRouter
+		expectedStatus int
+	}{
+		{name: "by router UUID", routerID: "r1-uuid", expectedStatus: http.StatusOK},
+		{name: "by router name", routerID: "r1", router: &models.LogicalRouter{UUID: "r1-uuid", Name: "r1"}, expectedStatus: http.StatusOK},
+		{name: "policy of another router", routerID: "r2", router: &models.LogicalRouter{UUID: "r2-uuid", Name: "r2"}, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewRouterPolicyHandler(mockService)
+
+			mockService.On("GetRouterPolicy", mock.Anything, "pol1").Return(policy, nil)
+			if tt.router != nil {
+				mockService.On("GetLogicalRouter", mock.Anything, tt.routerID).Return(tt.router, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: tt.routerID}, {Key: "policyId", Value: "pol1"}}
+			c.Request = httptest.NewRequest("GET", "/api/v1/routers/"+tt.routerID+"/policies/pol1", nil)
+
+			handler.Get(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouterPolicyHandler_Update(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewRouterPolicyHandler(mockService)
+
+	policy := &models.RouterPolicy{UUID: "pol1", RouterID: "r1", Priority: 100, Match: "ip4", Action: "allow"}
+	mockService.On("GetRouterPolicy", mock.Anything, "pol1").Return(policy, nil)
+	mockService.On("UpdateRouterPolicy", mock.Anything, "pol1", mock.Anything).
+		Return(nil, errors.New("invalid router policy: reroute requires at least one nexthop"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "r1"}, {Key: "policyId", Value: "pol1"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/routers/r1/policies/pol1", bytes.NewReader([]byte(`{"action":"reroute"}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Update(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRouterPolicyHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewRouterPolicyHandler(mockService)
+
+	mockService.On("ListRouterPolicies", mock.Anything, "missing").Return(nil, errors.New("logical router missing not found"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "missing"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/routers/missing/policies", nil)
+
+	handler.List(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
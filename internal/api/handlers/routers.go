@@ -5,88 +5,135 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
 type RouterHandler struct {
 	ovnService services.OVNServiceInterface
+	dependents *services.DependencyAnalyzer
+	trash      ResourceTrash // nil deletes resources for good
 }
 
 func NewRouterHandler(ovnService services.OVNServiceInterface) *RouterHandler {
 	return &RouterHandler{
 		ovnService: ovnService,
+		dependents: services.NewDependencyAnalyzer(ovnService),
 	}
 }
 
+// SetRecycleBin soft-deletes resources into trash
+func (h *RouterHandler) SetRecycleBin(trash ResourceTrash) {
+	h.trash = trash
+}
+
 func (h *RouterHandler) List(c *gin.Context) {
-	routers, err := h.ovnService.ListLogicalRouters(c.Request.Context())
+	opts, page, err := parseListOptions(c)
 	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	stream := streamOptions(c, opts)
+	routers, total, err := h.ovnService.ListLogicalRoutersPage(c.Request.Context(), opts)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported sort field") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithError(err))
+			return
+		}
 		h.handleError(c, err)
 		return
 	}
 
+	if stream {
+		streamNDJSON(c, routers, total, opts, func(opts *models.ListOptions) ([]*models.LogicalRouter, error) {
+			routers, _, err := h.ovnService.ListLogicalRoutersPage(c.Request.Context(), opts)
+			return routers, err
+		})
+		return
+	}
+
+	items, ok := selectFields(c, routers)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"routers": routers,
-		"count":   len(routers),
+		"routers":    items,
+		"count":      len(routers),
+		"pagination": pagination.Response(c, page, total),
 	})
 }
 
 func (h *RouterHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "router ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
 		return
 	}
 	
 	router, err := h.ovnService.GetLogicalRouter(c.Request.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, router)
+	respondWithETag(c, http.StatusOK, router)
+}
+
+// Dependents handles GET /api/v1/routers/:id/dependents, listing the
+// router ports, connected switches and load balancers deleting the router
+// would affect
+func (h *RouterHandler) Dependents(c *gin.Context) {
+	deps, err := h.dependents.RouterDependents(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deps)
 }
 
 func (h *RouterHandler) Create(c *gin.Context) {
 	var router models.LogicalRouter
 	if err := c.ShouldBindJSON(&router); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate required fields
 	if router.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name is required",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "required", "name is required"))
 		return
 	}
 
 	// Validate name format (alphanumeric, dash, underscore)
 	if !isValidName(router.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
 	// Validate static routes if provided
 	for _, route := range router.StaticRoutes {
 		if route.IPPrefix == "" || route.Nexthop == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "validation failed",
-				"details": "static routes must have ip_prefix and nexthop",
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+				WithDetail("static routes must have ip_prefix and nexthop"))
 			return
 		}
 		
@@ -101,10 +148,8 @@ func (h *RouterHandler) Create(c *gin.Context) {
 				}
 			}
 			if !isValid {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "validation failed",
-					"details": "static route policy must be 'dst-ip' or 'src-ip'",
-				})
+				problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+					WithFieldError("static_routes.policy", "oneof", "static route policy must be 'dst-ip' or 'src-ip'"))
 				return
 			}
 		}
@@ -113,96 +158,135 @@ func (h *RouterHandler) Create(c *gin.Context) {
 	created, err := h.ovnService.CreateLogicalRouter(c.Request.Context(), &router)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, created)
+	respondWithETag(c, http.StatusCreated, created)
 }
 
 func (h *RouterHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "router ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
 		return
 	}
 	
 	var router models.LogicalRouter
 	if err := c.ShouldBindJSON(&router); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate name if provided
 	if router.Name != "" && !isValidName(router.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
-	updated, err := h.ovnService.UpdateLogicalRouter(c.Request.Context(), id, &router)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLogicalRouter(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateLogicalRouter(ctx, id, &router)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	respondWithETag(c, http.StatusOK, updated)
 }
 
 func (h *RouterHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "router ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
 		return
 	}
 	
-	err := h.ovnService.DeleteLogicalRouter(c.Request.Context(), id)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLogicalRouter(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	// Routers with dependents are only deleted on ?cascade=true, which
+	// deletes their ports and the switch ports peered with them in the same
+	// transaction
+	if c.Query("cascade") == "true" {
+		ctx = ovn.WithCascade(ctx)
+	} else {
+		deps, err := h.dependents.RouterDependents(c.Request.Context(), id)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+				return
+			}
+			h.handleError(c, err)
+			return
+		}
+		if deps.Count > 0 {
+			respondHasDependents(c, "router", deps)
+			return
+		}
+	}
+
+	trashed, err := deleteResource(ctx, h.trash, models.ResourceRouter, id, h.ovnService.DeleteLogicalRouter)
 	if err != nil {
+		if respondNotRecyclable(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		if strings.Contains(err.Error(), "has") && strings.Contains(err.Error(), "ports") {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": "cannot delete router",
-				"details": err.Error(),
-			})
+			problem.Respond(c, problem.New(http.StatusConflict, "cannot delete router").
+				WithError(err))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	respondDeleted(c, trashed)
 }
 
 // handleError handles generic errors
 func (h *RouterHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
 	// Check if client is not connected
 	if strings.Contains(err.Error(), "not connected") {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "OVN service unavailable",
-			"details": "unable to connect to OVN northbound database",
-		})
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
 		return
 	}
 
 	// Default error response
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error": "internal server error",
-		"details": err.Error(),
-	})
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
 }
 
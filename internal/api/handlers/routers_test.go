@@ -82,7 +82,7 @@ func TestRouterHandler_List(t *testing.T) {
 			mockService := new(MockOVNService)
 			handler := NewRouterHandler(mockService)
 
-			mockService.On("ListLogicalRouters", mock.Anything).Return(tt.mockReturn, tt.mockError)
+			mockService.On("ListLogicalRoutersPage", mock.Anything, mock.Anything).Return(tt.mockReturn, len(tt.mockReturn), tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -447,7 +447,13 @@ func TestRouterHandler_Delete(t *testing.T) {
 			handler := NewRouterHandler(mockService)
 
 			if tt.routerID != "" {
-				mockService.On("DeleteLogicalRouter", mock.Anything, tt.routerID).Return(tt.mockError)
+				// The router is checked for dependents first
+				if tt.expectedStatus == http.StatusNotFound {
+					mockService.On("GetLogicalRouter", mock.Anything, tt.routerID).Return((*models.LogicalRouter)(nil), tt.mockError)
+				} else {
+					mockService.On("GetLogicalRouter", mock.Anything, tt.routerID).Return(&models.LogicalRouter{UUID: tt.routerID}, nil)
+					mockService.On("DeleteLogicalRouter", mock.Anything, tt.routerID).Return(tt.mockError)
+				}
 			}
 
 			w := httptest.NewRecorder()
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// SamplingHandler configures flow sampling: the IPFIX collectors samples
+// are exported to, their observation domains, and the ACLs sampled
+type SamplingHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewSamplingHandler creates a handler
+func NewSamplingHandler(ovnService services.OVNServiceInterface) *SamplingHandler {
+	return &SamplingHandler{
+		ovnService: ovnService,
+	}
+}
+
+// ListCollectors handles GET /api/v1/sampling/collectors
+func (h *SamplingHandler) ListCollectors(c *gin.Context) {
+	collectors, err := h.ovnService.ListSampleCollectors(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collectors": collectors,
+		"count":      len(collectors),
+	})
+}
+
+// GetCollector handles GET /api/v1/sampling/collectors/:id, by UUID or name
+func (h *SamplingHandler) GetCollector(c *gin.Context) {
+	collector, err := h.ovnService.GetSampleCollector(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, collector)
+}
+
+// CreateCollector handles POST /api/v1/sampling/collectors
+func (h *SamplingHandler) CreateCollector(c *gin.Context) {
+	var collector models.SampleCollector
+	if err := c.ShouldBindJSON(&collector); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if collector.Name == "" || collector.SetID == 0 || collector.Probability == nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail("name, set_id and probability are required"))
+		return
+	}
+
+	created, err := h.ovnService.CreateSampleCollector(c.Request.Context(), &collector)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusCreated, created)
+}
+
+// UpdateCollector handles PUT /api/v1/sampling/collectors/:id
+func (h *SamplingHandler) UpdateCollector(c *gin.Context) {
+	id := c.Param("id")
+
+	var collector models.SampleCollector
+	if err := c.ShouldBindJSON(&collector); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetSampleCollector(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateSampleCollector(ctx, id, &collector)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+// DeleteCollector handles DELETE /api/v1/sampling/collectors/:id. Collectors
+// observation points still export to can't be deleted.
+func (h *SamplingHandler) DeleteCollector(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetSampleCollector(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	if err := h.ovnService.DeleteSampleCollector(ctx, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListApps handles GET /api/v1/sampling/apps, the observation domains set
+func (h *SamplingHandler) ListApps(c *gin.Context) {
+	apps, err := h.ovnService.ListSamplingApps(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"apps":  apps,
+		"count": len(apps),
+	})
+}
+
+// SetApp handles PUT /api/v1/sampling/apps/:type, setting the observation
+// domain ID of the samples of a type
+func (h *SamplingHandler) SetApp(c *gin.Context) {
+	var req struct {
+		ID int `json:"id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	app, err := h.ovnService.SetSamplingApp(c.Request.Context(), c.Param("type"), req.ID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, app)
+}
+
+// DeleteApp handles DELETE /api/v1/sampling/apps/:type, unsetting the
+// observation domain of the samples of a type
+func (h *SamplingHandler) DeleteApp(c *gin.Context) {
+	if _, err := h.ovnService.SetSamplingApp(c.Request.Context(), c.Param("type"), 0); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetACL handles GET /api/v1/acls/:id/sampling
+func (h *SamplingHandler) GetACL(c *gin.Context) {
+	sampling, err := h.ovnService.GetACLSampling(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sampling)
+}
+
+// SetACL handles PUT /api/v1/acls/:id/sampling. The body replaces the
+// sampling of the ACL; an empty object stops sampling it.
+func (h *SamplingHandler) SetACL(c *gin.Context) {
+	var sampling models.ACLSampling
+	if err := c.ShouldBindJSON(&sampling); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	updated, err := h.ovnService.SetACLSampling(c.Request.Context(), c.Param("id"), &sampling)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// SetSwitch handles PUT /api/v1/switches/:id/sampling, replacing the
+// sampling of every ACL of the switch. ACLs added later aren't sampled.
+func (h *SamplingHandler) SetSwitch(c *gin.Context) {
+	var sampling models.ACLSampling
+	if err := c.ShouldBindJSON(&sampling); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	acls, err := h.ovnService.SetSwitchSampling(c.Request.Context(), c.Param("id"), &sampling)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"acls":  acls,
+		"count": len(acls),
+	})
+}
+
+// handleError handles generic errors
+func (h *SamplingHandler) handleError(c *gin.Context, err error) {
+	// Invalid collectors, sampling apps and observation points
+	if strings.Contains(err.Error(), "invalid sampl") {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	if strings.Contains(err.Error(), "not found") {
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+		return
+	}
+
+	// A collector ID, name or observation point ID is taken, or a collector
+	// is still exported to
+	if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "in use") {
+		problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
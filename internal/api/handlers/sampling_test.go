@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestSamplingHandler_CreateCollector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     *models.SampleCollector
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful create",
+			requestBody:    map[string]interface{}{"name": "soc", "set_id": 100, "probability": 655},
+			mockReturn:     &models.SampleCollector{UUID: "c1", ID: 1, Name: "soc", SetID: 100},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			// A probability of 0 is valid, so it's required rather than defaulted
+			name:           "missing probability",
+			requestBody:    map[string]interface{}{"name": "soc", "set_id": 100},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "probability out of range",
+			requestBody:    map[string]interface{}{"name": "soc", "set_id": 100, "probability": 70000},
+			mockError:      errors.New("invalid sample collector: probability must be between 0 and 65535"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ID taken",
+			requestBody:    map[string]interface{}{"id": 1, "name": "soc", "set_id": 100, "probability": 0},
+			mockError:      errors.New("sample collector ID 1 already exists"),
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewSamplingHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.mockReturn != nil || tt.mockError != nil {
+				mockService.On("CreateSampleCollector", mock.Anything, mock.Anything).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/sampling/collectors", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateCollector(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSamplingHandler_DeleteCollector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockError      error
+		expectedStatus int
+	}{
+		{name: "successful delete", expectedStatus: http.StatusNoContent},
+		{name: "not found", mockError: errors.New("sample collector soc not found"), expectedStatus: http.StatusNotFound},
+		{name: "still exported to", mockError: errors.New("sample collector soc is in use by 3 observation points"), expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewSamplingHandler(mockService)
+
+			mockService.On("DeleteSampleCollector", mock.Anything, "soc").Return(tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "soc"}}
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/sampling/collectors/soc", nil)
+
+			handler.DeleteCollector(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSamplingHandler_SetApp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewSamplingHandler(mockService)
+
+	mockService.On("SetSamplingApp", mock.Anything, "acl-new", 2).Return(&models.SamplingApp{Type: "acl-new", ID: 2}, nil)
+	mockService.On("SetSamplingApp", mock.Anything, "acl-new", 0).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "type", Value: "acl-new"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/sampling/apps/acl-new", bytes.NewReader([]byte(`{"id":2}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetApp(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// An ID of 0 would unset the domain; that takes a DELETE
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "type", Value: "acl-new"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/sampling/apps/acl-new", bytes.NewReader([]byte(`{"id":0}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetApp(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "type", Value: "acl-new"}}
+	c.Request = httptest.NewRequest("DELETE", "/api/v1/sampling/apps/acl-new", nil)
+
+	handler.DeleteApp(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSamplingHandler_SetACL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockReturn     *models.ACLSampling
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "sample new connections",
+			body:           `{"new":{"collectors":["soc"]}}`,
+			mockReturn:     &models.ACLSampling{ACLID: "acl1", New: &models.FlowSample{ObservationPointID: 1, Collectors: []string{"c1"}}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "stop sampling",
+			body:           `{}`,
+			mockReturn:     &models.ACLSampling{ACLID: "acl1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown collector",
+			body:           `{"new":{"collectors":["missing"]}}`,
+			mockError:      errors.New("sample collector missing not found"),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "established on a stateless ACL",
+			body:           `{"established":{"collectors":["soc"]}}`,
+			mockError:      errors.New("invalid sampling: established connections are only tracked for allow and allow-related ACLs"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "observation point taken",
+			body:           `{"new":{"observation_point_id":7,"collectors":["soc"]}}`,
+			mockError:      errors.New("observation point ID 7 already exists"),
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewSamplingHandler(mockService)
+
+			mockService.On("SetACLSampling", mock.Anything, "acl1", mock.Anything).Return(tt.mockReturn, tt.mockError)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "acl1"}}
+			c.Request = httptest.NewRequest("PUT", "/api/v1/acls/acl1/sampling", bytes.NewReader([]byte(tt.body)))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.SetACL(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSamplingHandler_SetSwitch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	handler := NewSamplingHandler(mockService)
+
+	sample := &models.FlowSample{Collectors: []string{"c1"}}
+	mockService.On("SetSwitchSampling", mock.Anything, "sw1", mock.MatchedBy(func(sampling *models.ACLSampling) bool {
+		return sampling.New != nil && sampling.Established != nil
+	})).Return([]*models.ACLSampling{
+		{ACLID: "acl1", New: sample, Established: sample},
+		{ACLID: "acl2", New: sample},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "sw1"}}
+	c.Request = httptest.NewRequest("PUT", "/api/v1/switches/sw1/sampling",
+		bytes.NewReader([]byte(`{"new":{"collectors":["soc"]},"established":{"collectors":["soc"]}}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetSwitch(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		ACLs  []models.ACLSampling `json:"acls"`
+		Count int                  `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	assert.Nil(t, response.ACLs[1].Established)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ServiceEntries manages the named services ACL matches reference.
+// *services.ServiceCatalog implements it.
+type ServiceEntries interface {
+	List(ctx context.Context) ([]*models.ServiceEntry, error)
+	Get(ctx context.Context, name string) (*models.ServiceEntry, error)
+	Create(ctx context.Context, req *services.ServiceEntryRequest, user string) (*models.ServiceEntry, error)
+	Update(ctx context.Context, name string, req *services.ServiceEntryRequest) (*models.ServiceEntry, error)
+	Delete(ctx context.Context, name string) error
+	ExpandMatch(ctx context.Context, match string) (string, error)
+}
+
+// ServiceCatalogHandler serves the service catalog at
+// /api/v1/service-catalog
+type ServiceCatalogHandler struct {
+	catalog ServiceEntries
+}
+
+func NewServiceCatalogHandler(catalog ServiceEntries) *ServiceCatalogHandler {
+	return &ServiceCatalogHandler{catalog: catalog}
+}
+
+// List handles GET /service-catalog, listing the built-in entries, the
+// global ones and the tenant's own. ?protocol= filters them.
+func (h *ServiceCatalogHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	entries, err := h.catalog.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if protocol := c.Query("protocol"); protocol != "" {
+		filtered := []*models.ServiceEntry{}
+		for _, entry := range entries {
+			for _, port := range entry.Ports {
+				if port.Protocol == protocol {
+					filtered = append(filtered, entry)
+					break
+				}
+			}
+		}
+		entries = filtered
+	}
+
+	pageItems := pagination.Slice(entries, page)
+	c.JSON(http.StatusOK, gin.H{
+		"services":   pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(entries)),
+	})
+}
+
+// Get handles GET /service-catalog/:name
+func (h *ServiceCatalogHandler) Get(c *gin.Context) {
+	entry, err := h.catalog.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// Create handles POST /service-catalog
+func (h *ServiceCatalogHandler) Create(c *gin.Context) {
+	var req services.ServiceEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	entry, err := h.catalog.Create(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// Update handles PUT /service-catalog/:name, replacing the description and
+// ports of a custom entry
+func (h *ServiceCatalogHandler) Update(c *gin.Context) {
+	var req services.ServiceEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	entry, err := h.catalog.Update(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// Delete handles DELETE /service-catalog/:name
+func (h *ServiceCatalogHandler) Delete(c *gin.Context) {
+	if err := h.catalog.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExpandMatchRequest is an ACL match to expand
+type ExpandMatchRequest struct {
+	Match string `json:"match" binding:"required"`
+}
+
+// Expand handles POST /service-catalog/expand, showing the match an ACL
+// would be created with
+func (h *ServiceCatalogHandler) Expand(c *gin.Context) {
+	var req ExpandMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	expanded, err := h.catalog.ExpandMatch(c.Request.Context(), req.Match)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"match":    req.Match,
+		"expanded": expanded,
+	})
+}
+
+func (h *ServiceCatalogHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrServiceEntryNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "service not found"))
+	case errors.Is(err, services.ErrUnknownService):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "unknown service").
+			WithError(err))
+	case errors.Is(err, services.ErrInvalidServiceEntry):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid service entry").
+			WithError(err))
+	case errors.Is(err, services.ErrServiceEntryConflict):
+		problem.Respond(c, problem.New(http.StatusConflict, "service entry conflict").
+			WithError(err))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
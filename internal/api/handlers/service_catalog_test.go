@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeServiceEntries has a built-in https entry
+type fakeServiceEntries struct {
+	entries map[string]*models.ServiceEntry
+}
+
+func (f *fakeServiceEntries) List(ctx context.Context) ([]*models.ServiceEntry, error) {
+	entries := []*models.ServiceEntry{}
+	for _, entry := range f.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (f *fakeServiceEntries) Get(ctx context.Context, name string) (*models.ServiceEntry, error) {
+	entry, ok := f.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", services.ErrServiceEntryNotFound, name)
+	}
+	return entry, nil
+}
+
+func (f *fakeServiceEntries) Create(ctx context.Context, req *services.ServiceEntryRequest, user string) (*models.ServiceEntry, error) {
+	if len(req.Ports) == 0 {
+		return nil, services.ErrInvalidServiceEntry
+	}
+	if _, ok := f.entries[req.Name]; ok {
+		return nil, services.ErrServiceEntryConflict
+	}
+	entry := &models.ServiceEntry{Name: req.Name, Ports: req.Ports, CreatedBy: user}
+	f.entries[entry.Name] = entry
+	return entry, nil
+}
+
+func (f *fakeServiceEntries) Update(ctx context.Context, name string, req *services.ServiceEntryRequest) (*models.ServiceEntry, error) {
+	entry, err := f.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.BuiltIn {
+		return nil, services.ErrServiceEntryConflict
+	}
+	entry.Ports = req.Ports
+	return entry, nil
+}
+
+func (f *fakeServiceEntries) Delete(ctx context.Context, name string) error {
+	if _, err := f.Get(ctx, name); err != nil {
+		return err
+	}
+	delete(f.entries, name)
+	return nil
+}
+
+func (f *fakeServiceEntries) ExpandMatch(ctx context.Context, match string) (string, error) {
+	if strings.Contains(match, "service(gopher)") {
+		return "", services.ErrUnknownService
+	}
+	return strings.ReplaceAll(match, "service(https)", "tcp.dst == 443"), nil
+}
+
+func TestServiceCatalogHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewServiceCatalogHandler(&fakeServiceEntries{entries: map[string]*models.ServiceEntry{
+		"https": {Name: "https", Ports: []models.ServicePort{{Protocol: "tcp", Port: 443}}, BuiltIn: true},
+	}})
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/service-catalog", handler.List)
+	router.GET("/service-catalog/:name", handler.Get)
+	router.POST("/service-catalog", handler.Create)
+	router.POST("/service-catalog/expand", handler.Expand)
+	router.PUT("/service-catalog/:name", handler.Update)
+	router.DELETE("/service-catalog/:name", handler.Delete)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/service-catalog", `{"name": "dns"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "ports are required")
+	w = serve(http.MethodPost, "/service-catalog", `{"name": "https", "ports": [{"protocol": "tcp", "port": 8443}]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serve(http.MethodPost, "/service-catalog", `{"name": "dns", "ports": [{"protocol": "udp", "port": 53}]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var entry models.ServiceEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Equal(t, "alice", entry.CreatedBy)
+
+	w = serve(http.MethodGet, "/service-catalog?protocol=udp", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+
+	w = serve(http.MethodPut, "/service-catalog/https", `{"ports": [{"protocol": "tcp", "port": 8443}]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(http.MethodPut, "/service-catalog/dns", `{"ports": [{"protocol": "tcp", "port": 53}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(http.MethodPost, "/service-catalog/expand", `{"match": "ip4 && service(https)"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var expanded map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &expanded))
+	assert.Equal(t, "ip4 && tcp.dst == 443", expanded["expanded"])
+	w = serve(http.MethodPost, "/service-catalog/expand", `{"match": "service(gopher)"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(http.MethodDelete, "/service-catalog/dns", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodGet, "/service-catalog/dns", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
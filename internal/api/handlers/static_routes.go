@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// maxRouteImportSize caps the size of a route import
+const maxRouteImportSize = 1 << 20
+
+// StaticRouteHandler manages the static routes of logical routers
+type StaticRouteHandler struct {
+	ovnService services.OVNServiceInterface
+}
+
+// NewStaticRouteHandler creates a handler
+func NewStaticRouteHandler(ovnService services.OVNServiceInterface) *StaticRouteHandler {
+	return &StaticRouteHandler{
+		ovnService: ovnService,
+	}
+}
+
+// List handles GET /api/v1/routers/:id/routes. Along with the routes it
+// returns their ECMP groups and warnings about overlapping prefixes and
+// inconsistently configured groups.
+func (h *StaticRouteHandler) List(c *gin.Context) {
+	routerID := c.Param("id")
+	if routerID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
+		return
+	}
+
+	routes, err := h.ovnService.ListStaticRoutes(c.Request.Context(), routerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	items, ok := selectFields(c, routes)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"routes":      items,
+		"ecmp_groups": services.GroupECMPRoutes(routes),
+		"warnings":    services.StaticRouteWarnings(nil, routes),
+		"count":       len(routes),
+	})
+}
+
+func (h *StaticRouteHandler) Get(c *gin.Context) {
+	route, ok := h.routeOfRouter(c)
+	if !ok {
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, route)
+}
+
+// Create handles POST /api/v1/routers/:id/routes, returning the route along
+// with warnings about how it interacts with the router's other routes
+func (h *StaticRouteHandler) Create(c *gin.Context) {
+	routerID := c.Param("id")
+	if routerID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
+		return
+	}
+
+	var route models.StaticRoute
+	if err := c.ShouldBindJSON(&route); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	if route.IPPrefix == "" || route.Nexthop == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithDetail("ip_prefix and nexthop are required"))
+		return
+	}
+
+	created, warnings, err := h.createRoutes(c.Request.Context(), routerID, []*models.StaticRoute{&route})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"route":    created[0],
+		"warnings": warnings,
+	})
+}
+
+// Import handles POST /api/v1/routers/:id/routes:import. The routes come as
+// an uploaded file, CSV if its name ends in .csv and JSON otherwise, or as
+// the request body, CSV if its content type is text/csv. They are added all
+// together or not at all.
+func (h *StaticRouteHandler) Import(c *gin.Context) {
+	// gin can't escape the colon in routes:import, so the route ends in a
+	// wildcard that must hold exactly ":import"
+	if c.Param("import") != ":import" {
+		problem.Respond(c, problem.New(http.StatusNotFound, "not found"))
+		return
+	}
+
+	routerID := c.Param("id")
+	if routerID == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID is required"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRouteImportSize)
+
+	var (
+		input  io.Reader = c.Request.Body
+		format           = services.RouteImportJSON
+	)
+	switch c.ContentType() {
+	case "multipart/form-data":
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid route import").
+				WithDetail("the upload has no file field"))
+			return
+		}
+		defer file.Close()
+		input = file
+		if strings.EqualFold(filepath.Ext(header.Filename), ".csv") {
+			format = services.RouteImportCSV
+		}
+	case "text/csv":
+		format = services.RouteImportCSV
+	}
+
+	routes, err := services.ParseStaticRouteImport(input, format)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid route import").
+			WithError(err))
+		return
+	}
+
+	created, warnings, err := h.createRoutes(c.Request.Context(), routerID, routes)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"created":  len(created),
+		"routes":   created,
+		"warnings": warnings,
+	})
+}
+
+func (h *StaticRouteHandler) Update(c *gin.Context) {
+	var updates models.StaticRoute
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	route, ok := h.routeOfRouter(c)
+	if !ok {
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return route, nil
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateStaticRoute(ctx, route.UUID, &updates)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, updated)
+}
+
+func (h *StaticRouteHandler) Delete(c *gin.Context) {
+	route, ok := h.routeOfRouter(c)
+	if !ok {
+		return
+	}
+
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return route, nil
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	err := h.ovnService.DeleteStaticRoute(ctx, route.UUID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// createRoutes adds routes to a router and describes how they interact with
+// the routes it already has
+func (h *StaticRouteHandler) createRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, []string, error) {
+	existing, err := h.ovnService.ListStaticRoutes(ctx, routerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, err := h.ovnService.CreateStaticRoutes(ctx, routerID, routes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, services.StaticRouteWarnings(existing, created), nil
+}
+
+// routeOfRouter loads the route :routeId of the router :id. It returns
+// false, with the response written, if there is no such route.
+func (h *StaticRouteHandler) routeOfRouter(c *gin.Context) (*models.StaticRoute, bool) {
+	routerID, id := c.Param("id"), c.Param("routeId")
+	if routerID == "" || id == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "router ID and route ID are required"))
+		return nil, false
+	}
+
+	route, err := h.routerRoute(c.Request.Context(), routerID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return nil, false
+		}
+		h.handleError(c, err)
+		return nil, false
+	}
+	return route, true
+}
+
+// routerRoute returns a route if it belongs to the router, which may be
+// given by name
+func (h *StaticRouteHandler) routerRoute(ctx context.Context, routerID, id string) (*models.StaticRoute, error) {
+	route, err := h.ovnService.GetStaticRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if route.RouterID == routerID {
+		return route, nil
+	}
+
+	router, err := h.ovnService.GetLogicalRouter(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+	if route.RouterID != router.UUID {
+		return nil, fmt.Errorf("static route %s not found on router %s", id, routerID)
+	}
+	return route, nil
+}
+
+// handleError handles generic errors
+func (h *StaticRouteHandler) handleError(c *gin.Context, err error) {
+	// Routes OVN would reject, such as a nexthop outside the prefix's family
+	if strings.Contains(err.Error(), "invalid static route") {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithError(err))
+		return
+	}
+
+	// Another route has the same prefix, nexthop and policy
+	if strings.Contains(err.Error(), "already exists") {
+		problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
+		return
+	}
+
+	// Check if client is not connected
+	if strings.Contains(err.Error(), "not connected") {
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
+		return
+	}
+
+	// Default error response
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestStaticRouteHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	existing := []*models.StaticRoute{
+		{UUID: "rt1", RouterID: "r1", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.1"},
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockReturn     []*models.StaticRoute
+		mockError      error
+		expectedStatus int
+		warnings       int
+	}{
+		{
+			name:           "second ECMP nexthop",
+			requestBody:    map[string]interface{}{"ip_prefix": "0.0.0.0/0", "nexthop": "172.16.0.2"},
+			mockReturn:     []*models.StaticRoute{{UUID: "rt2", RouterID: "r1", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.2"}},
+			expectedStatus: http.StatusCreated,
+			warnings:       1,
+		},
+		{
+			name:           "duplicate route",
+			requestBody:    map[string]interface{}{"ip_prefix": "0.0.0.0/0", "nexthop": "172.16.0.1"},
+			mockError:      errors.New("static route 0.0.0.0/0 via 172.16.0.1 already exists"),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "BFD without output port",
+			requestBody:    map[string]interface{}{"ip_prefix": "10.0.0.0/8", "nexthop": "172.16.0.1", "bfd": true},
+			mockError:      errors.New("route 0: invalid static route: BFD requires an output port"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing nexthop",
+			requestBody:    map[string]interface{}{"ip_prefix": "10.0.0.0/8"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewStaticRouteHandler(mockService)
+
+			body, _ := json.Marshal(tt.requestBody)
+
+			// Only set up mock if we expect the service to be called
+			if tt.mockReturn != nil || tt.mockError != nil {
+				mockService.On("ListStaticRoutes", mock.Anything, "r1").Return(existing, nil)
+				mockService.On("CreateStaticRoutes", mock.Anything, "r1", mock.Anything).Return(tt.mockReturn, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "r1"}}
+			c.Request = httptest.NewRequest("POST", "/api/v1/routers/r1/routes", bytes.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var response struct {
+					Route    models.StaticRoute `json:"route"`
+					Warnings []string           `json:"warnings"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "rt2", response.Route.UUID)
+				assert.Len(t, response.Warnings, tt.warnings)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStaticRouteHandler_Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	csvBody := "ip_prefix,nexthop,output_port,bfd\n" +
+		"0.0.0.0/0,172.16.0.1,lrp-uplink1,true\n" +
+		"0.0.0.0/0,172.16.0.2,lrp-uplink2,true\n"
+
+	multipartBody := func() (*bytes.Buffer, string) {
+		buf := &bytes.Buffer{}
+		writer := multipart.NewWriter(buf)
+		part, _ := writer.CreateFormFile("file", "uplinks.csv")
+		part.Write([]byte(csvBody))
+		writer.Close()
+		return buf, writer.FormDataContentType()
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		body           func() (*bytes.Buffer, string)
+		expectCreate   bool
+		expectedStatus int
+	}{
+		{
+			name:           "CSV body",
+			path:           "/routers/r1/routes:import",
+			body:           func() (*bytes.Buffer, string) { return bytes.NewBufferString(csvBody), "text/csv" },
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "CSV upload",
+			path:           "/routers/r1/routes:import",
+			body:           multipartBody,
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "JSON body",
+			path: "/routers/r1/routes:import",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`{"routes":[{"ip_prefix":"0.0.0.0/0","nexthop":"172.16.0.1"},{"ip_prefix":"0.0.0.0/0","nexthop":"172.16.0.2"}]}`), "application/json"
+			},
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "malformed CSV",
+			path:           "/routers/r1/routes:import",
+			body:           func() (*bytes.Buffer, string) { return bytes.NewBufferString("prefix,via\n"), "text/csv" },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown action",
+			path:           "/routers/r1/routes:export",
+			body:           func() (*bytes.Buffer, string) { return bytes.NewBufferString(csvBody), "text/csv" },
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewStaticRouteHandler(mockService)
+
+			router := gin.New()
+			router.POST("/routers/:id/routes", handler.Create)
+			router.POST("/routers/:id/routes:import", handler.Import)
+
+			if tt.expectCreate {
+				mockService.On("ListStaticRoutes", mock.Anything, "r1").Return([]*models.StaticRoute{}, nil)
+				mockService.On("CreateStaticRoutes", mock.Anything, "r1", mock.MatchedBy(func(routes []*models.StaticRoute) bool {
+					return len(routes) == 2 && routes[1].Nexthop == "172.16.0.2"
+				})).Return([]*models.StaticRoute{
+					{UUID: "rt1", RouterID: "r1", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.1"},
+					{UUID: "rt2", RouterID: "r1", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.2"},
+				}, nil)
+			}
+
+			body, contentType := tt.body()
+			req := httptest.NewRequest("POST", tt.path, body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				assert.True(t, strings.Contains(w.Body.String(), `"created":2`))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStaticRouteHandler_Delete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	route := &models.StaticRoute{UUID: "rt1", RouterID: "r1-uuid", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.1"}
+
+	tests := []struct {
+		name           string
+		routerID       string
+		router         *models.LogicalRouter
+		expectDelete   bool
+		expectedStatus int
+	}{
+		{name: "by router UUID", routerID: "r1-uuid", expectDelete: true, expectedStatus: http.StatusNoContent},
+		{name: "route of another router", routerID: "r2", router: &models.LogicalRouter{UUID: "r2-uuid", Name: "r2"}, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewStaticRouteHandler(mockService)
+
+			mockService.On("GetStaticRoute", mock.Anything, "rt1").Return(route, nil)
+			if tt.router != nil {
+				mockService.On("GetLogicalRouter", mock.Anything, tt.routerID).Return(tt.router, nil)
+			}
+			if tt.expectDelete {
+				mockService.On("DeleteStaticRoute", mock.Anything, "rt1").Return(nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: tt.routerID}, {Key: "routeId", Value: "rt1"}}
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/routers/"+tt.routerID+"/routes/rt1", nil)
+
+			handler.Delete(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -5,174 +5,267 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
 type SwitchHandler struct {
 	ovnService services.OVNServiceInterface
+	dependents *services.DependencyAnalyzer
+	trash      ResourceTrash // nil deletes resources for good
 }
 
 func NewSwitchHandler(ovnService services.OVNServiceInterface) *SwitchHandler {
 	return &SwitchHandler{
 		ovnService: ovnService,
+		dependents: services.NewDependencyAnalyzer(ovnService),
 	}
 }
 
+// SetRecycleBin soft-deletes resources into trash
+func (h *SwitchHandler) SetRecycleBin(trash ResourceTrash) {
+	h.trash = trash
+}
+
 func (h *SwitchHandler) List(c *gin.Context) {
-	switches, err := h.ovnService.ListLogicalSwitches(c.Request.Context())
+	opts, page, err := parseListOptions(c)
 	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	stream := streamOptions(c, opts)
+	switches, total, err := h.ovnService.ListLogicalSwitchesPage(c.Request.Context(), opts)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported sort field") {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+				WithError(err))
+			return
+		}
 		h.handleError(c, err)
 		return
 	}
 
+	if stream {
+		streamNDJSON(c, switches, total, opts, func(opts *models.ListOptions) ([]*models.LogicalSwitch, error) {
+			switches, _, err := h.ovnService.ListLogicalSwitchesPage(c.Request.Context(), opts)
+			return switches, err
+		})
+		return
+	}
+
+	items, ok := selectFields(c, switches)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"switches": switches,
-		"count":    len(switches),
+		"switches":   items,
+		"count":      len(switches),
+		"pagination": pagination.Response(c, page, total),
 	})
 }
 
 func (h *SwitchHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch ID is required"))
 		return
 	}
 	
 	sw, err := h.ovnService.GetLogicalSwitch(c.Request.Context(), id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.handleError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, sw)
+}
+
+// Dependents handles GET /api/v1/switches/:id/dependents, listing the
+// ports, ACLs, load balancers and router connections deleting the switch
+// would affect
+func (h *SwitchHandler) Dependents(c *gin.Context) {
+	deps, err := h.dependents.SwitchDependents(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, sw)
+	c.JSON(http.StatusOK, deps)
 }
 
 func (h *SwitchHandler) Create(c *gin.Context) {
 	var sw models.LogicalSwitch
 	if err := c.ShouldBindJSON(&sw); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate required fields
 	if sw.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name is required",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "required", "name is required"))
 		return
 	}
 
 	// Validate name format (alphanumeric, dash, underscore)
 	if !isValidName(sw.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
 	created, err := h.ovnService.CreateLogicalSwitch(c.Request.Context(), &sw)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, created)
+	respondWithETag(c, http.StatusCreated, created)
 }
 
 func (h *SwitchHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch ID is required"))
 		return
 	}
 	
 	var sw models.LogicalSwitch
 	if err := c.ShouldBindJSON(&sw); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-			"details": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
 		return
 	}
 
 	// Validate name if provided
 	if sw.Name != "" && !isValidName(sw.Name) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "validation failed",
-			"details": "name must contain only alphanumeric characters, dashes, and underscores",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+			WithFieldError("name", "format", "name must contain only alphanumeric characters, dashes, and underscores"))
 		return
 	}
 
-	updated, err := h.ovnService.UpdateLogicalSwitch(c.Request.Context(), id, &sw)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLogicalSwitch(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	updated, err := h.ovnService.UpdateLogicalSwitch(ctx, id, &sw)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	respondWithETag(c, http.StatusOK, updated)
 }
 
 func (h *SwitchHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "switch ID is required"})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "switch ID is required"))
 		return
 	}
 	
-	err := h.ovnService.DeleteLogicalSwitch(c.Request.Context(), id)
+	ctx, ok := checkIfMatch(c, func() (interface{}, error) {
+		return h.ovnService.GetLogicalSwitch(c.Request.Context(), id)
+	}, h.handleError)
+	if !ok {
+		return
+	}
+
+	// Switches with dependents are only deleted on ?cascade=true, which
+	// deletes their ports and ACLs and removes the ports from address sets,
+	// load balancers and port groups in the same transaction
+	if c.Query("cascade") == "true" {
+		ctx = ovn.WithCascade(ctx)
+	} else {
+		deps, err := h.dependents.SwitchDependents(c.Request.Context(), id)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+				return
+			}
+			h.handleError(c, err)
+			return
+		}
+		if deps.Count > 0 {
+			respondHasDependents(c, "switch", deps)
+			return
+		}
+	}
+
+	trashed, err := deleteResource(ctx, h.trash, models.ResourceSwitch, id, h.ovnService.DeleteLogicalSwitch)
 	if err != nil {
+		if respondNotRecyclable(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
 			return
 		}
 		if strings.Contains(err.Error(), "in use") {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": "cannot delete switch",
-				"details": "switch has associated ports or resources",
-			})
+			problem.Respond(c, problem.New(http.StatusConflict, "cannot delete switch").
+				WithDetail("switch has associated ports or resources"))
 			return
 		}
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	respondDeleted(c, trashed)
 }
 
 // handleError handles generic errors
 func (h *SwitchHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resources to be created
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	// Too many operations are already queued for OVN
+	if respondBackpressure(c, err) {
+		return
+	}
+
 	// Check if client is not connected
 	if strings.Contains(err.Error(), "not connected") {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "OVN service unavailable",
-			"details": "unable to connect to OVN northbound database",
-		})
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+		return
+	}
+
+	// The resource changed between the If-Match check and the write
+	if strings.Contains(err.Error(), "precondition failed") {
+		respondPreconditionFailed(c, "")
 		return
 	}
 
 	// Default error response
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error": "internal server error",
-		"details": err.Error(),
-	})
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
 }
 
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 	"github.com/lspecian/ovncp/internal/services"
 )
 
@@ -31,6 +35,14 @@ func (m *MockOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.Log
 	return args.Get(0).([]*models.LogicalSwitch), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalSwitch), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -69,6 +81,14 @@ func (m *MockOVNService) ListLogicalRouters(ctx context.Context) ([]*models.Logi
 	return args.Get(0).([]*models.LogicalRouter), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalRouter), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -106,6 +126,14 @@ func (m *MockOVNService) ListPorts(ctx context.Context, switchID string) ([]*mod
 	return args.Get(0).([]*models.LogicalSwitchPort), args.Error(1)
 }
 
+func (m *MockOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalSwitchPort), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -143,6 +171,14 @@ func (m *MockOVNService) ListACLs(ctx context.Context, switchID string) ([]*mode
 	return args.Get(0).([]*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.ACL), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -159,6 +195,14 @@ func (m *MockOVNService) CreateACL(ctx context.Context, switchID string, acl *mo
 	return args.Get(0).(*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	args := m.Called(ctx, switchID, acls)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
 func (m *MockOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
 	args := m.Called(ctx, id, acl)
 	if args.Get(0) == nil {
@@ -172,6 +216,350 @@ func (m *MockOVNService) DeleteACL(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LoadBalancer), args.Int(1), args.Error(2)
+}
+
+func (m *MockOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PortGroup), args.Error(1)
+}
+
+func (m *MockOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AddressSet), args.Error(1)
+}
+
+func (m *MockOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	args := m.Called(ctx, portGroupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
+func (m *MockOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	args := m.Called(ctx, owner, addressSets, portGroups)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ovn.OwnedObjects), args.Error(1)
+}
+
+func (m *MockOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, id, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteDNS(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, id, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteMirror(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	args := m.Called(ctx, appType, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	args := m.Called(ctx, switchID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID, routes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id, route)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	args := m.Called(ctx, routerID, port)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LogicalRouterPort), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	args := m.Called(ctx, portID, binding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	args := m.Called(ctx, priorities)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	args := m.Called(ctx, resource, id, set, remove)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *MockOVNService) ExecuteTransaction(ctx context.Context, ops []services.TransactionOp) error {
 	args := m.Called(ctx, ops)
 	return args.Error(0)
@@ -251,7 +639,7 @@ func TestSwitchHandler_List(t *testing.T) {
 			mockService := new(MockOVNService)
 			handler := NewSwitchHandler(mockService)
 
-			mockService.On("ListLogicalSwitches", mock.Anything).Return(tt.mockReturn, tt.mockError)
+			mockService.On("ListLogicalSwitchesPage", mock.Anything, mock.Anything).Return(tt.mockReturn, len(tt.mockReturn), tt.mockError)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
@@ -400,6 +788,30 @@ func TestSwitchHandler_Create(t *testing.T) {
 			mockError:      errors.New("switch already exists"),
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			name: "tenant quota used up",
+			requestBody: map[string]interface{}{
+				"name": "one-too-many",
+			},
+			mockError:      &services.QuotaExceededError{ResourceType: "switch", Current: 10, Requested: 1, Limit: 10},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "tenant not allowed switches",
+			requestBody: map[string]interface{}{
+				"name": "not-allowed",
+			},
+			mockError:      &services.QuotaExceededError{ResourceType: "switch", Requested: 1},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "batch queue full",
+			requestBody: map[string]interface{}{
+				"name": "queued",
+			},
+			mockError:      fmt.Errorf("create switch: %w", services.ErrBatchQueueFull),
+			expectedStatus: http.StatusTooManyRequests,
+		},
 		{
 			name:           "invalid json",
 			requestBody:    "invalid json",
@@ -417,7 +829,8 @@ func TestSwitchHandler_Create(t *testing.T) {
 			body, _ := json.Marshal(tt.requestBody)
 			
 			// Only set up mock if we expect the service to be called
-			if tt.expectedStatus == http.StatusCreated || tt.expectedStatus == http.StatusConflict {
+			serviceCalled := tt.mockReturn != nil || tt.mockError != nil
+			if serviceCalled {
 				mockService.On("CreateLogicalSwitch", mock.Anything, mock.Anything).Return(tt.mockReturn, tt.mockError)
 			}
 
@@ -430,7 +843,7 @@ func TestSwitchHandler_Create(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 
-			if tt.expectedStatus == http.StatusCreated || tt.expectedStatus == http.StatusConflict {
+			if serviceCalled {
 				mockService.AssertExpectations(t)
 			}
 		})
@@ -565,7 +978,13 @@ func TestSwitchHandler_Delete(t *testing.T) {
 			handler := NewSwitchHandler(mockService)
 
 			if tt.switchID != "" {
-				mockService.On("DeleteLogicalSwitch", mock.Anything, tt.switchID).Return(tt.mockError)
+				// The switch is checked for dependents first
+				if tt.expectedStatus == http.StatusNotFound {
+					mockService.On("GetLogicalSwitch", mock.Anything, tt.switchID).Return((*models.LogicalSwitch)(nil), tt.mockError)
+				} else {
+					mockService.On("GetLogicalSwitch", mock.Anything, tt.switchID).Return(&models.LogicalSwitch{UUID: tt.switchID}, nil)
+					mockService.On("DeleteLogicalSwitch", mock.Anything, tt.switchID).Return(tt.mockError)
+				}
 			}
 
 			w := httptest.NewRecorder()
@@ -582,4 +1001,162 @@ func TestSwitchHandler_Delete(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestSwitchHandler_ListOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedOpts   *models.ListOptions
+		mockError      error
+		expectedStatus int
+		expectedPage   map[string]interface{}
+		// expectedLinks are the relations of the Link header
+		expectedLinks []string
+	}{
+		{
+			name:           "default page size",
+			query:          "",
+			expectedOpts:   &models.ListOptions{Limit: 100},
+			expectedStatus: http.StatusOK,
+			expectedPage: map[string]interface{}{
+				"limit":       float64(100),
+				"offset":      float64(0),
+				"page":        float64(1),
+				"total_pages": float64(1),
+				"total_count": float64(5),
+			},
+			expectedLinks: []string{"first", "last"},
+		},
+		{
+			name:  "filters, sort and page",
+			query: "?limit=2&page=2&name=web&external_ids=owner=team-a,env&sort=-created_at",
+			expectedOpts: &models.ListOptions{
+				Limit:       2,
+				Offset:      2,
+				Name:        "web",
+				ExternalIDs: map[string]string{"owner": "team-a", "env": ""},
+				SortBy:      "created_at",
+				SortDesc:    true,
+			},
+			expectedStatus: http.StatusOK,
+			expectedPage: map[string]interface{}{
+				"limit":       float64(2),
+				"offset":      float64(2),
+				"page":        float64(2),
+				"total_pages": float64(3),
+				"total_count": float64(5),
+			},
+			expectedLinks: []string{"first", "prev", "next", "last"},
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=5000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "zero limit",
+			query:          "?limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=page-2",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid external_ids",
+			query:          "?external_ids==value",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "label selector",
+			query: "?labels=" + url.QueryEscape("app=web,env in (prod, staging),tier!=db,!legacy,team"),
+			expectedOpts: &models.ListOptions{
+				Limit: 100,
+				Labels: &models.LabelSelector{
+					MatchLabels: map[string]string{"app": "web"},
+					MatchExpressions: []models.LabelSelectorRequirement{
+						{Key: "env", Operator: "In", Values: []string{"prod", "staging"}},
+						{Key: "tier", Operator: "NotIn", Values: []string{"db"}},
+						{Key: "legacy", Operator: "DoesNotExist"},
+						{Key: "team", Operator: "Exists"},
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedPage: map[string]interface{}{
+				"limit":       float64(100),
+				"offset":      float64(0),
+				"page":        float64(1),
+				"total_pages": float64(1),
+				"total_count": float64(5),
+			},
+			expectedLinks: []string{"first", "last"},
+		},
+		{
+			name:           "invalid label selector",
+			query:          "?labels=" + url.QueryEscape("app=web,,env in (-prod)"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported sort field",
+			query:          "?sort=ports",
+			expectedOpts:   &models.ListOptions{Limit: 100, SortBy: "ports"},
+			mockError:      errors.New(`unsupported sort field "ports", expected one of: name, created_at, updated_at`),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOVNService)
+			handler := NewSwitchHandler(mockService)
+
+			if tt.expectedOpts != nil {
+				var switches []*models.LogicalSwitch
+				if tt.mockError == nil {
+					switches = []*models.LogicalSwitch{{UUID: "uuid1", Name: "web"}}
+				}
+				mockService.On("ListLogicalSwitchesPage", mock.Anything, tt.expectedOpts).Return(switches, 5, tt.mockError)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/switches"+tt.query, nil)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			if tt.expectedStatus == http.StatusBadRequest {
+				assert.Equal(t, "invalid query parameters", response["error"])
+			}
+			if tt.expectedPage != nil {
+				page := response["pagination"].(map[string]interface{})
+				for _, rel := range []string{"prev", "next"} {
+					if cursor, ok := page[rel+"_cursor"]; ok {
+						assert.Contains(t, w.Header().Get("Link"), fmt.Sprintf("cursor=%s&", cursor))
+						delete(page, rel+"_cursor")
+					}
+				}
+				assert.Equal(t, tt.expectedPage, page)
+				assert.Equal(t, float64(1), response["count"])
+
+				var rels []string
+				for _, link := range strings.Split(w.Header().Get("Link"), ", ") {
+					_, rel, _ := strings.Cut(link, "; rel=")
+					rels = append(rels, strings.Trim(rel, `"`))
+				}
+				assert.Equal(t, tt.expectedLinks, rels)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
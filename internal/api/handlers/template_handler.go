@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/services"
 	"go.uber.org/zap"
 )
@@ -48,9 +49,7 @@ func (h *TemplateHandler) GetTemplate(c *gin.Context) {
 	template, err := h.templateService.GetTemplate(templateID)
 	if err != nil {
 		h.logger.Error("Failed to get template", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Template not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Template not found"))
 		return
 	}
 
@@ -67,18 +66,14 @@ type ValidateTemplateRequest struct {
 func (h *TemplateHandler) ValidateTemplate(c *gin.Context) {
 	var req ValidateTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
-	result, err := h.templateService.ValidateTemplate(req.TemplateID, req.Variables)
+	result, err := h.templateService.ValidateTemplate(c.Request.Context(), req.TemplateID, req.Variables)
 	if err != nil {
 		h.logger.Error("Failed to validate template", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -97,28 +92,22 @@ type InstantiateTemplateRequest struct {
 func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
 	var req InstantiateTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
 	// If dry run, just validate and return preview
 	if req.DryRun {
-		result, err := h.templateService.ValidateTemplate(req.TemplateID, req.Variables)
+		result, err := h.templateService.ValidateTemplate(c.Request.Context(), req.TemplateID, req.Variables)
 		if err != nil {
 			h.logger.Error("Failed to validate template", zap.Error(err))
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 			return
 		}
 
 		if !result.Valid {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":      "Template validation failed",
-				"validation": result,
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Template validation failed").
+				With("validation", result))
 			return
 		}
 
@@ -133,9 +122,7 @@ func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
 	instance, err := h.templateService.InstantiateTemplate(c.Request.Context(), req.TemplateID, req.Variables, req.TargetSwitch)
 	if err != nil {
 		h.logger.Error("Failed to instantiate template", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -149,18 +136,14 @@ func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
 func (h *TemplateHandler) ImportTemplate(c *gin.Context) {
 	var data json.RawMessage
 	if err := c.ShouldBindJSON(&data); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid JSON data"))
 		return
 	}
 
 	template, err := h.templateService.ImportTemplate(data)
 	if err != nil {
 		h.logger.Error("Failed to import template", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -177,9 +160,7 @@ func (h *TemplateHandler) ExportTemplate(c *gin.Context) {
 	data, err := h.templateService.ExportTemplate(templateID)
 	if err != nil {
 		h.logger.Error("Failed to export template", zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Template not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Template not found"))
 		return
 	}
 
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// TenantChangeHandler serves the changes of tenants that require approval
+type TenantChangeHandler struct {
+	changeService *services.TenantChangeService
+}
+
+func NewTenantChangeHandler(changeService *services.TenantChangeService) *TenantChangeHandler {
+	return &TenantChangeHandler{
+		changeService: changeService,
+	}
+}
+
+// List handles GET /tenants/:id/changes, optionally filtered by ?status=
+func (h *TenantChangeHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	changes, err := h.changeService.ListChanges(c.Request.Context(), c.Param("id"), models.TenantChangeStatus(c.Query("status")))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	pageItems := pagination.Slice(changes, page)
+	c.JSON(http.StatusOK, gin.H{
+		"changes":    pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(changes)),
+	})
+}
+
+func (h *TenantChangeHandler) Get(c *gin.Context) {
+	change, err := h.changeService.GetChange(c.Request.Context(), c.Param("id"), c.Param("change_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// Approve handles POST /tenants/:id/changes/:change_id/approve, executing
+// the change in one transaction
+func (h *TenantChangeHandler) Approve(c *gin.Context) {
+	h.review(c, true)
+}
+
+func (h *TenantChangeHandler) Reject(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *TenantChangeHandler) review(c *gin.Context, approve bool) {
+	var req ChangesetReviewRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	review := h.changeService.RejectChange
+	if approve {
+		review = h.changeService.ApproveChange
+	}
+
+	change, err := review(c.Request.Context(), c.Param("id"), c.Param("change_id"), c.GetString("user_id"), req.Comment)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+func (h *TenantChangeHandler) handleError(c *gin.Context, err error) {
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrTenantChangeNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "change not found"))
+	case errors.Is(err, services.ErrTenantChangeStatus):
+		problem.Respond(c, problem.New(http.StatusConflict, "invalid change status").
+			WithError(err))
+	case errors.Is(err, services.ErrTenantChangeSelfReview):
+		problem.Respond(c, problem.New(http.StatusForbidden, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
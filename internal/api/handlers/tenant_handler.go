@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
 	"go.uber.org/zap"
@@ -12,12 +16,16 @@ import (
 
 type TenantHandler struct {
 	tenantService *services.TenantService
+	reclaimer     *services.TenantReclaimer
+	usage         *services.TenantUsageRecorder
 	logger        *zap.Logger
 }
 
-func NewTenantHandler(tenantService *services.TenantService, logger *zap.Logger) *TenantHandler {
+func NewTenantHandler(tenantService *services.TenantService, reclaimer *services.TenantReclaimer, usage *services.TenantUsageRecorder, logger *zap.Logger) *TenantHandler {
 	return &TenantHandler{
 		tenantService: tenantService,
+		reclaimer:     reclaimer,
+		usage:         usage,
 		logger:        logger,
 	}
 }
@@ -38,9 +46,7 @@ type CreateTenantRequest struct {
 func (h *TenantHandler) CreateTenant(c *gin.Context) {
 	var req CreateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -63,9 +69,7 @@ func (h *TenantHandler) CreateTenant(c *gin.Context) {
 	created, err := h.tenantService.CreateTenant(c.Request.Context(), tenant, userID.(string))
 	if err != nil {
 		h.logger.Error("Failed to create tenant", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -94,9 +98,7 @@ func (h *TenantHandler) ListTenants(c *gin.Context) {
 	tenants, err := h.tenantService.ListTenants(c.Request.Context(), filter)
 	if err != nil {
 		h.logger.Error("Failed to list tenants", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list tenants",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to list tenants"))
 		return
 	}
 
@@ -112,9 +114,7 @@ func (h *TenantHandler) GetTenant(c *gin.Context) {
 
 	tenant, err := h.tenantService.GetTenant(c.Request.Context(), tenantID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Tenant not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Tenant not found"))
 		return
 	}
 
@@ -122,9 +122,7 @@ func (h *TenantHandler) GetTenant(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	membership, err := h.tenantService.GetMembership(c.Request.Context(), tenantID, userID.(string))
 	if err != nil || membership == nil {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Access denied",
-		})
+		problem.Respond(c, problem.New(http.StatusForbidden, "Access denied"))
 		return
 	}
 
@@ -147,9 +145,7 @@ func (h *TenantHandler) UpdateTenant(c *gin.Context) {
 
 	var req UpdateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -165,30 +161,48 @@ func (h *TenantHandler) UpdateTenant(c *gin.Context) {
 	updated, err := h.tenantService.UpdateTenant(c.Request.Context(), tenantID, updates)
 	if err != nil {
 		h.logger.Error("Failed to update tenant", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, updated)
 }
 
-// DeleteTenant deletes a tenant
+// DeleteTenant starts deleting a tenant. With force=true the tenant's OVN
+// objects are deleted first; otherwise tenants with resources are refused.
 func (h *TenantHandler) DeleteTenant(c *gin.Context) {
 	tenantID := c.Param("id")
+	force := c.Query("force") == "true"
 
-	if err := h.tenantService.DeleteTenant(c.Request.Context(), tenantID); err != nil {
+	deletion, err := h.reclaimer.DeleteTenant(c.Request.Context(), tenantID, force)
+	if err != nil {
+		if errors.Is(err, services.ErrTenantHasResources) {
+			problem.Respond(c, problem.New(http.StatusConflict, err.Error()).
+				WithDetail("delete the tenant's resources first or use force=true"))
+			return
+		}
 		h.logger.Error("Failed to delete tenant", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Tenant marked for deletion",
-	})
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetDeletion returns the progress of a tenant's deletion
+func (h *TenantHandler) GetDeletion(c *gin.Context) {
+	deletion, err := h.reclaimer.GetDeletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrTenantDeletionNotFound) {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.logger.Error("Failed to get tenant deletion", zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "failed to get tenant deletion"))
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
 }
 
 // GetResourceUsage returns resource usage for a tenant
@@ -198,18 +212,14 @@ func (h *TenantHandler) GetResourceUsage(c *gin.Context) {
 	usage, err := h.tenantService.GetResourceUsage(c.Request.Context(), tenantID)
 	if err != nil {
 		h.logger.Error("Failed to get resource usage", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to get resource usage",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to get resource usage"))
 		return
 	}
 
 	// Get tenant for quotas
 	tenant, err := h.tenantService.GetTenant(c.Request.Context(), tenantID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Tenant not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Tenant not found"))
 		return
 	}
 
@@ -219,6 +229,59 @@ func (h *TenantHandler) GetResourceUsage(c *gin.Context) {
 	})
 }
 
+// GetUsageHistory handles GET /tenants/:id/usage/history?interval=hour|day|month
+// &from=&to=&format=json|csv. The range defaults to the last 30 days,
+// bucketed by day.
+func (h *TenantHandler) GetUsageHistory(c *gin.Context) {
+	tenantID := c.Param("id")
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid format. Supported formats: json, csv"))
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param+" time").
+					WithDetail("expected RFC 3339, e.g. 2024-01-02T15:04:05Z"))
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	interval := models.UsageInterval(c.DefaultQuery("interval", string(models.UsageIntervalDay)))
+	history, err := h.usage.GetHistory(c.Request.Context(), tenantID, interval, from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUsageQuery) {
+			problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		h.logger.Error("Failed to get usage history", zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to get usage history"))
+		return
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := services.WriteUsageHistoryCSV(&buf, history); err != nil {
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+				WithError(err))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", tenantID+"-usage.csv"))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // Member management
 
 // AddMemberRequest represents a request to add a member
@@ -233,9 +296,7 @@ func (h *TenantHandler) AddMember(c *gin.Context) {
 	
 	var req AddMemberRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -243,9 +304,7 @@ func (h *TenantHandler) AddMember(c *gin.Context) {
 
 	if err := h.tenantService.AddMember(c.Request.Context(), tenantID, req.UserID, req.Role, addedBy.(string)); err != nil {
 		h.logger.Error("Failed to add member", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -261,9 +320,7 @@ func (h *TenantHandler) RemoveMember(c *gin.Context) {
 
 	if err := h.tenantService.RemoveMember(c.Request.Context(), tenantID, userID); err != nil {
 		h.logger.Error("Failed to remove member", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -281,17 +338,13 @@ func (h *TenantHandler) UpdateMemberRole(c *gin.Context) {
 		Role string `json:"role" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
 	if err := h.tenantService.UpdateMemberRole(c.Request.Context(), tenantID, userID, req.Role); err != nil {
 		h.logger.Error("Failed to update member role", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -307,9 +360,7 @@ func (h *TenantHandler) ListMembers(c *gin.Context) {
 	members, err := h.tenantService.ListMembers(c.Request.Context(), tenantID)
 	if err != nil {
 		h.logger.Error("Failed to list members", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list members",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to list members"))
 		return
 	}
 
@@ -333,9 +384,7 @@ func (h *TenantHandler) CreateInvitation(c *gin.Context) {
 	
 	var req CreateInvitationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -344,9 +393,7 @@ func (h *TenantHandler) CreateInvitation(c *gin.Context) {
 	invitation, err := h.tenantService.CreateInvitation(c.Request.Context(), tenantID, req.Email, req.Role, createdBy.(string))
 	if err != nil {
 		h.logger.Error("Failed to create invitation", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -363,9 +410,7 @@ func (h *TenantHandler) AcceptInvitation(c *gin.Context) {
 
 	if err := h.tenantService.AcceptInvitation(c.Request.Context(), token, userID.(string)); err != nil {
 		h.logger.Error("Failed to accept invitation", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
 		return
 	}
 
@@ -380,7 +425,8 @@ func (h *TenantHandler) AcceptInvitation(c *gin.Context) {
 type CreateAPIKeyRequest struct {
 	Name        string   `json:"name" binding:"required"`
 	Description string   `json:"description"`
-	Scopes      []string `json:"scopes"`
+	Scopes      []string `json:"scopes"`        // read, write, backup, trace, admin; defaults to read
+	AllowedCIDRs []string `json:"allowed_cidrs"` // Client networks the key may be used from
 	ExpiresIn   int      `json:"expires_in"` // Days
 }
 
@@ -390,9 +436,7 @@ func (h *TenantHandler) CreateAPIKey(c *gin.Context) {
 	
 	var req CreateAPIKeyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body"))
 		return
 	}
 
@@ -402,6 +446,7 @@ func (h *TenantHandler) CreateAPIKey(c *gin.Context) {
 		Name:        req.Name,
 		Description: req.Description,
 		Scopes:      req.Scopes,
+		AllowedCIDRs: req.AllowedCIDRs,
 		CreatedBy:   createdBy.(string),
 	}
 
@@ -412,11 +457,13 @@ func (h *TenantHandler) CreateAPIKey(c *gin.Context) {
 	}
 
 	apiKey, err := h.tenantService.CreateAPIKey(c.Request.Context(), tenantID, key)
+	if errors.Is(err, services.ErrInvalidAPIKeySettings) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to create API key", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
@@ -426,6 +473,52 @@ func (h *TenantHandler) CreateAPIKey(c *gin.Context) {
 	})
 }
 
+// Grace periods for the old secret of a rotated API key
+const (
+	defaultAPIKeyGraceHours = 24
+	maxAPIKeyGraceHours     = 30 * 24
+)
+
+// RotateAPIKeyRequest represents an API key rotation request
+type RotateAPIKeyRequest struct {
+	GracePeriodHours *int `json:"grace_period_hours"` // Default 24, 0 revokes the old secret at once
+}
+
+// RotateAPIKey issues a new secret for an API key, keeping the old one valid
+// for a grace period
+func (h *TenantHandler) RotateAPIKey(c *gin.Context) {
+	var req RotateAPIKeyRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	graceHours := defaultAPIKeyGraceHours
+	if req.GracePeriodHours != nil {
+		graceHours = *req.GracePeriodHours
+	}
+	if graceHours < 0 || graceHours > maxAPIKeyGraceHours {
+		problem.Respond(c, problem.New(http.StatusBadRequest, fmt.Sprintf("grace_period_hours must be between 0 and %d", maxAPIKeyGraceHours)))
+		return
+	}
+
+	apiKey, key, err := h.tenantService.RotateAPIKey(c.Request.Context(), c.Param("id"), c.Param("key_id"), time.Duration(graceHours)*time.Hour)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+			return
+		}
+		h.logger.Error("Failed to rotate API key", zap.Error(err))
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to rotate API key"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key":     apiKey,
+		"api_key": key,
+		"message": "API key rotated successfully. Please save the key, it won't be shown again.",
+	})
+}
+
 // ListAPIKeys lists API keys for a tenant
 func (h *TenantHandler) ListAPIKeys(c *gin.Context) {
 	tenantID := c.Param("id")
@@ -433,9 +526,7 @@ func (h *TenantHandler) ListAPIKeys(c *gin.Context) {
 	keys, err := h.tenantService.ListAPIKeys(c.Request.Context(), tenantID)
 	if err != nil {
 		h.logger.Error("Failed to list API keys", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to list API keys",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to list API keys"))
 		return
 	}
 
@@ -452,9 +543,7 @@ func (h *TenantHandler) DeleteAPIKey(c *gin.Context) {
 
 	if err := h.tenantService.DeleteAPIKey(c.Request.Context(), tenantID, keyID); err != nil {
 		h.logger.Error("Failed to delete API key", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
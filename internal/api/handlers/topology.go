@@ -1,41 +1,237 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/internal/visualization"
 )
 
+// maxTopologyDepth bounds the neighborhood a topology query may span
+const maxTopologyDepth = 16
+
+// TopologyHistoryReader reads recorded topology snapshots. TopologyHistory
+// implements it.
+type TopologyHistoryReader interface {
+	TopologyAt(ctx context.Context, at time.Time) (*services.Topology, error)
+	ListSnapshots(ctx context.Context, from, to time.Time) ([]*models.TopologySnapshot, error)
+}
+
 // TopologyHandler handles topology-related requests
 type TopologyHandler struct {
 	service services.OVNServiceInterface
+	history TopologyHistoryReader
 }
 
 // NewTopologyHandler creates a new topology handler
-func NewTopologyHandler(service services.OVNServiceInterface) *TopologyHandler {
+func NewTopologyHandler(service services.OVNServiceInterface, history TopologyHistoryReader) *TopologyHandler {
 	return &TopologyHandler{
 		service: service,
+		history: history,
 	}
 }
 
-// GetTopology handles GET /api/v1/topology
+// GetTopology handles GET /api/v1/topology. With ?at=<RFC 3339 time> it
+// returns the topology recorded by the latest snapshot taken by then. The
+// query parameters parsed by parseTopologyScope narrow it.
 func (h *TopologyHandler) GetTopology(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	scope, err := parseTopologyScope(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid topology scope").
+			WithError(err))
+		return
+	}
+
+	var topology *services.Topology
+	if at := c.Query("at"); at != "" {
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339, at); err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid at time").
+				WithDetail("expected RFC 3339, e.g. 2024-01-02T15:04:05Z"))
+			return
+		}
+		topology, err = h.history.TopologyAt(ctx, t)
+	} else {
+		topology, err = h.service.GetTopology(ctx)
+	}
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if !scope.IsZero() {
+		if topology, err = services.ScopeTopology(topology, scope); err != nil {
+			h.handleError(c, err)
+			return
+		}
+	}
+
+	fields := parseFields(c)
+	if fields == nil {
+		c.JSON(http.StatusOK, topology)
+		return
+	}
+
+	// ?fields= applies to each resource; connections are kept whole
+	collections := []interface{}{topology.Switches, topology.Routers, topology.Ports, topology.RouterPorts, topology.ACLs}
+	types := make([]reflect.Type, len(collections))
+	for i, collection := range collections {
+		types[i] = reflect.TypeOf(collection)
+	}
+	if err := fields.validateAny(types...); err != nil {
+		respondInvalidFields(c, err)
+		return
+	}
+
+	projected := make([]interface{}, len(collections))
+	for i, collection := range collections {
+		if projected[i], err = fields.project(collection); err != nil {
+			h.handleError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"Switches":    projected[0],
+		"Routers":     projected[1],
+		"Ports":       projected[2],
+		"RouterPorts": projected[3],
+		"ACLs":        projected[4],
+		"Connections": topology.Connections,
+		"Timestamp":   topology.Timestamp,
+	})
+}
+
+// parseTopologyScope parses the query parameters narrowing a topology:
+//
+//	node      UUID of a switch, router, port, router port or ACL; only its
+//	          neighborhood is returned
+//	depth     number of edges the neighborhood spans, 1 by default
+//	tenant    only the resources labeled with this tenant
+//	selector  comma-separated key=value pairs, or bare keys that only have
+//	          to be present, the resources' external IDs must match
+//	labels    label selector the resources' labels must match, e.g.
+//	          app=web,env in (prod,staging)
+func parseTopologyScope(c *gin.Context) (services.TopologyScope, error) {
+	scope := services.TopologyScope{
+		TenantID: c.Query("tenant"),
+		Root:     c.Query("node"),
+		Depth:    1,
+	}
+
+	if d := c.Query("depth"); d != "" {
+		if scope.Root == "" {
+			return scope, fmt.Errorf("depth requires node")
+		}
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 0 || parsed > maxTopologyDepth {
+			return scope, fmt.Errorf("depth must be between 0 and %d", maxTopologyDepth)
+		}
+		scope.Depth = parsed
+	}
+
+	if selector := c.Query("selector"); selector != "" {
+		var err error
+		if scope.Selector, err = parseExternalIDs(selector); err != nil {
+			return scope, fmt.Errorf("invalid selector %w", err)
+		}
+	}
+
+	if labels := c.Query("labels"); labels != "" {
+		var err error
+		if scope.Labels, err = models.ParseLabelSelector(labels); err != nil {
+			return scope, fmt.Errorf("invalid labels selector: %w", err)
+		}
+	}
+
+	return scope, nil
+}
+
+// ListSnapshots handles GET /api/v1/topology/snapshots, listing the
+// snapshots taken between the from and to times, by default all of them
+func (h *TopologyHandler) ListSnapshots(c *gin.Context) {
+	var from time.Time
+	to := time.Now().UTC()
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid "+param+" time").
+					WithDetail("expected RFC 3339, e.g. 2024-01-02T15:04:05Z"))
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	snapshots, err := h.history.ListSnapshots(c.Request.Context(), from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots": snapshots,
+		"total":     len(snapshots),
+	})
+}
+
+// GetPath handles GET /api/v1/topology/path?from=<port>&to=<port>, returning
+// the switches and routers traffic crosses between two ports, referenced by
+// UUID or name. Switch hops list the ACLs that may apply to it and router
+// hops the NAT rules translating it.
+func (h *TopologyHandler) GetPath(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "from and to are required"))
+		return
+	}
+
+	ctx := c.Request.Context()
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, topology)
+	path, err := visualization.FindPath(topology, from, to, func(switchID string) ([]*models.ACL, error) {
+		return h.service.ListACLs(ctx, switchID)
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, path)
 }
 
 // handleError handles generic errors
 func (h *TopologyHandler) handleError(c *gin.Context, err error) {
-	c.JSON(http.StatusInternalServerError, gin.H{
-		"error": "internal server error",
-		"details": err.Error(),
-	})
+	switch {
+	case errors.Is(err, services.ErrInvalidTopologyRef):
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+		return
+	case errors.Is(err, services.ErrTopologySnapshotNotFound), errors.Is(err, services.ErrTopologyNodeNotFound),
+		errors.Is(err, visualization.ErrPathEndpointNotFound), errors.Is(err, visualization.ErrNoPath):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+		return
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+		return
+	}
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+		WithError(err))
 }
\ No newline at end of file
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// TopologyDiffer diffs two topology states. TopologyHistory implements it.
+type TopologyDiffer interface {
+	Diff(ctx context.Context, from, to services.TopologyRef) (*services.TopologyDiff, error)
+}
+
+// PermissionChecker reports whether a request is allowed a permission
+type PermissionChecker func(c *gin.Context, permission string) bool
+
+// TopologyDiffHandler compares topology states for change review and
+// incident analysis
+type TopologyDiffHandler struct {
+	differ        TopologyDiffer
+	hasPermission PermissionChecker
+}
+
+// NewTopologyDiffHandler creates a handler. hasPermission checks that
+// requests comparing backups may read them.
+func NewTopologyDiffHandler(differ TopologyDiffer, hasPermission PermissionChecker) *TopologyDiffHandler {
+	return &TopologyDiffHandler{
+		differ:        differ,
+		hasPermission: hasPermission,
+	}
+}
+
+// Diff returns the nodes and edges added, removed and changed between the
+// from and to states. Each is "now", "snapshot:<id>", "backup:<id>" or an
+// RFC 3339 time, which selects the latest snapshot taken by then; to
+// defaults to now.
+func (h *TopologyDiffHandler) Diff(c *gin.Context) {
+	fromParam := c.Query("from")
+	if fromParam == "" {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "from is required"))
+		return
+	}
+	from, err := services.ParseTopologyRef(fromParam)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+	to, err := services.ParseTopologyRef(c.DefaultQuery("to", services.TopologySourceNow))
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if from.Source == services.TopologySourceBackup || to.Source == services.TopologySourceBackup {
+		if !h.hasPermission(c, "backups:read") {
+			problem.Respond(c, problem.New(http.StatusForbidden, "Insufficient permissions"))
+			return
+		}
+	}
+
+	diff, err := h.differ.Diff(c.Request.Context(), from, to)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *TopologyDiffHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTopologyRef):
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+	case errors.Is(err, services.ErrTopologySnapshotNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeTopologyDiffer records the references it diffs
+type fakeTopologyDiffer struct {
+	from, to services.TopologyRef
+	err      error
+}
+
+func (d *fakeTopologyDiffer) Diff(ctx context.Context, from, to services.TopologyRef) (*services.TopologyDiff, error) {
+	d.from, d.to = from, to
+	if d.err != nil {
+		return nil, d.err
+	}
+	return services.DiffTopologies(&services.Topology{}, &services.Topology{}), nil
+}
+
+func serveTopologyDiff(handler *TopologyDiffHandler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/topology/diff"+query, nil)
+	handler.Diff(c)
+	return w
+}
+
+func TestTopologyDiffHandler_Diff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	differ := &fakeTopologyDiffer{}
+	canReadBackups := false
+	handler := NewTopologyDiffHandler(differ, func(c *gin.Context, permission string) bool {
+		return permission == "backups:read" && canReadBackups
+	})
+
+	w := serveTopologyDiff(handler, "?from=snapshot:snap-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, services.TopologyRef{Source: services.TopologySourceSnapshot, ID: "snap-1"}, differ.from)
+	assert.Equal(t, services.TopologyRef{Source: services.TopologySourceNow}, differ.to, "to defaults to now")
+
+	var diff services.TopologyDiff
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 0, diff.Summary["nodes_added"])
+
+	assert.Equal(t, http.StatusBadRequest, serveTopologyDiff(handler, "").Code)
+	assert.Equal(t, http.StatusBadRequest, serveTopologyDiff(handler, "?from=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, serveTopologyDiff(handler, "?from=now&to=tag:v1").Code)
+
+	// Comparing backups needs permission to read them
+	assert.Equal(t, http.StatusForbidden, serveTopologyDiff(handler, "?from=backup:backup-123").Code)
+	canReadBackups = true
+	assert.Equal(t, http.StatusOK, serveTopologyDiff(handler, "?from=backup:backup-123").Code)
+}
+
+func TestTopologyDiffHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{fmt.Errorf("%w: snap-1", services.ErrTopologySnapshotNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: backups are not available", services.ErrInvalidTopologyRef), http.StatusBadRequest},
+		{errors.New("OVN client not connected"), http.StatusServiceUnavailable},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		handler := NewTopologyDiffHandler(&fakeTopologyDiffer{err: tt.err}, func(*gin.Context, string) bool { return true })
+		assert.Equal(t, tt.code, serveTopologyDiff(handler, "?from=2024-03-01T10:00:00Z").Code, tt.err.Error())
+	}
+}
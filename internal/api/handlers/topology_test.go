@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeTopologyHistory serves one snapshot taken at takenAt
+type fakeTopologyHistory struct {
+	takenAt  time.Time
+	from, to time.Time
+}
+
+func (h *fakeTopologyHistory) TopologyAt(ctx context.Context, at time.Time) (*services.Topology, error) {
+	if at.Before(h.takenAt) {
+		return nil, fmt.Errorf("%w: none taken at or before %s", services.ErrTopologySnapshotNotFound, at)
+	}
+	return &services.Topology{
+		Switches:  []*models.LogicalSwitch{{UUID: "sw-1", Name: "web"}},
+		Timestamp: h.takenAt,
+	}, nil
+}
+
+func (h *fakeTopologyHistory) ListSnapshots(ctx context.Context, from, to time.Time) ([]*models.TopologySnapshot, error) {
+	h.from, h.to = from, to
+	return []*models.TopologySnapshot{{ID: "snap-1", Cluster: "us-east", TakenAt: h.takenAt}}, nil
+}
+
+func TestTopologyHandler_GetTopologyAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	takenAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	router := gin.New()
+	router.GET("/topology", NewTopologyHandler(new(MockOVNService), &fakeTopologyHistory{takenAt: takenAt}).GetTopology)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology?at=2024-03-01T11:00:00Z&fields=name", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "web"}}, body["Switches"])
+	assert.Equal(t, "2024-03-01T10:00:00Z", body["Timestamp"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology?at=2024-03-01T09:00:00Z", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology?at=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTopologyHandler_ListSnapshots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	history := &fakeTopologyHistory{takenAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+	router := gin.New()
+	router.GET("/topology/snapshots", NewTopologyHandler(new(MockOVNService), history).ListSnapshots)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology/snapshots?from=2024-03-01T00:00:00Z", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), history.from)
+	assert.WithinDuration(t, time.Now(), history.to, time.Minute, "to defaults to now")
+
+	var body struct {
+		Snapshots []*models.TopologySnapshot `json:"snapshots"`
+		Total     int                        `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Total)
+	assert.Equal(t, "snap-1", body.Snapshots[0].ID)
+	assert.NotContains(t, w.Body.String(), `"data"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/topology/snapshots?to=now", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTopologyHandler_GetTopologyScoped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(&services.Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Name: "web", Ports: []string{"port-1"}, ExternalIDs: map[string]string{"tier": "web", "ovncp.io/app": "web"}},
+			{UUID: "sw-2", Name: "db"},
+		},
+		Ports: []*models.LogicalSwitchPort{{UUID: "port-1", Name: "web-1"}},
+	}, nil)
+	router := gin.New()
+	router.GET("/topology", NewTopologyHandler(mockService, nil).GetTopology)
+
+	get := func(query string) (*httptest.ResponseRecorder, map[string][]map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/topology"+query, nil))
+		var body map[string][]map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	w, body := get("?node=sw-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, body["Switches"], 1)
+	assert.Len(t, body["Ports"], 1)
+
+	w, body = get("?node=sw-1&depth=0")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, body["Ports"])
+
+	w, body = get("?selector=tier=web")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, body["Switches"], 1)
+	assert.Equal(t, "web", body["Switches"][0]["name"])
+
+	w, body = get("?labels=" + url.QueryEscape("!app"))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, body["Switches"], 1)
+	assert.Equal(t, "db", body["Switches"][0]["name"])
+
+	w, _ = get("?node=missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, query := range []string{"?node=sw-1&depth=-1", "?node=sw-1&depth=100", "?depth=2", "?selector==web", "?labels=app+in+prod"} {
+		w, _ = get(query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestTopologyHandler_GetPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(&services.Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Name: "web", Ports: []string{"port-1", "port-2"}},
+			{UUID: "sw-2", Name: "db", Ports: []string{"port-3"}},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "port-1", Name: "web-1"},
+			{UUID: "port-2", Name: "web-2"},
+			{UUID: "port-3", Name: "db-1"},
+		},
+	}, nil)
+	mockService.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{
+		{UUID: "acl-1", Priority: 1000, Direction: "from-lport", Match: `inport == "web-1"`, Action: "drop"},
+		{UUID: "acl-2", Priority: 1000, Direction: "from-lport", Match: `inport == "web-2"`, Action: "drop"},
+	}, nil)
+	router := gin.New()
+	router.GET("/topology/path", NewTopologyHandler(mockService, nil).GetPath)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/topology/path"+query, nil))
+		return w
+	}
+
+	w := get("?from=web-1&to=port-2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var path struct {
+		Layer string
+		Hops  []struct {
+			ID   string
+			Type string
+			ACLs []models.ACL
+		}
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &path))
+	assert.Equal(t, "L2", path.Layer)
+	require.Len(t, path.Hops, 3)
+	assert.Equal(t, "sw-1", path.Hops[1].ID)
+	require.Len(t, path.Hops[1].ACLs, 1)
+	assert.Equal(t, "acl-1", path.Hops[1].ACLs[0].UUID)
+
+	assert.Equal(t, http.StatusBadRequest, get("?from=web-1").Code)
+	assert.Equal(t, http.StatusNotFound, get("?from=web-1&to=missing").Code)
+	assert.Equal(t, http.StatusNotFound, get("?from=web-1&to=db-1").Code)
+}
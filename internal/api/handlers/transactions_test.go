@@ -7,15 +7,30 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
+// mockTransaction expects a transaction returning err, which assigns ids to
+// its operations in order when it succeeds
+func mockTransaction(m *MockOVNService, err error, ids ...string) {
+	m.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if err != nil {
+			return
+		}
+		ops := args.Get(1).([]services.TransactionOp)
+		for i, id := range ids {
+			ops[i].ResourceID = id
+		}
+	}).Return(err)
+}
+
 func TestTransactionHandler_Execute(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -49,18 +64,7 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				},
 			},
 			setupMocks: func(m *MockOVNService) {
-				m.On("CreateLogicalSwitch", mock.Anything, mock.Anything).Return(&models.LogicalSwitch{
-					UUID:      "switch-uuid",
-					Name:      "test-switch",
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
-				m.On("CreateLogicalRouter", mock.Anything, mock.Anything).Return(&models.LogicalRouter{
-					UUID:      "router-uuid",
-					Name:      "test-router",
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
+				mockTransaction(m, nil, "switch-uuid", "router-uuid")
 			},
 			expectedStatus: http.StatusOK,
 			validateResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -110,22 +114,13 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				},
 			},
 			setupMocks: func(m *MockOVNService) {
-				m.On("CreatePort", mock.Anything, "switch-uuid", mock.Anything).Return(&models.LogicalSwitchPort{
-					UUID:      "port-uuid",
-					Name:      "test-port",
-					Addresses: []string{"00:00:00:00:00:01"},
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
-				m.On("CreateACL", mock.Anything, "switch-uuid", mock.Anything).Return(&models.ACL{
-					UUID:      "acl-uuid",
-					Priority:  100,
-					Direction: "from-lport",
-					Match:     "ip4.src == 10.0.0.1",
-					Action:    "allow",
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
+				m.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []services.TransactionOp) bool {
+					return len(ops) == 2 && ops[0].SwitchID == "switch-uuid" && ops[1].SwitchID == "switch-uuid"
+				})).Run(func(args mock.Arguments) {
+					ops := args.Get(1).([]services.TransactionOp)
+					ops[0].ResourceID = "port-uuid"
+					ops[1].ResourceID = "acl-uuid"
+				}).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			validateResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -135,7 +130,7 @@ func TestTransactionHandler_Execute(t *testing.T) {
 			},
 		},
 		{
-			name: "failed transaction with rollback",
+			name: "failed transaction applies nothing",
 			requestBody: map[string]interface{}{
 				"operations": []map[string]interface{}{
 					{
@@ -157,17 +152,7 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				},
 			},
 			setupMocks: func(m *MockOVNService) {
-				// First operation succeeds
-				m.On("CreateLogicalSwitch", mock.Anything, mock.Anything).Return(&models.LogicalSwitch{
-					UUID:      "switch-uuid",
-					Name:      "test-switch",
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
-				// Second operation fails
-				m.On("CreateLogicalRouter", mock.Anything, mock.Anything).Return(nil, errors.New("router creation failed"))
-				// Rollback
-				m.On("DeleteLogicalSwitch", mock.Anything, "switch-uuid").Return(nil)
+				mockTransaction(m, &ovn.TxError{Index: 1, Err: errors.New("router creation failed")})
 			},
 			expectedStatus: http.StatusBadRequest,
 			validateResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -176,13 +161,40 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				results := resp["results"].([]interface{})
 				assert.Len(t, results, 2)
 				
-				// First operation succeeded
+				// First operation was rolled back with the transaction
 				op1 := results[0].(map[string]interface{})
-				assert.True(t, op1["success"].(bool))
+				assert.False(t, op1["success"].(bool))
+				assert.Equal(t, "not executed due to transaction failure", op1["error"])
 				
 				// Second operation failed
 				op2 := results[1].(map[string]interface{})
 				assert.False(t, op2["success"].(bool))
+				assert.Equal(t, "router creation failed", op2["error"])
+			},
+		},
+		{
+			name: "tenant quota exceeded",
+			requestBody: map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"id":       "op1",
+						"type":     "create",
+						"resource": "switch",
+						"data": map[string]interface{}{
+							"name": "test-switch",
+						},
+					},
+				},
+			},
+			setupMocks: func(m *MockOVNService) {
+				mockTransaction(m, &services.QuotaExceededError{ResourceType: "switch", Current: 5, Requested: 1, Limit: 5})
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			validateResponse: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "quota exceeded", resp["error"])
+				quota := resp["quota"].(map[string]interface{})
+				assert.Equal(t, "switch", quota["resource_type"])
+				assert.Equal(t, float64(5), quota["limit"])
 			},
 		},
 		{
@@ -207,13 +219,7 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				},
 			},
 			setupMocks: func(m *MockOVNService) {
-				m.On("UpdateLogicalSwitch", mock.Anything, "switch-uuid", mock.Anything).Return(&models.LogicalSwitch{
-					UUID:      "switch-uuid",
-					Name:      "updated-switch",
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}, nil)
-				m.On("DeleteLogicalRouter", mock.Anything, "router-uuid").Return(nil)
+				mockTransaction(m, nil)
 			},
 			expectedStatus: http.StatusOK,
 			validateResponse: func(t *testing.T, resp map[string]interface{}) {
@@ -228,6 +234,83 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "router with NAT and load balancer referencing earlier creates",
+			requestBody: map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"id":       "edge",
+						"type":     "create",
+						"resource": "router",
+						"data": map[string]interface{}{
+							"name": "edge",
+						},
+					},
+					{
+						"id":        "snat",
+						"type":      "create",
+						"resource":  "nat",
+						"router_id": "$edge",
+						"data": map[string]interface{}{
+							"type":        "snat",
+							"external_ip": "203.0.113.1",
+							"logical_ip":  "10.0.0.0/24",
+						},
+					},
+					{
+						"id":       "web",
+						"type":     "create",
+						"resource": "load_balancer",
+						"data": map[string]interface{}{
+							"name":     "web",
+							"vips":     map[string]string{"203.0.113.10:80": "10.0.0.2:80"},
+							"protocol": "tcp",
+						},
+					},
+				},
+			},
+			setupMocks: func(m *MockOVNService) {
+				m.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []services.TransactionOp) bool {
+					return len(ops) == 3 && ops[1].ResourceType == models.ResourceNAT &&
+						ops[1].RouterID == "$edge" && ops[0].Ref == "edge"
+				})).Run(func(args mock.Arguments) {
+					ops := args.Get(1).([]services.TransactionOp)
+					ops[0].ResourceID = "router-uuid"
+					ops[1].ResourceID = "nat-uuid"
+					ops[2].ResourceID = "lb-uuid"
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			validateResponse: func(t *testing.T, resp map[string]interface{}) {
+				assert.True(t, resp["success"].(bool))
+				results := resp["results"].([]interface{})
+				assert.Len(t, results, 3)
+				assert.Equal(t, "nat-uuid", results[1].(map[string]interface{})["resource_id"])
+				assert.Equal(t, "lb-uuid", results[2].(map[string]interface{})["resource_id"])
+			},
+		},
+		{
+			name: "not connected",
+			requestBody: map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"id":       "op1",
+						"type":     "create",
+						"resource": "address_set",
+						"data": map[string]interface{}{
+							"name": "web",
+						},
+					},
+				},
+			},
+			setupMocks: func(m *MockOVNService) {
+				mockTransaction(m, errors.New("client not connected"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			validateResponse: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "OVN service unavailable", resp["error"])
+			},
+		},
 		{
 			name: "dry run validation",
 			requestBody: map[string]interface{}{
@@ -441,6 +524,58 @@ func TestTransactionHandler_Execute(t *testing.T) {
 				assert.Contains(t, resp["details"], "switch_id is required for port creation")
 			},
 		},
+		{
+			name: "validation error - create NAT without router_id",
+			requestBody: map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"id":       "op1",
+						"type":     "create",
+						"resource": "nat",
+						"data": map[string]interface{}{
+							"type": "snat",
+						},
+					},
+				},
+			},
+			setupMocks:     func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateResponse: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "validation failed", resp["error"])
+				assert.Contains(t, resp["details"], "router_id is required for nat creation")
+			},
+		},
+		{
+			name: "validation error - reference to a later or different resource",
+			requestBody: map[string]interface{}{
+				"operations": []map[string]interface{}{
+					{
+						"id":       "op1",
+						"type":     "create",
+						"resource": "router",
+						"data": map[string]interface{}{
+							"name": "edge",
+						},
+					},
+					{
+						"id":        "op2",
+						"type":      "create",
+						"resource":  "qos",
+						"switch_id": "$op1",
+						"data": map[string]interface{}{
+							"direction": "from-lport",
+							"match":     "inport == \"vm1\"",
+						},
+					},
+				},
+			},
+			setupMocks:     func(m *MockOVNService) {},
+			expectedStatus: http.StatusBadRequest,
+			validateResponse: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "validation failed", resp["error"])
+				assert.Contains(t, resp["details"], "$op1 does not refer to an earlier switch create operation")
+			},
+		},
 		{
 			name:           "invalid json",
 			requestBody:    "invalid json",
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// ResourceTrash soft-deletes resources into a recycle bin they can be
+// restored from. services.RecycleBin implements it.
+type ResourceTrash interface {
+	Delete(ctx context.Context, resource, id string) (*services.TrashedResource, error)
+	List(ctx context.Context) ([]*services.TrashedResource, error)
+	Get(ctx context.Context, id string) (*services.TrashedResource, error)
+	Restore(ctx context.Context, id string) (*services.TrashedResource, error)
+	Purge(ctx context.Context, id string) error
+}
+
+// TrashHandler serves the recycle bin at /api/v1/trash
+type TrashHandler struct {
+	trash ResourceTrash
+}
+
+// NewTrashHandler creates a handler
+func NewTrashHandler(trash ResourceTrash) *TrashHandler {
+	return &TrashHandler{trash: trash}
+}
+
+// List handles GET /trash, listing the deleted resources that can still be
+// restored, most recently deleted first
+func (h *TrashHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	items, err := h.trash.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	pageItems := pagination.Slice(items, page)
+	c.JSON(http.StatusOK, gin.H{
+		"items":      pageItems,
+		"count":      len(pageItems),
+		"pagination": pagination.Response(c, page, len(items)),
+	})
+}
+
+// Get handles GET /trash/:id, returning the archived definition of a
+// deleted resource
+func (h *TrashHandler) Get(c *gin.Context) {
+	item, err := h.trash.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Restore handles POST /trash/:id/restore, recreating the resource with a
+// new UUID reported as restored_id
+func (h *TrashHandler) Restore(c *gin.Context) {
+	item, err := h.trash.Restore(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// Purge handles DELETE /trash/:id, deleting a resource for good
+func (h *TrashHandler) Purge(c *gin.Context) {
+	if err := h.trash.Purge(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TrashHandler) handleError(c *gin.Context, err error) {
+	// A tenant quota doesn't allow the resource to be recreated
+	if respondQuotaExceeded(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrTrashedResourceNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "trashed resource not found"))
+	case strings.Contains(err.Error(), "already exists"):
+		problem.Respond(c, problem.New(http.StatusConflict, "cannot restore resource").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
+
+// deleteResource deletes a resource with del, or moves it to the recycle
+// bin when soft deletes are enabled, returning the recycle bin entry
+func deleteResource(ctx context.Context, trash ResourceTrash, resource, id string, del func(context.Context, string) error) (*services.TrashedResource, error) {
+	if trash == nil {
+		return nil, del(ctx, id)
+	}
+	return trash.Delete(ctx, resource, id)
+}
+
+// respondDeleted answers a successful delete: the recycle bin entry of a
+// soft-deleted resource, or no content
+func respondDeleted(c *gin.Context, trashed *services.TrashedResource) {
+	if trashed == nil {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+	c.JSON(http.StatusOK, trashed)
+}
+
+// respondNotRecyclable answers err if the resource can't be soft-deleted,
+// reporting whether it answered
+func respondNotRecyclable(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrNotRecyclable) {
+		return false
+	}
+	problem.Respond(c, problem.New(http.StatusConflict, "cannot delete resource").
+		WithError(err))
+	return true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestTrashHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockOVNService)
+	store, err := services.NewFileTrashStore(t.TempDir())
+	require.NoError(t, err)
+	bin := services.NewRecycleBin(store, mockService, time.Hour, zap.NewNop())
+
+	switchHandler := NewSwitchHandler(mockService)
+	switchHandler.SetRecycleBin(bin)
+	aclHandler := NewACLHandler(mockService)
+	aclHandler.SetRecycleBin(bin)
+	handler := NewTrashHandler(bin)
+
+	router := gin.New()
+	router.DELETE("/switches/:id", switchHandler.Delete)
+	router.DELETE("/acls/:id", aclHandler.Delete)
+	router.GET("/trash", handler.List)
+	router.GET("/trash/:id", handler.Get)
+	router.POST("/trash/:id/restore", handler.Restore)
+	router.DELETE("/trash/:id", handler.Purge)
+	request := func(method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var response map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	sw := &models.LogicalSwitch{UUID: "sw-1", Name: "web"}
+	mockService.On("GetLogicalSwitch", mock.Anything, "sw-1").Return(sw, nil)
+	mockService.On("ListPorts", mock.Anything, "sw-1").Return([]*models.LogicalSwitchPort{}, nil)
+	mockService.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{}, nil)
+	mockService.On("DeleteLogicalSwitch", mock.Anything, "sw-1").Return(nil)
+
+	// Soft deletes answer with the recycle bin entry
+	w, response := request("DELETE", "/switches/sw-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	id := response["id"].(string)
+	assert.Equal(t, "switch", response["resource"])
+	assert.Equal(t, "sw-1", response["resource_id"])
+
+	w, response = request("GET", "/trash")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(1), response["count"])
+
+	w, response = request("GET", "/trash/"+id)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "web", response["name"])
+
+	// A switch of the same name created since can't be restored over
+	mockService.On("CreateLogicalSwitch", mock.Anything, mock.Anything).
+		Return((*models.LogicalSwitch)(nil), errors.New("logical switch web already exists")).Once()
+	w, _ = request("POST", "/trash/"+id+"/restore")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockService.On("CreateLogicalSwitch", mock.Anything, mock.Anything).
+		Return(&models.LogicalSwitch{UUID: "sw-2", Name: "web"}, nil).Once()
+	w, response = request("POST", "/trash/"+id+"/restore")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "sw-2", response["restored_id"])
+
+	w, _ = request("GET", "/trash/"+id)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w, _ = request("DELETE", "/trash/"+id)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// ACLs on no switch aren't deleted at all
+	mockService.On("GetACL", mock.Anything, "acl-1").Return(&models.ACL{UUID: "acl-1"}, nil)
+	mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	w, _ = request("DELETE", "/acls/acl-1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertNotCalled(t, "DeleteACL", mock.Anything, mock.Anything)
+}
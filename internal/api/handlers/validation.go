@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// AddressValidator reports duplicate addresses. services.AddressValidator
+// implements it.
+type AddressValidator interface {
+	Validate(ctx context.Context) (*services.AddressReport, error)
+}
+
+// ValidationHandler serves on-demand checks of the logical network
+type ValidationHandler struct {
+	addresses AddressValidator
+}
+
+// NewValidationHandler creates a handler
+func NewValidationHandler(addresses AddressValidator) *ValidationHandler {
+	return &ValidationHandler{addresses: addresses}
+}
+
+// Addresses handles GET /api/v1/validate/addresses, listing each pair of
+// switch ports, router ports and NAT rules assigned the same MAC or IP
+// address
+func (h *ValidationHandler) Addresses(c *gin.Context) {
+	report, err := h.addresses.Validate(c.Request.Context())
+	if err != nil {
+		if strings.Contains(err.Error(), "not connected") {
+			problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+				WithError(err))
+			return
+		}
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/pagination"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// VPNConnections manages the VPN connections of tenant routers.
+// *services.VPNConnectionService implements it.
+type VPNConnections interface {
+	Create(ctx context.Context, req *services.CreateVPNConnectionRequest, user string) (*models.VPNConnection, error)
+	Get(ctx context.Context, id string) (*models.VPNConnection, error)
+	List(ctx context.Context) ([]*models.VPNConnection, error)
+	Update(ctx context.Context, id string, req *services.UpdateVPNConnectionRequest) (*models.VPNConnection, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// VPNConnectionHandler serves VPN connections at /api/v1/vpn-connections
+type VPNConnectionHandler struct {
+	conns VPNConnections
+}
+
+func NewVPNConnectionHandler(conns VPNConnections) *VPNConnectionHandler {
+	return &VPNConnectionHandler{conns: conns}
+}
+
+// List handles GET /vpn-connections, listing the tenant's connections, or
+// every tenant's without one. ?router_id= and ?state= filter them.
+func (h *VPNConnectionHandler) List(c *gin.Context) {
+	page, err := pagination.Parse(c)
+	if err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid query parameters").
+			WithError(err))
+		return
+	}
+
+	conns, err := h.conns.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	routerID, state := c.Query("router_id"), c.Query("state")
+	if routerID != "" || state != "" {
+		filtered := []*models.VPNConnection{}
+		for _, conn := range conns {
+			connState := models.VPNTunnelUnknown
+			if conn.Status != nil {
+				connState = conn.Status.State
+			}
+			if (routerID == "" || conn.RouterID == routerID) && (state == "" || connState == state) {
+				filtered = append(filtered, conn)
+			}
+		}
+		conns = filtered
+	}
+
+	pageItems := pagination.Slice(conns, page)
+	c.JSON(http.StatusOK, gin.H{
+		"vpn_connections": pageItems,
+		"count":           len(pageItems),
+		"pagination":      pagination.Response(c, page, len(conns)),
+	})
+}
+
+// Get handles GET /vpn-connections/:id, with the tunnel status last
+// collected
+func (h *VPNConnectionHandler) Get(c *gin.Context) {
+	conn, err := h.conns.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// Create handles POST /vpn-connections
+func (h *VPNConnectionHandler) Create(c *gin.Context) {
+	var req services.CreateVPNConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	conn, err := h.conns.Create(c.Request.Context(), &req, c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, conn)
+}
+
+// Update handles PUT /vpn-connections/:id; fields left out are kept
+func (h *VPNConnectionHandler) Update(c *gin.Context) {
+	var req services.UpdateVPNConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+			WithError(err))
+		return
+	}
+
+	conn, err := h.conns.Update(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conn)
+}
+
+// Delete handles DELETE /vpn-connections/:id
+func (h *VPNConnectionHandler) Delete(c *gin.Context) {
+	if err := h.conns.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *VPNConnectionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrVPNConnectionNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, "VPN connection not found"))
+	case errors.Is(err, services.ErrInvalidVPNConnection):
+		problem.Respond(c, problem.New(http.StatusBadRequest, "invalid VPN connection").
+			WithError(err))
+	case errors.Is(err, services.ErrVPNConnectionConflict):
+		problem.Respond(c, problem.New(http.StatusConflict, "VPN connection conflict").
+			WithError(err))
+	case errors.Is(err, services.ErrResourceNotInTenant):
+		problem.Respond(c, problem.New(http.StatusNotFound, "router not found").
+			WithError(err))
+	case strings.Contains(err.Error(), "not connected"):
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(problem.CodeOVNUnavailable).
+			WithDetail("unable to connect to OVN northbound database"))
+	case strings.Contains(err.Error(), "not found"):
+		problem.Respond(c, problem.New(http.StatusNotFound, "resource not found").
+			WithError(err))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeVPNConnections accepts connections of router lr-1 named once
+type fakeVPNConnections struct {
+	conns map[string]*models.VPNConnection
+}
+
+func (f *fakeVPNConnections) Create(ctx context.Context, req *services.CreateVPNConnectionRequest, user string) (*models.VPNConnection, error) {
+	if req.RouterID != "lr-1" {
+		return nil, services.ErrResourceNotInTenant
+	}
+	if !strings.Contains(req.PSKRef, ":") {
+		return nil, services.ErrInvalidVPNConnection
+	}
+	for _, conn := range f.conns {
+		if conn.Name == req.Name {
+			return nil, services.ErrVPNConnectionConflict
+		}
+	}
+	conn := &models.VPNConnection{ID: "vpn-1", Name: req.Name, RouterID: req.RouterID, PSKRef: req.PSKRef, CreatedBy: user}
+	f.conns[conn.ID] = conn
+	return conn, nil
+}
+
+func (f *fakeVPNConnections) Get(ctx context.Context, id string) (*models.VPNConnection, error) {
+	conn, ok := f.conns[id]
+	if !ok {
+		return nil, services.ErrVPNConnectionNotFound
+	}
+	return conn, nil
+}
+
+func (f *fakeVPNConnections) List(ctx context.Context) ([]*models.VPNConnection, error) {
+	conns := []*models.VPNConnection{}
+	for _, conn := range f.conns {
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+func (f *fakeVPNConnections) Update(ctx context.Context, id string, req *services.UpdateVPNConnectionRequest) (*models.VPNConnection, error) {
+	conn, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.PeerAddress != nil {
+		conn.PeerAddress = *req.PeerAddress
+	}
+	return conn, nil
+}
+
+func (f *fakeVPNConnections) Delete(ctx context.Context, id string) error {
+	if _, err := f.Get(ctx, id); err != nil {
+		return err
+	}
+	delete(f.conns, id)
+	return nil
+}
+
+func TestVPNConnectionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewVPNConnectionHandler(&fakeVPNConnections{conns: map[string]*models.VPNConnection{}})
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "alice") })
+	router.GET("/vpn-connections", handler.List)
+	router.GET("/vpn-connections/:id", handler.Get)
+	router.POST("/vpn-connections", handler.Create)
+	router.PUT("/vpn-connections/:id", handler.Update)
+	router.DELETE("/vpn-connections/:id", handler.Delete)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+	request := func(router, psk string) string {
+		return `{"name": "dc1", "router_id": "` + router + `", "gateway_ip": "10.0.0.254", "peer_address": "198.51.100.7",
+			"psk_ref": "` + psk + `", "local_subnets": ["10.0.0.0/24"], "remote_subnets": ["192.168.0.0/16"]}`
+	}
+
+	w := serve(http.MethodPost, "/vpn-connections", `{"name": "dc1"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the subnets and gateway are required")
+	w = serve(http.MethodPost, "/vpn-connections", request("lr-1", "s3cret"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodPost, "/vpn-connections", request("lr-other", "env:DC1_PSK"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(http.MethodPost, "/vpn-connections", request("lr-1", "env:DC1_PSK"))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var conn models.VPNConnection
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &conn))
+	assert.Equal(t, "alice", conn.CreatedBy)
+	w = serve(http.MethodPost, "/vpn-connections", request("lr-1", "env:DC1_PSK"))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Connections without a collected status are unknown
+	w = serve(http.MethodGet, "/vpn-connections?state=unknown", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	w = serve(http.MethodGet, "/vpn-connections?state=up", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+	w = serve(http.MethodGet, "/vpn-connections?router_id=lr-2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+
+	w = serve(http.MethodPut, "/vpn-connections/vpn-1", `{"peer_address": "198.51.100.8"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"peer_address":"198.51.100.8"`)
+
+	w = serve(http.MethodDelete, "/vpn-connections/vpn-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodGet, "/vpn-connections/vpn-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// WebhookManager manages webhooks. services.WebhookService implements it.
+type WebhookManager interface {
+	Create(ctx context.Context, tenantID string, webhook *models.Webhook) (*models.Webhook, string, error)
+	Get(ctx context.Context, tenantID, webhookID string) (*models.Webhook, error)
+	List(ctx context.Context, tenantID string) ([]*models.Webhook, error)
+	Update(ctx context.Context, tenantID, webhookID string, updates *models.Webhook) (*models.Webhook, error)
+	Delete(ctx context.Context, tenantID, webhookID string) error
+	Deliveries(ctx context.Context, tenantID, webhookID string, limit int) ([]*models.WebhookDelivery, error)
+}
+
+// WebhookHandler serves webhooks: global ones at /api/v1/webhooks, and a
+// tenant's at /api/v1/tenants/:id/webhooks
+type WebhookHandler struct {
+	webhooks WebhookManager
+}
+
+// NewWebhookHandler creates a handler
+func NewWebhookHandler(webhooks WebhookManager) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// WebhookRequest represents a webhook creation or update request
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`  // Empty subscribes to all events
+	Enabled     *bool    `json:"enabled"` // Default true
+	Secret      string   `json:"secret"`  // Generated on creation if empty; kept on update if empty
+}
+
+func (r *WebhookRequest) webhook() *models.Webhook {
+	return &models.Webhook{
+		URL:         r.URL,
+		Description: r.Description,
+		Events:      r.Events,
+		Enabled:     r.Enabled == nil || *r.Enabled,
+		Secret:      r.Secret,
+	}
+}
+
+// List handles GET /webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.webhooks.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// Create handles POST /webhooks. The secret payloads are signed with is
+// only returned here.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body").
+			WithError(err))
+		return
+	}
+
+	webhook := req.webhook()
+	webhook.CreatedBy = c.GetString("user_id")
+	webhook, secret, err := h.webhooks.Create(c.Request.Context(), c.Param("id"), webhook)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": webhook,
+		"secret":  secret,
+		"message": "Webhook created successfully. Please save the secret, it won't be shown again.",
+	})
+}
+
+// Get handles GET /webhooks/:webhook_id
+func (h *WebhookHandler) Get(c *gin.Context) {
+	webhook, err := h.webhooks.Get(c.Request.Context(), c.Param("id"), c.Param("webhook_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Update handles PUT /webhooks/:webhook_id
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request body").
+			WithError(err))
+		return
+	}
+
+	webhook, err := h.webhooks.Update(c.Request.Context(), c.Param("id"), c.Param("webhook_id"), req.webhook())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Delete handles DELETE /webhooks/:webhook_id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.webhooks.Delete(c.Request.Context(), c.Param("id"), c.Param("webhook_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries handles GET /webhooks/:webhook_id/deliveries?limit=50, listing
+// the most recent deliveries with their attempts and outcome
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhooks.Deliveries(c.Request.Context(), c.Param("id"), c.Param("webhook_id"), limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		problem.Respond(c, problem.New(http.StatusNotFound, err.Error()))
+	case errors.Is(err, services.ErrInvalidWebhook):
+		problem.Respond(c, problem.New(http.StatusBadRequest, err.Error()))
+	default:
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "internal server error").
+			WithError(err))
+	}
+}
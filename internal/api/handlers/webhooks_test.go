@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeWebhookManager keeps webhooks by tenant/ID
+type fakeWebhookManager struct {
+	webhooks map[string]*models.Webhook
+	limit    int
+}
+
+func (f *fakeWebhookManager) Create(ctx context.Context, tenantID string, webhook *models.Webhook) (*models.Webhook, string, error) {
+	if !strings.HasPrefix(webhook.URL, "https://") {
+		return nil, "", fmt.Errorf("%w: url must be an absolute http or https URL", services.ErrInvalidWebhook)
+	}
+	webhook.ID = fmt.Sprintf("wh-%d", len(f.webhooks)+1)
+	webhook.TenantID = tenantID
+	f.webhooks[tenantID+"/"+webhook.ID] = webhook
+	return webhook, "s3cret", nil
+}
+
+func (f *fakeWebhookManager) Get(ctx context.Context, tenantID, webhookID string) (*models.Webhook, error) {
+	webhook, ok := f.webhooks[tenantID+"/"+webhookID]
+	if !ok {
+		return nil, services.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (f *fakeWebhookManager) List(ctx context.Context, tenantID string) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	for _, webhook := range f.webhooks {
+		if webhook.TenantID == tenantID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (f *fakeWebhookManager) Update(ctx context.Context, tenantID, webhookID string, updates *models.Webhook) (*models.Webhook, error) {
+	webhook, err := f.Get(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	webhook.URL, webhook.Enabled = updates.URL, updates.Enabled
+	return webhook, nil
+}
+
+func (f *fakeWebhookManager) Delete(ctx context.Context, tenantID, webhookID string) error {
+	if _, err := f.Get(ctx, tenantID, webhookID); err != nil {
+		return err
+	}
+	delete(f.webhooks, tenantID+"/"+webhookID)
+	return nil
+}
+
+func (f *fakeWebhookManager) Deliveries(ctx context.Context, tenantID, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := f.Get(ctx, tenantID, webhookID); err != nil {
+		return nil, err
+	}
+	f.limit = limit
+	return []*models.WebhookDelivery{{ID: "d-1", WebhookID: webhookID, Status: models.WebhookDeliverySucceeded}}, nil
+}
+
+func TestWebhookHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &fakeWebhookManager{webhooks: make(map[string]*models.Webhook)}
+	handler := NewWebhookHandler(manager)
+	engine := gin.New()
+	for _, group := range []*gin.RouterGroup{engine.Group("/webhooks"), engine.Group("/tenants/:id/webhooks")} {
+		group.GET("", handler.List)
+		group.POST("", handler.Create)
+		group.GET("/:webhook_id", handler.Get)
+		group.PUT("/:webhook_id", handler.Update)
+		group.DELETE("/:webhook_id", handler.Delete)
+		group.GET("/:webhook_id/deliveries", handler.Deliveries)
+	}
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/tenants/acme/webhooks", `{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/tenants/acme/webhooks", `{"url": "ftp://example.com"}`).Code)
+
+	w := serve("POST", "/tenants/acme/webhooks", `{"url": "https://example.com/hook", "events": ["backup.failed"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Webhook models.Webhook `json:"webhook"`
+		Secret  string         `json:"secret"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "s3cret", created.Secret)
+	assert.True(t, created.Webhook.Enabled, "webhooks are enabled by default")
+	assert.Equal(t, "acme", created.Webhook.TenantID)
+	assert.NotContains(t, serve("GET", "/tenants/acme/webhooks/wh-1", "").Body.String(), "s3cret")
+
+	// A tenant's webhooks aren't global ones
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/webhooks/wh-1", "").Code)
+	assert.Contains(t, serve("GET", "/webhooks", "").Body.String(), `"count":0`)
+	assert.Contains(t, serve("GET", "/tenants/acme/webhooks", "").Body.String(), `"count":1`)
+
+	w = serve("PUT", "/tenants/acme/webhooks/wh-1", `{"url": "https://example.com/v2", "enabled": false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, manager.webhooks["acme/wh-1"].Enabled)
+
+	assert.Equal(t, http.StatusBadRequest, serve("GET", "/tenants/acme/webhooks/wh-1/deliveries?limit=0", "").Code)
+	w = serve("GET", "/tenants/acme/webhooks/wh-1/deliveries?limit=10", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10, manager.limit)
+	assert.Contains(t, w.Body.String(), `"d-1"`)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", "/tenants/acme/webhooks/wh-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/tenants/acme/webhooks/wh-1", "").Code)
+}
+
+func TestWebhookHandler_InternalError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	NewWebhookHandler(nil).handleError(c, errors.New("not implemented"))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+func serveReadiness(t *testing.T, h *HealthService) (int, map[string]DependencyStatus) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	h.RegisterHealthRoutes(engine)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body struct {
+		Dependencies map[string]DependencyStatus `json:"dependencies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body.Dependencies
+}
+
+func TestReadiness(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	h := &HealthService{logger: zap.NewNop(), checks: []dependencyCheck{
+		{name: "ovn_nb", critical: true, endpoint: "tcp:10.0.0.5:6641", check: up},
+		{name: "ovn_sb", check: down},
+		{name: "database", critical: true, check: up},
+		{name: "redis_cache"},
+	}}
+
+	code, deps := serveReadiness(t, h)
+	assert.Equal(t, http.StatusOK, code, "non-critical dependencies don't fail readiness")
+	assert.Equal(t, DependencyUp, deps["ovn_nb"].Status)
+	assert.Equal(t, "tcp:10.0.0.5:6641", deps["ovn_nb"].Endpoint)
+	assert.Equal(t, DependencyDown, deps["ovn_sb"].Status)
+	assert.Equal(t, "connection refused", deps["ovn_sb"].Error)
+	assert.Equal(t, DependencyDisabled, deps["redis_cache"].Status)
+
+	h.checks[0].check = down
+	code, deps = serveReadiness(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, DependencyDown, deps["ovn_nb"].Status)
+}
+
+func TestReadinessDatabaseReplica(t *testing.T) {
+	h := &HealthService{logger: zap.NewNop(), checks: []dependencyCheck{
+		{name: "database", critical: true, check: func(ctx context.Context) error { return nil }},
+	}}
+	h.AddDatabaseReplica("replica:5432", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	code, deps := serveReadiness(t, h)
+	assert.Equal(t, http.StatusOK, code, "reads fall back to the primary")
+	assert.Equal(t, DependencyDown, deps["database_replica"].Status)
+	assert.Equal(t, "replica:5432", deps["database_replica"].Endpoint)
+	assert.False(t, deps["database_replica"].Critical)
+}
+
+func TestLiveness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	h := &HealthService{logger: zap.NewNop(), checks: []dependencyCheck{
+		{name: "database", critical: true, check: func(ctx context.Context) error { return errors.New("down") }},
+	}}
+	h.RegisterHealthRoutes(engine)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "liveness ignores dependencies")
+}
+
+func TestProbeEndpoint(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, ovn.ProbeEndpoint(ctx, "tcp:"+listener.Addr().String()))
+	assert.NoError(t, ovn.ProbeEndpoint(ctx, "tcp:127.0.0.1:1,tcp:"+listener.Addr().String()), "any member of a cluster is enough")
+	assert.Error(t, ovn.ProbeEndpoint(ctx, "tcp:127.0.0.1:1"))
+	assert.Error(t, ovn.ProbeEndpoint(ctx, "http://127.0.0.1:1"))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/auth"
 	"github.com/lspecian/ovncp/internal/models"
 )
@@ -20,7 +21,7 @@ func AuthMiddleware(authService auth.Service) gin.HandlerFunc {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, "Authorization header required"))
 			c.Abort()
 			return
 		}
@@ -28,7 +29,7 @@ func AuthMiddleware(authService auth.Service) gin.HandlerFunc {
 		// Check Bearer prefix
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, "Invalid authorization header format"))
 			c.Abort()
 			return
 		}
@@ -38,7 +39,7 @@ func AuthMiddleware(authService auth.Service) gin.HandlerFunc {
 		// Validate token
 		user, err := authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, err.Error()))
 			c.Abort()
 			return
 		}
@@ -54,14 +55,14 @@ func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInterface, exists := c.Get(AuthUserKey)
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, "User not authenticated"))
 			c.Abort()
 			return
 		}
 		
 		user, ok := userInterface.(*models.User)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user context"})
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "Invalid user context"))
 			c.Abort()
 			return
 		}
@@ -81,7 +82,7 @@ func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
 		}
 		
 		if !hasRole {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			problem.Respond(c, problem.New(http.StatusForbidden, "Insufficient permissions"))
 			c.Abort()
 			return
 		}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -10,7 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 )
 
@@ -87,6 +90,22 @@ func (m *mockAuthService) LocalLogin(ctx context.Context, username, password str
 	return args.Get(0).(*models.Session), args.Error(1)
 }
 
+func (m *mockAuthService) ValidateProviderToken(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockAuthService) RefreshProviderToken(ctx context.Context, provider string, refreshToken string) (*models.Session, error) {
+	args := m.Called(ctx, provider, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	
@@ -170,7 +189,7 @@ func TestAuthMiddleware(t *testing.T) {
 			router.ServeHTTP(w, req)
 			
 			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assertJSONMembers(t, tt.expectedBody, w)
 			
 			mockAuth.AssertExpectations(t)
 		})
@@ -249,7 +268,7 @@ func TestRequireRole(t *testing.T) {
 			router.ServeHTTP(w, req)
 			
 			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assertJSONMembers(t, tt.expectedBody, w)
 		})
 	}
 }
@@ -330,4 +349,22 @@ func TestOptionalAuth(t *testing.T) {
 			mockAuth.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// assertJSONMembers checks the members of a response body that expected
+// names. Errors are problem details, which carry more.
+func assertJSONMembers(t *testing.T, expected string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if w.Code < http.StatusBadRequest {
+		assert.JSONEq(t, expected, w.Body.String())
+		return
+	}
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	var want, got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(expected), &want))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	for key, value := range want {
+		assert.Equal(t, value, got[key], key)
+	}
+	assert.Equal(t, problem.CodeForStatus(w.Code), got["code"])
+}
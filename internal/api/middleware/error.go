@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 )
 
 func ErrorHandler() gin.HandlerFunc {
@@ -15,18 +16,12 @@ func ErrorHandler() gin.HandlerFunc {
 			
 			switch err.Type {
 			case gin.ErrorTypePublic:
-				c.JSON(c.Writer.Status(), gin.H{
-					"error": err.Error(),
-				})
+				problem.Respond(c, problem.New(c.Writer.Status(), err.Error()))
 			case gin.ErrorTypeBind:
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Invalid request format",
-					"details": err.Error(),
-				})
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid request format").
+					WithError(err))
 			default:
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-				})
+				problem.Respond(c, problem.New(http.StatusInternalServerError, "Internal server error"))
 			}
 		}
 	}
@@ -36,10 +31,8 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-					"message": "An unexpected error occurred",
-				})
+				problem.Abort(c, problem.New(http.StatusInternalServerError, "Internal server error").
+					WithDetail("An unexpected error occurred"))
 			}
 		}()
 		
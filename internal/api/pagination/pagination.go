@@ -0,0 +1,203 @@
+// Package pagination pages API lists: it parses the limit and cursor query
+// parameters of list requests and describes the returned page with cursors
+// and a Link header.
+//
+// Cursors are opaque to clients. A cursor is only valid for the query it was
+// issued for; changing the filters or sort order while following cursors is
+// an error, changing the limit is not.
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultLimit is the page size of requests without a limit
+	DefaultLimit = 100
+	// MaxLimit is the largest page size a request may ask for
+	MaxLimit = 1000
+)
+
+// ErrInvalidCursor is returned for cursors that weren't issued by the API,
+// or were issued for another query
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pagingParams are the query parameters that select a page rather than
+// narrow or order the list; cursors stay valid when they change
+var pagingParams = []string{"cursor", "limit", "offset", "page", "fields"}
+
+// Page is the part of a list a request asks for
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// cursor is what a cursor token encodes: where the page starts and a
+// fingerprint of the query it belongs to
+type cursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"`
+}
+
+// Parse parses the paging query parameters of a list request:
+//
+//	limit               page size, 1 to MaxLimit; DefaultLimit if unset
+//	cursor              next_cursor or prev_cursor of an earlier page
+//	offset, page        deprecated; where the page starts, when there's no
+//	                    cursor
+func Parse(c *gin.Context) (Page, error) {
+	page := Page{Limit: DefaultLimit}
+
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > MaxLimit {
+			return Page{}, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+		}
+		page.Limit = parsed
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		if c.Query("offset") != "" || c.Query("page") != "" {
+			return Page{}, errors.New("cursor can't be combined with offset or page")
+		}
+		offset, err := decodeCursor(token, queryFingerprint(c.Request.URL.Query()))
+		if err != nil {
+			return Page{}, err
+		}
+		page.Offset = offset
+		return page, nil
+	}
+
+	if o := c.Query("offset"); o != "" {
+		parsed, err := strconv.Atoi(o)
+		if err != nil || parsed < 0 {
+			return Page{}, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = parsed
+	}
+
+	if p := c.Query("page"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil || parsed < 1 {
+			return Page{}, errors.New("page must be a positive integer")
+		}
+		page.Offset = (parsed - 1) * page.Limit
+	}
+
+	return page, nil
+}
+
+// Response describes the page of a list with total results: it sets the
+// Link header to the first, previous, next and last pages and returns the
+// pagination member of the response body
+func Response(c *gin.Context, page Page, total int) gin.H {
+	query := c.Request.URL.Query()
+	fingerprint := queryFingerprint(query)
+
+	pagination := gin.H{
+		"limit":       page.Limit,
+		"offset":      page.Offset,
+		"page":        page.Offset/page.Limit + 1,
+		"total_pages": (total + page.Limit - 1) / page.Limit,
+		"total_count": total,
+	}
+
+	var links []string
+	link := func(rel string, offset int) string {
+		token := encodeCursor(offset, fingerprint)
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", pageURL(c, query, page.Limit, token), rel))
+		return token
+	}
+
+	link("first", 0)
+	if page.Offset > 0 {
+		prev := max(0, page.Offset-page.Limit)
+		if prev >= total {
+			// Pages past the end go back to the last page
+			prev = max(0, lastOffset(total, page.Limit))
+		}
+		pagination["prev_cursor"] = link("prev", prev)
+	}
+	if next := page.Offset + page.Limit; next < total {
+		pagination["next_cursor"] = link("next", next)
+	}
+	if total > 0 {
+		link("last", lastOffset(total, page.Limit))
+	}
+
+	c.Writer.Header().Add("Link", strings.Join(links, ", "))
+	return pagination
+}
+
+// Slice returns the page of items, for lists that are paged in memory
+func Slice[T any](items []T, page Page) []T {
+	if page.Offset >= len(items) {
+		return items[:0]
+	}
+	end := min(page.Offset+page.Limit, len(items))
+	return items[page.Offset:end]
+}
+
+// lastOffset is where the last page of total results starts
+func lastOffset(total, limit int) int {
+	return (total - 1) / limit * limit
+}
+
+// pageURL is the URL of the request with the paging parameters replaced
+func pageURL(c *gin.Context, query url.Values, limit int, token string) string {
+	values := url.Values{}
+	for key, vals := range query {
+		values[key] = vals
+	}
+	values.Del("offset")
+	values.Del("page")
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("cursor", token)
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}
+	return u.String()
+}
+
+// queryFingerprint identifies the filters and sort order of a query
+func queryFingerprint(query url.Values) string {
+	values := url.Values{}
+	for key, vals := range query {
+		values[key] = vals
+	}
+	for _, param := range pagingParams {
+		values.Del(param)
+	}
+
+	sum := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeCursor(offset int, fingerprint string) string {
+	data, _ := json.Marshal(cursor{Offset: offset, Query: fingerprint})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token, fingerprint string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil || cur.Offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	if cur.Query != fingerprint {
+		return 0, fmt.Errorf("%w: it was issued for a list with other filters or sort order", ErrInvalidCursor)
+	}
+	return cur.Offset, nil
+}
@@ -0,0 +1,120 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c, w
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    Page
+		wantErr string
+	}{
+		{name: "defaults", query: "", want: Page{Limit: DefaultLimit}},
+		{name: "limit", query: "?limit=25", want: Page{Limit: 25}},
+		{name: "offset", query: "?limit=25&offset=30", want: Page{Limit: 25, Offset: 30}},
+		{name: "page", query: "?limit=25&page=3", want: Page{Limit: 25, Offset: 50}},
+		{name: "zero limit", query: "?limit=0", wantErr: "limit must be between 1 and 1000"},
+		{name: "limit too large", query: "?limit=1001", wantErr: "limit must be between 1 and 1000"},
+		{name: "negative offset", query: "?offset=-1", wantErr: "offset must be a non-negative integer"},
+		{name: "invalid page", query: "?page=0", wantErr: "page must be a positive integer"},
+		{name: "malformed cursor", query: "?cursor=abc!", wantErr: "invalid cursor"},
+		{name: "cursor and offset", query: "?cursor=abc&offset=10", wantErr: "cursor can't be combined with offset or page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testContext("/api/v1/switches" + tt.query)
+			page, err := Parse(c)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, page)
+		})
+	}
+}
+
+func TestResponse_FollowCursors(t *testing.T) {
+	c, w := testContext("/api/v1/switches?name=web&limit=2&page=2")
+	page, err := Parse(c)
+	require.NoError(t, err)
+
+	pagination := Response(c, page, 5)
+	assert.Equal(t, 2, pagination["limit"])
+	assert.Equal(t, 2, pagination["offset"])
+	assert.Equal(t, 2, pagination["page"])
+	assert.Equal(t, 3, pagination["total_pages"])
+	assert.Equal(t, 5, pagination["total_count"])
+
+	next := pagination["next_cursor"].(string)
+	prev := pagination["prev_cursor"].(string)
+	first := encodeCursor(0, queryFingerprint(c.Request.URL.Query()))
+	last := encodeCursor(4, queryFingerprint(c.Request.URL.Query()))
+	assert.Equal(t,
+		`</api/v1/switches?cursor=`+first+`&limit=2&name=web>; rel="first", `+
+			`</api/v1/switches?cursor=`+prev+`&limit=2&name=web>; rel="prev", `+
+			`</api/v1/switches?cursor=`+next+`&limit=2&name=web>; rel="next", `+
+			`</api/v1/switches?cursor=`+last+`&limit=2&name=web>; rel="last"`,
+		w.Header().Get("Link"))
+
+	// Following next_cursor returns the last page, which has no next page
+	c, _ = testContext("/api/v1/switches?name=web&limit=2&cursor=" + next)
+	page, err = Parse(c)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 2, Offset: 4}, page)
+	pagination = Response(c, page, 5)
+	assert.NotContains(t, pagination, "next_cursor")
+
+	// Cursors survive a change of page size
+	c, _ = testContext("/api/v1/switches?name=web&limit=10&cursor=" + prev)
+	page, err = Parse(c)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 10, Offset: 0}, page)
+
+	// but not of the filters
+	c, _ = testContext("/api/v1/switches?name=db&limit=2&cursor=" + next)
+	_, err = Parse(c)
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestResponse_Bounds(t *testing.T) {
+	// An empty list only links to its first page
+	c, w := testContext("/api/v1/acls?switch_id=ls1")
+	pagination := Response(c, Page{Limit: 20}, 0)
+	assert.Equal(t, 0, pagination["total_pages"])
+	assert.NotContains(t, pagination, "next_cursor")
+	assert.NotContains(t, pagination, "prev_cursor")
+	assert.Contains(t, w.Header().Get("Link"), `rel="first"`)
+	assert.NotContains(t, w.Header().Get("Link"), `rel="last"`)
+
+	// Pages past the end go back to the last page
+	c, _ = testContext("/api/v1/acls?switch_id=ls1")
+	pagination = Response(c, Page{Limit: 20, Offset: 100}, 45)
+	offset, err := decodeCursor(pagination["prev_cursor"].(string), queryFingerprint(c.Request.URL.Query()))
+	require.NoError(t, err)
+	assert.Equal(t, 40, offset)
+}
+
+func TestSlice(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	assert.Equal(t, []string{"a", "b"}, Slice(items, Page{Limit: 2}))
+	assert.Equal(t, []string{"e"}, Slice(items, Page{Limit: 2, Offset: 4}))
+	assert.Empty(t, Slice(items, Page{Limit: 2, Offset: 6}))
+}
@@ -0,0 +1,230 @@
+// Package problem writes API errors as RFC 7807 problem details
+// (application/problem+json), with a machine-readable code, a link to the
+// code's documentation and, for invalid requests, the errors of each field.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// DocsURL is where the error codes are documented; a problem's type is the
+// section documenting its code
+const DocsURL = "https://github.com/lspecian/ovncp/blob/main/docs/errors.md"
+
+// Error codes, documented in docs/errors.md
+const (
+	CodeInvalidRequest        = "invalid_request"
+	CodeValidationFailed      = "validation_failed"
+	CodeUnauthorized          = "unauthorized"
+	CodeForbidden             = "forbidden"
+	CodeNotFound              = "not_found"
+	CodeConflict              = "conflict"
+	CodeHasDependents         = "has_dependents"
+	CodeResourceOwned         = "resource_owned"
+	CodePreconditionFailed    = "precondition_failed"
+	CodePayloadTooLarge       = "payload_too_large"
+	CodeUnprocessable         = "unprocessable"
+	CodeResourceLocked        = "resource_locked"
+	CodeMaintenance           = "maintenance"
+	CodeQuotaExceeded         = "quota_exceeded"
+	CodeRateLimited           = "rate_limited"
+	CodeInternal              = "internal_error"
+	CodeNotImplemented        = "not_implemented"
+	CodeCapabilityUnavailable = "capability_unavailable"
+	CodeOVNUnavailable        = "ovn_unavailable"
+	CodeUnavailable           = "service_unavailable"
+	CodeTimeout               = "timeout"
+)
+
+// statusCodes are the codes of problems that don't name one, by status
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeInvalidRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusLocked:                CodeResourceLocked,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusNotImplemented:        CodeNotImplemented,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// FieldError is what's wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"` // JSON path of the field, e.g. "ports[0].name"
+	Code    string `json:"code"`  // The failed rule, e.g. "required"
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details object. Members that aren't part
+// of RFC 7807 are extensions; error and details repeat the title and
+// detail for clients of the earlier {"error", "details"} responses.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+
+	extensions map[string]interface{}
+}
+
+// New creates the problem of a response with status, summarized by title.
+// Its code is that of the status until set with WithCode.
+func New(status int, title string) *Problem {
+	p := &Problem{
+		Title:  title,
+		Status: status,
+	}
+	return p.WithCode(CodeForStatus(status))
+}
+
+// CodeForStatus returns the code of problems with status that don't name
+// one
+func CodeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
+
+// WithCode sets the problem's code, and its type to the code's
+// documentation
+func (p *Problem) WithCode(code string) *Problem {
+	p.Code = code
+	p.Type = DocsURL + "#" + code
+	return p
+}
+
+// WithDetail explains this occurrence of the problem
+func (p *Problem) WithDetail(detail string) *Problem {
+	p.Detail = detail
+	return p
+}
+
+// WithError explains the problem with err. The field errors of invalid
+// request bodies are listed, and their problems become validation_failed.
+func (p *Problem) WithError(err error) *Problem {
+	if err == nil {
+		return p
+	}
+	p.Detail = err.Error()
+	if fields := FieldErrors(err); len(fields) > 0 {
+		return p.WithErrors(fields...)
+	}
+	return p
+}
+
+// WithErrors lists what's wrong with the fields of a request; the problem
+// becomes validation_failed
+func (p *Problem) WithErrors(errs ...FieldError) *Problem {
+	p.Errors = append(p.Errors, errs...)
+	if p.Code == CodeInvalidRequest {
+		p.WithCode(CodeValidationFailed)
+	}
+	return p
+}
+
+// WithFieldError reports what's wrong with one field of a request, which
+// also explains the problem unless it already has a detail
+func (p *Problem) WithFieldError(field, code, message string) *Problem {
+	if p.Detail == "" {
+		p.Detail = message
+	}
+	return p.WithErrors(Field(field, code, message))
+}
+
+// With adds an extension member, such as the resources in conflict
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.extensions == nil {
+		p.extensions = make(map[string]interface{})
+	}
+	p.extensions[key] = value
+	return p
+}
+
+// Error makes problems errors
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+// MarshalJSON writes the RFC 7807 members, the extensions and the earlier
+// error and details members
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.extensions)+9)
+	for key, value := range p.extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code
+	members["error"] = p.Title
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+		members["details"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		members["errors"] = p.Errors
+	}
+	return json.Marshal(members)
+}
+
+// Respond writes p as the response. The problem's instance is the request
+// path, and the request ID is added when there is one.
+func Respond(c *gin.Context, p *Problem) {
+	if p.Instance == "" && c.Request != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	if requestID := c.GetString("request_id"); requestID != "" {
+		p.With("request_id", requestID)
+	}
+	c.Render(p.Status, render{problem: p})
+}
+
+// Abort writes p as the response and stops the remaining handlers
+func Abort(c *gin.Context, p *Problem) {
+	c.Abort()
+	Respond(c, p)
+}
+
+// render writes problems as application/problem+json
+type render struct {
+	problem *Problem
+}
+
+func (r render) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	data, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (r render) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
@@ -0,0 +1,115 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, handler gin.HandlerFunc, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/v1/switches", func(c *gin.Context) {
+		c.Set("request_id", "req-1")
+	}, handler)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/switches", strings.NewReader(body)))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response
+}
+
+func TestRespond(t *testing.T) {
+	w, response := serve(t, func(c *gin.Context) {
+		Respond(c, New(http.StatusConflict, "cannot delete switch").
+			WithCode(CodeHasDependents).
+			WithDetail("switch has 2 dependents").
+			With("dependents", []string{"p-1", "p-2"}))
+	}, "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]interface{}{
+		"type":       DocsURL + "#has_dependents",
+		"title":      "cannot delete switch",
+		"status":     float64(409),
+		"detail":     "switch has 2 dependents",
+		"instance":   "/api/v1/switches",
+		"code":       "has_dependents",
+		"dependents": []interface{}{"p-1", "p-2"},
+		"request_id": "req-1",
+		// Members of the earlier error responses
+		"error":   "cannot delete switch",
+		"details": "switch has 2 dependents",
+	}, response)
+}
+
+func TestCodeForStatus(t *testing.T) {
+	assert.Equal(t, CodeNotFound, New(http.StatusNotFound, "switch not found").Code)
+	assert.Equal(t, CodeOVNUnavailable, New(http.StatusServiceUnavailable, "OVN service unavailable").WithCode(CodeOVNUnavailable).Code)
+	assert.Equal(t, CodeInvalidRequest, CodeForStatus(http.StatusMethodNotAllowed))
+	assert.Equal(t, CodeInternal, CodeForStatus(http.StatusBadGateway))
+}
+
+func TestWithError_FieldErrors(t *testing.T) {
+	type portRequest struct {
+		Name      string   `json:"name" binding:"required"`
+		Addresses []string `json:"addresses" binding:"required,min=1"`
+		Type      string   `json:"type" binding:"omitempty,oneof=router localnet"`
+		Priority  int      `json:"priority" binding:"max=32767"`
+	}
+	bind := func(c *gin.Context) {
+		var req portRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Respond(c, New(http.StatusBadRequest, "invalid request body").WithError(err))
+			return
+		}
+		c.Status(http.StatusCreated)
+	}
+
+	w, response := serve(t, bind, `{"addresses": [], "type": "vif", "priority": 40000}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, response["code"])
+	assert.Equal(t, DocsURL+"#validation_failed", response["type"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "name", "code": "required", "message": "is required"},
+		map[string]interface{}{"field": "addresses", "code": "min", "message": "must be at least 1 items"},
+		map[string]interface{}{"field": "type", "code": "oneof", "message": "must be one of: router, localnet"},
+		map[string]interface{}{"field": "priority", "code": "max", "message": "must be at most 32767"},
+	}, response["errors"])
+
+	// Values of the wrong type are reported against their field
+	_, response = serve(t, bind, `{"name": "web", "addresses": ["dynamic"], "priority": "high"}`)
+	assert.Equal(t, CodeValidationFailed, response["code"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "priority", "code": "type", "message": "must be an integer, not string"},
+	}, response["errors"])
+
+	// Malformed bodies have no field errors
+	_, response = serve(t, bind, `{"name":`)
+	assert.Equal(t, CodeInvalidRequest, response["code"])
+	assert.Equal(t, "unexpected EOF", response["detail"])
+	assert.Nil(t, response["errors"])
+}
+
+func TestWithFieldError(t *testing.T) {
+	p := New(http.StatusBadRequest, "validation failed").WithFieldError("name", "required", "name is required")
+	assert.Equal(t, CodeValidationFailed, p.Code)
+	assert.Equal(t, "name is required", p.Detail)
+	assert.Equal(t, []FieldError{{Field: "name", Code: "required", Message: "name is required"}}, p.Errors)
+	assert.Equal(t, "validation failed: name is required", p.Error())
+
+	// Other errors keep their detail and code
+	p = New(http.StatusNotFound, "switch not found").WithError(errors.New("logical switch web not found"))
+	assert.Equal(t, CodeNotFound, p.Code)
+	assert.Empty(t, p.Errors)
+}
@@ -0,0 +1,137 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Field errors name fields as requests do, by their JSON names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the JSON name of a struct field, or "" for fields
+// that aren't serialized
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// Field reports what's wrong with a field
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+// FieldErrors returns the field errors of binding or decoding a request
+// body, or nil for other errors
+func FieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("must be %s, not %s", typeName(typeErr.Type), typeErr.Value),
+		}}
+	}
+	return nil
+}
+
+// fieldPath drops the struct name a validator namespace starts with
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// validationMessage describes a failed validation rule
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), lengthUnit(fe.Kind()))
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), lengthUnit(fe.Kind()))
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	case "cidr", "cidrv4", "cidrv6":
+		return "must be a CIDR"
+	case "ip", "ipv4", "ipv6":
+		return "must be an IP address"
+	case "mac":
+		return "must be a MAC address"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "email":
+		return "must be an email address"
+	case "url":
+		return "must be a URL"
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("must satisfy %s", fe.Tag())
+}
+
+// lengthUnit is what min and max count for values of kind: characters,
+// items or, for numbers, the values themselves
+func lengthUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return " items"
+	}
+	return ""
+}
+
+// typeName names a Go type as the JSON type it's decoded from
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "a value"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
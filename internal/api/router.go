@@ -1,15 +1,28 @@
 package api
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lspecian/ovncp/internal/api/handlers"
+	"github.com/lspecian/ovncp/internal/api/versioning"
 	"github.com/lspecian/ovncp/internal/auth"
+	"github.com/lspecian/ovncp/internal/backup"
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/cluster"
 	"github.com/lspecian/ovncp/internal/config"
 	"github.com/lspecian/ovncp/internal/db"
+	"github.com/lspecian/ovncp/internal/logging"
+	"github.com/lspecian/ovncp/internal/metering"
+	"github.com/lspecian/ovncp/internal/metrics"
 	"github.com/lspecian/ovncp/internal/middleware"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/secrets"
 	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/internal/visualization"
+	"github.com/lspecian/ovncp/pkg/ovn"
 	"go.uber.org/zap"
 )
 
@@ -17,25 +30,103 @@ type Router struct {
 	engine              *gin.Engine
 	ovnService          services.OVNServiceInterface
 	tenantService       *services.TenantService
+	tenantChanges       *services.TenantChangeService
+	tenantReclaimer     *services.TenantReclaimer
+	tenantUsage         *services.TenantUsageRecorder
+	topologyHistory     *services.TopologyHistory
+	trafficMonitor      *services.TrafficMonitor
+	graphCache          *visualization.GraphCache
+	ovsStats            *ovn.OVSStatsClient
+	aclStats            *services.ACLStatsRecorder
+	aclCollector        *ovn.ACLStatsCollector
+	aclStatsHandler     *handlers.ACLStatsHandler
+	aclLogs             *services.ACLLogIngester
+	aclLogHandler       *handlers.ACLLogHandler
+	aclSimulationHandler *handlers.ACLSimulationHandler
+	reportHandler       *handlers.ReportHandler
+	compliance          *services.ComplianceEngine
+	complianceHandler   *handlers.ComplianceHandler
+	drift               *backup.DriftDetector // nil when the backup routes failed to register
+	webhooks            *services.WebhookService
+	notifications       *services.NotificationService
+	notificationHandler *handlers.NotificationHandler
+	events              *services.EventBus
+	resourceHistory     *services.ResourceHistory
+	resourceHistoryHandler *handlers.ResourceHistoryHandler
+	webhookHandler      *handlers.WebhookHandler
+	ovnConnectivity     *services.OVNConnectivityMonitor
+	validationHandler   *handlers.ValidationHandler
+	gatewayHandler      *handlers.GatewayHandler
+	chassisInventory    *ovn.ChassisInventory
+	chassisHandler      *handlers.ChassisHandler
+	capabilityHandler   *handlers.CapabilityHandler
+	neutronHandler      *handlers.NeutronHandler
+	meter               *metering.Meter
+	cache               cache.Cache
+	cachedOVN           *services.CachedOVNService
 	authService         auth.Service
 	authHandler         *handlers.AuthHandler
 	switchHandler       *handlers.SwitchHandler
 	routerHandler       *handlers.RouterHandler
+	routerPolicyHandler *handlers.RouterPolicyHandler
+	staticRouteHandler  *handlers.StaticRouteHandler
 	portHandler         *handlers.PortHandler
 	aclHandler          *handlers.ACLHandler
+	loadBalancerHandler *handlers.LoadBalancerHandler
+	dnsHandler          *handlers.DNSHandler
+	mirrorHandler       *handlers.MirrorHandler
+	samplingHandler     *handlers.SamplingHandler
+	labelHandler        *handlers.LabelHandler
+	applyHandler        *handlers.ApplyHandler
+	importHandler       *handlers.ImportHandler
+	exportHandler       *handlers.ExportHandler
+	networkPolicyHandler *handlers.NetworkPolicyHandler
 	transactionHandler  *handlers.TransactionHandler
+	chunkedTransactionHandler *handlers.ChunkedTransactionHandler
+	recycleBin          *services.RecycleBin
+	trashHandler        *handlers.TrashHandler
+	resourceLocks       *services.ResourceLockService
+	maintenance         *services.MaintenanceService
+	aclRollouts         *services.ACLRolloutService
+	aclRolloutHandler   *handlers.ACLRolloutHandler
+	floatingIPHandler   *handlers.FloatingIPHandler
+	providerNetworkHandler *handlers.ProviderNetworkHandler
+	vpnConnections      *services.VPNConnectionService
+	vpnConnectionHandler *handlers.VPNConnectionHandler
+	serviceCatalog      *services.ServiceCatalog
+	serviceCatalogHandler *handlers.ServiceCatalogHandler
+	aclSchedules        *services.ACLScheduleService
+	aclScheduleHandler  *handlers.ACLScheduleHandler
+	accessGrants        *services.AccessGrantService
+	accessGrantHandler  *handlers.AccessGrantHandler
+	lockHandler         *handlers.LockHandler
 	topologyHandler     *handlers.TopologyHandler
+	topologyDiffHandler *handlers.TopologyDiffHandler
+	diagnostics         *services.DiagnosticsCollector
+	northdHandler       *handlers.NorthdConsistencyHandler // nil without a default cluster
+	ovnStatus           ovnStatusProvider
+	clusters            *services.OVNClusterManager
+	drStaging           *services.OVNClusterManager // Where backups are verified, nil unless configured
 	config              *config.Config
-	db                  *db.DB
+	configReloader      *services.ConfigReloader
+	leader              *cluster.LeaderElector
+	jwtSecret           *secrets.Secret
+	db                  db.Repository
 	logger              *zap.Logger
 }
 
-func NewRouter(ovnService services.OVNServiceInterface, cfg *config.Config, database *db.DB, logger *zap.Logger) *Router {
+// NewRouter creates the API router. JWTs are verified with jwtSecret,
+// which may be rotated while the API serves.
+func NewRouter(ovnService services.OVNServiceInterface, clusters *services.OVNClusterManager, cfg *config.Config, database db.Repository, jwtSecret *secrets.Secret, logger *zap.Logger) *Router {
 	// Set Gin mode based on environment
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// The last errors logged are kept for diagnostics bundles
+	recentErrors := logging.NewErrorRecorder(recentErrorCount)
+	logger = logger.WithOptions(zap.WrapCore(recentErrors.Tee))
+
 	// Create tenant service
 	tenantService := services.NewTenantService(database, logger)
 
@@ -45,26 +136,329 @@ func NewRouter(ovnService services.OVNServiceInterface, cfg *config.Config, data
 		logger.Fatal("Failed to create auth service", zap.Error(err))
 	}
 
+	// OVN reads are cached when a cache is configured
+	ovnCache, err := cache.New(&cfg.Cache, logger)
+	if err != nil {
+		logger.Fatal("Failed to create cache", zap.Error(err))
+	}
+	var backend services.OVNServiceInterface = services.NewInstrumentedOVNService(ovnService)
+	var cachedOVN *services.CachedOVNService
+	if ovnCache != nil {
+		cachedOVN = services.NewCachedOVNService(backend, ovnCache, logger)
+		backend = cachedOVN
+	}
+
 	// Create tenant-aware OVN service wrapper
-	tenantAwareOVN := services.NewTenantOVNService(ovnService, tenantService)
+	// Reads are served from snapshots while OVN is unreachable
+	tenantAwareOVN := services.NewTenantOVNService(services.NewSnapshotOVNService(backend), tenantService)
+
+	// Topology diffs read OVN directly rather than stale snapshots, and
+	// filter by tenant themselves
+	topologyHistory := services.NewTopologyHistory(database, backend, clusters,
+		cfg.Topology.SnapshotInterval, cfg.Topology.SnapshotRetention, logger)
+	if storage, err := backup.NewFileStorage(cfg.GetBackupPath()); err != nil {
+		logger.Warn("Backups are not available to topology diffs", zap.Error(err))
+	} else {
+		topologyHistory.SetBackupSource(backup.TopologySource(storage))
+	}
+
+	// New port addresses are checked against those already assigned, and
+	// "auto" MACs are generated from the configured pools
+	addressValidator := services.NewAddressValidator(tenantAwareOVN)
+	macAllocator, err := services.NewMACAllocator(ovnService, cfg.MACPools)
+	if err != nil {
+		logger.Fatal("Invalid MAC pools", zap.Error(err))
+	}
+
+	// The chassis and port bindings are read from the southbound database,
+	// connected to on first use
+	chassisInventory := ovn.NewChassisInventory(&cfg.OVN)
 
 	r := &Router{
 		engine:             gin.New(),
 		ovnService:         tenantAwareOVN,
 		tenantService:      tenantService,
+		tenantChanges:      services.NewTenantChangeService(database, tenantService, tenantAwareOVN, logger),
+		tenantReclaimer:    services.NewTenantReclaimer(database, ovnService, cfg.Tenancy.ReclaimInterval, logger),
+		tenantUsage:        services.NewTenantUsageRecorder(database, cfg.Tenancy.UsageSnapshotInterval, logger),
+		topologyHistory:    topologyHistory,
 		authService:        authService,
 		authHandler:        handlers.NewAuthHandler(authService),
 		switchHandler:      handlers.NewSwitchHandler(tenantAwareOVN),
 		routerHandler:      handlers.NewRouterHandler(tenantAwareOVN),
-		portHandler:        handlers.NewPortHandler(tenantAwareOVN),
+		routerPolicyHandler: handlers.NewRouterPolicyHandler(tenantAwareOVN),
+		staticRouteHandler:  handlers.NewStaticRouteHandler(tenantAwareOVN),
+		portHandler:        handlers.NewPortHandler(tenantAwareOVN, addressValidator, macAllocator, chassisInventory),
+		chassisInventory:    chassisInventory,
 		aclHandler:         handlers.NewACLHandler(tenantAwareOVN),
+		loadBalancerHandler: handlers.NewLoadBalancerHandler(tenantAwareOVN),
+		dnsHandler:          handlers.NewDNSHandler(tenantAwareOVN),
+		mirrorHandler:       handlers.NewMirrorHandler(tenantAwareOVN),
+		samplingHandler:     handlers.NewSamplingHandler(tenantAwareOVN),
+		labelHandler:        handlers.NewLabelHandler(tenantAwareOVN),
+		applyHandler:       handlers.NewApplyHandler(services.NewApplyService(tenantAwareOVN, logger)),
+		importHandler:      handlers.NewImportHandler(services.NewImportService(services.NewApplyService(tenantAwareOVN, logger), logger)),
+		exportHandler:      handlers.NewExportHandler(services.NewExportService(tenantAwareOVN, logger)),
+		networkPolicyHandler: handlers.NewNetworkPolicyHandler(services.NewNetworkPolicyService(tenantAwareOVN, logger)),
 		transactionHandler: handlers.NewTransactionHandler(tenantAwareOVN),
-		topologyHandler:    handlers.NewTopologyHandler(tenantAwareOVN),
+		validationHandler:  handlers.NewValidationHandler(addressValidator),
+		topologyHandler:    handlers.NewTopologyHandler(tenantAwareOVN, topologyHistory),
+		topologyDiffHandler: handlers.NewTopologyDiffHandler(topologyHistory, middleware.HasPermission),
+		cache:              ovnCache,
+		cachedOVN:          cachedOVN,
+		clusters:           clusters,
 		config:             cfg,
+		jwtSecret:          jwtSecret,
 		db:                 database,
 		logger:             logger,
 	}
 
+	// Webhooks and notification channels are notified of resource changes,
+	// backups and restores, quota thresholds crossed in usage snapshots and
+	// lost OVN connections. Resource changes are also recorded as the
+	// resources' history.
+	r.webhooks = services.NewWebhookService(database, cfg.Webhooks.DeliveryInterval,
+		cfg.Webhooks.MaxAttempts, cfg.Webhooks.RetryBackoff, logger)
+	r.webhookHandler = handlers.NewWebhookHandler(r.webhooks)
+	var notificationChannels []*services.NotificationChannel
+	if cfg.Notifications.ChannelsFile != "" {
+		notificationChannels, err = services.LoadNotificationChannels(cfg.Notifications.ChannelsFile)
+		if err != nil {
+			logger.Fatal("Failed to load notification channels", zap.Error(err))
+		}
+	}
+	r.notifications, err = services.NewNotificationService(notificationChannels, services.SMTPConfig{
+		Host:     cfg.Notifications.SMTPHost,
+		Port:     cfg.Notifications.SMTPPort,
+		Username: cfg.Notifications.SMTPUsername,
+		Password: cfg.Notifications.SMTPPassword,
+		From:     cfg.Notifications.SMTPFrom,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Invalid notification channels", zap.Error(err))
+	}
+	r.notificationHandler = handlers.NewNotificationHandler(r.notifications)
+	r.resourceHistory = services.NewResourceHistory(database, logger)
+	r.resourceHistoryHandler = handlers.NewResourceHistoryHandler(r.resourceHistory)
+	// Generated graphs are dropped when resources change
+	r.graphCache = visualization.NewGraphCache(cfg.Topology.GraphCacheTTL, cfg.Topology.GraphCacheSize)
+	metrics.RegisterCache("topology_graph", r.graphCache.Stats)
+	r.events = services.NewEventBus(r.webhooks, r.notifications, r.resourceHistory, r.graphCache)
+
+	// Locks are kept in the database so that every replica sees them. A
+	// write's lock lasts as long as the write may.
+	writeTTL := cfg.API.WriteTimeout
+	if writeTTL <= 0 {
+		writeTTL = time.Minute
+	}
+	r.resourceLocks = services.NewResourceLockService(database, cfg.Locks.DefaultTTL, cfg.Locks.MaxTTL,
+		writeTTL, cfg.Locks.Wait, logger)
+	r.lockHandler = handlers.NewLockHandler(r.resourceLocks)
+	r.maintenance = services.NewMaintenanceService(database, logger)
+	// Floating IPs are NATed on the provider's routers, whatever the
+	// tenant, and bound to ports of the caller's tenant
+	floatingIPs, err := services.NewFloatingIPService(database, ovnService, tenantAwareOVN, cfg.FloatingIPPools, logger)
+	if err != nil {
+		logger.Fatal("Invalid floating IP pools", zap.Error(err))
+	}
+	r.floatingIPHandler = handlers.NewFloatingIPHandler(floatingIPs)
+	// Provider networks are the provider's, whatever the tenant, and
+	// checked against the bridge mappings of the chassis
+	r.providerNetworkHandler = handlers.NewProviderNetworkHandler(
+		services.NewProviderNetworkService(ovnService, chassisInventory, macAllocator, logger))
+	// VPN connections are rendered on routers of the caller's tenant;
+	// their tunnels' status is collected when a command is configured
+	var vpnCollector services.VPNStatusCollector
+	if len(cfg.VPN.StatusCommand) > 0 {
+		vpnCollector = services.NewCommandVPNStatusCollector(cfg.VPN.StatusCommand, cfg.VPN.StatusTimeout)
+	}
+	r.vpnConnections = services.NewVPNConnectionService(database, tenantAwareOVN, vpnCollector, cfg.VPN.StatusInterval, logger)
+	r.vpnConnections.SetEvents(r.events)
+	r.vpnConnectionHandler = handlers.NewVPNConnectionHandler(r.vpnConnections)
+	// service(name) references in ACL and template matches are expanded
+	// from the catalog when the rules are compiled
+	r.serviceCatalog = services.NewServiceCatalog(database, logger)
+	r.aclHandler.SetServiceCatalog(r.serviceCatalog)
+	r.serviceCatalogHandler = handlers.NewServiceCatalogHandler(r.serviceCatalog)
+	// Scheduled ACLs are toggled by the leader at the boundaries of their
+	// windows
+	r.aclSchedules = services.NewACLScheduleService(database, tenantAwareOVN, cfg.ACLSchedules.CheckInterval, logger)
+	r.aclScheduleHandler = handlers.NewACLScheduleHandler(r.aclSchedules)
+	// Access grants are enforced with ACLs on switches of the caller's
+	// tenant, removed by the leader when they expire
+	r.accessGrants = services.NewAccessGrantService(database, tenantAwareOVN,
+		cfg.AccessGrants.MaxDuration, cfg.AccessGrants.CheckInterval, logger)
+	r.accessGrants.SetServiceCatalog(r.serviceCatalog)
+	r.accessGrants.SetEvents(r.events)
+	r.accessGrantHandler = handlers.NewAccessGrantHandler(r.accessGrants)
+	r.diagnostics = services.NewDiagnosticsCollector(apiVersion, clusters.DiagnosedClusters, chassisInventory, recentErrors)
+	// The southbound database is read from the default cluster only
+	if cluster := clusters.Default(); cluster != nil {
+		r.northdHandler = handlers.NewNorthdConsistencyHandler(
+			services.NewNorthdConsistencyChecker(cluster.Client, chassisInventory))
+	}
+	r.tenantUsage.SetEvents(r.events, cfg.Webhooks.QuotaThreshold)
+
+	// Reloading the configuration changes webhook retries and the TTL caps
+	// of the tiered cache, and rate limits once set up
+	r.configReloader = services.NewConfigReloader(cfg, config.Load, logger)
+	r.configReloader.OnReload(func(cfg *config.Config) {
+		r.webhooks.SetRetries(cfg.Webhooks.MaxAttempts, cfg.Webhooks.RetryBackoff)
+	})
+	if tiered, ok := ovnCache.(*cache.TieredCache); ok {
+		r.configReloader.OnReload(func(cfg *config.Config) {
+			tiered.SetTTLs(cfg.Cache.L1TTL, cfg.Cache.L2TTL)
+		})
+	}
+	r.leader = newLeaderElector(cfg, database, ovnCache, logger)
+	r.ovnConnectivity = services.NewOVNConnectivityMonitor(clusters, r.events, cfg.Webhooks.OVNCheckInterval, logger)
+
+	if cfg.Metering.Enabled {
+		meter, err := metering.New(database, metering.Config{
+			SampleInterval: cfg.Metering.SampleInterval,
+			ExportInterval: cfg.Metering.ExportInterval,
+			CSVPath:        cfg.Metering.CSVPath,
+			WebhookURL:     cfg.Metering.WebhookURL,
+			WebhookSecret:  cfg.Metering.WebhookSecret,
+			RemoteWriteURL: cfg.Metering.RemoteWriteURL,
+		}, logger)
+		if err != nil {
+			logger.Fatal("Failed to create meter", zap.Error(err))
+		}
+		r.meter = meter
+	}
+
+	// Port traffic is overlaid on topology graphs when chassis are configured
+	if len(cfg.Topology.OVSDBEndpoints) > 0 {
+		r.ovsStats = ovn.NewOVSStatsClient(cfg.Topology.OVSDBEndpoints, &cfg.OVN.TLS, cfg.OVN.Timeout)
+		r.trafficMonitor = services.NewTrafficMonitor(r.ovsStats, cfg.Topology.TrafficPollInterval, logger)
+	}
+
+	// Backups are verified by restoring them into a staging deployment of
+	// their own, which the API never serves
+	if cfg.DR.Staging.NorthboundDB != "" {
+		r.drStaging = services.NewOVNClusterManager(logger)
+		if err := r.drStaging.Add(&cfg.DR.Staging); err != nil {
+			logger.Fatal("Failed to create DR staging OVN client", zap.Error(err))
+		}
+	}
+
+	// ACL hit counters of the default cluster are recorded when flows can be
+	// dumped
+	var aclStats handlers.ACLStatsReader
+	if len(cfg.ACLStats.FlowDumpCommand) > 0 {
+		r.aclCollector = ovn.NewACLStatsCollector(&cfg.OVN, ovn.CommandFlowDumper(cfg.ACLStats.FlowDumpCommand))
+		r.aclStats = services.NewACLStatsRecorder(database, r.aclCollector, backend,
+			cfg.ACLStats.Interval, cfg.ACLStats.Retention, logger)
+		aclStats = r.aclStats
+	}
+	r.aclStatsHandler = handlers.NewACLStatsHandler(tenantAwareOVN, aclStats)
+
+	// ACL logs are ingested from ovn-controller's log file, syslog, or both
+	var aclLogSources []services.ACLLogSource
+	if cfg.ACLLogs.File != "" {
+		aclLogSources = append(aclLogSources, ovn.NewACLLogFile(cfg.ACLLogs.File, cfg.ACLLogs.PollInterval))
+	}
+	if cfg.ACLLogs.SyslogAddr != "" {
+		aclLogSources = append(aclLogSources, ovn.NewACLLogSyslog(cfg.ACLLogs.SyslogAddr))
+	}
+	var aclLogs handlers.ACLLogReader
+	if len(aclLogSources) > 0 {
+		r.aclLogs = services.NewACLLogIngester(database, aclLogSources, cfg.ACLLogs.Retention, logger)
+		aclLogs = r.aclLogs
+	}
+	r.aclLogHandler = handlers.NewACLLogHandler(tenantAwareOVN, aclLogs)
+
+	// ACL simulations can replay the flows ACLs logged
+	var observedFlows services.ObservedFlowSource
+	if r.aclLogs != nil {
+		observedFlows = r.aclLogs
+	}
+	r.aclSimulationHandler = handlers.NewACLSimulationHandler(services.NewACLSimulator(tenantAwareOVN, observedFlows))
+
+	// Unused resource reports find unbound ports and idle ACLs from the
+	// recorded history
+	var aclHits services.ACLHitSource
+	if r.aclStats != nil {
+		aclHits = r.aclStats
+	}
+	// ACL rollouts are judged on the traffic their rules match in observe
+	// mode
+	r.aclRollouts = services.NewACLRolloutService(database, tenantAwareOVN, aclHits, observedFlows,
+		cfg.ACLRollouts.CheckInterval, cfg.ACLRollouts.DefaultBakePeriod, cfg.ACLRollouts.MaxBakePeriod, logger)
+	r.aclRollouts.SetEvents(r.events)
+	r.aclRollouts.SetMaintenance(r.maintenance)
+	r.aclRolloutHandler = handlers.NewACLRolloutHandler(r.aclRollouts)
+	r.reportHandler = handlers.NewReportHandler(
+		services.NewUnusedResourceReporter(tenantAwareOVN, topologyHistory, aclHits),
+		services.NewSecurityReporter(tenantAwareOVN),
+	)
+
+	// Compliance rules are evaluated over every tenant's resources; packs
+	// from the rules file are added to the built-in ones
+	compliancePacks := services.BuiltinCompliancePacks()
+	if cfg.Compliance.RulesFile != "" {
+		filePacks, err := services.LoadCompliancePacks(cfg.Compliance.RulesFile)
+		if err != nil {
+			logger.Fatal("Failed to load compliance rules", zap.Error(err))
+		}
+		compliancePacks = append(compliancePacks, filePacks...)
+	}
+	compliancePacks, err = services.SelectCompliancePacks(compliancePacks, cfg.Compliance.Packs)
+	if err != nil {
+		logger.Fatal("Invalid compliance packs", zap.Error(err))
+	}
+	var complianceAlerter services.ComplianceAlerter
+	if cfg.Compliance.WebhookURL != "" {
+		complianceAlerter = services.NewComplianceWebhook(cfg.Compliance.WebhookURL, cfg.Compliance.WebhookSecret)
+	}
+	r.compliance = services.NewComplianceEngine(backend, compliancePacks, cfg.Compliance.Interval, complianceAlerter, logger)
+	r.complianceHandler = handlers.NewComplianceHandler(r.compliance)
+
+	// Gateway health includes BGP state when a collector is configured
+	var bgpCollector services.BGPCollector
+	if len(cfg.Gateways.BGPCollectorCommand) > 0 {
+		bgpCollector = services.NewCommandBGPCollector(cfg.Gateways.BGPCollectorCommand, cfg.Gateways.BGPCollectorTimeout)
+	}
+	r.gatewayHandler = handlers.NewGatewayHandler(services.NewGatewayMonitor(tenantAwareOVN, bgpCollector))
+	r.chassisHandler = handlers.NewChassisHandler(r.chassisInventory)
+	r.capabilityHandler = handlers.NewCapabilityHandler(clusters)
+	r.neutronHandler = handlers.NewNeutronHandler(services.NewNeutronMapper(tenantAwareOVN))
+
+	// Transactions too large for one OVSDB transaction are applied in
+	// checkpointed chunks, which can be resumed after a failure
+	if store, err := services.NewFileChunkedTransactionStore(cfg.GetChunkedTransactionPath()); err != nil {
+		logger.Warn("Chunked transactions are not available", zap.Error(err))
+	} else if chunkedTransactions, err := services.NewChunkedTransactionService(store, tenantAwareOVN, logger); err != nil {
+		logger.Warn("Chunked transactions are not available", zap.Error(err))
+	} else {
+		r.chunkedTransactionHandler = handlers.NewChunkedTransactionHandler(chunkedTransactions)
+	}
+
+	// With soft deletes, deleted switches, routers, ports and ACLs are kept
+	// in a recycle bin they can be restored from
+	if cfg.Trash.Enabled {
+		store, err := services.NewFileTrashStore(cfg.GetTrashPath())
+		if err != nil {
+			logger.Fatal("Failed to create recycle bin", zap.Error(err))
+		}
+		r.recycleBin = services.NewRecycleBin(store, tenantAwareOVN, cfg.Trash.Retention, logger)
+		r.trashHandler = handlers.NewTrashHandler(r.recycleBin)
+		r.switchHandler.SetRecycleBin(r.recycleBin)
+		r.routerHandler.SetRecycleBin(r.recycleBin)
+		r.portHandler.SetRecycleBin(r.recycleBin)
+		r.aclHandler.SetRecycleBin(r.recycleBin)
+	}
+
+
+	if err := r.engine.SetTrustedProxies(cfg.API.TrustedProxies); err != nil {
+		logger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
+
+	if provider, ok := ovnService.(ovnStatusProvider); ok {
+		r.ovnStatus = provider
+	}
+
 	r.setupMiddleware()
 	r.setupRoutes()
 	r.SetupSwaggerRoutes()
@@ -90,9 +484,9 @@ func (r *Router) setupMiddleware() {
 	corsConfig := middleware.SecurityConfig{
 		CORSEnabled:      true,
 		CORSAllowOrigins: r.config.Security.CORSAllowOrigins,
-		CORSAllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		CORSAllowHeaders: []string{"Authorization", "Content-Type", "X-Request-ID"},
-		CORSExposeHeaders: []string{"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining"},
+		CORSAllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		CORSAllowHeaders: []string{"Authorization", "Content-Type", "X-Request-ID", handlers.OVNClusterHeader, "If-Match", "If-None-Match"},
+		CORSExposeHeaders: []string{"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "ETag", "Warning", middleware.DataAsOfHeader, "Link", versioning.Header, versioning.DeprecationHeader, versioning.SunsetHeader},
 		CORSAllowCredentials: true,
 		CORSMaxAge: 86400,
 	}
@@ -101,7 +495,7 @@ func (r *Router) setupMiddleware() {
 	// Logging with context
 	r.engine.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Logger: r.logger,
-		SkipPaths: []string{"/health", "/metrics"},
+		SkipPaths: []string{"/health", "/healthz", "/readyz", "/metrics"},
 	}))
 	
 	// Rate limiting
@@ -114,7 +508,11 @@ func (r *Router) setupMiddleware() {
 			ByIP:             true,
 			ByUser:           true,
 		}
-		r.engine.Use(middleware.RateLimit(rateLimitConfig))
+		rateLimit, limits := middleware.NewRateLimit(rateLimitConfig)
+		r.configReloader.OnReload(func(cfg *config.Config) {
+			limits.Set(float64(cfg.Security.RateLimitRPS), cfg.Security.RateLimitBurst)
+		})
+		r.engine.Use(rateLimit)
 	}
 	
 	// Audit logging
@@ -126,7 +524,7 @@ func (r *Router) setupMiddleware() {
 			LogRequestBody:   true,
 			LogResponseBody:  false, // Don't log response bodies by default
 			MaxBodySize:      1024 * 1024, // 1MB
-			ExcludePaths:     []string{"/health", "/metrics"},
+			ExcludePaths:     []string{"/health", "/healthz", "/readyz", "/metrics"},
 			SensitiveFields:  []string{"password", "token", "secret", "key"},
 		}
 		r.engine.Use(middleware.Audit(auditConfig))
@@ -139,6 +537,13 @@ func (r *Router) setupMiddleware() {
 func (r *Router) setupRoutes() {
 	// Health check (no auth required)
 	r.engine.GET("/health", r.healthCheck)
+
+	// Liveness and readiness probes (no auth required)
+	health := NewHealthService(r.db.DB(), r.clusters, cache.RedisClient(r.cache), r.logger)
+	if replicated, ok := r.db.(*db.ReplicatedDB); ok {
+		health.AddDatabaseReplica(replicated.ReplicaEndpoint(), replicated.PingReplica)
+	}
+	health.RegisterHealthRoutes(r.engine)
 	
 	// Metrics endpoint (no auth required)
 	r.engine.GET("/metrics", middleware.PrometheusHandler())
@@ -147,7 +552,8 @@ func (r *Router) setupRoutes() {
 	r.engine.POST("/api/csp-report", middleware.CSPReportHandler())
 
 	// API v1 - all routes require authentication
-	v1 := r.engine.Group("/api/v1")
+	v1Version, v2Version := apiVersions(r.config.API)
+	v1 := r.engine.Group(v1Version.Prefix(), v1Version.Middleware())
 	
 	// Auth routes (public - must be before auth middleware)
 	authGroup := v1.Group("/auth")
@@ -160,15 +566,28 @@ func (r *Router) setupRoutes() {
 	
 	// Apply authentication middleware to all v1 routes
 	authMiddleware := middleware.Auth(middleware.AuthConfig{
-		Enabled:     r.config.Auth.Enabled,
-		JWTSecret:   r.config.Auth.JWTSecret,
-		SkipPaths:   []string{"/api/v1/health", "/api/v1/ready", "/api/v1/metrics"},
-		PublicPaths: []string{"/api/v1/auth"},
+		Enabled:        r.config.Auth.Enabled,
+		JWTSecret:      r.config.Auth.JWTSecret,
+		// Tokens signed before a rotation stay valid until they expire
+		JWTSecrets: func() []string {
+			return r.jwtSecret.Values(r.config.Auth.TokenExpiration)
+		},
+		SkipPaths:      []string{"/api/v1/health", "/api/v1/ready", "/api/v1/metrics"},
+		PublicPaths:    []string{"/api/v1/auth", "/api/v2/auth/login", "/api/v2/auth/callback", "/api/v2/auth/refresh"},
+		TokenValidator: r.validateToken,
+		APIKeyValidator: r.tenantService.ValidateAPIKey,
 	})
 	v1.Use(authMiddleware)
-	
-	// Apply tenant context middleware
-	v1.Use(middleware.TenantContext())
+
+	// POSTs carrying an Idempotency-Key are safe to retry. Their responses
+	// are kept in the configured cache, shared between instances, else in
+	// memory.
+	var idempotencyStore cache.Cache = r.cache
+	if idempotencyStore == nil {
+		idempotencyStore = cache.NewMemoryCache(r.logger)
+	}
+	idempotency := middleware.Idempotency(idempotencyStore, r.config.API.IdempotencyTTL, r.logger)
+	v1.Use(idempotency)
 	
 	// Authenticated auth routes
 	authGroup.POST("/logout", r.authHandler.Logout)
@@ -189,134 +608,976 @@ func (r *Router) setupRoutes() {
 		r.authHandler.DeactivateUser)
 	
 	// Register tenant management routes (no tenant context required)
-	RegisterTenantRoutes(v1, r.db, r.logger)
+	RegisterTenantRoutes(v1, r.tenantService, r.tenantReclaimer, r.tenantChanges, r.tenantUsage, r.webhookHandler, r.logger)
+
+	// Global webhooks, notified of every tenant's events and system events
+	webhooks := v1.Group("/webhooks")
+	{
+		webhooks.GET("",
+			middleware.RequirePermission("webhooks:read"),
+			r.webhookHandler.List)
+		webhooks.POST("",
+			middleware.RequirePermission("webhooks:write"),
+			r.webhookHandler.Create)
+		webhooks.GET("/:webhook_id",
+			middleware.RequirePermission("webhooks:read"),
+			r.webhookHandler.Get)
+		webhooks.PUT("/:webhook_id",
+			middleware.RequirePermission("webhooks:write"),
+			r.webhookHandler.Update)
+		webhooks.DELETE("/:webhook_id",
+			middleware.RequirePermission("webhooks:write"),
+			r.webhookHandler.Delete)
+		webhooks.GET("/:webhook_id/deliveries",
+			middleware.RequirePermission("webhooks:read"),
+			r.webhookHandler.Deliveries)
+	}
+
+	// Slack, Microsoft Teams and email channels of NOTIFICATION_CHANNELS_FILE
+	notifications := v1.Group("/notifications")
+	{
+		notifications.GET("/channels",
+			middleware.RequirePermission("notifications:read"),
+			r.notificationHandler.Channels)
+		notifications.POST("/channels/:name/test",
+			middleware.RequirePermission("notifications:test"),
+			r.notificationHandler.Test)
+	}
+
+	// Cache management routes, when OVN reads are cached
+	if r.cachedOVN != nil {
+		RegisterCacheRoutes(v1, r.cachedOVN, r.clusters, r.logger)
+	}
+
+	// The configuration in effect, and reloading it as SIGHUP does
+	configHandler := handlers.NewConfigHandler(r.configReloader, r.logger)
+	adminConfig := v1.Group("/admin/config", middleware.RequirePermission("admin"))
+	{
+		adminConfig.GET("", configHandler.Get)
+		adminConfig.POST("/reload", configHandler.Reload)
+	}
+
+	// The replica running the background jobs
+	leaderHandler := handlers.NewLeaderHandler(r.leader)
+	v1.GET("/admin/leader", middleware.RequirePermission("admin"), leaderHandler.Get)
+
+	// The diagnostics bundle attached to support cases
+	diagnosticsHandler := handlers.NewDiagnosticsHandler(r.diagnostics, r.configReloader, r.logger)
+	v1.GET("/admin/diagnostics", middleware.RequirePermission("admin"), diagnosticsHandler.Get)
+
+	// Whether ovn-northd keeps up with the northbound database
+	if r.northdHandler != nil {
+		v1.GET("/admin/northd-consistency", middleware.RequirePermission("admin"), r.northdHandler.Get)
+	}
+
+	// Maintenance mode, freezing writes to the routes below
+	maintenanceHandler := handlers.NewMaintenanceHandler(r.maintenance)
+	adminMaintenance := v1.Group("/admin/maintenance", middleware.RequirePermission("admin"))
+	{
+		adminMaintenance.GET("", maintenanceHandler.Get)
+		adminMaintenance.PUT("", maintenanceHandler.Set)
+		adminMaintenance.DELETE("", maintenanceHandler.Clear)
+	}
+
+	// Scope the remaining routes to the caller's tenant
+	v1.Use(middleware.TenantContext(r.tenantService))
+	v1.Use(middleware.WriteFreeze(r.maintenance, r.logger))
 	
+	// OVN clusters: resource routes are served for the default cluster at
+	// the top level and for every cluster under /clusters/:name
+	clusterHandler := handlers.NewOVNClusterHandler(r.clusters)
+	v1.GET("/clusters",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.List)
+	v1.GET("/clusters/:name",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.Get)
+	v1.GET("/clusters/:name/health",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.Health)
+	r.registerOVNRoutes(v1.Group("/clusters/:name", clusterHandler.SelectCluster))
+
+	// Other routes may select a cluster with the X-OVN-Cluster header
+	v1.Use(clusterHandler.SelectCluster)
+	r.registerOVNRoutes(v1)
+
+	// Who created, updated and deleted each resource written through the API
+	v1.GET("/resources/:uuid/history",
+		middleware.RequirePermission("history:read"),
+		r.resourceHistoryHandler.History)
+
+	// Locks keeping others from writing resources
+	r.registerLockRoutes(v1)
+
+	// Soft-deleted resources, restored to the cluster they were deleted from
+	if r.trashHandler != nil {
+		trash := v1.Group("/trash", middleware.ResourceOwnership())
+		trash.GET("",
+			middleware.RequirePermission("trash:read"),
+			r.trashHandler.List)
+		trash.GET("/:id",
+			middleware.RequirePermission("trash:read"),
+			r.trashHandler.Get)
+		trash.POST("/:id/restore",
+			middleware.RequirePermission("trash:write"),
+			r.trashHandler.Restore)
+		trash.DELETE("/:id",
+			middleware.RequirePermission("trash:delete"),
+			r.trashHandler.Purge)
+	}
+
 	{
-		// Logical Switches
-		switches := v1.Group("/switches")
-		switches.Use(middleware.RequirePermission("switches:read"))
+		// Visualization routes
+		visualization := v1.Group("", middleware.RequirePermission("topology:read"), middleware.OVNReadOnlyFallback(r.clusters))
+		visualizationHandler := NewVisualizationHandler(r.ovnService, r.logger)
+		if r.trafficMonitor != nil {
+			visualizationHandler.SetTrafficSource(r.trafficMonitor)
+		}
+		visualizationHandler.SetGraphCache(r.graphCache)
+		visualizationHandler.RegisterVisualizationRoutes(visualization)
+
+		// Flow trace routes run ovn-trace against the default cluster
+		if cluster := r.clusters.Default(); cluster != nil {
+			trace := v1.Group("", middleware.RequirePermission("trace:run"))
+			traceHandler := NewFlowTraceHandler(cluster.Client, cluster.Service, r.logger)
+			if len(r.config.Probes.AgentCommand) > 0 {
+				traceHandler.SetProbeAgent(services.NewCommandProbeAgent(r.config.Probes.AgentCommand, r.config.Probes.Timeout),
+					r.chassisInventory, r.config.Probes.Timeout)
+			}
+			traceHandler.RegisterFlowTraceRoutes(trace)
+		}
+
+		// Template routes
+		RegisterTemplateRoutes(v1, r.ovnService, r.serviceCatalog, r.logger)
+
+		// Backup routes
+		drift, err := RegisterBackupRoutes(v1, r.ovnService, r.drStaging, r.config, r.events, r.logger)
+		if err != nil {
+			r.logger.Error("Failed to register backup routes", zap.Error(err))
+		}
+		r.drift = drift
+
+		// Staged ACL rollouts
+		if r.aclRolloutHandler != nil {
+			rollouts := v1.Group("/acl-rollouts", middleware.RequirePermission("acls:read"))
+			rollouts.GET("", r.aclRolloutHandler.List)
+			rollouts.GET("/:id", r.aclRolloutHandler.Get)
+			rollouts.POST("",
+				middleware.RequirePermission("acls:write"),
+				middleware.EndpointRateLimit(5, 20),
+				r.requireApproval(nil),
+				r.aclRolloutHandler.Start)
+			rollouts.POST("/:id/promote",
+				middleware.RequirePermission("acls:write"),
+				r.aclRolloutHandler.Promote)
+			rollouts.POST("/:id/rollback",
+				middleware.RequirePermission("acls:write"),
+				r.aclRolloutHandler.Rollback)
+		}
+
+		// ACL schedules; the scheduler toggles ACLs of the default cluster
+		v1.GET("/acl-schedules", middleware.RequirePermission("acls:read"), r.aclScheduleHandler.List)
+		{
+			schedule := v1.Group("/acls/:id/schedule", middleware.RequirePermission("acls:read"))
+			schedule.GET("", r.aclScheduleHandler.Get)
+			schedule.GET("/toggles", r.aclScheduleHandler.Toggles)
+			schedule.PUT("",
+				middleware.RequirePermission("acls:write"),
+				r.aclScheduleHandler.Set)
+			schedule.DELETE("",
+				middleware.RequirePermission("acls:write"),
+				r.aclScheduleHandler.Delete)
+		}
+
+		// Access grants; expired grants are removed from the default cluster
 		{
-			switches.GET("", r.switchHandler.List)
-			switches.GET("/:id", r.switchHandler.Get)
-			
-			// Write operations require additional permission
-			switches.POST("", 
-				middleware.RequirePermission("switches:write"),
-				middleware.EndpointRateLimit(10, 100), // 10 req/s, burst 100
-				r.switchHandler.Create)
-			switches.PUT("/:id", 
-				middleware.RequirePermission("switches:write"),
-				r.switchHandler.Update)
-			switches.DELETE("/:id", 
-				middleware.RequirePermission("switches:delete"),
-				middleware.EndpointRateLimit(5, 10), // 5 req/s, burst 10
-				r.switchHandler.Delete)
+			grants := v1.Group("/access-grants", middleware.RequirePermission("acls:read"))
+			grants.GET("", r.accessGrantHandler.List)
+			grants.GET("/:id", r.accessGrantHandler.Get)
+			grants.POST("",
+				middleware.RequirePermission("acls:write"),
+				middleware.ResourceOwnership(),
+				r.accessGrantHandler.Grant)
+			grants.POST("/:id/revoke",
+				middleware.RequirePermission("acls:write"),
+				r.accessGrantHandler.Revoke)
 		}
 
-		// Logical Routers
-		routers := v1.Group("/routers")
-		routers.Use(middleware.RequirePermission("routers:read"))
+		// Floating IPs
 		{
-			routers.GET("", r.routerHandler.List)
-			routers.GET("/:id", r.routerHandler.Get)
-			
-			routers.POST("", 
-				middleware.RequirePermission("routers:write"),
+			fips := v1.Group("/floating-ips", middleware.RequirePermission("floating_ips:read"))
+			fips.GET("", r.floatingIPHandler.List)
+			fips.GET("/pools", r.floatingIPHandler.Pools)
+			fips.GET("/:id", r.floatingIPHandler.Get)
+			fips.POST("",
+				middleware.RequirePermission("floating_ips:write"),
 				middleware.EndpointRateLimit(10, 100),
-				r.routerHandler.Create)
-			routers.PUT("/:id", 
-				middleware.RequirePermission("routers:write"),
-				r.routerHandler.Update)
-			routers.DELETE("/:id", 
-				middleware.RequirePermission("routers:delete"),
-				middleware.EndpointRateLimit(5, 10),
-				r.routerHandler.Delete)
+				r.floatingIPHandler.Allocate)
+			fips.DELETE("/:id",
+				middleware.RequirePermission("floating_ips:write"),
+				r.floatingIPHandler.Release)
+			fips.POST("/:id/bind",
+				middleware.RequirePermission("floating_ips:write"),
+				r.floatingIPHandler.Bind)
+			fips.POST("/:id/unbind",
+				middleware.RequirePermission("floating_ips:write"),
+				r.floatingIPHandler.Unbind)
 		}
 
-		// Ports (under switches)
-		switches.GET("/:id/ports", 
-			middleware.RequirePermission("ports:read"),
-			r.portHandler.List)
-		switches.POST("/:id/ports", 
-			middleware.RequirePermission("ports:write"),
-			middleware.EndpointRateLimit(20, 200),
-			r.portHandler.Create)
-		
-		// Ports (standalone)
-		ports := v1.Group("/ports")
-		ports.Use(middleware.RequirePermission("ports:read"))
+		// Provider networks; changing them is reserved to admins
 		{
-			ports.GET("/:id", r.portHandler.Get)
-			ports.PUT("/:id", 
-				middleware.RequirePermission("ports:write"),
-				r.portHandler.Update)
-			ports.DELETE("/:id", 
-				middleware.RequirePermission("ports:delete"),
-				middleware.EndpointRateLimit(10, 50),
-				r.portHandler.Delete)
+			networks := v1.Group("/provider-networks", middleware.RequirePermission("provider_networks:read"))
+			networks.GET("", r.providerNetworkHandler.List)
+			networks.GET("/:id", r.providerNetworkHandler.Get)
+			networks.POST("",
+				middleware.RequirePermission("provider_networks:write"),
+				r.providerNetworkHandler.Create)
+			networks.DELETE("/:id",
+				middleware.RequirePermission("provider_networks:write"),
+				r.providerNetworkHandler.Delete)
+			networks.POST("/:id/routers",
+				middleware.RequirePermission("provider_networks:write"),
+				r.providerNetworkHandler.AttachRouter)
+			networks.DELETE("/:id/routers/:router_id",
+				middleware.RequirePermission("provider_networks:write"),
+				r.providerNetworkHandler.DetachRouter)
 		}
 
-		// ACLs
-		acls := v1.Group("/acls")
-		acls.Use(middleware.RequirePermission("acls:read"))
+		// VPN connections
 		{
-			acls.GET("", r.aclHandler.List)
-			acls.GET("/:id", r.aclHandler.Get)
-			
-			acls.POST("", 
+			vpn := v1.Group("/vpn-connections", middleware.RequirePermission("vpn:read"))
+			vpn.GET("", r.vpnConnectionHandler.List)
+			vpn.GET("/:id", r.vpnConnectionHandler.Get)
+			vpn.POST("",
+				middleware.RequirePermission("vpn:write"),
+				r.vpnConnectionHandler.Create)
+			vpn.PUT("/:id",
+				middleware.RequirePermission("vpn:write"),
+				r.vpnConnectionHandler.Update)
+			vpn.DELETE("/:id",
+				middleware.RequirePermission("vpn:write"),
+				r.vpnConnectionHandler.Delete)
+		}
+
+		// Service catalog
+		{
+			catalog := v1.Group("/service-catalog", middleware.RequirePermission("acls:read"))
+			catalog.GET("", r.serviceCatalogHandler.List)
+			catalog.GET("/:name", r.serviceCatalogHandler.Get)
+			catalog.POST("/expand", r.serviceCatalogHandler.Expand)
+			catalog.POST("",
 				middleware.RequirePermission("acls:write"),
-				middleware.EndpointRateLimit(10, 100),
-				r.aclHandler.Create)
-			acls.PUT("/:id", 
+				r.serviceCatalogHandler.Create)
+			catalog.PUT("/:name",
 				middleware.RequirePermission("acls:write"),
-				r.aclHandler.Update)
-			acls.DELETE("/:id", 
-				middleware.RequirePermission("acls:delete"),
-				middleware.EndpointRateLimit(5, 20),
-				r.aclHandler.Delete)
+				r.serviceCatalogHandler.Update)
+			catalog.DELETE("/:name",
+				middleware.RequirePermission("acls:write"),
+				r.serviceCatalogHandler.Delete)
+		}
+
+		// Changeset routes
+		if err := RegisterChangesetRoutes(v1, r.ovnService, r.config, r.logger); err != nil {
+			r.logger.Error("Failed to register changeset routes", zap.Error(err))
 		}
+	}
+
+	// API v2 - the routes that have moved to it so far
+	r.setupV2Routes(v2Version, authMiddleware, idempotency)
+}
+
+// registerLockRoutes registers the resource lock routes on group. Locking
+// and unlocking a resource requires the permission to write it.
+func (r *Router) registerLockRoutes(group *gin.RouterGroup) {
+	locks := group.Group("/locks")
+	locks.GET("", r.lockHandler.List)
+	locks.GET("/:type/:id", r.lockHandler.Get)
+	locks.PUT("/:type/:id", r.lockHandler.Acquire)
+	locks.DELETE("/:type/:id", r.lockHandler.Release)
+}
+
+// registerOVNRoutes registers the logical network resource routes on group
+func (r *Router) registerOVNRoutes(group *gin.RouterGroup) {
+	group = group.Group("", middleware.OVNReadOnlyFallback(r.clusters), middleware.ResourceEvents(r.events), middleware.ResourceOwnership(),
+		middleware.ResourceLocks(r.resourceLocks, r.logger))
+
+	// Logical Switches
+	switches := group.Group("/switches")
+	switches.Use(middleware.RequirePermission("switches:read"))
+	{
+		switches.GET("", r.switchHandler.List)
+		switches.GET("/:id", r.switchHandler.Get)
+		switches.GET("/:id/dependents", r.switchHandler.Dependents)
+		
+		// Write operations require additional permission
+		switches.POST("", 
+			middleware.RequirePermission("switches:write"),
+			middleware.EndpointRateLimit(10, 100), // 10 req/s, burst 100
+			r.requireApproval(middleware.ResourceChange(models.OperationCreate, models.ResourceSwitch)),
+			r.switchHandler.Create)
+		switches.PUT("/:id", 
+			middleware.RequirePermission("switches:write"),
+			r.requireApproval(middleware.ResourceChange(models.OperationUpdate, models.ResourceSwitch)),
+			r.switchHandler.Update)
+		switches.DELETE("/:id", 
+			middleware.RequirePermission("switches:delete"),
+			middleware.EndpointRateLimit(5, 10), // 5 req/s, burst 10
+			r.requireApproval(middleware.ResourceChange(models.OperationDelete, models.ResourceSwitch)),
+			r.switchHandler.Delete)
+
+		switches.GET("/:id/labels", r.labelHandler.Get(models.ResourceSwitch))
+		switches.PUT("/:id/labels",
+			middleware.RequirePermission("switches:write"),
+			r.labelHandler.Replace(models.ResourceSwitch))
+		switches.PATCH("/:id/labels",
+			middleware.RequirePermission("switches:write"),
+			r.labelHandler.Update(models.ResourceSwitch))
+	}
 
-		// Transactions - requires admin permission
-		v1.POST("/transactions", 
-			middleware.RequirePermission("admin"),
+	// Logical Routers
+	routers := group.Group("/routers")
+	routers.Use(middleware.RequirePermission("routers:read"))
+	{
+		routers.GET("", r.routerHandler.List)
+		routers.GET("/:id", r.routerHandler.Get)
+		routers.GET("/:id/dependents", r.routerHandler.Dependents)
+		
+		routers.POST("", 
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.requireApproval(middleware.ResourceChange(models.OperationCreate, models.ResourceRouter)),
+			r.routerHandler.Create)
+		routers.PUT("/:id", 
+			middleware.RequirePermission("routers:write"),
+			r.requireApproval(middleware.ResourceChange(models.OperationUpdate, models.ResourceRouter)),
+			r.routerHandler.Update)
+		routers.DELETE("/:id", 
+			middleware.RequirePermission("routers:delete"),
 			middleware.EndpointRateLimit(5, 10),
-			r.transactionHandler.Execute)
+			r.requireApproval(middleware.ResourceChange(models.OperationDelete, models.ResourceRouter)),
+			r.routerHandler.Delete)
 
-		// Topology
-		v1.GET("/topology",
-			middleware.RequirePermission("topology:read"),
-			r.topologyHandler.GetTopology)
+		routers.GET("/:id/labels", r.labelHandler.Get(models.ResourceRouter))
+		routers.PUT("/:id/labels",
+			middleware.RequirePermission("routers:write"),
+			r.labelHandler.Replace(models.ResourceRouter))
+		routers.PATCH("/:id/labels",
+			middleware.RequirePermission("routers:write"),
+			r.labelHandler.Update(models.ResourceRouter))
 
-		// Visualization routes
-		// Note: NewVisualizationHandler expects *OVNService, not interface
-		// For now, we'll skip visualization routes or need to refactor
-		// vizHandler := NewVisualizationHandler(r.ovnService, r.logger)
-		// vizHandler.RegisterVisualizationRoutes(v1)
+		// Policy-based routing
+		policies := routers.Group("/:id/policies", middleware.RequireOVNCapability(r.clusters, ovn.CapabilityRouterPolicies))
+		policies.GET("", r.routerPolicyHandler.List)
+		policies.GET("/:policyId", r.routerPolicyHandler.Get)
+		policies.POST("",
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.routerPolicyHandler.Create)
+		policies.PUT("/:policyId",
+			middleware.RequirePermission("routers:write"),
+			r.routerPolicyHandler.Update)
+		policies.DELETE("/:policyId",
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(5, 20),
+			r.routerPolicyHandler.Delete)
 
-		// Flow trace routes
-		// Note: We need the OVN client directly for flow tracing
-		// This would require updating the Router struct to include ovnClient
-		// For now, we'll skip this integration
+		// Static routes; gin treats the colon in routes:import as the start
+		// of a wildcard, which the handler checks
+		routers.GET("/:id/routes", r.staticRouteHandler.List)
+		routers.GET("/:id/routes/:routeId", r.staticRouteHandler.Get)
+		routers.POST("/:id/routes",
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.staticRouteHandler.Create)
+		routers.POST("/:id/routes:import",
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(2, 5),
+			r.staticRouteHandler.Import)
+		routers.PUT("/:id/routes/:routeId",
+			middleware.RequirePermission("routers:write"),
+			r.staticRouteHandler.Update)
+		routers.DELETE("/:id/routes/:routeId",
+			middleware.RequirePermission("routers:write"),
+			middleware.EndpointRateLimit(5, 20),
+			r.staticRouteHandler.Delete)
+	}
 
-		// Template routes
-		RegisterTemplateRoutes(v1, r.ovnService, r.logger)
+	// Ports (under switches)
+	switches.GET("/:id/ports", 
+		middleware.RequirePermission("ports:read"),
+		r.portHandler.List)
+	switches.POST("/:id/ports", 
+		middleware.RequirePermission("ports:write"),
+		middleware.EndpointRateLimit(20, 200),
+		r.requireApproval(middleware.ResourceChange(models.OperationCreate, models.ResourcePort)),
+		r.portHandler.Create)
 
-		// Backup routes
-		if err := RegisterBackupRoutes(v1, r.ovnService, r.config, r.logger); err != nil {
-			r.logger.Error("Failed to register backup routes", zap.Error(err))
-		}
+	// Sampling of every ACL of a switch
+	switches.PUT("/:id/sampling",
+		middleware.RequireOVNCapability(r.clusters, ovn.CapabilitySampling),
+		middleware.RequirePermission("sampling:write"),
+		r.samplingHandler.SetSwitch)
+	
+	// Ports (standalone)
+	ports := group.Group("/ports")
+	ports.Use(middleware.RequirePermission("ports:read"))
+	{
+		ports.GET("/:id", r.portHandler.Get)
+		ports.PUT("/:id", 
+			middleware.RequirePermission("ports:write"),
+			r.requireApproval(middleware.ResourceChange(models.OperationUpdate, models.ResourcePort)),
+			r.portHandler.Update)
+		ports.DELETE("/:id", 
+			middleware.RequirePermission("ports:delete"),
+			middleware.EndpointRateLimit(10, 50),
+			r.requireApproval(middleware.ResourceChange(models.OperationDelete, models.ResourcePort)),
+			r.portHandler.Delete)
+
+		ports.GET("/:id/labels", r.labelHandler.Get(models.ResourcePort))
+		ports.PUT("/:id/labels",
+			middleware.RequirePermission("ports:write"),
+			r.labelHandler.Replace(models.ResourcePort))
+		ports.PATCH("/:id/labels",
+			middleware.RequirePermission("ports:write"),
+			r.labelHandler.Update(models.ResourcePort))
+	}
+
+	// ACLs
+	acls := group.Group("/acls")
+	acls.Use(middleware.RequirePermission("acls:read"))
+	{
+		acls.GET("", r.aclHandler.List)
+		acls.GET("/:id", r.aclHandler.Get)
+		acls.GET("/:id/stats", r.aclStatsHandler.Get)
+		acls.GET("/:id/logs", r.aclLogHandler.List)
+		acls.GET("/:id/sampling",
+			middleware.RequireOVNCapability(r.clusters, ovn.CapabilitySampling),
+			middleware.RequirePermission("sampling:read"),
+			r.samplingHandler.GetACL)
+		acls.PUT("/:id/sampling",
+			middleware.RequireOVNCapability(r.clusters, ovn.CapabilitySampling),
+			middleware.RequirePermission("sampling:write"),
+			r.samplingHandler.SetACL)
+		
+		acls.POST("", 
+			middleware.RequirePermission("acls:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.requireApproval(middleware.ResourceChange(models.OperationCreate, models.ResourceACL)),
+			r.aclHandler.Create)
+		acls.PUT("/:id", 
+			middleware.RequirePermission("acls:write"),
+			r.requireApproval(middleware.ResourceChange(models.OperationUpdate, models.ResourceACL)),
+			r.aclHandler.Update)
+		acls.DELETE("/:id", 
+			middleware.RequirePermission("acls:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.requireApproval(middleware.ResourceChange(models.OperationDelete, models.ResourceACL)),
+			r.aclHandler.Delete)
+
+		acls.GET("/:id/labels", r.labelHandler.Get(models.ResourceACL))
+		acls.PUT("/:id/labels",
+			middleware.RequirePermission("acls:write"),
+			r.labelHandler.Replace(models.ResourceACL))
+		acls.PATCH("/:id/labels",
+			middleware.RequirePermission("acls:write"),
+			r.labelHandler.Update(models.ResourceACL))
 	}
+
+	// Bulk ACL creation; gin treats the colon in acls:bulk as the start of
+	// a wildcard, which the handler checks
+	switches.POST("/:id/acls:bulk",
+		middleware.RequirePermission("acls:write"),
+		middleware.EndpointRateLimit(10, 100),
+		r.requireApproval(middleware.BulkACLChange),
+		r.aclHandler.BulkCreate)
+
+	// What-if simulation of an ACL change; nothing is changed
+	switches.POST("/:id/acls/simulate",
+		middleware.RequirePermission("acls:read"),
+		middleware.EndpointRateLimit(5, 20),
+		r.aclSimulationHandler.Simulate)
+
+	// Load Balancers
+	loadBalancers := group.Group("/load-balancers")
+	loadBalancers.Use(middleware.RequirePermission("load_balancers:read"))
+	{
+		loadBalancers.GET("", r.loadBalancerHandler.List)
+		loadBalancers.GET("/:id", r.loadBalancerHandler.Get)
+
+		loadBalancers.POST("",
+			middleware.RequirePermission("load_balancers:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.requireApproval(middleware.ResourceChange(models.OperationCreate, models.ResourceLoadBalancer)),
+			r.loadBalancerHandler.Create)
+		loadBalancers.PUT("/:id",
+			middleware.RequirePermission("load_balancers:write"),
+			r.requireApproval(middleware.ResourceChange(models.OperationUpdate, models.ResourceLoadBalancer)),
+			r.loadBalancerHandler.Update)
+		loadBalancers.DELETE("/:id",
+			middleware.RequirePermission("load_balancers:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.requireApproval(middleware.ResourceChange(models.OperationDelete, models.ResourceLoadBalancer)),
+			r.loadBalancerHandler.Delete)
+	}
+
+	// Features of the northbound schema, and the routes of those it lacks
+	// are hidden
+	group.GET("/capabilities",
+		middleware.RequirePermission("clusters:read"),
+		r.capabilityHandler.Get)
+
+	// DNS records
+	dns := group.Group("/dns", middleware.RequireOVNCapability(r.clusters, ovn.CapabilityDNS))
+	dns.Use(middleware.RequirePermission("dns:read"))
+	{
+		dns.GET("", r.dnsHandler.List)
+		dns.GET("/:id", r.dnsHandler.Get)
+
+		dns.POST("",
+			middleware.RequirePermission("dns:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.dnsHandler.Create)
+		dns.PUT("/:id",
+			middleware.RequirePermission("dns:write"),
+			r.dnsHandler.Update)
+		dns.DELETE("/:id",
+			middleware.RequirePermission("dns:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.dnsHandler.Delete)
+	}
+
+	// Port mirroring
+	mirrors := group.Group("/mirrors", middleware.RequireOVNCapability(r.clusters, ovn.CapabilityMirrors))
+	mirrors.Use(middleware.RequirePermission("mirrors:read"))
+	{
+		mirrors.GET("", r.mirrorHandler.List)
+		mirrors.GET("/:id", r.mirrorHandler.Get)
+
+		mirrors.POST("",
+			middleware.RequirePermission("mirrors:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.mirrorHandler.Create)
+		mirrors.PUT("/:id",
+			middleware.RequirePermission("mirrors:write"),
+			r.mirrorHandler.Update)
+		mirrors.DELETE("/:id",
+			middleware.RequirePermission("mirrors:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.mirrorHandler.Delete)
+		mirrors.PUT("/:id/ports/:portId",
+			middleware.RequirePermission("mirrors:write"),
+			r.mirrorHandler.AttachPort)
+		mirrors.DELETE("/:id/ports/:portId",
+			middleware.RequirePermission("mirrors:write"),
+			r.mirrorHandler.DetachPort)
+	}
+
+	// Flow sampling and IPFIX export
+	sampling := group.Group("/sampling", middleware.RequireOVNCapability(r.clusters, ovn.CapabilitySampling))
+	sampling.Use(middleware.RequirePermission("sampling:read"))
+	{
+		sampling.GET("/collectors", r.samplingHandler.ListCollectors)
+		sampling.GET("/collectors/:id", r.samplingHandler.GetCollector)
+		sampling.GET("/apps", r.samplingHandler.ListApps)
+
+		sampling.POST("/collectors",
+			middleware.RequirePermission("sampling:write"),
+			middleware.EndpointRateLimit(10, 100),
+			r.samplingHandler.CreateCollector)
+		sampling.PUT("/collectors/:id",
+			middleware.RequirePermission("sampling:write"),
+			r.samplingHandler.UpdateCollector)
+		sampling.DELETE("/collectors/:id",
+			middleware.RequirePermission("sampling:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.samplingHandler.DeleteCollector)
+		sampling.PUT("/apps/:type",
+			middleware.RequirePermission("sampling:write"),
+			r.samplingHandler.SetApp)
+		sampling.DELETE("/apps/:type",
+			middleware.RequirePermission("sampling:write"),
+			r.samplingHandler.DeleteApp)
+	}
+
+	// Kubernetes NetworkPolicy translation
+	networkPolicies := group.Group("/network-policies")
+	networkPolicies.Use(middleware.RequirePermission("network_policies:read"))
+	{
+		networkPolicies.GET("", r.networkPolicyHandler.List)
+		networkPolicies.GET("/:namespace/:name", r.networkPolicyHandler.Get)
+
+		networkPolicies.POST("",
+			middleware.RequirePermission("network_policies:write"),
+			middleware.EndpointRateLimit(10, 50),
+			r.requireApproval(nil),
+			r.networkPolicyHandler.Apply)
+		networkPolicies.DELETE("/:namespace/:name",
+			middleware.RequirePermission("network_policies:delete"),
+			middleware.EndpointRateLimit(5, 20),
+			r.requireApproval(nil),
+			r.networkPolicyHandler.Delete)
+	}
+
+	// Declarative apply of a desired state document
+	group.POST("/apply",
+		middleware.RequirePermission("apply:write"),
+		middleware.EndpointRateLimit(2, 5),
+		r.requireApproval(nil),
+		r.applyHandler.Apply)
+
+	// Import of the logical network of an NB database export or
+	// ovn-nbctl show output, applied like a desired state document
+	group.POST("/import",
+		middleware.RequirePermission("apply:write"),
+		middleware.EndpointRateLimit(1, 5),
+		r.requireApproval(nil),
+		r.importHandler.Import)
+
+	// Export of current resources for infrastructure-as-code tooling
+	group.GET("/export",
+		middleware.RequirePermission("export:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.exportHandler.Export)
+
+	// Transactions - requires admin permission
+	group.POST("/transactions", 
+		middleware.RequirePermission("admin"),
+		middleware.EndpointRateLimit(5, 10),
+		r.requireApproval(middleware.TransactionChange),
+		r.transactionHandler.Execute)
+	if r.chunkedTransactionHandler != nil {
+		chunked := group.Group("/transactions/chunked", middleware.RequirePermission("admin"))
+		chunked.GET("", r.chunkedTransactionHandler.List)
+		chunked.GET("/:id", r.chunkedTransactionHandler.Get)
+		chunked.POST("",
+			middleware.EndpointRateLimit(1, 5),
+			r.requireApproval(middleware.TransactionChange),
+			r.chunkedTransactionHandler.Start)
+		chunked.POST("/:id/resume",
+			middleware.EndpointRateLimit(1, 5),
+			r.chunkedTransactionHandler.Resume)
+	}
+
+	// Reports
+	group.GET("/reports/unused",
+		middleware.RequirePermission("reports:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.reportHandler.Unused)
+	group.GET("/reports/security",
+		middleware.RequirePermission("reports:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.reportHandler.Security)
+
+	// Compliance
+	group.GET("/compliance/packs",
+		middleware.RequirePermission("compliance:read"),
+		r.complianceHandler.Packs)
+	group.GET("/compliance/report",
+		middleware.RequirePermission("compliance:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.complianceHandler.Report)
+	group.POST("/compliance/evaluate",
+		middleware.RequirePermission("compliance:evaluate"),
+		middleware.EndpointRateLimit(2, 5),
+		r.complianceHandler.Evaluate)
+
+	// Validation
+	group.GET("/validate/addresses",
+		middleware.RequirePermission("validate:read"),
+		middleware.EndpointRateLimit(5, 10),
+		r.validationHandler.Addresses)
+
+	// Gateways
+	gateways := group.Group("", middleware.RequireOVNCapability(r.clusters, ovn.CapabilityGatewayChassis))
+	gateways.GET("/gateways",
+		middleware.RequirePermission("gateways:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.gatewayHandler.List)
+	gateways.GET("/gateways/placement",
+		middleware.RequirePermission("gateways:read"),
+		r.gatewayHandler.Placement)
+	gateways.PUT("/gateways/:portId/chassis",
+		middleware.RequirePermission("gateways:write"),
+		r.gatewayHandler.SetChassis)
+	gateways.POST("/gateways:rebalance",
+		middleware.RequirePermission("gateways:write"),
+		middleware.EndpointRateLimit(1, 2),
+		r.gatewayHandler.Rebalance)
+
+	// Chassis
+	group.GET("/chassis",
+		middleware.RequirePermission("chassis:read"),
+		middleware.EndpointRateLimit(2, 5),
+		r.chassisHandler.List)
+	group.GET("/chassis/:name",
+		middleware.RequirePermission("chassis:read"),
+		r.chassisHandler.Get)
+
+	// Topology
+	group.GET("/topology",
+		middleware.RequirePermission("topology:read"),
+		r.topologyHandler.GetTopology)
+	group.GET("/topology/snapshots",
+		middleware.RequirePermission("topology:read"),
+		r.topologyHandler.ListSnapshots)
+	group.GET("/topology/path",
+		middleware.RequirePermission("topology:read"),
+		r.topologyHandler.GetPath)
+	group.GET("/topology/diff",
+		middleware.RequirePermission("topology:read"),
+		r.topologyDiffHandler.Diff)
+
+	// OpenStack Neutron mapping
+	group.GET("/integrations/neutron/networks",
+		middleware.RequirePermission("topology:read"),
+		r.neutronHandler.ListNetworks)
+	group.GET("/integrations/neutron/networks/:id",
+		middleware.RequirePermission("topology:read"),
+		r.neutronHandler.GetNetwork)
+	group.GET("/integrations/neutron/routers",
+		middleware.RequirePermission("topology:read"),
+		r.neutronHandler.ListRouters)
+	group.GET("/integrations/neutron/ports/:id",
+		middleware.RequirePermission("topology:read"),
+		r.neutronHandler.GetPort)
+}
+
+// requireApproval queues writes that need a tenant admin's approval as
+// changes built by build; see middleware.RequireChangeApproval
+func (r *Router) requireApproval(build middleware.ChangeBuilder) gin.HandlerFunc {
+	return middleware.RequireChangeApproval(r.tenantChanges, build, r.logger)
+}
+
+// validateToken accepts local session tokens and ID tokens from configured
+// OIDC providers
+func (r *Router) validateToken(ctx context.Context, token string) (*models.User, error) {
+	if user, err := r.authService.ValidateToken(ctx, token); err == nil {
+		return user, nil
+	}
+	return r.authService.ValidateProviderToken(ctx, token)
 }
 
 func (r *Router) Engine() *gin.Engine {
 	return r.engine
 }
 
+// ConfigReloader returns the reloader of the configuration, to which other
+// reloadable settings can be attached
+func (r *Router) ConfigReloader() *services.ConfigReloader {
+	return r.configReloader
+}
+
+// Run runs the router's background jobs until ctx is done. Jobs working
+// on shared state, such as snapshots, usage aggregation and webhook
+// deliveries, only run on the replica elected leader; the others serve
+// this replica alone.
+func (r *Router) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if r.aclLogs != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.aclLogs.Run(ctx)
+		}()
+	}
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		r.configReloader.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		r.notifications.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		r.ovnConnectivity.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		r.leader.Run(ctx, r.runLeaderJobs)
+	}()
+	if r.trafficMonitor != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.trafficMonitor.Run(ctx)
+		}()
+	}
+	// The recycle bin is on the replica's disk, so every replica purges
+	// its own
+	if r.recycleBin != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.recycleBin.Run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if r.ovsStats != nil {
+		r.ovsStats.Close()
+	}
+	if r.aclCollector != nil {
+		r.aclCollector.Close()
+	}
+	r.chassisInventory.Close()
+	if r.drStaging != nil {
+		r.drStaging.Close()
+	}
+	if r.cache != nil {
+		if err := r.cache.Close(); err != nil {
+			r.logger.Warn("Failed to close cache", zap.Error(err))
+		}
+	}
+}
+
+// runLeaderJobs runs the background jobs of the leader until ctx is done
+func (r *Router) runLeaderJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	if r.meter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.meter.Run(ctx)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.topologyHistory.Run(ctx)
+	}()
+	if r.aclStats != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.aclStats.Run(ctx)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.webhooks.Run(ctx)
+	}()
+	if r.config.Compliance.Interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.compliance.Run(ctx)
+		}()
+	}
+	if r.aclRollouts != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.aclRollouts.Run(ctx)
+		}()
+	}
+	if r.aclSchedules != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.aclSchedules.Run(ctx)
+		}()
+	}
+	if r.accessGrants != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.accessGrants.Run(ctx)
+		}()
+	}
+	if r.vpnConnections != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.vpnConnections.Run(ctx)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.tenantReclaimer.Run(ctx)
+	}()
+	if r.drift != nil && r.config.Drift.Interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.drift.Run(ctx)
+		}()
+	}
+	r.tenantUsage.Run(ctx)
+	wg.Wait()
+}
+
+// newLeaderElector elects the replica running the background jobs with
+// the lock LEADER_ELECTION selects
+func newLeaderElector(cfg *config.Config, database db.Repository, c cache.Cache, logger *zap.Logger) *cluster.LeaderElector {
+	backend := cfg.Leader.Election
+	redisClient := cache.RedisClient(c)
+	if backend == "auto" {
+		switch {
+		case database.Dialect() == db.DialectPostgres:
+			backend = "postgres"
+		case redisClient != nil:
+			backend = "redis"
+		default:
+			backend = "none"
+		}
+	}
+
+	var lock cluster.LeaderLock
+	switch backend {
+	case "postgres":
+		lock = cluster.NewPostgresLeaderLock(database.DB(), cluster.LeaderLockKey, cfg.Leader.NodeID)
+	case "redis":
+		lock = cluster.NewRedisLeaderLock(redisClient, "leader", cfg.Leader.NodeID, cfg.Leader.LeaseDuration, logger)
+	default:
+		lock = cluster.NewLocalLeaderLock(cfg.Leader.NodeID)
+	}
+	logger.Info("Electing the leader running background jobs",
+		zap.String("backend", backend), zap.String("node_id", cfg.Leader.NodeID))
+	return cluster.NewLeaderElector(lock, backend, cfg.Leader.NodeID, cfg.Leader.RenewInterval, logger)
+}
+
+// apiVersion is the version the API reports
+const apiVersion = "0.2.0"
+
+// recentErrorCount is the number of errors logged kept for diagnostics
+const recentErrorCount = 100
+
+// ovnStatusProvider is implemented by OVN services that can report the state
+// of their northbound connection
+type ovnStatusProvider interface {
+	ConnectionStatus() ovn.ConnectionStatus
+	ReadPoolStats() *ovn.PoolStats
+}
+
 func (r *Router) healthCheck(c *gin.Context) {
-	c.JSON(200, gin.H{
+	response := gin.H{
 		"status":  "healthy",
 		"service": "ovncp-api",
-		"version": "0.2.0",
-	})
+		"version": apiVersion,
+	}
+
+	// A lost OVN connection degrades the API but the process stays live
+	// while the client reconnects, so this still answers 200
+	if r.ovnStatus != nil {
+		status := r.ovnStatus.ConnectionStatus()
+		if !status.Connected {
+			response["status"] = "degraded"
+		}
+		response["ovn"] = status
+		if stats := r.ovnStatus.ReadPoolStats(); stats != nil {
+			response["ovn_read_pool"] = gin.H{
+				"total":    stats.TotalConns,
+				"active":   stats.ActiveConns,
+				"idle":     stats.IdleConns,
+				"timeouts": stats.Timeouts,
+			}
+		}
+	}
+
+	c.JSON(200, response)
 }
 
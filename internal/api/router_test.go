@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/db"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/secrets"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// TestRouterSandboxWithoutAuth boots the API as make run-sandbox does:
+// the seeded sandbox backend with authentication disabled
+func TestRouterSandboxWithoutAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"OVN_BACKEND":              config.OVNBackendSandbox,
+		"OVN_SANDBOX_SEED":         "true",
+		"DB_TYPE":                  "memory",
+		"AUTH_ENABLED":             "false",
+		"BACKUP_PATH":              dir + "/backups",
+		"CHANGESET_PATH":           dir + "/changesets",
+		"CHUNKED_TRANSACTION_PATH": dir + "/transactions",
+		"TRASH_PATH":               dir + "/trash",
+	} {
+		t.Setenv(name, value)
+	}
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+	database, err := db.New(&cfg.Database)
+	require.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+	_, err = database.MigrateUp()
+	require.NoError(t, err)
+
+	sandbox, err := services.NewSandboxOVNService(ctx, database, cfg.OVN.ClusterName, cfg.OVN.SandboxSeed, logger)
+	require.NoError(t, err)
+	clusters := services.NewOVNClusterManager(logger)
+	t.Cleanup(clusters.Close)
+	jwtSecret, err := secrets.NewRotator(secrets.NewResolver(), 0, logger).Add(ctx, "JWT_SECRET", cfg.Auth.JWTSecret)
+	require.NoError(t, err)
+
+	router := NewRouter(sandbox, clusters, cfg, database, jwtSecret, logger)
+
+	w := httptest.NewRecorder()
+	router.Engine().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/switches", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body struct {
+		Switches []*models.LogicalSwitch `json:"switches"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	names := make([]string, len(body.Switches))
+	for i, ls := range body.Switches {
+		names[i] = ls.Name
+	}
+	assert.ElementsMatch(t, []string{"web-tier", "app-tier"}, names)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 )
 
 //go:embed swagger-ui/*
@@ -95,9 +96,7 @@ func (r *Router) SetupSwaggerRoutes() {
 func (r *Router) swaggerUI(c *gin.Context) {
 	tmpl, err := template.New("swagger").Parse(SwaggerUIHTML)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to load documentation UI",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to load documentation UI"))
 		return
 	}
 	
@@ -109,9 +108,7 @@ func (r *Router) swaggerUI(c *gin.Context) {
 	
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	if err := tmpl.Execute(c.Writer, data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to render documentation UI",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to render documentation UI"))
 	}
 }
 
@@ -148,9 +145,7 @@ func (r *Router) SetupReDocRoutes() {
 func (r *Router) reDoc(c *gin.Context) {
 	tmpl, err := template.New("redoc").Parse(ReDocHTML)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to load API reference",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to load API reference"))
 		return
 	}
 	
@@ -162,8 +157,6 @@ func (r *Router) reDoc(c *gin.Context) {
 	
 	c.Header("Content-Type", "text/html; charset=utf-8")
 	if err := tmpl.Execute(c.Writer, data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to render API reference",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to render API reference"))
 	}
 }
\ No newline at end of file
@@ -9,9 +9,12 @@ import (
 )
 
 // RegisterTemplateRoutes registers policy template routes
-func RegisterTemplateRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInterface, logger *zap.Logger) {
+func RegisterTemplateRoutes(v1 *gin.RouterGroup, ovnService services.OVNServiceInterface, catalog *services.ServiceCatalog, logger *zap.Logger) {
 	// Create template service and handler
 	templateService := services.NewTemplateService(ovnService, logger)
+	if catalog != nil {
+		templateService.SetServiceCatalog(catalog)
+	}
 	templateHandler := handlers.NewTemplateHandler(templateService, logger)
 
 	// Template routes
@@ -3,20 +3,20 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/lspecian/ovncp/internal/api/handlers"
-	"github.com/lspecian/ovncp/internal/db"
 	"github.com/lspecian/ovncp/internal/middleware"
 	"github.com/lspecian/ovncp/internal/services"
 	"go.uber.org/zap"
 )
 
 // RegisterTenantRoutes registers tenant management routes
-func RegisterTenantRoutes(v1 *gin.RouterGroup, db *db.DB, logger *zap.Logger) {
-	// Create tenant service and handler
-	tenantService := services.NewTenantService(db, logger)
-	tenantHandler := handlers.NewTenantHandler(tenantService, logger)
+func RegisterTenantRoutes(v1 *gin.RouterGroup, tenantService *services.TenantService, reclaimer *services.TenantReclaimer, tenantChanges *services.TenantChangeService, usage *services.TenantUsageRecorder, webhookHandler *handlers.WebhookHandler, logger *zap.Logger) {
+	// Create handlers
+	tenantHandler := handlers.NewTenantHandler(tenantService, reclaimer, usage, logger)
+	changeHandler := handlers.NewTenantChangeHandler(tenantChanges)
 
-	// Public tenant routes (no tenant context required)
-	tenants := v1.Group("/tenants")
+	// Public tenant routes (no tenant context required); routes for one
+	// tenant check the user's role in it
+	tenants := v1.Group("/tenants", middleware.TenantParam(tenantService))
 	{
 		// List user's tenants
 		tenants.GET("",
@@ -45,11 +45,22 @@ func RegisterTenantRoutes(v1 *gin.RouterGroup, db *db.DB, logger *zap.Logger) {
 			middleware.RequireTenantRole("admin"),
 			tenantHandler.DeleteTenant)
 
+		// Progress of a tenant deletion
+		tenants.GET("/:id/deletion",
+			middleware.RequirePermission("tenants:delete"),
+			middleware.RequireTenantRole("admin"),
+			tenantHandler.GetDeletion)
+
 		// Get resource usage
 		tenants.GET("/:id/usage",
 			middleware.RequirePermission("tenants:read"),
 			tenantHandler.GetResourceUsage)
 
+		// Usage over time, for chargeback and showback reports
+		tenants.GET("/:id/usage/history",
+			middleware.RequirePermission("tenants:read"),
+			tenantHandler.GetUsageHistory)
+
 		// Member management
 		members := tenants.Group("/:id/members")
 		members.Use(middleware.RequireTenantRole("admin"))
@@ -68,6 +79,24 @@ func RegisterTenantRoutes(v1 *gin.RouterGroup, db *db.DB, logger *zap.Logger) {
 				tenantHandler.CreateInvitation)
 		}
 
+		// Changes awaiting approval; members can follow their changes and
+		// tenant admins approve or reject them
+		changes := tenants.Group("/:id/changes")
+		{
+			changes.GET("",
+				middleware.RequirePermission("tenants:read"),
+				changeHandler.List)
+			changes.GET("/:change_id",
+				middleware.RequirePermission("tenants:read"),
+				changeHandler.Get)
+			changes.POST("/:change_id/approve",
+				middleware.RequireTenantRole("admin"),
+				changeHandler.Approve)
+			changes.POST("/:change_id/reject",
+				middleware.RequireTenantRole("admin"),
+				changeHandler.Reject)
+		}
+
 		// API key management
 		apiKeys := tenants.Group("/:id/api-keys")
 		apiKeys.Use(middleware.RequireTenantRole("admin"))
@@ -75,6 +104,19 @@ func RegisterTenantRoutes(v1 *gin.RouterGroup, db *db.DB, logger *zap.Logger) {
 			apiKeys.GET("", tenantHandler.ListAPIKeys)
 			apiKeys.POST("", tenantHandler.CreateAPIKey)
 			apiKeys.DELETE("/:key_id", tenantHandler.DeleteAPIKey)
+			apiKeys.POST("/:key_id/rotate", tenantHandler.RotateAPIKey)
+		}
+
+		// Webhooks notified of the tenant's events
+		webhooks := tenants.Group("/:id/webhooks")
+		webhooks.Use(middleware.RequireTenantRole("admin"))
+		{
+			webhooks.GET("", webhookHandler.List)
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("/:webhook_id", webhookHandler.Get)
+			webhooks.PUT("/:webhook_id", webhookHandler.Update)
+			webhooks.DELETE("/:webhook_id", webhookHandler.Delete)
+			webhooks.GET("/:webhook_id/deliveries", webhookHandler.Deliveries)
 		}
 	}
 
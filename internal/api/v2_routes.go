@@ -0,0 +1,120 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/handlers"
+	"github.com/lspecian/ovncp/internal/api/versioning"
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/middleware"
+)
+
+// apiVersions returns the versions of the API. Handlers respond as v1
+// documents, and v2 converts their requests and responses; v1 stays stable
+// as v2 makes breaking changes.
+//
+// v2 differs from v1 in that:
+//   - lists are only paged with cursors, and return their items as items
+//   - errors are problem details without the error and details members
+//   - resources are only served to callers scoped to a tenant, or who may
+//     see every tenant's
+func apiVersions(cfg config.APIConfig) (v1, v2 versioning.Version) {
+	v1 = versioning.Version{Name: "v1", Successor: "v2"}
+	if cfg.V1Deprecated != "" {
+		v1.Deprecated, _ = time.Parse(time.DateOnly, cfg.V1Deprecated)
+	}
+	if cfg.V1Sunset != "" {
+		v1.Sunset, _ = time.Parse(time.DateOnly, cfg.V1Sunset)
+	}
+
+	v2 = versioning.Version{
+		Name: "v2",
+		Requests: []versioning.RequestConverter{
+			versioning.RejectQueryParams("cursor", "offset", "page"),
+		},
+		Responses: []versioning.ResponseConverter{
+			versioning.DropProblemMembers("error", "details"),
+			versioning.ListEnvelope("items"),
+		},
+	}
+	return v1, v2
+}
+
+// setupV2Routes registers /api/v2: authentication, clusters, the logical
+// network resources and their history, locks and recycle bin. Other routes are
+// only served by v1 until they move.
+func (r *Router) setupV2Routes(version versioning.Version, authMiddleware, idempotency gin.HandlerFunc) {
+	v2 := r.engine.Group(version.Prefix(), version.Middleware())
+
+	public := v2.Group("/auth")
+	{
+		public.POST("/login", r.authHandler.Login)
+		public.POST("/login/local", r.authHandler.LocalLogin)
+		public.GET("/callback/:provider", r.authHandler.Callback)
+		public.POST("/refresh", r.authHandler.Refresh)
+	}
+
+	v2.Use(authMiddleware, idempotency)
+
+	auth := v2.Group("/auth")
+	{
+		auth.POST("/logout", r.authHandler.Logout)
+		auth.GET("/profile", r.authHandler.GetProfile)
+		auth.GET("/users",
+			middleware.RequirePermission("users:read"),
+			r.authHandler.ListUsers)
+		auth.GET("/users/:id",
+			middleware.RequirePermission("users:read"),
+			r.authHandler.GetUser)
+		auth.PUT("/users/:id/role",
+			middleware.RequirePermission("users:write"),
+			r.authHandler.UpdateUserRole)
+		auth.DELETE("/users/:id",
+			middleware.RequirePermission("users:write"),
+			r.authHandler.DeactivateUser)
+	}
+
+	v2.Use(middleware.TenantContext(r.tenantService))
+	v2.Use(middleware.WriteFreeze(r.maintenance, r.logger))
+
+	clusterHandler := handlers.NewOVNClusterHandler(r.clusters)
+	v2.GET("/clusters",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.List)
+	v2.GET("/clusters/:name",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.Get)
+	v2.GET("/clusters/:name/health",
+		middleware.RequirePermission("clusters:read"),
+		clusterHandler.Health)
+
+	scoped := v2.Group("", middleware.RequireTenantScope())
+	r.registerOVNRoutes(scoped.Group("/clusters/:name", clusterHandler.SelectCluster))
+
+	scoped.Use(clusterHandler.SelectCluster)
+	r.registerOVNRoutes(scoped)
+
+	scoped.GET("/resources/:uuid/history",
+		middleware.RequirePermission("history:read"),
+		r.resourceHistoryHandler.History)
+
+	r.registerLockRoutes(scoped)
+
+	if r.trashHandler != nil {
+		trash := scoped.Group("/trash", middleware.ResourceOwnership())
+		trash.GET("",
+			middleware.RequirePermission("trash:read"),
+			r.trashHandler.List)
+		trash.GET("/:id",
+			middleware.RequirePermission("trash:read"),
+			r.trashHandler.Get)
+		trash.POST("/:id/restore",
+			middleware.RequirePermission("trash:write"),
+			r.trashHandler.Restore)
+		trash.DELETE("/:id",
+			middleware.RequirePermission("trash:delete"),
+			r.trashHandler.Purge)
+	}
+}
@@ -0,0 +1,72 @@
+package versioning
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+)
+
+// RejectQueryParams rejects requests using query parameters a version no
+// longer takes; instead says what to use in their place
+func RejectQueryParams(instead string, params ...string) RequestConverter {
+	return func(c *gin.Context) error {
+		query := c.Request.URL.Query()
+		for _, param := range params {
+			if query.Has(param) {
+				return fmt.Errorf("the %s query parameter isn't supported in this API version; use %s", param, instead)
+			}
+		}
+		return nil
+	}
+}
+
+// DropProblemMembers removes members from problem details responses, such
+// as those only kept for clients of earlier versions
+func DropProblemMembers(members ...string) ResponseConverter {
+	return func(c *gin.Context, body map[string]interface{}) {
+		if !strings.HasPrefix(c.Writer.Header().Get("Content-Type"), problem.ContentType) {
+			return
+		}
+		for _, member := range members {
+			delete(body, member)
+		}
+	}
+}
+
+// ListEnvelope renames the items of list responses, those with a
+// pagination member and a single array, to member, and drops their count,
+// which is the length of the array. Empty lists encoded as null become
+// empty arrays.
+func ListEnvelope(member string) ResponseConverter {
+	return func(c *gin.Context, body map[string]interface{}) {
+		if _, paged := body["pagination"]; !paged {
+			return
+		}
+
+		listKey := ""
+		for key, value := range body {
+			if _, isArray := value.([]interface{}); !isArray && value != nil {
+				continue
+			}
+			if listKey != "" {
+				// Not a plain list; leave it as is
+				return
+			}
+			listKey = key
+		}
+		if listKey == "" {
+			return
+		}
+
+		items := body[listKey]
+		if items == nil {
+			items = []interface{}{}
+		}
+		delete(body, listKey)
+		delete(body, "count")
+		body[member] = items
+	}
+}
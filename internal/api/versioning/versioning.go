@@ -0,0 +1,236 @@
+// Package versioning serves several versions of the API side by side. Each
+// version is a router group under /api/<name> whose requests and JSON
+// responses pass through the version's converters, so handlers are written
+// once and each version still behaves as documented. Deprecated versions
+// announce it in every response.
+package versioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+)
+
+const (
+	// Header names the API version that served a response
+	Header = "API-Version"
+	// DeprecationHeader is set, to when the version was deprecated, on
+	// responses of deprecated versions (RFC 9745)
+	DeprecationHeader = "Deprecation"
+	// SunsetHeader is set, to when the version will be removed, on
+	// responses of versions with a scheduled removal (RFC 8594)
+	SunsetHeader = "Sunset"
+
+	contextKey = "api_version"
+)
+
+// RequestConverter adapts a request to the form handlers take. An error
+// rejects the request with a 400.
+type RequestConverter func(c *gin.Context) error
+
+// ResponseConverter adapts the JSON object a handler responded with. It is
+// only called for JSON object bodies, which it changes in place.
+type ResponseConverter func(c *gin.Context, body map[string]interface{})
+
+// Version is a version of the API, served under /api/<Name>
+type Version struct {
+	Name string
+	// Deprecated is when the version was deprecated; zero if it isn't
+	Deprecated time.Time
+	// Sunset is when the version may be removed; zero if it isn't planned
+	Sunset time.Time
+	// Successor names the version clients should move to
+	Successor string
+
+	Requests  []RequestConverter
+	Responses []ResponseConverter
+}
+
+// Prefix is the path the version is served under
+func (v Version) Prefix() string {
+	return "/api/" + v.Name
+}
+
+// Middleware tags requests and responses with the version, announces its
+// deprecation and runs its converters. It must come before any middleware
+// that reads or writes response bodies, so that they see handlers'
+// responses before conversion.
+func (v Version) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, v.Name)
+		c.Header(Header, v.Name)
+		if !v.Deprecated.IsZero() {
+			c.Header(DeprecationHeader, fmt.Sprintf("@%d", v.Deprecated.Unix()))
+		}
+		if !v.Sunset.IsZero() {
+			c.Header(SunsetHeader, v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if v.Successor != "" && (!v.Deprecated.IsZero() || !v.Sunset.IsZero()) {
+			c.Writer.Header().Add("Link", fmt.Sprintf(`</api/%s>; rel="successor-version"`, v.Successor))
+		}
+
+		if len(v.Responses) == 0 || isStream(c.Request) {
+			v.serve(c)
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		v.serve(c)
+		c.Writer = writer.ResponseWriter
+		if writer.direct {
+			return
+		}
+
+		body := writer.body.Bytes()
+		if isJSON(c.Writer.Header().Get("Content-Type")) {
+			body = v.convertResponse(c, body)
+		}
+		c.Writer.WriteHeader(writer.status)
+		if len(body) > 0 {
+			c.Writer.Write(body)
+		} else {
+			c.Writer.WriteHeaderNow()
+		}
+	}
+}
+
+// serve converts the request and hands it to the next handlers
+func (v Version) serve(c *gin.Context) {
+	for _, convert := range v.Requests {
+		if err := convert(c); err != nil {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "invalid request").WithError(err))
+			return
+		}
+	}
+	c.Next()
+}
+
+// convertResponse runs the response converters on a JSON body, returning it
+// unchanged if it isn't an object
+func (v Version) convertResponse(c *gin.Context, data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep 64-bit integers, such as tunnel keys and counters, exact
+	decoder.UseNumber()
+	var body map[string]interface{}
+	if err := decoder.Decode(&body); err != nil || body == nil {
+		return data
+	}
+
+	for _, convert := range v.Responses {
+		convert(c, body)
+	}
+
+	converted, err := json.Marshal(body)
+	if err != nil {
+		return data
+	}
+	return converted
+}
+
+// FromContext returns the name of the API version serving the request, or
+// "" outside versioned routes
+func FromContext(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+// isStream reports whether the request expects a streamed response, which
+// can't be buffered for conversion
+func isStream(req *http.Request) bool {
+	return req.Header.Get("Upgrade") != "" || strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, problem.ContentType)
+}
+
+// bufferedWriter holds the response of a handler until it is converted.
+// Responses that aren't JSON, such as CSV or NDJSON streams, go straight
+// through, direct, as they aren't converted.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	direct  bool
+	body    bytes.Buffer
+}
+
+// start decides, once the handler starts writing, whether the response goes
+// straight through
+func (w *bufferedWriter) start() {
+	if w.written {
+		return
+	}
+	w.written = true
+	if contentType := w.Header().Get("Content-Type"); contentType != "" && !isJSON(contentType) {
+		w.direct = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.direct {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.start()
+	if w.direct {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.start()
+	if w.direct {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.start()
+	if w.direct {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.direct {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.direct {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Flush sends what was written of direct responses; the others are sent
+// once converted
+func (w *bufferedWriter) Flush() {
+	if w.direct {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -0,0 +1,126 @@
+package versioning
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+)
+
+func newEngine(version Version) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	group := engine.Group(version.Prefix(), version.Middleware())
+	group.GET("/switches", func(c *gin.Context) {
+		c.Header("Link", `</api/v2/switches?cursor=abc>; rel="next"`)
+		c.JSON(http.StatusOK, gin.H{
+			"switches":   []gin.H{{"name": "web", "tunnel_key": uint64(1) << 60}},
+			"count":      1,
+			"pagination": gin.H{"total_count": 1},
+		})
+	})
+	group.GET("/switches/:id", func(c *gin.Context) {
+		problem.Respond(c, problem.New(http.StatusNotFound, "switch not found").WithDetail("no switch "+c.Param("id")))
+	})
+	group.GET("/ports", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ports": nil, "count": 0, "pagination": gin.H{"total_count": 0}})
+	})
+	group.GET("/version", func(c *gin.Context) {
+		c.String(http.StatusOK, FromContext(c))
+	})
+	group.GET("/switches/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString(`{"name":"web"}` + "\n")
+		c.Writer.Flush()
+	})
+	group.DELETE("/switches/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return engine
+}
+
+func serve(engine *gin.Engine, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestMiddleware_Deprecation(t *testing.T) {
+	deprecated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	engine := newEngine(Version{
+		Name:       "v1",
+		Deprecated: deprecated,
+		Sunset:     time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Successor:  "v2",
+	})
+
+	w := serve(engine, http.MethodGet, "/api/v1/version")
+	assert.Equal(t, "v1", w.Body.String())
+	assert.Equal(t, "v1", w.Header().Get(Header))
+	assert.Equal(t, "@1767225600", w.Header().Get(DeprecationHeader))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get(SunsetHeader))
+	assert.Equal(t, []string{`</api/v2>; rel="successor-version"`}, w.Header().Values("Link"))
+
+	// Supported versions don't announce a successor
+	engine = newEngine(Version{Name: "v1", Successor: "v2"})
+	w = serve(engine, http.MethodGet, "/api/v1/switches")
+	assert.Empty(t, w.Header().Get(DeprecationHeader))
+	assert.Equal(t, []string{`</api/v2/switches?cursor=abc>; rel="next"`}, w.Header().Values("Link"))
+}
+
+func TestMiddleware_Converters(t *testing.T) {
+	engine := newEngine(Version{
+		Name:      "v2",
+		Requests:  []RequestConverter{RejectQueryParams("cursor", "offset", "page")},
+		Responses: []ResponseConverter{DropProblemMembers("error", "details"), ListEnvelope("items")},
+	})
+
+	w := serve(engine, http.MethodGet, "/api/v2/switches")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"items": [{"name": "web", "tunnel_key": 1152921504606846976}],
+		"pagination": {"total_count": 1}
+	}`, w.Body.String())
+
+	w = serve(engine, http.MethodGet, "/api/v2/ports")
+	assert.JSONEq(t, `{"items": [], "pagination": {"total_count": 0}}`, w.Body.String())
+
+	w = serve(engine, http.MethodGet, "/api/v2/switches/db")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "switch not found", body["title"])
+	assert.Equal(t, "no switch db", body["detail"])
+	assert.NotContains(t, body, "error")
+	assert.NotContains(t, body, "details")
+
+	w = serve(engine, http.MethodGet, "/api/v2/switches?offset=20")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "the offset query parameter isn't supported in this API version; use cursor", body["detail"])
+	assert.NotContains(t, body, "error")
+
+	// Bodiless responses pass through
+	w = serve(engine, http.MethodDelete, "/api/v2/switches/web")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// Other content is left alone
+	w = serve(engine, http.MethodGet, "/api/v2/version")
+	assert.Equal(t, "v2", w.Body.String())
+
+	// and streams aren't held back
+	w = serve(engine, http.MethodGet, "/api/v2/switches/stream")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, w.Flushed)
+	assert.Equal(t, `{"name":"web"}`+"\n", w.Body.String())
+}
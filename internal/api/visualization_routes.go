@@ -1,10 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/services"
 	"github.com/lspecian/ovncp/internal/visualization"
 	"go.uber.org/zap"
@@ -12,18 +14,37 @@ import (
 
 // VisualizationHandler handles topology visualization endpoints
 type VisualizationHandler struct {
-	service *services.OVNService
+	service services.OVNServiceInterface
+	traffic TrafficSource
+	graphs  *visualization.GraphCache
 	logger  *zap.Logger
 }
 
+// TrafficSource returns the traffic of logical ports by name.
+// *services.TrafficMonitor implements it.
+type TrafficSource interface {
+	Traffic() map[string]services.PortTraffic
+}
+
 // NewVisualizationHandler creates a new visualization handler
-func NewVisualizationHandler(service *services.OVNService, logger *zap.Logger) *VisualizationHandler {
+func NewVisualizationHandler(service services.OVNServiceInterface, logger *zap.Logger) *VisualizationHandler {
 	return &VisualizationHandler{
 		service: service,
 		logger:  logger,
 	}
 }
 
+// SetTrafficSource overlays port traffic on generated graphs
+func (h *VisualizationHandler) SetTrafficSource(traffic TrafficSource) {
+	h.traffic = traffic
+}
+
+// SetGraphCache reuses generated graphs while the topology, traffic and
+// options are unchanged
+func (h *VisualizationHandler) SetGraphCache(graphs *visualization.GraphCache) {
+	h.graphs = graphs
+}
+
 // RegisterVisualizationRoutes registers visualization routes
 func (h *VisualizationHandler) RegisterVisualizationRoutes(router *gin.RouterGroup) {
 	viz := router.Group("/visualization")
@@ -34,6 +55,9 @@ func (h *VisualizationHandler) RegisterVisualizationRoutes(router *gin.RouterGro
 		viz.GET("/topology/node/:id", h.getNodeDetails)
 		viz.GET("/topology/path/:source/:target", h.getPath)
 	}
+
+	// Dashboards embed rendered images from the topology API
+	router.GET("/topology/export", h.exportTopology)
 }
 
 // getTopologyVisualization returns the network topology visualization
@@ -47,22 +71,14 @@ func (h *VisualizationHandler) getTopologyVisualization(c *gin.Context) {
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get topology", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve topology",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to retrieve topology"))
 		return
 	}
 
-	// Create visualizer
-	visualizer := visualization.NewTopologyVisualizer(topology)
-
 	// Generate graph
-	graph, err := visualizer.GenerateGraph(options)
+	graph, err := h.generateGraph(topology, options)
 	if err != nil {
-		h.logger.Error("Failed to generate visualization", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate visualization",
-		})
+		h.handleGraphError(c, err)
 		return
 	}
 
@@ -83,20 +99,14 @@ func (h *VisualizationHandler) exportTopology(c *gin.Context) {
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get topology", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve topology",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to retrieve topology"))
 		return
 	}
 
 	// Generate graph
-	visualizer := visualization.NewTopologyVisualizer(topology)
-	graph, err := visualizer.GenerateGraph(options)
+	graph, err := h.generateGraph(topology, options)
 	if err != nil {
-		h.logger.Error("Failed to generate visualization", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate visualization",
-		})
+		h.handleGraphError(c, err)
 		return
 	}
 
@@ -105,9 +115,7 @@ func (h *VisualizationHandler) exportTopology(c *gin.Context) {
 	data, err := exporter.Export(format)
 	if err != nil {
 		h.logger.Error("Failed to export topology", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Unsupported export format: " + format,
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Unsupported export format: "+format))
 		return
 	}
 
@@ -126,9 +134,7 @@ func (h *VisualizationHandler) getCustomTopology(c *gin.Context) {
 	// Parse custom options from request body
 	var options visualization.VisualizationOptions
 	if err := c.ShouldBindJSON(&options); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid visualization options",
-		})
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid visualization options"))
 		return
 	}
 
@@ -136,20 +142,14 @@ func (h *VisualizationHandler) getCustomTopology(c *gin.Context) {
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get topology", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve topology",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to retrieve topology"))
 		return
 	}
 
 	// Generate graph with custom options
-	visualizer := visualization.NewTopologyVisualizer(topology)
-	graph, err := visualizer.GenerateGraph(&options)
+	graph, err := h.generateGraph(topology, &options)
 	if err != nil {
-		h.logger.Error("Failed to generate visualization", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate visualization",
-		})
+		h.handleGraphError(c, err)
 		return
 	}
 
@@ -165,18 +165,14 @@ func (h *VisualizationHandler) getNodeDetails(c *gin.Context) {
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get topology", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve topology",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to retrieve topology"))
 		return
 	}
 
 	// Find node details
 	nodeDetails := h.findNodeDetails(topology, nodeID)
 	if nodeDetails == nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Node not found",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "Node not found"))
 		return
 	}
 
@@ -193,18 +189,14 @@ func (h *VisualizationHandler) getPath(c *gin.Context) {
 	topology, err := h.service.GetTopology(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get topology", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve topology",
-		})
+		problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to retrieve topology"))
 		return
 	}
 
 	// Find path between nodes
 	path := h.findPath(topology, source, target)
 	if len(path) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "No path found between nodes",
-		})
+		problem.Respond(c, problem.New(http.StatusNotFound, "No path found between nodes"))
 		return
 	}
 
@@ -216,6 +208,39 @@ func (h *VisualizationHandler) getPath(c *gin.Context) {
 	})
 }
 
+// handleGraphError answers 400 for options that can't be applied and 500
+// otherwise
+func (h *VisualizationHandler) handleGraphError(c *gin.Context, err error) {
+	if errors.Is(err, visualization.ErrInvalidOptions) {
+		problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid visualization options").
+			WithError(err))
+		return
+	}
+	h.logger.Error("Failed to generate visualization", zap.Error(err))
+	problem.Respond(c, problem.New(http.StatusInternalServerError, "Failed to generate visualization"))
+}
+
+// generateGraph returns the graph of topology with the current traffic,
+// from the graph cache when set. The graph may be shared, so mustn't be
+// modified.
+func (h *VisualizationHandler) generateGraph(topology *services.Topology, options *visualization.VisualizationOptions) (*visualization.TopologyGraph, error) {
+	var traffic map[string]services.PortTraffic
+	if h.traffic != nil {
+		traffic = h.traffic.Traffic()
+	}
+	generate := func() (*visualization.TopologyGraph, error) {
+		visualizer := visualization.NewTopologyVisualizer(topology)
+		if traffic != nil {
+			visualizer.SetTraffic(traffic)
+		}
+		return visualizer.GenerateGraph(options)
+	}
+	if h.graphs == nil {
+		return generate()
+	}
+	return h.graphs.Graph(topology, traffic, options, generate)
+}
+
 // parseVisualizationOptions parses visualization options from query parameters
 func (h *VisualizationHandler) parseVisualizationOptions(c *gin.Context) *visualization.VisualizationOptions {
 	options := visualization.DefaultVisualizationOptions()
@@ -264,6 +289,10 @@ func (h *VisualizationHandler) parseVisualizationOptions(c *gin.Context) *visual
 	// Filters
 	options.FilterByName = c.Query("name")
 
+	// Grouping
+	options.GroupBy = c.Query("groupBy")
+	options.CollapseGroups = h.parseBool(c.Query("collapse"), false)
+
 	return options
 }
 
@@ -285,6 +314,9 @@ func (h *VisualizationHandler) getContentType(format string) string {
 		"d3":        "application/json",
 		"mermaid":   "text/plain",
 		"html":      "text/html",
+		"svg":       "image/svg+xml",
+		"graphml":   "application/graphml+xml",
+		"drawio":    "application/vnd.jgraph.mxfile",
 	}
 
 	if ct, ok := contentTypes[format]; ok {
@@ -303,6 +335,9 @@ func (h *VisualizationHandler) getFileExtension(format string) string {
 		"d3":        "json",
 		"mermaid":   "mmd",
 		"html":      "html",
+		"svg":       "svg",
+		"graphml":   "graphml",
+		"drawio":    "drawio",
 	}
 
 	if ext, ok := extensions[format]; ok {
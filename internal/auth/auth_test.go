@@ -4,6 +4,7 @@ import (
 	"testing"
 	
 	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,4 +51,50 @@ func TestNewProvider_InvalidType(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, provider)
 	assert.Contains(t, err.Error(), "unsupported provider type")
-}
\ No newline at end of file
+}
+func TestClaimsToUserInfo(t *testing.T) {
+	claims := map[string]interface{}{
+		"sub":                "user-123",
+		"email":              "jane@example.com",
+		"preferred_username": "jane",
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"ovncp-admins", "offline_access"},
+		},
+	}
+	
+	info := claimsToUserInfo(claims, "realm_access.roles")
+	assert.Equal(t, "user-123", info.ID)
+	assert.Equal(t, "jane@example.com", info.Email)
+	assert.Equal(t, "jane", info.Name)
+	assert.Equal(t, []string{"ovncp-admins", "offline_access"}, info.Groups)
+	
+	// Keycloak group paths are reported with a leading slash
+	info = claimsToUserInfo(map[string]interface{}{"groups": []interface{}{"/netops"}}, "groups")
+	assert.Equal(t, []string{"netops"}, info.Groups)
+	
+	info = claimsToUserInfo(map[string]interface{}{}, "groups")
+	assert.Empty(t, info.Groups)
+}
+
+func TestMapGroupsToRole(t *testing.T) {
+	mapping := map[string]string{
+		"netops":  "operator",
+		"admins":  "admin",
+		"readers": "viewer",
+	}
+	
+	role, ok := MapGroupsToRole([]string{"readers", "netops"}, mapping, "")
+	assert.True(t, ok)
+	assert.Equal(t, models.RoleOperator, role)
+	
+	role, ok = MapGroupsToRole([]string{"netops", "admins"}, mapping, "")
+	assert.True(t, ok)
+	assert.Equal(t, models.RoleAdmin, role)
+	
+	role, ok = MapGroupsToRole([]string{"unknown"}, mapping, "viewer")
+	assert.True(t, ok)
+	assert.Equal(t, models.RoleViewer, role)
+	
+	_, ok = MapGroupsToRole([]string{"unknown"}, mapping, "")
+	assert.False(t, ok)
+}
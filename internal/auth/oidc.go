@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+const defaultGroupsClaim = "groups"
+
+// TokenVerifier is implemented by providers that can validate bearer tokens
+// they issued, allowing API clients to authenticate with an IdP token directly
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, rawIDToken string) (*UserInfo, error)
+}
+
+// VerifyIDToken validates the signature, issuer, audience and expiry of an
+// ID token and extracts the user information from its claims
+func (p *oidcProvider) VerifyIDToken(ctx context.Context, rawIDToken string) (*UserInfo, error) {
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse ID token claims: %w", err)
+	}
+
+	return claimsToUserInfo(claims, p.groupsClaim), nil
+}
+
+// claimsToUserInfo maps standard OIDC claims to UserInfo
+func claimsToUserInfo(claims map[string]interface{}, groupsClaim string) *UserInfo {
+	info := &UserInfo{}
+
+	if sub, ok := claims["sub"].(string); ok {
+		info.ID = sub
+	}
+	if email, ok := claims["email"].(string); ok {
+		info.Email = email
+	}
+	if name, ok := claims["name"].(string); ok {
+		info.Name = name
+	} else if username, ok := claims["preferred_username"].(string); ok {
+		info.Name = username
+	}
+	if picture, ok := claims["picture"].(string); ok {
+		info.Picture = picture
+	}
+
+	info.Groups = extractGroups(lookupClaim(claims, groupsClaim))
+
+	return info
+}
+
+// lookupClaim resolves a dotted claim path such as "realm_access.roles"
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	if path == "" {
+		return nil
+	}
+
+	// Exact match first, some IdPs use URLs containing dots as claim names
+	if value, ok := claims[path]; ok {
+		return value
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil
+		}
+	}
+
+	return current
+}
+
+// extractGroups normalizes a groups claim into a string slice
+func extractGroups(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok && s != "" {
+				groups = append(groups, strings.TrimPrefix(s, "/"))
+			}
+		}
+		return groups
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{strings.TrimPrefix(v, "/")}
+	}
+	return nil
+}
+
+// MapGroupsToRole returns the most privileged role granted by the user's
+// groups. The default role is used when no group matches; ok is false when
+// neither yields a valid role.
+func MapGroupsToRole(groups []string, mapping map[string]string, defaultRole string) (role models.UserRole, ok bool) {
+	best := -1
+	for _, group := range groups {
+		mapped, found := mapping[group]
+		if !found {
+			continue
+		}
+		r := models.UserRole(mapped)
+		if rank := roleRank(r); rank > best {
+			best = rank
+			role = r
+		}
+	}
+
+	if best >= 0 {
+		return role, true
+	}
+
+	if r := models.UserRole(defaultRole); r.IsValid() {
+		return r, true
+	}
+
+	return "", false
+}
+
+// roleRank orders roles by privilege
+func roleRank(role models.UserRole) int {
+	switch role {
+	case models.RoleAdmin:
+		return 2
+	case models.RoleOperator:
+		return 1
+	case models.RoleViewer:
+		return 0
+	}
+	return -1
+}
@@ -18,12 +18,14 @@ type UserInfo struct {
 	Email   string
 	Name    string
 	Picture string
+	Groups  []string
 }
 
 type Provider interface {
 	GetAuthURL(state string) string
 	ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error)
 	GetUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error)
 }
 
 func NewProvider(cfg config.OAuthProvider) (Provider, error) {
@@ -39,8 +41,10 @@ func NewProvider(cfg config.OAuthProvider) (Provider, error) {
 
 // OIDC Provider
 type oidcProvider struct {
-	config   *oauth2.Config
-	provider *oidc.Provider
+	config      *oauth2.Config
+	provider    *oidc.Provider
+	verifier    *oidc.IDTokenVerifier
+	groupsClaim string
 }
 
 func newOIDCProvider(cfg config.OAuthProvider) (*oidcProvider, error) {
@@ -59,9 +63,18 @@ func newOIDCProvider(cfg config.OAuthProvider) (*oidcProvider, error) {
 		Scopes:       cfg.Scopes,
 	}
 	
+	groupsClaim := cfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultGroupsClaim
+	}
+	
 	return &oidcProvider{
 		config:   oauth2Config,
 		provider: provider,
+		// The verifier fetches signing keys from the discovered jwks_uri and
+		// caches them, refetching only when it sees an unknown key ID
+		verifier:    provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		groupsClaim: groupsClaim,
 	}, nil
 }
 
@@ -74,28 +87,26 @@ func (p *oidcProvider) ExchangeCode(ctx context.Context, code string) (*oauth2.T
 }
 
 func (p *oidcProvider) GetUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
+	// Prefer the signed ID token returned with the code exchange
+	if rawIDToken, ok := token.Extra("id_token").(string); ok && rawIDToken != "" {
+		return p.VerifyIDToken(ctx, rawIDToken)
+	}
+	
 	userInfo, err := p.provider.UserInfo(ctx, p.config.TokenSource(ctx, token))
 	if err != nil {
 		return nil, err
 	}
 	
-	var claims struct {
-		Subject string `json:"sub"`
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-		Picture string `json:"picture"`
-	}
-	
+	var claims map[string]interface{}
 	if err := userInfo.Claims(&claims); err != nil {
 		return nil, err
 	}
 	
-	return &UserInfo{
-		ID:      claims.Subject,
-		Email:   claims.Email,
-		Name:    claims.Name,
-		Picture: claims.Picture,
-	}, nil
+	return claimsToUserInfo(claims, p.groupsClaim), nil
+}
+
+func (p *oidcProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	return refreshOAuth2Token(ctx, p.config, refreshToken)
 }
 
 // OAuth2 Provider
@@ -130,6 +141,10 @@ func (p *oauth2Provider) ExchangeCode(ctx context.Context, code string) (*oauth2
 	return p.config.Exchange(ctx, code)
 }
 
+func (p *oauth2Provider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	return refreshOAuth2Token(ctx, p.config, refreshToken)
+}
+
 func (p *oauth2Provider) GetUserInfo(ctx context.Context, token *oauth2.Token) (*UserInfo, error) {
 	client := p.config.Client(ctx, token)
 	
@@ -178,4 +193,21 @@ func (p *oauth2Provider) GetUserInfo(ctx context.Context, token *oauth2.Token) (
 	}
 	
 	return userInfo, nil
-}
\ No newline at end of file
+}
+
+// refreshOAuth2Token exchanges a refresh token for a new token at the provider
+func refreshOAuth2Token(ctx context.Context, cfg *oauth2.Config, refreshToken string) (*oauth2.Token, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("refresh token is required")
+	}
+	
+	// A token without an access token is never valid, so the token source
+	// always goes to the token endpoint
+	src := cfg.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken})
+	token, err := src.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	
+	return token, nil
+}
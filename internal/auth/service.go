@@ -23,6 +23,8 @@ type Service interface {
 	ListUsers(ctx context.Context, limit, offset int) ([]*models.User, int, error)
 	DeactivateUser(ctx context.Context, userID string) error
 	LocalLogin(ctx context.Context, username, password string) (*models.Session, error)
+	ValidateProviderToken(ctx context.Context, token string) (*models.User, error)
+	RefreshProviderToken(ctx context.Context, provider string, refreshToken string) (*models.Session, error)
 }
 
 type service struct {
@@ -89,6 +91,11 @@ func (s *service) ExchangeCode(ctx context.Context, provider string, code string
 		return nil, err
 	}
 	
+	// Sync role from IdP group membership
+	if err := s.applyRoleMapping(ctx, tx, provider, user, userInfo); err != nil {
+		return nil, err
+	}
+	
 	// Update last login
 	_, err = tx.ExecContext(ctx,
 		"UPDATE users SET last_login_at = $1 WHERE id = $2",
@@ -183,6 +190,131 @@ func (s *service) findOrCreateUser(ctx context.Context, tx *sql.Tx, provider str
 	return &user, nil
 }
 
+// applyRoleMapping updates the user's role from the provider's group mapping.
+// Users in no mapped group get the default role, else the least privileged
+// one, so leaving a group takes its role away. Providers without a mapping
+// leave roles under local administration.
+func (s *service) applyRoleMapping(ctx context.Context, tx *sql.Tx, provider string, user *models.User, userInfo *UserInfo) error {
+	providerCfg, ok := s.config.Providers[provider]
+	if !ok || (len(providerCfg.RoleMapping) == 0 && providerCfg.DefaultRole == "") {
+		return nil
+	}
+	
+	role, ok := MapGroupsToRole(userInfo.Groups, providerCfg.RoleMapping, providerCfg.DefaultRole)
+	if !ok {
+		role = models.RoleViewer
+	}
+	if role == user.Role {
+		return nil
+	}
+	
+	now := time.Now()
+	_, err := tx.ExecContext(ctx,
+		"UPDATE users SET role = $1, updated_at = $2 WHERE id = $3",
+		role, now, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	
+	user.Role = role
+	user.UpdatedAt = now
+	return nil
+}
+
+// ValidateProviderToken validates an ID token issued by one of the configured
+// OIDC providers and returns the matching user, creating it on first use
+func (s *service) ValidateProviderToken(ctx context.Context, token string) (*models.User, error) {
+	for name, p := range s.providers {
+		verifier, ok := p.(TokenVerifier)
+		if !ok {
+			continue
+		}
+		
+		userInfo, err := verifier.VerifyIDToken(ctx, token)
+		if err != nil {
+			continue
+		}
+		
+		return s.syncProviderUser(ctx, name, userInfo)
+	}
+	
+	return nil, fmt.Errorf("invalid or expired token")
+}
+
+// RefreshProviderToken refreshes tokens at the identity provider. The returned
+// session is not persisted; its access token is the provider's new ID token.
+func (s *service) RefreshProviderToken(ctx context.Context, provider string, refreshToken string) (*models.Session, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("provider %s not found", provider)
+	}
+	
+	verifier, ok := p.(TokenVerifier)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support token refresh", provider)
+	}
+	
+	token, err := p.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("provider %s did not return an ID token", provider)
+	}
+	
+	userInfo, err := verifier.VerifyIDToken(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	
+	user, err := s.syncProviderUser(ctx, provider, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	
+	newRefreshToken := token.RefreshToken
+	if newRefreshToken == "" {
+		// Providers without refresh token rotation keep the original one valid
+		newRefreshToken = refreshToken
+	}
+	
+	return &models.Session{
+		UserID:       user.ID,
+		AccessToken:  rawIDToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    token.Expiry,
+		CreatedAt:    time.Now(),
+		User:         user,
+	}, nil
+}
+
+// syncProviderUser finds or creates the user for verified IdP claims and
+// applies the provider's role mapping
+func (s *service) syncProviderUser(ctx context.Context, provider string, userInfo *UserInfo) (*models.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	
+	user, err := s.findOrCreateUser(ctx, tx, provider, userInfo)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := s.applyRoleMapping(ctx, tx, provider, user, userInfo); err != nil {
+		return nil, err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	
+	return user, nil
+}
+
 func (s *service) ValidateToken(ctx context.Context, token string) (*models.User, error) {
 	var user models.User
 	
@@ -41,6 +41,14 @@ func (m *mockProvider) GetUserInfo(ctx context.Context, token *oauth2.Token) (*U
 	return args.Get(0).(*UserInfo), args.Error(1)
 }
 
+func (m *mockProvider) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oauth2.Token), args.Error(1)
+}
+
 func TestGetAuthURL(t *testing.T) {
 	db, _, _ := sqlmock.New()
 	defer db.Close()
@@ -141,6 +149,71 @@ func TestExchangeCode(t *testing.T) {
 	mockProv.AssertExpectations(t)
 }
 
+func TestExchangeCode_RoleMapping(t *testing.T) {
+	ctx := context.Background()
+	token := &oauth2.Token{AccessToken: "provider-token"}
+
+	tests := []struct {
+		name        string
+		groups      []string
+		defaultRole string
+		want        models.UserRole
+	}{
+		{"mapped group", []string{"netops"}, "", models.RoleOperator},
+		{"default role", []string{"unknown"}, "operator", models.RoleOperator},
+		{"no mapped group", []string{"unknown"}, "", models.RoleViewer},
+		{"no groups", nil, "", models.RoleViewer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, dbMock, _ := sqlmock.New()
+			defer db.Close()
+
+			userInfo := &UserInfo{ID: "123", Email: "test@example.com", Groups: tt.groups}
+			mockProv := new(mockProvider)
+			mockProv.On("ExchangeCode", ctx, "test-code").Return(token, nil)
+			mockProv.On("GetUserInfo", ctx, token).Return(userInfo, nil)
+
+			svc := &service{
+				db: db,
+				config: &config.AuthConfig{
+					TokenExpiration: 24 * time.Hour,
+					Providers: map[string]config.OAuthProvider{
+						"test": {RoleMapping: map[string]string{"netops": "operator"}, DefaultRole: tt.defaultRole},
+					},
+				},
+				providers: map[string]Provider{"test": mockProv},
+			}
+
+			// An admin returning with their groups changed
+			dbMock.ExpectBegin()
+			dbMock.ExpectQuery("SELECT .+ FROM users WHERE provider = \\$1 AND provider_id = \\$2").
+				WithArgs("test", "123").
+				WillReturnRows(sqlmock.NewRows([]string{
+					"id", "email", "name", "picture", "provider", "provider_id", "role", "active",
+					"last_login_at", "created_at", "updated_at",
+				}).AddRow("user-123", "test@example.com", "Test User", "", "test", "123",
+					"admin", true, time.Now(), time.Now(), time.Now()))
+			dbMock.ExpectExec("UPDATE users SET role = \\$1, updated_at = \\$2 WHERE id = \\$3").
+				WithArgs(string(tt.want), sqlmock.AnyArg(), "user-123").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			dbMock.ExpectExec("UPDATE users SET last_login_at = \\$1 WHERE id = \\$2").
+				WithArgs(sqlmock.AnyArg(), "user-123").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			dbMock.ExpectExec("INSERT INTO sessions").
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			dbMock.ExpectCommit()
+
+			session, err := svc.ExchangeCode(ctx, "test", "test-code")
+			assert.NoError(t, err)
+			if assert.NotNil(t, session) {
+				assert.Equal(t, tt.want, session.User.Role)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestValidateToken(t *testing.T) {
 	db, dbMock, _ := sqlmock.New()
 	defer db.Close()
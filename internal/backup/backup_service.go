@@ -7,9 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lspecian/ovncp/internal/metrics"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
 	"go.uber.org/zap"
@@ -21,6 +24,9 @@ type BackupService struct {
 	ovnService services.OVNServiceInterface
 	storage    BackupStorage
 	logger     *zap.Logger
+	events     services.EventPublisher
+	// verification is the DR staging deployment, nil unless configured
+	verification *verification
 }
 
 // NewBackupService creates a new backup service
@@ -32,9 +38,40 @@ func NewBackupService(ovnService services.OVNServiceInterface, storage BackupSto
 	}
 }
 
+// SetEvents publishes an event when a backup or a restore completes or fails
+func (s *BackupService) SetEvents(events services.EventPublisher) {
+	s.events = events
+}
+
 // CreateBackup creates a backup of OVN configuration
 func (s *BackupService) CreateBackup(ctx context.Context, options *BackupOptions) (*BackupMetadata, error) {
 	startTime := time.Now()
+	metadata, err := s.createBackup(ctx, options, startTime)
+	metrics.RecordBackupOperation("backup", err == nil, time.Since(startTime).Seconds())
+	if s.events != nil {
+		s.publishBackup(ctx, options, metadata, err)
+	}
+	return metadata, err
+}
+
+func (s *BackupService) publishBackup(ctx context.Context, options *BackupOptions, metadata *BackupMetadata, err error) {
+	event := &models.Event{
+		Type: models.EventBackupCompleted,
+		Data: map[string]interface{}{
+			"name": options.Name,
+			"type": options.Type,
+		},
+	}
+	if err != nil {
+		event.Type = models.EventBackupFailed
+		event.Data["error"] = err.Error()
+	} else {
+		event.Data["backup_id"] = metadata.ID
+	}
+	s.events.Publish(ctx, event)
+}
+
+func (s *BackupService) createBackup(ctx context.Context, options *BackupOptions, startTime time.Time) (*BackupMetadata, error) {
 	
 	// Set defaults
 	if options.Format == "" {
@@ -60,6 +97,7 @@ func (s *BackupService) CreateBackup(ctx context.Context, options *BackupOptions
 		},
 		Statistics: &BackupStatistics{
 			ObjectCounts: make(map[string]int),
+			PhaseTimings: make(map[string]time.Duration),
 		},
 	}
 
@@ -98,8 +136,20 @@ func (s *BackupService) CreateBackup(ctx context.Context, options *BackupOptions
 	return &backupData.Metadata, nil
 }
 
-// collectFullBackup collects all OVN resources
+// collectWorkers is how many switches or routers a full backup collects
+// the resources of at a time
+const collectWorkers = 8
+
+// collectFullBackup collects all OVN resources. The ports and ACLs of each
+// switch, and the policies of each router, are listed by a pool of
+// workers; the time each phase took is recorded in the statistics.
 func (s *BackupService) collectFullBackup(ctx context.Context, backup *BackupData, options *BackupOptions) error {
+	phase := time.Now()
+	endPhase := func(name string) {
+		backup.Statistics.PhaseTimings[name] = time.Since(phase)
+		phase = time.Now()
+	}
+
 	// Collect logical switches
 	switches, err := s.ovnService.ListLogicalSwitches(ctx)
 	if err != nil {
@@ -115,54 +165,115 @@ func (s *BackupService) collectFullBackup(ctx context.Context, backup *BackupDat
 	}
 	backup.LogicalRouters = routers
 	backup.Statistics.ObjectCounts["routers"] = len(routers)
-
-	// Collect ports for each switch
+	endPhase("switches_and_routers")
+
+	// Collect the ports and ACLs of each switch, kept in switch order
+	ports := make([][]*LogicalPortWithSwitch, len(switches))
+	acls := make([][]*ACLWithSwitch, len(switches))
+	err = s.forEach(ctx, len(switches), func(i int) {
+		ports[i], acls[i] = s.collectSwitchResources(ctx, switches[i])
+	})
+	if err != nil {
+		return err
+	}
 	backup.LogicalPorts = []*LogicalPortWithSwitch{}
-	for _, sw := range switches {
-		ports, err := s.ovnService.ListPorts(ctx, sw.UUID)
-		if err != nil {
-			s.logger.Warn("Failed to list ports for switch",
-				zap.String("switch", sw.Name),
-				zap.Error(err))
-			continue
-		}
-		
-		for _, port := range ports {
-			backup.LogicalPorts = append(backup.LogicalPorts, &LogicalPortWithSwitch{
-				LogicalSwitchPort: port,
-				SwitchID:          sw.UUID,
-				SwitchName:        sw.Name,
-			})
-		}
+	backup.ACLs = []*ACLWithSwitch{}
+	for i := range switches {
+		backup.LogicalPorts = append(backup.LogicalPorts, ports[i]...)
+		backup.ACLs = append(backup.ACLs, acls[i]...)
 	}
 	backup.Statistics.ObjectCounts["ports"] = len(backup.LogicalPorts)
+	backup.Statistics.ObjectCounts["acls"] = len(backup.ACLs)
+	endPhase("ports_and_acls")
 
-	// Collect ACLs for each switch
-	backup.ACLs = []*ACLWithSwitch{}
-	for _, sw := range switches {
-		acls, err := s.ovnService.ListACLs(ctx, sw.UUID)
-		if err != nil {
-			s.logger.Warn("Failed to list ACLs for switch",
-				zap.String("switch", sw.Name),
-				zap.Error(err))
-			continue
-		}
-		
-		for _, acl := range acls {
-			backup.ACLs = append(backup.ACLs, &ACLWithSwitch{
-				ACL:        acl,
-				SwitchID:   sw.UUID,
-				SwitchName: sw.Name,
-			})
-		}
+	// Collect policies for each router
+	policies := make([][]*models.RouterPolicy, len(routers))
+	err = s.forEach(ctx, len(routers), func(i int) {
+		policies[i] = s.listRouterPolicies(ctx, routers[i])
+	})
+	if err != nil {
+		return err
 	}
-	backup.Statistics.ObjectCounts["acls"] = len(backup.ACLs)
+	backup.RouterPolicies = []*models.RouterPolicy{}
+	for i := range routers {
+		backup.RouterPolicies = append(backup.RouterPolicies, policies[i]...)
+	}
+	backup.Statistics.ObjectCounts["router_policies"] = len(backup.RouterPolicies)
+	endPhase("router_policies")
 
 	// TODO: Collect other resources (LoadBalancers, NATs, etc.)
 
 	return nil
 }
 
+// forEach calls fn with 0 to n-1 from collectWorkers goroutines. It stops
+// handing out indexes once ctx is done, returning its error.
+func (s *BackupService) forEach(ctx context.Context, n int, fn func(i int)) error {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < collectWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// collectSwitchResources lists the ports and ACLs of a switch; either is
+// left out, with a warning, when it can't be listed
+func (s *BackupService) collectSwitchResources(ctx context.Context, sw *models.LogicalSwitch) ([]*LogicalPortWithSwitch, []*ACLWithSwitch) {
+	var ports []*LogicalPortWithSwitch
+	switchPorts, err := s.ovnService.ListPorts(ctx, sw.UUID)
+	if err != nil {
+		s.logger.Warn("Failed to list ports for switch",
+			zap.String("switch", sw.Name),
+			zap.Error(err))
+	}
+	for _, port := range switchPorts {
+		ports = append(ports, &LogicalPortWithSwitch{
+			LogicalSwitchPort: port,
+			SwitchID:          sw.UUID,
+			SwitchName:        sw.Name,
+		})
+	}
+
+	var acls []*ACLWithSwitch
+	switchACLs, err := s.ovnService.ListACLs(ctx, sw.UUID)
+	if err != nil {
+		s.logger.Warn("Failed to list ACLs for switch",
+			zap.String("switch", sw.Name),
+			zap.Error(err))
+	}
+	for _, acl := range switchACLs {
+		acls = append(acls, &ACLWithSwitch{
+			ACL:        acl,
+			SwitchID:   sw.UUID,
+			SwitchName: sw.Name,
+		})
+	}
+	return ports, acls
+}
+
 // collectSelectiveBackup collects only specified resources
 func (s *BackupService) collectSelectiveBackup(ctx context.Context, backup *BackupData, options *BackupOptions) error {
 	if options.ResourceFilter == nil {
@@ -239,15 +350,69 @@ func (s *BackupService) collectSelectiveBackup(ctx context.Context, backup *Back
 				continue
 			}
 			backup.LogicalRouters = append(backup.LogicalRouters, router)
+			s.collectRouterPolicies(ctx, backup, router)
 		}
 		backup.Statistics.ObjectCounts["routers"] = len(backup.LogicalRouters)
+		backup.Statistics.ObjectCounts["router_policies"] = len(backup.RouterPolicies)
 	}
 
 	return nil
 }
 
+// collectRouterPolicies adds the policies of a router to the backup
+func (s *BackupService) collectRouterPolicies(ctx context.Context, backup *BackupData, router *models.LogicalRouter) {
+	backup.RouterPolicies = append(backup.RouterPolicies, s.listRouterPolicies(ctx, router)...)
+}
+
+// listRouterPolicies lists the policies of a router, none with a warning
+// when they can't be listed
+func (s *BackupService) listRouterPolicies(ctx context.Context, router *models.LogicalRouter) []*models.RouterPolicy {
+	policies, err := s.ovnService.ListRouterPolicies(ctx, router.UUID)
+	if err != nil {
+		s.logger.Warn("Failed to list policies for router",
+			zap.String("router", router.Name),
+			zap.Error(err))
+		return nil
+	}
+	return policies
+}
+
 // RestoreBackup restores OVN configuration from a backup
 func (s *BackupService) RestoreBackup(ctx context.Context, backupID string, options *RestoreOptions) (*RestoreResult, error) {
+	result, err := s.restoreBackup(ctx, backupID, options)
+	if s.events != nil && !options.DryRun {
+		s.publishRestore(ctx, backupID, result, err)
+	}
+	return result, err
+}
+
+func (s *BackupService) publishRestore(ctx context.Context, backupID string, result *RestoreResult, err error) {
+	event := &models.Event{
+		Type: models.EventRestoreCompleted,
+		Data: map[string]interface{}{
+			"backup_id": backupID,
+		},
+	}
+	switch {
+	case err != nil:
+		event.Type = models.EventRestoreFailed
+		event.Data["error"] = err.Error()
+	case !result.Success || result.ErrorCount > 0:
+		event.Type = models.EventRestoreFailed
+		event.Data["restored"] = result.RestoredCount
+		event.Data["errors"] = result.ErrorCount
+		event.Data["error"] = fmt.Sprintf("%d resources failed to restore", result.ErrorCount)
+		if len(result.Errors) > 0 {
+			event.Data["error"] = strings.Join(result.Errors, "; ")
+		}
+	default:
+		event.Data["restored"] = result.RestoredCount
+		event.Data["skipped"] = result.SkippedCount
+	}
+	s.events.Publish(ctx, event)
+}
+
+func (s *BackupService) restoreBackup(ctx context.Context, backupID string, options *RestoreOptions) (*RestoreResult, error) {
 	startTime := time.Now()
 
 	// Retrieve backup
@@ -256,11 +421,6 @@ func (s *BackupService) RestoreBackup(ctx context.Context, backupID string, opti
 		return nil, fmt.Errorf("failed to retrieve backup: %w", err)
 	}
 
-	result := &RestoreResult{
-		Success: true,
-		Details: make(map[string]RestoreDetail),
-	}
-
 	// Validate backup if required
 	if !options.SkipValidation {
 		if err := s.validateBackup(backupData); err != nil {
@@ -268,36 +428,18 @@ func (s *BackupService) RestoreBackup(ctx context.Context, backupID string, opti
 		}
 	}
 
+	if options.Parallelism < 0 || options.Parallelism > MaxRestoreParallelism {
+		return nil, fmt.Errorf("parallelism must be between 1 and %d", MaxRestoreParallelism)
+	}
+
 	// Dry run mode - just validate and return what would be restored
 	if options.DryRun {
 		return s.dryRunRestore(ctx, backupData, options)
 	}
 
-	// Restore logical switches
-	if err := s.restoreSwitches(ctx, backupData, options, result); err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to restore switches: %v", err))
-	}
-
-	// Restore logical routers
-	if err := s.restoreRouters(ctx, backupData, options, result); err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to restore routers: %v", err))
-	}
-
-	// Restore ports (must be after switches)
-	if err := s.restorePorts(ctx, backupData, options, result); err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to restore ports: %v", err))
-	}
-
-	// Restore ACLs (must be after switches)
-	if err := s.restoreACLs(ctx, backupData, options, result); err != nil {
-		result.Success = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to restore ACLs: %v", err))
-	}
-
+	result := s.replay(ctx, backupData, options)
 	result.ProcessingTime = time.Since(startTime)
+	metrics.RecordBackupOperation("restore", result.Success, result.ProcessingTime.Seconds())
 
 	s.logger.Info("Restore completed",
 		zap.String("backup_id", backupID),
@@ -310,166 +452,6 @@ func (s *BackupService) RestoreBackup(ctx context.Context, backupID string, opti
 	return result, nil
 }
 
-// restoreSwitches restores logical switches
-func (s *BackupService) restoreSwitches(ctx context.Context, backup *BackupData, options *RestoreOptions, result *RestoreResult) error {
-	detail := RestoreDetail{
-		Total: len(backup.LogicalSwitches),
-	}
-
-	for _, sw := range backup.LogicalSwitches {
-		// Check if switch already exists
-		existing, err := s.ovnService.GetLogicalSwitch(ctx, sw.Name)
-		if err == nil && existing != nil {
-			// Handle conflict
-			switch options.ConflictPolicy {
-			case ConflictPolicySkip:
-				detail.Skipped++
-				result.SkippedCount++
-				continue
-			case ConflictPolicyOverwrite:
-				if err := s.ovnService.DeleteLogicalSwitch(ctx, existing.UUID); err != nil {
-					detail.Failed++
-					detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to delete existing switch %s: %v", sw.Name, err))
-					continue
-				}
-			case ConflictPolicyRename:
-				sw.Name = fmt.Sprintf("%s_restored_%d", sw.Name, time.Now().Unix())
-			case ConflictPolicyError:
-				detail.Failed++
-				detail.Errors = append(detail.Errors, fmt.Sprintf("Switch %s already exists", sw.Name))
-				continue
-			}
-		}
-
-		// Create the switch
-		_, err = s.ovnService.CreateLogicalSwitch(ctx, sw)
-		if err != nil {
-			detail.Failed++
-			detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to create switch %s: %v", sw.Name, err))
-			result.ErrorCount++
-		} else {
-			detail.Restored++
-			result.RestoredCount++
-		}
-	}
-
-	result.Details["switches"] = detail
-	return nil
-}
-
-// restoreRouters restores logical routers
-func (s *BackupService) restoreRouters(ctx context.Context, backup *BackupData, options *RestoreOptions, result *RestoreResult) error {
-	detail := RestoreDetail{
-		Total: len(backup.LogicalRouters),
-	}
-
-	for _, router := range backup.LogicalRouters {
-		// Check if router already exists
-		existing, err := s.ovnService.GetLogicalRouter(ctx, router.Name)
-		if err == nil && existing != nil {
-			// Handle conflict
-			switch options.ConflictPolicy {
-			case ConflictPolicySkip:
-				detail.Skipped++
-				result.SkippedCount++
-				continue
-			case ConflictPolicyOverwrite:
-				if err := s.ovnService.DeleteLogicalRouter(ctx, existing.UUID); err != nil {
-					detail.Failed++
-					detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to delete existing router %s: %v", router.Name, err))
-					continue
-				}
-			case ConflictPolicyRename:
-				router.Name = fmt.Sprintf("%s_restored_%d", router.Name, time.Now().Unix())
-			case ConflictPolicyError:
-				detail.Failed++
-				detail.Errors = append(detail.Errors, fmt.Sprintf("Router %s already exists", router.Name))
-				continue
-			}
-		}
-
-		// Create the router
-		_, err = s.ovnService.CreateLogicalRouter(ctx, router)
-		if err != nil {
-			detail.Failed++
-			detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to create router %s: %v", router.Name, err))
-			result.ErrorCount++
-		} else {
-			detail.Restored++
-			result.RestoredCount++
-		}
-	}
-
-	result.Details["routers"] = detail
-	return nil
-}
-
-// restorePorts restores logical switch ports
-func (s *BackupService) restorePorts(ctx context.Context, backup *BackupData, options *RestoreOptions, result *RestoreResult) error {
-	detail := RestoreDetail{
-		Total: len(backup.LogicalPorts),
-	}
-
-	for _, portWithSwitch := range backup.LogicalPorts {
-		port := portWithSwitch.LogicalSwitchPort
-		
-		// Find the switch (it might have been renamed)
-		switchID := portWithSwitch.SwitchID
-		if options.ResourceMapping != nil {
-			if mappedID, ok := options.ResourceMapping[switchID]; ok {
-				switchID = mappedID
-			}
-		}
-
-		// Create the port
-		_, err := s.ovnService.CreatePort(ctx, switchID, port)
-		if err != nil {
-			detail.Failed++
-			detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to create port %s: %v", port.Name, err))
-			result.ErrorCount++
-		} else {
-			detail.Restored++
-			result.RestoredCount++
-		}
-	}
-
-	result.Details["ports"] = detail
-	return nil
-}
-
-// restoreACLs restores ACLs
-func (s *BackupService) restoreACLs(ctx context.Context, backup *BackupData, options *RestoreOptions, result *RestoreResult) error {
-	detail := RestoreDetail{
-		Total: len(backup.ACLs),
-	}
-
-	for _, aclWithSwitch := range backup.ACLs {
-		acl := aclWithSwitch.ACL
-		
-		// Find the switch (it might have been renamed)
-		switchID := aclWithSwitch.SwitchID
-		if options.ResourceMapping != nil {
-			if mappedID, ok := options.ResourceMapping[switchID]; ok {
-				switchID = mappedID
-			}
-		}
-
-		// Create the ACL
-		_, err := s.ovnService.CreateACL(ctx, switchID, acl)
-		if err != nil {
-			detail.Failed++
-			detail.Errors = append(detail.Errors, fmt.Sprintf("Failed to create ACL %s: %v", acl.Name, err))
-			result.ErrorCount++
-		} else {
-			detail.Restored++
-			result.RestoredCount++
-		}
-	}
-
-	result.Details["acls"] = detail
-	return nil
-}
-
 // validateBackup validates backup data integrity
 func (s *BackupService) validateBackup(backup *BackupData) error {
 	// Validate metadata
@@ -533,7 +515,12 @@ func (s *BackupService) dryRunRestore(ctx context.Context, backup *BackupData, o
 	}
 	result.Details["routers"] = detail
 
-	// Ports and ACLs would be restored based on switch availability
+	// Router policies, ports and ACLs would be restored based on router and
+	// switch availability
+	result.Details["router_policies"] = RestoreDetail{
+		Total:    len(backup.RouterPolicies),
+		Restored: len(backup.RouterPolicies), // Assume all would be restored in dry run
+	}
 	result.Details["ports"] = RestoreDetail{
 		Total:    len(backup.LogicalPorts),
 		Restored: len(backup.LogicalPorts), // Assume all would be restored in dry run
@@ -562,6 +549,7 @@ func (s *BackupService) calculateTotalObjects(backup *BackupData) int {
 	total += len(backup.ACLs)
 	total += len(backup.LoadBalancers)
 	total += len(backup.NATs)
+	total += len(backup.RouterPolicies)
 	total += len(backup.DHCPOptions)
 	total += len(backup.QoSRules)
 	total += len(backup.PortGroups)
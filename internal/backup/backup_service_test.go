@@ -2,9 +2,12 @@ package backup
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 	"github.com/lspecian/ovncp/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -24,6 +27,14 @@ func (m *MockOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.Log
 	return args.Get(0).([]*models.LogicalSwitch), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalSwitch), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -61,6 +72,14 @@ func (m *MockOVNService) ListLogicalRouters(ctx context.Context) ([]*models.Logi
 	return args.Get(0).([]*models.LogicalRouter), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalRouter), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -98,6 +117,14 @@ func (m *MockOVNService) ListPorts(ctx context.Context, switchID string) ([]*mod
 	return args.Get(0).([]*models.LogicalSwitchPort), args.Error(1)
 }
 
+func (m *MockOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LogicalSwitchPort), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -135,6 +162,14 @@ func (m *MockOVNService) ListACLs(ctx context.Context, switchID string) ([]*mode
 	return args.Get(0).([]*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.ACL), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -151,6 +186,14 @@ func (m *MockOVNService) CreateACL(ctx context.Context, switchID string, acl *mo
 	return args.Get(0).(*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	args := m.Called(ctx, switchID, acls)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
 func (m *MockOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
 	args := m.Called(ctx, id, acl)
 	if args.Get(0) == nil {
@@ -164,6 +207,350 @@ func (m *MockOVNService) DeleteACL(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.LoadBalancer), args.Int(1), args.Error(2)
+}
+
+func (m *MockOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PortGroup), args.Error(1)
+}
+
+func (m *MockOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AddressSet), args.Error(1)
+}
+
+func (m *MockOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	args := m.Called(ctx, portGroupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
+func (m *MockOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	args := m.Called(ctx, owner, addressSets, portGroups)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ovn.OwnedObjects), args.Error(1)
+}
+
+func (m *MockOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, id, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteDNS(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, id, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteMirror(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	args := m.Called(ctx, appType, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	args := m.Called(ctx, switchID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID, routes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id, route)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	args := m.Called(ctx, routerID, port)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LogicalRouterPort), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	args := m.Called(ctx, portID, binding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	args := m.Called(ctx, priorities)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	args := m.Called(ctx, resource, id, set, remove)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *MockOVNService) ExecuteTransaction(ctx context.Context, ops []services.TransactionOp) error {
 	args := m.Called(ctx, ops)
 	return args.Error(0)
@@ -255,6 +642,10 @@ func TestBackupService_CreateFullBackup(t *testing.T) {
 		{UUID: "acl1", Name: "allow-http"},
 	}
 	
+	policies := []*models.RouterPolicy{
+		{UUID: "pol1", RouterID: "r1", Priority: 100, Match: "ip4.src == 10.0.0.0/24", Action: "reroute", Nexthops: []string{"172.16.0.1"}},
+	}
+	
 	// Setup expectations
 	mockOVN.On("ListLogicalSwitches", ctx).Return(switches, nil)
 	mockOVN.On("ListLogicalRouters", ctx).Return(routers, nil)
@@ -262,8 +653,11 @@ func TestBackupService_CreateFullBackup(t *testing.T) {
 	mockOVN.On("ListPorts", ctx, "sw2").Return([]*models.LogicalSwitchPort{}, nil)
 	mockOVN.On("ListACLs", ctx, "sw1").Return(acls, nil)
 	mockOVN.On("ListACLs", ctx, "sw2").Return([]*models.ACL{}, nil)
+	mockOVN.On("ListRouterPolicies", ctx, "r1").Return(policies, nil)
 	
-	mockStorage.On("Store", mock.Anything, mock.Anything).Return("backup-id", nil)
+	mockStorage.On("Store", mock.MatchedBy(func(backup *BackupData) bool {
+		return len(backup.RouterPolicies) == 1 && backup.Statistics.ObjectCounts["router_policies"] == 1
+	}), mock.Anything).Return("backup-id", nil)
 	
 	// Create backup
 	options := &BackupOptions{
@@ -285,6 +679,75 @@ func TestBackupService_CreateFullBackup(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestBackupService_CreateFullBackupConcurrently(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(mockOVN, mockStorage, zap.NewNop())
+
+	// More switches than workers, each with a port and an ACL
+	var switches []*models.LogicalSwitch
+	for i := 0; i < 3*collectWorkers; i++ {
+		sw := &models.LogicalSwitch{UUID: fmt.Sprintf("sw%d", i), Name: fmt.Sprintf("switch%d", i)}
+		switches = append(switches, sw)
+		if i == 5 {
+			mockOVN.On("ListPorts", ctx, sw.UUID).Return(nil, fmt.Errorf("connection reset"))
+		} else {
+			mockOVN.On("ListPorts", ctx, sw.UUID).Return([]*models.LogicalSwitchPort{{UUID: "p-" + sw.UUID}}, nil)
+		}
+		mockOVN.On("ListACLs", ctx, sw.UUID).Return([]*models.ACL{{UUID: "acl-" + sw.UUID}}, nil)
+	}
+	mockOVN.On("ListLogicalSwitches", ctx).Return(switches, nil)
+	mockOVN.On("ListLogicalRouters", ctx).Return([]*models.LogicalRouter{}, nil)
+
+	var stored *BackupData
+	mockStorage.On("Store", mock.MatchedBy(func(backup *BackupData) bool {
+		stored = backup
+		return true
+	}), mock.Anything).Return("backup-id", nil)
+
+	_, err := service.CreateBackup(ctx, &BackupOptions{Name: "Concurrent", Type: BackupTypeFull})
+	assert.NoError(t, err)
+
+	// Resources keep the order of their switches; a switch whose ports
+	// can't be listed still has its ACLs backed up
+	var portSwitches, aclSwitches []string
+	for _, port := range stored.LogicalPorts {
+		portSwitches = append(portSwitches, port.SwitchID)
+	}
+	for _, acl := range stored.ACLs {
+		aclSwitches = append(aclSwitches, acl.SwitchID)
+	}
+	var want []string
+	for _, sw := range switches {
+		want = append(want, sw.UUID)
+	}
+	assert.Equal(t, want, aclSwitches)
+	assert.Equal(t, append(append([]string{}, want[:5]...), want[6:]...), portSwitches)
+	assert.Equal(t, len(switches)-1, stored.Statistics.ObjectCounts["ports"])
+
+	for _, phase := range []string{"switches_and_routers", "ports_and_acls", "router_policies"} {
+		assert.Contains(t, stored.Statistics.PhaseTimings, phase)
+	}
+}
+
+func TestBackupService_CreateFullBackupCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(mockOVN, mockStorage, zap.NewNop())
+
+	mockOVN.On("ListLogicalSwitches", ctx).Return([]*models.LogicalSwitch{{UUID: "sw1"}, {UUID: "sw2"}}, nil)
+	mockOVN.On("ListLogicalRouters", ctx).Return([]*models.LogicalRouter{}, nil)
+	mockOVN.On("ListPorts", ctx, mock.Anything).Return([]*models.LogicalSwitchPort{}, nil).Maybe()
+	mockOVN.On("ListACLs", ctx, mock.Anything).Return([]*models.ACL{}, nil).Maybe()
+
+	_, err := service.CreateBackup(ctx, &BackupOptions{Name: "Canceled", Type: BackupTypeFull})
+	assert.ErrorIs(t, err, context.Canceled)
+	mockStorage.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
 func TestBackupService_CreateSelectiveBackup(t *testing.T) {
 	ctx := context.Background()
 	
@@ -367,6 +830,9 @@ func TestBackupService_RestoreBackup(t *testing.T) {
 				SwitchID: "sw1",
 			},
 		},
+		RouterPolicies: []*models.RouterPolicy{
+			{UUID: "pol1", RouterID: "r1", Priority: 100, Match: "ip4", Action: "allow"},
+		},
 	}
 	
 	// Setup expectations
@@ -381,6 +847,7 @@ func TestBackupService_RestoreBackup(t *testing.T) {
 	mockOVN.On("CreateLogicalRouter", ctx, mock.Anything).Return(&models.LogicalRouter{}, nil)
 	mockOVN.On("CreatePort", ctx, "sw1", mock.Anything).Return(&models.LogicalSwitchPort{}, nil)
 	mockOVN.On("CreateACL", ctx, "sw1", mock.Anything).Return(&models.ACL{}, nil)
+	mockOVN.On("CreateRouterPolicy", ctx, "r1", mock.Anything).Return(&models.RouterPolicy{}, nil)
 	
 	// Restore backup
 	options := &RestoreOptions{
@@ -394,7 +861,7 @@ func TestBackupService_RestoreBackup(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.True(t, result.Success)
-	assert.Equal(t, 4, result.RestoredCount) // 1 switch + 1 router + 1 port + 1 ACL
+	assert.Equal(t, 5, result.RestoredCount) // 1 switch + 1 router + 1 policy + 1 port + 1 ACL
 	assert.Equal(t, 0, result.SkippedCount)
 	assert.Equal(t, 0, result.ErrorCount)
 	
@@ -506,4 +973,69 @@ func TestBackupService_DryRunRestore(t *testing.T) {
 	// Verify mocks
 	mockOVN.AssertExpectations(t)
 	mockStorage.AssertExpectations(t)
-}
\ No newline at end of file
+}
+func TestTopologySource(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sw := &models.LogicalSwitch{UUID: "sw-1", Name: "switch1"}
+	mockStorage := new(MockBackupStorage)
+	mockStorage.On("Retrieve", "backup-123").Return(&BackupData{
+		Metadata:        BackupMetadata{ID: "backup-123", CreatedAt: createdAt},
+		LogicalSwitches: []*models.LogicalSwitch{sw},
+		LogicalPorts: []*LogicalPortWithSwitch{
+			{LogicalSwitchPort: &models.LogicalSwitchPort{UUID: "port-1"}, SwitchID: "sw-1"},
+		},
+		ACLs: []*ACLWithSwitch{
+			{ACL: &models.ACL{UUID: "acl-1"}, SwitchID: "sw-1"},
+		},
+	}, nil)
+	mockStorage.On("Retrieve", "missing").Return(nil, fmt.Errorf("backup not found: missing"))
+
+	source := TopologySource(mockStorage)
+
+	topology, err := source("backup-123")
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, topology.Timestamp)
+	assert.Equal(t, []*models.LogicalSwitch{sw}, topology.Switches)
+	assert.Len(t, topology.Ports, 1)
+	assert.Equal(t, "acl-1", topology.ACLs[0].UUID)
+
+	_, err = source("missing")
+	assert.Error(t, err)
+}
+
+type recordingPublisher struct {
+	events []*models.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *models.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestBackupService_RestoreEvents(t *testing.T) {
+	ctx := context.Background()
+
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	events := &recordingPublisher{}
+	service := NewBackupService(mockOVN, mockStorage, zap.NewNop())
+	service.SetEvents(events)
+
+	mockStorage.On("Retrieve", "backup-ok").Return(&BackupData{Metadata: BackupMetadata{ID: "backup-ok"}}, nil)
+	mockStorage.On("Retrieve", "backup-missing").Return(nil, fmt.Errorf("backup not found"))
+
+	_, err := service.RestoreBackup(ctx, "backup-ok", &RestoreOptions{SkipValidation: true, DryRun: true})
+	assert.NoError(t, err)
+	assert.Empty(t, events.events, "dry runs restore nothing")
+
+	_, err = service.RestoreBackup(ctx, "backup-ok", &RestoreOptions{SkipValidation: true})
+	assert.NoError(t, err)
+	_, err = service.RestoreBackup(ctx, "backup-missing", &RestoreOptions{})
+	assert.Error(t, err)
+
+	if assert.Len(t, events.events, 2) {
+		assert.Equal(t, models.EventRestoreCompleted, events.events[0].Type)
+		assert.Equal(t, "backup-ok", events.events[0].Data["backup_id"])
+		assert.Equal(t, models.EventRestoreFailed, events.events[1].Type)
+		assert.Contains(t, events.events[1].Data["error"], "backup not found")
+	}
+}
@@ -0,0 +1,174 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// DiffLive stands for the live OVN configuration in a backup diff
+const DiffLive = "live"
+
+// BackupDiff lists the objects added, removed and modified between two
+// backups, or a backup and the live configuration
+type BackupDiff struct {
+	From     DiffSide       `json:"from"`
+	To       DiffSide       `json:"to"`
+	Added    []DiffObject   `json:"added"`
+	Removed  []DiffObject   `json:"removed"`
+	Modified []ObjectChange `json:"modified"`
+	Summary  map[string]int `json:"summary"`
+}
+
+// DiffSide identifies one side of a diff
+type DiffSide struct {
+	Source string    `json:"source"` // backup or live
+	ID     string    `json:"id,omitempty"`
+	Name   string    `json:"name,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// DiffObject is a switch, router, port, ACL or router policy
+type DiffObject struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ObjectChange is an object present on both sides whose fields differ
+type ObjectChange struct {
+	DiffObject
+	Fields []services.FieldChange `json:"fields"`
+}
+
+// diffObject is an object of a backup with the resource it was recorded as
+type diffObject struct {
+	DiffObject
+	resource interface{}
+}
+
+// DiffBackups compares two backups, either of which may be DiffLive.
+// Objects are matched by UUID; an object was modified when any of its
+// fields but its volatile ones differ, ports and ACLs moving to another
+// switch included.
+func (s *BackupService) DiffBackups(ctx context.Context, from, to string) (*BackupDiff, error) {
+	before, fromSide, err := s.diffSide(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, toSide, err := s.diffSide(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &BackupDiff{
+		From:     fromSide,
+		To:       toSide,
+		Added:    []DiffObject{},
+		Removed:  []DiffObject{},
+		Modified: []ObjectChange{},
+	}
+	beforeObjects, afterObjects := before.diffObjects(), after.diffObjects()
+	for key, object := range afterObjects {
+		old, ok := beforeObjects[key]
+		if !ok {
+			diff.Added = append(diff.Added, object.DiffObject)
+			continue
+		}
+		if fields := services.DiffFields(old.resource, object.resource); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, ObjectChange{DiffObject: object.DiffObject, Fields: fields})
+		}
+	}
+	for key, object := range beforeObjects {
+		if _, ok := afterObjects[key]; !ok {
+			diff.Removed = append(diff.Removed, object.DiffObject)
+		}
+	}
+
+	sortDiffObjects(diff.Added)
+	sortDiffObjects(diff.Removed)
+	sort.Slice(diff.Modified, func(i, j int) bool {
+		return diffObjectLess(diff.Modified[i].DiffObject, diff.Modified[j].DiffObject)
+	})
+	diff.Summary = map[string]int{
+		"added":    len(diff.Added),
+		"removed":  len(diff.Removed),
+		"modified": len(diff.Modified),
+	}
+	return diff, nil
+}
+
+// diffSide returns the backup with an ID, or the live configuration
+// collected as a full backup would
+func (s *BackupService) diffSide(ctx context.Context, id string) (*BackupData, DiffSide, error) {
+	if id == DiffLive {
+		live := &BackupData{
+			Statistics: &BackupStatistics{
+				ObjectCounts: make(map[string]int),
+				PhaseTimings: make(map[string]time.Duration),
+			},
+		}
+		now := time.Now()
+		if err := s.collectFullBackup(ctx, live, &BackupOptions{Type: BackupTypeFull}); err != nil {
+			return nil, DiffSide{}, fmt.Errorf("failed to read the live configuration: %w", err)
+		}
+		return live, DiffSide{Source: DiffLive, Time: now}, nil
+	}
+
+	backup, err := s.storage.Retrieve(id)
+	if err != nil {
+		return nil, DiffSide{}, err
+	}
+	return backup, DiffSide{
+		Source: "backup",
+		ID:     id,
+		Name:   backup.Metadata.Name,
+		Time:   backup.Metadata.CreatedAt,
+	}, nil
+}
+
+// diffObjects indexes the objects of a backup by type and UUID
+func (b *BackupData) diffObjects() map[DiffObject]diffObject {
+	objects := make(map[DiffObject]diffObject)
+	add := func(objectType, id, name string, resource interface{}) {
+		object := DiffObject{Type: objectType, ID: id, Name: name}
+		objects[DiffObject{Type: objectType, ID: id}] = diffObject{object, resource}
+	}
+	for _, sw := range b.LogicalSwitches {
+		add("switch", sw.UUID, sw.Name, sw)
+	}
+	for _, router := range b.LogicalRouters {
+		add("router", router.UUID, router.Name, router)
+	}
+	for _, port := range b.LogicalPorts {
+		if port.LogicalSwitchPort != nil {
+			add("port", port.UUID, port.Name, port)
+		}
+	}
+	for _, acl := range b.ACLs {
+		if acl.ACL != nil {
+			add("acl", acl.UUID, acl.Name, acl)
+		}
+	}
+	for _, policy := range b.RouterPolicies {
+		add("router_policy", policy.UUID, policy.Match, policy)
+	}
+	return objects
+}
+
+func diffObjectLess(a, b DiffObject) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ID < b.ID
+}
+
+func sortDiffObjects(objects []DiffObject) {
+	sort.Slice(objects, func(i, j int) bool { return diffObjectLess(objects[i], objects[j]) })
+}
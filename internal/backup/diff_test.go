@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func diffBackupData(id string) *BackupData {
+	return &BackupData{
+		Metadata:        BackupMetadata{ID: id, Name: "backup " + id},
+		LogicalSwitches: []*models.LogicalSwitch{{UUID: "sw1", Name: "web"}, {UUID: "sw2", Name: "db"}},
+		LogicalRouters:  []*models.LogicalRouter{{UUID: "r1", Name: "edge"}},
+		LogicalPorts: []*LogicalPortWithSwitch{{
+			LogicalSwitchPort: &models.LogicalSwitchPort{UUID: "p1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.11"}},
+			SwitchID:          "sw1",
+			SwitchName:        "web",
+		}},
+		ACLs: []*ACLWithSwitch{{
+			ACL:        &models.ACL{UUID: "acl1", Name: "allow-http", Priority: 1000, Action: "allow"},
+			SwitchID:   "sw1",
+			SwitchName: "web",
+		}},
+	}
+}
+
+func TestBackupService_DiffBackups(t *testing.T) {
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(new(MockOVNService), mockStorage, zap.NewNop())
+
+	// Between the backups, a switch was removed and a router policy added,
+	// the port's address changed and the ACL moved to another switch
+	before, after := diffBackupData("a"), diffBackupData("b")
+	after.LogicalSwitches = after.LogicalSwitches[:1]
+	after.LogicalPorts[0].Addresses = []string{"00:00:00:00:00:01 10.0.0.12"}
+	after.ACLs[0].SwitchID = "sw3"
+	after.RouterPolicies = []*models.RouterPolicy{{UUID: "pol1", RouterID: "r1", Match: "ip4.src == 10.0.0.0/24"}}
+	mockStorage.On("Retrieve", "a").Return(before, nil)
+	mockStorage.On("Retrieve", "b").Return(after, nil)
+
+	diff, err := service.DiffBackups(context.Background(), "a", "b")
+	require.NoError(t, err)
+
+	assert.Equal(t, DiffSide{Source: "backup", ID: "b", Name: "backup b"}, diff.To)
+	assert.Equal(t, []DiffObject{{Type: "router_policy", ID: "pol1", Name: "ip4.src == 10.0.0.0/24"}}, diff.Added)
+	assert.Equal(t, []DiffObject{{Type: "switch", ID: "sw2", Name: "db"}}, diff.Removed)
+	assert.Equal(t, []ObjectChange{
+		{
+			DiffObject: DiffObject{Type: "acl", ID: "acl1", Name: "allow-http"},
+			Fields:     []services.FieldChange{{Field: "switch_id", Before: "sw1", After: "sw3"}},
+		},
+		{
+			DiffObject: DiffObject{Type: "port", ID: "p1", Name: "web-1"},
+			Fields: []services.FieldChange{{
+				Field:  "addresses",
+				Before: []interface{}{"00:00:00:00:00:01 10.0.0.11"},
+				After:  []interface{}{"00:00:00:00:00:01 10.0.0.12"},
+			}},
+		},
+	}, diff.Modified)
+	assert.Equal(t, map[string]int{"added": 1, "removed": 1, "modified": 2}, diff.Summary)
+
+	// A backup doesn't differ from itself
+	diff, err = service.DiffBackups(context.Background(), "a", "a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"added": 0, "removed": 0, "modified": 0}, diff.Summary)
+}
+
+func TestBackupService_DiffBackupWithLive(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(mockOVN, mockStorage, zap.NewNop())
+
+	backupData := diffBackupData("a")
+	mockStorage.On("Retrieve", "a").Return(backupData, nil)
+	mockStorage.On("Retrieve", "missing").Return(nil, fmt.Errorf("backup not found: missing"))
+
+	// The port is gone; the ACL now reads as up to date, which isn't a change
+	mockOVN.On("ListLogicalSwitches", ctx).Return(backupData.LogicalSwitches, nil)
+	mockOVN.On("ListLogicalRouters", ctx).Return(backupData.LogicalRouters, nil)
+	mockOVN.On("ListPorts", ctx, "sw1").Return([]*models.LogicalSwitchPort{}, nil)
+	mockOVN.On("ListPorts", ctx, "sw2").Return([]*models.LogicalSwitchPort{}, nil)
+	acl := *backupData.ACLs[0].ACL
+	acl.ExternalIDs = map[string]string{models.UpdatedByKey: "admin"}
+	mockOVN.On("ListACLs", ctx, "sw1").Return([]*models.ACL{&acl}, nil)
+	mockOVN.On("ListACLs", ctx, "sw2").Return([]*models.ACL{}, nil)
+	mockOVN.On("ListRouterPolicies", ctx, "r1").Return([]*models.RouterPolicy{}, nil)
+
+	diff, err := service.DiffBackups(ctx, "a", DiffLive)
+	require.NoError(t, err)
+	assert.Equal(t, DiffLive, diff.To.Source)
+	assert.Equal(t, []DiffObject{{Type: "port", ID: "p1", Name: "web-1"}}, diff.Removed)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Modified)
+
+	_, err = service.DiffBackups(ctx, "a", "missing")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,605 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// DriftReport compares the live configuration of a tenant, or the whole
+// cluster, with its golden backup. Added objects exist live but not in the
+// golden backup; the changes of modified objects go from golden to live.
+type DriftReport struct {
+	TenantID  string         `json:"tenant_id,omitempty"`
+	Golden    DiffSide       `json:"golden"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Drifted   bool           `json:"drifted"`
+	Added     []DiffObject   `json:"added"`
+	Removed   []DiffObject   `json:"removed"`
+	Modified  []ObjectChange `json:"modified"`
+	Summary   map[string]int `json:"summary"`
+}
+
+// ReconcilePlan lists the steps bringing the live configuration of a scope
+// back to its golden backup
+type ReconcilePlan struct {
+	TenantID  string          `json:"tenant_id,omitempty"`
+	Golden    DiffSide        `json:"golden"`
+	CreatedAt time.Time       `json:"created_at"`
+	Steps     []ReconcileStep `json:"steps"`
+	Summary   map[string]int  `json:"summary"`
+}
+
+// ReconcileStep creates, updates or deletes an object. Created objects are
+// identified by their golden UUID, the others by their live one; the field
+// changes of updates go from live to golden.
+type ReconcileStep struct {
+	Action string `json:"action"` // create, update or delete
+	DiffObject
+	Parent string                 `json:"parent,omitempty"` // Switch or router name of ports, ACLs and policies
+	Fields []services.FieldChange `json:"fields,omitempty"`
+
+	resource interface{} // What's created or updated
+}
+
+// Reconcile step actions
+const (
+	ReconcileCreate = "create"
+	ReconcileUpdate = "update"
+	ReconcileDelete = "delete"
+)
+
+// ReconcileResult is the outcome of applying a reconcile plan, with the
+// drift left afterwards
+type ReconcileResult struct {
+	Plan    *ReconcilePlan `json:"plan"`
+	Applied int            `json:"applied"`
+	Failed  int            `json:"failed"`
+	Errors  []string       `json:"errors,omitempty"`
+	Drift   *DriftReport   `json:"drift,omitempty"`
+	Success bool           `json:"success"`
+}
+
+// driftObject is an object of a scope, keyed by what identifies it across
+// backups and restores rather than its UUID
+type driftObject struct {
+	DiffObject
+	parent   string
+	resource interface{}
+}
+
+// DriftDetector compares the live configuration with golden backups, on
+// demand and every interval, publishing an event when unmanaged changes
+// appear or are undone
+type DriftDetector struct {
+	backups  *BackupService
+	golden   GoldenStore
+	interval time.Duration
+	events   services.EventPublisher
+	logger   *zap.Logger
+
+	mu sync.Mutex
+	// alerted holds the drifted objects of each scope as of the last event
+	alerted map[string]map[string]bool
+}
+
+// NewDriftDetector creates a detector comparing what backups reads live
+// with the golden backups of golden
+func NewDriftDetector(backups *BackupService, golden GoldenStore, interval time.Duration, logger *zap.Logger) *DriftDetector {
+	return &DriftDetector{
+		backups:  backups,
+		golden:   golden,
+		interval: interval,
+		logger:   logger,
+		alerted:  make(map[string]map[string]bool),
+	}
+}
+
+// SetEvents publishes drift.detected when objects drift from a golden
+// backup, and drift.resolved when a scope matches it again
+func (d *DriftDetector) SetEvents(events services.EventPublisher) {
+	d.events = events
+}
+
+// MarkGolden marks a backup as the golden configuration of a tenant, or of
+// the cluster when tenantID is empty
+func (d *DriftDetector) MarkGolden(tenantID, backupID, user string) (*GoldenConfig, error) {
+	backup, err := d.backups.GetBackup(backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	golden := &GoldenConfig{
+		TenantID:   tenantID,
+		BackupID:   backup.ID,
+		BackupName: backup.Name,
+		MarkedBy:   user,
+		MarkedAt:   time.Now().UTC(),
+	}
+	if err := d.golden.Save(golden); err != nil {
+		return nil, err
+	}
+
+	// Drift from the previous golden backup no longer applies
+	d.mu.Lock()
+	delete(d.alerted, tenantID)
+	d.mu.Unlock()
+
+	d.logger.Info("Golden backup marked",
+		zap.String("tenant_id", tenantID),
+		zap.String("backup_id", backupID),
+		zap.String("user", user))
+	return golden, nil
+}
+
+// UnmarkGolden stops checking the drift of a scope
+func (d *DriftDetector) UnmarkGolden(tenantID string) error {
+	if err := d.golden.Delete(tenantID); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	delete(d.alerted, tenantID)
+	d.mu.Unlock()
+	return nil
+}
+
+// GoldenConfigs returns the golden backups, the cluster's first
+func (d *DriftDetector) GoldenConfigs() ([]*GoldenConfig, error) {
+	return d.golden.List()
+}
+
+// Latest returns the report of the last check of each scope. Reports are
+// kept in the golden store, so replicas sharing it return those of the
+// leader's checks.
+func (d *DriftDetector) Latest() ([]*DriftReport, error) {
+	return d.golden.Reports()
+}
+
+// Run checks every scope every interval until ctx is done
+func (d *DriftDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.CheckAll(ctx); err != nil {
+			d.logger.Error("Failed to check configuration drift", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every scope with a golden backup, reading the live
+// configuration once
+func (d *DriftDetector) CheckAll(ctx context.Context) ([]*DriftReport, error) {
+	goldens, err := d.golden.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(goldens) == 0 {
+		return []*DriftReport{}, nil
+	}
+
+	live, _, err := d.backups.diffSide(ctx, DiffLive)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]*DriftReport, 0, len(goldens))
+	for _, golden := range goldens {
+		report, err := d.check(ctx, golden, live)
+		if err != nil {
+			d.logger.Error("Failed to check configuration drift",
+				zap.String("tenant_id", golden.TenantID),
+				zap.Error(err))
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Check compares the live configuration of a scope with its golden backup
+func (d *DriftDetector) Check(ctx context.Context, tenantID string) (*DriftReport, error) {
+	golden, err := d.golden.Get(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	live, _, err := d.backups.diffSide(ctx, DiffLive)
+	if err != nil {
+		return nil, err
+	}
+	return d.check(ctx, golden, live)
+}
+
+func (d *DriftDetector) check(ctx context.Context, golden *GoldenConfig, live *BackupData) (*DriftReport, error) {
+	backup, side, err := d.backups.diffSide(ctx, golden.BackupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden backup %s: %w", golden.BackupID, err)
+	}
+
+	report := &DriftReport{
+		TenantID:  golden.TenantID,
+		Golden:    side,
+		CheckedAt: time.Now().UTC(),
+		Added:     []DiffObject{},
+		Removed:   []DiffObject{},
+		Modified:  []ObjectChange{},
+	}
+	goldenObjects, liveObjects := backup.driftObjects(golden.TenantID), live.driftObjects(golden.TenantID)
+	drifted := make(map[string]bool)
+	for key, object := range liveObjects {
+		expected, ok := goldenObjects[key]
+		if !ok {
+			report.Added = append(report.Added, object.DiffObject)
+			drifted[key] = true
+			continue
+		}
+		if fields := driftFields(expected.resource, object.resource); len(fields) > 0 {
+			report.Modified = append(report.Modified, ObjectChange{DiffObject: object.DiffObject, Fields: fields})
+			drifted[key] = true
+		}
+	}
+	for key, object := range goldenObjects {
+		if _, ok := liveObjects[key]; !ok {
+			report.Removed = append(report.Removed, object.DiffObject)
+			drifted[key] = true
+		}
+	}
+
+	sortDiffObjects(report.Added)
+	sortDiffObjects(report.Removed)
+	sort.Slice(report.Modified, func(i, j int) bool {
+		return diffObjectLess(report.Modified[i].DiffObject, report.Modified[j].DiffObject)
+	})
+	report.Drifted = len(drifted) > 0
+	report.Summary = map[string]int{
+		"added":    len(report.Added),
+		"removed":  len(report.Removed),
+		"modified": len(report.Modified),
+	}
+
+	if err := d.golden.SaveReport(report); err != nil {
+		d.logger.Warn("Failed to save drift report",
+			zap.String("tenant_id", golden.TenantID), zap.Error(err))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.alert(ctx, report, drifted)
+	return report, nil
+}
+
+// alert publishes drift.detected when objects drifted since the last event,
+// and drift.resolved when none drift anymore
+func (d *DriftDetector) alert(ctx context.Context, report *DriftReport, drifted map[string]bool) {
+	previous := d.alerted[report.TenantID]
+	d.alerted[report.TenantID] = drifted
+	if d.events == nil {
+		return
+	}
+
+	if !report.Drifted {
+		if len(previous) > 0 {
+			d.events.Publish(ctx, &models.Event{
+				Type:     models.EventDriftResolved,
+				TenantID: report.TenantID,
+				Data:     map[string]interface{}{"golden_backup_id": report.Golden.ID},
+			})
+		}
+		return
+	}
+	for key := range drifted {
+		if !previous[key] {
+			d.events.Publish(ctx, &models.Event{
+				Type:     models.EventDriftDetected,
+				TenantID: report.TenantID,
+				Data: map[string]interface{}{
+					"golden_backup_id": report.Golden.ID,
+					"added":            len(report.Added),
+					"removed":          len(report.Removed),
+					"modified":         len(report.Modified),
+					"objects":          driftedObjects(report),
+				},
+			})
+			return
+		}
+	}
+}
+
+// driftedObjects lists the objects of a report as "type name"
+func driftedObjects(report *DriftReport) []string {
+	objects := make([]string, 0, len(report.Added)+len(report.Removed)+len(report.Modified))
+	for _, object := range report.Added {
+		objects = append(objects, object.Type+" "+object.Name)
+	}
+	for _, object := range report.Removed {
+		objects = append(objects, object.Type+" "+object.Name)
+	}
+	for _, change := range report.Modified {
+		objects = append(objects, change.Type+" "+change.Name)
+	}
+	return objects
+}
+
+// Plan returns the steps reconciling the live configuration of a scope
+// with its golden backup: objects added since are deleted, removed ones
+// created again and modified ones updated. Ports, ACLs and policies are
+// deleted before their switch or router and created after it.
+func (d *DriftDetector) Plan(ctx context.Context, tenantID string) (*ReconcilePlan, error) {
+	golden, err := d.golden.Get(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	backup, side, err := d.backups.diffSide(ctx, golden.BackupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden backup %s: %w", golden.BackupID, err)
+	}
+	live, _, err := d.backups.diffSide(ctx, DiffLive)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ReconcilePlan{
+		TenantID:  tenantID,
+		Golden:    side,
+		CreatedAt: time.Now().UTC(),
+		Steps:     []ReconcileStep{},
+	}
+	goldenObjects, liveObjects := backup.driftObjects(tenantID), live.driftObjects(tenantID)
+	var deletes, creates, updates []ReconcileStep
+	for key, object := range liveObjects {
+		expected, ok := goldenObjects[key]
+		if !ok {
+			deletes = append(deletes, ReconcileStep{Action: ReconcileDelete, DiffObject: object.DiffObject, Parent: object.parent})
+			continue
+		}
+		if fields := driftFields(object.resource, expected.resource); len(fields) > 0 {
+			updates = append(updates, ReconcileStep{
+				Action:     ReconcileUpdate,
+				DiffObject: object.DiffObject,
+				Parent:     object.parent,
+				Fields:     fields,
+				resource:   expected.resource,
+			})
+		}
+	}
+	for key, object := range goldenObjects {
+		if _, ok := liveObjects[key]; !ok {
+			creates = append(creates, ReconcileStep{
+				Action:     ReconcileCreate,
+				DiffObject: object.DiffObject,
+				Parent:     object.parent,
+				resource:   object.resource,
+			})
+		}
+	}
+
+	sortReconcileSteps(deletes, true)
+	sortReconcileSteps(creates, false)
+	sortReconcileSteps(updates, false)
+	plan.Steps = append(plan.Steps, deletes...)
+	plan.Steps = append(plan.Steps, creates...)
+	plan.Steps = append(plan.Steps, updates...)
+	plan.Summary = map[string]int{
+		ReconcileCreate: len(creates),
+		ReconcileUpdate: len(updates),
+		ReconcileDelete: len(deletes),
+	}
+	return plan, nil
+}
+
+// Reconcile applies the plan of a scope, then checks its drift again. A
+// failed step doesn't stop the others.
+func (d *DriftDetector) Reconcile(ctx context.Context, tenantID string) (*ReconcileResult, error) {
+	plan, err := d.Plan(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{Plan: plan}
+	for _, step := range plan.Steps {
+		if err := d.apply(ctx, step); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to %s %s %s: %v", step.Action, step.Type, step.Name, err))
+			continue
+		}
+		result.Applied++
+	}
+	result.Success = result.Failed == 0
+
+	d.logger.Info("Configuration reconciled with golden backup",
+		zap.String("tenant_id", tenantID),
+		zap.String("backup_id", plan.Golden.ID),
+		zap.Int("applied", result.Applied),
+		zap.Int("failed", result.Failed))
+
+	if len(plan.Steps) > 0 {
+		if result.Drift, err = d.Check(ctx, tenantID); err != nil {
+			d.logger.Error("Failed to check configuration drift after reconciling",
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+		}
+	}
+	return result, nil
+}
+
+// apply carries out a reconcile step
+func (d *DriftDetector) apply(ctx context.Context, step ReconcileStep) error {
+	ovn := d.backups.ovnService
+	switch step.Action {
+	case ReconcileDelete:
+		switch step.Type {
+		case "switch":
+			return ovn.DeleteLogicalSwitch(ctx, step.ID)
+		case "router":
+			return ovn.DeleteLogicalRouter(ctx, step.ID)
+		case "port":
+			return ovn.DeletePort(ctx, step.ID)
+		case "acl":
+			return ovn.DeleteACL(ctx, step.ID)
+		case "router_policy":
+			return ovn.DeleteRouterPolicy(ctx, step.ID)
+		}
+
+	case ReconcileCreate:
+		switch resource := step.resource.(type) {
+		case *models.LogicalSwitch:
+			sw := *resource
+			sw.UUID = ""
+			_, err := ovn.CreateLogicalSwitch(ctx, &sw)
+			return err
+		case *models.LogicalRouter:
+			router := *resource
+			router.UUID = ""
+			_, err := ovn.CreateLogicalRouter(ctx, &router)
+			return err
+		case *LogicalPortWithSwitch:
+			parent, err := ovn.GetLogicalSwitch(ctx, step.Parent)
+			if err != nil {
+				return fmt.Errorf("switch %s: %w", step.Parent, err)
+			}
+			port := *resource.LogicalSwitchPort
+			port.UUID = ""
+			_, err = ovn.CreatePort(ctx, parent.UUID, &port)
+			return err
+		case *ACLWithSwitch:
+			parent, err := ovn.GetLogicalSwitch(ctx, step.Parent)
+			if err != nil {
+				return fmt.Errorf("switch %s: %w", step.Parent, err)
+			}
+			acl := *resource.ACL
+			acl.UUID = ""
+			_, err = ovn.CreateACL(ctx, parent.UUID, &acl)
+			return err
+		case *models.RouterPolicy:
+			parent, err := ovn.GetLogicalRouter(ctx, step.Parent)
+			if err != nil {
+				return fmt.Errorf("router %s: %w", step.Parent, err)
+			}
+			policy := *resource
+			policy.UUID = ""
+			policy.RouterID = parent.UUID
+			_, err = ovn.CreateRouterPolicy(ctx, parent.UUID, &policy)
+			return err
+		}
+
+	case ReconcileUpdate:
+		switch resource := step.resource.(type) {
+		case *models.LogicalSwitch:
+			sw := *resource
+			sw.UUID = step.ID
+			_, err := ovn.UpdateLogicalSwitch(ctx, step.ID, &sw)
+			return err
+		case *models.LogicalRouter:
+			router := *resource
+			router.UUID = step.ID
+			_, err := ovn.UpdateLogicalRouter(ctx, step.ID, &router)
+			return err
+		case *LogicalPortWithSwitch:
+			port := *resource.LogicalSwitchPort
+			port.UUID = step.ID
+			_, err := ovn.UpdatePort(ctx, step.ID, &port)
+			return err
+		case *ACLWithSwitch:
+			acl := *resource.ACL
+			acl.UUID = step.ID
+			_, err := ovn.UpdateACL(ctx, step.ID, &acl)
+			return err
+		case *models.RouterPolicy:
+			policy := *resource
+			policy.UUID = step.ID
+			_, err := ovn.UpdateRouterPolicy(ctx, step.ID, &policy)
+			return err
+		}
+	}
+	return errors.New("unsupported step")
+}
+
+// driftObjects indexes the switches and routers of a backup belonging to a
+// tenant, all of them when tenantID is empty, and their ports, ACLs and
+// policies. Objects are keyed by type and name, ports and ACLs within
+// their switch and policies within their router, since UUIDs change when
+// objects are deleted and created again.
+func (b *BackupData) driftObjects(tenantID string) map[string]*driftObject {
+	owned := func(externalIDs map[string]string) bool {
+		return tenantID == "" || externalIDs[models.TenantIDKey] == tenantID
+	}
+	objects := make(map[string]*driftObject)
+	add := func(key, objectType, id, name, parent string, resource interface{}) {
+		objects[objectType+"/"+key] = &driftObject{
+			DiffObject: DiffObject{Type: objectType, ID: id, Name: name},
+			parent:     parent,
+			resource:   resource,
+		}
+	}
+
+	switchNames := make(map[string]string)
+	for _, sw := range b.LogicalSwitches {
+		if owned(sw.ExternalIDs) {
+			switchNames[sw.UUID] = sw.Name
+			add(sw.Name, "switch", sw.UUID, sw.Name, "", sw)
+		}
+	}
+	routerNames := make(map[string]string)
+	for _, router := range b.LogicalRouters {
+		if owned(router.ExternalIDs) {
+			routerNames[router.UUID] = router.Name
+			add(router.Name, "router", router.UUID, router.Name, "", router)
+		}
+	}
+	for _, port := range b.LogicalPorts {
+		if sw, ok := switchNames[port.SwitchID]; ok && port.LogicalSwitchPort != nil {
+			add(sw+"/"+port.Name, "port", port.UUID, port.Name, sw, port)
+		}
+	}
+	for _, acl := range b.ACLs {
+		if sw, ok := switchNames[acl.SwitchID]; ok && acl.ACL != nil {
+			name := acl.Name
+			if name == "" {
+				name = acl.Match
+			}
+			add(sw+"/"+acl.Direction+"/"+strconv.Itoa(acl.Priority)+"/"+acl.Match, "acl", acl.UUID, name, sw, acl)
+		}
+	}
+	for _, policy := range b.RouterPolicies {
+		if router, ok := routerNames[policy.RouterID]; ok {
+			add(router+"/"+strconv.Itoa(policy.Priority)+"/"+policy.Match, "router_policy", policy.UUID, policy.Match, router, policy)
+		}
+	}
+	return objects
+}
+
+// driftFields returns the configuration fields differing between two
+// versions of an object, leaving out what differs between a backup and
+// its restore
+func driftFields(before, after interface{}) []services.FieldChange {
+	return services.DiffFieldValues(
+		configFields(services.ResourceFields(before)),
+		configFields(services.ResourceFields(after)))
+}
+
+// reconcileKinds orders the object types of reconcile steps, parents first
+var reconcileKinds = map[string]int{"switch": 0, "router": 0, "port": 1, "acl": 1, "router_policy": 1}
+
+// sortReconcileSteps sorts steps parents first, or children first
+func sortReconcileSteps(steps []ReconcileStep, childrenFirst bool) {
+	sort.Slice(steps, func(i, j int) bool {
+		a, b := reconcileKinds[steps[i].Type], reconcileKinds[steps[j].Type]
+		if a != b {
+			return (a < b) != childrenFirst
+		}
+		return diffObjectLess(steps[i].DiffObject, steps[j].DiffObject)
+	})
+}
@@ -0,0 +1,263 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// driftConfiguration is a switch with a port and an ACL and a router with
+// a policy belonging to tenant acme, and a switch of another tenant
+func driftConfiguration() *BackupData {
+	acme := map[string]string{models.TenantIDKey: "acme"}
+	return &BackupData{
+		Metadata: BackupMetadata{ID: "golden", Name: "baseline"},
+		LogicalSwitches: []*models.LogicalSwitch{
+			{UUID: "sw1", Name: "web", OtherConfig: map[string]string{"mcast_snoop": "true"}, ExternalIDs: acme},
+			{UUID: "sw2", Name: "db", ExternalIDs: map[string]string{models.TenantIDKey: "other"}},
+		},
+		LogicalRouters: []*models.LogicalRouter{{UUID: "r1", Name: "edge", ExternalIDs: acme}},
+		LogicalPorts: []*LogicalPortWithSwitch{{
+			LogicalSwitchPort: &models.LogicalSwitchPort{UUID: "p1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.11"}},
+			SwitchID:          "sw1",
+			SwitchName:        "web",
+		}},
+		ACLs: []*ACLWithSwitch{{
+			ACL:        &models.ACL{UUID: "a1", Name: "allow-http", Priority: 1000, Direction: "to-lport", Match: "tcp.dst == 80", Action: "allow"},
+			SwitchID:   "sw1",
+			SwitchName: "web",
+		}},
+		RouterPolicies: []*models.RouterPolicy{{UUID: "pol1", RouterID: "r1", Priority: 100, Match: "ip4.src == 10.0.0.0/24", Action: "allow"}},
+	}
+}
+
+// serveLive makes ovn list the switches, routers, ports, ACLs and policies
+// of live
+func serveLive(ctx context.Context, ovn *MockOVNService, live *BackupData) {
+	ovn.ExpectedCalls = nil
+	ovn.On("ListLogicalSwitches", ctx).Return(live.LogicalSwitches, nil)
+	ovn.On("ListLogicalRouters", ctx).Return(live.LogicalRouters, nil)
+	for _, sw := range live.LogicalSwitches {
+		ports, acls := []*models.LogicalSwitchPort{}, []*models.ACL{}
+		for _, port := range live.LogicalPorts {
+			if port.SwitchID == sw.UUID {
+				ports = append(ports, port.LogicalSwitchPort)
+			}
+		}
+		for _, acl := range live.ACLs {
+			if acl.SwitchID == sw.UUID {
+				acls = append(acls, acl.ACL)
+			}
+		}
+		ovn.On("ListPorts", ctx, sw.UUID).Return(ports, nil)
+		ovn.On("ListACLs", ctx, sw.UUID).Return(acls, nil)
+	}
+	for _, router := range live.LogicalRouters {
+		policies := []*models.RouterPolicy{}
+		for _, policy := range live.RouterPolicies {
+			if policy.RouterID == router.UUID {
+				policies = append(policies, policy)
+			}
+		}
+		ovn.On("ListRouterPolicies", ctx, router.UUID).Return(policies, nil)
+	}
+}
+
+// driftedConfiguration is driftConfiguration after unmanaged changes: the
+// web switch's configuration changed, its port was replaced and the db
+// switch removed. The router and its policy were deleted and created again,
+// which isn't drift.
+func driftedConfiguration() *BackupData {
+	live := driftConfiguration()
+	live.LogicalSwitches[0].OtherConfig = map[string]string{"mcast_snoop": "false"}
+	live.LogicalSwitches = live.LogicalSwitches[:1]
+	live.LogicalPorts[0].LogicalSwitchPort = &models.LogicalSwitchPort{UUID: "p2", Name: "web-2"}
+	live.LogicalRouters[0].UUID = "r2"
+	live.RouterPolicies[0].UUID = "pol2"
+	live.RouterPolicies[0].RouterID = "r2"
+	return live
+}
+
+func newTestDriftDetector(t *testing.T) (*DriftDetector, *MockOVNService, *recordingPublisher) {
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	mockStorage.On("List").Return([]*BackupMetadata{{ID: "golden", Name: "baseline"}}, nil)
+	mockStorage.On("Retrieve", "golden").Return(driftConfiguration(), nil)
+
+	golden, err := NewFileGoldenStore(filepath.Join(t.TempDir(), "drift", "golden.json"))
+	require.NoError(t, err)
+	events := &recordingPublisher{}
+	detector := NewDriftDetector(NewBackupService(mockOVN, mockStorage, zap.NewNop()), golden, 0, zap.NewNop())
+	detector.SetEvents(events)
+	return detector, mockOVN, events
+}
+
+func TestDriftDetector_MarkGolden(t *testing.T) {
+	detector, _, _ := newTestDriftDetector(t)
+
+	_, err := detector.MarkGolden("acme", "missing", "admin")
+	assert.ErrorContains(t, err, "backup not found")
+
+	golden, err := detector.MarkGolden("acme", "golden", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "baseline", golden.BackupName)
+	_, err = detector.MarkGolden("", "golden", "admin")
+	require.NoError(t, err)
+
+	goldens, err := detector.GoldenConfigs()
+	require.NoError(t, err)
+	require.Len(t, goldens, 2)
+	assert.Equal(t, "", goldens[0].TenantID, "the cluster's golden backup comes first")
+	assert.Equal(t, "acme", goldens[1].TenantID)
+
+	require.NoError(t, detector.UnmarkGolden("acme"))
+	assert.ErrorIs(t, detector.UnmarkGolden("acme"), ErrGoldenNotFound)
+	_, err = detector.Check(context.Background(), "acme")
+	assert.ErrorIs(t, err, ErrGoldenNotFound)
+}
+
+func TestDriftDetector_Check(t *testing.T) {
+	ctx := context.Background()
+	detector, mockOVN, events := newTestDriftDetector(t)
+	_, err := detector.MarkGolden("acme", "golden", "admin")
+	require.NoError(t, err)
+	_, err = detector.MarkGolden("", "golden", "admin")
+	require.NoError(t, err)
+
+	serveLive(ctx, mockOVN, driftedConfiguration())
+	reports, err := detector.CheckAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	// The tenant's scope leaves the other tenant's switch out
+	report := reports[1]
+	assert.Equal(t, "acme", report.TenantID)
+	assert.True(t, report.Drifted)
+	assert.Equal(t, DiffSide{Source: "backup", ID: "golden", Name: "baseline"}, report.Golden)
+	assert.Equal(t, []DiffObject{{Type: "port", ID: "p2", Name: "web-2"}}, report.Added)
+	assert.Equal(t, []DiffObject{{Type: "port", ID: "p1", Name: "web-1"}}, report.Removed)
+	assert.Equal(t, []ObjectChange{{
+		DiffObject: DiffObject{Type: "switch", ID: "sw1", Name: "web"},
+		Fields: []services.FieldChange{{
+			Field:  "other_config",
+			Before: map[string]interface{}{"mcast_snoop": "true"},
+			After:  map[string]interface{}{"mcast_snoop": "false"},
+		}},
+	}}, report.Modified)
+	assert.Equal(t, []DiffObject{{Type: "port", ID: "p1", Name: "web-1"}, {Type: "switch", ID: "sw2", Name: "db"}},
+		reports[0].Removed, "the cluster's scope covers every tenant")
+	latest, err := detector.Latest()
+	require.NoError(t, err)
+	assert.Len(t, latest, 2)
+
+	require.Len(t, events.events, 2)
+	assert.Equal(t, models.EventDriftDetected, events.events[1].Type)
+	assert.Equal(t, "acme", events.events[1].TenantID)
+	assert.Equal(t, "golden", events.events[1].Data["golden_backup_id"])
+	assert.Equal(t, []string{"port web-2", "port web-1", "switch web"}, events.events[1].Data["objects"])
+
+	// Drift already reported isn't reported again
+	_, err = detector.Check(ctx, "acme")
+	require.NoError(t, err)
+	assert.Len(t, events.events, 2)
+
+	// Undoing the changes resolves the drift
+	serveLive(ctx, mockOVN, driftConfiguration())
+	report, err = detector.Check(ctx, "acme")
+	require.NoError(t, err)
+	assert.False(t, report.Drifted)
+	require.Len(t, events.events, 3)
+	assert.Equal(t, models.EventDriftResolved, events.events[2].Type)
+}
+
+func TestDriftDetector_LatestReports(t *testing.T) {
+	ctx := context.Background()
+	detector, mockOVN, _ := newTestDriftDetector(t)
+	_, err := detector.MarkGolden("acme", "golden", "admin")
+	require.NoError(t, err)
+	_, err = detector.MarkGolden("", "golden", "admin")
+	require.NoError(t, err)
+
+	serveLive(ctx, mockOVN, driftedConfiguration())
+	_, err = detector.CheckAll(ctx)
+	require.NoError(t, err)
+
+	// Replicas sharing the golden store return the reports of the leader's
+	// checks
+	replica := NewDriftDetector(detector.backups, detector.golden, 0, zap.NewNop())
+	reports, err := replica.Latest()
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, "", reports[0].TenantID)
+	assert.Equal(t, "acme", reports[1].TenantID)
+	assert.True(t, reports[1].Drifted)
+
+	// Drift from a golden backup no longer applies once it's replaced or
+	// unmarked
+	_, err = detector.MarkGolden("acme", "golden", "admin")
+	require.NoError(t, err)
+	require.NoError(t, detector.UnmarkGolden(""))
+	reports, err = replica.Latest()
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+
+	// Nor are reports of a golden backup unmarked during the check kept
+	require.NoError(t, detector.golden.SaveReport(&DriftReport{TenantID: "", Golden: DiffSide{ID: "golden"}}))
+	reports, err = replica.Latest()
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+}
+
+func TestDriftDetector_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	detector, mockOVN, _ := newTestDriftDetector(t)
+	_, err := detector.MarkGolden("acme", "golden", "admin")
+	require.NoError(t, err)
+
+	serveLive(ctx, mockOVN, driftedConfiguration())
+	plan, err := detector.Plan(ctx, "acme")
+	require.NoError(t, err)
+
+	// The extra port is deleted, the missing one created in its switch,
+	// then the switch's configuration restored
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, ReconcileStep{Action: ReconcileDelete, DiffObject: DiffObject{Type: "port", ID: "p2", Name: "web-2"}, Parent: "web"},
+		plan.Steps[0])
+	assert.Equal(t, ReconcileCreate, plan.Steps[1].Action)
+	assert.Equal(t, DiffObject{Type: "port", ID: "p1", Name: "web-1"}, plan.Steps[1].DiffObject)
+	assert.Equal(t, ReconcileUpdate, plan.Steps[2].Action)
+	assert.Equal(t, []services.FieldChange{{
+		Field:  "other_config",
+		Before: map[string]interface{}{"mcast_snoop": "false"},
+		After:  map[string]interface{}{"mcast_snoop": "true"},
+	}}, plan.Steps[2].Fields)
+	assert.Equal(t, map[string]int{"create": 1, "update": 1, "delete": 1}, plan.Summary)
+
+	mockOVN.On("DeletePort", ctx, "p2").Return(nil)
+	mockOVN.On("GetLogicalSwitch", ctx, "web").Return(&models.LogicalSwitch{UUID: "sw1", Name: "web"}, nil)
+	mockOVN.On("CreatePort", ctx, "sw1", mock.MatchedBy(func(port *models.LogicalSwitchPort) bool {
+		return port.Name == "web-1" && port.UUID == ""
+	})).Return(nil, errors.New("address in use"))
+	mockOVN.On("UpdateLogicalSwitch", ctx, "sw1", mock.MatchedBy(func(sw *models.LogicalSwitch) bool {
+		return sw.OtherConfig["mcast_snoop"] == "true"
+	})).Return(&models.LogicalSwitch{}, nil)
+
+	result, err := detector.Reconcile(ctx, "acme")
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.Applied)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []string{"Failed to create port web-1: address in use"}, result.Errors)
+	// The drift is checked again afterwards
+	require.NotNil(t, result.Drift)
+	mockOVN.AssertExpectations(t)
+}
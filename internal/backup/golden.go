@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrGoldenNotFound is returned when no golden backup is marked for a scope
+var ErrGoldenNotFound = errors.New("no golden backup marked")
+
+// GoldenConfig marks a backup as the configuration a tenant, or the whole
+// cluster when TenantID is empty, is expected to have
+type GoldenConfig struct {
+	TenantID   string    `json:"tenant_id,omitempty"`
+	BackupID   string    `json:"backup_id"`
+	BackupName string    `json:"backup_name,omitempty"`
+	MarkedBy   string    `json:"marked_by,omitempty"`
+	MarkedAt   time.Time `json:"marked_at"`
+}
+
+// GoldenStore persists the golden backup of each scope, and the report of
+// its last drift check
+type GoldenStore interface {
+	// Save creates or replaces the golden backup of golden.TenantID,
+	// dropping the report of the previous one
+	Save(golden *GoldenConfig) error
+
+	// Get returns the golden backup of a tenant, "" for the cluster, or
+	// ErrGoldenNotFound
+	Get(tenantID string) (*GoldenConfig, error)
+
+	// List returns the golden backups, the cluster's first
+	List() ([]*GoldenConfig, error)
+
+	// Delete unmarks the golden backup of a tenant, with its report, or
+	// returns ErrGoldenNotFound
+	Delete(tenantID string) error
+
+	// SaveReport replaces the report of the last check of
+	// report.TenantID. Reports of a golden backup that was replaced or
+	// unmarked since the check are dropped.
+	SaveReport(report *DriftReport) error
+
+	// Reports returns the report of the last check of each scope, the
+	// cluster's first
+	Reports() ([]*DriftReport, error)
+}
+
+// FileGoldenStore keeps the golden backups in a JSON file, and the drift
+// reports in reports.json next to it
+type FileGoldenStore struct {
+	path        string
+	reportsPath string
+	mu          sync.Mutex
+}
+
+// NewFileGoldenStore creates a store writing to path, creating its
+// directory
+func NewFileGoldenStore(path string) (*FileGoldenStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create golden backup directory: %w", err)
+	}
+
+	return &FileGoldenStore{
+		path:        path,
+		reportsPath: filepath.Join(filepath.Dir(path), "reports.json"),
+	}, nil
+}
+
+// Save writes the golden backup, replacing the file atomically
+func (s *FileGoldenStore) Save(golden *GoldenConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goldens, err := s.read()
+	if err != nil {
+		return err
+	}
+	goldens[golden.TenantID] = golden
+	if err := s.write(goldens); err != nil {
+		return err
+	}
+	return s.deleteReport(golden.TenantID)
+}
+
+// Get reads the golden backup of a scope
+func (s *FileGoldenStore) Get(tenantID string) (*GoldenConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goldens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	golden, ok := goldens[tenantID]
+	if !ok {
+		return nil, ErrGoldenNotFound
+	}
+	return golden, nil
+}
+
+// List reads the golden backups
+func (s *FileGoldenStore) List() ([]*GoldenConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goldens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return sortedGoldens(goldens), nil
+}
+
+// Delete removes the golden backup of a scope
+func (s *FileGoldenStore) Delete(tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goldens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := goldens[tenantID]; !ok {
+		return ErrGoldenNotFound
+	}
+	delete(goldens, tenantID)
+	if err := s.write(goldens); err != nil {
+		return err
+	}
+	return s.deleteReport(tenantID)
+}
+
+// SaveReport writes the report of a scope, replacing the file atomically
+func (s *FileGoldenStore) SaveReport(report *DriftReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goldens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if golden, ok := goldens[report.TenantID]; !ok || golden.BackupID != report.Golden.ID {
+		return nil
+	}
+	reports, err := s.readReports()
+	if err != nil {
+		return err
+	}
+	reports[report.TenantID] = report
+	return s.writeReports(reports)
+}
+
+// Reports reads the drift reports
+func (s *FileGoldenStore) Reports() ([]*DriftReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports, err := s.readReports()
+	if err != nil {
+		return nil, err
+	}
+	return sortedReports(reports), nil
+}
+
+// deleteReport removes the report of a scope, if any
+func (s *FileGoldenStore) deleteReport(tenantID string) error {
+	reports, err := s.readReports()
+	if err != nil {
+		return err
+	}
+	if _, ok := reports[tenantID]; !ok {
+		return nil
+	}
+	delete(reports, tenantID)
+	return s.writeReports(reports)
+}
+
+// read returns the golden backups by tenant, none when the file doesn't
+// exist yet
+func (s *FileGoldenStore) read() (map[string]*GoldenConfig, error) {
+	goldens := make(map[string]*GoldenConfig)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return goldens, nil
+		}
+		return nil, fmt.Errorf("failed to read golden backups: %w", err)
+	}
+
+	var list []*GoldenConfig
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse golden backups: %w", err)
+	}
+	for _, golden := range list {
+		goldens[golden.TenantID] = golden
+	}
+	return goldens, nil
+}
+
+func (s *FileGoldenStore) write(goldens map[string]*GoldenConfig) error {
+	return writeJSONFile(s.path, sortedGoldens(goldens), "golden backups")
+}
+
+// readReports returns the drift reports by tenant, none when the file
+// doesn't exist yet
+func (s *FileGoldenStore) readReports() (map[string]*DriftReport, error) {
+	reports := make(map[string]*DriftReport)
+	data, err := os.ReadFile(s.reportsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reports, nil
+		}
+		return nil, fmt.Errorf("failed to read drift reports: %w", err)
+	}
+
+	var list []*DriftReport
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse drift reports: %w", err)
+	}
+	for _, report := range list {
+		reports[report.TenantID] = report
+	}
+	return reports, nil
+}
+
+func (s *FileGoldenStore) writeReports(reports map[string]*DriftReport) error {
+	return writeJSONFile(s.reportsPath, sortedReports(reports), "drift reports")
+}
+
+// writeJSONFile writes v to path as JSON, replacing the file atomically
+func writeJSONFile(path string, v interface{}, what string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	return nil
+}
+
+// sortedGoldens lists golden backups by tenant, the cluster's first
+func sortedGoldens(goldens map[string]*GoldenConfig) []*GoldenConfig {
+	list := make([]*GoldenConfig, 0, len(goldens))
+	for _, golden := range goldens {
+		list = append(list, golden)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TenantID < list[j].TenantID })
+	return list
+}
+
+// sortedReports lists drift reports by tenant, the cluster's first
+func sortedReports(reports map[string]*DriftReport) []*DriftReport {
+	list := make([]*DriftReport, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, report)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TenantID < list[j].TenantID })
+	return list
+}
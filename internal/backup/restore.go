@@ -0,0 +1,300 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// replay creates the resources of a backup: switches and routers, then the
+// resources they hold, with up to the requested parallelism
+func (s *BackupService) replay(ctx context.Context, backup *BackupData, options *RestoreOptions) *RestoreResult {
+	result := &RestoreResult{
+		Success: true,
+		Details: make(map[string]RestoreDetail),
+	}
+	parallelism := options.Parallelism
+	if parallelism == 0 {
+		parallelism = DefaultRestoreParallelism
+	}
+	run := newRestoreRun(backup, options, result)
+	if err := runRestoreTasks(ctx, s.restoreTasks(run), parallelism); err != nil {
+		result.Success = false
+		result.Errors = append(result.Errors, fmt.Sprintf("Restore interrupted: %v", err))
+	}
+	run.finish()
+	return result
+}
+
+// restoreTask restores one resource; its dependents, the resources it
+// holds, are restored once it was
+type restoreTask struct {
+	restore    func(ctx context.Context)
+	dependents []*restoreTask
+}
+
+// restoreTasks builds the dependency graph of a backup: switches hold
+// their ports and ACLs, routers their policies. Resources whose switch or
+// router isn't in the backup don't wait for anything.
+func (s *BackupService) restoreTasks(run *restoreRun) []*restoreTask {
+	var roots []*restoreTask
+	switches := make(map[string]*restoreTask, len(run.backup.LogicalSwitches))
+	for _, sw := range run.backup.LogicalSwitches {
+		sw := sw
+		task := &restoreTask{restore: func(ctx context.Context) { s.restoreSwitch(ctx, run, sw) }}
+		switches[sw.UUID] = task
+		roots = append(roots, task)
+	}
+	routers := make(map[string]*restoreTask, len(run.backup.LogicalRouters))
+	for _, router := range run.backup.LogicalRouters {
+		router := router
+		task := &restoreTask{restore: func(ctx context.Context) { s.restoreRouter(ctx, run, router) }}
+		routers[router.UUID] = task
+		roots = append(roots, task)
+	}
+
+	dependOn := func(parents map[string]*restoreTask, parentID string, task *restoreTask) {
+		if parent, ok := parents[parentID]; ok {
+			parent.dependents = append(parent.dependents, task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+	for _, policy := range run.backup.RouterPolicies {
+		policy := policy
+		dependOn(routers, policy.RouterID, &restoreTask{restore: func(ctx context.Context) { s.restoreRouterPolicy(ctx, run, policy) }})
+	}
+	for _, port := range run.backup.LogicalPorts {
+		port := port
+		dependOn(switches, port.SwitchID, &restoreTask{restore: func(ctx context.Context) { s.restorePort(ctx, run, port) }})
+	}
+	for _, acl := range run.backup.ACLs {
+		acl := acl
+		dependOn(switches, acl.SwitchID, &restoreTask{restore: func(ctx context.Context) { s.restoreACL(ctx, run, acl) }})
+	}
+	return roots
+}
+
+// runRestoreTasks runs the tasks of a dependency graph on parallelism
+// workers, each task once those it depends on ran. Once ctx is done, the
+// remaining tasks are dropped and its error returned.
+func runRestoreTasks(ctx context.Context, roots []*restoreTask, parallelism int) error {
+	var (
+		mu      sync.Mutex
+		ready   = append([]*restoreTask(nil), roots...)
+		pending = 0
+	)
+	var count func(tasks []*restoreTask)
+	count = func(tasks []*restoreTask) {
+		for _, task := range tasks {
+			pending++
+			count(task.dependents)
+		}
+	}
+	count(roots)
+	cond := sync.NewCond(&mu)
+	workers := parallelism
+	if pending < workers {
+		workers = pending
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			for {
+				for len(ready) == 0 && pending > 0 {
+					cond.Wait()
+				}
+				if pending == 0 {
+					return
+				}
+				task := ready[0]
+				ready = ready[1:]
+
+				mu.Unlock()
+				if ctx.Err() == nil {
+					task.restore(ctx)
+				}
+				mu.Lock()
+
+				ready = append(ready, task.dependents...)
+				pending--
+				cond.Broadcast()
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// restoreRun accounts for the resources restored, skipped and failed by
+// concurrent tasks
+type restoreRun struct {
+	backup  *BackupData
+	options *RestoreOptions
+
+	mu      sync.Mutex
+	result  *RestoreResult
+	details map[string]*RestoreDetail
+}
+
+func newRestoreRun(backup *BackupData, options *RestoreOptions, result *RestoreResult) *restoreRun {
+	return &restoreRun{
+		backup:  backup,
+		options: options,
+		result:  result,
+		details: map[string]*RestoreDetail{
+			"switches":        {Total: len(backup.LogicalSwitches)},
+			"routers":         {Total: len(backup.LogicalRouters)},
+			"router_policies": {Total: len(backup.RouterPolicies)},
+			"ports":           {Total: len(backup.LogicalPorts)},
+			"acls":            {Total: len(backup.ACLs)},
+		},
+	}
+}
+
+func (r *restoreRun) restored(kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[kind].Restored++
+	r.result.RestoredCount++
+}
+
+func (r *restoreRun) skipped(kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[kind].Skipped++
+	r.result.SkippedCount++
+}
+
+// failed records a resource that couldn't be created
+func (r *restoreRun) failed(kind, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[kind].Failed++
+	r.details[kind].Errors = append(r.details[kind].Errors, message)
+	r.result.ErrorCount++
+}
+
+// conflicted records a resource left alone because of a conflict with an
+// existing one
+func (r *restoreRun) conflicted(kind, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.details[kind].Failed++
+	r.details[kind].Errors = append(r.details[kind].Errors, message)
+}
+
+// finish adds the details of each kind of resource to the result
+func (r *restoreRun) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for kind, detail := range r.details {
+		r.result.Details[kind] = *detail
+	}
+}
+
+// mapID returns the ID a resource of the backup is restored under
+func (r *restoreRun) mapID(id string) string {
+	if mappedID, ok := r.options.ResourceMapping[id]; ok {
+		return mappedID
+	}
+	return id
+}
+
+// restoreSwitch restores a logical switch
+func (s *BackupService) restoreSwitch(ctx context.Context, run *restoreRun, sw *models.LogicalSwitch) {
+	// Check if switch already exists
+	existing, err := s.ovnService.GetLogicalSwitch(ctx, sw.Name)
+	if err == nil && existing != nil {
+		// Handle conflict
+		switch run.options.ConflictPolicy {
+		case ConflictPolicySkip:
+			run.skipped("switches")
+			return
+		case ConflictPolicyOverwrite:
+			if err := s.ovnService.DeleteLogicalSwitch(ctx, existing.UUID); err != nil {
+				run.conflicted("switches", fmt.Sprintf("Failed to delete existing switch %s: %v", sw.Name, err))
+				return
+			}
+		case ConflictPolicyRename:
+			sw.Name = fmt.Sprintf("%s_restored_%d", sw.Name, time.Now().Unix())
+		case ConflictPolicyError:
+			run.conflicted("switches", fmt.Sprintf("Switch %s already exists", sw.Name))
+			return
+		}
+	}
+
+	if _, err := s.ovnService.CreateLogicalSwitch(ctx, sw); err != nil {
+		run.failed("switches", fmt.Sprintf("Failed to create switch %s: %v", sw.Name, err))
+		return
+	}
+	run.restored("switches")
+}
+
+// restoreRouter restores a logical router
+func (s *BackupService) restoreRouter(ctx context.Context, run *restoreRun, router *models.LogicalRouter) {
+	// Check if router already exists
+	existing, err := s.ovnService.GetLogicalRouter(ctx, router.Name)
+	if err == nil && existing != nil {
+		// Handle conflict
+		switch run.options.ConflictPolicy {
+		case ConflictPolicySkip:
+			run.skipped("routers")
+			return
+		case ConflictPolicyOverwrite:
+			if err := s.ovnService.DeleteLogicalRouter(ctx, existing.UUID); err != nil {
+				run.conflicted("routers", fmt.Sprintf("Failed to delete existing router %s: %v", router.Name, err))
+				return
+			}
+		case ConflictPolicyRename:
+			router.Name = fmt.Sprintf("%s_restored_%d", router.Name, time.Now().Unix())
+		case ConflictPolicyError:
+			run.conflicted("routers", fmt.Sprintf("Router %s already exists", router.Name))
+			return
+		}
+	}
+
+	if _, err := s.ovnService.CreateLogicalRouter(ctx, router); err != nil {
+		run.failed("routers", fmt.Sprintf("Failed to create router %s: %v", router.Name, err))
+		return
+	}
+	run.restored("routers")
+}
+
+// restoreRouterPolicy restores a policy in its router, which might have
+// been mapped to another
+func (s *BackupService) restoreRouterPolicy(ctx context.Context, run *restoreRun, policy *models.RouterPolicy) {
+	if _, err := s.ovnService.CreateRouterPolicy(ctx, run.mapID(policy.RouterID), policy); err != nil {
+		run.failed("router_policies", fmt.Sprintf("Failed to create router policy %q: %v", policy.Match, err))
+		return
+	}
+	run.restored("router_policies")
+}
+
+// restorePort restores a logical switch port in its switch, which might
+// have been mapped to another
+func (s *BackupService) restorePort(ctx context.Context, run *restoreRun, port *LogicalPortWithSwitch) {
+	if _, err := s.ovnService.CreatePort(ctx, run.mapID(port.SwitchID), port.LogicalSwitchPort); err != nil {
+		run.failed("ports", fmt.Sprintf("Failed to create port %s: %v", port.Name, err))
+		return
+	}
+	run.restored("ports")
+}
+
+// restoreACL restores an ACL in its switch, which might have been mapped
+// to another
+func (s *BackupService) restoreACL(ctx context.Context, run *restoreRun, acl *ACLWithSwitch) {
+	if _, err := s.ovnService.CreateACL(ctx, run.mapID(acl.SwitchID), acl.ACL); err != nil {
+		run.failed("acls", fmt.Sprintf("Failed to create ACL %s: %v", acl.Name, err))
+		return
+	}
+	run.restored("acls")
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestBackupService_RestoreInParallel(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(mockOVN, mockStorage, zap.NewNop())
+
+	// Two switches of ten ports and an ACL each, and ports of a switch that
+	// isn't in the backup
+	backupData := &BackupData{Metadata: BackupMetadata{ID: "backup-123", Version: "1.0"}}
+	for _, id := range []string{"sw1", "sw2"} {
+		backupData.LogicalSwitches = append(backupData.LogicalSwitches, &models.LogicalSwitch{UUID: id, Name: "switch-" + id})
+		for i := 0; i < 10; i++ {
+			backupData.LogicalPorts = append(backupData.LogicalPorts, &LogicalPortWithSwitch{
+				LogicalSwitchPort: &models.LogicalSwitchPort{Name: fmt.Sprintf("%s-port%d", id, i)},
+				SwitchID:          id,
+			})
+		}
+		backupData.ACLs = append(backupData.ACLs, &ACLWithSwitch{ACL: &models.ACL{Name: "acl-" + id}, SwitchID: id})
+	}
+	backupData.LogicalPorts = append(backupData.LogicalPorts, &LogicalPortWithSwitch{
+		LogicalSwitchPort: &models.LogicalSwitchPort{Name: "existing-port"},
+		SwitchID:          "sw-existing",
+	})
+	mockStorage.On("Retrieve", "backup-123").Return(backupData, nil)
+
+	var (
+		mu       sync.Mutex
+		created  = map[string]bool{}
+		early    []string
+		inFlight int32
+		peak     int32
+	)
+	mockOVN.On("GetLogicalSwitch", ctx, mock.Anything).Return(nil, nil)
+	mockOVN.On("CreateLogicalSwitch", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		created[args.Get(1).(*models.LogicalSwitch).UUID] = true
+	}).Return(&models.LogicalSwitch{}, nil)
+	holdSwitch := func(args mock.Arguments) {
+		mu.Lock()
+		if switchID := args.String(1); switchID != "sw-existing" && !created[switchID] {
+			early = append(early, switchID)
+		}
+		mu.Unlock()
+
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}
+	mockOVN.On("CreatePort", ctx, mock.Anything, mock.Anything).Run(holdSwitch).Return(&models.LogicalSwitchPort{}, nil)
+	mockOVN.On("CreateACL", ctx, mock.Anything, mock.Anything).Run(holdSwitch).Return(&models.ACL{}, nil)
+
+	result, err := service.RestoreBackup(ctx, "backup-123", &RestoreOptions{ConflictPolicy: ConflictPolicySkip, Parallelism: 4})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2+21+2, result.RestoredCount)
+	assert.Equal(t, RestoreDetail{Total: 21, Restored: 21}, result.Details["ports"])
+	assert.Equal(t, RestoreDetail{Total: 0}, result.Details["routers"])
+
+	assert.Empty(t, early, "ports and ACLs are restored after their switch")
+	assert.LessOrEqual(t, peak, int32(4))
+	assert.Greater(t, peak, int32(1))
+	mockOVN.AssertNumberOfCalls(t, "CreatePort", 21)
+}
+
+func TestBackupService_RestoreParallelismBounds(t *testing.T) {
+	mockStorage := NewMockBackupStorage()
+	service := NewBackupService(new(MockOVNService), mockStorage, zap.NewNop())
+	mockStorage.On("Retrieve", "backup-123").Return(&BackupData{Metadata: BackupMetadata{ID: "backup-123", Version: "1.0"}}, nil)
+
+	for _, parallelism := range []int{-1, MaxRestoreParallelism + 1} {
+		_, err := service.RestoreBackup(context.Background(), "backup-123", &RestoreOptions{Parallelism: parallelism})
+		assert.ErrorContains(t, err, "parallelism must be between 1 and")
+	}
+}
+
+func TestRunRestoreTasksCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ran int32
+
+	// The first root cancels the restore; nothing else runs
+	var roots []*restoreTask
+	for i := 0; i < 10; i++ {
+		task := &restoreTask{restore: func(context.Context) {
+			if atomic.AddInt32(&ran, 1) == 1 {
+				cancel()
+			}
+		}}
+		task.dependents = []*restoreTask{{restore: func(context.Context) { atomic.AddInt32(&ran, 1) }}}
+		roots = append(roots, task)
+	}
+
+	err := runRestoreTasks(ctx, roots, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), ran)
+}
@@ -0,0 +1,36 @@
+package backup
+
+import (
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// Topology returns the topology recorded in the backup, as of its creation
+func (b *BackupData) Topology() *services.Topology {
+	topology := &services.Topology{
+		Switches:  b.LogicalSwitches,
+		Routers:   b.LogicalRouters,
+		Timestamp: b.Metadata.CreatedAt,
+	}
+	for _, port := range b.LogicalPorts {
+		if port.LogicalSwitchPort != nil {
+			topology.Ports = append(topology.Ports, port.LogicalSwitchPort)
+		}
+	}
+	for _, acl := range b.ACLs {
+		if acl.ACL != nil {
+			topology.ACLs = append(topology.ACLs, acl.ACL)
+		}
+	}
+	return topology
+}
+
+// TopologySource returns the topology recorded in the stored backups
+func TopologySource(storage BackupStorage) services.TopologyBackupSource {
+	return func(id string) (*services.Topology, error) {
+		backup, err := storage.Retrieve(id)
+		if err != nil {
+			return nil, err
+		}
+		return backup.Topology(), nil
+	}
+}
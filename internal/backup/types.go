@@ -48,6 +48,7 @@ type BackupData struct {
 	ACLs             []*ACLWithSwitch                    `json:"acls" yaml:"acls"`
 	LoadBalancers    []*models.LoadBalancer              `json:"load_balancers,omitempty" yaml:"load_balancers,omitempty"`
 	NATs             []*NATWithRouter                    `json:"nats,omitempty" yaml:"nats,omitempty"`
+	RouterPolicies   []*models.RouterPolicy              `json:"router_policies,omitempty" yaml:"router_policies,omitempty"`
 	DHCPOptions      []*models.DHCPOptions               `json:"dhcp_options,omitempty" yaml:"dhcp_options,omitempty"`
 	QoSRules         []*models.QoS                       `json:"qos_rules,omitempty" yaml:"qos_rules,omitempty"`
 	PortGroups       []*models.PortGroup                 `json:"port_groups,omitempty" yaml:"port_groups,omitempty"`
@@ -82,6 +83,8 @@ type BackupStatistics struct {
 	TotalObjects      int            `json:"total_objects" yaml:"total_objects"`
 	ObjectCounts      map[string]int `json:"object_counts" yaml:"object_counts"`
 	ProcessingTime    time.Duration  `json:"processing_time" yaml:"processing_time"`
+	// PhaseTimings is how long each phase of collecting a full backup took
+	PhaseTimings      map[string]time.Duration `json:"phase_timings,omitempty" yaml:"phase_timings,omitempty"`
 	CompressedSize    int64          `json:"compressed_size,omitempty" yaml:"compressed_size,omitempty"`
 	UncompressedSize  int64          `json:"uncompressed_size" yaml:"uncompressed_size"`
 }
@@ -125,8 +128,17 @@ type RestoreOptions struct {
 	ConflictPolicy  ConflictPolicy    `json:"conflict_policy"`
 	RestoreFilter   *ResourceFilter   `json:"restore_filter,omitempty"`
 	DecryptionKey   string            `json:"-"` // Never serialize
+	// Parallelism is how many resources are restored at a time, 0 for
+	// DefaultRestoreParallelism
+	Parallelism     int               `json:"parallelism,omitempty"`
 }
 
+// Bounds of RestoreOptions.Parallelism
+const (
+	DefaultRestoreParallelism = 8
+	MaxRestoreParallelism     = 64
+)
+
 // ConflictPolicy defines how to handle conflicts during restore
 type ConflictPolicy string
 
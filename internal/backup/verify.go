@@ -0,0 +1,334 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrVerificationDisabled is returned when no staging deployment is
+	// configured to verify backups against
+	ErrVerificationDisabled = errors.New("DR verification is not configured")
+	// ErrVerificationInProgress is returned when the staging deployment is
+	// already verifying another backup
+	ErrVerificationInProgress = errors.New("a DR verification is already in progress")
+)
+
+// verificationKinds are the kinds of resources a verification checks, in
+// report order
+var verificationKinds = []string{"switches", "routers", "ports", "acls", "router_policies"}
+
+// identityFields are assigned by OVN or reference other resources by UUID,
+// so they differ between a backup and its restore
+var identityFields = []string{"uuid", "ports", "acls", "qos_rules", "load_balancer", "dns_records",
+	"policies", "policy_rules", "switch_id", "router_id", "parent_uuid", "bfd_status"}
+
+// VerificationReport is the outcome of replaying a backup against the DR
+// staging deployment. It is signed so auditors can check it wasn't altered.
+type VerificationReport struct {
+	ID             string              `json:"id"`
+	BackupID       string              `json:"backup_id"`
+	BackupName     string              `json:"backup_name"`
+	BackupChecksum string              `json:"backup_checksum,omitempty"`
+	Staging        string              `json:"staging"`
+	StartedAt      time.Time           `json:"started_at"`
+	CompletedAt    time.Time           `json:"completed_at"`
+	Passed         bool                `json:"passed"`
+	Restore        *RestoreResult      `json:"restore"`
+	Checks         []VerificationCheck `json:"checks"`
+	// Signature is "sha256=" and the hex HMAC-SHA256 of the report's JSON
+	// without the signature
+	Signature string `json:"signature"`
+}
+
+// VerificationCheck compares the resources of a kind in a backup with those
+// restored from it. Checksums cover the resources' configuration, not the
+// UUIDs OVN assigned them.
+type VerificationCheck struct {
+	Kind             string `json:"kind"`
+	ExpectedCount    int    `json:"expected_count"`
+	ActualCount      int    `json:"actual_count"`
+	ExpectedChecksum string `json:"expected_checksum"`
+	ActualChecksum   string `json:"actual_checksum"`
+	Passed           bool   `json:"passed"`
+}
+
+// verification is the staging deployment backups are verified against
+type verification struct {
+	staging    services.OVNServiceInterface
+	endpoint   string
+	signingKey []byte
+	running    chan struct{} // Holds a token while a verification runs
+}
+
+// SetVerification enables VerifyRestore against staging, reached at
+// endpoint, signing reports with signingKey. Staging is emptied by every
+// verification, so it must not be a deployment anything else uses.
+func (s *BackupService) SetVerification(staging services.OVNServiceInterface, endpoint, signingKey string) {
+	s.verification = &verification{
+		staging:    staging,
+		endpoint:   endpoint,
+		signingKey: []byte(signingKey),
+		running:    make(chan struct{}, 1),
+	}
+}
+
+// VerifyRestore replays a backup against the DR staging deployment: it
+// empties staging, restores the backup into it, reads it back and compares
+// the resources of each kind with the backup's by count and checksum. The
+// restored resources are left in staging for inspection until the next
+// verification.
+func (s *BackupService) VerifyRestore(ctx context.Context, backupID string) (*VerificationReport, error) {
+	v := s.verification
+	if v == nil {
+		return nil, ErrVerificationDisabled
+	}
+	select {
+	case v.running <- struct{}{}:
+		defer func() { <-v.running }()
+	default:
+		return nil, ErrVerificationInProgress
+	}
+
+	startTime := time.Now()
+	report, err := s.verifyRestore(ctx, v, backupID)
+	metrics.RecordBackupOperation("verify", err == nil && report.Passed, time.Since(startTime).Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Restore verification completed",
+		zap.String("backup_id", backupID),
+		zap.String("report_id", report.ID),
+		zap.Bool("passed", report.Passed))
+	return report, nil
+}
+
+func (s *BackupService) verifyRestore(ctx context.Context, v *verification, backupID string) (*VerificationReport, error) {
+	backupData, err := s.storage.Retrieve(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve backup: %w", err)
+	}
+	if err := s.validateBackup(backupData); err != nil {
+		return nil, fmt.Errorf("backup validation failed: %w", err)
+	}
+
+	report := &VerificationReport{
+		ID:         uuid.New().String(),
+		BackupID:   backupID,
+		BackupName: backupData.Metadata.Name,
+		Staging:    v.endpoint,
+		StartedAt:  time.Now().UTC(),
+	}
+	// Stored backups are checksummed when written; the checksum identifies
+	// the backup the report is about
+	if metadata, err := s.GetBackup(backupID); err == nil {
+		report.BackupChecksum = metadata.Checksum
+	}
+
+	// Checksum the backup before restoring it, which may modify it
+	expected := verificationDigests(backupData)
+
+	staging := NewBackupService(v.staging, s.storage, s.logger)
+	if err := staging.clearStaging(ctx); err != nil {
+		return nil, fmt.Errorf("failed to clear the staging deployment: %w", err)
+	}
+	report.Restore = staging.replay(ctx, backupData, &RestoreOptions{ConflictPolicy: ConflictPolicyError})
+	report.Restore.ProcessingTime = time.Since(report.StartedAt)
+
+	restored := &BackupData{Statistics: &BackupStatistics{
+		ObjectCounts: make(map[string]int),
+		PhaseTimings: make(map[string]time.Duration),
+	}}
+	if err := staging.collectFullBackup(ctx, restored, nil); err != nil {
+		return nil, fmt.Errorf("failed to read the staging deployment: %w", err)
+	}
+	actual := verificationDigests(restored)
+
+	report.Passed = report.Restore.Success && report.Restore.ErrorCount == 0
+	for _, kind := range verificationKinds {
+		check := VerificationCheck{
+			Kind:             kind,
+			ExpectedCount:    expected[kind].count,
+			ActualCount:      actual[kind].count,
+			ExpectedChecksum: expected[kind].checksum,
+			ActualChecksum:   actual[kind].checksum,
+		}
+		check.Passed = check.ExpectedCount == check.ActualCount && check.ExpectedChecksum == check.ActualChecksum
+		report.Passed = report.Passed && check.Passed
+		report.Checks = append(report.Checks, check)
+	}
+	report.CompletedAt = time.Now().UTC()
+
+	if err := SignReport(report, v.signingKey); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// clearStaging deletes the routers and switches of the staging deployment,
+// and with them the resources they hold
+func (s *BackupService) clearStaging(ctx context.Context) error {
+	routers, err := s.ovnService.ListLogicalRouters(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	for _, router := range routers {
+		if err := s.ovnService.DeleteLogicalRouter(ctx, router.UUID); err != nil {
+			return fmt.Errorf("failed to delete router %s: %w", router.Name, err)
+		}
+	}
+
+	switches, err := s.ovnService.ListLogicalSwitches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list logical switches: %w", err)
+	}
+	for _, sw := range switches {
+		if err := s.ovnService.DeleteLogicalSwitch(ctx, sw.UUID); err != nil {
+			return fmt.Errorf("failed to delete switch %s: %w", sw.Name, err)
+		}
+	}
+	return nil
+}
+
+// kindDigest is the number of resources of a kind and their checksum
+type kindDigest struct {
+	count    int
+	checksum string
+}
+
+// verificationDigests returns the digest of each kind of resource in a
+// backup. A resource is its configuration as canonical JSON, without
+// volatile and identity fields; a kind's checksum is the SHA-256 of its
+// resources sorted, so it doesn't depend on the order OVN lists them in.
+func verificationDigests(backup *BackupData) map[string]kindDigest {
+	routerNames := make(map[string]string, len(backup.LogicalRouters))
+	for _, router := range backup.LogicalRouters {
+		routerNames[router.UUID] = router.Name
+	}
+
+	resources := map[string][]interface{}{}
+	for _, sw := range backup.LogicalSwitches {
+		resources["switches"] = append(resources["switches"], sw)
+	}
+	for _, router := range backup.LogicalRouters {
+		resources["routers"] = append(resources["routers"], router)
+	}
+	for _, port := range backup.LogicalPorts {
+		resources["ports"] = append(resources["ports"], port)
+	}
+	for _, acl := range backup.ACLs {
+		resources["acls"] = append(resources["acls"], acl)
+	}
+
+	digests := make(map[string]kindDigest, len(verificationKinds))
+	for _, kind := range verificationKinds {
+		var lines []string
+		for _, resource := range resources[kind] {
+			lines = append(lines, canonicalResource(services.ResourceFields(resource)))
+		}
+		if kind == "router_policies" {
+			// Policies are told apart by their router's name, its UUID
+			// differing once restored
+			for _, policy := range backup.RouterPolicies {
+				fields := services.ResourceFields(policy)
+				fields["router"] = routerNames[policy.RouterID]
+				lines = append(lines, canonicalResource(fields))
+			}
+		}
+		digests[kind] = digestLines(lines)
+	}
+	return digests
+}
+
+// canonicalResource returns the configuration fields of a resource as JSON
+func canonicalResource(fields map[string]interface{}) string {
+	// encoding/json sorts map keys, so the output is canonical
+	data, _ := json.Marshal(configFields(fields))
+	return string(data)
+}
+
+// configFields removes from fields the identity fields, and those of the
+// objects they hold, and who created the resource, leaving what a restore
+// reproduces
+func configFields(fields map[string]interface{}) map[string]interface{} {
+	stripIdentity(fields)
+	if externalIDs, ok := fields["external_ids"].(map[string]interface{}); ok {
+		delete(externalIDs, models.CreatedByKey)
+		delete(externalIDs, models.CreatedSourceKey)
+		if len(externalIDs) == 0 {
+			delete(fields, "external_ids")
+		}
+	}
+	return fields
+}
+
+func stripIdentity(fields map[string]interface{}) {
+	for _, name := range identityFields {
+		delete(fields, name)
+	}
+	for _, value := range fields {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				if object, ok := item.(map[string]interface{}); ok {
+					stripIdentity(object)
+				}
+			}
+		}
+	}
+}
+
+func digestLines(lines []string) kindDigest {
+	sort.Strings(lines)
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+	return kindDigest{count: len(lines), checksum: hex.EncodeToString(hash.Sum(nil))}
+}
+
+// SignReport sets the signature of a report with key
+func SignReport(report *VerificationReport, key []byte) error {
+	signature, err := reportSignature(report, key)
+	if err != nil {
+		return err
+	}
+	report.Signature = signature
+	return nil
+}
+
+// VerifyReportSignature reports whether a report carries a valid signature
+// by key, i.e. wasn't altered since it was signed
+func VerifyReportSignature(report *VerificationReport, key []byte) bool {
+	expected, err := reportSignature(report, key)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(report.Signature))
+}
+
+func reportSignature(report *VerificationReport, key []byte) (string, error) {
+	unsigned := *report
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode verification report: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
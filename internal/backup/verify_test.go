@@ -0,0 +1,164 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// verificationBackup is a backup of a switch with a port and an ACL, and a
+// router with a policy
+func verificationBackup() *BackupData {
+	return &BackupData{
+		Metadata: BackupMetadata{ID: "backup-123", Name: "nightly", Version: "1.0"},
+		LogicalSwitches: []*models.LogicalSwitch{{
+			UUID: "sw1", Name: "web", Ports: []string{"p1"}, ACLs: []string{"a1"},
+			ExternalIDs: map[string]string{models.CreatedByKey: "alice", "team": "web"},
+		}},
+		LogicalRouters: []*models.LogicalRouter{{UUID: "r1", Name: "edge", Policies: []string{"pol1"}}},
+		LogicalPorts: []*LogicalPortWithSwitch{{
+			LogicalSwitchPort: &models.LogicalSwitchPort{UUID: "p1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.1"}},
+			SwitchID:          "sw1",
+			SwitchName:        "web",
+		}},
+		ACLs: []*ACLWithSwitch{{
+			ACL:        &models.ACL{UUID: "a1", Name: "allow-http", Priority: 1000, Direction: "to-lport", Match: "tcp.dst == 80", Action: "allow"},
+			SwitchID:   "sw1",
+			SwitchName: "web",
+		}},
+		RouterPolicies: []*models.RouterPolicy{{UUID: "pol1", RouterID: "r1", Priority: 100, Match: "ip4.src == 10.0.0.0/24", Action: "allow"}},
+	}
+}
+
+// stageVerification sets staging up to hold a stale switch and router
+// before the restore, and what the backup restores to after
+func stageVerification(ctx context.Context, staging *MockOVNService, aclRestored bool) {
+	staging.On("ListLogicalRouters", ctx).Return([]*models.LogicalRouter{{UUID: "stale-r", Name: "stale"}}, nil).Once()
+	staging.On("DeleteLogicalRouter", ctx, "stale-r").Return(nil)
+	staging.On("ListLogicalSwitches", ctx).Return([]*models.LogicalSwitch{{UUID: "stale-sw", Name: "stale"}}, nil).Once()
+	staging.On("DeleteLogicalSwitch", ctx, "stale-sw").Return(nil)
+
+	staging.On("GetLogicalSwitch", ctx, "web").Return(nil, errors.New("not found"))
+	staging.On("CreateLogicalSwitch", ctx, mock.Anything).Return(&models.LogicalSwitch{}, nil)
+	staging.On("GetLogicalRouter", ctx, "edge").Return(nil, errors.New("not found"))
+	staging.On("CreateLogicalRouter", ctx, mock.Anything).Return(&models.LogicalRouter{}, nil)
+	staging.On("CreatePort", ctx, "sw1", mock.Anything).Return(&models.LogicalSwitchPort{}, nil)
+	staging.On("CreateRouterPolicy", ctx, "r1", mock.Anything).Return(&models.RouterPolicy{}, nil)
+	var acls []*models.ACL
+	if aclRestored {
+		staging.On("CreateACL", ctx, "sw1", mock.Anything).Return(&models.ACL{}, nil)
+		acls = []*models.ACL{{UUID: "new-a1", Name: "allow-http", Priority: 1000, Direction: "to-lport", Match: "tcp.dst == 80", Action: "allow"}}
+	} else {
+		staging.On("CreateACL", ctx, "sw1", mock.Anything).Return(nil, errors.New("constraint violation"))
+	}
+
+	// Restored resources have new UUIDs, timestamps, state and creator
+	up := true
+	staging.On("ListLogicalSwitches", ctx).Return([]*models.LogicalSwitch{{
+		UUID: "new-sw1", Name: "web", Ports: []string{"new-p1"}, CreatedAt: time.Now(),
+		ExternalIDs: map[string]string{models.CreatedByKey: "dr-verifier", "team": "web"},
+	}}, nil)
+	staging.On("ListLogicalRouters", ctx).Return([]*models.LogicalRouter{{UUID: "new-r1", Name: "edge", Policies: []string{"new-pol1"}}}, nil)
+	staging.On("ListPorts", ctx, "new-sw1").Return([]*models.LogicalSwitchPort{{
+		UUID: "new-p1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.1"}, Up: &up, SwitchID: "new-sw1",
+	}}, nil)
+	staging.On("ListACLs", ctx, "new-sw1").Return(acls, nil)
+	staging.On("ListRouterPolicies", ctx, "new-r1").Return([]*models.RouterPolicy{{
+		UUID: "new-pol1", RouterID: "new-r1", Priority: 100, Match: "ip4.src == 10.0.0.0/24", Action: "allow",
+	}}, nil)
+}
+
+func TestBackupService_VerifyRestore(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := NewMockBackupStorage()
+	mockStorage.On("Retrieve", "backup-123").Return(verificationBackup(), nil)
+	mockStorage.On("List").Return([]*BackupMetadata{{ID: "backup-123", Checksum: "abc123"}}, nil)
+	staging := new(MockOVNService)
+	stageVerification(ctx, staging, true)
+
+	service := NewBackupService(new(MockOVNService), mockStorage, zap.NewNop())
+	service.SetVerification(staging, "tcp:staging:6641", "secret")
+
+	report, err := service.VerifyRestore(ctx, "backup-123")
+	require.NoError(t, err)
+	assert.True(t, report.Passed)
+	assert.Equal(t, "nightly", report.BackupName)
+	assert.Equal(t, "abc123", report.BackupChecksum)
+	assert.Equal(t, "tcp:staging:6641", report.Staging)
+	assert.Equal(t, 5, report.Restore.RestoredCount)
+
+	require.Len(t, report.Checks, 5)
+	for _, check := range report.Checks {
+		assert.True(t, check.Passed, check.Kind)
+		assert.Equal(t, 1, check.ActualCount, check.Kind)
+		assert.Equal(t, check.ExpectedChecksum, check.ActualChecksum, check.Kind)
+	}
+	staging.AssertCalled(t, "DeleteLogicalRouter", ctx, "stale-r")
+	staging.AssertCalled(t, "DeleteLogicalSwitch", ctx, "stale-sw")
+
+	// The report is signed with the configured key
+	assert.True(t, VerifyReportSignature(report, []byte("secret")))
+	assert.False(t, VerifyReportSignature(report, []byte("other")))
+	report.Checks[0].ActualCount = 2
+	assert.False(t, VerifyReportSignature(report, []byte("secret")))
+}
+
+func TestBackupService_VerifyRestoreMismatch(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := NewMockBackupStorage()
+	mockStorage.On("Retrieve", "backup-123").Return(verificationBackup(), nil)
+	mockStorage.On("List").Return([]*BackupMetadata{}, nil)
+	staging := new(MockOVNService)
+	stageVerification(ctx, staging, false)
+
+	service := NewBackupService(new(MockOVNService), mockStorage, zap.NewNop())
+	service.SetVerification(staging, "tcp:staging:6641", "secret")
+
+	report, err := service.VerifyRestore(ctx, "backup-123")
+	require.NoError(t, err)
+	assert.False(t, report.Passed)
+	assert.Equal(t, 1, report.Restore.ErrorCount)
+
+	checks := map[string]VerificationCheck{}
+	for _, check := range report.Checks {
+		checks[check.Kind] = check
+	}
+	assert.False(t, checks["acls"].Passed)
+	assert.Equal(t, 1, checks["acls"].ExpectedCount)
+	assert.Equal(t, 0, checks["acls"].ActualCount)
+	assert.True(t, checks["switches"].Passed)
+	assert.True(t, VerifyReportSignature(report, []byte("secret")))
+}
+
+func TestBackupService_VerifyRestoreDisabled(t *testing.T) {
+	service := NewBackupService(new(MockOVNService), NewMockBackupStorage(), zap.NewNop())
+
+	_, err := service.VerifyRestore(context.Background(), "backup-123")
+	assert.ErrorIs(t, err, ErrVerificationDisabled)
+}
+
+func TestVerificationDigests(t *testing.T) {
+	backup := verificationBackup()
+	digests := verificationDigests(backup)
+
+	// Changing a resource's configuration changes its kind's checksum, not
+	// the others'
+	backup.ACLs[0].Action = "drop"
+	changed := verificationDigests(backup)
+	assert.NotEqual(t, digests["acls"].checksum, changed["acls"].checksum)
+	assert.Equal(t, digests["switches"], changed["switches"])
+
+	// Policies are checksummed with their router's name
+	backup = verificationBackup()
+	backup.LogicalRouters[0].Name = "core"
+	changed = verificationDigests(backup)
+	assert.NotEqual(t, digests["router_policies"].checksum, changed["router_policies"].checksum)
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -37,7 +37,8 @@ type RedisCache struct {
 	stats  *CacheStats
 }
 
-// CacheStats tracks cache statistics
+// CacheStats tracks cache statistics. Counters are updated atomically so
+// stats can be read while the cache is in use.
 type CacheStats struct {
 	Hits       int64
 	Misses     int64
@@ -120,22 +121,22 @@ func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) erro
 	
 	val, err := c.client.Get(ctx, fullKey).Result()
 	if err == redis.Nil {
-		c.stats.Misses++
+		atomic.AddInt64(&c.stats.Misses, 1)
 		return ErrCacheMiss
 	}
 	if err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache get error", zap.String("key", key), zap.Error(err))
 		return err
 	}
 
 	if err := json.Unmarshal([]byte(val), dest); err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache unmarshal error", zap.String("key", key), zap.Error(err))
 		return err
 	}
 
-	c.stats.Hits++
+	atomic.AddInt64(&c.stats.Hits, 1)
 	return nil
 }
 
@@ -145,18 +146,18 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl
 	
 	data, err := json.Marshal(value)
 	if err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache marshal error", zap.String("key", key), zap.Error(err))
 		return err
 	}
 
 	if err := c.client.Set(ctx, fullKey, data, ttl).Err(); err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache set error", zap.String("key", key), zap.Error(err))
 		return err
 	}
 
-	c.stats.Sets++
+	atomic.AddInt64(&c.stats.Sets, 1)
 	return nil
 }
 
@@ -172,12 +173,12 @@ func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
 	}
 
 	if err := c.client.Del(ctx, fullKeys...).Err(); err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache delete error", zap.Strings("keys", keys), zap.Error(err))
 		return err
 	}
 
-	c.stats.Deletes += int64(len(keys))
+	atomic.AddInt64(&c.stats.Deletes, int64(len(keys)))
 	return nil
 }
 
@@ -194,7 +195,7 @@ func (c *RedisCache) Exists(ctx context.Context, keys ...string) (int64, error)
 
 	count, err := c.client.Exists(ctx, fullKeys...).Result()
 	if err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache exists error", zap.Strings("keys", keys), zap.Error(err))
 		return 0, err
 	}
@@ -208,7 +209,7 @@ func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error)
 	
 	ttl, err := c.client.TTL(ctx, fullKey).Result()
 	if err != nil {
-		c.stats.Errors++
+		atomic.AddInt64(&c.stats.Errors, 1)
 		c.logger.Error("Cache TTL error", zap.String("key", key), zap.Error(err))
 		return 0, err
 	}
@@ -233,7 +234,7 @@ func (c *RedisCache) Clear(ctx context.Context, pattern string) error {
 		var batch []string
 		batch, cursor, err = c.client.Scan(ctx, cursor, fullPattern, 100).Result()
 		if err != nil {
-			c.stats.Errors++
+			atomic.AddInt64(&c.stats.Errors, 1)
 			c.logger.Error("Cache scan error", zap.String("pattern", pattern), zap.Error(err))
 			return err
 		}
@@ -247,11 +248,11 @@ func (c *RedisCache) Clear(ctx context.Context, pattern string) error {
 
 	if len(keys) > 0 {
 		if err := c.client.Del(ctx, keys...).Err(); err != nil {
-			c.stats.Errors++
+			atomic.AddInt64(&c.stats.Errors, 1)
 			c.logger.Error("Cache clear error", zap.String("pattern", pattern), zap.Error(err))
 			return err
 		}
-		c.stats.Deletes += int64(len(keys))
+		atomic.AddInt64(&c.stats.Deletes, int64(len(keys)))
 	}
 
 	return nil
@@ -264,7 +265,7 @@ func (c *RedisCache) Close() error {
 
 // Stats returns cache statistics
 func (c *RedisCache) Stats() CacheStats {
-	return *c.stats
+	return c.stats.snapshot()
 }
 
 // MemoryCache implements in-memory cache (for development/testing)
@@ -301,7 +302,7 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 	m.mu.RUnlock()
 
 	if !exists {
-		m.stats.Misses++
+		atomic.AddInt64(&m.stats.Misses, 1)
 		return ErrCacheMiss
 	}
 
@@ -309,17 +310,17 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 		m.mu.Lock()
 		delete(m.data, key)
 		m.mu.Unlock()
-		m.stats.Misses++
-		m.stats.Evictions++
+		atomic.AddInt64(&m.stats.Misses, 1)
+		atomic.AddInt64(&m.stats.Evictions, 1)
 		return ErrCacheMiss
 	}
 
 	if err := json.Unmarshal(item.value, dest); err != nil {
-		m.stats.Errors++
+		atomic.AddInt64(&m.stats.Errors, 1)
 		return err
 	}
 
-	m.stats.Hits++
+	atomic.AddInt64(&m.stats.Hits, 1)
 	return nil
 }
 
@@ -327,7 +328,7 @@ func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) err
 func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	data, err := json.Marshal(value)
 	if err != nil {
-		m.stats.Errors++
+		atomic.AddInt64(&m.stats.Errors, 1)
 		return err
 	}
 
@@ -338,7 +339,7 @@ func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 	}
 	m.mu.Unlock()
 
-	m.stats.Sets++
+	atomic.AddInt64(&m.stats.Sets, 1)
 	return nil
 }
 
@@ -350,7 +351,7 @@ func (m *MemoryCache) Delete(ctx context.Context, keys ...string) error {
 	}
 	m.mu.Unlock()
 
-	m.stats.Deletes += int64(len(keys))
+	atomic.AddInt64(&m.stats.Deletes, int64(len(keys)))
 	return nil
 }
 
@@ -394,10 +395,9 @@ func (m *MemoryCache) Clear(ctx context.Context, pattern string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Simple pattern matching (just prefix for now)
 	keysToDelete := []string{}
 	for key := range m.data {
-		if strings.HasPrefix(key, pattern) {
+		if matchPattern(pattern, key) {
 			keysToDelete = append(keysToDelete, key)
 		}
 	}
@@ -406,7 +406,7 @@ func (m *MemoryCache) Clear(ctx context.Context, pattern string) error {
 		delete(m.data, key)
 	}
 
-	m.stats.Deletes += int64(len(keysToDelete))
+	atomic.AddInt64(&m.stats.Deletes, int64(len(keysToDelete)))
 	return nil
 }
 
@@ -426,7 +426,7 @@ func (m *MemoryCache) cleanup() {
 		for key, item := range m.data {
 			if now.After(item.expiresAt) {
 				delete(m.data, key)
-				m.stats.Evictions++
+				atomic.AddInt64(&m.stats.Evictions, 1)
 			}
 		}
 		m.mu.Unlock()
@@ -435,5 +435,17 @@ func (m *MemoryCache) cleanup() {
 
 // Stats returns cache statistics
 func (m *MemoryCache) Stats() CacheStats {
-	return *m.stats
+	return m.stats.snapshot()
+}
+
+// snapshot reads the counters atomically
+func (s *CacheStats) snapshot() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadInt64(&s.Hits),
+		Misses:    atomic.LoadInt64(&s.Misses),
+		Sets:      atomic.LoadInt64(&s.Sets),
+		Deletes:   atomic.LoadInt64(&s.Deletes),
+		Errors:    atomic.LoadInt64(&s.Errors),
+		Evictions: atomic.LoadInt64(&s.Evictions),
+	}
 }
\ No newline at end of file
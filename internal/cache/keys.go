@@ -158,6 +158,16 @@ func ACLPattern() string {
 	return PrefixACL + "*"
 }
 
+// LoadBalancerPattern returns pattern to match all load balancer keys
+func LoadBalancerPattern() string {
+	return PrefixLoadBalancer + "*"
+}
+
+// NATPattern returns pattern to match all NAT keys
+func NATPattern() string {
+	return PrefixNAT + "*"
+}
+
 // TopologyPattern returns pattern to match topology keys
 func TopologyPattern() string {
 	return PrefixTopology + "*"
@@ -170,6 +180,13 @@ type CacheKeyInfo struct {
 	Invalidates []string // Patterns to invalidate when this key changes
 }
 
+// SoftTTL returns the age past which a cached value is refreshed in the
+// background. The value is still served until its TTL expires, so readers
+// don't wait on the refresh.
+func (i CacheKeyInfo) SoftTTL() time.Duration {
+	return i.TTL / 2
+}
+
 // GetCacheKeyInfo returns caching information for different resource types
 func GetCacheKeyInfo(resourceType string, operation string) CacheKeyInfo {
 	switch resourceType {
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// Cache types selectable with CACHE_TYPE
+const (
+	TypeNone   = "none"
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+	TypeTiered = "tiered"
+)
+
+// New creates the cache selected by cfg, or nil when caching is disabled
+func New(cfg *config.CacheConfig, logger *zap.Logger) (Cache, error) {
+	switch cfg.Type {
+	case "", TypeNone:
+		return nil, nil
+	case TypeMemory:
+		return NewMemoryCache(logger), nil
+	case TypeRedis, TypeTiered:
+		redisCfg := DefaultRedisConfig()
+		redisCfg.Addr = cfg.RedisAddr
+		redisCfg.Password = cfg.RedisPassword
+		redisCfg.DB = cfg.RedisDB
+		if cfg.KeyPrefix != "" {
+			redisCfg.KeyPrefix = cfg.KeyPrefix
+		}
+		l2, err := NewRedisCache(redisCfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Type == TypeRedis {
+			return l2, nil
+		}
+		return NewTieredCache(l2, &TieredConfig{
+			L1MaxEntries: cfg.L1MaxEntries,
+			L1TTL:        cfg.L1TTL,
+			L2TTL:        cfg.L2TTL,
+		}, logger), nil
+	}
+	return nil, fmt.Errorf("unknown cache type %q", cfg.Type)
+}
+
+// RedisClient returns the Redis client backing c, or nil when c doesn't use
+// Redis
+func RedisClient(c Cache) *redis.Client {
+	switch c := c.(type) {
+	case *RedisCache:
+		return c.client
+	case *TieredCache:
+		return RedisClient(c.l2)
+	}
+	return nil
+}
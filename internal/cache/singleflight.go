@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrLoadPanicked is returned to callers sharing a load that panicked
+var ErrLoadPanicked = errors.New("cache: load panicked")
+
+// Group coalesces concurrent loads of the same key into a single call, so a
+// burst of cache misses for a key reaches the backend once. The zero value
+// is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a load in flight
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+	dups int
+}
+
+// Do calls fn and returns its result. Callers asking for a key while a load
+// of it is in flight wait for that load and share its result instead of
+// calling fn. shared reports whether the result went to more than one
+// caller.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		<-c.done
+		return c.val, c.err, true
+	}
+	c := &call{done: make(chan struct{}), err: ErrLoadPanicked}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		shared = c.dups > 0
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var g Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	shared := make([]bool, 5)
+	for i := range shared {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err, s := g.Do("key", func() (interface{}, error) {
+				calls.Add(1)
+				<-release
+				return "value", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "value", v)
+			shared[i] = s
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []bool{true, true, true, true, true}, shared)
+
+	// Once a load finishes, the next one calls fn again
+	_, err, s := g.Do("key", func() (interface{}, error) { return nil, errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+	assert.False(t, s)
+}
+
+func TestGroup_DoPanic(t *testing.T) {
+	var g Group
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		g.Do("key", func() (interface{}, error) {
+			close(entered)
+			<-release
+			panic("load failed")
+		})
+	}()
+	<-entered
+
+	result := make(chan error)
+	go func() {
+		_, err, _ := g.Do("key", func() (interface{}, error) { return nil, nil })
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	assert.ErrorIs(t, <-result, ErrLoadPanicked)
+}
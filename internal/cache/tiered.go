@@ -0,0 +1,315 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TieredConfig configures a TieredCache
+type TieredConfig struct {
+	L1MaxEntries int           // Entries kept in process, least recently used evicted first
+	L1TTL        time.Duration // Upper bound for an entry's TTL in process, 0 keeps the caller's TTL
+	L2TTL        time.Duration // Upper bound for an entry's TTL in the shared cache, 0 keeps the caller's TTL
+}
+
+// DefaultTieredConfig returns default tiered cache configuration. Entries
+// live briefly in process since writes made by other replicas only reach
+// them through expiry.
+func DefaultTieredConfig() *TieredConfig {
+	return &TieredConfig{
+		L1MaxEntries: 1024,
+		L1TTL:        5 * time.Second,
+	}
+}
+
+// TieredCache implements Cache with a small in-process LRU (L1) in front of
+// a shared cache such as Redis (L2). Writes go through to both layers and
+// L2 hits are copied into L1.
+type TieredCache struct {
+	l1     *lruCache
+	l2     Cache
+	cfg    TieredConfig
+	mu     sync.RWMutex // Guards cfg's TTLs, which SetTTLs changes
+	logger *zap.Logger
+	stats  *CacheStats
+}
+
+// NewTieredCache creates a tiered cache in front of l2
+func NewTieredCache(l2 Cache, cfg *TieredConfig, logger *zap.Logger) *TieredCache {
+	if cfg == nil {
+		cfg = DefaultTieredConfig()
+	}
+	c := &TieredCache{
+		l2:     l2,
+		cfg:    *cfg,
+		logger: logger,
+		stats:  &CacheStats{},
+	}
+	c.l1 = newLRUCache(cfg.L1MaxEntries, c.stats)
+	return c
+}
+
+// Get retrieves a value from L1, falling back to L2
+func (c *TieredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if data, ok := c.l1.get(key); ok {
+		if err := json.Unmarshal(data, dest); err != nil {
+			atomic.AddInt64(&c.stats.Errors, 1)
+			return err
+		}
+		atomic.AddInt64(&c.stats.Hits, 1)
+		return nil
+	}
+
+	var data json.RawMessage
+	if err := c.l2.Get(ctx, key, &data); err != nil {
+		if errors.Is(err, ErrCacheMiss) {
+			atomic.AddInt64(&c.stats.Misses, 1)
+		} else {
+			atomic.AddInt64(&c.stats.Errors, 1)
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		atomic.AddInt64(&c.stats.Errors, 1)
+		return err
+	}
+
+	// L1 mustn't keep the entry longer than L2 does. Without a known TTL
+	// it's kept only when L1 has its own.
+	ttl, err := c.l2.TTL(ctx, key)
+	if err != nil {
+		ttl = 0
+	}
+	l1TTL, _ := c.ttls()
+	if ttl > 0 || l1TTL > 0 {
+		c.l1.set(key, data, capTTL(ttl, l1TTL))
+	}
+	atomic.AddInt64(&c.stats.Hits, 1)
+	return nil
+}
+
+// Set stores a value in both layers
+func (c *TieredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		atomic.AddInt64(&c.stats.Errors, 1)
+		return err
+	}
+
+	l1TTL, l2TTL := c.ttls()
+	if err := c.l2.Set(ctx, key, json.RawMessage(data), capTTL(ttl, l2TTL)); err != nil {
+		// Don't keep in process what other replicas can't see
+		c.l1.delete(key)
+		atomic.AddInt64(&c.stats.Errors, 1)
+		return err
+	}
+	c.l1.set(key, data, capTTL(ttl, l1TTL))
+
+	atomic.AddInt64(&c.stats.Sets, 1)
+	return nil
+}
+
+// Delete removes values from both layers
+func (c *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.l1.delete(key)
+	}
+	atomic.AddInt64(&c.stats.Deletes, int64(len(keys)))
+	return c.l2.Delete(ctx, keys...)
+}
+
+// Exists checks if keys exist in either layer
+func (c *TieredCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	var count int64
+	var missing []string
+	for _, key := range keys {
+		if _, ok := c.l1.get(key); ok {
+			count++
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return count, nil
+	}
+
+	n, err := c.l2.Exists(ctx, missing...)
+	if err != nil {
+		return 0, err
+	}
+	return count + n, nil
+}
+
+// TTL returns the time-to-live of a key in L2, which outlives L1
+func (c *TieredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.l2.TTL(ctx, key)
+}
+
+// Clear removes all keys matching a pattern from both layers
+func (c *TieredCache) Clear(ctx context.Context, pattern string) error {
+	atomic.AddInt64(&c.stats.Deletes, int64(c.l1.clear(pattern)))
+	return c.l2.Clear(ctx, pattern)
+}
+
+// Close closes L2
+func (c *TieredCache) Close() error {
+	return c.l2.Close()
+}
+
+// SetTTLs changes the upper bounds of the TTLs of entries set from now on;
+// 0 keeps the caller's TTL
+func (c *TieredCache) SetTTLs(l1TTL, l2TTL time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg.L1TTL, c.cfg.L2TTL = l1TTL, l2TTL
+}
+
+func (c *TieredCache) ttls() (l1TTL, l2TTL time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg.L1TTL, c.cfg.L2TTL
+}
+
+// Stats returns cache statistics. Hits count lookups served by either
+// layer and evictions count entries evicted from L1.
+func (c *TieredCache) Stats() CacheStats {
+	return c.stats.snapshot()
+}
+
+// capTTL bounds ttl by limit unless limit is 0. A ttl of 0 means the entry
+// doesn't expire, so any limit applies.
+func capTTL(ttl, limit time.Duration) time.Duration {
+	if limit > 0 && (ttl <= 0 || ttl > limit) {
+		return limit
+	}
+	return ttl
+}
+
+// lruCache is a bounded in-process store of encoded values
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Front is most recently used
+	items      map[string]*list.Element
+	stats      *CacheStats
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Zero when the entry doesn't expire
+}
+
+func newLRUCache(maxEntries int, stats *CacheStats) *lruCache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		stats:      stats,
+	}
+}
+
+func (l *lruCache) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		l.remove(elem)
+		atomic.AddInt64(&l.stats.Evictions, 1)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *lruCache) set(key string, value []byte, ttl time.Duration) {
+	if l.maxEntries <= 0 {
+		return
+	}
+
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		elem.Value = entry
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[key] = l.order.PushFront(entry)
+
+	for l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+		atomic.AddInt64(&l.stats.Evictions, 1)
+	}
+}
+
+func (l *lruCache) delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.remove(elem)
+	}
+}
+
+// clear removes the keys matching a pattern and returns how many it removed.
+// Like the Redis patterns used for invalidation, "*" matches any run of
+// characters.
+func (l *lruCache) clear(pattern string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	removed := 0
+	for key, elem := range l.items {
+		if matchPattern(pattern, key) {
+			l.remove(elem)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (l *lruCache) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*lruEntry).key)
+}
+
+// matchPattern matches key against a glob pattern where "*" matches any run
+// of characters
+func matchPattern(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == key
+	}
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return strings.HasSuffix(key, last)
+}
@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// countingCache counts the lookups reaching the cache it wraps
+type countingCache struct {
+	Cache
+	gets int
+}
+
+func (c *countingCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.gets++
+	return c.Cache.Get(ctx, key, dest)
+}
+
+func newTestTieredCache(cfg *TieredConfig) (*TieredCache, *countingCache) {
+	l2 := &countingCache{Cache: NewMemoryCache(zap.NewNop())}
+	return NewTieredCache(l2, cfg, zap.NewNop()), l2
+}
+
+func TestTieredCache_WriteThrough(t *testing.T) {
+	ctx := context.Background()
+	c, l2 := newTestTieredCache(DefaultTieredConfig())
+
+	require.NoError(t, c.Set(ctx, "switch:ls1", map[string]string{"name": "web"}, time.Minute))
+
+	var got map[string]string
+	require.NoError(t, c.Get(ctx, "switch:ls1", &got))
+	assert.Equal(t, "web", got["name"])
+	assert.Equal(t, 0, l2.gets, "L1 serves the read")
+
+	// The value reached L2 for other replicas
+	var shared map[string]string
+	require.NoError(t, l2.Cache.Get(ctx, "switch:ls1", &shared))
+	assert.Equal(t, got, shared)
+}
+
+func TestTieredCache_FillsL1FromL2(t *testing.T) {
+	ctx := context.Background()
+	c, l2 := newTestTieredCache(DefaultTieredConfig())
+
+	// Written by another replica
+	require.NoError(t, l2.Set(ctx, "router:lr1", "edge", time.Minute))
+
+	var got string
+	require.NoError(t, c.Get(ctx, "router:lr1", &got))
+	assert.Equal(t, "edge", got)
+	require.NoError(t, c.Get(ctx, "router:lr1", &got))
+	assert.Equal(t, 1, l2.gets, "the second read is served by L1")
+
+	assert.ErrorIs(t, c.Get(ctx, "router:missing", &got), ErrCacheMiss)
+	stats := c.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+}
+
+func TestTieredCache_LayerTTLs(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestTieredCache(&TieredConfig{L1MaxEntries: 10, L1TTL: 20 * time.Millisecond, L2TTL: time.Minute})
+
+	require.NoError(t, c.Set(ctx, "topology:full", "v1", time.Hour))
+	ttl, err := c.TTL(ctx, "topology:full")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ttl, time.Minute, "L2 caps the TTL")
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok := c.l1.get("topology:full")
+	assert.False(t, ok, "the entry expired from L1")
+
+	var got string
+	require.NoError(t, c.Get(ctx, "topology:full", &got), "L2 still serves it")
+	assert.Equal(t, "v1", got)
+}
+
+func TestTieredCache_SetTTLs(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestTieredCache(&TieredConfig{L1MaxEntries: 10, L1TTL: time.Hour})
+
+	c.SetTTLs(0, 30*time.Second)
+	require.NoError(t, c.Set(ctx, "switches:all", "v1", time.Hour))
+	ttl, err := c.TTL(ctx, "switches:all")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ttl, 30*time.Second, "the new L2 cap applies")
+
+	require.NoError(t, c.Set(ctx, "routers:all", "v1", 0))
+	ttl, err = c.TTL(ctx, "routers:all")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ttl, 30*time.Second, "entries without a TTL are capped too")
+}
+
+func TestTieredCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestTieredCache(&TieredConfig{L1MaxEntries: 2})
+
+	require.NoError(t, c.Set(ctx, "a", 1, time.Minute))
+	require.NoError(t, c.Set(ctx, "b", 2, time.Minute))
+	var v int
+	require.NoError(t, c.Get(ctx, "a", &v))
+	require.NoError(t, c.Set(ctx, "c", 3, time.Minute))
+
+	_, ok := c.l1.get("b")
+	assert.False(t, ok, "b was least recently used")
+	_, ok = c.l1.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), c.Stats().Evictions)
+}
+
+func TestTieredCache_ClearBothLayers(t *testing.T) {
+	ctx := context.Background()
+	c, l2 := newTestTieredCache(DefaultTieredConfig())
+
+	require.NoError(t, c.Set(ctx, "port:list:switch:ls1", []string{"p1"}, time.Minute))
+	require.NoError(t, c.Set(ctx, "port:p1", "p1", time.Minute))
+	require.NoError(t, c.Set(ctx, "switch:ls1", "ls1", time.Minute))
+
+	require.NoError(t, c.Clear(ctx, PortsByParentPattern("ls1")))
+	n, err := c.Exists(ctx, "port:list:switch:ls1", "port:p1", "switch:ls1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	require.NoError(t, c.Clear(ctx, SwitchPattern()))
+	n, err = l2.Exists(ctx, "switch:ls1")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	require.NoError(t, c.Delete(ctx, "port:p1"))
+	var v string
+	assert.ErrorIs(t, c.Get(ctx, "port:p1", &v), ErrCacheMiss)
+}
+
+func TestMatchPattern(t *testing.T) {
+	assert.True(t, matchPattern("switch:*", "switch:ls1"))
+	assert.True(t, matchPattern("port:list:*:ls1", "port:list:switch:ls1"))
+	assert.False(t, matchPattern("port:list:*:ls1", "port:list:switch:ls12"))
+	assert.True(t, matchPattern("switch:ls1", "switch:ls1"))
+	assert.False(t, matchPattern("switch:ls1", "switch:ls10"))
+	assert.False(t, matchPattern("a*b*b", "ab"))
+}
+
+func TestNew(t *testing.T) {
+	c, err := New(&config.CacheConfig{Type: TypeNone}, zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = New(&config.CacheConfig{Type: TypeMemory}, zap.NewNop())
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryCache{}, c)
+	assert.Nil(t, RedisClient(c))
+
+	_, err = New(&config.CacheConfig{Type: "memcached"}, zap.NewNop())
+	assert.Error(t, err)
+}
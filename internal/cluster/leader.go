@@ -0,0 +1,342 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// LeaderLockKey is the Postgres advisory lock key of the leader lock
+const LeaderLockKey int64 = 0x6f766e6370 // "ovncp"
+
+// LeaderLock is held by at most one replica at a time
+type LeaderLock interface {
+	// TryAcquire takes the lock if it's free, or keeps it if this replica
+	// holds it, and reports whether this replica holds it
+	TryAcquire(ctx context.Context) (bool, error)
+	// Holder returns the node ID of the replica holding the lock, "" when
+	// it's free
+	Holder(ctx context.Context) (string, error)
+	// Release frees the lock if this replica holds it
+	Release(ctx context.Context) error
+}
+
+// PostgresLeaderLock is a Postgres session advisory lock. It's held on a
+// connection of its own, so it's released by Postgres as soon as the
+// replica holding it goes away.
+type PostgresLeaderLock struct {
+	db     *sql.DB
+	key    int64
+	nodeID string
+
+	mu   sync.Mutex
+	conn *sql.Conn // Holding the lock
+}
+
+// NewPostgresLeaderLock creates a lock on the advisory lock key of db,
+// taken by the replica nodeID
+func NewPostgresLeaderLock(db *sql.DB, key int64, nodeID string) *PostgresLeaderLock {
+	return &PostgresLeaderLock{db: db, key: key, nodeID: nodeID}
+}
+
+// TryAcquire takes the advisory lock, or checks that the connection
+// holding it is still alive
+func (l *PostgresLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err != nil {
+			l.conn.Close()
+			l.conn = nil
+			return false, fmt.Errorf("lost the connection holding the leader lock: %w", err)
+		}
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to take the leader lock: %w", err)
+	}
+	// The holder is found by the application name of its connection
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", "ovncp:"+l.nodeID); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to take the leader lock: %w", err)
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to take the leader lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Holder returns the node ID of the connection holding the advisory lock
+func (l *PostgresLeaderLock) Holder(ctx context.Context) (string, error) {
+	// pg_locks splits bigint keys into their high and low 32 bits
+	var name string
+	err := l.db.QueryRowContext(ctx, `
+		SELECT a.application_name FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.classid = $1 AND l.objid = $2 AND l.objsubid = 1 AND l.granted`,
+		uint32(uint64(l.key)>>32), uint32(l.key)).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the leader: %w", err)
+	}
+	return strings.TrimPrefix(name, "ovncp:"), nil
+}
+
+// Release unlocks the advisory lock and closes its connection
+func (l *PostgresLeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	l.conn = nil
+	if err != nil {
+		return fmt.Errorf("failed to release the leader lock: %w", err)
+	}
+	return nil
+}
+
+// RedisLeaderLock is a Redis key expiring after a lease unless renewed by
+// the replica holding it
+type RedisLeaderLock struct {
+	lock *DistributedLock
+
+	mu   sync.Mutex
+	held bool
+}
+
+// NewRedisLeaderLock creates a lock on key, taken by the replica nodeID
+// for lease at a time
+func NewRedisLeaderLock(client *redis.Client, key, nodeID string, lease time.Duration, logger *zap.Logger) *RedisLeaderLock {
+	lock := NewDistributedLock(client, key, &LockOptions{TTL: lease}, logger)
+	// Node IDs may be shared by mistake, so the value stays unique
+	lock.value = nodeID + "/" + uuid.New().String()
+	return &RedisLeaderLock{lock: lock}
+}
+
+// TryAcquire renews the lease if this replica holds the lock, and takes
+// the lock otherwise
+func (l *RedisLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held {
+		err := l.lock.Extend(ctx, l.lock.ttl)
+		if err == nil {
+			return true, nil
+		}
+		l.held = false
+		if !errors.Is(err, ErrLockNotHeld) {
+			return false, err
+		}
+	}
+
+	err := l.lock.Acquire(ctx)
+	if errors.Is(err, ErrLockAcquireFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.held = true
+	return true, nil
+}
+
+// Holder returns the node ID stored in the lock's key
+func (l *RedisLeaderLock) Holder(ctx context.Context) (string, error) {
+	value, err := l.lock.redis.Get(ctx, l.lock.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the leader: %w", err)
+	}
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		value = value[:i]
+	}
+	return value, nil
+}
+
+// Release deletes the lock's key if this replica holds it
+func (l *RedisLeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held {
+		return nil
+	}
+	l.held = false
+	if err := l.lock.Release(ctx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+		return err
+	}
+	return nil
+}
+
+// LocalLeaderLock is always held, for a single replica
+type LocalLeaderLock struct {
+	nodeID string
+}
+
+// NewLocalLeaderLock creates a lock held by the replica nodeID
+func NewLocalLeaderLock(nodeID string) *LocalLeaderLock {
+	return &LocalLeaderLock{nodeID: nodeID}
+}
+
+func (l *LocalLeaderLock) TryAcquire(ctx context.Context) (bool, error) { return true, nil }
+func (l *LocalLeaderLock) Holder(ctx context.Context) (string, error)   { return l.nodeID, nil }
+func (l *LocalLeaderLock) Release(ctx context.Context) error            { return nil }
+
+// LeaderStatus is a replica's view of the leader election
+type LeaderStatus struct {
+	NodeID  string `json:"node_id"`
+	Backend string `json:"backend"`
+	// Leader reports whether this replica is the leader, running the
+	// background jobs
+	Leader      bool       `json:"leader"`
+	LeaderID    string     `json:"leader_id,omitempty"`
+	LeaderSince *time.Time `json:"leader_since,omitempty"` // When this replica became the leader
+}
+
+// LeaderElector runs background jobs on one replica at a time. Every
+// interval, each replica tries to take the leader lock, and the one
+// holding it renews it; a replica that gets the lock starts the jobs, and
+// stops them when it loses it, so another replica takes over when the
+// leader goes away.
+type LeaderElector struct {
+	lock     LeaderLock
+	backend  string
+	nodeID   string
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu    sync.RWMutex
+	since time.Time // When this replica became the leader, zero when it isn't
+}
+
+// NewLeaderElector creates an elector taking lock, named backend in its
+// status, for the replica nodeID every interval
+func NewLeaderElector(lock LeaderLock, backend, nodeID string, interval time.Duration, logger *zap.Logger) *LeaderElector {
+	return &LeaderElector{
+		lock:     lock,
+		backend:  backend,
+		nodeID:   nodeID,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// IsLeader reports whether this replica is the leader
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return !e.since.IsZero()
+}
+
+// Status returns the state of the election as this replica sees it
+func (e *LeaderElector) Status(ctx context.Context) (*LeaderStatus, error) {
+	status := &LeaderStatus{NodeID: e.nodeID, Backend: e.backend}
+	e.mu.RLock()
+	if !e.since.IsZero() {
+		since := e.since
+		status.Leader, status.LeaderSince, status.LeaderID = true, &since, e.nodeID
+	}
+	e.mu.RUnlock()
+
+	if !status.Leader {
+		holder, err := e.lock.Holder(ctx)
+		if err != nil {
+			return nil, err
+		}
+		status.LeaderID = holder
+	}
+	return status, nil
+}
+
+// Run takes part in the election until ctx is done, running jobs while
+// this replica is the leader. jobs must return once its context is done.
+func (e *LeaderElector) Run(ctx context.Context, jobs func(ctx context.Context)) {
+	var stop context.CancelFunc
+	var stopped chan struct{}
+	stepDown := func() {
+		if stop == nil {
+			return
+		}
+		stop()
+		<-stopped
+		stop = nil
+		e.mu.Lock()
+		e.since = time.Time{}
+		e.mu.Unlock()
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		held, err := e.lock.TryAcquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("Failed to take the leader lock", zap.Error(err))
+		}
+
+		switch {
+		case held && stop == nil:
+			e.logger.Info("Became the leader, starting background jobs", zap.String("node_id", e.nodeID))
+			e.mu.Lock()
+			e.since = e.now().UTC()
+			e.mu.Unlock()
+			stop, stopped = startJobs(ctx, jobs)
+		case !held && stop != nil:
+			e.logger.Warn("Lost leadership, stopping background jobs", zap.String("node_id", e.nodeID))
+			stepDown()
+		}
+
+		select {
+		case <-ctx.Done():
+			stepDown()
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := e.lock.Release(releaseCtx); err != nil {
+				e.logger.Warn("Failed to release the leader lock", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startJobs runs jobs until stop is called, closing stopped once they
+// return
+func startJobs(ctx context.Context, jobs func(ctx context.Context)) (stop context.CancelFunc, stopped chan struct{}) {
+	ctx, stop = context.WithCancel(ctx)
+	stopped = make(chan struct{})
+	go func() {
+		defer close(stopped)
+		jobs(ctx)
+	}()
+	return stop, stopped
+}
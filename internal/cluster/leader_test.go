@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeLeaderLock is held while held is set
+type fakeLeaderLock struct {
+	mu       sync.Mutex
+	held     bool
+	err      error
+	released bool
+}
+
+func (l *fakeLeaderLock) set(held bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held, l.err = held, err
+}
+
+func (l *fakeLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held, l.err
+}
+
+func (l *fakeLeaderLock) Holder(ctx context.Context) (string, error) {
+	return "node-b", nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	return nil
+}
+
+func TestLeaderElector_Run(t *testing.T) {
+	lock := &fakeLeaderLock{}
+	elector := NewLeaderElector(lock, "redis", "node-a", 5*time.Millisecond, zap.NewNop())
+
+	var running, started atomic.Int32
+	jobs := func(ctx context.Context) {
+		started.Add(1)
+		running.Add(1)
+		defer running.Add(-1)
+		<-ctx.Done()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		elector.Run(ctx, jobs)
+		close(done)
+	}()
+
+	// A follower runs no jobs and reports the leader
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, elector.IsLeader())
+	assert.Zero(t, started.Load())
+	status, err := elector.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &LeaderStatus{NodeID: "node-a", Backend: "redis", LeaderID: "node-b"}, status)
+
+	// Taking the lock starts the jobs once
+	lock.set(true, nil)
+	require.Eventually(t, elector.IsLeader, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), started.Load())
+	status, err = elector.Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Leader)
+	assert.Equal(t, "node-a", status.LeaderID)
+	assert.NotNil(t, status.LeaderSince)
+
+	// Losing it stops them
+	lock.set(false, errors.New("connection refused"))
+	require.Eventually(t, func() bool { return !elector.IsLeader() && running.Load() == 0 }, time.Second, time.Millisecond)
+
+	// Failing over back starts them again, and stopping releases the lock
+	lock.set(true, nil)
+	require.Eventually(t, func() bool { return started.Load() == 2 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+	assert.Zero(t, running.Load())
+	assert.True(t, lock.released)
+}
+
+func TestPostgresLeaderLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	lock := NewPostgresLeaderLock(db, LeaderLockKey, "node-a")
+	ctx := context.Background()
+
+	// Another replica holds the lock
+	mock.ExpectExec("SELECT set_config").WithArgs("ovncp:node-a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(LeaderLockKey).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+	held, err := lock.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	mock.ExpectQuery("SELECT a.application_name FROM pg_locks").WithArgs(0x6f, 0x766e6370).
+		WillReturnRows(sqlmock.NewRows([]string{"application_name"}).AddRow("ovncp:node-b"))
+	holder, err := lock.Holder(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", holder)
+
+	// Taking the lock keeps its connection, which is checked afterwards
+	mock.ExpectExec("SELECT set_config").WithArgs("ovncp:node-a").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WithArgs(LeaderLockKey).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	held, err = lock.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = lock.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(LeaderLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, lock.Release(ctx))
+	require.NoError(t, lock.Release(ctx))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
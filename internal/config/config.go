@@ -2,19 +2,49 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
 	API         APIConfig
 	OVN         OVNConfig
+	OVNClusters []OVNConfig // Additional named OVN deployments, e.g. one per region
 	Database    DatabaseConfig
 	Auth        AuthConfig
 	Security    SecurityConfig
+	Tenancy     TenancyConfig
+	Metering    MeteringConfig
+	Cache       CacheConfig
+	Topology    TopologyConfig
+	ACLStats    ACLStatsConfig
+	ACLLogs     ACLLogsConfig
+	ACLRollouts ACLRolloutsConfig
+	ACLSchedules ACLSchedulesConfig
+	AccessGrants AccessGrantsConfig
+	Compliance  ComplianceConfig
+	DR          DRConfig
+	Drift       DriftConfig
+	Webhooks    WebhooksConfig
+	Notifications NotificationsConfig
+	Gateways    GatewaysConfig
+	Probes      ProbesConfig
+	VPN         VPNConfig
+	Trash       TrashConfig
+	MACPools    []MACPoolConfig // Ranges MACs of ports created with "auto" addresses are taken from
+	FloatingIPPools []FloatingIPPoolConfig // External address ranges floating IPs are allocated from
 	Log         LogConfig
+	Reload      ReloadConfig
+	Secrets     SecretsConfig
+	Leader      LeaderConfig
+	Locks       LocksConfig
 	Environment string
+
+	settings map[string]Setting // Variables read to load the configuration
 }
 
 type APIConfig struct {
@@ -22,14 +52,52 @@ type APIConfig struct {
 	Host         string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// TrustedProxies are the proxies whose X-Forwarded-For header is
+	// trusted for the client address; none are trusted by default
+	TrustedProxies []string
+	// IdempotencyTTL is how long responses to POSTs carrying an
+	// Idempotency-Key are kept to be replayed to retries
+	IdempotencyTTL time.Duration
+	// V1Deprecated and V1Sunset are the dates, as YYYY-MM-DD, /api/v1 was
+	// deprecated and may be removed, announced in its responses; empty
+	// while v1 is supported
+	V1Deprecated string
+	V1Sunset     string
 }
 
+// OVN backends
+const (
+	OVNBackendOVSDB   = "ovsdb"   // A real OVN deployment, reached over OVSDB
+	OVNBackendSandbox = "sandbox" // An in-memory OVN kept in the database, for demos and CI
+)
+
 type OVNConfig struct {
-	NorthboundDB   string
-	SouthboundDB   string
-	Timeout        time.Duration
-	MaxRetries     int
-	MaxConnections int
+	ClusterName       string // Name used to select this deployment in the API
+	Backend           string // OVNBackendOVSDB or OVNBackendSandbox
+	SandboxSeed       bool   // Start an empty sandbox with a demo topology
+	NorthboundDB      string
+	SouthboundDB      string
+	Timeout           time.Duration
+	MaxRetries        int
+	MaxConnections    int
+	ReconnectInterval time.Duration // Initial delay before reconnecting, doubled on each failure
+	ReconnectMaxDelay time.Duration // Upper bound for the reconnect delay
+	InactivityProbe   time.Duration // Echo probe interval used to detect dead connections, 0 disables
+	TxMaxAttempts     int           // Attempts of transactions failing with retryable errors, 1 disables retries
+	TxRetryBackoff    time.Duration // Delay before retrying a transaction, doubled for each next retry
+	TxRetryMaxDelay   time.Duration // Upper bound for the transaction retry delay
+	TLS               OVNTLSConfig
+}
+
+// OVNTLSConfig configures TLS for ssl: OVSDB endpoints
+type OVNTLSConfig struct {
+	Enabled            bool
+	CAFile             string // PEM bundle used to verify the OVSDB server
+	CertFile           string // Client certificate for mutual TLS
+	KeyFile            string // Client private key for mutual TLS
+	ServerName         string // SNI and verification name, defaults to the endpoint host
+	InsecureSkipVerify bool
+	MinVersion         string // "1.2" or "1.3"
 }
 
 type DatabaseConfig struct {
@@ -43,7 +111,26 @@ type DatabaseConfig struct {
 	SSLMode         string
 	MaxOpenConns    int
 	MaxIdleConns    int
-	ConnMaxLifetime time.Duration
+	ConnMaxLifetime time.Duration // How long a connection is reused before it's closed
+	ConnMaxIdleTime time.Duration // How long a connection may stay idle before it's closed
+	// AutoMigrate applies pending migrations on startup. When false, they're
+	// applied with `ovncp-api migrate up`, and the API refuses to start until
+	// they are.
+	AutoMigrate bool
+	// ReplicaHost and ReplicaPort locate a read replica of the Postgres
+	// database, which serves usage history, audit and report queries; the
+	// other settings are the primary's. Empty disables the replica.
+	ReplicaHost string
+	ReplicaPort string // Port by default
+}
+
+// IsPostgres reports whether the database is Postgres rather than SQLite
+func (d *DatabaseConfig) IsPostgres() bool {
+	switch d.Type {
+	case "sqlite", "sqlite3", "memory":
+		return false
+	}
+	return true
 }
 
 type AuthConfig struct {
@@ -76,6 +163,195 @@ type SecurityConfig struct {
 	HSTSMaxAge int
 }
 
+// TenancyConfig configures multi-tenancy
+type TenancyConfig struct {
+	UsageSnapshotInterval time.Duration // How often tenants' usage is recorded for history, 0 disables
+	ReclaimInterval       time.Duration // How often the leader looks for tenant deletions to carry out
+}
+
+// TopologyConfig configures the topology snapshots diffs are computed from
+type TopologyConfig struct {
+	SnapshotInterval  time.Duration // How often each cluster's topology is recorded, 0 disables
+	SnapshotRetention time.Duration // How long snapshots are kept
+	// OVSDBEndpoints are the Open_vSwitch databases of the chassis whose
+	// interface statistics are overlaid on the topology; none disables it
+	OVSDBEndpoints      []string
+	TrafficPollInterval time.Duration // How often interface statistics are read
+	// Generated graphs are reused while the topology, traffic and options
+	// are unchanged, for up to GraphCacheTTL; 0 disables it
+	GraphCacheTTL  time.Duration
+	GraphCacheSize int // Maximum number of graphs kept
+}
+
+// ACLStatsConfig configures the collection of ACL hit counters, attributed
+// from OpenFlow flow counters through the southbound logical flows
+type ACLStatsConfig struct {
+	// FlowDumpCommand prints flows in ovs-ofctl dump-flows format, e.g.
+	// "ovs-ofctl dump-flows br-int"; none disables collection
+	FlowDumpCommand []string
+	Interval        time.Duration // How often counters are recorded
+	Retention       time.Duration // How long samples are kept
+}
+
+// ACLLogsConfig configures the ingestion of the logs ovn-controller writes
+// for ACLs with logging enabled
+type ACLLogsConfig struct {
+	// File is an ovn-controller log file followed for ACL logs, e.g.
+	// /var/log/ovn/ovn-controller.log
+	File         string
+	PollInterval time.Duration // How often the file is checked for new lines
+	// SyslogAddr is a UDP address ovn-controllers send syslog messages to,
+	// e.g. ":5514"; with neither it nor File, logs aren't ingested
+	SyslogAddr string
+	Retention  time.Duration // How long entries are kept
+}
+
+// ComplianceConfig configures the evaluation of the configuration against
+// compliance rule packs
+type ComplianceConfig struct {
+	Interval time.Duration // How often rules are evaluated, 0 only on request
+	Packs    []string      // Names of the enabled packs
+	// RulesFile is a JSON file of packs added to the built-in ones
+	RulesFile     string
+	WebhookURL    string // URL alerts are POSTed to as JSON
+	WebhookSecret string // Key for the webhook's HMAC-SHA256 signature
+}
+
+// DRConfig configures disaster-recovery verification, which restores
+// backups into a staging OVN deployment kept for the purpose
+type DRConfig struct {
+	Staging       OVNConfig // Staging deployment; verification is disabled without a northbound DB
+	SigningSecret string    // Key for the verification reports' HMAC-SHA256 signature
+}
+
+// ACLRolloutsConfig configures the staged rollouts of ACLs, observed for
+// a bake period before they're enforced
+type ACLRolloutsConfig struct {
+	CheckInterval     time.Duration // How often rollouts in progress are checked, 0 never
+	DefaultBakePeriod time.Duration // How long rules are observed unless asked otherwise
+	MaxBakePeriod     time.Duration
+}
+
+// ACLSchedulesConfig configures the scheduler enabling and disabling ACLs
+// at the boundaries of their activation windows
+type ACLSchedulesConfig struct {
+	CheckInterval time.Duration // How often the windows are checked, 0 never
+}
+
+// AccessGrantsConfig configures temporary access grants and the removal
+// of the expired ones
+type AccessGrantsConfig struct {
+	CheckInterval time.Duration // How often expired grants are removed, 0 never
+	MaxDuration   time.Duration // Longest grant, 0 unlimited
+}
+
+// DriftConfig configures the comparison of the live configuration with the
+// golden backups
+type DriftConfig struct {
+	Interval time.Duration // How often drift is checked, 0 only on request
+}
+
+// WebhooksConfig configures the delivery of events to the webhooks managed
+// through the API
+type WebhooksConfig struct {
+	DeliveryInterval time.Duration // How often failed deliveries due for a retry are looked for
+	MaxAttempts      int           // Attempts per delivery before it's marked failed
+	RetryBackoff     time.Duration // Delay before the first retry, doubled for each next one
+	QuotaThreshold   int           // Percent of a quota whose crossing is notified
+	OVNCheckInterval time.Duration // How often OVN connections are checked
+}
+
+// NotificationsConfig configures the Slack, Microsoft Teams and email
+// channels events are sent to
+type NotificationsConfig struct {
+	ChannelsFile string // JSON file of {"channels": [...]}; none sends no notifications
+	SMTPHost     string // Mail server of email channels
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// GatewaysConfig configures the gateway status report
+type GatewaysConfig struct {
+	// BGPCollectorCommand prints the BGP sessions and advertised prefixes
+	// of the gateway chassis as JSON, e.g. a script querying FRR on each of
+	// them; none reports gateways from OVN alone
+	BGPCollectorCommand []string
+	BGPCollectorTimeout time.Duration
+}
+
+// ProbesConfig configures the reachability probes sent from chassis
+// alongside logical traces
+type ProbesConfig struct {
+	// AgentCommand reads a probe as JSON on its standard input, sends it
+	// from the chassis and prints the loss and RTTs as JSON, e.g. a script
+	// running ping in the port's namespace over SSH; none disables probes
+	AgentCommand []string
+	Timeout      time.Duration // Time the agent has to send every probe of a request
+}
+
+// VPNConfig configures the tracking of VPN tunnel status
+type VPNConfig struct {
+	// StatusCommand reads the VPN connections as JSON on its standard
+	// input and prints the state of their tunnels as JSON, e.g. a script
+	// querying strongSwan on the IPsec gateways; none leaves tunnel status
+	// untracked
+	StatusCommand  []string
+	StatusTimeout  time.Duration
+	StatusInterval time.Duration // How often tunnel status is collected
+}
+
+// TrashConfig configures soft deletes: deleted switches, routers, ports and
+// ACLs are kept in a recycle bin they can be restored from
+type TrashConfig struct {
+	Enabled   bool
+	Retention time.Duration // How long deleted resources can be restored
+}
+
+// MACPoolConfig is a range of MAC addresses under an OUI prefix
+type MACPoolConfig struct {
+	Name   string // Switches select a pool with the mac_pool external ID
+	Prefix string // The first three octets, e.g. "0a:58:a9"
+	Start  string // The last three octets of the first address, e.g. "00:00:01"
+	End    string // The last three octets of the last address
+}
+
+// FloatingIPPoolConfig is a range of external addresses handed out as
+// floating IPs, NATed by a gateway router
+type FloatingIPPoolConfig struct {
+	Name   string // Floating IPs are allocated from a pool by name
+	CIDR   string // The external network, e.g. "203.0.113.0/24"
+	Start  string // The first address handed out, the network's first host by default
+	End    string // The last address handed out, the network's last host by default
+	Router string // ID or name of the router the NAT rules are created on
+}
+
+// MeteringConfig configures the export of per-tenant resource-hours to
+// billing systems. Each exporter is enabled by setting its destination.
+type MeteringConfig struct {
+	Enabled        bool
+	SampleInterval time.Duration // How often usage is sampled
+	ExportInterval time.Duration // Period covered by each exported record
+	CSVPath        string        // File records are appended to
+	WebhookURL     string        // URL records are POSTed to as JSON
+	WebhookSecret  string        // Key for the webhook's HMAC-SHA256 signature
+	RemoteWriteURL string        // Prometheus remote-write endpoint
+}
+
+// CacheConfig selects the cache: "none", "memory", "redis", or "tiered" for
+// an in-process LRU in front of Redis
+type CacheConfig struct {
+	Type          string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	KeyPrefix     string
+	L1MaxEntries  int           // Entries kept in process by the tiered cache
+	L1TTL         time.Duration // Upper bound for an entry's TTL in process, 0 keeps the caller's TTL
+	L2TTL         time.Duration // Upper bound for an entry's TTL in Redis, 0 keeps the caller's TTL
+}
+
 type OAuthProvider struct {
 	Type         string // "oidc" or "oauth2"
 	ClientID     string
@@ -83,7 +359,10 @@ type OAuthProvider struct {
 	RedirectURL  string
 	Scopes       []string
 	// OIDC specific
-	IssuerURL string
+	IssuerURL   string
+	GroupsClaim string            // Claim holding group membership, dotted paths allowed (e.g. realm_access.roles)
+	RoleMapping map[string]string // Group name to ovncp role
+	DefaultRole string            // Role for users matching no mapped group
 	// OAuth2 specific
 	AuthURL     string
 	TokenURL    string
@@ -96,7 +375,67 @@ type LogConfig struct {
 	Output string
 }
 
+// SecretsConfig configures where secret settings, such as DB_PASSWORD and
+// JWT_SECRET, are read from when they reference a secret as env:NAME,
+// file:/path or vault:path#key rather than hold it
+type SecretsConfig struct {
+	VaultAddr string // Vault server of vault: references, e.g. https://vault:8200
+	// VaultToken authenticates to Vault; it may itself be an env: or file:
+	// reference, e.g. to the token a Vault agent writes
+	VaultToken      string
+	VaultNamespace  string
+	RefreshInterval time.Duration // How often DB_PASSWORD and JWT_SECRET are read again to follow rotations, 0 disables
+}
+
+// LeaderConfig configures the election of the replica running background
+// jobs, such as topology snapshots, usage aggregation and webhook
+// deliveries, when several API replicas run
+type LeaderConfig struct {
+	// Election is the lock replicas compete for: "postgres" for an advisory
+	// lock, "redis" for a key in the cache's Redis, "none" for a single
+	// replica, or "auto" for Postgres, else Redis, else none
+	Election      string
+	NodeID        string        // Identifies the replica, the hostname by default
+	LeaseDuration time.Duration // How long a Redis lock outlives a leader that stopped renewing it
+	RenewInterval time.Duration // How often the leader renews the lock and the others try to take it
+}
+
+// LocksConfig configures the locks keeping writes to a resource from
+// interleaving
+type LocksConfig struct {
+	DefaultTTL time.Duration // How long locks taken through the API last unless asked otherwise
+	MaxTTL     time.Duration // The longest locks taken through the API may last
+	// Wait is how long a write waits for another write to the resource to
+	// finish before it's refused
+	Wait time.Duration
+}
+
+// ReloadConfig configures reloading the configuration while the server
+// runs. Reloads apply the log level, rate limits, cache TTL caps and webhook
+// retries; other settings take effect on restart.
+type ReloadConfig struct {
+	// File is a file of KEY=value lines overriding the environment, so
+	// settings can change on a running server; read again on SIGHUP and
+	// when it changes
+	File          string
+	WatchInterval time.Duration // How often File is checked for changes, 0 only reloads on SIGHUP
+}
+
 func Load() (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	loading = &loadState{settings: make(map[string]Setting)}
+	defer func() { loading = nil }()
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		values, err := readConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		loading.file = values
+		loading.settings["CONFIG_FILE"] = Setting{Name: "CONFIG_FILE", Value: file, Source: SourceEnv}
+	}
+
 	cfg := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		API: APIConfig{
@@ -104,13 +443,35 @@ func Load() (*Config, error) {
 			Host:         getEnv("API_HOST", "0.0.0.0"),
 			ReadTimeout:  getDurationEnv("API_READ_TIMEOUT", 15*time.Second),
 			WriteTimeout: getDurationEnv("API_WRITE_TIMEOUT", 15*time.Second),
+			TrustedProxies: getStringSliceEnv("API_TRUSTED_PROXIES", nil),
+			IdempotencyTTL: getDurationEnv("API_IDEMPOTENCY_TTL", 24*time.Hour),
+			V1Deprecated:   getEnv("API_V1_DEPRECATED", ""),
+			V1Sunset:       getEnv("API_V1_SUNSET", ""),
 		},
 		OVN: OVNConfig{
-			NorthboundDB:   getEnv("OVN_NORTHBOUND_DB", "tcp:127.0.0.1:6641"),
-			SouthboundDB:   getEnv("OVN_SOUTHBOUND_DB", "tcp:127.0.0.1:6642"),
-			Timeout:        getDurationEnv("OVN_TIMEOUT", 30*time.Second),
-			MaxRetries:     getIntEnv("OVN_MAX_RETRIES", 3),
-			MaxConnections: getIntEnv("OVN_MAX_CONNECTIONS", 10),
+			ClusterName:       getEnv("OVN_CLUSTER_NAME", "default"),
+			Backend:           getEnv("OVN_BACKEND", OVNBackendOVSDB),
+			SandboxSeed:       getBoolEnv("OVN_SANDBOX_SEED", false),
+			NorthboundDB:      getEnv("OVN_NORTHBOUND_DB", "tcp:127.0.0.1:6641"),
+			SouthboundDB:      getEnv("OVN_SOUTHBOUND_DB", "tcp:127.0.0.1:6642"),
+			Timeout:           getDurationEnv("OVN_TIMEOUT", 30*time.Second),
+			MaxRetries:        getIntEnv("OVN_MAX_RETRIES", 3),
+			MaxConnections:    getIntEnv("OVN_MAX_CONNECTIONS", 10),
+			ReconnectInterval: getDurationEnv("OVN_RECONNECT_INTERVAL", time.Second),
+			ReconnectMaxDelay: getDurationEnv("OVN_RECONNECT_MAX_DELAY", 30*time.Second),
+			InactivityProbe:   getDurationEnv("OVN_INACTIVITY_PROBE", 15*time.Second),
+			TxMaxAttempts:     getIntEnv("OVN_TX_MAX_ATTEMPTS", 3),
+			TxRetryBackoff:    getDurationEnv("OVN_TX_RETRY_BACKOFF", 200*time.Millisecond),
+			TxRetryMaxDelay:   getDurationEnv("OVN_TX_RETRY_MAX_DELAY", 5*time.Second),
+			TLS: OVNTLSConfig{
+				Enabled:            getBoolEnv("OVN_TLS_ENABLED", false),
+				CAFile:             getEnv("OVN_TLS_CA_FILE", ""),
+				CertFile:           getEnv("OVN_TLS_CERT_FILE", ""),
+				KeyFile:            getEnv("OVN_TLS_KEY_FILE", ""),
+				ServerName:         getEnv("OVN_TLS_SERVER_NAME", ""),
+				InsecureSkipVerify: getBoolEnv("OVN_TLS_INSECURE_SKIP_VERIFY", false),
+				MinVersion:         getEnv("OVN_TLS_MIN_VERSION", "1.2"),
+			},
 		},
 		Database: DatabaseConfig{
 			Type:     getEnv("DB_TYPE", "sqlite"),
@@ -120,6 +481,14 @@ func Load() (*Config, error) {
 			User:     getEnv("DB_USER", "ovncp"),
 			Password: getEnv("DB_PASSWORD", ""),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			// 0 picks the database's default
+			MaxOpenConns:    getIntEnv("DB_MAX_OPEN_CONNS", 0),
+			MaxIdleConns:    getIntEnv("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getDurationEnv("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			ConnMaxIdleTime: getDurationEnv("DB_CONN_MAX_IDLE_TIME", 0),
+			AutoMigrate:     getBoolEnv("DB_AUTO_MIGRATE", true),
+			ReplicaHost:     getEnv("DB_REPLICA_HOST", ""),
+			ReplicaPort:     getEnv("DB_REPLICA_PORT", ""),
 		},
 		Auth: AuthConfig{
 			Enabled:           getBoolEnv("AUTH_ENABLED", false),
@@ -140,13 +509,136 @@ func Load() (*Config, error) {
 			HSTSEnabled:      getBoolEnv("HSTS_ENABLED", true),
 			HSTSMaxAge:       getIntEnv("HSTS_MAX_AGE", 31536000), // 1 year
 		},
+		Tenancy: TenancyConfig{
+			UsageSnapshotInterval: getDurationEnv("TENANT_USAGE_SNAPSHOT_INTERVAL", time.Hour),
+			ReclaimInterval:       getDurationEnv("TENANT_RECLAIM_INTERVAL", 30*time.Second),
+		},
+		Topology: TopologyConfig{
+			SnapshotInterval:  getDurationEnv("TOPOLOGY_SNAPSHOT_INTERVAL", time.Hour),
+			SnapshotRetention: getDurationEnv("TOPOLOGY_SNAPSHOT_RETENTION", 30*24*time.Hour),
+			OVSDBEndpoints:      getStringSliceEnv("TOPOLOGY_OVSDB_ENDPOINTS", nil),
+			TrafficPollInterval: getDurationEnv("TOPOLOGY_TRAFFIC_POLL_INTERVAL", 10*time.Second),
+			GraphCacheTTL:       getDurationEnv("TOPOLOGY_GRAPH_CACHE_TTL", 5*time.Minute),
+			GraphCacheSize:      getIntEnv("TOPOLOGY_GRAPH_CACHE_SIZE", 64),
+		},
+		ACLStats: ACLStatsConfig{
+			FlowDumpCommand: strings.Fields(getEnv("ACL_STATS_FLOW_DUMP_COMMAND", "")),
+			Interval:        getDurationEnv("ACL_STATS_INTERVAL", time.Minute),
+			Retention:       getDurationEnv("ACL_STATS_RETENTION", 30*24*time.Hour),
+		},
+		ACLLogs: ACLLogsConfig{
+			File:         getEnv("ACL_LOG_FILE", ""),
+			PollInterval: getDurationEnv("ACL_LOG_POLL_INTERVAL", time.Second),
+			SyslogAddr:   getEnv("ACL_LOG_SYSLOG_ADDR", ""),
+			Retention:    getDurationEnv("ACL_LOG_RETENTION", 7*24*time.Hour),
+		},
+		Compliance: ComplianceConfig{
+			Interval:      getDurationEnv("COMPLIANCE_INTERVAL", time.Hour),
+			Packs:         getStringSliceEnv("COMPLIANCE_PACKS", []string{"baseline"}),
+			RulesFile:     getEnv("COMPLIANCE_RULES_FILE", ""),
+			WebhookURL:    getEnv("COMPLIANCE_WEBHOOK_URL", ""),
+			WebhookSecret: getEnv("COMPLIANCE_WEBHOOK_SECRET", ""),
+		},
+		ACLRollouts: ACLRolloutsConfig{
+			CheckInterval:     getDurationEnv("ACL_ROLLOUT_CHECK_INTERVAL", time.Minute),
+			DefaultBakePeriod: getDurationEnv("ACL_ROLLOUT_BAKE_PERIOD", time.Hour),
+			MaxBakePeriod:     getDurationEnv("ACL_ROLLOUT_MAX_BAKE_PERIOD", 7*24*time.Hour),
+		},
+		ACLSchedules: ACLSchedulesConfig{
+			CheckInterval: getDurationEnv("ACL_SCHEDULE_CHECK_INTERVAL", 30*time.Second),
+		},
+		AccessGrants: AccessGrantsConfig{
+			CheckInterval: getDurationEnv("ACCESS_GRANT_CHECK_INTERVAL", time.Minute),
+			MaxDuration:   getDurationEnv("ACCESS_GRANT_MAX_DURATION", 24*time.Hour),
+		},
+		Drift: DriftConfig{
+			Interval: getDurationEnv("DRIFT_CHECK_INTERVAL", 15*time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			DeliveryInterval: getDurationEnv("WEBHOOK_DELIVERY_INTERVAL", 10*time.Second),
+			MaxAttempts:      getIntEnv("WEBHOOK_MAX_ATTEMPTS", 6),
+			RetryBackoff:     getDurationEnv("WEBHOOK_RETRY_BACKOFF", 30*time.Second),
+			QuotaThreshold:   getIntEnv("WEBHOOK_QUOTA_THRESHOLD", 80),
+			OVNCheckInterval: getDurationEnv("WEBHOOK_OVN_CHECK_INTERVAL", 15*time.Second),
+		},
+		Notifications: NotificationsConfig{
+			ChannelsFile: getEnv("NOTIFICATION_CHANNELS_FILE", ""),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getIntEnv("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			SMTPFrom:     getEnv("SMTP_FROM", ""),
+		},
+		Gateways: GatewaysConfig{
+			BGPCollectorCommand: strings.Fields(getEnv("GATEWAY_BGP_COLLECTOR_COMMAND", "")),
+			BGPCollectorTimeout: getDurationEnv("GATEWAY_BGP_COLLECTOR_TIMEOUT", 10*time.Second),
+		},
+		Probes: ProbesConfig{
+			AgentCommand: strings.Fields(getEnv("PROBE_AGENT_COMMAND", "")),
+			Timeout:      getDurationEnv("PROBE_TIMEOUT", 30*time.Second),
+		},
+		VPN: VPNConfig{
+			StatusCommand:  strings.Fields(getEnv("VPN_STATUS_COMMAND", "")),
+			StatusTimeout:  getDurationEnv("VPN_STATUS_TIMEOUT", 10*time.Second),
+			StatusInterval: getDurationEnv("VPN_STATUS_INTERVAL", time.Minute),
+		},
+		Trash: TrashConfig{
+			Enabled:   getBoolEnv("SOFT_DELETE_ENABLED", false),
+			Retention: getDurationEnv("SOFT_DELETE_RETENTION", 7*24*time.Hour),
+		},
+		Metering: MeteringConfig{
+			Enabled:        getBoolEnv("METERING_ENABLED", false),
+			SampleInterval: getDurationEnv("METERING_SAMPLE_INTERVAL", 5*time.Minute),
+			ExportInterval: getDurationEnv("METERING_EXPORT_INTERVAL", time.Hour),
+			CSVPath:        getEnv("METERING_CSV_PATH", ""),
+			WebhookURL:     getEnv("METERING_WEBHOOK_URL", ""),
+			WebhookSecret:  getEnv("METERING_WEBHOOK_SECRET", ""),
+			RemoteWriteURL: getEnv("METERING_REMOTE_WRITE_URL", ""),
+		},
+		Cache: CacheConfig{
+			Type:          getEnv("CACHE_TYPE", "none"),
+			RedisAddr:     getEnv("CACHE_REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnv("CACHE_REDIS_PASSWORD", ""),
+			RedisDB:       getIntEnv("CACHE_REDIS_DB", 0),
+			KeyPrefix:     getEnv("CACHE_KEY_PREFIX", "ovncp:"),
+			L1MaxEntries:  getIntEnv("CACHE_L1_MAX_ENTRIES", 1024),
+			L1TTL:         getDurationEnv("CACHE_L1_TTL", 5*time.Second),
+			L2TTL:         getDurationEnv("CACHE_L2_TTL", 0),
+		},
 		Log: LogConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 			Output: getEnv("LOG_OUTPUT", "stdout"),
 		},
+		Reload: ReloadConfig{
+			File:          os.Getenv("CONFIG_FILE"),
+			WatchInterval: getDurationEnv("CONFIG_WATCH_INTERVAL", 10*time.Second),
+		},
+		Secrets: SecretsConfig{
+			VaultAddr:       getEnv("VAULT_ADDR", ""),
+			VaultToken:      getEnv("VAULT_TOKEN", ""),
+			VaultNamespace:  getEnv("VAULT_NAMESPACE", ""),
+			RefreshInterval: getDurationEnv("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+		},
+		Leader: LeaderConfig{
+			Election:      getEnv("LEADER_ELECTION", "auto"),
+			NodeID:        getEnv("NODE_ID", hostname()),
+			LeaseDuration: getDurationEnv("LEADER_LEASE_DURATION", 15*time.Second),
+			RenewInterval: getDurationEnv("LEADER_RENEW_INTERVAL", 5*time.Second),
+		},
+		Locks: LocksConfig{
+			DefaultTTL: getDurationEnv("RESOURCE_LOCK_TTL", 5*time.Minute),
+			MaxTTL:     getDurationEnv("RESOURCE_LOCK_MAX_TTL", time.Hour),
+			Wait:       getDurationEnv("RESOURCE_LOCK_WAIT", 5*time.Second),
+		},
 	}
 
+	cfg.OVNClusters = loadOVNClusters(cfg.OVN)
+	cfg.DR = loadDR(cfg.OVN)
+	cfg.MACPools = loadMACPools()
+	cfg.FloatingIPPools = loadFloatingIPPools()
+	cfg.settings = loading.settings
+
 	return cfg, cfg.Validate()
 }
 
@@ -155,6 +647,245 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET is required when AUTH_ENABLED is true")
 	}
 	
+	if err := c.OVN.TLS.Validate(c.OVN.NorthboundDB); err != nil {
+		return err
+	}
+	if c.OVN.Backend != OVNBackendOVSDB && c.OVN.Backend != OVNBackendSandbox {
+		return fmt.Errorf("OVN_BACKEND must be %s or %s", OVNBackendOVSDB, OVNBackendSandbox)
+	}
+	if c.OVN.TxMaxAttempts < 1 {
+		return fmt.Errorf("OVN_TX_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.OVN.TxMaxAttempts > 1 && (c.OVN.TxRetryBackoff <= 0 || c.OVN.TxRetryMaxDelay < c.OVN.TxRetryBackoff) {
+		return fmt.Errorf("OVN_TX_RETRY_MAX_DELAY must be at least OVN_TX_RETRY_BACKOFF, which must be positive")
+	}
+	
+	names := map[string]bool{c.OVN.ClusterName: true}
+	if !isValidClusterName(c.OVN.ClusterName) {
+		return fmt.Errorf("invalid OVN_CLUSTER_NAME %q", c.OVN.ClusterName)
+	}
+	for _, cluster := range c.OVNClusters {
+		if !isValidClusterName(cluster.ClusterName) {
+			return fmt.Errorf("invalid OVN cluster name %q", cluster.ClusterName)
+		}
+		if names[cluster.ClusterName] {
+			return fmt.Errorf("duplicate OVN cluster name %q", cluster.ClusterName)
+		}
+		names[cluster.ClusterName] = true
+		if cluster.NorthboundDB == "" {
+			return fmt.Errorf("OVN cluster %s is missing a northbound DB address", cluster.ClusterName)
+		}
+		if err := cluster.TLS.Validate(cluster.NorthboundDB); err != nil {
+			return fmt.Errorf("OVN cluster %s: %w", cluster.ClusterName, err)
+		}
+	}
+	
+	if c.Metering.Enabled {
+		if c.Metering.CSVPath == "" && c.Metering.WebhookURL == "" && c.Metering.RemoteWriteURL == "" {
+			return fmt.Errorf("METERING_ENABLED requires METERING_CSV_PATH, METERING_WEBHOOK_URL or METERING_REMOTE_WRITE_URL")
+		}
+		if c.Metering.SampleInterval <= 0 || c.Metering.ExportInterval < c.Metering.SampleInterval {
+			return fmt.Errorf("METERING_EXPORT_INTERVAL must be at least METERING_SAMPLE_INTERVAL, which must be positive")
+		}
+	}
+	
+	if len(c.Topology.OVSDBEndpoints) > 0 && c.Topology.TrafficPollInterval <= 0 {
+		return fmt.Errorf("TOPOLOGY_TRAFFIC_POLL_INTERVAL must be positive when TOPOLOGY_OVSDB_ENDPOINTS is set")
+	}
+	
+	if c.Topology.GraphCacheTTL > 0 && c.Topology.GraphCacheSize <= 0 {
+		return fmt.Errorf("TOPOLOGY_GRAPH_CACHE_SIZE must be positive when TOPOLOGY_GRAPH_CACHE_TTL is set")
+	}
+	
+	if len(c.ACLStats.FlowDumpCommand) > 0 && c.ACLStats.Interval <= 0 {
+		return fmt.Errorf("ACL_STATS_INTERVAL must be positive when ACL_STATS_FLOW_DUMP_COMMAND is set")
+	}
+	
+	if c.ACLLogs.File != "" && c.ACLLogs.PollInterval <= 0 {
+		return fmt.Errorf("ACL_LOG_POLL_INTERVAL must be positive when ACL_LOG_FILE is set")
+	}
+	
+	if c.Compliance.Interval < 0 {
+		return fmt.Errorf("COMPLIANCE_INTERVAL must not be negative")
+	}
+	if c.Compliance.WebhookURL != "" && c.Compliance.Interval == 0 {
+		return fmt.Errorf("COMPLIANCE_WEBHOOK_URL requires a positive COMPLIANCE_INTERVAL")
+	}
+	
+	if c.Tenancy.ReclaimInterval <= 0 {
+		return fmt.Errorf("TENANT_RECLAIM_INTERVAL must be positive")
+	}
+	
+	if c.ACLRollouts.CheckInterval < 0 {
+		return fmt.Errorf("ACL_ROLLOUT_CHECK_INTERVAL must not be negative")
+	}
+	if c.ACLRollouts.DefaultBakePeriod <= 0 || c.ACLRollouts.DefaultBakePeriod > c.ACLRollouts.MaxBakePeriod {
+		return fmt.Errorf("ACL_ROLLOUT_BAKE_PERIOD must be positive and at most ACL_ROLLOUT_MAX_BAKE_PERIOD")
+	}
+	if c.ACLSchedules.CheckInterval < 0 {
+		return fmt.Errorf("ACL_SCHEDULE_CHECK_INTERVAL must not be negative")
+	}
+
+	if c.AccessGrants.CheckInterval < 0 {
+		return fmt.Errorf("ACCESS_GRANT_CHECK_INTERVAL must not be negative")
+	}
+
+	if c.AccessGrants.MaxDuration < 0 {
+		return fmt.Errorf("ACCESS_GRANT_MAX_DURATION must not be negative")
+	}
+
+	if c.Drift.Interval < 0 {
+		return fmt.Errorf("DRIFT_CHECK_INTERVAL must not be negative")
+	}
+	
+	if c.DR.Staging.NorthboundDB != "" {
+		if err := c.DR.Staging.TLS.Validate(c.DR.Staging.NorthboundDB); err != nil {
+			return fmt.Errorf("DR staging: %w", err)
+		}
+		if c.DR.SigningSecret == "" {
+			return fmt.Errorf("DR_REPORT_SIGNING_SECRET is required when DR_STAGING_NORTHBOUND_DB is set")
+		}
+	}
+	
+	if c.Webhooks.DeliveryInterval <= 0 || c.Webhooks.RetryBackoff <= 0 || c.Webhooks.OVNCheckInterval <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_INTERVAL, WEBHOOK_RETRY_BACKOFF and WEBHOOK_OVN_CHECK_INTERVAL must be positive")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhooks.QuotaThreshold < 1 || c.Webhooks.QuotaThreshold > 100 {
+		return fmt.Errorf("WEBHOOK_QUOTA_THRESHOLD must be between 1 and 100")
+	}
+	if c.Notifications.SMTPHost != "" && (c.Notifications.SMTPPort < 1 || c.Notifications.SMTPPort > 65535) {
+		return fmt.Errorf("SMTP_PORT must be between 1 and 65535 when SMTP_HOST is set")
+	}
+	
+	if len(c.Gateways.BGPCollectorCommand) > 0 && c.Gateways.BGPCollectorTimeout <= 0 {
+		return fmt.Errorf("GATEWAY_BGP_COLLECTOR_TIMEOUT must be positive when GATEWAY_BGP_COLLECTOR_COMMAND is set")
+	}
+	if len(c.Probes.AgentCommand) > 0 && c.Probes.Timeout <= 0 {
+		return fmt.Errorf("PROBE_TIMEOUT must be positive when PROBE_AGENT_COMMAND is set")
+	}
+	if len(c.VPN.StatusCommand) > 0 && (c.VPN.StatusTimeout <= 0 || c.VPN.StatusInterval <= 0) {
+		return fmt.Errorf("VPN_STATUS_TIMEOUT and VPN_STATUS_INTERVAL must be positive when VPN_STATUS_COMMAND is set")
+	}
+	
+	if c.API.IdempotencyTTL <= 0 {
+		return fmt.Errorf("API_IDEMPOTENCY_TTL must be positive")
+	}
+	for name, date := range map[string]string{"API_V1_DEPRECATED": c.API.V1Deprecated, "API_V1_SUNSET": c.API.V1Sunset} {
+		if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+			return fmt.Errorf("%s must be a date in the YYYY-MM-DD form", name)
+		}
+	}
+	
+	if c.Trash.Enabled && c.Trash.Retention <= 0 {
+		return fmt.Errorf("SOFT_DELETE_RETENTION must be positive when SOFT_DELETE_ENABLED is true")
+	}
+	
+	pools := map[string]bool{}
+	for _, pool := range c.MACPools {
+		if pools[pool.Name] {
+			return fmt.Errorf("duplicate MAC pool name %q", pool.Name)
+		}
+		pools[pool.Name] = true
+		if _, _, err := pool.Range(); err != nil {
+			return fmt.Errorf("MAC pool %s: %w", pool.Name, err)
+		}
+	}
+	
+	pools = map[string]bool{}
+	for _, pool := range c.FloatingIPPools {
+		if pools[pool.Name] {
+			return fmt.Errorf("duplicate floating IP pool name %q", pool.Name)
+		}
+		pools[pool.Name] = true
+		if _, _, err := pool.Range(); err != nil {
+			return fmt.Errorf("floating IP pool %s: %w", pool.Name, err)
+		}
+		if pool.Router == "" {
+			return fmt.Errorf("floating IP pool %s: FLOATING_IP_POOL_<NAME>_ROUTER is required", pool.Name)
+		}
+	}
+	
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid LOG_LEVEL %q, must be debug, info, warn or error", c.Log.Level)
+	}
+	
+	if c.Security.RateLimitEnabled && (c.Security.RateLimitRPS < 1 || c.Security.RateLimitBurst < 1) {
+		return fmt.Errorf("RATE_LIMIT_RPS and RATE_LIMIT_BURST must be positive when RATE_LIMIT_ENABLED is true")
+	}
+	
+	if c.Reload.WatchInterval < 0 {
+		return fmt.Errorf("CONFIG_WATCH_INTERVAL must not be negative")
+	}
+	
+	if c.Secrets.RefreshInterval < 0 {
+		return fmt.Errorf("SECRETS_REFRESH_INTERVAL must not be negative")
+	}
+	if strings.HasPrefix(c.Secrets.VaultToken, "vault:") {
+		return fmt.Errorf("VAULT_TOKEN can't be a vault: reference")
+	}
+	if err := c.ResolveSecrets(func(name, setting string) (string, error) {
+		if strings.HasPrefix(setting, "vault:") && (c.Secrets.VaultAddr == "" || c.Secrets.VaultToken == "") {
+			return "", fmt.Errorf("%s is a vault: reference but VAULT_ADDR or VAULT_TOKEN isn't set", name)
+		}
+		return setting, nil
+	}); err != nil {
+		return err
+	}
+	
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		return fmt.Errorf("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		return fmt.Errorf("DB_MAX_IDLE_CONNS must be at most DB_MAX_OPEN_CONNS")
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative")
+	}
+	if c.Database.ReplicaHost != "" && !c.Database.IsPostgres() {
+		return fmt.Errorf("DB_REPLICA_HOST requires DB_TYPE=postgres")
+	}
+	
+	switch c.Leader.Election {
+	case "auto", "none":
+	case "postgres":
+		if !c.Database.IsPostgres() {
+			return fmt.Errorf("LEADER_ELECTION=postgres requires DB_TYPE=postgres")
+		}
+	case "redis":
+		if c.Cache.Type != "redis" && c.Cache.Type != "tiered" {
+			return fmt.Errorf("LEADER_ELECTION=redis requires CACHE_TYPE=redis or tiered")
+		}
+	default:
+		return fmt.Errorf("invalid LEADER_ELECTION %q, must be auto, postgres, redis or none", c.Leader.Election)
+	}
+	if c.Leader.RenewInterval <= 0 || c.Leader.LeaseDuration <= c.Leader.RenewInterval {
+		return fmt.Errorf("LEADER_LEASE_DURATION must be longer than LEADER_RENEW_INTERVAL, which must be positive")
+	}
+	if c.Locks.DefaultTTL <= 0 || c.Locks.MaxTTL < c.Locks.DefaultTTL {
+		return fmt.Errorf("RESOURCE_LOCK_TTL must be positive and at most RESOURCE_LOCK_MAX_TTL")
+	}
+	if c.Locks.Wait < 0 {
+		return fmt.Errorf("RESOURCE_LOCK_WAIT must not be negative")
+	}
+	
+	if c.Cache.L1TTL < 0 || c.Cache.L2TTL < 0 {
+		return fmt.Errorf("CACHE_L1_TTL and CACHE_L2_TTL must not be negative")
+	}
+	switch c.Cache.Type {
+	case "", "none", "memory", "redis":
+	case "tiered":
+		if c.Cache.L1MaxEntries <= 0 {
+			return fmt.Errorf("CACHE_L1_MAX_ENTRIES must be positive for the tiered cache")
+		}
+	default:
+		return fmt.Errorf("invalid CACHE_TYPE %q, must be none, memory, redis or tiered", c.Cache.Type)
+	}
+	
 	// OAuth providers are optional - we can use local auth
 	// if c.Auth.Enabled && len(c.Auth.Providers) == 0 {
 	// 	return fmt.Errorf("at least one OAuth provider must be configured when AUTH_ENABLED is true")
@@ -167,6 +898,14 @@ func (c *Config) Validate() error {
 		if provider.Type == "oidc" && provider.IssuerURL == "" {
 			return fmt.Errorf("OIDC provider %s is missing issuer URL", name)
 		}
+		for group, role := range provider.RoleMapping {
+			if !isValidRole(role) {
+				return fmt.Errorf("OIDC provider %s maps group %s to invalid role %s", name, group, role)
+			}
+		}
+		if provider.DefaultRole != "" && !isValidRole(provider.DefaultRole) {
+			return fmt.Errorf("OIDC provider %s has invalid default role %s", name, provider.DefaultRole)
+		}
 		if provider.Type == "oauth2" && (provider.AuthURL == "" || provider.TokenURL == "") {
 			return fmt.Errorf("OAuth2 provider %s is missing auth or token URL", name)
 		}
@@ -175,28 +914,64 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Validate checks that the TLS settings are usable for the given endpoint
+func (t *OVNTLSConfig) Validate(endpoint string) error {
+	if !t.Enabled {
+		return nil
+	}
+	
+	if len(endpoint) < 4 || endpoint[:4] != "ssl:" {
+		return fmt.Errorf("OVN TLS is enabled but northbound endpoint %q is not an ssl: endpoint", endpoint)
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("OVN_TLS_CERT_FILE and OVN_TLS_KEY_FILE must be set together")
+	}
+	if t.CAFile == "" && !t.InsecureSkipVerify {
+		return fmt.Errorf("OVN_TLS_CA_FILE is required unless OVN_TLS_INSECURE_SKIP_VERIFY is set")
+	}
+	switch t.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("unsupported OVN_TLS_MIN_VERSION %q", t.MinVersion)
+	}
+	
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		return value
 	}
+	recordDefault(key, defaultValue)
 	return defaultValue
 }
 
+// hostname returns the host's name, the pod's under Kubernetes
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "ovncp"
+	}
+	return name
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
-	value := os.Getenv(key)
+	value := lookupEnv(key)
 	switch value {
 	case "true", "1", "yes", "on":
 		return true
 	case "false", "0", "no", "off":
 		return false
 	default:
+		recordDefault(key, strconv.FormatBool(defaultValue))
 		return defaultValue
 	}
 }
 
 func getIntEnv(key string, defaultValue int) int {
-	value := os.Getenv(key)
+	value := lookupEnv(key)
 	if value == "" {
+		recordDefault(key, strconv.Itoa(defaultValue))
 		return defaultValue
 	}
 	var result int
@@ -205,17 +980,187 @@ func getIntEnv(key string, defaultValue int) int {
 }
 
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	value := os.Getenv(key)
+	value := lookupEnv(key)
 	if value == "" {
+		recordDefault(key, defaultValue.String())
 		return defaultValue
 	}
 	duration, err := time.ParseDuration(value)
 	if err != nil {
+		recordDefault(key, defaultValue.String())
 		return defaultValue
 	}
 	return duration
 }
 
+// loadOVNClusters reads the clusters listed in OVN_CLUSTERS. Each cluster
+// starts from the base OVN settings and overrides them with
+// OVN_CLUSTER_<NAME>_* variables, e.g. OVN_CLUSTER_EU_WEST_NORTHBOUND_DB.
+func loadOVNClusters(base OVNConfig) []OVNConfig {
+	var clusters []OVNConfig
+	for _, name := range getStringSliceEnv("OVN_CLUSTERS", nil) {
+		prefix := "OVN_CLUSTER_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		
+		cluster := base
+		cluster.ClusterName = name
+		cluster.NorthboundDB = getEnv(prefix+"NORTHBOUND_DB", "")
+		cluster.SouthboundDB = getEnv(prefix+"SOUTHBOUND_DB", "")
+		cluster.TLS.Enabled = getBoolEnv(prefix+"TLS_ENABLED", base.TLS.Enabled)
+		cluster.TLS.CAFile = getEnv(prefix+"TLS_CA_FILE", base.TLS.CAFile)
+		cluster.TLS.CertFile = getEnv(prefix+"TLS_CERT_FILE", base.TLS.CertFile)
+		cluster.TLS.KeyFile = getEnv(prefix+"TLS_KEY_FILE", base.TLS.KeyFile)
+		cluster.TLS.ServerName = getEnv(prefix+"TLS_SERVER_NAME", "")
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// loadDR reads the staging deployment backups are verified against from
+// DR_STAGING_NORTHBOUND_DB and DR_STAGING_TLS_*, which default to the
+// primary deployment's TLS settings
+func loadDR(base OVNConfig) DRConfig {
+	staging := base
+	staging.ClusterName = "dr-staging"
+	staging.NorthboundDB = getEnv("DR_STAGING_NORTHBOUND_DB", "")
+	staging.SouthboundDB = ""
+	staging.TLS.Enabled = getBoolEnv("DR_STAGING_TLS_ENABLED", base.TLS.Enabled)
+	staging.TLS.CAFile = getEnv("DR_STAGING_TLS_CA_FILE", base.TLS.CAFile)
+	staging.TLS.CertFile = getEnv("DR_STAGING_TLS_CERT_FILE", base.TLS.CertFile)
+	staging.TLS.KeyFile = getEnv("DR_STAGING_TLS_KEY_FILE", base.TLS.KeyFile)
+	staging.TLS.ServerName = getEnv("DR_STAGING_TLS_SERVER_NAME", "")
+	return DRConfig{
+		Staging:       staging,
+		SigningSecret: getEnv("DR_REPORT_SIGNING_SECRET", ""),
+	}
+}
+
+// loadMACPools reads the pools listed in MAC_POOLS from
+// MAC_POOL_<NAME>_PREFIX, _START and _END, e.g. MAC_POOL_DEFAULT_PREFIX
+func loadMACPools() []MACPoolConfig {
+	var pools []MACPoolConfig
+	for _, name := range getStringSliceEnv("MAC_POOLS", nil) {
+		prefix := "MAC_POOL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		pools = append(pools, MACPoolConfig{
+			Name:   name,
+			Prefix: getEnv(prefix+"PREFIX", ""),
+			Start:  getEnv(prefix+"START", "00:00:01"),
+			End:    getEnv(prefix+"END", "ff:ff:fe"),
+		})
+	}
+	return pools
+}
+
+// Range returns the first and last address of the pool as integers
+func (p MACPoolConfig) Range() (first, last uint64, err error) {
+	oui, err := parseOctets(p.Prefix, 3)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid prefix %q: %w", p.Prefix, err)
+	}
+	if oui>>16&1 != 0 {
+		return 0, 0, fmt.Errorf("prefix %s is a multicast prefix", p.Prefix)
+	}
+	start, err := parseOctets(p.Start, 3)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start %q: %w", p.Start, err)
+	}
+	end, err := parseOctets(p.End, 3)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end %q: %w", p.End, err)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("start %s is after end %s", p.Start, p.End)
+	}
+	return oui<<24 | start, oui<<24 | end, nil
+}
+
+// parseOctets parses n colon-separated hex octets as an integer
+// loadFloatingIPPools reads the pools listed in FLOATING_IP_POOLS from
+// FLOATING_IP_POOL_<NAME>_CIDR, _START, _END and _ROUTER
+func loadFloatingIPPools() []FloatingIPPoolConfig {
+	var pools []FloatingIPPoolConfig
+	for _, name := range getStringSliceEnv("FLOATING_IP_POOLS", nil) {
+		prefix := "FLOATING_IP_POOL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		pools = append(pools, FloatingIPPoolConfig{
+			Name:   name,
+			CIDR:   getEnv(prefix+"CIDR", ""),
+			Start:  getEnv(prefix+"START", ""),
+			End:    getEnv(prefix+"END", ""),
+			Router: getEnv(prefix+"ROUTER", ""),
+		})
+	}
+	return pools
+}
+
+// Range returns the first and last address of the pool. Without a start
+// or end, the network's first or last host is used; IPv4 networks' own
+// and broadcast addresses aren't hosts.
+func (p FloatingIPPoolConfig) Range() (first, last netip.Addr, err error) {
+	network, err := netip.ParsePrefix(p.CIDR)
+	if err != nil {
+		return first, last, fmt.Errorf("invalid CIDR %q", p.CIDR)
+	}
+	network = network.Masked()
+
+	first = network.Addr().Next()
+	last = lastAddr(network)
+	if network.Addr().Is4() {
+		last = last.Prev()
+	}
+	if p.Start != "" {
+		if first, err = netip.ParseAddr(p.Start); err != nil || !network.Contains(first) {
+			return first, last, fmt.Errorf("start %q isn't an address of %s", p.Start, network)
+		}
+	}
+	if p.End != "" {
+		if last, err = netip.ParseAddr(p.End); err != nil || !network.Contains(last) {
+			return first, last, fmt.Errorf("end %q isn't an address of %s", p.End, network)
+		}
+	}
+	if !first.IsValid() || !last.IsValid() || first.Compare(last) > 0 {
+		return first, last, fmt.Errorf("%s has no addresses from %s to %s", network, first, last)
+	}
+	return first, last, nil
+}
+
+// lastAddr returns the last address of a masked network
+func lastAddr(network netip.Prefix) netip.Addr {
+	bytes := network.Addr().AsSlice()
+	for bit := network.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+func parseOctets(value string, n int) (uint64, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != n {
+		return 0, fmt.Errorf("expected %d colon-separated octets", n)
+	}
+	var result uint64
+	for _, part := range parts {
+		octet, err := strconv.ParseUint(part, 16, 8)
+		if err != nil || len(part) != 2 {
+			return 0, fmt.Errorf("invalid octet %q", part)
+		}
+		result = result<<8 | octet
+	}
+	return result, nil
+}
+
+// isValidClusterName accepts lowercase DNS-label style names usable in URL paths
+func isValidClusterName(name string) bool {
+	if name == "" || len(name) > 63 || name[0] == '-' || name[len(name)-1] == '-' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
 func loadOAuthProviders() map[string]OAuthProvider {
 	providers := make(map[string]OAuthProvider)
 	
@@ -254,6 +1199,9 @@ func loadOAuthProviders() map[string]OAuthProvider {
 			RedirectURL:  getEnv("OAUTH_OIDC_REDIRECT_URL", ""),
 			IssuerURL:    getEnv("OAUTH_OIDC_ISSUER_URL", ""),
 			Scopes:       getStringSliceEnv("OAUTH_OIDC_SCOPES", []string{"openid", "email", "profile"}),
+			GroupsClaim:  getEnv("OAUTH_OIDC_GROUPS_CLAIM", "groups"),
+			RoleMapping:  getMapEnv("OAUTH_OIDC_ROLE_MAPPING"),
+			DefaultRole:  getEnv("OAUTH_OIDC_DEFAULT_ROLE", ""),
 		}
 	}
 	
@@ -261,8 +1209,9 @@ func loadOAuthProviders() map[string]OAuthProvider {
 }
 
 func getStringSliceEnv(key string, defaultValue []string) []string {
-	value := os.Getenv(key)
+	value := lookupEnv(key)
 	if value == "" {
+		recordDefault(key, strings.Join(defaultValue, ","))
 		return defaultValue
 	}
 	// Simple comma-separated parsing
@@ -275,6 +1224,31 @@ func getStringSliceEnv(key string, defaultValue []string) []string {
 	return result
 }
 
+// getMapEnv parses a comma-separated list of key=value pairs
+func getMapEnv(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getStringSliceEnv(key, nil) {
+		parts := splitString(pair, "=")
+		if len(parts) != 2 {
+			continue
+		}
+		k, v := trimString(parts[0]), trimString(parts[1])
+		if k != "" && v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// isValidRole reports whether role is one of the built-in user roles
+func isValidRole(role string) bool {
+	switch role {
+	case "admin", "operator", "viewer":
+		return true
+	}
+	return false
+}
+
 func splitString(s, sep string) []string {
 	var result []string
 	start := 0
@@ -312,4 +1286,38 @@ func (c *Config) GetBackupPath() string {
 		path = filepath.Join(pwd, path)
 	}
 	return path
+}
+
+// GetChangesetPath returns the changeset storage path
+func (c *Config) GetChangesetPath() string {
+	path := getEnv("CHANGESET_PATH", "/var/lib/ovncp/changesets")
+	if !filepath.IsAbs(path) {
+		// Make it absolute relative to current directory
+		pwd, _ := os.Getwd()
+		path = filepath.Join(pwd, path)
+	}
+	return path
+}
+
+// GetChunkedTransactionPath returns the storage path of chunked transactions
+// and their checkpoints
+func (c *Config) GetChunkedTransactionPath() string {
+	path := getEnv("CHUNKED_TRANSACTION_PATH", "/var/lib/ovncp/transactions")
+	if !filepath.IsAbs(path) {
+		// Make it absolute relative to current directory
+		pwd, _ := os.Getwd()
+		path = filepath.Join(pwd, path)
+	}
+	return path
+}
+
+// GetTrashPath returns the storage path of the recycle bin
+func (c *Config) GetTrashPath() string {
+	path := getEnv("TRASH_PATH", "/var/lib/ovncp/trash")
+	if !filepath.IsAbs(path) {
+		// Make it absolute relative to current directory
+		pwd, _ := os.Getwd()
+		path = filepath.Join(pwd, path)
+	}
+	return path
 }
\ No newline at end of file
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sources of a setting's value
+const (
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceDefault = "default"
+)
+
+// reloadableSettings are applied to the running server when the
+// configuration is reloaded; other settings take effect on restart
+var reloadableSettings = map[string]bool{
+	"LOG_LEVEL":             true,
+	"RATE_LIMIT_RPS":        true,
+	"RATE_LIMIT_BURST":      true,
+	"CACHE_L1_TTL":          true,
+	"CACHE_L2_TTL":          true,
+	"WEBHOOK_MAX_ATTEMPTS":  true,
+	"WEBHOOK_RETRY_BACKOFF": true,
+}
+
+// Setting is a configuration variable and the value in effect
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	// Reloadable settings can change without restarting the server
+	Reloadable bool `json:"reloadable"`
+}
+
+// Settings returns the settings read to load the configuration, sorted by
+// name. Secrets are redacted.
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(c.settings))
+	for _, setting := range c.settings {
+		if isSecret(setting.Name) && setting.Value != "" {
+			setting.Value = "REDACTED"
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return settings
+}
+
+// Changed returns the names of the settings whose values differ in next,
+// sorted
+func (c *Config) Changed(next *Config) []string {
+	var changed []string
+	for name, setting := range next.settings {
+		if c.settings[name].Value != setting.Value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range c.settings {
+		if _, ok := next.settings[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Reloaded returns the configuration in effect once the reloadable
+// settings of next are applied to a server running with c
+func (c *Config) Reloaded(next *Config) *Config {
+	reloaded := *c
+	reloaded.Log.Level = next.Log.Level
+	reloaded.Security.RateLimitRPS = next.Security.RateLimitRPS
+	reloaded.Security.RateLimitBurst = next.Security.RateLimitBurst
+	reloaded.Cache.L1TTL = next.Cache.L1TTL
+	reloaded.Cache.L2TTL = next.Cache.L2TTL
+	reloaded.Webhooks.MaxAttempts = next.Webhooks.MaxAttempts
+	reloaded.Webhooks.RetryBackoff = next.Webhooks.RetryBackoff
+
+	reloaded.settings = make(map[string]Setting, len(c.settings))
+	for name, setting := range c.settings {
+		reloaded.settings[name] = setting
+	}
+	for name := range reloadableSettings {
+		if setting, ok := next.settings[name]; ok {
+			reloaded.settings[name] = setting
+		}
+	}
+	return &reloaded
+}
+
+// ResolveSecrets replaces each secret setting with what resolve returns
+// for it, e.g. the secret it references
+func (c *Config) ResolveSecrets(resolve func(name, setting string) (string, error)) error {
+	fields := map[string]*string{
+		"DB_PASSWORD":               &c.Database.Password,
+		"JWT_SECRET":                &c.Auth.JWTSecret,
+		"CACHE_REDIS_PASSWORD":      &c.Cache.RedisPassword,
+		"SMTP_PASSWORD":             &c.Notifications.SMTPPassword,
+		"COMPLIANCE_WEBHOOK_SECRET": &c.Compliance.WebhookSecret,
+		"METERING_WEBHOOK_SECRET":   &c.Metering.WebhookSecret,
+		"DR_REPORT_SIGNING_SECRET":  &c.DR.SigningSecret,
+	}
+	for name, field := range fields {
+		if *field == "" {
+			continue
+		}
+		value, err := resolve(name, *field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+
+	for name, provider := range c.Auth.Providers {
+		if provider.ClientSecret == "" {
+			continue
+		}
+		value, err := resolve("OAUTH_"+strings.ToUpper(name)+"_CLIENT_SECRET", provider.ClientSecret)
+		if err != nil {
+			return err
+		}
+		provider.ClientSecret = value
+		c.Auth.Providers[name] = provider
+	}
+	return nil
+}
+
+func isSecret(name string) bool {
+	return strings.Contains(name, "SECRET") || strings.Contains(name, "PASSWORD") || strings.Contains(name, "TOKEN")
+}
+
+// loading holds the config file and the settings read by the Load in
+// progress; loadMu serializes loads
+var (
+	loadMu  sync.Mutex
+	loading *loadState
+)
+
+type loadState struct {
+	file     map[string]string
+	settings map[string]Setting
+}
+
+// lookupEnv returns a variable from the config file, else from the
+// environment, and records where it came from
+func lookupEnv(key string) string {
+	if loading == nil {
+		return os.Getenv(key)
+	}
+	value, source := loading.file[key], SourceFile
+	if value == "" {
+		value, source = os.Getenv(key), SourceEnv
+	}
+	if value != "" {
+		loading.settings[key] = Setting{Name: key, Value: value, Source: source, Reloadable: reloadableSettings[key]}
+	}
+	return value
+}
+
+// recordDefault records that a variable took its default value
+func recordDefault(key, value string) {
+	if loading != nil {
+		loading.settings[key] = Setting{Name: key, Value: value, Source: SourceDefault, Reloadable: reloadableSettings[key]}
+	}
+}
+
+// readConfigFile reads a file of KEY=value lines. Blank lines and lines
+// starting with # are skipped, and values may be quoted.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CONFIG_FILE: %w", err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, n)
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Access grant operations
+
+const accessGrantColumns = `id, tenant_id, switch_id, port_id, port_name, service, source_cidr, reason, acl_id,
+	status, granted_by, granted_at, expires_at, ended_at, revoked_by`
+
+// CreateAccessGrant records an access grant
+func (db *DB) CreateAccessGrant(ctx context.Context, grant *models.AccessGrant) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO access_grants (`+accessGrantColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		grant.ID, grant.TenantID, grant.SwitchID, grant.PortID, grant.PortName, grant.Service, grant.SourceCIDR,
+		grant.Reason, grant.ACLID, grant.Status, grant.GrantedBy, grant.GrantedAt, grant.ExpiresAt,
+		nullTime(grant.EndedAt), grant.RevokedBy)
+	if err != nil {
+		return fmt.Errorf("failed to create access grant: %w", err)
+	}
+	return nil
+}
+
+// GetAccessGrant retrieves an access grant by ID, nil when there's none
+func (db *DB) GetAccessGrant(ctx context.Context, id string) (*models.AccessGrant, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+accessGrantColumns+` FROM access_grants WHERE id = $1`, id)
+	grant, err := scanAccessGrant(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access grant: %w", err)
+	}
+	return grant, nil
+}
+
+// ListAccessGrants lists the access grants of tenantID, or all of them
+// when it's empty, latest first
+func (db *DB) ListAccessGrants(ctx context.Context, tenantID string) ([]*models.AccessGrant, error) {
+	return db.listAccessGrants(ctx, `WHERE ($1 = '' OR tenant_id = $1) ORDER BY granted_at DESC, id`, tenantID)
+}
+
+// ListExpiredAccessGrants lists the active access grants expiring at or
+// before now
+func (db *DB) ListExpiredAccessGrants(ctx context.Context, now time.Time) ([]*models.AccessGrant, error) {
+	return db.listAccessGrants(ctx, `WHERE status = $1 AND expires_at <= $2 ORDER BY expires_at, id`,
+		models.AccessGrantActive, now)
+}
+
+// EndAccessGrant records an access grant ending with status
+func (db *DB) EndAccessGrant(ctx context.Context, grant *models.AccessGrant) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE access_grants SET status = $1, ended_at = $2, revoked_by = $3
+		WHERE id = $4`,
+		grant.Status, nullTime(grant.EndedAt), grant.RevokedBy, grant.ID)
+	if err != nil {
+		return fmt.Errorf("failed to end access grant: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) listAccessGrants(ctx context.Context, where string, args ...interface{}) ([]*models.AccessGrant, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+accessGrantColumns+` FROM access_grants `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []*models.AccessGrant{}
+	for rows.Next() {
+		grant, err := scanAccessGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access grants: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+func scanAccessGrant(row interface{ Scan(...interface{}) error }) (*models.AccessGrant, error) {
+	var grant models.AccessGrant
+	var endedAt sql.NullTime
+	if err := row.Scan(&grant.ID, &grant.TenantID, &grant.SwitchID, &grant.PortID, &grant.PortName, &grant.Service,
+		&grant.SourceCIDR, &grant.Reason, &grant.ACLID, &grant.Status, &grant.GrantedBy, &grant.GrantedAt,
+		&grant.ExpiresAt, &endedAt, &grant.RevokedBy); err != nil {
+		return nil, err
+	}
+	if endedAt.Valid {
+		grant.EndedAt = &endedAt.Time
+	}
+	return &grant, nil
+}
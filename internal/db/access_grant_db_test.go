@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestAccessGrants(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newGrant := func(id, tenantID string, grantedAt time.Time, ttl time.Duration) *models.AccessGrant {
+		return &models.AccessGrant{
+			ID:         id,
+			TenantID:   tenantID,
+			SwitchID:   "ls-1",
+			PortName:   "db-1",
+			Service:    "postgres",
+			SourceCIDR: "10.0.0.5/32",
+			ACLID:      "acl-" + id[len(id)-2:],
+			Status:     models.AccessGrantActive,
+			GrantedBy:  "alice",
+			GrantedAt:  grantedAt,
+			ExpiresAt:  grantedAt.Add(ttl),
+		}
+	}
+	expired := newGrant("7b5d3f1e-9c8a-4e6b-a2d4-1f3e5a7c9b01", "tenant-a", now.Add(-2*time.Hour), time.Hour)
+	active := newGrant("7b5d3f1e-9c8a-4e6b-a2d4-1f3e5a7c9b02", "tenant-a", now.Add(-time.Hour), 2*time.Hour)
+	other := newGrant("7b5d3f1e-9c8a-4e6b-a2d4-1f3e5a7c9b03", "tenant-b", now, time.Hour)
+	for _, grant := range []*models.AccessGrant{expired, active, other} {
+		require.NoError(t, db.CreateAccessGrant(ctx, grant))
+	}
+
+	due, err := db.ListExpiredAccessGrants(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, expired.ID, due[0].ID)
+
+	endedAt := now
+	expired.Status, expired.EndedAt = models.AccessGrantExpired, &endedAt
+	require.NoError(t, db.EndAccessGrant(ctx, expired))
+	due, err = db.ListExpiredAccessGrants(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	got, err := db.GetAccessGrant(ctx, expired.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, models.AccessGrantExpired, got.Status)
+	require.NotNil(t, got.EndedAt)
+	assert.True(t, endedAt.Equal(*got.EndedAt))
+
+	grants, err := db.ListAccessGrants(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, active.ID, grants[0].ID)
+	grants, err = db.ListAccessGrants(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, grants, 3)
+
+	got, err = db.GetAccessGrant(ctx, "7b5d3f1e-9c8a-4e6b-a2d4-1f3e5a7c9b09")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ACL rollout operations
+
+const aclRolloutColumns = `id, tenant_id, switch_id, rules, thresholds, status, observation, reason, created_by,
+	started_at, bake_until, ended_at, ended_by`
+
+// SaveACLRollout creates or replaces a rollout
+func (db *DB) SaveACLRollout(ctx context.Context, rollout *models.ACLRollout) error {
+	rules, thresholds, observation, err := marshalACLRollout(rollout)
+	if err != nil {
+		return fmt.Errorf("failed to save ACL rollout: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO acl_rollouts (`+aclRolloutColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET rules = EXCLUDED.rules, thresholds = EXCLUDED.thresholds,
+			status = EXCLUDED.status, observation = EXCLUDED.observation, reason = EXCLUDED.reason,
+			bake_until = EXCLUDED.bake_until, ended_at = EXCLUDED.ended_at, ended_by = EXCLUDED.ended_by`,
+		rollout.ID, rollout.TenantID, rollout.SwitchID, rules, thresholds, rollout.Status, observation,
+		rollout.Reason, rollout.CreatedBy, rollout.StartedAt.UTC(), rollout.BakeUntil.UTC(),
+		nullUTCTime(rollout.EndedAt), rollout.EndedBy)
+	if err != nil {
+		return fmt.Errorf("failed to save ACL rollout: %w", err)
+	}
+	return nil
+}
+
+// GetACLRollout retrieves a rollout by ID, nil when there's none with the
+// ID
+func (db *DB) GetACLRollout(ctx context.Context, id string) (*models.ACLRollout, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+aclRolloutColumns+` FROM acl_rollouts WHERE id = $1`, id)
+	rollout, err := scanACLRollout(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACL rollout: %w", err)
+	}
+	return rollout, nil
+}
+
+// ListACLRollouts lists the rollouts of tenantID, or all of them when it's
+// empty, oldest first
+func (db *DB) ListACLRollouts(ctx context.Context, tenantID string) ([]*models.ACLRollout, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+aclRolloutColumns+` FROM acl_rollouts
+		WHERE ($1 = '' OR tenant_id = $1)
+		ORDER BY started_at, id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACL rollouts: %w", err)
+	}
+	defer rows.Close()
+
+	rollouts := []*models.ACLRollout{}
+	for rows.Next() {
+		rollout, err := scanACLRollout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACL rollouts: %w", err)
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, rows.Err()
+}
+
+// marshalACLRollout encodes the rules, thresholds and observation of a
+// rollout, the observation empty when it hasn't been observed
+func marshalACLRollout(rollout *models.ACLRollout) (rules, thresholds, observation string, err error) {
+	r := rollout.Rules
+	if r == nil {
+		r = []*models.ACL{}
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", "", "", err
+	}
+	rules = string(b)
+	if b, err = json.Marshal(rollout.Thresholds); err != nil {
+		return "", "", "", err
+	}
+	thresholds = string(b)
+	if rollout.Observation != nil {
+		if b, err = json.Marshal(rollout.Observation); err != nil {
+			return "", "", "", err
+		}
+		observation = string(b)
+	}
+	return rules, thresholds, observation, nil
+}
+
+func scanACLRollout(row interface{ Scan(...interface{}) error }) (*models.ACLRollout, error) {
+	var rollout models.ACLRollout
+	var rules, thresholds, observation string
+	var endedAt sql.NullTime
+	if err := row.Scan(&rollout.ID, &rollout.TenantID, &rollout.SwitchID, &rules, &thresholds, &rollout.Status,
+		&observation, &rollout.Reason, &rollout.CreatedBy, &rollout.StartedAt, &rollout.BakeUntil, &endedAt,
+		&rollout.EndedBy); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(rules), &rollout.Rules); err != nil {
+		return nil, fmt.Errorf("invalid rules of ACL rollout %s: %w", rollout.ID, err)
+	}
+	if err := json.Unmarshal([]byte(thresholds), &rollout.Thresholds); err != nil {
+		return nil, fmt.Errorf("invalid thresholds of ACL rollout %s: %w", rollout.ID, err)
+	}
+	if observation != "" {
+		rollout.Observation = &models.ACLRolloutObservation{}
+		if err := json.Unmarshal([]byte(observation), rollout.Observation); err != nil {
+			return nil, fmt.Errorf("invalid observation of ACL rollout %s: %w", rollout.ID, err)
+		}
+	}
+	if endedAt.Valid {
+		rollout.EndedAt = &endedAt.Time
+	}
+	return &rollout, nil
+}
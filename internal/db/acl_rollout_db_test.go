@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestACLRollouts(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	maxHits := uint64(100)
+	rollout := &models.ACLRollout{
+		ID:         "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01",
+		TenantID:   "tenant-a",
+		SwitchID:   "sw-web",
+		Rules:      []*models.ACL{{UUID: "acl-1", Name: "deny-telnet", Action: "drop", Match: "tcp.dst == 23"}},
+		Thresholds: models.ACLRolloutThresholds{MaxHits: &maxHits},
+		Status:     models.ACLRolloutObserving,
+		CreatedBy:  "alice",
+		StartedAt:  now,
+		BakeUntil:  now.Add(time.Hour),
+	}
+	require.NoError(t, db.SaveACLRollout(ctx, rollout))
+	require.NoError(t, db.SaveACLRollout(ctx, &models.ACLRollout{
+		ID:        "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d02",
+		TenantID:  "tenant-b",
+		SwitchID:  "sw-db",
+		Status:    models.ACLRolloutObserving,
+		StartedAt: now.Add(time.Minute),
+		BakeUntil: now.Add(time.Hour),
+	}))
+
+	got, err := db.GetACLRollout(ctx, rollout.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, rollout.Rules, got.Rules)
+	require.NotNil(t, got.Thresholds.MaxHits)
+	assert.Equal(t, maxHits, *got.Thresholds.MaxHits)
+	assert.Nil(t, got.Observation)
+	assert.Nil(t, got.EndedAt)
+
+	// Saving again replaces the rollout
+	rollout.Observation = &models.ACLRolloutObservation{Hits: 250, HitsRecorded: true, ObservedAt: now}
+	rollout.Status = models.ACLRolloutRolledBack
+	rollout.Reason = "rules matched 250 packets"
+	rollout.EndedAt = &now
+	require.NoError(t, db.SaveACLRollout(ctx, rollout))
+
+	rollouts, err := db.ListACLRollouts(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, rollouts, 1)
+	assert.Equal(t, models.ACLRolloutRolledBack, rollouts[0].Status)
+	assert.Equal(t, "rules matched 250 packets", rollouts[0].Reason)
+	require.NotNil(t, rollouts[0].Observation)
+	assert.Equal(t, uint64(250), rollouts[0].Observation.Hits)
+	require.NotNil(t, rollouts[0].EndedAt)
+	assert.True(t, now.Equal(*rollouts[0].EndedAt))
+
+	rollouts, err = db.ListACLRollouts(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, rollouts, 2)
+	assert.Equal(t, rollout.ID, rollouts[0].ID)
+
+	got, err = db.GetACLRollout(ctx, "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d09")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ACL schedule operations
+
+const aclScheduleColumns = `acl_id, tenant_id, windows, timezone, acl_match, active, created_by, created_at, updated_at`
+
+// SaveACLSchedule creates or replaces the schedule of an ACL, keeping who
+// created it and when
+func (db *DB) SaveACLSchedule(ctx context.Context, schedule *models.ACLSchedule) error {
+	windows, err := json.Marshal(schedule.Windows)
+	if err != nil {
+		return fmt.Errorf("failed to save ACL schedule: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO acl_schedules (`+aclScheduleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (acl_id) DO UPDATE SET windows = EXCLUDED.windows, timezone = EXCLUDED.timezone,
+			acl_match = EXCLUDED.acl_match, active = EXCLUDED.active, updated_at = EXCLUDED.updated_at`,
+		schedule.ACLID, schedule.TenantID, string(windows), schedule.Timezone, schedule.Match, schedule.Active,
+		schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save ACL schedule: %w", err)
+	}
+	return nil
+}
+
+// GetACLSchedule retrieves the schedule of an ACL, nil when it has none
+func (db *DB) GetACLSchedule(ctx context.Context, aclID string) (*models.ACLSchedule, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+aclScheduleColumns+` FROM acl_schedules WHERE acl_id = $1`, aclID)
+	schedule, err := scanACLSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ACL schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// ListACLSchedules lists the ACL schedules of tenantID, or all of them
+// when it's empty
+func (db *DB) ListACLSchedules(ctx context.Context, tenantID string) ([]*models.ACLSchedule, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+aclScheduleColumns+` FROM acl_schedules
+		WHERE ($1 = '' OR tenant_id = $1)
+		ORDER BY created_at, acl_id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACL schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.ACLSchedule{}
+	for rows.Next() {
+		schedule, err := scanACLSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACL schedules: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// DeleteACLSchedule deletes the schedule of an ACL, leaving its toggles
+func (db *DB) DeleteACLSchedule(ctx context.Context, aclID string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM acl_schedules WHERE acl_id = $1`, aclID); err != nil {
+		return fmt.Errorf("failed to delete ACL schedule: %w", err)
+	}
+	return nil
+}
+
+// CreateACLScheduleToggle records a scheduled ACL being enabled or
+// disabled
+func (db *DB) CreateACLScheduleToggle(ctx context.Context, toggle *models.ACLScheduleToggle) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO acl_schedule_toggles
+		(id, acl_id, tenant_id, action, reason, user_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		toggle.ID, toggle.ACLID, toggle.TenantID, toggle.Action, toggle.Reason, toggle.UserID, toggle.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL schedule toggle: %w", err)
+	}
+	return nil
+}
+
+// ListACLScheduleToggles lists the toggles of an ACL, oldest first
+func (db *DB) ListACLScheduleToggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT id, acl_id, tenant_id, action, reason, user_id, occurred_at
+		FROM acl_schedule_toggles WHERE acl_id = $1
+		ORDER BY occurred_at, id`, aclID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACL schedule toggles: %w", err)
+	}
+	defer rows.Close()
+
+	toggles := []*models.ACLScheduleToggle{}
+	for rows.Next() {
+		var toggle models.ACLScheduleToggle
+		if err := rows.Scan(&toggle.ID, &toggle.ACLID, &toggle.TenantID, &toggle.Action, &toggle.Reason,
+			&toggle.UserID, &toggle.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to list ACL schedule toggles: %w", err)
+		}
+		toggles = append(toggles, &toggle)
+	}
+	return toggles, rows.Err()
+}
+
+func scanACLSchedule(row interface{ Scan(...interface{}) error }) (*models.ACLSchedule, error) {
+	var schedule models.ACLSchedule
+	var windows string
+	if err := row.Scan(&schedule.ACLID, &schedule.TenantID, &windows, &schedule.Timezone, &schedule.Match,
+		&schedule.Active, &schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(windows), &schedule.Windows); err != nil {
+		return nil, fmt.Errorf("invalid windows of ACL schedule %s: %w", schedule.ACLID, err)
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestACLSchedules(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	schedule := &models.ACLSchedule{
+		ACLID:     "acl-1",
+		TenantID:  "tenant-a",
+		Windows:   []models.ACLScheduleWindow{{Start: "09:00", End: "17:00", Days: []string{"mon", "tue"}}},
+		Timezone:  "Europe/Paris",
+		Match:     "ip4.src == 10.0.0.0/24",
+		Active:    true,
+		CreatedBy: "alice",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.SaveACLSchedule(ctx, schedule))
+
+	// Saving again replaces the schedule, keeping who created it and when
+	replaced := *schedule
+	replaced.Windows = []models.ACLScheduleWindow{{Start: "22:00", End: "06:00"}}
+	replaced.Active = false
+	replaced.CreatedBy = "bob"
+	replaced.CreatedAt = now.Add(time.Hour)
+	replaced.UpdatedAt = now.Add(time.Hour)
+	require.NoError(t, db.SaveACLSchedule(ctx, &replaced))
+
+	got, err := db.GetACLSchedule(ctx, "acl-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, replaced.Windows, got.Windows)
+	assert.False(t, got.Active)
+	assert.Equal(t, "alice", got.CreatedBy)
+	assert.True(t, now.Equal(got.CreatedAt))
+	assert.True(t, replaced.UpdatedAt.Equal(got.UpdatedAt))
+
+	schedules, err := db.ListACLSchedules(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+	schedules, err = db.ListACLSchedules(ctx, "tenant-b")
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+
+	for i, action := range []string{"disabled", "enabled"} {
+		require.NoError(t, db.CreateACLScheduleToggle(ctx, &models.ACLScheduleToggle{
+			ID:         []string{"4c2e0a8b-6d1f-4b3a-9e5c-7a9b1d3f5e01", "4c2e0a8b-6d1f-4b3a-9e5c-7a9b1d3f5e02"}[i],
+			ACLID:      "acl-1",
+			TenantID:   "tenant-a",
+			Action:     action,
+			Reason:     "schedule",
+			OccurredAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	// Toggles outlive their schedule
+	require.NoError(t, db.DeleteACLSchedule(ctx, "acl-1"))
+	got, err = db.GetACLSchedule(ctx, "acl-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	toggles, err := db.ListACLScheduleToggles(ctx, "acl-1")
+	require.NoError(t, err)
+	require.Len(t, toggles, 2)
+	assert.Equal(t, "disabled", toggles[0].Action)
+	assert.Equal(t, "enabled", toggles[1].Action)
+}
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// newTestDB returns an in-memory SQLite database with every migration
+// applied, closed when the test ends
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(&config.DatabaseConfig{Type: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Migrate())
+	return db
+}
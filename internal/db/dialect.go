@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// Names of the databases the repository runs on
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// dialect is what differs between the databases the repository runs on.
+// Queries are written once, with $1-style placeholders; only opening
+// connections and the migrations' DDL differ. SQLite takes $n for a named
+// parameter, bound by order of first appearance rather than by n, so the
+// placeholders of a query must be numbered in the order they appear.
+type dialect interface {
+	name() string
+	open(cfg *config.DatabaseConfig, password func() string) (*sql.DB, error)
+	// maxOpenConns returns the connection pool size when DB_MAX_OPEN_CONNS
+	// isn't set
+	maxOpenConns() int
+	// adaptMigration rewrites a migration written for Postgres
+	adaptMigration(sql string) string
+}
+
+// dialectFor returns the dialect of a DB_TYPE; anything but SQLite is
+// Postgres or compatible, such as CockroachDB
+func dialectFor(cfg *config.DatabaseConfig) dialect {
+	if cfg.IsPostgres() {
+		return postgresDialect{}
+	}
+	return sqliteDialect{memory: cfg.Type == "memory"}
+}
+
+// postgresDialect runs on a Postgres server
+type postgresDialect struct{}
+
+func (postgresDialect) name() string { return DialectPostgres }
+
+func (postgresDialect) open(cfg *config.DatabaseConfig, password func() string) (*sql.DB, error) {
+	return sql.OpenDB(&postgresConnector{cfg: cfg, password: password}), nil
+}
+
+func (postgresDialect) maxOpenConns() int { return 25 }
+
+func (postgresDialect) adaptMigration(sql string) string { return sql }
+
+// postgresConnector opens Postgres connections with the current password
+type postgresConnector struct {
+	cfg      *config.DatabaseConfig
+	password func() string
+	// connectTimeout bounds connecting, 0 waits as long as the OS does
+	connectTimeout time.Duration
+}
+
+func (c *postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.cfg.Host, c.cfg.Port, c.cfg.User, quoteDSNValue(c.password()), c.cfg.Name, c.cfg.SSLMode)
+	if c.connectTimeout > 0 {
+		dsn += fmt.Sprintf(" connect_timeout=%d", int(c.connectTimeout.Seconds()))
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *postgresConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// quoteDSNValue quotes a connection string value, which may contain spaces
+// and quotes, as generated passwords do
+func quoteDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// sqliteDialect runs on an embedded SQLite database, a file named by
+// DB_NAME, or in memory for DB_TYPE=memory, so single-binary installs need
+// no database server
+type sqliteDialect struct {
+	memory bool
+}
+
+func (sqliteDialect) name() string { return DialectSQLite }
+
+func (d sqliteDialect) open(cfg *config.DatabaseConfig, password func() string) (*sql.DB, error) {
+	// Writers wait for each other rather than fail with "database is
+	// locked", and WAL lets reads proceed during a write
+	params := url.Values{
+		"_busy_timeout": {"5000"},
+		"_foreign_keys": {"on"},
+	}
+	path := ":memory:"
+	if !d.memory {
+		// Default to a local file in the data directory
+		path = cfg.Name
+		if path == "" || path == "ovncp" {
+			path = "ovncp.db"
+		}
+		if dir := filepath.Dir(path); dir != "." && dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create the database directory: %w", err)
+			}
+		}
+		params.Set("_journal_mode", "WAL")
+	}
+	return sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+}
+
+// maxOpenConns is 1 in memory, each connection having a database of its
+// own; NewWithPassword enforces it
+func (d sqliteDialect) maxOpenConns() int {
+	if d.memory {
+		return 1
+	}
+	return 25
+}
+
+// adaptMigration converts PostgreSQL-specific syntax to SQLite
+func (sqliteDialect) adaptMigration(sql string) string {
+	// For SQLite, we'll use TEXT for UUID and generate them in the application
+	sql = strings.ReplaceAll(sql, "UUID PRIMARY KEY DEFAULT gen_random_uuid()", "TEXT PRIMARY KEY")
+	sql = strings.ReplaceAll(sql, "UUID", "TEXT")
+	sql = strings.ReplaceAll(sql, "TIMESTAMP WITH TIME ZONE", "DATETIME")
+	sql = strings.ReplaceAll(sql, "BIGSERIAL", "INTEGER")
+	sql = strings.ReplaceAll(sql, "SERIAL", "INTEGER")
+	sql = strings.ReplaceAll(sql, "BOOLEAN", "INTEGER")
+	sql = strings.ReplaceAll(sql, "true", "1")
+	sql = strings.ReplaceAll(sql, "false", "0")
+
+	// Remove PostgreSQL-specific function and trigger definitions
+	lines := strings.Split(sql, "\n")
+	var result []string
+	skipBlock := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Functions and triggers aren't created, so aren't dropped
+		if strings.HasPrefix(trimmed, "DROP FUNCTION") || strings.HasPrefix(trimmed, "DROP TRIGGER") {
+			continue
+		}
+
+		// Skip function and trigger blocks
+		if strings.Contains(trimmed, "CREATE OR REPLACE FUNCTION") ||
+			strings.Contains(trimmed, "CREATE TRIGGER") {
+			skipBlock = true
+			continue
+		}
+
+		// End of function block
+		if skipBlock && (strings.HasPrefix(trimmed, "$$ language") || strings.HasPrefix(trimmed, "$$;")) {
+			skipBlock = false
+			continue
+		}
+
+		// Triggers end with their statement
+		if skipBlock && strings.HasSuffix(trimmed, ";") && strings.Contains(trimmed, "EXECUTE FUNCTION") {
+			skipBlock = false
+			continue
+		}
+
+		if !skipBlock {
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
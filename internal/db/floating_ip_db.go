@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Floating IP operations
+
+const floatingIPColumns = `id, tenant_id, pool, ip, description, router_id, port_id, logical_ip, nat_id,
+	distributed, gratuitous_arp, allocated_by, allocated_at, bound_at`
+
+// CreateFloatingIP records fip unless its address is allocated already,
+// reporting whether it did
+func (db *DB) CreateFloatingIP(ctx context.Context, fip *models.FloatingIP) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `INSERT INTO floating_ips (`+floatingIPColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (ip) DO NOTHING`,
+		fip.ID, fip.TenantID, fip.Pool, fip.IP, fip.Description, fip.RouterID, fip.PortID, fip.LogicalIP,
+		fip.NATID, fip.Distributed, fip.GratuitousARP, fip.AllocatedBy, fip.AllocatedAt, nullTime(fip.BoundAt))
+	if err != nil {
+		return false, fmt.Errorf("failed to create floating IP: %w", err)
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return created == 1, nil
+}
+
+// GetFloatingIP retrieves a floating IP, nil when there's none with the ID
+func (db *DB) GetFloatingIP(ctx context.Context, id string) (*models.FloatingIP, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+floatingIPColumns+` FROM floating_ips WHERE id = $1`, id)
+	fip, err := scanFloatingIP(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get floating IP: %w", err)
+	}
+	return fip, nil
+}
+
+// ListFloatingIPs lists the floating IPs of tenantID, or all of them when
+// it's empty, in the order they were allocated
+func (db *DB) ListFloatingIPs(ctx context.Context, tenantID string) ([]*models.FloatingIP, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+floatingIPColumns+` FROM floating_ips
+		WHERE ($1 = '' OR tenant_id = $1)
+		ORDER BY allocated_at, ip`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list floating IPs: %w", err)
+	}
+	defer rows.Close()
+
+	fips := []*models.FloatingIP{}
+	for rows.Next() {
+		fip, err := scanFloatingIP(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list floating IPs: %w", err)
+		}
+		fips = append(fips, fip)
+	}
+	return fips, rows.Err()
+}
+
+// UpdateFloatingIP saves the description and binding of a floating IP
+func (db *DB) UpdateFloatingIP(ctx context.Context, fip *models.FloatingIP) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE floating_ips SET description = $1, port_id = $2, logical_ip = $3,
+		nat_id = $4, distributed = $5, gratuitous_arp = $6, bound_at = $7
+		WHERE id = $8`,
+		fip.Description, fip.PortID, fip.LogicalIP, fip.NATID, fip.Distributed, fip.GratuitousARP,
+		nullTime(fip.BoundAt), fip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update floating IP: %w", err)
+	}
+	return nil
+}
+
+// DeleteFloatingIP releases a floating IP's address
+func (db *DB) DeleteFloatingIP(ctx context.Context, id string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM floating_ips WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete floating IP: %w", err)
+	}
+	return nil
+}
+
+func scanFloatingIP(row interface{ Scan(...interface{}) error }) (*models.FloatingIP, error) {
+	var fip models.FloatingIP
+	var boundAt sql.NullTime
+	if err := row.Scan(&fip.ID, &fip.TenantID, &fip.Pool, &fip.IP, &fip.Description, &fip.RouterID, &fip.PortID,
+		&fip.LogicalIP, &fip.NATID, &fip.Distributed, &fip.GratuitousARP, &fip.AllocatedBy, &fip.AllocatedAt,
+		&boundAt); err != nil {
+		return nil, err
+	}
+	if boundAt.Valid {
+		fip.BoundAt = &boundAt.Time
+	}
+	return &fip, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestFloatingIPs(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	allocatedAt := time.Now().UTC().Truncate(time.Second)
+	fip := &models.FloatingIP{
+		ID:          "5b0f2a4e-1c1d-4f7e-9d7a-0d6b2c1e8f01",
+		TenantID:    "tenant-a",
+		Pool:        "public",
+		IP:          "172.16.0.10",
+		RouterID:    "lr-public",
+		AllocatedAt: allocatedAt,
+	}
+	created, err := db.CreateFloatingIP(ctx, fip)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// An address is allocated at most once
+	created, err = db.CreateFloatingIP(ctx, &models.FloatingIP{
+		ID: "5b0f2a4e-1c1d-4f7e-9d7a-0d6b2c1e8f02", Pool: "public", IP: "172.16.0.10",
+		RouterID: "lr-public", AllocatedAt: allocatedAt,
+	})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	boundAt := allocatedAt.Add(time.Minute)
+	fip.Description = "web"
+	fip.PortID, fip.LogicalIP, fip.NATID = "lsp-web", "10.0.0.10", "nat-1"
+	fip.Distributed, fip.BoundAt = true, &boundAt
+	require.NoError(t, db.UpdateFloatingIP(ctx, fip))
+
+	got, err := db.GetFloatingIP(ctx, fip.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "web", got.Description)
+	assert.Equal(t, "lsp-web", got.PortID)
+	assert.Equal(t, "10.0.0.10", got.LogicalIP)
+	assert.True(t, got.Distributed)
+	require.NotNil(t, got.BoundAt)
+	assert.True(t, boundAt.Equal(*got.BoundAt))
+
+	fips, err := db.ListFloatingIPs(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Len(t, fips, 1)
+	fips, err = db.ListFloatingIPs(ctx, "tenant-b")
+	require.NoError(t, err)
+	assert.Empty(t, fips)
+
+	require.NoError(t, db.DeleteFloatingIP(ctx, fip.ID))
+	got, err = db.GetFloatingIP(ctx, fip.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Maintenance mode operations
+
+// ListMaintenanceModes lists the maintenance modes set, the cluster's
+// first
+func (db *DB) ListMaintenanceModes(ctx context.Context) ([]*models.MaintenanceMode, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT tenant_id, frozen, reason, eta, set_by, set_at
+		FROM maintenance_modes ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance modes: %w", err)
+	}
+	defer rows.Close()
+
+	modes := []*models.MaintenanceMode{}
+	for rows.Next() {
+		var mode models.MaintenanceMode
+		var eta sql.NullTime
+		if err := rows.Scan(&mode.TenantID, &mode.Frozen, &mode.Reason, &eta, &mode.SetBy, &mode.SetAt); err != nil {
+			return nil, fmt.Errorf("failed to list maintenance modes: %w", err)
+		}
+		if eta.Valid {
+			mode.ETA = &eta.Time
+		}
+		modes = append(modes, &mode)
+	}
+	return modes, rows.Err()
+}
+
+// SaveMaintenanceMode sets the maintenance mode of mode.TenantID,
+// replacing the one set before
+func (db *DB) SaveMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error {
+	var eta sql.NullTime
+	if mode.ETA != nil {
+		eta = sql.NullTime{Time: *mode.ETA, Valid: true}
+	}
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO maintenance_modes (tenant_id, frozen, reason, eta, set_by, set_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id) DO UPDATE SET frozen = EXCLUDED.frozen, reason = EXCLUDED.reason,
+			eta = EXCLUDED.eta, set_by = EXCLUDED.set_by, set_at = EXCLUDED.set_at`,
+		mode.TenantID, mode.Frozen, mode.Reason, eta, mode.SetBy, mode.SetAt)
+	if err != nil {
+		return fmt.Errorf("failed to save maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// DeleteMaintenanceMode clears the maintenance mode of a tenant, "" for
+// the cluster, reporting whether one was set
+func (db *DB) DeleteMaintenanceMode(ctx context.Context, tenantID string) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `DELETE FROM maintenance_modes WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete maintenance mode: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestMaintenanceModes(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	eta := now.Add(time.Hour)
+	require.NoError(t, db.SaveMaintenanceMode(ctx, &models.MaintenanceMode{
+		Frozen: true, Reason: "upgrade", ETA: &eta, SetBy: "alice", SetAt: now,
+	}))
+	require.NoError(t, db.SaveMaintenanceMode(ctx, &models.MaintenanceMode{
+		TenantID: "tenant-a", Frozen: true, SetBy: "alice", SetAt: now,
+	}))
+
+	// Setting a mode again replaces it
+	require.NoError(t, db.SaveMaintenanceMode(ctx, &models.MaintenanceMode{
+		TenantID: "tenant-a", Frozen: false, Reason: "exempt", SetBy: "bob", SetAt: now.Add(time.Minute),
+	}))
+
+	modes, err := db.ListMaintenanceModes(ctx)
+	require.NoError(t, err)
+	require.Len(t, modes, 2)
+	assert.Equal(t, "", modes[0].TenantID)
+	assert.True(t, modes[0].Frozen)
+	require.NotNil(t, modes[0].ETA)
+	assert.True(t, eta.Equal(*modes[0].ETA))
+	assert.Equal(t, "tenant-a", modes[1].TenantID)
+	assert.False(t, modes[1].Frozen)
+	assert.Equal(t, "bob", modes[1].SetBy)
+	assert.Nil(t, modes[1].ETA)
+
+	deleted, err := db.DeleteMaintenanceMode(ctx, "")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = db.DeleteMaintenanceMode(ctx, "")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	modes, err = db.ListMaintenanceModes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, modes, 1)
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// MigrationStatus is whether a migration was applied
+type MigrationStatus struct {
+	Version   string     `json:"version"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrate applies the migrations not applied yet
+func (db *DB) Migrate() error {
+	_, err := db.MigrateUp()
+	return err
+}
+
+// MigrateUp applies the migrations not applied yet, in order, recording
+// them in schema_migrations, and returns their versions. Migrations are
+// the migrations/*.up.sql files, written for Postgres and adapted to the
+// dialect.
+func (db *DB) MigrateUp() ([]string, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+		if err := db.runMigration(status.Version, "up"); err != nil {
+			// Databases migrated before migrations were recorded already
+			// have the tables and indexes
+			if !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "duplicate") {
+				return applied, err
+			}
+		}
+		if err := db.recordMigration(status.Version); err != nil {
+			return applied, err
+		}
+		applied = append(applied, status.Version)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the last steps migrations applied, latest first, and
+// returns their versions
+func (db *DB) MigrateDown(steps int) ([]string, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []string
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+		version := statuses[i].Version
+		if !statuses[i].Applied {
+			continue
+		}
+		if err := db.runMigration(version, "down"); err != nil {
+			return reverted, err
+		}
+		if _, err := db.conn.Exec(`DELETE FROM schema_migrations WHERE version = $1`, version); err != nil {
+			return reverted, fmt.Errorf("failed to record migration %s reverted: %w", version, err)
+		}
+		reverted = append(reverted, version)
+	}
+	return reverted, nil
+}
+
+// Baseline records the migrations up to version as applied without running
+// them, for databases whose schema was created otherwise, e.g. by a DBA
+// from the migration files. It returns the versions recorded.
+func (db *DB) Baseline(version string) ([]string, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, status := range statuses {
+		if status.Version == version {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown migration %q", version)
+	}
+
+	var recorded []string
+	for _, status := range statuses {
+		if !status.Applied {
+			if err := db.recordMigration(status.Version); err != nil {
+				return recorded, err
+			}
+			recorded = append(recorded, status.Version)
+		}
+		if status.Version == version {
+			break
+		}
+	}
+	return recorded, nil
+}
+
+// MigrationStatus lists the migrations in order, with whether and when
+// each was applied
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	if _, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	appliedAt := make(map[string]time.Time)
+	for rows.Next() {
+		var version string
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(versions))
+	for _, version := range versions {
+		status := MigrationStatus{Version: version}
+		if at, ok := appliedAt[version]; ok {
+			status.Applied, status.AppliedAt = true, &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// PendingMigrations returns the versions of the migrations not applied yet
+func (db *DB) PendingMigrations() ([]string, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Version)
+		}
+	}
+	return pending, nil
+}
+
+// runMigration runs the up or down file of a migration
+func (db *DB) runMigration(version, direction string) error {
+	file := fmt.Sprintf("migrations/%s.%s.sql", version, direction)
+	content, err := migrationFS.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file %s: %w", file, err)
+	}
+	if _, err := db.conn.Exec(db.dialect.adaptMigration(string(content))); err != nil {
+		return fmt.Errorf("failed to execute migration %s: %w", file, err)
+	}
+	return nil
+}
+
+func (db *DB) recordMigration(version string) error {
+	if _, err := db.conn.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`,
+		version, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", version, err)
+	}
+	return nil
+}
+
+// migrationVersions returns the versions of the embedded migrations, in
+// the order they're applied
+func migrationVersions() ([]string, error) {
+	files, err := fs.Glob(migrationFS, "migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(files))
+	for _, file := range files {
+		versions = append(versions, strings.TrimSuffix(path.Base(file), ".up.sql"))
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
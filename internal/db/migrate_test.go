@@ -0,0 +1,28 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	db := newTestDB(t)
+
+	pending, err := db.PendingMigrations()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// Every migration reverts and applies again
+	versions, err := migrationVersions()
+	require.NoError(t, err)
+	reverted, err := db.MigrateDown(len(versions))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(versions))
+	assert.Equal(t, versions[len(versions)-1], reverted[0])
+
+	applied, err := db.MigrateUp()
+	require.NoError(t, err)
+	assert.Equal(t, versions, applied)
+}
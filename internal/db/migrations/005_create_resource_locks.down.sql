@@ -0,0 +1,2 @@
+-- Drop resource locks table
+DROP TABLE IF EXISTS resource_locks;
\ No newline at end of file
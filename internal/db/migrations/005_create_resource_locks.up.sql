@@ -0,0 +1,16 @@
+-- Create resource locks table; a resource has at most one lock
+CREATE TABLE IF NOT EXISTS resource_locks (
+    id UUID NOT NULL UNIQUE,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    holder VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    automatic BOOLEAN NOT NULL DEFAULT false,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+-- Create index on tenant_id for listing a tenant's locks
+CREATE INDEX IF NOT EXISTS idx_resource_locks_tenant_id ON resource_locks(tenant_id);
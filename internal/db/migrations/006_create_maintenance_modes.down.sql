@@ -0,0 +1,2 @@
+-- Drop maintenance modes table
+DROP TABLE IF EXISTS maintenance_modes;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Create maintenance modes table; '' is the cluster-wide mode
+CREATE TABLE IF NOT EXISTS maintenance_modes (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    frozen BOOLEAN NOT NULL DEFAULT true,
+    reason TEXT NOT NULL DEFAULT '',
+    eta TIMESTAMP WITH TIME ZONE,
+    set_by VARCHAR(255) NOT NULL DEFAULT '',
+    set_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Drop floating IPs table
+DROP TABLE IF EXISTS floating_ips;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- Create floating IPs table; an address is allocated at most once
+CREATE TABLE IF NOT EXISTS floating_ips (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    pool VARCHAR(255) NOT NULL,
+    ip VARCHAR(64) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    router_id VARCHAR(255) NOT NULL,
+    port_id VARCHAR(255) NOT NULL DEFAULT '',
+    logical_ip VARCHAR(64) NOT NULL DEFAULT '',
+    nat_id VARCHAR(255) NOT NULL DEFAULT '',
+    distributed BOOLEAN NOT NULL DEFAULT false,
+    gratuitous_arp BOOLEAN NOT NULL DEFAULT false,
+    allocated_by VARCHAR(255) NOT NULL DEFAULT '',
+    allocated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    bound_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index on tenant_id for listing a tenant's floating IPs
+CREATE INDEX IF NOT EXISTS idx_floating_ips_tenant_id ON floating_ips(tenant_id);
@@ -0,0 +1,2 @@
+-- Drop VPN connections table
+DROP TABLE IF EXISTS vpn_connections;
@@ -0,0 +1,21 @@
+-- Create VPN connections table; subnets and status are kept as JSON
+CREATE TABLE IF NOT EXISTS vpn_connections (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    router_id VARCHAR(255) NOT NULL,
+    gateway_ip VARCHAR(64) NOT NULL,
+    peer_address VARCHAR(255) NOT NULL,
+    psk_ref TEXT NOT NULL,
+    local_subnets TEXT NOT NULL,
+    remote_subnets TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (tenant_id, name)
+);
+
+-- Create index on tenant_id for listing a tenant's VPN connections
+CREATE INDEX IF NOT EXISTS idx_vpn_connections_tenant_id ON vpn_connections(tenant_id);
@@ -0,0 +1,2 @@
+-- Drop service entries table
+DROP TABLE IF EXISTS service_entries;
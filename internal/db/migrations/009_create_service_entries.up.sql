@@ -0,0 +1,13 @@
+-- Create service entries table for the custom entries of the service
+-- catalog; ports are kept as JSON
+CREATE TABLE IF NOT EXISTS service_entries (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    ports TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (tenant_id, name)
+);
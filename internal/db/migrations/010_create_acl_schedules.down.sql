@@ -0,0 +1,3 @@
+-- Drop ACL schedule tables
+DROP TABLE IF EXISTS acl_schedule_toggles;
+DROP TABLE IF EXISTS acl_schedules;
@@ -0,0 +1,30 @@
+-- Create ACL schedules table; windows are kept as JSON
+CREATE TABLE IF NOT EXISTS acl_schedules (
+    acl_id VARCHAR(255) PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    windows TEXT NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    acl_match TEXT NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on tenant_id for listing a tenant's ACL schedules
+CREATE INDEX IF NOT EXISTS idx_acl_schedules_tenant_id ON acl_schedules(tenant_id);
+
+-- Create ACL schedule toggles table, the audit trail of scheduled ACLs;
+-- toggles outlive the schedules they were made by
+CREATE TABLE IF NOT EXISTS acl_schedule_toggles (
+    id UUID PRIMARY KEY,
+    acl_id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on acl_id and occurred_at for listing an ACL's toggles
+CREATE INDEX IF NOT EXISTS idx_acl_schedule_toggles_acl_id ON acl_schedule_toggles(acl_id, occurred_at);
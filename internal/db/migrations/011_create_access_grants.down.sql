@@ -0,0 +1,2 @@
+-- Drop access grants table
+DROP TABLE IF EXISTS access_grants;
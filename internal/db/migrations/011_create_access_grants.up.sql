@@ -0,0 +1,24 @@
+-- Create access grants table; ended grants are kept as their record
+CREATE TABLE IF NOT EXISTS access_grants (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    switch_id VARCHAR(255) NOT NULL,
+    port_id VARCHAR(255) NOT NULL DEFAULT '',
+    port_name VARCHAR(255) NOT NULL DEFAULT '',
+    service VARCHAR(255) NOT NULL DEFAULT '',
+    source_cidr VARCHAR(64) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    acl_id VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    granted_by VARCHAR(255) NOT NULL DEFAULT '',
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- Create index on tenant_id for listing a tenant's access grants
+CREATE INDEX IF NOT EXISTS idx_access_grants_tenant_id ON access_grants(tenant_id);
+
+-- Create index on status and expires_at for finding the expired grants
+CREATE INDEX IF NOT EXISTS idx_access_grants_expires_at ON access_grants(status, expires_at);
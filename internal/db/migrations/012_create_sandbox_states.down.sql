@@ -0,0 +1,2 @@
+-- Drop sandbox states table
+DROP TABLE IF EXISTS sandbox_states;
@@ -0,0 +1,7 @@
+-- Create sandbox states table; each holds the northbound database of an
+-- in-memory sandbox OVN backend as JSON
+CREATE TABLE IF NOT EXISTS sandbox_states (
+    name VARCHAR(255) PRIMARY KEY,
+    state TEXT NOT NULL,
+    saved_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
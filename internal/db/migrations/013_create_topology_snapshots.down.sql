@@ -0,0 +1,2 @@
+-- Drop topology snapshots table
+DROP TABLE IF EXISTS topology_snapshots;
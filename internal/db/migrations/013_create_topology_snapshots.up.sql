@@ -0,0 +1,14 @@
+-- Create topology snapshots table; each holds a cluster's topology as JSON
+CREATE TABLE IF NOT EXISTS topology_snapshots (
+    id UUID PRIMARY KEY,
+    cluster VARCHAR(255) NOT NULL DEFAULT '',
+    taken_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    data TEXT NOT NULL
+);
+
+-- Create index on cluster and taken_at for finding a cluster's snapshots
+-- by time
+CREATE INDEX IF NOT EXISTS idx_topology_snapshots_cluster_taken_at ON topology_snapshots(cluster, taken_at);
+
+-- Create index on taken_at for deleting the expired snapshots
+CREATE INDEX IF NOT EXISTS idx_topology_snapshots_taken_at ON topology_snapshots(taken_at);
@@ -0,0 +1,2 @@
+-- Drop ACL stats samples table
+DROP TABLE IF EXISTS acl_stats_samples;
@@ -0,0 +1,11 @@
+-- Create ACL stats samples table, the history of ACL counters
+CREATE TABLE IF NOT EXISTS acl_stats_samples (
+    acl_id VARCHAR(255) NOT NULL,
+    packets BIGINT NOT NULL,
+    bytes BIGINT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (acl_id, recorded_at)
+);
+
+-- Create index on recorded_at for deleting the expired samples
+CREATE INDEX IF NOT EXISTS idx_acl_stats_samples_recorded_at ON acl_stats_samples(recorded_at);
@@ -0,0 +1,2 @@
+-- Drop ACL log entries table
+DROP TABLE IF EXISTS acl_log_entries;
@@ -0,0 +1,20 @@
+-- Create ACL log entries table, the packets logged by ACLs
+CREATE TABLE IF NOT EXISTS acl_log_entries (
+    id BIGSERIAL PRIMARY KEY,
+    acl_name VARCHAR(255) NOT NULL DEFAULT '',
+    verdict VARCHAR(16) NOT NULL,
+    severity VARCHAR(16) NOT NULL DEFAULT '',
+    direction VARCHAR(16) NOT NULL DEFAULT '',
+    protocol VARCHAR(16) NOT NULL DEFAULT '',
+    src_ip VARCHAR(64) NOT NULL DEFAULT '',
+    dst_ip VARCHAR(64) NOT NULL DEFAULT '',
+    src_port INTEGER NOT NULL DEFAULT 0,
+    dst_port INTEGER NOT NULL DEFAULT 0,
+    logged_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on acl_name and logged_at for listing an ACL's entries
+CREATE INDEX IF NOT EXISTS idx_acl_log_entries_acl_name ON acl_log_entries(acl_name, logged_at);
+
+-- Create index on logged_at for deleting the expired entries
+CREATE INDEX IF NOT EXISTS idx_acl_log_entries_logged_at ON acl_log_entries(logged_at);
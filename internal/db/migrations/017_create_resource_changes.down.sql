@@ -0,0 +1,2 @@
+-- Drop resource changes table
+DROP TABLE IF EXISTS resource_changes;
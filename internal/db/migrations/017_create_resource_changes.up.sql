@@ -0,0 +1,19 @@
+-- Create resource changes table, the history of resources changed
+-- through the API
+CREATE TABLE IF NOT EXISTS resource_changes (
+    id UUID PRIMARY KEY,
+    resource_id VARCHAR(255) NOT NULL,
+    resource VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(64) NOT NULL DEFAULT '',
+    cluster VARCHAR(255) NOT NULL DEFAULT '',
+    method VARCHAR(16) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on resource_id and occurred_at for listing a resource's
+-- changes
+CREATE INDEX IF NOT EXISTS idx_resource_changes_resource_id ON resource_changes(resource_id, occurred_at);
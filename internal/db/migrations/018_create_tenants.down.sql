@@ -0,0 +1,9 @@
+-- Drop tenant tables
+DROP TABLE IF EXISTS tenant_changes;
+DROP TABLE IF EXISTS tenant_api_keys;
+DROP TABLE IF EXISTS tenant_invitations;
+DROP TABLE IF EXISTS tenant_usage_snapshots;
+DROP TABLE IF EXISTS tenant_resource_usage;
+DROP TABLE IF EXISTS tenant_resources;
+DROP TABLE IF EXISTS tenant_memberships;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,125 @@
+-- Create tenants table; metadata, settings and quotas are kept as JSON
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    parent VARCHAR(255),
+    metadata TEXT NOT NULL,
+    settings TEXT NOT NULL,
+    quotas TEXT NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on parent for listing a tenant's children
+CREATE INDEX IF NOT EXISTS idx_tenants_parent ON tenants(parent);
+
+-- Create tenant memberships table; a user is a member of a tenant once
+CREATE TABLE IF NOT EXISTS tenant_memberships (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (tenant_id, user_id)
+);
+
+-- Create index on user_id for listing a user's tenants
+CREATE INDEX IF NOT EXISTS idx_tenant_memberships_user_id ON tenant_memberships(user_id);
+
+-- Create tenant resources table, the OVN objects owned by tenants
+CREATE TABLE IF NOT EXISTS tenant_resources (
+    resource_id VARCHAR(255) PRIMARY KEY,
+    resource_type VARCHAR(32) NOT NULL,
+    tenant_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on tenant_id for listing a tenant's resources
+CREATE INDEX IF NOT EXISTS idx_tenant_resources_tenant_id ON tenant_resources(tenant_id);
+
+-- Create tenant resource usage table, the number of resources of each type
+-- a tenant has
+CREATE TABLE IF NOT EXISTS tenant_resource_usage (
+    tenant_id VARCHAR(255) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    count INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (tenant_id, resource_type)
+);
+
+-- Create tenant usage snapshots table, the history of tenants' usage kept
+-- as JSON
+CREATE TABLE IF NOT EXISTS tenant_usage_snapshots (
+    tenant_id VARCHAR(255) NOT NULL,
+    usage TEXT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (tenant_id, recorded_at)
+);
+
+-- Create tenant invitations table
+CREATE TABLE IF NOT EXISTS tenant_invitations (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    token VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create tenant API keys table; scopes and allowed CIDRs are kept as JSON,
+-- secrets only as hashes
+CREATE TABLE IF NOT EXISTS tenant_api_keys (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    key_hash VARCHAR(255) NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    scopes TEXT NOT NULL,
+    allowed_cidrs TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    usage_count BIGINT NOT NULL DEFAULT 0,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    previous_key_hash VARCHAR(255) NOT NULL DEFAULT '',
+    previous_prefix VARCHAR(32) NOT NULL DEFAULT '',
+    previous_expires_at TIMESTAMP WITH TIME ZONE,
+    rotated_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes on tenant_id for listing a tenant's keys, and on the
+-- prefixes for finding the keys a secret may be of
+CREATE INDEX IF NOT EXISTS idx_tenant_api_keys_tenant_id ON tenant_api_keys(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_tenant_api_keys_prefix ON tenant_api_keys(prefix);
+CREATE INDEX IF NOT EXISTS idx_tenant_api_keys_previous_prefix ON tenant_api_keys(previous_prefix);
+
+-- Create tenant changes table, the writes held for approval; operations
+-- and results are kept as JSON
+CREATE TABLE IF NOT EXISTS tenant_changes (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cluster VARCHAR(255) NOT NULL DEFAULT '',
+    operations TEXT NOT NULL,
+    requested_by VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+    review_comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    result TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index on tenant_id and created_at for listing a tenant's changes
+CREATE INDEX IF NOT EXISTS idx_tenant_changes_tenant_id ON tenant_changes(tenant_id, created_at);
@@ -0,0 +1,2 @@
+-- Drop ACL rollouts table
+DROP TABLE IF EXISTS acl_rollouts;
@@ -0,0 +1,20 @@
+-- Create ACL rollouts table; rules, thresholds and the last observation are
+-- kept as JSON
+CREATE TABLE IF NOT EXISTS acl_rollouts (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL DEFAULT '',
+    switch_id VARCHAR(255) NOT NULL,
+    rules TEXT NOT NULL,
+    thresholds TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    observation TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    bake_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    ended_by VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- Create index on tenant_id for listing a tenant's ACL rollouts
+CREATE INDEX IF NOT EXISTS idx_acl_rollouts_tenant_id ON acl_rollouts(tenant_id);
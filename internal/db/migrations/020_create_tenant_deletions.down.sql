@@ -0,0 +1,2 @@
+-- Drop tenant deletions table
+DROP TABLE IF EXISTS tenant_deletions;
@@ -0,0 +1,15 @@
+-- Create tenant deletions table, the progress of deleting each tenant; the
+-- resources left by type are kept as JSON
+CREATE TABLE IF NOT EXISTS tenant_deletions (
+    tenant_id VARCHAR(255) PRIMARY KEY,
+    status VARCHAR(16) NOT NULL,
+    total INTEGER NOT NULL,
+    deleted INTEGER NOT NULL,
+    remaining TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index on status for finding the deletions to carry out
+CREATE INDEX IF NOT EXISTS idx_tenant_deletions_status ON tenant_deletions(status);
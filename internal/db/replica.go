@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+const (
+	// replicaConnectTimeout bounds connecting to the read replica, so that
+	// queries fall back to the primary soon when it's unreachable
+	replicaConnectTimeout = 5 * time.Second
+	// replicaRetryInterval is how long the replica pool connects to the
+	// primary after failing to connect to the replica
+	replicaRetryInterval = 30 * time.Second
+)
+
+// primaryDB names the DB a ReplicatedDB embeds, whose DB method its field
+// would otherwise shadow
+type primaryDB = DB
+
+// ReplicatedDB is a DB whose usage history, audit and report queries run
+// on a read replica of the Postgres database, so they don't load the
+// primary. They tolerate the replica lagging behind the primary.
+//
+// While the replica can't be reached, the replica pool connects to the
+// primary instead, retrying the replica every replicaRetryInterval; its
+// connections to the primary are replaced as they reach
+// DB_CONN_MAX_LIFETIME. A query running when the replica goes down fails.
+type ReplicatedDB struct {
+	*primaryDB
+	replica   *DB
+	connector *fallbackConnector
+}
+
+// NewReplicated returns primary with a pool reading from the replica at
+// cfg.ReplicaHost, authenticating as on the primary
+func NewReplicated(primary *DB, cfg *config.DatabaseConfig, password func() string) *ReplicatedDB {
+	replicaCfg := *cfg
+	replicaCfg.Host = cfg.ReplicaHost
+	if cfg.ReplicaPort != "" {
+		replicaCfg.Port = cfg.ReplicaPort
+	}
+	connector := &fallbackConnector{
+		replica: &postgresConnector{cfg: &replicaCfg, password: password, connectTimeout: replicaConnectTimeout},
+		primary: &postgresConnector{cfg: cfg, password: password},
+		now:     time.Now,
+	}
+	conn := sql.OpenDB(connector)
+	configurePool(conn, cfg, primary.dialect)
+
+	return &ReplicatedDB{
+		primaryDB: primary,
+		replica:   &DB{conn: conn, dialect: primary.dialect},
+		connector: connector,
+	}
+}
+
+// Replica returns the replica's connection pool, e.g. for its metrics
+func (r *ReplicatedDB) Replica() *sql.DB {
+	return r.replica.conn
+}
+
+// ReplicaEndpoint returns the replica's host:port
+func (r *ReplicatedDB) ReplicaEndpoint() string {
+	cfg := r.connector.replica.cfg
+	return net.JoinHostPort(cfg.Host, cfg.Port)
+}
+
+// PingReplica connects to the replica itself, without falling back to the
+// primary
+func (r *ReplicatedDB) PingReplica(ctx context.Context) error {
+	conn, err := r.connector.replica.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if pinger, ok := conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Close closes both connection pools
+func (r *ReplicatedDB) Close() error {
+	replicaErr := r.replica.Close()
+	if err := r.primaryDB.Close(); err != nil {
+		return err
+	}
+	return replicaErr
+}
+
+// QueryReadOnly runs a read-only query on the replica
+func (r *ReplicatedDB) QueryReadOnly(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.replica.QueryReadOnly(query, args...)
+}
+
+// ListUsageSnapshots reads tenants' usage history from the replica
+func (r *ReplicatedDB) ListUsageSnapshots(ctx context.Context, tenantID string, from, to time.Time) ([]*models.TenantUsageSnapshot, error) {
+	return r.replica.ListUsageSnapshots(ctx, tenantID, from, to)
+}
+
+// ListResourceChanges reads resources' history from the replica
+func (r *ReplicatedDB) ListResourceChanges(ctx context.Context, resourceID string) ([]*models.ResourceChange, error) {
+	return r.replica.ListResourceChanges(ctx, resourceID)
+}
+
+// FindTopologySnapshot reads past topologies from the replica
+func (r *ReplicatedDB) FindTopologySnapshot(ctx context.Context, cluster string, at time.Time) (*models.TopologySnapshot, error) {
+	return r.replica.FindTopologySnapshot(ctx, cluster, at)
+}
+
+// ListTopologySnapshots reads past topologies from the replica
+func (r *ReplicatedDB) ListTopologySnapshots(ctx context.Context, cluster string, from, to time.Time) ([]*models.TopologySnapshot, error) {
+	return r.replica.ListTopologySnapshots(ctx, cluster, from, to)
+}
+
+// ListACLStatsSamples reads ACL counters' history from the replica
+func (r *ReplicatedDB) ListACLStatsSamples(ctx context.Context, aclID string, from, to time.Time) ([]*models.ACLStatsSample, error) {
+	return r.replica.ListACLStatsSamples(ctx, aclID, from, to)
+}
+
+// ListACLLogEntries reads ACL logs from the replica
+func (r *ReplicatedDB) ListACLLogEntries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	return r.replica.ListACLLogEntries(ctx, filter)
+}
+
+// fallbackConnector connects to the replica, or to the primary while the
+// replica can't be reached
+type fallbackConnector struct {
+	replica *postgresConnector
+	primary *postgresConnector
+	now     func() time.Time
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (c *fallbackConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	replicaDown := c.now().Before(c.downUntil)
+	c.mu.Unlock()
+
+	if !replicaDown {
+		conn, err := c.replica.Connect(ctx)
+		if err == nil || ctx.Err() != nil {
+			return conn, err
+		}
+		c.mu.Lock()
+		c.downUntil = c.now().Add(replicaRetryInterval)
+		c.mu.Unlock()
+	}
+	return c.primary.Connect(ctx)
+}
+
+func (c *fallbackConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Repository keeps the API's state. *DB implements it on Postgres or an
+// embedded SQLite database, chosen with DB_TYPE; services take the parts
+// of it they need.
+type Repository interface {
+	TenantRepository
+	WebhookRepository
+	HistoryRepository
+	ResourceLockRepository
+	MaintenanceRepository
+	FloatingIPRepository
+	VPNConnectionRepository
+	ServiceEntryRepository
+	ACLScheduleRepository
+	ACLRolloutRepository
+	AccessGrantRepository
+	SandboxRepository
+
+	// Exec and Query run SQL of the caller's own, e.g. against the audit
+	// log, with $1-style placeholders
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	// QueryReadOnly runs a read-only query that tolerates replication lag,
+	// on the read replica if there's one
+	QueryReadOnly(query string, args ...interface{}) (*sql.Rows, error)
+	// DB returns the connection pool, e.g. for health checks
+	DB() *sql.DB
+	Dialect() string
+	Migrate() error
+	Close() error
+}
+
+var (
+	_ Repository = (*DB)(nil)
+	_ Repository = (*ReplicatedDB)(nil)
+)
+
+// TenantRepository keeps tenants, their members, resources, usage,
+// invitations, API keys, pending changes and deletions
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant *models.Tenant) error
+	GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error)
+	UpdateTenant(ctx context.Context, tenantID string, tenant *models.Tenant) error
+	DeleteTenant(ctx context.Context, tenantID string) error
+	ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error)
+
+	CreateTenantMembership(ctx context.Context, membership *models.TenantMembership) error
+	GetTenantMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error)
+	UpdateTenantMembership(ctx context.Context, membership *models.TenantMembership) error
+	DeleteTenantMembership(ctx context.Context, tenantID, userID string) error
+	ListTenantMembers(ctx context.Context, tenantID string) ([]*models.TenantMembership, error)
+
+	CreateTenantResource(ctx context.Context, resource *models.TenantResource) error
+	GetTenantResource(ctx context.Context, resourceID string) (*models.TenantResource, error)
+	DeleteTenantResource(ctx context.Context, resourceID string) error
+	ListTenantResources(ctx context.Context, tenantID string) ([]*models.TenantResource, error)
+	GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error)
+	UpdateResourceUsage(ctx context.Context, tenantID, resourceType string, delta int) error
+	CreateUsageSnapshot(ctx context.Context, snapshot *models.TenantUsageSnapshot) error
+	ListUsageSnapshots(ctx context.Context, tenantID string, from, to time.Time) ([]*models.TenantUsageSnapshot, error)
+
+	CreateTenantInvitation(ctx context.Context, invitation *models.TenantInvitation) error
+	GetTenantInvitationByToken(ctx context.Context, token string) (*models.TenantInvitation, error)
+	UpdateTenantInvitation(ctx context.Context, invitation *models.TenantInvitation) error
+
+	CreateTenantAPIKey(ctx context.Context, key *models.TenantAPIKey) error
+	GetTenantAPIKey(ctx context.Context, keyID string) (*models.TenantAPIKey, error)
+	GetTenantAPIKeyByHash(ctx context.Context, keyHash string) (*models.TenantAPIKey, error)
+	ListTenantAPIKeysByPrefix(ctx context.Context, prefix string) ([]*models.TenantAPIKey, error)
+	RecordTenantAPIKeyUse(ctx context.Context, keyID string, usedAt time.Time) error
+	UpdateTenantAPIKey(ctx context.Context, key *models.TenantAPIKey) error
+	DeleteTenantAPIKey(ctx context.Context, keyID string) error
+	ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*models.TenantAPIKey, error)
+
+	CreateTenantChange(ctx context.Context, change *models.TenantChange) error
+	GetTenantChange(ctx context.Context, changeID string) (*models.TenantChange, error)
+	UpdateTenantChange(ctx context.Context, change *models.TenantChange) error
+	ListTenantChanges(ctx context.Context, tenantID string, status models.TenantChangeStatus) ([]*models.TenantChange, error)
+
+	SaveTenantDeletion(ctx context.Context, deletion *models.TenantDeletion) error
+	GetTenantDeletion(ctx context.Context, tenantID string) (*models.TenantDeletion, error)
+	ListTenantDeletions(ctx context.Context, status models.TenantDeletionStatus) ([]*models.TenantDeletion, error)
+}
+
+// WebhookRepository keeps webhooks, their deliveries and the resource
+// changes they notify of
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error)
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time) ([]*models.WebhookDelivery, error)
+
+	CreateResourceChange(ctx context.Context, change *models.ResourceChange) error
+	ListResourceChanges(ctx context.Context, resourceID string) ([]*models.ResourceChange, error)
+}
+
+// HistoryRepository keeps what's recorded over time: topology snapshots,
+// ACL counters and ACL logs
+type HistoryRepository interface {
+	CreateTopologySnapshot(ctx context.Context, snapshot *models.TopologySnapshot) error
+	GetTopologySnapshot(ctx context.Context, id string) (*models.TopologySnapshot, error)
+	FindTopologySnapshot(ctx context.Context, cluster string, at time.Time) (*models.TopologySnapshot, error)
+	ListTopologySnapshots(ctx context.Context, cluster string, from, to time.Time) ([]*models.TopologySnapshot, error)
+	DeleteTopologySnapshotsBefore(ctx context.Context, cutoff time.Time) error
+
+	CreateACLStatsSamples(ctx context.Context, samples []*models.ACLStatsSample) error
+	ListACLStatsSamples(ctx context.Context, aclID string, from, to time.Time) ([]*models.ACLStatsSample, error)
+	DeleteACLStatsSamplesBefore(ctx context.Context, cutoff time.Time) error
+
+	CreateACLLogEntries(ctx context.Context, entries []*models.ACLLogEntry) error
+	ListACLLogEntries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error)
+	DeleteACLLogEntriesBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// ResourceLockRepository keeps the locks on resources
+type ResourceLockRepository interface {
+	CreateResourceLock(ctx context.Context, lock *models.ResourceLock) (bool, error)
+	GetResourceLock(ctx context.Context, resourceType, resourceID string, now time.Time) (*models.ResourceLock, error)
+	ListResourceLocks(ctx context.Context, tenantID string, now time.Time) ([]*models.ResourceLock, error)
+	ExtendResourceLock(ctx context.Context, id string, expiresAt time.Time, reason string, now time.Time) (bool, error)
+	DeleteResourceLock(ctx context.Context, id string) error
+}
+
+// MaintenanceRepository keeps the maintenance modes of the cluster and of
+// tenants
+type MaintenanceRepository interface {
+	ListMaintenanceModes(ctx context.Context) ([]*models.MaintenanceMode, error)
+	SaveMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error
+	DeleteMaintenanceMode(ctx context.Context, tenantID string) (bool, error)
+}
+
+// FloatingIPRepository keeps the floating IPs allocated to tenants
+type FloatingIPRepository interface {
+	CreateFloatingIP(ctx context.Context, fip *models.FloatingIP) (bool, error)
+	GetFloatingIP(ctx context.Context, id string) (*models.FloatingIP, error)
+	ListFloatingIPs(ctx context.Context, tenantID string) ([]*models.FloatingIP, error)
+	UpdateFloatingIP(ctx context.Context, fip *models.FloatingIP) error
+	DeleteFloatingIP(ctx context.Context, id string) error
+}
+
+// VPNConnectionRepository keeps the VPN connections of tenant routers and
+// their last tunnel status
+type VPNConnectionRepository interface {
+	CreateVPNConnection(ctx context.Context, conn *models.VPNConnection) (bool, error)
+	GetVPNConnection(ctx context.Context, id string) (*models.VPNConnection, error)
+	ListVPNConnections(ctx context.Context, tenantID string) ([]*models.VPNConnection, error)
+	UpdateVPNConnection(ctx context.Context, conn *models.VPNConnection) error
+	UpdateVPNConnectionStatus(ctx context.Context, id string, status *models.VPNTunnelStatus) error
+	DeleteVPNConnection(ctx context.Context, id string) error
+}
+
+// ServiceEntryRepository keeps the custom entries of the service catalog
+type ServiceEntryRepository interface {
+	CreateServiceEntry(ctx context.Context, entry *models.ServiceEntry) (bool, error)
+	GetServiceEntry(ctx context.Context, tenantID, name string) (*models.ServiceEntry, error)
+	ListServiceEntries(ctx context.Context) ([]*models.ServiceEntry, error)
+	UpdateServiceEntry(ctx context.Context, entry *models.ServiceEntry) error
+	DeleteServiceEntry(ctx context.Context, id string) error
+}
+
+// ACLScheduleRepository keeps the activation windows of scheduled ACLs and
+// the record of their toggles
+type ACLScheduleRepository interface {
+	SaveACLSchedule(ctx context.Context, schedule *models.ACLSchedule) error
+	GetACLSchedule(ctx context.Context, aclID string) (*models.ACLSchedule, error)
+	ListACLSchedules(ctx context.Context, tenantID string) ([]*models.ACLSchedule, error)
+	DeleteACLSchedule(ctx context.Context, aclID string) error
+	CreateACLScheduleToggle(ctx context.Context, toggle *models.ACLScheduleToggle) error
+	ListACLScheduleToggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error)
+}
+
+// ACLRolloutRepository keeps the staged rollouts of ACLs, including those
+// that ended
+type ACLRolloutRepository interface {
+	SaveACLRollout(ctx context.Context, rollout *models.ACLRollout) error
+	GetACLRollout(ctx context.Context, id string) (*models.ACLRollout, error)
+	ListACLRollouts(ctx context.Context, tenantID string) ([]*models.ACLRollout, error)
+}
+
+// AccessGrantRepository keeps temporary access grants, including those
+// that ended
+type AccessGrantRepository interface {
+	CreateAccessGrant(ctx context.Context, grant *models.AccessGrant) error
+	GetAccessGrant(ctx context.Context, id string) (*models.AccessGrant, error)
+	ListAccessGrants(ctx context.Context, tenantID string) ([]*models.AccessGrant, error)
+	ListExpiredAccessGrants(ctx context.Context, now time.Time) ([]*models.AccessGrant, error)
+	EndAccessGrant(ctx context.Context, grant *models.AccessGrant) error
+}
+
+// SandboxRepository keeps the state of the sandbox OVN backend, which
+// serves the API from memory instead of a real OVN deployment
+type SandboxRepository interface {
+	LoadSandboxState(ctx context.Context, name string) ([]byte, error)
+	SaveSandboxState(ctx context.Context, name string, state []byte) error
+}
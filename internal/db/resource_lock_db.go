@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Resource lock operations
+
+const resourceLockColumns = `id, resource_type, resource_id, tenant_id, holder, reason, automatic, acquired_at, expires_at`
+
+// CreateResourceLock records lock unless its resource has a lock that
+// hasn't expired, reporting whether it did
+func (db *DB) CreateResourceLock(ctx context.Context, lock *models.ResourceLock) (bool, error) {
+	if _, err := db.conn.ExecContext(ctx,
+		`DELETE FROM resource_locks WHERE resource_type = $1 AND resource_id = $2 AND expires_at <= $3`,
+		lock.ResourceType, lock.ResourceID, lock.AcquiredAt); err != nil {
+		return false, fmt.Errorf("failed to delete expired lock: %w", err)
+	}
+
+	result, err := db.conn.ExecContext(ctx, `INSERT INTO resource_locks (`+resourceLockColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (resource_type, resource_id) DO NOTHING`,
+		lock.ID, lock.ResourceType, lock.ResourceID, lock.TenantID, lock.Holder, lock.Reason,
+		lock.Automatic, lock.AcquiredAt, lock.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create lock: %w", err)
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return created == 1, nil
+}
+
+// GetResourceLock retrieves the lock of a resource, nil when it has none
+// that hasn't expired at now
+func (db *DB) GetResourceLock(ctx context.Context, resourceType, resourceID string, now time.Time) (*models.ResourceLock, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+resourceLockColumns+` FROM resource_locks
+		WHERE resource_type = $1 AND resource_id = $2 AND expires_at > $3`,
+		resourceType, resourceID, now)
+	lock, err := scanResourceLock(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock: %w", err)
+	}
+	return lock, nil
+}
+
+// ListResourceLocks lists the locks that haven't expired at now, those of
+// tenantID only unless it's empty, soonest to expire first
+func (db *DB) ListResourceLocks(ctx context.Context, tenantID string, now time.Time) ([]*models.ResourceLock, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+resourceLockColumns+` FROM resource_locks
+		WHERE expires_at > $1 AND ($2 = '' OR tenant_id = $2)
+		ORDER BY expires_at`, now, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locks: %w", err)
+	}
+	defer rows.Close()
+
+	locks := []*models.ResourceLock{}
+	for rows.Next() {
+		lock, err := scanResourceLock(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list locks: %w", err)
+		}
+		locks = append(locks, lock)
+	}
+	return locks, rows.Err()
+}
+
+// ExtendResourceLock sets the expiry and reason of a lock that hasn't
+// expired at now, reporting whether there was one
+func (db *DB) ExtendResourceLock(ctx context.Context, id string, expiresAt time.Time, reason string, now time.Time) (bool, error) {
+	result, err := db.conn.ExecContext(ctx,
+		`UPDATE resource_locks SET expires_at = $1, reason = $2 WHERE id = $3 AND expires_at > $4`,
+		expiresAt, reason, id, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %w", err)
+	}
+	extended, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return extended == 1, nil
+}
+
+// DeleteResourceLock deletes a lock
+func (db *DB) DeleteResourceLock(ctx context.Context, id string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM resource_locks WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete lock: %w", err)
+	}
+	return nil
+}
+
+func scanResourceLock(row interface{ Scan(...interface{}) error }) (*models.ResourceLock, error) {
+	var lock models.ResourceLock
+	if err := row.Scan(&lock.ID, &lock.ResourceType, &lock.ResourceID, &lock.TenantID, &lock.Holder,
+		&lock.Reason, &lock.Automatic, &lock.AcquiredAt, &lock.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestResourceLocks(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	lock := &models.ResourceLock{
+		ID:           "1e3a5c7e-9b2d-4f6a-8c0e-2b4d6f8a0c01",
+		ResourceType: "switch",
+		ResourceID:   "ls-1",
+		TenantID:     "tenant-a",
+		Holder:       "alice",
+		Reason:       "migration",
+		AcquiredAt:   now,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	created, err := db.CreateResourceLock(ctx, lock)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// A resource has one lock at a time
+	contender := *lock
+	contender.ID, contender.Holder = "1e3a5c7e-9b2d-4f6a-8c0e-2b4d6f8a0c02", "bob"
+	created, err = db.CreateResourceLock(ctx, &contender)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	extended, err := db.ExtendResourceLock(ctx, lock.ID, now.Add(2*time.Hour), "longer migration", now)
+	require.NoError(t, err)
+	assert.True(t, extended)
+
+	got, err := db.GetResourceLock(ctx, "switch", "ls-1", now)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "longer migration", got.Reason)
+	assert.True(t, now.Add(2*time.Hour).Equal(got.ExpiresAt))
+
+	locks, err := db.ListResourceLocks(ctx, "tenant-a", now)
+	require.NoError(t, err)
+	assert.Len(t, locks, 1)
+	locks, err = db.ListResourceLocks(ctx, "tenant-b", now)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+
+	// Once expired, the lock is ignored and replaced by new ones
+	later := now.Add(3 * time.Hour)
+	got, err = db.GetResourceLock(ctx, "switch", "ls-1", later)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	extended, err = db.ExtendResourceLock(ctx, lock.ID, later.Add(time.Hour), "", later)
+	require.NoError(t, err)
+	assert.False(t, extended)
+
+	contender.AcquiredAt, contender.ExpiresAt = later, later.Add(time.Hour)
+	created, err = db.CreateResourceLock(ctx, &contender)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	require.NoError(t, db.DeleteResourceLock(ctx, contender.ID))
+	locks, err = db.ListResourceLocks(ctx, "", later)
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sandbox state operations
+
+// LoadSandboxState retrieves the state the sandbox OVN backend saved under
+// name, nil when it saved none yet
+func (db *DB) LoadSandboxState(ctx context.Context, name string) ([]byte, error) {
+	var state string
+	err := db.conn.QueryRowContext(ctx, `SELECT state FROM sandbox_states WHERE name = $1`, name).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sandbox state: %w", err)
+	}
+	return []byte(state), nil
+}
+
+// SaveSandboxState saves the state of the sandbox OVN backend under name,
+// replacing the one saved before
+func (db *DB) SaveSandboxState(ctx context.Context, name string, state []byte) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO sandbox_states (name, state, saved_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET state = EXCLUDED.state, saved_at = EXCLUDED.saved_at`,
+		name, string(state), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save sandbox state: %w", err)
+	}
+	return nil
+}
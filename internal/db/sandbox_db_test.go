@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandboxStates(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	state, err := db.LoadSandboxState(ctx, "sandbox")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	require.NoError(t, db.SaveSandboxState(ctx, "sandbox", []byte(`{"switches":{}}`)))
+	require.NoError(t, db.SaveSandboxState(ctx, "sandbox", []byte(`{"switches":{"ls1":{}}}`)))
+
+	state, err = db.LoadSandboxState(ctx, "sandbox")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"switches":{"ls1":{}}}`, string(state))
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Service catalog operations
+
+const serviceEntryColumns = `id, tenant_id, name, description, ports, created_by, created_at, updated_at`
+
+// CreateServiceEntry records a custom service entry unless its tenant has
+// one of the same name already, reporting whether it did
+func (db *DB) CreateServiceEntry(ctx context.Context, entry *models.ServiceEntry) (bool, error) {
+	ports, err := json.Marshal(entry.Ports)
+	if err != nil {
+		return false, fmt.Errorf("failed to create service entry: %w", err)
+	}
+	result, err := db.conn.ExecContext(ctx, `INSERT INTO service_entries (`+serviceEntryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, name) DO NOTHING`,
+		entry.ID, entry.TenantID, entry.Name, entry.Description, string(ports), entry.CreatedBy,
+		entryTime(entry.CreatedAt), entryTime(entry.UpdatedAt))
+	if err != nil {
+		return false, fmt.Errorf("failed to create service entry: %w", err)
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return created == 1, nil
+}
+
+// GetServiceEntry retrieves the custom service entry of tenantID named
+// name, nil when there's none
+func (db *DB) GetServiceEntry(ctx context.Context, tenantID, name string) (*models.ServiceEntry, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+serviceEntryColumns+` FROM service_entries
+		WHERE tenant_id = $1 AND name = $2`, tenantID, name)
+	entry, err := scanServiceEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ListServiceEntries lists the custom service entries of every tenant, by
+// name
+func (db *DB) ListServiceEntries(ctx context.Context) ([]*models.ServiceEntry, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+serviceEntryColumns+` FROM service_entries
+		ORDER BY name, tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.ServiceEntry{}
+	for rows.Next() {
+		entry, err := scanServiceEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list service entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// UpdateServiceEntry saves the description and ports of a custom service
+// entry
+func (db *DB) UpdateServiceEntry(ctx context.Context, entry *models.ServiceEntry) error {
+	ports, err := json.Marshal(entry.Ports)
+	if err != nil {
+		return fmt.Errorf("failed to update service entry: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE service_entries SET description = $1, ports = $2, updated_at = $3
+		WHERE id = $4`,
+		entry.Description, string(ports), entryTime(entry.UpdatedAt), entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update service entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteServiceEntry deletes a custom service entry
+func (db *DB) DeleteServiceEntry(ctx context.Context, id string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM service_entries WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete service entry: %w", err)
+	}
+	return nil
+}
+
+func scanServiceEntry(row interface{ Scan(...interface{}) error }) (*models.ServiceEntry, error) {
+	var entry models.ServiceEntry
+	var ports string
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&entry.ID, &entry.TenantID, &entry.Name, &entry.Description, &ports, &entry.CreatedBy,
+		&createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(ports), &entry.Ports); err != nil {
+		return nil, fmt.Errorf("invalid ports of service entry %s: %w", entry.ID, err)
+	}
+	entry.CreatedAt, entry.UpdatedAt = &createdAt, &updatedAt
+	return &entry, nil
+}
+
+// entryTime is the time of a custom entry, which always has one
+func entryTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestServiceEntries(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := &models.ServiceEntry{
+		ID:        "8a1e6f3c-52d4-4b8a-9c0e-1f2d3c4b5a01",
+		TenantID:  "tenant-a",
+		Name:      "app",
+		Ports:     []models.ServicePort{{Protocol: "tcp", Port: 8080}},
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	created, err := db.CreateServiceEntry(ctx, entry)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// Names are unique within a tenant
+	duplicate := *entry
+	duplicate.ID = "8a1e6f3c-52d4-4b8a-9c0e-1f2d3c4b5a02"
+	created, err = db.CreateServiceEntry(ctx, &duplicate)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	updatedAt := now.Add(time.Minute)
+	entry.Description = "application servers"
+	entry.Ports = []models.ServicePort{{Protocol: "tcp", Port: 8080, EndPort: 8089}}
+	entry.UpdatedAt = &updatedAt
+	require.NoError(t, db.UpdateServiceEntry(ctx, entry))
+
+	got, err := db.GetServiceEntry(ctx, "tenant-a", "app")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "application servers", got.Description)
+	assert.Equal(t, entry.Ports, got.Ports)
+	require.NotNil(t, got.UpdatedAt)
+	assert.True(t, updatedAt.Equal(*got.UpdatedAt))
+
+	got, err = db.GetServiceEntry(ctx, "tenant-b", "app")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	entries, err := db.ListServiceEntries(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, db.DeleteServiceEntry(ctx, entry.ID))
+	entries, err = db.ListServiceEntries(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
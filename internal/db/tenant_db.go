@@ -2,161 +2,869 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/lspecian/ovncp/internal/models"
 )
 
 // Tenant operations
 
+const tenantColumns = `id, name, display_name, description, status, type, parent, metadata, settings, quotas,
+	created_by, created_at, updated_at`
+
 // CreateTenant creates a new tenant
 func (db *DB) CreateTenant(ctx context.Context, tenant *models.Tenant) error {
-	// Implementation would insert into database
-	return fmt.Errorf("not implemented")
+	metadata, settings, quotas, err := marshalTenant(tenant)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO tenants (`+tenantColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		tenant.ID, tenant.Name, tenant.DisplayName, tenant.Description, string(tenant.Status),
+		string(tenant.Type), tenant.Parent, metadata, settings, quotas, tenant.CreatedBy,
+		tenant.CreatedAt.UTC(), tenant.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
 }
 
-// GetTenant retrieves a tenant by ID
+// GetTenant retrieves a tenant by ID. The error wraps sql.ErrNoRows when
+// there's none with the ID.
 func (db *DB) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, tenantID)
+	tenant, err := scanTenant(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("tenant %s not found: %w", tenantID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant, nil
 }
 
-// UpdateTenant updates a tenant
+// UpdateTenant saves everything but the ID, creator and creation time of a
+// tenant
 func (db *DB) UpdateTenant(ctx context.Context, tenantID string, tenant *models.Tenant) error {
-	// Implementation would update database
-	return fmt.Errorf("not implemented")
+	metadata, settings, quotas, err := marshalTenant(tenant)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE tenants SET name = $1, display_name = $2, description = $3,
+		status = $4, type = $5, parent = $6, metadata = $7, settings = $8, quotas = $9, updated_at = $10
+		WHERE id = $11`,
+		tenant.Name, tenant.DisplayName, tenant.Description, string(tenant.Status), string(tenant.Type),
+		tenant.Parent, metadata, settings, quotas, tenant.UpdatedAt.UTC(), tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
 }
 
-// DeleteTenant deletes a tenant
+// DeleteTenant deletes a tenant with its memberships, invitations, API keys
+// and usage counters. Its resources, usage history and changes are kept for
+// the record.
 func (db *DB) DeleteTenant(ctx context.Context, tenantID string) error {
-	// Implementation would delete from database
-	return fmt.Errorf("not implemented")
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"tenant_memberships", "tenant_invitations", "tenant_api_keys", "tenant_resource_usage"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1`, tenantID); err != nil {
+			return fmt.Errorf("failed to delete tenant from %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, tenantID); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return nil
 }
 
-// ListTenants lists tenants based on filter
+// ListTenants lists the tenants matching filter, by name
 func (db *DB) ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error) {
-	// Implementation would query database with filters
-	return nil, fmt.Errorf("not implemented")
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter != nil {
+		if filter.UserID != "" {
+			add("id IN (SELECT tenant_id FROM tenant_memberships WHERE user_id = $%d)", filter.UserID)
+		}
+		if filter.Type != "" {
+			add("type = $%d", string(filter.Type))
+		}
+		if filter.Status != "" {
+			add("status = $%d", string(filter.Status))
+		}
+		if filter.Parent != "" {
+			add("parent = $%d", filter.Parent)
+		}
+	}
+
+	query := `SELECT ` + tenantColumns + ` FROM tenants`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY name`
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*models.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenants: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+	return tenants, rows.Err()
+}
+
+// marshalTenant encodes the metadata, settings and quotas of a tenant
+func marshalTenant(tenant *models.Tenant) (metadata, settings, quotas string, err error) {
+	m := tenant.Metadata
+	if m == nil {
+		m = map[string]string{}
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", "", "", err
+	}
+	metadata = string(b)
+	if b, err = json.Marshal(tenant.Settings); err != nil {
+		return "", "", "", err
+	}
+	settings = string(b)
+	if b, err = json.Marshal(tenant.Quotas); err != nil {
+		return "", "", "", err
+	}
+	quotas = string(b)
+	return metadata, settings, quotas, nil
+}
+
+func scanTenant(row interface{ Scan(...interface{}) error }) (*models.Tenant, error) {
+	var tenant models.Tenant
+	var status, tenantType, metadata, settings, quotas string
+	var parent sql.NullString
+	if err := row.Scan(&tenant.ID, &tenant.Name, &tenant.DisplayName, &tenant.Description, &status,
+		&tenantType, &parent, &metadata, &settings, &quotas, &tenant.CreatedBy, &tenant.CreatedAt,
+		&tenant.UpdatedAt); err != nil {
+		return nil, err
+	}
+	tenant.Status = models.TenantStatus(status)
+	tenant.Type = models.TenantType(tenantType)
+	if parent.Valid {
+		tenant.Parent = &parent.String
+	}
+	if err := json.Unmarshal([]byte(metadata), &tenant.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata of tenant %s: %w", tenant.ID, err)
+	}
+	if err := json.Unmarshal([]byte(settings), &tenant.Settings); err != nil {
+		return nil, fmt.Errorf("invalid settings of tenant %s: %w", tenant.ID, err)
+	}
+	if err := json.Unmarshal([]byte(quotas), &tenant.Quotas); err != nil {
+		return nil, fmt.Errorf("invalid quotas of tenant %s: %w", tenant.ID, err)
+	}
+	return &tenant, nil
 }
 
 // Membership operations
 
+const tenantMembershipColumns = `id, tenant_id, user_id, role, created_by, created_at`
+
 // CreateTenantMembership creates a new membership
 func (db *DB) CreateTenantMembership(ctx context.Context, membership *models.TenantMembership) error {
-	// Implementation would insert into database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO tenant_memberships (`+tenantMembershipColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		membership.ID, membership.TenantID, membership.UserID, membership.Role, membership.CreatedBy,
+		membership.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tenant membership: %w", err)
+	}
+	return nil
 }
 
-// GetTenantMembership retrieves a membership
+// GetTenantMembership retrieves a user's membership of a tenant. The error
+// wraps sql.ErrNoRows when the user isn't a member.
 func (db *DB) GetTenantMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantMembershipColumns+` FROM tenant_memberships
+		WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	membership, err := scanTenantMembership(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %s is not a member of tenant %s: %w", userID, tenantID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant membership: %w", err)
+	}
+	return membership, nil
 }
 
-// UpdateTenantMembership updates a membership
+// UpdateTenantMembership saves the role of a membership
 func (db *DB) UpdateTenantMembership(ctx context.Context, membership *models.TenantMembership) error {
-	// Implementation would update database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `UPDATE tenant_memberships SET role = $1 WHERE tenant_id = $2 AND user_id = $3`,
+		membership.Role, membership.TenantID, membership.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant membership: %w", err)
+	}
+	return nil
 }
 
 // DeleteTenantMembership deletes a membership
 func (db *DB) DeleteTenantMembership(ctx context.Context, tenantID, userID string) error {
-	// Implementation would delete from database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM tenant_memberships WHERE tenant_id = $1 AND user_id = $2`,
+		tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant membership: %w", err)
+	}
+	return nil
 }
 
-// ListTenantMembers lists all members of a tenant
+// ListTenantMembers lists all members of a tenant, in the order they joined
 func (db *DB) ListTenantMembers(ctx context.Context, tenantID string) ([]*models.TenantMembership, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+tenantMembershipColumns+` FROM tenant_memberships
+		WHERE tenant_id = $1 ORDER BY created_at, user_id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant members: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []*models.TenantMembership{}
+	for rows.Next() {
+		membership, err := scanTenantMembership(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenant members: %w", err)
+		}
+		memberships = append(memberships, membership)
+	}
+	return memberships, rows.Err()
+}
+
+func scanTenantMembership(row interface{ Scan(...interface{}) error }) (*models.TenantMembership, error) {
+	var membership models.TenantMembership
+	if err := row.Scan(&membership.ID, &membership.TenantID, &membership.UserID, &membership.Role,
+		&membership.CreatedBy, &membership.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &membership, nil
 }
 
 // Resource operations
 
+const tenantResourceColumns = `resource_id, resource_type, tenant_id, created_at`
+
 // CreateTenantResource associates a resource with a tenant
 func (db *DB) CreateTenantResource(ctx context.Context, resource *models.TenantResource) error {
-	// Implementation would insert into database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO tenant_resources (`+tenantResourceColumns+`)
+		VALUES ($1, $2, $3, $4)`,
+		resource.ResourceID, resource.ResourceType, resource.TenantID, resource.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tenant resource: %w", err)
+	}
+	return nil
 }
 
-// GetTenantResource retrieves resource association
+// GetTenantResource retrieves the association of a resource. The error
+// wraps sql.ErrNoRows when the resource belongs to no tenant.
 func (db *DB) GetTenantResource(ctx context.Context, resourceID string) (*models.TenantResource, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantResourceColumns+` FROM tenant_resources
+		WHERE resource_id = $1`, resourceID)
+	resource, err := scanTenantResource(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("resource %s belongs to no tenant: %w", resourceID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant resource: %w", err)
+	}
+	return resource, nil
 }
 
 // DeleteTenantResource removes resource association
 func (db *DB) DeleteTenantResource(ctx context.Context, resourceID string) error {
-	// Implementation would delete from database
-	return fmt.Errorf("not implemented")
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM tenant_resources WHERE resource_id = $1`, resourceID); err != nil {
+		return fmt.Errorf("failed to delete tenant resource: %w", err)
+	}
+	return nil
+}
+
+// ListTenantResources lists all resources associated with a tenant, in the
+// order they were associated
+func (db *DB) ListTenantResources(ctx context.Context, tenantID string) ([]*models.TenantResource, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+tenantResourceColumns+` FROM tenant_resources
+		WHERE tenant_id = $1 ORDER BY created_at, resource_id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := []*models.TenantResource{}
+	for rows.Next() {
+		resource, err := scanTenantResource(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenant resources: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, rows.Err()
 }
 
-// GetResourceUsage retrieves current resource usage
+func scanTenantResource(row interface{ Scan(...interface{}) error }) (*models.TenantResource, error) {
+	var resource models.TenantResource
+	if err := row.Scan(&resource.ResourceID, &resource.ResourceType, &resource.TenantID,
+		&resource.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GetResourceUsage retrieves the number of resources of each type a tenant
+// has
 func (db *DB) GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error) {
-	// Implementation would calculate from database
-	return nil, fmt.Errorf("not implemented")
+	rows, err := db.conn.QueryContext(ctx, `SELECT resource_type, count, updated_at FROM tenant_resource_usage
+		WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := &models.ResourceUsage{TenantID: tenantID}
+	for rows.Next() {
+		var resourceType string
+		var count int
+		var updatedAt time.Time
+		if err := rows.Scan(&resourceType, &count, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to get resource usage: %w", err)
+		}
+		if counter := usageCounter(usage, resourceType); counter != nil {
+			*counter = count
+		}
+		if updatedAt.After(usage.LastUpdated) {
+			usage.LastUpdated = updatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get resource usage: %w", err)
+	}
+	return usage, nil
 }
 
-// UpdateResourceUsage updates resource usage count
+// UpdateResourceUsage adds delta to the number of resources of a type a
+// tenant has
 func (db *DB) UpdateResourceUsage(ctx context.Context, tenantID, resourceType string, delta int) error {
-	// Implementation would update counters
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO tenant_resource_usage (tenant_id, resource_type, count, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, resource_type) DO UPDATE SET count = tenant_resource_usage.count + EXCLUDED.count,
+			updated_at = EXCLUDED.updated_at`,
+		tenantID, resourceType, delta, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update resource usage: %w", err)
+	}
+	return nil
+}
+
+// usageCounter returns the field of usage counting resources of a type, as
+// named by TenantQuotas.IsWithinQuota, nil for types usage doesn't count
+func usageCounter(usage *models.ResourceUsage, resourceType string) *int {
+	switch resourceType {
+	case "switch":
+		return &usage.Switches
+	case "router":
+		return &usage.Routers
+	case "port":
+		return &usage.Ports
+	case "acl":
+		return &usage.ACLs
+	case "load_balancer":
+		return &usage.LoadBalancers
+	case "address_set":
+		return &usage.AddressSets
+	case "port_group":
+		return &usage.PortGroups
+	case "backup":
+		return &usage.Backups
+	}
+	return nil
+}
+
+// CreateUsageSnapshot records a tenant's usage at a point in time
+func (db *DB) CreateUsageSnapshot(ctx context.Context, snapshot *models.TenantUsageSnapshot) error {
+	usage, err := json.Marshal(snapshot.Usage)
+	if err != nil {
+		return fmt.Errorf("failed to create usage snapshot: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO tenant_usage_snapshots (tenant_id, usage, recorded_at)
+		VALUES ($1, $2, $3)`,
+		snapshot.TenantID, string(usage), snapshot.RecordedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create usage snapshot: %w", err)
+	}
+	return nil
+}
+
+// ListUsageSnapshots lists a tenant's usage snapshots recorded in [from, to),
+// oldest first
+func (db *DB) ListUsageSnapshots(ctx context.Context, tenantID string, from, to time.Time) ([]*models.TenantUsageSnapshot, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT tenant_id, usage, recorded_at FROM tenant_usage_snapshots
+		WHERE tenant_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+		ORDER BY recorded_at`, tenantID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*models.TenantUsageSnapshot{}
+	for rows.Next() {
+		var snapshot models.TenantUsageSnapshot
+		var usage string
+		if err := rows.Scan(&snapshot.TenantID, &usage, &snapshot.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to list usage snapshots: %w", err)
+		}
+		if err := json.Unmarshal([]byte(usage), &snapshot.Usage); err != nil {
+			return nil, fmt.Errorf("invalid usage snapshot of tenant %s: %w", snapshot.TenantID, err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	return snapshots, rows.Err()
 }
 
 // Invitation operations
 
+const tenantInvitationColumns = `id, tenant_id, email, role, token, expires_at, accepted_at, created_by, created_at`
+
 // CreateTenantInvitation creates a new invitation
 func (db *DB) CreateTenantInvitation(ctx context.Context, invitation *models.TenantInvitation) error {
-	// Implementation would insert into database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO tenant_invitations (`+tenantInvitationColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		invitation.ID, invitation.TenantID, invitation.Email, invitation.Role, invitation.Token,
+		invitation.ExpiresAt.UTC(), nullUTCTime(invitation.AcceptedAt), invitation.CreatedBy,
+		invitation.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tenant invitation: %w", err)
+	}
+	return nil
 }
 
-// GetTenantInvitationByToken retrieves invitation by token
+// GetTenantInvitationByToken retrieves invitation by token. The error wraps
+// sql.ErrNoRows when there's none with the token.
 func (db *DB) GetTenantInvitationByToken(ctx context.Context, token string) (*models.TenantInvitation, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	var invitation models.TenantInvitation
+	var acceptedAt sql.NullTime
+	err := db.conn.QueryRowContext(ctx, `SELECT `+tenantInvitationColumns+` FROM tenant_invitations
+		WHERE token = $1`, token).Scan(&invitation.ID, &invitation.TenantID, &invitation.Email,
+		&invitation.Role, &invitation.Token, &invitation.ExpiresAt, &acceptedAt, &invitation.CreatedBy,
+		&invitation.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("invitation not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant invitation: %w", err)
+	}
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+	return &invitation, nil
 }
 
-// UpdateTenantInvitation updates an invitation
+// UpdateTenantInvitation saves the role, expiry and acceptance of an
+// invitation
 func (db *DB) UpdateTenantInvitation(ctx context.Context, invitation *models.TenantInvitation) error {
-	// Implementation would update database
-	return fmt.Errorf("not implemented")
+	_, err := db.conn.ExecContext(ctx, `UPDATE tenant_invitations SET role = $1, expires_at = $2, accepted_at = $3
+		WHERE id = $4`,
+		invitation.Role, invitation.ExpiresAt.UTC(), nullUTCTime(invitation.AcceptedAt), invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant invitation: %w", err)
+	}
+	return nil
 }
 
 // API Key operations
 
+const tenantAPIKeyColumns = `id, tenant_id, name, description, key_hash, prefix, scopes, allowed_cidrs,
+	expires_at, last_used_at, usage_count, created_by, created_at, previous_key_hash, previous_prefix,
+	previous_expires_at, rotated_at`
+
 // CreateTenantAPIKey creates a new API key
 func (db *DB) CreateTenantAPIKey(ctx context.Context, key *models.TenantAPIKey) error {
-	// Implementation would insert into database
-	return fmt.Errorf("not implemented")
+	scopes, cidrs, err := marshalTenantAPIKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant API key: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO tenant_api_keys (`+tenantAPIKeyColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		key.ID, key.TenantID, key.Name, key.Description, key.KeyHash, key.Prefix, scopes, cidrs,
+		nullUTCTime(key.ExpiresAt), nullUTCTime(key.LastUsedAt), key.UsageCount, key.CreatedBy,
+		key.CreatedAt.UTC(), key.PreviousKeyHash, key.PreviousPrefix, nullUTCTime(key.PreviousExpiresAt),
+		nullUTCTime(key.RotatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create tenant API key: %w", err)
+	}
+	return nil
 }
 
-// GetTenantAPIKey retrieves an API key by ID
+// GetTenantAPIKey retrieves an API key by ID. The error wraps sql.ErrNoRows
+// when there's none with the ID.
 func (db *DB) GetTenantAPIKey(ctx context.Context, keyID string) (*models.TenantAPIKey, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantAPIKeyColumns+` FROM tenant_api_keys WHERE id = $1`, keyID)
+	key, err := scanTenantAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("API key %s not found: %w", keyID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant API key: %w", err)
+	}
+	return key, nil
 }
 
-// GetTenantAPIKeyByHash retrieves an API key by hash
+// GetTenantAPIKeyByHash retrieves an API key by the hash of its current
+// secret. The error wraps sql.ErrNoRows when there's none with the hash.
 func (db *DB) GetTenantAPIKeyByHash(ctx context.Context, keyHash string) (*models.TenantAPIKey, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantAPIKeyColumns+` FROM tenant_api_keys
+		WHERE key_hash = $1`, keyHash)
+	key, err := scanTenantAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("API key not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListTenantAPIKeysByPrefix lists the API keys whose current or previous
+// secret starts with prefix
+func (db *DB) ListTenantAPIKeysByPrefix(ctx context.Context, prefix string) ([]*models.TenantAPIKey, error) {
+	return db.listTenantAPIKeys(ctx, `WHERE prefix = $1 OR previous_prefix = $1 ORDER BY created_at, id`, prefix)
+}
+
+// RecordTenantAPIKeyUse increments an API key's usage count and sets when it
+// was last used
+func (db *DB) RecordTenantAPIKeyUse(ctx context.Context, keyID string, usedAt time.Time) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE tenant_api_keys SET usage_count = usage_count + 1, last_used_at = $1
+		WHERE id = $2`, usedAt.UTC(), keyID)
+	if err != nil {
+		return fmt.Errorf("failed to record tenant API key use: %w", err)
+	}
+	return nil
 }
 
-// UpdateTenantAPIKey updates an API key
+// UpdateTenantAPIKey saves the name, description, secrets, scopes, allowed
+// networks and expiry of an API key. Its usage is only recorded by
+// RecordTenantAPIKeyUse.
 func (db *DB) UpdateTenantAPIKey(ctx context.Context, key *models.TenantAPIKey) error {
-	// Implementation would update database
-	return fmt.Errorf("not implemented")
+	scopes, cidrs, err := marshalTenantAPIKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant API key: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE tenant_api_keys SET name = $1, description = $2, key_hash = $3,
+		prefix = $4, scopes = $5, allowed_cidrs = $6, expires_at = $7, previous_key_hash = $8,
+		previous_prefix = $9, previous_expires_at = $10, rotated_at = $11
+		WHERE id = $12`,
+		key.Name, key.Description, key.KeyHash, key.Prefix, scopes, cidrs, nullUTCTime(key.ExpiresAt),
+		key.PreviousKeyHash, key.PreviousPrefix, nullUTCTime(key.PreviousExpiresAt), nullUTCTime(key.RotatedAt),
+		key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant API key: %w", err)
+	}
+	return nil
 }
 
 // DeleteTenantAPIKey deletes an API key
 func (db *DB) DeleteTenantAPIKey(ctx context.Context, keyID string) error {
-	// Implementation would delete from database
-	return fmt.Errorf("not implemented")
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM tenant_api_keys WHERE id = $1`, keyID); err != nil {
+		return fmt.Errorf("failed to delete tenant API key: %w", err)
+	}
+	return nil
 }
 
-// ListTenantAPIKeys lists all API keys for a tenant
+// ListTenantAPIKeys lists all API keys for a tenant, in the order they were
+// created
 func (db *DB) ListTenantAPIKeys(ctx context.Context, tenantID string) ([]*models.TenantAPIKey, error) {
-	// Implementation would query database
-	return nil, fmt.Errorf("not implemented")
-}
\ No newline at end of file
+	return db.listTenantAPIKeys(ctx, `WHERE tenant_id = $1 ORDER BY created_at, id`, tenantID)
+}
+
+func (db *DB) listTenantAPIKeys(ctx context.Context, where string, args ...interface{}) ([]*models.TenantAPIKey, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+tenantAPIKeyColumns+` FROM tenant_api_keys `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.TenantAPIKey{}
+	for rows.Next() {
+		key, err := scanTenantAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenant API keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// marshalTenantAPIKey encodes the scopes and allowed networks of a key,
+// empty lists rather than JSON null when there are none
+func marshalTenantAPIKey(key *models.TenantAPIKey) (scopes, cidrs string, err error) {
+	s, c := key.Scopes, key.AllowedCIDRs
+	if s == nil {
+		s = []string{}
+	}
+	if c == nil {
+		c = []string{}
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", "", err
+	}
+	scopes = string(b)
+	if b, err = json.Marshal(c); err != nil {
+		return "", "", err
+	}
+	return scopes, string(b), nil
+}
+
+func scanTenantAPIKey(row interface{ Scan(...interface{}) error }) (*models.TenantAPIKey, error) {
+	var key models.TenantAPIKey
+	var scopes, cidrs string
+	var expiresAt, lastUsedAt, previousExpiresAt, rotatedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.TenantID, &key.Name, &key.Description, &key.KeyHash, &key.Prefix,
+		&scopes, &cidrs, &expiresAt, &lastUsedAt, &key.UsageCount, &key.CreatedBy, &key.CreatedAt,
+		&key.PreviousKeyHash, &key.PreviousPrefix, &previousExpiresAt, &rotatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes of API key %s: %w", key.ID, err)
+	}
+	if err := json.Unmarshal([]byte(cidrs), &key.AllowedCIDRs); err != nil {
+		return nil, fmt.Errorf("invalid allowed CIDRs of API key %s: %w", key.ID, err)
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if previousExpiresAt.Valid {
+		key.PreviousExpiresAt = &previousExpiresAt.Time
+	}
+	if rotatedAt.Valid {
+		key.RotatedAt = &rotatedAt.Time
+	}
+	return &key, nil
+}
+
+// Change operations
+
+const tenantChangeColumns = `id, tenant_id, status, description, cluster, operations, requested_by, reviewed_by,
+	review_comment, reviewed_at, result, created_at, updated_at`
+
+// CreateTenantChange creates a pending change
+func (db *DB) CreateTenantChange(ctx context.Context, change *models.TenantChange) error {
+	operations, result, err := marshalTenantChange(change)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant change: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO tenant_changes (`+tenantChangeColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		change.ID, change.TenantID, string(change.Status), change.Description, change.Cluster, operations,
+		change.RequestedBy, change.ReviewedBy, change.ReviewComment, nullUTCTime(change.ReviewedAt), result,
+		change.CreatedAt.UTC(), change.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tenant change: %w", err)
+	}
+	return nil
+}
+
+// GetTenantChange retrieves a change by ID, nil when there's none with the
+// ID
+func (db *DB) GetTenantChange(ctx context.Context, changeID string) (*models.TenantChange, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantChangeColumns+` FROM tenant_changes WHERE id = $1`, changeID)
+	change, err := scanTenantChange(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant change: %w", err)
+	}
+	return change, nil
+}
+
+// UpdateTenantChange saves the status, review and result of a change
+func (db *DB) UpdateTenantChange(ctx context.Context, change *models.TenantChange) error {
+	_, result, err := marshalTenantChange(change)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant change: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE tenant_changes SET status = $1, reviewed_by = $2,
+		review_comment = $3, reviewed_at = $4, result = $5, updated_at = $6
+		WHERE id = $7`,
+		string(change.Status), change.ReviewedBy, change.ReviewComment, nullUTCTime(change.ReviewedAt), result,
+		change.UpdatedAt.UTC(), change.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant change: %w", err)
+	}
+	return nil
+}
+
+// ListTenantChanges lists a tenant's changes, optionally with one status,
+// in the order they were requested
+func (db *DB) ListTenantChanges(ctx context.Context, tenantID string, status models.TenantChangeStatus) ([]*models.TenantChange, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+tenantChangeColumns+` FROM tenant_changes
+		WHERE tenant_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id`, tenantID, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*models.TenantChange{}
+	for rows.Next() {
+		change, err := scanTenantChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenant changes: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// marshalTenantChange encodes the operations of a change, and its result,
+// empty when it hasn't been executed
+func marshalTenantChange(change *models.TenantChange) (operations, result string, err error) {
+	ops := change.Operations
+	if ops == nil {
+		ops = []models.TransactionOperation{}
+	}
+	b, err := json.Marshal(ops)
+	if err != nil {
+		return "", "", err
+	}
+	operations = string(b)
+	if change.Result != nil {
+		if b, err = json.Marshal(change.Result); err != nil {
+			return "", "", err
+		}
+		result = string(b)
+	}
+	return operations, result, nil
+}
+
+func scanTenantChange(row interface{ Scan(...interface{}) error }) (*models.TenantChange, error) {
+	var change models.TenantChange
+	var status, operations, result string
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&change.ID, &change.TenantID, &status, &change.Description, &change.Cluster,
+		&operations, &change.RequestedBy, &change.ReviewedBy, &change.ReviewComment, &reviewedAt, &result,
+		&change.CreatedAt, &change.UpdatedAt); err != nil {
+		return nil, err
+	}
+	change.Status = models.TenantChangeStatus(status)
+	if err := json.Unmarshal([]byte(operations), &change.Operations); err != nil {
+		return nil, fmt.Errorf("invalid operations of tenant change %s: %w", change.ID, err)
+	}
+	if result != "" {
+		change.Result = &models.TransactionResponse{}
+		if err := json.Unmarshal([]byte(result), change.Result); err != nil {
+			return nil, fmt.Errorf("invalid result of tenant change %s: %w", change.ID, err)
+		}
+	}
+	if reviewedAt.Valid {
+		change.ReviewedAt = &reviewedAt.Time
+	}
+	return &change, nil
+}
+
+// Deletion operations
+
+const tenantDeletionColumns = `tenant_id, status, total, deleted, remaining, error, started_at, completed_at`
+
+// SaveTenantDeletion records the progress of a tenant's deletion,
+// replacing that of an earlier deletion
+func (db *DB) SaveTenantDeletion(ctx context.Context, deletion *models.TenantDeletion) error {
+	remaining := deletion.Remaining
+	if remaining == nil {
+		remaining = map[string]int{}
+	}
+	b, err := json.Marshal(remaining)
+	if err != nil {
+		return fmt.Errorf("failed to save tenant deletion: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO tenant_deletions (`+tenantDeletionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id) DO UPDATE SET status = EXCLUDED.status, total = EXCLUDED.total,
+			deleted = EXCLUDED.deleted, remaining = EXCLUDED.remaining, error = EXCLUDED.error,
+			started_at = EXCLUDED.started_at, completed_at = EXCLUDED.completed_at`,
+		deletion.TenantID, string(deletion.Status), deletion.Total, deletion.Deleted, string(b), deletion.Error,
+		deletion.StartedAt.UTC(), nullUTCTime(deletion.CompletedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save tenant deletion: %w", err)
+	}
+	return nil
+}
+
+// GetTenantDeletion retrieves the last deletion of a tenant, nil when it
+// was never deleted
+func (db *DB) GetTenantDeletion(ctx context.Context, tenantID string) (*models.TenantDeletion, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+tenantDeletionColumns+` FROM tenant_deletions WHERE tenant_id = $1`, tenantID)
+	deletion, err := scanTenantDeletion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant deletion: %w", err)
+	}
+	return deletion, nil
+}
+
+// ListTenantDeletions lists the deletions with a status, or all of them
+// when it's empty, in the order they started
+func (db *DB) ListTenantDeletions(ctx context.Context, status models.TenantDeletionStatus) ([]*models.TenantDeletion, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+tenantDeletionColumns+` FROM tenant_deletions
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY started_at, tenant_id`, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant deletions: %w", err)
+	}
+	defer rows.Close()
+
+	deletions := []*models.TenantDeletion{}
+	for rows.Next() {
+		deletion, err := scanTenantDeletion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenant deletions: %w", err)
+		}
+		deletions = append(deletions, deletion)
+	}
+	return deletions, rows.Err()
+}
+
+func scanTenantDeletion(row interface{ Scan(...interface{}) error }) (*models.TenantDeletion, error) {
+	var deletion models.TenantDeletion
+	var status, remaining string
+	var completedAt sql.NullTime
+	if err := row.Scan(&deletion.TenantID, &status, &deletion.Total, &deletion.Deleted, &remaining,
+		&deletion.Error, &deletion.StartedAt, &completedAt); err != nil {
+		return nil, err
+	}
+	deletion.Status = models.TenantDeletionStatus(status)
+	if err := json.Unmarshal([]byte(remaining), &deletion.Remaining); err != nil {
+		return nil, fmt.Errorf("invalid remaining resources of tenant deletion %s: %w", deletion.TenantID, err)
+	}
+	if completedAt.Valid {
+		deletion.CompletedAt = &completedAt.Time
+	}
+	return &deletion, nil
+}
@@ -0,0 +1,343 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestTenants(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	org := &models.Tenant{
+		ID:        "org",
+		Name:      "acme",
+		Status:    models.TenantStatusActive,
+		Type:      models.TenantTypeOrganization,
+		Metadata:  map[string]string{"cost-center": "42"},
+		Settings:  models.TenantSettings{RequireApproval: true},
+		Quotas:    models.DefaultQuotas(),
+		CreatedBy: "alice",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	parent := org.ID
+	project := &models.Tenant{
+		ID:        "project",
+		Name:      "acme-web",
+		Status:    models.TenantStatusActive,
+		Type:      models.TenantTypeProject,
+		Parent:    &parent,
+		Quotas:    models.UnlimitedQuotas(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.CreateTenant(ctx, org))
+	require.NoError(t, db.CreateTenant(ctx, project))
+	require.NoError(t, db.CreateTenantMembership(ctx, &models.TenantMembership{
+		ID: "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01", TenantID: project.ID, UserID: "bob", Role: "viewer", CreatedAt: now,
+	}))
+
+	got, err := db.GetTenant(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Parent)
+	assert.Equal(t, org.Metadata, got.Metadata)
+	assert.True(t, got.Settings.RequireApproval)
+	assert.Equal(t, models.DefaultQuotas(), got.Quotas)
+
+	_, err = db.GetTenant(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	project.Status = models.TenantStatusSuspended
+	project.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, db.UpdateTenant(ctx, project.ID, project))
+
+	tenants, err := db.ListTenants(ctx, &models.TenantFilter{Parent: org.ID, Status: models.TenantStatusSuspended})
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+	assert.Equal(t, "acme-web", tenants[0].Name)
+	assert.Equal(t, org.ID, *tenants[0].Parent)
+
+	tenants, err = db.ListTenants(ctx, &models.TenantFilter{UserID: "bob"})
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+	assert.Equal(t, project.ID, tenants[0].ID)
+
+	tenants, err = db.ListTenants(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 2)
+
+	// Deleting a tenant takes its memberships with it
+	require.NoError(t, db.DeleteTenant(ctx, project.ID))
+	_, err = db.GetTenantMembership(ctx, project.ID, "bob")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	tenants, err = db.ListTenants(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, tenants, 1)
+}
+
+func TestTenantMembersAndResources(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	membership := &models.TenantMembership{
+		ID: "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01", TenantID: "tenant-a", UserID: "bob", Role: "viewer", CreatedAt: now,
+	}
+	require.NoError(t, db.CreateTenantMembership(ctx, membership))
+	membership.Role = "admin"
+	require.NoError(t, db.UpdateTenantMembership(ctx, membership))
+
+	got, err := db.GetTenantMembership(ctx, "tenant-a", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", got.Role)
+
+	members, err := db.ListTenantMembers(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.NoError(t, db.DeleteTenantMembership(ctx, "tenant-a", "bob"))
+	members, err = db.ListTenantMembers(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	require.NoError(t, db.CreateTenantResource(ctx, &models.TenantResource{
+		ResourceID: "ls1", ResourceType: "switch", TenantID: "tenant-a", CreatedAt: now,
+	}))
+	resource, err := db.GetTenantResource(ctx, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", resource.TenantID)
+	resources, err := db.ListTenantResources(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Len(t, resources, 1)
+
+	require.NoError(t, db.DeleteTenantResource(ctx, "ls1"))
+	_, err = db.GetTenantResource(ctx, "ls1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTenantUsage(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	require.NoError(t, db.UpdateResourceUsage(ctx, "tenant-a", "switch", 1))
+	require.NoError(t, db.UpdateResourceUsage(ctx, "tenant-a", "switch", 1))
+	require.NoError(t, db.UpdateResourceUsage(ctx, "tenant-a", "port", 3))
+	require.NoError(t, db.UpdateResourceUsage(ctx, "tenant-a", "port", -1))
+	require.NoError(t, db.UpdateResourceUsage(ctx, "tenant-b", "router", 1))
+
+	usage, err := db.GetResourceUsage(ctx, "tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Switches)
+	assert.Equal(t, 2, usage.Ports)
+	assert.Zero(t, usage.Routers)
+	assert.False(t, usage.LastUpdated.IsZero())
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.CreateUsageSnapshot(ctx, &models.TenantUsageSnapshot{
+			TenantID:   "tenant-a",
+			Usage:      models.ResourceUsage{TenantID: "tenant-a", Switches: i},
+			RecordedAt: start.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	snapshots, err := db.ListUsageSnapshots(ctx, "tenant-a", start.Add(time.Hour), start.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, 1, snapshots[0].Usage.Switches)
+	assert.Equal(t, 2, snapshots[1].Usage.Switches)
+	assert.True(t, start.Add(time.Hour).Equal(snapshots[0].RecordedAt))
+}
+
+func TestTenantInvitations(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	invitation := &models.TenantInvitation{
+		ID:        "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01",
+		TenantID:  "tenant-a",
+		Email:     "bob@example.com",
+		Role:      "viewer",
+		Token:     "token",
+		ExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt: now,
+	}
+	require.NoError(t, db.CreateTenantInvitation(ctx, invitation))
+
+	got, err := db.GetTenantInvitationByToken(ctx, "token")
+	require.NoError(t, err)
+	assert.Nil(t, got.AcceptedAt)
+	assert.True(t, invitation.ExpiresAt.Equal(got.ExpiresAt))
+
+	invitation.AcceptedAt = &now
+	require.NoError(t, db.UpdateTenantInvitation(ctx, invitation))
+	got, err = db.GetTenantInvitationByToken(ctx, "token")
+	require.NoError(t, err)
+	require.NotNil(t, got.AcceptedAt)
+	assert.True(t, now.Equal(*got.AcceptedAt))
+
+	_, err = db.GetTenantInvitationByToken(ctx, "bogus")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTenantAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	key := &models.TenantAPIKey{
+		ID:        "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01",
+		TenantID:  "tenant-a",
+		Name:      "ci",
+		KeyHash:   "hash1",
+		Prefix:    "ovncp_aaaa",
+		Scopes:    []string{models.APIKeyScopeRead},
+		CreatedAt: now,
+	}
+	require.NoError(t, db.CreateTenantAPIKey(ctx, key))
+
+	got, err := db.GetTenantAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.APIKeyScopeRead}, got.Scopes)
+	assert.Empty(t, got.AllowedCIDRs)
+	assert.Nil(t, got.ExpiresAt)
+
+	// Rotating keeps the previous secret findable by its prefix
+	expires := now.Add(time.Hour)
+	key.PreviousKeyHash, key.PreviousPrefix, key.PreviousExpiresAt = key.KeyHash, key.Prefix, &expires
+	key.KeyHash, key.Prefix, key.RotatedAt = "hash2", "ovncp_bbbb", &now
+	key.AllowedCIDRs = []string{"10.0.0.0/8"}
+	require.NoError(t, db.UpdateTenantAPIKey(ctx, key))
+
+	for _, prefix := range []string{"ovncp_aaaa", "ovncp_bbbb"} {
+		keys, err := db.ListTenantAPIKeysByPrefix(ctx, prefix)
+		require.NoError(t, err)
+		require.Len(t, keys, 1, prefix)
+		assert.Equal(t, key.ID, keys[0].ID)
+	}
+	got, err = db.GetTenantAPIKeyByHash(ctx, "hash2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, got.AllowedCIDRs)
+	require.NotNil(t, got.PreviousExpiresAt)
+	assert.True(t, expires.Equal(*got.PreviousExpiresAt))
+
+	require.NoError(t, db.RecordTenantAPIKeyUse(ctx, key.ID, now))
+	require.NoError(t, db.RecordTenantAPIKeyUse(ctx, key.ID, now.Add(time.Minute)))
+	keys, err := db.ListTenantAPIKeys(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.EqualValues(t, 2, keys[0].UsageCount)
+	require.NotNil(t, keys[0].LastUsedAt)
+	assert.True(t, now.Add(time.Minute).Equal(*keys[0].LastUsedAt))
+
+	require.NoError(t, db.DeleteTenantAPIKey(ctx, key.ID))
+	_, err = db.GetTenantAPIKey(ctx, key.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTenantChanges(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	change := &models.TenantChange{
+		ID:       "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01",
+		TenantID: "tenant-a",
+		Status:   models.TenantChangeStatusPending,
+		Operations: []models.TransactionOperation{
+			{Type: "create", Resource: "switch", Data: map[string]interface{}{"name": "ls1"}},
+		},
+		RequestedBy: "bob",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, db.CreateTenantChange(ctx, change))
+
+	got, err := db.GetTenantChange(ctx, change.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, change.Operations, got.Operations)
+	assert.Nil(t, got.Result)
+	assert.Nil(t, got.ReviewedAt)
+
+	change.Status = models.TenantChangeStatusExecuted
+	change.ReviewedBy = "alice"
+	change.ReviewedAt = &now
+	change.Result = &models.TransactionResponse{TransactionID: "tx1", Success: true, ExecutedAt: now}
+	change.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, db.UpdateTenantChange(ctx, change))
+
+	changes, err := db.ListTenantChanges(ctx, "tenant-a", models.TenantChangeStatusPending)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changes, err = db.ListTenantChanges(ctx, "tenant-a", "")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "alice", changes[0].ReviewedBy)
+	require.NotNil(t, changes[0].Result)
+	assert.Equal(t, "tx1", changes[0].Result.TransactionID)
+	assert.True(t, changes[0].Result.Success)
+
+	got, err = db.GetTenantChange(ctx, "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d09")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestTenantDeletions(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	deletion := &models.TenantDeletion{
+		TenantID:  "tenant-a",
+		Status:    models.TenantDeletionStatusRunning,
+		Total:     3,
+		Remaining: map[string]int{"port": 2, "switch": 1},
+		StartedAt: now,
+	}
+	require.NoError(t, db.SaveTenantDeletion(ctx, deletion))
+	require.NoError(t, db.SaveTenantDeletion(ctx, &models.TenantDeletion{
+		TenantID:    "tenant-b",
+		Status:      models.TenantDeletionStatusCompleted,
+		StartedAt:   now,
+		CompletedAt: &now,
+	}))
+
+	got, err := db.GetTenantDeletion(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, deletion.Remaining, got.Remaining)
+	assert.Nil(t, got.CompletedAt)
+
+	// Saving again records progress
+	deletion.Deleted = 2
+	deletion.Remaining = map[string]int{"switch": 1}
+	require.NoError(t, db.SaveTenantDeletion(ctx, deletion))
+
+	deletions, err := db.ListTenantDeletions(ctx, models.TenantDeletionStatusRunning)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, "tenant-a", deletions[0].TenantID)
+	assert.Equal(t, 2, deletions[0].Deleted)
+	assert.Equal(t, map[string]int{"switch": 1}, deletions[0].Remaining)
+
+	deletions, err = db.ListTenantDeletions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, deletions, 2)
+	require.NotNil(t, deletions[1].CompletedAt)
+	assert.Empty(t, deletions[1].Remaining)
+
+	got, err = db.GetTenantDeletion(ctx, "tenant-c")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
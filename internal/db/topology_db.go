@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Topology snapshot operations
+
+// CreateTopologySnapshot records a cluster's topology
+func (db *DB) CreateTopologySnapshot(ctx context.Context, snapshot *models.TopologySnapshot) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO topology_snapshots (id, cluster, taken_at, data)
+		VALUES ($1, $2, $3, $4)`,
+		snapshot.ID, snapshot.Cluster, snapshot.TakenAt.UTC(), string(snapshot.Data))
+	if err != nil {
+		return fmt.Errorf("failed to create topology snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetTopologySnapshot retrieves a topology snapshot by ID, nil when there's
+// none with the ID
+func (db *DB) GetTopologySnapshot(ctx context.Context, id string) (*models.TopologySnapshot, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT id, cluster, taken_at, data FROM topology_snapshots
+		WHERE id = $1`, id)
+	snapshot, err := scanTopologySnapshot(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// FindTopologySnapshot retrieves a cluster's latest topology snapshot taken
+// at or before at, nil when there's none
+func (db *DB) FindTopologySnapshot(ctx context.Context, cluster string, at time.Time) (*models.TopologySnapshot, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT id, cluster, taken_at, data FROM topology_snapshots
+		WHERE cluster = $1 AND taken_at <= $2
+		ORDER BY taken_at DESC, id
+		LIMIT 1`, cluster, at.UTC())
+	snapshot, err := scanTopologySnapshot(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find topology snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// ListTopologySnapshots lists a cluster's topology snapshots taken in
+// [from, to], oldest first, without their data
+func (db *DB) ListTopologySnapshots(ctx context.Context, cluster string, from, to time.Time) ([]*models.TopologySnapshot, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT id, cluster, taken_at FROM topology_snapshots
+		WHERE cluster = $1 AND taken_at >= $2 AND taken_at <= $3
+		ORDER BY taken_at, id`, cluster, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topology snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*models.TopologySnapshot{}
+	for rows.Next() {
+		var snapshot models.TopologySnapshot
+		if err := rows.Scan(&snapshot.ID, &snapshot.Cluster, &snapshot.TakenAt); err != nil {
+			return nil, fmt.Errorf("failed to list topology snapshots: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// DeleteTopologySnapshotsBefore deletes the topology snapshots taken before
+// cutoff
+func (db *DB) DeleteTopologySnapshotsBefore(ctx context.Context, cutoff time.Time) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM topology_snapshots WHERE taken_at < $1`,
+		cutoff.UTC()); err != nil {
+		return fmt.Errorf("failed to delete topology snapshots: %w", err)
+	}
+	return nil
+}
+
+func scanTopologySnapshot(row interface{ Scan(...interface{}) error }) (*models.TopologySnapshot, error) {
+	var snapshot models.TopologySnapshot
+	var data string
+	if err := row.Scan(&snapshot.ID, &snapshot.Cluster, &snapshot.TakenAt, &data); err != nil {
+		return nil, err
+	}
+	snapshot.Data = json.RawMessage(data)
+	return &snapshot, nil
+}
+
+// ACL statistics operations
+
+// CreateACLStatsSamples records ACL counters, all of them or none
+func (db *DB) CreateACLStatsSamples(ctx context.Context, samples []*models.ACLStatsSample) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL statistics: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO acl_stats_samples (acl_id, packets, bytes, recorded_at)
+		VALUES ($1, $2, $3, $4)`)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL statistics: %w", err)
+	}
+	defer stmt.Close()
+	for _, sample := range samples {
+		if _, err := stmt.ExecContext(ctx, sample.ACLID, int64(sample.Packets), int64(sample.Bytes),
+			sample.RecordedAt.UTC()); err != nil {
+			return fmt.Errorf("failed to record ACL statistics: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record ACL statistics: %w", err)
+	}
+	return nil
+}
+
+// ListACLStatsSamples lists an ACL's samples recorded in [from, to], oldest
+// first
+func (db *DB) ListACLStatsSamples(ctx context.Context, aclID string, from, to time.Time) ([]*models.ACLStatsSample, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT acl_id, packets, bytes, recorded_at FROM acl_stats_samples
+		WHERE acl_id = $1 AND recorded_at >= $2 AND recorded_at <= $3
+		ORDER BY recorded_at`, aclID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACL statistics: %w", err)
+	}
+	defer rows.Close()
+
+	samples := []*models.ACLStatsSample{}
+	for rows.Next() {
+		var sample models.ACLStatsSample
+		var packets, bytes int64
+		if err := rows.Scan(&sample.ACLID, &packets, &bytes, &sample.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to list ACL statistics: %w", err)
+		}
+		sample.Packets, sample.Bytes = uint64(packets), uint64(bytes)
+		samples = append(samples, &sample)
+	}
+	return samples, rows.Err()
+}
+
+// DeleteACLStatsSamplesBefore deletes the ACL samples recorded before cutoff
+func (db *DB) DeleteACLStatsSamplesBefore(ctx context.Context, cutoff time.Time) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM acl_stats_samples WHERE recorded_at < $1`,
+		cutoff.UTC()); err != nil {
+		return fmt.Errorf("failed to delete ACL statistics: %w", err)
+	}
+	return nil
+}
+
+// ACL log operations
+
+const aclLogEntryColumns = `acl_name, verdict, severity, direction, protocol, src_ip, dst_ip, src_port,
+	dst_port, logged_at`
+
+// CreateACLLogEntries records ACL log entries, all of them or none,
+// setting their IDs
+func (db *DB) CreateACLLogEntries(ctx context.Context, entries []*models.ACLLogEntry) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL log entries: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO acl_log_entries (`+aclLogEntryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`)
+	if err != nil {
+		return fmt.Errorf("failed to record ACL log entries: %w", err)
+	}
+	defer stmt.Close()
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		if err := stmt.QueryRowContext(ctx, entry.ACLName, entry.Verdict, entry.Severity, entry.Direction,
+			entry.Protocol, entry.SrcIP, entry.DstIP, entry.SrcPort, entry.DstPort,
+			entry.LoggedAt.UTC()).Scan(&ids[i]); err != nil {
+			return fmt.Errorf("failed to record ACL log entries: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record ACL log entries: %w", err)
+	}
+	for i, entry := range entries {
+		entry.ID = ids[i]
+	}
+	return nil
+}
+
+// ListACLLogEntries lists the ACL log entries matching filter, most recent
+// first, up to its limit
+func (db *DB) ListACLLogEntries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	// Placeholders are numbered in the order they appear, as SQLite
+	// requires
+	var where []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf("%s $%d", condition, len(args)))
+	}
+	add("acl_name =", filter.ACLName)
+	if !filter.From.IsZero() {
+		add("logged_at >=", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		add("logged_at <=", filter.To.UTC())
+	}
+	if filter.Verdict != "" {
+		add("verdict =", filter.Verdict)
+	}
+	if filter.Protocol != "" {
+		// tcp also matches tcp6, and so on
+		args = append(args, filter.Protocol, filter.Protocol+"6")
+		where = append(where, fmt.Sprintf("protocol IN ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if filter.SrcIP != "" {
+		add("src_ip =", filter.SrcIP)
+	}
+	if filter.DstIP != "" {
+		add("dst_ip =", filter.DstIP)
+	}
+	if filter.SrcPort != 0 {
+		add("src_port =", filter.SrcPort)
+	}
+	if filter.DstPort != 0 {
+		add("dst_port =", filter.DstPort)
+	}
+	query := `SELECT id, ` + aclLogEntryColumns + ` FROM acl_log_entries
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY logged_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACL log entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.ACLLogEntry{}
+	for rows.Next() {
+		var entry models.ACLLogEntry
+		if err := rows.Scan(&entry.ID, &entry.ACLName, &entry.Verdict, &entry.Severity, &entry.Direction,
+			&entry.Protocol, &entry.SrcIP, &entry.DstIP, &entry.SrcPort, &entry.DstPort,
+			&entry.LoggedAt); err != nil {
+			return nil, fmt.Errorf("failed to list ACL log entries: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteACLLogEntriesBefore deletes the ACL log entries logged before cutoff
+func (db *DB) DeleteACLLogEntriesBefore(ctx context.Context, cutoff time.Time) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM acl_log_entries WHERE logged_at < $1`,
+		cutoff.UTC()); err != nil {
+		return fmt.Errorf("failed to delete ACL log entries: %w", err)
+	}
+	return nil
+}
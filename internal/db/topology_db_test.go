@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestTopologySnapshots(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, snapshot := range []*models.TopologySnapshot{
+		{ID: "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d01", Cluster: "us-east", TakenAt: now.Add(-2 * time.Hour)},
+		{ID: "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d02", Cluster: "us-east", TakenAt: now.Add(-time.Hour)},
+		{ID: "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d03", Cluster: "eu-west", TakenAt: now.Add(-time.Hour)},
+	} {
+		snapshot.Data = json.RawMessage(`{"switches":[]}`)
+		require.NoError(t, db.CreateTopologySnapshot(ctx, snapshot))
+	}
+
+	got, err := db.GetTopologySnapshot(ctx, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d01")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "us-east", got.Cluster)
+	assert.JSONEq(t, `{"switches":[]}`, string(got.Data))
+
+	got, err = db.GetTopologySnapshot(ctx, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d09")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// The latest snapshot of the cluster at or before the time
+	got, err = db.FindTopologySnapshot(ctx, "us-east", now.Add(-90*time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d01", got.ID)
+	got, err = db.FindTopologySnapshot(ctx, "us-east", now)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d02", got.ID)
+	got, err = db.FindTopologySnapshot(ctx, "us-east", now.Add(-3*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	snapshots, err := db.ListTopologySnapshots(ctx, "us-east", now.Add(-2*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d01", snapshots[0].ID)
+	assert.True(t, now.Add(-2*time.Hour).Equal(snapshots[0].TakenAt))
+	assert.Empty(t, snapshots[0].Data)
+
+	require.NoError(t, db.DeleteTopologySnapshotsBefore(ctx, now.Add(-90*time.Minute)))
+	snapshots, err = db.ListTopologySnapshots(ctx, "us-east", now.Add(-3*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "3f9c1f4e-6a0b-4c55-8e0e-5d8a9b7c6d02", snapshots[0].ID)
+}
+
+func TestACLStatsSamples(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, db.CreateACLStatsSamples(ctx, []*models.ACLStatsSample{
+		{ACLID: "acl-1", Packets: 10, Bytes: 1000, RecordedAt: now.Add(-2 * time.Minute)},
+		{ACLID: "acl-1", Packets: 25, Bytes: 2500, RecordedAt: now.Add(-time.Minute)},
+		{ACLID: "acl-2", Packets: 1, Bytes: 64, RecordedAt: now.Add(-time.Minute)},
+	}))
+
+	// A batch failing part way records none of its samples
+	err := db.CreateACLStatsSamples(ctx, []*models.ACLStatsSample{
+		{ACLID: "acl-2", Packets: 2, Bytes: 128, RecordedAt: now},
+		{ACLID: "acl-1", Packets: 25, Bytes: 2500, RecordedAt: now.Add(-time.Minute)},
+	})
+	assert.Error(t, err)
+
+	samples, err := db.ListACLStatsSamples(ctx, "acl-1", now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, uint64(10), samples[0].Packets)
+	assert.Equal(t, uint64(2500), samples[1].Bytes)
+	assert.True(t, now.Add(-time.Minute).Equal(samples[1].RecordedAt))
+
+	samples, err = db.ListACLStatsSamples(ctx, "acl-2", now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+
+	require.NoError(t, db.DeleteACLStatsSamplesBefore(ctx, now.Add(-90*time.Second)))
+	samples, err = db.ListACLStatsSamples(ctx, "acl-1", now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, uint64(25), samples[0].Packets)
+}
+
+func TestACLLogEntries(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entries := []*models.ACLLogEntry{
+		{ACLName: "allow-web", Verdict: "allow", Protocol: "tcp", SrcIP: "10.0.0.5", DstIP: "10.0.1.10", SrcPort: 40000, DstPort: 80, LoggedAt: now.Add(-2 * time.Minute)},
+		{ACLName: "allow-web", Verdict: "allow", Protocol: "tcp6", SrcIP: "fd00::5", DstIP: "fd00::10", SrcPort: 40001, DstPort: 443, LoggedAt: now.Add(-time.Minute)},
+		{ACLName: "allow-web", Verdict: "drop", Protocol: "udp", SrcIP: "10.0.0.6", DstIP: "10.0.1.10", SrcPort: 5353, DstPort: 53, LoggedAt: now},
+		{ACLName: "deny-all", Verdict: "drop", Protocol: "icmp", LoggedAt: now},
+	}
+	require.NoError(t, db.CreateACLLogEntries(ctx, entries))
+	for _, entry := range entries {
+		assert.NotZero(t, entry.ID)
+	}
+
+	got, err := db.ListACLLogEntries(ctx, &models.ACLLogFilter{ACLName: "allow-web"})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, entries[2].ID, got[0].ID)
+	assert.Equal(t, *entries[2], *got[0])
+
+	got, err = db.ListACLLogEntries(ctx, &models.ACLLogFilter{ACLName: "allow-web", Protocol: "tcp"})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	got, err = db.ListACLLogEntries(ctx, &models.ACLLogFilter{
+		ACLName: "allow-web", From: now.Add(-90 * time.Second), To: now, Verdict: "allow", DstPort: 443,
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "fd00::5", got[0].SrcIP)
+
+	got, err = db.ListACLLogEntries(ctx, &models.ACLLogFilter{ACLName: "allow-web", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "drop", got[0].Verdict)
+
+	require.NoError(t, db.DeleteACLLogEntriesBefore(ctx, now))
+	got, err = db.ListACLLogEntries(ctx, &models.ACLLogFilter{ACLName: "allow-web"})
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
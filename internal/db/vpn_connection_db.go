@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// VPN connection operations
+
+const vpnConnectionColumns = `id, tenant_id, name, description, router_id, gateway_ip, peer_address, psk_ref,
+	local_subnets, remote_subnets, status, created_by, created_at, updated_at`
+
+// CreateVPNConnection records conn unless its tenant has a connection of
+// the same name already, reporting whether it did
+func (db *DB) CreateVPNConnection(ctx context.Context, conn *models.VPNConnection) (bool, error) {
+	local, remote, status, err := marshalVPNConnection(conn)
+	if err != nil {
+		return false, fmt.Errorf("failed to create VPN connection: %w", err)
+	}
+	result, err := db.conn.ExecContext(ctx, `INSERT INTO vpn_connections (`+vpnConnectionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (tenant_id, name) DO NOTHING`,
+		conn.ID, conn.TenantID, conn.Name, conn.Description, conn.RouterID, conn.GatewayIP, conn.PeerAddress,
+		conn.PSKRef, local, remote, status, conn.CreatedBy, conn.CreatedAt, conn.UpdatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create VPN connection: %w", err)
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return created == 1, nil
+}
+
+// GetVPNConnection retrieves a VPN connection, nil when there's none with
+// the ID
+func (db *DB) GetVPNConnection(ctx context.Context, id string) (*models.VPNConnection, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+vpnConnectionColumns+` FROM vpn_connections WHERE id = $1`, id)
+	conn, err := scanVPNConnection(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VPN connection: %w", err)
+	}
+	return conn, nil
+}
+
+// ListVPNConnections lists the VPN connections of tenantID, or all of them
+// when it's empty, by name
+func (db *DB) ListVPNConnections(ctx context.Context, tenantID string) ([]*models.VPNConnection, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+vpnConnectionColumns+` FROM vpn_connections
+		WHERE ($1 = '' OR tenant_id = $1)
+		ORDER BY name, tenant_id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VPN connections: %w", err)
+	}
+	defer rows.Close()
+
+	conns := []*models.VPNConnection{}
+	for rows.Next() {
+		conn, err := scanVPNConnection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list VPN connections: %w", err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, rows.Err()
+}
+
+// UpdateVPNConnection saves the configuration of a VPN connection, leaving
+// its status alone
+func (db *DB) UpdateVPNConnection(ctx context.Context, conn *models.VPNConnection) error {
+	local, remote, _, err := marshalVPNConnection(conn)
+	if err != nil {
+		return fmt.Errorf("failed to update VPN connection: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE vpn_connections SET description = $1, gateway_ip = $2,
+		peer_address = $3, psk_ref = $4, local_subnets = $5, remote_subnets = $6, updated_at = $7
+		WHERE id = $8`,
+		conn.Description, conn.GatewayIP, conn.PeerAddress, conn.PSKRef, local, remote, conn.UpdatedAt, conn.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update VPN connection: %w", err)
+	}
+	return nil
+}
+
+// UpdateVPNConnectionStatus saves the tunnel status last collected for a
+// VPN connection
+func (db *DB) UpdateVPNConnectionStatus(ctx context.Context, id string, status *models.VPNTunnelStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to update VPN connection status: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE vpn_connections SET status = $1 WHERE id = $2`, string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to update VPN connection status: %w", err)
+	}
+	return nil
+}
+
+// DeleteVPNConnection deletes a VPN connection
+func (db *DB) DeleteVPNConnection(ctx context.Context, id string) error {
+	if _, err := db.conn.ExecContext(ctx, `DELETE FROM vpn_connections WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete VPN connection: %w", err)
+	}
+	return nil
+}
+
+// marshalVPNConnection encodes the subnets and status of conn as JSON, the
+// status as an empty string when there's none
+func marshalVPNConnection(conn *models.VPNConnection) (local, remote, status string, err error) {
+	data, err := json.Marshal(conn.LocalSubnets)
+	if err != nil {
+		return "", "", "", err
+	}
+	local = string(data)
+	if data, err = json.Marshal(conn.RemoteSubnets); err != nil {
+		return "", "", "", err
+	}
+	remote = string(data)
+	if conn.Status != nil {
+		if data, err = json.Marshal(conn.Status); err != nil {
+			return "", "", "", err
+		}
+		status = string(data)
+	}
+	return local, remote, status, nil
+}
+
+func scanVPNConnection(row interface{ Scan(...interface{}) error }) (*models.VPNConnection, error) {
+	var conn models.VPNConnection
+	var local, remote, status string
+	if err := row.Scan(&conn.ID, &conn.TenantID, &conn.Name, &conn.Description, &conn.RouterID, &conn.GatewayIP,
+		&conn.PeerAddress, &conn.PSKRef, &local, &remote, &status, &conn.CreatedBy, &conn.CreatedAt,
+		&conn.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(local), &conn.LocalSubnets); err != nil {
+		return nil, fmt.Errorf("invalid local subnets of VPN connection %s: %w", conn.ID, err)
+	}
+	if err := json.Unmarshal([]byte(remote), &conn.RemoteSubnets); err != nil {
+		return nil, fmt.Errorf("invalid remote subnets of VPN connection %s: %w", conn.ID, err)
+	}
+	if status != "" {
+		conn.Status = &models.VPNTunnelStatus{}
+		if err := json.Unmarshal([]byte(status), conn.Status); err != nil {
+			return nil, fmt.Errorf("invalid status of VPN connection %s: %w", conn.ID, err)
+		}
+	}
+	return &conn, nil
+}
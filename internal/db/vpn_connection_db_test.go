@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestVPNConnections(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	conn := &models.VPNConnection{
+		ID:            "0c7d5a8e-3b61-4d2f-a1c4-7e9b8f6a5d01",
+		TenantID:      "tenant-a",
+		Name:          "hq",
+		RouterID:      "lr-tenant-a",
+		GatewayIP:     "10.0.0.2",
+		PeerAddress:   "203.0.113.1",
+		PSKRef:        "vault:secret/data/vpn#hq",
+		LocalSubnets:  []string{"10.0.0.0/24"},
+		RemoteSubnets: []string{"192.168.0.0/16"},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	created, err := db.CreateVPNConnection(ctx, conn)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// Names are unique within a tenant
+	duplicate := *conn
+	duplicate.ID = "0c7d5a8e-3b61-4d2f-a1c4-7e9b8f6a5d02"
+	created, err = db.CreateVPNConnection(ctx, &duplicate)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	conn.Description = "headquarters"
+	conn.PeerAddress = "203.0.113.2"
+	conn.RemoteSubnets = []string{"192.168.0.0/16", "172.20.0.0/16"}
+	conn.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, db.UpdateVPNConnection(ctx, conn))
+	require.NoError(t, db.UpdateVPNConnectionStatus(ctx, conn.ID, &models.VPNTunnelStatus{
+		State: models.VPNTunnelUp, BytesIn: 42, CheckedAt: now,
+	}))
+
+	got, err := db.GetVPNConnection(ctx, conn.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "headquarters", got.Description)
+	assert.Equal(t, "203.0.113.2", got.PeerAddress)
+	assert.Equal(t, conn.RemoteSubnets, got.RemoteSubnets)
+	assert.True(t, conn.UpdatedAt.Equal(got.UpdatedAt))
+	require.NotNil(t, got.Status)
+	assert.Equal(t, models.VPNTunnelUp, got.Status.State)
+	assert.Equal(t, uint64(42), got.Status.BytesIn)
+
+	conns, err := db.ListVPNConnections(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, conns, 1)
+	conns, err = db.ListVPNConnections(ctx, "tenant-b")
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+
+	require.NoError(t, db.DeleteVPNConnection(ctx, conn.ID))
+	got, err = db.GetVPNConnection(ctx, conn.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
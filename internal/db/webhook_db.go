@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Webhook operations
+
+const webhookColumns = `id, tenant_id, url, description, secret, events, enabled, created_by, created_at,
+	updated_at`
+
+// CreateWebhook creates a webhook
+func (db *DB) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	events, err := json.Marshal(webhookEvents(webhook))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `INSERT INTO webhooks (`+webhookColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		webhook.ID, webhook.TenantID, webhook.URL, webhook.Description, webhook.Secret, string(events),
+		webhook.Enabled, webhook.CreatedBy, webhook.CreatedAt.UTC(), webhook.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhook retrieves a webhook by ID, nil when there's none with the ID
+func (db *DB) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, webhookID)
+	webhook, err := scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook saves the URL, description, secret, events and enabled
+// state of a webhook
+func (db *DB) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	events, err := json.Marshal(webhookEvents(webhook))
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	_, err = db.conn.ExecContext(ctx, `UPDATE webhooks SET url = $1, description = $2, secret = $3, events = $4,
+		enabled = $5, updated_at = $6
+		WHERE id = $7`,
+		webhook.URL, webhook.Description, webhook.Secret, string(events), webhook.Enabled,
+		webhook.UpdatedAt.UTC(), webhook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebhook deletes a webhook and its deliveries
+func (db *DB) DeleteWebhook(ctx context.Context, webhookID string) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = $1`, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhooks lists the global webhooks and those of every tenant, in the
+// order they were created
+func (db *DB) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// webhookEvents returns the event types of a webhook, an empty list rather
+// than JSON null when it subscribes to all
+func webhookEvents(webhook *models.Webhook) []string {
+	if webhook.Events == nil {
+		return []string{}
+	}
+	return webhook.Events
+}
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var events string
+	if err := row.Scan(&webhook.ID, &webhook.TenantID, &webhook.URL, &webhook.Description, &webhook.Secret,
+		&events, &webhook.Enabled, &webhook.CreatedBy, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, fmt.Errorf("invalid events of webhook %s: %w", webhook.ID, err)
+	}
+	return &webhook, nil
+}
+
+// Webhook delivery operations
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_code,
+	last_error, next_attempt_at, delivered_at, created_at`
+
+// CreateWebhookDelivery records a delivery
+func (db *DB) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, string(delivery.Payload),
+		string(delivery.Status), delivery.Attempts, delivery.ResponseCode, delivery.LastError,
+		nullUTCTime(delivery.NextAttemptAt), nullUTCTime(delivery.DeliveredAt), delivery.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery's last attempt
+func (db *DB) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := db.conn.ExecContext(ctx, `UPDATE webhook_deliveries SET status = $1, attempts = $2,
+		response_code = $3, last_error = $4, next_attempt_at = $5, delivered_at = $6
+		WHERE id = $7`,
+		string(delivery.Status), delivery.Attempts, delivery.ResponseCode, delivery.LastError,
+		nullUTCTime(delivery.NextAttemptAt), nullUTCTime(delivery.DeliveredAt), delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries lists a webhook's deliveries, most recent first, up
+// to limit
+func (db *DB) ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	return db.listWebhookDeliveries(ctx, `WHERE webhook_id = $1 ORDER BY created_at DESC, id LIMIT $2`,
+		webhookID, limit)
+}
+
+// ListDueWebhookDeliveries lists the pending deliveries whose next attempt
+// is due at now, the longest due first
+func (db *DB) ListDueWebhookDeliveries(ctx context.Context, now time.Time) ([]*models.WebhookDelivery, error) {
+	return db.listWebhookDeliveries(ctx, `WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at, id`, string(models.WebhookDeliveryPending), now.UTC())
+}
+
+func (db *DB) listWebhookDeliveries(ctx context.Context, where string, args ...interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var payload, status string
+		var nextAttemptAt, deliveredAt sql.NullTime
+		if err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload,
+			&status, &delivery.Attempts, &delivery.ResponseCode, &delivery.LastError, &nextAttemptAt,
+			&deliveredAt, &delivery.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+		}
+		delivery.Payload = json.RawMessage(payload)
+		delivery.Status = models.WebhookDeliveryStatus(status)
+		if nextAttemptAt.Valid {
+			delivery.NextAttemptAt = &nextAttemptAt.Time
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// nullUTCTime is t in UTC, NULL when nil, so times compare in SQL whatever
+// the location they were taken in
+func nullUTCTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// Resource change operations
+
+const resourceChangeColumns = `id, resource_id, resource, action, user_id, tenant_id, source, cluster, method,
+	path, occurred_at`
+
+// CreateResourceChange records a change to a resource
+func (db *DB) CreateResourceChange(ctx context.Context, change *models.ResourceChange) error {
+	_, err := db.conn.ExecContext(ctx, `INSERT INTO resource_changes (`+resourceChangeColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		change.ID, change.ResourceID, change.Resource, change.Action, change.UserID, change.TenantID,
+		change.Source, change.Cluster, change.Method, change.Path, change.OccurredAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record resource change: %w", err)
+	}
+	return nil
+}
+
+// ListResourceChanges lists the changes of a resource, oldest first
+func (db *DB) ListResourceChanges(ctx context.Context, resourceID string) ([]*models.ResourceChange, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+resourceChangeColumns+` FROM resource_changes
+		WHERE resource_id = $1
+		ORDER BY occurred_at, id`, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []*models.ResourceChange{}
+	for rows.Next() {
+		var change models.ResourceChange
+		if err := rows.Scan(&change.ID, &change.ResourceID, &change.Resource, &change.Action, &change.UserID,
+			&change.TenantID, &change.Source, &change.Cluster, &change.Method, &change.Path,
+			&change.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to list resource changes: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+	return changes, rows.Err()
+}
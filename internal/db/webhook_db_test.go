@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	webhook := &models.Webhook{
+		ID:        "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01",
+		TenantID:  "tenant-a",
+		URL:       "https://example.com/hooks",
+		Secret:    "s3cret",
+		Enabled:   true,
+		CreatedBy: "alice",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.CreateWebhook(ctx, webhook))
+
+	got, err := db.GetWebhook(ctx, webhook.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "s3cret", got.Secret)
+	assert.Empty(t, got.Events)
+	assert.True(t, got.Enabled)
+
+	webhook.URL = "https://example.com/v2/hooks"
+	webhook.Events = []string{models.EventResourceCreated, models.EventBackupFailed}
+	webhook.Enabled = false
+	webhook.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, db.UpdateWebhook(ctx, webhook))
+
+	webhooks, err := db.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, "https://example.com/v2/hooks", webhooks[0].URL)
+	assert.Equal(t, webhook.Events, webhooks[0].Events)
+	assert.False(t, webhooks[0].Enabled)
+	assert.True(t, webhook.UpdatedAt.Equal(webhooks[0].UpdatedAt))
+
+	got, err = db.GetWebhook(ctx, "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d09")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	webhook := &models.Webhook{ID: "6e2b7c1a-8d4f-4a3e-b5c6-9f0a1b2c3d01", URL: "https://example.com/hooks",
+		Enabled: true, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.CreateWebhook(ctx, webhook))
+
+	newDelivery := func(id string, createdAt time.Time) *models.WebhookDelivery {
+		nextAttemptAt := createdAt
+		return &models.WebhookDelivery{
+			ID:            id,
+			WebhookID:     webhook.ID,
+			EventID:       "event-" + id[len(id)-2:],
+			EventType:     models.EventResourceCreated,
+			Payload:       json.RawMessage(`{"type":"resource.created"}`),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &nextAttemptAt,
+			CreatedAt:     createdAt,
+		}
+	}
+	first := newDelivery("9d3c2b1a-0f4e-4d5c-8b7a-6e5f4d3c2b01", now.Add(-time.Minute))
+	second := newDelivery("9d3c2b1a-0f4e-4d5c-8b7a-6e5f4d3c2b02", now)
+	later := newDelivery("9d3c2b1a-0f4e-4d5c-8b7a-6e5f4d3c2b03", now.Add(time.Minute))
+	for _, delivery := range []*models.WebhookDelivery{first, second, later} {
+		require.NoError(t, db.CreateWebhookDelivery(ctx, delivery))
+	}
+
+	due, err := db.ListDueWebhookDeliveries(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, first.ID, due[0].ID)
+	assert.JSONEq(t, `{"type":"resource.created"}`, string(due[0].Payload))
+
+	// Delivered deliveries are no longer due
+	deliveredAt := now
+	first.Status, first.Attempts, first.ResponseCode = models.WebhookDeliverySucceeded, 1, 204
+	first.NextAttemptAt, first.DeliveredAt = nil, &deliveredAt
+	require.NoError(t, db.UpdateWebhookDelivery(ctx, first))
+
+	due, err = db.ListDueWebhookDeliveries(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, second.ID, due[0].ID)
+
+	deliveries, err := db.ListWebhookDeliveries(ctx, webhook.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, later.ID, deliveries[0].ID)
+	assert.Equal(t, second.ID, deliveries[1].ID)
+
+	deliveries, err = db.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	delivered := deliveries[2]
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivered.Status)
+	assert.Equal(t, 204, delivered.ResponseCode)
+	assert.Nil(t, delivered.NextAttemptAt)
+	require.NotNil(t, delivered.DeliveredAt)
+	assert.True(t, deliveredAt.Equal(*delivered.DeliveredAt))
+
+	// Deleting a webhook deletes its deliveries
+	require.NoError(t, db.DeleteWebhook(ctx, webhook.ID))
+	deliveries, err = db.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestResourceChanges(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, change := range []*models.ResourceChange{
+		{ID: "2a4c6e8f-1b3d-4f5a-9c7e-0d2f4a6c8e02", ResourceID: "ls-1", Resource: "switches", Action: "updated",
+			UserID: "bob", TenantID: "tenant-a", Method: "PUT", Path: "/api/v1/switches/ls-1", OccurredAt: now},
+		{ID: "2a4c6e8f-1b3d-4f5a-9c7e-0d2f4a6c8e01", ResourceID: "ls-1", Resource: "switches", Action: "created",
+			UserID: "alice", TenantID: "tenant-a", Method: "POST", Path: "/api/v1/switches", OccurredAt: now.Add(-time.Hour)},
+		{ID: "2a4c6e8f-1b3d-4f5a-9c7e-0d2f4a6c8e03", ResourceID: "ls-2", Resource: "switches", Action: "created",
+			Method: "POST", Path: "/api/v1/switches", OccurredAt: now},
+	} {
+		require.NoError(t, db.CreateResourceChange(ctx, change))
+	}
+
+	changes, err := db.ListResourceChanges(ctx, "ls-1")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "created", changes[0].Action)
+	assert.Equal(t, "alice", changes[0].UserID)
+	assert.Equal(t, "updated", changes[1].Action)
+	assert.Equal(t, "/api/v1/switches/ls-1", changes[1].Path)
+	assert.True(t, now.Equal(changes[1].OccurredAt))
+
+	changes, err = db.ListResourceChanges(ctx, "ls-3")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
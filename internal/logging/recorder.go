@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrorEntry is an error-level entry kept by an ErrorRecorder
+type ErrorEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Logger  string                 `json:"logger,omitempty"`
+	Message string                 `json:"message"`
+	Caller  string                 `json:"caller,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ErrorRecorder is a zapcore.Core keeping the last errors logged, so they
+// can be reported without access to the log output. It is teed with the
+// logger's core:
+//
+//	logger = logger.WithOptions(zap.WrapCore(recorder.Tee))
+type ErrorRecorder struct {
+	ring   *errorRing
+	fields []zapcore.Field // Added to the logger with With
+}
+
+// errorRing holds the entries of a recorder and the loggers derived from it
+type errorRing struct {
+	mu      sync.Mutex
+	entries []ErrorEntry
+	next    int // Where the next entry is written
+	full    bool
+}
+
+// NewErrorRecorder creates a recorder keeping the last size errors
+func NewErrorRecorder(size int) *ErrorRecorder {
+	if size < 1 {
+		size = 1
+	}
+	return &ErrorRecorder{ring: &errorRing{entries: make([]ErrorEntry, size)}}
+}
+
+// Tee returns a core writing to core and recording errors
+func (r *ErrorRecorder) Tee(core zapcore.Core) zapcore.Core {
+	return zapcore.NewTee(core, r)
+}
+
+// Entries returns the errors recorded, oldest first
+func (r *ErrorRecorder) Entries() []ErrorEntry {
+	r.ring.mu.Lock()
+	defer r.ring.mu.Unlock()
+
+	if !r.ring.full {
+		return append([]ErrorEntry(nil), r.ring.entries[:r.ring.next]...)
+	}
+	entries := make([]ErrorEntry, 0, len(r.ring.entries))
+	entries = append(entries, r.ring.entries[r.ring.next:]...)
+	return append(entries, r.ring.entries[:r.ring.next]...)
+}
+
+// Enabled records errors and above
+func (r *ErrorRecorder) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.ErrorLevel
+}
+
+func (r *ErrorRecorder) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(r.fields)+len(fields))
+	combined = append(combined, r.fields...)
+	return &ErrorRecorder{ring: r.ring, fields: append(combined, fields...)}
+}
+
+func (r *ErrorRecorder) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if r.Enabled(entry.Level) {
+		return checked.AddCore(entry, r)
+	}
+	return checked
+}
+
+func (r *ErrorRecorder) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	recorded := ErrorEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+	}
+	if entry.Caller.Defined {
+		recorded.Caller = entry.Caller.TrimmedPath()
+	}
+	if len(r.fields)+len(fields) > 0 {
+		encoder := zapcore.NewMapObjectEncoder()
+		for _, field := range r.fields {
+			field.AddTo(encoder)
+		}
+		for _, field := range fields {
+			field.AddTo(encoder)
+		}
+		recorded.Fields = encoder.Fields
+	}
+
+	r.ring.mu.Lock()
+	defer r.ring.mu.Unlock()
+	r.ring.entries[r.ring.next] = recorded
+	r.ring.next = (r.ring.next + 1) % len(r.ring.entries)
+	if r.ring.next == 0 {
+		r.ring.full = true
+	}
+	return nil
+}
+
+func (r *ErrorRecorder) Sync() error {
+	return nil
+}
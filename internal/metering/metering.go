@@ -0,0 +1,299 @@
+package metering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Units of metered quantities
+const (
+	UnitHours = "hours" // resource-hours accumulated over the period
+	UnitCount = "count" // peak number of resources during the period
+)
+
+// maxPendingRecords bounds the records kept for an exporter that keeps
+// failing; the oldest are dropped first
+const maxPendingRecords = 100000
+
+// Record is a tenant's metered use of one kind of resource over a period
+type Record struct {
+	TenantID    string    `json:"tenant_id"`
+	Metric      string    `json:"metric"`
+	Value       float64   `json:"value"`
+	Unit        string    `json:"unit"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// Exporter sends metering records to a billing or monitoring system
+type Exporter interface {
+	Name() string
+	Export(ctx context.Context, records []Record) error
+}
+
+// UsageSource reports tenants' current resource usage. *db.DB implements it.
+type UsageSource interface {
+	ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error)
+	GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error)
+}
+
+// Config configures metering. Each exporter is enabled by setting its
+// destination.
+type Config struct {
+	SampleInterval time.Duration // How often usage is sampled
+	ExportInterval time.Duration // Length of the period each record covers
+	CSVPath        string        // File records are appended to
+	WebhookURL     string        // URL records are POSTed to as JSON
+	WebhookSecret  string        // Key for the webhook's HMAC-SHA256 signature
+	RemoteWriteURL string        // Prometheus remote-write endpoint
+}
+
+// meter derives one metric from a tenant's usage
+type meter struct {
+	metric string
+	unit   string
+	count  func(*models.ResourceUsage) int
+}
+
+var meters = []meter{
+	{"switch_hours", UnitHours, func(u *models.ResourceUsage) int { return u.Switches }},
+	{"router_hours", UnitHours, func(u *models.ResourceUsage) int { return u.Routers }},
+	{"load_balancer_hours", UnitHours, func(u *models.ResourceUsage) int { return u.LoadBalancers }},
+	{"port_count", UnitCount, func(u *models.ResourceUsage) int { return u.Ports }},
+}
+
+// Meter samples the usage of active tenants, aggregates it into
+// resource-hours and peak counts, and exports a record per tenant and metric
+// at the end of every period
+type Meter struct {
+	source    UsageSource
+	exporters []Exporter
+	cfg       Config
+	logger    *zap.Logger
+	now       func() time.Time
+
+	mu          sync.Mutex
+	periodStart time.Time
+	lastSample  time.Time
+	last        map[string]*models.ResourceUsage // usage at the last sample
+	totals      map[string]map[string]float64    // tenant to metric to value
+	pending     map[string][]Record              // exporter to records not yet exported
+}
+
+// New creates a meter exporting to the destinations set in cfg
+func New(source UsageSource, cfg Config, logger *zap.Logger) (*Meter, error) {
+	if cfg.SampleInterval <= 0 || cfg.ExportInterval <= 0 {
+		return nil, fmt.Errorf("metering sample and export intervals must be positive")
+	}
+
+	var exporters []Exporter
+	if cfg.CSVPath != "" {
+		exporters = append(exporters, NewCSVExporter(cfg.CSVPath))
+	}
+	if cfg.WebhookURL != "" {
+		exporters = append(exporters, NewWebhookExporter(cfg.WebhookURL, cfg.WebhookSecret))
+	}
+	if cfg.RemoteWriteURL != "" {
+		exporters = append(exporters, NewRemoteWriteExporter(cfg.RemoteWriteURL))
+	}
+	if len(exporters) == 0 {
+		return nil, fmt.Errorf("metering requires a CSV path, webhook URL or remote-write URL")
+	}
+
+	return NewMeter(source, exporters, cfg, logger), nil
+}
+
+// NewMeter creates a meter with the given exporters
+func NewMeter(source UsageSource, exporters []Exporter, cfg Config, logger *zap.Logger) *Meter {
+	return &Meter{
+		source:    source,
+		exporters: exporters,
+		cfg:       cfg,
+		logger:    logger,
+		now:       time.Now,
+		last:      make(map[string]*models.ResourceUsage),
+		totals:    make(map[string]map[string]float64),
+		pending:   make(map[string][]Record),
+	}
+}
+
+// Run samples and exports until ctx is done, then exports the partial period
+func (m *Meter) Run(ctx context.Context) {
+	sample := time.NewTicker(m.cfg.SampleInterval)
+	defer sample.Stop()
+	export := time.NewTicker(m.cfg.ExportInterval)
+	defer export.Stop()
+
+	if err := m.Sample(ctx); err != nil {
+		m.logger.Error("Failed to sample usage for metering", zap.Error(err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := m.Flush(flushCtx); err != nil {
+				m.logger.Error("Failed to export metering records", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-sample.C:
+			if err := m.Sample(ctx); err != nil {
+				m.logger.Error("Failed to sample usage for metering", zap.Error(err))
+			}
+		case <-export.C:
+			if err := m.Flush(ctx); err != nil {
+				m.logger.Error("Failed to export metering records", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sample accumulates resource-hours since the last sample, at the usage seen
+// then, and reads the current usage of active tenants
+func (m *Meter) Sample(ctx context.Context) error {
+	tenants, err := m.source.ListTenants(ctx, &models.TenantFilter{Status: models.TenantStatusActive})
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	current := make(map[string]*models.ResourceUsage, len(tenants))
+	for _, tenant := range tenants {
+		usage, err := m.source.GetResourceUsage(ctx, tenant.ID)
+		if err != nil {
+			m.logger.Warn("Failed to get usage for metering",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+			continue
+		}
+		current[tenant.ID] = usage
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sampleLocked(current)
+	return nil
+}
+
+func (m *Meter) sampleLocked(current map[string]*models.ResourceUsage) {
+	now := m.now()
+	if m.periodStart.IsZero() {
+		m.periodStart = now
+	}
+
+	if !m.lastSample.IsZero() {
+		hours := now.Sub(m.lastSample).Hours()
+		for tenantID, usage := range m.last {
+			for _, mt := range meters {
+				if mt.unit == UnitHours {
+					m.total(tenantID)[mt.metric] += float64(mt.count(usage)) * hours
+				}
+			}
+		}
+	}
+
+	m.last = current
+	m.lastSample = now
+	m.recordPeaksLocked()
+}
+
+// recordPeaksLocked raises the period's peak counts to the last usage seen
+func (m *Meter) recordPeaksLocked() {
+	for tenantID, usage := range m.last {
+		for _, mt := range meters {
+			if mt.unit == UnitCount {
+				totals := m.total(tenantID)
+				totals[mt.metric] = max(totals[mt.metric], float64(mt.count(usage)))
+			}
+		}
+	}
+}
+
+func (m *Meter) total(tenantID string) map[string]float64 {
+	totals, ok := m.totals[tenantID]
+	if !ok {
+		totals = make(map[string]float64)
+		m.totals[tenantID] = totals
+	}
+	return totals
+}
+
+// Flush samples usage, ends the current period and exports its records.
+// Records an exporter fails to take are retried with the next period's.
+func (m *Meter) Flush(ctx context.Context) error {
+	if err := m.Sample(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	records := m.closePeriodLocked()
+	batches := make(map[string][]Record, len(m.exporters))
+	for _, exporter := range m.exporters {
+		batch := append(m.pending[exporter.Name()], records...)
+		if len(batch) > maxPendingRecords {
+			batch = batch[len(batch)-maxPendingRecords:]
+		}
+		batches[exporter.Name()] = batch
+		delete(m.pending, exporter.Name())
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, exporter := range m.exporters {
+		batch := batches[exporter.Name()]
+		if len(batch) == 0 {
+			continue
+		}
+		if err := exporter.Export(ctx, batch); err != nil {
+			m.mu.Lock()
+			m.pending[exporter.Name()] = append(batch, m.pending[exporter.Name()]...)
+			m.mu.Unlock()
+			errs = append(errs, fmt.Errorf("%s: %w", exporter.Name(), err))
+			continue
+		}
+		m.logger.Debug("Exported metering records",
+			zap.String("exporter", exporter.Name()),
+			zap.Int("records", len(batch)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// closePeriodLocked returns the records of the current period, ordered by
+// tenant and metric, and starts the next period
+func (m *Meter) closePeriodLocked() []Record {
+	var tenantIDs []string
+	for tenantID := range m.totals {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Strings(tenantIDs)
+
+	var records []Record
+	for _, tenantID := range tenantIDs {
+		for _, mt := range meters {
+			records = append(records, Record{
+				TenantID:    tenantID,
+				Metric:      mt.metric,
+				Value:       m.totals[tenantID][mt.metric],
+				Unit:        mt.unit,
+				PeriodStart: m.periodStart,
+				PeriodEnd:   m.lastSample,
+			})
+		}
+	}
+
+	m.totals = make(map[string]map[string]float64)
+	m.periodStart = m.lastSample
+
+	// Peaks of the next period start from the usage seen now
+	m.recordPeaksLocked()
+	return records
+}
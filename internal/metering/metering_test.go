@@ -0,0 +1,240 @@
+package metering
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// staticUsageSource serves the usage set in its map
+type staticUsageSource struct {
+	usage map[string]*models.ResourceUsage
+}
+
+func (s *staticUsageSource) ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	for id := range s.usage {
+		tenants = append(tenants, &models.Tenant{ID: id})
+	}
+	return tenants, nil
+}
+
+func (s *staticUsageSource) GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error) {
+	usage := *s.usage[tenantID]
+	return &usage, nil
+}
+
+// recordingExporter keeps what it exports and fails while err is set
+type recordingExporter struct {
+	err     error
+	batches [][]Record
+}
+
+func (e *recordingExporter) Name() string {
+	return "recording"
+}
+
+func (e *recordingExporter) Export(ctx context.Context, records []Record) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.batches = append(e.batches, records)
+	return nil
+}
+
+// testMeter returns a meter whose clock is advanced with the returned func
+func testMeter(source UsageSource, exporter Exporter) (*Meter, func(time.Duration)) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	meter := NewMeter(source, []Exporter{exporter}, Config{SampleInterval: time.Minute, ExportInterval: time.Hour}, zap.NewNop())
+	meter.now = func() time.Time { return now }
+	return meter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func recordValue(t *testing.T, records []Record, tenantID, metric string) float64 {
+	for _, record := range records {
+		if record.TenantID == tenantID && record.Metric == metric {
+			return record.Value
+		}
+	}
+	t.Fatalf("no %s record for %s", metric, tenantID)
+	return 0
+}
+
+func TestMeter_AggregatesResourceHours(t *testing.T) {
+	ctx := context.Background()
+	source := &staticUsageSource{usage: map[string]*models.ResourceUsage{
+		"acme": {Switches: 2, LoadBalancers: 1, Ports: 10},
+	}}
+	exporter := &recordingExporter{}
+	meter, advance := testMeter(source, exporter)
+
+	require.NoError(t, meter.Sample(ctx))
+	advance(30 * time.Minute)
+	source.usage["acme"] = &models.ResourceUsage{Switches: 4, LoadBalancers: 1, Ports: 30}
+	require.NoError(t, meter.Sample(ctx))
+	advance(30 * time.Minute)
+	source.usage["acme"] = &models.ResourceUsage{Switches: 4, Ports: 20}
+	require.NoError(t, meter.Flush(ctx))
+
+	require.Len(t, exporter.batches, 1)
+	records := exporter.batches[0]
+	assert.InDelta(t, 3.0, recordValue(t, records, "acme", "switch_hours"), 1e-9)
+	assert.InDelta(t, 1.0, recordValue(t, records, "acme", "load_balancer_hours"), 1e-9)
+	assert.Equal(t, 0.0, recordValue(t, records, "acme", "router_hours"))
+	assert.Equal(t, 30.0, recordValue(t, records, "acme", "port_count"))
+	assert.Equal(t, time.Hour, records[0].PeriodEnd.Sub(records[0].PeriodStart))
+
+	// The next period continues from the last sample
+	advance(time.Hour)
+	require.NoError(t, meter.Flush(ctx))
+	require.Len(t, exporter.batches, 2)
+	assert.InDelta(t, 4.0, recordValue(t, exporter.batches[1], "acme", "switch_hours"), 1e-9)
+	assert.Equal(t, 0.0, recordValue(t, exporter.batches[1], "acme", "load_balancer_hours"))
+	assert.Equal(t, 20.0, recordValue(t, exporter.batches[1], "acme", "port_count"))
+}
+
+func TestMeter_RetriesFailedExports(t *testing.T) {
+	ctx := context.Background()
+	source := &staticUsageSource{usage: map[string]*models.ResourceUsage{"acme": {Switches: 1}}}
+	exporter := &recordingExporter{err: errors.New("billing down")}
+	meter, advance := testMeter(source, exporter)
+
+	require.NoError(t, meter.Sample(ctx))
+	advance(time.Hour)
+	err := meter.Flush(ctx)
+	assert.ErrorContains(t, err, "recording: billing down")
+
+	exporter.err = nil
+	advance(time.Hour)
+	require.NoError(t, meter.Flush(ctx))
+
+	require.Len(t, exporter.batches, 1)
+	assert.Len(t, exporter.batches[0], 2*len(meters), "both periods are exported")
+}
+
+func TestCSVExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metering.csv")
+	exporter := NewCSVExporter(path)
+	record := Record{
+		TenantID:    "acme",
+		Metric:      "switch_hours",
+		Value:       1.5,
+		Unit:        UnitHours,
+		PeriodStart: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, exporter.Export(context.Background(), []Record{record}))
+	require.NoError(t, exporter.Export(context.Background(), []Record{record}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant_id,metric,value,unit,period_start,period_end\n"+
+		"acme,switch_hours,1.5,hours,2024-03-01T10:00:00Z,2024-03-01T11:00:00Z\n"+
+		"acme,switch_hours,1.5,hours,2024-03-01T10:00:00Z,2024-03-01T11:00:00Z\n", string(data))
+}
+
+func TestWebhookExporter(t *testing.T) {
+	var got struct {
+		Records []Record `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if r.Header.Get(WebhookSignatureHeader) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	records := []Record{{TenantID: "acme", Metric: "switch_hours", Value: 2, Unit: UnitHours}}
+	require.NoError(t, NewWebhookExporter(server.URL, "s3cret").Export(context.Background(), records))
+	require.Len(t, got.Records, 1)
+	assert.Equal(t, "acme", got.Records[0].TenantID)
+
+	err := NewWebhookExporter(server.URL, "wrong").Export(context.Background(), records)
+	assert.ErrorContains(t, err, "401")
+}
+
+// snappyDecode decodes snappy blocks made of literals only
+func snappyDecode(t *testing.T, src []byte) []byte {
+	n, read := binary.Uvarint(src)
+	src = src[read:]
+	var dst []byte
+	for len(src) > 0 {
+		tag := src[0]
+		require.Zero(t, tag&3, "only literals are expected")
+		length := int(tag>>2) + 1
+		src = src[1:]
+		switch tag >> 2 {
+		case 60:
+			length = int(src[0]) + 1
+			src = src[1:]
+		case 61:
+			length = int(binary.LittleEndian.Uint16(src)) + 1
+			src = src[2:]
+		}
+		dst = append(dst, src[:length]...)
+		src = src[length:]
+	}
+	require.Equal(t, int(n), len(dst))
+	return dst
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, size := range []int{0, 1, 60, 61, 256, 257, 65536, 70000} {
+		src := []byte(strings.Repeat("x", size))
+		assert.Equal(t, string(src), string(snappyDecode(t, snappyEncode(src))), "size %d", size)
+	}
+}
+
+func TestRemoteWriteExporter(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		compressed, _ := io.ReadAll(r.Body)
+		body = snappyDecode(t, compressed)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	end := time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)
+	records := []Record{{TenantID: "acme", Metric: "switch_hours", Value: 2.5, Unit: UnitHours, PeriodEnd: end}}
+	require.NoError(t, NewRemoteWriteExporter(server.URL).Export(context.Background(), records))
+
+	// One time series with sorted labels and one sample
+	var want []byte
+	label := func(name, value string) []byte {
+		return appendBytesField(appendBytesField(nil, 1, []byte(name)), 2, []byte(value))
+	}
+	series := appendBytesField(nil, 1, label("__name__", "ovncp_metering_switch_hours"))
+	series = appendBytesField(series, 1, label("tenant_id", "acme"))
+	sample := []byte{0x09, 0, 0, 0, 0, 0, 0, 0x04, 0x40} // value: fixed64 2.5
+	sample = append(sample, 0x10)
+	sample = binary.AppendUvarint(sample, uint64(end.UnixMilli()))
+	series = appendBytesField(series, 2, sample)
+	want = appendBytesField(want, 1, series)
+	assert.Equal(t, want, body)
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lspecian/ovncp/internal/cache"
+)
+
+// CacheStatsFunc returns a cache's cumulative statistics
+type CacheStatsFunc func() cache.CacheStats
+
+var (
+	cacheHitsDesc = prometheus.NewDesc(
+		"ovncp_cache_hits_total",
+		"Total number of cache hits",
+		[]string{"cache_name"}, nil,
+	)
+	cacheMissesDesc = prometheus.NewDesc(
+		"ovncp_cache_misses_total",
+		"Total number of cache misses",
+		[]string{"cache_name"}, nil,
+	)
+	cacheEvictionsDesc = prometheus.NewDesc(
+		"ovncp_cache_evictions_total",
+		"Total number of cache evictions",
+		[]string{"cache_name"}, nil,
+	)
+	cacheHitRatioDesc = prometheus.NewDesc(
+		"ovncp_cache_hit_ratio",
+		"Ratio of cache lookups that were hits",
+		[]string{"cache_name"}, nil,
+	)
+)
+
+// cacheCollector reads the statistics of registered caches at scrape time
+type cacheCollector struct {
+	mu     sync.RWMutex
+	caches map[string]CacheStatsFunc
+}
+
+var caches = &cacheCollector{caches: make(map[string]CacheStatsFunc)}
+
+func init() {
+	prometheus.MustRegister(caches)
+}
+
+// RegisterCache exposes a cache's statistics under name; registering a name
+// again replaces the cache
+func RegisterCache(name string, stats CacheStatsFunc) {
+	caches.mu.Lock()
+	defer caches.mu.Unlock()
+	caches.caches[name] = stats
+}
+
+// CacheStatistics returns the statistics of every registered cache, keyed
+// by name
+func CacheStatistics() map[string]cache.CacheStats {
+	caches.mu.RLock()
+	defer caches.mu.RUnlock()
+
+	stats := make(map[string]cache.CacheStats, len(caches.caches))
+	for name, statsFn := range caches.caches {
+		stats[name] = statsFn()
+	}
+	return stats
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheHitRatioDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, statsFn := range c.caches {
+		stats := statsFn()
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions), name)
+
+		ratio := 0.0
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			ratio = float64(stats.Hits) / float64(lookups)
+		}
+		ch <- prometheus.MustNewConstMetric(cacheHitRatioDesc, prometheus.GaugeValue, ratio, name)
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/cache"
+)
+
+func TestCacheCollector(t *testing.T) {
+	stats := cache.CacheStats{Hits: 3, Misses: 1, Evictions: 2}
+	RegisterCache("test", func() cache.CacheStats { return stats })
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "cache_name" && label.GetValue() == "test" {
+					values[family.GetName()] = metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"ovncp_cache_hits_total":      3,
+		"ovncp_cache_misses_total":    1,
+		"ovncp_cache_evictions_total": 2,
+		"ovncp_cache_hit_ratio":       0.75,
+	}, values)
+}
+
+func TestCacheStatistics(t *testing.T) {
+	RegisterCache("statistics", func() cache.CacheStats { return cache.CacheStats{Hits: 5, Sets: 2} })
+
+	stats := CacheStatistics()
+	assert.Equal(t, cache.CacheStats{Hits: 5, Sets: 2}, stats["statistics"])
+}
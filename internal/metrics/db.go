@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBStatsFunc returns a connection pool's statistics, as (*sql.DB).Stats
+// does
+type DBStatsFunc func() sql.DBStats
+
+var (
+	dbConnectionsMaxOpenDesc = prometheus.NewDesc(
+		"ovncp_db_connections_max_open",
+		"Maximum number of open database connections",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionsOpenDesc = prometheus.NewDesc(
+		"ovncp_db_connections_open",
+		"Number of open database connections, in use or idle",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionsActiveDesc = prometheus.NewDesc(
+		"ovncp_db_connections_active",
+		"Number of database connections in use",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionsIdleDesc = prometheus.NewDesc(
+		"ovncp_db_connections_idle",
+		"Number of idle database connections",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionWaitsDesc = prometheus.NewDesc(
+		"ovncp_db_connection_waits_total",
+		"Total number of times a query waited for a free database connection",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionWaitSecondsDesc = prometheus.NewDesc(
+		"ovncp_db_connection_wait_seconds_total",
+		"Total time queries waited for a free database connection",
+		[]string{"pool"}, nil,
+	)
+	dbConnectionsClosedDesc = prometheus.NewDesc(
+		"ovncp_db_connections_closed_total",
+		"Total number of database connections closed by the pool, by reason",
+		[]string{"pool", "reason"}, nil,
+	)
+)
+
+// dbPoolCollector reads the statistics of registered connection pools at
+// scrape time
+type dbPoolCollector struct {
+	mu    sync.RWMutex
+	pools map[string]DBStatsFunc
+}
+
+var dbPools = &dbPoolCollector{pools: make(map[string]DBStatsFunc)}
+
+func init() {
+	prometheus.MustRegister(dbPools)
+}
+
+// RegisterDBPool exposes a connection pool's statistics under name;
+// registering a name again replaces the pool
+func RegisterDBPool(name string, stats DBStatsFunc) {
+	dbPools.mu.Lock()
+	defer dbPools.mu.Unlock()
+	dbPools.pools[name] = stats
+}
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbConnectionsMaxOpenDesc
+	ch <- dbConnectionsOpenDesc
+	ch <- dbConnectionsActiveDesc
+	ch <- dbConnectionsIdleDesc
+	ch <- dbConnectionWaitsDesc
+	ch <- dbConnectionWaitSecondsDesc
+	ch <- dbConnectionsClosedDesc
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, statsFn := range c.pools {
+		stats := statsFn()
+		ch <- prometheus.MustNewConstMetric(dbConnectionsMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionsOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionsActiveDesc, prometheus.GaugeValue, float64(stats.InUse), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionsIdleDesc, prometheus.GaugeValue, float64(stats.Idle), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionWaitsDesc, prometheus.CounterValue, float64(stats.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionWaitSecondsDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(dbConnectionsClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), name, "max_idle")
+		ch <- prometheus.MustNewConstMetric(dbConnectionsClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), name, "max_idle_time")
+		ch <- prometheus.MustNewConstMetric(dbConnectionsClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), name, "max_lifetime")
+	}
+}
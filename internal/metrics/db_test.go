@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBPoolCollector(t *testing.T) {
+	stats := sql.DBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    7,
+		InUse:              4,
+		Idle:               3,
+		WaitCount:          2,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxLifetimeClosed:  5,
+	}
+	RegisterDBPool("test", func() sql.DBStats { return stats })
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var pool, reason string
+			for _, label := range metric.GetLabel() {
+				switch label.GetName() {
+				case "pool":
+					pool = label.GetValue()
+				case "reason":
+					reason = "/" + label.GetValue()
+				}
+			}
+			if pool == "test" {
+				values[family.GetName()+reason] = metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"ovncp_db_connections_max_open":                   25,
+		"ovncp_db_connections_open":                       7,
+		"ovncp_db_connections_active":                     4,
+		"ovncp_db_connections_idle":                       3,
+		"ovncp_db_connection_waits_total":                 2,
+		"ovncp_db_connection_wait_seconds_total":          1.5,
+		"ovncp_db_connections_closed_total/max_idle":      0,
+		"ovncp_db_connections_closed_total/max_idle_time": 0,
+		"ovncp_db_connections_closed_total/max_lifetime":  5,
+	}, values)
+}
@@ -62,6 +62,39 @@ var (
 		[]string{"operation", "resource"},
 	)
 
+	OVSDBTransactionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ovncp_ovsdb_transactions_total",
+			Help: "Total number of OVSDB transactions committed to the northbound database",
+		},
+		[]string{"status"}, // success, failure (an operation failed), error (the transaction failed)
+	)
+
+	OVSDBTransactionRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ovncp_ovsdb_transaction_retries_total",
+			Help: "Total number of OVSDB transactions retried, by the error retried",
+		},
+		[]string{"reason"}, // disconnected, timeout, cluster_error, or committed when a failed attempt turned out committed
+	)
+
+	OVSDBTransactionDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "ovncp_ovsdb_transaction_duration_seconds",
+			Help:    "OVSDB transaction duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	OVSDBCacheSyncDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ovncp_ovsdb_cache_sync_duration_seconds",
+			Help:    "Time for the OVSDB cache to reflect a committed transaction, in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+		},
+		[]string{"status"}, // synced or timeout
+	)
+
 	OVNConnectionStatus = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "ovncp_ovn_connection_status",
@@ -88,20 +121,6 @@ var (
 		[]string{"query_type", "table"},
 	)
 
-	DBConnectionsActive = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "ovncp_db_connections_active",
-			Help: "Number of active database connections",
-		},
-	)
-
-	DBConnectionsIdle = promauto.NewGauge(
-		prometheus.GaugeOpts{
-			Name: "ovncp_db_connections_idle",
-			Help: "Number of idle database connections",
-		},
-	)
-
 	// Business metrics
 	LogicalSwitchesTotal = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -171,29 +190,23 @@ var (
 		},
 	)
 
-	// Cache metrics
-	CacheHitsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "ovncp_cache_hits_total",
-			Help: "Total number of cache hits",
-		},
-		[]string{"cache_name"},
-	)
-
-	CacheMissesTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "ovncp_cache_misses_total",
-			Help: "Total number of cache misses",
+	// Batch processor metrics
+	BatchQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ovncp_batch_queue_depth",
+			Help: "Number of operations waiting in a batch processor queue",
 		},
-		[]string{"cache_name"},
+		[]string{"queue"},
 	)
 
-	CacheEvictionsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "ovncp_cache_evictions_total",
-			Help: "Total number of cache evictions",
+	// Backup metrics
+	BackupDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ovncp_backup_duration_seconds",
+			Help:    "Backup and restore duration in seconds",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
 		},
-		[]string{"cache_name"},
+		[]string{"operation", "status"}, // operation: backup, restore
 	)
 
 	// Error metrics
@@ -282,13 +295,35 @@ func RecordTransaction(status string, operationCount int) {
 	TransactionOperationsHistogram.Observe(float64(operationCount))
 }
 
-// RecordCacheOperation records cache operation metrics
-func RecordCacheOperation(cacheName string, hit bool) {
-	if hit {
-		CacheHitsTotal.WithLabelValues(cacheName).Inc()
-	} else {
-		CacheMissesTotal.WithLabelValues(cacheName).Inc()
+// RecordOVSDBTransaction records an OVSDB transaction
+func RecordOVSDBTransaction(status string, duration float64) {
+	OVSDBTransactionsTotal.WithLabelValues(status).Inc()
+	OVSDBTransactionDuration.Observe(duration)
+}
+
+// RecordOVSDBTransactionRetry records the retry of an OVSDB transaction
+func RecordOVSDBTransactionRetry(reason string) {
+	OVSDBTransactionRetriesTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordOVSDBCacheSync records the wait for the OVSDB cache to reflect a
+// committed transaction
+func RecordOVSDBCacheSync(status string, duration float64) {
+	OVSDBCacheSyncDuration.WithLabelValues(status).Observe(duration)
+}
+
+// SetBatchQueueDepth sets the number of operations waiting in a batch queue
+func SetBatchQueueDepth(queue string, depth int) {
+	BatchQueueDepth.WithLabelValues(queue).Set(float64(depth))
+}
+
+// RecordBackupOperation records the duration of a backup or restore
+func RecordBackupOperation(operation string, success bool, duration float64) {
+	status := "success"
+	if !success {
+		status = "failure"
 	}
+	BackupDuration.WithLabelValues(operation, status).Observe(duration)
 }
 
 // RecordError records error metrics
@@ -313,9 +348,3 @@ func SetOVNConnectionStatus(component string, connected bool) {
 	}
 	OVNConnectionStatus.WithLabelValues(component).Set(value)
 }
-
-// UpdateDBConnectionMetrics updates database connection pool metrics
-func UpdateDBConnectionMetrics(active, idle int) {
-	DBConnectionsActive.Set(float64(active))
-	DBConnectionsIdle.Set(float64(idle))
-}
\ No newline at end of file
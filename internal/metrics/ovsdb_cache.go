@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OVSDBCacheStats describes the in-memory cache an OVN client keeps of the
+// northbound database through its monitors
+type OVSDBCacheStats struct {
+	// Synced is whether the monitors are established, so that the cache
+	// follows the database
+	Synced bool
+	// LastUpdate is when the cache last applied an update from the server,
+	// zero before the first one
+	LastUpdate time.Time
+	// Rows is the number of cached rows of each table
+	Rows map[string]int
+}
+
+// OVSDBCacheStatsFunc returns the current state of an OVSDB cache
+type OVSDBCacheStatsFunc func() OVSDBCacheStats
+
+var (
+	ovsdbCacheAgeDesc = prometheus.NewDesc(
+		"ovncp_ovsdb_cache_age_seconds",
+		"Seconds since the OVSDB cache last applied an update from the server",
+		[]string{"cluster"}, nil,
+	)
+	ovsdbCacheSyncedDesc = prometheus.NewDesc(
+		"ovncp_ovsdb_cache_synced",
+		"Whether the OVSDB cache follows the database (1=monitoring, 0=stale)",
+		[]string{"cluster"}, nil,
+	)
+	ovsdbCacheRowsDesc = prometheus.NewDesc(
+		"ovncp_ovsdb_cache_rows",
+		"Number of rows in the OVSDB cache",
+		[]string{"cluster", "table"}, nil,
+	)
+)
+
+// ovsdbCacheCollector reads the state of registered OVSDB caches at scrape
+// time
+type ovsdbCacheCollector struct {
+	mu     sync.RWMutex
+	caches map[string]OVSDBCacheStatsFunc
+	now    func() time.Time
+}
+
+var ovsdbCaches = &ovsdbCacheCollector{caches: make(map[string]OVSDBCacheStatsFunc), now: time.Now}
+
+func init() {
+	prometheus.MustRegister(ovsdbCaches)
+}
+
+// RegisterOVSDBCache exposes the OVSDB cache of the named OVN cluster;
+// registering a cluster again replaces its cache
+func RegisterOVSDBCache(cluster string, stats OVSDBCacheStatsFunc) {
+	ovsdbCaches.mu.Lock()
+	defer ovsdbCaches.mu.Unlock()
+	ovsdbCaches.caches[cluster] = stats
+}
+
+func (c *ovsdbCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ovsdbCacheAgeDesc
+	ch <- ovsdbCacheSyncedDesc
+	ch <- ovsdbCacheRowsDesc
+}
+
+func (c *ovsdbCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for cluster, statsFn := range c.caches {
+		stats := statsFn()
+
+		synced := 0.0
+		if stats.Synced {
+			synced = 1
+		}
+		ch <- prometheus.MustNewConstMetric(ovsdbCacheSyncedDesc, prometheus.GaugeValue, synced, cluster)
+		// Without an update yet, the cache has no age to report
+		if !stats.LastUpdate.IsZero() {
+			age := c.now().Sub(stats.LastUpdate).Seconds()
+			ch <- prometheus.MustNewConstMetric(ovsdbCacheAgeDesc, prometheus.GaugeValue, age, cluster)
+		}
+		for table, rows := range stats.Rows {
+			ch <- prometheus.MustNewConstMetric(ovsdbCacheRowsDesc, prometheus.GaugeValue, float64(rows), cluster, table)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOVSDBCacheCollector(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ovsdbCaches.now = func() time.Time { return now }
+	defer func() { ovsdbCaches.now = time.Now }()
+
+	RegisterOVSDBCache("test", func() OVSDBCacheStats {
+		return OVSDBCacheStats{
+			Synced:     true,
+			LastUpdate: now.Add(-90 * time.Second),
+			Rows:       map[string]int{"Logical_Switch": 4, "ACL": 12},
+		}
+	})
+	RegisterOVSDBCache("never-updated", func() OVSDBCacheStats {
+		return OVSDBCacheStats{}
+	})
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["cluster"] == "" {
+				continue
+			}
+			key := labels["cluster"] + " " + family.GetName()
+			if labels["table"] != "" {
+				key += " " + labels["table"]
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"test ovncp_ovsdb_cache_synced":              1,
+		"test ovncp_ovsdb_cache_age_seconds":         90,
+		"test ovncp_ovsdb_cache_rows Logical_Switch": 4,
+		"test ovncp_ovsdb_cache_rows ACL":            12,
+		"never-updated ovncp_ovsdb_cache_synced":     0,
+	}, values)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// APIKeyContextKey is the context key of the API key a request was
+// authenticated with
+const APIKeyContextKey = "api_key"
+
+// APIKeyValidator validates a tenant API key. TenantService.ValidateAPIKey
+// implements it.
+type APIKeyValidator func(ctx context.Context, apiKey string) (*models.TenantAPIKey, error)
+
+// authenticateAPIKey authenticates the request with a tenant API key,
+// rejecting keys used from outside their allowed CIDRs
+func authenticateAPIKey(c *gin.Context, validate APIKeyValidator, apiKey string) {
+	key, err := validate(c.Request.Context(), apiKey)
+	if err != nil || key == nil {
+		problem.Respond(c, problem.New(http.StatusUnauthorized, "Invalid API key"))
+		c.Abort()
+		return
+	}
+
+	if !clientAllowed(key, c.ClientIP()) {
+		problem.Respond(c, problem.New(http.StatusForbidden, "API key not allowed from this address"))
+		c.Abort()
+		return
+	}
+
+	c.Set(APIKeyContextKey, key)
+	c.Set("user_id", "apikey:"+key.ID)
+	c.Next()
+}
+
+// GetAPIKey returns the API key the request was authenticated with, if any
+func GetAPIKey(c *gin.Context) *models.TenantAPIKey {
+	if key, exists := c.Get(APIKeyContextKey); exists {
+		if apiKey, ok := key.(*models.TenantAPIKey); ok {
+			return apiKey
+		}
+	}
+	return nil
+}
+
+// clientAllowed reports whether the key may be used from clientIP. Keys
+// without allowed CIDRs may be used from anywhere.
+func clientAllowed(key *models.TenantAPIKey, clientIP string) bool {
+	if len(key.AllowedCIDRs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, cidr := range key.AllowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyHasPermission reports whether any of the key's scopes grants
+// permission
+func apiKeyHasPermission(key *models.TenantAPIKey, permission string) bool {
+	for _, scope := range key.Scopes {
+		if scopeGrants(scope, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeGrants reports whether an API key scope grants permission. Keys act
+// within their tenant only, so no scope grants user management, global
+// admin or permissions over tenants themselves.
+func scopeGrants(scope, permission string) bool {
+	resource, action, _ := strings.Cut(permission, ":")
+	switch resource {
+	case "users", "admin":
+		return false
+	}
+
+	switch scope {
+	case models.APIKeyScopeRead:
+		return action == "read" && resource != "backups"
+	case models.APIKeyScopeWrite:
+		switch resource {
+		case "switches", "routers", "ports", "acls", "load_balancers", "network_policies", "dns", "mirrors", "sampling", "floating_ips", "vpn", "gateways", "apply":
+			return action == "write" || action == "delete"
+		case "changesets":
+			return action == "write" || action == "execute"
+		case "trash":
+			// Purging the recycle bin stays with admin keys
+			return action == "write"
+		}
+		return false
+	case models.APIKeyScopeBackup:
+		return resource == "backups"
+	case models.APIKeyScopeTrace:
+		return permission == "trace:run"
+	case models.APIKeyScopeAdmin:
+		switch permission {
+		case "tenants:create", "tenants:delete", "tenants:join", TenantOverridePermission, "templates:admin":
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestScopeGrants(t *testing.T) {
+	tests := []struct {
+		scope      string
+		permission string
+		want       bool
+	}{
+		{models.APIKeyScopeRead, "switches:read", true},
+		{models.APIKeyScopeRead, "switches:write", false},
+		{models.APIKeyScopeRead, "backups:read", false},
+		{models.APIKeyScopeRead, "users:read", false},
+		{models.APIKeyScopeWrite, "ports:write", true},
+		{models.APIKeyScopeWrite, "ports:delete", true},
+		{models.APIKeyScopeWrite, "dns:write", true},
+		{models.APIKeyScopeWrite, "dns:delete", true},
+		{models.APIKeyScopeWrite, "mirrors:delete", true},
+		{models.APIKeyScopeWrite, "sampling:write", true},
+		{models.APIKeyScopeWrite, "floating_ips:write", true},
+		{models.APIKeyScopeWrite, "vpn:write", true},
+		{models.APIKeyScopeWrite, "gateways:write", true},
+		{models.APIKeyScopeWrite, "changesets:execute", true},
+		{models.APIKeyScopeWrite, "trash:write", true},
+		{models.APIKeyScopeWrite, "trash:delete", false},
+		{models.APIKeyScopeWrite, "changesets:approve", false},
+		{models.APIKeyScopeWrite, "backups:write", false},
+		{models.APIKeyScopeWrite, "provider_networks:write", false},
+		{models.APIKeyScopeAdmin, "provider_networks:write", true},
+		{models.APIKeyScopeBackup, "backups:write", true},
+		{models.APIKeyScopeBackup, "backups:read", true},
+		{models.APIKeyScopeTrace, "trace:run", true},
+		{models.APIKeyScopeTrace, "topology:read", false},
+		{models.APIKeyScopeAdmin, "tenants:write", true},
+		{models.APIKeyScopeAdmin, "changesets:approve", true},
+		{models.APIKeyScopeAdmin, "admin", false},
+		{models.APIKeyScopeAdmin, "users:write", false},
+		{models.APIKeyScopeAdmin, "tenants:delete", false},
+		{models.APIKeyScopeAdmin, TenantOverridePermission, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, scopeGrants(tt.scope, tt.permission), "%s grants %s", tt.scope, tt.permission)
+	}
+}
+
+func TestClientAllowed(t *testing.T) {
+	key := &models.TenantAPIKey{AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}}
+	assert.True(t, clientAllowed(key, "10.20.30.40"))
+	assert.True(t, clientAllowed(key, "::ffff:10.20.30.40"))
+	assert.True(t, clientAllowed(key, "2001:db8::5"))
+	assert.False(t, clientAllowed(key, "192.0.2.1"))
+	assert.False(t, clientAllowed(key, ""))
+
+	assert.True(t, clientAllowed(&models.TenantAPIKey{}, "192.0.2.1"), "keys without CIDRs are allowed anywhere")
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := map[string]*models.TenantAPIKey{
+		"ovncp_t1_reader": {ID: "k1", TenantID: "t1", Scopes: []string{"read"}},
+		"ovncp_t1_office": {ID: "k2", TenantID: "t1", Scopes: []string{"read", "write"}, AllowedCIDRs: []string{"192.0.2.0/24"}},
+	}
+	validate := func(ctx context.Context, apiKey string) (*models.TenantAPIKey, error) {
+		if key, ok := keys[apiKey]; ok {
+			return key, nil
+		}
+		return nil, errors.New("invalid API key")
+	}
+
+	engine := gin.New()
+	engine.Use(requireAuth(AuthConfig{APIKeyValidator: validate}))
+	engine.GET("/switches", RequirePermission("switches:read"), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+	engine.POST("/switches", RequirePermission("switches:write"), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	serve := func(method, apiKey, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/switches", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "ovncp_t1_reader", "198.51.100.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "apikey:k1", w.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "ovncp_t1_reader", "198.51.100.1:1234").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "ovncp_t1_unknown", "198.51.100.1:1234").Code)
+
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "ovncp_t1_office", "192.0.2.10:1234").Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "ovncp_t1_office", "198.51.100.1:1234").Code)
+}
@@ -55,12 +55,12 @@ type AuditFilter struct {
 
 // DatabaseAuditLogger stores audit logs in the database
 type DatabaseAuditLogger struct {
-	db     *db.DB
+	db     db.Repository
 	logger *zap.Logger
 }
 
 // NewDatabaseAuditLogger creates a new database audit logger
-func NewDatabaseAuditLogger(database *db.DB, logger *zap.Logger) *DatabaseAuditLogger {
+func NewDatabaseAuditLogger(database db.Repository, logger *zap.Logger) *DatabaseAuditLogger {
 	return &DatabaseAuditLogger{
 		db:     database,
 		logger: logger,
@@ -169,7 +169,7 @@ func (l *DatabaseAuditLogger) Query(filter AuditFilter) ([]*AuditEvent, error) {
 		args = append(args, filter.Offset)
 	}
 	
-	rows, err := l.db.Query(query, args...)
+	rows, err := l.db.QueryReadOnly(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -225,14 +225,19 @@ type AuditConfig struct {
 	IncludeResources []string // Resource types to audit
 }
 
-// responseWriter wraps gin.ResponseWriter to capture response body
+// responseWriter wraps gin.ResponseWriter to capture response body, up to
+// one byte past limit: longer bodies aren't logged, and streamed ones could
+// be of any length
 type responseWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body  *bytes.Buffer
+	limit int64
 }
 
 func (w responseWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	if room := w.limit + 1 - int64(w.body.Len()); room > 0 {
+		w.body.Write(b[:min(int64(len(b)), room)])
+	}
 	return w.ResponseWriter.Write(b)
 }
 
@@ -272,7 +277,7 @@ func Audit(cfg AuditConfig) gin.HandlerFunc {
 		}
 		
 		// Wrap response writer to capture response
-		blw := &responseWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		blw := &responseWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer, limit: cfg.MaxBodySize}
 		c.Writer = blw
 		
 		// Process request
@@ -1,15 +1,39 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
 )
 
+// defaultJWTSecret is used when no secret has been configured
+const defaultJWTSecret = "your-secret-key-here-min-32-chars"
+
+// TokenValidator validates an opaque or externally issued bearer token
+type TokenValidator func(ctx context.Context, token string) (*models.User, error)
+
 // RequireAuth middleware checks for valid JWT token
 func RequireAuth() gin.HandlerFunc {
+	return requireAuth(AuthConfig{})
+}
+
+func requireAuth(cfg AuthConfig) gin.HandlerFunc {
+	secret := cfg.JWTSecret
+	if secret == "" {
+		secret = defaultJWTSecret
+	}
+	jwtSecrets := cfg.JWTSecrets
+	if jwtSecrets == nil {
+		jwtSecrets = func() []string { return []string{secret} }
+	}
+
 	return func(c *gin.Context) {
 		// Skip auth in development if AUTH_ENABLED is false
 		if c.GetString("AUTH_ENABLED") == "false" {
@@ -17,9 +41,14 @@ func RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		if apiKey := extractAPIKey(c); apiKey != "" && cfg.APIKeyValidator != nil {
+			authenticateAPIKey(c, cfg.APIKeyValidator, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, "Authorization header required"))
 			c.Abort()
 			return
 		}
@@ -27,7 +56,7 @@ func RequireAuth() gin.HandlerFunc {
 		// Extract token from Bearer scheme
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			problem.Respond(c, problem.New(http.StatusUnauthorized, "Invalid authorization header format"))
 			c.Abort()
 			return
 		}
@@ -35,32 +64,71 @@ func RequireAuth() gin.HandlerFunc {
 		tokenString := parts[1]
 		
 		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		token, err := parseJWT(tokenString, jwtSecrets())
+
+		if err == nil && token.Valid {
+			// Extract claims
+			if claims, ok := token.Claims.(jwt.MapClaims); ok {
+				c.Set("user_id", claims["sub"])
+				c.Set("user_email", claims["email"])
+				c.Set("user_roles", claims["roles"])
+				c.Set("user", map[string]interface{}{
+					"id":    claims["sub"],
+					"email": claims["email"],
+					"roles": claims["roles"],
+				})
 			}
-			// Return the secret key (should be from config)
-			return []byte("your-secret-key-here-min-32-chars"), nil
-		})
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			c.Next()
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("user_id", claims["sub"])
-			c.Set("user_email", claims["email"])
-			c.Set("user_roles", claims["roles"])
+		// Fall back to session tokens and identity provider tokens
+		if cfg.TokenValidator != nil {
+			if user, verr := cfg.TokenValidator(c.Request.Context(), tokenString); verr == nil && user != nil {
+				setUserContext(c, user)
+				c.Next()
+				return
+			}
 		}
 
-		c.Next()
+		problem.Respond(c, problem.New(http.StatusUnauthorized, "Invalid token"))
+		c.Abort()
 	}
 }
 
+// parseJWT validates a token signed with any of secrets
+func parseJWT(tokenString string, secrets []string) (*jwt.Token, error) {
+	var token *jwt.Token
+	err := jwt.ErrSignatureInvalid
+	for _, secret := range secrets {
+		token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			// Validate signing method
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(secret), nil
+		})
+		if err == nil || !errors.Is(err, jwt.ErrSignatureInvalid) {
+			break
+		}
+	}
+	return token, err
+}
+
+// setUserContext exposes an authenticated user to downstream middleware
+func setUserContext(c *gin.Context, user *models.User) {
+	roles := []string{user.Role.String()}
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("user_roles", roles)
+	c.Set("user", map[string]interface{}{
+		"id":    user.ID,
+		"email": user.Email,
+		"roles": roles,
+	})
+}
+
 // RequirePermission middleware checks if user has required permission
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -70,52 +138,67 @@ func RequirePermission(permission string) gin.HandlerFunc {
 			return
 		}
 
-		// Get user roles from context
-		rolesInterface, exists := c.Get("user_roles")
-		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{"error": "No roles found"})
+		if _, exists := c.Get("user_roles"); !exists && GetAPIKey(c) == nil {
+			problem.Respond(c, problem.New(http.StatusForbidden, "No roles found"))
 			c.Abort()
 			return
 		}
 
-		// Convert roles to string slice
-		var userRoles []string
-		switch v := rolesInterface.(type) {
-		case []string:
-			userRoles = v
-		case []interface{}:
-			for _, role := range v {
-				if roleStr, ok := role.(string); ok {
-					userRoles = append(userRoles, roleStr)
-				}
-			}
+		if !hasPermission(c, permission) {
+			problem.Respond(c, problem.New(http.StatusForbidden, "Insufficient permissions"))
+			c.Abort()
+			return
 		}
 
-		// Check if user has admin role (admin has all permissions)
-		for _, role := range userRoles {
-			if role == "admin" {
-				c.Next()
-				return
-			}
-		}
+		c.Next()
+	}
+}
+
+// HasPermission reports whether the request is allowed permission, for
+// handlers whose required permission depends on the request
+func HasPermission(c *gin.Context, permission string) bool {
+	if c.GetString("AUTH_ENABLED") == "false" {
+		return true
+	}
+	return hasPermission(c, permission)
+}
 
-		// Check specific permission
-		hasPermission := false
-		for _, role := range userRoles {
-			if checkRolePermission(role, permission) {
-				hasPermission = true
-				break
+// hasPermission reports whether the user's roles, or the scopes of the API
+// key the request was authenticated with, grant permission
+func hasPermission(c *gin.Context, permission string) bool {
+	if key := GetAPIKey(c); key != nil {
+		return apiKeyHasPermission(key, permission)
+	}
+
+	rolesInterface, _ := c.Get("user_roles")
+
+	// Convert roles to string slice
+	var userRoles []string
+	switch v := rolesInterface.(type) {
+	case []string:
+		userRoles = v
+	case []interface{}:
+		for _, role := range v {
+			if roleStr, ok := role.(string); ok {
+				userRoles = append(userRoles, roleStr)
 			}
 		}
+	}
 
-		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
-			c.Abort()
-			return
+	// Check if user has admin role (admin has all permissions)
+	for _, role := range userRoles {
+		if role == "admin" {
+			return true
 		}
+	}
 
-		c.Next()
+	// Check specific permission
+	for _, role := range userRoles {
+		if checkRolePermission(role, permission) {
+			return true
+		}
 	}
+	return false
 }
 
 // checkRolePermission checks if a role has a specific permission
@@ -127,16 +210,54 @@ func checkRolePermission(role, permission string) bool {
 			"routers:read", "routers:write",
 			"ports:read", "ports:write",
 			"acls:read", "acls:write",
+			"load_balancers:read", "load_balancers:write",
+			"dns:read", "dns:write",
+			"mirrors:read", "mirrors:write",
+			"sampling:read", "sampling:write",
+			"network_policies:read", "network_policies:write",
+			"apply:write",
+			"export:read",
 			"backups:read", "backups:write",
+			"changesets:read", "changesets:write", "changesets:execute",
 			"topology:read",
+			"history:read",
+			"trash:read", "trash:write",
+			"reports:read",
+			"compliance:read", "compliance:evaluate",
+			"validate:read",
+			"gateways:read", "gateways:write",
+			"floating_ips:read", "floating_ips:write",
+			"provider_networks:read",
+			"vpn:read", "vpn:write",
+			"chassis:read",
+			"trace:run",
+			"clusters:read",
 		},
 		"viewer": {
 			"switches:read",
 			"routers:read",
 			"ports:read",
 			"acls:read",
+			"load_balancers:read",
+			"dns:read",
+			"mirrors:read",
+			"sampling:read",
+			"network_policies:read",
+			"export:read",
 			"backups:read",
+			"changesets:read",
 			"topology:read",
+			"history:read",
+			"trash:read",
+			"reports:read",
+			"validate:read",
+			"gateways:read",
+			"floating_ips:read",
+			"provider_networks:read",
+			"vpn:read",
+			"chassis:read",
+			"trace:run",
+			"clusters:read",
 		},
 	}
 
@@ -153,17 +274,28 @@ func checkRolePermission(role, permission string) bool {
 
 // AuthConfig holds configuration for authentication middleware
 type AuthConfig struct {
-	Enabled      bool
-	JWTSecret    string
-	SkipPaths    []string
-	PublicPaths  []string
+	Enabled     bool
+	JWTSecret   string
+	// JWTSecrets, when set, returns the secrets JWTs may be signed with
+	// instead of JWTSecret, e.g. the current and the previous one while a
+	// rotated secret is phased out
+	JWTSecrets func() []string
+	SkipPaths   []string
+	PublicPaths []string
+	// TokenValidator is consulted for bearer tokens that are not valid JWTs
+	// signed with JWTSecret, e.g. session tokens or OIDC ID tokens
+	TokenValidator TokenValidator
+	// APIKeyValidator authenticates requests made with tenant API keys
+	APIKeyValidator APIKeyValidator
 }
 
 // Auth creates an authentication middleware with the given config
 func Auth(cfg AuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip auth if disabled
+		// Skip auth if disabled, and tell RequirePermission to skip
+		// permission checks, as there are no users to check
 		if !cfg.Enabled {
+			c.Set("AUTH_ENABLED", "false")
 			c.Next()
 			return
 		}
@@ -185,30 +317,125 @@ func Auth(cfg AuthConfig) gin.HandlerFunc {
 			}
 		}
 
-		// Use RequireAuth for other paths
-		RequireAuth()(c)
+		// Authenticate all other paths
+		requireAuth(cfg)(c)
 	}
 }
 
-// TenantContext middleware adds tenant context to requests
-func TenantContext() gin.HandlerFunc {
+// TenantResolver looks up the tenants of users. *services.TenantService
+// implements it.
+type TenantResolver interface {
+	ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error)
+	GetMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error)
+}
+
+// TenantOverridePermission lets a user act in any tenant without being a
+// member, and see all tenants' resources when no tenant is selected
+const TenantOverridePermission = "tenants:override"
+
+// TenantContext middleware scopes requests to a tenant: the one selected
+// with the X-Tenant-ID header, which the user must be a member of, or else
+// the user's only tenant. Users with the tenant override permission may
+// select any tenant and are not scoped by default. Requests made with an API
+// key are always scoped to the key's tenant. The tenant is also put
+// in the request context, where the tenant-aware OVN service uses it to
+// isolate, quota and associate resources.
+func TenantContext(tenants TenantResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract tenant from header or user context
-		tenantID := c.GetHeader("X-Tenant-ID")
-		
-		// If not in header, try to get from user's default tenant
-		if tenantID == "" {
-			if userID, exists := c.Get("user_id"); exists {
-				// TODO: Look up user's default tenant
-				_ = userID
+		tenantID := c.GetHeader(TenantHeaderKey)
+		userID := c.GetString("user_id")
+		if key := GetAPIKey(c); key != nil && tenantID == "" {
+			tenantID = key.TenantID
+		}
+
+		// If not in header, use the user's default tenant
+		if tenantID == "" && userID != "" && !hasPermission(c, TenantOverridePermission) {
+			memberOf, err := tenants.ListTenants(c.Request.Context(), &models.TenantFilter{UserID: userID})
+			if err != nil {
+				// Unscoped requests see every tenant's resources, so fail closed
+				problem.Abort(c, problem.New(http.StatusServiceUnavailable, "failed to resolve tenant").
+					WithDetail("the user's tenants could not be looked up; retry, or select one with the X-Tenant-ID header"))
+				return
 			}
+			if len(memberOf) == 1 {
+				tenantID = memberOf[0].ID
+			} else if len(memberOf) > 1 {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "X-Tenant-ID header required: user belongs to several tenants"))
+				c.Abort()
+				return
+			}
+		}
+
+		if tenantID != "" && !enterTenant(c, tenants, tenantID) {
+			return
 		}
 
-		// Set tenant in context if found
-		if tenantID != "" {
-			c.Set("tenant_id", tenantID)
+		c.Next()
+	}
+}
+
+// RequireTenantScope middleware, after TenantContext, refuses requests that
+// aren't scoped to a tenant, unless the caller may see every tenant's
+// resources. Without authentication there are no tenants to scope to, and
+// requests pass.
+func RequireTenantScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, scoped := c.Get(TenantContextKey)
+		authenticated := c.GetString("user_id") != "" || GetAPIKey(c) != nil
+		if scoped || !authenticated || hasPermission(c, TenantOverridePermission) {
+			c.Next()
+			return
 		}
 
+		problem.Abort(c, problem.New(http.StatusForbidden, "tenant required").
+			WithDetail("the caller belongs to no tenant; select one with the X-Tenant-ID header"))
+	}
+}
+
+// TenantParam middleware resolves the user's role in the tenant named by the
+// :id path parameter, for tenant management routes
+func TenantParam(tenants TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenantID := c.Param("id"); tenantID != "" && !enterTenant(c, tenants, tenantID) {
+			return
+		}
 		c.Next()
 	}
 }
+
+// enterTenant checks the user has access to the tenant and sets it, and the
+// user's role in it, in the context. API keys only have access to their own
+// tenant, as its admin with the admin scope and as a member otherwise.
+// Without authentication the tenant is trusted. It aborts the request and
+// returns false when access is denied.
+func enterTenant(c *gin.Context, tenants TenantResolver, tenantID string) bool {
+	if key := GetAPIKey(c); key != nil {
+		if key.TenantID != tenantID {
+			problem.Respond(c, problem.New(http.StatusForbidden, "Access denied to tenant"))
+			c.Abort()
+			return false
+		}
+		role := "member"
+		if key.HasScope(models.APIKeyScopeAdmin) {
+			role = "admin"
+		}
+		c.Set("tenant_role", role)
+	} else if userID := c.GetString("user_id"); userID != "" {
+		membership, err := tenants.GetMembership(c.Request.Context(), tenantID, userID)
+		switch {
+		case err == nil && membership != nil:
+			c.Set("tenant_role", membership.Role)
+		case hasPermission(c, TenantOverridePermission):
+			c.Set("tenant_role", "admin")
+		default:
+			problem.Respond(c, problem.New(http.StatusForbidden, "Access denied to tenant"))
+			c.Abort()
+			return false
+		}
+	}
+
+	c.Set(TenantContextKey, tenantID)
+	c.Request = c.Request.WithContext(services.ContextWithTenant(c.Request.Context(), tenantID))
+	return true
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestAuth_JWTSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sign := func(secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "u1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{name: "signed with the current secret", secret: "current", want: http.StatusOK},
+		{name: "signed with the previous secret", secret: "previous", want: http.StatusOK},
+		{name: "signed with another secret", secret: "other", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(Auth(AuthConfig{
+				Enabled:    true,
+				JWTSecret:  "current",
+				JWTSecrets: func() []string { return []string{"current", "previous"} },
+			}))
+			engine.GET("/switches", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString("user_id"))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/switches", nil)
+			req.Header.Set("Authorization", "Bearer "+sign(tt.secret))
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, "u1", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireTenantScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		userID   string
+		roles    []string
+		tenantID string
+		want     int
+	}{
+		{name: "scoped to a tenant", userID: "u1", roles: []string{"operator"}, tenantID: "t1", want: http.StatusOK},
+		{name: "in no tenant", userID: "u1", roles: []string{"operator"}, want: http.StatusForbidden},
+		{name: "sees every tenant", userID: "u1", roles: []string{"admin"}, want: http.StatusOK},
+		{name: "without authentication", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				if tt.userID != "" {
+					c.Set("user_id", tt.userID)
+					c.Set("user_roles", tt.roles)
+				}
+				if tt.tenantID != "" {
+					c.Set(TenantContextKey, tt.tenantID)
+				}
+			}, RequireTenantScope())
+			engine.GET("/switches", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/switches", nil))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+// fakeTenantResolver returns fixed tenants and memberships
+type fakeTenantResolver struct {
+	tenants []*models.Tenant
+	err     error
+}
+
+func (f *fakeTenantResolver) ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error) {
+	return f.tenants, f.err
+}
+
+func (f *fakeTenantResolver) GetMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error) {
+	for _, tenant := range f.tenants {
+		if tenant.ID == tenantID {
+			return &models.TenantMembership{TenantID: tenantID, UserID: userID, Role: "member"}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestTenantContext_DefaultTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		resolver   *fakeTenantResolver
+		wantStatus int
+		wantTenant string
+	}{
+		{
+			name:       "member of one tenant",
+			resolver:   &fakeTenantResolver{tenants: []*models.Tenant{{ID: "acme"}}},
+			wantStatus: http.StatusOK,
+			wantTenant: "acme",
+		},
+		{
+			name:       "member of several tenants",
+			resolver:   &fakeTenantResolver{tenants: []*models.Tenant{{ID: "acme"}, {ID: "globex"}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "member of no tenant",
+			resolver:   &fakeTenantResolver{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			// Going on unscoped would show every tenant's resources
+			name:       "tenants not looked up",
+			resolver:   &fakeTenantResolver{err: errors.New("database is down")},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				c.Set("user_id", "u1")
+				c.Set("user_roles", []string{"operator"})
+			})
+			engine.Use(TenantContext(tt.resolver))
+			engine.GET("/switches", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetString(TenantContextKey))
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/switches", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantTenant, w.Body.String())
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"go.uber.org/zap"
 )
 
@@ -30,10 +31,7 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 			}
 
 			// Send error response
-			c.JSON(status, gin.H{
-				"error": err.Error(),
-				"request_id": c.GetString("request_id"),
-			})
+			problem.Respond(c, problem.New(status, err.Error()))
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// eventResources are the routes whose successful writes publish resource
+// events, by their first path segment
+var eventResources = map[string]bool{
+	"switches":         true,
+	"routers":          true,
+	"ports":            true,
+	"acls":             true,
+	"load-balancers":   true,
+	"dns":              true,
+	"mirrors":          true,
+	"sampling":         true,
+	"network-policies": true,
+	"gateways":         true,
+	"apply":            true,
+	"transactions":     true,
+}
+
+// eventReadOnlyRoutes are POST routes of event resources that change nothing
+var eventReadOnlyRoutes = []string{"/acls/simulate"}
+
+// eventResponseWriter keeps the body of create responses, which hold the
+// ID of the created resource
+type eventResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w eventResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// ResourceEvents publishes a resource.created, resource.updated or
+// resource.deleted event for each successful write to the logical network,
+// on behalf of the caller's tenant. Handlers set "dry_run" in the context
+// for writes that change nothing.
+func ResourceEvents(events services.EventPublisher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var eventType string
+		switch c.Request.Method {
+		case http.MethodPost:
+			eventType = models.EventResourceCreated
+		case http.MethodPut, http.MethodPatch:
+			eventType = models.EventResourceUpdated
+		case http.MethodDelete:
+			eventType = models.EventResourceDeleted
+		default:
+			c.Next()
+			return
+		}
+
+		route := strings.TrimPrefix(c.FullPath(), "/api/v1")
+		cluster := ""
+		if strings.HasPrefix(route, "/clusters/:name/") {
+			route = strings.TrimPrefix(route, "/clusters/:name")
+			cluster = c.Param("name")
+		}
+		segments := strings.Split(strings.TrimPrefix(route, "/"), "/")
+		if first, _, _ := strings.Cut(segments[0], ":"); !eventResources[first] {
+			c.Next()
+			return
+		}
+		for _, suffix := range eventReadOnlyRoutes {
+			if strings.HasSuffix(route, suffix) {
+				c.Next()
+				return
+			}
+		}
+
+		writer := &eventResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		if eventType == models.EventResourceCreated {
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		// Writes held for approval (202) and dry runs change nothing
+		status := c.Writer.Status()
+		if status < 200 || status > 299 || status == http.StatusAccepted || c.GetBool("dry_run") {
+			return
+		}
+		// The resource is named by the last literal segment of the route,
+		// and identified by the parameter following it
+		var resource, resourceID string
+		for _, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				resourceID = c.Param(segment[1:])
+			} else {
+				resource, _, _ = strings.Cut(segment, ":")
+				resourceID = ""
+			}
+		}
+		if eventType == models.EventResourceCreated {
+			var created struct {
+				UUID string `json:"uuid"`
+				ID   string `json:"id"`
+			}
+			if json.Unmarshal(writer.body.Bytes(), &created) == nil {
+				if created.UUID != "" {
+					resourceID = created.UUID
+				} else if created.ID != "" {
+					resourceID = created.ID
+				}
+			}
+		}
+
+		data := map[string]interface{}{
+			"resource": resource,
+			"method":   c.Request.Method,
+			"path":     c.Request.URL.Path,
+			"source":   RequestSource(c),
+		}
+		if resourceID != "" {
+			data["resource_id"] = resourceID
+		}
+		if cluster != "" {
+			data["cluster"] = cluster
+		}
+		if userID := c.GetString("user_id"); userID != "" {
+			data["user_id"] = userID
+		}
+		events.Publish(c.Request.Context(), &models.Event{
+			Type:     eventType,
+			TenantID: GetTenantID(c),
+			Data:     data,
+		})
+	}
+}
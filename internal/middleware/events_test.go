@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+type recordingPublisher struct {
+	events []*models.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *models.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestResourceEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	events := &recordingPublisher{}
+	engine := gin.New()
+	v1 := engine.Group("/api/v1", func(c *gin.Context) {
+		c.Set(TenantContextKey, "acme")
+		c.Set("user_id", "alice")
+	}, ResourceEvents(events))
+	for _, group := range []*gin.RouterGroup{v1, v1.Group("/clusters/:name")} {
+		group.POST("/switches", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{"uuid": "sw-1", "name": "web"})
+		})
+		group.PUT("/switches/:id", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+		group.DELETE("/routers/:id/policies/:policyId", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		group.POST("/switches/:id/acls/simulate", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+		group.POST("/transactions", func(c *gin.Context) {
+			c.Set("dry_run", c.Query("dry_run") == "true")
+			c.JSON(http.StatusOK, gin.H{})
+		})
+		group.POST("/compliance/evaluate", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+		group.DELETE("/acls/:id", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{}) })
+		group.PUT("/ports/:id", func(c *gin.Context) { c.JSON(http.StatusAccepted, gin.H{}) })
+	}
+
+	serve := func(method, path string) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader("{}")))
+	}
+	serve("POST", "/api/v1/switches")
+	serve("PUT", "/api/v1/switches/sw-1")
+	serve("DELETE", "/api/v1/clusters/east/routers/lr-1/policies/pol-1")
+	// Nothing changes for reads, simulations, dry runs, routes of other
+	// resources, failures and writes held for approval
+	serve("GET", "/api/v1/switches")
+	serve("POST", "/api/v1/switches/sw-1/acls/simulate")
+	serve("POST", "/api/v1/transactions?dry_run=true")
+	serve("POST", "/api/v1/compliance/evaluate")
+	serve("DELETE", "/api/v1/acls/acl-1")
+	serve("PUT", "/api/v1/ports/p-1")
+	serve("POST", "/api/v1/transactions")
+
+	require.Len(t, events.events, 4)
+	assert.Equal(t, &models.Event{
+		Type:     models.EventResourceCreated,
+		TenantID: "acme",
+		Data: map[string]interface{}{
+			"resource": "switches", "resource_id": "sw-1", "method": "POST",
+			"path": "/api/v1/switches", "user_id": "alice", "source": "api",
+		},
+	}, events.events[0])
+	assert.Equal(t, models.EventResourceUpdated, events.events[1].Type)
+	assert.Equal(t, "sw-1", events.events[1].Data["resource_id"])
+	assert.Equal(t, models.EventResourceDeleted, events.events[2].Type)
+	assert.Equal(t, "policies", events.events[2].Data["resource"])
+	assert.Equal(t, "pol-1", events.events[2].Data["resource_id"])
+	assert.Equal(t, "east", events.events[2].Data["cluster"])
+	assert.Equal(t, "transactions", events.events[3].Data["resource"])
+	assert.NotContains(t, events.events[3].Data, "resource_id")
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/cache"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-supplied key making a POST
+	// safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retry
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	idempotencyCachePrefix  = "idempotency:"
+	// maxIdempotentBodySize limits the bodies read to fingerprint a
+	// request; it is the largest any handler accepts, that of imports
+	maxIdempotentBodySize = 50 << 20
+)
+
+// idempotentResponse is the response stored for an idempotency key, with
+// the fingerprint of the request it answered
+type idempotentResponse struct {
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// idempotencyResponseWriter keeps the body of the response to store
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe
+// to retry. The first successful response to a key is stored in store for
+// ttl, and returned again, with Idempotent-Replayed: true, to retries
+// rather than repeating the request. Keys are scoped to the caller and the
+// tenant they select; reusing one for a different request answers 422, and
+// retrying while the first request is still running answers 409. Failed
+// requests created nothing, so aren't stored and may be retried as is.
+func Idempotency(store cache.Cache, ttl time.Duration, logger *zap.Logger) gin.HandlerFunc {
+	var mu sync.Mutex
+	inFlight := make(map[string]bool)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "invalid idempotency key").
+				WithDetail("Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			problem.Abort(c, problem.New(http.StatusRequestEntityTooLarge, "request body too large").
+				WithDetail(fmt.Sprintf("the body must be at most %d bytes", maxIdempotentBodySize)))
+			return
+		case err != nil:
+			problem.Abort(c, problem.New(http.StatusBadRequest, "failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+		cacheKey := idempotencyCacheKey(c.GetString("user_id"), c.GetHeader(TenantHeaderKey), key)
+
+		mu.Lock()
+		if inFlight[cacheKey] {
+			mu.Unlock()
+			problem.Abort(c, problem.New(http.StatusConflict, "request in progress").
+				WithDetail("a request with this idempotency key is still being processed"))
+			return
+		}
+		inFlight[cacheKey] = true
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(inFlight, cacheKey)
+			mu.Unlock()
+		}()
+
+		var stored idempotentResponse
+		err = store.Get(c.Request.Context(), cacheKey, &stored)
+		switch {
+		case err == nil:
+			if stored.Fingerprint != fingerprint {
+				problem.Abort(c, problem.New(http.StatusUnprocessableEntity, "idempotency key reused").
+					WithDetail("the idempotency key was used for a different request"))
+				return
+			}
+			if stored.ContentType != "" {
+				c.Header("Content-Type", stored.ContentType)
+			}
+			if stored.Location != "" {
+				c.Header("Location", stored.Location)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Status(stored.Status)
+			_, _ = c.Writer.Write(stored.Body)
+			c.Abort()
+			return
+		case !errors.Is(err, cache.ErrCacheMiss):
+			// Without the store, requests are served as if they carried
+			// no key
+			logger.Warn("Failed to read idempotency key", zap.Error(err))
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < 200 || status > 299 {
+			return
+		}
+		response := &idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Location:    c.Writer.Header().Get("Location"),
+			Body:        writer.body.Bytes(),
+			CreatedAt:   time.Now().UTC(),
+		}
+		if err := store.Set(c.Request.Context(), cacheKey, response, ttl); err != nil {
+			logger.Warn("Failed to store idempotency key", zap.Error(err))
+		}
+	}
+}
+
+// idempotencyCacheKey scopes an idempotency key to the caller and tenant
+func idempotencyCacheKey(userID, tenantID, key string) string {
+	sum := sha256.Sum256([]byte(userID + "\x00" + tenantID + "\x00" + key))
+	return idempotencyCachePrefix + hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint identifies a request by its method, URI and body
+func requestFingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/cache"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := 0
+	started, release := make(chan struct{}), make(chan struct{})
+	engine := gin.New()
+	v1 := engine.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+	}, Idempotency(cache.NewMemoryCache(zap.NewNop()), time.Hour, zap.NewNop()))
+	v1.POST("/switches", func(c *gin.Context) {
+		created++
+		c.Header("Location", "/api/v1/switches/sw-1")
+		c.JSON(http.StatusCreated, gin.H{"uuid": "sw-1", "created": created})
+	})
+	v1.POST("/routers", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid router"})
+	})
+	v1.POST("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	serve := func(path, key, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/api/v1/switches", "key-1", "alice", `{"name":"web"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	first := w.Body.String()
+
+	// Retries get the original response
+	w = serve("/api/v1/switches", "key-1", "alice", `{"name":"web"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, first, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, "/api/v1/switches/sw-1", w.Header().Get("Location"))
+	assert.Equal(t, 1, created)
+
+	// Keys are scoped to the caller, and requests without one always run
+	serve("/api/v1/switches", "key-1", "bob", `{"name":"web"}`)
+	serve("/api/v1/switches", "", "alice", `{"name":"web"}`)
+	assert.Equal(t, 3, created)
+
+	// Reusing a key for another request is refused
+	w = serve("/api/v1/switches", "key-1", "alice", `{"name":"db"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 3, created)
+
+	w = serve("/api/v1/switches", strings.Repeat("k", 256), "alice", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Bodies are read to fingerprint them, so their size is limited
+	req := httptest.NewRequest("POST", "/api/v1/switches", strings.NewReader(strings.Repeat(" ", maxIdempotentBodySize+1)))
+	req.Header.Set(IdempotencyKeyHeader, "key-4")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 3, created)
+
+	// Failures aren't stored
+	serve("/api/v1/routers", "key-2", "alice", `{}`)
+	w = serve("/api/v1/routers", "key-2", "alice", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+
+	// Retries while the first request runs are refused
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve("/api/v1/slow", "key-3", "alice", `{}`) }()
+	<-started
+	assert.Equal(t, http.StatusConflict, serve("/api/v1/slow", "key-3", "alice", `{}`).Code)
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"go.uber.org/zap"
+)
+
+// MaintenanceChecker tells whether the writes of a tenant are frozen.
+// *services.MaintenanceService implements it.
+type MaintenanceChecker interface {
+	Frozen(ctx context.Context, tenantID string) (*models.MaintenanceMode, error)
+}
+
+// readOnlyPosts are the suffixes of the routes answering POSTs without
+// changing the configuration: traces, simulations, validations, plans and
+// checks, and taking or verifying backups
+var readOnlyPosts = []string{
+	"/trace/flow",
+	"/trace/multi-path",
+	"/trace/connectivity",
+	"/trace/simulate",
+	"/trace/probe",
+	"/acls/simulate",
+	"/compliance/evaluate",
+	"/topology/custom",
+	"/validate",
+	"/changesets/:id/plan",
+	"/drift/check",
+	"/backups",
+	"/backups/:id/verify",
+}
+
+// WriteFreeze refuses writes with 409 Conflict while the caller's tenant,
+// after TenantContext, or the cluster is in maintenance mode. The problem
+// carries the maintenance mode, with its reason and ETA, and a Retry-After
+// header counts down to the ETA. Reads, dry runs and POSTs that don't
+// change the configuration are served as usual.
+func WriteFreeze(checker MaintenanceChecker, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isWrite(c) {
+			c.Next()
+			return
+		}
+
+		mode, err := checker.Frozen(c.Request.Context(), c.GetString(TenantContextKey))
+		if err != nil {
+			// The maintenance mode is a safeguard, so an unreadable one
+			// doesn't block the API
+			logger.Error("Failed to check maintenance mode", zap.Error(err))
+			c.Next()
+			return
+		}
+		if mode == nil {
+			c.Next()
+			return
+		}
+
+		detail := "writes are frozen for maintenance"
+		if mode.Reason != "" {
+			detail += ": " + mode.Reason
+		}
+		if mode.ETA != nil {
+			detail += "; expected to end at " + mode.ETA.UTC().Format(time.RFC3339)
+			if wait := time.Until(*mode.ETA); wait > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			}
+		}
+		problem.Abort(c, problem.New(http.StatusConflict, "Maintenance in progress").
+			WithCode(problem.CodeMaintenance).
+			WithDetail(detail).
+			With("maintenance", mode))
+	}
+}
+
+// isWrite tells whether a request may change the configuration
+func isWrite(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	if c.Query("dry_run") == "true" {
+		return false
+	}
+	if c.Request.Method == http.MethodPost {
+		route := c.FullPath()
+		for _, suffix := range readOnlyPosts {
+			if strings.HasSuffix(route, suffix) {
+				return false
+			}
+		}
+	}
+	return true
+}
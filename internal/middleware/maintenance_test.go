@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// fakeMaintenance freezes the tenants it has a mode for
+type fakeMaintenance map[string]*models.MaintenanceMode
+
+func (f fakeMaintenance) Frozen(ctx context.Context, tenantID string) (*models.MaintenanceMode, error) {
+	return f[tenantID], nil
+}
+
+func TestWriteFreeze(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	eta := time.Now().Add(30 * time.Minute)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		if tenantID := c.GetHeader(TenantHeaderKey); tenantID != "" {
+			c.Set(TenantContextKey, tenantID)
+		}
+	})
+	engine.Use(WriteFreeze(fakeMaintenance{
+		"acme": {TenantID: "acme", Frozen: true, Reason: "OVN upgrade", ETA: &eta},
+	}, zap.NewNop()))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/switches", ok)
+	engine.POST("/switches", ok)
+	engine.DELETE("/switches/:id", ok)
+	engine.POST("/apply", ok)
+	engine.POST("/switches/:id/acls/simulate", ok)
+	engine.POST("/changesets/:id/plan", ok)
+
+	serve := func(method, path, tenantID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(TenantHeaderKey, tenantID)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/switches", "acme")
+	require.Equal(t, http.StatusConflict, w.Code)
+	retryAfter := w.Header().Get("Retry-After")
+	assert.Contains(t, []string{"1800", "1799"}, retryAfter)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "maintenance", body["code"])
+	assert.Contains(t, body["detail"], "OVN upgrade")
+	maintenance := body["maintenance"].(map[string]interface{})
+	assert.Equal(t, "OVN upgrade", maintenance["reason"])
+	assert.NotEmpty(t, maintenance["eta"])
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, "/switches/sw1", "acme").Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/apply", "acme").Code)
+
+	// Reads, dry runs and POSTs that don't change anything are served
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/switches", "acme").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/apply?dry_run=true", "acme").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/switches/sw1/acls/simulate", "acme").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/changesets/cs1/plan", "acme").Code)
+
+	// Other tenants aren't frozen
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/switches", "other").Code)
+}
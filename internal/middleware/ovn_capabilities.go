@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// OVNCapabilities returns the capability matrix of the northbound schema of
+// the OVN cluster selected in the context. *services.OVNClusterManager
+// implements it.
+type OVNCapabilities interface {
+	Capabilities(ctx context.Context) (*ovn.Capabilities, error)
+}
+
+// RequireOVNCapability hides the routes of a feature the northbound schema
+// of the selected cluster doesn't support, answering 404 with the
+// capability_unavailable code rather than failing on the missing tables.
+// Until the schema is known, requests go through.
+func RequireOVNCapability(capabilities OVNCapabilities, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caps, err := capabilities.Capabilities(c.Request.Context())
+		if err != nil || caps.Available(name) {
+			c.Next()
+			return
+		}
+
+		p := problem.New(http.StatusNotFound, fmt.Sprintf("%s is not supported by this OVN cluster", name)).
+			WithCode(problem.CodeCapabilityUnavailable).
+			With("capability", name)
+		for _, feature := range caps.Features {
+			if feature.Name == name {
+				p = p.WithDetail(fmt.Sprintf("OVN northbound schema %s lacks %s",
+					caps.SchemaVersion, strings.Join(feature.Missing, ", "))).
+					With("missing", feature.Missing)
+			}
+		}
+		problem.Respond(c, p)
+		c.Abort()
+	}
+}
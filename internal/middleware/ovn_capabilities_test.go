@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/pkg/ovn"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+type fixedCapabilities struct {
+	capabilities *ovn.Capabilities
+	err          error
+}
+
+func (f fixedCapabilities) Capabilities(ctx context.Context) (*ovn.Capabilities, error) {
+	return f.capabilities, f.err
+}
+
+func TestRequireOVNCapability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// An older schema, without the DNS table
+	schema := nbdb.Schema()
+	schema.Version = "5.32.1"
+	delete(schema.Tables, "DNS")
+	_, capabilities, err := ovn.DetectCapabilities(schema)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DNS"}, capabilities.UnsupportedTables)
+	assert.False(t, capabilities.Available(ovn.CapabilityDNS))
+	assert.True(t, capabilities.Available(ovn.CapabilityMirrors))
+
+	serve := func(resolver OVNCapabilities, name string) *httptest.ResponseRecorder {
+		engine := gin.New()
+		engine.GET("/feature", RequireOVNCapability(resolver, name), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feature", nil))
+		return w
+	}
+
+	w := serve(fixedCapabilities{capabilities: capabilities}, ovn.CapabilityDNS)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, problem.CodeCapabilityUnavailable, body["code"])
+	assert.Equal(t, "dns", body["capability"])
+	assert.Equal(t, []interface{}{"DNS"}, body["missing"])
+	assert.Equal(t, "OVN northbound schema 5.32.1 lacks DNS", body["detail"])
+
+	assert.Equal(t, http.StatusOK, serve(fixedCapabilities{capabilities: capabilities}, ovn.CapabilityMirrors).Code)
+	// Until the schema is known, requests go through
+	assert.Equal(t, http.StatusOK, serve(fixedCapabilities{err: errors.New("client not connected")}, ovn.CapabilityDNS).Code)
+}
+
+func TestDetectCapabilities_CoreTables(t *testing.T) {
+	schema := nbdb.Schema()
+	delete(schema.Tables["ACL"].Columns, "sample_new")
+
+	_, _, err := ovn.DetectCapabilities(schema)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ACL.sample_new")
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// DataAsOfHeader carries the time of the snapshot a stale response was
+// served from
+const DataAsOfHeader = "X-OVNCP-Data-As-Of"
+
+// OVNReadOnlyFallback keeps OVN routes usable while the selected cluster is
+// unreachable. Reads answered from the last snapshot are marked with a
+// Warning and the DataAsOfHeader of the oldest snapshot used, and writes
+// are refused with 503 until the connection is restored.
+func OVNReadOnlyFallback(clusters *services.OVNClusterManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			var mu sync.Mutex
+			var oldest time.Time
+			ctx := services.ContextWithStaleReads(c.Request.Context(), func(takenAt time.Time) {
+				mu.Lock()
+				defer mu.Unlock()
+				if !oldest.IsZero() && !takenAt.Before(oldest) {
+					return
+				}
+				oldest = takenAt
+				c.Header("Warning", `110 - "Response is Stale"`)
+				c.Header(DataAsOfHeader, takenAt.UTC().Format(time.RFC3339))
+			})
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		// Unknown clusters are left to the handlers to report
+		cluster, err := clusters.Resolve(c.Request.Context())
+		if err != nil || cluster.Client.IsConnected() {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", "5")
+		problem.Respond(c, problem.New(http.StatusServiceUnavailable, "OVN service unavailable: the API is read-only until the connection is restored").WithCode(problem.CodeOVNUnavailable))
+		c.Abort()
+	}
+}
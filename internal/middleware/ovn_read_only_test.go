@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestOVNReadOnlyFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clusters := services.NewOVNClusterManager(zap.NewNop())
+	t.Cleanup(clusters.Close)
+	require.NoError(t, clusters.Add(&config.OVNConfig{
+		ClusterName:  "default",
+		NorthboundDB: "tcp:127.0.0.1:1",
+		Timeout:      100 * time.Millisecond,
+	}))
+
+	takenAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	engine := gin.New()
+	engine.Use(OVNReadOnlyFallback(clusters))
+	engine.GET("/switches", func(c *gin.Context) {
+		// A read served from snapshots of different ages
+		ctx := c.Request.Context()
+		onStale := ctx.Value("ovn_stale_reads").(func(time.Time))
+		onStale(takenAt.Add(time.Minute))
+		onStale(takenAt)
+		c.Status(http.StatusOK)
+	})
+	engine.POST("/switches", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/switches", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `110 - "Response is Stale"`, w.Header().Get("Warning"))
+	assert.Equal(t, "2024-03-01T10:00:00Z", w.Header().Get(DataAsOfHeader))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/switches", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// SourceHeader names the client a request comes from: api, cli or
+// terraform
+const SourceHeader = "X-OVNCP-Source"
+
+// RequestSource returns the client a request comes from: the source named
+// by SourceHeader, terraform for Terraform's user agent, otherwise api
+func RequestSource(c *gin.Context) string {
+	source := strings.ToLower(strings.TrimSpace(c.GetHeader(SourceHeader)))
+	for _, valid := range models.Sources {
+		if source == valid {
+			return source
+		}
+	}
+	if strings.Contains(strings.ToLower(c.Request.UserAgent()), "terraform") {
+		return models.SourceTerraform
+	}
+	return models.SourceAPI
+}
+
+// ResourceOwnership attributes the resources a request creates or changes
+// to the caller and the client it uses; they record it in their
+// external_ids
+func ResourceOwnership() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := ovn.Actor{
+			UserID: c.GetString("user_id"),
+			Source: RequestSource(c),
+		}
+		c.Request = c.Request.WithContext(ovn.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
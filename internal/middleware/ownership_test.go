@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestSource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		header    string
+		userAgent string
+		want      string
+	}{
+		{"default", "", "curl/8.0", "api"},
+		{"header", "CLI", "", "cli"},
+		{"terraform user agent", "", "Terraform/1.7.0 terraform-provider-ovncp/0.3.0", "terraform"},
+		{"header wins over user agent", "api", "Terraform/1.7.0", "api"},
+		{"unknown header", "ansible", "", "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/switches", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(SourceHeader, tt.header)
+			}
+			c.Request.Header.Set("User-Agent", tt.userAgent)
+			assert.Equal(t, tt.want, RequestSource(c))
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/metrics"
 	"golang.org/x/time/rate"
 )
@@ -61,14 +62,31 @@ func (rl *IPRateLimiter) Allow(key string) bool {
 
 // Limit returns the rate limit
 func (rl *IPRateLimiter) Limit() rate.Limit {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
 	return rl.limit
 }
 
 // Burst returns the burst size
 func (rl *IPRateLimiter) Burst() int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
 	return rl.burst
 }
 
+// SetLimit changes the rate limit and burst size, of the addresses already
+// seen too
+func (rl *IPRateLimiter) SetLimit(rps float64, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	
+	rl.limit, rl.burst = rate.Limit(rps), burst
+	for _, limiter := range rl.ips {
+		limiter.SetLimit(rl.limit)
+		limiter.SetBurst(burst)
+	}
+}
+
 // gcLoop periodically cleans up old entries
 func (rl *IPRateLimiter) gcLoop() {
 	ticker := time.NewTicker(rl.ttl)
@@ -148,14 +166,29 @@ func (rl *UserRateLimiter) SetUserLimit(userID string, role string, customLimit
 
 // Limit returns the default rate limit
 func (rl *UserRateLimiter) Limit() rate.Limit {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
 	return rl.limits["default"]
 }
 
 // Burst returns the burst size
 func (rl *UserRateLimiter) Burst() int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
 	return rl.burst
 }
 
+// SetLimit changes the default rate limit and the burst size. Users'
+// limiters are recreated with them on their next request.
+func (rl *UserRateLimiter) SetLimit(defaultLimit float64, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	
+	rl.limits["default"] = rate.Limit(defaultLimit)
+	rl.burst = burst
+	rl.users = make(map[string]*rate.Limiter)
+}
+
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
 	Enabled       bool
@@ -167,10 +200,34 @@ type RateLimitConfig struct {
 	CustomHeader  string // Custom header for rate limit key (e.g., "X-API-Key")
 }
 
+// RateLimits are the limiters of a RateLimit middleware
+type RateLimits struct {
+	ip   *IPRateLimiter
+	user *UserRateLimiter
+}
+
+// Set changes the rate limit and burst size while the middleware serves
+func (l *RateLimits) Set(rps float64, burst int) {
+	if l.ip != nil {
+		l.ip.SetLimit(rps, burst)
+	}
+	if l.user != nil {
+		l.user.SetLimit(rps, burst)
+	}
+}
+
 // RateLimit middleware factory
 func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	handler, _ := NewRateLimit(cfg)
+	return handler
+}
+
+// NewRateLimit returns the RateLimit middleware and its limiters, whose
+// limits can be changed while it serves
+func NewRateLimit(cfg RateLimitConfig) (gin.HandlerFunc, *RateLimits) {
+	limits := &RateLimits{}
 	if !cfg.Enabled {
-		return func(c *gin.Context) { c.Next() }
+		return func(c *gin.Context) { c.Next() }, limits
 	}
 	
 	// Create rate limiters
@@ -184,6 +241,7 @@ func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
 	if cfg.ByUser {
 		userLimiter = NewUserRateLimiter(cfg.RequestsPerSecond, cfg.Burst)
 	}
+	limits.ip, limits.user = ipLimiter, userLimiter
 	
 	return func(c *gin.Context) {
 		var allowed bool
@@ -243,16 +301,14 @@ func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
 			).Inc()
 			
 			c.Header("X-RateLimit-Retry-After", "1")
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"message": fmt.Sprintf("Too many requests from %s", key),
-			})
+			problem.Respond(c, problem.New(http.StatusTooManyRequests, "Rate limit exceeded").
+				WithDetail(fmt.Sprintf("Too many requests from %s", key)))
 			c.Abort()
 			return
 		}
 		
 		c.Next()
-	}
+	}, limits
 }
 
 // EndpointRateLimit provides per-endpoint rate limiting
@@ -271,10 +327,8 @@ func EndpointRateLimit(rps float64, burst int) gin.HandlerFunc {
 				"429",
 			).Inc()
 			
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
-				"message": "Endpoint rate limit exceeded",
-			})
+			problem.Respond(c, problem.New(http.StatusTooManyRequests, "Rate limit exceeded").
+				WithDetail("Endpoint rate limit exceeded"))
 			c.Abort()
 			return
 		}
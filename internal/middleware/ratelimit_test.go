@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimits_Set(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler, limits := NewRateLimit(RateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1,
+		Burst:             1,
+		TTL:               time.Minute,
+		ByIP:              true,
+	})
+	engine := gin.New()
+	engine.Use(handler)
+	engine.GET("/switches", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	get := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/switches", nil)
+		req.RemoteAddr = addr + ":40000"
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, get("192.0.2.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get("192.0.2.1").Code)
+
+	limits.Set(50, 3)
+	w := get("192.0.2.2")
+	assert.Equal(t, "50", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "3", w.Header().Get("X-RateLimit-Burst"))
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, get("192.0.2.2").Code, "the new burst applies")
+	}
+
+	// The middleware can be disabled, and then has no limits to change
+	handler, limits = NewRateLimit(RateLimitConfig{})
+	assert.NotNil(t, handler)
+	limits.Set(10, 10)
+}
@@ -6,6 +6,7 @@ import (
 	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/metrics"
 	"go.uber.org/zap"
 )
@@ -31,10 +32,8 @@ func Recovery(logger *zap.Logger) gin.HandlerFunc {
 				)
 
 				// Abort with error response
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-					"message": fmt.Sprintf("%v", err),
-				})
+				problem.Abort(c, problem.New(http.StatusInternalServerError, "Internal server error").
+					WithDetail(fmt.Sprintf("%v", err)))
 			}
 		}()
 
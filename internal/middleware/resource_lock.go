@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// Headers naming who holds the lock of a resource read or written
+const (
+	LockHolderHeader  = "X-OVNCP-Lock-Holder"
+	LockExpiresHeader = "X-OVNCP-Lock-Expires"
+)
+
+// ResourceLocker locks resources for writes. *services.ResourceLockService
+// implements it.
+type ResourceLocker interface {
+	Get(ctx context.Context, resourceType, resourceID string) (*models.ResourceLock, error)
+	LockForWrite(ctx context.Context, resourceType, resourceID, holder string) (func(), error)
+}
+
+// lockCollections are the types of the resources of the route collections
+// whose resources can be locked
+var lockCollections = map[string]string{
+	"switches":       models.ResourceSwitch,
+	"routers":        models.ResourceRouter,
+	"ports":          models.ResourcePort,
+	"acls":           models.ResourceACL,
+	"load-balancers": models.ResourceLoadBalancer,
+}
+
+// ResourceLocks keeps writes to a resource from interleaving. A write
+// locks the resource it changes until it's done, waiting for other writes
+// to the resource, and is refused with 423 Locked while someone else holds
+// a lock on it. Writes under a resource, such as creating a switch's ports
+// or ACLs, lock the resource. Reads and writes of a locked resource name
+// the holder in the X-OVNCP-Lock-Holder and X-OVNCP-Lock-Expires headers.
+func ResourceLocks(locks ResourceLocker, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceType, resourceID := lockedResource(c)
+		if resourceType == "" {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			if lock, err := locks.Get(c.Request.Context(), resourceType, resourceID); err == nil {
+				SetLockHeaders(c, lock)
+			}
+			c.Next()
+			return
+		}
+
+		release, err := locks.LockForWrite(c.Request.Context(), resourceType, resourceID, LockHolder(c))
+		if err != nil {
+			var locked *services.ResourceLockedError
+			if errors.As(err, &locked) {
+				SetLockHeaders(c, locked.Lock)
+				problem.Abort(c, problem.New(http.StatusLocked, "Resource locked").
+					WithDetail(locked.Error()).
+					With("lock", locked.Lock))
+				return
+			}
+			logger.Error("Failed to lock resource for a write",
+				zap.String("resource_type", resourceType),
+				zap.String("resource_id", resourceID),
+				zap.Error(err))
+			problem.Abort(c, problem.New(http.StatusInternalServerError, "failed to lock resource"))
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+// lockedResource returns the resource a request reads or writes, by the
+// collection and :id of its route; ACLs are created in the switch named by
+// the switch_id query parameter. Requests to collections, simulations and
+// other routes have none.
+func lockedResource(c *gin.Context) (resourceType, resourceID string) {
+	if strings.HasSuffix(c.FullPath(), "/simulate") {
+		// Simulations don't change anything
+		return "", ""
+	}
+	segments := strings.Split(c.FullPath(), "/")
+	for i, segment := range segments {
+		collection, ok := lockCollections[segment]
+		if !ok {
+			continue
+		}
+		if i+1 < len(segments) && segments[i+1] == ":id" {
+			return collection, c.Param("id")
+		}
+		if collection == models.ResourceACL && c.Request.Method == http.MethodPost && c.Query("switch_id") != "" {
+			return models.ResourceSwitch, c.Query("switch_id")
+		}
+		return "", ""
+	}
+	return "", ""
+}
+
+// LockHolder returns who locks resources for a request: its user, or
+// "anonymous" without authentication
+func LockHolder(c *gin.Context) string {
+	if holder := c.GetString("user_id"); holder != "" {
+		return holder
+	}
+	return "anonymous"
+}
+
+// SetLockHeaders names the holder of lock in the response
+func SetLockHeaders(c *gin.Context, lock *models.ResourceLock) {
+	c.Header(LockHolderHeader, lock.Holder)
+	c.Header(LockExpiresHeader, lock.ExpiresAt.UTC().Format(time.RFC3339))
+}
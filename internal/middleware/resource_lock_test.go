@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// fakeResourceLocker holds a single lock, taken by writes of its holder
+type fakeResourceLocker struct {
+	lock     *models.ResourceLock
+	writes   []string // Resources locked for writes
+	released int
+}
+
+func (l *fakeResourceLocker) Get(ctx context.Context, resourceType, resourceID string) (*models.ResourceLock, error) {
+	if l.lock == nil || l.lock.ResourceType != resourceType || l.lock.ResourceID != resourceID {
+		return nil, services.ErrResourceLockNotFound
+	}
+	return l.lock, nil
+}
+
+func (l *fakeResourceLocker) LockForWrite(ctx context.Context, resourceType, resourceID, holder string) (func(), error) {
+	if lock, _ := l.Get(ctx, resourceType, resourceID); lock != nil && lock.Holder != holder {
+		return nil, &services.ResourceLockedError{Lock: lock}
+	}
+	l.writes = append(l.writes, resourceType+"/"+resourceID)
+	return func() { l.released++ }, nil
+}
+
+func TestResourceLocks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	expires := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	locker := &fakeResourceLocker{lock: &models.ResourceLock{
+		ResourceType: models.ResourceSwitch, ResourceID: "sw-1", Holder: "alice", ExpiresAt: expires,
+	}}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+	})
+	api := router.Group("/api/v1", ResourceLocks(locker, zap.NewNop()))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/switches/:id", ok)
+	api.PUT("/switches/:id", ok)
+	api.POST("/switches", ok)
+	api.POST("/acls", ok)
+	api.POST("/switches/:id/acls/simulate", ok)
+	request := func(method, path, user string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-User", user)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Reads of a locked resource name its holder
+	w := request(http.MethodGet, "/api/v1/switches/sw-1", "bob")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", w.Header().Get(LockHolderHeader))
+	assert.Equal(t, "2024-05-01T12:00:00Z", w.Header().Get(LockExpiresHeader))
+
+	// Writes by others are refused
+	w = request(http.MethodPut, "/api/v1/switches/sw-1", "bob")
+	require.Equal(t, http.StatusLocked, w.Code)
+	assert.Equal(t, "alice", w.Header().Get(LockHolderHeader))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "resource_locked", response["code"])
+	assert.Equal(t, "alice", response["lock"].(map[string]interface{})["holder"])
+
+	// ACLs created in the switch are writes to it
+	w = request(http.MethodPost, "/api/v1/acls?switch_id=sw-1", "bob")
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	// The holder's writes go through, and release their lock
+	w = request(http.MethodPut, "/api/v1/switches/sw-1", "alice")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"switch/sw-1"}, locker.writes)
+	assert.Equal(t, 1, locker.released)
+
+	// Creating resources and simulations lock nothing
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/api/v1/switches", "bob").Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "/api/v1/switches/sw-1/acls/simulate", "bob").Code)
+	assert.Len(t, locker.writes, 1)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 )
 
 // SecurityConfig holds security middleware configuration
@@ -98,7 +99,7 @@ func DefaultSecurityConfig() SecurityConfig {
 		// CORS defaults (restrictive)
 		CORSEnabled:          true,
 		CORSAllowOrigins:     []string{}, // Must be explicitly set
-		CORSAllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		CORSAllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		CORSAllowHeaders:     []string{"Authorization", "Content-Type", "X-Request-ID"},
 		CORSExposeHeaders:    []string{"X-Request-ID"},
 		CORSAllowCredentials: true,
@@ -241,7 +242,7 @@ func CSPReportHandler() gin.HandlerFunc {
 		}
 		
 		if err := c.ShouldBindJSON(&report); err != nil {
-			c.JSON(400, gin.H{"error": "Invalid CSP report"})
+			problem.Respond(c, problem.New(400, "Invalid CSP report"))
 			return
 		}
 		
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
 	"github.com/lspecian/ovncp/internal/models"
 	"github.com/lspecian/ovncp/internal/services"
 	"go.uber.org/zap"
@@ -65,9 +66,7 @@ func TenantMiddleware(tenantService *services.TenantService, logger *zap.Logger)
 				logger.Warn("Invalid tenant ID",
 					zap.String("tenant_id", tenantID),
 					zap.Error(err))
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Invalid tenant ID",
-				})
+				problem.Respond(c, problem.New(http.StatusBadRequest, "Invalid tenant ID"))
 				c.Abort()
 				return
 			}
@@ -76,9 +75,7 @@ func TenantMiddleware(tenantService *services.TenantService, logger *zap.Logger)
 			if userID, exists := c.Get("user_id"); exists {
 				membership, err := tenantService.GetMembership(c.Request.Context(), tenantID, userID.(string))
 				if err != nil || membership == nil {
-					c.JSON(http.StatusForbidden, gin.H{
-						"error": "Access denied to tenant",
-					})
+					problem.Respond(c, problem.New(http.StatusForbidden, "Access denied to tenant"))
 					c.Abort()
 					return
 				}
@@ -90,6 +87,7 @@ func TenantMiddleware(tenantService *services.TenantService, logger *zap.Logger)
 			// Set tenant in context
 			c.Set(TenantContextKey, tenantID)
 			c.Set("tenant", tenant)
+			c.Request = c.Request.WithContext(services.ContextWithTenant(c.Request.Context(), tenantID))
 		}
 		
 		c.Next()
@@ -100,9 +98,7 @@ func TenantMiddleware(tenantService *services.TenantService, logger *zap.Logger)
 func RequireTenant() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if _, exists := c.Get(TenantContextKey); !exists {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Tenant context required",
-			})
+			problem.Respond(c, problem.New(http.StatusBadRequest, "Tenant context required"))
 			c.Abort()
 			return
 		}
@@ -115,9 +111,7 @@ func RequireTenantRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tenantRole, exists := c.Get("tenant_role")
 		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "No tenant role found",
-			})
+			problem.Respond(c, problem.New(http.StatusForbidden, "No tenant role found"))
 			c.Abort()
 			return
 		}
@@ -130,9 +124,7 @@ func RequireTenantRole(roles ...string) gin.HandlerFunc {
 			}
 		}
 		
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Insufficient tenant permissions",
-		})
+		problem.Respond(c, problem.New(http.StatusForbidden, "Insufficient tenant permissions"))
 		c.Abort()
 	}
 }
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lspecian/ovncp/internal/api/problem"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"go.uber.org/zap"
+)
+
+// ChangeBuilder turns a write request into the transaction operations that
+// make the same change
+type ChangeBuilder func(c *gin.Context) ([]models.TransactionOperation, error)
+
+// RequireChangeApproval holds writes by non-admin members of tenants that
+// require approval. Instead of running the handler, the write is recorded as
+// a pending change built by build and answered with 202 Accepted; a tenant
+// admin approves it under /tenants/:id/changes. Writes that can't be
+// expressed as a change (a nil build) are refused for those members.
+func RequireChangeApproval(changes *services.TenantChangeService, build ChangeBuilder, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := GetTenantID(c)
+		userID := c.GetString("user_id")
+		if tenantID == "" || userID == "" || GetTenantRole(c) == "admin" {
+			c.Next()
+			return
+		}
+
+		var required bool
+		var err error
+		if GetAPIKey(c) != nil {
+			// Keys without the admin scope act as members of their tenant
+			required, err = changes.TenantRequiresApproval(c.Request.Context(), tenantID)
+		} else {
+			required, err = changes.RequiresApproval(c.Request.Context(), tenantID, userID)
+		}
+		if err != nil {
+			logger.Error("Failed to check tenant approval policy",
+				zap.String("tenant_id", tenantID),
+				zap.Error(err))
+			problem.Respond(c, problem.New(http.StatusInternalServerError, "failed to check tenant approval policy"))
+			c.Abort()
+			return
+		}
+		if !required {
+			c.Next()
+			return
+		}
+
+		if build == nil {
+			problem.Respond(c, problem.New(http.StatusForbidden, "changes in this tenant require approval; submit this change as a transaction"))
+			c.Abort()
+			return
+		}
+
+		operations, err := build(c)
+		if err != nil {
+			problem.Respond(c, problem.New(http.StatusBadRequest, "invalid request body").
+				WithError(err))
+			c.Abort()
+			return
+		}
+
+		change, err := changes.RequestChange(c.Request.Context(), &models.TenantChange{
+			TenantID:    tenantID,
+			Description: c.Request.Method + " " + c.Request.URL.Path,
+			Operations:  operations,
+			RequestedBy: userID,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidTenantChange) {
+				problem.Respond(c, problem.New(http.StatusBadRequest, "validation failed").
+					WithError(err))
+			} else {
+				logger.Error("Failed to record tenant change",
+					zap.String("tenant_id", tenantID),
+					zap.Error(err))
+				problem.Respond(c, problem.New(http.StatusInternalServerError, "failed to record change"))
+			}
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusAccepted, change)
+		c.Abort()
+	}
+}
+
+// ResourceChange builds a change from a single resource route: the body is
+// the operation's data, :id names the resource updated or deleted, and the
+// switch of a new port or ACL comes from :id or the switch_id query
+// parameter as it does for the handlers.
+func ResourceChange(opType, resource string) ChangeBuilder {
+	return func(c *gin.Context) ([]models.TransactionOperation, error) {
+		op := models.TransactionOperation{
+			ID:       resource,
+			Type:     opType,
+			Resource: resource,
+		}
+
+		if opType == models.OperationCreate {
+			switch resource {
+			case models.ResourcePort:
+				op.SwitchID = c.Param("id")
+			case models.ResourceACL:
+				op.SwitchID = c.Query("switch_id")
+			}
+		} else {
+			op.ResourceID = c.Param("id")
+		}
+
+		if opType != models.OperationDelete {
+			if err := c.ShouldBindJSON(&op.Data); err != nil {
+				return nil, err
+			}
+		}
+
+		return []models.TransactionOperation{op}, nil
+	}
+}
+
+// BulkACLChange builds a change creating each ACL of a bulk ACL request
+func BulkACLChange(c *gin.Context) ([]models.TransactionOperation, error) {
+	var req struct {
+		ACLs []map[string]interface{} `json:"acls"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+
+	operations := make([]models.TransactionOperation, len(req.ACLs))
+	for i, acl := range req.ACLs {
+		operations[i] = models.TransactionOperation{
+			ID:       fmt.Sprintf("acl-%d", i),
+			Type:     models.OperationCreate,
+			Resource: models.ResourceACL,
+			SwitchID: c.Param("id"),
+			Data:     acl,
+		}
+	}
+	return operations, nil
+}
+
+// TransactionChange builds a change from the operations of a transaction
+// request
+func TransactionChange(c *gin.Context) ([]models.TransactionOperation, error) {
+	var req models.TransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+	return req.Operations, nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// AccessGrantKey is the external ID identifying the access grant an ACL
+// was created for
+const AccessGrantKey = "ovncp:access_grant"
+
+// AccessGrantACLPriority is the priority of the ACLs of access grants,
+// above those of tenants' rules
+const AccessGrantACLPriority = 2000
+
+// Statuses of access grants
+const (
+	AccessGrantActive  = "active"
+	AccessGrantExpired = "expired" // Removed when it expired
+	AccessGrantRevoked = "revoked" // Removed before it expired
+)
+
+// AccessGrant is temporary access of a source CIDR to a port or a service
+// of a switch, e.g. an engineer's address to SSH on a VM for two hours.
+// It's enforced by an allow ACL removed when the grant expires.
+type AccessGrant struct {
+	ID         string `json:"id"`
+	TenantID   string `json:"tenant_id,omitempty"`
+	SwitchID   string `json:"switch_id"`
+	PortID     string `json:"port_id,omitempty"`
+	PortName   string `json:"port_name,omitempty"`
+	Service    string `json:"service,omitempty"` // Name of a service of the catalog
+	SourceCIDR string `json:"source_cidr"`
+	Reason     string `json:"reason,omitempty"`
+	// ACLID is the ACL enforcing the grant while it's active
+	ACLID     string     `json:"acl_id"`
+	Status    string     `json:"status"`
+	GrantedBy string     `json:"granted_by,omitempty"`
+	GrantedAt time.Time  `json:"granted_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// RevokedBy is the user who revoked the grant before it expired
+	RevokedBy string `json:"revoked_by,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// ACLOwnerKey is the external ID naming what manages an ACL. ACLs owned by
+// templates or operators are changed through them; the generic ACL
+// endpoints refuse to change them unless forced.
+const ACLOwnerKey = "ovncp:owner"
+
+// Owners of ACLs
+const (
+	ACLOwnerTemplate = "template" // Instantiated from a policy template
+	ACLOwnerOperator = "operator" // Translated from Kubernetes NetworkPolicies, or managed by another controller
+	ACLOwnerUser     = "user"     // Written by hand
+)
+
+// ACLOwners lists the valid owners of ACLs
+var ACLOwners = []string{ACLOwnerTemplate, ACLOwnerOperator, ACLOwnerUser}
+
+// ACLOwnerFromExternalIDs returns the owner of an ACL with external IDs.
+// ACLs created before owners were recorded are attributed from the keys
+// their creators set: templates set template, the NetworkPolicy
+// translator k8s.networkpolicy.
+func ACLOwnerFromExternalIDs(externalIDs map[string]string) string {
+	if owner := externalIDs[ACLOwnerKey]; owner != "" {
+		return owner
+	}
+	switch {
+	case externalIDs["template"] != "":
+		return ACLOwnerTemplate
+	case externalIDs["k8s.networkpolicy"] != "":
+		return ACLOwnerOperator
+	}
+	return ACLOwnerUser
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// ACL rollout statuses. A rollout observes its rules until its bake period
+// ends and is then promoted, unless its rules matched more traffic than its
+// thresholds allow, which rolls it back.
+const (
+	ACLRolloutObserving  = "observing"
+	ACLRolloutPromoted   = "promoted"
+	ACLRolloutRolledBack = "rolled_back"
+)
+
+// ACLRolloutThresholds are how much traffic the rules of a rollout may
+// match during the bake period and still be promoted. Unset thresholds
+// aren't checked.
+type ACLRolloutThresholds struct {
+	// MaxHits is the most packets the rules may match, from the recorded
+	// ACL statistics
+	MaxHits *uint64 `json:"max_hits,omitempty"`
+	// MaxLoggedFlows is the most flows the rules may log, from the
+	// ingested ACL logs
+	MaxLoggedFlows *int `json:"max_logged_flows,omitempty"`
+}
+
+// ACLRolloutObservation is the traffic the rules of a rollout matched
+// since it started. For drop and reject rules, it's the traffic they would
+// have blocked.
+type ACLRolloutObservation struct {
+	Hits uint64 `json:"hits"`
+	// HitsRecorded is false when ACL statistics aren't recorded
+	HitsRecorded bool `json:"hits_recorded"`
+	// LoggedFlows counts the flows logged, up to 1000
+	LoggedFlows int `json:"logged_flows"`
+	// LogsIngested is false when ACL logs aren't ingested
+	LogsIngested bool `json:"logs_ingested"`
+	// Samples are the latest flows logged
+	Samples    []*ACLLogEntry `json:"samples,omitempty"`
+	ObservedAt time.Time      `json:"observed_at"`
+}
+
+// ACLRollout rolls ACLs out to a switch in stages: the rules are created in
+// observe mode, allowing and logging the traffic they match, then promoted
+// to their own action once they've been observed for the bake period.
+type ACLRollout struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id,omitempty"`
+	SwitchID string `json:"switch_id"`
+	// Rules are the ACLs as enforced once promoted, with the UUIDs of the
+	// ACLs created
+	Rules       []*ACL                 `json:"rules"`
+	Thresholds  ACLRolloutThresholds   `json:"thresholds"`
+	Status      string                 `json:"status"`
+	Observation *ACLRolloutObservation `json:"observation,omitempty"`
+	// Reason is why the rollout was rolled back
+	Reason    string     `json:"reason,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	BakeUntil time.Time  `json:"bake_until"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// EndedBy is the user who promoted or rolled back the rollout, empty
+	// when it ended on its own
+	EndedBy string `json:"ended_by,omitempty"`
+}
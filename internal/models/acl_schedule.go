@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ACLDisabledMatch is the match of a scheduled ACL outside its activation
+// windows, which never matches
+const ACLDisabledMatch = "0"
+
+// Actions of ACL schedule toggles
+const (
+	ACLScheduleEnabled  = "enabled"
+	ACLScheduleDisabled = "disabled"
+)
+
+// Reasons of ACL schedule toggles
+const (
+	ACLToggleWindowStart     = "window_start"     // A window of the schedule started
+	ACLToggleWindowEnd       = "window_end"       // The last window of the schedule ended
+	ACLToggleScheduleSet     = "schedule_set"     // The schedule was set, or changed
+	ACLToggleScheduleRemoved = "schedule_removed" // The schedule was removed, enabling the ACL for good
+)
+
+// ACLScheduleWindow is a daily time window an ACL is active in, e.g. from
+// 02:00 to 04:00. A window ending before it starts spans midnight.
+type ACLScheduleWindow struct {
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+	// Days are the days of the week the window starts on, mon to sun;
+	// every day when empty
+	Days []string `json:"days,omitempty"`
+}
+
+// ACLSchedule activates an ACL within time windows only. Outside them, the
+// ACL is kept with a match that never matches, and its own match is kept
+// with the schedule.
+type ACLSchedule struct {
+	ACLID    string              `json:"acl_id"`
+	TenantID string              `json:"tenant_id,omitempty"`
+	Windows  []ACLScheduleWindow `json:"windows"`
+	Timezone string              `json:"timezone"` // IANA name the windows are in
+	// Match is the match of the ACL within its windows
+	Match string `json:"match"`
+	// Active tells whether the ACL is enabled, within one of its windows
+	Active    bool      `json:"active"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ACLScheduleToggle is the audit record of a scheduled ACL being enabled
+// or disabled
+type ACLScheduleToggle struct {
+	ID       string `json:"id"`
+	ACLID    string `json:"acl_id"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Action   string `json:"action"` // enabled or disabled
+	Reason   string `json:"reason"`
+	// UserID is the user whose change of the schedule toggled the ACL,
+	// empty for the scheduler
+	UserID     string    `json:"user_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// Dependent is a resource that depends on a switch or router
+type Dependent struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name,omitempty"`
+}
+
+// RouterConnection links a switch to a router: a switch port of type router
+// peered with a router port
+type RouterConnection struct {
+	SwitchID       string   `json:"switch_id"`
+	SwitchName     string   `json:"switch_name,omitempty"`
+	SwitchPortID   string   `json:"switch_port_id"`
+	SwitchPortName string   `json:"switch_port_name,omitempty"`
+	RouterID       string   `json:"router_id,omitempty"` // Empty when the peer router port doesn't exist
+	RouterName     string   `json:"router_name,omitempty"`
+	RouterPortID   string   `json:"router_port_id,omitempty"`
+	RouterPortName string   `json:"router_port_name"`
+	Networks       []string `json:"networks,omitempty"` // Of the router port
+}
+
+// Dependents lists what depends on a switch or router, and would be deleted
+// or left dangling by deleting it
+type Dependents struct {
+	Resource   string `json:"resource"` // ResourceSwitch or ResourceRouter
+	ResourceID string `json:"resource_id"`
+	Name       string `json:"name,omitempty"`
+	// Ports are a switch's ports, or a router's router ports
+	Ports             []Dependent        `json:"ports"`
+	ACLs              []Dependent        `json:"acls,omitempty"` // Of a switch
+	LoadBalancers     []Dependent        `json:"load_balancers"`
+	RouterConnections []RouterConnection `json:"router_connections"`
+	// Count is the number of ports, ACLs and load balancers; router
+	// connections are made through ports, so aren't counted again
+	Count int `json:"count"`
+}
@@ -6,4 +6,61 @@ type TenantFilter struct {
 	Type   TenantType
 	Status TenantStatus
 	Parent string
-}
\ No newline at end of file
+}
+
+// ListOptions narrows, orders and pages OVN resource lists. A nil or zero
+// value returns every resource in the default order.
+type ListOptions struct {
+	// Limit caps the number of results; 0 means no limit
+	Limit  int
+	Offset int
+
+	// Name matches the resource name exactly
+	Name string
+	// ExternalIDs must all be present with the given value; an empty
+	// value only requires the key
+	ExternalIDs map[string]string
+	// Labels must match the resource labels; see ParseLabelSelector
+	Labels *LabelSelector
+
+	// SortBy names the field to order by; empty uses the resource default
+	SortBy   string
+	SortDesc bool
+}
+
+// Matches reports whether a resource with the given name and external IDs
+// passes the filters
+func (o *ListOptions) Matches(name string, externalIDs map[string]string) bool {
+	if o == nil {
+		return true
+	}
+	if o.Name != "" && o.Name != name {
+		return false
+	}
+	for k, v := range o.ExternalIDs {
+		actual, ok := externalIDs[k]
+		if !ok || (v != "" && actual != v) {
+			return false
+		}
+	}
+	if o.Labels != nil && !o.Labels.Matches(LabelsFromExternalIDs(externalIDs)) {
+		return false
+	}
+	return true
+}
+
+// Bounds returns the slice bounds of the requested page within total results
+func (o *ListOptions) Bounds(total int) (start, end int) {
+	if o == nil {
+		return 0, total
+	}
+	start = o.Offset
+	if start > total {
+		start = total
+	}
+	end = total
+	if o.Limit > 0 && start+o.Limit < total {
+		end = start + o.Limit
+	}
+	return start, end
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// FloatingIPKey is the external ID identifying the floating IP a NAT rule
+// was created for
+const FloatingIPKey = "ovncp:floating_ip"
+
+// FloatingIP is an external address allocated to a tenant from a pool.
+// Bound to a logical port, it's translated to the port's address by a
+// dnat_and_snat rule on the pool's router.
+type FloatingIP struct {
+	ID          string `json:"id"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Pool        string `json:"pool"`
+	IP          string `json:"ip"`
+	Description string `json:"description,omitempty"`
+	// RouterID is the router NATing the pool's addresses
+	RouterID string `json:"router_id"`
+	// PortID and LogicalIP are the port the floating IP is bound to and
+	// the address of it that's translated, empty while unbound
+	PortID    string `json:"port_id,omitempty"`
+	LogicalIP string `json:"logical_ip,omitempty"`
+	NATID     string `json:"nat_id,omitempty"`
+	// Distributed floating IPs are NATed on the chassis of their port,
+	// with the port's MAC as external MAC
+	Distributed bool `json:"distributed,omitempty"`
+	// GratuitousARP announces the address from the router's gateway once
+	// it's bound
+	GratuitousARP bool       `json:"gratuitous_arp,omitempty"`
+	AllocatedBy   string     `json:"allocated_by,omitempty"`
+	AllocatedAt   time.Time  `json:"allocated_at"`
+	BoundAt       *time.Time `json:"bound_at,omitempty"`
+}
+
+// Bound tells whether the floating IP is bound to a port
+func (f *FloatingIP) Bound() bool {
+	return f.PortID != ""
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LabelPrefix prefixes the external_ids keys resource labels are stored
+// under, keeping them apart from the keys OVN, ovncp and other systems set
+const LabelPrefix = "ovncp.io/"
+
+// Label keys are an optional DNS subdomain prefix and a name, as in
+// Kubernetes; values are empty or a name
+var (
+	labelNamePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+	setRequirement     = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// LabelsFromExternalIDs returns the labels stored in external IDs, without
+// their prefix
+func LabelsFromExternalIDs(externalIDs map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range externalIDs {
+		if name, ok := strings.CutPrefix(k, LabelPrefix); ok {
+			labels[name] = v
+		}
+	}
+	return labels
+}
+
+// ValidateLabelKey checks that a label key is a name of at most 63
+// alphanumerics, '-', '_' and '.', optionally after a DNS subdomain prefix
+// and a slash
+func ValidateLabelKey(key string) error {
+	name := key
+	if prefix, rest, ok := strings.Cut(key, "/"); ok {
+		if !labelPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("invalid label key %q: prefix must be a DNS subdomain", key)
+		}
+		name = rest
+	}
+	if !labelNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid label key %q: names are at most 63 alphanumerics, '-', '_' and '.', beginning and ending with an alphanumeric", key)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks that a label value is empty or a name
+func ValidateLabelValue(value string) error {
+	if value != "" && !labelNamePattern.MatchString(value) {
+		return fmt.Errorf("invalid label value %q: values are empty or at most 63 alphanumerics, '-', '_' and '.', beginning and ending with an alphanumeric", value)
+	}
+	return nil
+}
+
+// ValidateLabels checks the keys and values of labels
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if err := ValidateLabelKey(k); err != nil {
+			return err
+		}
+		if err := ValidateLabelValue(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseLabelSelector parses a selector in the Kubernetes syntax:
+// comma-separated requirements, each one of
+//
+//	key=value, key==value  the label has the value
+//	key!=value             the label doesn't have the value, or is unset
+//	key in (v1,v2)         the label has one of the values
+//	key notin (v1,v2)      the label has none of the values, or is unset
+//	key                    the label is set
+//	!key                   the label is unset
+func ParseLabelSelector(selector string) (*LabelSelector, error) {
+	parsed := &LabelSelector{MatchLabels: make(map[string]string)}
+	for _, requirement := range splitRequirements(selector) {
+		requirement = strings.TrimSpace(requirement)
+		if requirement == "" {
+			return nil, fmt.Errorf("empty requirement in label selector %q", selector)
+		}
+
+		var key string
+		var values []string
+		var req *LabelSelectorRequirement
+		if m := setRequirement.FindStringSubmatch(requirement); m != nil {
+			key = m[1]
+			for _, value := range strings.Split(m[3], ",") {
+				values = append(values, strings.TrimSpace(value))
+			}
+			operator := "In"
+			if m[2] == "notin" {
+				operator = "NotIn"
+			}
+			req = &LabelSelectorRequirement{Key: key, Operator: operator, Values: values}
+		} else if k, v, ok := strings.Cut(requirement, "!="); ok {
+			key = strings.TrimSpace(k)
+			values = []string{strings.TrimSpace(v)}
+			req = &LabelSelectorRequirement{Key: key, Operator: "NotIn", Values: values}
+		} else if k, v, ok := strings.Cut(requirement, "="); ok {
+			key = strings.TrimSpace(k)
+			value := strings.TrimSpace(strings.TrimPrefix(v, "="))
+			values = []string{value}
+			if existing, ok := parsed.MatchLabels[key]; ok && existing != value {
+				return nil, fmt.Errorf("label selector %q requires %s to be both %q and %q", selector, key, existing, value)
+			}
+			parsed.MatchLabels[key] = value
+		} else if k, ok := strings.CutPrefix(requirement, "!"); ok {
+			key = strings.TrimSpace(k)
+			req = &LabelSelectorRequirement{Key: key, Operator: "DoesNotExist"}
+		} else {
+			key = requirement
+			req = &LabelSelectorRequirement{Key: key, Operator: "Exists"}
+		}
+
+		if err := ValidateLabelKey(key); err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if err := ValidateLabelValue(value); err != nil {
+				return nil, err
+			}
+		}
+		if req != nil {
+			parsed.MatchExpressions = append(parsed.MatchExpressions, *req)
+		}
+	}
+	return parsed, nil
+}
+
+// splitRequirements splits a label selector at the commas outside
+// parentheses
+func splitRequirements(selector string) []string {
+	var requirements []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(requirements, selector[start:])
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// MaintenanceMode freezes writes to the API, e.g. while the OVN cluster
+// underneath is upgraded. The mode with an empty TenantID applies to the
+// whole cluster; a tenant's own mode overrides it, freezing the tenant
+// alone or exempting it from a cluster-wide freeze.
+type MaintenanceMode struct {
+	TenantID string `json:"tenant_id,omitempty"`
+	// Frozen refuses writes; a tenant's mode that isn't frozen exempts the
+	// tenant from the cluster's
+	Frozen bool   `json:"frozen"`
+	Reason string `json:"reason,omitempty"`
+	// ETA is when writes are expected to be allowed again
+	ETA   *time.Time `json:"eta,omitempty"`
+	SetBy string     `json:"set_by,omitempty"`
+	SetAt time.Time  `json:"set_at"`
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// NetworkPolicy mirrors the Kubernetes networking.k8s.io/v1 NetworkPolicy
+// object. Only the fields needed for translation to OVN are kept.
+type NetworkPolicy struct {
+	APIVersion string            `json:"apiVersion,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Spec       NetworkPolicySpec `json:"spec"`
+}
+
+// ObjectMeta is the subset of Kubernetes object metadata used here
+type ObjectMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	UID       string            `json:"uid,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// NetworkPolicySpec describes which pods a policy selects and the traffic
+// it allows to and from them
+type NetworkPolicySpec struct {
+	PodSelector LabelSelector              `json:"podSelector"`
+	Ingress     []NetworkPolicyIngressRule `json:"ingress,omitempty"`
+	Egress      []NetworkPolicyEgressRule  `json:"egress,omitempty"`
+	PolicyTypes []string                   `json:"policyTypes,omitempty"`
+}
+
+// NetworkPolicyIngressRule allows traffic from peers to ports
+type NetworkPolicyIngressRule struct {
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	From  []NetworkPolicyPeer `json:"from,omitempty"`
+}
+
+// NetworkPolicyEgressRule allows traffic to peers on ports
+type NetworkPolicyEgressRule struct {
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	To    []NetworkPolicyPeer `json:"to,omitempty"`
+}
+
+// NetworkPolicyPeer selects pods, namespaces or an IP block
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `json:"podSelector,omitempty"`
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty"`
+	IPBlock           *IPBlock       `json:"ipBlock,omitempty"`
+}
+
+// IPBlock is a CIDR with optional exceptions
+type IPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// NetworkPolicyPort is a protocol and port or port range
+type NetworkPolicyPort struct {
+	Protocol *string      `json:"protocol,omitempty"`
+	Port     *IntOrString `json:"port,omitempty"`
+	EndPort  *int32       `json:"endPort,omitempty"`
+}
+
+// LabelSelector matches labels. An empty selector matches everything.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// LabelSelectorRequirement is a set-based label requirement
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"` // In, NotIn, Exists, DoesNotExist
+	Values   []string `json:"values,omitempty"`
+}
+
+// Matches reports whether labels satisfy the selector
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	for k, v := range s.MatchLabels {
+		if actual, ok := labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+
+	for _, req := range s.MatchExpressions {
+		value, exists := labels[req.Key]
+		switch req.Operator {
+		case "In":
+			if !exists || !containsString(req.Values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && containsString(req.Values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate checks that the selector's operators are known
+func (s *LabelSelector) Validate() error {
+	for _, req := range s.MatchExpressions {
+		switch req.Operator {
+		case "In", "NotIn":
+			if len(req.Values) == 0 {
+				return fmt.Errorf("operator %s on key %s requires values", req.Operator, req.Key)
+			}
+		case "Exists", "DoesNotExist":
+		default:
+			return fmt.Errorf("unknown selector operator %q", req.Operator)
+		}
+	}
+	return nil
+}
+
+// IntOrString holds a port number or a named port
+type IntOrString struct {
+	IntVal   int
+	StrVal   string
+	IsString bool
+}
+
+// UnmarshalJSON accepts a JSON number or string
+func (v *IntOrString) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		v.IsString = true
+		return json.Unmarshal(data, &v.StrVal)
+	}
+	v.IsString = false
+	return json.Unmarshal(data, &v.IntVal)
+}
+
+// MarshalJSON writes the value back in its original form
+func (v IntOrString) MarshalJSON() ([]byte, error) {
+	if v.IsString {
+		return json.Marshal(v.StrVal)
+	}
+	return json.Marshal(v.IntVal)
+}
+
+func (v IntOrString) String() string {
+	if v.IsString {
+		return v.StrVal
+	}
+	return strconv.Itoa(v.IntVal)
+}
+
+// PolicyPod is a pod known to the translator, with the labels selectors are
+// evaluated against
+type PolicyPod struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	IPs       []string          `json:"ips,omitempty"`
+}
+
+// PolicyNamespace is a namespace with its labels
+type PolicyNamespace struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
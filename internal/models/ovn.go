@@ -18,6 +18,7 @@ type LogicalSwitch struct {
 	ExternalIDs map[string]string      `json:"external_ids,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+	Ownership   *Ownership             `json:"ownership,omitempty"`
 }
 
 type LogicalRouter struct {
@@ -27,12 +28,14 @@ type LogicalRouter struct {
 	Ports         []string               `json:"ports,omitempty"`
 	StaticRoutes  []StaticRoute          `json:"static_routes,omitempty"`
 	Policies      []string               `json:"policies,omitempty"`
+	PolicyRules   []RouterPolicy         `json:"policy_rules,omitempty"` // The policies themselves, in the topology
 	NAT           []NAT                  `json:"nat,omitempty"`
 	LoadBalancer  []string               `json:"load_balancer,omitempty"`
 	Options       map[string]string      `json:"options,omitempty"`
 	ExternalIDs   map[string]string      `json:"external_ids,omitempty"`
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
+	Ownership     *Ownership             `json:"ownership,omitempty"`
 }
 
 type LogicalSwitchPort struct {
@@ -53,8 +56,28 @@ type LogicalSwitchPort struct {
 	Tag              int                    `json:"tag,omitempty"`
 	ParentUUID       string                 `json:"parent_uuid,omitempty"` // For compatibility with cached service
 	ParentType       string                 `json:"parent_type,omitempty"` // For compatibility with cached service
+	Binding          *PortBinding           `json:"binding,omitempty"`     // Southbound state, when requested
 	CreatedAt        time.Time              `json:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at"`
+	Ownership        *Ownership             `json:"ownership,omitempty"`
+}
+
+// Port binding status
+const (
+	PortBindingUp      = "up"      // Bound, with its flows installed
+	PortBindingDown    = "down"    // Bound, but not up yet
+	PortBindingUnbound = "unbound" // No chassis has claimed it
+	PortBindingPending = "pending" // ovn-northd hasn't created its binding
+)
+
+// PortBinding is the southbound state of a logical port: whether and where
+// it is bound
+type PortBinding struct {
+	Status          string `json:"status"`
+	Chassis         string `json:"chassis,omitempty"`
+	ChassisHostname string `json:"chassis_hostname,omitempty"`
+	Up              bool   `json:"up"`
+	TunnelKey       int    `json:"tunnel_key,omitempty"`
 }
 
 type LogicalRouterPort struct {
@@ -80,16 +103,25 @@ type ACL struct {
 	Log         bool                   `json:"log"`
 	Severity    string                 `json:"severity,omitempty"`
 	Alert       bool                   `json:"alert"`
+	Owner       string                 `json:"owner,omitempty"` // template, operator or user
 	ExternalIDs map[string]string      `json:"external_ids,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+	Ownership   *Ownership             `json:"ownership,omitempty"`
 }
 
 type StaticRoute struct {
+	UUID       string                 `json:"uuid,omitempty"`
+	RouterID   string                 `json:"router_id,omitempty"`
 	IPPrefix   string                 `json:"ip_prefix"`
 	Nexthop    string                 `json:"nexthop"`
 	OutputPort *string                `json:"output_port,omitempty"`
 	Policy     *string                `json:"policy,omitempty"`
+	RouteTable string                 `json:"route_table,omitempty"`
+	BFD        *bool                  `json:"bfd,omitempty"`        // Monitor the nexthop with BFD
+	BFDStatus  string                 `json:"bfd_status,omitempty"` // up, down, init or admin_down
+	ECMPSymmetricReply *bool          `json:"ecmp_symmetric_reply,omitempty"`
+	ExternalIDs map[string]string     `json:"external_ids,omitempty"`
 }
 
 type NAT struct {
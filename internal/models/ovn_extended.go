@@ -34,4 +34,135 @@ type AddressSet struct {
 	ExternalIDs map[string]string `json:"external_ids,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// DNS represents a set of DNS records OVN answers queries for from the VMs
+// on the switches it's attached to
+type DNS struct {
+	UUID        string            `json:"uuid"`
+	Records     map[string]string `json:"records"` // Hostname to space-separated IP addresses
+	Options     map[string]string `json:"options,omitempty"`
+	Switches    []string          `json:"switches,omitempty"` // UUIDs of the switches it's attached to
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Mirror is a traffic mirroring session: traffic of its source ports, in
+// the direction of its filter, is copied to its sink
+type Mirror struct {
+	UUID        string            `json:"uuid"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`            // gre, erspan, local or lport
+	Filter      string            `json:"filter"`          // from-lport, to-lport or both
+	Sink        string            `json:"sink"`            // Remote IP, OVS interface or logical port, by type
+	Index       int               `json:"index,omitempty"` // GRE key or ERSPAN session ID
+	Ports       []string          `json:"ports,omitempty"` // UUIDs of the ports it mirrors
+	Active      bool              `json:"active"`          // Mirrors at least one port
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// SampleCollector is an IPFIX collector set flow samples are exported to.
+// The exporter itself is the Flow_Sample_Collector_Set with the same set ID
+// in the Open vSwitch database of each chassis.
+type SampleCollector struct {
+	UUID        string            `json:"uuid"`
+	ID          int               `json:"id"` // 1-255
+	Name        string            `json:"name"`
+	SetID       int               `json:"set_id"`                // Flow_Sample_Collector_Set ID
+	Probability *int              `json:"probability,omitempty"` // Of sampling a packet, out of 65535
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+}
+
+// SamplingApp sets the IPFIX observation domain ID of the samples taken for
+// one purpose: dropped packets or packets matching ACLs
+type SamplingApp struct {
+	Type string `json:"type"` // drop, acl-new or acl-est
+	ID   int    `json:"id"`   // Observation domain ID, 1-255
+}
+
+// ACLSampling is the sampling of the traffic an ACL matches
+type ACLSampling struct {
+	ACLID       string      `json:"acl_id"`
+	New         *FlowSample `json:"new,omitempty"`         // Packets of new connections
+	Established *FlowSample `json:"established,omitempty"` // Packets of established connections
+}
+
+// FlowSample is an IPFIX observation point; the packets it samples are
+// exported to its collectors
+type FlowSample struct {
+	ObservationPointID int      `json:"observation_point_id,omitempty"`
+	Collectors         []string `json:"collectors"` // UUIDs or names of sample collectors
+}
+
+// RouterPolicy is a policy-based routing rule of a logical router. Packets
+// matching a higher priority policy are allowed, dropped or rerouted first.
+type RouterPolicy struct {
+	UUID        string            `json:"uuid"`
+	RouterID    string            `json:"router_id,omitempty"`
+	Priority    int               `json:"priority"`
+	Match       string            `json:"match"`
+	Action      string            `json:"action"`             // allow, drop or reroute
+	Nexthops    []string          `json:"nexthops,omitempty"` // Where reroute sends packets
+	Options     map[string]string `json:"options,omitempty"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Gateway is where a logical router reaches the external network: either a
+// distributed gateway port bound to gateway chassis, or a gateway router
+// pinned to a single chassis
+type Gateway struct {
+	Type           string           `json:"type"` // distributed or l3_gateway
+	RouterID       string           `json:"router_id"`
+	RouterName     string           `json:"router_name"`
+	PortID         string           `json:"port_id,omitempty"` // The gateway port, for distributed gateways
+	PortName       string           `json:"port_name,omitempty"`
+	Networks       []string         `json:"networks,omitempty"`
+	HAChassisGroup string           `json:"ha_chassis_group,omitempty"`
+	Chassis        []GatewayChassis `json:"chassis"`                   // Highest priority first
+	HostingChassis string           `json:"hosting_chassis,omitempty"` // Where the gateway is active, as reported by ovn-northd
+}
+
+// GatewayChassis is a chassis a gateway may be active on
+type GatewayChassis struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+}
+
+// GatewayBinding places a router port on gateway chassis, making it a
+// distributed gateway port. With an HA chassis group name, the chassis are
+// set on that group, created if needed, which other ports may share.
+type GatewayBinding struct {
+	HAChassisGroup string           `json:"ha_chassis_group,omitempty"`
+	Chassis        []GatewayChassis `json:"chassis"`
+}
+
+// Chassis is a hypervisor or gateway node running ovn-controller, as
+// registered in the southbound database
+type Chassis struct {
+	UUID           string            `json:"uuid"`
+	Name           string            `json:"name"`
+	Hostname       string            `json:"hostname"`
+	Encaps         []ChassisEncap    `json:"encaps"`
+	Gateway        bool              `json:"gateway"` // Eligible to host gateways
+	Interconnect   bool              `json:"interconnect,omitempty"`
+	DatapathType   string            `json:"datapath_type,omitempty"`
+	InterfaceTypes []string          `json:"interface_types,omitempty"`
+	BridgeMappings []string          `json:"bridge_mappings,omitempty"` // physnet:bridge pairs
+	TransportZones []string          `json:"transport_zones,omitempty"`
+	Features       []string          `json:"features"`      // Supported by its ovn-controller
+	Ports          int               `json:"ports"`         // Port bindings bound to the chassis
+	GatewayPorts   int               `json:"gateway_ports"` // Of which gateway ports
+	ExternalIDs    map[string]string `json:"external_ids,omitempty"`
+}
+
+// ChassisEncap is a tunnel encapsulation a chassis is reachable through
+type ChassisEncap struct {
+	Type    string            `json:"type"` // geneve, vxlan or stt
+	IP      string            `json:"ip"`
+	Options map[string]string `json:"options,omitempty"`
+}
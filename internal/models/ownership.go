@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// external_ids keys recording who created and last changed a resource
+// through ovncp, and through what. The tenant is recorded under tenant_id.
+const (
+	CreatedByKey     = "ovncp:created_by"
+	CreatedSourceKey = "ovncp:created_source"
+	UpdatedByKey     = "ovncp:updated_by"
+	UpdatedSourceKey = "ovncp:updated_source"
+	TenantIDKey      = "tenant_id"
+)
+
+// Sources of changes, i.e. the clients making them
+const (
+	SourceAPI       = "api"
+	SourceCLI       = "cli"
+	SourceTerraform = "terraform"
+)
+
+// Sources lists the valid change sources
+var Sources = []string{SourceAPI, SourceCLI, SourceTerraform}
+
+// Ownership is who created and last changed a resource, from its
+// external_ids. The times are those of created_at and updated_at.
+type Ownership struct {
+	TenantID      string `json:"tenant_id,omitempty"`
+	CreatedBy     string `json:"created_by,omitempty"`
+	CreatedSource string `json:"created_source,omitempty"`
+	UpdatedBy     string `json:"updated_by,omitempty"`
+	UpdatedSource string `json:"updated_source,omitempty"`
+}
+
+// OwnershipFromExternalIDs returns the ownership recorded in external IDs,
+// or nil if none is
+func OwnershipFromExternalIDs(externalIDs map[string]string) *Ownership {
+	o := &Ownership{
+		TenantID:      externalIDs[TenantIDKey],
+		CreatedBy:     externalIDs[CreatedByKey],
+		CreatedSource: externalIDs[CreatedSourceKey],
+		UpdatedBy:     externalIDs[UpdatedByKey],
+		UpdatedSource: externalIDs[UpdatedSourceKey],
+	}
+	if *o == (Ownership{}) {
+		return nil
+	}
+	return o
+}
+
+// ResourceChange is a recorded change to a resource: who made it, on behalf
+// of which tenant and through what
+type ResourceChange struct {
+	ID         string    `json:"id" db:"id"`
+	ResourceID string    `json:"resource_id" db:"resource_id"`
+	Resource   string    `json:"resource" db:"resource"` // Route segment, e.g. switches or ports
+	Action     string    `json:"action" db:"action"`     // created, updated or deleted
+	UserID     string    `json:"user_id,omitempty" db:"user_id"`
+	TenantID   string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	Source     string    `json:"source,omitempty" db:"source"`
+	Cluster    string    `json:"cluster,omitempty" db:"cluster"`
+	Method     string    `json:"method" db:"method"`
+	Path       string    `json:"path" db:"path"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// ProviderNetworkKey is the switch external ID naming the physical network
+// a provider network's switch is bridged to, and the router port external
+// ID naming the provider network's switch a router is attached through
+const ProviderNetworkKey = "ovncp:provider_network"
+
+// ProviderNetworkCIDRKey is the switch external ID holding the subnet of a
+// provider network, if it was given one
+const ProviderNetworkCIDRKey = "ovncp:provider_cidr"
+
+// LocalnetNetworkNameOption is the localnet port option naming the physical
+// network, mapped to a bridge by the chassis' ovn-bridge-mappings
+const LocalnetNetworkNameOption = "network_name"
+
+// ProviderNetwork is a logical switch bridged to a physical network by a
+// localnet port, on the VLAN of its tag
+type ProviderNetwork struct {
+	ID              string `json:"id"` // The switch's UUID
+	Name            string `json:"name"`
+	PhysicalNetwork string `json:"physical_network"`
+	VLAN            int    `json:"vlan,omitempty"`
+	CIDR            string `json:"cidr,omitempty"`
+	LocalnetPortID  string `json:"localnet_port_id"`
+	// Chassis are those mapping the physical network to a bridge
+	Chassis []string                 `json:"chassis"`
+	Routers []*ProviderNetworkRouter `json:"routers"`
+}
+
+// ProviderNetworkRouter is a router attached to a provider network by a
+// gateway port, peered with a router-type port of the network's switch
+type ProviderNetworkRouter struct {
+	RouterID       string           `json:"router_id"`
+	RouterName     string           `json:"router_name"`
+	RouterPortID   string           `json:"router_port_id"`
+	RouterPortName string           `json:"router_port_name"`
+	SwitchPortID   string           `json:"switch_port_id,omitempty"`
+	MAC            string           `json:"mac"`
+	Networks       []string         `json:"networks"`
+	HAChassisGroup string           `json:"ha_chassis_group,omitempty"`
+	Chassis        []GatewayChassis `json:"chassis,omitempty"` // Highest priority first
+}
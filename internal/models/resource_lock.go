@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ResourceLockTypes are the types of resources that can be locked
+var ResourceLockTypes = []string{
+	ResourceSwitch, ResourceRouter, ResourcePort, ResourceACL, ResourceLoadBalancer,
+}
+
+// ResourceLock is an advisory lock on a resource: while it's held, writes
+// to the resource by anyone but the holder are refused. Locks expire, so a
+// forgotten lock doesn't block the resource for good.
+type ResourceLock struct {
+	ID           string `json:"id"`
+	ResourceType string `json:"resource_type"` // One of ResourceLockTypes
+	ResourceID   string `json:"resource_id"`
+	TenantID     string `json:"tenant_id,omitempty"`
+	Holder       string `json:"holder"` // ID of the user holding the lock
+	Reason       string `json:"reason,omitempty"`
+	// Automatic locks are taken for the duration of a single write
+	Automatic  bool      `json:"automatic"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
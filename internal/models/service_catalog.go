@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ACLMatchSourceKey is the external ID keeping the match of an ACL as it
+// was written, when service references were expanded in it
+const ACLMatchSourceKey = "ovncp:match_source"
+
+// ServicePort is a port, or a range of ports, of a transport protocol
+type ServicePort struct {
+	Protocol string `json:"protocol"` // tcp, udp or sctp
+	Port     int    `json:"port"`
+	// EndPort ends a range of ports starting at Port; 0 for a single port
+	EndPort int `json:"end_port,omitempty"`
+}
+
+// ServiceEntry is a named L4 service of the service catalog, referenced in
+// ACL matches as service(name). Built-in entries are shared by every
+// tenant; custom entries belong to a tenant, or to every tenant when
+// created without one.
+type ServiceEntry struct {
+	ID          string        `json:"id,omitempty"`
+	TenantID    string        `json:"tenant_id,omitempty"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Ports       []ServicePort `json:"ports"`
+	BuiltIn     bool          `json:"built_in"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+}
@@ -94,6 +94,61 @@ type ResourceUsage struct {
 	LastUpdated      time.Time `json:"last_updated" db:"last_updated"`
 }
 
+// UsageResourceTypes lists the resources counted in usage, in report order
+var UsageResourceTypes = []string{
+	"switches", "routers", "ports", "acls", "load_balancers", "address_sets", "port_groups", "backups",
+}
+
+// Counts returns the usage of each resource, keyed by the names in
+// UsageResourceTypes
+func (u *ResourceUsage) Counts() map[string]int {
+	return map[string]int{
+		"switches":       u.Switches,
+		"routers":        u.Routers,
+		"ports":          u.Ports,
+		"acls":           u.ACLs,
+		"load_balancers": u.LoadBalancers,
+		"address_sets":   u.AddressSets,
+		"port_groups":    u.PortGroups,
+		"backups":        u.Backups,
+	}
+}
+
+// TenantUsageSnapshot records a tenant's resource usage at one time
+type TenantUsageSnapshot struct {
+	TenantID   string        `json:"tenant_id" db:"tenant_id"`
+	Usage      ResourceUsage `json:"usage" db:"usage"`
+	RecordedAt time.Time     `json:"recorded_at" db:"recorded_at"`
+}
+
+// UsageInterval is the period usage history is bucketed by
+type UsageInterval string
+
+const (
+	UsageIntervalHour  UsageInterval = "hour"
+	UsageIntervalDay   UsageInterval = "day"
+	UsageIntervalMonth UsageInterval = "month"
+)
+
+// UsageBucket summarizes the usage snapshots taken in one period
+type UsageBucket struct {
+	Start   time.Time          `json:"start"`
+	End     time.Time          `json:"end"`
+	Samples int                `json:"samples"`
+	Average map[string]float64 `json:"average"`
+	Peak    map[string]int     `json:"peak"`
+}
+
+// TenantUsageHistory is a tenant's usage over time, for chargeback and
+// showback reports
+type TenantUsageHistory struct {
+	TenantID string         `json:"tenant_id"`
+	Interval UsageInterval  `json:"interval"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Buckets  []*UsageBucket `json:"buckets"`
+}
+
 // TenantInvitation represents an invitation to join a tenant
 type TenantInvitation struct {
 	ID          string    `json:"id" db:"id"`
@@ -116,10 +171,47 @@ type TenantAPIKey struct {
 	KeyHash     string    `json:"-" db:"key_hash"`
 	Prefix      string    `json:"prefix" db:"prefix"`
 	Scopes      []string  `json:"scopes" db:"scopes"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty" db:"allowed_cidrs"` // Client networks the key may be used from; empty allows any
 	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
 	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	UsageCount  int64     `json:"usage_count" db:"usage_count"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	CreatedBy   string    `json:"created_by" db:"created_by"`
+
+	// The secret replaced by the last rotation stays valid until
+	// PreviousExpiresAt
+	PreviousKeyHash   string     `json:"-" db:"previous_key_hash"`
+	PreviousPrefix    string     `json:"previous_prefix,omitempty" db:"previous_prefix"`
+	PreviousExpiresAt *time.Time `json:"previous_expires_at,omitempty" db:"previous_expires_at"`
+	RotatedAt         *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+}
+
+// API key scopes
+const (
+	APIKeyScopeRead   = "read"   // Read network resources, topology and changesets
+	APIKeyScopeWrite  = "write"  // Create, update and delete network resources
+	APIKeyScopeBackup = "backup" // List, create and delete backups
+	APIKeyScopeTrace  = "trace"  // Run flow traces
+	APIKeyScopeAdmin  = "admin"  // Everything within the key's tenant
+)
+
+// IsValidAPIKeyScope reports whether scope is a known API key scope
+func IsValidAPIKeyScope(scope string) bool {
+	switch scope {
+	case APIKeyScopeRead, APIKeyScopeWrite, APIKeyScopeBackup, APIKeyScopeTrace, APIKeyScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// HasScope reports whether the key was granted scope
+func (k *TenantAPIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // DefaultQuotas returns default quota values
@@ -150,6 +242,21 @@ func UnlimitedQuotas() TenantQuotas {
 	}
 }
 
+// Limits returns the quota of each resource, keyed by the names in
+// UsageResourceTypes
+func (q TenantQuotas) Limits() map[string]int {
+	return map[string]int{
+		"switches":       q.MaxSwitches,
+		"routers":        q.MaxRouters,
+		"ports":          q.MaxPorts,
+		"acls":           q.MaxACLs,
+		"load_balancers": q.MaxLoadBalancers,
+		"address_sets":   q.MaxAddressSets,
+		"port_groups":    q.MaxPortGroups,
+		"backups":        q.MaxBackups,
+	}
+}
+
 // IsWithinQuota checks if adding count resources would exceed quota
 func (q TenantQuotas) IsWithinQuota(resourceType string, current, toAdd int) bool {
 	var limit int
@@ -181,4 +288,54 @@ func (q TenantQuotas) IsWithinQuota(resourceType string, current, toAdd int) boo
 	}
 	
 	return (current + toAdd) <= limit
-}
\ No newline at end of file
+}
+// TenantChange is a write to a tenant's resources held for approval by a
+// tenant admin because the tenant requires approval
+type TenantChange struct {
+	ID            string                 `json:"id" db:"id"`
+	TenantID      string                 `json:"tenant_id" db:"tenant_id"`
+	Status        TenantChangeStatus     `json:"status" db:"status"`
+	Description   string                 `json:"description,omitempty" db:"description"`
+	Cluster       string                 `json:"cluster,omitempty" db:"cluster"`
+	Operations    []TransactionOperation `json:"operations" db:"operations"`
+	RequestedBy   string                 `json:"requested_by" db:"requested_by"`
+	ReviewedBy    string                 `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewComment string                 `json:"review_comment,omitempty" db:"review_comment"`
+	ReviewedAt    *time.Time             `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	Result        *TransactionResponse   `json:"result,omitempty" db:"result"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// TenantChangeStatus represents the status of a tenant change
+type TenantChangeStatus string
+
+const (
+	TenantChangeStatusPending  TenantChangeStatus = "pending"
+	TenantChangeStatusApproved TenantChangeStatus = "approved"
+	TenantChangeStatusRejected TenantChangeStatus = "rejected"
+	TenantChangeStatusExecuted TenantChangeStatus = "executed"
+	TenantChangeStatusFailed   TenantChangeStatus = "failed"
+)
+
+// TenantDeletion reports the progress of deleting a tenant and the OVN
+// objects associated with it
+type TenantDeletion struct {
+	TenantID    string               `json:"tenant_id"`
+	Status      TenantDeletionStatus `json:"status"`
+	Total       int                  `json:"total"`
+	Deleted     int                  `json:"deleted"`
+	Remaining   map[string]int       `json:"remaining,omitempty"` // Resources left to delete by type
+	Error       string               `json:"error,omitempty"`
+	StartedAt   time.Time            `json:"started_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
+
+// TenantDeletionStatus represents the status of a tenant deletion
+type TenantDeletionStatus string
+
+const (
+	TenantDeletionStatusRunning   TenantDeletionStatus = "running"
+	TenantDeletionStatusCompleted TenantDeletionStatus = "completed"
+	TenantDeletionStatusFailed    TenantDeletionStatus = "failed"
+)
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TopologySnapshot is the topology of an OVN cluster recorded at one time,
+// kept so later states can be compared with it
+type TopologySnapshot struct {
+	ID      string          `json:"id" db:"id"`
+	Cluster string          `json:"cluster" db:"cluster"`
+	TakenAt time.Time       `json:"taken_at" db:"taken_at"`
+	Data    json.RawMessage `json:"data,omitempty" db:"data"` // The encoded topology
+}
+
+// ACLStatsSample is the cumulative traffic an ACL's flows matched when
+// recorded
+type ACLStatsSample struct {
+	ACLID      string    `json:"acl_id" db:"acl_id"`
+	Packets    uint64    `json:"packets" db:"packets"`
+	Bytes      uint64    `json:"bytes" db:"bytes"`
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// ACLLogEntry is a packet matching an ACL with logging enabled, as logged
+// by ovn-controller
+type ACLLogEntry struct {
+	ID        int64     `json:"id,omitempty" db:"id"`
+	ACLName   string    `json:"acl_name" db:"acl_name"` // Empty for unnamed ACLs
+	Verdict   string    `json:"verdict" db:"verdict"`   // allow, drop or reject
+	Severity  string    `json:"severity,omitempty" db:"severity"`
+	Direction string    `json:"direction,omitempty" db:"direction"`
+	Protocol  string    `json:"protocol,omitempty" db:"protocol"` // As OVS names it, e.g. tcp or icmp6
+	SrcIP     string    `json:"src_ip,omitempty" db:"src_ip"`
+	DstIP     string    `json:"dst_ip,omitempty" db:"dst_ip"`
+	SrcPort   int       `json:"src_port,omitempty" db:"src_port"`
+	DstPort   int       `json:"dst_port,omitempty" db:"dst_port"`
+	LoggedAt  time.Time `json:"logged_at" db:"logged_at"`
+}
+
+// ACLLogFilter selects the log entries of the ACLs with a name. Other zero
+// fields match every entry.
+type ACLLogFilter struct {
+	ACLName  string
+	From     time.Time
+	To       time.Time
+	Verdict  string
+	Protocol string // tcp also matches tcp6, and so on
+	SrcIP    string
+	DstIP    string
+	SrcPort  int
+	DstPort  int
+	Limit    int // Most recent entries returned
+}
+
+// Matches reports whether entry is selected by the filter, limit aside
+func (f *ACLLogFilter) Matches(entry *ACLLogEntry) bool {
+	switch {
+	case entry.ACLName != f.ACLName:
+		return false
+	case !f.From.IsZero() && entry.LoggedAt.Before(f.From):
+		return false
+	case !f.To.IsZero() && entry.LoggedAt.After(f.To):
+		return false
+	case f.Verdict != "" && entry.Verdict != f.Verdict:
+		return false
+	case f.Protocol != "" && entry.Protocol != f.Protocol && entry.Protocol != f.Protocol+"6":
+		return false
+	case f.SrcIP != "" && entry.SrcIP != f.SrcIP:
+		return false
+	case f.DstIP != "" && entry.DstIP != f.DstIP:
+		return false
+	case f.SrcPort != 0 && entry.SrcPort != f.SrcPort:
+		return false
+	case f.DstPort != 0 && entry.DstPort != f.DstPort:
+		return false
+	}
+	return true
+}
@@ -8,9 +8,10 @@ import (
 type TransactionOperation struct {
 	ID         string                 `json:"id"`          // Client-provided ID for tracking
 	Type       string                 `json:"type"`        // "create", "update", "delete"
-	Resource   string                 `json:"resource"`    // "switch", "router", "port", "acl"
+	Resource   string                 `json:"resource"`    // "switch", "router", "port", "acl", "load_balancer", ...
 	ResourceID string                 `json:"resource_id,omitempty"` // Required for update/delete
-	SwitchID   string                 `json:"switch_id,omitempty"`   // Required for port/acl creation
+	SwitchID   string                 `json:"switch_id,omitempty"`   // Required for port/acl/qos creation
+	RouterID   string                 `json:"router_id,omitempty"`   // Required for nat creation
 	Data       map[string]interface{} `json:"data,omitempty"`        // Resource data for create/update
 }
 
@@ -20,6 +21,14 @@ type TransactionRequest struct {
 	DryRun     bool                   `json:"dry_run,omitempty"` // If true, validate but don't execute
 }
 
+// ChunkedTransactionRequest represents a transaction too large for one OVSDB
+// transaction, applied as consecutive transactions of ChunkSize operations
+type ChunkedTransactionRequest struct {
+	Operations []TransactionOperation `json:"operations"`
+	ChunkSize  int                    `json:"chunk_size,omitempty"` // Defaults to MaxTransactionOperations
+	DryRun     bool                   `json:"dry_run,omitempty"`    // If true, validate but don't execute
+}
+
 // TransactionOperationResult represents the result of a single operation
 type TransactionOperationResult struct {
 	ID         string                 `json:"id"`
@@ -40,8 +49,39 @@ type TransactionResponse struct {
 	ExecutedAt    time.Time                    `json:"executed_at"`
 }
 
+// BulkACLRequest represents a request creating many ACLs on one switch
+type BulkACLRequest struct {
+	ACLs         []*ACL `json:"acls"`
+	AllOrNothing bool   `json:"all_or_nothing,omitempty"` // If true, any invalid ACL fails the whole request
+}
+
+// BulkACLResult represents the result for a single ACL of a bulk request
+type BulkACLResult struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	ACL     *ACL   `json:"acl,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkACLResponse represents the response for a bulk ACL request
+type BulkACLResponse struct {
+	SwitchID string          `json:"switch_id"`
+	Success  bool            `json:"success"`
+	Created  int             `json:"created"`
+	Failed   int             `json:"failed"`
+	Results  []BulkACLResult `json:"results"`
+	Error    string          `json:"error,omitempty"`
+}
+
 // Validation constants
 const (
+	// MaxTransactionOperations limits the operations of one transaction
+	MaxTransactionOperations = 100
+
+	// MaxChunkedTransactionOperations limits the operations of a chunked
+	// transaction
+	MaxChunkedTransactionOperations = 10000
+
 	// Operation types
 	OperationCreate = "create"
 	OperationUpdate = "update"
@@ -52,6 +92,13 @@ const (
 	ResourceRouter = "router"
 	ResourcePort   = "port"
 	ResourceACL    = "acl"
+
+	ResourceLoadBalancer = "load_balancer"
+	ResourceNAT          = "nat"
+	ResourcePortGroup    = "port_group"
+	ResourceAddressSet   = "address_set"
+	ResourceDHCPOptions  = "dhcp_options"
+	ResourceQoS          = "qos"
 )
 
 // ValidOperationTypes returns all valid operation types
@@ -61,7 +108,11 @@ func ValidOperationTypes() []string {
 
 // ValidResourceTypes returns all valid resource types
 func ValidResourceTypes() []string {
-	return []string{ResourceSwitch, ResourceRouter, ResourcePort, ResourceACL}
+	return []string{
+		ResourceSwitch, ResourceRouter, ResourcePort, ResourceACL,
+		ResourceLoadBalancer, ResourceNAT, ResourcePortGroup, ResourceAddressSet,
+		ResourceDHCPOptions, ResourceQoS,
+	}
 }
 
 // IsValidOperationType checks if the operation type is valid
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// VPNConnectionKey is the external ID identifying the VPN connection a
+// static route or router policy was rendered for
+const VPNConnectionKey = "ovncp:vpn_connection"
+
+// VPN tunnel states, as reported by the status collector
+const (
+	VPNTunnelUp         = "up"
+	VPNTunnelConnecting = "connecting"
+	VPNTunnelDown       = "down"
+	VPNTunnelUnknown    = "unknown" // Not reported, or not collected yet
+)
+
+// VPNConnection is an IPsec tunnel from a tenant router to an on-premises
+// peer, terminated by a gateway adjacent to OVN. Traffic between the local
+// and remote subnets, its traffic selectors, is routed through the gateway;
+// other traffic to the remote subnets is dropped by the router.
+type VPNConnection struct {
+	ID          string `json:"id"`
+	TenantID    string `json:"tenant_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	RouterID    string `json:"router_id"`
+	// GatewayIP is the IPsec gateway's address on a network of the router
+	GatewayIP string `json:"gateway_ip"`
+	// PeerAddress is the on-premises endpoint of the tunnel
+	PeerAddress string `json:"peer_address"`
+	// PSKRef references the pre-shared key, e.g. vault:secret/data/vpn#acme;
+	// the key itself is never stored
+	PSKRef        string           `json:"psk_ref"`
+	LocalSubnets  []string         `json:"local_subnets"`
+	RemoteSubnets []string         `json:"remote_subnets"`
+	Status        *VPNTunnelStatus `json:"status,omitempty"`
+	CreatedBy     string           `json:"created_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// VPNTunnelStatus is the state of a VPN connection's tunnel when it was
+// last collected
+type VPNTunnelStatus struct {
+	State         string     `json:"state"`
+	EstablishedAt *time.Time `json:"established_at,omitempty"`
+	BytesIn       uint64     `json:"bytes_in,omitempty"`
+	BytesOut      uint64     `json:"bytes_out,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+	CheckedAt     time.Time  `json:"checked_at"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of the events webhooks and notification channels are notified of
+const (
+	EventResourceCreated      = "resource.created"
+	EventResourceUpdated      = "resource.updated"
+	EventResourceDeleted      = "resource.deleted"
+	EventBackupCompleted      = "backup.completed"
+	EventBackupFailed         = "backup.failed"
+	EventRestoreCompleted     = "backup.restore_completed"
+	EventRestoreFailed        = "backup.restore_failed"
+	EventQuotaThreshold       = "quota.threshold_crossed"
+	EventQuotaExhausted       = "quota.exhausted"
+	EventOVNDisconnected      = "ovn.disconnected"
+	EventOVNReconnected       = "ovn.reconnected"
+	EventDriftDetected        = "drift.detected"
+	EventDriftResolved        = "drift.resolved"
+	EventACLRolloutPromoted   = "acl_rollout.promoted"
+	EventACLRolloutRolledBack = "acl_rollout.rolled_back"
+	EventVPNTunnelUp          = "vpn.tunnel_up"
+	EventVPNTunnelDown        = "vpn.tunnel_down"
+)
+
+// EventTypes lists the event types webhooks may subscribe to
+var EventTypes = []string{
+	EventResourceCreated, EventResourceUpdated, EventResourceDeleted,
+	EventBackupCompleted, EventBackupFailed, EventRestoreCompleted, EventRestoreFailed,
+	EventQuotaThreshold, EventQuotaExhausted,
+	EventOVNDisconnected, EventOVNReconnected,
+	EventDriftDetected, EventDriftResolved,
+	EventACLRolloutPromoted, EventACLRolloutRolledBack,
+	EventVPNTunnelUp, EventVPNTunnelDown,
+}
+
+// Event is something that happened in ovncp, POSTed to the webhooks
+// subscribed to its type
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	TenantID   string                 `json:"tenant_id,omitempty"` // Empty for system events
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// Webhook is a URL events are POSTed to. Global webhooks, without a tenant,
+// receive every event; a tenant's webhooks receive the events of its
+// resources.
+type Webhook struct {
+	ID          string    `json:"id" db:"id"`
+	TenantID    string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	URL         string    `json:"url" db:"url"`
+	Description string    `json:"description,omitempty" db:"description"`
+	Secret      string    `json:"-" db:"secret"`      // Key for the HMAC-SHA256 signature of payloads
+	Events      []string  `json:"events" db:"events"` // Event types subscribed to; empty subscribes to all
+	Enabled     bool      `json:"enabled" db:"enabled"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	CreatedBy   string    `json:"created_by" db:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Subscribes reports whether the webhook is notified of events of eventType
+func (w *Webhook) Subscribes(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending" // Awaiting its first attempt or a retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Out of attempts
+)
+
+// WebhookDelivery is an event POSTed, or to be POSTed, to a webhook
+type WebhookDelivery struct {
+	ID            string                `json:"id" db:"id"`
+	WebhookID     string                `json:"webhook_id" db:"webhook_id"`
+	EventID       string                `json:"event_id" db:"event_id"`
+	EventType     string                `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage       `json:"payload" db:"payload"`
+	Status        WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts      int                   `json:"attempts" db:"attempts"`
+	ResponseCode  int                   `json:"response_code,omitempty" db:"response_code"` // Of the last attempt
+	LastError     string                `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Secret is a secret setting kept up to date by a Rotator
+type Secret struct {
+	name      string
+	reference string
+	now       func() time.Time
+
+	mu        sync.RWMutex
+	value     string
+	previous  string    // The value before the last rotation
+	rotatedAt time.Time // When the value last changed
+}
+
+// Value returns the current value of the secret
+func (s *Secret) Value() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// Values returns the current value of the secret, followed by the value
+// it was rotated from if that was less than grace ago. Keys that verify
+// signatures accept both, so what was signed just before a rotation stays
+// valid.
+func (s *Secret) Values(grace time.Duration) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.previous != "" && s.now().Sub(s.rotatedAt) < grace {
+		return []string{s.value, s.previous}
+	}
+	return []string{s.value}
+}
+
+// set records a new value, returning whether it changed
+func (s *Secret) set(value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value == s.value {
+		return false
+	}
+	s.previous, s.value, s.rotatedAt = s.value, value, s.now()
+	return true
+}
+
+// Rotator resolves secret settings again every interval, so that secrets
+// rotated where they're kept replace the ones in use
+type Rotator struct {
+	resolver *Resolver
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	secrets []*Secret
+}
+
+// NewRotator creates a rotator resolving secrets with resolver every
+// interval
+func NewRotator(resolver *Resolver, interval time.Duration, logger *zap.Logger) *Rotator {
+	return &Rotator{
+		resolver: resolver,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Resolver returns the resolver of the rotator's secrets
+func (r *Rotator) Resolver() *Resolver {
+	return r.resolver
+}
+
+// Add resolves the setting name and keeps it up to date. Settings that
+// aren't references never change.
+func (r *Rotator) Add(ctx context.Context, name, setting string) (*Secret, error) {
+	value, err := r.resolver.Resolve(ctx, setting)
+	if err != nil {
+		return nil, err
+	}
+	secret := &Secret{name: name, reference: setting, value: value, now: r.now}
+	if IsReference(setting) {
+		r.mu.Lock()
+		r.secrets = append(r.secrets, secret)
+		r.mu.Unlock()
+	}
+	return secret, nil
+}
+
+// Refresh resolves the secrets again. A secret that can't be resolved
+// keeps its value.
+func (r *Rotator) Refresh(ctx context.Context) {
+	r.mu.Lock()
+	secrets := append([]*Secret(nil), r.secrets...)
+	r.mu.Unlock()
+
+	for _, secret := range secrets {
+		value, err := r.resolver.Resolve(ctx, secret.reference)
+		if err != nil {
+			r.logger.Warn("Failed to refresh secret", zap.String("setting", secret.name), zap.Error(err))
+			continue
+		}
+		if secret.set(value) {
+			r.logger.Info("Secret rotated", zap.String("setting", secret.name))
+		}
+	}
+}
+
+// Run refreshes the secrets every interval until ctx is done. A zero
+// interval disables it.
+func (r *Rotator) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
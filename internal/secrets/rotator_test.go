@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRotator_Refresh(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jwt-secret")
+	require.NoError(t, os.WriteFile(file, []byte("first"), 0o600))
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotator := NewRotator(NewResolver(), time.Minute, zap.NewNop())
+	rotator.now = func() time.Time { return now }
+
+	secret, err := rotator.Add(context.Background(), "JWT_SECRET", "file:"+file)
+	require.NoError(t, err)
+	literal, err := rotator.Add(context.Background(), "DB_PASSWORD", "s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, "first", secret.Value())
+	assert.Equal(t, []string{"first"}, secret.Values(time.Hour))
+
+	// A rotated secret is picked up, and the previous one honoured for the
+	// grace period
+	require.NoError(t, os.WriteFile(file, []byte("second\n"), 0o600))
+	rotator.Refresh(context.Background())
+	assert.Equal(t, "second", secret.Value())
+	assert.Equal(t, []string{"second", "first"}, secret.Values(time.Hour))
+	assert.Equal(t, "s3cret", literal.Value())
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, []string{"second"}, secret.Values(time.Hour))
+
+	// A secret that can't be read keeps its value
+	require.NoError(t, os.Remove(file))
+	rotator.Refresh(context.Background())
+	assert.Equal(t, "second", secret.Value())
+}
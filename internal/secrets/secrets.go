@@ -0,0 +1,124 @@
+// Package secrets resolves secret settings, such as database passwords and
+// signing keys, from where they're kept. A secret setting is either the
+// secret itself or a reference to it:
+//
+//	env:NAME              the environment variable NAME
+//	file:/path            the content of a file, e.g. a Kubernetes secret
+//	                      mounted in the pod, without its trailing newline
+//	vault:path#key        a key of a HashiCorp Vault KV secret, e.g.
+//	                      vault:secret/data/ovncp#db_password
+//
+// References are resolved again by a Rotator, so secrets rotated where
+// they're kept are picked up without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// ErrNotFound is returned for references to secrets that don't exist
+var ErrNotFound = errors.New("secret not found")
+
+// Provider reads secrets from where they're kept
+type Provider interface {
+	// Get returns the secret at path, whose form depends on the provider
+	Get(ctx context.Context, path string) (string, error)
+}
+
+// Resolver resolves secret settings with the provider their reference
+// names
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver of env: and file: references. Other
+// providers, such as Vault, are added with Register.
+func NewResolver() *Resolver {
+	return &Resolver{providers: map[string]Provider{
+		"env":  EnvProvider{},
+		"file": FileProvider{},
+	}}
+}
+
+// NewConfiguredResolver creates a resolver of env: and file: references,
+// and of vault: references when cfg names a Vault server. The Vault token
+// may itself be an env: or file: reference, e.g. to the file a Vault agent
+// renews, and is resolved again for each read.
+func NewConfiguredResolver(cfg *config.SecretsConfig) *Resolver {
+	resolver := NewResolver()
+	if cfg.VaultAddr != "" {
+		vaultToken := NewResolver()
+		resolver.Register("vault", NewVaultProvider(cfg.VaultAddr, cfg.VaultNamespace,
+			func(ctx context.Context) (string, error) {
+				return vaultToken.Resolve(ctx, cfg.VaultToken)
+			}))
+	}
+	return resolver
+}
+
+// Register resolves references starting with scheme: with provider
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.providers[scheme] = provider
+}
+
+// Resolve returns the secret a setting references, or the setting itself
+// when it isn't a reference
+func (r *Resolver) Resolve(ctx context.Context, setting string) (string, error) {
+	scheme, path, ok := strings.Cut(setting, ":")
+	if !ok {
+		return setting, nil
+	}
+	provider, ok := r.providers[scheme]
+	if !ok {
+		if IsReference(setting) {
+			return "", fmt.Errorf("%s: references aren't available; configure the %s provider", scheme, scheme)
+		}
+		return setting, nil
+	}
+	value, err := provider.Get(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: reference: %w", scheme, err)
+	}
+	return value, nil
+}
+
+// IsReference reports whether a setting references a secret rather than
+// being one
+func IsReference(setting string) bool {
+	scheme, _, ok := strings.Cut(setting, ":")
+	return ok && (scheme == "env" || scheme == "file" || scheme == "vault")
+}
+
+// EnvProvider reads secrets from environment variables
+type EnvProvider struct{}
+
+// Get returns the variable name
+func (EnvProvider) Get(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s isn't set", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// FileProvider reads secrets from files, such as those of Kubernetes
+// secrets mounted in the pod
+type FileProvider struct{}
+
+// Get returns the content of the file at path, without trailing newlines
+func (FileProvider) Get(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s doesn't exist", ErrNotFound, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "db-password")
+	require.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+	t.Setenv("OVNCP_TEST_SECRET", "from-env")
+
+	tests := []struct {
+		name    string
+		setting string
+		want    string
+		wantErr error
+	}{
+		{name: "literal", setting: "s3cret", want: "s3cret"},
+		{name: "literal with a colon", setting: "pass:word", want: "pass:word"},
+		{name: "environment variable", setting: "env:OVNCP_TEST_SECRET", want: "from-env"},
+		{name: "file", setting: "file:" + file, want: "from-file"},
+		{name: "missing variable", setting: "env:OVNCP_TEST_UNSET", wantErr: ErrNotFound},
+		{name: "missing file", setting: "file:" + file + ".missing", wantErr: ErrNotFound},
+	}
+
+	resolver := NewResolver()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.setting)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("vault without the provider", func(t *testing.T) {
+		_, err := resolver.Resolve(context.Background(), "vault:secret/data/ovncp#db_password")
+		assert.ErrorContains(t, err, "configure the vault provider")
+	})
+}
+
+func TestVaultProvider_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" || r.Header.Get("X-Vault-Namespace") != "ovn" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/ovncp":
+			w.Write([]byte(`{"data": {"data": {"db_password": "v2-password"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/ovncp":
+			w.Write([]byte(`{"data": {"db_password": "v1-password", "port": 5432}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewResolver()
+	resolver.Register("vault", NewVaultProvider(server.URL+"/", "ovn", func(ctx context.Context) (string, error) {
+		return "token", nil
+	}))
+
+	tests := []struct {
+		name    string
+		setting string
+		want    string
+		wantErr string
+	}{
+		{name: "KV version 2", setting: "vault:secret/data/ovncp#db_password", want: "v2-password"},
+		{name: "KV version 1", setting: "vault:kv/ovncp#db_password", want: "v1-password"},
+		{name: "missing key", setting: "vault:kv/ovncp#jwt_secret", wantErr: "has no key jwt_secret"},
+		{name: "missing secret", setting: "vault:kv/other#db_password", wantErr: "no secret at kv/other"},
+		{name: "key that isn't a string", setting: "vault:kv/ovncp#port", wantErr: "isn't a string"},
+		{name: "without a key", setting: "vault:kv/ovncp", wantErr: "must be of the form path#key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.setting)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads keys of HashiCorp Vault KV secrets, of version 1 or
+// 2 engines. Paths are "<secret path>#<key>", the secret path being the
+// API path under /v1, e.g. secret/data/ovncp#db_password for a version 2
+// engine mounted at secret/.
+type VaultProvider struct {
+	addr      string
+	namespace string
+	token     func(ctx context.Context) (string, error)
+	client    *http.Client
+}
+
+// NewVaultProvider creates a provider reading from the Vault server at addr,
+// in namespace unless it's empty. token returns the token to authenticate
+// with; it's called for each read, so a token renewed by e.g. a Vault agent
+// is picked up.
+func NewVaultProvider(addr, namespace string, token func(ctx context.Context) (string, error)) *VaultProvider {
+	return &VaultProvider{
+		addr:      strings.TrimRight(addr, "/"),
+		namespace: namespace,
+		token:     token,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Get returns a key of a secret
+func (p *VaultProvider) Get(ctx context.Context, path string) (string, error) {
+	secretPath, key, ok := strings.Cut(path, "#")
+	if !ok || secretPath == "" || key == "" {
+		return "", fmt.Errorf("vault reference %q must be of the form path#key", path)
+	}
+
+	token, err := p.token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the vault token: %w", err)
+	}
+
+	endpoint := p.addr + "/v1/" + (&url.URL{Path: strings.TrimLeft(secretPath, "/")}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from vault: %w", secretPath, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: vault has no secret at %s", ErrNotFound, secretPath)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to read %s from vault: %s", secretPath, resp.Status)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode %s from vault: %w", secretPath, err)
+	}
+
+	// Version 2 engines nest the keys under data, next to metadata
+	data := body.Data
+	if nested, ok := data["data"]; ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("failed to decode %s from vault: %w", secretPath, err)
+			}
+		}
+	}
+
+	raw, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: vault secret %s has no key %s", ErrNotFound, secretPath, key)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret %s has a key %s that isn't a string", secretPath, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrAccessGrantNotFound is returned for grants the caller's tenant
+	// doesn't have
+	ErrAccessGrantNotFound = errors.New("access grant not found")
+
+	// ErrInvalidAccessGrant is wrapped by validation errors of grants
+	ErrInvalidAccessGrant = errors.New("invalid access grant")
+
+	// ErrAccessGrantEnded is returned for revoking grants that ended
+	// already
+	ErrAccessGrantEnded = errors.New("access grant ended")
+)
+
+// AccessGrantStore keeps access grants, including those that ended.
+// *db.DB implements it.
+type AccessGrantStore interface {
+	CreateAccessGrant(ctx context.Context, grant *models.AccessGrant) error
+	// GetAccessGrant returns a grant, nil when there's none
+	GetAccessGrant(ctx context.Context, id string) (*models.AccessGrant, error)
+	// ListAccessGrants lists the grants of a tenant, or all of them when
+	// tenantID is empty, latest first
+	ListAccessGrants(ctx context.Context, tenantID string) ([]*models.AccessGrant, error)
+	// ListExpiredAccessGrants lists the active grants expiring at or
+	// before now
+	ListExpiredAccessGrants(ctx context.Context, now time.Time) ([]*models.AccessGrant, error)
+	// EndAccessGrant saves the status, end and revoker of a grant
+	EndAccessGrant(ctx context.Context, grant *models.AccessGrant) error
+}
+
+// AccessGrantRequest grants a source CIDR access to a port of a switch, a
+// service of the catalog on the switch, or a service of a port
+type AccessGrantRequest struct {
+	SwitchID   string `json:"switch_id" binding:"required"`
+	PortID     string `json:"port_id"` // UUID or name of a port of the switch
+	Service    string `json:"service"`
+	SourceCIDR string `json:"source_cidr" binding:"required"`
+	Hours      int    `json:"hours" binding:"required,min=1"`
+	Reason     string `json:"reason"`
+}
+
+// AccessGrantService grants just-in-time access: an operator allows a
+// source CIDR to reach a port or a service for a few hours, e.g. to debug
+// a VM, without changing the tenant's rules. Each grant is enforced by an
+// allow ACL tagged with the grant's ID, which is deleted when the grant
+// expires or is revoked. Grants, their revocation and their expiry are
+// published as resource events, which record them in the resources'
+// history.
+type AccessGrantService struct {
+	store       AccessGrantStore
+	ovn         OVNServiceInterface
+	catalog     *ServiceCatalog
+	events      EventPublisher
+	maxDuration time.Duration
+	interval    time.Duration
+	logger      *zap.Logger
+	now         func() time.Time
+
+	// mu keeps the expiry of a grant and its revocation from both deleting
+	// its ACL
+	mu sync.Mutex
+}
+
+// NewAccessGrantService creates a service enforcing grants of at most
+// maxDuration, unlimited when it's 0, with ovn, which checks the switches
+// belong to the caller's tenant. Expired grants are removed every
+// interval.
+func NewAccessGrantService(store AccessGrantStore, ovn OVNServiceInterface, maxDuration, interval time.Duration,
+	logger *zap.Logger) *AccessGrantService {
+	return &AccessGrantService{
+		store:       store,
+		ovn:         ovn,
+		maxDuration: maxDuration,
+		interval:    interval,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetServiceCatalog lets grants name a service of catalog
+func (s *AccessGrantService) SetServiceCatalog(catalog *ServiceCatalog) {
+	s.catalog = catalog
+}
+
+// SetEvents publishes grants being given and ending to events
+func (s *AccessGrantService) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// Grant creates the ACL allowing the access of a grant for the context's
+// tenant, and records the grant
+func (s *AccessGrantService) Grant(ctx context.Context, req *AccessGrantRequest, user string) (*models.AccessGrant, error) {
+	source, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	grant := &models.AccessGrant{
+		ID:         uuid.New().String(),
+		TenantID:   getTenantFromContext(ctx),
+		SwitchID:   req.SwitchID,
+		Service:    req.Service,
+		SourceCIDR: source.String(),
+		Reason:     req.Reason,
+		Status:     models.AccessGrantActive,
+		GrantedBy:  user,
+		GrantedAt:  now,
+		ExpiresAt:  now.Add(time.Duration(req.Hours) * time.Hour),
+	}
+	sw, err := s.ovn.GetLogicalSwitch(ctx, req.SwitchID)
+	if err != nil {
+		return nil, err
+	}
+	grant.SwitchID = sw.UUID
+	if req.PortID != "" {
+		port, err := s.findPort(ctx, sw.UUID, req.PortID)
+		if err != nil {
+			return nil, err
+		}
+		grant.PortID, grant.PortName = port.UUID, port.Name
+	}
+
+	acl, err := s.grantACL(ctx, grant, source)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.ovn.CreateACL(ctx, grant.SwitchID, acl)
+	if err != nil {
+		return nil, err
+	}
+	grant.ACLID = created.UUID
+	if err := s.store.CreateAccessGrant(ctx, grant); err != nil {
+		if err := s.ovn.DeleteACL(ctx, created.UUID); err != nil {
+			s.logger.Error("Failed to delete ACL of access grant that could not be recorded",
+				zap.String("acl_id", created.UUID),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.publish(ctx, models.EventResourceCreated, grant, user)
+	s.logger.Info("Access granted",
+		zap.String("access_grant_id", grant.ID),
+		zap.String("switch_id", grant.SwitchID),
+		zap.String("source_cidr", grant.SourceCIDR),
+		zap.Time("expires_at", grant.ExpiresAt),
+		zap.String("user", user))
+	return grant, nil
+}
+
+// Get returns a grant of the context's tenant, or any grant without a
+// tenant
+func (s *AccessGrantService) Get(ctx context.Context, id string) (*models.AccessGrant, error) {
+	grant, err := s.store.GetAccessGrant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := getTenantFromContext(ctx)
+	if grant == nil || (tenantID != "" && grant.TenantID != tenantID) {
+		return nil, fmt.Errorf("%w: %s", ErrAccessGrantNotFound, id)
+	}
+	return grant, nil
+}
+
+// List lists the grants of the context's tenant, or every tenant's
+// without one, latest first
+func (s *AccessGrantService) List(ctx context.Context) ([]*models.AccessGrant, error) {
+	return s.store.ListAccessGrants(ctx, getTenantFromContext(ctx))
+}
+
+// Revoke ends an active grant before it expires, deleting its ACL
+func (s *AccessGrantService) Revoke(ctx context.Context, id, user string) (*models.AccessGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grant, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != models.AccessGrantActive {
+		return nil, fmt.Errorf("%w: access grant %s is %s", ErrAccessGrantEnded, grant.ID, grant.Status)
+	}
+	if err := s.end(ctx, grant, models.AccessGrantRevoked, user); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Access grant revoked",
+		zap.String("access_grant_id", grant.ID),
+		zap.String("user", user))
+	return grant, nil
+}
+
+// Run removes expired grants every interval until ctx is done
+func (s *AccessGrantService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckAll(ctx); err != nil {
+				s.logger.Error("Failed to check access grants", zap.Error(err))
+			}
+		}
+	}
+}
+
+// CheckAll deletes the ACLs of the grants that expired. Those that can't
+// be deleted are tried again at the next check.
+func (s *AccessGrantService) CheckAll(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grants, err := s.store.ListExpiredAccessGrants(ctx, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	for _, grant := range grants {
+		if err := s.end(ContextWithTenant(ctx, grant.TenantID), grant, models.AccessGrantExpired, ""); err != nil {
+			s.logger.Error("Failed to remove expired access grant",
+				zap.String("access_grant_id", grant.ID),
+				zap.Error(err))
+			continue
+		}
+		s.logger.Info("Access grant expired", zap.String("access_grant_id", grant.ID))
+	}
+	return nil
+}
+
+// end deletes the ACL of grant, unless it's gone already, and records the
+// grant ending with status
+func (s *AccessGrantService) end(ctx context.Context, grant *models.AccessGrant, status, user string) error {
+	if err := s.ovn.DeleteACL(ctx, grant.ACLID); err != nil && !strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("failed to delete ACL %s of access grant: %w", grant.ACLID, err)
+	}
+
+	now := s.now().UTC()
+	grant.Status = status
+	grant.EndedAt = &now
+	grant.RevokedBy = user
+	if err := s.store.EndAccessGrant(ctx, grant); err != nil {
+		return err
+	}
+	s.publish(ctx, models.EventResourceDeleted, grant, user)
+	return nil
+}
+
+// validate checks a request and returns its source network
+func (s *AccessGrantService) validate(req *AccessGrantRequest) (*net.IPNet, error) {
+	if req.PortID == "" && req.Service == "" {
+		return nil, fmt.Errorf("%w: a port or a service is required", ErrInvalidAccessGrant)
+	}
+	_, source, err := net.ParseCIDR(req.SourceCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("%w: source_cidr must be a CIDR, got %q", ErrInvalidAccessGrant, req.SourceCIDR)
+	}
+	if ones, _ := source.Mask.Size(); ones == 0 {
+		return nil, fmt.Errorf("%w: source_cidr %s would grant access to every address", ErrInvalidAccessGrant, req.SourceCIDR)
+	}
+	if req.Hours < 1 {
+		return nil, fmt.Errorf("%w: hours must be at least 1", ErrInvalidAccessGrant)
+	}
+	if s.maxDuration > 0 && time.Duration(req.Hours)*time.Hour > s.maxDuration {
+		return nil, fmt.Errorf("%w: access may be granted for %s at most", ErrInvalidAccessGrant, s.maxDuration)
+	}
+	if req.Service != "" && s.catalog == nil {
+		return nil, fmt.Errorf("%w: services can't be granted without a service catalog", ErrInvalidAccessGrant)
+	}
+	return source, nil
+}
+
+// findPort returns the port of switchID with the UUID or name id
+func (s *AccessGrantService) findPort(ctx context.Context, switchID, id string) (*models.LogicalSwitchPort, error) {
+	ports, err := s.ovn.ListPorts(ctx, switchID)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		if port.UUID == id || port.Name == id {
+			return port, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: port %s is not on switch %s", ErrInvalidAccessGrant, id, switchID)
+}
+
+// grantACL returns the ACL allowing the traffic of grant from source to
+// the grant's port and service
+func (s *AccessGrantService) grantACL(ctx context.Context, grant *models.AccessGrant, source *net.IPNet) (*models.ACL, error) {
+	family := "ip4"
+	if source.IP.To4() == nil {
+		family = "ip6"
+	}
+	terms := []string{fmt.Sprintf("%s.src == %s", family, source)}
+	if grant.PortName != "" {
+		terms = append(terms, fmt.Sprintf("outport == %q", grant.PortName))
+	}
+	if grant.Service != "" {
+		terms = append(terms, fmt.Sprintf("service(%s)", grant.Service))
+	}
+	match := strings.Join(terms, " && ")
+
+	acl := &models.ACL{
+		Name:        "access-grant-" + grant.ID[:8],
+		Priority:    models.AccessGrantACLPriority,
+		Direction:   "to-lport",
+		Match:       match,
+		Action:      "allow-related",
+		ExternalIDs: map[string]string{models.AccessGrantKey: grant.ID},
+	}
+	if grant.Service != "" {
+		expanded, err := s.catalog.ExpandMatch(ctx, match)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAccessGrant, err)
+		}
+		acl.Match = expanded
+		acl.ExternalIDs[models.ACLMatchSourceKey] = match
+	}
+	return acl, nil
+}
+
+// publish records a grant being given or ending, along with its ACL, in
+// the resources' history
+func (s *AccessGrantService) publish(ctx context.Context, eventType string, grant *models.AccessGrant, user string) {
+	if s.events == nil {
+		return
+	}
+
+	for _, resource := range []struct{ name, id string }{
+		{"access-grants", grant.ID},
+		{"acls", grant.ACLID},
+	} {
+		data := map[string]interface{}{
+			"resource":        resource.name,
+			"resource_id":     resource.id,
+			"access_grant_id": grant.ID,
+			"source_cidr":     grant.SourceCIDR,
+			"status":          grant.Status,
+		}
+		if user != "" {
+			data["user_id"] = user
+		}
+		s.events.Publish(ctx, &models.Event{
+			Type:     eventType,
+			TenantID: grant.TenantID,
+			Data:     data,
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryAccessGrantStore keeps access grants in memory, in the order they
+// were granted
+type memoryAccessGrantStore struct {
+	grants []*models.AccessGrant
+}
+
+func (s *memoryAccessGrantStore) CreateAccessGrant(ctx context.Context, grant *models.AccessGrant) error {
+	stored := *grant
+	s.grants = append(s.grants, &stored)
+	return nil
+}
+
+func (s *memoryAccessGrantStore) GetAccessGrant(ctx context.Context, id string) (*models.AccessGrant, error) {
+	for _, grant := range s.grants {
+		if grant.ID == id {
+			stored := *grant
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryAccessGrantStore) ListAccessGrants(ctx context.Context, tenantID string) ([]*models.AccessGrant, error) {
+	grants := []*models.AccessGrant{}
+	for i := len(s.grants) - 1; i >= 0; i-- {
+		if tenantID == "" || s.grants[i].TenantID == tenantID {
+			stored := *s.grants[i]
+			grants = append(grants, &stored)
+		}
+	}
+	return grants, nil
+}
+
+func (s *memoryAccessGrantStore) ListExpiredAccessGrants(ctx context.Context, now time.Time) ([]*models.AccessGrant, error) {
+	grants := []*models.AccessGrant{}
+	for _, grant := range s.grants {
+		if grant.Status == models.AccessGrantActive && !grant.ExpiresAt.After(now) {
+			stored := *grant
+			grants = append(grants, &stored)
+		}
+	}
+	return grants, nil
+}
+
+func (s *memoryAccessGrantStore) EndAccessGrant(ctx context.Context, grant *models.AccessGrant) error {
+	for _, stored := range s.grants {
+		if stored.ID == grant.ID {
+			stored.Status, stored.EndedAt, stored.RevokedBy = grant.Status, grant.EndedAt, grant.RevokedBy
+		}
+	}
+	return nil
+}
+
+func accessGrantService(mockOVN *MockOVNService, store *memoryAccessGrantStore, now time.Time) *AccessGrantService {
+	service := NewAccessGrantService(store, mockOVN, 24*time.Hour, time.Minute, zap.NewNop())
+	service.SetServiceCatalog(NewServiceCatalog(&memoryServiceEntryStore{}, zap.NewNop()))
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestAccessGrantService_Grant(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "web").Return(&models.LogicalSwitch{UUID: "ls-1", Name: "web"}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "ls-1").Return([]*models.LogicalSwitchPort{
+		{UUID: "lsp-1", Name: "vm-1"},
+		{UUID: "lsp-2", Name: "vm-2"},
+	}, nil)
+	var acl *models.ACL
+	mockOVN.On("CreateACL", mock.Anything, "ls-1", mock.Anything).Run(func(args mock.Arguments) {
+		acl = args.Get(2).(*models.ACL)
+	}).Return(&models.ACL{UUID: "acl-1"}, nil).Once()
+	store := &memoryAccessGrantStore{}
+	events := &recordingPublisher{}
+	service := accessGrantService(mockOVN, store, now)
+	service.SetEvents(events)
+
+	grant, err := service.Grant(ctx, &AccessGrantRequest{
+		SwitchID:   "web",
+		PortID:     "vm-2",
+		Service:    "ssh",
+		SourceCIDR: "198.51.100.7/24",
+		Hours:      2,
+		Reason:     "debug boot loop",
+	}, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", grant.TenantID)
+	assert.Equal(t, "ls-1", grant.SwitchID)
+	assert.Equal(t, "lsp-2", grant.PortID)
+	assert.Equal(t, "198.51.100.0/24", grant.SourceCIDR)
+	assert.Equal(t, "acl-1", grant.ACLID)
+	assert.Equal(t, models.AccessGrantActive, grant.Status)
+	assert.Equal(t, now.Add(2*time.Hour), grant.ExpiresAt)
+	require.Len(t, store.grants, 1)
+	mockOVN.AssertExpectations(t)
+
+	require.NotNil(t, acl)
+	assert.Equal(t, `ip4.src == 198.51.100.0/24 && outport == "vm-2" && tcp.dst == 22`, acl.Match)
+	assert.Equal(t, "allow-related", acl.Action)
+	assert.Equal(t, "to-lport", acl.Direction)
+	assert.Equal(t, grant.ID, acl.ExternalIDs[models.AccessGrantKey])
+	assert.Equal(t, `ip4.src == 198.51.100.0/24 && outport == "vm-2" && service(ssh)`, acl.ExternalIDs[models.ACLMatchSourceKey])
+
+	// The grant and its ACL are recorded in the resources' history
+	require.Len(t, events.events, 2)
+	assert.Equal(t, models.EventResourceCreated, events.events[0].Type)
+	assert.Equal(t, "access-grants", events.events[0].Data["resource"])
+	assert.Equal(t, grant.ID, events.events[0].Data["resource_id"])
+	assert.Equal(t, "alice", events.events[0].Data["user_id"])
+	assert.Equal(t, "acl-1", events.events[1].Data["resource_id"])
+
+	_, err = service.Get(ContextWithTenant(context.Background(), "other"), grant.ID)
+	assert.ErrorIs(t, err, ErrAccessGrantNotFound)
+}
+
+func TestAccessGrantService_Validation(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "ls-1").Return(&models.LogicalSwitch{UUID: "ls-1"}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "ls-1").Return([]*models.LogicalSwitchPort{{UUID: "lsp-1", Name: "vm-1"}}, nil)
+	service := accessGrantService(mockOVN, &memoryAccessGrantStore{}, time.Now())
+
+	for name, req := range map[string]*AccessGrantRequest{
+		"no port or service": {SwitchID: "ls-1", SourceCIDR: "10.0.0.0/24", Hours: 1},
+		"address":            {SwitchID: "ls-1", Service: "ssh", SourceCIDR: "10.0.0.1", Hours: 1},
+		"everyone":           {SwitchID: "ls-1", Service: "ssh", SourceCIDR: "0.0.0.0/0", Hours: 1},
+		"too long":           {SwitchID: "ls-1", Service: "ssh", SourceCIDR: "10.0.0.0/24", Hours: 48},
+		"unknown service":    {SwitchID: "ls-1", Service: "gopher", SourceCIDR: "10.0.0.0/24", Hours: 1},
+		"port elsewhere":     {SwitchID: "ls-1", PortID: "vm-9", SourceCIDR: "10.0.0.0/24", Hours: 1},
+	} {
+		_, err := service.Grant(ctx, req, "alice")
+		assert.ErrorIs(t, err, ErrInvalidAccessGrant, name)
+	}
+	mockOVN.AssertNotCalled(t, "CreateACL", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccessGrantService_Expiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := &memoryAccessGrantStore{grants: []*models.AccessGrant{
+		{ID: "grant-1", TenantID: "acme", ACLID: "acl-1", Status: models.AccessGrantActive, ExpiresAt: now.Add(-time.Minute)},
+		{ID: "grant-2", TenantID: "acme", ACLID: "acl-2", Status: models.AccessGrantActive, ExpiresAt: now.Add(time.Hour)},
+		{ID: "grant-3", TenantID: "acme", ACLID: "acl-3", Status: models.AccessGrantActive, ExpiresAt: now.Add(-time.Hour)},
+		{ID: "grant-4", TenantID: "acme", ACLID: "acl-4", Status: models.AccessGrantActive, ExpiresAt: now.Add(-time.Hour)},
+	}}
+	mockOVN := new(MockOVNService)
+	mockOVN.On("DeleteACL", mock.Anything, "acl-1").Return(nil).Once()
+	mockOVN.On("DeleteACL", mock.Anything, "acl-3").Return(errors.New("ACL acl-3 not found")).Once()
+	mockOVN.On("DeleteACL", mock.Anything, "acl-4").Return(errors.New("client not connected")).Once()
+	events := &recordingPublisher{}
+	service := accessGrantService(mockOVN, store, now)
+	service.SetEvents(events)
+
+	require.NoError(t, service.CheckAll(context.Background()))
+	mockOVN.AssertExpectations(t)
+	assert.Equal(t, models.AccessGrantExpired, store.grants[0].Status)
+	assert.Equal(t, &now, store.grants[0].EndedAt)
+	assert.Equal(t, models.AccessGrantActive, store.grants[1].Status)
+	assert.Equal(t, models.AccessGrantExpired, store.grants[2].Status, "its ACL was deleted already")
+	assert.Equal(t, models.AccessGrantActive, store.grants[3].Status, "tried again at the next check")
+
+	require.Len(t, events.events, 4)
+	assert.Equal(t, models.EventResourceDeleted, events.events[0].Type)
+	assert.Equal(t, "grant-1", events.events[0].Data["resource_id"])
+	assert.Equal(t, "acme", events.events[0].TenantID)
+	assert.NotContains(t, events.events[0].Data, "user_id")
+
+	// Revoking ends an active grant only
+	mockOVN.On("DeleteACL", mock.Anything, "acl-2").Return(nil).Once()
+	ctx := ContextWithTenant(context.Background(), "acme")
+	grant, err := service.Revoke(ctx, "grant-2", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessGrantRevoked, grant.Status)
+	assert.Equal(t, "bob", store.grants[1].RevokedBy)
+	_, err = service.Revoke(ctx, "grant-2", "bob")
+	assert.ErrorIs(t, err, ErrAccessGrantEnded)
+	_, err = service.Revoke(ContextWithTenant(context.Background(), "other"), "grant-4", "bob")
+	assert.ErrorIs(t, err, ErrAccessGrantNotFound)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Entries are written in batches of at most aclLogBatchSize, and at least
+// every aclLogFlushInterval
+const (
+	aclLogBatchSize     = 500
+	aclLogFlushInterval = time.Second
+)
+
+// Default and largest number of log entries returned
+const (
+	defaultACLLogLimit = 100
+	maxACLLogLimit     = 1000
+)
+
+// ACLLogSource delivers ACL log lines until ctx is done. *ovn.ACLLogFile
+// and *ovn.ACLLogSyslog implement it.
+type ACLLogSource interface {
+	Follow(ctx context.Context, lines chan<- string) error
+}
+
+// ACLLogStore holds ACL log entries. *db.DB implements it.
+type ACLLogStore interface {
+	CreateACLLogEntries(ctx context.Context, entries []*models.ACLLogEntry) error
+	// ListACLLogEntries lists the entries matching filter, most recent
+	// first, up to its limit
+	ListACLLogEntries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error)
+	DeleteACLLogEntriesBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// ACLLogIngester stores the ACL logs ovn-controller writes, read from its
+// log files or received over syslog
+type ACLLogIngester struct {
+	store     ACLLogStore
+	sources   []ACLLogSource
+	retention time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewACLLogIngester creates an ingester storing the logs of sources and
+// keeping them for retention
+func NewACLLogIngester(store ACLLogStore, sources []ACLLogSource, retention time.Duration, logger *zap.Logger) *ACLLogIngester {
+	return &ACLLogIngester{
+		store:     store,
+		sources:   sources,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Run ingests the logs of every source until ctx is done. Expired entries
+// are deleted hourly.
+func (i *ACLLogIngester) Run(ctx context.Context) {
+	lines := make(chan string, aclLogBatchSize)
+
+	var wg sync.WaitGroup
+	for _, source := range i.sources {
+		wg.Add(1)
+		go func(source ACLLogSource) {
+			defer wg.Done()
+			if err := source.Follow(ctx, lines); err != nil {
+				i.logger.Error("Failed to follow ACL logs", zap.Error(err))
+			}
+		}(source)
+	}
+
+	flush := time.NewTicker(aclLogFlushInterval)
+	defer flush.Stop()
+	expire := time.NewTicker(time.Hour)
+	defer expire.Stop()
+
+	var batch []string
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// The context may be done; the last batch is still written
+		if err := i.Ingest(context.WithoutCancel(ctx), batch); err != nil {
+			i.logger.Error("Failed to store ACL logs", zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			write()
+			return
+		case line := <-lines:
+			batch = append(batch, line)
+			if len(batch) >= aclLogBatchSize {
+				write()
+			}
+		case <-flush.C:
+			write()
+		case <-expire.C:
+			if err := i.Expire(ctx); err != nil {
+				i.logger.Warn("Failed to delete expired ACL logs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Ingest stores the ACL logs among lines. Other lines are skipped, and
+// entries without a time are logged now.
+func (i *ACLLogIngester) Ingest(ctx context.Context, lines []string) error {
+	entries := make([]*models.ACLLogEntry, 0, len(lines))
+	for _, line := range lines {
+		entry, ok := ovn.ParseACLLogLine(line)
+		if !ok {
+			continue
+		}
+		if entry.LoggedAt.IsZero() {
+			entry.LoggedAt = i.now()
+		}
+		entry.LoggedAt = entry.LoggedAt.UTC()
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := i.store.CreateACLLogEntries(ctx, entries); err != nil {
+		return fmt.Errorf("failed to save ACL logs: %w", err)
+	}
+	return nil
+}
+
+// Expire deletes the entries past retention. A zero retention keeps them.
+func (i *ACLLogIngester) Expire(ctx context.Context) error {
+	if i.retention <= 0 {
+		return nil
+	}
+	return i.store.DeleteACLLogEntriesBefore(ctx, i.now().UTC().Add(-i.retention))
+}
+
+// Entries returns the entries matching filter, most recent first. The limit
+// defaults to 100 and is at most 1000.
+func (i *ACLLogIngester) Entries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultACLLogLimit
+	}
+	if filter.Limit > maxACLLogLimit {
+		filter.Limit = maxACLLogLimit
+	}
+
+	entries, err := i.store.ListACLLogEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*models.ACLLogEntry{}
+	}
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryACLLogStore keeps entries in memory
+type memoryACLLogStore struct {
+	entries []*models.ACLLogEntry
+}
+
+func (s *memoryACLLogStore) CreateACLLogEntries(ctx context.Context, entries []*models.ACLLogEntry) error {
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *memoryACLLogStore) ListACLLogEntries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	var entries []*models.ACLLogEntry
+	for _, entry := range s.entries {
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].LoggedAt.After(entries[j].LoggedAt) })
+	if len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+func (s *memoryACLLogStore) DeleteACLLogEntriesBefore(ctx context.Context, cutoff time.Time) error {
+	kept := s.entries[:0]
+	for _, entry := range s.entries {
+		if !entry.LoggedAt.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	s.entries = kept
+	return nil
+}
+
+func TestACLLogIngester(t *testing.T) {
+	store := &memoryACLLogStore{}
+	ingester := NewACLLogIngester(store, nil, 24*time.Hour, zap.NewNop())
+	now := time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC)
+	ingester.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, ingester.Ingest(ctx, []string{
+		`2024-01-02T15:04:05.123Z|00005|acl_log(ovn_pinctrl0)|INFO|name="allow-ssh", verdict=allow, severity=info, direction=to-lport: tcp,vlan_tci=0x0000,dl_src=0a:58:0a:00:00:01,dl_dst=0a:58:0a:00:00:02,nw_src=10.0.0.1,nw_dst=10.0.0.2,nw_tos=0,nw_ecn=0,nw_ttl=64,tp_src=43512,tp_dst=22,tcp_flags=syn`,
+		`2024-01-02T15:04:06.000Z|00006|acl_log(ovn_pinctrl0)|INFO|name="allow-ssh", verdict=drop, severity=alert, direction=from-lport: tcp6,vlan_tci=0x0000,ipv6_src=fd00::1,ipv6_dst=fd00::2,tp_src=40000,tp_dst=22`,
+		`2024-01-02T15:04:07.000Z|00007|binding|INFO|Claiming lport sw0-port1 for this chassis.`,
+		// Received over syslog, without a time of its own
+		`<134>ovn-controller: acl_log(ovn_pinctrl0)|INFO|name="<unnamed>", verdict=reject, severity=warning, direction=to-lport: udp,nw_src=10.0.0.3,nw_dst=10.0.0.4,tp_src=5353,tp_dst=53`,
+	}))
+	require.Len(t, store.entries, 3, "lines that aren't ACL logs are skipped")
+
+	ssh := store.entries[0]
+	assert.Equal(t, "allow-ssh", ssh.ACLName)
+	assert.Equal(t, "allow", ssh.Verdict)
+	assert.Equal(t, "info", ssh.Severity)
+	assert.Equal(t, "to-lport", ssh.Direction)
+	assert.Equal(t, "tcp", ssh.Protocol)
+	assert.Equal(t, "10.0.0.1", ssh.SrcIP)
+	assert.Equal(t, "10.0.0.2", ssh.DstIP)
+	assert.Equal(t, 43512, ssh.SrcPort)
+	assert.Equal(t, 22, ssh.DstPort)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC), ssh.LoggedAt)
+
+	assert.Equal(t, "fd00::1", store.entries[1].SrcIP)
+	assert.Empty(t, store.entries[2].ACLName)
+	assert.Equal(t, now, store.entries[2].LoggedAt)
+
+	entries, err := ingester.Entries(ctx, &models.ACLLogFilter{ACLName: "allow-ssh", To: now, Protocol: "tcp"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "tcp matches IPv6 TCP too")
+	assert.Equal(t, "drop", entries[0].Verdict, "the most recent comes first")
+
+	entries, err = ingester.Entries(ctx, &models.ACLLogFilter{ACLName: "allow-ssh", To: now, Verdict: "allow", DstPort: 22})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = ingester.Entries(ctx, &models.ACLLogFilter{ACLName: "allow-ssh", To: now, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Entries past retention are deleted
+	now = now.Add(23*time.Hour + 30*time.Minute)
+	require.NoError(t, ingester.Expire(ctx))
+	assert.Len(t, store.entries, 1)
+}
+
+func TestACLLogIngester_NoEntries(t *testing.T) {
+	ingester := NewACLLogIngester(&memoryACLLogStore{}, nil, 0, zap.NewNop())
+
+	entries, err := ingester.Entries(context.Background(), &models.ACLLogFilter{ACLName: "allow-ssh", To: time.Now()})
+	require.NoError(t, err)
+	assert.NotNil(t, entries)
+}
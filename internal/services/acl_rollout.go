@@ -0,0 +1,481 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ACLRolloutIDKey is the external ID naming the rollout an ACL was created
+// by. It's kept once the rollout is promoted.
+const ACLRolloutIDKey = "ovncp:acl_rollout"
+
+// ACLRolloutObserveAction is the action of the rules of a rollout in
+// observe mode, logged
+const ACLRolloutObserveAction = "allow-related"
+
+// aclRolloutSampleCount is how many of the latest flows logged by the rules
+// of a rollout are shown with it
+const aclRolloutSampleCount = 10
+
+var (
+	// ErrACLRolloutNotFound is returned for unknown rollout IDs
+	ErrACLRolloutNotFound = errors.New("ACL rollout not found")
+
+	// ErrInvalidACLRollout is wrapped by validation errors of rollouts
+	ErrInvalidACLRollout = errors.New("invalid ACL rollout")
+
+	// ErrACLRolloutStatus is wrapped by errors for rollouts that were
+	// already promoted or rolled back
+	ErrACLRolloutStatus = errors.New("ACL rollout status does not allow this action")
+)
+
+// ACLRolloutStore persists ACL rollouts. *db.DB implements it.
+type ACLRolloutStore interface {
+	// SaveACLRollout creates or replaces a rollout
+	SaveACLRollout(ctx context.Context, rollout *models.ACLRollout) error
+	// GetACLRollout returns a rollout, nil when there's none with the ID
+	GetACLRollout(ctx context.Context, id string) (*models.ACLRollout, error)
+	// ListACLRollouts lists the rollouts of tenantID, or all of them when
+	// it's empty, oldest first
+	ListACLRollouts(ctx context.Context, tenantID string) ([]*models.ACLRollout, error)
+}
+
+// ACLRolloutSpec describes the ACLs to roll out to a switch
+type ACLRolloutSpec struct {
+	SwitchID string
+	// ACLs are the rules as they're enforced once promoted
+	ACLs []*models.ACL
+	// BakePeriod is how long the rules are observed, the default when 0
+	BakePeriod time.Duration
+	Thresholds models.ACLRolloutThresholds
+}
+
+// ACLRolloutService stages risky ACL changes. The rules of a rollout are
+// observed for a bake period, then promoted to enforce their action or
+// rolled back automatically, depending on the traffic they matched.
+type ACLRolloutService struct {
+	store             ACLRolloutStore
+	ovn               OVNServiceInterface
+	hits              ACLHitSource
+	logs              ObservedFlowSource
+	interval          time.Duration
+	defaultBakePeriod time.Duration
+	maxBakePeriod     time.Duration
+	events            EventPublisher
+	maintenance       *MaintenanceService
+	logger            *zap.Logger
+	now               func() time.Time
+
+	// mu keeps a rollout from being promoted and rolled back at once
+	mu sync.Mutex
+}
+
+// NewACLRolloutService creates a service keeping rollouts in store and
+// checking them every interval. hits and logs are nil when ACL statistics
+// aren't recorded or ACL logs aren't ingested, which leaves the thresholds
+// they back unavailable. Rules are observed for defaultBakePeriod unless
+// asked otherwise, and at most maxBakePeriod.
+func NewACLRolloutService(store ACLRolloutStore, ovn OVNServiceInterface, hits ACLHitSource, logs ObservedFlowSource,
+	interval, defaultBakePeriod, maxBakePeriod time.Duration, logger *zap.Logger) *ACLRolloutService {
+	return &ACLRolloutService{
+		store:             store,
+		ovn:               ovn,
+		hits:              hits,
+		logs:              logs,
+		interval:          interval,
+		defaultBakePeriod: defaultBakePeriod,
+		maxBakePeriod:     maxBakePeriod,
+		logger:            logger,
+		now:               time.Now,
+	}
+}
+
+// SetEvents publishes the promotions and rollbacks of rollouts to events
+func (s *ACLRolloutService) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// SetMaintenance holds promotions and rollbacks while the rollout's tenant
+// is in maintenance mode
+func (s *ACLRolloutService) SetMaintenance(maintenance *MaintenanceService) {
+	s.maintenance = maintenance
+}
+
+// Start creates the ACLs of spec in observe mode
+func (s *ACLRolloutService) Start(ctx context.Context, spec *ACLRolloutSpec, user string) (*models.ACLRollout, error) {
+	bakePeriod := spec.BakePeriod
+	if bakePeriod == 0 {
+		bakePeriod = s.defaultBakePeriod
+	}
+	if err := s.validate(spec, bakePeriod); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	rollout := &models.ACLRollout{
+		ID:         uuid.New().String(),
+		TenantID:   getTenantFromContext(ctx),
+		SwitchID:   spec.SwitchID,
+		Thresholds: spec.Thresholds,
+		Status:     models.ACLRolloutObserving,
+		CreatedBy:  user,
+		StartedAt:  now,
+		BakeUntil:  now.Add(bakePeriod),
+	}
+
+	// ACL logs are attributed by name, so every rule needs one
+	observed := make([]*models.ACL, len(spec.ACLs))
+	for i, acl := range spec.ACLs {
+		rule := *acl
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rollout-%s-%d", rollout.ID[:8], i+1)
+		}
+		rule.ExternalIDs = make(map[string]string, len(acl.ExternalIDs)+1)
+		for k, v := range acl.ExternalIDs {
+			rule.ExternalIDs[k] = v
+		}
+		rule.ExternalIDs[ACLRolloutIDKey] = rollout.ID
+		rollout.Rules = append(rollout.Rules, &rule)
+
+		observe := rule
+		observe.Action = ACLRolloutObserveAction
+		observe.Log = true
+		if observe.Severity == "" {
+			observe.Severity = "info"
+		}
+		observed[i] = &observe
+	}
+
+	created, err := s.ovn.CreateACLs(ctx, spec.SwitchID, observed)
+	if err != nil {
+		return nil, err
+	}
+	for i, acl := range created {
+		rollout.Rules[i].UUID = acl.UUID
+		rollout.Rules[i].CreatedAt, rollout.Rules[i].UpdatedAt = acl.CreatedAt, acl.UpdatedAt
+	}
+	if err := s.store.SaveACLRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("ACL rollout started",
+		zap.String("rollout_id", rollout.ID),
+		zap.String("switch_id", rollout.SwitchID),
+		zap.Int("rules", len(rollout.Rules)),
+		zap.Time("bake_until", rollout.BakeUntil))
+	return rollout, nil
+}
+
+func (s *ACLRolloutService) validate(spec *ACLRolloutSpec, bakePeriod time.Duration) error {
+	switch {
+	case spec.SwitchID == "":
+		return fmt.Errorf("%w: switch_id is required", ErrInvalidACLRollout)
+	case len(spec.ACLs) == 0:
+		return fmt.Errorf("%w: at least one ACL is required", ErrInvalidACLRollout)
+	case bakePeriod <= 0 || bakePeriod > s.maxBakePeriod:
+		return fmt.Errorf("%w: bake_period must be positive and at most %s", ErrInvalidACLRollout, s.maxBakePeriod)
+	case spec.Thresholds.MaxHits != nil && s.hits == nil:
+		return fmt.Errorf("%w: max_hits requires ACL statistics to be recorded", ErrInvalidACLRollout)
+	case spec.Thresholds.MaxLoggedFlows != nil && s.logs == nil:
+		return fmt.Errorf("%w: max_logged_flows requires ACL logs to be ingested", ErrInvalidACLRollout)
+	}
+	return nil
+}
+
+// Get returns a rollout of the context's tenant, observed now while it's in
+// progress
+func (s *ACLRolloutService) Get(ctx context.Context, id string) (*models.ACLRollout, error) {
+	rollout, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status == models.ACLRolloutObserving {
+		if rollout.Observation, err = s.observe(ctx, rollout); err != nil {
+			return nil, err
+		}
+	}
+	return rollout, nil
+}
+
+// List lists the rollouts, those of the context's tenant only within a
+// tenant's context, latest first
+func (s *ACLRolloutService) List(ctx context.Context) ([]*models.ACLRollout, error) {
+	rollouts, err := s.store.ListACLRollouts(ctx, getTenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(rollouts, func(i, j int) bool { return rollouts[i].StartedAt.After(rollouts[j].StartedAt) })
+	return rollouts, nil
+}
+
+// Promote enforces the rules of a rollout before its bake period ends
+func (s *ACLRolloutService) Promote(ctx context.Context, id, user string) (*models.ACLRollout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rollout, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Observation, err = s.observe(ctx, rollout); err != nil {
+		return nil, err
+	}
+	if err := s.promote(ctx, rollout, user); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Rollback deletes the rules of a rollout
+func (s *ACLRolloutService) Rollback(ctx context.Context, id, user, reason string) (*models.ACLRollout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rollout, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Observation, err = s.observe(ctx, rollout); err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "rolled back by " + user
+	}
+	if err := s.rollback(ctx, rollout, user, reason); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// Run checks the rollouts in progress every interval until ctx is done. A
+// zero interval disables it.
+func (s *ACLRolloutService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckAll(ctx); err != nil {
+				s.logger.Error("Failed to check ACL rollouts", zap.Error(err))
+			}
+		}
+	}
+}
+
+// CheckAll checks every rollout in progress: those whose rules matched more
+// traffic than their thresholds allow are rolled back, and those past
+// their bake period promoted. Rollouts of tenants in maintenance mode are
+// left as they are.
+func (s *ACLRolloutService) CheckAll(ctx context.Context) error {
+	rollouts, err := s.store.ListACLRollouts(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rollout := range rollouts {
+		if rollout.Status != models.ACLRolloutObserving {
+			continue
+		}
+		if err := s.check(ContextWithTenant(ctx, rollout.TenantID), rollout); err != nil {
+			s.logger.Error("Failed to check ACL rollout",
+				zap.String("rollout_id", rollout.ID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *ACLRolloutService) check(ctx context.Context, rollout *models.ACLRollout) error {
+	observation, err := s.observe(ctx, rollout)
+	if err != nil {
+		return err
+	}
+	rollout.Observation = observation
+
+	if s.maintenance != nil {
+		if mode, err := s.maintenance.Frozen(ctx, rollout.TenantID); err != nil || mode != nil {
+			return s.store.SaveACLRollout(ctx, rollout)
+		}
+	}
+
+	if reason := exceededThreshold(rollout.Thresholds, observation); reason != "" {
+		return s.rollback(ctx, rollout, "", reason)
+	}
+	if !s.now().Before(rollout.BakeUntil) {
+		return s.promote(ctx, rollout, "")
+	}
+	return s.store.SaveACLRollout(ctx, rollout)
+}
+
+// exceededThreshold tells how an observation exceeds thresholds, empty when
+// it doesn't
+func exceededThreshold(thresholds models.ACLRolloutThresholds, observation *models.ACLRolloutObservation) string {
+	if thresholds.MaxHits != nil && observation.Hits > *thresholds.MaxHits {
+		return fmt.Sprintf("rules matched %d packets, more than max_hits %d", observation.Hits, *thresholds.MaxHits)
+	}
+	if thresholds.MaxLoggedFlows != nil && observation.LoggedFlows > *thresholds.MaxLoggedFlows {
+		return fmt.Sprintf("rules logged %d flows, more than max_logged_flows %d", observation.LoggedFlows, *thresholds.MaxLoggedFlows)
+	}
+	return ""
+}
+
+// observe collects the traffic the rules of a rollout matched since it
+// started
+func (s *ACLRolloutService) observe(ctx context.Context, rollout *models.ACLRollout) (*models.ACLRolloutObservation, error) {
+	now := s.now().UTC()
+	observation := &models.ACLRolloutObservation{
+		HitsRecorded: s.hits != nil,
+		LogsIngested: s.logs != nil,
+		ObservedAt:   now,
+	}
+
+	for _, rule := range rollout.Rules {
+		if s.hits != nil {
+			stats, err := s.hits.Stats(ctx, rule.UUID, rollout.StartedAt, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read statistics of ACL %s: %w", rule.Name, err)
+			}
+			observation.Hits += stats.PacketsInRange
+		}
+		if s.logs != nil {
+			entries, err := s.logs.Entries(ctx, &models.ACLLogFilter{ACLName: rule.Name, From: rollout.StartedAt, Limit: maxACLLogLimit})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read logs of ACL %s: %w", rule.Name, err)
+			}
+			observation.LoggedFlows += len(entries)
+			observation.Samples = append(observation.Samples, entries...)
+		}
+	}
+
+	if observation.LoggedFlows > maxACLLogLimit {
+		observation.LoggedFlows = maxACLLogLimit
+	}
+	sort.SliceStable(observation.Samples, func(i, j int) bool {
+		return observation.Samples[i].LoggedAt.After(observation.Samples[j].LoggedAt)
+	})
+	if len(observation.Samples) > aclRolloutSampleCount {
+		observation.Samples = observation.Samples[:aclRolloutSampleCount]
+	}
+	return observation, nil
+}
+
+// promote sets the rules of a rollout to their own action. Rules already
+// promoted are promoted again, so a promotion that failed can be retried.
+func (s *ACLRolloutService) promote(ctx context.Context, rollout *models.ACLRollout, user string) error {
+	if rollout.Status != models.ACLRolloutObserving {
+		return fmt.Errorf("%w: the rollout is %s", ErrACLRolloutStatus, rollout.Status)
+	}
+
+	for _, rule := range rollout.Rules {
+		update := &models.ACL{Action: rule.Action, Log: rule.Log, Severity: rule.Severity}
+		if _, err := s.ovn.UpdateACL(ctx, rule.UUID, update); err != nil {
+			return fmt.Errorf("failed to promote ACL %s: %w", rule.Name, err)
+		}
+	}
+
+	s.end(rollout, models.ACLRolloutPromoted, user, "")
+	if err := s.store.SaveACLRollout(ctx, rollout); err != nil {
+		return err
+	}
+	s.logger.Info("ACL rollout promoted",
+		zap.String("rollout_id", rollout.ID),
+		zap.String("promoted_by", user))
+	s.publish(ctx, models.EventACLRolloutPromoted, rollout)
+	return nil
+}
+
+// rollback deletes the rules of a rollout. Rules already deleted are
+// skipped, so a rollback that failed can be retried.
+func (s *ACLRolloutService) rollback(ctx context.Context, rollout *models.ACLRollout, user, reason string) error {
+	if rollout.Status != models.ACLRolloutObserving {
+		return fmt.Errorf("%w: the rollout is %s", ErrACLRolloutStatus, rollout.Status)
+	}
+
+	for _, rule := range rollout.Rules {
+		if err := s.ovn.DeleteACL(ctx, rule.UUID); err != nil && !strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("failed to roll back ACL %s: %w", rule.Name, err)
+		}
+	}
+
+	s.end(rollout, models.ACLRolloutRolledBack, user, reason)
+	if err := s.store.SaveACLRollout(ctx, rollout); err != nil {
+		return err
+	}
+	s.logger.Warn("ACL rollout rolled back",
+		zap.String("rollout_id", rollout.ID),
+		zap.String("rolled_back_by", user),
+		zap.String("reason", reason))
+	s.publish(ctx, models.EventACLRolloutRolledBack, rollout)
+	return nil
+}
+
+func (s *ACLRolloutService) end(rollout *models.ACLRollout, status, user, reason string) {
+	endedAt := s.now().UTC()
+	rollout.Status = status
+	rollout.Reason = reason
+	rollout.EndedAt = &endedAt
+	rollout.EndedBy = user
+}
+
+func (s *ACLRolloutService) publish(ctx context.Context, eventType string, rollout *models.ACLRollout) {
+	if s.events == nil {
+		return
+	}
+
+	names := make([]string, len(rollout.Rules))
+	for i, rule := range rollout.Rules {
+		names[i] = rule.Name
+	}
+	data := map[string]interface{}{
+		"rollout_id": rollout.ID,
+		"switch_id":  rollout.SwitchID,
+		"acls":       names,
+	}
+	if rollout.Observation != nil {
+		data["hits"] = rollout.Observation.Hits
+		data["logged_flows"] = rollout.Observation.LoggedFlows
+	}
+	if rollout.Reason != "" {
+		data["reason"] = rollout.Reason
+	}
+	s.events.Publish(ctx, &models.Event{
+		Type:     eventType,
+		TenantID: rollout.TenantID,
+		Data:     data,
+	})
+}
+
+// get returns a rollout of the context's tenant
+func (s *ACLRolloutService) get(ctx context.Context, id string) (*models.ACLRollout, error) {
+	rollout, err := s.store.GetACLRollout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout == nil {
+		return nil, ErrACLRolloutNotFound
+	}
+	if tenantID := getTenantFromContext(ctx); tenantID != "" && rollout.TenantID != tenantID {
+		return nil, ErrACLRolloutNotFound
+	}
+	return rollout, nil
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryACLRolloutStore keeps ACL rollouts in memory
+type memoryACLRolloutStore struct {
+	rollouts map[string]*models.ACLRollout
+}
+
+func (s *memoryACLRolloutStore) SaveACLRollout(ctx context.Context, rollout *models.ACLRollout) error {
+	stored := *rollout
+	s.rollouts[rollout.ID] = &stored
+	return nil
+}
+
+func (s *memoryACLRolloutStore) GetACLRollout(ctx context.Context, id string) (*models.ACLRollout, error) {
+	rollout, ok := s.rollouts[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := *rollout
+	return &stored, nil
+}
+
+func (s *memoryACLRolloutStore) ListACLRollouts(ctx context.Context, tenantID string) ([]*models.ACLRollout, error) {
+	rollouts := []*models.ACLRollout{}
+	for _, rollout := range s.rollouts {
+		if tenantID == "" || rollout.TenantID == tenantID {
+			stored := *rollout
+			rollouts = append(rollouts, &stored)
+		}
+	}
+	return rollouts, nil
+}
+
+// newTestACLRollouts creates a service observing a drop rule on switch
+// sw-web, created as acl-1, and the publisher of its events
+func newTestACLRollouts(t *testing.T, hits fakeACLHits, logs fakeObservedFlows) (*ACLRolloutService, *MockOVNService, *recordingPublisher, *time.Time) {
+	store := &memoryACLRolloutStore{rollouts: map[string]*models.ACLRollout{}}
+
+	mockOVN := new(MockOVNService)
+	mockOVN.On("CreateACLs", mock.Anything, "sw-web", mock.MatchedBy(func(acls []*models.ACL) bool {
+		return len(acls) == 1 && acls[0].Action == ACLRolloutObserveAction && acls[0].Log
+	})).Return([]*models.ACL{{UUID: "acl-1"}}, nil)
+
+	// Without them, the service doesn't have the sources at all
+	var hitSource ACLHitSource
+	if hits != nil {
+		hitSource = hits
+	}
+	var logSource ObservedFlowSource
+	if logs != nil {
+		logSource = logs
+	}
+	service := NewACLRolloutService(store, mockOVN, hitSource, logSource, time.Minute, time.Hour, 24*time.Hour, zap.NewNop())
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	events := &recordingPublisher{}
+	service.SetEvents(events)
+	return service, mockOVN, events, &now
+}
+
+func dropTelnet() *ACLRolloutSpec {
+	maxHits := uint64(100)
+	return &ACLRolloutSpec{
+		SwitchID:   "sw-web",
+		ACLs:       []*models.ACL{{Priority: 2000, Direction: "to-lport", Match: "tcp.dst == 23", Action: "drop"}},
+		Thresholds: models.ACLRolloutThresholds{MaxHits: &maxHits},
+	}
+}
+
+func TestACLRolloutService_Start(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service, mockOVN, _, now := newTestACLRollouts(t, fakeACLHits{"acl-1": 7}, nil)
+
+	rollout, err := service.Start(ctx, dropTelnet(), "alice")
+	require.NoError(t, err)
+	mockOVN.AssertExpectations(t)
+
+	assert.Equal(t, models.ACLRolloutObserving, rollout.Status)
+	assert.Equal(t, "acme", rollout.TenantID)
+	assert.Equal(t, now.Add(time.Hour), rollout.BakeUntil, "the default bake period")
+	require.Len(t, rollout.Rules, 1)
+	rule := rollout.Rules[0]
+	assert.Equal(t, "acl-1", rule.UUID)
+	assert.Equal(t, "drop", rule.Action, "rules keep the action they're promoted to")
+	assert.Equal(t, "rollout-"+rollout.ID[:8]+"-1", rule.Name, "unnamed rules are named for their logs")
+	assert.Equal(t, rollout.ID, rule.ExternalIDs[ACLRolloutIDKey])
+
+	got, err := service.Get(ctx, rollout.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Observation)
+	assert.Equal(t, uint64(7), got.Observation.Hits)
+	assert.True(t, got.Observation.HitsRecorded)
+	assert.False(t, got.Observation.LogsIngested)
+
+	_, err = service.Get(ContextWithTenant(context.Background(), "other"), rollout.ID)
+	assert.ErrorIs(t, err, ErrACLRolloutNotFound)
+
+	// Thresholds need what backs them
+	spec := dropTelnet()
+	maxFlows := 10
+	spec.Thresholds.MaxLoggedFlows = &maxFlows
+	_, err = service.Start(ctx, spec, "alice")
+	assert.ErrorIs(t, err, ErrInvalidACLRollout)
+	spec = dropTelnet()
+	spec.BakePeriod = 48 * time.Hour
+	_, err = service.Start(ctx, spec, "alice")
+	assert.ErrorIs(t, err, ErrInvalidACLRollout)
+}
+
+func TestACLRolloutService_CheckAll(t *testing.T) {
+	ctx := context.Background()
+	hits := fakeACLHits{"acl-1": 40}
+	service, mockOVN, events, now := newTestACLRollouts(t, hits, nil)
+	rollout, err := service.Start(ContextWithTenant(ctx, "acme"), dropTelnet(), "alice")
+	require.NoError(t, err)
+
+	// Within thresholds during the bake period, the rules are observed
+	require.NoError(t, service.CheckAll(ctx))
+	stored, err := service.get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ACLRolloutObserving, stored.Status)
+	assert.Equal(t, uint64(40), stored.Observation.Hits)
+
+	// Then promoted once it's over
+	*now = now.Add(time.Hour)
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", &models.ACL{Action: "drop"}).Return(&models.ACL{}, nil)
+	require.NoError(t, service.CheckAll(ctx))
+	stored, err = service.get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ACLRolloutPromoted, stored.Status)
+	assert.Empty(t, stored.EndedBy)
+	mockOVN.AssertExpectations(t)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.EventACLRolloutPromoted, events.events[0].Type)
+	assert.Equal(t, "acme", events.events[0].TenantID)
+
+	_, err = service.Rollback(ctx, rollout.ID, "alice", "")
+	assert.ErrorIs(t, err, ErrACLRolloutStatus)
+}
+
+func TestACLRolloutService_AutoRollback(t *testing.T) {
+	ctx := context.Background()
+	logged := &models.ACLLogEntry{ACLName: "drop-telnet", Verdict: "allow", SrcIP: "10.0.0.5", DstPort: 23}
+	hits := fakeACLHits{"acl-1": 250}
+	service, mockOVN, events, _ := newTestACLRollouts(t, hits, fakeObservedFlows{"drop-telnet": {logged}})
+	spec := dropTelnet()
+	spec.ACLs[0].Name = "drop-telnet"
+	rollout, err := service.Start(ctx, spec, "alice")
+	require.NoError(t, err)
+
+	// Matching more traffic than allowed rolls the rules back before the
+	// bake period ends
+	mockOVN.On("DeleteACL", mock.Anything, "acl-1").Return(nil)
+	require.NoError(t, service.CheckAll(ctx))
+	stored, err := service.get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ACLRolloutRolledBack, stored.Status)
+	assert.Equal(t, "rules matched 250 packets, more than max_hits 100", stored.Reason)
+	assert.Equal(t, 1, stored.Observation.LoggedFlows)
+	assert.Equal(t, []*models.ACLLogEntry{logged}, stored.Observation.Samples)
+	mockOVN.AssertExpectations(t)
+
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.EventACLRolloutRolledBack, events.events[0].Type)
+	assert.Equal(t, stored.Reason, events.events[0].Data["reason"])
+}
+
+func TestACLRolloutService_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	service, _, events, now := newTestACLRollouts(t, fakeACLHits{"acl-1": 250}, nil)
+	maintenance := NewMaintenanceService(&memoryMaintenanceStore{modes: map[string]*models.MaintenanceMode{}}, zap.NewNop())
+	require.NoError(t, maintenance.Set(ctx, &models.MaintenanceMode{Frozen: true}))
+	service.SetMaintenance(maintenance)
+	rollout, err := service.Start(ctx, dropTelnet(), "alice")
+	require.NoError(t, err)
+
+	// Neither rolled back nor promoted while writes are frozen
+	*now = now.Add(2 * time.Hour)
+	require.NoError(t, service.CheckAll(ctx))
+	stored, err := service.get(ctx, rollout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ACLRolloutObserving, stored.Status)
+	assert.Empty(t, events.events)
+}
+
+func TestACLRolloutService_Promote(t *testing.T) {
+	ctx := context.Background()
+	service, mockOVN, _, _ := newTestACLRollouts(t, nil, nil)
+	spec := dropTelnet()
+	spec.Thresholds = models.ACLRolloutThresholds{}
+	rollout, err := service.Start(ctx, spec, "alice")
+	require.NoError(t, err)
+
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", &models.ACL{Action: "drop"}).Return(&models.ACL{}, nil)
+	promoted, err := service.Promote(ctx, rollout.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.ACLRolloutPromoted, promoted.Status)
+	assert.Equal(t, "bob", promoted.EndedBy)
+	assert.False(t, promoted.Observation.HitsRecorded)
+
+	rollouts, err := service.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, rollouts, 1)
+	assert.Equal(t, models.ACLRolloutPromoted, rollouts[0].Status)
+}
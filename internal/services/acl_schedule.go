@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrACLScheduleNotFound is returned for ACLs without a schedule
+	ErrACLScheduleNotFound = errors.New("ACL schedule not found")
+
+	// ErrInvalidACLSchedule is wrapped by validation errors of schedules
+	ErrInvalidACLSchedule = errors.New("invalid ACL schedule")
+)
+
+// scheduleWeekdays are the days of ACL schedule windows
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ACLScheduleStore keeps ACL schedules and the record of their toggles,
+// shared by the API's replicas. *db.DB implements it.
+type ACLScheduleStore interface {
+	// SaveACLSchedule creates or replaces the schedule of an ACL
+	SaveACLSchedule(ctx context.Context, schedule *models.ACLSchedule) error
+	// GetACLSchedule returns the schedule of an ACL, nil when it has none
+	GetACLSchedule(ctx context.Context, aclID string) (*models.ACLSchedule, error)
+	ListACLSchedules(ctx context.Context, tenantID string) ([]*models.ACLSchedule, error)
+	DeleteACLSchedule(ctx context.Context, aclID string) error
+	CreateACLScheduleToggle(ctx context.Context, toggle *models.ACLScheduleToggle) error
+	// ListACLScheduleToggles lists the toggles of an ACL, oldest first
+	ListACLScheduleToggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error)
+}
+
+// ACLScheduleRequest sets the activation windows of an ACL
+type ACLScheduleRequest struct {
+	Windows []models.ACLScheduleWindow `json:"windows" binding:"required"`
+	// Timezone is the IANA name of the windows' time zone, UTC when empty
+	Timezone string `json:"timezone"`
+}
+
+// ACLScheduleService activates ACLs within time windows only, e.g. to
+// allow backups from 02:00 to 04:00. Outside its windows, a scheduled ACL
+// is disabled by replacing its match with one that never matches, which
+// keeps its UUID; its own match is restored when a window starts. Each
+// toggle is recorded.
+type ACLScheduleService struct {
+	store    ACLScheduleStore
+	ovn      OVNServiceInterface
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	// mu keeps the scheduler and changes of schedules from toggling an
+	// ACL at once
+	mu sync.Mutex
+}
+
+// NewACLScheduleService creates a service keeping schedules in store and
+// toggling ACLs at the boundaries of their windows, checked every interval
+func NewACLScheduleService(store ACLScheduleStore, ovn OVNServiceInterface, interval time.Duration, logger *zap.Logger) *ACLScheduleService {
+	return &ACLScheduleService{
+		store:    store,
+		ovn:      ovn,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Set sets the activation windows of an ACL of the context's tenant,
+// enabling or disabling it right away depending on the time
+func (s *ACLScheduleService) Set(ctx context.Context, aclID string, req *ACLScheduleRequest, user string) (*models.ACLSchedule, error) {
+	loc, err := validateACLSchedule(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acl, err := s.ovn.GetACL(ctx, aclID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.store.GetACLSchedule(ctx, acl.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if schedule == nil {
+		if acl.Match == models.ACLDisabledMatch {
+			return nil, fmt.Errorf("%w: the ACL never matches", ErrInvalidACLSchedule)
+		}
+		schedule = &models.ACLSchedule{
+			ACLID:     acl.UUID,
+			TenantID:  getTenantFromContext(ctx),
+			Match:     acl.Match,
+			Active:    true,
+			CreatedBy: user,
+			CreatedAt: now,
+		}
+	}
+	schedule.Windows = req.Windows
+	schedule.Timezone = loc.String()
+	schedule.UpdatedAt = now
+
+	if active := aclScheduleActive(schedule.Windows, loc, now); active != schedule.Active {
+		if err := s.toggle(ctx, schedule, acl, active, models.ACLToggleScheduleSet, user); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.store.SaveACLSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("ACL schedule set",
+		zap.String("acl_id", schedule.ACLID),
+		zap.Bool("active", schedule.Active),
+		zap.String("user", user))
+	return schedule, nil
+}
+
+// Get returns the schedule of an ACL of the context's tenant
+func (s *ACLScheduleService) Get(ctx context.Context, aclID string) (*models.ACLSchedule, error) {
+	schedule, err := s.store.GetACLSchedule(ctx, aclID)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := getTenantFromContext(ctx)
+	if schedule == nil || (tenantID != "" && schedule.TenantID != tenantID) {
+		return nil, fmt.Errorf("%w: %s", ErrACLScheduleNotFound, aclID)
+	}
+	return schedule, nil
+}
+
+// List lists the schedules of the context's tenant, or every tenant's
+// without one
+func (s *ACLScheduleService) List(ctx context.Context) ([]*models.ACLSchedule, error) {
+	return s.store.ListACLSchedules(ctx, getTenantFromContext(ctx))
+}
+
+// Delete removes the schedule of an ACL, enabling it for good when it's
+// disabled
+func (s *ACLScheduleService) Delete(ctx context.Context, aclID, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.Get(ctx, aclID)
+	if err != nil {
+		return err
+	}
+	if !schedule.Active {
+		acl, err := s.ovn.GetACL(ctx, schedule.ACLID)
+		if err != nil {
+			return err
+		}
+		if err := s.toggle(ctx, schedule, acl, true, models.ACLToggleScheduleRemoved, user); err != nil {
+			return err
+		}
+	}
+	if err := s.store.DeleteACLSchedule(ctx, schedule.ACLID); err != nil {
+		return err
+	}
+
+	s.logger.Info("ACL schedule removed",
+		zap.String("acl_id", schedule.ACLID),
+		zap.String("user", user))
+	return nil
+}
+
+// Toggles lists the toggles of an ACL, oldest first, including those made
+// before its schedule was removed. Within a tenant's context, only the
+// tenant's toggles are listed.
+func (s *ACLScheduleService) Toggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error) {
+	toggles, err := s.store.ListACLScheduleToggles(ctx, aclID)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := getTenantFromContext(ctx)
+	result := make([]*models.ACLScheduleToggle, 0, len(toggles))
+	for _, toggle := range toggles {
+		if tenantID == "" || toggle.TenantID == tenantID {
+			result = append(result, toggle)
+		}
+	}
+	return result, nil
+}
+
+// Run toggles scheduled ACLs every interval until ctx is done
+func (s *ACLScheduleService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckAll(ctx); err != nil {
+				s.logger.Error("Failed to check ACL schedules", zap.Error(err))
+			}
+		}
+	}
+}
+
+// CheckAll enables the scheduled ACLs a window of which started, and
+// disables those whose windows ended. Schedules of ACLs that were deleted
+// are removed.
+func (s *ACLScheduleService) CheckAll(ctx context.Context) error {
+	schedules, err := s.store.ListACLSchedules(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	for _, schedule := range schedules {
+		if err := s.check(ContextWithTenant(ctx, schedule.TenantID), schedule, now); err != nil {
+			s.logger.Error("Failed to toggle scheduled ACL",
+				zap.String("acl_id", schedule.ACLID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *ACLScheduleService) check(ctx context.Context, schedule *models.ACLSchedule, now time.Time) error {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return err
+	}
+	active := aclScheduleActive(schedule.Windows, loc, now)
+	if active == schedule.Active {
+		return nil
+	}
+
+	acl, err := s.ovn.GetACL(ctx, schedule.ACLID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.logger.Info("Removing schedule of deleted ACL", zap.String("acl_id", schedule.ACLID))
+			return s.store.DeleteACLSchedule(ctx, schedule.ACLID)
+		}
+		return err
+	}
+
+	reason := models.ACLToggleWindowEnd
+	if active {
+		reason = models.ACLToggleWindowStart
+	}
+	if err := s.toggle(ctx, schedule, acl, active, reason, ""); err != nil {
+		return err
+	}
+	return s.store.SaveACLSchedule(ctx, schedule)
+}
+
+// toggle enables or disables the scheduled ACL acl and records it. The
+// match the ACL has when enabled is kept with the schedule, so changes made
+// to it between toggles are kept too.
+func (s *ACLScheduleService) toggle(ctx context.Context, schedule *models.ACLSchedule, acl *models.ACL, active bool, reason, user string) error {
+	update := *acl
+	update.ExternalIDs = nil
+	if acl.Match != models.ACLDisabledMatch {
+		schedule.Match = acl.Match
+	}
+	if active {
+		update.Match = schedule.Match
+	} else {
+		update.Match = models.ACLDisabledMatch
+	}
+	if update.Match != acl.Match {
+		if _, err := s.ovn.UpdateACL(ctx, acl.UUID, &update); err != nil {
+			return fmt.Errorf("failed to toggle ACL %s: %w", acl.UUID, err)
+		}
+	}
+	schedule.Active = active
+
+	toggle := &models.ACLScheduleToggle{
+		ID:         uuid.New().String(),
+		ACLID:      schedule.ACLID,
+		TenantID:   schedule.TenantID,
+		Action:     models.ACLScheduleDisabled,
+		Reason:     reason,
+		UserID:     user,
+		OccurredAt: s.now().UTC(),
+	}
+	if active {
+		toggle.Action = models.ACLScheduleEnabled
+	}
+	if err := s.store.CreateACLScheduleToggle(ctx, toggle); err != nil {
+		s.logger.Warn("Failed to record ACL schedule toggle",
+			zap.String("acl_id", schedule.ACLID),
+			zap.String("action", toggle.Action),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Scheduled ACL toggled",
+		zap.String("acl_id", schedule.ACLID),
+		zap.String("action", toggle.Action),
+		zap.String("reason", reason))
+	return nil
+}
+
+// validateACLSchedule checks the windows of a schedule and returns the
+// time zone they're in
+func validateACLSchedule(req *ACLScheduleRequest) (*time.Location, error) {
+	if len(req.Windows) == 0 {
+		return nil, fmt.Errorf("%w: at least one window is required", ErrInvalidACLSchedule)
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidACLSchedule, timezone)
+	}
+
+	for _, window := range req.Windows {
+		start, err := parseScheduleClock(window.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseScheduleClock(window.End)
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("%w: window %s-%s is empty", ErrInvalidACLSchedule, window.Start, window.End)
+		}
+		for _, day := range window.Days {
+			if _, ok := scheduleWeekdays[day]; !ok {
+				return nil, fmt.Errorf("%w: day must be one of mon, tue, wed, thu, fri, sat or sun, got %q", ErrInvalidACLSchedule, day)
+			}
+		}
+	}
+	return loc, nil
+}
+
+// parseScheduleClock returns the minute of the day of an HH:MM time
+func parseScheduleClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time must be HH:MM, got %q", ErrInvalidACLSchedule, clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// aclScheduleActive tells whether now is within one of windows, in loc
+func aclScheduleActive(windows []models.ACLScheduleWindow, loc *time.Location, now time.Time) bool {
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, window := range windows {
+		start, err := parseScheduleClock(window.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseScheduleClock(window.End)
+		if err != nil {
+			continue
+		}
+		if start < end {
+			if minute >= start && minute < end && scheduleOnDay(window.Days, today) {
+				return true
+			}
+			continue
+		}
+		// The window spans midnight, started today or yesterday
+		if (minute >= start && scheduleOnDay(window.Days, today)) || (minute < end && scheduleOnDay(window.Days, yesterday)) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleOnDay tells whether a window with days starts on day
+func scheduleOnDay(days []string, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if scheduleWeekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryACLScheduleStore keeps ACL schedules and toggles in memory
+type memoryACLScheduleStore struct {
+	schedules map[string]*models.ACLSchedule
+	toggles   []*models.ACLScheduleToggle
+}
+
+func newMemoryACLScheduleStore() *memoryACLScheduleStore {
+	return &memoryACLScheduleStore{schedules: map[string]*models.ACLSchedule{}}
+}
+
+func (s *memoryACLScheduleStore) SaveACLSchedule(ctx context.Context, schedule *models.ACLSchedule) error {
+	stored := *schedule
+	s.schedules[schedule.ACLID] = &stored
+	return nil
+}
+
+func (s *memoryACLScheduleStore) GetACLSchedule(ctx context.Context, aclID string) (*models.ACLSchedule, error) {
+	schedule, ok := s.schedules[aclID]
+	if !ok {
+		return nil, nil
+	}
+	stored := *schedule
+	return &stored, nil
+}
+
+func (s *memoryACLScheduleStore) ListACLSchedules(ctx context.Context, tenantID string) ([]*models.ACLSchedule, error) {
+	schedules := []*models.ACLSchedule{}
+	for _, schedule := range s.schedules {
+		if tenantID == "" || schedule.TenantID == tenantID {
+			stored := *schedule
+			schedules = append(schedules, &stored)
+		}
+	}
+	return schedules, nil
+}
+
+func (s *memoryACLScheduleStore) DeleteACLSchedule(ctx context.Context, aclID string) error {
+	delete(s.schedules, aclID)
+	return nil
+}
+
+func (s *memoryACLScheduleStore) CreateACLScheduleToggle(ctx context.Context, toggle *models.ACLScheduleToggle) error {
+	s.toggles = append(s.toggles, toggle)
+	return nil
+}
+
+func (s *memoryACLScheduleStore) ListACLScheduleToggles(ctx context.Context, aclID string) ([]*models.ACLScheduleToggle, error) {
+	toggles := []*models.ACLScheduleToggle{}
+	for _, toggle := range s.toggles {
+		if toggle.ACLID == aclID {
+			toggles = append(toggles, toggle)
+		}
+	}
+	return toggles, nil
+}
+
+func TestACLScheduleActive(t *testing.T) {
+	backups := []models.ACLScheduleWindow{{Start: "02:00", End: "04:00"}}
+	overnight := []models.ACLScheduleWindow{{Start: "22:00", End: "06:00", Days: []string{"fri"}}}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2026-10-16 is a Friday
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", "2026-10-16 "+clock)
+		return t
+	}
+	assert.False(t, aclScheduleActive(backups, time.UTC, at("01:59")))
+	assert.True(t, aclScheduleActive(backups, time.UTC, at("02:00")))
+	assert.True(t, aclScheduleActive(backups, time.UTC, at("03:59")))
+	assert.False(t, aclScheduleActive(backups, time.UTC, at("04:00")))
+	assert.True(t, aclScheduleActive(backups, berlin, at("00:30")), "02:30 in Berlin")
+
+	assert.True(t, aclScheduleActive(overnight, time.UTC, at("23:00")))
+	assert.False(t, aclScheduleActive(overnight, time.UTC, at("05:00")), "started on Thursday")
+	assert.True(t, aclScheduleActive(overnight, time.UTC, at("23:00").Add(6*time.Hour)), "Saturday morning")
+	assert.False(t, aclScheduleActive(overnight, time.UTC, at("23:00").Add(8*time.Hour)))
+}
+
+func TestACLScheduleService_Set(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	acl := &models.ACL{UUID: "acl-1", Priority: 1000, Direction: "to-lport", Match: "ip4.dst == 10.0.0.5 && tcp.dst == 873", Action: "allow", Log: true}
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetACL", mock.Anything, "acl-1").Return(acl, nil)
+	store := newMemoryACLScheduleStore()
+	service := NewACLScheduleService(store, mockOVN, time.Minute, zap.NewNop())
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	// Outside its window, the ACL is disabled right away, keeping the rest
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", mock.MatchedBy(func(update *models.ACL) bool {
+		return update.Match == models.ACLDisabledMatch && update.Action == "allow" && update.Log
+	})).Return(acl, nil).Once()
+	schedule, err := service.Set(ctx, "acl-1", &ACLScheduleRequest{Windows: []models.ACLScheduleWindow{{Start: "02:00", End: "04:00"}}}, "alice")
+	require.NoError(t, err)
+	assert.False(t, schedule.Active)
+	assert.Equal(t, "acme", schedule.TenantID)
+	assert.Equal(t, "UTC", schedule.Timezone)
+	assert.Equal(t, acl.Match, schedule.Match)
+	mockOVN.AssertExpectations(t)
+
+	require.Len(t, store.toggles, 1)
+	assert.Equal(t, models.ACLScheduleDisabled, store.toggles[0].Action)
+	assert.Equal(t, models.ACLToggleScheduleSet, store.toggles[0].Reason)
+	assert.Equal(t, "alice", store.toggles[0].UserID)
+
+	_, err = service.Get(ContextWithTenant(context.Background(), "other"), "acl-1")
+	assert.ErrorIs(t, err, ErrACLScheduleNotFound)
+
+	for name, req := range map[string]*ACLScheduleRequest{
+		"no windows":       {},
+		"unknown timezone": {Windows: []models.ACLScheduleWindow{{Start: "02:00", End: "04:00"}}, Timezone: "Mars/Olympus"},
+		"invalid time":     {Windows: []models.ACLScheduleWindow{{Start: "2am", End: "04:00"}}},
+		"empty window":     {Windows: []models.ACLScheduleWindow{{Start: "02:00", End: "02:00"}}},
+		"unknown day":      {Windows: []models.ACLScheduleWindow{{Start: "02:00", End: "04:00", Days: []string{"monday"}}}},
+	} {
+		_, err := service.Set(ctx, "acl-1", req, "alice")
+		assert.ErrorIs(t, err, ErrInvalidACLSchedule, name)
+	}
+}
+
+func TestACLScheduleService_CheckAll(t *testing.T) {
+	ctx := context.Background()
+	acl := &models.ACL{UUID: "acl-1", Match: models.ACLDisabledMatch, Action: "allow"}
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetACL", mock.Anything, "acl-1").Return(acl, nil)
+	mockOVN.On("GetACL", mock.Anything, "acl-gone").Return((*models.ACL)(nil), errors.New("ACL acl-gone not found"))
+	store := newMemoryACLScheduleStore()
+	windows := []models.ACLScheduleWindow{{Start: "02:00", End: "04:00"}}
+	store.schedules["acl-1"] = &models.ACLSchedule{ACLID: "acl-1", TenantID: "acme", Windows: windows, Timezone: "UTC", Match: "tcp.dst == 873"}
+	store.schedules["acl-gone"] = &models.ACLSchedule{ACLID: "acl-gone", Windows: windows, Timezone: "UTC", Match: "tcp.dst == 22"}
+	service := NewACLScheduleService(store, mockOVN, time.Minute, zap.NewNop())
+	now := time.Date(2026, 10, 16, 1, 59, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	require.NoError(t, service.CheckAll(ctx))
+	assert.Empty(t, store.toggles, "before the window")
+
+	// The window starts: the match is restored
+	now = now.Add(time.Minute)
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", mock.MatchedBy(func(update *models.ACL) bool {
+		return update.Match == "tcp.dst == 873"
+	})).Return(acl, nil).Once()
+	require.NoError(t, service.CheckAll(ctx))
+	assert.True(t, store.schedules["acl-1"].Active)
+	assert.NotContains(t, store.schedules, "acl-gone", "the ACL was deleted")
+	require.Len(t, store.toggles, 1)
+	assert.Equal(t, models.ACLScheduleEnabled, store.toggles[0].Action)
+	assert.Equal(t, models.ACLToggleWindowStart, store.toggles[0].Reason)
+	assert.Equal(t, "acme", store.toggles[0].TenantID)
+	assert.Empty(t, store.toggles[0].UserID)
+
+	// The window ends after the ACL's match was changed, which is kept
+	acl.Match = "tcp.dst == 874"
+	now = now.Add(2 * time.Hour)
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", mock.MatchedBy(func(update *models.ACL) bool {
+		return update.Match == models.ACLDisabledMatch
+	})).Return(acl, nil).Once()
+	require.NoError(t, service.CheckAll(ctx))
+	assert.False(t, store.schedules["acl-1"].Active)
+	assert.Equal(t, "tcp.dst == 874", store.schedules["acl-1"].Match)
+	require.Len(t, store.toggles, 2)
+	assert.Equal(t, models.ACLToggleWindowEnd, store.toggles[1].Reason)
+	mockOVN.AssertExpectations(t)
+
+	// Removing the schedule enables the ACL for good
+	acl.Match = models.ACLDisabledMatch
+	mockOVN.On("UpdateACL", mock.Anything, "acl-1", mock.MatchedBy(func(update *models.ACL) bool {
+		return update.Match == "tcp.dst == 874"
+	})).Return(acl, nil).Once()
+	require.NoError(t, service.Delete(ContextWithTenant(ctx, "acme"), "acl-1", "alice"))
+	assert.Empty(t, store.schedules)
+	mockOVN.AssertExpectations(t)
+
+	toggles, err := service.Toggles(ContextWithTenant(ctx, "acme"), "acl-1")
+	require.NoError(t, err)
+	require.Len(t, toggles, 3)
+	assert.Equal(t, models.ACLToggleScheduleRemoved, toggles[2].Reason)
+	toggles, err = service.Toggles(ContextWithTenant(ctx, "other"), "acl-1")
+	require.NoError(t, err)
+	assert.Empty(t, toggles)
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// ErrInvalidSimulation is returned for ACL simulation requests that are
+// malformed
+var ErrInvalidSimulation = errors.New("invalid ACL simulation")
+
+// Observed flows are taken from the ACL logs of the last
+// observedFlowWindow, up to observedFlowsPerACL per ACL
+const (
+	observedFlowWindow  = 24 * time.Hour
+	observedFlowsPerACL = 100
+)
+
+// maxSimulatedFlows limits the flows of one simulation
+const maxSimulatedFlows = 1000
+
+// Verdicts of a flow
+const (
+	VerdictAllow   = "allow"
+	VerdictDrop    = "drop"
+	VerdictReject  = "reject"
+	VerdictUnknown = "unknown"
+)
+
+// Changes of a flow's verdict
+const (
+	ChangeNewlyBlocked = "newly_blocked"
+	ChangeNewlyAllowed = "newly_allowed"
+	ChangeUnchanged    = "unchanged"
+	ChangeUnknown      = "unknown"
+)
+
+// ObservedFlowSource returns logged flows of an ACL. ACLLogIngester
+// implements it.
+type ObservedFlowSource interface {
+	Entries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error)
+}
+
+// SimulatedFlow is the first packet of a connection crossing a switch
+type SimulatedFlow struct {
+	// InPort and OutPort name the ports the packet enters and leaves the
+	// switch by. When empty, they're the ports of the switch with the
+	// source and destination IPs, if any.
+	InPort   string `json:"in_port,omitempty"`
+	OutPort  string `json:"out_port,omitempty"`
+	SrcIP    string `json:"src_ip"`
+	DstIP    string `json:"dst_ip"`
+	Protocol string `json:"protocol,omitempty"` // tcp, udp, sctp or icmp
+	SrcPort  int    `json:"src_port,omitempty"`
+	DstPort  int    `json:"dst_port,omitempty"`
+	Observed bool   `json:"observed,omitempty"` // Taken from the ACL logs
+}
+
+// ACLSimulationRequest proposes a new set of ACLs for a switch and the
+// flows to compare its verdicts on
+type ACLSimulationRequest struct {
+	// ACLs replace the switch's own ACLs; the ACLs of its port groups are
+	// kept. An empty list removes them all.
+	ACLs  []*models.ACL   `json:"acls"`
+	Flows []SimulatedFlow `json:"flows,omitempty"`
+	// Observed adds the flows the ACLs logged over the last day
+	Observed bool `json:"observed,omitempty"`
+}
+
+// ACLVerdict is what the ACLs of a switch do with a flow, and the ACL that
+// decided it
+type ACLVerdict struct {
+	Verdict   string `json:"verdict"` // allow, drop, reject or unknown
+	ACLID     string `json:"acl_id,omitempty"`
+	ACLName   string `json:"acl_name,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// FlowSimulation compares the verdicts of the current and proposed ACLs on
+// a flow
+type FlowSimulation struct {
+	Flow     SimulatedFlow `json:"flow"`
+	Current  ACLVerdict    `json:"current"`
+	Proposed ACLVerdict    `json:"proposed"`
+	Change   string        `json:"change"` // newly_blocked, newly_allowed, unchanged or unknown
+}
+
+// ACLSimulation is the result of simulating an ACL change on a switch
+type ACLSimulation struct {
+	SwitchID string           `json:"switch_id"`
+	Flows    []FlowSimulation `json:"flows"`
+	Summary  map[string]int   `json:"summary"` // Flows by change
+}
+
+// ACLSimulator simulates ACL changes, evaluating the current and proposed
+// ACLs of a switch on flows without applying anything
+type ACLSimulator struct {
+	ovn      OVNServiceInterface
+	observed ObservedFlowSource
+	now      func() time.Time
+}
+
+// NewACLSimulator creates a simulator. observed is nil when ACL logs aren't
+// ingested, and only the flows of requests can be simulated.
+func NewACLSimulator(ovn OVNServiceInterface, observed ObservedFlowSource) *ACLSimulator {
+	return &ACLSimulator{
+		ovn:      ovn,
+		observed: observed,
+		now:      time.Now,
+	}
+}
+
+// Simulate evaluates the switch's current ACLs and the proposed ones on each
+// flow, as the switch would on the first packet of a connection: from-lport
+// ACLs, then to-lport ones, the highest priority match of each deciding.
+// Flows no ACL matches are allowed.
+func (s *ACLSimulator) Simulate(ctx context.Context, switchID string, req *ACLSimulationRequest) (*ACLSimulation, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	sw, err := s.ovn.GetLogicalSwitch(ctx, switchID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.ovn.ListACLs(ctx, sw.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACLs: %w", err)
+	}
+	ports, err := s.ovn.ListPorts(ctx, sw.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ports: %w", err)
+	}
+	portGroups, err := s.ovn.ListPortGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+	addressSets, err := s.ovn.ListAddressSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address sets: %w", err)
+	}
+
+	sets := &ovn.MatchSets{AddressSets: map[string][]string{}, PortGroups: map[string][]string{}}
+	for _, as := range addressSets {
+		sets.AddressSets[as.Name] = as.Addresses
+	}
+	portNames := make(map[string]string, len(ports))
+	for _, port := range ports {
+		portNames[port.UUID] = port.Name
+	}
+	// Port groups apply their ACLs to the switch through the ports they
+	// have on it
+	var shared []*models.ACL
+	for _, pg := range portGroups {
+		members := []string{}
+		for _, uuid := range pg.Ports {
+			if name, ok := portNames[uuid]; ok {
+				members = append(members, name)
+			}
+		}
+		sets.PortGroups[pg.Name] = members
+		if len(members) == 0 {
+			continue
+		}
+		acls, err := s.ovn.ListPortGroupACLs(ctx, pg.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of port group %s: %w", pg.UUID, err)
+		}
+		shared = append(shared, acls...)
+	}
+
+	flows := req.Flows
+	if req.Observed {
+		observed, err := s.observedFlows(ctx, append(append(append([]*models.ACL{}, current...), shared...), req.ACLs...))
+		if err != nil {
+			return nil, err
+		}
+		flows = append(append([]SimulatedFlow{}, flows...), observed...)
+	}
+	if len(flows) > maxSimulatedFlows {
+		flows = flows[:maxSimulatedFlows]
+	}
+
+	currentACLs := append(append([]*models.ACL{}, current...), shared...)
+	proposedACLs := append(append([]*models.ACL{}, req.ACLs...), shared...)
+	simulation := &ACLSimulation{
+		SwitchID: sw.UUID,
+		Flows:    make([]FlowSimulation, 0, len(flows)),
+		Summary:  map[string]int{},
+	}
+	for _, flow := range flows {
+		packet := flowPacket(flow, ports)
+		result := FlowSimulation{
+			Flow:     flow,
+			Current:  evaluateACLs(currentACLs, packet, sets),
+			Proposed: evaluateACLs(proposedACLs, packet, sets),
+		}
+		result.Flow.InPort, result.Flow.OutPort = packet.InPort, packet.OutPort
+		result.Change = verdictChange(result.Current.Verdict, result.Proposed.Verdict)
+		simulation.Flows = append(simulation.Flows, result)
+		simulation.Summary[result.Change]++
+	}
+	return simulation, nil
+}
+
+// validate checks the proposed ACLs and the flows of a request
+func (s *ACLSimulator) validate(req *ACLSimulationRequest) error {
+	if req.ACLs == nil {
+		return fmt.Errorf("%w: acls is required; an empty list simulates removing every ACL of the switch", ErrInvalidSimulation)
+	}
+	if len(req.Flows) == 0 && !req.Observed {
+		return fmt.Errorf("%w: flows are required unless observed flows are simulated", ErrInvalidSimulation)
+	}
+	if len(req.Flows) > maxSimulatedFlows {
+		return fmt.Errorf("%w: at most %d flows can be simulated", ErrInvalidSimulation, maxSimulatedFlows)
+	}
+	if req.Observed && s.observed == nil {
+		return fmt.Errorf("%w: observed flows come from ACL logs, which are not ingested", ErrInvalidSimulation)
+	}
+
+	for i, acl := range req.ACLs {
+		switch {
+		case acl.Direction != "from-lport" && acl.Direction != "to-lport":
+			return fmt.Errorf("%w: acls[%d]: direction must be from-lport or to-lport", ErrInvalidSimulation, i)
+		case acl.Priority < 0 || acl.Priority > 32767:
+			return fmt.Errorf("%w: acls[%d]: priority must be between 0 and 32767", ErrInvalidSimulation, i)
+		}
+		switch acl.Action {
+		case "allow", "allow-related", "allow-stateless", "pass", "drop", "reject":
+		default:
+			return fmt.Errorf("%w: acls[%d]: unknown action %q", ErrInvalidSimulation, i, acl.Action)
+		}
+		if err := ovn.ValidateMatch(acl.Match); err != nil {
+			return fmt.Errorf("%w: acls[%d]: %v", ErrInvalidSimulation, i, err)
+		}
+	}
+
+	for i, flow := range req.Flows {
+		for field, ip := range map[string]string{"src_ip": flow.SrcIP, "dst_ip": flow.DstIP} {
+			if net.ParseIP(ip) == nil {
+				return fmt.Errorf("%w: flows[%d]: %s must be an IP address", ErrInvalidSimulation, i, field)
+			}
+		}
+		if (net.ParseIP(flow.SrcIP).To4() == nil) != (net.ParseIP(flow.DstIP).To4() == nil) {
+			return fmt.Errorf("%w: flows[%d]: src_ip and dst_ip must both be IPv4 or IPv6", ErrInvalidSimulation, i)
+		}
+		switch flow.Protocol {
+		case "", "tcp", "udp", "sctp", "icmp":
+		default:
+			return fmt.Errorf("%w: flows[%d]: protocol must be tcp, udp, sctp or icmp", ErrInvalidSimulation, i)
+		}
+		if flow.SrcPort < 0 || flow.SrcPort > 65535 || flow.DstPort < 0 || flow.DstPort > 65535 {
+			return fmt.Errorf("%w: flows[%d]: ports must be at most 65535", ErrInvalidSimulation, i)
+		}
+	}
+	return nil
+}
+
+// observedFlows returns the distinct flows the named ACLs among acls logged
+// recently. Source ports are left out, as they're mostly ephemeral.
+func (s *ACLSimulator) observedFlows(ctx context.Context, acls []*models.ACL) ([]SimulatedFlow, error) {
+	to := s.now().UTC()
+	seenACL := make(map[string]bool)
+	seenFlow := make(map[SimulatedFlow]bool)
+	var flows []SimulatedFlow
+	for _, acl := range acls {
+		if acl.Name == "" || seenACL[acl.Name] {
+			continue
+		}
+		seenACL[acl.Name] = true
+
+		entries, err := s.observed.Entries(ctx, &models.ACLLogFilter{
+			ACLName: acl.Name,
+			From:    to.Add(-observedFlowWindow),
+			To:      to,
+			Limit:   observedFlowsPerACL,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ACL logs: %w", err)
+		}
+		for _, entry := range entries {
+			flow := SimulatedFlow{
+				SrcIP:    entry.SrcIP,
+				DstIP:    entry.DstIP,
+				Protocol: strings.TrimSuffix(entry.Protocol, "6"),
+				DstPort:  entry.DstPort,
+				Observed: true,
+			}
+			switch flow.Protocol {
+			case "tcp", "udp", "sctp", "icmp":
+			default:
+				flow.Protocol = ""
+			}
+			if net.ParseIP(flow.SrcIP) == nil || net.ParseIP(flow.DstIP) == nil || seenFlow[flow] {
+				continue
+			}
+			seenFlow[flow] = true
+			flows = append(flows, flow)
+		}
+	}
+	return flows, nil
+}
+
+// flowPacket returns the packet of a flow, with the ports and MACs of the
+// switch ports holding its IPs
+func flowPacket(flow SimulatedFlow, ports []*models.LogicalSwitchPort) *ovn.MatchPacket {
+	packet := &ovn.MatchPacket{
+		InPort:   flow.InPort,
+		OutPort:  flow.OutPort,
+		SrcIP:    net.ParseIP(flow.SrcIP),
+		DstIP:    net.ParseIP(flow.DstIP),
+		Protocol: flow.Protocol,
+		SrcPort:  flow.SrcPort,
+		DstPort:  flow.DstPort,
+	}
+	for _, port := range ports {
+		for _, address := range port.Addresses {
+			fields := strings.Fields(address)
+			if len(fields) < 2 {
+				continue
+			}
+			for _, ip := range fields[1:] {
+				parsed := net.ParseIP(ip)
+				if parsed == nil {
+					continue
+				}
+				if parsed.Equal(packet.SrcIP) && (packet.InPort == "" || packet.InPort == port.Name) {
+					packet.InPort, packet.EthSrc = port.Name, fields[0]
+				}
+				if parsed.Equal(packet.DstIP) && (packet.OutPort == "" || packet.OutPort == port.Name) {
+					packet.OutPort, packet.EthDst = port.Name, fields[0]
+				}
+			}
+		}
+	}
+	return packet
+}
+
+// evaluateACLs returns the verdict of acls on packet. A match that can't be
+// evaluated before any ACL of its direction decides makes it unknown.
+func evaluateACLs(acls []*models.ACL, packet *ovn.MatchPacket, sets *ovn.MatchSets) ACLVerdict {
+	sorted := append([]*models.ACL{}, acls...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority > sorted[j].Priority })
+
+	verdict := ACLVerdict{Verdict: VerdictAllow, Reason: "no ACL matched"}
+	for _, direction := range []string{"from-lport", "to-lport"} {
+		for _, acl := range sorted {
+			if acl.Direction != direction {
+				continue
+			}
+			matched, err := ovn.EvaluateMatch(acl.Match, packet, sets)
+			decided := ACLVerdict{
+				ACLID:     acl.UUID,
+				ACLName:   acl.Name,
+				Direction: acl.Direction,
+				Priority:  acl.Priority,
+			}
+			if err != nil {
+				decided.Verdict, decided.Reason = VerdictUnknown, err.Error()
+				return decided
+			}
+			if !matched {
+				continue
+			}
+
+			switch acl.Action {
+			case "drop", "reject":
+				decided.Verdict = acl.Action
+				return decided
+			}
+			decided.Verdict = VerdictAllow
+			verdict = decided
+			break
+		}
+	}
+	return verdict
+}
+
+// verdictChange compares the current and proposed verdicts of a flow
+func verdictChange(current, proposed string) string {
+	switch {
+	case current == VerdictUnknown || proposed == VerdictUnknown:
+		return ChangeUnknown
+	case current == VerdictAllow && proposed != VerdictAllow:
+		return ChangeNewlyBlocked
+	case current != VerdictAllow && proposed == VerdictAllow:
+		return ChangeNewlyAllowed
+	}
+	return ChangeUnchanged
+}
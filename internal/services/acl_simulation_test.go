@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// fakeObservedFlows returns the entries logged by each ACL
+type fakeObservedFlows map[string][]*models.ACLLogEntry
+
+func (f fakeObservedFlows) Entries(ctx context.Context, filter *models.ACLLogFilter) ([]*models.ACLLogEntry, error) {
+	return f[filter.ACLName], nil
+}
+
+func newACLSimulationMock() *MockOVNService {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "web").Return(&models.LogicalSwitch{UUID: "sw-web", Name: "web"}, nil)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "missing").Return((*models.LogicalSwitch)(nil), errors.New("switch not found"))
+	mockOVN.On("ListACLs", mock.Anything, "sw-web").Return([]*models.ACL{
+		{UUID: "acl-ssh", Name: "allow-ssh", Priority: 1000, Direction: "to-lport", Match: `outport == "vm-1" && tcp.dst == 22`, Action: "allow-related"},
+		{UUID: "acl-web", Priority: 1000, Direction: "to-lport", Match: "ip4.src == $clients && tcp.dst == {80, 443}", Action: "allow-related"},
+		{UUID: "acl-deny", Name: "deny-all", Priority: 1, Direction: "to-lport", Match: "ip", Action: "drop"},
+	}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-web").Return([]*models.LogicalSwitchPort{
+		{UUID: "p-1", Name: "vm-1", Addresses: []string{"0a:58:0a:00:00:05 10.0.0.5"}},
+		{UUID: "p-2", Name: "vm-2", Addresses: []string{"0a:58:0a:00:00:06 10.0.0.6"}},
+		{UUID: "p-rtr", Name: "rtr", Addresses: []string{"router"}},
+	}, nil)
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{
+		{UUID: "pg-db", Name: "db", Ports: []string{"p-2"}},
+		{UUID: "pg-other", Name: "other", Ports: []string{"p-elsewhere"}},
+	}, nil)
+	mockOVN.On("ListPortGroupACLs", mock.Anything, "pg-db").Return([]*models.ACL{
+		{UUID: "acl-db", Priority: 2000, Direction: "to-lport", Match: "outport == @db && tcp.dst == 5432", Action: "reject"},
+	}, nil)
+	mockOVN.On("ListAddressSets", mock.Anything).Return([]*models.AddressSet{
+		{UUID: "as-clients", Name: "clients", Addresses: []string{"192.168.0.0/16"}},
+	}, nil)
+	return mockOVN
+}
+
+func TestACLSimulator_Simulate(t *testing.T) {
+	simulator := NewACLSimulator(newACLSimulationMock(), nil)
+
+	simulation, err := simulator.Simulate(context.Background(), "web", &ACLSimulationRequest{
+		// SSH is no longer allowed, and HTTP is allowed from anywhere
+		ACLs: []*models.ACL{
+			{Priority: 1000, Direction: "to-lport", Match: "tcp.dst == 80", Action: "allow-related"},
+			{Priority: 1, Direction: "to-lport", Match: "ip", Action: "drop"},
+		},
+		Flows: []SimulatedFlow{
+			{SrcIP: "172.16.0.1", DstIP: "10.0.0.5", Protocol: "tcp", DstPort: 22},
+			{SrcIP: "172.16.0.1", DstIP: "10.0.0.5", Protocol: "tcp", DstPort: 80},
+			{SrcIP: "192.168.1.1", DstIP: "10.0.0.5", Protocol: "tcp", DstPort: 80},
+			{SrcIP: "192.168.1.1", DstIP: "10.0.0.6", Protocol: "tcp", DstPort: 5432},
+			{SrcIP: "192.168.1.1", DstIP: "10.0.0.5", Protocol: "udp", DstPort: 53},
+			// Whether it's TCP isn't known
+			{SrcIP: "192.168.1.1", DstIP: "10.0.0.5"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sw-web", simulation.SwitchID)
+	require.Len(t, simulation.Flows, 6)
+
+	ssh := simulation.Flows[0]
+	assert.Equal(t, "vm-1", ssh.Flow.OutPort, "ports are found by IP")
+	assert.Equal(t, ACLVerdict{Verdict: VerdictAllow, ACLID: "acl-ssh", ACLName: "allow-ssh", Direction: "to-lport", Priority: 1000}, ssh.Current)
+	assert.Equal(t, VerdictDrop, ssh.Proposed.Verdict)
+	assert.Equal(t, ChangeNewlyBlocked, ssh.Change)
+
+	assert.Equal(t, ChangeNewlyAllowed, simulation.Flows[1].Change, "HTTP from outside the clients address set")
+	assert.Equal(t, ChangeUnchanged, simulation.Flows[2].Change)
+
+	// Port group ACLs are kept
+	db := simulation.Flows[3]
+	assert.Equal(t, VerdictReject, db.Current.Verdict)
+	assert.Equal(t, "acl-db", db.Proposed.ACLID)
+	assert.Equal(t, ChangeUnchanged, db.Change)
+
+	assert.Equal(t, ChangeUnchanged, simulation.Flows[4].Change)
+	assert.Equal(t, ChangeUnknown, simulation.Flows[5].Change)
+	assert.Contains(t, simulation.Flows[5].Current.Reason, "tcp.dst")
+
+	assert.Equal(t, map[string]int{ChangeNewlyBlocked: 1, ChangeNewlyAllowed: 1, ChangeUnchanged: 3, ChangeUnknown: 1}, simulation.Summary)
+}
+
+func TestACLSimulator_ObservedFlows(t *testing.T) {
+	logged := &models.ACLLogEntry{ACLName: "allow-ssh", Verdict: "allow", Protocol: "tcp", SrcIP: "172.16.0.1", DstIP: "10.0.0.5", SrcPort: 40000, DstPort: 22}
+	again := *logged
+	again.SrcPort = 40001
+	simulator := NewACLSimulator(newACLSimulationMock(), fakeObservedFlows{"allow-ssh": {logged, &again}})
+	simulator.now = func() time.Time { return time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) }
+
+	simulation, err := simulator.Simulate(context.Background(), "web", &ACLSimulationRequest{
+		ACLs:     []*models.ACL{},
+		Observed: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, simulation.Flows, 1, "flows differing in source port only are simulated once")
+	assert.True(t, simulation.Flows[0].Flow.Observed)
+	assert.Equal(t, ChangeUnchanged, simulation.Flows[0].Change, "without ACLs, everything is allowed")
+}
+
+func TestACLSimulator_Invalid(t *testing.T) {
+	simulator := NewACLSimulator(newACLSimulationMock(), nil)
+	flow := SimulatedFlow{SrcIP: "10.0.0.1", DstIP: "10.0.0.5"}
+
+	for name, req := range map[string]*ACLSimulationRequest{
+		"no proposed ACLs": {Flows: []SimulatedFlow{flow}},
+		"no flows":         {ACLs: []*models.ACL{}},
+		"no ACL logs":      {ACLs: []*models.ACL{}, Observed: true},
+		"bad match":        {ACLs: []*models.ACL{{Direction: "to-lport", Match: "tcp.dst ==", Action: "drop"}}, Flows: []SimulatedFlow{flow}},
+		"bad action":       {ACLs: []*models.ACL{{Direction: "to-lport", Match: "ip", Action: "deny"}}, Flows: []SimulatedFlow{flow}},
+		"bad IP":           {ACLs: []*models.ACL{}, Flows: []SimulatedFlow{{SrcIP: "vm-1", DstIP: "10.0.0.5"}}},
+		"mixed families":   {ACLs: []*models.ACL{}, Flows: []SimulatedFlow{{SrcIP: "fd00::1", DstIP: "10.0.0.5"}}},
+	} {
+		_, err := simulator.Simulate(context.Background(), "web", req)
+		assert.True(t, errors.Is(err, ErrInvalidSimulation), name)
+	}
+
+	_, err := simulator.Simulate(context.Background(), "missing", &ACLSimulationRequest{ACLs: []*models.ACL{}, Flows: []SimulatedFlow{flow}})
+	assert.EqualError(t, err, "switch not found")
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// ACLCounterSource returns the counters of each ACL, keyed by the first 8
+// hex digits of its UUID. *ovn.ACLStatsCollector implements it.
+type ACLCounterSource interface {
+	ACLCounters(ctx context.Context) (map[string]ovn.ACLCounter, error)
+}
+
+// ACLStatsStore holds recorded ACL counters. *db.DB implements it.
+type ACLStatsStore interface {
+	CreateACLStatsSamples(ctx context.Context, samples []*models.ACLStatsSample) error
+	ListACLStatsSamples(ctx context.Context, aclID string, from, to time.Time) ([]*models.ACLStatsSample, error)
+	DeleteACLStatsSamplesBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// ACLStats is the traffic an ACL matched over a time range
+type ACLStats struct {
+	ACLID string    `json:"acl_id"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	// Packets and Bytes are the counters of the latest sample, since the
+	// ACL's flows were installed
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+	// PacketsInRange and BytesInRange are matched between the first and
+	// last sample of the range
+	PacketsInRange uint64                   `json:"packets_in_range"`
+	BytesInRange   uint64                   `json:"bytes_in_range"`
+	PacketRate     float64                  `json:"packet_rate"` // Per second
+	ByteRate       float64                  `json:"byte_rate"`
+	LastHitAt      *time.Time               `json:"last_hit_at,omitempty"`
+	Samples        []*models.ACLStatsSample `json:"samples"`
+}
+
+// ACLStatsRecorder periodically records the counters of every ACL
+type ACLStatsRecorder struct {
+	store     ACLStatsStore
+	source    ACLCounterSource
+	ovn       OVNServiceInterface
+	interval  time.Duration
+	retention time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewACLStatsRecorder creates a recorder sampling the counters of the ACLs
+// of ovn each interval and keeping samples for retention
+func NewACLStatsRecorder(store ACLStatsStore, source ACLCounterSource, ovn OVNServiceInterface, interval, retention time.Duration, logger *zap.Logger) *ACLStatsRecorder {
+	return &ACLStatsRecorder{
+		store:     store,
+		source:    source,
+		ovn:       ovn,
+		interval:  interval,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Run records samples until ctx is done. A zero interval disables it.
+func (r *ACLStatsRecorder) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Record(ctx); err != nil {
+				r.logger.Error("Failed to record ACL statistics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Record samples the counters of every ACL and deletes the samples past
+// retention. ACLs without installed flows are recorded with no traffic.
+func (r *ACLStatsRecorder) Record(ctx context.Context) error {
+	counters, err := r.source.ACLCounters(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read ACL counters: %w", err)
+	}
+	acls, err := listAllACLs(ctx, r.ovn)
+	if err != nil {
+		return fmt.Errorf("failed to list ACLs: %w", err)
+	}
+
+	recordedAt := r.now().UTC()
+	samples := make([]*models.ACLStatsSample, 0, len(acls))
+	for _, acl := range acls {
+		sample := &models.ACLStatsSample{ACLID: acl.UUID, RecordedAt: recordedAt}
+		if len(acl.UUID) >= 8 {
+			counter := counters[acl.UUID[:8]]
+			sample.Packets, sample.Bytes = counter.Packets, counter.Bytes
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) > 0 {
+		if err := r.store.CreateACLStatsSamples(ctx, samples); err != nil {
+			return fmt.Errorf("failed to save ACL statistics: %w", err)
+		}
+	}
+
+	if r.retention > 0 {
+		if err := r.store.DeleteACLStatsSamplesBefore(ctx, recordedAt.Add(-r.retention)); err != nil {
+			r.logger.Warn("Failed to delete expired ACL statistics", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Stats returns the traffic an ACL matched in [from, to]. Counters going
+// back, e.g. when flows are reinstalled, restart from zero.
+func (r *ACLStatsRecorder) Stats(ctx context.Context, aclID string, from, to time.Time) (*ACLStats, error) {
+	samples, err := r.store.ListACLStatsSamples(ctx, aclID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ACLStats{ACLID: aclID, From: from, To: to, Samples: samples}
+	if stats.Samples == nil {
+		stats.Samples = []*models.ACLStatsSample{}
+	}
+	for i, sample := range samples {
+		stats.Packets, stats.Bytes = sample.Packets, sample.Bytes
+		if i == 0 {
+			continue
+		}
+		previous := samples[i-1]
+		packets, bytes := sample.Packets, sample.Bytes
+		if packets >= previous.Packets && bytes >= previous.Bytes {
+			packets -= previous.Packets
+			bytes -= previous.Bytes
+		}
+		stats.PacketsInRange += packets
+		stats.BytesInRange += bytes
+		if packets > 0 {
+			hitAt := sample.RecordedAt
+			stats.LastHitAt = &hitAt
+		}
+	}
+	if len(samples) > 1 {
+		if seconds := samples[len(samples)-1].RecordedAt.Sub(samples[0].RecordedAt).Seconds(); seconds > 0 {
+			stats.PacketRate = float64(stats.PacketsInRange) / seconds
+			stats.ByteRate = float64(stats.BytesInRange) / seconds
+		}
+	}
+	return stats, nil
+}
+
+// listAllACLs lists the ACLs of every switch and port group
+func listAllACLs(ctx context.Context, service OVNServiceInterface) ([]*models.ACL, error) {
+	switches, err := service.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	portGroups, err := service.ListPortGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acls []*models.ACL
+	seen := make(map[string]bool)
+	add := func(list []*models.ACL) {
+		for _, acl := range list {
+			if !seen[acl.UUID] {
+				seen[acl.UUID] = true
+				acls = append(acls, acl)
+			}
+		}
+	}
+	for _, sw := range switches {
+		list, err := service.ListACLs(ctx, sw.UUID)
+		if err != nil {
+			return nil, err
+		}
+		add(list)
+	}
+	for _, pg := range portGroups {
+		list, err := service.ListPortGroupACLs(ctx, pg.UUID)
+		if err != nil {
+			return nil, err
+		}
+		add(list)
+	}
+	return acls, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// memoryACLStatsStore keeps samples in memory
+type memoryACLStatsStore struct {
+	samples []*models.ACLStatsSample
+}
+
+func (s *memoryACLStatsStore) CreateACLStatsSamples(ctx context.Context, samples []*models.ACLStatsSample) error {
+	s.samples = append(s.samples, samples...)
+	return nil
+}
+
+func (s *memoryACLStatsStore) ListACLStatsSamples(ctx context.Context, aclID string, from, to time.Time) ([]*models.ACLStatsSample, error) {
+	var samples []*models.ACLStatsSample
+	for _, sample := range s.samples {
+		if sample.ACLID == aclID && !sample.RecordedAt.Before(from) && !sample.RecordedAt.After(to) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+func (s *memoryACLStatsStore) DeleteACLStatsSamplesBefore(ctx context.Context, cutoff time.Time) error {
+	kept := s.samples[:0]
+	for _, sample := range s.samples {
+		if !sample.RecordedAt.Before(cutoff) {
+			kept = append(kept, sample)
+		}
+	}
+	s.samples = kept
+	return nil
+}
+
+// fakeACLCounters returns its counters on each read
+type fakeACLCounters map[string]ovn.ACLCounter
+
+func (f fakeACLCounters) ACLCounters(ctx context.Context) (map[string]ovn.ACLCounter, error) {
+	return f, nil
+}
+
+func TestACLStatsRecorder(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{{UUID: "sw-1"}}, nil)
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{{UUID: "pg-1"}}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{
+		{UUID: "1a2b3c4d-0000-0000-0000-000000000001"},
+	}, nil)
+	mockOVN.On("ListPortGroupACLs", mock.Anything, "pg-1").Return([]*models.ACL{
+		{UUID: "1a2b3c4d-0000-0000-0000-000000000001"},
+		{UUID: "5e6f7a8b-0000-0000-0000-000000000002"},
+	}, nil)
+
+	store := &memoryACLStatsStore{}
+	counters := fakeACLCounters{"1a2b3c4d": {Packets: 10, Bytes: 1000}}
+	recorder := NewACLStatsRecorder(store, counters, mockOVN, time.Minute, time.Hour, zap.NewNop())
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	recorder.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, recorder.Record(ctx))
+	require.Len(t, store.samples, 2, "ACLs without flows are recorded too, each once")
+
+	now = now.Add(time.Minute)
+	counters["1a2b3c4d"] = ovn.ACLCounter{Packets: 70, Bytes: 7000}
+	require.NoError(t, recorder.Record(ctx))
+
+	// The flows were reinstalled, so the counters restart
+	now = now.Add(time.Minute)
+	counters["1a2b3c4d"] = ovn.ACLCounter{Packets: 30, Bytes: 3000}
+	require.NoError(t, recorder.Record(ctx))
+
+	stats, err := recorder.Stats(ctx, "1a2b3c4d-0000-0000-0000-000000000001", start, now)
+	require.NoError(t, err)
+	assert.Len(t, stats.Samples, 3)
+	assert.Equal(t, uint64(30), stats.Packets)
+	assert.Equal(t, uint64(90), stats.PacketsInRange)
+	assert.Equal(t, uint64(9000), stats.BytesInRange)
+	assert.Equal(t, 0.75, stats.PacketRate)
+	require.NotNil(t, stats.LastHitAt)
+	assert.Equal(t, now, *stats.LastHitAt)
+
+	unused, err := recorder.Stats(ctx, "5e6f7a8b-0000-0000-0000-000000000002", start, now)
+	require.NoError(t, err)
+	assert.Zero(t, unused.PacketsInRange)
+	assert.Nil(t, unused.LastHitAt)
+
+	// Samples past retention are deleted
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, recorder.Record(ctx))
+	assert.Len(t, store.samples, 2)
+}
+
+func TestACLStatsRecorder_NoSamples(t *testing.T) {
+	recorder := NewACLStatsRecorder(&memoryACLStatsStore{}, fakeACLCounters{}, new(MockOVNService), time.Minute, 0, zap.NewNop())
+
+	stats, err := recorder.Stats(context.Background(), "acl-1", time.Time{}, time.Now())
+	require.NoError(t, err)
+	assert.NotNil(t, stats.Samples)
+	assert.Zero(t, stats.Packets)
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Types of conflicting addresses
+const (
+	AddressTypeMAC = "mac"
+	AddressTypeIP  = "ip"
+)
+
+// ResourceRouterPort is the resource type of logical router ports in address
+// conflicts
+const ResourceRouterPort = "router_port"
+
+// AddressOwner is an object a MAC or IP address is assigned to
+type AddressOwner struct {
+	Resource string `json:"resource"` // port, router_port or nat
+	UUID     string `json:"uuid"`
+	Name     string `json:"name,omitempty"`
+	ParentID string `json:"parent_id,omitempty"` // The switch of a port, the router of a router port or NAT rule
+	NATType  string `json:"nat_type,omitempty"`
+}
+
+// AddressConflict is a MAC or IP address assigned to two objects
+type AddressConflict struct {
+	Type    string          `json:"type"` // mac or ip
+	Address string          `json:"address"`
+	Objects [2]AddressOwner `json:"objects"`
+}
+
+// AddressReport lists the duplicate addresses in the logical network
+type AddressReport struct {
+	CheckedAt time.Time         `json:"checked_at"`
+	Conflicts []AddressConflict `json:"conflicts"`
+}
+
+// addressKey identifies a normalized address
+type addressKey struct {
+	addressType string
+	address     string
+}
+
+// addressIndex maps addresses to the objects they're assigned to, in the
+// order they were added
+type addressIndex struct {
+	keys   []addressKey
+	owners map[addressKey][]AddressOwner
+}
+
+// AddressValidator detects MAC and IP addresses assigned to more than one
+// switch port, router port or NAT external address
+type AddressValidator struct {
+	ovn OVNServiceInterface
+	now func() time.Time
+}
+
+// NewAddressValidator creates a validator
+func NewAddressValidator(ovn OVNServiceInterface) *AddressValidator {
+	return &AddressValidator{ovn: ovn, now: time.Now}
+}
+
+// Validate reports every pair of objects sharing an address
+func (v *AddressValidator) Validate(ctx context.Context) (*AddressReport, error) {
+	index, err := v.index(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AddressReport{CheckedAt: v.now().UTC(), Conflicts: []AddressConflict{}}
+	for _, key := range index.keys {
+		owners := index.owners[key]
+		for i := range owners {
+			for j := i + 1; j < len(owners); j++ {
+				if conflicting(owners[i], owners[j]) {
+					report.Conflicts = append(report.Conflicts, AddressConflict{
+						Type:    key.addressType,
+						Address: key.address,
+						Objects: [2]AddressOwner{owners[i], owners[j]},
+					})
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// CheckPort returns the objects sharing an address with a port about to be
+// created on switchID, or updated when port.UUID is set
+func (v *AddressValidator) CheckPort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) ([]AddressConflict, error) {
+	index, err := v.index(ctx, port.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate := AddressOwner{
+		Resource: models.ResourcePort,
+		UUID:     port.UUID,
+		Name:     port.Name,
+		ParentID: switchID,
+	}
+	var conflicts []AddressConflict
+	for _, key := range switchPortAddresses(port) {
+		for _, owner := range index.owners[key] {
+			if conflicting(candidate, owner) {
+				conflicts = append(conflicts, AddressConflict{
+					Type:    key.addressType,
+					Address: key.address,
+					Objects: [2]AddressOwner{candidate, owner},
+				})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// index collects the addresses of the current topology, leaving out the
+// port excludePort
+func (v *AddressValidator) index(ctx context.Context, excludePort string) (*addressIndex, error) {
+	topology, err := v.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+	return newAddressIndex(topology, excludePort), nil
+}
+
+// newAddressIndex collects the addresses of a topology, leaving out the port
+// excludePort
+func newAddressIndex(topology *Topology, excludePort string) *addressIndex {
+	index := &addressIndex{owners: make(map[addressKey][]AddressOwner)}
+
+	portSwitch := make(map[string]string)
+	for _, sw := range topology.Switches {
+		for _, portID := range sw.Ports {
+			portSwitch[portID] = sw.UUID
+		}
+	}
+	for _, port := range topology.Ports {
+		if port.UUID == excludePort {
+			continue
+		}
+		owner := AddressOwner{
+			Resource: models.ResourcePort,
+			UUID:     port.UUID,
+			Name:     port.Name,
+			ParentID: portSwitch[port.UUID],
+		}
+		for _, key := range switchPortAddresses(port) {
+			index.add(key, owner)
+		}
+	}
+
+	routerPortRouter := make(map[string]string)
+	for _, router := range topology.Routers {
+		for _, portID := range router.Ports {
+			routerPortRouter[portID] = router.UUID
+		}
+	}
+	for _, lrp := range topology.RouterPorts {
+		owner := AddressOwner{
+			Resource: ResourceRouterPort,
+			UUID:     lrp.UUID,
+			Name:     lrp.Name,
+			ParentID: routerPortRouter[lrp.UUID],
+		}
+		if key, ok := macKey(lrp.MAC); ok {
+			index.add(key, owner)
+		}
+		for _, network := range lrp.Networks {
+			if key, ok := ipKey(network); ok {
+				index.add(key, owner)
+			}
+		}
+	}
+
+	for _, router := range topology.Routers {
+		for _, nat := range router.NAT {
+			owner := AddressOwner{
+				Resource: models.ResourceNAT,
+				UUID:     nat.UUID,
+				ParentID: router.UUID,
+				NATType:  nat.Type,
+			}
+			if key, ok := ipKey(nat.ExternalIP); ok {
+				index.add(key, owner)
+			}
+			if nat.ExternalMAC != nil {
+				if key, ok := macKey(*nat.ExternalMAC); ok {
+					index.add(key, owner)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// add records that owner is assigned key, once per owner
+func (x *addressIndex) add(key addressKey, owner AddressOwner) {
+	owners, seen := x.owners[key]
+	if !seen {
+		x.keys = append(x.keys, key)
+	}
+	for _, existing := range owners {
+		if existing.Resource == owner.Resource && existing.UUID == owner.UUID {
+			return
+		}
+	}
+	x.owners[key] = append(owners, owner)
+}
+
+// conflicting reports whether two objects may not share an address. SNAT
+// rules translate to an address of their router, so they may share it with
+// the router's ports and other NAT rules.
+func conflicting(a, b AddressOwner) bool {
+	if a.ParentID != "" && a.ParentID == b.ParentID &&
+		(a.NATType == "snat" || b.NATType == "snat") {
+		return false
+	}
+	return true
+}
+
+// switchPortAddresses returns the addresses assigned to a switch port.
+// Router-type ports are left out, as they mirror their router port, as are
+// "dynamic", "unknown" and "router" addresses.
+func switchPortAddresses(port *models.LogicalSwitchPort) []addressKey {
+	if port.Type == "router" {
+		return nil
+	}
+
+	var keys []addressKey
+	seen := make(map[addressKey]bool)
+	for _, address := range port.Addresses {
+		for _, field := range strings.Fields(address) {
+			key, ok := macKey(field)
+			if !ok {
+				key, ok = ipKey(field)
+			}
+			if ok && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// macKey normalizes a MAC address
+func macKey(value string) (addressKey, bool) {
+	mac, err := net.ParseMAC(value)
+	if err != nil || len(mac) != 6 {
+		return addressKey{}, false
+	}
+	return addressKey{addressType: AddressTypeMAC, address: mac.String()}, true
+}
+
+// ipKey normalizes an IP address, dropping the prefix length of a network
+func ipKey(value string) (addressKey, bool) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		var err error
+		if ip, _, err = net.ParseCIDR(value); err != nil {
+			return addressKey{}, false
+		}
+	}
+	return addressKey{addressType: AddressTypeIP, address: ip.String()}, true
+}
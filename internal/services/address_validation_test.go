@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func newAddressMock() *MockOVNService {
+	externalMAC := "0A:00:00:00:00:99"
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetTopology", mock.Anything).Return(&Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Ports: []string{"p-1", "p-2", "p-rtr"}},
+			{UUID: "sw-2", Ports: []string{"p-3"}},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "p-1", Name: "vm-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.10", "00:00:00:00:00:01"}},
+			{UUID: "p-2", Name: "vm-2", Addresses: []string{"00:00:00:00:00:02 10.0.0.10 fd00::0:10"}},
+			{UUID: "p-3", Name: "vm-3", Addresses: []string{"0a:00:00:00:00:99 fd00::10", "unknown"}},
+			{UUID: "p-rtr", Name: "rtr", Type: "router", Addresses: []string{"router"}},
+		},
+		Routers: []*models.LogicalRouter{
+			{UUID: "lr-1", Ports: []string{"lrp-1"}, NAT: []models.NAT{
+				{UUID: "nat-snat", Type: "snat", ExternalIP: "172.16.0.1", LogicalIP: "10.0.0.0/24"},
+				{UUID: "nat-fip", Type: "dnat_and_snat", ExternalIP: "172.16.0.1", LogicalIP: "10.0.0.20", ExternalMAC: &externalMAC},
+			}},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			{UUID: "lrp-1", Name: "lrp-gw", MAC: "00:00:00:00:ff:01", Networks: []string{"172.16.0.1/24"}},
+		},
+	}, nil)
+	return mockOVN
+}
+
+func TestAddressValidator_Validate(t *testing.T) {
+	report, err := NewAddressValidator(newAddressMock()).Validate(context.Background())
+	require.NoError(t, err)
+
+	var found []string
+	for _, conflict := range report.Conflicts {
+		found = append(found, conflict.Type+" "+conflict.Address+" "+
+			conflict.Objects[0].UUID+" "+conflict.Objects[1].UUID)
+	}
+	assert.Equal(t, []string{
+		"ip 10.0.0.10 p-1 p-2",
+		"ip fd00::10 p-2 p-3",
+		"mac 0a:00:00:00:00:99 p-3 nat-fip",
+		// The SNAT rule may use the router's address, the floating IP may not
+		"ip 172.16.0.1 lrp-1 nat-fip",
+	}, found)
+	assert.Equal(t, ResourceRouterPort, report.Conflicts[3].Objects[0].Resource)
+	assert.Equal(t, "lr-1", report.Conflicts[3].Objects[1].ParentID)
+}
+
+func TestAddressValidator_CheckPort(t *testing.T) {
+	validator := NewAddressValidator(newAddressMock())
+	ctx := context.Background()
+
+	conflicts, err := validator.CheckPort(ctx, "sw-2", &models.LogicalSwitchPort{
+		Name:      "vm-4",
+		Addresses: []string{"00:00:00:00:00:01 172.16.0.1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, conflicts, 4)
+	assert.Equal(t, "p-1", conflicts[0].Objects[1].UUID)
+	assert.Equal(t, "vm-4", conflicts[0].Objects[0].Name)
+
+	// An updated port doesn't conflict with itself, nor with conflicts
+	// between other objects
+	conflicts, err = validator.CheckPort(ctx, "", &models.LogicalSwitchPort{
+		UUID:      "p-1",
+		Addresses: []string{"00:00:00:00:00:01 10.0.0.11"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
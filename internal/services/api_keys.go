@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrAPIKeyNotFound is returned for API keys that don't exist in a tenant
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidAPIKeySettings is returned for API keys created with unknown
+	// scopes or malformed CIDRs
+	ErrInvalidAPIKeySettings = errors.New("invalid API key settings")
+)
+
+// apiKeyPrefixLen is the length of the secret prefix stored in clear to find
+// a key's hash
+const apiKeyPrefixLen = 8
+
+// Argon2id parameters for API key secrets. Secrets are random 256-bit
+// values, so they don't need the cost passwords do; this keeps verifying a
+// key on every request cheap.
+const (
+	apiKeyArgonTime    = 1
+	apiKeyArgonMemory  = 19 * 1024 // KiB
+	apiKeyArgonThreads = 1
+	apiKeyArgonKeyLen  = 32
+	apiKeySaltLen      = 16
+)
+
+// legacyAPIKeyHashPrefix marks hashes stored before argon2id was used. They
+// are still accepted and rehashed on use.
+const legacyAPIKeyHashPrefix = "hash_"
+
+// newAPIKey generates a secret and the API key handed to the client,
+// ovncp_<tenant_prefix>_<secret>
+func newAPIKey(tenantID string) (apiKey, secret string, err error) {
+	keyBytes := make([]byte, 32)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	secret = base64.RawURLEncoding.EncodeToString(keyBytes)
+
+	tenantPrefix := tenantID
+	if len(tenantPrefix) > 8 {
+		tenantPrefix = tenantPrefix[:8]
+	}
+	return fmt.Sprintf("ovncp_%s_%s", tenantPrefix, secret), secret, nil
+}
+
+// hashAPIKey returns the argon2id hash of an API key secret in the PHC
+// string format
+func hashAPIKey(secret string) (string, error) {
+	salt := make([]byte, apiKeySaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(secret), salt, apiKeyArgonTime, apiKeyArgonMemory, apiKeyArgonThreads, apiKeyArgonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, apiKeyArgonMemory, apiKeyArgonTime, apiKeyArgonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// verifyAPIKey reports whether secret matches an encoded hash, using the
+// parameters stored with the hash
+func verifyAPIKey(encoded, secret string) bool {
+	if strings.HasPrefix(encoded, legacyAPIKeyHashPrefix) {
+		return subtle.ConstantTimeCompare([]byte(encoded), []byte(legacyAPIKeyHashPrefix+secret)) == 1
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false
+	}
+
+	got := argon2.IDKey([]byte(secret), salt, iterations, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// matchAPIKey checks secret against a key's current secret and, during the
+// grace period after a rotation, its previous one. It reports whether the
+// key matched and whether it was by its previous secret.
+func matchAPIKey(key *models.TenantAPIKey, secret string, now time.Time) (matched, previous bool) {
+	if verifyAPIKey(key.KeyHash, secret) {
+		return true, false
+	}
+	if key.PreviousKeyHash != "" && key.PreviousExpiresAt != nil && now.Before(*key.PreviousExpiresAt) &&
+		verifyAPIKey(key.PreviousKeyHash, secret) {
+		return true, true
+	}
+	return false, false
+}
+
+// normalizeAPIKeySettings checks a new key's scopes, defaulting to read
+// only, and normalizes its allowed CIDRs; single addresses are allowed as
+// host prefixes
+func normalizeAPIKeySettings(key *models.TenantAPIKey) error {
+	if len(key.Scopes) == 0 {
+		key.Scopes = []string{models.APIKeyScopeRead}
+	}
+	for _, scope := range key.Scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeySettings, scope)
+		}
+	}
+
+	for i, cidr := range key.AllowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return fmt.Errorf("%w: invalid CIDR %q", ErrInvalidAPIKeySettings, cidr)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		key.AllowedCIDRs[i] = prefix.Masked().String()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestHashAPIKey(t *testing.T) {
+	hash, err := hashAPIKey("s3cret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=1,p=1$"))
+	assert.NotContains(t, hash, "s3cret")
+
+	assert.True(t, verifyAPIKey(hash, "s3cret"))
+	assert.False(t, verifyAPIKey(hash, "s3cre"))
+	assert.False(t, verifyAPIKey("$argon2id$garbage", "s3cret"))
+
+	// Hashes are salted
+	again, err := hashAPIKey("s3cret")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again)
+
+	// Hashes stored before argon2id are still accepted
+	assert.True(t, verifyAPIKey("hash_s3cret", "s3cret"))
+	assert.False(t, verifyAPIKey("hash_s3cret", "other"))
+}
+
+func TestNewAPIKey(t *testing.T) {
+	apiKey, secret, err := newAPIKey("0c5e2f7a-1b2c-4d5e-8f90-123456789abc")
+	require.NoError(t, err)
+
+	parts := strings.SplitN(apiKey, "_", 3)
+	require.Len(t, parts, 3)
+	assert.Equal(t, "ovncp", parts[0])
+	assert.Equal(t, "0c5e2f7a", parts[1])
+	assert.Equal(t, secret, parts[2])
+	assert.Len(t, secret, 43)
+
+	apiKey, _, err = newAPIKey("t1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(apiKey, "ovncp_t1_"))
+}
+
+func TestMatchAPIKey_RotationGracePeriod(t *testing.T) {
+	now := time.Now()
+	current, err := hashAPIKey("new")
+	require.NoError(t, err)
+	previous, err := hashAPIKey("old")
+	require.NoError(t, err)
+	graceEnd := now.Add(time.Hour)
+
+	key := &models.TenantAPIKey{KeyHash: current, PreviousKeyHash: previous, PreviousExpiresAt: &graceEnd}
+
+	matched, prev := matchAPIKey(key, "new", now)
+	assert.True(t, matched)
+	assert.False(t, prev)
+
+	matched, prev = matchAPIKey(key, "old", now)
+	assert.True(t, matched)
+	assert.True(t, prev)
+
+	matched, _ = matchAPIKey(key, "old", graceEnd.Add(time.Second))
+	assert.False(t, matched, "the old secret expires with the grace period")
+
+	matched, _ = matchAPIKey(key, "other", now)
+	assert.False(t, matched)
+}
+
+func TestNormalizeAPIKeySettings(t *testing.T) {
+	key := &models.TenantAPIKey{AllowedCIDRs: []string{"10.1.2.3/8", "192.0.2.7", "2001:db8::1"}}
+	require.NoError(t, normalizeAPIKeySettings(key))
+	assert.Equal(t, []string{models.APIKeyScopeRead}, key.Scopes, "keys default to read only")
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.7/32", "2001:db8::1/128"}, key.AllowedCIDRs)
+
+	err := normalizeAPIKeySettings(&models.TenantAPIKey{Scopes: []string{"read", "everything"}})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeySettings)
+
+	err = normalizeAPIKeySettings(&models.TenantAPIKey{AllowedCIDRs: []string{"10.0.0.0/33"}})
+	assert.ErrorIs(t, err, ErrInvalidAPIKeySettings)
+}
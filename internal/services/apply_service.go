@@ -0,0 +1,693 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"go.uber.org/zap"
+)
+
+// ApplyManagedByKey marks top-level resources created by apply so that prune
+// only removes resources apply owns
+const (
+	ApplyManagedByKey   = "managed_by"
+	ApplyManagedByValue = "ovncp-apply"
+)
+
+// DesiredState describes the logical network a caller wants to exist. Fields
+// left out of a resource are not managed and keep their current values.
+type DesiredState struct {
+	Switches []DesiredSwitch `json:"switches,omitempty" yaml:"switches,omitempty"`
+	Routers  []DesiredRouter `json:"routers,omitempty" yaml:"routers,omitempty"`
+}
+
+// DesiredSwitch is a logical switch with the ports and ACLs it should have
+type DesiredSwitch struct {
+	Name        string            `json:"name" yaml:"name"`
+	OtherConfig map[string]string `json:"other_config,omitempty" yaml:"other_config,omitempty"`
+	Ports       []DesiredPort     `json:"ports,omitempty" yaml:"ports,omitempty"`
+	ACLs        []DesiredACL      `json:"acls,omitempty" yaml:"acls,omitempty"`
+}
+
+// DesiredPort is a logical switch port, identified by name
+type DesiredPort struct {
+	Name         string            `json:"name" yaml:"name"`
+	Type         string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Addresses    []string          `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	PortSecurity []string          `json:"port_security,omitempty" yaml:"port_security,omitempty"`
+	Options      map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	Enabled      *bool             `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// DesiredACL is an ACL, identified by direction, priority and match
+type DesiredACL struct {
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+	Direction string `json:"direction" yaml:"direction"`
+	Priority  int    `json:"priority" yaml:"priority"`
+	Match     string `json:"match" yaml:"match"`
+	Action    string `json:"action" yaml:"action"`
+	Log       bool   `json:"log,omitempty" yaml:"log,omitempty"`
+	Severity  string `json:"severity,omitempty" yaml:"severity,omitempty"`
+}
+
+// DesiredRouter is a logical router, identified by name
+type DesiredRouter struct {
+	Name    string            `json:"name" yaml:"name"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ApplyOptions controls how a desired state is applied
+type ApplyOptions struct {
+	// DryRun returns the plan without changing anything
+	DryRun bool
+	// Prune deletes ports and ACLs of declared switches that are not in the
+	// document, and switches and routers created by apply that were removed
+	Prune bool
+}
+
+// ApplyChange is a single step of an apply plan
+type ApplyChange struct {
+	Action       string   `json:"action"` // create, update, delete
+	ResourceType string   `json:"resource_type"`
+	Name         string   `json:"name"`
+	Switch       string   `json:"switch,omitempty"`
+	ID           string   `json:"id,omitempty"`
+	Fields       []string `json:"fields,omitempty"`
+
+	run func(ctx context.Context, switchIDs map[string]string) error
+}
+
+// ApplySummary counts the changes in a plan
+type ApplySummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ApplyPlan is the ordered set of changes that converge the current state to
+// the desired state
+type ApplyPlan struct {
+	Changes []*ApplyChange `json:"changes"`
+	Summary ApplySummary   `json:"summary"`
+}
+
+// ApplyResult reports the outcome of an apply
+type ApplyResult struct {
+	DryRun  bool       `json:"dry_run"`
+	Plan    *ApplyPlan `json:"plan"`
+	Applied int        `json:"applied"`
+}
+
+// ApplyService converges OVN to a declared desired state
+type ApplyService struct {
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+}
+
+// NewApplyService creates a new apply service
+func NewApplyService(ovnService OVNServiceInterface, logger *zap.Logger) *ApplyService {
+	return &ApplyService{
+		ovnService: ovnService,
+		logger:     logger,
+	}
+}
+
+// Apply diffs the desired state against OVN and, unless DryRun is set,
+// executes the resulting plan. Changes run in dependency order: switches and
+// routers, then ports and ACLs, then deletions. On failure the result reports
+// how many changes were applied before the error.
+func (s *ApplyService) Apply(ctx context.Context, state *DesiredState, opts ApplyOptions) (*ApplyResult, error) {
+	plan, err := s.Plan(ctx, state, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{
+		DryRun: opts.DryRun,
+		Plan:   plan,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	switchIDs := make(map[string]string)
+	for _, change := range plan.Changes {
+		if err := change.run(ctx, switchIDs); err != nil {
+			return result, fmt.Errorf("failed to %s %s %s: %w", change.Action, change.ResourceType, change.Name, err)
+		}
+		result.Applied++
+	}
+
+	s.logger.Info("Applied desired state",
+		zap.Int("created", plan.Summary.Create),
+		zap.Int("updated", plan.Summary.Update),
+		zap.Int("deleted", plan.Summary.Delete))
+
+	return result, nil
+}
+
+// Plan computes the changes needed to reach the desired state
+func (s *ApplyService) Plan(ctx context.Context, state *DesiredState, opts ApplyOptions) (*ApplyPlan, error) {
+	if err := validateDesiredState(state); err != nil {
+		return nil, fmt.Errorf("invalid desired state: %w", err)
+	}
+
+	switches, err := s.ovnService.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list switches: %w", err)
+	}
+	routers, err := s.ovnService.ListLogicalRouters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routers: %w", err)
+	}
+
+	currentSwitches := make(map[string]*models.LogicalSwitch, len(switches))
+	for _, sw := range switches {
+		currentSwitches[sw.Name] = sw
+	}
+	currentRouters := make(map[string]*models.LogicalRouter, len(routers))
+	for _, lr := range routers {
+		currentRouters[lr.Name] = lr
+	}
+
+	plan := &ApplyPlan{Changes: []*ApplyChange{}}
+	var children, deletions []*ApplyChange
+
+	declaredSwitches := make(map[string]bool)
+	for i := range state.Switches {
+		desired := &state.Switches[i]
+		declaredSwitches[desired.Name] = true
+
+		current := currentSwitches[desired.Name]
+		if current == nil {
+			plan.add(s.createSwitch(desired))
+			for j := range desired.Ports {
+				children = append(children, s.createPort(desired.Name, &desired.Ports[j]))
+			}
+			for j := range desired.ACLs {
+				children = append(children, s.createACL(desired.Name, &desired.ACLs[j]))
+			}
+			continue
+		}
+
+		if fields := diffSwitch(desired, current); len(fields) > 0 {
+			plan.add(s.updateSwitch(desired, current, fields))
+		} else {
+			plan.Summary.Unchanged++
+		}
+
+		portChanges, portDeletions, unchanged, err := s.planPorts(ctx, desired, current, opts.Prune)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, portChanges...)
+		deletions = append(deletions, portDeletions...)
+		plan.Summary.Unchanged += unchanged
+
+		aclChanges, aclDeletions, unchanged, err := s.planACLs(ctx, desired, current, opts.Prune)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, aclChanges...)
+		deletions = append(deletions, aclDeletions...)
+		plan.Summary.Unchanged += unchanged
+	}
+
+	declaredRouters := make(map[string]bool)
+	for i := range state.Routers {
+		desired := &state.Routers[i]
+		declaredRouters[desired.Name] = true
+
+		current := currentRouters[desired.Name]
+		if current == nil {
+			plan.add(s.createRouter(desired))
+			continue
+		}
+
+		if !optionsMatch(desired.Options, current.Options) {
+			plan.add(s.updateRouter(desired, current))
+		} else {
+			plan.Summary.Unchanged++
+		}
+	}
+
+	for _, change := range children {
+		plan.add(change)
+	}
+	for _, change := range deletions {
+		plan.add(change)
+	}
+
+	if opts.Prune {
+		for _, sw := range sortedSwitches(switches) {
+			if !declaredSwitches[sw.Name] && sw.ExternalIDs[ApplyManagedByKey] == ApplyManagedByValue {
+				plan.add(s.deleteResource("switch", sw.Name, "", sw.UUID, s.ovnService.DeleteLogicalSwitch))
+			}
+		}
+		for _, lr := range sortedRouters(routers) {
+			if !declaredRouters[lr.Name] && lr.ExternalIDs[ApplyManagedByKey] == ApplyManagedByValue {
+				plan.add(s.deleteResource("router", lr.Name, "", lr.UUID, s.ovnService.DeleteLogicalRouter))
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func (s *ApplyService) planPorts(ctx context.Context, desired *DesiredSwitch, current *models.LogicalSwitch, prune bool) (changes, deletions []*ApplyChange, unchanged int, err error) {
+	ports, err := s.ovnService.ListPorts(ctx, current.UUID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to list ports of switch %s: %w", desired.Name, err)
+	}
+
+	existing := make(map[string]*models.LogicalSwitchPort, len(ports))
+	for _, port := range ports {
+		existing[port.Name] = port
+	}
+
+	declared := make(map[string]bool)
+	for i := range desired.Ports {
+		port := &desired.Ports[i]
+		declared[port.Name] = true
+
+		currentPort := existing[port.Name]
+		if currentPort == nil {
+			changes = append(changes, s.createPort(desired.Name, port))
+			continue
+		}
+		if fields := diffPort(port, currentPort); len(fields) > 0 {
+			changes = append(changes, s.updatePort(desired.Name, port, currentPort, fields))
+		} else {
+			unchanged++
+		}
+	}
+
+	if prune {
+		sort.Slice(ports, func(i, j int) bool { return ports[i].Name < ports[j].Name })
+		for _, port := range ports {
+			if !declared[port.Name] {
+				deletions = append(deletions, s.deleteResource("port", port.Name, desired.Name, port.UUID, s.ovnService.DeletePort))
+			}
+		}
+	}
+
+	return changes, deletions, unchanged, nil
+}
+
+func (s *ApplyService) planACLs(ctx context.Context, desired *DesiredSwitch, current *models.LogicalSwitch, prune bool) (changes, deletions []*ApplyChange, unchanged int, err error) {
+	acls, err := s.ovnService.ListACLs(ctx, current.UUID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to list ACLs of switch %s: %w", desired.Name, err)
+	}
+
+	existing := make(map[string]*models.ACL, len(acls))
+	for _, acl := range acls {
+		existing[aclKey(acl.Direction, acl.Priority, acl.Match)] = acl
+	}
+
+	declared := make(map[string]bool)
+	for i := range desired.ACLs {
+		acl := &desired.ACLs[i]
+		key := aclKey(acl.Direction, acl.Priority, acl.Match)
+		declared[key] = true
+
+		currentACL := existing[key]
+		if currentACL == nil {
+			changes = append(changes, s.createACL(desired.Name, acl))
+			continue
+		}
+		if fields := diffACL(acl, currentACL); len(fields) > 0 {
+			changes = append(changes, s.updateACL(desired.Name, acl, currentACL, fields))
+		} else {
+			unchanged++
+		}
+	}
+
+	if prune {
+		for _, acl := range acls {
+			if !declared[aclKey(acl.Direction, acl.Priority, acl.Match)] {
+				deletions = append(deletions, s.deleteResource("acl", aclName(acl.Name, acl.Direction, acl.Priority, acl.Match), desired.Name, acl.UUID, s.ovnService.DeleteACL))
+			}
+		}
+	}
+
+	return changes, deletions, unchanged, nil
+}
+
+func (s *ApplyService) createSwitch(desired *DesiredSwitch) *ApplyChange {
+	return &ApplyChange{
+		Action:       "create",
+		ResourceType: "switch",
+		Name:         desired.Name,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			created, err := s.ovnService.CreateLogicalSwitch(ctx, &models.LogicalSwitch{
+				Name:        desired.Name,
+				OtherConfig: desired.OtherConfig,
+				ExternalIDs: map[string]string{ApplyManagedByKey: ApplyManagedByValue},
+			})
+			if err != nil {
+				return err
+			}
+			switchIDs[desired.Name] = created.UUID
+			return nil
+		},
+	}
+}
+
+func (s *ApplyService) updateSwitch(desired *DesiredSwitch, current *models.LogicalSwitch, fields []string) *ApplyChange {
+	return &ApplyChange{
+		Action:       "update",
+		ResourceType: "switch",
+		Name:         desired.Name,
+		ID:           current.UUID,
+		Fields:       fields,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			_, err := s.ovnService.UpdateLogicalSwitch(ctx, current.UUID, &models.LogicalSwitch{
+				OtherConfig: desired.OtherConfig,
+			})
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) createRouter(desired *DesiredRouter) *ApplyChange {
+	return &ApplyChange{
+		Action:       "create",
+		ResourceType: "router",
+		Name:         desired.Name,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			_, err := s.ovnService.CreateLogicalRouter(ctx, &models.LogicalRouter{
+				Name:        desired.Name,
+				Options:     desired.Options,
+				ExternalIDs: map[string]string{ApplyManagedByKey: ApplyManagedByValue},
+			})
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) updateRouter(desired *DesiredRouter, current *models.LogicalRouter) *ApplyChange {
+	return &ApplyChange{
+		Action:       "update",
+		ResourceType: "router",
+		Name:         desired.Name,
+		ID:           current.UUID,
+		Fields:       []string{"options"},
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			_, err := s.ovnService.UpdateLogicalRouter(ctx, current.UUID, &models.LogicalRouter{
+				Options: desired.Options,
+			})
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) createPort(switchName string, desired *DesiredPort) *ApplyChange {
+	return &ApplyChange{
+		Action:       "create",
+		ResourceType: "port",
+		Name:         desired.Name,
+		Switch:       switchName,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			switchID, err := s.resolveSwitch(ctx, switchName, switchIDs)
+			if err != nil {
+				return err
+			}
+			_, err = s.ovnService.CreatePort(ctx, switchID, desiredPortModel(desired))
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) updatePort(switchName string, desired *DesiredPort, current *models.LogicalSwitchPort, fields []string) *ApplyChange {
+	return &ApplyChange{
+		Action:       "update",
+		ResourceType: "port",
+		Name:         desired.Name,
+		Switch:       switchName,
+		ID:           current.UUID,
+		Fields:       fields,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			_, err := s.ovnService.UpdatePort(ctx, current.UUID, desiredPortModel(desired))
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) createACL(switchName string, desired *DesiredACL) *ApplyChange {
+	return &ApplyChange{
+		Action:       "create",
+		ResourceType: "acl",
+		Name:         aclName(desired.Name, desired.Direction, desired.Priority, desired.Match),
+		Switch:       switchName,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			switchID, err := s.resolveSwitch(ctx, switchName, switchIDs)
+			if err != nil {
+				return err
+			}
+			_, err = s.ovnService.CreateACL(ctx, switchID, desiredACLModel(desired))
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) updateACL(switchName string, desired *DesiredACL, current *models.ACL, fields []string) *ApplyChange {
+	return &ApplyChange{
+		Action:       "update",
+		ResourceType: "acl",
+		Name:         aclName(desired.Name, desired.Direction, desired.Priority, desired.Match),
+		Switch:       switchName,
+		ID:           current.UUID,
+		Fields:       fields,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			_, err := s.ovnService.UpdateACL(ctx, current.UUID, desiredACLModel(desired))
+			return err
+		},
+	}
+}
+
+func (s *ApplyService) deleteResource(resourceType, name, switchName, id string, del func(ctx context.Context, id string) error) *ApplyChange {
+	return &ApplyChange{
+		Action:       "delete",
+		ResourceType: resourceType,
+		Name:         name,
+		Switch:       switchName,
+		ID:           id,
+		run: func(ctx context.Context, switchIDs map[string]string) error {
+			return del(ctx, id)
+		},
+	}
+}
+
+// resolveSwitch returns the UUID of a switch created earlier in the same
+// apply, or looks it up by name
+func (s *ApplyService) resolveSwitch(ctx context.Context, name string, switchIDs map[string]string) (string, error) {
+	if id, ok := switchIDs[name]; ok {
+		return id, nil
+	}
+
+	sw, err := s.ovnService.GetLogicalSwitch(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	switchIDs[name] = sw.UUID
+	return sw.UUID, nil
+}
+
+func (p *ApplyPlan) add(change *ApplyChange) {
+	p.Changes = append(p.Changes, change)
+	switch change.Action {
+	case "create":
+		p.Summary.Create++
+	case "update":
+		p.Summary.Update++
+	case "delete":
+		p.Summary.Delete++
+	}
+}
+
+// validateDesiredState checks required fields and name uniqueness
+func validateDesiredState(state *DesiredState) error {
+	switchNames := make(map[string]bool)
+	portNames := make(map[string]bool)
+	for _, sw := range state.Switches {
+		if sw.Name == "" {
+			return fmt.Errorf("switch name is required")
+		}
+		if switchNames[sw.Name] {
+			return fmt.Errorf("switch %s is declared more than once", sw.Name)
+		}
+		switchNames[sw.Name] = true
+
+		for _, port := range sw.Ports {
+			if port.Name == "" {
+				return fmt.Errorf("switch %s: port name is required", sw.Name)
+			}
+			// Port names are unique across the northbound database
+			if portNames[port.Name] {
+				return fmt.Errorf("port %s is declared more than once", port.Name)
+			}
+			portNames[port.Name] = true
+		}
+
+		aclKeys := make(map[string]bool)
+		for _, acl := range sw.ACLs {
+			if acl.Direction != "from-lport" && acl.Direction != "to-lport" {
+				return fmt.Errorf("switch %s: ACL direction must be 'from-lport' or 'to-lport'", sw.Name)
+			}
+			if acl.Match == "" || acl.Action == "" {
+				return fmt.Errorf("switch %s: ACL match and action are required", sw.Name)
+			}
+			if acl.Priority < 0 || acl.Priority > 32767 {
+				return fmt.Errorf("switch %s: ACL priority must be between 0 and 32767", sw.Name)
+			}
+			key := aclKey(acl.Direction, acl.Priority, acl.Match)
+			if aclKeys[key] {
+				return fmt.Errorf("switch %s: ACL %s is declared more than once", sw.Name, aclName(acl.Name, acl.Direction, acl.Priority, acl.Match))
+			}
+			aclKeys[key] = true
+		}
+	}
+
+	routerNames := make(map[string]bool)
+	for _, lr := range state.Routers {
+		if lr.Name == "" {
+			return fmt.Errorf("router name is required")
+		}
+		if routerNames[lr.Name] {
+			return fmt.Errorf("router %s is declared more than once", lr.Name)
+		}
+		routerNames[lr.Name] = true
+	}
+
+	return nil
+}
+
+func diffSwitch(desired *DesiredSwitch, current *models.LogicalSwitch) []string {
+	var fields []string
+	if !optionsMatch(desired.OtherConfig, current.OtherConfig) {
+		fields = append(fields, "other_config")
+	}
+	return fields
+}
+
+func diffPort(desired *DesiredPort, current *models.LogicalSwitchPort) []string {
+	var fields []string
+	if desired.Type != "" && desired.Type != current.Type {
+		fields = append(fields, "type")
+	}
+	if desired.Addresses != nil && !sameStrings(desired.Addresses, current.Addresses) {
+		fields = append(fields, "addresses")
+	}
+	if desired.PortSecurity != nil && !sameStrings(desired.PortSecurity, current.PortSecurity) {
+		fields = append(fields, "port_security")
+	}
+	if !optionsMatch(desired.Options, current.Options) {
+		fields = append(fields, "options")
+	}
+	if desired.Enabled != nil && (current.Enabled == nil || *desired.Enabled != *current.Enabled) {
+		fields = append(fields, "enabled")
+	}
+	return fields
+}
+
+func diffACL(desired *DesiredACL, current *models.ACL) []string {
+	var fields []string
+	if desired.Action != current.Action {
+		fields = append(fields, "action")
+	}
+	if desired.Log != current.Log {
+		fields = append(fields, "log")
+	}
+	if desired.Name != "" && desired.Name != current.Name {
+		fields = append(fields, "name")
+	}
+	if desired.Severity != "" && desired.Severity != current.Severity {
+		fields = append(fields, "severity")
+	}
+	return fields
+}
+
+// optionsMatch reports whether a declared map equals the current one. A nil
+// declared map is unmanaged and always matches.
+func optionsMatch(desired, current map[string]string) bool {
+	if desired == nil {
+		return true
+	}
+	if len(desired) != len(current) {
+		return false
+	}
+	for k, v := range desired {
+		if cv, ok := current[k]; !ok || cv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// sameStrings compares two lists ignoring order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa := append([]string(nil), a...)
+	sb := append([]string(nil), b...)
+	sort.Strings(sa)
+	sort.Strings(sb)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func aclKey(direction string, priority int, match string) string {
+	return fmt.Sprintf("%s/%d/%s", direction, priority, strings.TrimSpace(match))
+}
+
+func aclName(name, direction string, priority int, match string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s %d %s", direction, priority, match)
+}
+
+func desiredPortModel(desired *DesiredPort) *models.LogicalSwitchPort {
+	return &models.LogicalSwitchPort{
+		Name:         desired.Name,
+		Type:         desired.Type,
+		Addresses:    desired.Addresses,
+		PortSecurity: desired.PortSecurity,
+		Options:      desired.Options,
+		Enabled:      desired.Enabled,
+	}
+}
+
+func desiredACLModel(desired *DesiredACL) *models.ACL {
+	return &models.ACL{
+		Name:      desired.Name,
+		Direction: desired.Direction,
+		Priority:  desired.Priority,
+		Match:     desired.Match,
+		Action:    desired.Action,
+		Log:       desired.Log,
+		Severity:  desired.Severity,
+	}
+}
+
+func sortedSwitches(switches []*models.LogicalSwitch) []*models.LogicalSwitch {
+	sorted := append([]*models.LogicalSwitch(nil), switches...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func sortedRouters(routers []*models.LogicalRouter) []*models.LogicalRouter {
+	sorted := append([]*models.LogicalRouter(nil), routers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
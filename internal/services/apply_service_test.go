@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestApplyService_PlanCreatesMissingResources(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewApplyService(mockOVN, zap.NewNop())
+
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+
+	state := &DesiredState{
+		Switches: []DesiredSwitch{{
+			Name:  "web",
+			Ports: []DesiredPort{{Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}}},
+			ACLs:  []DesiredACL{{Direction: "to-lport", Priority: 1000, Match: "tcp.dst == 443", Action: "allow"}},
+		}},
+		Routers: []DesiredRouter{{Name: "edge"}},
+	}
+
+	plan, err := service.Plan(context.Background(), state, ApplyOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, ApplySummary{Create: 4}, plan.Summary)
+	// Parents are created before the ports and ACLs that reference them
+	var order []string
+	for _, change := range plan.Changes {
+		order = append(order, change.ResourceType)
+	}
+	assert.Equal(t, []string{"switch", "router", "port", "acl"}, order)
+	mockOVN.AssertExpectations(t)
+}
+
+func TestApplyService_PlanDiffsExistingResources(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewApplyService(mockOVN, zap.NewNop())
+
+	enabled := true
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-uuid", Name: "web", OtherConfig: map[string]string{"subnet": "10.0.1.0/24"}},
+	}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{
+		{UUID: "lr-uuid", Name: "edge"},
+	}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-uuid").Return([]*models.LogicalSwitchPort{
+		{UUID: "p1-uuid", Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}, Enabled: &enabled},
+		{UUID: "p2-uuid", Name: "web-2", Addresses: []string{"00:00:00:00:01:02 10.0.1.12"}},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-uuid").Return([]*models.ACL{
+		{UUID: "acl-uuid", Direction: "to-lport", Priority: 1000, Match: "tcp.dst == 443", Action: "allow"},
+	}, nil)
+
+	state := &DesiredState{
+		Switches: []DesiredSwitch{{
+			Name:        "web",
+			OtherConfig: map[string]string{"subnet": "10.0.1.0/24"},
+			Ports: []DesiredPort{
+				{Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}},
+				{Name: "web-2", Addresses: []string{"00:00:00:00:01:02 10.0.1.22"}},
+			},
+			ACLs: []DesiredACL{{Direction: "to-lport", Priority: 1000, Match: "tcp.dst == 443", Action: "allow-related"}},
+		}},
+		Routers: []DesiredRouter{{Name: "edge", Options: map[string]string{"chassis": "gw-1"}}},
+	}
+
+	plan, err := service.Plan(context.Background(), state, ApplyOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, ApplySummary{Update: 3, Unchanged: 2}, plan.Summary)
+
+	changes := make(map[string]*ApplyChange)
+	for _, change := range plan.Changes {
+		changes[change.ResourceType+"/"+change.Name] = change
+	}
+	assert.Equal(t, []string{"options"}, changes["router/edge"].Fields)
+	assert.Equal(t, []string{"addresses"}, changes["port/web-2"].Fields)
+	assert.Equal(t, "p2-uuid", changes["port/web-2"].ID)
+	assert.Equal(t, []string{"action"}, changes["acl/to-lport 1000 tcp.dst == 443"].Fields)
+}
+
+func TestApplyService_PlanPrune(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewApplyService(mockOVN, zap.NewNop())
+
+	managed := map[string]string{ApplyManagedByKey: ApplyManagedByValue}
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-uuid", Name: "web"},
+		{UUID: "old-uuid", Name: "old", ExternalIDs: managed},
+		{UUID: "manual-uuid", Name: "manual"},
+	}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{
+		{UUID: "lr-uuid", Name: "edge", ExternalIDs: managed},
+	}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-uuid").Return([]*models.LogicalSwitchPort{
+		{UUID: "p1-uuid", Name: "stale"},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-uuid").Return([]*models.ACL{}, nil)
+
+	state := &DesiredState{Switches: []DesiredSwitch{{Name: "web"}}}
+
+	plan, err := service.Plan(context.Background(), state, ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, plan.Summary.Delete)
+
+	plan, err = service.Plan(context.Background(), state, ApplyOptions{Prune: true})
+	require.NoError(t, err)
+
+	var deleted []string
+	for _, change := range plan.Changes {
+		assert.Equal(t, "delete", change.Action)
+		deleted = append(deleted, change.ResourceType+"/"+change.Name)
+	}
+	// Switches not created by apply are left alone
+	assert.Equal(t, []string{"port/stale", "switch/old", "router/edge"}, deleted)
+}
+
+func TestApplyService_ApplyRunsPlan(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewApplyService(mockOVN, zap.NewNop())
+
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+	mockOVN.On("CreateLogicalSwitch", mock.Anything, mock.MatchedBy(func(ls *models.LogicalSwitch) bool {
+		return ls.Name == "web" && ls.ExternalIDs[ApplyManagedByKey] == ApplyManagedByValue
+	})).Return(&models.LogicalSwitch{UUID: "sw-uuid", Name: "web"}, nil)
+	mockOVN.On("CreatePort", mock.Anything, "sw-uuid", mock.MatchedBy(func(port *models.LogicalSwitchPort) bool {
+		return port.Name == "web-1"
+	})).Return(&models.LogicalSwitchPort{UUID: "p1-uuid", Name: "web-1"}, nil)
+
+	state := &DesiredState{
+		Switches: []DesiredSwitch{{Name: "web", Ports: []DesiredPort{{Name: "web-1"}}}},
+	}
+
+	result, err := service.Apply(context.Background(), state, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 0, result.Applied)
+	mockOVN.AssertNotCalled(t, "CreateLogicalSwitch", mock.Anything, mock.Anything)
+
+	result, err = service.Apply(context.Background(), state, ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Applied)
+	mockOVN.AssertExpectations(t)
+}
+
+func TestApplyService_ApplyReportsPartialFailure(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewApplyService(mockOVN, zap.NewNop())
+
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+	mockOVN.On("CreateLogicalSwitch", mock.Anything, mock.Anything).Return(&models.LogicalSwitch{UUID: "sw-uuid", Name: "web"}, nil)
+	mockOVN.On("CreateLogicalRouter", mock.Anything, mock.Anything).Return((*models.LogicalRouter)(nil), errors.New("quota exceeded"))
+
+	state := &DesiredState{
+		Switches: []DesiredSwitch{{Name: "web"}},
+		Routers:  []DesiredRouter{{Name: "edge"}},
+	}
+
+	result, err := service.Apply(context.Background(), state, ApplyOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create router edge")
+	require.NotNil(t, result)
+	assert.Equal(t, 1, result.Applied)
+}
+
+func TestApplyService_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *DesiredState
+		err   string
+	}{
+		{
+			name:  "missing switch name",
+			state: &DesiredState{Switches: []DesiredSwitch{{}}},
+			err:   "switch name is required",
+		},
+		{
+			name:  "duplicate switch",
+			state: &DesiredState{Switches: []DesiredSwitch{{Name: "web"}, {Name: "web"}}},
+			err:   "switch web is declared more than once",
+		},
+		{
+			name: "duplicate port across switches",
+			state: &DesiredState{Switches: []DesiredSwitch{
+				{Name: "a", Ports: []DesiredPort{{Name: "p1"}}},
+				{Name: "b", Ports: []DesiredPort{{Name: "p1"}}},
+			}},
+			err: "port p1 is declared more than once",
+		},
+		{
+			name: "invalid ACL direction",
+			state: &DesiredState{Switches: []DesiredSwitch{
+				{Name: "web", ACLs: []DesiredACL{{Direction: "ingress", Match: "ip4", Action: "allow"}}},
+			}},
+			err: "ACL direction",
+		},
+		{
+			name:  "missing router name",
+			state: &DesiredState{Routers: []DesiredRouter{{}}},
+			err:   "router name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewApplyService(new(MockOVNService), zap.NewNop())
+
+			_, err := service.Plan(context.Background(), tt.state, ApplyOptions{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid desired state")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -2,29 +2,46 @@ package services
 
 import (
 	"context"
+	"errors"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/lspecian/ovncp/internal/metrics"
 	"github.com/lspecian/ovncp/internal/models"
 	"go.uber.org/zap"
 )
 
-// BatchProcessor handles batching of OVN operations for improved performance
+// ErrBatchQueueFull is returned when a batch queue can't take more
+// operations; callers should retry later (HTTP 429)
+var ErrBatchQueueFull = errors.New("batch queue full")
+
+// latencySamples is how many batch latencies the p99 is computed over
+const latencySamples = 1000
+
+// BatchProcessor handles batching of OVN operations for improved performance.
+// Each queue adapts its batch size to the load: batches grow while
+// operations back up and shrink when transactions fail. Queues are bounded;
+// operations beyond their size are rejected with ErrBatchQueueFull rather
+// than blocking the caller.
 type BatchProcessor struct {
-	service        OVNServiceInterface
-	logger         *zap.Logger
-	batchSize      int
-	batchTimeout   time.Duration
-	maxConcurrent  int
-	
-	// Channels for different operation types
-	createSwitchCh chan *batchItem
-	updateSwitchCh chan *batchItem
-	deleteSwitchCh chan *batchItem
-	createPortCh   chan *batchItem
-	updatePortCh   chan *batchItem
-	deletePortCh   chan *batchItem
-	
+	service       OVNServiceInterface
+	logger        *zap.Logger
+	batchTimeout  time.Duration
+	minBatchSize  int
+	maxBatchSize  int
+	queueSize     int
+	maxConcurrent int
+
+	// Queues of the different operation types
+	createSwitch *batchQueue
+	updateSwitch *batchQueue
+	deleteSwitch *batchQueue
+	createPort   *batchQueue
+	updatePort   *batchQueue
+	deletePort   *batchQueue
+
 	wg     sync.WaitGroup
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -43,237 +60,324 @@ type batchResult struct {
 	err  error
 }
 
+// batchQueue queues the operations of one type and tracks their statistics
+type batchQueue struct {
+	name    string
+	ch      chan *batchItem
+	process func([]batchItem) error
+
+	mu        sync.Mutex
+	size      int // Current batch size
+	pending   int // Operations queued or being processed
+	processed int64
+	failed    int64
+	rejected  int64
+	batches   int64
+	latencies []time.Duration // Of the last latencySamples batches
+	next      int             // Index in latencies of the next sample once full
+}
+
 // BatchProcessorConfig holds configuration for batch processor
 type BatchProcessorConfig struct {
-	BatchSize     int
+	BatchSize     int // Initial batch size
+	MinBatchSize  int
+	MaxBatchSize  int
 	BatchTimeout  time.Duration
 	MaxConcurrent int
+	QueueSize     int // Operations each queue holds before rejecting more
 }
 
 // DefaultBatchProcessorConfig returns default configuration
 func DefaultBatchProcessorConfig() *BatchProcessorConfig {
 	return &BatchProcessorConfig{
 		BatchSize:     100,
+		MinBatchSize:  10,
+		MaxBatchSize:  1000,
 		BatchTimeout:  100 * time.Millisecond,
 		MaxConcurrent: 4,
+		QueueSize:     10000,
 	}
 }
 
-// NewBatchProcessor creates a new batch processor
+// NewBatchProcessor creates a new batch processor. Unset sizes default to
+// BatchSize, and queues to ten batches of the largest size.
 func NewBatchProcessor(service OVNServiceInterface, cfg *BatchProcessorConfig, logger *zap.Logger) *BatchProcessor {
 	ctx, cancel := context.WithCancel(context.Background())
-	
+
+	minSize, maxSize := cfg.MinBatchSize, cfg.MaxBatchSize
+	if minSize < 1 || minSize > cfg.BatchSize {
+		minSize = cfg.BatchSize
+	}
+	if maxSize < cfg.BatchSize {
+		maxSize = cfg.BatchSize
+	}
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 10 * maxSize
+	}
+
 	bp := &BatchProcessor{
-		service:        service,
-		logger:         logger,
-		batchSize:      cfg.BatchSize,
-		batchTimeout:   cfg.BatchTimeout,
-		maxConcurrent:  cfg.MaxConcurrent,
-		createSwitchCh: make(chan *batchItem, cfg.BatchSize),
-		updateSwitchCh: make(chan *batchItem, cfg.BatchSize),
-		deleteSwitchCh: make(chan *batchItem, cfg.BatchSize),
-		createPortCh:   make(chan *batchItem, cfg.BatchSize),
-		updatePortCh:   make(chan *batchItem, cfg.BatchSize),
-		deletePortCh:   make(chan *batchItem, cfg.BatchSize),
-		ctx:            ctx,
-		cancel:         cancel,
-	}
-	
+		service:       service,
+		logger:        logger,
+		batchTimeout:  cfg.BatchTimeout,
+		minBatchSize:  minSize,
+		maxBatchSize:  maxSize,
+		queueSize:     queueSize,
+		maxConcurrent: cfg.MaxConcurrent,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	newQueue := func(name string, process func([]batchItem) error) *batchQueue {
+		return &batchQueue{
+			name:    name,
+			ch:      make(chan *batchItem, queueSize),
+			process: process,
+			size:    cfg.BatchSize,
+		}
+	}
+	bp.createSwitch = newQueue("create_switch", bp.processCreateSwitchBatch)
+	bp.updateSwitch = newQueue("update_switch", bp.processUpdateSwitchBatch)
+	bp.deleteSwitch = newQueue("delete_switch", bp.processDeleteSwitchBatch)
+	bp.createPort = newQueue("create_port", bp.processCreatePortBatch)
+	bp.updatePort = newQueue("update_port", bp.processUpdatePortBatch)
+	bp.deletePort = newQueue("delete_port", bp.processDeletePortBatch)
+
 	// Start batch workers
 	bp.start()
-	
+
 	return bp
 }
 
+func (bp *BatchProcessor) queues() []*batchQueue {
+	return []*batchQueue{bp.createSwitch, bp.updateSwitch, bp.deleteSwitch, bp.createPort, bp.updatePort, bp.deletePort}
+}
+
 // start initializes all batch workers
 func (bp *BatchProcessor) start() {
-	// Switch workers
-	bp.wg.Add(3)
-	go bp.batchWorker("create_switch", bp.createSwitchCh, bp.processCreateSwitchBatch)
-	go bp.batchWorker("update_switch", bp.updateSwitchCh, bp.processUpdateSwitchBatch)
-	go bp.batchWorker("delete_switch", bp.deleteSwitchCh, bp.processDeleteSwitchBatch)
-	
-	// Port workers
-	bp.wg.Add(3)
-	go bp.batchWorker("create_port", bp.createPortCh, bp.processCreatePortBatch)
-	go bp.batchWorker("update_port", bp.updatePortCh, bp.processUpdatePortBatch)
-	go bp.batchWorker("delete_port", bp.deletePortCh, bp.processDeletePortBatch)
+	for _, q := range bp.queues() {
+		bp.wg.Add(1)
+		go bp.batchWorker(q)
+	}
 }
 
 // Stop gracefully stops the batch processor
 func (bp *BatchProcessor) Stop() {
 	bp.cancel()
-	
+
 	// Close all channels
-	close(bp.createSwitchCh)
-	close(bp.updateSwitchCh)
-	close(bp.deleteSwitchCh)
-	close(bp.createPortCh)
-	close(bp.updatePortCh)
-	close(bp.deletePortCh)
-	
+	for _, q := range bp.queues() {
+		close(q.ch)
+	}
+
 	// Wait for all workers to finish
 	bp.wg.Wait()
 }
 
-// batchWorker is a generic batch processing worker
-func (bp *BatchProcessor) batchWorker(name string, ch chan *batchItem, processFn func([]batchItem)) {
+// batchWorker collects the operations of a queue into batches, processed
+// when they reach the queue's current batch size or on timeout
+func (bp *BatchProcessor) batchWorker(q *batchQueue) {
 	defer bp.wg.Done()
-	
+
 	ticker := time.NewTicker(bp.batchTimeout)
 	defer ticker.Stop()
-	
-	batch := make([]batchItem, 0, bp.batchSize)
-	
+
+	batch := make([]batchItem, 0, bp.maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		err := q.process(batch)
+		bp.record(q, len(batch), time.Since(start), err)
+		batch = batch[:0]
+	}
+
 	for {
+		// Operations queued or collected but not yet processed
+		metrics.SetBatchQueueDepth(q.name, len(q.ch)+len(batch))
+
 		select {
-		case item, ok := <-ch:
+		case item, ok := <-q.ch:
 			if !ok {
 				// Channel closed, process remaining batch
-				if len(batch) > 0 {
-					processFn(batch)
-				}
+				flush()
 				return
 			}
-			
+
 			batch = append(batch, *item)
-			
+
 			// Process batch if it's full
-			if len(batch) >= bp.batchSize {
-				processFn(batch)
-				batch = batch[:0]
+			if len(batch) >= q.batchSize() {
+				flush()
 				ticker.Reset(bp.batchTimeout)
 			}
-			
+
 		case <-ticker.C:
 			// Process batch on timeout
-			if len(batch) > 0 {
-				processFn(batch)
-				batch = batch[:0]
-			}
-			
+			flush()
+
 		case <-bp.ctx.Done():
 			// Shutdown requested, process remaining batch
-			if len(batch) > 0 {
-				processFn(batch)
-			}
+			flush()
 			return
 		}
 	}
 }
 
-// Logical Switch batch operations
+func (q *batchQueue) batchSize() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
 
-// CreateLogicalSwitchBatch creates multiple switches in a batch
-func (bp *BatchProcessor) CreateLogicalSwitchBatch(ctx context.Context, switches []*models.LogicalSwitch) error {
-	resultChs := make([]chan batchResult, len(switches))
-	
-	// Queue all items
-	for i, sw := range switches {
-		resultCh := make(chan batchResult, 1)
-		resultChs[i] = resultCh
-		
-		select {
-		case bp.createSwitchCh <- &batchItem{
-			ctx:      ctx,
-			data:     sw,
-			resultCh: resultCh,
-		}:
-		case <-ctx.Done():
-			return ctx.Err()
+// record accounts for a processed batch and adapts the batch size: failed
+// batches halve it, and batches leaving at least as many operations queued
+// double it
+func (bp *BatchProcessor) record(q *batchQueue, n int, latency time.Duration, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending -= n
+	q.batches++
+	if len(q.latencies) < latencySamples {
+		q.latencies = append(q.latencies, latency)
+	} else {
+		q.latencies[q.next] = latency
+		q.next = (q.next + 1) % latencySamples
+	}
+
+	previous := q.size
+	if err != nil {
+		q.failed += int64(n)
+		q.size = max(q.size/2, bp.minBatchSize)
+	} else {
+		q.processed += int64(n)
+		if len(q.ch) >= q.size {
+			q.size = min(q.size*2, bp.maxBatchSize)
 		}
 	}
-	
+	if q.size != previous {
+		bp.logger.Debug("Adapted batch size",
+			zap.String("queue", q.name),
+			zap.Int("from", previous),
+			zap.Int("to", q.size),
+			zap.Bool("failed", err != nil))
+	}
+}
+
+// submit queues the operations on data and waits for their results. It
+// fails with ErrBatchQueueFull, queuing none of them, if the queue can't
+// take them all.
+func (bp *BatchProcessor) submit(ctx context.Context, q *batchQueue, data []interface{}) ([]batchResult, error) {
+	q.mu.Lock()
+	if q.pending+len(data) > bp.queueSize {
+		q.rejected += int64(len(data))
+		q.mu.Unlock()
+		return nil, ErrBatchQueueFull
+	}
+	q.pending += len(data)
+	q.mu.Unlock()
+
+	// Reserved operations fit in the channel, so sends never block
+	resultChs := make([]chan batchResult, len(data))
+	for i, d := range data {
+		resultChs[i] = make(chan batchResult, 1)
+		q.ch <- &batchItem{ctx: ctx, data: d, resultCh: resultChs[i]}
+	}
+
 	// Wait for all results
-	var firstErr error
+	results := make([]batchResult, len(data))
 	for i, resultCh := range resultChs {
 		select {
-		case result := <-resultCh:
-			if result.err != nil && firstErr == nil {
-				firstErr = result.err
-			}
-			// Update the switch with any returned data (like UUID)
-			if result.data != nil {
-				*switches[i] = *result.data.(*models.LogicalSwitch)
-			}
+		case results[i] = <-resultCh:
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		}
 	}
-	
-	return firstErr
+	return results, nil
 }
 
-// processCreateSwitchBatch processes a batch of switch creations
-func (bp *BatchProcessor) processCreateSwitchBatch(items []batchItem) {
-	bp.logger.Debug("Processing switch creation batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
-	ops := make([]TransactionOp, 0, len(items))
-	for _, item := range items {
-		sw := item.data.(*models.LogicalSwitch)
-		ops = append(ops, TransactionOp{
-			Operation:    "create",
-			ResourceType: "logical_switch",
-			Data:         sw,
-		})
+// firstError returns the first error of results
+func firstError(results []batchResult) error {
+	for _, result := range results {
+		if result.err != nil {
+			return result.err
+		}
 	}
-	
-	// Execute as a single transaction
+	return nil
+}
+
+// executeBatch executes ops as a single transaction and sends the result to
+// each item, with the data of its operation when sendData is set
+func (bp *BatchProcessor) executeBatch(items []batchItem, ops []TransactionOp, sendData bool) error {
 	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
+
 	// Send results back
 	for i, item := range items {
 		result := batchResult{err: err}
-		if err == nil {
+		if err == nil && sendData {
 			result.data = ops[i].Data
 		}
 		item.resultCh <- result
 		close(item.resultCh)
 	}
+	return err
+}
+
+// Logical Switch batch operations
+
+// CreateLogicalSwitchBatch creates multiple switches in a batch
+func (bp *BatchProcessor) CreateLogicalSwitchBatch(ctx context.Context, switches []*models.LogicalSwitch) error {
+	data := make([]interface{}, len(switches))
+	for i, sw := range switches {
+		data[i] = sw
+	}
+	results, err := bp.submit(ctx, bp.createSwitch, data)
+	if err != nil {
+		return err
+	}
+
+	// Update the switches with any returned data (like UUID)
+	for i, result := range results {
+		if result.data != nil {
+			*switches[i] = *result.data.(*models.LogicalSwitch)
+		}
+	}
+	return firstError(results)
+}
+
+// processCreateSwitchBatch processes a batch of switch creations
+func (bp *BatchProcessor) processCreateSwitchBatch(items []batchItem) error {
+	bp.logger.Debug("Processing switch creation batch", zap.Int("size", len(items)))
+
+	ops := make([]TransactionOp, 0, len(items))
+	for _, item := range items {
+		ops = append(ops, TransactionOp{
+			Operation:    "create",
+			ResourceType: "logical_switch",
+			Data:         item.data.(*models.LogicalSwitch),
+		})
+	}
+	return bp.executeBatch(items, ops, true)
 }
 
 // UpdateLogicalSwitchBatch updates multiple switches in a batch
 func (bp *BatchProcessor) UpdateLogicalSwitchBatch(ctx context.Context, switches []*models.LogicalSwitch) error {
-	resultChs := make([]chan batchResult, len(switches))
-	
-	// Queue all items
+	data := make([]interface{}, len(switches))
 	for i, sw := range switches {
-		resultCh := make(chan batchResult, 1)
-		resultChs[i] = resultCh
-		
-		select {
-		case bp.updateSwitchCh <- &batchItem{
-			ctx:      ctx,
-			data:     sw,
-			resultCh: resultCh,
-		}:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		data[i] = sw
 	}
-	
-	// Wait for all results
-	var firstErr error
-	for _, resultCh := range resultChs {
-		select {
-		case result := <-resultCh:
-			if result.err != nil && firstErr == nil {
-				firstErr = result.err
-			}
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+	results, err := bp.submit(ctx, bp.updateSwitch, data)
+	if err != nil {
+		return err
 	}
-	
-	return firstErr
+	return firstError(results)
 }
 
 // processUpdateSwitchBatch processes a batch of switch updates
-func (bp *BatchProcessor) processUpdateSwitchBatch(items []batchItem) {
+func (bp *BatchProcessor) processUpdateSwitchBatch(items []batchItem) error {
 	bp.logger.Debug("Processing switch update batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
+
 	ops := make([]TransactionOp, 0, len(items))
 	for _, item := range items {
 		sw := item.data.(*models.LogicalSwitch)
@@ -284,154 +388,78 @@ func (bp *BatchProcessor) processUpdateSwitchBatch(items []batchItem) {
 			Data:         sw,
 		})
 	}
-	
-	// Execute as a single transaction
-	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
-	// Send results back
-	for _, item := range items {
-		item.resultCh <- batchResult{err: err}
-		close(item.resultCh)
-	}
+	return bp.executeBatch(items, ops, false)
 }
 
 // DeleteLogicalSwitchBatch deletes multiple switches in a batch
 func (bp *BatchProcessor) DeleteLogicalSwitchBatch(ctx context.Context, ids []string) error {
-	resultChs := make([]chan batchResult, len(ids))
-	
-	// Queue all items
+	data := make([]interface{}, len(ids))
 	for i, id := range ids {
-		resultCh := make(chan batchResult, 1)
-		resultChs[i] = resultCh
-		
-		select {
-		case bp.deleteSwitchCh <- &batchItem{
-			ctx:      ctx,
-			data:     id,
-			resultCh: resultCh,
-		}:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		data[i] = id
 	}
-	
-	// Wait for all results
-	var firstErr error
-	for _, resultCh := range resultChs {
-		select {
-		case result := <-resultCh:
-			if result.err != nil && firstErr == nil {
-				firstErr = result.err
-			}
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+	results, err := bp.submit(ctx, bp.deleteSwitch, data)
+	if err != nil {
+		return err
 	}
-	
-	return firstErr
+	return firstError(results)
 }
 
 // processDeleteSwitchBatch processes a batch of switch deletions
-func (bp *BatchProcessor) processDeleteSwitchBatch(items []batchItem) {
+func (bp *BatchProcessor) processDeleteSwitchBatch(items []batchItem) error {
 	bp.logger.Debug("Processing switch deletion batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
+
 	ops := make([]TransactionOp, 0, len(items))
 	for _, item := range items {
-		id := item.data.(string)
 		ops = append(ops, TransactionOp{
 			Operation:    "delete",
 			ResourceType: "logical_switch",
-			ResourceID:   id,
+			ResourceID:   item.data.(string),
 		})
 	}
-	
-	// Execute as a single transaction
-	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
-	// Send results back
-	for _, item := range items {
-		item.resultCh <- batchResult{err: err}
-		close(item.resultCh)
-	}
+	return bp.executeBatch(items, ops, false)
 }
 
 // Port batch operations
 
 // CreatePortBatch creates multiple ports in a batch
 func (bp *BatchProcessor) CreatePortBatch(ctx context.Context, ports []*models.LogicalSwitchPort) error {
-	resultChs := make([]chan batchResult, len(ports))
-	
-	// Queue all items
+	data := make([]interface{}, len(ports))
 	for i, port := range ports {
-		resultCh := make(chan batchResult, 1)
-		resultChs[i] = resultCh
-		
-		select {
-		case bp.createPortCh <- &batchItem{
-			ctx:      ctx,
-			data:     port,
-			resultCh: resultCh,
-		}:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		data[i] = port
 	}
-	
-	// Wait for all results
-	var firstErr error
-	for i, resultCh := range resultChs {
-		select {
-		case result := <-resultCh:
-			if result.err != nil && firstErr == nil {
-				firstErr = result.err
-			}
-			// Update the port with any returned data
-			if result.data != nil {
-				*ports[i] = *result.data.(*models.LogicalSwitchPort)
-			}
-		case <-ctx.Done():
-			return ctx.Err()
+	results, err := bp.submit(ctx, bp.createPort, data)
+	if err != nil {
+		return err
+	}
+
+	// Update the ports with any returned data
+	for i, result := range results {
+		if result.data != nil {
+			*ports[i] = *result.data.(*models.LogicalSwitchPort)
 		}
 	}
-	
-	return firstErr
+	return firstError(results)
 }
 
 // processCreatePortBatch processes a batch of port creations
-func (bp *BatchProcessor) processCreatePortBatch(items []batchItem) {
+func (bp *BatchProcessor) processCreatePortBatch(items []batchItem) error {
 	bp.logger.Debug("Processing port creation batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
+
 	ops := make([]TransactionOp, 0, len(items))
 	for _, item := range items {
-		port := item.data.(*models.LogicalSwitchPort)
 		ops = append(ops, TransactionOp{
 			Operation:    "create",
 			ResourceType: "logical_port",
-			Data:         port,
+			Data:         item.data.(*models.LogicalSwitchPort),
 		})
 	}
-	
-	// Execute as a single transaction
-	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
-	// Send results back
-	for i, item := range items {
-		result := batchResult{err: err}
-		if err == nil {
-			result.data = ops[i].Data
-		}
-		item.resultCh <- result
-		close(item.resultCh)
-	}
+	return bp.executeBatch(items, ops, true)
 }
 
 // processUpdatePortBatch processes a batch of port updates
-func (bp *BatchProcessor) processUpdatePortBatch(items []batchItem) {
+func (bp *BatchProcessor) processUpdatePortBatch(items []batchItem) error {
 	bp.logger.Debug("Processing port update batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
+
 	ops := make([]TransactionOp, 0, len(items))
 	for _, item := range items {
 		port := item.data.(*models.LogicalSwitchPort)
@@ -442,40 +470,79 @@ func (bp *BatchProcessor) processUpdatePortBatch(items []batchItem) {
 			Data:         port,
 		})
 	}
-	
-	// Execute as a single transaction
-	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
-	// Send results back
-	for _, item := range items {
-		item.resultCh <- batchResult{err: err}
-		close(item.resultCh)
-	}
+	return bp.executeBatch(items, ops, false)
 }
 
 // processDeletePortBatch processes a batch of port deletions
-func (bp *BatchProcessor) processDeletePortBatch(items []batchItem) {
+func (bp *BatchProcessor) processDeletePortBatch(items []batchItem) error {
 	bp.logger.Debug("Processing port deletion batch", zap.Int("size", len(items)))
-	
-	// Group operations by transaction
+
 	ops := make([]TransactionOp, 0, len(items))
 	for _, item := range items {
-		id := item.data.(string)
 		ops = append(ops, TransactionOp{
 			Operation:    "delete",
 			ResourceType: "logical_port",
-			ResourceID:   id,
+			ResourceID:   item.data.(string),
 		})
 	}
-	
-	// Execute as a single transaction
-	err := bp.service.ExecuteTransaction(context.Background(), ops)
-	
-	// Send results back
-	for _, item := range items {
-		item.resultCh <- batchResult{err: err}
-		close(item.resultCh)
+	return bp.executeBatch(items, ops, false)
+}
+
+// BatchQueueStats are the statistics of a batch queue
+type BatchQueueStats struct {
+	BatchSize         int     `json:"batch_size"` // Current, adapted batch size
+	Queued            int     `json:"queued"`     // Operations queued or being processed
+	Processed         int64   `json:"processed"`
+	Failed            int64   `json:"failed"`
+	Rejected          int64   `json:"rejected"` // Operations refused because the queue was full
+	Batches           int64   `json:"batches"`
+	P99LatencySeconds float64 `json:"p99_latency_seconds"` // Of the last batches' transactions
+}
+
+// BatchStats are the statistics of a batch processor, by queue
+type BatchStats struct {
+	BatchTimeout  time.Duration              `json:"batch_timeout"`
+	MinBatchSize  int                        `json:"min_batch_size"`
+	MaxBatchSize  int                        `json:"max_batch_size"`
+	QueueSize     int                        `json:"queue_size"`
+	MaxConcurrent int                        `json:"max_concurrent"`
+	Queues        map[string]BatchQueueStats `json:"queues"`
+}
+
+// Stats returns the statistics of each queue
+func (bp *BatchProcessor) Stats() *BatchStats {
+	stats := &BatchStats{
+		BatchTimeout:  bp.batchTimeout,
+		MinBatchSize:  bp.minBatchSize,
+		MaxBatchSize:  bp.maxBatchSize,
+		QueueSize:     bp.queueSize,
+		MaxConcurrent: bp.maxConcurrent,
+		Queues:        make(map[string]BatchQueueStats),
 	}
+	for _, q := range bp.queues() {
+		q.mu.Lock()
+		stats.Queues[q.name] = BatchQueueStats{
+			BatchSize:         q.size,
+			Queued:            q.pending,
+			Processed:         q.processed,
+			Failed:            q.failed,
+			Rejected:          q.rejected,
+			Batches:           q.batches,
+			P99LatencySeconds: percentile(q.latencies, 0.99).Seconds(),
+		}
+		q.mu.Unlock()
+	}
+	return stats
+}
+
+// percentile returns the p-th percentile of durations, 0 if there are none
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
 }
 
 // Utility functions
@@ -490,7 +557,7 @@ type BatchedService struct {
 func NewBatchedService(service OVNServiceInterface, cfg *BatchProcessorConfig, logger *zap.Logger) *BatchedService {
 	return &BatchedService{
 		OVNServiceInterface: service,
-		processor:          NewBatchProcessor(service, cfg, logger),
+		processor:           NewBatchProcessor(service, cfg, logger),
 	}
 }
 
@@ -504,7 +571,7 @@ func (bs *BatchedService) CreateLogicalSwitches(ctx context.Context, switches []
 		}
 		return nil
 	}
-	
+
 	return bs.processor.CreateLogicalSwitchBatch(ctx, switches)
 }
 
@@ -518,7 +585,7 @@ func (bs *BatchedService) UpdateLogicalSwitches(ctx context.Context, switches []
 		}
 		return nil
 	}
-	
+
 	return bs.processor.UpdateLogicalSwitchBatch(ctx, switches)
 }
 
@@ -531,7 +598,7 @@ func (bs *BatchedService) DeleteLogicalSwitches(ctx context.Context, ids []strin
 		}
 		return nil
 	}
-	
+
 	return bs.processor.DeleteLogicalSwitchBatch(ctx, ids)
 }
 
@@ -550,7 +617,7 @@ func (bs *BatchedService) CreatePorts(ctx context.Context, ports []*models.Logic
 		}
 		return nil
 	}
-	
+
 	return bs.processor.CreatePortBatch(ctx, ports)
 }
 
@@ -560,11 +627,6 @@ func (bs *BatchedService) Stop() {
 }
 
 // GetBatchStats returns batch processing statistics
-func (bs *BatchedService) GetBatchStats() map[string]interface{} {
-	// This could be enhanced to track actual statistics
-	return map[string]interface{}{
-		"batch_size":     bs.processor.batchSize,
-		"batch_timeout":  bs.processor.batchTimeout,
-		"max_concurrent": bs.processor.maxConcurrent,
-	}
-}
\ No newline at end of file
+func (bs *BatchedService) GetBatchStats() *BatchStats {
+	return bs.processor.Stats()
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestBatchProcessor_Backpressure(t *testing.T) {
+	release := make(chan struct{})
+	mockService := new(MockOVNService)
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		<-release
+	}).Return(nil)
+
+	bp := NewBatchProcessor(mockService, &BatchProcessorConfig{
+		BatchSize: 2, MinBatchSize: 1, MaxBatchSize: 4,
+		BatchTimeout: 10 * time.Millisecond, MaxConcurrent: 1, QueueSize: 3,
+	}, zap.NewNop())
+	defer bp.Stop()
+	ctx := context.Background()
+
+	done := make(chan error, 1)
+	go func() { done <- bp.DeleteLogicalSwitchBatch(ctx, []string{"a", "b", "c"}) }()
+	assert.Eventually(t, func() bool {
+		return bp.Stats().Queues["delete_switch"].Queued == 3
+	}, time.Second, 5*time.Millisecond)
+
+	// The queue is full: further operations are rejected, not blocked on
+	assert.ErrorIs(t, bp.DeleteLogicalSwitchBatch(ctx, []string{"d"}), ErrBatchQueueFull)
+	assert.Equal(t, int64(1), bp.Stats().Queues["delete_switch"].Rejected)
+
+	close(release)
+	require.NoError(t, <-done)
+	stats := bp.Stats().Queues["delete_switch"]
+	assert.Equal(t, 0, stats.Queued)
+	assert.Equal(t, int64(3), stats.Processed)
+	assert.Equal(t, int64(0), stats.Failed)
+	assert.Positive(t, stats.P99LatencySeconds)
+
+	require.NoError(t, bp.DeleteLogicalSwitchBatch(ctx, []string{"d"}))
+}
+
+func TestBatchProcessor_AdaptiveSize(t *testing.T) {
+	release := make(chan struct{})
+	mockService := new(MockOVNService)
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(errors.New("cluster error")).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		<-release
+	}).Return(nil).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	bp := NewBatchProcessor(mockService, &BatchProcessorConfig{
+		BatchSize: 2, MinBatchSize: 1, MaxBatchSize: 8,
+		BatchTimeout: 10 * time.Millisecond, MaxConcurrent: 1, QueueSize: 100,
+	}, zap.NewNop())
+	defer bp.Stop()
+	ctx := context.Background()
+
+	switches := func(n int) []*models.LogicalSwitch {
+		result := make([]*models.LogicalSwitch, n)
+		for i := range result {
+			result[i] = &models.LogicalSwitch{UUID: "sw", Name: "sw"}
+		}
+		return result
+	}
+
+	// Failed batches halve the batch size
+	assert.Error(t, bp.UpdateLogicalSwitchBatch(ctx, switches(2)))
+	stats := bp.Stats().Queues["update_switch"]
+	assert.Equal(t, 1, stats.BatchSize)
+	assert.Equal(t, int64(2), stats.Failed)
+
+	// Operations backing up behind a slow batch grow it
+	done := make(chan error, 1)
+	go func() { done <- bp.UpdateLogicalSwitchBatch(ctx, switches(20)) }()
+	assert.Eventually(t, func() bool {
+		return len(bp.updateSwitch.ch) == 19
+	}, time.Second, 5*time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+
+	stats = bp.Stats().Queues["update_switch"]
+	assert.Greater(t, stats.BatchSize, 1)
+	assert.LessOrEqual(t, stats.BatchSize, 8)
+	assert.Equal(t, int64(20), stats.Processed)
+	assert.Less(t, stats.Batches, int64(21), "batches grew past one operation")
+}
@@ -2,87 +2,208 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/metrics"
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 	"go.uber.org/zap"
 )
 
-// CachedOVNService wraps OVNService with caching
+// ErrInvalidCachePattern is returned when clearing a pattern that doesn't
+// match cached OVN data
+var ErrInvalidCachePattern = errors.New("invalid cache pattern")
+
+// cacheRefreshTimeout bounds a background refresh of a soft-expired value
+const cacheRefreshTimeout = 30 * time.Second
+
+// CachedOVNService wraps OVNService with caching. Concurrent misses for a key
+// share one load, and values past their soft TTL are served while a single
+// background load refreshes them, so expensive reads such as the topology
+// don't pile up on OVN under load.
 type CachedOVNService struct {
 	service OVNServiceInterface
-	cache   cache.Cache
+	cache   *clusterCache
 	logger  *zap.Logger
+
+	loads      cache.Group
+	refreshing sync.Map
 }
 
+var _ OVNServiceInterface = (*CachedOVNService)(nil)
+
 // NewCachedOVNService creates a new cached OVN service
-func NewCachedOVNService(service OVNServiceInterface, cache cache.Cache, logger *zap.Logger) *CachedOVNService {
-	return &CachedOVNService{
+func NewCachedOVNService(service OVNServiceInterface, c cache.Cache, logger *zap.Logger) *CachedOVNService {
+	s := &CachedOVNService{
 		service: service,
-		cache:   cache,
+		cache:   &clusterCache{Cache: c},
 		logger:  logger,
 	}
+	metrics.RegisterCache("ovn", s.GetCacheStats)
+	return s
 }
 
-// Logical Switch operations with caching
+// clusterCache scopes cached values to the OVN cluster selected in the
+// context. Invalidations apply to every cluster, which is conservative but
+// keeps callers free of cluster names. It also counts invalidations, so a
+// load that started before a write neither stores its result nor is shared
+// with readers arriving after the write.
+type clusterCache struct {
+	cache.Cache
+	version atomic.Uint64
+}
 
-func (s *CachedOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
-	// Generate cache key
-	cacheKey := cache.SwitchListKey(0, 0, nil)
-	
-	// Try to get from cache
-	var switches []*models.LogicalSwitch
-	err := s.cache.Get(ctx, cacheKey, &switches)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return switches, nil
+// scopedKey suffixes key with the cluster selected in ctx
+func scopedKey(ctx context.Context, key string) string {
+	return key + "@" + getOVNClusterFromContext(ctx)
+}
+
+func (c *clusterCache) Get(ctx context.Context, key string, dest interface{}) error {
+	return c.Cache.Get(ctx, scopedKey(ctx, key), dest)
+}
+
+func (c *clusterCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.Cache.Set(ctx, scopedKey(ctx, key), value, ttl)
+}
+
+func (c *clusterCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	scoped := make([]string, len(keys))
+	for i, key := range keys {
+		scoped[i] = scopedKey(ctx, key)
 	}
-	
-	// Cache miss, get from service
-	switches, err = s.service.ListLogicalSwitches(ctx)
-	if err != nil {
-		return nil, err
+	return c.Cache.Exists(ctx, scoped...)
+}
+
+func (c *clusterCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.Cache.TTL(ctx, scopedKey(ctx, key))
+}
+
+func (c *clusterCache) Delete(ctx context.Context, keys ...string) error {
+	c.version.Add(1)
+	for _, key := range keys {
+		if err := c.Cache.Clear(ctx, key+"@*"); err != nil {
+			return err
+		}
 	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("switch", "list")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, switches, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache switches", zap.Error(err))
+	return nil
+}
+
+func (c *clusterCache) Clear(ctx context.Context, pattern string) error {
+	c.version.Add(1)
+	return c.Cache.Clear(ctx, pattern+"@*")
+}
+
+// cachedEntry is a cached value with the time it was loaded at
+type cachedEntry[T any] struct {
+	Value    T         `json:"value"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// cachedLoad serves key from the cache, calling load on a miss. Concurrent
+// misses share one load; a value past the key's soft TTL is served as is
+// and refreshed in the background.
+func cachedLoad[T any](ctx context.Context, s *CachedOVNService, key string, info cache.CacheKeyInfo, load func(context.Context) (T, error)) (T, error) {
+	var entry cachedEntry[T]
+	if err := s.cache.Get(ctx, key, &entry); err == nil {
+		s.logger.Debug("Cache hit", zap.String("key", key))
+		if time.Since(entry.LoadedAt) >= info.SoftTTL() {
+			refreshInBackground(ctx, s, key, info, load)
 		}
+		return entry.Value, nil
 	}
-	
-	return switches, nil
+
+	v, err, shared := s.loads.Do(loadKey(s, key), func() (interface{}, error) {
+		return loadAndStore(ctx, s, key, info, load)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	value := v.(T)
+	if shared {
+		// Callers sharing a load get their own copy, as they would from
+		// the cache
+		return cloneValue(value)
+	}
+	return value, nil
 }
 
-func (s *CachedOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
-	// Generate cache key
-	cacheKey := cache.SwitchKey(id)
-	
-	// Try to get from cache
-	var sw models.LogicalSwitch
-	err := s.cache.Get(ctx, cacheKey, &sw)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return &sw, nil
+// refreshInBackground reloads key unless a refresh of it is already running.
+// The refresh keeps the request's values, such as its tenant and cluster,
+// but not its cancellation.
+func refreshInBackground[T any](ctx context.Context, s *CachedOVNService, key string, info cache.CacheKeyInfo, load func(context.Context) (T, error)) {
+	if _, running := s.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
 	}
-	
-	// Cache miss, get from service
-	switchPtr, err := s.service.GetLogicalSwitch(ctx, id)
+
+	refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheRefreshTimeout)
+	go func() {
+		defer cancel()
+		defer s.refreshing.Delete(key)
+
+		_, err, _ := s.loads.Do(loadKey(s, key), func() (interface{}, error) {
+			return loadAndStore(refreshCtx, s, key, info, load)
+		})
+		if err != nil {
+			s.logger.Warn("Failed to refresh cache", zap.String("key", key), zap.Error(err))
+		}
+	}()
+}
+
+// loadAndStore loads key and caches the result, unless the cache was
+// invalidated while loading
+func loadAndStore[T any](ctx context.Context, s *CachedOVNService, key string, info cache.CacheKeyInfo, load func(context.Context) (T, error)) (interface{}, error) {
+	version := s.cache.version.Load()
+	value, err := load(ctx)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("switch", "get")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, switchPtr, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache switch", zap.Error(err))
+
+	if info.TTL > 0 && s.cache.version.Load() == version {
+		entry := cachedEntry[T]{Value: value, LoadedAt: time.Now()}
+		if err := s.cache.Set(ctx, key, entry, info.TTL); err != nil {
+			s.logger.Warn("Failed to cache value", zap.String("key", key), zap.Error(err))
 		}
 	}
-	
-	return switchPtr, nil
+	return value, nil
+}
+
+// loadKey keys loads by cache version, so readers arriving after a write
+// don't join a load that started before it
+func loadKey(s *CachedOVNService, key string) string {
+	return fmt.Sprintf("%d:%s", s.cache.version.Load(), key)
+}
+
+// cloneValue deep copies a loaded value
+func cloneValue[T any](value T) (T, error) {
+	var clone T
+	data, err := json.Marshal(value)
+	if err != nil {
+		return clone, err
+	}
+	err = json.Unmarshal(data, &clone)
+	return clone, err
+}
+
+// Logical Switch operations with caching
+
+func (s *CachedOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	return cachedLoad(ctx, s, cache.SwitchListKey(0, 0, nil), cache.GetCacheKeyInfo("switch", "list"),
+		s.service.ListLogicalSwitches)
+}
+
+func (s *CachedOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
+	return cachedLoad(ctx, s, cache.SwitchKey(id), cache.GetCacheKeyInfo("switch", "get"),
+		func(ctx context.Context) (*models.LogicalSwitch, error) {
+			return s.service.GetLogicalSwitch(ctx, id)
+		})
 }
 
 func (s *CachedOVNService) CreateLogicalSwitch(ctx context.Context, sw *models.LogicalSwitch) (*models.LogicalSwitch, error) {
@@ -136,61 +257,15 @@ func (s *CachedOVNService) DeleteLogicalSwitch(ctx context.Context, id string) e
 // Logical Router operations with caching
 
 func (s *CachedOVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
-	// Generate cache key
-	cacheKey := cache.RouterListKey(0, 0, nil)
-	
-	// Try to get from cache
-	var routers []*models.LogicalRouter
-	err := s.cache.Get(ctx, cacheKey, &routers)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return routers, nil
-	}
-	
-	// Cache miss, get from service
-	routers, err = s.service.ListLogicalRouters(ctx)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("router", "list")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, routers, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache routers", zap.Error(err))
-		}
-	}
-	
-	return routers, nil
+	return cachedLoad(ctx, s, cache.RouterListKey(0, 0, nil), cache.GetCacheKeyInfo("router", "list"),
+		s.service.ListLogicalRouters)
 }
 
 func (s *CachedOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
-	// Generate cache key
-	cacheKey := cache.RouterKey(id)
-	
-	// Try to get from cache
-	var router models.LogicalRouter
-	err := s.cache.Get(ctx, cacheKey, &router)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return &router, nil
-	}
-	
-	// Cache miss, get from service
-	routerPtr, err := s.service.GetLogicalRouter(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("router", "get")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, routerPtr, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache router", zap.Error(err))
-		}
-	}
-	
-	return routerPtr, nil
+	return cachedLoad(ctx, s, cache.RouterKey(id), cache.GetCacheKeyInfo("router", "get"),
+		func(ctx context.Context) (*models.LogicalRouter, error) {
+			return s.service.GetLogicalRouter(ctx, id)
+		})
 }
 
 func (s *CachedOVNService) CreateLogicalRouter(ctx context.Context, router *models.LogicalRouter) (*models.LogicalRouter, error) {
@@ -244,61 +319,17 @@ func (s *CachedOVNService) DeleteLogicalRouter(ctx context.Context, id string) e
 // Port operations with caching
 
 func (s *CachedOVNService) ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
-	// Generate cache key
-	cacheKey := cache.PortListKey(switchID, "switch")
-	
-	// Try to get from cache
-	var ports []*models.LogicalSwitchPort
-	err := s.cache.Get(ctx, cacheKey, &ports)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return ports, nil
-	}
-	
-	// Cache miss, get from service
-	ports, err = s.service.ListPorts(ctx, switchID)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("port", "list")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, ports, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache ports", zap.Error(err))
-		}
-	}
-	
-	return ports, nil
+	return cachedLoad(ctx, s, cache.PortListKey(switchID, "switch"), cache.GetCacheKeyInfo("port", "list"),
+		func(ctx context.Context) ([]*models.LogicalSwitchPort, error) {
+			return s.service.ListPorts(ctx, switchID)
+		})
 }
 
 func (s *CachedOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
-	// Generate cache key
-	cacheKey := cache.PortKey(id)
-	
-	// Try to get from cache
-	var port models.LogicalSwitchPort
-	err := s.cache.Get(ctx, cacheKey, &port)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return &port, nil
-	}
-	
-	// Cache miss, get from service
-	portPtr, err := s.service.GetPort(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("port", "get")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, portPtr, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache port", zap.Error(err))
-		}
-	}
-	
-	return portPtr, nil
+	return cachedLoad(ctx, s, cache.PortKey(id), cache.GetCacheKeyInfo("port", "get"),
+		func(ctx context.Context) (*models.LogicalSwitchPort, error) {
+			return s.service.GetPort(ctx, id)
+		})
 }
 
 func (s *CachedOVNService) CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
@@ -387,61 +418,17 @@ func (s *CachedOVNService) DeletePort(ctx context.Context, id string) error {
 // ACL operations with caching
 
 func (s *CachedOVNService) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
-	// Generate cache key
-	cacheKey := cache.ACLListKey(map[string]string{"switch": switchID})
-	
-	// Try to get from cache
-	var acls []*models.ACL
-	err := s.cache.Get(ctx, cacheKey, &acls)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return acls, nil
-	}
-	
-	// Cache miss, get from service
-	acls, err = s.service.ListACLs(ctx, switchID)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("acl", "list")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, acls, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache ACLs", zap.Error(err))
-		}
-	}
-	
-	return acls, nil
+	return cachedLoad(ctx, s, cache.ACLListKey(map[string]string{"switch": switchID}), cache.GetCacheKeyInfo("acl", "list"),
+		func(ctx context.Context) ([]*models.ACL, error) {
+			return s.service.ListACLs(ctx, switchID)
+		})
 }
 
 func (s *CachedOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
-	// Generate cache key
-	cacheKey := cache.ACLKey(id)
-	
-	// Try to get from cache
-	var acl models.ACL
-	err := s.cache.Get(ctx, cacheKey, &acl)
-	if err == nil {
-		s.logger.Debug("Cache hit", zap.String("key", cacheKey))
-		return &acl, nil
-	}
-	
-	// Cache miss, get from service
-	aclPtr, err := s.service.GetACL(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("acl", "get")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, aclPtr, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache ACL", zap.Error(err))
-		}
-	}
-	
-	return aclPtr, nil
+	return cachedLoad(ctx, s, cache.ACLKey(id), cache.GetCacheKeyInfo("acl", "get"),
+		func(ctx context.Context) (*models.ACL, error) {
+			return s.service.GetACL(ctx, id)
+		})
 }
 
 func (s *CachedOVNService) CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error) {
@@ -505,32 +492,378 @@ func (s *CachedOVNService) DeleteACL(ctx context.Context, id string) error {
 // Topology operation with caching
 
 func (s *CachedOVNService) GetTopology(ctx context.Context) (*Topology, error) {
-	// Generate cache key
-	cacheKey := cache.TopologyKey()
+	return cachedLoad(ctx, s, cache.TopologyKey(), cache.GetCacheKeyInfo("topology", "get"),
+		s.service.GetTopology)
+}
+
+// Paginated lists aren't cached: their options vary too much for entries to
+// be reused
+
+func (s *CachedOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	return s.service.ListLogicalSwitchesPage(ctx, opts)
+}
+
+func (s *CachedOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	return s.service.ListLogicalRoutersPage(ctx, opts)
+}
+
+func (s *CachedOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	return s.service.ListPortsPage(ctx, switchID, opts)
+}
+
+func (s *CachedOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	return s.service.ListACLsPage(ctx, switchID, opts)
+}
+
+func (s *CachedOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	createdACLs, err := s.service.CreateACLs(ctx, switchID, acls)
+	if err != nil {
+		return nil, err
+	}
 	
-	// Try to get from cache
-	var topology Topology
-	err := s.cache.Get(ctx, cacheKey, &topology)
-	if err == nil {
-		s.logger.Debug("Cache hit for topology")
-		return &topology, nil
+	s.invalidate(ctx, cache.ACLPattern(), cache.TopologyPattern())
+	return createdACLs, nil
+}
+
+// Load Balancer operations with caching
+
+func (s *CachedOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	return cachedLoad(ctx, s, cache.LoadBalancerListKey(), cache.GetCacheKeyInfo("load_balancer", "list"),
+		s.service.ListLoadBalancers)
+}
+
+func (s *CachedOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	return s.service.ListLoadBalancersPage(ctx, opts)
+}
+
+func (s *CachedOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	return cachedLoad(ctx, s, cache.LoadBalancerKey(id), cache.GetCacheKeyInfo("load_balancer", "get"),
+		func(ctx context.Context) (*models.LoadBalancer, error) {
+			return s.service.GetLoadBalancer(ctx, id)
+		})
+}
+
+func (s *CachedOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	createdLB, err := s.service.CreateLoadBalancer(ctx, lb)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Cache miss, get from service
-	topologyPtr, err := s.service.GetTopology(ctx)
+	s.invalidate(ctx, cache.LoadBalancerPattern(), cache.TopologyPattern())
+	return createdLB, nil
+}
+
+func (s *CachedOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	updatedLB, err := s.service.UpdateLoadBalancer(ctx, id, lb)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Store in cache
-	keyInfo := cache.GetCacheKeyInfo("topology", "get")
-	if keyInfo.TTL > 0 {
-		if err := s.cache.Set(ctx, cacheKey, topologyPtr, keyInfo.TTL); err != nil {
-			s.logger.Warn("Failed to cache topology", zap.Error(err))
-		}
+	s.invalidate(ctx, cache.LoadBalancerPattern(), cache.TopologyPattern())
+	return updatedLB, nil
+}
+
+func (s *CachedOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	if err := s.service.DeleteLoadBalancer(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.LoadBalancerPattern(), cache.TopologyPattern())
+	return nil
+}
+
+// Port groups and address sets are read by network policies, which need
+// them current, so they aren't cached
+
+func (s *CachedOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	return s.service.ListPortGroups(ctx)
+}
+
+func (s *CachedOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	return s.service.ListAddressSets(ctx)
+}
+
+func (s *CachedOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	return s.service.ListPortGroupACLs(ctx, portGroupID)
+}
+
+func (s *CachedOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	owned, err := s.service.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+	if err != nil {
+		return nil, err
 	}
 	
-	return topologyPtr, nil
+	s.invalidate(ctx, cache.TopologyPattern())
+	return owned, nil
+}
+
+// DNS records aren't cached, but writes change the switches they're
+// attached to
+
+func (s *CachedOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	return s.service.ListDNS(ctx)
+}
+
+func (s *CachedOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	return s.service.GetDNS(ctx, id)
+}
+
+func (s *CachedOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	created, err := s.service.CreateDNS(ctx, dns)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.SwitchPattern(), cache.TopologyPattern())
+	return created, nil
+}
+
+func (s *CachedOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	updated, err := s.service.UpdateDNS(ctx, id, dns)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.SwitchPattern(), cache.TopologyPattern())
+	return updated, nil
+}
+
+func (s *CachedOVNService) DeleteDNS(ctx context.Context, id string) error {
+	if err := s.service.DeleteDNS(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.SwitchPattern(), cache.TopologyPattern())
+	return nil
+}
+
+// Mirrors aren't cached, but writes change the ports they mirror
+
+func (s *CachedOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	return s.service.ListMirrors(ctx)
+}
+
+func (s *CachedOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	return s.service.GetMirror(ctx, id)
+}
+
+func (s *CachedOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	created, err := s.service.CreateMirror(ctx, mirror)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.PortPattern())
+	return created, nil
+}
+
+func (s *CachedOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	updated, err := s.service.UpdateMirror(ctx, id, mirror)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.PortPattern())
+	return updated, nil
+}
+
+func (s *CachedOVNService) DeleteMirror(ctx context.Context, id string) error {
+	if err := s.service.DeleteMirror(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.PortPattern())
+	return nil
+}
+
+// Sampling isn't cached and doesn't change the resources that are
+
+func (s *CachedOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	return s.service.ListSampleCollectors(ctx)
+}
+
+func (s *CachedOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	return s.service.GetSampleCollector(ctx, id)
+}
+
+func (s *CachedOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.service.CreateSampleCollector(ctx, collector)
+}
+
+func (s *CachedOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.service.UpdateSampleCollector(ctx, id, collector)
+}
+
+func (s *CachedOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	return s.service.DeleteSampleCollector(ctx, id)
+}
+
+func (s *CachedOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	return s.service.ListSamplingApps(ctx)
+}
+
+func (s *CachedOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	return s.service.SetSamplingApp(ctx, appType, id)
+}
+
+func (s *CachedOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	return s.service.GetACLSampling(ctx, aclID)
+}
+
+func (s *CachedOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	return s.service.SetACLSampling(ctx, aclID, sampling)
+}
+
+func (s *CachedOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	return s.service.SetSwitchSampling(ctx, switchID, sampling)
+}
+
+// Router policies aren't cached, but writes change the routers they belong
+// to
+
+func (s *CachedOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	return s.service.ListRouterPolicies(ctx, routerID)
+}
+
+func (s *CachedOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	return s.service.GetRouterPolicy(ctx, id)
+}
+
+func (s *CachedOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	created, err := s.service.CreateRouterPolicy(ctx, routerID, policy)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return created, nil
+}
+
+func (s *CachedOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	updated, err := s.service.UpdateRouterPolicy(ctx, id, policy)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return updated, nil
+}
+
+func (s *CachedOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	if err := s.service.DeleteRouterPolicy(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return nil
+}
+
+// Static routes aren't cached either
+
+func (s *CachedOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	return s.service.ListStaticRoutes(ctx, routerID)
+}
+
+func (s *CachedOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	return s.service.GetStaticRoute(ctx, id)
+}
+
+func (s *CachedOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	created, err := s.service.CreateStaticRoutes(ctx, routerID, routes)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return created, nil
+}
+
+func (s *CachedOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	updated, err := s.service.UpdateStaticRoute(ctx, id, route)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return updated, nil
+}
+
+func (s *CachedOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	if err := s.service.DeleteStaticRoute(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return nil
+}
+
+func (s *CachedOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	created, err := s.service.CreateRouterPort(ctx, routerID, port)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return created, nil
+}
+
+func (s *CachedOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	if err := s.service.DeleteRouterPort(ctx, id); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return nil
+}
+
+func (s *CachedOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	return s.service.ListGateways(ctx)
+}
+
+func (s *CachedOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	gateway, err := s.service.SetGatewayBinding(ctx, portID, binding)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return gateway, nil
+}
+
+func (s *CachedOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	if err := s.service.SetGatewayPriorities(ctx, priorities); err != nil {
+		return err
+	}
+	
+	s.invalidate(ctx, cache.RouterPattern(), cache.TopologyPattern())
+	return nil
+}
+
+// labelPatterns are the cached data holding the labels of each resource
+var labelPatterns = map[string]string{
+	models.ResourceSwitch: cache.SwitchPattern(),
+	models.ResourceRouter: cache.RouterPattern(),
+	models.ResourcePort:   cache.PortPattern(),
+	models.ResourceACL:    cache.ACLPattern(),
+}
+
+func (s *CachedOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	labels, err := s.service.SetLabels(ctx, resource, id, set, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, labelPatterns[resource], cache.TopologyPattern())
+	return labels, nil
+}
+
+// invalidate clears the cached data matching patterns
+func (s *CachedOVNService) invalidate(ctx context.Context, patterns ...string) {
+	for _, pattern := range patterns {
+		if err := s.cache.Clear(ctx, pattern); err != nil {
+			s.logger.Warn("Failed to invalidate cache", zap.String("pattern", pattern), zap.Error(err))
+		}
+	}
 }
 
 // Transaction executes multiple operations atomically (no caching for transactions)
@@ -546,19 +879,23 @@ func (s *CachedOVNService) ExecuteTransaction(ctx context.Context, ops []Transac
 	patterns := make(map[string]bool)
 	
 	for _, op := range ops {
-		switch op.ResourceType {
-		case "logical_switch":
+		resourceType := op.ResourceType
+		if resourceType == "" {
+			resourceType = op.Table
+		}
+		switch transactionResourceTypes[resourceType] {
+		case models.ResourceSwitch:
 			patterns[cache.SwitchPattern()] = true
-			patterns[cache.TopologyPattern()] = true
-		case "logical_router":
+		case models.ResourceRouter:
 			patterns[cache.RouterPattern()] = true
-			patterns[cache.TopologyPattern()] = true
-		case "logical_port":
+		case models.ResourcePort:
 			patterns[cache.PortPattern()] = true
-			patterns[cache.TopologyPattern()] = true
-		case "acl":
+		case models.ResourceACL:
 			patterns[cache.ACLPattern()] = true
+		case models.ResourceLoadBalancer:
+			patterns[cache.LoadBalancerPattern()] = true
 		}
+		patterns[cache.TopologyPattern()] = true
 	}
 	
 	// Clear all affected patterns
@@ -575,39 +912,56 @@ func (s *CachedOVNService) ExecuteTransaction(ctx context.Context, ops []Transac
 
 // Cache management methods
 
-// WarmCache pre-populates cache with frequently accessed data
+// WarmCache pre-populates cache with frequently accessed data of the cluster
+// selected in ctx
 func (s *CachedOVNService) WarmCache(ctx context.Context) error {
 	s.logger.Info("Warming cache")
 	
+	var errs []error
+	
 	// Warm up topology cache
 	if _, err := s.GetTopology(ctx); err != nil {
 		s.logger.Warn("Failed to warm topology cache", zap.Error(err))
+		errs = append(errs, fmt.Errorf("topology: %w", err))
 	}
 	
 	// Warm up switch list cache
 	if _, err := s.ListLogicalSwitches(ctx); err != nil {
 		s.logger.Warn("Failed to warm switch cache", zap.Error(err))
+		errs = append(errs, fmt.Errorf("switches: %w", err))
 	}
 	
 	// Warm up router list cache
 	if _, err := s.ListLogicalRouters(ctx); err != nil {
 		s.logger.Warn("Failed to warm router cache", zap.Error(err))
+		errs = append(errs, fmt.Errorf("routers: %w", err))
 	}
 	
 	s.logger.Info("Cache warming completed")
-	return nil
+	return errors.Join(errs...)
 }
 
-// ClearCache clears all cached data
-func (s *CachedOVNService) ClearCache(ctx context.Context) error {
-	patterns := []string{
-		cache.SwitchPattern(),
-		cache.RouterPattern(),
-		cache.PortPattern(),
-		cache.ACLPattern(),
-		cache.TopologyPattern(),
-		cache.LoadBalancerKey("*"),
-		cache.NATKey("*"),
+// cachePatterns match all cached OVN data
+var cachePatterns = []string{
+	cache.SwitchPattern(),
+	cache.RouterPattern(),
+	cache.PortPattern(),
+	cache.ACLPattern(),
+	cache.TopologyPattern(),
+	cache.LoadBalancerPattern(),
+	cache.NATPattern(),
+}
+
+// ClearCache clears cached data matching pattern in every cluster, or all
+// cached data when pattern is empty. Patterns must start with the key
+// prefix of a cached resource, e.g. "switch:*".
+func (s *CachedOVNService) ClearCache(ctx context.Context, pattern string) error {
+	patterns := cachePatterns
+	if pattern != "" {
+		if !isCachePattern(pattern) {
+			return fmt.Errorf("%w: %q", ErrInvalidCachePattern, pattern)
+		}
+		patterns = []string{pattern}
 	}
 	
 	for _, pattern := range patterns {
@@ -616,17 +970,24 @@ func (s *CachedOVNService) ClearCache(ctx context.Context) error {
 		}
 	}
 	
-	s.logger.Info("Cache cleared")
+	s.logger.Info("Cache cleared", zap.Strings("patterns", patterns))
 	return nil
 }
 
+// isCachePattern reports whether pattern only matches cached OVN data
+func isCachePattern(pattern string) bool {
+	for _, prefix := range cachePatterns {
+		if strings.HasPrefix(pattern, strings.TrimSuffix(prefix, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetCacheStats returns cache statistics
 func (s *CachedOVNService) GetCacheStats() cache.CacheStats {
-	if rc, ok := s.cache.(*cache.RedisCache); ok {
-		return rc.Stats()
-	}
-	if mc, ok := s.cache.(*cache.MemoryCache); ok {
-		return mc.Stats()
+	if sc, ok := s.cache.Cache.(interface{ Stats() cache.CacheStats }); ok {
+		return sc.Stats()
 	}
 	return cache.CacheStats{}
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestCachedOVNService_CoalescesMisses(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewCachedOVNService(mockOVN, cache.NewMemoryCache(zap.NewNop()), zap.NewNop())
+
+	release := make(chan struct{})
+	mockOVN.On("GetTopology", mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return(&Topology{Switches: []*models.LogicalSwitch{{UUID: "ls1"}}}, nil).Once()
+
+	const readers = 10
+	results := make([]*Topology, readers)
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topology, err := svc.GetTopology(ctx)
+			assert.NoError(t, err)
+			results[i] = topology
+		}(i)
+	}
+
+	// Let the readers pile up on the first load before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, topology := range results {
+		require.NotNil(t, topology)
+		assert.Equal(t, "ls1", topology.Switches[0].UUID)
+	}
+	assert.NotSame(t, results[0], results[1], "readers get their own copy")
+	mockOVN.AssertNumberOfCalls(t, "GetTopology", 1)
+
+	// Later reads are cache hits
+	_, err := svc.GetTopology(ctx)
+	require.NoError(t, err)
+	mockOVN.AssertNumberOfCalls(t, "GetTopology", 1)
+}
+
+func TestCachedOVNService_RefreshesSoftExpiredValues(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewCachedOVNService(mockOVN, cache.NewMemoryCache(zap.NewNop()), zap.NewNop())
+
+	info := cache.GetCacheKeyInfo("switch", "list")
+	stale := cachedEntry[[]*models.LogicalSwitch]{
+		Value:    []*models.LogicalSwitch{{UUID: "old"}},
+		LoadedAt: time.Now().Add(-info.SoftTTL() - time.Second),
+	}
+	key := cache.SwitchListKey(0, 0, nil)
+	require.NoError(t, svc.cache.Set(ctx, key, stale, info.TTL))
+
+	mockOVN.On("ListLogicalSwitches", mock.Anything).
+		Return([]*models.LogicalSwitch{{UUID: "new"}}, nil).Once()
+
+	// The stale value is served without waiting for the refresh
+	switches, err := svc.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "old", switches[0].UUID)
+
+	assert.Eventually(t, func() bool {
+		switches, err := svc.ListLogicalSwitches(ctx)
+		return err == nil && switches[0].UUID == "new"
+	}, time.Second, 10*time.Millisecond)
+	mockOVN.AssertNumberOfCalls(t, "ListLogicalSwitches", 1)
+}
+
+func TestCachedOVNService_WriteDiscardsLoadsInFlight(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewCachedOVNService(mockOVN, cache.NewMemoryCache(zap.NewNop()), zap.NewNop())
+
+	release := make(chan struct{})
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "ls1").
+		Run(func(mock.Arguments) { <-release }).
+		Return(&models.LogicalSwitch{UUID: "ls1", Name: "before"}, nil).Once()
+	mockOVN.On("UpdateLogicalSwitch", mock.Anything, "ls1", mock.Anything).
+		Return(&models.LogicalSwitch{UUID: "ls1", Name: "after"}, nil)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "ls1").
+		Return(&models.LogicalSwitch{UUID: "ls1", Name: "after"}, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sw, err := svc.GetLogicalSwitch(ctx, "ls1")
+		assert.NoError(t, err)
+		assert.Equal(t, "before", sw.Name)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	_, err := svc.UpdateLogicalSwitch(ctx, "ls1", &models.LogicalSwitch{Name: "after"})
+	require.NoError(t, err)
+	close(release)
+	<-done
+
+	// The load that started before the update wasn't cached
+	sw, err := svc.GetLogicalSwitch(ctx, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, "after", sw.Name)
+}
+
+func TestCachedOVNService_ScopesEntriesByCluster(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	svc := NewCachedOVNService(mockOVN, cache.NewMemoryCache(zap.NewNop()), zap.NewNop())
+
+	east := ContextWithOVNCluster(context.Background(), "us-east")
+	west := ContextWithOVNCluster(context.Background(), "eu-west")
+	mockOVN.On("GetLogicalSwitch", east, "ls1").Return(&models.LogicalSwitch{UUID: "ls1", Name: "east"}, nil).Once()
+	mockOVN.On("GetLogicalSwitch", west, "ls1").Return(&models.LogicalSwitch{UUID: "ls1", Name: "west"}, nil).Twice()
+
+	sw, err := svc.GetLogicalSwitch(east, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, "east", sw.Name)
+	sw, err = svc.GetLogicalSwitch(west, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, "west", sw.Name, "clusters don't share entries")
+
+	// Clearing applies to every cluster
+	require.NoError(t, svc.ClearCache(context.Background(), "switch:*"))
+	_, err = svc.GetLogicalSwitch(west, "ls1")
+	require.NoError(t, err)
+	mockOVN.AssertExpectations(t)
+
+	assert.ErrorIs(t, svc.ClearCache(context.Background(), "session:*"), ErrInvalidCachePattern)
+}
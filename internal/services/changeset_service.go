@@ -0,0 +1,653 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/templates"
+)
+
+// Changeset statuses. A changeset is drafted, submitted for approval and
+// approved or rejected; approved changesets can be executed any number of
+// times. Editing a changeset returns it to draft.
+const (
+	ChangesetStatusDraft    = "draft"
+	ChangesetStatusPending  = "pending_approval"
+	ChangesetStatusApproved = "approved"
+	ChangesetStatusRejected = "rejected"
+)
+
+var (
+	// ErrChangesetNotFound is returned for unknown changeset IDs
+	ErrChangesetNotFound = errors.New("changeset not found")
+
+	// ErrInvalidChangeset is wrapped by validation errors of changesets and
+	// their parameters
+	ErrInvalidChangeset = errors.New("invalid changeset")
+
+	// ErrChangesetStatus is wrapped by errors for actions the changeset's
+	// status doesn't allow, such as executing a changeset not yet approved
+	ErrChangesetStatus = errors.New("changeset status does not allow this action")
+
+	// ErrChangesetSelfReview is returned when the author reviews a changeset
+	ErrChangesetSelfReview = errors.New("changesets cannot be reviewed by their author")
+)
+
+// Changeset is a saved transaction. String values of its operations may use
+// the parameters as {{.name}}; a value that is only a placeholder takes the
+// parameter's value with its type, so numbers stay numbers.
+type Changeset struct {
+	ID          string                        `json:"id"`
+	Name        string                        `json:"name"`
+	Description string                        `json:"description,omitempty"`
+	Parameters  []templates.TemplateVariable  `json:"parameters,omitempty"`
+	Operations  []models.TransactionOperation `json:"operations"`
+	Status      string                        `json:"status"`
+	CreatedBy   string                        `json:"created_by,omitempty"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	UpdatedAt   time.Time                     `json:"updated_at"`
+	Review      *ChangesetReview              `json:"review,omitempty"`
+	Executions  []ChangesetExecution          `json:"executions,omitempty"`
+}
+
+// ChangesetReview records the approval or rejection of a changeset
+type ChangesetReview struct {
+	Approved   bool      `json:"approved"`
+	ReviewedBy string    `json:"reviewed_by,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+	Comment    string    `json:"comment,omitempty"`
+}
+
+// ChangesetExecution records one execution of a changeset
+type ChangesetExecution struct {
+	TransactionID string                 `json:"transaction_id,omitempty"`
+	ExecutedBy    string                 `json:"executed_by,omitempty"`
+	ExecutedAt    time.Time              `json:"executed_at"`
+	Parameters    map[string]interface{} `json:"parameters,omitempty"`
+	Success       bool                   `json:"success"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// ChangesetChange describes what one operation of a changeset will do
+type ChangesetChange struct {
+	ID         string   `json:"id"`
+	Action     string   `json:"action"` // create, update, delete
+	Resource   string   `json:"resource"`
+	Name       string   `json:"name,omitempty"`
+	ResourceID string   `json:"resource_id,omitempty"`
+	Parent     string   `json:"parent,omitempty"` // switch or router of a new resource
+	Fields     []string `json:"fields,omitempty"` // fields set by a create or update
+}
+
+// ChangesetSummary counts the changes of a plan
+type ChangesetSummary struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+// ChangesetPlan is what executing a changeset with the given parameters
+// would do. Operations are the rendered transaction operations.
+type ChangesetPlan struct {
+	ChangesetID string                        `json:"changeset_id"`
+	Status      string                        `json:"status"`
+	Parameters  map[string]interface{}        `json:"parameters,omitempty"`
+	Operations  []models.TransactionOperation `json:"operations"`
+	Changes     []ChangesetChange             `json:"changes"`
+	Summary     ChangesetSummary              `json:"summary"`
+}
+
+// ChangesetService manages saved, reviewable transactions
+type ChangesetService struct {
+	store      ChangesetStore
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+
+	// mu serializes changes to changesets, so that a changeset can't be
+	// edited while it is being approved or executed
+	mu sync.Mutex
+}
+
+// NewChangesetService creates a new changeset service
+func NewChangesetService(store ChangesetStore, ovnService OVNServiceInterface, logger *zap.Logger) *ChangesetService {
+	return &ChangesetService{
+		store:      store,
+		ovnService: ovnService,
+		logger:     logger,
+	}
+}
+
+// CreateChangeset validates and saves a new draft changeset
+func (s *ChangesetService) CreateChangeset(ctx context.Context, cs *Changeset, user string) (*Changeset, error) {
+	if err := validateChangeset(cs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	saved := &Changeset{
+		ID:          uuid.New().String(),
+		Name:        cs.Name,
+		Description: cs.Description,
+		Parameters:  cs.Parameters,
+		Operations:  cs.Operations,
+		Status:      ChangesetStatusDraft,
+		CreatedBy:   user,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.store.Save(saved); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Created changeset",
+		zap.String("id", saved.ID),
+		zap.String("name", saved.Name),
+		zap.String("user", user))
+	return saved, nil
+}
+
+// GetChangeset returns a changeset
+func (s *ChangesetService) GetChangeset(ctx context.Context, id string) (*Changeset, error) {
+	return s.store.Get(id)
+}
+
+// ListChangesets returns all changesets, optionally only those with status
+func (s *ChangesetService) ListChangesets(ctx context.Context, status string) ([]*Changeset, error) {
+	changesets, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		return changesets, nil
+	}
+
+	filtered := make([]*Changeset, 0, len(changesets))
+	for _, cs := range changesets {
+		if cs.Status == status {
+			filtered = append(filtered, cs)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateChangeset replaces the content of a changeset and returns it to
+// draft, discarding any review. Changesets pending approval can't be edited.
+func (s *ChangesetService) UpdateChangeset(ctx context.Context, id string, cs *Changeset, user string) (*Changeset, error) {
+	if err := validateChangeset(cs); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status == ChangesetStatusPending {
+		return nil, fmt.Errorf("%w: changeset is pending approval", ErrChangesetStatus)
+	}
+
+	existing.Name = cs.Name
+	existing.Description = cs.Description
+	existing.Parameters = cs.Parameters
+	existing.Operations = cs.Operations
+	existing.Status = ChangesetStatusDraft
+	existing.Review = nil
+	existing.UpdatedAt = time.Now()
+
+	if err := s.store.Save(existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// DeleteChangeset removes a changeset
+func (s *ChangesetService) DeleteChangeset(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.store.Delete(id)
+}
+
+// SubmitChangeset asks for approval of a draft or rejected changeset
+func (s *ChangesetService) SubmitChangeset(ctx context.Context, id, user string) (*Changeset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cs, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if cs.Status != ChangesetStatusDraft && cs.Status != ChangesetStatusRejected {
+		return nil, fmt.Errorf("%w: only draft or rejected changesets can be submitted, changeset is %s", ErrChangesetStatus, cs.Status)
+	}
+
+	cs.Status = ChangesetStatusPending
+	cs.Review = nil
+	cs.UpdatedAt = time.Now()
+
+	if err := s.store.Save(cs); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Submitted changeset for approval",
+		zap.String("id", cs.ID),
+		zap.String("user", user))
+	return cs, nil
+}
+
+// ReviewChangeset approves or rejects a changeset pending approval. The
+// author of a changeset can't review it.
+func (s *ChangesetService) ReviewChangeset(ctx context.Context, id, user string, approve bool, comment string) (*Changeset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cs, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if cs.Status != ChangesetStatusPending {
+		return nil, fmt.Errorf("%w: only changesets pending approval can be reviewed, changeset is %s", ErrChangesetStatus, cs.Status)
+	}
+	if user != "" && user == cs.CreatedBy {
+		return nil, ErrChangesetSelfReview
+	}
+
+	now := time.Now()
+	cs.Status = ChangesetStatusRejected
+	if approve {
+		cs.Status = ChangesetStatusApproved
+	}
+	cs.Review = &ChangesetReview{
+		Approved:   approve,
+		ReviewedBy: user,
+		ReviewedAt: now,
+		Comment:    comment,
+	}
+	cs.UpdatedAt = now
+
+	if err := s.store.Save(cs); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reviewed changeset",
+		zap.String("id", cs.ID),
+		zap.String("status", cs.Status),
+		zap.String("user", user))
+	return cs, nil
+}
+
+// PlanChangeset renders a changeset with params and describes the changes
+// executing it would make. Resources to update or delete must exist.
+func (s *ChangesetService) PlanChangeset(ctx context.Context, id string, params map[string]interface{}) (*ChangesetPlan, error) {
+	cs, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	values, operations, err := renderChangeset(cs, params)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ChangesetPlan{
+		ChangesetID: cs.ID,
+		Status:      cs.Status,
+		Parameters:  values,
+		Operations:  operations,
+		Changes:     make([]ChangesetChange, 0, len(operations)),
+	}
+
+	for _, op := range operations {
+		change := ChangesetChange{
+			ID:         op.ID,
+			Action:     op.Type,
+			Resource:   op.Resource,
+			ResourceID: op.ResourceID,
+			Fields:     sortedKeys(op.Data),
+		}
+		if name, ok := op.Data["name"].(string); ok {
+			change.Name = name
+		}
+		if op.Type == models.OperationCreate {
+			change.Parent = op.SwitchID
+			if op.RouterID != "" {
+				change.Parent = op.RouterID
+			}
+		}
+
+		if op.Type != models.OperationCreate {
+			current, err := s.currentName(ctx, op.Resource, op.ResourceID)
+			if err != nil {
+				return nil, fmt.Errorf("operation %s: %w", op.ID, err)
+			}
+			if change.Name == "" {
+				change.Name = current
+			}
+		}
+
+		switch op.Type {
+		case models.OperationCreate:
+			plan.Summary.Create++
+		case models.OperationUpdate:
+			plan.Summary.Update++
+		case models.OperationDelete:
+			plan.Summary.Delete++
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	return plan, nil
+}
+
+// ExecuteChangeset renders an approved changeset with params and runs it as
+// one transaction. The execution is recorded on the changeset whether or not
+// it succeeds.
+func (s *ChangesetService) ExecuteChangeset(ctx context.Context, id string, params map[string]interface{}, user string) (*models.TransactionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cs, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if cs.Status != ChangesetStatusApproved {
+		return nil, fmt.Errorf("%w: only approved changesets can be executed, changeset is %s", ErrChangesetStatus, cs.Status)
+	}
+
+	values, operations, err := renderChangeset(cs, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := RunTransaction(ctx, s.ovnService, operations)
+
+	execution := ChangesetExecution{
+		ExecutedBy: user,
+		ExecutedAt: time.Now(),
+		Parameters: values,
+	}
+	switch {
+	case err != nil:
+		execution.Error = err.Error()
+	case response.Success:
+		execution.TransactionID = response.TransactionID
+		execution.Success = true
+	default:
+		execution.TransactionID = response.TransactionID
+		execution.Error = response.Error
+	}
+	cs.Executions = append(cs.Executions, execution)
+
+	if saveErr := s.store.Save(cs); saveErr != nil {
+		s.logger.Error("Failed to record changeset execution",
+			zap.String("id", cs.ID),
+			zap.Error(saveErr))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Executed changeset",
+		zap.String("id", cs.ID),
+		zap.String("transaction_id", response.TransactionID),
+		zap.Bool("success", response.Success),
+		zap.String("user", user))
+	return response, nil
+}
+
+// currentName looks up the resource an update or delete targets and returns
+// its name. Resources without a lookup are assumed to exist.
+func (s *ChangesetService) currentName(ctx context.Context, resource, id string) (string, error) {
+	var name string
+	var err error
+	switch resource {
+	case models.ResourceSwitch:
+		var ls *models.LogicalSwitch
+		if ls, err = s.ovnService.GetLogicalSwitch(ctx, id); err == nil {
+			name = ls.Name
+		}
+	case models.ResourceRouter:
+		var lr *models.LogicalRouter
+		if lr, err = s.ovnService.GetLogicalRouter(ctx, id); err == nil {
+			name = lr.Name
+		}
+	case models.ResourcePort:
+		var port *models.LogicalSwitchPort
+		if port, err = s.ovnService.GetPort(ctx, id); err == nil {
+			name = port.Name
+		}
+	case models.ResourceACL:
+		var acl *models.ACL
+		if acl, err = s.ovnService.GetACL(ctx, id); err == nil {
+			name = acl.Name
+		}
+	case models.ResourceLoadBalancer:
+		var lb *models.LoadBalancer
+		if lb, err = s.ovnService.GetLoadBalancer(ctx, id); err == nil {
+			name = lb.Name
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s %s: %w", resource, id, err)
+	}
+	return name, nil
+}
+
+// validateChangeset checks a changeset before it is saved. Operations are
+// validated as written, before parameters are substituted, and may only use
+// declared parameters.
+func validateChangeset(cs *Changeset) error {
+	if cs.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidChangeset)
+	}
+	if len(cs.Operations) == 0 {
+		return fmt.Errorf("%w: at least one operation is required", ErrInvalidChangeset)
+	}
+	if len(cs.Operations) > models.MaxTransactionOperations {
+		return fmt.Errorf("%w: maximum %d operations per changeset", ErrInvalidChangeset, models.MaxTransactionOperations)
+	}
+
+	declared := make(map[string]interface{}, len(cs.Parameters))
+	for _, p := range cs.Parameters {
+		if !parameterNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrInvalidChangeset, p.Name)
+		}
+		if _, ok := declared[p.Name]; ok {
+			return fmt.Errorf("%w: parameter %s is declared more than once", ErrInvalidChangeset, p.Name)
+		}
+		if p.Default != nil {
+			if err := validateTemplateVariable(p, p.Default); err != nil {
+				return fmt.Errorf("%w: parameter %s: invalid default: %v", ErrInvalidChangeset, p.Name, err)
+			}
+		}
+		declared[p.Name] = p.Name
+	}
+
+	if err := ValidateTransactionOperations(cs.Operations); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChangeset, err)
+	}
+
+	// Rendering with every parameter set finds placeholders that are
+	// malformed or use undeclared parameters
+	for _, op := range cs.Operations {
+		if _, err := renderOperation(op, declared); err != nil {
+			return fmt.Errorf("%w: operation %s: %v", ErrInvalidChangeset, op.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// parameterNamePattern restricts parameter names to those usable as {{.name}}
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// placeholderPattern matches a value that is a single parameter placeholder
+var placeholderPattern = regexp.MustCompile(`^\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+// resolveParameters checks params against the changeset's parameters and
+// fills in defaults
+func resolveParameters(defs []templates.TemplateVariable, params map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(defs))
+	known := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		known[def.Name] = true
+
+		value, ok := params[def.Name]
+		if !ok {
+			if def.Default != nil {
+				values[def.Name] = def.Default
+				continue
+			}
+			if def.Required {
+				return nil, fmt.Errorf("%w: parameter %s is required", ErrInvalidChangeset, def.Name)
+			}
+			// Optional parameters without a default render as empty
+			values[def.Name] = ""
+			continue
+		}
+
+		if err := validateTemplateVariable(def, value); err != nil {
+			return nil, fmt.Errorf("%w: parameter %s: %v", ErrInvalidChangeset, def.Name, err)
+		}
+		values[def.Name] = value
+	}
+
+	for name := range params {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown parameter %s", ErrInvalidChangeset, name)
+		}
+	}
+
+	return values, nil
+}
+
+// renderChangeset substitutes params into the operations of a changeset and
+// validates the result
+func renderChangeset(cs *Changeset, params map[string]interface{}) (map[string]interface{}, []models.TransactionOperation, error) {
+	values, err := resolveParameters(cs.Parameters, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	operations := make([]models.TransactionOperation, len(cs.Operations))
+	for i, op := range cs.Operations {
+		rendered, err := renderOperation(op, values)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: operation %s: %v", ErrInvalidChangeset, op.ID, err)
+		}
+		operations[i] = rendered
+	}
+
+	if err := ValidateTransactionOperations(operations); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidChangeset, err)
+	}
+
+	return values, operations, nil
+}
+
+// renderOperation substitutes values into the IDs and data of op
+func renderOperation(op models.TransactionOperation, values map[string]interface{}) (models.TransactionOperation, error) {
+	var err error
+	if op.ResourceID, err = renderString(op.ResourceID, values); err != nil {
+		return op, fmt.Errorf("resource_id: %w", err)
+	}
+	if op.SwitchID, err = renderString(op.SwitchID, values); err != nil {
+		return op, fmt.Errorf("switch_id: %w", err)
+	}
+	if op.RouterID, err = renderString(op.RouterID, values); err != nil {
+		return op, fmt.Errorf("router_id: %w", err)
+	}
+
+	if op.Data != nil {
+		data, err := renderValue(op.Data, values)
+		if err != nil {
+			return op, fmt.Errorf("data: %w", err)
+		}
+		op.Data = data.(map[string]interface{})
+	}
+	return op, nil
+}
+
+// renderValue substitutes values into the strings, including map keys, of
+// a decoded JSON value. A string that is only a placeholder is replaced by
+// the parameter's value itself.
+func renderValue(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(v); m != nil {
+			if param, ok := values[m[1]]; ok {
+				return param, nil
+			}
+		}
+		return renderString(v, values)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			renderedKey, err := renderString(key, values)
+			if err != nil {
+				return nil, err
+			}
+			if rendered[renderedKey], err = renderValue(item, values); err != nil {
+				return nil, err
+			}
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if rendered[i], err = renderValue(item, values); err != nil {
+				return nil, err
+			}
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+// renderString executes s as a text template over values
+func renderString(s string, values map[string]interface{}) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return "", fmt.Errorf("template execution failed: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
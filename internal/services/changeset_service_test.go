@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/templates"
+)
+
+func newTestChangesetService(t *testing.T, mockService *MockOVNService) *ChangesetService {
+	store, err := NewFileChangesetStore(t.TempDir())
+	require.NoError(t, err)
+	return NewChangesetService(store, mockService, zap.NewNop())
+}
+
+// webTierChangeset creates a switch with a parameterized name and an ACL
+// with a parameterized priority on it
+func webTierChangeset() *Changeset {
+	return &Changeset{
+		Name: "web tier",
+		Parameters: []templates.TemplateVariable{
+			{Name: "env", Type: "string", Required: true},
+			{Name: "priority", Type: "number", Default: float64(1000)},
+		},
+		Operations: []models.TransactionOperation{
+			{
+				ID:       "sw",
+				Type:     models.OperationCreate,
+				Resource: models.ResourceSwitch,
+				Data:     map[string]interface{}{"name": "web-{{.env}}"},
+			},
+			{
+				ID:       "allow-http",
+				Type:     models.OperationCreate,
+				Resource: models.ResourceACL,
+				SwitchID: "$sw",
+				Data: map[string]interface{}{
+					"priority":  "{{.priority}}",
+					"direction": "to-lport",
+					"match":     "tcp.dst == 80",
+					"action":    "allow-related",
+				},
+			},
+		},
+	}
+}
+
+func TestChangesetService_Workflow(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockOVNService)
+	service := newTestChangesetService(t, mockService)
+
+	cs, err := service.CreateChangeset(ctx, webTierChangeset(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetStatusDraft, cs.Status)
+	assert.Equal(t, "alice", cs.CreatedBy)
+
+	// Drafts can't be executed or reviewed
+	_, err = service.ExecuteChangeset(ctx, cs.ID, map[string]interface{}{"env": "prod"}, "alice")
+	assert.ErrorIs(t, err, ErrChangesetStatus)
+	_, err = service.ReviewChangeset(ctx, cs.ID, "bob", true, "")
+	assert.ErrorIs(t, err, ErrChangesetStatus)
+
+	cs, err = service.SubmitChangeset(ctx, cs.ID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetStatusPending, cs.Status)
+
+	// Pending changesets can't be edited or approved by their author
+	_, err = service.UpdateChangeset(ctx, cs.ID, webTierChangeset(), "alice")
+	assert.ErrorIs(t, err, ErrChangesetStatus)
+	_, err = service.ReviewChangeset(ctx, cs.ID, "alice", true, "")
+	assert.ErrorIs(t, err, ErrChangesetSelfReview)
+
+	cs, err = service.ReviewChangeset(ctx, cs.ID, "bob", true, "looks good")
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetStatusApproved, cs.Status)
+	assert.Equal(t, "bob", cs.Review.ReviewedBy)
+
+	mockService.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		data, _ := ops[1].Data.(map[string]interface{})
+		return len(ops) == 2 && ops[1].SwitchID == "$sw" && data["priority"] == float64(1000)
+	})).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		ops[0].ResourceID = "switch-uuid"
+		ops[1].ResourceID = "acl-uuid"
+	}).Return(nil).Once()
+
+	response, err := service.ExecuteChangeset(ctx, cs.ID, map[string]interface{}{"env": "prod"}, "carol")
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, "switch-uuid", response.Results[0].ResourceID)
+	assert.Equal(t, "web-prod", response.Results[0].Data["name"])
+
+	// Approved changesets stay approved and record each execution
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(errors.New("client not connected")).Once()
+	_, err = service.ExecuteChangeset(ctx, cs.ID, map[string]interface{}{"env": "staging"}, "carol")
+	assert.Error(t, err)
+
+	cs, err = service.GetChangeset(ctx, cs.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetStatusApproved, cs.Status)
+	require.Len(t, cs.Executions, 2)
+	assert.True(t, cs.Executions[0].Success)
+	assert.Equal(t, "carol", cs.Executions[0].ExecutedBy)
+	assert.Equal(t, "prod", cs.Executions[0].Parameters["env"])
+	assert.False(t, cs.Executions[1].Success)
+	assert.Contains(t, cs.Executions[1].Error, "not connected")
+
+	// Editing an approved changeset requires approving it again
+	cs, err = service.UpdateChangeset(ctx, cs.ID, webTierChangeset(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetStatusDraft, cs.Status)
+	assert.Nil(t, cs.Review)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestChangesetService_Plan(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockOVNService)
+	service := newTestChangesetService(t, mockService)
+
+	changeset := webTierChangeset()
+	changeset.Parameters = append(changeset.Parameters, templates.TemplateVariable{Name: "old", Type: "string", Required: true})
+	changeset.Operations = append(changeset.Operations, models.TransactionOperation{
+		ID:         "retire",
+		Type:       models.OperationDelete,
+		Resource:   models.ResourceSwitch,
+		ResourceID: "{{.old}}",
+	})
+	cs, err := service.CreateChangeset(ctx, changeset, "alice")
+	require.NoError(t, err)
+
+	mockService.On("GetLogicalSwitch", mock.Anything, "old-uuid").Return(&models.LogicalSwitch{UUID: "old-uuid", Name: "web-legacy"}, nil)
+
+	plan, err := service.PlanChangeset(ctx, cs.ID, map[string]interface{}{"env": "prod", "old": "old-uuid", "priority": 2000})
+	require.NoError(t, err)
+	assert.Equal(t, ChangesetSummary{Create: 2, Delete: 1}, plan.Summary)
+	assert.Equal(t, "web-prod", plan.Changes[0].Name)
+	assert.Equal(t, "$sw", plan.Changes[1].Parent)
+	assert.Equal(t, 2000, plan.Operations[1].Data["priority"])
+	assert.Equal(t, "web-legacy", plan.Changes[2].Name)
+	assert.Equal(t, "old-uuid", plan.Changes[2].ResourceID)
+}
+
+func TestChangesetService_Parameters(t *testing.T) {
+	ctx := context.Background()
+	service := newTestChangesetService(t, new(MockOVNService))
+
+	cs, err := service.CreateChangeset(ctx, webTierChangeset(), "alice")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		err    string
+	}{
+		{
+			name:   "missing required parameter",
+			params: map[string]interface{}{},
+			err:    "parameter env is required",
+		},
+		{
+			name:   "unknown parameter",
+			params: map[string]interface{}{"env": "prod", "zone": "a"},
+			err:    "unknown parameter zone",
+		},
+		{
+			name:   "wrong type",
+			params: map[string]interface{}{"env": "prod", "priority": "high"},
+			err:    "parameter priority: expected number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.PlanChangeset(ctx, cs.ID, tt.params)
+			assert.ErrorIs(t, err, ErrInvalidChangeset)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestChangesetService_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cs *Changeset)
+		err    string
+	}{
+		{
+			name:   "missing name",
+			modify: func(cs *Changeset) { cs.Name = "" },
+			err:    "name is required",
+		},
+		{
+			name:   "no operations",
+			modify: func(cs *Changeset) { cs.Operations = nil },
+			err:    "at least one operation is required",
+		},
+		{
+			name: "undeclared parameter",
+			modify: func(cs *Changeset) {
+				cs.Operations[0].Data["name"] = "web-{{.region}}"
+			},
+			err: "operation sw: data",
+		},
+		{
+			name: "invalid operation",
+			modify: func(cs *Changeset) {
+				cs.Operations[1].SwitchID = ""
+			},
+			err: "switch_id is required for acl creation",
+		},
+		{
+			name: "duplicate parameter",
+			modify: func(cs *Changeset) {
+				cs.Parameters = append(cs.Parameters, templates.TemplateVariable{Name: "env", Type: "string"})
+			},
+			err: "parameter env is declared more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestChangesetService(t, new(MockOVNService))
+			cs := webTierChangeset()
+			tt.modify(cs)
+
+			_, err := service.CreateChangeset(context.Background(), cs, "alice")
+			assert.ErrorIs(t, err, ErrInvalidChangeset)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// ChangesetStore persists changesets
+type ChangesetStore interface {
+	// Save creates or replaces a changeset
+	Save(cs *Changeset) error
+
+	// Get returns a changeset, or ErrChangesetNotFound
+	Get(id string) (*Changeset, error)
+
+	// List returns all changesets, oldest first
+	List() ([]*Changeset, error)
+
+	// Delete removes a changeset, or returns ErrChangesetNotFound
+	Delete(id string) error
+}
+
+// changesetIDPattern keeps changeset IDs usable as file names
+var changesetIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// FileChangesetStore stores each changeset as a JSON file in a directory
+type FileChangesetStore struct {
+	basePath string
+	mu       sync.RWMutex
+}
+
+// NewFileChangesetStore creates a changeset store in basePath
+func NewFileChangesetStore(basePath string) (*FileChangesetStore, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create changeset directory: %w", err)
+	}
+
+	return &FileChangesetStore{
+		basePath: basePath,
+	}, nil
+}
+
+// Save writes the changeset, replacing the file atomically
+func (s *FileChangesetStore) Save(cs *Changeset) error {
+	if !changesetIDPattern.MatchString(cs.ID) {
+		return fmt.Errorf("invalid changeset id: %q", cs.ID)
+	}
+
+	data, err := json.MarshalIndent(cs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal changeset: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path(cs.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write changeset: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(cs.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write changeset: %w", err)
+	}
+	return nil
+}
+
+// Get reads a changeset
+func (s *FileChangesetStore) Get(id string) (*Changeset, error) {
+	if !changesetIDPattern.MatchString(id) {
+		return nil, ErrChangesetNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(s.path(id))
+}
+
+// List reads all changesets
+func (s *FileChangesetStore) List() ([]*Changeset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := filepath.Glob(filepath.Join(s.basePath, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changesets: %w", err)
+	}
+
+	changesets := make([]*Changeset, 0, len(files))
+	for _, file := range files {
+		cs, err := s.read(file)
+		if err != nil {
+			return nil, err
+		}
+		changesets = append(changesets, cs)
+	}
+
+	sort.Slice(changesets, func(i, j int) bool {
+		if changesets[i].CreatedAt.Equal(changesets[j].CreatedAt) {
+			return changesets[i].ID < changesets[j].ID
+		}
+		return changesets[i].CreatedAt.Before(changesets[j].CreatedAt)
+	})
+	return changesets, nil
+}
+
+// Delete removes a changeset's file
+func (s *FileChangesetStore) Delete(id string) error {
+	if !changesetIDPattern.MatchString(id) {
+		return ErrChangesetNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrChangesetNotFound
+		}
+		return fmt.Errorf("failed to delete changeset: %w", err)
+	}
+	return nil
+}
+
+func (s *FileChangesetStore) path(id string) string {
+	return filepath.Join(s.basePath, id+".json")
+}
+
+func (s *FileChangesetStore) read(path string) (*Changeset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrChangesetNotFound
+		}
+		return nil, fmt.Errorf("failed to read changeset: %w", err)
+	}
+
+	var cs Changeset
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return nil, fmt.Errorf("failed to parse changeset %s: %w", filepath.Base(path), err)
+	}
+	return &cs, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// chassisOverloadFactor is how many times the mean number of bound ports a
+// chassis must exceed to be reported as overloaded
+const chassisOverloadFactor = 1.5
+
+// ChassisSource lists the chassis of the southbound database.
+// ovn.ChassisInventory implements it.
+type ChassisSource interface {
+	ListChassis(ctx context.Context) ([]*models.Chassis, error)
+}
+
+// ChassisCapacity is a chassis with its load relative to the others
+type ChassisCapacity struct {
+	*models.Chassis
+	Load       float64 `json:"load"` // Bound ports over the mean of all chassis
+	Overloaded bool    `json:"overloaded"`
+}
+
+// ChassisReport is the inventory of the chassis and how the ports bound to
+// them are spread
+type ChassisReport struct {
+	Chassis    []ChassisCapacity `json:"chassis"`
+	Count      int               `json:"count"`
+	TotalPorts int               `json:"total_ports"`
+	MeanPorts  float64           `json:"mean_ports"`
+	Overloaded int               `json:"overloaded"`
+}
+
+// BuildChassisReport computes the load of each chassis. A chassis is
+// overloaded when it has more than chassisOverloadFactor times the mean
+// number of bound ports; with a single chassis there is nothing to compare.
+func BuildChassisReport(chassis []*models.Chassis) *ChassisReport {
+	report := &ChassisReport{Chassis: make([]ChassisCapacity, 0, len(chassis)), Count: len(chassis)}
+	for _, ch := range chassis {
+		report.TotalPorts += ch.Ports
+	}
+	if len(chassis) > 0 {
+		report.MeanPorts = roundLoad(float64(report.TotalPorts) / float64(len(chassis)))
+	}
+
+	for _, ch := range chassis {
+		capacity := ChassisCapacity{Chassis: ch}
+		if report.TotalPorts > 0 {
+			mean := float64(report.TotalPorts) / float64(len(chassis))
+			capacity.Load = roundLoad(float64(ch.Ports) / mean)
+			capacity.Overloaded = len(chassis) > 1 && float64(ch.Ports) > chassisOverloadFactor*mean
+		}
+		if capacity.Overloaded {
+			report.Overloaded++
+		}
+		report.Chassis = append(report.Chassis, capacity)
+	}
+	return report
+}
+
+// roundLoad rounds to two decimals
+func roundLoad(load float64) float64 {
+	return math.Round(load*100) / 100
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestBuildChassisReport(t *testing.T) {
+	t.Run("one overloaded chassis", func(t *testing.T) {
+		report := BuildChassisReport([]*models.Chassis{
+			{Name: "hv-1", Ports: 10},
+			{Name: "hv-2", Ports: 10},
+			{Name: "hv-3", Ports: 40},
+		})
+
+		assert.Equal(t, 60, report.TotalPorts)
+		assert.Equal(t, 20.0, report.MeanPorts)
+		assert.Equal(t, 1, report.Overloaded)
+		require.Len(t, report.Chassis, 3)
+		assert.Equal(t, 0.5, report.Chassis[0].Load)
+		assert.False(t, report.Chassis[0].Overloaded)
+		assert.Equal(t, 2.0, report.Chassis[2].Load)
+		assert.True(t, report.Chassis[2].Overloaded)
+	})
+
+	t.Run("single chassis", func(t *testing.T) {
+		report := BuildChassisReport([]*models.Chassis{{Name: "hv-1", Ports: 25}})
+		assert.Equal(t, 1.0, report.Chassis[0].Load)
+		assert.Zero(t, report.Overloaded)
+	})
+
+	t.Run("no ports bound", func(t *testing.T) {
+		report := BuildChassisReport([]*models.Chassis{{Name: "hv-1"}, {Name: "hv-2"}})
+		assert.Zero(t, report.MeanPorts)
+		assert.Zero(t, report.Chassis[1].Load)
+	})
+
+	t.Run("no chassis", func(t *testing.T) {
+		report := BuildChassisReport(nil)
+		assert.Empty(t, report.Chassis)
+		assert.Zero(t, report.Count)
+	})
+}
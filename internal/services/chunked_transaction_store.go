@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ChunkedTransactionStore persists chunked transactions and their
+// checkpoints
+type ChunkedTransactionStore interface {
+	// Save creates or replaces a chunked transaction
+	Save(tx *ChunkedTransaction) error
+
+	// Get returns a chunked transaction, or ErrChunkedTransactionNotFound
+	Get(id string) (*ChunkedTransaction, error)
+
+	// List returns all chunked transactions, oldest first
+	List() ([]*ChunkedTransaction, error)
+}
+
+// FileChunkedTransactionStore stores each chunked transaction as a JSON file
+// in a directory
+type FileChunkedTransactionStore struct {
+	basePath string
+	mu       sync.RWMutex
+}
+
+// NewFileChunkedTransactionStore creates a chunked transaction store in
+// basePath
+func NewFileChunkedTransactionStore(basePath string) (*FileChunkedTransactionStore, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create chunked transaction directory: %w", err)
+	}
+
+	return &FileChunkedTransactionStore{
+		basePath: basePath,
+	}, nil
+}
+
+// Save writes the chunked transaction, replacing the file atomically so that
+// a checkpoint is never half written
+func (s *FileChunkedTransactionStore) Save(tx *ChunkedTransaction) error {
+	if !changesetIDPattern.MatchString(tx.ID) {
+		return fmt.Errorf("invalid chunked transaction id: %q", tx.ID)
+	}
+
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chunked transaction: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path(tx.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write chunked transaction: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(tx.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write chunked transaction: %w", err)
+	}
+	return nil
+}
+
+// Get reads a chunked transaction
+func (s *FileChunkedTransactionStore) Get(id string) (*ChunkedTransaction, error) {
+	if !changesetIDPattern.MatchString(id) {
+		return nil, ErrChunkedTransactionNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(s.path(id))
+}
+
+// List reads all chunked transactions
+func (s *FileChunkedTransactionStore) List() ([]*ChunkedTransaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := filepath.Glob(filepath.Join(s.basePath, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunked transactions: %w", err)
+	}
+
+	transactions := make([]*ChunkedTransaction, 0, len(files))
+	for _, file := range files {
+		tx, err := s.read(file)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].CreatedAt.Equal(transactions[j].CreatedAt) {
+			return transactions[i].ID < transactions[j].ID
+		}
+		return transactions[i].CreatedAt.Before(transactions[j].CreatedAt)
+	})
+	return transactions, nil
+}
+
+func (s *FileChunkedTransactionStore) path(id string) string {
+	return filepath.Join(s.basePath, id+".json")
+}
+
+func (s *FileChunkedTransactionStore) read(path string) (*ChunkedTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrChunkedTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to read chunked transaction: %w", err)
+	}
+
+	var tx ChunkedTransaction
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse chunked transaction %s: %w", filepath.Base(path), err)
+	}
+	return &tx, nil
+}
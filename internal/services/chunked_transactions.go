@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Chunked transaction statuses. A failed transaction, including one
+// interrupted by a restart, can be resumed from its last completed chunk.
+const (
+	ChunkedTransactionStatusRunning   = "running"
+	ChunkedTransactionStatusCompleted = "completed"
+	ChunkedTransactionStatusFailed    = "failed"
+)
+
+var (
+	// ErrChunkedTransactionNotFound is returned for unknown chunked
+	// transaction IDs
+	ErrChunkedTransactionNotFound = errors.New("chunked transaction not found")
+
+	// ErrInvalidChunkedTransaction is wrapped by validation errors of chunked
+	// transaction requests
+	ErrInvalidChunkedTransaction = errors.New("invalid chunked transaction")
+
+	// ErrChunkedTransactionStatus is returned when resuming a chunked
+	// transaction that is running or completed
+	ErrChunkedTransactionStatus = errors.New("only failed chunked transactions can be resumed")
+)
+
+// ChunkedTransaction is a large transaction applied as consecutive OVSDB
+// transactions of ChunkSize operations. Chunks follow the order of the
+// operations, which validation makes dependency order: an operation only
+// refers to resources created before it. Each chunk is applied atomically
+// and checkpointed, recording the resources its creates made so that later
+// chunks can refer to them.
+type ChunkedTransaction struct {
+	ID              string                              `json:"id"`
+	Status          string                              `json:"status"`
+	TenantID        string                              `json:"tenant_id,omitempty"`
+	Operations      []models.TransactionOperation       `json:"operations"`
+	ChunkSize       int                                 `json:"chunk_size"`
+	Chunks          int                                 `json:"chunks"`
+	CompletedChunks int                                 `json:"completed_chunks"`           // The checkpoint
+	References      map[string]string                   `json:"references,omitempty"`       // UUIDs of created resources by operation ID
+	Results         []models.TransactionOperationResult `json:"results,omitempty"`          // Of the completed chunks
+	FailedOperation string                              `json:"failed_operation,omitempty"` // ID of the operation that failed, if one did
+	Error           string                              `json:"error,omitempty"`
+	Attempts        int                                 `json:"attempts"`
+	CreatedBy       string                              `json:"created_by,omitempty"`
+	CreatedAt       time.Time                           `json:"created_at"`
+	UpdatedAt       time.Time                           `json:"updated_at"`
+	CompletedAt     *time.Time                          `json:"completed_at,omitempty"`
+}
+
+// ChunkedTransactionService applies chunked transactions in the background
+type ChunkedTransactionService struct {
+	store      ChunkedTransactionStore
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+
+	// mu serializes status changes, so that a transaction is run by one
+	// goroutine at a time
+	mu sync.Mutex
+	wg sync.WaitGroup
+}
+
+// NewChunkedTransactionService creates a chunked transaction service.
+// Transactions left running by a previous process are marked failed, so that
+// they can be resumed.
+func NewChunkedTransactionService(store ChunkedTransactionStore, ovnService OVNServiceInterface, logger *zap.Logger) (*ChunkedTransactionService, error) {
+	transactions, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range transactions {
+		if tx.Status != ChunkedTransactionStatusRunning {
+			continue
+		}
+		tx.Status = ChunkedTransactionStatusFailed
+		tx.Error = "interrupted by a restart"
+		tx.UpdatedAt = time.Now()
+		if err := store.Save(tx); err != nil {
+			return nil, err
+		}
+		logger.Warn("Chunked transaction was interrupted",
+			zap.String("id", tx.ID),
+			zap.Int("completed_chunks", tx.CompletedChunks),
+			zap.Int("chunks", tx.Chunks))
+	}
+
+	return &ChunkedTransactionService{
+		store:      store,
+		ovnService: ovnService,
+		logger:     logger,
+	}, nil
+}
+
+// ValidateChunkedTransaction checks a chunked transaction request and
+// returns its chunk size
+func ValidateChunkedTransaction(req *models.ChunkedTransactionRequest) (int, error) {
+	if len(req.Operations) == 0 {
+		return 0, fmt.Errorf("%w: at least one operation is required", ErrInvalidChunkedTransaction)
+	}
+	if len(req.Operations) > models.MaxChunkedTransactionOperations {
+		return 0, fmt.Errorf("%w: maximum %d operations per chunked transaction",
+			ErrInvalidChunkedTransaction, models.MaxChunkedTransactionOperations)
+	}
+
+	chunkSize := req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = models.MaxTransactionOperations
+	}
+	if chunkSize < 1 || chunkSize > models.MaxTransactionOperations {
+		return 0, fmt.Errorf("%w: chunk_size must be between 1 and %d",
+			ErrInvalidChunkedTransaction, models.MaxTransactionOperations)
+	}
+
+	if err := ValidateTransactionOperations(req.Operations); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidChunkedTransaction, err)
+	}
+	return chunkSize, nil
+}
+
+// Start validates and saves a chunked transaction, then applies it in the
+// background for the tenant of ctx
+func (s *ChunkedTransactionService) Start(ctx context.Context, req *models.ChunkedTransactionRequest, user string) (*ChunkedTransaction, error) {
+	chunkSize, err := ValidateChunkedTransaction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tx := &ChunkedTransaction{
+		ID:         uuid.New().String(),
+		Status:     ChunkedTransactionStatusRunning,
+		TenantID:   getTenantFromContext(ctx),
+		Operations: req.Operations,
+		ChunkSize:  chunkSize,
+		Chunks:     (len(req.Operations) + chunkSize - 1) / chunkSize,
+		References: make(map[string]string),
+		Attempts:   1,
+		CreatedBy:  user,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.store.Save(tx); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Started chunked transaction",
+		zap.String("id", tx.ID),
+		zap.Int("operations", len(tx.Operations)),
+		zap.Int("chunks", tx.Chunks),
+		zap.String("user", user))
+	s.launch(tx)
+	return tx, nil
+}
+
+// Resume applies a failed chunked transaction from its last completed chunk
+func (s *ChunkedTransactionService) Resume(ctx context.Context, id, user string) (*ChunkedTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != ChunkedTransactionStatusFailed {
+		return nil, fmt.Errorf("%w: transaction is %s", ErrChunkedTransactionStatus, tx.Status)
+	}
+
+	tx.Status = ChunkedTransactionStatusRunning
+	tx.FailedOperation = ""
+	tx.Error = ""
+	tx.Attempts++
+	tx.UpdatedAt = time.Now()
+	if err := s.store.Save(tx); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Resumed chunked transaction",
+		zap.String("id", tx.ID),
+		zap.Int("completed_chunks", tx.CompletedChunks),
+		zap.Int("chunks", tx.Chunks),
+		zap.String("user", user))
+	s.launch(tx)
+	return tx, nil
+}
+
+// Get returns a chunked transaction of the tenant of ctx
+func (s *ChunkedTransactionService) Get(ctx context.Context, id string) (*ChunkedTransaction, error) {
+	tx, err := s.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID := getTenantFromContext(ctx); tenantID != "" && tx.TenantID != tenantID {
+		return nil, ErrChunkedTransactionNotFound
+	}
+	return tx, nil
+}
+
+// List returns the chunked transactions of the tenant of ctx, oldest first
+func (s *ChunkedTransactionService) List(ctx context.Context) ([]*ChunkedTransaction, error) {
+	transactions, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return transactions, nil
+	}
+	filtered := make([]*ChunkedTransaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if tx.TenantID == tenantID {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered, nil
+}
+
+// Wait waits for the transactions being applied
+func (s *ChunkedTransactionService) Wait() {
+	s.wg.Wait()
+}
+
+// launch applies tx in the background; the caller holds s.mu. The
+// goroutine works on its own copy, as tx is returned to the caller.
+func (s *ChunkedTransactionService) launch(tx *ChunkedTransaction) {
+	copied := *tx
+	copied.References = make(map[string]string, len(tx.References))
+	for id, resourceID := range tx.References {
+		copied.References[id] = resourceID
+	}
+	copied.Results = append([]models.TransactionOperationResult(nil), tx.Results...)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(&copied)
+	}()
+}
+
+// run applies the remaining chunks of tx, saving a checkpoint after each
+func (s *ChunkedTransactionService) run(tx *ChunkedTransaction) {
+	ctx := context.Background()
+	if tx.TenantID != "" {
+		ctx = ContextWithTenant(ctx, tx.TenantID)
+	}
+
+	for tx.CompletedChunks < tx.Chunks {
+		start := tx.CompletedChunks * tx.ChunkSize
+		end := min(start+tx.ChunkSize, len(tx.Operations))
+		chunk := resolveChunkReferences(tx.Operations[start:end], tx.References)
+
+		response, err := RunTransaction(ctx, s.ovnService, chunk)
+		if err != nil {
+			s.fail(tx, "", err.Error())
+			return
+		}
+		if !response.Success {
+			var failed string
+			for _, result := range response.Results {
+				if !result.Success && result.Error != errNotExecuted {
+					failed = result.ID
+					break
+				}
+			}
+			s.fail(tx, failed, response.Error)
+			return
+		}
+
+		for _, result := range response.Results {
+			if result.Type == models.OperationCreate {
+				tx.References[result.ID] = result.ResourceID
+			}
+		}
+		tx.Results = append(tx.Results, response.Results...)
+		tx.CompletedChunks++
+		tx.UpdatedAt = time.Now()
+		if tx.CompletedChunks == tx.Chunks {
+			tx.Status = ChunkedTransactionStatusCompleted
+			tx.CompletedAt = &tx.UpdatedAt
+		}
+
+		if err := s.save(tx); err != nil {
+			// Left running, the transaction is marked failed on restart;
+			// resuming it then applies this chunk again
+			s.logger.Error("Failed to checkpoint chunked transaction",
+				zap.String("id", tx.ID),
+				zap.Int("completed_chunks", tx.CompletedChunks),
+				zap.Error(err))
+			return
+		}
+	}
+
+	s.logger.Info("Completed chunked transaction",
+		zap.String("id", tx.ID),
+		zap.Int("operations", len(tx.Operations)),
+		zap.Int("chunks", tx.Chunks))
+}
+
+// fail records the failure of the chunk after the checkpoint. The chunk was
+// applied in one OVSDB transaction, so none of it took effect.
+func (s *ChunkedTransactionService) fail(tx *ChunkedTransaction, operation, message string) {
+	tx.Status = ChunkedTransactionStatusFailed
+	tx.FailedOperation = operation
+	tx.Error = message
+	tx.UpdatedAt = time.Now()
+
+	s.logger.Warn("Chunked transaction failed",
+		zap.String("id", tx.ID),
+		zap.Int("chunk", tx.CompletedChunks+1),
+		zap.Int("chunks", tx.Chunks),
+		zap.String("operation", operation),
+		zap.String("error", message))
+	if err := s.save(tx); err != nil {
+		s.logger.Error("Failed to save chunked transaction",
+			zap.String("id", tx.ID),
+			zap.Error(err))
+	}
+}
+
+func (s *ChunkedTransactionService) save(tx *ChunkedTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Save(tx)
+}
+
+// resolveChunkReferences returns operations with the "$<id>" references to
+// resources created by earlier chunks replaced by their UUIDs. References
+// to creates of the same chunk are resolved by its transaction.
+func resolveChunkReferences(operations []models.TransactionOperation, references map[string]string) []models.TransactionOperation {
+	resolve := func(id string) string {
+		if !strings.HasPrefix(id, "$") {
+			return id
+		}
+		if resourceID, ok := references[id[1:]]; ok {
+			return resourceID
+		}
+		return id
+	}
+
+	resolved := make([]models.TransactionOperation, len(operations))
+	for i, op := range operations {
+		op.SwitchID = resolve(op.SwitchID)
+		op.RouterID = resolve(op.RouterID)
+		resolved[i] = op
+	}
+	return resolved
+}
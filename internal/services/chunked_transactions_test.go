@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// memoryChunkedTransactionStore keeps chunked transactions in memory
+type memoryChunkedTransactionStore struct {
+	mu           sync.Mutex
+	transactions map[string]ChunkedTransaction
+}
+
+func newMemoryChunkedTransactionStore() *memoryChunkedTransactionStore {
+	return &memoryChunkedTransactionStore{transactions: make(map[string]ChunkedTransaction)}
+}
+
+func (s *memoryChunkedTransactionStore) Save(tx *ChunkedTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *tx
+	copied.References = make(map[string]string)
+	for id, resourceID := range tx.References {
+		copied.References[id] = resourceID
+	}
+	copied.Results = append([]models.TransactionOperationResult(nil), tx.Results...)
+	s.transactions[tx.ID] = copied
+	return nil
+}
+
+func (s *memoryChunkedTransactionStore) Get(id string) (*ChunkedTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.transactions[id]
+	if !ok {
+		return nil, ErrChunkedTransactionNotFound
+	}
+	return &tx, nil
+}
+
+func (s *memoryChunkedTransactionStore) List() ([]*ChunkedTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transactions := make([]*ChunkedTransaction, 0, len(s.transactions))
+	for _, tx := range s.transactions {
+		tx := tx
+		transactions = append(transactions, &tx)
+	}
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].CreatedAt.Before(transactions[j].CreatedAt) })
+	return transactions, nil
+}
+
+func importOperations() []models.TransactionOperation {
+	return []models.TransactionOperation{
+		{ID: "web", Type: models.OperationCreate, Resource: models.ResourceSwitch, Data: map[string]interface{}{"name": "web"}},
+		{ID: "web-1", Type: models.OperationCreate, Resource: models.ResourcePort, SwitchID: "$web", Data: map[string]interface{}{"name": "web-1"}},
+		{ID: "web-2", Type: models.OperationCreate, Resource: models.ResourcePort, SwitchID: "$web", Data: map[string]interface{}{"name": "web-2"}},
+		{ID: "db", Type: models.OperationCreate, Resource: models.ResourceSwitch, Data: map[string]interface{}{"name": "db"}},
+		{ID: "db-1", Type: models.OperationCreate, Resource: models.ResourcePort, SwitchID: "$db", Data: map[string]interface{}{"name": "db-1"}},
+	}
+}
+
+func TestChunkedTransactionService_CheckpointAndResume(t *testing.T) {
+	var executed [][]TransactionOp
+	assignUUIDs := func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		for i := range ops {
+			ops[i].ResourceID = "uuid-" + ops[i].Ref
+		}
+		executed = append(executed, append([]TransactionOp(nil), ops...))
+	}
+	mockService := new(MockOVNService)
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(assignUUIDs).Return(nil).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(&ovn.TxError{Index: 1, Err: errors.New("switch db already exists")}).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(assignUUIDs).Return(nil)
+
+	service, err := NewChunkedTransactionService(newMemoryChunkedTransactionStore(), mockService, zap.NewNop())
+	require.NoError(t, err)
+	ctx := ContextWithTenant(context.Background(), "acme")
+
+	tx, err := service.Start(ctx, &models.ChunkedTransactionRequest{Operations: importOperations(), ChunkSize: 2}, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 3, tx.Chunks)
+	assert.Equal(t, "acme", tx.TenantID)
+	service.Wait()
+
+	// The second chunk failed as a whole; the first stays applied
+	tx, err = service.Get(ctx, tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkedTransactionStatusFailed, tx.Status)
+	assert.Equal(t, 1, tx.CompletedChunks)
+	assert.Equal(t, "db", tx.FailedOperation)
+	assert.Contains(t, tx.Error, "switch db already exists")
+	assert.Equal(t, map[string]string{"web": "uuid-web", "web-1": "uuid-web-1"}, tx.References)
+	assert.Len(t, tx.Results, 2)
+
+	_, err = service.Get(ContextWithTenant(context.Background(), "other"), tx.ID)
+	assert.ErrorIs(t, err, ErrChunkedTransactionNotFound, "other tenants don't see the transaction")
+
+	tx, err = service.Resume(ctx, tx.ID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, ChunkedTransactionStatusRunning, tx.Status)
+	service.Wait()
+
+	tx, err = service.Get(ctx, tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkedTransactionStatusCompleted, tx.Status)
+	assert.Equal(t, 3, tx.CompletedChunks)
+	assert.Equal(t, 2, tx.Attempts)
+	assert.NotNil(t, tx.CompletedAt)
+	require.Len(t, tx.Results, 5)
+	assert.Equal(t, "uuid-db-1", tx.Results[4].ResourceID)
+
+	// References to creates of earlier chunks are resolved from the
+	// checkpoint, those within a chunk by its transaction
+	require.Len(t, executed, 3)
+	assert.Equal(t, "uuid-web", executed[1][0].SwitchID)
+	assert.Equal(t, "uuid-db", executed[2][0].SwitchID)
+	assert.Equal(t, "$web", executed[0][1].SwitchID)
+
+	_, err = service.Resume(ctx, tx.ID, "alice")
+	assert.ErrorIs(t, err, ErrChunkedTransactionStatus)
+}
+
+func TestChunkedTransactionService_Validation(t *testing.T) {
+	service, err := NewChunkedTransactionService(newMemoryChunkedTransactionStore(), new(MockOVNService), zap.NewNop())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for name, req := range map[string]*models.ChunkedTransactionRequest{
+		"no operations":     {},
+		"chunks too large":  {Operations: importOperations(), ChunkSize: models.MaxTransactionOperations + 1},
+		"unknown reference": {Operations: importOperations()[1:]},
+	} {
+		_, err := service.Start(ctx, req, "alice")
+		assert.ErrorIs(t, err, ErrInvalidChunkedTransaction, name)
+	}
+
+	chunkSize, err := ValidateChunkedTransaction(&models.ChunkedTransactionRequest{Operations: importOperations()})
+	require.NoError(t, err)
+	assert.Equal(t, models.MaxTransactionOperations, chunkSize)
+}
+
+func TestNewChunkedTransactionService_Interrupted(t *testing.T) {
+	store := newMemoryChunkedTransactionStore()
+	require.NoError(t, store.Save(&ChunkedTransaction{
+		ID: "tx-1", Status: ChunkedTransactionStatusRunning, Chunks: 3, CompletedChunks: 1, CreatedAt: time.Now(),
+	}))
+
+	_, err := NewChunkedTransactionService(store, new(MockOVNService), zap.NewNop())
+	require.NoError(t, err)
+
+	tx, err := store.Get("tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, ChunkedTransactionStatusFailed, tx.Status, "interrupted transactions can be resumed")
+	assert.Equal(t, 1, tx.CompletedChunks)
+}
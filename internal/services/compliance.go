@@ -0,0 +1,582 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/metering"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Kinds of compliance rules
+const (
+	// ComplianceForbidACL fails for ACLs allowing the traffic the rule
+	// describes
+	ComplianceForbidACL = "forbid_acl"
+	// ComplianceRequireDefaultDeny fails for switches not denying traffic
+	// by default
+	ComplianceRequireDefaultDeny = "require_default_deny"
+	// ComplianceRequireLogging fails for ACLs that don't log
+	ComplianceRequireLogging = "require_logging"
+)
+
+// Statuses of compliance rules
+const (
+	ComplianceStatusPass = "pass"
+	ComplianceStatusFail = "fail"
+)
+
+// ComplianceRule is a requirement on the configuration, declared by its kind
+// and the fields that kind reads
+type ComplianceRule struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"` // high, medium or low
+	Kind     string `json:"kind"`     // forbid_acl, require_default_deny or require_logging
+
+	// Actions of the ACLs checked: by default those allowing traffic for
+	// forbid_acl, and drop and reject for require_logging
+	Actions []string `json:"actions,omitempty"`
+	// Direction checked: to-lport by default for forbid_acl and
+	// require_default_deny, both by default for require_logging
+	Direction string `json:"direction,omitempty"`
+
+	// forbid_acl fails for ACLs that may allow Protocol traffic to Port,
+	// from any address with AnySource, and to any port with AnyPort
+	Protocol  string `json:"protocol,omitempty"` // tcp, udp or sctp
+	Port      int    `json:"port,omitempty"`
+	AnySource bool   `json:"any_source,omitempty"`
+	AnyPort   bool   `json:"any_port,omitempty"`
+
+	// TenantSwitchesOnly limits require_default_deny to the switches
+	// tenants own
+	TenantSwitchesOnly bool `json:"tenant_switches_only,omitempty"`
+}
+
+// CompliancePack is a named set of rules evaluated together
+type CompliancePack struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Rules       []ComplianceRule `json:"rules"`
+}
+
+// ComplianceViolation is a resource failing a rule
+type ComplianceViolation struct {
+	Resource string `json:"resource"` // switch or acl
+	UUID     string `json:"uuid"`
+	Name     string `json:"name,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// ComplianceRuleResult is the outcome of a rule
+type ComplianceRuleResult struct {
+	Pack       string                `json:"pack"`
+	ID         string                `json:"id"`
+	Title      string                `json:"title"`
+	Severity   string                `json:"severity"`
+	Status     string                `json:"status"` // pass or fail
+	Violations []ComplianceViolation `json:"violations"`
+}
+
+// ComplianceReport is the outcome of every rule of the enabled packs
+type ComplianceReport struct {
+	EvaluatedAt time.Time              `json:"evaluated_at"`
+	Passed      int                    `json:"passed"`
+	Failed      int                    `json:"failed"`
+	Rules       []ComplianceRuleResult `json:"rules"`
+}
+
+// ComplianceAlert reports the rules whose outcome changed since the last
+// alert: those failing for new resources, and those passing again
+type ComplianceAlert struct {
+	EvaluatedAt time.Time              `json:"evaluated_at"`
+	Failed      []ComplianceRuleResult `json:"failed"`
+	Resolved    []string               `json:"resolved"` // IDs of the rules
+}
+
+// ComplianceAlerter delivers compliance alerts
+type ComplianceAlerter interface {
+	Alert(ctx context.Context, alert *ComplianceAlert) error
+}
+
+// BuiltinCompliancePacks returns the rule packs ovncp ships
+func BuiltinCompliancePacks() []CompliancePack {
+	return []CompliancePack{
+		{
+			Name:        "baseline",
+			Description: "Remote access isn't open to the world and tenant switches deny inbound traffic by default",
+			Rules: []ComplianceRule{
+				{ID: "baseline-ssh", Title: "No ACL allows SSH (tcp/22) from any address", Severity: SeverityHigh,
+					Kind: ComplianceForbidACL, Protocol: "tcp", Port: 22, AnySource: true},
+				{ID: "baseline-rdp", Title: "No ACL allows RDP (tcp/3389) from any address", Severity: SeverityHigh,
+					Kind: ComplianceForbidACL, Protocol: "tcp", Port: 3389, AnySource: true},
+				{ID: "baseline-telnet", Title: "No ACL allows Telnet (tcp/23) from any address", Severity: SeverityHigh,
+					Kind: ComplianceForbidACL, Protocol: "tcp", Port: 23, AnySource: true},
+				{ID: "baseline-default-deny", Title: "All tenant switches deny inbound traffic by default", Severity: SeverityHigh,
+					Kind: ComplianceRequireDefaultDeny, TenantSwitchesOnly: true},
+			},
+		},
+		{
+			Name:        "strict",
+			Description: "Every switch denies traffic both ways by default, nothing is open to the world, and denials are logged",
+			Rules: []ComplianceRule{
+				{ID: "strict-any-traffic", Title: "No ACL allows all traffic from any address", Severity: SeverityHigh,
+					Kind: ComplianceForbidACL, AnySource: true, AnyPort: true},
+				{ID: "strict-snmp", Title: "No ACL allows SNMP (udp/161) from any address", Severity: SeverityMedium,
+					Kind: ComplianceForbidACL, Protocol: "udp", Port: 161, AnySource: true},
+				{ID: "strict-default-deny-inbound", Title: "All switches deny inbound traffic by default", Severity: SeverityHigh,
+					Kind: ComplianceRequireDefaultDeny},
+				{ID: "strict-default-deny-outbound", Title: "All switches deny outbound traffic by default", Severity: SeverityMedium,
+					Kind: ComplianceRequireDefaultDeny, Direction: "from-lport"},
+				{ID: "strict-deny-logging", Title: "Drop and reject ACLs log what they deny", Severity: SeverityLow,
+					Kind: ComplianceRequireLogging},
+			},
+		},
+	}
+}
+
+// LoadCompliancePacks reads rule packs from a JSON file holding
+// {"packs": [...]}
+func LoadCompliancePacks(path string) ([]CompliancePack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compliance rules: %w", err)
+	}
+	var file struct {
+		Packs []CompliancePack `json:"packs"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse compliance rules: %w", err)
+	}
+	return file.Packs, nil
+}
+
+// SelectCompliancePacks returns the packs named among available, checking
+// their rules
+func SelectCompliancePacks(available []CompliancePack, names []string) ([]CompliancePack, error) {
+	byName := make(map[string]CompliancePack, len(available))
+	for _, pack := range available {
+		if _, ok := byName[pack.Name]; ok {
+			return nil, fmt.Errorf("compliance pack %s is defined twice", pack.Name)
+		}
+		byName[pack.Name] = pack
+	}
+
+	var packs []CompliancePack
+	ids := make(map[string]bool)
+	for _, name := range names {
+		pack, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("compliance pack %s not found", name)
+		}
+		for _, rule := range pack.Rules {
+			if ids[rule.ID] {
+				return nil, fmt.Errorf("compliance rule %s is defined twice", rule.ID)
+			}
+			ids[rule.ID] = true
+			if err := validateComplianceRule(&rule); err != nil {
+				return nil, fmt.Errorf("compliance pack %s: %w", name, err)
+			}
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+// validateComplianceRule checks the fields of a rule against its kind
+func validateComplianceRule(rule *ComplianceRule) error {
+	if rule.ID == "" || rule.Title == "" {
+		return fmt.Errorf("compliance rules need an id and a title")
+	}
+	if _, ok := severityPenalty[rule.Severity]; !ok {
+		return fmt.Errorf("rule %s: severity must be high, medium or low", rule.ID)
+	}
+	switch rule.Direction {
+	case "", "from-lport", "to-lport":
+	default:
+		return fmt.Errorf("rule %s: direction must be from-lport or to-lport", rule.ID)
+	}
+
+	switch rule.Kind {
+	case ComplianceForbidACL:
+		switch rule.Protocol {
+		case "", "tcp", "udp", "sctp":
+		default:
+			return fmt.Errorf("rule %s: protocol must be tcp, udp or sctp", rule.ID)
+		}
+		if rule.Port < 0 || rule.Port > 65535 || (rule.Port > 0 && rule.Protocol == "") {
+			return fmt.Errorf("rule %s: port must be between 1 and 65535, with a protocol", rule.ID)
+		}
+		if !rule.AnySource && !rule.AnyPort && rule.Protocol == "" {
+			return fmt.Errorf("rule %s: forbid_acl rules need a protocol, any_source or any_port", rule.ID)
+		}
+	case ComplianceRequireDefaultDeny, ComplianceRequireLogging:
+	default:
+		return fmt.Errorf("rule %s: kind must be forbid_acl, require_default_deny or require_logging", rule.ID)
+	}
+	return nil
+}
+
+// ComplianceEngine evaluates the configuration against rule packs, on
+// demand and every interval, alerting when the outcome changes
+type ComplianceEngine struct {
+	ovn      OVNServiceInterface
+	packs    []CompliancePack
+	interval time.Duration
+	alerter  ComplianceAlerter
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex
+	latest *ComplianceReport
+	// alerted holds the rule/resource pairs failing as of the last alert
+	alerted map[string]bool
+}
+
+// NewComplianceEngine creates an engine evaluating packs. alerter is nil
+// when alerts aren't sent.
+func NewComplianceEngine(ovn OVNServiceInterface, packs []CompliancePack, interval time.Duration, alerter ComplianceAlerter, logger *zap.Logger) *ComplianceEngine {
+	return &ComplianceEngine{
+		ovn:      ovn,
+		packs:    packs,
+		interval: interval,
+		alerter:  alerter,
+		logger:   logger,
+		now:      time.Now,
+		alerted:  map[string]bool{},
+	}
+}
+
+// Packs returns the enabled rule packs
+func (e *ComplianceEngine) Packs() []CompliancePack {
+	return e.packs
+}
+
+// Latest returns the report of the last evaluation, or nil before the first
+func (e *ComplianceEngine) Latest() *ComplianceReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.latest
+}
+
+// Run evaluates the configuration every interval until ctx is done
+func (e *ComplianceEngine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.Evaluate(ctx); err != nil {
+			e.logger.Error("Failed to evaluate compliance", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate evaluates every rule, keeps the report as the latest, and alerts
+// on rules failing for new resources or passing again
+func (e *ComplianceEngine) Evaluate(ctx context.Context) (*ComplianceReport, error) {
+	config, err := e.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ComplianceReport{
+		EvaluatedAt: e.now().UTC(),
+		Rules:       []ComplianceRuleResult{},
+	}
+	for _, pack := range e.packs {
+		for _, rule := range pack.Rules {
+			result := ComplianceRuleResult{
+				Pack:       pack.Name,
+				ID:         rule.ID,
+				Title:      rule.Title,
+				Severity:   rule.Severity,
+				Status:     ComplianceStatusPass,
+				Violations: config.evaluate(&rule),
+			}
+			if len(result.Violations) > 0 {
+				result.Status = ComplianceStatusFail
+				report.Failed++
+			} else {
+				report.Passed++
+			}
+			report.Rules = append(report.Rules, result)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.latest = report
+	if e.alerter != nil {
+		e.alert(ctx, report)
+	}
+	return report, nil
+}
+
+// alert sends the changes since the last alert. A failed alert is retried
+// with the next evaluation.
+func (e *ComplianceEngine) alert(ctx context.Context, report *ComplianceReport) {
+	alert := &ComplianceAlert{EvaluatedAt: report.EvaluatedAt, Failed: []ComplianceRuleResult{}, Resolved: []string{}}
+	failing := make(map[string]bool)
+	for _, result := range report.Rules {
+		changed := false
+		for _, violation := range result.Violations {
+			key := result.ID + "/" + violation.UUID
+			failing[key] = true
+			changed = changed || !e.alerted[key]
+		}
+		if changed {
+			alert.Failed = append(alert.Failed, result)
+		}
+		if result.Status == ComplianceStatusPass && e.failedBefore(result.ID) {
+			alert.Resolved = append(alert.Resolved, result.ID)
+		}
+	}
+	if len(alert.Failed) == 0 && len(alert.Resolved) == 0 {
+		e.alerted = failing
+		return
+	}
+
+	if err := e.alerter.Alert(ctx, alert); err != nil {
+		e.logger.Error("Failed to send compliance alert", zap.Error(err))
+		return
+	}
+	e.alerted = failing
+}
+
+// failedBefore reports whether a rule was failing as of the last alert
+func (e *ComplianceEngine) failedBefore(ruleID string) bool {
+	for key := range e.alerted {
+		if strings.HasPrefix(key, ruleID+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// complianceConfig is the configuration rules are evaluated against
+type complianceConfig struct {
+	switches      []*models.LogicalSwitch
+	switchACLs    map[string][]*models.ACL
+	portGroups    []*models.PortGroup
+	portGroupACLs map[string][]*models.ACL
+	acls          []*models.ACL // Every ACL, once
+}
+
+// snapshot reads the switches, port groups and their ACLs
+func (e *ComplianceEngine) snapshot(ctx context.Context) (*complianceConfig, error) {
+	switches, err := e.ovn.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list switches: %w", err)
+	}
+	portGroups, err := e.ovn.ListPortGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+
+	config := &complianceConfig{
+		switches:      switches,
+		switchACLs:    make(map[string][]*models.ACL, len(switches)),
+		portGroups:    portGroups,
+		portGroupACLs: make(map[string][]*models.ACL, len(portGroups)),
+	}
+	seen := make(map[string]bool)
+	add := func(acls []*models.ACL) {
+		for _, acl := range acls {
+			if !seen[acl.UUID] {
+				seen[acl.UUID] = true
+				config.acls = append(config.acls, acl)
+			}
+		}
+	}
+	for _, sw := range switches {
+		acls, err := e.ovn.ListACLs(ctx, sw.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of switch %s: %w", sw.UUID, err)
+		}
+		config.switchACLs[sw.UUID] = acls
+		add(acls)
+	}
+	for _, pg := range portGroups {
+		acls, err := e.ovn.ListPortGroupACLs(ctx, pg.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of port group %s: %w", pg.UUID, err)
+		}
+		config.portGroupACLs[pg.UUID] = acls
+		add(acls)
+	}
+	return config, nil
+}
+
+// evaluate returns the resources failing rule
+func (c *complianceConfig) evaluate(rule *ComplianceRule) []ComplianceViolation {
+	violations := []ComplianceViolation{}
+	switch rule.Kind {
+	case ComplianceForbidACL:
+		for _, acl := range c.acls {
+			if reason, ok := forbiddenACL(rule, acl); ok {
+				violations = append(violations, aclViolation(acl, reason))
+			}
+		}
+	case ComplianceRequireDefaultDeny:
+		direction := rule.Direction
+		if direction == "" {
+			direction = "to-lport"
+		}
+		for _, sw := range c.switches {
+			if rule.TenantSwitchesOnly && sw.ExternalIDs["tenant_id"] == "" {
+				continue
+			}
+			if _, denied := switchACLs(sw, c.switchACLs[sw.UUID], c.portGroups, c.portGroupACLs); denied[direction] {
+				continue
+			}
+			violations = append(violations, ComplianceViolation{
+				Resource: models.ResourceSwitch,
+				UUID:     sw.UUID,
+				Name:     sw.Name,
+				Reason:   "no drop or reject ACL matches all " + direction + " IP traffic of its ports",
+			})
+		}
+	case ComplianceRequireLogging:
+		actions := rule.Actions
+		if len(actions) == 0 {
+			actions = []string{"drop", "reject"}
+		}
+		for _, acl := range c.acls {
+			if acl.Log || !containsString(actions, acl.Action) || (rule.Direction != "" && acl.Direction != rule.Direction) {
+				continue
+			}
+			violations = append(violations, aclViolation(acl, acl.Action+" ACL doesn't log"))
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].UUID < violations[j].UUID })
+	return violations
+}
+
+// forbiddenACL reports whether acl may allow the traffic a forbid_acl rule
+// describes, and why. ACLs are flagged unless their match rules the traffic
+// out, e.g. by matching another port.
+func forbiddenACL(rule *ComplianceRule, acl *models.ACL) (string, bool) {
+	actions := rule.Actions
+	if len(actions) == 0 {
+		actions = []string{"allow", "allow-related", "allow-stateless"}
+	}
+	direction := rule.Direction
+	if direction == "" {
+		direction = "to-lport"
+	}
+	if !containsString(actions, acl.Action) || acl.Direction != direction {
+		return "", false
+	}
+
+	what := "traffic"
+	if rule.AnyPort {
+		if restrictsPorts(acl.Match) {
+			return "", false
+		}
+		what = "all traffic"
+	}
+	if rule.Protocol != "" {
+		// The match is evaluated on a packet whose addresses are unknown
+		packet := &ovn.MatchPacket{Protocol: rule.Protocol, DstPort: rule.Port}
+		if matched, err := ovn.EvaluateMatch(acl.Match, packet, nil); err == nil && !matched {
+			return "", false
+		}
+		what = rule.Protocol
+		if rule.Port > 0 {
+			what = fmt.Sprintf("%s/%d", rule.Protocol, rule.Port)
+		}
+	}
+	if rule.AnySource {
+		if _, anySource := sourceScope(acl.Match); !anySource {
+			return "", false
+		}
+		what += " from any address"
+	}
+	return "allows " + what + ": " + acl.Match, true
+}
+
+func aclViolation(acl *models.ACL, reason string) ComplianceViolation {
+	return ComplianceViolation{
+		Resource: models.ResourceACL,
+		UUID:     acl.UUID,
+		Name:     acl.Name,
+		Reason:   reason,
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ComplianceWebhook POSTs alerts as JSON, signed like metering webhooks
+// when a secret is set
+type ComplianceWebhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewComplianceWebhook creates an alerter posting to url
+func NewComplianceWebhook(url, secret string) *ComplianceWebhook {
+	return &ComplianceWebhook{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Alert posts alert, failing on responses other than 2xx
+func (w *ComplianceWebhook) Alert(ctx context.Context, alert *ComplianceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set(metering.WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
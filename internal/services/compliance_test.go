@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/metering"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+type recordingAlerter struct {
+	alerts []*ComplianceAlert
+	err    error
+}
+
+func (a *recordingAlerter) Alert(ctx context.Context, alert *ComplianceAlert) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.alerts = append(a.alerts, alert)
+	return nil
+}
+
+func complianceOVN(tenantACLs []*models.ACL) *MockOVNService {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-tenant", Name: "tenant", Ports: []string{"p-1"}, ExternalIDs: map[string]string{"tenant_id": "acme"}},
+		{UUID: "sw-infra", Name: "infra", Ports: []string{"p-2"}},
+	}, nil)
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{
+		{UUID: "pg-infra", Name: "infra", Ports: []string{"p-2"}},
+	}, nil)
+	mockOVN.On("ListPortGroupACLs", mock.Anything, "pg-infra").Return([]*models.ACL{
+		{UUID: "acl-pg-deny", Direction: "to-lport", Match: "outport == @infra && ip", Action: "drop", Log: true},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-tenant").Return(tenantACLs, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-infra").Return([]*models.ACL{
+		{UUID: "acl-https", Direction: "to-lport", Match: "ip4.src == 0.0.0.0/0 && tcp.dst == 443", Action: "allow-related"},
+		{UUID: "acl-rdp", Direction: "to-lport", Match: "ip4 && tcp.dst >= 3000 && tcp.dst <= 4000", Action: "allow"},
+		{UUID: "acl-any", Direction: "to-lport", Match: "ip4.src == 0.0.0.0/0 && udp", Action: "allow"},
+	}, nil)
+	return mockOVN
+}
+
+func TestComplianceEngine_Evaluate(t *testing.T) {
+	mockOVN := complianceOVN([]*models.ACL{
+		{UUID: "acl-ssh", Direction: "to-lport", Match: "tcp.dst == 22", Action: "allow-related"},
+		{UUID: "acl-ssh-internal", Direction: "to-lport", Match: "ip4.src == 10.0.0.0/8 && tcp.dst == 22", Action: "allow-related"},
+		{UUID: "acl-ssh-out", Direction: "from-lport", Match: "tcp.dst == 22", Action: "allow-related"},
+	})
+	packs, err := SelectCompliancePacks(BuiltinCompliancePacks(), []string{"baseline", "strict"})
+	require.NoError(t, err)
+
+	engine := NewComplianceEngine(mockOVN, packs, 0, nil, zap.NewNop())
+	assert.Nil(t, engine.Latest())
+	report, err := engine.Evaluate(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, report, engine.Latest())
+
+	violations := make(map[string][]string)
+	for _, result := range report.Rules {
+		for _, violation := range result.Violations {
+			violations[result.ID] = append(violations[result.ID], violation.UUID)
+			assert.NotEmpty(t, violation.Reason)
+		}
+		assert.Equal(t, result.Status == ComplianceStatusFail, len(result.Violations) > 0)
+	}
+
+	// Internal and outbound SSH, and HTTPS, are allowed
+	assert.Equal(t, []string{"acl-ssh"}, violations["baseline-ssh"])
+	// Matches that may allow the port are flagged
+	assert.Equal(t, []string{"acl-rdp"}, violations["baseline-rdp"])
+	assert.Empty(t, violations["baseline-telnet"])
+	// Only the tenant's switch must deny by default
+	assert.Equal(t, []string{"sw-tenant"}, violations["baseline-default-deny"])
+	assert.Equal(t, []string{"acl-any"}, violations["strict-any-traffic"])
+	assert.Equal(t, []string{"acl-any"}, violations["strict-snmp"])
+	assert.Equal(t, []string{"sw-tenant"}, violations["strict-default-deny-inbound"], "port groups cover the infra switch")
+	assert.Equal(t, []string{"sw-infra", "sw-tenant"}, violations["strict-default-deny-outbound"])
+	assert.Empty(t, violations["strict-deny-logging"])
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 7, report.Failed)
+}
+
+func TestComplianceEngine_Alerts(t *testing.T) {
+	packs, err := SelectCompliancePacks(BuiltinCompliancePacks(), []string{"baseline"})
+	require.NoError(t, err)
+	ssh := &models.ACL{UUID: "acl-ssh", Direction: "to-lport", Match: "tcp.dst == 22", Action: "allow-related"}
+	deny := &models.ACL{UUID: "acl-deny", Direction: "to-lport", Match: "ip", Action: "drop"}
+	alerter := &recordingAlerter{}
+	engine := NewComplianceEngine(nil, packs, 0, alerter, zap.NewNop())
+	evaluate := func(acls ...*models.ACL) {
+		engine.ovn = complianceOVN(acls)
+		_, err := engine.Evaluate(context.Background())
+		require.NoError(t, err)
+	}
+
+	evaluate(ssh)
+	require.Len(t, alerter.alerts, 1)
+	var failed []string
+	for _, result := range alerter.alerts[0].Failed {
+		failed = append(failed, result.ID)
+	}
+	assert.Equal(t, []string{"baseline-ssh", "baseline-rdp", "baseline-default-deny"}, failed)
+
+	// Unchanged violations aren't alerted again
+	evaluate(ssh)
+	assert.Len(t, alerter.alerts, 1)
+
+	// Failed alerts are retried
+	alerter.err = errors.New("connection refused")
+	evaluate(deny)
+	assert.Len(t, alerter.alerts, 1)
+	alerter.err = nil
+	evaluate(deny)
+	require.Len(t, alerter.alerts, 2)
+	assert.Empty(t, alerter.alerts[1].Failed)
+	assert.Equal(t, []string{"baseline-ssh", "baseline-default-deny"}, alerter.alerts[1].Resolved)
+}
+
+func TestSelectCompliancePacks(t *testing.T) {
+	custom := CompliancePack{Name: "custom", Rules: []ComplianceRule{
+		{ID: "custom-mysql", Title: "No ACL allows MySQL", Severity: SeverityMedium,
+			Kind: ComplianceForbidACL, Protocol: "tcp", Port: 3306},
+	}}
+	packs, err := SelectCompliancePacks(append(BuiltinCompliancePacks(), custom), []string{"custom"})
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+	assert.Equal(t, "custom", packs[0].Name)
+
+	_, err = SelectCompliancePacks(BuiltinCompliancePacks(), []string{"missing"})
+	assert.Error(t, err)
+
+	invalid := []ComplianceRule{
+		{ID: "r", Title: "t", Severity: "critical", Kind: ComplianceRequireLogging},
+		{ID: "r", Title: "t", Severity: SeverityLow, Kind: "require_tls"},
+		{ID: "r", Title: "t", Severity: SeverityLow, Kind: ComplianceForbidACL, Port: 22},
+		{ID: "r", Title: "t", Severity: SeverityLow, Kind: ComplianceForbidACL},
+		{ID: "r", Title: "t", Severity: SeverityLow, Kind: ComplianceRequireDefaultDeny, Direction: "inbound"},
+	}
+	for _, rule := range invalid {
+		_, err := SelectCompliancePacks([]CompliancePack{{Name: "p", Rules: []ComplianceRule{rule}}}, []string{"p"})
+		assert.Error(t, err, "%+v", rule)
+	}
+	duplicate := CompliancePack{Name: "dup", Rules: packs[0].Rules}
+	_, err = SelectCompliancePacks([]CompliancePack{custom, duplicate}, []string{"custom", "dup"})
+	assert.Error(t, err)
+}
+
+func TestLoadCompliancePacks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"packs": [{"name": "pci", "rules": [
+		{"id": "pci-logging", "title": "Denials are logged", "severity": "medium", "kind": "require_logging"}
+	]}]}`), 0o600))
+
+	packs, err := LoadCompliancePacks(path)
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+	assert.Equal(t, ComplianceRequireLogging, packs[0].Rules[0].Kind)
+
+	_, err = LoadCompliancePacks(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestComplianceWebhook_Alert(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(metering.WebhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alert := &ComplianceAlert{Failed: []ComplianceRuleResult{{ID: "baseline-ssh", Status: ComplianceStatusFail}}, Resolved: []string{}}
+	require.NoError(t, NewComplianceWebhook(server.URL, "secret").Alert(context.Background(), alert))
+
+	var received ComplianceAlert
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, "baseline-ssh", received.Failed[0].ID)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewComplianceWebhook(failing.URL, "").Alert(context.Background(), alert))
+}
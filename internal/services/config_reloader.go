@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// ConfigReload is the outcome of a configuration reload
+type ConfigReload struct {
+	// Applied are the changed settings applied to the running server
+	Applied []string `json:"applied"`
+	// PendingRestart are the changed settings that take effect on restart
+	PendingRestart []string `json:"pending_restart"`
+}
+
+// ConfigReloader reloads the configuration on request, e.g. on SIGHUP, and
+// when its file changes. The settings that can change while the server
+// runs are handed to the OnReload functions; a configuration failing
+// validation is rejected as a whole.
+type ConfigReloader struct {
+	load   func() (*config.Config, error)
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	current  *config.Config // In effect
+	latest   *config.Config // Last loaded, which may change more than current
+	loadedAt time.Time
+	appliers []func(*config.Config)
+	file     os.FileInfo // The config file as last seen by Run
+}
+
+// NewConfigReloader creates a reloader of the configuration cfg was loaded
+// with, reloading it with load
+func NewConfigReloader(cfg *config.Config, load func() (*config.Config, error), logger *zap.Logger) *ConfigReloader {
+	r := &ConfigReloader{
+		load:     load,
+		logger:   logger,
+		now:      time.Now,
+		current:  cfg,
+		latest:   cfg,
+		loadedAt: time.Now(),
+	}
+	if cfg.Reload.File != "" {
+		r.file, _ = os.Stat(cfg.Reload.File)
+	}
+	return r
+}
+
+// OnReload calls apply with the configuration after each reload changing
+// reloadable settings
+func (r *ConfigReloader) OnReload(apply func(cfg *config.Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appliers = append(r.appliers, apply)
+}
+
+// Effective returns the configuration in effect, when it was last loaded,
+// and the settings changed since startup that wait for a restart
+func (r *ConfigReloader) Effective() (cfg *config.Config, loadedAt time.Time, pendingRestart []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current, r.loadedAt, r.current.Changed(r.latest)
+}
+
+// Reload loads the configuration again and applies its reloadable settings
+func (r *ConfigReloader) Reload() (*ConfigReload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		r.logger.Error("Rejected configuration reload", zap.Error(err))
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	reloaded := r.current.Reloaded(next)
+	result := &ConfigReload{
+		Applied:        r.current.Changed(reloaded),
+		PendingRestart: reloaded.Changed(next),
+	}
+	if len(result.Applied) > 0 {
+		for _, apply := range r.appliers {
+			apply(reloaded)
+		}
+	}
+	r.current, r.latest, r.loadedAt = reloaded, next, r.now()
+
+	r.logger.Info("Reloaded configuration", zap.Strings("applied", result.Applied))
+	if len(result.PendingRestart) > 0 {
+		r.logger.Warn("Changed settings take effect on restart", zap.Strings("settings", result.PendingRestart))
+	}
+	return result, nil
+}
+
+// Run reloads the configuration when the config file it was loaded from
+// changes, checking every CONFIG_WATCH_INTERVAL until ctx is done. Without
+// a file or interval it returns at once.
+func (r *ConfigReloader) Run(ctx context.Context) {
+	cfg, _, _ := r.Effective()
+	file, interval := cfg.Reload.File, cfg.Reload.WatchInterval
+	if file == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			// The file may be replaced, as editors and config maps do
+			continue
+		}
+		if r.file != nil && info.ModTime().Equal(r.file.ModTime()) && info.Size() == r.file.Size() {
+			continue
+		}
+		r.file = info
+		r.Reload()
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// newTestConfigReloader loads the configuration from a config file with
+// the given content, returning the file and the configurations applied
+func newTestConfigReloader(t *testing.T, content string) (*ConfigReloader, string, func() []*config.Config) {
+	file := filepath.Join(t.TempDir(), "ovncp.env")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("CONFIG_WATCH_INTERVAL", "10ms")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	reloader := NewConfigReloader(cfg, config.Load, zap.NewNop())
+
+	var mu sync.Mutex
+	var applied []*config.Config
+	reloader.OnReload(func(cfg *config.Config) {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = append(applied, cfg)
+	})
+	return reloader, file, func() []*config.Config {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*config.Config(nil), applied...)
+	}
+}
+
+func TestConfigReloader_Reload(t *testing.T) {
+	reloader, file, applied := newTestConfigReloader(t, "# ovncp\nRATE_LIMIT_RPS=100\nDB_PASSWORD=hunter2\n")
+
+	cfg, _, pending := reloader.Effective()
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Empty(t, pending)
+	settings := map[string]config.Setting{}
+	for _, setting := range cfg.Settings() {
+		settings[setting.Name] = setting
+	}
+	assert.Equal(t, config.Setting{Name: "LOG_LEVEL", Value: "warn", Source: config.SourceEnv, Reloadable: true}, settings["LOG_LEVEL"])
+	assert.Equal(t, config.Setting{Name: "RATE_LIMIT_RPS", Value: "100", Source: config.SourceFile, Reloadable: true}, settings["RATE_LIMIT_RPS"])
+	assert.Equal(t, config.Setting{Name: "API_PORT", Value: "8080", Source: config.SourceDefault}, settings["API_PORT"])
+	assert.Equal(t, "REDACTED", settings["DB_PASSWORD"].Value)
+
+	// The file overrides the environment; settings needing a restart wait
+	// for it
+	require.NoError(t, os.WriteFile(file, []byte("LOG_LEVEL=debug\nRATE_LIMIT_RPS='50'\nAPI_PORT=9090\nDB_PASSWORD=hunter2\n"), 0o600))
+	result, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOG_LEVEL", "RATE_LIMIT_RPS"}, result.Applied)
+	assert.Equal(t, []string{"API_PORT"}, result.PendingRestart)
+
+	require.Len(t, applied(), 1)
+	cfg = applied()[0]
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, 50, cfg.Security.RateLimitRPS)
+	assert.Equal(t, "8080", cfg.API.Port)
+
+	effective, _, pending := reloader.Effective()
+	assert.Same(t, cfg, effective)
+	assert.Equal(t, []string{"API_PORT"}, pending)
+
+	// Reloads changing nothing reloadable apply nothing
+	result, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, result.Applied)
+	assert.Len(t, applied(), 1)
+
+	// Invalid configurations are rejected as a whole
+	require.NoError(t, os.WriteFile(file, []byte("LOG_LEVEL=info\nRATE_LIMIT_RPS=0\n"), 0o600))
+	_, err = reloader.Reload()
+	assert.ErrorContains(t, err, "RATE_LIMIT_RPS")
+	require.NoError(t, os.WriteFile(file, []byte("LOG_LEVEL\n"), 0o600))
+	_, err = reloader.Reload()
+	assert.ErrorContains(t, err, "expected KEY=value")
+	effective, _, _ = reloader.Effective()
+	assert.Equal(t, "debug", effective.Log.Level)
+	assert.Len(t, applied(), 1)
+}
+
+func TestConfigReloader_Run(t *testing.T) {
+	reloader, file, applied := newTestConfigReloader(t, "LOG_LEVEL=info\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		reloader.Run(ctx)
+		close(done)
+	}()
+
+	require.NoError(t, os.WriteFile(file, []byte("LOG_LEVEL=error\n"), 0o600))
+	require.Eventually(t, func() bool {
+		return len(applied()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "error", applied()[0].Log.Level)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// routerPortOption is the option of a switch port of type router naming
+// its peer router port
+const routerPortOption = "router-port"
+
+// DependencyAnalyzer reports what depends on switches and routers before
+// they are deleted
+type DependencyAnalyzer struct {
+	ovn OVNServiceInterface
+}
+
+// NewDependencyAnalyzer creates a dependency analyzer
+func NewDependencyAnalyzer(ovnService OVNServiceInterface) *DependencyAnalyzer {
+	return &DependencyAnalyzer{
+		ovn: ovnService,
+	}
+}
+
+// SwitchDependents lists a switch's ports and ACLs, which are deleted with
+// it, the load balancers applied to it, and the routers it connects to
+func (a *DependencyAnalyzer) SwitchDependents(ctx context.Context, id string) (*models.Dependents, error) {
+	sw, err := a.ovn.GetLogicalSwitch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := newDependents(models.ResourceSwitch, sw.UUID, sw.Name)
+	var routerPorts []*models.LogicalSwitchPort
+	if len(sw.Ports) > 0 {
+		ports, err := a.ovn.ListPorts(ctx, sw.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ports of switch %s: %w", sw.UUID, err)
+		}
+		for _, port := range ports {
+			deps.Ports = append(deps.Ports, models.Dependent{UUID: port.UUID, Name: port.Name})
+			if port.Type == "router" && port.Options[routerPortOption] != "" {
+				routerPorts = append(routerPorts, port)
+			}
+		}
+	}
+	if len(sw.ACLs) > 0 {
+		acls, err := a.ovn.ListACLs(ctx, sw.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of switch %s: %w", sw.UUID, err)
+		}
+		for _, acl := range acls {
+			deps.ACLs = append(deps.ACLs, models.Dependent{UUID: acl.UUID, Name: acl.Name})
+		}
+	}
+	if deps.LoadBalancers, err = a.loadBalancers(ctx, sw.LoadBalancer); err != nil {
+		return nil, err
+	}
+
+	if len(routerPorts) > 0 {
+		topology, err := a.ovn.GetTopology(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get topology: %w", err)
+		}
+		routers := routerPortsByName(topology)
+		for _, port := range routerPorts {
+			conn := models.RouterConnection{
+				SwitchID:       sw.UUID,
+				SwitchName:     sw.Name,
+				SwitchPortID:   port.UUID,
+				SwitchPortName: port.Name,
+				RouterPortName: port.Options[routerPortOption],
+			}
+			if peer, ok := routers[conn.RouterPortName]; ok {
+				conn.RouterID, conn.RouterName = peer.router.UUID, peer.router.Name
+				conn.RouterPortID, conn.Networks = peer.port.UUID, peer.port.Networks
+			}
+			deps.RouterConnections = append(deps.RouterConnections, conn)
+		}
+	}
+
+	deps.Count = len(deps.Ports) + len(deps.ACLs) + len(deps.LoadBalancers)
+	return deps, nil
+}
+
+// RouterDependents lists a router's ports, the switches connected to them,
+// and the load balancers applied to it
+func (a *DependencyAnalyzer) RouterDependents(ctx context.Context, id string) (*models.Dependents, error) {
+	lr, err := a.ovn.GetLogicalRouter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := newDependents(models.ResourceRouter, lr.UUID, lr.Name)
+	if len(lr.Ports) > 0 {
+		topology, err := a.ovn.GetTopology(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get topology: %w", err)
+		}
+
+		lrps := make(map[string]*models.LogicalRouterPort, len(topology.RouterPorts))
+		for _, lrp := range topology.RouterPorts {
+			lrps[lrp.UUID] = lrp
+		}
+		peers := make(map[string][]*models.LogicalSwitchPort)
+		for _, port := range topology.Ports {
+			if peer := port.Options[routerPortOption]; port.Type == "router" && peer != "" {
+				peers[peer] = append(peers[peer], port)
+			}
+		}
+		switches := make(map[string]*models.LogicalSwitch)
+		for _, sw := range topology.Switches {
+			for _, portID := range sw.Ports {
+				switches[portID] = sw
+			}
+		}
+
+		for _, portID := range lr.Ports {
+			lrp, ok := lrps[portID]
+			if !ok {
+				deps.Ports = append(deps.Ports, models.Dependent{UUID: portID})
+				continue
+			}
+			deps.Ports = append(deps.Ports, models.Dependent{UUID: lrp.UUID, Name: lrp.Name})
+			for _, port := range peers[lrp.Name] {
+				conn := models.RouterConnection{
+					SwitchID:       port.SwitchID,
+					SwitchPortID:   port.UUID,
+					SwitchPortName: port.Name,
+					RouterID:       lr.UUID,
+					RouterName:     lr.Name,
+					RouterPortID:   lrp.UUID,
+					RouterPortName: lrp.Name,
+					Networks:       lrp.Networks,
+				}
+				if sw, ok := switches[port.UUID]; ok {
+					conn.SwitchID, conn.SwitchName = sw.UUID, sw.Name
+				}
+				deps.RouterConnections = append(deps.RouterConnections, conn)
+			}
+		}
+	}
+	if deps.LoadBalancers, err = a.loadBalancers(ctx, lr.LoadBalancer); err != nil {
+		return nil, err
+	}
+
+	deps.Count = len(deps.Ports) + len(deps.LoadBalancers)
+	return deps, nil
+}
+
+// loadBalancers names the load balancers of ids
+func (a *DependencyAnalyzer) loadBalancers(ctx context.Context, ids []string) ([]models.Dependent, error) {
+	deps := make([]models.Dependent, 0, len(ids))
+	if len(ids) == 0 {
+		return deps, nil
+	}
+
+	lbs, err := a.ovn.ListLoadBalancers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list load balancers: %w", err)
+	}
+	names := make(map[string]string, len(lbs))
+	for _, lb := range lbs {
+		names[lb.UUID] = lb.Name
+	}
+	for _, id := range ids {
+		deps = append(deps, models.Dependent{UUID: id, Name: names[id]})
+	}
+	return deps, nil
+}
+
+func newDependents(resource, id, name string) *models.Dependents {
+	return &models.Dependents{
+		Resource:          resource,
+		ResourceID:        id,
+		Name:              name,
+		Ports:             []models.Dependent{},
+		LoadBalancers:     []models.Dependent{},
+		RouterConnections: []models.RouterConnection{},
+	}
+}
+
+type routerPeer struct {
+	router *models.LogicalRouter
+	port   *models.LogicalRouterPort
+}
+
+// routerPortsByName indexes the router ports of a topology, with their
+// routers, by name
+func routerPortsByName(topology *Topology) map[string]routerPeer {
+	lrps := make(map[string]*models.LogicalRouterPort, len(topology.RouterPorts))
+	for _, lrp := range topology.RouterPorts {
+		lrps[lrp.UUID] = lrp
+	}
+	peers := make(map[string]routerPeer, len(topology.RouterPorts))
+	for _, lr := range topology.Routers {
+		for _, portID := range lr.Ports {
+			if lrp, ok := lrps[portID]; ok {
+				peers[lrp.Name] = routerPeer{router: lr, port: lrp}
+			}
+		}
+	}
+	return peers
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// dependentsTopology is a web switch connected to an edge router, with a VM
+// port, an ACL and a load balancer
+func dependentsTopology() *Topology {
+	return &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-web", Name: "web", Ports: []string{"p-vm", "p-rtr"}, ACLs: []string{"acl-1"}, LoadBalancer: []string{"lb-1"}},
+		},
+		Routers: []*models.LogicalRouter{
+			{UUID: "lr-edge", Name: "edge", Ports: []string{"lrp-web"}, LoadBalancer: []string{"lb-2"}},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "p-vm", Name: "vm-1"},
+			{UUID: "p-rtr", Name: "web-to-edge", Type: "router", Options: map[string]string{"router-port": "edge-web"}},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			{UUID: "lrp-web", Name: "edge-web", Networks: []string{"10.0.0.1/24"}},
+		},
+	}
+}
+
+func TestDependencyAnalyzer_SwitchDependents(t *testing.T) {
+	topology := dependentsTopology()
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "web").Return(topology.Switches[0], nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-web").Return(topology.Ports, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-web").Return([]*models.ACL{{UUID: "acl-1", Name: "allow-http"}}, nil)
+	mockOVN.On("ListLoadBalancers", mock.Anything).Return([]*models.LoadBalancer{{UUID: "lb-1", Name: "web-lb"}}, nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(topology, nil)
+
+	deps, err := NewDependencyAnalyzer(mockOVN).SwitchDependents(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, models.ResourceSwitch, deps.Resource)
+	assert.Equal(t, "sw-web", deps.ResourceID)
+	assert.Equal(t, []models.Dependent{{UUID: "p-vm", Name: "vm-1"}, {UUID: "p-rtr", Name: "web-to-edge"}}, deps.Ports)
+	assert.Equal(t, []models.Dependent{{UUID: "acl-1", Name: "allow-http"}}, deps.ACLs)
+	assert.Equal(t, []models.Dependent{{UUID: "lb-1", Name: "web-lb"}}, deps.LoadBalancers)
+	assert.Equal(t, []models.RouterConnection{{
+		SwitchID: "sw-web", SwitchName: "web", SwitchPortID: "p-rtr", SwitchPortName: "web-to-edge",
+		RouterID: "lr-edge", RouterName: "edge", RouterPortID: "lrp-web", RouterPortName: "edge-web",
+		Networks: []string{"10.0.0.1/24"},
+	}}, deps.RouterConnections)
+	assert.Equal(t, 4, deps.Count)
+}
+
+func TestDependencyAnalyzer_EmptySwitch(t *testing.T) {
+	// Nothing but the switch is read when it references nothing
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "sw-1").Return(&models.LogicalSwitch{UUID: "sw-1", Name: "empty"}, nil)
+
+	deps, err := NewDependencyAnalyzer(mockOVN).SwitchDependents(context.Background(), "sw-1")
+	require.NoError(t, err)
+	assert.Zero(t, deps.Count)
+	assert.Empty(t, deps.Ports)
+	assert.NotNil(t, deps.RouterConnections)
+	mockOVN.AssertExpectations(t)
+}
+
+func TestDependencyAnalyzer_RouterDependents(t *testing.T) {
+	topology := dependentsTopology()
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalRouter", mock.Anything, "lr-edge").Return(topology.Routers[0], nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(topology, nil)
+	mockOVN.On("ListLoadBalancers", mock.Anything).Return([]*models.LoadBalancer{}, nil)
+
+	deps, err := NewDependencyAnalyzer(mockOVN).RouterDependents(context.Background(), "lr-edge")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Dependent{{UUID: "lrp-web", Name: "edge-web"}}, deps.Ports)
+	assert.Nil(t, deps.ACLs)
+	// Load balancers the tenant can't list are reported by UUID
+	assert.Equal(t, []models.Dependent{{UUID: "lb-2"}}, deps.LoadBalancers)
+	require.Len(t, deps.RouterConnections, 1)
+	assert.Equal(t, "sw-web", deps.RouterConnections[0].SwitchID)
+	assert.Equal(t, "web", deps.RouterConnections[0].SwitchName)
+	assert.Equal(t, "p-rtr", deps.RouterConnections[0].SwitchPortID)
+	assert.Equal(t, 2, deps.Count)
+	mockOVN.AssertExpectations(t)
+}
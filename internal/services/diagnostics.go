@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/logging"
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// NorthboundDiagnoser reports the state of a northbound connection, as
+// *ovn.Client does
+type NorthboundDiagnoser interface {
+	Status() ovn.ConnectionStatus
+	NorthdStatus(ctx context.Context) (*ovn.NorthdStatus, error)
+	TableSizes() map[string]int
+	TransactionStats() ovn.TransactionStats
+}
+
+// SouthboundDiagnoser reports the state of the southbound connection, as
+// *ovn.ChassisInventory does
+type SouthboundDiagnoser interface {
+	Status(ctx context.Context) ovn.SouthboundStatus
+}
+
+// RecentErrorReader returns the errors logged recently, as
+// *logging.ErrorRecorder does
+type RecentErrorReader interface {
+	Entries() []logging.ErrorEntry
+}
+
+// DiagnosedCluster is an OVN cluster diagnostics are collected from
+type DiagnosedCluster struct {
+	Name       string
+	Default    bool
+	Northbound NorthboundDiagnoser
+	ReadPool   *ovn.PoolStats // nil without a read pool
+}
+
+// DiagnosticsReport is the state of the API and the OVN deployments it
+// manages, gathered for support cases
+type DiagnosticsReport struct {
+	GeneratedAt  time.Time                   `json:"generated_at"`
+	Version      string                      `json:"version"`
+	Hostname     string                      `json:"hostname"`
+	Clusters     []ClusterDiagnostics        `json:"clusters"`
+	Southbound   *ovn.SouthboundStatus       `json:"southbound,omitempty"`
+	Caches       map[string]CacheDiagnostics `json:"caches"`
+	Runtime      RuntimeDiagnostics          `json:"runtime"`
+	RecentErrors []logging.ErrorEntry        `json:"recent_errors"`
+}
+
+// ClusterDiagnostics is the state of an OVN cluster's northbound connection
+type ClusterDiagnostics struct {
+	Name         string               `json:"name"`
+	Default      bool                 `json:"default"`
+	Northbound   ovn.ConnectionStatus `json:"northbound"`
+	Northd       *ovn.NorthdStatus    `json:"northd,omitempty"`
+	NorthdError  string               `json:"northd_error,omitempty"`
+	TableSizes   map[string]int       `json:"table_sizes"`
+	Transactions ovn.TransactionStats `json:"transactions"`
+	ReadPool     *ReadPoolDiagnostics `json:"read_pool,omitempty"`
+}
+
+// ReadPoolDiagnostics is the state of a cluster's read connection pool
+type ReadPoolDiagnostics struct {
+	Total    int64 `json:"total"`
+	Active   int64 `json:"active"`
+	Idle     int64 `json:"idle"`
+	Waits    int64 `json:"waits"`
+	Timeouts int64 `json:"timeouts"`
+}
+
+// CacheDiagnostics is the cumulative statistics of a cache
+type CacheDiagnostics struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Sets      int64   `json:"sets"`
+	Deletes   int64   `json:"deletes"`
+	Errors    int64   `json:"errors"`
+	Evictions int64   `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
+
+// RuntimeDiagnostics is the state of the API process
+type RuntimeDiagnostics struct {
+	GoVersion      string    `json:"go_version"`
+	StartedAt      time.Time `json:"started_at"`
+	UptimeSeconds  float64   `json:"uptime_seconds"`
+	Goroutines     int       `json:"goroutines"`
+	HeapAllocBytes uint64    `json:"heap_alloc_bytes"`
+	NumGC          uint32    `json:"num_gc"`
+}
+
+// DiagnosticsCollector gathers diagnostics reports
+type DiagnosticsCollector struct {
+	version    string
+	clusters   func() []DiagnosedCluster
+	southbound SouthboundDiagnoser
+	errors     RecentErrorReader
+	startedAt  time.Time
+}
+
+// NewDiagnosticsCollector creates a collector reading the clusters listed
+// by clusters. southbound and recentErrors may be nil.
+func NewDiagnosticsCollector(version string, clusters func() []DiagnosedCluster, southbound SouthboundDiagnoser, recentErrors RecentErrorReader) *DiagnosticsCollector {
+	return &DiagnosticsCollector{
+		version:    version,
+		clusters:   clusters,
+		southbound: southbound,
+		errors:     recentErrors,
+		startedAt:  time.Now(),
+	}
+}
+
+// Collect gathers a report. Parts that can't be read are reported with
+// their error rather than failing the report, which matters most when OVN
+// is unreachable.
+func (d *DiagnosticsCollector) Collect(ctx context.Context) *DiagnosticsReport {
+	report := &DiagnosticsReport{
+		GeneratedAt:  time.Now().UTC(),
+		Version:      d.version,
+		Clusters:     []ClusterDiagnostics{},
+		Caches:       make(map[string]CacheDiagnostics),
+		Runtime:      d.runtime(),
+		RecentErrors: []logging.ErrorEntry{},
+	}
+	report.Hostname, _ = os.Hostname()
+
+	for _, cluster := range d.clusters() {
+		report.Clusters = append(report.Clusters, diagnoseCluster(ctx, cluster))
+	}
+	if d.southbound != nil {
+		status := d.southbound.Status(ctx)
+		report.Southbound = &status
+	}
+	for name, stats := range metrics.CacheStatistics() {
+		diagnostics := CacheDiagnostics{
+			Hits:      stats.Hits,
+			Misses:    stats.Misses,
+			Sets:      stats.Sets,
+			Deletes:   stats.Deletes,
+			Errors:    stats.Errors,
+			Evictions: stats.Evictions,
+		}
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			diagnostics.HitRatio = float64(stats.Hits) / float64(lookups)
+		}
+		report.Caches[name] = diagnostics
+	}
+	if d.errors != nil {
+		report.RecentErrors = append(report.RecentErrors, d.errors.Entries()...)
+	}
+	return report
+}
+
+func diagnoseCluster(ctx context.Context, cluster DiagnosedCluster) ClusterDiagnostics {
+	diagnostics := ClusterDiagnostics{
+		Name:         cluster.Name,
+		Default:      cluster.Default,
+		Northbound:   cluster.Northbound.Status(),
+		TableSizes:   cluster.Northbound.TableSizes(),
+		Transactions: cluster.Northbound.TransactionStats(),
+	}
+	if northd, err := cluster.Northbound.NorthdStatus(ctx); err != nil {
+		diagnostics.NorthdError = err.Error()
+	} else {
+		diagnostics.Northd = northd
+	}
+	if stats := cluster.ReadPool; stats != nil {
+		diagnostics.ReadPool = &ReadPoolDiagnostics{
+			Total:    stats.TotalConns,
+			Active:   stats.ActiveConns,
+			Idle:     stats.IdleConns,
+			Waits:    stats.WaitCount,
+			Timeouts: stats.Timeouts,
+		}
+	}
+	return diagnostics
+}
+
+func (d *DiagnosticsCollector) runtime() RuntimeDiagnostics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return RuntimeDiagnostics{
+		GoVersion:      runtime.Version(),
+		StartedAt:      d.startedAt.UTC(),
+		UptimeSeconds:  time.Since(d.startedAt).Seconds(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		NumGC:          mem.NumGC,
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/logging"
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+type fakeNorthbound struct {
+	northd    *ovn.NorthdStatus
+	northdErr error
+}
+
+func (f *fakeNorthbound) Status() ovn.ConnectionStatus {
+	return ovn.ConnectionStatus{Connected: f.northdErr == nil, Endpoint: "tcp:ovn:6641"}
+}
+
+func (f *fakeNorthbound) NorthdStatus(ctx context.Context) (*ovn.NorthdStatus, error) {
+	return f.northd, f.northdErr
+}
+
+func (f *fakeNorthbound) TableSizes() map[string]int {
+	return map[string]int{"Logical_Switch": 3, "ACL": 12}
+}
+
+func (f *fakeNorthbound) TransactionStats() ovn.TransactionStats {
+	return ovn.TransactionStats{Succeeded: 40, Failed: 2, Retried: 1}
+}
+
+type fakeSouthbound struct{}
+
+func (fakeSouthbound) Status(ctx context.Context) ovn.SouthboundStatus {
+	return ovn.SouthboundStatus{Endpoint: "tcp:ovn:6642", Error: "connection refused"}
+}
+
+func TestDiagnosticsCollector_Collect(t *testing.T) {
+	metrics.RegisterCache("diagnostics", func() cache.CacheStats { return cache.CacheStats{Hits: 3, Misses: 1} })
+
+	// Errors are recorded with the fields of the logger and the entry
+	recorder := logging.NewErrorRecorder(2)
+	logger := zap.New(zapcore.NewNopCore(), zap.WrapCore(recorder.Tee)).With(zap.String("component", "sync"))
+	logger.Info("Not recorded")
+	logger.Error("First", zap.Int("attempt", 1))
+	logger.Error("Second")
+	logger.Error("Third", zap.Error(errors.New("timeout")))
+
+	clusters := func() []DiagnosedCluster {
+		return []DiagnosedCluster{
+			{Name: "east", Default: true, Northbound: &fakeNorthbound{northd: &ovn.NorthdStatus{NbCfg: 7, SbCfg: 7, HvCfg: 5, HvLag: 2}},
+				ReadPool: &ovn.PoolStats{TotalConns: 4, IdleConns: 3, ActiveConns: 1}},
+			{Name: "west", Northbound: &fakeNorthbound{northdErr: errors.New("client not connected")}},
+		}
+	}
+	collector := NewDiagnosticsCollector("1.2.3", clusters, fakeSouthbound{}, recorder)
+
+	report := collector.Collect(context.Background())
+	assert.Equal(t, "1.2.3", report.Version)
+	require.Len(t, report.Clusters, 2)
+
+	east := report.Clusters[0]
+	assert.True(t, east.Default)
+	assert.Equal(t, 2, east.Northd.HvLag)
+	assert.Equal(t, 12, east.TableSizes["ACL"])
+	assert.Equal(t, int64(2), east.Transactions.Failed)
+	assert.Equal(t, &ReadPoolDiagnostics{Total: 4, Active: 1, Idle: 3}, east.ReadPool)
+
+	// A cluster that can't be read is reported with its error
+	west := report.Clusters[1]
+	assert.Nil(t, west.Northd)
+	assert.Equal(t, "client not connected", west.NorthdError)
+	assert.False(t, west.Northbound.Connected)
+	assert.Nil(t, west.ReadPool)
+
+	assert.Equal(t, "connection refused", report.Southbound.Error)
+	assert.Equal(t, CacheDiagnostics{Hits: 3, Misses: 1, HitRatio: 0.75}, report.Caches["diagnostics"])
+	assert.Positive(t, report.Runtime.Goroutines)
+
+	// Only the last errors are kept, oldest first
+	require.Len(t, report.RecentErrors, 2)
+	assert.Equal(t, "Second", report.RecentErrors[0].Message)
+	assert.Equal(t, "Third", report.RecentErrors[1].Message)
+	assert.Equal(t, "error", report.RecentErrors[1].Level)
+	assert.Equal(t, map[string]interface{}{"component": "sync", "error": "timeout"}, report.RecentErrors[1].Fields)
+}
+
+func TestDiagnosticsCollector_Optional(t *testing.T) {
+	collector := NewDiagnosticsCollector("1.2.3", func() []DiagnosedCluster { return nil }, nil, nil)
+
+	report := collector.Collect(context.Background())
+	assert.Nil(t, report.Southbound)
+	assert.NotNil(t, report.Clusters)
+	assert.NotNil(t, report.RecentErrors)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// volatileFields change without the resource's configuration changing, so
+// they are left out of ETags. Otherwise a no-op PUT or a port coming up
+// would invalidate every ETag a client holds. A port's binding is only
+// present when requested; ownership records who last changed a resource.
+var volatileFields = []string{"created_at", "updated_at", "up", "binding", "ownership",
+	models.UpdatedByKey, models.UpdatedSourceKey}
+
+// ResourceETag returns a strong ETag for a resource. The tag is a hash of
+// the resource's JSON form without volatile fields, so it is stable across
+// reads and idempotent updates.
+func ResourceETag(resource interface{}) (string, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	externalIDs, _ := fields["external_ids"].(map[string]interface{})
+	for _, name := range volatileFields {
+		delete(fields, name)
+		delete(externalIDs, name)
+	}
+
+	// encoding/json sorts map keys, so the output is canonical
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// EventBus publishes each event to several publishers, e.g. the webhooks and
+// the notification channels
+type EventBus struct {
+	publishers []EventPublisher
+}
+
+// NewEventBus creates a bus publishing to publishers
+func NewEventBus(publishers ...EventPublisher) *EventBus {
+	return &EventBus{publishers: publishers}
+}
+
+// Publish publishes event to every publisher, in order
+func (b *EventBus) Publish(ctx context.Context, event *models.Event) {
+	for _, publisher := range b.publishers {
+		publisher.Publish(ctx, event)
+	}
+}
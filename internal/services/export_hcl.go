@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Terraform resource types used in HCL exports
+const (
+	hclSwitchType       = "ovncp_logical_switch"
+	hclPortType         = "ovncp_logical_switch_port"
+	hclACLType          = "ovncp_acl"
+	hclRouterType       = "ovncp_logical_router"
+	hclLoadBalancerType = "ovncp_load_balancer"
+)
+
+// RenderHCL renders an export document as Terraform configuration. Each
+// resource gets an import block with its ID so that `terraform plan` adopts
+// existing resources instead of recreating them. Ports and ACLs reference
+// their switch by address rather than by ID.
+func RenderHCL(doc *ExportDocument) []byte {
+	w := &hclWriter{labels: make(map[string]bool)}
+	w.line("# Generated by ovncp export (format version %d)", doc.Version)
+
+	for _, sw := range doc.Switches {
+		switchLabel := w.label(hclSwitchType, sw.Name)
+		w.resource(hclSwitchType, switchLabel, sw.ID, func() {
+			w.attr("name", hclString(sw.Name))
+			w.mapAttr("other_config", sw.OtherConfig)
+			w.mapAttr("external_ids", sw.ExternalIDs)
+		})
+
+		switchRef := hclSwitchType + "." + switchLabel + ".id"
+		for _, port := range sw.Ports {
+			w.resource(hclPortType, w.label(hclPortType, port.Name), port.ID, func() {
+				w.attr("switch_id", switchRef)
+				w.attr("name", hclString(port.Name))
+				if port.Type != "" {
+					w.attr("type", hclString(port.Type))
+				}
+				w.listAttr("addresses", port.Addresses)
+				w.listAttr("port_security", port.PortSecurity)
+				if port.Enabled != nil {
+					w.attr("enabled", fmt.Sprintf("%t", *port.Enabled))
+				}
+				w.mapAttr("options", port.Options)
+				w.mapAttr("external_ids", port.ExternalIDs)
+			})
+		}
+
+		for _, acl := range sw.ACLs {
+			name := acl.Name
+			if name == "" {
+				name = fmt.Sprintf("%s_%s_%d", sw.Name, acl.Direction, acl.Priority)
+			}
+			w.resource(hclACLType, w.label(hclACLType, name), acl.ID, func() {
+				w.attr("switch_id", switchRef)
+				if acl.Name != "" {
+					w.attr("name", hclString(acl.Name))
+				}
+				w.attr("direction", hclString(acl.Direction))
+				w.attr("priority", fmt.Sprintf("%d", acl.Priority))
+				w.attr("match", hclString(acl.Match))
+				w.attr("action", hclString(acl.Action))
+				if acl.Log {
+					w.attr("log", "true")
+				}
+				if acl.Severity != "" {
+					w.attr("severity", hclString(acl.Severity))
+				}
+				w.mapAttr("external_ids", acl.ExternalIDs)
+			})
+		}
+	}
+
+	for _, lr := range doc.Routers {
+		w.resource(hclRouterType, w.label(hclRouterType, lr.Name), lr.ID, func() {
+			w.attr("name", hclString(lr.Name))
+			w.mapAttr("options", lr.Options)
+			w.mapAttr("external_ids", lr.ExternalIDs)
+		})
+	}
+
+	for _, lb := range doc.LoadBalancers {
+		w.resource(hclLoadBalancerType, w.label(hclLoadBalancerType, lb.Name), lb.ID, func() {
+			w.attr("name", hclString(lb.Name))
+			if lb.Protocol != "" {
+				w.attr("protocol", hclString(lb.Protocol))
+			}
+			w.mapAttr("vips", lb.VIPs)
+			w.mapAttr("options", lb.Options)
+			w.mapAttr("external_ids", lb.ExternalIDs)
+		})
+	}
+
+	return w.buf.Bytes()
+}
+
+type hclWriter struct {
+	buf    bytes.Buffer
+	indent string
+	// labels holds the resource addresses already used, to keep them unique
+	labels map[string]bool
+}
+
+func (w *hclWriter) line(format string, args ...interface{}) {
+	w.buf.WriteString(w.indent)
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteByte('\n')
+}
+
+func (w *hclWriter) resource(resourceType, label, id string, body func()) {
+	w.buf.WriteByte('\n')
+	w.line("import {")
+	w.line("  to = %s.%s", resourceType, label)
+	w.line("  id = %s", hclString(id))
+	w.line("}")
+	w.buf.WriteByte('\n')
+	w.line("resource %q %q {", resourceType, label)
+	w.indent = "  "
+	body()
+	w.indent = ""
+	w.line("}")
+}
+
+func (w *hclWriter) attr(name, value string) {
+	w.line("%s = %s", name, value)
+}
+
+func (w *hclWriter) listAttr(name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = hclString(v)
+	}
+	w.attr(name, "["+strings.Join(quoted, ", ")+"]")
+}
+
+func (w *hclWriter) mapAttr(name string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w.line("%s = {", name)
+	for _, k := range keys {
+		w.line("  %s = %s", hclString(k), hclString(values[k]))
+	}
+	w.line("}")
+}
+
+// label turns a resource name into a unique Terraform resource label
+func (w *hclWriter) label(resourceType, name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	base := b.String()
+	if base == "" || (base[0] >= '0' && base[0] <= '9') || base[0] == '-' {
+		base = "_" + base
+	}
+
+	label := base
+	for i := 2; w.labels[resourceType+"."+label]; i++ {
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	w.labels[resourceType+"."+label] = true
+	return label
+}
+
+// hclString quotes s as an HCL string literal. JSON escapes are valid HCL;
+// template sequences are escaped so values are taken literally.
+func hclString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	quoted := strings.TrimSuffix(buf.String(), "\n")
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
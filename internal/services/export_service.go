@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"go.uber.org/zap"
+)
+
+// ExportVersion is the version of the export document format
+const ExportVersion = 1
+
+// ExportDocument is a stable, ID-annotated snapshot of the OVN resources
+// managed through the API. Resources are sorted by name and carry the same
+// ETag the API returns for them, so the document diffs cleanly between runs
+// and can seed infrastructure-as-code state.
+type ExportDocument struct {
+	Version       int                    `json:"version" yaml:"version"`
+	Switches      []ExportedSwitch       `json:"switches" yaml:"switches"`
+	Routers       []ExportedRouter       `json:"routers" yaml:"routers"`
+	LoadBalancers []ExportedLoadBalancer `json:"load_balancers" yaml:"load_balancers"`
+}
+
+// ExportedSwitch is a logical switch with its ports and ACLs
+type ExportedSwitch struct {
+	ID          string            `json:"id" yaml:"id"`
+	ETag        string            `json:"etag" yaml:"etag"`
+	Name        string            `json:"name" yaml:"name"`
+	OtherConfig map[string]string `json:"other_config,omitempty" yaml:"other_config,omitempty"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" yaml:"external_ids,omitempty"`
+	Ports       []ExportedPort    `json:"ports" yaml:"ports"`
+	ACLs        []ExportedACL     `json:"acls" yaml:"acls"`
+}
+
+// ExportedPort is a logical switch port
+type ExportedPort struct {
+	ID           string            `json:"id" yaml:"id"`
+	ETag         string            `json:"etag" yaml:"etag"`
+	Name         string            `json:"name" yaml:"name"`
+	Type         string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Addresses    []string          `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	PortSecurity []string          `json:"port_security,omitempty" yaml:"port_security,omitempty"`
+	Options      map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	Enabled      *bool             `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	ExternalIDs  map[string]string `json:"external_ids,omitempty" yaml:"external_ids,omitempty"`
+}
+
+// ExportedACL is an ACL applied to a logical switch
+type ExportedACL struct {
+	ID          string            `json:"id" yaml:"id"`
+	ETag        string            `json:"etag" yaml:"etag"`
+	Name        string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Direction   string            `json:"direction" yaml:"direction"`
+	Priority    int               `json:"priority" yaml:"priority"`
+	Match       string            `json:"match" yaml:"match"`
+	Action      string            `json:"action" yaml:"action"`
+	Log         bool              `json:"log,omitempty" yaml:"log,omitempty"`
+	Severity    string            `json:"severity,omitempty" yaml:"severity,omitempty"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" yaml:"external_ids,omitempty"`
+}
+
+// ExportedRouter is a logical router
+type ExportedRouter struct {
+	ID          string            `json:"id" yaml:"id"`
+	ETag        string            `json:"etag" yaml:"etag"`
+	Name        string            `json:"name" yaml:"name"`
+	Options     map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" yaml:"external_ids,omitempty"`
+}
+
+// ExportedLoadBalancer is a load balancer
+type ExportedLoadBalancer struct {
+	ID          string            `json:"id" yaml:"id"`
+	ETag        string            `json:"etag" yaml:"etag"`
+	Name        string            `json:"name" yaml:"name"`
+	Protocol    string            `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	VIPs        map[string]string `json:"vips" yaml:"vips"`
+	Options     map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" yaml:"external_ids,omitempty"`
+}
+
+// ExportService builds export documents from the current OVN state
+type ExportService struct {
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+}
+
+// NewExportService creates a new export service
+func NewExportService(ovnService OVNServiceInterface, logger *zap.Logger) *ExportService {
+	return &ExportService{
+		ovnService: ovnService,
+		logger:     logger,
+	}
+}
+
+// Export reads switches, ports, ACLs, routers and load balancers
+func (s *ExportService) Export(ctx context.Context) (*ExportDocument, error) {
+	switches, err := s.ovnService.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list switches: %w", err)
+	}
+	routers, err := s.ovnService.ListLogicalRouters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routers: %w", err)
+	}
+	loadBalancers, err := s.ovnService.ListLoadBalancers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list load balancers: %w", err)
+	}
+
+	doc := &ExportDocument{
+		Version:       ExportVersion,
+		Switches:      []ExportedSwitch{},
+		Routers:       []ExportedRouter{},
+		LoadBalancers: []ExportedLoadBalancer{},
+	}
+
+	for _, sw := range sortedSwitches(switches) {
+		exported, err := s.exportSwitch(ctx, sw)
+		if err != nil {
+			return nil, err
+		}
+		doc.Switches = append(doc.Switches, *exported)
+	}
+
+	for _, lr := range sortedRouters(routers) {
+		etag, err := ResourceETag(lr)
+		if err != nil {
+			return nil, err
+		}
+		doc.Routers = append(doc.Routers, ExportedRouter{
+			ID:          lr.UUID,
+			ETag:        etag,
+			Name:        lr.Name,
+			Options:     lr.Options,
+			ExternalIDs: exportedExternalIDs(lr.ExternalIDs),
+		})
+	}
+
+	sort.Slice(loadBalancers, func(i, j int) bool { return loadBalancers[i].Name < loadBalancers[j].Name })
+	for _, lb := range loadBalancers {
+		etag, err := ResourceETag(lb)
+		if err != nil {
+			return nil, err
+		}
+		exported := ExportedLoadBalancer{
+			ID:          lb.UUID,
+			ETag:        etag,
+			Name:        lb.Name,
+			VIPs:        lb.VIPs,
+			Options:     lb.Options,
+			ExternalIDs: exportedExternalIDs(lb.ExternalIDs),
+		}
+		if lb.Protocol != nil {
+			exported.Protocol = *lb.Protocol
+		}
+		doc.LoadBalancers = append(doc.LoadBalancers, exported)
+	}
+
+	return doc, nil
+}
+
+func (s *ExportService) exportSwitch(ctx context.Context, sw *models.LogicalSwitch) (*ExportedSwitch, error) {
+	etag, err := ResourceETag(sw)
+	if err != nil {
+		return nil, err
+	}
+	exported := &ExportedSwitch{
+		ID:          sw.UUID,
+		ETag:        etag,
+		Name:        sw.Name,
+		OtherConfig: sw.OtherConfig,
+		ExternalIDs: exportedExternalIDs(sw.ExternalIDs),
+		Ports:       []ExportedPort{},
+		ACLs:        []ExportedACL{},
+	}
+
+	ports, err := s.ovnService.ListPorts(ctx, sw.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ports of switch %s: %w", sw.Name, err)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Name < ports[j].Name })
+	for _, port := range ports {
+		etag, err := ResourceETag(port)
+		if err != nil {
+			return nil, err
+		}
+		exported.Ports = append(exported.Ports, ExportedPort{
+			ID:           port.UUID,
+			ETag:         etag,
+			Name:         port.Name,
+			Type:         port.Type,
+			Addresses:    port.Addresses,
+			PortSecurity: port.PortSecurity,
+			Options:      port.Options,
+			Enabled:      port.Enabled,
+			ExternalIDs:  exportedExternalIDs(port.ExternalIDs),
+		})
+	}
+
+	acls, err := s.ovnService.ListACLs(ctx, sw.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACLs of switch %s: %w", sw.Name, err)
+	}
+	sort.Slice(acls, func(i, j int) bool {
+		if acls[i].Direction != acls[j].Direction {
+			return acls[i].Direction < acls[j].Direction
+		}
+		if acls[i].Priority != acls[j].Priority {
+			return acls[i].Priority > acls[j].Priority
+		}
+		return acls[i].Match < acls[j].Match
+	})
+	for _, acl := range acls {
+		etag, err := ResourceETag(acl)
+		if err != nil {
+			return nil, err
+		}
+		exported.ACLs = append(exported.ACLs, ExportedACL{
+			ID:          acl.UUID,
+			ETag:        etag,
+			Name:        acl.Name,
+			Direction:   acl.Direction,
+			Priority:    acl.Priority,
+			Match:       acl.Match,
+			Action:      acl.Action,
+			Log:         acl.Log,
+			Severity:    acl.Severity,
+			ExternalIDs: exportedExternalIDs(acl.ExternalIDs),
+		})
+	}
+
+	return exported, nil
+}
+
+// exportedExternalIDs drops the timestamps and ownership the API keeps in
+// external_ids so that exports only change when configuration does
+func exportedExternalIDs(externalIDs map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range externalIDs {
+		switch k {
+		case "created_at", "updated_at", models.CreatedByKey, models.CreatedSourceKey, models.UpdatedByKey, models.UpdatedSourceKey:
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[k] = v
+	}
+	return result
+}
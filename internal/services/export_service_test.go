@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestResourceETag(t *testing.T) {
+	sw := &models.LogicalSwitch{
+		UUID:        "sw-uuid",
+		Name:        "web",
+		OtherConfig: map[string]string{"subnet": "10.0.1.0/24"},
+		ExternalIDs: map[string]string{"owner": "team-a", "updated_at": "2024-01-01T00:00:00Z"},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	etag, err := ResourceETag(sw)
+	require.NoError(t, err)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	// Timestamps don't affect the tag, so idempotent updates keep it
+	touched := *sw
+	touched.ExternalIDs = map[string]string{"owner": "team-a", "updated_at": "2024-06-01T00:00:00Z"}
+	touched.UpdatedAt = time.Now().Add(time.Hour)
+	touchedETag, err := ResourceETag(&touched)
+	require.NoError(t, err)
+	assert.Equal(t, etag, touchedETag)
+
+	changed := *sw
+	changed.OtherConfig = map[string]string{"subnet": "10.0.2.0/24"}
+	changedETag, err := ResourceETag(&changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, etag, changedETag)
+}
+
+func TestExportService_Export(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	service := NewExportService(mockOVN, zap.NewNop())
+
+	tcp := "tcp"
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "db-uuid", Name: "db"},
+		{UUID: "app-uuid", Name: "app", ExternalIDs: map[string]string{"created_at": "2024-01-01T00:00:00Z", "owner": "team-a"}},
+	}, nil)
+	mockOVN.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{
+		{UUID: "lr-uuid", Name: "edge", Options: map[string]string{"chassis": "gw-1"}},
+	}, nil)
+	mockOVN.On("ListLoadBalancers", mock.Anything).Return([]*models.LoadBalancer{
+		{UUID: "lb-uuid", Name: "web-lb", Protocol: &tcp, VIPs: map[string]string{"10.0.0.10:80": "10.0.1.11:80"}},
+	}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "app-uuid").Return([]*models.LogicalSwitchPort{
+		{UUID: "p2-uuid", Name: "app-2"},
+		{UUID: "p1-uuid", Name: "app-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "app-uuid").Return([]*models.ACL{
+		{UUID: "acl-low", Direction: "to-lport", Priority: 100, Match: "ip4", Action: "drop"},
+		{UUID: "acl-high", Direction: "to-lport", Priority: 1000, Match: "tcp.dst == 80", Action: "allow"},
+	}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "db-uuid").Return([]*models.LogicalSwitchPort{}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "db-uuid").Return([]*models.ACL{}, nil)
+
+	doc, err := service.Export(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, ExportVersion, doc.Version)
+	require.Len(t, doc.Switches, 2)
+	app := doc.Switches[0]
+	assert.Equal(t, "app", app.Name)
+	assert.Equal(t, "app-uuid", app.ID)
+	assert.NotEmpty(t, app.ETag)
+	assert.Equal(t, map[string]string{"owner": "team-a"}, app.ExternalIDs)
+	assert.Equal(t, "app-1", app.Ports[0].Name)
+	assert.Equal(t, "acl-high", app.ACLs[0].ID)
+	assert.Equal(t, "db", doc.Switches[1].Name)
+
+	require.Len(t, doc.Routers, 1)
+	assert.Equal(t, "lr-uuid", doc.Routers[0].ID)
+	require.Len(t, doc.LoadBalancers, 1)
+	assert.Equal(t, "tcp", doc.LoadBalancers[0].Protocol)
+
+	// The ETag in the export is the one the API returns for the resource
+	etag, err := ResourceETag(&models.LoadBalancer{UUID: "lb-uuid", Name: "web-lb", Protocol: &tcp, VIPs: map[string]string{"10.0.0.10:80": "10.0.1.11:80"}})
+	require.NoError(t, err)
+	assert.Equal(t, etag, doc.LoadBalancers[0].ETag)
+}
+
+func TestRenderHCL(t *testing.T) {
+	enabled := false
+	doc := &ExportDocument{
+		Version: ExportVersion,
+		Switches: []ExportedSwitch{{
+			ID:          "sw-uuid",
+			Name:        "web-tier",
+			OtherConfig: map[string]string{"subnet": "10.0.1.0/24"},
+			Ports: []ExportedPort{
+				{ID: "p1-uuid", Name: "web.1", Addresses: []string{"dynamic"}, Enabled: &enabled},
+				{ID: "p2-uuid", Name: "web_1"},
+			},
+			ACLs: []ExportedACL{{ID: "acl-uuid", Direction: "to-lport", Priority: 1000, Match: `ip4.src == ${net}`, Action: "allow"}},
+		}},
+		Routers: []ExportedRouter{{ID: "lr-uuid", Name: "1edge"}},
+	}
+
+	hcl := string(RenderHCL(doc))
+
+	assert.Contains(t, hcl, "import {\n  to = ovncp_logical_switch.web-tier\n  id = \"sw-uuid\"\n}")
+	assert.Contains(t, hcl, `resource "ovncp_logical_switch" "web-tier" {`)
+	assert.Contains(t, hcl, "other_config = {\n    \"subnet\" = \"10.0.1.0/24\"\n  }")
+	assert.Contains(t, hcl, "switch_id = ovncp_logical_switch.web-tier.id")
+	assert.Contains(t, hcl, `addresses = ["dynamic"]`)
+	assert.Contains(t, hcl, "enabled = false")
+	// Labels are sanitized and kept unique
+	assert.Contains(t, hcl, `resource "ovncp_logical_switch_port" "web_1" {`)
+	assert.Contains(t, hcl, `resource "ovncp_logical_switch_port" "web_1_2" {`)
+	assert.Contains(t, hcl, `resource "ovncp_acl" "web-tier_to-lport_1000" {`)
+	assert.Contains(t, hcl, `resource "ovncp_logical_router" "_1edge" {`)
+	// Template sequences are escaped
+	assert.Contains(t, hcl, `match = "ip4.src == $${net}"`)
+}
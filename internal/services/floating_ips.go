@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// NATAddressesOption is the option of router-type switch ports that makes
+// the chassis hosting the router's gateway announce its NAT addresses with
+// gratuitous ARPs
+const NATAddressesOption = "nat-addresses"
+
+var (
+	// ErrFloatingIPNotFound is returned for floating IPs that aren't
+	// allocated to the caller's tenant
+	ErrFloatingIPNotFound = errors.New("floating IP not found")
+
+	// ErrInvalidFloatingIP is returned for bindings to ports without a
+	// usable address
+	ErrInvalidFloatingIP = errors.New("invalid floating IP")
+
+	// ErrNoFloatingIPPool is returned when no pool is configured, or an
+	// unknown pool is requested
+	ErrNoFloatingIPPool = errors.New("no floating IP pool")
+
+	// ErrFloatingIPPoolExhausted is returned when every address of a pool
+	// is in use
+	ErrFloatingIPPoolExhausted = errors.New("floating IP pool exhausted")
+
+	// ErrFloatingIPBinding is returned when binding a floating IP that's
+	// bound, or unbinding one that isn't
+	ErrFloatingIPBinding = errors.New("floating IP binding conflict")
+)
+
+// FloatingIPStore keeps the floating IPs allocated to tenants, shared by
+// the API's replicas. *db.DB implements it.
+type FloatingIPStore interface {
+	// CreateFloatingIP records fip unless its address is allocated
+	// already, reporting whether it did
+	CreateFloatingIP(ctx context.Context, fip *models.FloatingIP) (bool, error)
+	// GetFloatingIP returns a floating IP, nil when there's none with the
+	// ID
+	GetFloatingIP(ctx context.Context, id string) (*models.FloatingIP, error)
+	ListFloatingIPs(ctx context.Context, tenantID string) ([]*models.FloatingIP, error)
+	UpdateFloatingIP(ctx context.Context, fip *models.FloatingIP) error
+	DeleteFloatingIP(ctx context.Context, id string) error
+}
+
+// PortLookup finds logical ports within the caller's tenant, as
+// TenantOVNService does
+type PortLookup interface {
+	GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error)
+}
+
+// FloatingIPPool describes a pool floating IPs are allocated from
+type FloatingIPPool struct {
+	Name      string `json:"name"`
+	CIDR      string `json:"cidr"`
+	First     string `json:"first"`
+	Last      string `json:"last"`
+	Router    string `json:"router"`
+	Allocated int    `json:"allocated"`
+}
+
+// FloatingIPBinding binds a floating IP to a logical port
+type FloatingIPBinding struct {
+	PortID string `json:"port_id" binding:"required"`
+	// LogicalIP is the port's address the floating IP translates to, the
+	// first of the floating IP's family when empty
+	LogicalIP string `json:"logical_ip,omitempty"`
+	// Distributed NATs the floating IP on the port's chassis rather than
+	// on the router's gateway
+	Distributed bool `json:"distributed,omitempty"`
+	// GratuitousARP announces the address from the router's gateway
+	GratuitousARP bool `json:"gratuitous_arp,omitempty"`
+}
+
+// floatingIPPool is a configured pool with its range parsed
+type floatingIPPool struct {
+	config.FloatingIPPoolConfig
+	first, last netip.Addr
+}
+
+// FloatingIPService allocates external addresses to tenants from pools and
+// binds them to logical ports with dnat_and_snat rules on the pools'
+// routers. The NAT rules are the provider's, created whatever the caller's
+// tenant; ports are looked up within it.
+type FloatingIPService struct {
+	store  FloatingIPStore
+	ovn    OVNServiceInterface
+	ports  PortLookup
+	pools  []*floatingIPPool
+	byName map[string]*floatingIPPool
+	logger *zap.Logger
+	now    func() time.Time
+
+	// mu keeps concurrent requests from binding the same floating IP twice
+	mu sync.Mutex
+}
+
+// NewFloatingIPService creates a service allocating from pools, which may
+// be none
+func NewFloatingIPService(store FloatingIPStore, ovn OVNServiceInterface, ports PortLookup, pools []config.FloatingIPPoolConfig, logger *zap.Logger) (*FloatingIPService, error) {
+	s := &FloatingIPService{
+		store:  store,
+		ovn:    ovn,
+		ports:  ports,
+		byName: make(map[string]*floatingIPPool, len(pools)),
+		logger: logger,
+		now:    time.Now,
+	}
+	for _, cfg := range pools {
+		first, last, err := cfg.Range()
+		if err != nil {
+			return nil, fmt.Errorf("floating IP pool %s: %w", cfg.Name, err)
+		}
+		pool := &floatingIPPool{FloatingIPPoolConfig: cfg, first: first, last: last}
+		s.pools = append(s.pools, pool)
+		s.byName[cfg.Name] = pool
+	}
+	return s, nil
+}
+
+// Pools lists the configured pools with the number of their addresses
+// allocated
+func (s *FloatingIPService) Pools(ctx context.Context) ([]*FloatingIPPool, error) {
+	fips, err := s.store.ListFloatingIPs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	allocated := make(map[string]int)
+	for _, fip := range fips {
+		allocated[fip.Pool]++
+	}
+
+	pools := make([]*FloatingIPPool, len(s.pools))
+	for i, pool := range s.pools {
+		pools[i] = &FloatingIPPool{
+			Name:      pool.Name,
+			CIDR:      pool.CIDR,
+			First:     pool.first.String(),
+			Last:      pool.last.String(),
+			Router:    pool.Router,
+			Allocated: allocated[pool.Name],
+		}
+	}
+	return pools, nil
+}
+
+// Allocate allocates the next free address of a pool, the first pool when
+// poolName is empty, to the context's tenant. Addresses used by NAT rules
+// or router ports aren't handed out.
+func (s *FloatingIPService) Allocate(ctx context.Context, poolName, description, user string) (*models.FloatingIP, error) {
+	pool, err := s.pool(poolName)
+	if err != nil {
+		return nil, err
+	}
+	router, err := s.ovn.GetLogicalRouter(ctx, pool.Router)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get router %s of floating IP pool %s: %w", pool.Router, pool.Name, err)
+	}
+	taken, err := s.takenAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fip := &models.FloatingIP{
+		ID:          uuid.New().String(),
+		TenantID:    getTenantFromContext(ctx),
+		Pool:        pool.Name,
+		Description: description,
+		RouterID:    router.UUID,
+		AllocatedBy: user,
+		AllocatedAt: s.now().UTC(),
+	}
+	for addr := pool.first; addr.IsValid() && addr.Compare(pool.last) <= 0; addr = addr.Next() {
+		if taken[addr] {
+			continue
+		}
+		// Another replica may allocate the address in the meantime
+		fip.IP = addr.String()
+		created, err := s.store.CreateFloatingIP(ctx, fip)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			s.logger.Info("Floating IP allocated",
+				zap.String("floating_ip_id", fip.ID),
+				zap.String("ip", fip.IP),
+				zap.String("pool", pool.Name),
+				zap.String("tenant_id", fip.TenantID))
+			return fip, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: every address of floating IP pool %s is in use", ErrFloatingIPPoolExhausted, pool.Name)
+}
+
+// pool returns the pool named name, the first one when it's empty
+func (s *FloatingIPService) pool(name string) (*floatingIPPool, error) {
+	if len(s.pools) == 0 {
+		return nil, fmt.Errorf("%w: no floating IP pools are configured", ErrNoFloatingIPPool)
+	}
+	if name == "" {
+		return s.pools[0], nil
+	}
+	pool, ok := s.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown floating IP pool %q", ErrNoFloatingIPPool, name)
+	}
+	return pool, nil
+}
+
+// takenAddresses returns the addresses already allocated, NATed or
+// assigned to router ports
+func (s *FloatingIPService) takenAddresses(ctx context.Context) (map[netip.Addr]bool, error) {
+	taken := make(map[netip.Addr]bool)
+	add := func(value string) {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			taken[addr] = true
+		} else if prefix, err := netip.ParsePrefix(value); err == nil {
+			taken[prefix.Addr()] = true
+		}
+	}
+
+	fips, err := s.store.ListFloatingIPs(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, fip := range fips {
+		add(fip.IP)
+	}
+
+	topology, err := s.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+	for _, router := range topology.Routers {
+		for _, nat := range router.NAT {
+			add(nat.ExternalIP)
+		}
+	}
+	for _, port := range topology.RouterPorts {
+		for _, network := range port.Networks {
+			add(network)
+		}
+	}
+	return taken, nil
+}
+
+// Get returns a floating IP of the context's tenant
+func (s *FloatingIPService) Get(ctx context.Context, id string) (*models.FloatingIP, error) {
+	fip, err := s.store.GetFloatingIP(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if fip == nil {
+		return nil, ErrFloatingIPNotFound
+	}
+	if tenantID := getTenantFromContext(ctx); tenantID != "" && fip.TenantID != tenantID {
+		return nil, ErrFloatingIPNotFound
+	}
+	return fip, nil
+}
+
+// List lists the floating IPs of the context's tenant, or all of them
+// without a tenant
+func (s *FloatingIPService) List(ctx context.Context) ([]*models.FloatingIP, error) {
+	return s.store.ListFloatingIPs(ctx, getTenantFromContext(ctx))
+}
+
+// Bind creates the dnat_and_snat rule translating a floating IP to an
+// address of a port of the context's tenant
+func (s *FloatingIPService) Bind(ctx context.Context, id string, binding *FloatingIPBinding) (*models.FloatingIP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fip, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if fip.Bound() {
+		return nil, fmt.Errorf("%w: floating IP %s is bound to port %s", ErrFloatingIPBinding, fip.IP, fip.PortID)
+	}
+
+	port, err := s.ports.GetPort(ctx, binding.PortID)
+	if err != nil {
+		return nil, err
+	}
+	mac, logicalIP, err := bindingAddress(port, fip.IP, binding.LogicalIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUnbound(ctx, fip, logicalIP); err != nil {
+		return nil, err
+	}
+
+	nat := &models.NAT{
+		Type:       "dnat_and_snat",
+		ExternalIP: fip.IP,
+		LogicalIP:  logicalIP,
+		ExternalIDs: map[string]string{
+			models.FloatingIPKey: fip.ID,
+			models.TenantIDKey:   fip.TenantID,
+		},
+	}
+	if binding.Distributed {
+		if mac == "" {
+			return nil, fmt.Errorf("%w: port %s has no MAC to NAT a distributed floating IP with", ErrInvalidFloatingIP, port.Name)
+		}
+		nat.LogicalPort, nat.ExternalMAC = &port.Name, &mac
+	}
+	ops := []TransactionOp{{
+		Operation:    models.OperationCreate,
+		ResourceType: models.ResourceNAT,
+		RouterID:     fip.RouterID,
+		Data:         nat,
+	}}
+	if err := s.ovn.ExecuteTransaction(ctx, ops); err != nil {
+		return nil, fmt.Errorf("failed to create NAT rule: %w", err)
+	}
+
+	boundAt := s.now().UTC()
+	fip.PortID, fip.LogicalIP, fip.NATID = port.UUID, logicalIP, ops[0].ResourceID
+	fip.Distributed, fip.GratuitousARP, fip.BoundAt = binding.Distributed, binding.GratuitousARP, &boundAt
+	if err := s.store.UpdateFloatingIP(ctx, fip); err != nil {
+		if err := s.deleteNAT(ctx, fip.NATID); err != nil {
+			s.logger.Error("Failed to delete NAT rule of unsaved floating IP binding",
+				zap.String("nat_id", fip.NATID),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if binding.GratuitousARP {
+		// The binding holds without the announcement; its neighbours
+		// learn the address when they ARP for it
+		if err := s.announce(ctx, fip.RouterID); err != nil {
+			s.logger.Warn("Failed to enable gratuitous ARPs for floating IP",
+				zap.String("floating_ip_id", fip.ID),
+				zap.String("router_id", fip.RouterID),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Floating IP bound",
+		zap.String("floating_ip_id", fip.ID),
+		zap.String("ip", fip.IP),
+		zap.String("port_id", fip.PortID),
+		zap.String("logical_ip", fip.LogicalIP))
+	return fip, nil
+}
+
+// bindingAddress returns the MAC of a port and its address a floating IP
+// translates to: logicalIP, which must be one of the port's, or else the
+// port's first address of the floating IP's family
+func bindingAddress(port *models.LogicalSwitchPort, floatingIP, logicalIP string) (string, string, error) {
+	external, err := netip.ParseAddr(floatingIP)
+	if err != nil {
+		return "", "", err
+	}
+
+	mac := port.MAC
+	var candidates []netip.Addr
+	for _, address := range append(append([]string{}, port.Addresses...), port.PortSecurity...) {
+		fields := strings.Fields(address)
+		if len(fields) < 2 {
+			continue
+		}
+		if mac == "" {
+			mac = fields[0]
+		}
+		for _, field := range fields[1:] {
+			if addr, err := netip.ParseAddr(strings.SplitN(field, "/", 2)[0]); err == nil {
+				candidates = append(candidates, addr)
+			}
+		}
+	}
+
+	for _, addr := range candidates {
+		if logicalIP == "" && addr.Is4() == external.Is4() {
+			return mac, addr.String(), nil
+		}
+		if logicalIP != "" && addr.String() == logicalIP {
+			if addr.Is4() != external.Is4() {
+				return "", "", fmt.Errorf("%w: logical IP %s and floating IP %s aren't of the same family", ErrInvalidFloatingIP, logicalIP, floatingIP)
+			}
+			return mac, logicalIP, nil
+		}
+	}
+	if logicalIP != "" {
+		return "", "", fmt.Errorf("%w: %s isn't an address of port %s", ErrInvalidFloatingIP, logicalIP, port.Name)
+	}
+	return "", "", fmt.Errorf("%w: port %s has no address of floating IP %s's family", ErrInvalidFloatingIP, port.Name, floatingIP)
+}
+
+// checkUnbound refuses to translate a second floating IP of the same
+// router to the same logical IP, which OVN can't tell apart for SNAT
+func (s *FloatingIPService) checkUnbound(ctx context.Context, fip *models.FloatingIP, logicalIP string) error {
+	fips, err := s.store.ListFloatingIPs(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, other := range fips {
+		if other.ID != fip.ID && other.RouterID == fip.RouterID && other.LogicalIP == logicalIP {
+			return fmt.Errorf("%w: %s is translated to floating IP %s already", ErrFloatingIPBinding, logicalIP, other.IP)
+		}
+	}
+	return nil
+}
+
+// announce makes the gateways of a router announce its NAT addresses on
+// the switches with a localnet port it's connected to
+func (s *FloatingIPService) announce(ctx context.Context, routerID string) error {
+	topology, err := s.ovn.GetTopology(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get topology: %w", err)
+	}
+
+	routerPorts := make(map[string]bool)
+	for _, router := range topology.Routers {
+		if router.UUID == routerID {
+			for _, id := range router.Ports {
+				routerPorts[id] = true
+			}
+		}
+	}
+	peers := make(map[string]bool)
+	for _, port := range topology.RouterPorts {
+		if routerPorts[port.UUID] {
+			peers[port.Name] = true
+		}
+	}
+
+	ports := make(map[string]*models.LogicalSwitchPort, len(topology.Ports))
+	for _, port := range topology.Ports {
+		ports[port.UUID] = port
+	}
+	for _, sw := range topology.Switches {
+		external := false
+		var gateways []*models.LogicalSwitchPort
+		for _, id := range sw.Ports {
+			port, ok := ports[id]
+			if !ok {
+				continue
+			}
+			switch {
+			case port.Type == "localnet":
+				external = true
+			case port.Type == "router" && peers[port.Options["router-port"]]:
+				gateways = append(gateways, port)
+			}
+		}
+		if !external {
+			continue
+		}
+
+		for _, port := range gateways {
+			if _, ok := port.Options[NATAddressesOption]; ok {
+				continue
+			}
+			options := make(map[string]string, len(port.Options)+1)
+			for k, v := range port.Options {
+				options[k] = v
+			}
+			options[NATAddressesOption] = "router"
+			if _, err := s.ovn.UpdatePort(ctx, port.UUID, &models.LogicalSwitchPort{Options: options}); err != nil {
+				return fmt.Errorf("failed to update port %s: %w", port.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Unbind deletes the NAT rule of a floating IP, keeping it allocated
+func (s *FloatingIPService) Unbind(ctx context.Context, id string) (*models.FloatingIP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fip, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !fip.Bound() {
+		return nil, fmt.Errorf("%w: floating IP %s isn't bound", ErrFloatingIPBinding, fip.IP)
+	}
+	if err := s.unbind(ctx, fip); err != nil {
+		return nil, err
+	}
+	return fip, nil
+}
+
+// Release unbinds a floating IP if it's bound and returns its address to
+// the pool
+func (s *FloatingIPService) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fip, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if fip.Bound() {
+		if err := s.unbind(ctx, fip); err != nil {
+			return err
+		}
+	}
+	if err := s.store.DeleteFloatingIP(ctx, fip.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Floating IP released",
+		zap.String("floating_ip_id", fip.ID),
+		zap.String("ip", fip.IP))
+	return nil
+}
+
+func (s *FloatingIPService) unbind(ctx context.Context, fip *models.FloatingIP) error {
+	if err := s.deleteNAT(ctx, fip.NATID); err != nil {
+		return fmt.Errorf("failed to delete NAT rule: %w", err)
+	}
+
+	portID := fip.PortID
+	fip.PortID, fip.LogicalIP, fip.NATID = "", "", ""
+	fip.Distributed, fip.GratuitousARP, fip.BoundAt = false, false, nil
+	if err := s.store.UpdateFloatingIP(ctx, fip); err != nil {
+		return err
+	}
+
+	s.logger.Info("Floating IP unbound",
+		zap.String("floating_ip_id", fip.ID),
+		zap.String("ip", fip.IP),
+		zap.String("port_id", portID))
+	return nil
+}
+
+// deleteNAT deletes a NAT rule. Rules deleted already are skipped, so an
+// unbinding that failed can be retried.
+func (s *FloatingIPService) deleteNAT(ctx context.Context, natID string) error {
+	err := s.ovn.ExecuteTransaction(ctx, []TransactionOp{{
+		Operation:    models.OperationDelete,
+		ResourceType: models.ResourceNAT,
+		ResourceID:   natID,
+	}})
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return err
+	}
+	return nil
+}
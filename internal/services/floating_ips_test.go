@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryFloatingIPStore keeps floating IPs in memory, in the order they
+// were allocated
+type memoryFloatingIPStore struct {
+	fips []*models.FloatingIP
+}
+
+func (s *memoryFloatingIPStore) CreateFloatingIP(ctx context.Context, fip *models.FloatingIP) (bool, error) {
+	for _, other := range s.fips {
+		if other.IP == fip.IP {
+			return false, nil
+		}
+	}
+	stored := *fip
+	s.fips = append(s.fips, &stored)
+	return true, nil
+}
+
+func (s *memoryFloatingIPStore) GetFloatingIP(ctx context.Context, id string) (*models.FloatingIP, error) {
+	for _, fip := range s.fips {
+		if fip.ID == id {
+			stored := *fip
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryFloatingIPStore) ListFloatingIPs(ctx context.Context, tenantID string) ([]*models.FloatingIP, error) {
+	fips := []*models.FloatingIP{}
+	for _, fip := range s.fips {
+		if tenantID == "" || fip.TenantID == tenantID {
+			stored := *fip
+			fips = append(fips, &stored)
+		}
+	}
+	return fips, nil
+}
+
+func (s *memoryFloatingIPStore) UpdateFloatingIP(ctx context.Context, fip *models.FloatingIP) error {
+	for i, other := range s.fips {
+		if other.ID == fip.ID {
+			stored := *fip
+			s.fips[i] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryFloatingIPStore) DeleteFloatingIP(ctx context.Context, id string) error {
+	for i, fip := range s.fips {
+		if fip.ID == id {
+			s.fips = append(s.fips[:i], s.fips[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakePorts are the ports of tenant acme by ID
+type fakePorts map[string]*models.LogicalSwitchPort
+
+func (f fakePorts) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
+	port, ok := f[id]
+	if !ok || getTenantFromContext(ctx) != "acme" {
+		return nil, ErrResourceNotInTenant
+	}
+	return port, nil
+}
+
+// floatingIPTopology is the gateway router edge, whose external port is
+// connected to the provider switch with a localnet port, and which NATs
+// 203.0.113.2 already
+func floatingIPTopology() *Topology {
+	return &Topology{
+		Switches: []*models.LogicalSwitch{{UUID: "sw-ext", Name: "provider", Ports: []string{"lsp-ln", "lsp-edge"}}},
+		Routers: []*models.LogicalRouter{{
+			UUID:  "lr-edge",
+			Name:  "edge",
+			Ports: []string{"lrp-ext"},
+			NAT:   []models.NAT{{UUID: "nat-0", Type: "snat", ExternalIP: "203.0.113.2", LogicalIP: "10.0.0.0/24"}},
+		}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "lsp-ln", Name: "provider-ln", Type: "localnet"},
+			{UUID: "lsp-edge", Name: "provider-edge", Type: "router", Options: map[string]string{"router-port": "edge-ext"}},
+		},
+		RouterPorts: []*models.LogicalRouterPort{{UUID: "lrp-ext", Name: "edge-ext", Networks: []string{"203.0.113.1/24"}}},
+	}
+}
+
+func newTestFloatingIPs(t *testing.T) (*FloatingIPService, *MockOVNService, *memoryFloatingIPStore) {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalRouter", mock.Anything, "edge").Return(&models.LogicalRouter{UUID: "lr-edge", Name: "edge"}, nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(floatingIPTopology(), nil)
+
+	store := &memoryFloatingIPStore{}
+	ports := fakePorts{
+		"lsp-web1": {UUID: "lsp-web1", Name: "web-1", Addresses: []string{"0a:58:a9:00:00:01 10.0.0.11 fd00::11"}},
+		"lsp-web2": {UUID: "lsp-web2", Name: "web-2", Addresses: []string{"0a:58:a9:00:00:02 10.0.0.12"}},
+	}
+	service, err := NewFloatingIPService(store, mockOVN, ports, []config.FloatingIPPoolConfig{
+		{Name: "public", CIDR: "203.0.113.0/24", End: "203.0.113.3", Router: "edge"},
+	}, zap.NewNop())
+	require.NoError(t, err)
+	return service, mockOVN, store
+}
+
+func TestFloatingIPService_Allocate(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service, _, _ := newTestFloatingIPs(t)
+
+	// The router's own address and the NATed one are skipped
+	fip, err := service.Allocate(ctx, "", "web frontend", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.3", fip.IP)
+	assert.Equal(t, "public", fip.Pool)
+	assert.Equal(t, "acme", fip.TenantID)
+	assert.Equal(t, "lr-edge", fip.RouterID)
+	assert.False(t, fip.Bound())
+
+	_, err = service.Allocate(ctx, "", "", "alice")
+	assert.ErrorIs(t, err, ErrFloatingIPPoolExhausted)
+	_, err = service.Allocate(ctx, "private", "", "alice")
+	assert.ErrorIs(t, err, ErrNoFloatingIPPool)
+
+	pools, err := service.Pools(ctx)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+	assert.Equal(t, "203.0.113.1", pools[0].First)
+	assert.Equal(t, 1, pools[0].Allocated)
+
+	// Listed and found within the tenant only
+	other := ContextWithTenant(context.Background(), "other")
+	fips, err := service.List(other)
+	require.NoError(t, err)
+	assert.Empty(t, fips)
+	_, err = service.Get(other, fip.ID)
+	assert.ErrorIs(t, err, ErrFloatingIPNotFound)
+	fips, err = service.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, fips, 1, "all floating IPs without a tenant")
+}
+
+func TestFloatingIPService_Bind(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service, mockOVN, store := newTestFloatingIPs(t)
+	fip, err := service.Allocate(ctx, "public", "", "alice")
+	require.NoError(t, err)
+
+	mockOVN.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		return len(ops) == 1 && ops[0].Operation == models.OperationCreate
+	})).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		nat := ops[0].Data.(*models.NAT)
+		assert.Equal(t, "lr-edge", ops[0].RouterID)
+		assert.Equal(t, "dnat_and_snat", nat.Type)
+		assert.Equal(t, "203.0.113.3", nat.ExternalIP)
+		assert.Equal(t, "10.0.0.11", nat.LogicalIP, "the port's address of the floating IP's family")
+		assert.Equal(t, "web-1", *nat.LogicalPort)
+		assert.Equal(t, "0a:58:a9:00:00:01", *nat.ExternalMAC)
+		assert.Equal(t, fip.ID, nat.ExternalIDs[models.FloatingIPKey])
+		ops[0].ResourceID = "nat-1"
+	}).Return(nil).Once()
+	mockOVN.On("UpdatePort", mock.Anything, "lsp-edge", &models.LogicalSwitchPort{
+		Options: map[string]string{"router-port": "edge-ext", NATAddressesOption: "router"},
+	}).Return(&models.LogicalSwitchPort{}, nil)
+
+	bound, err := service.Bind(ctx, fip.ID, &FloatingIPBinding{PortID: "lsp-web1", Distributed: true, GratuitousARP: true})
+	require.NoError(t, err)
+	assert.Equal(t, "lsp-web1", bound.PortID)
+	assert.Equal(t, "nat-1", bound.NATID)
+	assert.NotNil(t, bound.BoundAt)
+	assert.Equal(t, "nat-1", store.fips[0].NATID)
+	mockOVN.AssertExpectations(t)
+
+	_, err = service.Bind(ctx, fip.ID, &FloatingIPBinding{PortID: "lsp-web2"})
+	assert.ErrorIs(t, err, ErrFloatingIPBinding)
+
+	// Unbinding deletes the NAT rule, keeping the address allocated
+	mockOVN.On("ExecuteTransaction", mock.Anything, []TransactionOp{{
+		Operation:    models.OperationDelete,
+		ResourceType: models.ResourceNAT,
+		ResourceID:   "nat-1",
+	}}).Return(nil).Once()
+	unbound, err := service.Unbind(ctx, fip.ID)
+	require.NoError(t, err)
+	assert.False(t, unbound.Bound())
+	assert.Empty(t, store.fips[0].NATID)
+	_, err = service.Unbind(ctx, fip.ID)
+	assert.ErrorIs(t, err, ErrFloatingIPBinding)
+
+	require.NoError(t, service.Release(ctx, fip.ID))
+	assert.Empty(t, store.fips)
+}
+
+func TestFloatingIPService_BindAddress(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service, _, store := newTestFloatingIPs(t)
+	fip, err := service.Allocate(ctx, "", "", "alice")
+	require.NoError(t, err)
+
+	_, err = service.Bind(ctx, fip.ID, &FloatingIPBinding{PortID: "lsp-web1", LogicalIP: "10.0.0.99"})
+	assert.ErrorIs(t, err, ErrInvalidFloatingIP)
+	_, err = service.Bind(ctx, fip.ID, &FloatingIPBinding{PortID: "lsp-web1", LogicalIP: "fd00::11"})
+	assert.ErrorIs(t, err, ErrInvalidFloatingIP, "an IPv4 floating IP can't translate to an IPv6 address")
+	_, err = service.Bind(ContextWithTenant(context.Background(), "other"), fip.ID, &FloatingIPBinding{PortID: "lsp-web1"})
+	assert.ErrorIs(t, err, ErrFloatingIPNotFound)
+
+	// An address is translated to one floating IP of a router
+	store.fips = append(store.fips, &models.FloatingIP{ID: "fip-2", IP: "203.0.113.9", RouterID: "lr-edge", PortID: "lsp-web2", LogicalIP: "10.0.0.12"})
+	_, err = service.Bind(ctx, fip.ID, &FloatingIPBinding{PortID: "lsp-web2"})
+	assert.ErrorIs(t, err, ErrFloatingIPBinding)
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// maxGatewayPriority is the highest priority OVN accepts for a gateway chassis
+const maxGatewayPriority = 32767
+
+// ChassisPlacement is the gateways a chassis hosts, by port name or, for l3
+// gateway routers, router name
+type ChassisPlacement struct {
+	Chassis string   `json:"chassis"`
+	Active  []string `json:"active"`
+	Standby []string `json:"standby"`
+}
+
+// GatewayPlacement is where the gateways are placed across the chassis
+type GatewayPlacement struct {
+	Chassis  []ChassisPlacement `json:"chassis"`
+	Unplaced []string           `json:"unplaced,omitempty"` // Gateways without a chassis
+}
+
+// GatewayMove is a change of the chassis a gateway, or every port of an HA
+// chassis group, is active on
+type GatewayMove struct {
+	Ports          []string                `json:"ports"`
+	HAChassisGroup string                  `json:"ha_chassis_group,omitempty"`
+	From           string                  `json:"from"`
+	To             string                  `json:"to"`
+	Chassis        []models.GatewayChassis `json:"chassis"` // The new priorities
+}
+
+// GatewayRebalance is a plan redistributing the active gateways evenly across
+// the chassis, and whether it was applied
+type GatewayRebalance struct {
+	DryRun  bool           `json:"dry_run"`
+	Applied bool           `json:"applied"`
+	Moves   []GatewayMove  `json:"moves"`
+	Before  map[string]int `json:"before"` // Active gateways per chassis
+	After   map[string]int `json:"after"`
+}
+
+// gatewayName names a gateway in a placement
+func gatewayName(gateway *models.Gateway) string {
+	if gateway.PortName != "" {
+		return gateway.PortName
+	}
+	return gateway.RouterName
+}
+
+// activeChassis returns the chassis a gateway is active on: the one
+// ovn-northd reports, or else the one with the highest priority
+func activeChassis(gateway *models.Gateway) string {
+	if gateway.HostingChassis != "" {
+		return gateway.HostingChassis
+	}
+	if len(gateway.Chassis) > 0 {
+		return gateway.Chassis[0].Name
+	}
+	return ""
+}
+
+// PlaceGateways lists the gateways each chassis hosts, active or standby
+func PlaceGateways(gateways []*models.Gateway) *GatewayPlacement {
+	placement := &GatewayPlacement{Chassis: []ChassisPlacement{}}
+	byChassis := make(map[string]*ChassisPlacement)
+	chassisPlacement := func(name string) *ChassisPlacement {
+		if p, ok := byChassis[name]; ok {
+			return p
+		}
+		p := &ChassisPlacement{Chassis: name, Active: []string{}, Standby: []string{}}
+		byChassis[name] = p
+		return p
+	}
+
+	for _, gateway := range gateways {
+		name := gatewayName(gateway)
+		active := activeChassis(gateway)
+		if active == "" {
+			placement.Unplaced = append(placement.Unplaced, name)
+			continue
+		}
+		chassisPlacement(active).Active = append(chassisPlacement(active).Active, name)
+		for _, chassis := range gateway.Chassis {
+			if chassis.Name != active {
+				chassisPlacement(chassis.Name).Standby = append(chassisPlacement(chassis.Name).Standby, name)
+			}
+		}
+	}
+
+	for _, p := range byChassis {
+		placement.Chassis = append(placement.Chassis, *p)
+	}
+	sort.Slice(placement.Chassis, func(i, j int) bool {
+		return placement.Chassis[i].Chassis < placement.Chassis[j].Chassis
+	})
+	return placement
+}
+
+// gatewayUnit is what a rebalance moves: the ports sharing an HA chassis
+// group, which fail over together, or a single port
+type gatewayUnit struct {
+	ports   []*models.Gateway
+	group   string
+	chassis []models.GatewayChassis
+}
+
+func (u *gatewayUnit) primary() string {
+	return u.chassis[0].Name
+}
+
+func (u *gatewayUnit) key() string {
+	if u.group != "" {
+		return u.group
+	}
+	return u.ports[0].PortName
+}
+
+// PlanGatewayRebalance plans moving the active gateways so each chassis
+// hosts as close to its share as the chassis they may use allow. Gateways
+// already on a chassis below its share stay there, so a balanced placement
+// yields no moves. A move raises the new chassis to the top priority,
+// reusing the priorities the gateway already has, and leaves the others in
+// their order.
+func PlanGatewayRebalance(gateways []*models.Gateway) *GatewayRebalance {
+	plan := &GatewayRebalance{Moves: []GatewayMove{}, Before: map[string]int{}, After: map[string]int{}}
+
+	// L3 gateway routers and gateways with a single chassis can't move but
+	// still load their chassis
+	var units []*gatewayUnit
+	groups := make(map[string]*gatewayUnit)
+	for _, gateway := range gateways {
+		for _, chassis := range gateway.Chassis {
+			if _, ok := plan.Before[chassis.Name]; !ok {
+				plan.Before[chassis.Name] = 0
+				plan.After[chassis.Name] = 0
+			}
+		}
+		if gateway.Type != ovn.GatewayTypeDistributed || len(gateway.Chassis) < 2 {
+			if active := activeChassis(gateway); active != "" {
+				plan.Before[active]++
+				plan.After[active]++
+			}
+			continue
+		}
+
+		if gateway.HAChassisGroup != "" {
+			if unit, ok := groups[gateway.HAChassisGroup]; ok {
+				unit.ports = append(unit.ports, gateway)
+				continue
+			}
+		}
+		unit := &gatewayUnit{ports: []*models.Gateway{gateway}, group: gateway.HAChassisGroup, chassis: gateway.Chassis}
+		if unit.group != "" {
+			groups[unit.group] = unit
+		}
+		units = append(units, unit)
+	}
+
+	total := 0
+	for _, count := range plan.Before {
+		total += count
+	}
+	for _, unit := range units {
+		plan.Before[unit.primary()] += len(unit.ports)
+		total += len(unit.ports)
+	}
+	if len(plan.Before) == 0 {
+		return plan
+	}
+	share := (total + len(plan.Before) - 1) / len(plan.Before)
+
+	// Place the largest units first, as they are the hardest to fit
+	sort.SliceStable(units, func(i, j int) bool {
+		if len(units[i].ports) != len(units[j].ports) {
+			return len(units[i].ports) > len(units[j].ports)
+		}
+		return units[i].key() < units[j].key()
+	})
+	for _, unit := range units {
+		weight := len(unit.ports)
+		current := unit.primary()
+		target := current
+		if plan.After[current]+weight > share {
+			for _, chassis := range unit.chassis {
+				load, best := plan.After[chassis.Name], plan.After[target]
+				if load < best || (load == best && chassis.Name < target) {
+					target = chassis.Name
+				}
+			}
+		}
+		plan.After[target] += weight
+		if target == current {
+			continue
+		}
+
+		move := GatewayMove{HAChassisGroup: unit.group, From: current, To: target, Chassis: reprioritize(unit.chassis, target)}
+		for _, port := range unit.ports {
+			move.Ports = append(move.Ports, port.PortName)
+		}
+		plan.Moves = append(plan.Moves, move)
+	}
+	return plan
+}
+
+// reprioritize gives the top priority to a chassis, keeping the priorities
+// already in use and the order of the other chassis. The top priority is
+// raised when it's shared, as OVN picks among equal priorities itself.
+func reprioritize(chassis []models.GatewayChassis, top string) []models.GatewayChassis {
+	priorities := make([]int, len(chassis))
+	order := []string{top}
+	for i, ch := range chassis {
+		priorities[i] = ch.Priority
+		if ch.Name != top {
+			order = append(order, ch.Name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+	if len(priorities) > 1 && priorities[0] == priorities[1] && priorities[0] < maxGatewayPriority {
+		priorities[0]++
+	}
+
+	result := make([]models.GatewayChassis, len(order))
+	for i, name := range order {
+		result[i] = models.GatewayChassis{Name: name, Priority: priorities[i]}
+	}
+	return result
+}
+
+// Placement lists the gateways each chassis hosts
+func (m *GatewayMonitor) Placement(ctx context.Context) (*GatewayPlacement, error) {
+	gateways, err := m.ovn.ListGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return PlaceGateways(gateways), nil
+}
+
+// Rebalance plans redistributing the active gateways evenly across the
+// chassis and, unless dryRun, applies the plan in a single transaction
+func (m *GatewayMonitor) Rebalance(ctx context.Context, dryRun bool) (*GatewayRebalance, error) {
+	gateways, err := m.ovn.ListGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := PlanGatewayRebalance(gateways)
+	plan.DryRun = dryRun
+	if dryRun || len(plan.Moves) == 0 {
+		return plan, nil
+	}
+
+	portIDs := make(map[string]string, len(gateways))
+	for _, gateway := range gateways {
+		portIDs[gateway.PortName] = gateway.PortID
+	}
+	// Ports sharing an HA chassis group change with it, so one is enough
+	priorities := make(map[string][]models.GatewayChassis, len(plan.Moves))
+	for _, move := range plan.Moves {
+		priorities[portIDs[move.Ports[0]]] = move.Chassis
+	}
+	if err := m.ovn.SetGatewayPriorities(ctx, priorities); err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// SetBinding binds a router port to gateway chassis, or an HA chassis group
+// when binding names one, after checking the chassis and priorities
+func (m *GatewayMonitor) SetBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	if err := validateGatewayBinding(binding); err != nil {
+		return nil, err
+	}
+	return m.ovn.SetGatewayBinding(ctx, portID, binding)
+}
+
+// validateGatewayBinding checks a binding has distinct, named chassis with
+// priorities OVN accepts
+func validateGatewayBinding(binding *models.GatewayBinding) error {
+	if len(binding.Chassis) == 0 {
+		return fmt.Errorf("invalid gateway binding: at least one chassis is required")
+	}
+	seen := make(map[string]bool, len(binding.Chassis))
+	for _, chassis := range binding.Chassis {
+		if chassis.Name == "" {
+			return fmt.Errorf("invalid gateway binding: chassis name is required")
+		}
+		if seen[chassis.Name] {
+			return fmt.Errorf("invalid gateway binding: chassis %s is listed twice", chassis.Name)
+		}
+		seen[chassis.Name] = true
+		if chassis.Priority < 0 || chassis.Priority > maxGatewayPriority {
+			return fmt.Errorf("invalid gateway binding: priority of chassis %s must be between 0 and %d", chassis.Name, maxGatewayPriority)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func distributedGateway(port, group string, chassis ...string) *models.Gateway {
+	gateway := &models.Gateway{Type: "distributed", RouterName: "r-" + port, PortID: port + "-uuid", PortName: port, HAChassisGroup: group}
+	for i, name := range chassis {
+		gateway.Chassis = append(gateway.Chassis, models.GatewayChassis{Name: name, Priority: 30 - 10*i})
+	}
+	return gateway
+}
+
+func TestPlaceGateways(t *testing.T) {
+	placement := PlaceGateways([]*models.Gateway{
+		distributedGateway("lrp-1", "", "gw-1", "gw-2"),
+		{Type: "l3_gateway", RouterName: "gr-1", Chassis: []models.GatewayChassis{{Name: "gw-2"}}, HostingChassis: "gw-2"},
+		distributedGateway("lrp-2", ""),
+	})
+
+	assert.Equal(t, []ChassisPlacement{
+		{Chassis: "gw-1", Active: []string{"lrp-1"}, Standby: []string{}},
+		{Chassis: "gw-2", Active: []string{"gr-1"}, Standby: []string{"lrp-1"}},
+	}, placement.Chassis)
+	assert.Equal(t, []string{"lrp-2"}, placement.Unplaced)
+}
+
+func TestPlanGatewayRebalance(t *testing.T) {
+	t.Run("balanced", func(t *testing.T) {
+		plan := PlanGatewayRebalance([]*models.Gateway{
+			distributedGateway("lrp-1", "", "gw-1", "gw-2"),
+			distributedGateway("lrp-2", "", "gw-2", "gw-1"),
+		})
+		assert.Empty(t, plan.Moves)
+		assert.Equal(t, map[string]int{"gw-1": 1, "gw-2": 1}, plan.After)
+	})
+
+	t.Run("all on one chassis", func(t *testing.T) {
+		plan := PlanGatewayRebalance([]*models.Gateway{
+			distributedGateway("lrp-1", "", "gw-1", "gw-2", "gw-3"),
+			distributedGateway("lrp-2", "", "gw-1", "gw-2", "gw-3"),
+			distributedGateway("lrp-3", "", "gw-1", "gw-3", "gw-2"),
+		})
+
+		assert.Equal(t, map[string]int{"gw-1": 3, "gw-2": 0, "gw-3": 0}, plan.Before)
+		assert.Equal(t, map[string]int{"gw-1": 1, "gw-2": 1, "gw-3": 1}, plan.After)
+		require.Len(t, plan.Moves, 2)
+		assert.Equal(t, GatewayMove{
+			Ports: []string{"lrp-2"}, From: "gw-1", To: "gw-2",
+			Chassis: []models.GatewayChassis{{Name: "gw-2", Priority: 30}, {Name: "gw-1", Priority: 20}, {Name: "gw-3", Priority: 10}},
+		}, plan.Moves[0])
+		assert.Equal(t, "gw-3", plan.Moves[1].To)
+	})
+
+	t.Run("HA chassis group moves as one", func(t *testing.T) {
+		plan := PlanGatewayRebalance([]*models.Gateway{
+			{Type: "l3_gateway", RouterName: "gr-1", Chassis: []models.GatewayChassis{{Name: "gw-1"}}, HostingChassis: "gw-1"},
+			{Type: "l3_gateway", RouterName: "gr-2", Chassis: []models.GatewayChassis{{Name: "gw-1"}}, HostingChassis: "gw-1"},
+			distributedGateway("lrp-1", "ha-a", "gw-1", "gw-2"),
+			distributedGateway("lrp-2", "ha-a", "gw-1", "gw-2"),
+		})
+
+		require.Len(t, plan.Moves, 1)
+		assert.Equal(t, []string{"lrp-1", "lrp-2"}, plan.Moves[0].Ports)
+		assert.Equal(t, "ha-a", plan.Moves[0].HAChassisGroup)
+		assert.Equal(t, map[string]int{"gw-1": 2, "gw-2": 2}, plan.After)
+	})
+
+	t.Run("pinned gateways count", func(t *testing.T) {
+		plan := PlanGatewayRebalance([]*models.Gateway{
+			{Type: "l3_gateway", RouterName: "gr-1", Chassis: []models.GatewayChassis{{Name: "gw-1"}}, HostingChassis: "gw-1"},
+			distributedGateway("lrp-1", "", "gw-1", "gw-2"),
+		})
+
+		require.Len(t, plan.Moves, 1)
+		assert.Equal(t, "gw-2", plan.Moves[0].To)
+	})
+}
+
+func TestReprioritize_SharedTopPriority(t *testing.T) {
+	chassis := reprioritize([]models.GatewayChassis{{Name: "gw-1", Priority: 10}, {Name: "gw-2", Priority: 10}}, "gw-2")
+	assert.Equal(t, []models.GatewayChassis{{Name: "gw-2", Priority: 11}, {Name: "gw-1", Priority: 10}}, chassis)
+}
+
+func TestGatewayMonitor_Rebalance(t *testing.T) {
+	gateways := []*models.Gateway{
+		distributedGateway("lrp-1", "", "gw-1", "gw-2"),
+		distributedGateway("lrp-2", "", "gw-1", "gw-2"),
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockOVN := new(MockOVNService)
+		mockOVN.On("ListGateways", mock.Anything).Return(gateways, nil)
+
+		plan, err := NewGatewayMonitor(mockOVN, nil).Rebalance(context.Background(), true)
+		require.NoError(t, err)
+		assert.Len(t, plan.Moves, 1)
+		assert.False(t, plan.Applied)
+		mockOVN.AssertNotCalled(t, "SetGatewayPriorities", mock.Anything, mock.Anything)
+	})
+
+	t.Run("applied", func(t *testing.T) {
+		mockOVN := new(MockOVNService)
+		mockOVN.On("ListGateways", mock.Anything).Return(gateways, nil)
+		mockOVN.On("SetGatewayPriorities", mock.Anything, map[string][]models.GatewayChassis{
+			"lrp-2-uuid": {{Name: "gw-2", Priority: 30}, {Name: "gw-1", Priority: 20}},
+		}).Return(nil)
+
+		plan, err := NewGatewayMonitor(mockOVN, nil).Rebalance(context.Background(), false)
+		require.NoError(t, err)
+		assert.True(t, plan.Applied)
+		mockOVN.AssertExpectations(t)
+	})
+}
+
+func TestGatewayMonitor_SetBinding(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding *models.GatewayBinding
+		wantErr string
+	}{
+		{name: "no chassis", binding: &models.GatewayBinding{}, wantErr: "at least one chassis"},
+		{name: "duplicate chassis", binding: &models.GatewayBinding{Chassis: []models.GatewayChassis{{Name: "gw-1"}, {Name: "gw-1"}}}, wantErr: "listed twice"},
+		{name: "priority too high", binding: &models.GatewayBinding{Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 40000}}}, wantErr: "between 0 and 32767"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOVN := new(MockOVNService)
+			_, err := NewGatewayMonitor(mockOVN, nil).SetBinding(context.Background(), "lrp-1", tt.binding)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid gateway binding")
+			assert.Contains(t, err.Error(), tt.wantErr)
+			mockOVN.AssertNotCalled(t, "SetGatewayBinding", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
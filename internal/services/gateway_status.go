@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Gateway health
+const (
+	GatewayHealthy  = "healthy"
+	GatewayDegraded = "degraded"
+	GatewayDown     = "down"
+	GatewayUnknown  = "unknown"
+)
+
+// bgpEstablished is the state of a BGP session exchanging routes
+const bgpEstablished = "Established"
+
+// BGPSession is the state of a BGP peering of a gateway chassis
+type BGPSession struct {
+	Peer             string `json:"peer"`
+	RemoteAS         int64  `json:"remote_as,omitempty"`
+	State            string `json:"state"` // Established, Idle, Active, Connect, ...
+	Uptime           string `json:"uptime,omitempty"`
+	PrefixesReceived int    `json:"prefixes_received"`
+	PrefixesSent     int    `json:"prefixes_sent"`
+}
+
+// BGPSpeaker is the BGP state of a gateway chassis
+type BGPSpeaker struct {
+	Chassis            string       `json:"chassis"`
+	LocalAS            int64        `json:"local_as,omitempty"`
+	Sessions           []BGPSession `json:"sessions"`
+	AdvertisedPrefixes []string     `json:"advertised_prefixes"`
+}
+
+// BGPCollector reads the BGP state of the gateway chassis, e.g. from the FRR
+// daemons peering them with the physical network
+type BGPCollector interface {
+	CollectBGP(ctx context.Context) ([]BGPSpeaker, error)
+}
+
+// CommandBGPCollector runs a command printing BGP state as JSON of the form
+// {"speakers": [{"chassis": "gw-1", "sessions": [...], "advertised_prefixes": [...]}]}
+type CommandBGPCollector struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewCommandBGPCollector creates a collector running command, which is
+// killed after timeout
+func NewCommandBGPCollector(command []string, timeout time.Duration) *CommandBGPCollector {
+	return &CommandBGPCollector{command: command, timeout: timeout}
+}
+
+// CollectBGP runs the command and parses its output
+func (c *CommandBGPCollector) CollectBGP(ctx context.Context) ([]BGPSpeaker, error) {
+	if len(c.command) == 0 {
+		return nil, errors.New("no BGP collector command")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, c.command[0], c.command[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect BGP state: %w", err)
+	}
+
+	var doc struct {
+		Speakers []BGPSpeaker `json:"speakers"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("invalid BGP collector output: %w", err)
+	}
+	return doc.Speakers, nil
+}
+
+// GatewayStatus is the health of a gateway, with the BGP state of the
+// chassis it's active on when it's collected
+type GatewayStatus struct {
+	*models.Gateway
+	Health             string       `json:"health"`
+	Problems           []string     `json:"problems,omitempty"`
+	BGPSessions        []BGPSession `json:"bgp_sessions,omitempty"`
+	AdvertisedPrefixes []string     `json:"advertised_prefixes,omitempty"`
+}
+
+// GatewayReport is the status of every gateway
+type GatewayReport struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// BGPCollected tells whether BGP state was read; BGPError says why not
+	// when the collector failed
+	BGPCollected bool            `json:"bgp_collected"`
+	BGPError     string          `json:"bgp_error,omitempty"`
+	Summary      map[string]int  `json:"summary"` // Gateways per health
+	Gateways     []GatewayStatus `json:"gateways"`
+}
+
+// GatewayMonitor reports the health of the gateways connecting logical
+// routers to the external network, from their chassis bindings in OVN and,
+// with a collector, the BGP sessions of the chassis they're active on
+type GatewayMonitor struct {
+	ovn       OVNServiceInterface
+	collector BGPCollector
+	now       func() time.Time
+}
+
+// NewGatewayMonitor creates a monitor. collector may be nil, in which case
+// gateways are reported from OVN alone.
+func NewGatewayMonitor(ovn OVNServiceInterface, collector BGPCollector) *GatewayMonitor {
+	return &GatewayMonitor{ovn: ovn, collector: collector, now: time.Now}
+}
+
+// Status reports every gateway. A failing collector doesn't fail the
+// report; its error is included instead.
+func (m *GatewayMonitor) Status(ctx context.Context) (*GatewayReport, error) {
+	gateways, err := m.ovn.ListGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GatewayReport{
+		CheckedAt: m.now().UTC(),
+		Summary:   map[string]int{GatewayHealthy: 0, GatewayDegraded: 0, GatewayDown: 0, GatewayUnknown: 0},
+		Gateways:  make([]GatewayStatus, 0, len(gateways)),
+	}
+
+	var speakers map[string]*BGPSpeaker
+	if m.collector != nil {
+		collected, err := m.collector.CollectBGP(ctx)
+		if err != nil {
+			report.BGPError = err.Error()
+		} else {
+			report.BGPCollected = true
+			speakers = make(map[string]*BGPSpeaker, len(collected))
+			for i := range collected {
+				speakers[collected[i].Chassis] = &collected[i]
+			}
+		}
+	}
+
+	for _, gateway := range gateways {
+		status := evaluateGateway(gateway, speakers, report.BGPCollected)
+		report.Summary[status.Health]++
+		report.Gateways = append(report.Gateways, status)
+	}
+	return report, nil
+}
+
+// evaluateGateway decides the health of a gateway. It is down without a
+// chassis to be active on, or when none of the BGP sessions of that chassis
+// is established, and degraded when some are not or it's active on a
+// chassis it isn't bound to.
+func evaluateGateway(gateway *models.Gateway, speakers map[string]*BGPSpeaker, bgpCollected bool) GatewayStatus {
+	status := GatewayStatus{Gateway: gateway, Health: GatewayHealthy}
+	worsen := func(health, problem string) {
+		rank := map[string]int{GatewayHealthy: 0, GatewayUnknown: 1, GatewayDegraded: 2, GatewayDown: 3}
+		if rank[health] > rank[status.Health] {
+			status.Health = health
+		}
+		status.Problems = append(status.Problems, problem)
+	}
+
+	if len(gateway.Chassis) == 0 {
+		worsen(GatewayDown, "no gateway chassis bound")
+		return status
+	}
+	if gateway.HostingChassis == "" {
+		worsen(GatewayDown, "not active on any chassis")
+		return status
+	}
+	bound := false
+	for _, chassis := range gateway.Chassis {
+		bound = bound || chassis.Name == gateway.HostingChassis
+	}
+	if !bound {
+		worsen(GatewayDegraded, fmt.Sprintf("active on %s, which it isn't bound to", gateway.HostingChassis))
+	}
+
+	if !bgpCollected {
+		return status
+	}
+	speaker, ok := speakers[gateway.HostingChassis]
+	if !ok {
+		worsen(GatewayUnknown, fmt.Sprintf("no BGP state collected from %s", gateway.HostingChassis))
+		return status
+	}
+	status.BGPSessions = speaker.Sessions
+	status.AdvertisedPrefixes = speaker.AdvertisedPrefixes
+
+	var down []string
+	for _, session := range speaker.Sessions {
+		if !strings.EqualFold(session.State, bgpEstablished) {
+			down = append(down, fmt.Sprintf("%s (%s)", session.Peer, session.State))
+		}
+	}
+	switch {
+	case len(speaker.Sessions) == 0:
+		worsen(GatewayDown, fmt.Sprintf("%s has no BGP sessions", gateway.HostingChassis))
+	case len(down) == len(speaker.Sessions):
+		worsen(GatewayDown, fmt.Sprintf("no BGP session established on %s: %s", gateway.HostingChassis, strings.Join(down, ", ")))
+	case len(down) > 0:
+		worsen(GatewayDegraded, fmt.Sprintf("BGP sessions not established on %s: %s", gateway.HostingChassis, strings.Join(down, ", ")))
+	}
+	return status
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+type fakeBGPCollector struct {
+	speakers []BGPSpeaker
+	err      error
+}
+
+func (f *fakeBGPCollector) CollectBGP(ctx context.Context) ([]BGPSpeaker, error) {
+	return f.speakers, f.err
+}
+
+func newGatewayMock() *MockOVNService {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("ListGateways", mock.Anything).Return([]*models.Gateway{
+		{Type: "distributed", RouterName: "edge-1", PortName: "lrp-edge-1",
+			Chassis:        []models.GatewayChassis{{Name: "gw-1", Priority: 20}, {Name: "gw-2", Priority: 10}},
+			HostingChassis: "gw-1"},
+		{Type: "distributed", RouterName: "edge-2", PortName: "lrp-edge-2",
+			Chassis:        []models.GatewayChassis{{Name: "gw-2", Priority: 20}},
+			HostingChassis: "gw-2"},
+		{Type: "distributed", RouterName: "edge-3", PortName: "lrp-edge-3",
+			Chassis: []models.GatewayChassis{{Name: "gw-3", Priority: 20}}},
+		{Type: "l3_gateway", RouterName: "gr-1",
+			Chassis:        []models.GatewayChassis{{Name: "gw-4"}},
+			HostingChassis: "gw-4"},
+	}, nil)
+	return mockOVN
+}
+
+func TestGatewayMonitor_Status(t *testing.T) {
+	t.Run("without collector", func(t *testing.T) {
+		report, err := NewGatewayMonitor(newGatewayMock(), nil).Status(context.Background())
+		require.NoError(t, err)
+
+		assert.False(t, report.BGPCollected)
+		require.Len(t, report.Gateways, 4)
+		assert.Equal(t, GatewayHealthy, report.Gateways[0].Health)
+		assert.Equal(t, GatewayDown, report.Gateways[2].Health)
+		assert.Equal(t, []string{"not active on any chassis"}, report.Gateways[2].Problems)
+		assert.Equal(t, map[string]int{GatewayHealthy: 3, GatewayDegraded: 0, GatewayDown: 1, GatewayUnknown: 0}, report.Summary)
+	})
+
+	t.Run("with BGP state", func(t *testing.T) {
+		collector := &fakeBGPCollector{speakers: []BGPSpeaker{
+			{Chassis: "gw-1", Sessions: []BGPSession{
+				{Peer: "172.16.0.254", State: "Established"},
+				{Peer: "172.16.1.254", State: "Active"},
+			}, AdvertisedPrefixes: []string{"10.0.0.0/24"}},
+			{Chassis: "gw-2", Sessions: []BGPSession{{Peer: "172.16.0.254", State: "Idle"}}},
+		}}
+		report, err := NewGatewayMonitor(newGatewayMock(), collector).Status(context.Background())
+		require.NoError(t, err)
+
+		assert.True(t, report.BGPCollected)
+		assert.Equal(t, GatewayDegraded, report.Gateways[0].Health)
+		assert.Equal(t, []string{"BGP sessions not established on gw-1: 172.16.1.254 (Active)"}, report.Gateways[0].Problems)
+		assert.Equal(t, []string{"10.0.0.0/24"}, report.Gateways[0].AdvertisedPrefixes)
+		assert.Equal(t, GatewayDown, report.Gateways[1].Health)
+		assert.Equal(t, GatewayDown, report.Gateways[2].Health)
+		assert.Equal(t, GatewayUnknown, report.Gateways[3].Health)
+	})
+
+	t.Run("failing collector", func(t *testing.T) {
+		collector := &fakeBGPCollector{err: errors.New("vtysh: connection refused")}
+		report, err := NewGatewayMonitor(newGatewayMock(), collector).Status(context.Background())
+		require.NoError(t, err)
+
+		assert.False(t, report.BGPCollected)
+		assert.Equal(t, "vtysh: connection refused", report.BGPError)
+		assert.Equal(t, GatewayHealthy, report.Gateways[0].Health)
+	})
+}
+
+func TestEvaluateGateway_UnboundHostingChassis(t *testing.T) {
+	status := evaluateGateway(&models.Gateway{
+		Chassis:        []models.GatewayChassis{{Name: "gw-1", Priority: 20}},
+		HostingChassis: "gw-9",
+	}, nil, false)
+
+	assert.Equal(t, GatewayDegraded, status.Health)
+	assert.Equal(t, []string{"active on gw-9, which it isn't bound to"}, status.Problems)
+}
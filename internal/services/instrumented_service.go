@@ -0,0 +1,555 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// InstrumentedOVNService records the count and latency of every operation
+// on the wrapped service in the OVN operation metrics
+type InstrumentedOVNService struct {
+	service OVNServiceInterface
+}
+
+// NewInstrumentedOVNService creates an OVN service recording metrics
+func NewInstrumentedOVNService(service OVNServiceInterface) *InstrumentedOVNService {
+	return &InstrumentedOVNService{
+		service: service,
+	}
+}
+
+var _ OVNServiceInterface = (*InstrumentedOVNService)(nil)
+
+// observeOVNOperation starts timing an operation; the returned func records
+// it with its outcome
+func observeOVNOperation(operation, resource string) func(error) {
+	start := time.Now()
+	return func(err error) {
+		status := "success"
+		if err != nil {
+			status = "error"
+		}
+		metrics.RecordOVNOperation(operation, resource, status, time.Since(start).Seconds())
+	}
+}
+
+func (s *InstrumentedOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	done := observeOVNOperation("list", "logical_switch")
+	result, err := s.service.ListLogicalSwitches(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	done := observeOVNOperation("list_page", "logical_switch")
+	result, total, err := s.service.ListLogicalSwitchesPage(ctx, opts)
+	done(err)
+	return result, total, err
+}
+
+func (s *InstrumentedOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
+	done := observeOVNOperation("get", "logical_switch")
+	result, err := s.service.GetLogicalSwitch(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	done := observeOVNOperation("create", "logical_switch")
+	result, err := s.service.CreateLogicalSwitch(ctx, ls)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateLogicalSwitch(ctx context.Context, id string, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	done := observeOVNOperation("update", "logical_switch")
+	result, err := s.service.UpdateLogicalSwitch(ctx, id, ls)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteLogicalSwitch(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "logical_switch")
+	err := s.service.DeleteLogicalSwitch(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
+	done := observeOVNOperation("list", "logical_router")
+	result, err := s.service.ListLogicalRouters(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	done := observeOVNOperation("list_page", "logical_router")
+	result, total, err := s.service.ListLogicalRoutersPage(ctx, opts)
+	done(err)
+	return result, total, err
+}
+
+func (s *InstrumentedOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
+	done := observeOVNOperation("get", "logical_router")
+	result, err := s.service.GetLogicalRouter(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	done := observeOVNOperation("create", "logical_router")
+	result, err := s.service.CreateLogicalRouter(ctx, lr)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateLogicalRouter(ctx context.Context, id string, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	done := observeOVNOperation("update", "logical_router")
+	result, err := s.service.UpdateLogicalRouter(ctx, id, lr)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteLogicalRouter(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "logical_router")
+	err := s.service.DeleteLogicalRouter(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
+	done := observeOVNOperation("list", "logical_switch_port")
+	result, err := s.service.ListPorts(ctx, switchID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	done := observeOVNOperation("list_page", "logical_switch_port")
+	result, total, err := s.service.ListPortsPage(ctx, switchID, opts)
+	done(err)
+	return result, total, err
+}
+
+func (s *InstrumentedOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
+	done := observeOVNOperation("get", "logical_switch_port")
+	result, err := s.service.GetPort(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	done := observeOVNOperation("create", "logical_switch_port")
+	result, err := s.service.CreatePort(ctx, switchID, port)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdatePort(ctx context.Context, id string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	done := observeOVNOperation("update", "logical_switch_port")
+	result, err := s.service.UpdatePort(ctx, id, port)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeletePort(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "logical_switch_port")
+	err := s.service.DeletePort(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
+	done := observeOVNOperation("list", "acl")
+	result, err := s.service.ListACLs(ctx, switchID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	done := observeOVNOperation("list_page", "acl")
+	result, total, err := s.service.ListACLsPage(ctx, switchID, opts)
+	done(err)
+	return result, total, err
+}
+
+func (s *InstrumentedOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
+	done := observeOVNOperation("get", "acl")
+	result, err := s.service.GetACL(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error) {
+	done := observeOVNOperation("create", "acl")
+	result, err := s.service.CreateACL(ctx, switchID, acl)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	done := observeOVNOperation("create_batch", "acl")
+	result, err := s.service.CreateACLs(ctx, switchID, acls)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+	done := observeOVNOperation("update", "acl")
+	result, err := s.service.UpdateACL(ctx, id, acl)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteACL(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "acl")
+	err := s.service.DeleteACL(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	done := observeOVNOperation("list", "load_balancer")
+	result, err := s.service.ListLoadBalancers(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	done := observeOVNOperation("list_page", "load_balancer")
+	result, total, err := s.service.ListLoadBalancersPage(ctx, opts)
+	done(err)
+	return result, total, err
+}
+
+func (s *InstrumentedOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	done := observeOVNOperation("get", "load_balancer")
+	result, err := s.service.GetLoadBalancer(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	done := observeOVNOperation("create", "load_balancer")
+	result, err := s.service.CreateLoadBalancer(ctx, lb)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	done := observeOVNOperation("update", "load_balancer")
+	result, err := s.service.UpdateLoadBalancer(ctx, id, lb)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "load_balancer")
+	err := s.service.DeleteLoadBalancer(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	done := observeOVNOperation("list", "port_group")
+	result, err := s.service.ListPortGroups(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	done := observeOVNOperation("list", "address_set")
+	result, err := s.service.ListAddressSets(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	done := observeOVNOperation("list_port_group", "acl")
+	result, err := s.service.ListPortGroupACLs(ctx, portGroupID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	done := observeOVNOperation("replace", "owned_objects")
+	result, err := s.service.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	done := observeOVNOperation("list", "dns")
+	result, err := s.service.ListDNS(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	done := observeOVNOperation("get", "dns")
+	result, err := s.service.GetDNS(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	done := observeOVNOperation("create", "dns")
+	result, err := s.service.CreateDNS(ctx, dns)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	done := observeOVNOperation("update", "dns")
+	result, err := s.service.UpdateDNS(ctx, id, dns)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteDNS(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "dns")
+	err := s.service.DeleteDNS(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	done := observeOVNOperation("list", "mirror")
+	result, err := s.service.ListMirrors(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	done := observeOVNOperation("get", "mirror")
+	result, err := s.service.GetMirror(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	done := observeOVNOperation("create", "mirror")
+	result, err := s.service.CreateMirror(ctx, mirror)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	done := observeOVNOperation("update", "mirror")
+	result, err := s.service.UpdateMirror(ctx, id, mirror)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteMirror(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "mirror")
+	err := s.service.DeleteMirror(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	done := observeOVNOperation("list", "sample_collector")
+	result, err := s.service.ListSampleCollectors(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	done := observeOVNOperation("get", "sample_collector")
+	result, err := s.service.GetSampleCollector(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	done := observeOVNOperation("create", "sample_collector")
+	result, err := s.service.CreateSampleCollector(ctx, collector)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	done := observeOVNOperation("update", "sample_collector")
+	result, err := s.service.UpdateSampleCollector(ctx, id, collector)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "sample_collector")
+	err := s.service.DeleteSampleCollector(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	done := observeOVNOperation("list", "sampling_app")
+	result, err := s.service.ListSamplingApps(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	done := observeOVNOperation("update", "sampling_app")
+	result, err := s.service.SetSamplingApp(ctx, appType, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	done := observeOVNOperation("get", "acl_sampling")
+	result, err := s.service.GetACLSampling(ctx, aclID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	done := observeOVNOperation("update", "acl_sampling")
+	result, err := s.service.SetACLSampling(ctx, aclID, sampling)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	done := observeOVNOperation("update", "acl_sampling")
+	result, err := s.service.SetSwitchSampling(ctx, switchID, sampling)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	done := observeOVNOperation("list", "router_policy")
+	result, err := s.service.ListRouterPolicies(ctx, routerID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	done := observeOVNOperation("get", "router_policy")
+	result, err := s.service.GetRouterPolicy(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	done := observeOVNOperation("create", "router_policy")
+	result, err := s.service.CreateRouterPolicy(ctx, routerID, policy)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	done := observeOVNOperation("update", "router_policy")
+	result, err := s.service.UpdateRouterPolicy(ctx, id, policy)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "router_policy")
+	err := s.service.DeleteRouterPolicy(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	done := observeOVNOperation("list", "static_route")
+	result, err := s.service.ListStaticRoutes(ctx, routerID)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	done := observeOVNOperation("get", "static_route")
+	result, err := s.service.GetStaticRoute(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	done := observeOVNOperation("create", "static_route")
+	result, err := s.service.CreateStaticRoutes(ctx, routerID, routes)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	done := observeOVNOperation("update", "static_route")
+	result, err := s.service.UpdateStaticRoute(ctx, id, route)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "static_route")
+	err := s.service.DeleteStaticRoute(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	done := observeOVNOperation("create", "router_port")
+	result, err := s.service.CreateRouterPort(ctx, routerID, port)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	done := observeOVNOperation("delete", "router_port")
+	err := s.service.DeleteRouterPort(ctx, id)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	done := observeOVNOperation("list", "gateway")
+	result, err := s.service.ListGateways(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	done := observeOVNOperation("update", "gateway")
+	result, err := s.service.SetGatewayBinding(ctx, portID, binding)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	done := observeOVNOperation("rebalance", "gateway")
+	err := s.service.SetGatewayPriorities(ctx, priorities)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	done := observeOVNOperation("set_labels", resource)
+	result, err := s.service.SetLabels(ctx, resource, id, set, remove)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
+	done := observeOVNOperation("execute", "transaction")
+	err := s.service.ExecuteTransaction(ctx, ops)
+	done(err)
+
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	metrics.RecordTransaction(status, len(ops))
+	return err
+}
+
+func (s *InstrumentedOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+	done := observeOVNOperation("get", "topology")
+	result, err := s.service.GetTopology(ctx)
+	done(err)
+	return result, err
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	var m dto.Metric
+	require.NoError(t, counter.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestInstrumentedOVNService_RecordsOperations(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewInstrumentedOVNService(mockOVN)
+
+	succeeded := metrics.OVNOperationsTotal.WithLabelValues("get", "logical_switch", "success")
+	failed := metrics.OVNOperationsTotal.WithLabelValues("get", "logical_switch", "error")
+	beforeSuccess, beforeError := counterValue(t, succeeded), counterValue(t, failed)
+
+	mockOVN.On("GetLogicalSwitch", ctx, "ls1").Return(&models.LogicalSwitch{UUID: "ls1"}, nil).Once()
+	mockOVN.On("GetLogicalSwitch", ctx, "missing").Return((*models.LogicalSwitch)(nil), errors.New("not found")).Once()
+
+	ls, err := svc.GetLogicalSwitch(ctx, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, "ls1", ls.UUID)
+	_, err = svc.GetLogicalSwitch(ctx, "missing")
+	assert.Error(t, err)
+
+	assert.Equal(t, beforeSuccess+1, counterValue(t, succeeded))
+	assert.Equal(t, beforeError+1, counterValue(t, failed))
+	mockOVN.AssertExpectations(t)
+}
+
+func TestInstrumentedOVNService_RecordsTransactions(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewInstrumentedOVNService(mockOVN)
+
+	failures := metrics.TransactionsTotal.WithLabelValues("failure")
+	before := counterValue(t, failures)
+
+	mockOVN.On("ExecuteTransaction", ctx, mock.Anything).Return(errors.New("constraint violation"))
+	err := svc.ExecuteTransaction(ctx, []TransactionOp{{Operation: "create", ResourceType: "logical_switch"}})
+	assert.Error(t, err)
+
+	assert.Equal(t, before+1, counterValue(t, failures))
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
 // OVNServiceInterface defines the interface for OVN operations
 type OVNServiceInterface interface {
 	// Logical Switch operations
 	ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error)
+	ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error)
 	GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error)
 	CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error)
 	UpdateLogicalSwitch(ctx context.Context, id string, ls *models.LogicalSwitch) (*models.LogicalSwitch, error)
@@ -17,6 +19,7 @@ type OVNServiceInterface interface {
 
 	// Logical Router operations
 	ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error)
+	ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error)
 	GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error)
 	CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error)
 	UpdateLogicalRouter(ctx context.Context, id string, lr *models.LogicalRouter) (*models.LogicalRouter, error)
@@ -24,6 +27,7 @@ type OVNServiceInterface interface {
 
 	// Port operations
 	ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error)
+	ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error)
 	GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error)
 	CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error)
 	UpdatePort(ctx context.Context, id string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error)
@@ -31,10 +35,78 @@ type OVNServiceInterface interface {
 
 	// ACL operations
 	ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error)
+	ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error)
 	GetACL(ctx context.Context, id string) (*models.ACL, error)
 	CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error)
+	CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error)
 	UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error)
 	DeleteACL(ctx context.Context, id string) error
+
+	// Load Balancer operations
+	ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error)
+	ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error)
+	GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error)
+	CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error)
+	UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error)
+	DeleteLoadBalancer(ctx context.Context, id string) error
+
+	// Port group and address set operations
+	ListPortGroups(ctx context.Context) ([]*models.PortGroup, error)
+	ListAddressSets(ctx context.Context) ([]*models.AddressSet, error)
+	ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error)
+	ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error)
+
+	// DNS operations
+	ListDNS(ctx context.Context) ([]*models.DNS, error)
+	GetDNS(ctx context.Context, id string) (*models.DNS, error)
+	CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error)
+	UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error)
+	DeleteDNS(ctx context.Context, id string) error
+
+	// Mirror operations
+	ListMirrors(ctx context.Context) ([]*models.Mirror, error)
+	GetMirror(ctx context.Context, id string) (*models.Mirror, error)
+	CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error)
+	UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error)
+	DeleteMirror(ctx context.Context, id string) error
+
+	// Flow sampling operations
+	ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error)
+	GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error)
+	CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error)
+	UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error)
+	DeleteSampleCollector(ctx context.Context, id string) error
+	ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error)
+	SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error)
+	GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error)
+	SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error)
+	SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error)
+
+	// Router policy operations
+	ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error)
+	GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error)
+	CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error)
+	UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error)
+	DeleteRouterPolicy(ctx context.Context, id string) error
+
+	// Static route operations
+	ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error)
+	GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error)
+	CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error)
+	UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error)
+	DeleteStaticRoute(ctx context.Context, id string) error
+
+	// Router port operations
+	CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error)
+	DeleteRouterPort(ctx context.Context, id string) error
+
+	// Gateway operations
+	ListGateways(ctx context.Context) ([]*models.Gateway, error)
+	SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error)
+	SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error
+
+	// Label operations
+	SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error)
 	
 	// Transaction operations
 	ExecuteTransaction(ctx context.Context, ops []TransactionOp) error
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// AutoAddress is the MAC of a port address that's generated from a MAC pool,
+// e.g. "auto" or "auto 10.0.0.5"
+const AutoAddress = "auto"
+
+// MACPoolExternalID is the switch external ID naming the MAC pool its ports
+// are assigned from; switches without it use the first pool
+const MACPoolExternalID = "mac_pool"
+
+// macReservationTTL is how long a generated MAC is held back for the port it
+// was generated for, covering the time until the port is created and read
+// back in the topology
+const macReservationTTL = 5 * time.Minute
+
+var (
+	// ErrNoMACPool is returned when "auto" addresses can't be assigned
+	// because no pool is configured, or the switch names an unknown pool
+	ErrNoMACPool = errors.New("no MAC pool")
+
+	// ErrMACPoolExhausted is returned when every address of a pool is in use
+	ErrMACPoolExhausted = errors.New("MAC pool exhausted")
+)
+
+// macPool is a range of MACs, handed out in order from next
+type macPool struct {
+	name        string
+	first, last uint64
+	next        uint64
+}
+
+// MACAllocator generates MACs for port addresses from configured pools. A
+// generated MAC isn't assigned to any switch port, router port or NAT rule,
+// nor handed out for another port in the meantime.
+type MACAllocator struct {
+	ovn    OVNServiceInterface
+	pools  []*macPool
+	byName map[string]*macPool
+
+	mu       sync.Mutex
+	reserved map[uint64]time.Time // Generated MACs, until they expire
+	now      func() time.Time
+}
+
+// NewMACAllocator creates an allocator for the pools, which may be none
+func NewMACAllocator(ovn OVNServiceInterface, pools []config.MACPoolConfig) (*MACAllocator, error) {
+	a := &MACAllocator{
+		ovn:      ovn,
+		byName:   make(map[string]*macPool, len(pools)),
+		reserved: make(map[uint64]time.Time),
+		now:      time.Now,
+	}
+	for _, cfg := range pools {
+		first, last, err := cfg.Range()
+		if err != nil {
+			return nil, fmt.Errorf("MAC pool %s: %w", cfg.Name, err)
+		}
+		pool := &macPool{name: cfg.Name, first: first, last: last, next: first}
+		a.pools = append(a.pools, pool)
+		a.byName[pool.name] = pool
+	}
+	return a, nil
+}
+
+// AssignMACs replaces "auto" MACs in a port's addresses with MACs from the
+// pool of the switch the port is created on. Ports without "auto" addresses
+// are left alone.
+func (a *MACAllocator) AssignMACs(ctx context.Context, switchID string, port *models.LogicalSwitchPort) error {
+	var auto []int
+	for i, address := range port.Addresses {
+		if fields := strings.Fields(address); len(fields) > 0 && fields[0] == AutoAddress {
+			auto = append(auto, i)
+		}
+	}
+	if len(auto) == 0 {
+		return nil
+	}
+
+	pool, err := a.switchPool(ctx, switchID)
+	if err != nil {
+		return err
+	}
+
+	topology, err := a.ovn.GetTopology(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get topology: %w", err)
+	}
+	index := newAddressIndex(topology, "")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for mac, expires := range a.reserved {
+		if !now.Before(expires) {
+			delete(a.reserved, mac)
+		}
+	}
+
+	for _, i := range auto {
+		mac, err := a.allocate(pool, index, now)
+		if err != nil {
+			return err
+		}
+		port.Addresses[i] = mac + strings.TrimPrefix(strings.TrimSpace(port.Addresses[i]), AutoAddress)
+	}
+	return nil
+}
+
+// switchPool returns the pool a switch selects
+func (a *MACAllocator) switchPool(ctx context.Context, switchID string) (*macPool, error) {
+	if len(a.pools) == 0 {
+		return nil, fmt.Errorf("%w: no MAC pools are configured for \"auto\" addresses", ErrNoMACPool)
+	}
+
+	sw, err := a.ovn.GetLogicalSwitch(ctx, switchID)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := sw.ExternalIDs[MACPoolExternalID]
+	if !ok {
+		return a.pools[0], nil
+	}
+	pool, ok := a.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: switch %s selects unknown MAC pool %q", ErrNoMACPool, switchID, name)
+	}
+	return pool, nil
+}
+
+// allocate reserves the next MAC of the pool that's neither in use nor
+// reserved. It's called with mu held.
+func (a *MACAllocator) allocate(pool *macPool, index *addressIndex, now time.Time) (string, error) {
+	size := pool.last - pool.first + 1
+	for n := uint64(0); n < size; n++ {
+		candidate := pool.next
+		if pool.next == pool.last {
+			pool.next = pool.first
+		} else {
+			pool.next++
+		}
+
+		if _, ok := a.reserved[candidate]; ok {
+			continue
+		}
+		mac := formatMAC(candidate)
+		if len(index.owners[addressKey{addressType: AddressTypeMAC, address: mac}]) > 0 {
+			continue
+		}
+		a.reserved[candidate] = now.Add(macReservationTTL)
+		return mac, nil
+	}
+	return "", fmt.Errorf("%w: every address of MAC pool %s is in use", ErrMACPoolExhausted, pool.name)
+}
+
+// formatMAC formats the low 48 bits of value as a MAC address
+func formatMAC(value uint64) string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x",
+		byte(value>>40), byte(value>>32), byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestMACAllocator_AssignMACs(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "sw-1").Return(&models.LogicalSwitch{UUID: "sw-1"}, nil)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "sw-dmz").Return(&models.LogicalSwitch{
+		UUID: "sw-dmz", ExternalIDs: map[string]string{MACPoolExternalID: "dmz"},
+	}, nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(&Topology{
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "p-1", Addresses: []string{"0a:58:a9:00:00:01 10.0.0.1"}},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			{UUID: "lrp-1", MAC: "0A:58:A9:00:00:02"},
+		},
+	}, nil)
+
+	allocator, err := NewMACAllocator(mockOVN, []config.MACPoolConfig{
+		{Name: "default", Prefix: "0a:58:a9", Start: "00:00:01", End: "00:00:04"},
+		{Name: "dmz", Prefix: "0a:58:aa", Start: "00:00:01", End: "00:00:01"},
+	})
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	allocator.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	port := &models.LogicalSwitchPort{Addresses: []string{"auto 10.0.0.5", "auto"}}
+	require.NoError(t, allocator.AssignMACs(ctx, "sw-1", port))
+	assert.Equal(t, []string{"0a:58:a9:00:00:03 10.0.0.5", "0a:58:a9:00:00:04"}, port.Addresses)
+
+	// Handed out MACs are held back until they'd show up in the topology
+	port = &models.LogicalSwitchPort{Addresses: []string{"auto"}}
+	err = allocator.AssignMACs(ctx, "sw-1", port)
+	assert.True(t, errors.Is(err, ErrMACPoolExhausted))
+
+	now = now.Add(macReservationTTL)
+	require.NoError(t, allocator.AssignMACs(ctx, "sw-1", port))
+	assert.Equal(t, []string{"0a:58:a9:00:00:03"}, port.Addresses)
+
+	// Switches select their pool
+	port = &models.LogicalSwitchPort{Addresses: []string{"auto"}}
+	require.NoError(t, allocator.AssignMACs(ctx, "sw-dmz", port))
+	assert.Equal(t, []string{"0a:58:aa:00:00:01"}, port.Addresses)
+
+	// Ports with their own MACs don't need a pool
+	port = &models.LogicalSwitchPort{Addresses: []string{"0a:00:00:00:00:01"}}
+	require.NoError(t, allocator.AssignMACs(ctx, "sw-unknown", port))
+}
+
+func TestMACAllocator_NoPool(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "sw-1").Return(&models.LogicalSwitch{
+		UUID: "sw-1", ExternalIDs: map[string]string{MACPoolExternalID: "missing"},
+	}, nil)
+
+	allocator, err := NewMACAllocator(mockOVN, nil)
+	require.NoError(t, err)
+	err = allocator.AssignMACs(context.Background(), "sw-1", &models.LogicalSwitchPort{Addresses: []string{"auto"}})
+	assert.True(t, errors.Is(err, ErrNoMACPool))
+
+	allocator, err = NewMACAllocator(mockOVN, []config.MACPoolConfig{
+		{Name: "default", Prefix: "0a:58:a9", Start: "00:00:01", End: "ff:ff:fe"},
+	})
+	require.NoError(t, err)
+	err = allocator.AssignMACs(context.Background(), "sw-1", &models.LogicalSwitchPort{Addresses: []string{"auto"}})
+	assert.True(t, errors.Is(err, ErrNoMACPool))
+
+	_, err = NewMACAllocator(mockOVN, []config.MACPoolConfig{
+		{Name: "multicast", Prefix: "01:00:5e", Start: "00:00:01", End: "00:00:02"},
+	})
+	assert.Error(t, err)
+}
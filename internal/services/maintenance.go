@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrMaintenanceNotFound is returned when clearing a maintenance mode
+	// that isn't set
+	ErrMaintenanceNotFound = errors.New("maintenance mode not set")
+
+	// ErrInvalidMaintenance is returned for maintenance modes expected to
+	// end in the past
+	ErrInvalidMaintenance = errors.New("invalid maintenance mode")
+)
+
+// MaintenanceStore keeps maintenance modes, shared by the API's replicas.
+// *db.DB implements it.
+type MaintenanceStore interface {
+	// ListMaintenanceModes lists the modes set, the cluster's first
+	ListMaintenanceModes(ctx context.Context) ([]*models.MaintenanceMode, error)
+	// SaveMaintenanceMode sets the mode of mode.TenantID
+	SaveMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error
+	// DeleteMaintenanceMode clears the mode of a tenant, reporting whether
+	// one was set
+	DeleteMaintenanceMode(ctx context.Context, tenantID string) (bool, error)
+}
+
+// MaintenanceService freezes writes to the API while the OVN cluster
+// underneath is maintained, for every tenant or for some only
+type MaintenanceService struct {
+	store  MaintenanceStore
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewMaintenanceService creates a service keeping maintenance modes in
+// store
+func NewMaintenanceService(store MaintenanceStore, logger *zap.Logger) *MaintenanceService {
+	return &MaintenanceService{
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Status lists the maintenance modes set, the cluster's first
+func (s *MaintenanceService) Status(ctx context.Context) ([]*models.MaintenanceMode, error) {
+	return s.store.ListMaintenanceModes(ctx)
+}
+
+// Set sets the maintenance mode of mode.TenantID, the cluster's when it's
+// empty
+func (s *MaintenanceService) Set(ctx context.Context, mode *models.MaintenanceMode) error {
+	mode.SetAt = s.now().UTC()
+	if mode.ETA != nil {
+		if !mode.ETA.After(mode.SetAt) {
+			return fmt.Errorf("%w: eta must be in the future", ErrInvalidMaintenance)
+		}
+		eta := mode.ETA.UTC()
+		mode.ETA = &eta
+	}
+	if err := s.store.SaveMaintenanceMode(ctx, mode); err != nil {
+		return err
+	}
+
+	s.logger.Info("Maintenance mode set",
+		zap.String("tenant_id", mode.TenantID),
+		zap.Bool("frozen", mode.Frozen),
+		zap.String("reason", mode.Reason),
+		zap.String("set_by", mode.SetBy))
+	return nil
+}
+
+// Clear clears the maintenance mode of a tenant, or of the cluster when
+// tenantID is empty
+func (s *MaintenanceService) Clear(ctx context.Context, tenantID string) error {
+	deleted, err := s.store.DeleteMaintenanceMode(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMaintenanceNotFound
+	}
+
+	s.logger.Info("Maintenance mode cleared", zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// Frozen returns the maintenance mode freezing the writes of a tenant, or
+// of requests outside a tenant's scope when tenantID is empty, nil when
+// they're allowed. The tenant's own mode takes precedence over the
+// cluster's.
+func (s *MaintenanceService) Frozen(ctx context.Context, tenantID string) (*models.MaintenanceMode, error) {
+	modes, err := s.store.ListMaintenanceModes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var effective *models.MaintenanceMode
+	for _, mode := range modes {
+		switch mode.TenantID {
+		case tenantID:
+			effective = mode
+		case "":
+			if effective == nil {
+				effective = mode
+			}
+		}
+	}
+	if effective == nil || !effective.Frozen {
+		return nil, nil
+	}
+	return effective, nil
+}
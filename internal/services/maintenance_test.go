@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryMaintenanceStore keeps maintenance modes in memory
+type memoryMaintenanceStore struct {
+	modes map[string]*models.MaintenanceMode
+}
+
+func (s *memoryMaintenanceStore) ListMaintenanceModes(ctx context.Context) ([]*models.MaintenanceMode, error) {
+	modes := []*models.MaintenanceMode{}
+	for _, mode := range s.modes {
+		modes = append(modes, mode)
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i].TenantID < modes[j].TenantID })
+	return modes, nil
+}
+
+func (s *memoryMaintenanceStore) SaveMaintenanceMode(ctx context.Context, mode *models.MaintenanceMode) error {
+	s.modes[mode.TenantID] = mode
+	return nil
+}
+
+func (s *memoryMaintenanceStore) DeleteMaintenanceMode(ctx context.Context, tenantID string) (bool, error) {
+	_, ok := s.modes[tenantID]
+	delete(s.modes, tenantID)
+	return ok, nil
+}
+
+func TestMaintenanceService_Frozen(t *testing.T) {
+	ctx := context.Background()
+	service := NewMaintenanceService(&memoryMaintenanceStore{modes: map[string]*models.MaintenanceMode{}}, zap.NewNop())
+
+	mode, err := service.Frozen(ctx, "acme")
+	require.NoError(t, err)
+	assert.Nil(t, mode, "writes are allowed without a maintenance mode")
+
+	// A cluster-wide freeze applies to every tenant, and outside tenants
+	require.NoError(t, service.Set(ctx, &models.MaintenanceMode{Frozen: true, Reason: "OVN upgrade", SetBy: "admin"}))
+	mode, err = service.Frozen(ctx, "acme")
+	require.NoError(t, err)
+	require.NotNil(t, mode)
+	assert.Equal(t, "OVN upgrade", mode.Reason)
+	mode, err = service.Frozen(ctx, "")
+	require.NoError(t, err)
+	assert.NotNil(t, mode)
+
+	// A tenant's mode overrides the cluster's
+	require.NoError(t, service.Set(ctx, &models.MaintenanceMode{TenantID: "acme", Frozen: false}))
+	mode, err = service.Frozen(ctx, "acme")
+	require.NoError(t, err)
+	assert.Nil(t, mode, "the tenant is exempted from the cluster's freeze")
+	mode, err = service.Frozen(ctx, "other")
+	require.NoError(t, err)
+	assert.NotNil(t, mode)
+
+	require.NoError(t, service.Clear(ctx, ""))
+	assert.ErrorIs(t, service.Clear(ctx, ""), ErrMaintenanceNotFound)
+	require.NoError(t, service.Set(ctx, &models.MaintenanceMode{TenantID: "other", Frozen: true, Reason: "migration"}))
+	mode, err = service.Frozen(ctx, "other")
+	require.NoError(t, err)
+	require.NotNil(t, mode)
+	assert.Equal(t, "migration", mode.Reason)
+	mode, err = service.Frozen(ctx, "acme")
+	require.NoError(t, err)
+	assert.Nil(t, mode, "a tenant's freeze leaves other tenants alone")
+
+	modes, err := service.Status(ctx)
+	require.NoError(t, err)
+	assert.Len(t, modes, 2)
+}
+
+func TestMaintenanceService_SetETA(t *testing.T) {
+	ctx := context.Background()
+	service := NewMaintenanceService(&memoryMaintenanceStore{modes: map[string]*models.MaintenanceMode{}}, zap.NewNop())
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	past := now.Add(-time.Minute)
+	err := service.Set(ctx, &models.MaintenanceMode{Frozen: true, ETA: &past})
+	assert.ErrorIs(t, err, ErrInvalidMaintenance)
+
+	eta := now.Add(time.Hour).In(time.FixedZone("CEST", 2*3600))
+	mode := &models.MaintenanceMode{Frozen: true, ETA: &eta}
+	require.NoError(t, service.Set(ctx, mode))
+	assert.Equal(t, now, mode.SetAt)
+	assert.Equal(t, time.UTC, mode.ETA.Location())
+	assert.True(t, mode.ETA.Equal(eta))
+}
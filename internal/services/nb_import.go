@@ -0,0 +1,564 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Import formats
+const (
+	// ImportFormatNBJSON is the JSON output of
+	// "ovsdb-client dump --format=json --data=json OVN_Northbound", or rows
+	// keyed by table and UUID as in OVSDB monitor updates
+	ImportFormatNBJSON = "nb-json"
+
+	// ImportFormatNBShow is the output of "ovn-nbctl show"
+	ImportFormatNBShow = "nbctl-show"
+)
+
+// ErrInvalidImport is wrapped by errors parsing an import document
+var ErrInvalidImport = errors.New("invalid import")
+
+// importIgnoredTables hold database configuration rather than resources
+var importIgnoredTables = map[string]bool{
+	"NB_Global":  true,
+	"Connection": true,
+	"SSL":        true,
+}
+
+// ImportResult reports an import. Skipped counts the rows that were not
+// imported, by OVSDB table: tables apply doesn't manage, such as router
+// ports and NAT rules, unnamed resources and rows no switch refers to.
+type ImportResult struct {
+	Format  string         `json:"format"`
+	State   *DesiredState  `json:"state"`
+	Skipped map[string]int `json:"skipped,omitempty"`
+	*ApplyResult
+}
+
+// ImportService imports the logical network of another OVN deployment as
+// ovncp-managed resources. Imports are applied like desired state documents
+// without pruning: missing resources are created, existing ones with the
+// same names are updated and nothing is deleted.
+type ImportService struct {
+	applyService *ApplyService
+	logger       *zap.Logger
+}
+
+// NewImportService creates a new import service
+func NewImportService(applyService *ApplyService, logger *zap.Logger) *ImportService {
+	return &ImportService{
+		applyService: applyService,
+		logger:       logger,
+	}
+}
+
+// DetectImportFormat guesses the format of an import document: JSON
+// documents are NB database exports, anything else ovn-nbctl show output
+func DetectImportFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return ImportFormatNBJSON
+	}
+	return ImportFormatNBShow
+}
+
+// Import converts data in format into a desired state and applies it, or
+// with dryRun only plans it. Resources are created in the tenant of ctx.
+func (s *ImportService) Import(ctx context.Context, format string, data []byte, dryRun bool) (*ImportResult, error) {
+	var state *DesiredState
+	var skipped map[string]int
+	var err error
+	switch format {
+	case ImportFormatNBJSON:
+		state, skipped, err = ParseNBJSON(data)
+	case ImportFormatNBShow:
+		state, skipped, err = ParseNBShow(data)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidImport, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Switches) == 0 && len(state.Routers) == 0 {
+		return nil, fmt.Errorf("%w: no switches or routers found", ErrInvalidImport)
+	}
+
+	result := &ImportResult{
+		Format:  format,
+		State:   state,
+		Skipped: skipped,
+	}
+	result.ApplyResult, err = s.applyService.Apply(ctx, state, ApplyOptions{DryRun: dryRun})
+	if err != nil {
+		if result.ApplyResult == nil {
+			return nil, err
+		}
+		return result, err
+	}
+
+	if !dryRun {
+		s.logger.Info("Imported logical network",
+			zap.String("format", format),
+			zap.Int("switches", len(state.Switches)),
+			zap.Int("routers", len(state.Routers)),
+			zap.Int("applied", result.Applied))
+	}
+	return result, nil
+}
+
+// nbRow is a row of an NB database export, with OVSDB JSON values
+type nbRow map[string]interface{}
+
+// ParseNBJSON converts an NB database JSON export into a desired state.
+// Cells must be OVSDB JSON values ("--data=json").
+func ParseNBJSON(data []byte) (*DesiredState, map[string]int, error) {
+	tables, err := decodeNBTables(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	skipped := make(map[string]int)
+	for table, rows := range tables {
+		switch table {
+		case "Logical_Switch", "Logical_Switch_Port", "ACL", "Logical_Router":
+		default:
+			if !importIgnoredTables[table] && len(rows) > 0 {
+				skipped[table] += len(rows)
+			}
+		}
+	}
+
+	// Ports and ACLs are imported with the switch referring to them
+	ports := make(map[string]nbRow)
+	for _, row := range tables["Logical_Switch_Port"] {
+		ports[ovsdbUUID(row["_uuid"])] = row
+	}
+	acls := make(map[string]nbRow)
+	for _, row := range tables["ACL"] {
+		acls[ovsdbUUID(row["_uuid"])] = row
+	}
+	usedPorts := make(map[string]bool)
+	usedACLs := make(map[string]bool)
+
+	state := &DesiredState{}
+	for _, row := range tables["Logical_Switch"] {
+		name := ovsdbString(row["name"])
+		if name == "" {
+			skipped["Logical_Switch"]++
+			continue
+		}
+		sw := DesiredSwitch{
+			Name:        name,
+			OtherConfig: ovsdbMap(row["other_config"]),
+		}
+
+		for _, id := range ovsdbUUIDs(row["ports"]) {
+			port, ok := ports[id]
+			if !ok || ovsdbString(port["name"]) == "" {
+				continue
+			}
+			usedPorts[id] = true
+			desired := DesiredPort{
+				Name:         ovsdbString(port["name"]),
+				Type:         ovsdbString(port["type"]),
+				Addresses:    ovsdbStrings(port["addresses"]),
+				PortSecurity: ovsdbStrings(port["port_security"]),
+				Options:      ovsdbMap(port["options"]),
+			}
+			if enabled := ovsdbSet(port["enabled"]); len(enabled) == 1 {
+				if value, ok := enabled[0].(bool); ok {
+					desired.Enabled = &value
+				}
+			}
+			sw.Ports = append(sw.Ports, desired)
+		}
+		sort.Slice(sw.Ports, func(i, j int) bool { return sw.Ports[i].Name < sw.Ports[j].Name })
+
+		for _, id := range ovsdbUUIDs(row["acls"]) {
+			acl, ok := acls[id]
+			if !ok {
+				continue
+			}
+			usedACLs[id] = true
+			sw.ACLs = append(sw.ACLs, DesiredACL{
+				Name:      ovsdbString(acl["name"]),
+				Direction: ovsdbString(acl["direction"]),
+				Priority:  ovsdbInt(acl["priority"]),
+				Match:     ovsdbString(acl["match"]),
+				Action:    ovsdbString(acl["action"]),
+				Log:       ovsdbBool(acl["log"]),
+				Severity:  ovsdbString(acl["severity"]),
+			})
+		}
+		sort.Slice(sw.ACLs, func(i, j int) bool {
+			a, b := sw.ACLs[i], sw.ACLs[j]
+			if a.Direction != b.Direction {
+				return a.Direction < b.Direction
+			}
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return a.Match < b.Match
+		})
+
+		state.Switches = append(state.Switches, sw)
+	}
+	sort.Slice(state.Switches, func(i, j int) bool { return state.Switches[i].Name < state.Switches[j].Name })
+
+	for id := range ports {
+		if !usedPorts[id] {
+			skipped["Logical_Switch_Port"]++
+		}
+	}
+	for id := range acls {
+		if !usedACLs[id] {
+			// Such as the ACLs of port groups
+			skipped["ACL"]++
+		}
+	}
+
+	for _, row := range tables["Logical_Router"] {
+		name := ovsdbString(row["name"])
+		if name == "" {
+			skipped["Logical_Router"]++
+			continue
+		}
+		state.Routers = append(state.Routers, DesiredRouter{
+			Name:    name,
+			Options: ovsdbMap(row["options"]),
+		})
+	}
+	sort.Slice(state.Routers, func(i, j int) bool { return state.Routers[i].Name < state.Routers[j].Name })
+
+	return state, skipped, nil
+}
+
+// nbTable is a table as printed by ovsdb-client with --format=json
+type nbTable struct {
+	Caption  string          `json:"caption"`
+	Headings []string        `json:"headings"`
+	Data     [][]interface{} `json:"data"`
+}
+
+// decodeNBTables reads the rows of each table of an export. It accepts
+// ovsdb-client's tables, one after the other or in an array, and objects
+// mapping table names to rows by UUID.
+func decodeNBTables(data []byte) (map[string][]nbRow, error) {
+	tables := make(map[string][]nbRow)
+	addTable := func(raw json.RawMessage) error {
+		var table nbTable
+		if err := unmarshalNumbers(raw, &table); err != nil {
+			return err
+		}
+		if table.Headings != nil {
+			name := strings.TrimSuffix(table.Caption, " table")
+			if name == "" {
+				return fmt.Errorf("table without caption")
+			}
+			for _, cells := range table.Data {
+				if len(cells) != len(table.Headings) {
+					return fmt.Errorf("%s: row has %d cells for %d headings", name, len(cells), len(table.Headings))
+				}
+				row := make(nbRow, len(cells))
+				for i, heading := range table.Headings {
+					row[heading] = cells[i]
+				}
+				tables[name] = append(tables[name], row)
+			}
+			return nil
+		}
+
+		var byTable map[string]map[string]nbRow
+		if err := unmarshalNumbers(raw, &byTable); err != nil {
+			return fmt.Errorf("neither an ovsdb-client table nor rows by table: %v", err)
+		}
+		for name, rows := range byTable {
+			for id, row := range rows {
+				// Monitor updates wrap rows as {"initial": row} or {"new": row}
+				for _, key := range []string{"initial", "new"} {
+					if inner, ok := row[key].(map[string]interface{}); ok && len(row) == 1 {
+						row = inner
+					}
+				}
+				row["_uuid"] = []interface{}{"uuid", id}
+				tables[name] = append(tables[name], row)
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+			var list []json.RawMessage
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, err
+			}
+			for _, item := range list {
+				if err := addTable(item); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := addTable(raw); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// unmarshalNumbers decodes raw keeping numbers as json.Number, so that
+// integers stay exact
+func unmarshalNumbers(raw json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// ovsdbSet returns the atoms of an OVSDB JSON value: the elements of a
+// ["set", [...]], or the value itself if it is a single atom
+func ovsdbSet(value interface{}) []interface{} {
+	if pair, ok := value.([]interface{}); ok && len(pair) == 2 {
+		if tag, _ := pair[0].(string); tag == "set" {
+			elements, _ := pair[1].([]interface{})
+			return elements
+		}
+	}
+	if value == nil {
+		return nil
+	}
+	return []interface{}{value}
+}
+
+// ovsdbAtom returns the string of an atom: strings, numbers and UUIDs
+func ovsdbAtom(atom interface{}) string {
+	switch v := atom.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	case []interface{}:
+		if len(v) == 2 {
+			if tag, _ := v[0].(string); tag == "uuid" || tag == "named-uuid" {
+				id, _ := v[1].(string)
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+func ovsdbString(value interface{}) string {
+	if atoms := ovsdbSet(value); len(atoms) == 1 {
+		return ovsdbAtom(atoms[0])
+	}
+	return ""
+}
+
+func ovsdbStrings(value interface{}) []string {
+	var result []string
+	for _, atom := range ovsdbSet(value) {
+		result = append(result, ovsdbAtom(atom))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func ovsdbUUID(value interface{}) string {
+	return ovsdbAtom(value)
+}
+
+func ovsdbUUIDs(value interface{}) []string {
+	var result []string
+	for _, atom := range ovsdbSet(value) {
+		result = append(result, ovsdbAtom(atom))
+	}
+	return result
+}
+
+func ovsdbInt(value interface{}) int {
+	if atoms := ovsdbSet(value); len(atoms) == 1 {
+		if n, ok := atoms[0].(json.Number); ok {
+			i, _ := n.Int64()
+			return int(i)
+		}
+	}
+	return 0
+}
+
+func ovsdbBool(value interface{}) bool {
+	if atoms := ovsdbSet(value); len(atoms) == 1 {
+		b, _ := atoms[0].(bool)
+		return b
+	}
+	return false
+}
+
+// ovsdbMap returns a ["map", [[key, value], ...]] as a map, nil if empty
+func ovsdbMap(value interface{}) map[string]string {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return nil
+	}
+	if tag, _ := pair[0].(string); tag != "map" {
+		return nil
+	}
+	entries, _ := pair[1].([]interface{})
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if kv, ok := entry.([]interface{}); ok && len(kv) == 2 {
+			result[ovsdbAtom(kv[0])] = ovsdbAtom(kv[1])
+		}
+	}
+	return result
+}
+
+// ParseNBShow converts "ovn-nbctl show" output into a desired state. The
+// output lists switches with their ports' types, addresses and router
+// ports, and routers; ACLs and router details are not part of it.
+func ParseNBShow(data []byte) (*DesiredState, map[string]int, error) {
+	state := &DesiredState{}
+	skipped := make(map[string]int)
+
+	var sw *DesiredSwitch
+	var port *DesiredPort
+	inRouter := false
+	flush := func() {
+		if sw != nil {
+			if port != nil {
+				sw.Ports = append(sw.Ports, *port)
+			}
+			state.Switches = append(state.Switches, *sw)
+		}
+		sw, port, inRouter = nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		fields := strings.Fields(line)
+
+		switch {
+		case indent == 0:
+			flush()
+			name := nbShowName(line)
+			switch fields[0] {
+			case "switch":
+				if name == "" {
+					skipped["Logical_Switch"]++
+					continue
+				}
+				sw = &DesiredSwitch{Name: name}
+			case "router":
+				inRouter = true
+				if name == "" {
+					skipped["Logical_Router"]++
+					continue
+				}
+				state.Routers = append(state.Routers, DesiredRouter{Name: name})
+			default:
+				return nil, nil, fmt.Errorf("%w: line %d: expected a switch or router, got %q", ErrInvalidImport, lineNumber, fields[0])
+			}
+
+		case indent <= 4:
+			if sw != nil && port != nil {
+				sw.Ports = append(sw.Ports, *port)
+			}
+			port = nil
+			if fields[0] == "port" && sw != nil && len(fields) > 1 {
+				port = &DesiredPort{Name: fields[1]}
+			} else if fields[0] == "port" && inRouter {
+				skipped["Logical_Router_Port"]++
+			} else if sw != nil || inRouter {
+				skipped[nbShowTable(fields[0])]++
+			}
+
+		default:
+			// An attribute of the port above
+			if port == nil {
+				continue
+			}
+			key, value, ok := strings.Cut(strings.TrimSpace(line), ": ")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "type":
+				port.Type = value
+			case "addresses":
+				var addresses []string
+				if err := json.Unmarshal([]byte(value), &addresses); err != nil {
+					return nil, nil, fmt.Errorf("%w: line %d: invalid addresses: %v", ErrInvalidImport, lineNumber, err)
+				}
+				port.Addresses = addresses
+			case "router-port":
+				if port.Options == nil {
+					port.Options = make(map[string]string)
+				}
+				port.Options["router-port"] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	flush()
+
+	for i := range state.Switches {
+		ports := state.Switches[i].Ports
+		sort.Slice(ports, func(a, b int) bool { return ports[a].Name < ports[b].Name })
+	}
+	sort.Slice(state.Switches, func(i, j int) bool { return state.Switches[i].Name < state.Switches[j].Name })
+	sort.Slice(state.Routers, func(i, j int) bool { return state.Routers[i].Name < state.Routers[j].Name })
+	return state, skipped, nil
+}
+
+// nbShowName returns the name in "switch <uuid> (<name>)", "" if there is
+// none
+func nbShowName(line string) string {
+	start := strings.Index(line, "(")
+	end := strings.Index(line, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(line[start+1 : end])
+}
+
+// nbShowTable returns the table of a child listed by ovn-nbctl show
+func nbShowTable(keyword string) string {
+	switch keyword {
+	case "nat":
+		return "NAT"
+	case "dns":
+		return "DNS"
+	}
+	return keyword
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// nbDump is ovsdb-client dump --format=json --data=json output, one table
+// per line
+const nbDump = `{"caption":"ACL table","data":[[["uuid","a1"],"allow-related","to-lport",false,"tcp.dst == 80",["set",[]],1000,["set",[]]],[["uuid","a2"],"drop","to-lport",true,"ip4",["set",[]],900,"warning"],[["uuid","pg-acl"],"drop","from-lport",false,"ip4",["set",[]],100,["set",[]]]],"headings":["_uuid","action","direction","log","match","name",  "priority","severity"]}
+{"caption":"Logical_Router table","data":[[["uuid","r1"],"edge",["map",[["chassis","gw-1"]]]]],"headings":["_uuid","name","options"]}
+{"caption":"Logical_Router_Port table","data":[[["uuid","rp1"],"edge-web"]],"headings":["_uuid","name"]}
+{"caption":"Logical_Switch table","data":[[["uuid","s1"],["set",[["uuid","a1"],["uuid","a2"]]],"web",["map",[["subnet","10.0.1.0/24"]]],["set",[["uuid","p1"],["uuid","p2"]]]],[["uuid","s2"],["set",[]],"",["map",[]],["set",[]]]],"headings":["_uuid","acls","name","other_config","ports"]}
+{"caption":"Logical_Switch_Port table","data":[[["uuid","p1"],["set",["00:00:00:00:01:01 10.0.1.11"]],["set",[]],"web-1",["map",[]],"",["set",[]]],[["uuid","p2"],"router",false,"web-edge",["map",[["router-port","edge-web"]]],"router",["set",[]]],[["uuid","p3"],["set",[]],["set",[]],"orphan",["map",[]],"",["set",[]]]],"headings":["_uuid","addresses","enabled","name","options","type","port_security"]}
+{"caption":"NB_Global table","data":[[["uuid","g1"]]],"headings":["_uuid"]}
+`
+
+func TestParseNBJSON(t *testing.T) {
+	state, skipped, err := ParseNBJSON([]byte(nbDump))
+	require.NoError(t, err)
+
+	require.Len(t, state.Switches, 1)
+	web := state.Switches[0]
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, map[string]string{"subnet": "10.0.1.0/24"}, web.OtherConfig)
+	require.Len(t, web.Ports, 2)
+	assert.Equal(t, DesiredPort{Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}}, web.Ports[0])
+	assert.Equal(t, "router", web.Ports[1].Type)
+	assert.Equal(t, map[string]string{"router-port": "edge-web"}, web.Ports[1].Options)
+	require.NotNil(t, web.Ports[1].Enabled)
+	assert.False(t, *web.Ports[1].Enabled)
+	assert.Equal(t, []DesiredACL{
+		{Direction: "to-lport", Priority: 1000, Match: "tcp.dst == 80", Action: "allow-related"},
+		{Direction: "to-lport", Priority: 900, Match: "ip4", Action: "drop", Log: true, Severity: "warning"},
+	}, web.ACLs)
+
+	assert.Equal(t, []DesiredRouter{{Name: "edge", Options: map[string]string{"chassis": "gw-1"}}}, state.Routers)
+	assert.Equal(t, map[string]int{
+		"Logical_Switch":      1, // unnamed
+		"Logical_Switch_Port": 1, // on no switch
+		"ACL":                 1, // of no switch
+		"Logical_Router_Port": 1,
+	}, skipped)
+}
+
+func TestParseNBJSON_RowsByTable(t *testing.T) {
+	state, skipped, err := ParseNBJSON([]byte(`{
+		"Logical_Switch": {"s1": {"initial": {"name": "db", "ports": ["uuid", "p1"]}}},
+		"Logical_Switch_Port": {"p1": {"initial": {"name": "db-1", "addresses": "dynamic"}}},
+		"NAT": {"n1": {"initial": {"type": "snat"}}}
+	}`))
+	require.NoError(t, err)
+	require.Len(t, state.Switches, 1)
+	assert.Equal(t, []DesiredPort{{Name: "db-1", Addresses: []string{"dynamic"}}}, state.Switches[0].Ports)
+	assert.Equal(t, map[string]int{"NAT": 1}, skipped)
+
+	_, _, err = ParseNBJSON([]byte(`{"caption": "ACL table", "headings": ["_uuid", "match"], "data": [[["uuid", "a1"]]]}`))
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
+
+func TestParseNBShow(t *testing.T) {
+	state, skipped, err := ParseNBShow([]byte(`switch 4d0b3c1a-8f3e-4b59-9c5e-0d1f0e5f6a7b (web)
+    port web-1
+        addresses: ["00:00:00:00:01:01 10.0.1.11"]
+    port web-edge
+        type: router
+        router-port: edge-web
+        addresses: ["router"]
+switch 9a8b7c6d-1e2f-4a3b-8c9d-0e1f2a3b4c5d (db) (aka database)
+    port db-1
+        addresses: ["dynamic"]
+router 1f2e3d4c-5b6a-4978-8695-a4b3c2d1e0f9 (edge)
+    port edge-web
+        mac: "00:00:00:00:ff:01"
+        networks: ["10.0.1.1/24"]
+    nat 0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d
+        external ip: "172.16.0.10"
+        logical ip: "10.0.1.0/24"
+        type: "snat"
+`))
+	require.NoError(t, err)
+
+	require.Len(t, state.Switches, 2)
+	assert.Equal(t, DesiredSwitch{Name: "db", Ports: []DesiredPort{{Name: "db-1", Addresses: []string{"dynamic"}}}}, state.Switches[0])
+	assert.Equal(t, "web", state.Switches[1].Name)
+	assert.Equal(t, []DesiredPort{
+		{Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.11"}},
+		{Name: "web-edge", Type: "router", Addresses: []string{"router"}, Options: map[string]string{"router-port": "edge-web"}},
+	}, state.Switches[1].Ports)
+	assert.Equal(t, []DesiredRouter{{Name: "edge"}}, state.Routers)
+	assert.Equal(t, map[string]int{"Logical_Router_Port": 1, "NAT": 1}, skipped)
+
+	_, _, err = ParseNBShow([]byte("chassis 1234\n"))
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
+
+func TestImportService_DryRun(t *testing.T) {
+	mockService := new(MockOVNService)
+	mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{}, nil)
+	mockService.On("ListLogicalRouters", mock.Anything).Return([]*models.LogicalRouter{}, nil)
+
+	service := NewImportService(NewApplyService(mockService, zap.NewNop()), zap.NewNop())
+	result, err := service.Import(context.Background(), DetectImportFormat([]byte(nbDump)), []byte(nbDump), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, ImportFormatNBJSON, result.Format)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 0, result.Applied)
+	// The switch, its two ports and two ACLs, and the router
+	assert.Equal(t, 6, result.Plan.Summary.Create)
+	mockService.AssertNotCalled(t, "CreateLogicalSwitch", mock.Anything, mock.Anything)
+
+	_, err = service.Import(context.Background(), ImportFormatNBShow, []byte("\n"), true)
+	assert.ErrorIs(t, err, ErrInvalidImport, "documents without resources are refused")
+}
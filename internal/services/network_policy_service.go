@@ -0,0 +1,701 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+	"go.uber.org/zap"
+)
+
+const (
+	// NetworkPolicyOwnerKey is the external_ids key naming the policy
+	// (namespace/name) an OVN object was translated from
+	NetworkPolicyOwnerKey = "k8s.networkpolicy"
+	// NetworkPolicyRuleKey identifies the rule an ACL or address set
+	// implements, e.g. ingress/0
+	NetworkPolicyRuleKey = "k8s.networkpolicy.rule"
+
+	// PodLabelPrefix marks pod labels stored in a logical switch port's
+	// external_ids, e.g. pod-label/app=web
+	PodLabelPrefix = "pod-label/"
+
+	// ACL priorities: allow rules of any policy override the default deny
+	// of every other policy selecting the same pod
+	networkPolicyDenyPriority  = 1000
+	networkPolicyAllowPriority = 1001
+)
+
+// NetworkPolicyRequest is a policy to translate, with an optional inventory
+// of pods and namespaces to evaluate its selectors against. Without an
+// inventory, pods are read from logical switch ports whose external_ids
+// carry namespace and pod-label/ keys.
+type NetworkPolicyRequest struct {
+	Policy     models.NetworkPolicy     `json:"policy"`
+	Pods       []models.PolicyPod       `json:"pods,omitempty"`
+	Namespaces []models.PolicyNamespace `json:"namespaces,omitempty"`
+}
+
+// NetworkPolicyPortGroup is a port group with the ACLs applied to it
+type NetworkPolicyPortGroup struct {
+	PortGroup *models.PortGroup `json:"port_group"`
+	ACLs      []*models.ACL     `json:"acls"`
+}
+
+// NetworkPolicyObjects are the OVN objects a policy translates to
+type NetworkPolicyObjects struct {
+	Policy       string                    `json:"policy"`
+	PortGroups   []*NetworkPolicyPortGroup `json:"port_groups"`
+	AddressSets  []*models.AddressSet      `json:"address_sets"`
+	SelectedPods []string                  `json:"selected_pods,omitempty"`
+	Warnings     []string                  `json:"warnings,omitempty"`
+}
+
+// NetworkPolicyService translates Kubernetes NetworkPolicies into OVN port
+// groups, address sets and ACLs, following the model used by ovn-kubernetes:
+// a port group holds the pods a policy selects, a default-deny ACL drops
+// their traffic in each policy direction, and one allow ACL per rule matches
+// the rule's peers, held in address sets, and ports.
+type NetworkPolicyService struct {
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+}
+
+// NewNetworkPolicyService creates a new network policy service
+func NewNetworkPolicyService(ovnService OVNServiceInterface, logger *zap.Logger) *NetworkPolicyService {
+	return &NetworkPolicyService{
+		ovnService: ovnService,
+		logger:     logger,
+	}
+}
+
+// policyPod is a pod with the logical switch port it is attached to, if any
+type policyPod struct {
+	models.PolicyPod
+	portUUID string
+}
+
+// Apply translates a policy and, unless dryRun is set, replaces the OVN
+// objects previously created for it
+func (s *NetworkPolicyService) Apply(ctx context.Context, req *NetworkPolicyRequest, dryRun bool) (*NetworkPolicyObjects, error) {
+	objects, specs, addressSets, err := s.Translate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return objects, nil
+	}
+
+	owner := map[string]string{NetworkPolicyOwnerKey: objects.Policy}
+	created, err := s.ovnService.ReplaceOwnedObjects(ctx, owner, addressSets, specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply network policy %s: %w", objects.Policy, err)
+	}
+
+	// Report the objects with the UUIDs OVN assigned
+	objects.AddressSets = created.AddressSets
+	for i, pg := range created.PortGroups {
+		objects.PortGroups[i].PortGroup = pg
+	}
+
+	s.logger.Info("Applied network policy",
+		zap.String("policy", objects.Policy),
+		zap.Int("selected_pods", len(objects.SelectedPods)),
+		zap.Int("address_sets", len(objects.AddressSets)))
+
+	return objects, nil
+}
+
+// Translate computes the OVN objects for a policy without changing anything
+func (s *NetworkPolicyService) Translate(ctx context.Context, req *NetworkPolicyRequest) (*NetworkPolicyObjects, []*ovn.PortGroupSpec, []*models.AddressSet, error) {
+	policy := &req.Policy
+	if err := validateNetworkPolicy(policy); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid network policy: %w", err)
+	}
+
+	pods, warnings, err := s.inventory(ctx, req.Pods)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	namespaceLabels := namespaceLabelIndex(req.Namespaces, pods)
+
+	key := policy.Metadata.Namespace + "/" + policy.Metadata.Name
+	base := networkPolicyBaseName(key)
+	objects := &NetworkPolicyObjects{
+		Policy:      key,
+		AddressSets: []*models.AddressSet{},
+		Warnings:    warnings,
+	}
+
+	pg := &models.PortGroup{
+		Name:        base,
+		Ports:       []string{},
+		ExternalIDs: map[string]string{NetworkPolicyOwnerKey: key},
+	}
+	for _, pod := range pods {
+		if pod.Namespace != policy.Metadata.Namespace || !policy.Spec.PodSelector.Matches(pod.Labels) {
+			continue
+		}
+		objects.SelectedPods = append(objects.SelectedPods, pod.Namespace+"/"+pod.Name)
+		if pod.portUUID == "" {
+			objects.Warnings = append(objects.Warnings, fmt.Sprintf("pod %s/%s has no logical switch port", pod.Namespace, pod.Name))
+			continue
+		}
+		pg.Ports = append(pg.Ports, pod.portUUID)
+	}
+	sort.Strings(pg.Ports)
+
+	var acls []*models.ACL
+	addressSets := []*models.AddressSet{}
+	translate := func(direction string, index int, peers []models.NetworkPolicyPeer, ports []models.NetworkPolicyPort) {
+		rule := fmt.Sprintf("%s/%d", direction, index)
+		acl, sets, ruleWarnings := s.translateRule(policy, base, key, rule, direction, peers, ports, pods, namespaceLabels)
+		objects.Warnings = append(objects.Warnings, ruleWarnings...)
+		addressSets = append(addressSets, sets...)
+		if acl != nil {
+			acls = append(acls, acl)
+		}
+	}
+
+	if hasPolicyType(policy, "Ingress") {
+		acls = append(acls, defaultDenyACL(base, key, "ingress"))
+		for i, rule := range policy.Spec.Ingress {
+			translate("ingress", i, rule.From, rule.Ports)
+		}
+	}
+	if hasPolicyType(policy, "Egress") {
+		acls = append(acls, defaultDenyACL(base, key, "egress"))
+		for i, rule := range policy.Spec.Egress {
+			translate("egress", i, rule.To, rule.Ports)
+		}
+	}
+
+	objects.PortGroups = []*NetworkPolicyPortGroup{{PortGroup: pg, ACLs: acls}}
+	objects.AddressSets = addressSets
+	specs := []*ovn.PortGroupSpec{{PortGroup: pg, ACLs: acls}}
+
+	return objects, specs, addressSets, nil
+}
+
+// Get returns the OVN objects created for a policy
+func (s *NetworkPolicyService) Get(ctx context.Context, namespace, name string) (*NetworkPolicyObjects, error) {
+	key := namespace + "/" + name
+	found, err := s.collect(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("network policy %s not found", key)
+	}
+	return found[0], nil
+}
+
+// List returns the OVN objects of every translated policy
+func (s *NetworkPolicyService) List(ctx context.Context) ([]*NetworkPolicyObjects, error) {
+	return s.collect(ctx, "")
+}
+
+// collect groups port groups and address sets by the policy named in their
+// external_ids, limited to one policy unless key is empty
+func (s *NetworkPolicyService) collect(ctx context.Context, key string) ([]*NetworkPolicyObjects, error) {
+	portGroups, err := s.ovnService.ListPortGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+	addressSets, err := s.ovnService.ListAddressSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address sets: %w", err)
+	}
+
+	byPolicy := make(map[string]*NetworkPolicyObjects)
+	get := func(policy string) *NetworkPolicyObjects {
+		objects, ok := byPolicy[policy]
+		if !ok {
+			objects = &NetworkPolicyObjects{
+				Policy:      policy,
+				PortGroups:  []*NetworkPolicyPortGroup{},
+				AddressSets: []*models.AddressSet{},
+			}
+			byPolicy[policy] = objects
+		}
+		return objects
+	}
+
+	for _, pg := range portGroups {
+		owner, ok := pg.ExternalIDs[NetworkPolicyOwnerKey]
+		if !ok || (key != "" && owner != key) {
+			continue
+		}
+		acls, err := s.ovnService.ListPortGroupACLs(ctx, pg.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of port group %s: %w", pg.Name, err)
+		}
+		sort.Slice(acls, func(i, j int) bool {
+			if acls[i].Priority != acls[j].Priority {
+				return acls[i].Priority < acls[j].Priority
+			}
+			return acls[i].Match < acls[j].Match
+		})
+		objects := get(owner)
+		objects.PortGroups = append(objects.PortGroups, &NetworkPolicyPortGroup{PortGroup: pg, ACLs: acls})
+	}
+	for _, as := range addressSets {
+		if owner, ok := as.ExternalIDs[NetworkPolicyOwnerKey]; ok && (key == "" || owner == key) {
+			objects := get(owner)
+			objects.AddressSets = append(objects.AddressSets, as)
+		}
+	}
+
+	result := make([]*NetworkPolicyObjects, 0, len(byPolicy))
+	for _, objects := range byPolicy {
+		sort.Slice(objects.AddressSets, func(i, j int) bool { return objects.AddressSets[i].Name < objects.AddressSets[j].Name })
+		result = append(result, objects)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Policy < result[j].Policy })
+
+	return result, nil
+}
+
+// Delete removes the OVN objects created for a policy
+func (s *NetworkPolicyService) Delete(ctx context.Context, namespace, name string) error {
+	if _, err := s.Get(ctx, namespace, name); err != nil {
+		return err
+	}
+
+	owner := map[string]string{NetworkPolicyOwnerKey: namespace + "/" + name}
+	if _, err := s.ovnService.ReplaceOwnedObjects(ctx, owner, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete network policy %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// translateRule builds the allow ACL and address sets for one rule. It
+// returns a nil ACL when none of the rule's ports can be expressed in OVN.
+func (s *NetworkPolicyService) translateRule(policy *models.NetworkPolicy, base, key, rule, direction string, peers []models.NetworkPolicyPeer, ports []models.NetworkPolicyPort, pods []*policyPod, namespaceLabels map[string]map[string]string) (*models.ACL, []*models.AddressSet, []string) {
+	var warnings []string
+
+	portMatch, portWarnings, ok := portsMatch(ports)
+	warnings = append(warnings, prefixWarnings(rule, portWarnings)...)
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("%s: skipped, none of its ports can be translated", rule))
+		return nil, nil, warnings
+	}
+
+	// Traffic from peers to selected pods is matched on the source address
+	// at the selected pod's outport, and the reverse for egress
+	portField, ipField, aclDirection := "outport", "src", "to-lport"
+	if direction == "egress" {
+		portField, ipField, aclDirection = "inport", "dst", "from-lport"
+	}
+
+	clauses := []string{fmt.Sprintf("%s == @%s", portField, base)}
+
+	var sets []*models.AddressSet
+	if len(peers) > 0 {
+		var v4, v6 []string
+		var blocks []string
+		hasPodPeers := false
+		for _, peer := range peers {
+			if peer.IPBlock != nil {
+				blocks = append(blocks, ipBlockMatch(peer.IPBlock, ipField))
+				continue
+			}
+			hasPodPeers = true
+			for _, pod := range pods {
+				if peerSelectsPod(policy.Metadata.Namespace, &peer, pod, namespaceLabels) {
+					for _, ip := range pod.IPs {
+						if strings.Contains(ip, ":") {
+							v6 = append(v6, ip)
+						} else {
+							v4 = append(v4, ip)
+						}
+					}
+				}
+			}
+		}
+
+		var peerClauses []string
+		if hasPodPeers {
+			setBase := fmt.Sprintf("%s_%s_%s", base, direction, strings.TrimPrefix(rule, direction+"/"))
+			v4Set := newPolicyAddressSet(setBase+"_v4", key, rule, v4)
+			sets = append(sets, v4Set)
+			peerClauses = append(peerClauses, fmt.Sprintf("ip4.%s == $%s", ipField, v4Set.Name))
+			if len(v6) > 0 {
+				v6Set := newPolicyAddressSet(setBase+"_v6", key, rule, v6)
+				sets = append(sets, v6Set)
+				peerClauses = append(peerClauses, fmt.Sprintf("ip6.%s == $%s", ipField, v6Set.Name))
+			}
+		}
+		peerClauses = append(peerClauses, blocks...)
+		clauses = append(clauses, "("+strings.Join(peerClauses, " || ")+")")
+	}
+
+	if portMatch != "" {
+		clauses = append(clauses, portMatch)
+	}
+
+	acl := &models.ACL{
+		Direction: aclDirection,
+		Priority:  networkPolicyAllowPriority,
+		Match:     strings.Join(clauses, " && "),
+		Action:    "allow-related",
+		ExternalIDs: map[string]string{
+			NetworkPolicyOwnerKey: key,
+			NetworkPolicyRuleKey:  rule,
+			models.ACLOwnerKey:    models.ACLOwnerOperator,
+		},
+	}
+
+	return acl, sets, warnings
+}
+
+// inventory returns the pods selectors are evaluated against, matched to
+// their logical switch ports
+func (s *NetworkPolicyService) inventory(ctx context.Context, declared []models.PolicyPod) ([]*policyPod, []string, error) {
+	switches, err := s.ovnService.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list switches: %w", err)
+	}
+
+	var ports []*models.LogicalSwitchPort
+	for _, sw := range switches {
+		swPorts, err := s.ovnService.ListPorts(ctx, sw.UUID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list ports of switch %s: %w", sw.Name, err)
+		}
+		ports = append(ports, swPorts...)
+	}
+
+	// Pods are attached to ports named <namespace>_<pod>, the ovn-kubernetes
+	// convention, or carrying namespace and pod external IDs
+	portsByPod := make(map[string]*models.LogicalSwitchPort)
+	for _, port := range ports {
+		namespace, pod := podOfPort(port)
+		if namespace != "" {
+			portsByPod[namespace+"/"+pod] = port
+		}
+	}
+
+	var pods []*policyPod
+	var warnings []string
+	if len(declared) > 0 {
+		for _, pod := range declared {
+			p := &policyPod{PolicyPod: pod}
+			if port, ok := portsByPod[pod.Namespace+"/"+pod.Name]; ok {
+				p.portUUID = port.UUID
+				if len(p.IPs) == 0 {
+					p.IPs = portIPs(port)
+				}
+			}
+			pods = append(pods, p)
+		}
+	} else {
+		for _, port := range ports {
+			namespace, name := podOfPort(port)
+			if namespace == "" || port.ExternalIDs["namespace"] == "" {
+				continue
+			}
+			labels := make(map[string]string)
+			for k, v := range port.ExternalIDs {
+				if strings.HasPrefix(k, PodLabelPrefix) {
+					labels[strings.TrimPrefix(k, PodLabelPrefix)] = v
+				}
+			}
+			pods = append(pods, &policyPod{
+				PolicyPod: models.PolicyPod{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    labels,
+					IPs:       portIPs(port),
+				},
+				portUUID: port.UUID,
+			})
+		}
+		if len(pods) == 0 {
+			warnings = append(warnings, "no pods given and no logical switch ports carry pod external IDs; selectors match nothing")
+		}
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	return pods, warnings, nil
+}
+
+// podOfPort returns the namespace and pod a logical switch port belongs to
+func podOfPort(port *models.LogicalSwitchPort) (namespace, pod string) {
+	if ns := port.ExternalIDs["namespace"]; ns != "" {
+		if strings.HasPrefix(port.Name, ns+"_") {
+			return ns, strings.TrimPrefix(port.Name, ns+"_")
+		}
+		return ns, port.Name
+	}
+	if i := strings.Index(port.Name, "_"); i > 0 && i < len(port.Name)-1 {
+		return port.Name[:i], port.Name[i+1:]
+	}
+	return "", ""
+}
+
+// portIPs extracts the IP addresses from a port's "MAC IP..." addresses
+func portIPs(port *models.LogicalSwitchPort) []string {
+	var ips []string
+	for _, addr := range port.Addresses {
+		fields := strings.Fields(addr)
+		for _, field := range fields[min(1, len(fields)):] {
+			ip := field
+			if i := strings.Index(ip, "/"); i >= 0 {
+				ip = ip[:i]
+			}
+			if net.ParseIP(ip) != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// namespaceLabelIndex returns the labels of every known namespace. As in
+// Kubernetes, each namespace carries a kubernetes.io/metadata.name label.
+func namespaceLabelIndex(namespaces []models.PolicyNamespace, pods []*policyPod) map[string]map[string]string {
+	index := make(map[string]map[string]string)
+	for _, pod := range pods {
+		if _, ok := index[pod.Namespace]; !ok {
+			index[pod.Namespace] = map[string]string{}
+		}
+	}
+	for _, ns := range namespaces {
+		labels := make(map[string]string, len(ns.Labels)+1)
+		for k, v := range ns.Labels {
+			labels[k] = v
+		}
+		index[ns.Name] = labels
+	}
+	for name, labels := range index {
+		labels["kubernetes.io/metadata.name"] = name
+	}
+	return index
+}
+
+// peerSelectsPod evaluates a pod or namespace selector peer
+func peerSelectsPod(policyNamespace string, peer *models.NetworkPolicyPeer, pod *policyPod, namespaceLabels map[string]map[string]string) bool {
+	if peer.NamespaceSelector != nil {
+		if !peer.NamespaceSelector.Matches(namespaceLabels[pod.Namespace]) {
+			return false
+		}
+	} else if pod.Namespace != policyNamespace {
+		return false
+	}
+
+	if peer.PodSelector != nil {
+		return peer.PodSelector.Matches(pod.Labels)
+	}
+	return true
+}
+
+// portsMatch builds the L4 clause for a rule's ports. Named ports can't be
+// resolved without the pod spec and are skipped with a warning; ok is false
+// when the rule had ports and none of them could be translated.
+func portsMatch(ports []models.NetworkPolicyPort) (match string, warnings []string, ok bool) {
+	if len(ports) == 0 {
+		return "", nil, true
+	}
+
+	var clauses []string
+	for _, port := range ports {
+		protocol := "tcp"
+		if port.Protocol != nil {
+			protocol = strings.ToLower(*port.Protocol)
+		}
+
+		switch {
+		case port.Port == nil:
+			clauses = append(clauses, protocol)
+		case port.Port.IsString:
+			warnings = append(warnings, fmt.Sprintf("named port %q is not supported", port.Port.StrVal))
+		case port.EndPort != nil:
+			clauses = append(clauses, fmt.Sprintf("%s && %s.dst >= %d && %s.dst <= %d", protocol, protocol, port.Port.IntVal, protocol, *port.EndPort))
+		default:
+			clauses = append(clauses, fmt.Sprintf("%s && %s.dst == %d", protocol, protocol, port.Port.IntVal))
+		}
+	}
+
+	if len(clauses) == 0 {
+		return "", warnings, false
+	}
+	if len(clauses) == 1 {
+		return clauses[0], warnings, true
+	}
+	for i, clause := range clauses {
+		clauses[i] = "(" + clause + ")"
+	}
+	return "(" + strings.Join(clauses, " || ") + ")", warnings, true
+}
+
+// ipBlockMatch matches a CIDR minus its exceptions
+func ipBlockMatch(block *models.IPBlock, ipField string) string {
+	family := "ip4"
+	if strings.Contains(block.CIDR, ":") {
+		family = "ip6"
+	}
+
+	match := fmt.Sprintf("%s.%s == %s", family, ipField, block.CIDR)
+	if len(block.Except) == 0 {
+		return match
+	}
+	return fmt.Sprintf("(%s && %s.%s != {%s})", match, family, ipField, strings.Join(block.Except, ", "))
+}
+
+func defaultDenyACL(base, key, direction string) *models.ACL {
+	acl := &models.ACL{
+		Direction: "to-lport",
+		Priority:  networkPolicyDenyPriority,
+		Match:     fmt.Sprintf("outport == @%s && ip", base),
+		Action:    "drop",
+		ExternalIDs: map[string]string{
+			NetworkPolicyOwnerKey: key,
+			NetworkPolicyRuleKey:  direction + "/default-deny",
+			models.ACLOwnerKey:    models.ACLOwnerOperator,
+		},
+	}
+	if direction == "egress" {
+		acl.Direction = "from-lport"
+		acl.Match = fmt.Sprintf("inport == @%s && ip", base)
+	}
+	return acl
+}
+
+func newPolicyAddressSet(name, key, rule string, addresses []string) *models.AddressSet {
+	sort.Strings(addresses)
+	unique := addresses[:0]
+	for i, addr := range addresses {
+		if i == 0 || addr != addresses[i-1] {
+			unique = append(unique, addr)
+		}
+	}
+	return &models.AddressSet{
+		Name:      name,
+		Addresses: append([]string{}, unique...),
+		ExternalIDs: map[string]string{
+			NetworkPolicyOwnerKey: key,
+			NetworkPolicyRuleKey:  rule,
+		},
+	}
+}
+
+// networkPolicyBaseName derives a stable OVN name for a policy. Port group
+// and address set names must be valid identifiers in ACL matches, which
+// Kubernetes names aren't, so the name is a hash of namespace/name.
+func networkPolicyBaseName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "np_" + hex.EncodeToString(sum[:8])
+}
+
+// hasPolicyType applies the Kubernetes default: Ingress always, Egress only
+// when the policy has egress rules
+func hasPolicyType(policy *models.NetworkPolicy, policyType string) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return policyType == "Ingress" || len(policy.Spec.Egress) > 0
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixWarnings(rule string, warnings []string) []string {
+	result := make([]string, len(warnings))
+	for i, w := range warnings {
+		result[i] = rule + ": " + w
+	}
+	return result
+}
+
+// validateNetworkPolicy checks the fields translation relies on
+func validateNetworkPolicy(policy *models.NetworkPolicy) error {
+	if policy.Kind != "" && policy.Kind != "NetworkPolicy" {
+		return fmt.Errorf("kind must be NetworkPolicy, got %s", policy.Kind)
+	}
+	if policy.Metadata.Name == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+	if policy.Metadata.Namespace == "" {
+		return fmt.Errorf("metadata.namespace is required")
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		if t != "Ingress" && t != "Egress" {
+			return fmt.Errorf("policyTypes must be Ingress or Egress, got %s", t)
+		}
+	}
+	if err := policy.Spec.PodSelector.Validate(); err != nil {
+		return fmt.Errorf("podSelector: %w", err)
+	}
+
+	validatePeers := func(rule string, peers []models.NetworkPolicyPeer, ports []models.NetworkPolicyPort) error {
+		for _, peer := range peers {
+			if peer.IPBlock != nil {
+				if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+					return fmt.Errorf("%s: ipBlock can't be combined with selectors", rule)
+				}
+				if _, _, err := net.ParseCIDR(peer.IPBlock.CIDR); err != nil {
+					return fmt.Errorf("%s: invalid ipBlock cidr %s", rule, peer.IPBlock.CIDR)
+				}
+				for _, except := range peer.IPBlock.Except {
+					if _, _, err := net.ParseCIDR(except); err != nil {
+						return fmt.Errorf("%s: invalid ipBlock except %s", rule, except)
+					}
+				}
+				continue
+			}
+			for _, selector := range []*models.LabelSelector{peer.PodSelector, peer.NamespaceSelector} {
+				if selector != nil {
+					if err := selector.Validate(); err != nil {
+						return fmt.Errorf("%s: %w", rule, err)
+					}
+				}
+			}
+		}
+		for _, port := range ports {
+			if port.Protocol != nil {
+				switch strings.ToUpper(*port.Protocol) {
+				case "TCP", "UDP", "SCTP":
+				default:
+					return fmt.Errorf("%s: protocol must be TCP, UDP or SCTP", rule)
+				}
+			}
+			if port.Port != nil && !port.Port.IsString && (port.Port.IntVal < 1 || port.Port.IntVal > 65535) {
+				return fmt.Errorf("%s: port must be between 1 and 65535", rule)
+			}
+			if port.EndPort != nil {
+				if port.Port == nil || port.Port.IsString || int(*port.EndPort) < port.Port.IntVal || *port.EndPort > 65535 {
+					return fmt.Errorf("%s: endPort requires a numeric port and must not be below it", rule)
+				}
+			}
+		}
+		return nil
+	}
+
+	for i, rule := range policy.Spec.Ingress {
+		if err := validatePeers(fmt.Sprintf("ingress/%d", i), rule.From, rule.Ports); err != nil {
+			return err
+		}
+	}
+	for i, rule := range policy.Spec.Egress {
+		if err := validatePeers(fmt.Sprintf("egress/%d", i), rule.To, rule.Ports); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func mustPolicy(t *testing.T, doc string) models.NetworkPolicy {
+	var policy models.NetworkPolicy
+	require.NoError(t, json.Unmarshal([]byte(doc), &policy))
+	return policy
+}
+
+func setupPolicyPorts(mockOVN *MockOVNService) {
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{{UUID: "sw-uuid", Name: "pods"}}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-uuid").Return([]*models.LogicalSwitchPort{
+		{
+			UUID:        "web-port",
+			Name:        "prod_web-1",
+			Addresses:   []string{"0a:58:0a:f4:00:05 10.244.0.5"},
+			ExternalIDs: map[string]string{"namespace": "prod", PodLabelPrefix + "app": "web"},
+		},
+		{
+			UUID:        "api-port",
+			Name:        "prod_api-1",
+			Addresses:   []string{"0a:58:0a:f4:00:06 10.244.0.6 fd00::6"},
+			ExternalIDs: map[string]string{"namespace": "prod", PodLabelPrefix + "app": "api"},
+		},
+		{
+			UUID:        "mon-port",
+			Name:        "monitoring_prom-1",
+			Addresses:   []string{"0a:58:0a:f4:01:07 10.244.1.7"},
+			ExternalIDs: map[string]string{"namespace": "monitoring", PodLabelPrefix + "app": "prometheus"},
+		},
+		{UUID: "router-port", Name: "rtr-port", Addresses: []string{"router"}},
+	}, nil)
+}
+
+func TestNetworkPolicyService_TranslateIngress(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	setupPolicyPorts(mockOVN)
+	service := NewNetworkPolicyService(mockOVN, zap.NewNop())
+
+	policy := mustPolicy(t, `{
+		"kind": "NetworkPolicy",
+		"metadata": {"name": "api-allow", "namespace": "prod"},
+		"spec": {
+			"podSelector": {"matchLabels": {"app": "api"}},
+			"ingress": [
+				{
+					"from": [
+						{"podSelector": {"matchLabels": {"app": "web"}}},
+						{"namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "monitoring"}}}
+					],
+					"ports": [{"protocol": "TCP", "port": 8080}, {"port": "metrics"}]
+				},
+				{
+					"from": [{"ipBlock": {"cidr": "192.168.0.0/16", "except": ["192.168.1.0/24"]}}],
+					"ports": [{"protocol": "UDP", "port": 5000, "endPort": 5010}]
+				}
+			]
+		}
+	}`)
+
+	objects, specs, addressSets, err := service.Translate(context.Background(), &NetworkPolicyRequest{Policy: policy})
+	require.NoError(t, err)
+
+	base := networkPolicyBaseName("prod/api-allow")
+	assert.Equal(t, "prod/api-allow", objects.Policy)
+	assert.Equal(t, []string{"prod/api-1"}, objects.SelectedPods)
+
+	require.Len(t, specs, 1)
+	assert.Equal(t, base, specs[0].PortGroup.Name)
+	assert.Equal(t, []string{"api-port"}, specs[0].PortGroup.Ports)
+
+	require.Len(t, addressSets, 1)
+	assert.Equal(t, base+"_ingress_0_v4", addressSets[0].Name)
+	assert.Equal(t, []string{"10.244.0.5", "10.244.1.7"}, addressSets[0].Addresses)
+	assert.Equal(t, "ingress/0", addressSets[0].ExternalIDs[NetworkPolicyRuleKey])
+
+	acls := specs[0].ACLs
+	require.Len(t, acls, 3)
+	assert.Equal(t, "drop", acls[0].Action)
+	assert.Equal(t, "to-lport", acls[0].Direction)
+	assert.Equal(t, networkPolicyDenyPriority, acls[0].Priority)
+	assert.Equal(t, "outport == @"+base+" && ip", acls[0].Match)
+	assert.Equal(t, models.ACLOwnerOperator, models.ACLOwnerFromExternalIDs(acls[0].ExternalIDs))
+
+	assert.Equal(t, "allow-related", acls[1].Action)
+	assert.Equal(t, networkPolicyAllowPriority, acls[1].Priority)
+	assert.Equal(t, "outport == @"+base+" && (ip4.src == $"+base+"_ingress_0_v4) && tcp && tcp.dst == 8080", acls[1].Match)
+
+	assert.Equal(t, "outport == @"+base+" && ((ip4.src == 192.168.0.0/16 && ip4.src != {192.168.1.0/24})) && udp && udp.dst >= 5000 && udp.dst <= 5010", acls[2].Match)
+
+	// Named ports can't be resolved and are reported
+	assert.Contains(t, objects.Warnings, `ingress/0: named port "metrics" is not supported`)
+}
+
+func TestNetworkPolicyService_TranslateEgressAndDefaults(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	setupPolicyPorts(mockOVN)
+	service := NewNetworkPolicyService(mockOVN, zap.NewNop())
+
+	// Selects every pod in prod; egress only to api pods on any TCP port
+	policy := mustPolicy(t, `{
+		"metadata": {"name": "egress", "namespace": "prod"},
+		"spec": {
+			"podSelector": {},
+			"policyTypes": ["Egress"],
+			"egress": [{"to": [{"podSelector": {"matchExpressions": [{"key": "app", "operator": "In", "values": ["api"]}]}}], "ports": [{"protocol": "TCP"}]}]
+		}
+	}`)
+
+	objects, specs, addressSets, err := service.Translate(context.Background(), &NetworkPolicyRequest{Policy: policy})
+	require.NoError(t, err)
+
+	base := networkPolicyBaseName("prod/egress")
+	assert.Equal(t, []string{"prod/api-1", "prod/web-1"}, objects.SelectedPods)
+	assert.Equal(t, []string{"api-port", "web-port"}, specs[0].PortGroup.Ports)
+
+	// The api pod is dual-stack, so the rule gets a v6 set as well
+	require.Len(t, addressSets, 2)
+	assert.Equal(t, []string{"10.244.0.6"}, addressSets[0].Addresses)
+	assert.Equal(t, []string{"fd00::6"}, addressSets[1].Addresses)
+
+	acls := specs[0].ACLs
+	require.Len(t, acls, 2)
+	assert.Equal(t, "from-lport", acls[0].Direction)
+	assert.Equal(t, "inport == @"+base+" && ip", acls[0].Match)
+	assert.Equal(t, "inport == @"+base+" && (ip4.dst == $"+base+"_egress_0_v4 || ip6.dst == $"+base+"_egress_0_v6) && tcp", acls[1].Match)
+}
+
+func TestNetworkPolicyService_TranslateWithInventory(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	setupPolicyPorts(mockOVN)
+	service := NewNetworkPolicyService(mockOVN, zap.NewNop())
+
+	policy := mustPolicy(t, `{
+		"metadata": {"name": "deny-all", "namespace": "prod"},
+		"spec": {"podSelector": {"matchLabels": {"tier": "frontend"}}, "ingress": [{"from": [{"namespaceSelector": {"matchLabels": {"team": "ops"}}}]}]}
+	}`)
+
+	req := &NetworkPolicyRequest{
+		Policy: policy,
+		Pods: []models.PolicyPod{
+			{Name: "web-1", Namespace: "prod", Labels: map[string]string{"tier": "frontend"}},
+			{Name: "web-2", Namespace: "prod", Labels: map[string]string{"tier": "frontend"}},
+			{Name: "prom-1", Namespace: "monitoring", IPs: []string{"10.244.9.9"}},
+		},
+		Namespaces: []models.PolicyNamespace{{Name: "monitoring", Labels: map[string]string{"team": "ops"}}},
+	}
+
+	objects, specs, addressSets, err := service.Translate(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"prod/web-1", "prod/web-2"}, objects.SelectedPods)
+	// web-1 is matched to its port by name; web-2 has none
+	assert.Equal(t, []string{"web-port"}, specs[0].PortGroup.Ports)
+	assert.Contains(t, objects.Warnings, "pod prod/web-2 has no logical switch port")
+	// Inventory IPs take precedence over port addresses
+	require.Len(t, addressSets, 1)
+	assert.Equal(t, []string{"10.244.9.9"}, addressSets[0].Addresses)
+}
+
+func TestNetworkPolicyService_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{
+			name:   "missing namespace",
+			policy: `{"metadata": {"name": "p"}, "spec": {"podSelector": {}}}`,
+			err:    "metadata.namespace is required",
+		},
+		{
+			name:   "wrong kind",
+			policy: `{"kind": "Pod", "metadata": {"name": "p", "namespace": "n"}, "spec": {"podSelector": {}}}`,
+			err:    "kind must be NetworkPolicy",
+		},
+		{
+			name:   "bad selector operator",
+			policy: `{"metadata": {"name": "p", "namespace": "n"}, "spec": {"podSelector": {"matchExpressions": [{"key": "a", "operator": "Gt"}]}}}`,
+			err:    "unknown selector operator",
+		},
+		{
+			name:   "bad cidr",
+			policy: `{"metadata": {"name": "p", "namespace": "n"}, "spec": {"podSelector": {}, "ingress": [{"from": [{"ipBlock": {"cidr": "10.0.0.0"}}]}]}}`,
+			err:    "invalid ipBlock cidr",
+		},
+		{
+			name:   "endPort below port",
+			policy: `{"metadata": {"name": "p", "namespace": "n"}, "spec": {"podSelector": {}, "ingress": [{"ports": [{"port": 100, "endPort": 50}]}]}}`,
+			err:    "endPort",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewNetworkPolicyService(new(MockOVNService), zap.NewNop())
+
+			_, err := service.Apply(context.Background(), &NetworkPolicyRequest{Policy: mustPolicy(t, tt.policy)}, true)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid network policy")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestNetworkPolicyService_ApplyAndLookup(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	setupPolicyPorts(mockOVN)
+	service := NewNetworkPolicyService(mockOVN, zap.NewNop())
+
+	owner := map[string]string{NetworkPolicyOwnerKey: "prod/web"}
+	mockOVN.On("ReplaceOwnedObjects", mock.Anything, owner, mock.Anything, mock.Anything).
+		Return(&ovn.OwnedObjects{
+			PortGroups:  []*models.PortGroup{{UUID: "pg-uuid", Name: networkPolicyBaseName("prod/web")}},
+			AddressSets: []*models.AddressSet{},
+		}, nil)
+
+	policy := mustPolicy(t, `{"metadata": {"name": "web", "namespace": "prod"}, "spec": {"podSelector": {"matchLabels": {"app": "web"}}}}`)
+	objects, err := service.Apply(context.Background(), &NetworkPolicyRequest{Policy: policy}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-uuid", objects.PortGroups[0].PortGroup.UUID)
+	// No ingress rules: only the default deny
+	require.Len(t, objects.PortGroups[0].ACLs, 1)
+	assert.Equal(t, "drop", objects.PortGroups[0].ACLs[0].Action)
+
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{
+		{UUID: "pg-uuid", Name: "np_web", ExternalIDs: owner},
+		{UUID: "other-uuid", Name: "unrelated"},
+	}, nil)
+	mockOVN.On("ListAddressSets", mock.Anything).Return([]*models.AddressSet{
+		{UUID: "as-uuid", Name: "np_web_ingress_0_v4", ExternalIDs: owner},
+	}, nil)
+	mockOVN.On("ListPortGroupACLs", mock.Anything, "pg-uuid").Return([]*models.ACL{
+		{UUID: "acl-uuid", Priority: networkPolicyDenyPriority, Action: "drop"},
+	}, nil)
+
+	found, err := service.Get(context.Background(), "prod", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "prod/web", found.Policy)
+	require.Len(t, found.PortGroups, 1)
+	assert.Equal(t, "acl-uuid", found.PortGroups[0].ACLs[0].UUID)
+	require.Len(t, found.AddressSets, 1)
+
+	_, err = service.Get(context.Background(), "prod", "missing")
+	assert.EqualError(t, err, "network policy prod/missing not found")
+
+	mockOVN.On("ReplaceOwnedObjects", mock.Anything, owner, []*models.AddressSet(nil), []*ovn.PortGroupSpec(nil)).
+		Return(&ovn.OwnedObjects{}, nil)
+	require.NoError(t, service.Delete(context.Background(), "prod", "web"))
+	mockOVN.AssertCalled(t, "ReplaceOwnedObjects", mock.Anything, owner, []*models.AddressSet(nil), []*ovn.PortGroupSpec(nil))
+}
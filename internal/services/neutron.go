@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ErrNeutronResourceNotFound is returned when no OVN object maps to a
+// Neutron ID
+var ErrNeutronResourceNotFound = errors.New("neutron resource not found")
+
+// Names and external_ids the Neutron ML2/OVN driver gives the objects it
+// creates. Switches and routers are named neutron-<network or router ID>,
+// switch ports by their Neutron port ID and router ports lrp-<port ID>.
+const (
+	neutronNamePrefix       = "neutron-"
+	neutronRouterPortPrefix = "lrp-"
+	neutronKeyPrefix        = "neutron:"
+
+	neutronNetworkNameKey    = "neutron:network_name"
+	neutronRouterNameKey     = "neutron:router_name"
+	neutronPortNameKey       = "neutron:port_name"
+	neutronProjectIDKey      = "neutron:project_id"
+	neutronDeviceIDKey       = "neutron:device_id"
+	neutronDeviceOwnerKey    = "neutron:device_owner"
+	neutronHostIDKey         = "neutron:host_id"
+	neutronCIDRsKey          = "neutron:cidrs"
+	neutronSecurityGroupsKey = "neutron:security_group_ids"
+	neutronSubnetIDsKey      = "neutron:subnet_ids"
+	neutronMTUKey            = "neutron:mtu"
+	neutronRevisionKey       = "neutron:revision_number"
+	neutronGatewayPortKey    = "neutron:gw_port_id"
+	neutronRouterPortOption  = "router-port"
+)
+
+// NeutronNetwork is a Neutron network and the logical switch implementing it
+type NeutronNetwork struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name,omitempty"`
+	MTU            string        `json:"mtu,omitempty"`
+	RevisionNumber string        `json:"revision_number,omitempty"`
+	SwitchID       string        `json:"switch_id"`
+	SwitchName     string        `json:"switch_name"`
+	Routers        []string      `json:"routers,omitempty"` // Neutron IDs of the routers with an interface on it
+	Ports          []NeutronPort `json:"ports"`
+}
+
+// NeutronPort is a Neutron port and the logical switch port implementing it
+type NeutronPort struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name,omitempty"`
+	NetworkID        string   `json:"network_id"`
+	ProjectID        string   `json:"project_id,omitempty"`
+	DeviceID         string   `json:"device_id,omitempty"`
+	DeviceOwner      string   `json:"device_owner,omitempty"`
+	HostID           string   `json:"host_id,omitempty"`
+	CIDRs            []string `json:"cidrs,omitempty"`
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty"`
+	PortID           string   `json:"port_id"`             // UUID of the logical switch port
+	Type             string   `json:"type,omitempty"`      // OVN port type, e.g. router or localnet
+	Addresses        []string `json:"addresses,omitempty"` // OVN addresses
+	Up               *bool    `json:"up,omitempty"`
+}
+
+// NeutronRouterInterface is a router port attaching a Neutron router to a
+// network
+type NeutronRouterInterface struct {
+	PortID       string   `json:"port_id"` // Neutron port ID
+	NetworkID    string   `json:"network_id,omitempty"`
+	SubnetIDs    []string `json:"subnet_ids,omitempty"`
+	Networks     []string `json:"networks,omitempty"` // OVN router port networks
+	MAC          string   `json:"mac,omitempty"`
+	RouterPortID string   `json:"router_port_id"` // UUID of the logical router port
+	Gateway      bool     `json:"gateway,omitempty"`
+}
+
+// NeutronRouter is a Neutron router and the logical router implementing it
+type NeutronRouter struct {
+	ID                string                   `json:"id"`
+	Name              string                   `json:"name,omitempty"`
+	RevisionNumber    string                   `json:"revision_number,omitempty"`
+	GatewayPortID     string                   `json:"gateway_port_id,omitempty"`
+	LogicalRouterID   string                   `json:"logical_router_id"`
+	LogicalRouterName string                   `json:"logical_router_name"`
+	Interfaces        []NeutronRouterInterface `json:"interfaces"`
+}
+
+// NeutronMapper groups the OVN objects Neutron created by the Neutron
+// network, router and port IDs they implement. It is read-only; objects
+// Neutron didn't create are left out.
+type NeutronMapper struct {
+	ovn OVNServiceInterface
+}
+
+// NewNeutronMapper creates a mapper over the OVN service
+func NewNeutronMapper(ovn OVNServiceInterface) *NeutronMapper {
+	return &NeutronMapper{ovn: ovn}
+}
+
+// Networks lists the Neutron networks with their ports, by name then ID
+func (m *NeutronMapper) Networks(ctx context.Context) ([]NeutronNetwork, error) {
+	networks, _, err := m.view(ctx)
+	return networks, err
+}
+
+// Network returns the Neutron network with the ID
+func (m *NeutronMapper) Network(ctx context.Context, id string) (*NeutronNetwork, error) {
+	networks, _, err := m.view(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range networks {
+		if networks[i].ID == id {
+			return &networks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: network %s", ErrNeutronResourceNotFound, id)
+}
+
+// Routers lists the Neutron routers with their interfaces, by name then ID
+func (m *NeutronMapper) Routers(ctx context.Context) ([]NeutronRouter, error) {
+	_, routers, err := m.view(ctx)
+	return routers, err
+}
+
+// Port returns the Neutron port with the ID
+func (m *NeutronMapper) Port(ctx context.Context, id string) (*NeutronPort, error) {
+	networks, _, err := m.view(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		for i := range network.Ports {
+			if network.Ports[i].ID == id {
+				return &network.Ports[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: port %s", ErrNeutronResourceNotFound, id)
+}
+
+// view maps the current topology to Neutron networks and routers
+func (m *NeutronMapper) view(ctx context.Context) ([]NeutronNetwork, []NeutronRouter, error) {
+	topology, err := m.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+	networks, routers := mapNeutronTopology(topology)
+	return networks, routers, nil
+}
+
+// mapNeutronTopology maps the switches and routers named after Neutron IDs
+// to Neutron networks and routers
+func mapNeutronTopology(topology *Topology) ([]NeutronNetwork, []NeutronRouter) {
+	ports := make(map[string]*models.LogicalSwitchPort, len(topology.Ports))
+	for _, port := range topology.Ports {
+		ports[port.UUID] = port
+	}
+
+	// The network of each router port, from the switch port peering it, for
+	// router ports without neutron:network_name
+	routerPortNetwork := make(map[string]string)
+	networks := make([]NeutronNetwork, 0)
+	for _, sw := range topology.Switches {
+		networkID, ok := neutronID(sw.Name)
+		if !ok {
+			continue
+		}
+		network := NeutronNetwork{
+			ID:             networkID,
+			Name:           sw.ExternalIDs[neutronNetworkNameKey],
+			MTU:            sw.ExternalIDs[neutronMTUKey],
+			RevisionNumber: sw.ExternalIDs[neutronRevisionKey],
+			SwitchID:       sw.UUID,
+			SwitchName:     sw.Name,
+			Ports:          make([]NeutronPort, 0, len(sw.Ports)),
+		}
+		for _, portID := range sw.Ports {
+			port, ok := ports[portID]
+			if !ok || !hasNeutronKeys(port.ExternalIDs) {
+				continue
+			}
+			if peer := port.Options[neutronRouterPortOption]; port.Type == "router" && peer != "" {
+				routerPortNetwork[peer] = networkID
+			}
+			network.Ports = append(network.Ports, NeutronPort{
+				ID:               port.Name,
+				Name:             port.ExternalIDs[neutronPortNameKey],
+				NetworkID:        networkID,
+				ProjectID:        port.ExternalIDs[neutronProjectIDKey],
+				DeviceID:         port.ExternalIDs[neutronDeviceIDKey],
+				DeviceOwner:      port.ExternalIDs[neutronDeviceOwnerKey],
+				HostID:           port.ExternalIDs[neutronHostIDKey],
+				CIDRs:            strings.Fields(port.ExternalIDs[neutronCIDRsKey]),
+				SecurityGroupIDs: strings.Fields(port.ExternalIDs[neutronSecurityGroupsKey]),
+				PortID:           port.UUID,
+				Type:             port.Type,
+				Addresses:        port.Addresses,
+				Up:               port.Up,
+			})
+		}
+		sort.Slice(network.Ports, func(i, j int) bool {
+			return network.Ports[i].ID < network.Ports[j].ID
+		})
+		networks = append(networks, network)
+	}
+
+	routerPorts := make(map[string]*models.LogicalRouterPort, len(topology.RouterPorts))
+	for _, lrp := range topology.RouterPorts {
+		routerPorts[lrp.UUID] = lrp
+	}
+
+	networkRouters := make(map[string][]string)
+	routers := make([]NeutronRouter, 0)
+	for _, lr := range topology.Routers {
+		routerID, ok := neutronID(lr.Name)
+		if !ok {
+			continue
+		}
+		router := NeutronRouter{
+			ID:                routerID,
+			Name:              lr.ExternalIDs[neutronRouterNameKey],
+			RevisionNumber:    lr.ExternalIDs[neutronRevisionKey],
+			GatewayPortID:     lr.ExternalIDs[neutronGatewayPortKey],
+			LogicalRouterID:   lr.UUID,
+			LogicalRouterName: lr.Name,
+			Interfaces:        make([]NeutronRouterInterface, 0, len(lr.Ports)),
+		}
+		for _, portID := range lr.Ports {
+			lrp, ok := routerPorts[portID]
+			if !ok || !strings.HasPrefix(lrp.Name, neutronRouterPortPrefix) {
+				continue
+			}
+			networkID, ok := neutronID(lrp.ExternalIDs[neutronNetworkNameKey])
+			if !ok {
+				networkID = routerPortNetwork[lrp.Name]
+			}
+			neutronPortID := strings.TrimPrefix(lrp.Name, neutronRouterPortPrefix)
+			router.Interfaces = append(router.Interfaces, NeutronRouterInterface{
+				PortID:       neutronPortID,
+				NetworkID:    networkID,
+				SubnetIDs:    strings.Fields(lrp.ExternalIDs[neutronSubnetIDsKey]),
+				Networks:     lrp.Networks,
+				MAC:          lrp.MAC,
+				RouterPortID: lrp.UUID,
+				Gateway:      neutronPortID == router.GatewayPortID,
+			})
+			if networkID != "" && !containsString(networkRouters[networkID], routerID) {
+				networkRouters[networkID] = append(networkRouters[networkID], routerID)
+			}
+		}
+		sort.Slice(router.Interfaces, func(i, j int) bool {
+			return router.Interfaces[i].PortID < router.Interfaces[j].PortID
+		})
+		routers = append(routers, router)
+	}
+
+	for i := range networks {
+		if attached := networkRouters[networks[i].ID]; len(attached) > 0 {
+			sort.Strings(attached)
+			networks[i].Routers = attached
+		}
+	}
+
+	sort.Slice(networks, func(i, j int) bool {
+		if networks[i].Name != networks[j].Name {
+			return networks[i].Name < networks[j].Name
+		}
+		return networks[i].ID < networks[j].ID
+	})
+	sort.Slice(routers, func(i, j int) bool {
+		if routers[i].Name != routers[j].Name {
+			return routers[i].Name < routers[j].Name
+		}
+		return routers[i].ID < routers[j].ID
+	})
+	return networks, routers
+}
+
+// neutronID returns the Neutron ID of an object named neutron-<ID>
+func neutronID(name string) (string, bool) {
+	id := strings.TrimPrefix(name, neutronNamePrefix)
+	if id == name || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// hasNeutronKeys reports whether Neutron set any external_ids, i.e. whether
+// the object implements a Neutron resource
+func hasNeutronKeys(externalIDs map[string]string) bool {
+	for key := range externalIDs {
+		if strings.HasPrefix(key, neutronKeyPrefix) {
+			return true
+		}
+	}
+	return false
+}
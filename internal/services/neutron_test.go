@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// neutronTopology is a network with a VM port and a router interface, the
+// router's gateway on an external network, and a switch Neutron didn't
+// create
+func neutronTopology() *Topology {
+	return &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "ls-private", Name: "neutron-net-private", Ports: []string{"lsp-vm", "lsp-rtr", "lsp-localnet"},
+				ExternalIDs: map[string]string{"neutron:network_name": "private", "neutron:mtu": "1442"}},
+			{UUID: "ls-public", Name: "neutron-net-public", Ports: []string{"lsp-gw"},
+				ExternalIDs: map[string]string{"neutron:network_name": "public"}},
+			{UUID: "ls-manual", Name: "manual", Ports: []string{"lsp-manual"}},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "lsp-vm", Name: "port-vm", Addresses: []string{"fa:16:3e:00:00:01 10.0.0.5"}, ExternalIDs: map[string]string{
+				"neutron:port_name":          "vm-1",
+				"neutron:project_id":         "proj-a",
+				"neutron:device_id":          "instance-1",
+				"neutron:device_owner":       "compute:nova",
+				"neutron:cidrs":              "10.0.0.5/24",
+				"neutron:security_group_ids": "sg-1 sg-2",
+			}},
+			{UUID: "lsp-rtr", Name: "port-rtr", Type: "router", Options: map[string]string{"router-port": "lrp-port-rtr"},
+				ExternalIDs: map[string]string{"neutron:device_owner": "network:router_interface"}},
+			{UUID: "lsp-localnet", Name: "provnet-1", Type: "localnet"},
+			{UUID: "lsp-gw", Name: "port-gw", Type: "router",
+				ExternalIDs: map[string]string{"neutron:device_owner": "network:router_gateway"}},
+			{UUID: "lsp-manual", Name: "manual-1"},
+		},
+		Routers: []*models.LogicalRouter{
+			{UUID: "lr-1", Name: "neutron-router-1", Ports: []string{"lrp-1", "lrp-2"},
+				ExternalIDs: map[string]string{"neutron:router_name": "edge", "neutron:gw_port_id": "port-gw"}},
+			{UUID: "lr-manual", Name: "manual"},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			// Without neutron:network_name, the network is found from the
+			// peering switch port
+			{UUID: "lrp-1", Name: "lrp-port-rtr", MAC: "fa:16:3e:00:00:ff", Networks: []string{"10.0.0.1/24"},
+				ExternalIDs: map[string]string{"neutron:subnet_ids": "subnet-1"}},
+			{UUID: "lrp-2", Name: "lrp-port-gw", Networks: []string{"172.24.4.10/24"},
+				ExternalIDs: map[string]string{"neutron:network_name": "neutron-net-public"}},
+		},
+	}
+}
+
+func TestNeutronMapper(t *testing.T) {
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(neutronTopology(), nil)
+	mapper := NewNeutronMapper(mockService)
+	ctx := context.Background()
+
+	networks, err := mapper.Networks(ctx)
+	require.NoError(t, err)
+	require.Len(t, networks, 2)
+	private := networks[0]
+	assert.Equal(t, "net-private", private.ID)
+	assert.Equal(t, "private", private.Name)
+	assert.Equal(t, "1442", private.MTU)
+	assert.Equal(t, "ls-private", private.SwitchID)
+	assert.Equal(t, []string{"router-1"}, private.Routers)
+	// The localnet port isn't a Neutron port
+	require.Len(t, private.Ports, 2)
+	assert.Equal(t, NeutronPort{
+		ID:               "port-vm",
+		Name:             "vm-1",
+		NetworkID:        "net-private",
+		ProjectID:        "proj-a",
+		DeviceID:         "instance-1",
+		DeviceOwner:      "compute:nova",
+		CIDRs:            []string{"10.0.0.5/24"},
+		SecurityGroupIDs: []string{"sg-1", "sg-2"},
+		PortID:           "lsp-vm",
+		Addresses:        []string{"fa:16:3e:00:00:01 10.0.0.5"},
+	}, private.Ports[1])
+	assert.Equal(t, "public", networks[1].Name)
+
+	routers, err := mapper.Routers(ctx)
+	require.NoError(t, err)
+	require.Len(t, routers, 1)
+	assert.Equal(t, "router-1", routers[0].ID)
+	assert.Equal(t, "edge", routers[0].Name)
+	assert.Equal(t, "lr-1", routers[0].LogicalRouterID)
+	assert.Equal(t, []NeutronRouterInterface{
+		{PortID: "port-gw", NetworkID: "net-public", SubnetIDs: []string{}, Networks: []string{"172.24.4.10/24"}, RouterPortID: "lrp-2", Gateway: true},
+		{PortID: "port-rtr", NetworkID: "net-private", SubnetIDs: []string{"subnet-1"}, Networks: []string{"10.0.0.1/24"}, MAC: "fa:16:3e:00:00:ff", RouterPortID: "lrp-1"},
+	}, routers[0].Interfaces)
+
+	port, err := mapper.Port(ctx, "port-vm")
+	require.NoError(t, err)
+	assert.Equal(t, "net-private", port.NetworkID)
+
+	_, err = mapper.Port(ctx, "manual-1")
+	assert.ErrorIs(t, err, ErrNeutronResourceNotFound)
+	_, err = mapper.Network(ctx, "manual")
+	assert.ErrorIs(t, err, ErrNeutronResourceNotFound)
+}
+
+func TestNeutronMapper_TopologyError(t *testing.T) {
+	mockService := new(MockOVNService)
+	mockService.On("GetTopology", mock.Anything).Return(nil, fmt.Errorf("client not connected"))
+
+	_, err := NewNeutronMapper(mockService).Networks(context.Background())
+	assert.ErrorContains(t, err, "not connected")
+}
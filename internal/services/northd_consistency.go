@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// DefaultNorthdStuckThreshold is how long a northbound object may wait for
+// ovn-northd to realize it before northd is reported stuck
+const DefaultNorthdStuckThreshold = 30 * time.Second
+
+// Status of a northd consistency check
+const (
+	NorthdConsistent = "consistent" // Everything is realized
+	NorthdPending    = "pending"    // Realization is in progress
+	NorthdStuck      = "stuck"      // Something has been pending past the threshold
+)
+
+// NorthboundIntentReader lists what ovn-northd should realize, as
+// *ovn.Client does
+type NorthboundIntentReader interface {
+	NorthboundObjects(ctx context.Context) ([]ovn.NorthboundObject, error)
+	NorthdStatus(ctx context.Context) (*ovn.NorthdStatus, error)
+}
+
+// SouthboundRealizationReader reads what ovn-northd realized, as
+// *ovn.ChassisInventory does
+type SouthboundRealizationReader interface {
+	Realization(ctx context.Context) (*ovn.SouthboundRealization, error)
+}
+
+// PendingObject is a northbound object without its southbound counterpart
+type PendingObject struct {
+	ovn.NorthboundObject
+	// PendingSince is when the object was created, or when a check first
+	// found it pending for objects created outside ovncp
+	PendingSince   time.Time `json:"pending_since"`
+	PendingSeconds float64   `json:"pending_seconds"`
+	Stuck          bool      `json:"stuck"`
+}
+
+// NorthdConsistencyReport compares the northbound intent to its southbound
+// realization
+type NorthdConsistencyReport struct {
+	CheckedAt        time.Time         `json:"checked_at"`
+	Status           string            `json:"status"`
+	Summary          string            `json:"summary"`
+	ThresholdSeconds float64           `json:"threshold_seconds"`
+	Northd           *ovn.NorthdStatus `json:"northd,omitempty"`
+	NorthdError      string            `json:"northd_error,omitempty"`
+	// SbLagSeconds is how long the southbound database has been behind
+	// nb_cfg, 0 when it caught up
+	SbLagSeconds float64         `json:"sb_lag_seconds"`
+	Checked      int             `json:"checked"`
+	Pending      []PendingObject `json:"pending"`
+	Stuck        int             `json:"stuck"`
+}
+
+// NorthdConsistencyChecker compares the northbound switches, routers and
+// ports to the datapaths and port bindings ovn-northd created for them in
+// the southbound database, to catch a stuck ovn-northd
+type NorthdConsistencyChecker struct {
+	nb  NorthboundIntentReader
+	sb  SouthboundRealizationReader
+	now func() time.Time
+
+	// firstSeen is when a check first found each object pending, by UUID
+	mu        sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+// NewNorthdConsistencyChecker creates a checker comparing nb to sb
+func NewNorthdConsistencyChecker(nb NorthboundIntentReader, sb SouthboundRealizationReader) *NorthdConsistencyChecker {
+	return &NorthdConsistencyChecker{
+		nb:        nb,
+		sb:        sb,
+		now:       time.Now,
+		firstSeen: make(map[string]time.Time),
+	}
+}
+
+// Check reports the northbound objects not realized yet and how long they
+// have been pending. ovn-northd is stuck when an object has been pending,
+// or the southbound database behind nb_cfg, for longer than threshold.
+func (c *NorthdConsistencyChecker) Check(ctx context.Context, threshold time.Duration) (*NorthdConsistencyReport, error) {
+	if threshold <= 0 {
+		threshold = DefaultNorthdStuckThreshold
+	}
+
+	objects, err := c.nb.NorthboundObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	realization, err := c.sb.Realization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the southbound database: %w", err)
+	}
+
+	now := c.now()
+	report := &NorthdConsistencyReport{
+		CheckedAt:        now.UTC(),
+		ThresholdSeconds: threshold.Seconds(),
+		Checked:          len(objects),
+		Pending:          []PendingObject{},
+	}
+	if northd, err := c.nb.NorthdStatus(ctx); err != nil {
+		report.NorthdError = err.Error()
+	} else {
+		report.Northd = northd
+		if northd.SbLag > 0 && northd.NbCfgTimestamp != nil {
+			report.SbLagSeconds = roundSeconds(now.Sub(*northd.NbCfgTimestamp))
+		}
+	}
+
+	c.mu.Lock()
+	pending := make(map[string]bool)
+	for _, object := range objects {
+		var realized bool
+		switch object.Type {
+		case ovn.NorthboundLogicalSwitch, ovn.NorthboundLogicalRouter:
+			realized = realization.Datapaths[object.UUID]
+		default:
+			realized = realization.Ports[object.Name]
+		}
+		if realized {
+			continue
+		}
+
+		pending[object.UUID] = true
+		since, seen := c.firstSeen[object.UUID]
+		if !seen {
+			since = now
+			c.firstSeen[object.UUID] = now
+		}
+		if object.CreatedAt != nil {
+			since = *object.CreatedAt
+		}
+		item := PendingObject{
+			NorthboundObject: object,
+			PendingSince:     since.UTC(),
+			PendingSeconds:   roundSeconds(now.Sub(since)),
+		}
+		item.Stuck = now.Sub(since) > threshold
+		if item.Stuck {
+			report.Stuck++
+		}
+		report.Pending = append(report.Pending, item)
+	}
+	// Forget the objects realized or deleted since
+	for uuid := range c.firstSeen {
+		if !pending[uuid] {
+			delete(c.firstSeen, uuid)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(report.Pending, func(i, j int) bool {
+		if !report.Pending[i].PendingSince.Equal(report.Pending[j].PendingSince) {
+			return report.Pending[i].PendingSince.Before(report.Pending[j].PendingSince)
+		}
+		return report.Pending[i].UUID < report.Pending[j].UUID
+	})
+
+	lagging := report.SbLagSeconds > threshold.Seconds()
+	switch {
+	case report.Stuck > 0 || lagging:
+		var reasons []string
+		if report.Stuck > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d objects pending for more than %s", report.Stuck, threshold))
+		}
+		if lagging {
+			reasons = append(reasons, fmt.Sprintf("southbound database %d configurations behind for %.0fs", report.Northd.SbLag, report.SbLagSeconds))
+		}
+		report.Status = NorthdStuck
+		report.Summary = "ovn-northd looks stuck: " + strings.Join(reasons, "; ")
+	case len(report.Pending) > 0:
+		report.Status = NorthdPending
+		report.Summary = fmt.Sprintf("%d of %d objects not realized yet", len(report.Pending), report.Checked)
+	case report.SbLagSeconds > 0:
+		report.Status = NorthdPending
+		report.Summary = fmt.Sprintf("southbound database %d configurations behind", report.Northd.SbLag)
+	default:
+		report.Status = NorthdConsistent
+		report.Summary = fmt.Sprintf("all %d objects realized", report.Checked)
+	}
+	return report, nil
+}
+
+// roundSeconds returns d in seconds, rounded to milliseconds
+func roundSeconds(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+	return d.Round(time.Millisecond).Seconds()
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+type fakeNorthboundIntent struct {
+	objects []ovn.NorthboundObject
+	northd  *ovn.NorthdStatus
+}
+
+func (f *fakeNorthboundIntent) NorthboundObjects(ctx context.Context) ([]ovn.NorthboundObject, error) {
+	return f.objects, nil
+}
+
+func (f *fakeNorthboundIntent) NorthdStatus(ctx context.Context) (*ovn.NorthdStatus, error) {
+	if f.northd == nil {
+		return nil, errors.New("NB_Global not found")
+	}
+	return f.northd, nil
+}
+
+type fakeRealization struct {
+	realization *ovn.SouthboundRealization
+	err         error
+}
+
+func (f *fakeRealization) Realization(ctx context.Context) (*ovn.SouthboundRealization, error) {
+	return f.realization, f.err
+}
+
+func TestNorthdConsistencyChecker_Check(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	createdAt := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+
+	nb := &fakeNorthboundIntent{objects: []ovn.NorthboundObject{
+		{Type: ovn.NorthboundLogicalSwitch, UUID: "ls-1", Name: "web", CreatedAt: createdAt(time.Hour)},
+		{Type: ovn.NorthboundLogicalSwitchPort, UUID: "lsp-1", Name: "web-1", CreatedAt: createdAt(time.Hour)},
+		{Type: ovn.NorthboundLogicalRouter, UUID: "lr-1", Name: "edge", CreatedAt: createdAt(5 * time.Second)},
+		{Type: ovn.NorthboundLogicalRouterPort, UUID: "lrp-1", Name: "edge-web"},
+	}}
+	sb := &fakeRealization{realization: &ovn.SouthboundRealization{
+		Datapaths: map[string]bool{"ls-1": true},
+		Ports:     map[string]bool{"web-1": true},
+	}}
+	checker := NewNorthdConsistencyChecker(nb, sb)
+	checker.now = func() time.Time { return now }
+
+	report, err := checker.Check(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, NorthdPending, report.Status)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, float64(30), report.ThresholdSeconds)
+	assert.Equal(t, "NB_Global not found", report.NorthdError)
+	require.Len(t, report.Pending, 2)
+	// The router is pending since it was created, the port created outside
+	// ovncp since the check found it
+	assert.Equal(t, "edge", report.Pending[0].Name)
+	assert.Equal(t, float64(5), report.Pending[0].PendingSeconds)
+	assert.Equal(t, "edge-web", report.Pending[1].Name)
+	assert.Equal(t, now, report.Pending[1].PendingSince)
+	assert.Zero(t, report.Stuck)
+
+	// A minute later, both have been pending past the threshold
+	now = now.Add(time.Minute)
+	report, err = checker.Check(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, NorthdStuck, report.Status)
+	assert.Equal(t, 2, report.Stuck)
+	assert.Equal(t, float64(60), report.Pending[1].PendingSeconds)
+	assert.Equal(t, "ovn-northd looks stuck: 2 objects pending for more than 30s", report.Summary)
+
+	// Once realized, they are forgotten
+	sb.realization.Datapaths["lr-1"] = true
+	sb.realization.Ports["edge-web"] = true
+	report, err = checker.Check(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, NorthdConsistent, report.Status)
+	assert.Empty(t, report.Pending)
+	assert.Empty(t, checker.firstSeen)
+}
+
+func TestNorthdConsistencyChecker_SouthboundLag(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bumped := now.Add(-2 * time.Minute)
+	nb := &fakeNorthboundIntent{northd: &ovn.NorthdStatus{NbCfg: 12, SbCfg: 9, SbLag: 3, NbCfgTimestamp: &bumped}}
+	sb := &fakeRealization{realization: &ovn.SouthboundRealization{}}
+	checker := NewNorthdConsistencyChecker(nb, sb)
+	checker.now = func() time.Time { return now }
+
+	report, err := checker.Check(context.Background(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, NorthdStuck, report.Status)
+	assert.Equal(t, float64(120), report.SbLagSeconds)
+	assert.Equal(t, "ovn-northd looks stuck: southbound database 3 configurations behind for 120s", report.Summary)
+
+	report, err = checker.Check(context.Background(), 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, NorthdPending, report.Status)
+}
+
+func TestNorthdConsistencyChecker_SouthboundUnavailable(t *testing.T) {
+	checker := NewNorthdConsistencyChecker(&fakeNorthboundIntent{},
+		&fakeRealization{err: errors.New("failed to connect to OVN southbound DB: connection refused")})
+
+	_, err := checker.Check(context.Background(), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "southbound")
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Types of notification channels
+const (
+	NotificationSlack = "slack"
+	NotificationTeams = "teams"
+	NotificationEmail = "email"
+)
+
+// ErrNotificationChannelNotFound is returned for unknown channel names
+var ErrNotificationChannelNotFound = errors.New("notification channel not found")
+
+// EventNotificationTest is the type of the events sent to test channels
+const EventNotificationTest = "notification.test"
+
+// notificationQueueSize is how many events may wait to be sent before new
+// ones are dropped
+const notificationQueueSize = 256
+
+// Default templates of messages and email subjects
+const (
+	defaultNotificationTemplate = `{{.Summary}}
+Event: {{.Event.Type}}{{if .Event.TenantID}}
+Tenant: {{.Event.TenantID}}{{end}}
+Time: {{.Event.OccurredAt.Format "2006-01-02 15:04:05 MST"}}`
+	defaultNotificationSubject = `[ovncp] {{.Summary}}`
+)
+
+// NotificationChannel sends messages about events to a Slack or Microsoft
+// Teams incoming webhook, or by email
+type NotificationChannel struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`               // slack, teams or email
+	URL      string   `json:"url,omitempty"`      // Incoming webhook of slack and teams channels
+	To       []string `json:"to,omitempty"`       // Recipients of email channels
+	Events   []string `json:"events,omitempty"`   // Event types sent; all if empty
+	Tenants  []string `json:"tenants,omitempty"`  // Tenants whose events are sent; all tenants' and system events if empty
+	Template string   `json:"template,omitempty"` // text/template of messages over a NotificationMessage
+	Subject  string   `json:"subject,omitempty"`  // text/template of email subjects and Teams titles
+
+	template *template.Template
+	subject  *template.Template
+}
+
+// NotificationMessage is what message templates are executed over
+type NotificationMessage struct {
+	Event   *models.Event
+	Summary string // One line describing the event
+}
+
+// sends reports whether the channel sends event
+func (c *NotificationChannel) sends(event *models.Event) bool {
+	if event.Type == EventNotificationTest {
+		return false
+	}
+	if len(c.Events) > 0 && !containsString(c.Events, event.Type) {
+		return false
+	}
+	return len(c.Tenants) == 0 || (event.TenantID != "" && containsString(c.Tenants, event.TenantID))
+}
+
+// SMTPConfig is the mail server email channels send through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Authenticates with PLAIN if set
+	Password string
+	From     string
+}
+
+// LoadNotificationChannels reads channels from a JSON file of
+// {"channels": [...]}
+func LoadNotificationChannels(path string) ([]*NotificationChannel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification channels: %w", err)
+	}
+	var file struct {
+		Channels []*NotificationChannel `json:"channels"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse notification channels: %w", err)
+	}
+	return file.Channels, nil
+}
+
+// NotificationService sends events to the notification channels they pass
+// the filters of. Events are queued on publication and sent in the
+// background; failed messages are logged, not retried.
+type NotificationService struct {
+	channels []*NotificationChannel
+	smtp     SMTPConfig
+	client   *http.Client
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+	queue    chan *models.Event
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewNotificationService creates a service sending to channels, checking
+// them and parsing their templates
+func NewNotificationService(channels []*NotificationChannel, smtpConfig SMTPConfig, logger *zap.Logger) (*NotificationService, error) {
+	names := make(map[string]bool, len(channels))
+	for _, channel := range channels {
+		if channel.Name == "" {
+			return nil, fmt.Errorf("notification channel without a name")
+		}
+		if names[channel.Name] {
+			return nil, fmt.Errorf("notification channel %s is defined twice", channel.Name)
+		}
+		names[channel.Name] = true
+		if err := prepareNotificationChannel(channel, smtpConfig); err != nil {
+			return nil, fmt.Errorf("notification channel %s: %w", channel.Name, err)
+		}
+	}
+
+	return &NotificationService{
+		channels: channels,
+		smtp:     smtpConfig,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		sendMail: smtp.SendMail,
+		queue:    make(chan *models.Event, notificationQueueSize),
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+func prepareNotificationChannel(channel *NotificationChannel, smtpConfig SMTPConfig) error {
+	switch channel.Type {
+	case NotificationSlack, NotificationTeams:
+		u, err := url.Parse(channel.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+	case NotificationEmail:
+		if len(channel.To) == 0 {
+			return fmt.Errorf("email channels need recipients")
+		}
+		if smtpConfig.Host == "" || smtpConfig.From == "" {
+			return fmt.Errorf("email channels need SMTP_HOST and SMTP_FROM")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, must be slack, teams or email", channel.Type)
+	}
+	for _, eventType := range channel.Events {
+		if !containsString(models.EventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+
+	text, subject := channel.Template, channel.Subject
+	if text == "" {
+		text = defaultNotificationTemplate
+	}
+	if subject == "" {
+		subject = defaultNotificationSubject
+	}
+	var err error
+	if channel.template, err = template.New(channel.Name).Parse(text); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	if channel.subject, err = template.New(channel.Name + "-subject").Parse(subject); err != nil {
+		return fmt.Errorf("invalid subject: %w", err)
+	}
+	return nil
+}
+
+// Channels lists the channels
+func (s *NotificationService) Channels() []*NotificationChannel {
+	return s.channels
+}
+
+// Publish queues event for the channels, dropping it if the queue is full
+func (s *NotificationService) Publish(ctx context.Context, event *models.Event) {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.now().UTC()
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		s.logger.Warn("Notification queue full, dropping event",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type))
+	}
+}
+
+// Run sends the queued events until ctx is done
+func (s *NotificationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.queue:
+			s.Notify(ctx, event)
+		}
+	}
+}
+
+// Notify sends event to the channels it passes the filters of, now
+func (s *NotificationService) Notify(ctx context.Context, event *models.Event) {
+	for _, channel := range s.channels {
+		if !channel.sends(event) {
+			continue
+		}
+		if err := s.send(ctx, channel, event); err != nil {
+			s.logger.Warn("Failed to send notification",
+				zap.String("channel", channel.Name),
+				zap.String("event_id", event.ID),
+				zap.String("event_type", event.Type),
+				zap.Error(err))
+		}
+	}
+}
+
+// Test sends a test message to a channel
+func (s *NotificationService) Test(ctx context.Context, name string) error {
+	for _, channel := range s.channels {
+		if channel.Name == name {
+			return s.send(ctx, channel, &models.Event{
+				ID:         uuid.New().String(),
+				Type:       EventNotificationTest,
+				OccurredAt: s.now().UTC(),
+				Data:       map[string]interface{}{"channel": name},
+			})
+		}
+	}
+	return ErrNotificationChannelNotFound
+}
+
+func (s *NotificationService) send(ctx context.Context, channel *NotificationChannel, event *models.Event) error {
+	message := NotificationMessage{Event: event, Summary: eventSummary(event)}
+	var text, subject bytes.Buffer
+	if err := channel.template.Execute(&text, message); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if err := channel.subject.Execute(&subject, message); err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+	// Subjects are single lines
+	title, _, _ := strings.Cut(subject.String(), "\n")
+
+	switch channel.Type {
+	case NotificationSlack:
+		return s.post(ctx, channel.URL, map[string]interface{}{"text": text.String()})
+	case NotificationTeams:
+		return s.post(ctx, channel.URL, map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  title,
+			"title":    title,
+			"text":     text.String(),
+		})
+	default:
+		return s.email(channel.To, title, text.String())
+	}
+}
+
+// post posts payload to an incoming webhook, failing on responses other
+// than 2xx
+func (s *NotificationService) post(ctx context.Context, webhookURL string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// email sends a plain text email through the SMTP server
+func (s *NotificationService) email(to []string, subject, text string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if s.smtp.Username != "" {
+		auth = smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, s.smtp.Host)
+	}
+	addr := net.JoinHostPort(s.smtp.Host, strconv.Itoa(s.smtp.Port))
+	return s.sendMail(addr, auth, s.smtp.From, to, msg.Bytes())
+}
+
+// driftScope names what a drift event is about
+func driftScope(event *models.Event) string {
+	if event.TenantID != "" {
+		return "tenant " + event.TenantID
+	}
+	return "the cluster"
+}
+
+// eventSummary describes an event in one line
+func eventSummary(event *models.Event) string {
+	data := event.Data
+	switch event.Type {
+	case models.EventResourceCreated, models.EventResourceUpdated, models.EventResourceDeleted:
+		summary := fmt.Sprintf("%v", data["resource"])
+		if id, ok := data["resource_id"]; ok {
+			summary += fmt.Sprintf(" %v", id)
+		}
+		summary += " " + strings.TrimPrefix(event.Type, "resource.")
+		if user, ok := data["user_id"]; ok {
+			summary += fmt.Sprintf(" by %v", user)
+		}
+		return summary
+	case models.EventBackupCompleted:
+		return fmt.Sprintf("Backup %v completed", data["name"])
+	case models.EventBackupFailed:
+		return fmt.Sprintf("Backup %v failed: %v", data["name"], data["error"])
+	case models.EventRestoreCompleted:
+		return fmt.Sprintf("Backup %v restored, %v resources restored", data["backup_id"], data["restored"])
+	case models.EventRestoreFailed:
+		return fmt.Sprintf("Restore of backup %v failed: %v", data["backup_id"], data["error"])
+	case models.EventQuotaThreshold:
+		return fmt.Sprintf("Tenant %s reached %v%% of its %v quota (%v of %v)",
+			event.TenantID, data["threshold_percent"], data["resource"], data["usage"], data["limit"])
+	case models.EventQuotaExhausted:
+		return fmt.Sprintf("Tenant %s exhausted its %v quota (%v of %v)",
+			event.TenantID, data["resource"], data["usage"], data["limit"])
+	case models.EventOVNDisconnected:
+		return fmt.Sprintf("Lost the connection to OVN cluster %v: %v", data["cluster"], data["last_error"])
+	case models.EventOVNReconnected:
+		return fmt.Sprintf("Reconnected to OVN cluster %v", data["cluster"])
+	case models.EventDriftDetected:
+		return fmt.Sprintf("Configuration of %v drifted from golden backup %v: %v added, %v removed, %v modified",
+			driftScope(event), data["golden_backup_id"], data["added"], data["removed"], data["modified"])
+	case models.EventDriftResolved:
+		return fmt.Sprintf("Configuration of %v matches golden backup %v again", driftScope(event), data["golden_backup_id"])
+	case models.EventACLRolloutPromoted:
+		return fmt.Sprintf("ACL rollout %v promoted: %v enforced on switch %v", data["rollout_id"], data["acls"], data["switch_id"])
+	case models.EventACLRolloutRolledBack:
+		return fmt.Sprintf("ACL rollout %v rolled back from switch %v: %v", data["rollout_id"], data["switch_id"], data["reason"])
+	case models.EventVPNTunnelUp:
+		return fmt.Sprintf("VPN tunnel %v to %v is up", data["name"], data["peer_address"])
+	case models.EventVPNTunnelDown:
+		return fmt.Sprintf("VPN tunnel %v to %v is down", data["name"], data["peer_address"])
+	case EventNotificationTest:
+		return fmt.Sprintf("Test notification to channel %v", data["channel"])
+	}
+	return event.Type
+}
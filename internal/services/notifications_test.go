@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestNewNotificationService_Invalid(t *testing.T) {
+	smtpConfig := SMTPConfig{Host: "mail.example.com", Port: 587, From: "ovncp@example.com"}
+	for name, channel := range map[string]*NotificationChannel{
+		"unknown type":     {Name: "a", Type: "pager"},
+		"relative url":     {Name: "a", Type: NotificationSlack, URL: "/hooks"},
+		"no recipients":    {Name: "a", Type: NotificationEmail},
+		"unknown event":    {Name: "a", Type: NotificationTeams, URL: "https://example.com", Events: []string{"switch.created"}},
+		"invalid template": {Name: "a", Type: NotificationSlack, URL: "https://example.com", Template: "{{.Summary"},
+	} {
+		_, err := NewNotificationService([]*NotificationChannel{channel}, smtpConfig, zap.NewNop())
+		assert.Error(t, err, name)
+	}
+
+	email := &NotificationChannel{Name: "ops", Type: NotificationEmail, To: []string{"ops@example.com"}}
+	_, err := NewNotificationService([]*NotificationChannel{email}, SMTPConfig{}, zap.NewNop())
+	assert.ErrorContains(t, err, "SMTP_HOST", "email channels need a mail server")
+
+	slack := &NotificationChannel{Name: "ops", Type: NotificationSlack, URL: "https://example.com"}
+	_, err = NewNotificationService([]*NotificationChannel{slack, slack}, SMTPConfig{}, zap.NewNop())
+	assert.ErrorContains(t, err, "defined twice")
+}
+
+func TestLoadNotificationChannels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channels.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"channels": [
+		{"name": "ops", "type": "slack", "url": "https://hooks.slack.com/services/x", "events": ["backup.restore_failed"]}
+	]}`), 0o600))
+
+	channels, err := LoadNotificationChannels(path)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, NotificationSlack, channels[0].Type)
+	assert.Equal(t, []string{models.EventRestoreFailed}, channels[0].Events)
+}
+
+func TestNotificationService_Notify(t *testing.T) {
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body["path"] = r.URL.Path
+		received = append(received, body)
+	}))
+	defer server.Close()
+
+	type mail struct {
+		addr string
+		from string
+		to   []string
+		msg  string
+	}
+	var mails []mail
+
+	service, err := NewNotificationService([]*NotificationChannel{
+		{Name: "restores", Type: NotificationSlack, URL: server.URL + "/slack", Events: []string{models.EventRestoreFailed}},
+		{Name: "acme", Type: NotificationTeams, URL: server.URL + "/teams", Tenants: []string{"acme"}},
+		{
+			Name: "quotas", Type: NotificationEmail, To: []string{"ops@example.com", "noc@example.com"},
+			Events:   []string{models.EventQuotaExhausted},
+			Subject:  "Quota: {{index .Event.Data \"resource\"}}",
+			Template: "{{.Summary}} in tenant {{.Event.TenantID}}",
+		},
+	}, SMTPConfig{Host: "mail.example.com", Port: 25, From: "ovncp@example.com"}, zap.NewNop())
+	require.NoError(t, err)
+	service.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, mail{addr, from, to, string(msg)})
+		return nil
+	}
+	ctx := context.Background()
+
+	service.Notify(ctx, &models.Event{
+		Type: models.EventRestoreFailed,
+		Data: map[string]interface{}{"backup_id": "b-1", "error": "backup not found"},
+	})
+	service.Notify(ctx, &models.Event{
+		Type:     models.EventQuotaExhausted,
+		TenantID: "acme",
+		Data:     map[string]interface{}{"resource": "switches", "usage": 10, "limit": 10},
+	})
+	service.Notify(ctx, &models.Event{Type: models.EventQuotaExhausted, TenantID: "other",
+		Data: map[string]interface{}{"resource": "ports", "usage": 5, "limit": 5}})
+
+	// Slack gets the restore, Teams only acme's events, email every exhaustion
+	require.Len(t, received, 2)
+	assert.Equal(t, "/slack", received[0]["path"])
+	assert.Contains(t, received[0]["text"], "Restore of backup b-1 failed: backup not found")
+	assert.Contains(t, received[0]["text"], "Event: backup.restore_failed")
+	assert.Equal(t, "/teams", received[1]["path"])
+	assert.Equal(t, "MessageCard", received[1]["@type"])
+	assert.Equal(t, "[ovncp] Tenant acme exhausted its switches quota (10 of 10)", received[1]["title"])
+
+	require.Len(t, mails, 2)
+	assert.Equal(t, "mail.example.com:25", mails[0].addr)
+	assert.Equal(t, "ovncp@example.com", mails[0].from)
+	assert.Equal(t, []string{"ops@example.com", "noc@example.com"}, mails[0].to)
+	assert.Contains(t, mails[0].msg, "Subject: Quota: switches\r\n")
+	assert.Contains(t, mails[0].msg, "\r\n\r\nTenant acme exhausted its switches quota (10 of 10) in tenant acme\r\n")
+	assert.Contains(t, mails[1].msg, "Subject: Quota: ports\r\n")
+}
+
+func TestNotificationService_PublishAndTest(t *testing.T) {
+	var status, requests atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	service, err := NewNotificationService([]*NotificationChannel{
+		{Name: "ops", Type: NotificationSlack, URL: server.URL},
+	}, SMTPConfig{}, zap.NewNop())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	event := &models.Event{Type: models.EventOVNDisconnected, Data: map[string]interface{}{"cluster": "east"}}
+	service.Publish(ctx, event)
+	assert.NotEmpty(t, event.ID)
+	assert.False(t, event.OccurredAt.IsZero())
+	assert.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, 10*time.Millisecond)
+	cancel()
+
+	require.NoError(t, service.Test(context.Background(), "ops"))
+	assert.Equal(t, int32(2), requests.Load())
+	status.Store(http.StatusForbidden)
+	assert.ErrorContains(t, service.Test(context.Background(), "ops"), "403")
+	assert.ErrorIs(t, service.Test(context.Background(), "missing"), ErrNotificationChannelNotFound)
+}
@@ -0,0 +1,880 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+	"go.uber.org/zap"
+)
+
+// ovnClusterContextKey is the context key holding the selected OVN cluster
+const ovnClusterContextKey = "ovn_cluster"
+
+// OVNCluster is a named OVN deployment with its own client and service
+type OVNCluster struct {
+	Name     string
+	Config   *config.OVNConfig
+	Client   *ovn.Client
+	Service  *OVNService
+	readPool *ovn.PooledClient
+	cancel   context.CancelFunc
+}
+
+// OVNClusterInfo summarizes a cluster for listing
+type OVNClusterInfo struct {
+	Name    string               `json:"name"`
+	Default bool                 `json:"default"`
+	Status  ovn.ConnectionStatus `json:"status"`
+}
+
+// OVNClusterManager owns the clients of all configured OVN clusters
+type OVNClusterManager struct {
+	mu          sync.RWMutex
+	clusters    map[string]*OVNCluster
+	order       []string
+	defaultName string
+	logger      *zap.Logger
+}
+
+// NewOVNClusterManager creates an empty cluster manager
+func NewOVNClusterManager(logger *zap.Logger) *OVNClusterManager {
+	return &OVNClusterManager{
+		clusters: make(map[string]*OVNCluster),
+		logger:   logger,
+	}
+}
+
+// Add creates a client for the cluster and connects it. The first cluster
+// added becomes the default. An unreachable cluster does not fail Add; the
+// connection is retried in the background.
+func (m *OVNClusterManager) Add(cfg *config.OVNConfig) error {
+	m.mu.RLock()
+	_, exists := m.clusters[cfg.ClusterName]
+	m.mu.RUnlock()
+	if exists {
+		return fmt.Errorf("OVN cluster %s already exists", cfg.ClusterName)
+	}
+
+	client, err := ovn.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create OVN client for cluster %s: %w", cfg.ClusterName, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cluster := &OVNCluster{
+		Name:    cfg.ClusterName,
+		Config:  cfg,
+		Client:  client,
+		Service: NewOVNService(client),
+		cancel:  cancel,
+	}
+	logger := m.logger.With(zap.String("cluster", cfg.ClusterName))
+	metrics.RegisterOVSDBCache(cfg.ClusterName, client.CacheStats)
+
+	connectCtx, connectCancel := context.WithTimeout(ctx, cfg.Timeout)
+	err = client.Connect(connectCtx)
+	connectCancel()
+	if err != nil {
+		logger.Warn("Failed to connect to OVN, retrying in background", zap.Error(err))
+		go func() {
+			if err := client.ConnectWithBackoff(ctx); err != nil {
+				logger.Info("Stopped retrying OVN connection", zap.Error(err))
+				return
+			}
+			logger.Info("Connected to OVN northbound database")
+		}()
+	}
+
+	// Serve list operations from dedicated connections so they don't hold up writes
+	if cfg.MaxConnections > 1 {
+		readPool, err := ovn.NewPooledClient(cfg, logger)
+		if err != nil {
+			logger.Warn("Failed to create OVN read pool", zap.Error(err))
+		} else {
+			cluster.readPool = readPool
+			cluster.Service.SetReadPool(readPool)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clusters[cluster.Name] = cluster
+	m.order = append(m.order, cluster.Name)
+	if m.defaultName == "" {
+		m.defaultName = cluster.Name
+	}
+
+	return nil
+}
+
+// Get returns the named cluster
+func (m *OVNClusterManager) Get(name string) (*OVNCluster, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cluster, ok := m.clusters[name]
+	return cluster, ok
+}
+
+// Default returns the default cluster, or nil if none has been added
+func (m *OVNClusterManager) Default() *OVNCluster {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.clusters[m.defaultName]
+}
+
+// Resolve returns the cluster selected in the context, or the default
+// cluster when none is selected
+func (m *OVNClusterManager) Resolve(ctx context.Context) (*OVNCluster, error) {
+	name := getOVNClusterFromContext(ctx)
+	if name == "" {
+		cluster := m.Default()
+		if cluster == nil {
+			return nil, fmt.Errorf("no OVN cluster configured")
+		}
+		return cluster, nil
+	}
+
+	cluster, ok := m.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("OVN cluster %s not found", name)
+	}
+	return cluster, nil
+}
+
+// Capabilities returns the capability matrix of the northbound schema of
+// the cluster selected in the context, known once its client connected
+func (m *OVNClusterManager) Capabilities(ctx context.Context) (*ovn.Capabilities, error) {
+	cluster, err := m.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	capabilities := cluster.Client.Capabilities()
+	if capabilities == nil {
+		return nil, fmt.Errorf("OVN cluster %s: client not connected", cluster.Name)
+	}
+	return capabilities, nil
+}
+
+// List returns all clusters in configuration order
+func (m *OVNClusterManager) List() []OVNClusterInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]OVNClusterInfo, 0, len(m.order))
+	for _, name := range m.order {
+		infos = append(infos, m.clusters[name].info(name == m.defaultName))
+	}
+	return infos
+}
+
+// Info returns the summary of the named cluster
+func (m *OVNClusterManager) Info(name string) (OVNClusterInfo, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cluster, ok := m.clusters[name]
+	if !ok {
+		return OVNClusterInfo{}, false
+	}
+	return cluster.info(name == m.defaultName), true
+}
+
+// DiagnosedClusters returns all clusters in configuration order, for
+// DiagnosticsCollector
+func (m *OVNClusterManager) DiagnosedClusters() []DiagnosedCluster {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clusters := make([]DiagnosedCluster, 0, len(m.order))
+	for _, name := range m.order {
+		cluster := m.clusters[name]
+		clusters = append(clusters, DiagnosedCluster{
+			Name:       name,
+			Default:    name == m.defaultName,
+			Northbound: cluster.Client,
+			ReadPool:   cluster.Service.ReadPoolStats(),
+		})
+	}
+	return clusters
+}
+
+// ReloadTLS reloads TLS material for every cluster
+func (m *OVNClusterManager) ReloadTLS() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var errs []error
+	for _, name := range m.order {
+		if err := m.clusters[name].Client.ReloadTLS(); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops reconnect attempts and closes all cluster connections
+func (m *OVNClusterManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range m.order {
+		cluster := m.clusters[name]
+		cluster.cancel()
+		if cluster.readPool != nil {
+			cluster.readPool.Close()
+		}
+		cluster.Client.Close()
+	}
+}
+
+func (c *OVNCluster) info(isDefault bool) OVNClusterInfo {
+	return OVNClusterInfo{
+		Name:    c.Name,
+		Default: isDefault,
+		Status:  c.Client.Status(),
+	}
+}
+
+// ContextWithOVNCluster returns a new context selecting the named cluster
+func ContextWithOVNCluster(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ovnClusterContextKey, name)
+}
+
+func getOVNClusterFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(ovnClusterContextKey).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// ClusterOVNService dispatches each call to the cluster selected in the
+// context, or to the default cluster when none is selected
+type ClusterOVNService struct {
+	clusters *OVNClusterManager
+}
+
+// NewClusterOVNService creates a cluster-dispatching OVN service
+func NewClusterOVNService(clusters *OVNClusterManager) *ClusterOVNService {
+	return &ClusterOVNService{
+		clusters: clusters,
+	}
+}
+
+var _ OVNServiceInterface = (*ClusterOVNService)(nil)
+
+func (s *ClusterOVNService) resolve(ctx context.Context) (*OVNService, error) {
+	cluster, err := s.clusters.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.Service, nil
+}
+
+// ConnectionStatus reports the state of the default cluster
+func (s *ClusterOVNService) ConnectionStatus() ovn.ConnectionStatus {
+	if cluster := s.clusters.Default(); cluster != nil {
+		return cluster.Service.ConnectionStatus()
+	}
+	return ovn.ConnectionStatus{}
+}
+
+// ReadPoolStats returns read pool statistics of the default cluster
+func (s *ClusterOVNService) ReadPoolStats() *ovn.PoolStats {
+	if cluster := s.clusters.Default(); cluster != nil {
+		return cluster.Service.ReadPoolStats()
+	}
+	return nil
+}
+
+func (s *ClusterOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListLogicalSwitches(ctx)
+}
+
+func (s *ClusterOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return svc.ListLogicalSwitchesPage(ctx, opts)
+}
+
+func (s *ClusterOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetLogicalSwitch(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateLogicalSwitch(ctx, ls)
+}
+
+func (s *ClusterOVNService) UpdateLogicalSwitch(ctx context.Context, id string, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateLogicalSwitch(ctx, id, ls)
+}
+
+func (s *ClusterOVNService) DeleteLogicalSwitch(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteLogicalSwitch(ctx, id)
+}
+
+func (s *ClusterOVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListLogicalRouters(ctx)
+}
+
+func (s *ClusterOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return svc.ListLogicalRoutersPage(ctx, opts)
+}
+
+func (s *ClusterOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetLogicalRouter(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateLogicalRouter(ctx, lr)
+}
+
+func (s *ClusterOVNService) UpdateLogicalRouter(ctx context.Context, id string, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateLogicalRouter(ctx, id, lr)
+}
+
+func (s *ClusterOVNService) DeleteLogicalRouter(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteLogicalRouter(ctx, id)
+}
+
+func (s *ClusterOVNService) ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListPorts(ctx, switchID)
+}
+
+func (s *ClusterOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return svc.ListPortsPage(ctx, switchID, opts)
+}
+
+func (s *ClusterOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetPort(ctx, id)
+}
+
+func (s *ClusterOVNService) CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreatePort(ctx, switchID, port)
+}
+
+func (s *ClusterOVNService) UpdatePort(ctx context.Context, id string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdatePort(ctx, id, port)
+}
+
+func (s *ClusterOVNService) DeletePort(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeletePort(ctx, id)
+}
+
+func (s *ClusterOVNService) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListACLs(ctx, switchID)
+}
+
+func (s *ClusterOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return svc.ListACLsPage(ctx, switchID, opts)
+}
+
+func (s *ClusterOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetACL(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateACL(ctx, switchID, acl)
+}
+
+func (s *ClusterOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateACLs(ctx, switchID, acls)
+}
+
+func (s *ClusterOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateACL(ctx, id, acl)
+}
+
+func (s *ClusterOVNService) DeleteACL(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteACL(ctx, id)
+}
+
+func (s *ClusterOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListLoadBalancers(ctx)
+}
+
+func (s *ClusterOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return svc.ListLoadBalancersPage(ctx, opts)
+}
+
+func (s *ClusterOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetLoadBalancer(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateLoadBalancer(ctx, lb)
+}
+
+func (s *ClusterOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateLoadBalancer(ctx, id, lb)
+}
+
+func (s *ClusterOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteLoadBalancer(ctx, id)
+}
+
+func (s *ClusterOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListPortGroups(ctx)
+}
+
+func (s *ClusterOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListAddressSets(ctx)
+}
+
+func (s *ClusterOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListPortGroupACLs(ctx, portGroupID)
+}
+
+func (s *ClusterOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+}
+
+func (s *ClusterOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListDNS(ctx)
+}
+
+func (s *ClusterOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetDNS(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateDNS(ctx, dns)
+}
+
+func (s *ClusterOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateDNS(ctx, id, dns)
+}
+
+func (s *ClusterOVNService) DeleteDNS(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteDNS(ctx, id)
+}
+
+func (s *ClusterOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListMirrors(ctx)
+}
+
+func (s *ClusterOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetMirror(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateMirror(ctx, mirror)
+}
+
+func (s *ClusterOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateMirror(ctx, id, mirror)
+}
+
+func (s *ClusterOVNService) DeleteMirror(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteMirror(ctx, id)
+}
+
+func (s *ClusterOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSampleCollectors(ctx)
+}
+
+func (s *ClusterOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetSampleCollector(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateSampleCollector(ctx, collector)
+}
+
+func (s *ClusterOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateSampleCollector(ctx, id, collector)
+}
+
+func (s *ClusterOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteSampleCollector(ctx, id)
+}
+
+func (s *ClusterOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSamplingApps(ctx)
+}
+
+func (s *ClusterOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetSamplingApp(ctx, appType, id)
+}
+
+func (s *ClusterOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetACLSampling(ctx, aclID)
+}
+
+func (s *ClusterOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetACLSampling(ctx, aclID, sampling)
+}
+
+func (s *ClusterOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetSwitchSampling(ctx, switchID, sampling)
+}
+
+func (s *ClusterOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListRouterPolicies(ctx, routerID)
+}
+
+func (s *ClusterOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetRouterPolicy(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateRouterPolicy(ctx, routerID, policy)
+}
+
+func (s *ClusterOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateRouterPolicy(ctx, id, policy)
+}
+
+func (s *ClusterOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteRouterPolicy(ctx, id)
+}
+
+func (s *ClusterOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListStaticRoutes(ctx, routerID)
+}
+
+func (s *ClusterOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetStaticRoute(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateStaticRoutes(ctx, routerID, routes)
+}
+
+func (s *ClusterOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.UpdateStaticRoute(ctx, id, route)
+}
+
+func (s *ClusterOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteStaticRoute(ctx, id)
+}
+
+func (s *ClusterOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.CreateRouterPort(ctx, routerID, port)
+}
+
+func (s *ClusterOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.DeleteRouterPort(ctx, id)
+}
+
+func (s *ClusterOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListGateways(ctx)
+}
+
+func (s *ClusterOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetGatewayBinding(ctx, portID, binding)
+}
+
+func (s *ClusterOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetGatewayPriorities(ctx, priorities)
+}
+
+func (s *ClusterOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.SetLabels(ctx, resource, id, set, remove)
+}
+
+func (s *ClusterOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.ExecuteTransaction(ctx, ops)
+}
+
+func (s *ClusterOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+	svc, err := s.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetTopology(ctx)
+}
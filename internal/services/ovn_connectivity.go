@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// OVNClusterLister lists OVN clusters with their connection status.
+// *OVNClusterManager implements it.
+type OVNClusterLister interface {
+	List() []OVNClusterInfo
+}
+
+// OVNConnectivityMonitor publishes an event when the northbound connection
+// of an OVN cluster is lost and when it's back
+type OVNConnectivityMonitor struct {
+	clusters  OVNClusterLister
+	events    EventPublisher
+	interval  time.Duration
+	logger    *zap.Logger
+	connected map[string]bool // As of the last check, by cluster
+}
+
+// NewOVNConnectivityMonitor creates a monitor checking clusters every
+// interval
+func NewOVNConnectivityMonitor(clusters OVNClusterLister, events EventPublisher, interval time.Duration, logger *zap.Logger) *OVNConnectivityMonitor {
+	return &OVNConnectivityMonitor{
+		clusters:  clusters,
+		events:    events,
+		interval:  interval,
+		logger:    logger,
+		connected: make(map[string]bool),
+	}
+}
+
+// Run checks the clusters every interval until ctx is done
+func (m *OVNConnectivityMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check publishes events for the clusters whose connection changed since the
+// last check. Clusters seen for the first time are only recorded.
+func (m *OVNConnectivityMonitor) Check(ctx context.Context) {
+	for _, cluster := range m.clusters.List() {
+		was, seen := m.connected[cluster.Name]
+		m.connected[cluster.Name] = cluster.Status.Connected
+		if !seen || was == cluster.Status.Connected {
+			continue
+		}
+
+		eventType := models.EventOVNReconnected
+		if !cluster.Status.Connected {
+			eventType = models.EventOVNDisconnected
+			m.logger.Warn("Lost connection to OVN", zap.String("cluster", cluster.Name))
+		}
+		m.events.Publish(ctx, &models.Event{
+			Type: eventType,
+			Data: map[string]interface{}{
+				"cluster":    cluster.Name,
+				"endpoint":   cluster.Status.Endpoint,
+				"last_error": cluster.Status.LastError,
+			},
+		})
+	}
+}
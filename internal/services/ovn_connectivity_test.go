@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// fakeClusterLister reports fixed connection states
+type fakeClusterLister struct {
+	connected map[string]bool
+}
+
+func (f *fakeClusterLister) List() []OVNClusterInfo {
+	var infos []OVNClusterInfo
+	for _, name := range []string{"east", "west"} {
+		if connected, ok := f.connected[name]; ok {
+			infos = append(infos, OVNClusterInfo{Name: name, Status: ovn.ConnectionStatus{Connected: connected}})
+		}
+	}
+	return infos
+}
+
+func TestOVNConnectivityMonitor_Check(t *testing.T) {
+	clusters := &fakeClusterLister{connected: map[string]bool{"east": true, "west": false}}
+	events := &recordingPublisher{}
+	monitor := NewOVNConnectivityMonitor(clusters, events, 0, zap.NewNop())
+	ctx := context.Background()
+
+	// The state found on the first check isn't a change
+	monitor.Check(ctx)
+	assert.Empty(t, events.events)
+
+	clusters.connected["east"] = false
+	monitor.Check(ctx)
+	monitor.Check(ctx)
+	clusters.connected["east"] = true
+	clusters.connected["west"] = true
+	monitor.Check(ctx)
+
+	var published []string
+	for _, event := range events.events {
+		published = append(published, event.Type+":"+event.Data["cluster"].(string))
+	}
+	require.Len(t, published, 3)
+	assert.Equal(t, []string{
+		models.EventOVNDisconnected + ":east",
+		models.EventOVNReconnected + ":east",
+		models.EventOVNReconnected + ":west",
+	}, published)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/lspecian/ovncp/internal/models"
@@ -9,7 +10,8 @@ import (
 )
 
 type OVNService struct {
-	client *ovn.Client
+	client   *ovn.Client
+	readPool *ovn.PooledClient
 }
 
 func NewOVNService(client *ovn.Client) *OVNService {
@@ -23,8 +25,57 @@ func (s *OVNService) GetOVNClient() *ovn.Client {
 	return s.client
 }
 
+// SetReadPool routes list operations through a pool of dedicated
+// connections so large listings don't contend with writes on the primary client
+func (s *OVNService) SetReadPool(pool *ovn.PooledClient) {
+	s.readPool = pool
+}
+
+// ConnectionStatus reports the state of the primary northbound connection
+func (s *OVNService) ConnectionStatus() ovn.ConnectionStatus {
+	return s.client.Status()
+}
+
+// ReadPoolStats returns read pool statistics, or nil without a read pool
+func (s *OVNService) ReadPoolStats() *ovn.PoolStats {
+	if s.readPool == nil {
+		return nil
+	}
+	stats := s.readPool.Stats()
+	return &stats
+}
+
+// read runs fn on a pooled connection, falling back to the primary client
+// when there is no pool or it has no usable connection
+func (s *OVNService) read(ctx context.Context, fn func(*ovn.Client) error) error {
+	if s.readPool != nil {
+		err := s.readPool.Execute(ctx, fn)
+		if !errors.Is(err, ovn.ErrPoolUnavailable) {
+			return err
+		}
+	}
+	return fn(s.client)
+}
+
 func (s *OVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
-	return s.client.ListLogicalSwitches(ctx)
+	var switches []*models.LogicalSwitch
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		switches, err = c.ListLogicalSwitches(ctx)
+		return err
+	})
+	return switches, err
+}
+
+func (s *OVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	var switches []*models.LogicalSwitch
+	var total int
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		switches, total, err = c.ListLogicalSwitchesPage(ctx, opts)
+		return err
+	})
+	return switches, total, err
 }
 
 func (s *OVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
@@ -59,7 +110,24 @@ func (s *OVNService) DeleteLogicalSwitch(ctx context.Context, id string) error {
 }
 
 func (s *OVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
-	return s.client.ListLogicalRouters(ctx)
+	var routers []*models.LogicalRouter
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		routers, err = c.ListLogicalRouters(ctx)
+		return err
+	})
+	return routers, err
+}
+
+func (s *OVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	var routers []*models.LogicalRouter
+	var total int
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		routers, total, err = c.ListLogicalRoutersPage(ctx, opts)
+		return err
+	})
+	return routers, total, err
 }
 
 func (s *OVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
@@ -99,7 +167,29 @@ func (s *OVNService) ListPorts(ctx context.Context, switchID string) ([]*models.
 		return nil, fmt.Errorf("switch ID is required")
 	}
 
-	return s.client.ListLogicalSwitchPorts(ctx, switchID)
+	var ports []*models.LogicalSwitchPort
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		ports, err = c.ListLogicalSwitchPorts(ctx, switchID)
+		return err
+	})
+	return ports, err
+}
+
+func (s *OVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, 0, fmt.Errorf("switch ID is required")
+	}
+
+	var ports []*models.LogicalSwitchPort
+	var total int
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		ports, total, err = c.ListLogicalSwitchPortsPage(ctx, switchID, opts)
+		return err
+	})
+	return ports, total, err
 }
 
 func (s *OVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
@@ -147,7 +237,29 @@ func (s *OVNService) ListACLs(ctx context.Context, switchID string) ([]*models.A
 		return nil, fmt.Errorf("switch ID is required")
 	}
 
-	return s.client.ListACLs(ctx, switchID)
+	var acls []*models.ACL
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		acls, err = c.ListACLs(ctx, switchID)
+		return err
+	})
+	return acls, err
+}
+
+func (s *OVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, 0, fmt.Errorf("switch ID is required")
+	}
+
+	var acls []*models.ACL
+	var total int
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		acls, total, err = c.ListACLsPage(ctx, switchID, opts)
+		return err
+	})
+	return acls, total, err
 }
 
 func (s *OVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
@@ -177,6 +289,27 @@ func (s *OVNService) CreateACL(ctx context.Context, switchID string, acl *models
 	return s.client.CreateACL(ctx, switchID, acl)
 }
 
+// CreateACLs creates acls on a switch in a single transaction
+func (s *OVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+	for i, acl := range acls {
+		if acl.Match == "" {
+			return nil, fmt.Errorf("ACL %d: ACL match expression is required", i)
+		}
+		if acl.Action == "" {
+			return nil, fmt.Errorf("ACL %d: ACL action is required", i)
+		}
+		if acl.Direction == "" {
+			return nil, fmt.Errorf("ACL %d: ACL direction is required", i)
+		}
+	}
+
+	return s.client.CreateACLs(ctx, switchID, acls)
+}
+
 func (s *OVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
 	// Validate input
 	if id == "" {
@@ -195,3 +328,431 @@ func (s *OVNService) DeleteACL(ctx context.Context, id string) error {
 	return s.client.DeleteACL(ctx, id)
 }
 
+
+func (s *OVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	var lbs []*models.LoadBalancer
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		lbs, err = c.ListLoadBalancers(ctx)
+		return err
+	})
+	return lbs, err
+}
+
+func (s *OVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	var lbs []*models.LoadBalancer
+	var total int
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		lbs, total, err = c.ListLoadBalancersPage(ctx, opts)
+		return err
+	})
+	return lbs, total, err
+}
+
+func (s *OVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("load balancer ID is required")
+	}
+
+	return s.client.GetLoadBalancer(ctx, id)
+}
+
+func (s *OVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	// Validate input
+	if lb.Name == "" {
+		return nil, fmt.Errorf("load balancer name is required")
+	}
+	if len(lb.VIPs) == 0 {
+		return nil, fmt.Errorf("at least one VIP is required")
+	}
+
+	return s.client.CreateLoadBalancer(ctx, lb)
+}
+
+func (s *OVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("load balancer ID is required")
+	}
+
+	return s.client.UpdateLoadBalancer(ctx, id, lb)
+}
+
+func (s *OVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("load balancer ID is required")
+	}
+
+	return s.client.DeleteLoadBalancer(ctx, id)
+}
+
+func (s *OVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	var pgs []*models.PortGroup
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		pgs, err = c.ListPortGroups(ctx)
+		return err
+	})
+	return pgs, err
+}
+
+func (s *OVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	var sets []*models.AddressSet
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		sets, err = c.ListAddressSets(ctx)
+		return err
+	})
+	return sets, err
+}
+
+func (s *OVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	// Validate input
+	if portGroupID == "" {
+		return nil, fmt.Errorf("port group ID is required")
+	}
+
+	return s.client.ListPortGroupACLs(ctx, portGroupID)
+}
+
+func (s *OVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	var records []*models.DNS
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		records, err = c.ListDNS(ctx)
+		return err
+	})
+	return records, err
+}
+
+func (s *OVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("DNS record set ID is required")
+	}
+
+	return s.client.GetDNS(ctx, id)
+}
+
+func (s *OVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	return s.client.CreateDNS(ctx, dns)
+}
+
+func (s *OVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("DNS record set ID is required")
+	}
+
+	return s.client.UpdateDNS(ctx, id, dns)
+}
+
+func (s *OVNService) DeleteDNS(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("DNS record set ID is required")
+	}
+
+	return s.client.DeleteDNS(ctx, id)
+}
+
+func (s *OVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	var mirrors []*models.Mirror
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		mirrors, err = c.ListMirrors(ctx)
+		return err
+	})
+	return mirrors, err
+}
+
+func (s *OVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("mirror ID is required")
+	}
+
+	return s.client.GetMirror(ctx, id)
+}
+
+func (s *OVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	return s.client.CreateMirror(ctx, mirror)
+}
+
+func (s *OVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("mirror ID is required")
+	}
+
+	return s.client.UpdateMirror(ctx, id, mirror)
+}
+
+func (s *OVNService) DeleteMirror(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("mirror ID is required")
+	}
+
+	return s.client.DeleteMirror(ctx, id)
+}
+
+func (s *OVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	var collectors []*models.SampleCollector
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		collectors, err = c.ListSampleCollectors(ctx)
+		return err
+	})
+	return collectors, err
+}
+
+func (s *OVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("sample collector ID is required")
+	}
+
+	return s.client.GetSampleCollector(ctx, id)
+}
+
+func (s *OVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.client.CreateSampleCollector(ctx, collector)
+}
+
+func (s *OVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("sample collector ID is required")
+	}
+
+	return s.client.UpdateSampleCollector(ctx, id, collector)
+}
+
+func (s *OVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("sample collector ID is required")
+	}
+
+	return s.client.DeleteSampleCollector(ctx, id)
+}
+
+func (s *OVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	var apps []*models.SamplingApp
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		apps, err = c.ListSamplingApps(ctx)
+		return err
+	})
+	return apps, err
+}
+
+func (s *OVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	return s.client.SetSamplingApp(ctx, appType, id)
+}
+
+func (s *OVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	// Validate input
+	if aclID == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	return s.client.GetACLSampling(ctx, aclID)
+}
+
+func (s *OVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	// Validate input
+	if aclID == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	return s.client.SetACLSampling(ctx, aclID, sampling)
+}
+
+func (s *OVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+
+	return s.client.SetSwitchSampling(ctx, switchID, sampling)
+}
+
+func (s *OVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	var policies []*models.RouterPolicy
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		policies, err = c.ListRouterPolicies(ctx, routerID)
+		return err
+	})
+	return policies, err
+}
+
+func (s *OVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("router policy ID is required")
+	}
+
+	return s.client.GetRouterPolicy(ctx, id)
+}
+
+func (s *OVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	return s.client.CreateRouterPolicy(ctx, routerID, policy)
+}
+
+func (s *OVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("router policy ID is required")
+	}
+
+	return s.client.UpdateRouterPolicy(ctx, id, policy)
+}
+
+func (s *OVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("router policy ID is required")
+	}
+
+	return s.client.DeleteRouterPolicy(ctx, id)
+}
+
+func (s *OVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	var routes []*models.StaticRoute
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		routes, err = c.ListStaticRoutes(ctx, routerID)
+		return err
+	})
+	return routes, err
+}
+
+func (s *OVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("static route ID is required")
+	}
+
+	return s.client.GetStaticRoute(ctx, id)
+}
+
+func (s *OVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("at least one static route is required")
+	}
+
+	return s.client.CreateStaticRoutes(ctx, routerID, routes)
+}
+
+func (s *OVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("static route ID is required")
+	}
+
+	return s.client.UpdateStaticRoute(ctx, id, route)
+}
+
+func (s *OVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("static route ID is required")
+	}
+
+	return s.client.DeleteStaticRoute(ctx, id)
+}
+
+func (s *OVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+	if port.Name == "" || port.MAC == "" || len(port.Networks) == 0 {
+		return nil, fmt.Errorf("router port name, MAC and networks are required")
+	}
+
+	return s.client.CreateLogicalRouterPort(ctx, routerID, port)
+}
+
+func (s *OVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("router port ID is required")
+	}
+
+	return s.client.DeleteLogicalRouterPort(ctx, id)
+}
+
+func (s *OVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	var gateways []*models.Gateway
+	err := s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		gateways, err = c.ListGateways(ctx)
+		return err
+	})
+	return gateways, err
+}
+
+func (s *OVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	// Validate input
+	if portID == "" {
+		return nil, fmt.Errorf("router port ID is required")
+	}
+
+	return s.client.SetGatewayBinding(ctx, portID, binding)
+}
+
+func (s *OVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	return s.client.SetGatewayPriorities(ctx, priorities)
+}
+
+// SetLabels sets and removes labels of a switch, router, port or ACL; see
+// models.LabelPrefix
+func (s *OVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%s ID is required", resource)
+	}
+
+	return s.client.SetLabels(ctx, resource, id, set, remove)
+}
+
+func (s *OVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	// Validate input
+	for _, as := range addressSets {
+		if as.Name == "" {
+			return nil, fmt.Errorf("address set name is required")
+		}
+	}
+	for _, spec := range portGroups {
+		if spec.PortGroup == nil || spec.PortGroup.Name == "" {
+			return nil, fmt.Errorf("port group name is required")
+		}
+	}
+
+	return s.client.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+}
@@ -2,73 +2,142 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 	
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
-// ExecuteTransaction executes multiple operations in a single transaction
+// ExecuteTransaction executes multiple operations in a single OVSDB
+// transaction: either all of them are applied or none is. Creates set the
+// ResourceID of their operation, and Data is updated with the resulting
+// resource. Failures are reported as an *ovn.TxError naming the operation.
 func (s *OVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
 	if len(ops) == 0 {
 		return fmt.Errorf("no operations provided")
 	}
 	
-	// TODO: Implement actual transaction logic with OVN
-	// For now, execute operations sequentially
-	for _, op := range ops {
-		if err := s.executeOp(ctx, op); err != nil {
-			return fmt.Errorf("transaction failed at operation %s on %s: %w", op.Operation, op.ResourceType, err)
+	txOps := make([]ovn.TxOp, len(ops))
+	for i, op := range ops {
+		txOp, err := toTxOp(op)
+		if err != nil {
+			return &ovn.TxError{Index: i, Err: err}
+		}
+		txOps[i] = txOp
+	}
+	
+	if err := s.client.ExecuteTransaction(ctx, txOps); err != nil {
+		return err
+	}
+	
+	for i := range ops {
+		ops[i].ResourceID = txOps[i].ResourceID
+		if ops[i].Data != nil {
+			ops[i].Data = txOps[i].Data
 		}
 	}
 	
 	return nil
 }
 
-// executeOp executes a single operation
-func (s *OVNService) executeOp(ctx context.Context, op TransactionOp) error {
+// transactionResourceTypes maps the resource names used by callers,
+// including the table names of older clients, to the transaction resources
+var transactionResourceTypes = map[string]string{
+	"switch":         models.ResourceSwitch,
+	"logical_switch": models.ResourceSwitch,
+	"router":         models.ResourceRouter,
+	"logical_router": models.ResourceRouter,
+	"port":           models.ResourcePort,
+	"logical_port":   models.ResourcePort,
+	"acl":            models.ResourceACL,
+	"load_balancer":  models.ResourceLoadBalancer,
+	"nat":            models.ResourceNAT,
+	"port_group":     models.ResourcePortGroup,
+	"address_set":    models.ResourceAddressSet,
+	"dhcp_options":   models.ResourceDHCPOptions,
+	"qos":            models.ResourceQoS,
+}
+
+// toTxOp converts a transaction operation to the OVN client's form,
+// decoding untyped data into the model of its resource
+func toTxOp(op TransactionOp) (ovn.TxOp, error) {
 	// Support both Table and ResourceType for backward compatibility
 	resourceType := op.ResourceType
 	if resourceType == "" {
 		resourceType = op.Table
 	}
+	resource, ok := transactionResourceTypes[resourceType]
+	if !ok {
+		return ovn.TxOp{}, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
 	
-	switch resourceType {
-	case "switch", "logical_switch":
-		return s.executeSwitchOp(ctx, op)
-	case "router", "logical_router":
-		return s.executeRouterOp(ctx, op)
-	case "port", "logical_port":
-		return s.executePortOp(ctx, op)
-	case "acl":
-		return s.executeACLOp(ctx, op)
-	default:
-		return fmt.Errorf("unknown resource type: %s", resourceType)
+	resourceID := op.ResourceID
+	if resourceID == "" {
+		resourceID = op.ID
 	}
+	
+	data, err := transactionData(resource, op.Data)
+	if err != nil {
+		return ovn.TxOp{}, err
+	}
+	
+	return ovn.TxOp{
+		Ref:        op.Ref,
+		Operation:  op.Operation,
+		Resource:   resource,
+		ResourceID: resourceID,
+		SwitchID:   op.SwitchID,
+		RouterID:   op.RouterID,
+		Data:       data,
+	}, nil
 }
 
-// executeSwitchOp executes switch operations
-func (s *OVNService) executeSwitchOp(ctx context.Context, op TransactionOp) error {
-	// TODO: Implement switch operations
-	return fmt.Errorf("switch operations not implemented")
-}
-
-// executeRouterOp executes router operations
-func (s *OVNService) executeRouterOp(ctx context.Context, op TransactionOp) error {
-	// TODO: Implement router operations
-	return fmt.Errorf("router operations not implemented")
-}
-
-// executePortOp executes port operations
-func (s *OVNService) executePortOp(ctx context.Context, op TransactionOp) error {
-	// TODO: Implement port operations
-	return fmt.Errorf("port operations not implemented")
-}
-
-// executeACLOp executes ACL operations
-func (s *OVNService) executeACLOp(ctx context.Context, op TransactionOp) error {
-	// TODO: Implement ACL operations
-	return fmt.Errorf("ACL operations not implemented")
+// transactionData returns data as the model of resource. Models are used
+// as they are; anything else, such as a decoded JSON object, is converted.
+func transactionData(resource string, data interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	
+	var typed interface{}
+	switch resource {
+	case models.ResourceSwitch:
+		typed = &models.LogicalSwitch{}
+	case models.ResourceRouter:
+		typed = &models.LogicalRouter{}
+	case models.ResourcePort:
+		typed = &models.LogicalSwitchPort{}
+	case models.ResourceACL:
+		typed = &models.ACL{}
+	case models.ResourceLoadBalancer:
+		typed = &models.LoadBalancer{}
+	case models.ResourceNAT:
+		typed = &models.NAT{}
+	case models.ResourcePortGroup:
+		typed = &models.PortGroup{}
+	case models.ResourceAddressSet:
+		typed = &models.AddressSet{}
+	case models.ResourceDHCPOptions:
+		typed = &models.DHCPOptions{}
+	case models.ResourceQoS:
+		typed = &models.QoS{}
+	}
+	
+	if reflect.TypeOf(data) == reflect.TypeOf(typed) {
+		return data, nil
+	}
+	
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", resource, err)
+	}
+	if err := json.Unmarshal(raw, typed); err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", resource, err)
+	}
+	return typed, nil
 }
 
 // GetTopology returns the current network topology
@@ -95,6 +164,34 @@ func (s *OVNService) GetTopology(ctx context.Context) (*Topology, error) {
 		ports = append(ports, swPorts...)
 	}
 	
+	// Get router ports and each router's NAT rules and policies, which
+	// routers leave out
+	var routerPorts []*models.LogicalRouterPort
+	var nats map[string][]models.NAT
+	var policies map[string][]models.RouterPolicy
+	err = s.read(ctx, func(c *ovn.Client) error {
+		var err error
+		if routerPorts, err = c.ListLogicalRouterPorts(ctx); err != nil {
+			return err
+		}
+		if nats, err = c.ListLogicalRouterNATs(ctx); err != nil {
+			return err
+		}
+		policies, err = c.ListLogicalRouterPolicies(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list router ports: %w", err)
+	}
+	for _, router := range routers {
+		if routerNATs, ok := nats[router.UUID]; ok {
+			router.NAT = routerNATs
+		}
+		if routerPolicies, ok := policies[router.UUID]; ok {
+			router.PolicyRules = routerPolicies
+		}
+	}
+	
 	// Build connections
 	var connections []Connection
 	// TODO: Build actual connections based on port associations
@@ -103,6 +200,7 @@ func (s *OVNService) GetTopology(ctx context.Context) (*Topology, error) {
 		Switches:    switches,
 		Routers:     routers,
 		Ports:       ports,
+		RouterPorts: routerPorts,
 		Connections: connections,
 		Timestamp:   time.Now(),
 	}, nil
@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrProviderNetworkNotFound is returned for switches that aren't
+	// provider networks
+	ErrProviderNetworkNotFound = errors.New("provider network not found")
+
+	// ErrInvalidProviderNetwork is returned for provider networks and
+	// router attachments with invalid settings
+	ErrInvalidProviderNetwork = errors.New("invalid provider network")
+
+	// ErrBridgeMappingMissing is returned when no chassis, or not every
+	// chassis a router is attached on, maps the physical network to a
+	// bridge
+	ErrBridgeMappingMissing = errors.New("bridge mapping missing")
+
+	// ErrProviderNetworkConflict is returned when deleting a provider
+	// network routers are attached to, or attaching a router twice
+	ErrProviderNetworkConflict = errors.New("provider network conflict")
+)
+
+// MACAssigner replaces "auto" MACs of port addresses. *MACAllocator
+// implements it.
+type MACAssigner interface {
+	AssignMACs(ctx context.Context, switchID string, port *models.LogicalSwitchPort) error
+}
+
+// CreateProviderNetworkRequest defines a provider network: a switch with a
+// localnet port bridged to the physical network, tagged with VLAN when it
+// isn't zero
+type CreateProviderNetworkRequest struct {
+	Name            string `json:"name" binding:"required"`
+	PhysicalNetwork string `json:"physical_network" binding:"required"`
+	VLAN            int    `json:"vlan"`
+	CIDR            string `json:"cidr"`
+}
+
+// AttachRouterRequest attaches a router to a provider network through a
+// gateway port with the addresses of Networks, e.g. "203.0.113.2/24". MAC
+// is generated from the MAC pools when empty or "auto". The gateway port is
+// placed on Chassis, highest priority first, or else on every gateway
+// chassis mapping the physical network.
+type AttachRouterRequest struct {
+	RouterID       string   `json:"router_id" binding:"required"`
+	Networks       []string `json:"networks" binding:"required"`
+	MAC            string   `json:"mac"`
+	Chassis        []string `json:"chassis"`
+	HAChassisGroup string   `json:"ha_chassis_group"`
+}
+
+// ProviderNetworkService manages the switches bridged to physical networks
+// and the routers attached to them. Provider networks belong to the
+// provider, so the OVN service it's given isn't scoped to a tenant.
+type ProviderNetworkService struct {
+	ovn     OVNServiceInterface
+	chassis ChassisSource
+	macs    MACAssigner
+	logger  *zap.Logger
+}
+
+func NewProviderNetworkService(ovn OVNServiceInterface, chassis ChassisSource, macs MACAssigner, logger *zap.Logger) *ProviderNetworkService {
+	return &ProviderNetworkService{ovn: ovn, chassis: chassis, macs: macs, logger: logger}
+}
+
+// localnetPortName names the localnet port of a provider network
+func localnetPortName(network string) string {
+	return network + "-localnet"
+}
+
+// Create creates the switch of a provider network and its localnet port.
+// Some chassis must map the physical network to a bridge.
+func (s *ProviderNetworkService) Create(ctx context.Context, req *CreateProviderNetworkRequest) (*models.ProviderNetwork, error) {
+	if req.VLAN < 0 || req.VLAN > 4094 {
+		return nil, fmt.Errorf("%w: VLAN %d isn't between 1 and 4094", ErrInvalidProviderNetwork, req.VLAN)
+	}
+	if strings.ContainsAny(req.PhysicalNetwork, ":, ") {
+		return nil, fmt.Errorf("%w: invalid physical network name %q", ErrInvalidProviderNetwork, req.PhysicalNetwork)
+	}
+	externalIDs := map[string]string{models.ProviderNetworkKey: req.PhysicalNetwork}
+	if req.CIDR != "" {
+		prefix, err := netip.ParsePrefix(req.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid CIDR %q", ErrInvalidProviderNetwork, req.CIDR)
+		}
+		externalIDs[models.ProviderNetworkCIDRKey] = prefix.Masked().String()
+	}
+
+	mapped, err := s.mappedChassis(ctx, req.PhysicalNetwork)
+	if err != nil {
+		return nil, err
+	}
+	if len(mapped) == 0 {
+		return nil, fmt.Errorf("%w: no chassis maps physical network %s in ovn-bridge-mappings", ErrBridgeMappingMissing, req.PhysicalNetwork)
+	}
+
+	sw, err := s.ovn.CreateLogicalSwitch(ctx, &models.LogicalSwitch{
+		Name:        req.Name,
+		Description: fmt.Sprintf("Provider network on %s", req.PhysicalNetwork),
+		ExternalIDs: externalIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.ovn.CreatePort(ctx, sw.UUID, &models.LogicalSwitchPort{
+		Name:      localnetPortName(req.Name),
+		Type:      "localnet",
+		Addresses: []string{"unknown"},
+		Tag:       req.VLAN,
+		Options:   map[string]string{models.LocalnetNetworkNameOption: req.PhysicalNetwork},
+	})
+	if err != nil {
+		if delErr := s.ovn.DeleteLogicalSwitch(ctx, sw.UUID); delErr != nil {
+			s.logger.Error("Failed to delete the switch of a provider network left without localnet port",
+				zap.String("switch", sw.UUID), zap.Error(delErr))
+		}
+		return nil, fmt.Errorf("failed to create localnet port: %w", err)
+	}
+
+	return s.Get(ctx, sw.UUID)
+}
+
+// List returns the provider networks with the routers attached to them
+func (s *ProviderNetworkService) List(ctx context.Context) ([]*models.ProviderNetwork, error) {
+	topology, err := s.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+	gateways, err := s.ovn.ListGateways(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gateways: %w", err)
+	}
+	chassis, err := s.chassis.ListChassis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chassis: %w", err)
+	}
+
+	networks := []*models.ProviderNetwork{}
+	for _, sw := range topology.Switches {
+		if _, ok := sw.ExternalIDs[models.ProviderNetworkKey]; ok {
+			networks = append(networks, buildProviderNetwork(sw, topology, gateways, chassis))
+		}
+	}
+	return networks, nil
+}
+
+// Get returns a provider network by its switch's UUID or name
+func (s *ProviderNetworkService) Get(ctx context.Context, id string) (*models.ProviderNetwork, error) {
+	networks, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		if network.ID == id || network.Name == id {
+			return network, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrProviderNetworkNotFound, id)
+}
+
+// Delete deletes a provider network's switch with its localnet port. The
+// routers attached to it must be detached first.
+func (s *ProviderNetworkService) Delete(ctx context.Context, id string) error {
+	network, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(network.Routers) > 0 {
+		return fmt.Errorf("%w: %d routers are attached to provider network %s", ErrProviderNetworkConflict, len(network.Routers), network.Name)
+	}
+	return s.ovn.DeleteLogicalSwitch(ctx, network.ID)
+}
+
+// AttachRouter creates a gateway port on the router with a peer port on the
+// provider network's switch, and places the gateway port on chassis mapping
+// the physical network
+func (s *ProviderNetworkService) AttachRouter(ctx context.Context, id string, req *AttachRouterRequest) (*models.ProviderNetwork, error) {
+	network, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	router, err := s.ovn.GetLogicalRouter(ctx, req.RouterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, attached := range network.Routers {
+		if attached.RouterID == router.UUID {
+			return nil, fmt.Errorf("%w: router %s is attached to provider network %s already", ErrProviderNetworkConflict, router.Name, network.Name)
+		}
+	}
+	if err := validateAttachNetworks(network, req.Networks); err != nil {
+		return nil, err
+	}
+	chassis, err := s.gatewayChassis(ctx, network, req.Chassis)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := s.routerPortMAC(ctx, network, req.MAC)
+	if err != nil {
+		return nil, err
+	}
+
+	lrp, err := s.ovn.CreateRouterPort(ctx, router.UUID, &models.LogicalRouterPort{
+		Name:        router.Name + "-" + network.Name,
+		MAC:         mac,
+		Networks:    req.Networks,
+		ExternalIDs: map[string]string{models.ProviderNetworkKey: network.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	lsp, err := s.ovn.CreatePort(ctx, network.ID, &models.LogicalSwitchPort{
+		Name:      network.Name + "-" + router.Name,
+		Type:      "router",
+		Addresses: []string{"router"},
+		Options:   map[string]string{routerPortOption: lrp.Name},
+	})
+	if err != nil {
+		s.rollbackAttach(ctx, lrp.UUID, "")
+		return nil, fmt.Errorf("failed to create switch port: %w", err)
+	}
+	_, err = s.ovn.SetGatewayBinding(ctx, lrp.UUID, &models.GatewayBinding{HAChassisGroup: req.HAChassisGroup, Chassis: chassis})
+	if err != nil {
+		s.rollbackAttach(ctx, lrp.UUID, lsp.UUID)
+		return nil, fmt.Errorf("failed to place gateway port: %w", err)
+	}
+
+	s.logger.Info("Router attached to provider network",
+		zap.String("router", router.Name), zap.String("network", network.Name))
+	return s.Get(ctx, network.ID)
+}
+
+// DetachRouter deletes the gateway port attaching a router to the provider
+// network and its peer port
+func (s *ProviderNetworkService) DetachRouter(ctx context.Context, id, routerID string) (*models.ProviderNetwork, error) {
+	network, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var attached *models.ProviderNetworkRouter
+	for _, r := range network.Routers {
+		if r.RouterID == routerID || r.RouterName == routerID {
+			attached = r
+		}
+	}
+	if attached == nil {
+		return nil, fmt.Errorf("%w: router %s isn't attached to provider network %s", ErrProviderNetworkNotFound, routerID, network.Name)
+	}
+
+	if attached.SwitchPortID != "" {
+		if err := s.ovn.DeletePort(ctx, attached.SwitchPortID); err != nil {
+			return nil, fmt.Errorf("failed to delete switch port: %w", err)
+		}
+	}
+	if err := s.ovn.DeleteRouterPort(ctx, attached.RouterPortID); err != nil {
+		return nil, fmt.Errorf("failed to delete router port: %w", err)
+	}
+
+	s.logger.Info("Router detached from provider network",
+		zap.String("router", attached.RouterName), zap.String("network", network.Name))
+	return s.Get(ctx, network.ID)
+}
+
+// rollbackAttach deletes the ports of an attachment that failed
+func (s *ProviderNetworkService) rollbackAttach(ctx context.Context, lrpID, lspID string) {
+	if lspID != "" {
+		if err := s.ovn.DeletePort(ctx, lspID); err != nil {
+			s.logger.Error("Failed to roll back switch port", zap.String("port", lspID), zap.Error(err))
+		}
+	}
+	if err := s.ovn.DeleteRouterPort(ctx, lrpID); err != nil {
+		s.logger.Error("Failed to roll back router port", zap.String("port", lrpID), zap.Error(err))
+	}
+}
+
+// mappedChassis returns the chassis mapping a physical network to a bridge
+func (s *ProviderNetworkService) mappedChassis(ctx context.Context, physnet string) ([]*models.Chassis, error) {
+	chassis, err := s.chassis.ListChassis(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chassis: %w", err)
+	}
+	var mapped []*models.Chassis
+	for _, ch := range chassis {
+		if mapsPhysicalNetwork(ch, physnet) {
+			mapped = append(mapped, ch)
+		}
+	}
+	return mapped, nil
+}
+
+// gatewayChassis returns the chassis to place a provider network's gateway
+// port on, highest priority first. Each named chassis must map the
+// physical network; without names, every gateway chassis that does is used.
+func (s *ProviderNetworkService) gatewayChassis(ctx context.Context, network *models.ProviderNetwork, names []string) ([]models.GatewayChassis, error) {
+	mapped, err := s.mappedChassis(ctx, network.PhysicalNetwork)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Chassis, len(mapped))
+	for _, ch := range mapped {
+		byName[ch.Name] = ch
+	}
+
+	if len(names) == 0 {
+		for _, ch := range mapped {
+			if ch.Gateway {
+				names = append(names, ch.Name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%w: no gateway chassis maps physical network %s", ErrBridgeMappingMissing, network.PhysicalNetwork)
+		}
+	}
+	if len(names) > maxGatewayPriority {
+		return nil, fmt.Errorf("%w: too many chassis", ErrInvalidProviderNetwork)
+	}
+
+	chassis := make([]models.GatewayChassis, 0, len(names))
+	for i, name := range names {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("%w: chassis %s doesn't map physical network %s", ErrBridgeMappingMissing, name, network.PhysicalNetwork)
+		}
+		chassis = append(chassis, models.GatewayChassis{Name: name, Priority: len(names) - i})
+	}
+	return chassis, nil
+}
+
+// routerPortMAC returns the MAC of a new gateway port, generated from the
+// MAC pool of the network's switch unless one is given
+func (s *ProviderNetworkService) routerPortMAC(ctx context.Context, network *models.ProviderNetwork, mac string) (string, error) {
+	if mac != "" && mac != AutoAddress {
+		if !isValidMAC(mac) {
+			return "", fmt.Errorf("%w: invalid MAC %q", ErrInvalidProviderNetwork, mac)
+		}
+		return mac, nil
+	}
+	if s.macs == nil {
+		return "", fmt.Errorf("%w: a MAC is required without MAC pools", ErrInvalidProviderNetwork)
+	}
+	port := &models.LogicalSwitchPort{Addresses: []string{AutoAddress}}
+	if err := s.macs.AssignMACs(ctx, network.ID, port); err != nil {
+		if errors.Is(err, ErrNoMACPool) {
+			return "", fmt.Errorf("%w: %v", ErrInvalidProviderNetwork, err)
+		}
+		return "", err
+	}
+	return port.Addresses[0], nil
+}
+
+// validateAttachNetworks checks that the addresses of a gateway port are
+// prefixes, within the provider network's CIDR if it has one
+func validateAttachNetworks(network *models.ProviderNetwork, networks []string) error {
+	if len(networks) == 0 {
+		return fmt.Errorf("%w: networks are required", ErrInvalidProviderNetwork)
+	}
+	var cidr netip.Prefix
+	if network.CIDR != "" {
+		cidr, _ = netip.ParsePrefix(network.CIDR)
+	}
+	for _, n := range networks {
+		prefix, err := netip.ParsePrefix(n)
+		if err != nil {
+			return fmt.Errorf("%w: invalid network %q, expected an address with a prefix length", ErrInvalidProviderNetwork, n)
+		}
+		if cidr.IsValid() && prefix.Addr().Is4() == cidr.Addr().Is4() && !cidr.Contains(prefix.Addr()) {
+			return fmt.Errorf("%w: %s isn't within provider network %s's CIDR %s", ErrInvalidProviderNetwork, n, network.Name, network.CIDR)
+		}
+	}
+	return nil
+}
+
+// mapsPhysicalNetwork tells whether a chassis's ovn-bridge-mappings map the
+// physical network to a bridge
+func mapsPhysicalNetwork(ch *models.Chassis, physnet string) bool {
+	for _, mapping := range ch.BridgeMappings {
+		if name, bridge, ok := strings.Cut(strings.TrimSpace(mapping), ":"); ok && name == physnet && bridge != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildProviderNetwork describes the provider network of a switch, with the
+// routers whose gateway ports are peered with its router-type ports
+func buildProviderNetwork(sw *models.LogicalSwitch, topology *Topology, gateways []*models.Gateway, chassis []*models.Chassis) *models.ProviderNetwork {
+	network := &models.ProviderNetwork{
+		ID:              sw.UUID,
+		Name:            sw.Name,
+		PhysicalNetwork: sw.ExternalIDs[models.ProviderNetworkKey],
+		CIDR:            sw.ExternalIDs[models.ProviderNetworkCIDRKey],
+		Chassis:         []string{},
+		Routers:         []*models.ProviderNetworkRouter{},
+	}
+	for _, ch := range chassis {
+		if mapsPhysicalNetwork(ch, network.PhysicalNetwork) {
+			network.Chassis = append(network.Chassis, ch.Name)
+		}
+	}
+
+	onSwitch := make(map[string]bool, len(sw.Ports))
+	for _, id := range sw.Ports {
+		onSwitch[id] = true
+	}
+	peers := make(map[string]*models.LogicalSwitchPort)
+	for _, port := range topology.Ports {
+		if !onSwitch[port.UUID] {
+			continue
+		}
+		switch port.Type {
+		case "localnet":
+			if port.Options[models.LocalnetNetworkNameOption] == network.PhysicalNetwork {
+				network.LocalnetPortID = port.UUID
+				network.VLAN = port.Tag
+			}
+		case "router":
+			peers[port.Options[routerPortOption]] = port
+		}
+	}
+
+	routers := make(map[string]*models.LogicalRouter)
+	for _, router := range topology.Routers {
+		for _, id := range router.Ports {
+			routers[id] = router
+		}
+	}
+	byPort := make(map[string]*models.Gateway, len(gateways))
+	for _, gateway := range gateways {
+		if gateway.PortID != "" {
+			byPort[gateway.PortID] = gateway
+		}
+	}
+	for _, lrp := range topology.RouterPorts {
+		peer, ok := peers[lrp.Name]
+		router := routers[lrp.UUID]
+		if (!ok && lrp.ExternalIDs[models.ProviderNetworkKey] != sw.UUID) || router == nil {
+			continue
+		}
+		attached := &models.ProviderNetworkRouter{
+			RouterID:       router.UUID,
+			RouterName:     router.Name,
+			RouterPortID:   lrp.UUID,
+			RouterPortName: lrp.Name,
+			MAC:            lrp.MAC,
+			Networks:       lrp.Networks,
+		}
+		if peer != nil {
+			attached.SwitchPortID = peer.UUID
+		}
+		if gateway, ok := byPort[lrp.UUID]; ok {
+			attached.HAChassisGroup = gateway.HAChassisGroup
+			attached.Chassis = gateway.Chassis
+		}
+		network.Routers = append(network.Routers, attached)
+	}
+	sort.Slice(network.Routers, func(i, j int) bool {
+		return network.Routers[i].RouterName < network.Routers[j].RouterName
+	})
+	return network
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// fakeChassis lists the same chassis every time
+type fakeChassis []*models.Chassis
+
+func (f fakeChassis) ListChassis(ctx context.Context) ([]*models.Chassis, error) {
+	return f, nil
+}
+
+// providerChassis are two chassis mapping physnet1, of which gw-1 may host
+// gateways, and a gateway chassis mapping another network
+var providerChassis = fakeChassis{
+	{Name: "gw-1", Gateway: true, BridgeMappings: []string{"physnet1:br-ex"}},
+	{Name: "hv-1", BridgeMappings: []string{"physnet1:br-ex", "storage:br-storage"}},
+	{Name: "gw-2", Gateway: true, BridgeMappings: []string{"physnet2:br-ex"}},
+}
+
+// providerTopology is provider network public, to which router edge is
+// attached when attached is true
+func providerTopology(attached bool) *Topology {
+	topology := &Topology{
+		Switches: []*models.LogicalSwitch{{
+			UUID:        "sw-public",
+			Name:        "public",
+			Ports:       []string{"lsp-ln"},
+			ExternalIDs: map[string]string{models.ProviderNetworkKey: "physnet1", models.ProviderNetworkCIDRKey: "203.0.113.0/24"},
+		}, {UUID: "sw-web", Name: "web"}},
+		Routers: []*models.LogicalRouter{{UUID: "lr-edge", Name: "edge"}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "lsp-ln", Name: "public-localnet", Type: "localnet", Tag: 100,
+				Options: map[string]string{models.LocalnetNetworkNameOption: "physnet1"}},
+		},
+	}
+	if attached {
+		topology.Switches[0].Ports = append(topology.Switches[0].Ports, "lsp-edge")
+		topology.Routers[0].Ports = []string{"lrp-edge"}
+		topology.Ports = append(topology.Ports, &models.LogicalSwitchPort{
+			UUID: "lsp-edge", Name: "public-edge", Type: "router", Options: map[string]string{"router-port": "edge-public"},
+		})
+		topology.RouterPorts = []*models.LogicalRouterPort{{
+			UUID: "lrp-edge", Name: "edge-public", MAC: "0a:58:cb:00:71:02", Networks: []string{"203.0.113.2/24"},
+		}}
+	}
+	return topology
+}
+
+func TestProviderNetworkService_Create(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	service := NewProviderNetworkService(mockOVN, providerChassis, nil, zap.NewNop())
+
+	_, err := service.Create(ctx, &CreateProviderNetworkRequest{Name: "public", PhysicalNetwork: "physnet3"})
+	assert.ErrorIs(t, err, ErrBridgeMappingMissing)
+	_, err = service.Create(ctx, &CreateProviderNetworkRequest{Name: "public", PhysicalNetwork: "physnet1", VLAN: 5000})
+	assert.ErrorIs(t, err, ErrInvalidProviderNetwork)
+	_, err = service.Create(ctx, &CreateProviderNetworkRequest{Name: "public", PhysicalNetwork: "physnet1", CIDR: "203.0.113.0"})
+	assert.ErrorIs(t, err, ErrInvalidProviderNetwork)
+
+	mockOVN.On("CreateLogicalSwitch", mock.Anything, mock.MatchedBy(func(ls *models.LogicalSwitch) bool {
+		return ls.Name == "public" && ls.ExternalIDs[models.ProviderNetworkKey] == "physnet1" &&
+			ls.ExternalIDs[models.ProviderNetworkCIDRKey] == "203.0.113.0/24"
+	})).Return(&models.LogicalSwitch{UUID: "sw-public", Name: "public"}, nil)
+	mockOVN.On("CreatePort", mock.Anything, "sw-public", &models.LogicalSwitchPort{
+		Name:      "public-localnet",
+		Type:      "localnet",
+		Addresses: []string{"unknown"},
+		Tag:       100,
+		Options:   map[string]string{models.LocalnetNetworkNameOption: "physnet1"},
+	}).Return(&models.LogicalSwitchPort{UUID: "lsp-ln"}, nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(providerTopology(false), nil)
+	mockOVN.On("ListGateways", mock.Anything).Return([]*models.Gateway{}, nil)
+
+	network, err := service.Create(ctx, &CreateProviderNetworkRequest{Name: "public", PhysicalNetwork: "physnet1", VLAN: 100, CIDR: "203.0.113.7/24"})
+	require.NoError(t, err)
+	assert.Equal(t, "sw-public", network.ID)
+	assert.Equal(t, "physnet1", network.PhysicalNetwork)
+	assert.Equal(t, 100, network.VLAN)
+	assert.Equal(t, "lsp-ln", network.LocalnetPortID)
+	assert.Equal(t, []string{"gw-1", "hv-1"}, network.Chassis)
+	assert.Empty(t, network.Routers)
+	mockOVN.AssertExpectations(t)
+
+	// Switches without a localnet port aren't provider networks
+	networks, err := service.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, networks, 1)
+	_, err = service.Get(ctx, "web")
+	assert.ErrorIs(t, err, ErrProviderNetworkNotFound)
+}
+
+func TestProviderNetworkService_AttachRouter(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	service := NewProviderNetworkService(mockOVN, providerChassis, nil, zap.NewNop())
+	mockOVN.On("GetLogicalRouter", mock.Anything, "edge").Return(&models.LogicalRouter{UUID: "lr-edge", Name: "edge"}, nil)
+	mockOVN.On("GetTopology", mock.Anything).Return(providerTopology(false), nil).Times(5)
+	mockOVN.On("GetTopology", mock.Anything).Return(providerTopology(true), nil)
+	mockOVN.On("ListGateways", mock.Anything).Return([]*models.Gateway{{
+		Type: "distributed", RouterID: "lr-edge", PortID: "lrp-edge", Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 1}},
+	}}, nil)
+
+	// Chassis must map the physical network, addresses lie within the CIDR
+	// and a MAC is needed without MAC pools
+	attach := &AttachRouterRequest{RouterID: "edge", Networks: []string{"203.0.113.2/24"}, MAC: "0a:58:cb:00:71:02"}
+	_, err := service.AttachRouter(ctx, "public", &AttachRouterRequest{RouterID: "edge", Networks: attach.Networks, MAC: attach.MAC, Chassis: []string{"gw-2"}})
+	assert.ErrorIs(t, err, ErrBridgeMappingMissing)
+	_, err = service.AttachRouter(ctx, "public", &AttachRouterRequest{RouterID: "edge", Networks: []string{"198.51.100.2/24"}, MAC: attach.MAC})
+	assert.ErrorIs(t, err, ErrInvalidProviderNetwork)
+	_, err = service.AttachRouter(ctx, "public", &AttachRouterRequest{RouterID: "edge", Networks: attach.Networks})
+	assert.ErrorIs(t, err, ErrInvalidProviderNetwork)
+	_, err = service.AttachRouter(ctx, "private", attach)
+	assert.ErrorIs(t, err, ErrProviderNetworkNotFound)
+
+	// Placed on the gateway chassis mapping physnet1
+	mockOVN.On("CreateRouterPort", mock.Anything, "lr-edge", &models.LogicalRouterPort{
+		Name:        "edge-public",
+		MAC:         "0a:58:cb:00:71:02",
+		Networks:    []string{"203.0.113.2/24"},
+		ExternalIDs: map[string]string{models.ProviderNetworkKey: "sw-public"},
+	}).Return(&models.LogicalRouterPort{UUID: "lrp-edge", Name: "edge-public"}, nil)
+	mockOVN.On("CreatePort", mock.Anything, "sw-public", &models.LogicalSwitchPort{
+		Name:      "public-edge",
+		Type:      "router",
+		Addresses: []string{"router"},
+		Options:   map[string]string{"router-port": "edge-public"},
+	}).Return(&models.LogicalSwitchPort{UUID: "lsp-edge"}, nil)
+	mockOVN.On("SetGatewayBinding", mock.Anything, "lrp-edge", &models.GatewayBinding{
+		Chassis: []models.GatewayChassis{{Name: "gw-1", Priority: 1}},
+	}).Return(&models.Gateway{}, nil)
+
+	network, err := service.AttachRouter(ctx, "public", attach)
+	require.NoError(t, err)
+	require.Len(t, network.Routers, 1)
+	assert.Equal(t, &models.ProviderNetworkRouter{
+		RouterID:       "lr-edge",
+		RouterName:     "edge",
+		RouterPortID:   "lrp-edge",
+		RouterPortName: "edge-public",
+		SwitchPortID:   "lsp-edge",
+		MAC:            "0a:58:cb:00:71:02",
+		Networks:       []string{"203.0.113.2/24"},
+		Chassis:        []models.GatewayChassis{{Name: "gw-1", Priority: 1}},
+	}, network.Routers[0])
+	mockOVN.AssertExpectations(t)
+
+	_, err = service.AttachRouter(ctx, "public", attach)
+	assert.ErrorIs(t, err, ErrProviderNetworkConflict)
+	assert.ErrorIs(t, service.Delete(ctx, "public"), ErrProviderNetworkConflict)
+
+	mockOVN.On("DeletePort", mock.Anything, "lsp-edge").Return(nil).Once()
+	mockOVN.On("DeleteRouterPort", mock.Anything, "lrp-edge").Return(nil).Once()
+	_, err = service.DetachRouter(ctx, "public", "edge")
+	require.NoError(t, err)
+	mockOVN.AssertCalled(t, "DeleteRouterPort", mock.Anything, "lrp-edge")
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidProbe is returned for reachability probe requests that can't be
+// sent
+var ErrInvalidProbe = errors.New("invalid reachability probe")
+
+// Probe verdicts, comparing what the logical flows allow with what the
+// dataplane delivers
+const (
+	// ProbeReachable is allowed by the logical flows and answered
+	ProbeReachable = "reachable"
+	// ProbeDegraded is allowed and answered, with some loss
+	ProbeDegraded = "degraded"
+	// ProbeDataplane is allowed by the logical flows yet unanswered: the
+	// problem is in the dataplane, e.g. tunnels, MTU or the destination
+	ProbeDataplane = "dataplane"
+	// ProbeBlocked is dropped by the logical flows and unanswered: the
+	// problem is policy, e.g. an ACL
+	ProbeBlocked = "blocked"
+	// ProbeInconsistent is dropped by the logical flows yet answered: the
+	// chassis' flows don't match the northbound configuration
+	ProbeInconsistent = "inconsistent"
+	// ProbeUnknown is a probe whose logical trace failed
+	ProbeUnknown = "unknown"
+)
+
+const (
+	defaultProbeCount = 5
+	maxProbeCount     = 100
+)
+
+// ProbeSpec is a probe an agent sends from a chassis
+type ProbeSpec struct {
+	Chassis         string `json:"chassis"`
+	ChassisHostname string `json:"chassis_hostname,omitempty"`
+	// Namespace is the network namespace the probe is sent from; the agent
+	// finds the source port's when it's empty
+	Namespace       string `json:"namespace,omitempty"`
+	SourcePort      string `json:"source_port"`
+	SourceIP        string `json:"source_ip"`
+	Protocol        string `json:"protocol"` // icmp, icmp6 or tcp
+	DestinationIP   string `json:"destination_ip"`
+	DestinationPort int    `json:"destination_port,omitempty"`
+	Count           int    `json:"count"`
+	TimeoutMS       int64  `json:"timeout_ms"` // Time the agent has to send every probe
+}
+
+// ProbeResult is what an agent measured: pings answered, or TCP
+// connections established
+type ProbeResult struct {
+	Sent        int     `json:"sent"`
+	Received    int     `json:"received"`
+	LossPercent float64 `json:"loss_percent"`
+	RTTMinMS    float64 `json:"rtt_min_ms,omitempty"`
+	RTTAvgMS    float64 `json:"rtt_avg_ms,omitempty"`
+	RTTMaxMS    float64 `json:"rtt_max_ms,omitempty"`
+}
+
+// ProbeAgent sends probes from chassis, e.g. by running ping or a TCP
+// connect in the network namespace of a port
+type ProbeAgent interface {
+	Probe(ctx context.Context, spec *ProbeSpec) (*ProbeResult, error)
+}
+
+// CommandProbeAgent runs a command reading a ProbeSpec as JSON on its
+// standard input and printing a ProbeResult as JSON, e.g. a script sending
+// the probe over SSH to the chassis
+type CommandProbeAgent struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewCommandProbeAgent creates an agent running command, which is killed
+// after timeout
+func NewCommandProbeAgent(command []string, timeout time.Duration) *CommandProbeAgent {
+	return &CommandProbeAgent{command: command, timeout: timeout}
+}
+
+// Probe runs the command with spec and parses its output
+func (a *CommandProbeAgent) Probe(ctx context.Context, spec *ProbeSpec) (*ProbeResult, error) {
+	if len(a.command) == 0 {
+		return nil, errors.New("no probe agent command")
+	}
+	input, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode probe: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, a.command[0], a.command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("probe agent failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("probe agent failed: %w", err)
+	}
+
+	var result ProbeResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("invalid probe agent output: %w", err)
+	}
+	return &result, nil
+}
+
+// FlowTracer traces packets through the logical flows, as
+// FlowTraceService does with ovn-trace
+type FlowTracer interface {
+	TraceFlow(ctx context.Context, req *ovn.FlowTraceRequest) (*ovn.FlowTraceResult, error)
+}
+
+// PortBindingReader reads where logical ports are bound, as
+// ovn.ChassisInventory does
+type PortBindingReader interface {
+	PortBindings(ctx context.Context, ports []string) (map[string]*models.PortBinding, error)
+}
+
+// ReachabilityProbeRequest is a flow to trace and probe. Probes are sent
+// from the chassis the source port is bound to unless one is given.
+type ReachabilityProbeRequest struct {
+	ovn.FlowTraceRequest
+	Chassis   string `json:"chassis,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Count     int    `json:"count,omitempty"` // Probes sent, 5 by default
+}
+
+// ReachabilityProbeResult is the logical trace of a flow alongside the
+// probe of the dataplane, and the verdict comparing them
+type ReachabilityProbeResult struct {
+	Chassis    string               `json:"chassis"`
+	Trace      *ovn.FlowTraceResult `json:"trace,omitempty"`
+	TraceError string               `json:"trace_error,omitempty"`
+	Probe      *ProbeResult         `json:"probe"`
+	Verdict    string               `json:"verdict"`
+	Summary    string               `json:"summary"`
+}
+
+// ReachabilityProber traces a flow through the logical flows and probes it
+// through the dataplane at the same time, to tell policy issues from
+// dataplane ones
+type ReachabilityProber struct {
+	tracer   FlowTracer
+	bindings PortBindingReader
+	agent    ProbeAgent
+	timeout  time.Duration
+	logger   *zap.Logger
+}
+
+// NewReachabilityProber creates a prober sending probes with agent, which
+// has timeout to send them all
+func NewReachabilityProber(tracer FlowTracer, bindings PortBindingReader, agent ProbeAgent, timeout time.Duration, logger *zap.Logger) *ReachabilityProber {
+	return &ReachabilityProber{tracer: tracer, bindings: bindings, agent: agent, timeout: timeout, logger: logger}
+}
+
+// Probe traces and probes the flow. A failing trace doesn't fail the probe;
+// its error is reported instead, with an unknown verdict.
+func (p *ReachabilityProber) Probe(ctx context.Context, req *ReachabilityProbeRequest) (*ReachabilityProbeResult, error) {
+	if err := validateProbe(req); err != nil {
+		return nil, err
+	}
+
+	spec := &ProbeSpec{
+		Chassis:         req.Chassis,
+		Namespace:       req.Namespace,
+		SourcePort:      req.SourcePort,
+		SourceIP:        req.SourceIP,
+		Protocol:        req.Protocol,
+		DestinationIP:   req.DestinationIP,
+		DestinationPort: req.DestinationPort,
+		Count:           req.Count,
+		TimeoutMS:       p.timeout.Milliseconds(),
+	}
+	if spec.Count == 0 {
+		spec.Count = defaultProbeCount
+	}
+	if spec.Chassis == "" {
+		bindings, err := p.bindings.PortBindings(ctx, []string{req.SourcePort})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the chassis of port %s: %w", req.SourcePort, err)
+		}
+		binding := bindings[req.SourcePort]
+		if binding == nil || binding.Chassis == "" {
+			return nil, fmt.Errorf("%w: port %s isn't bound to a chassis", ErrInvalidProbe, req.SourcePort)
+		}
+		spec.Chassis, spec.ChassisHostname = binding.Chassis, binding.ChassisHostname
+	}
+
+	result := &ReachabilityProbeResult{Chassis: spec.Chassis}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		trace, err := p.tracer.TraceFlow(ctx, &req.FlowTraceRequest)
+		if err != nil {
+			result.TraceError = err.Error()
+			return
+		}
+		result.Trace = trace
+	}()
+	probe, err := p.agent.Probe(ctx, spec)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	result.Probe = probe
+
+	result.Verdict = probeVerdict(result.Trace, probe)
+	result.Summary = probeSummary(result.Verdict, probe)
+	p.logger.Info("Reachability probed",
+		zap.String("source_port", req.SourcePort),
+		zap.String("destination_ip", req.DestinationIP),
+		zap.String("chassis", spec.Chassis),
+		zap.String("verdict", result.Verdict))
+	return result, nil
+}
+
+func validateProbe(req *ReachabilityProbeRequest) error {
+	switch req.Protocol {
+	case "icmp", "icmp6":
+	case "tcp":
+		if req.DestinationPort == 0 {
+			return fmt.Errorf("%w: destination_port_num is required for tcp probes", ErrInvalidProbe)
+		}
+	default:
+		return fmt.Errorf("%w: probes are sent with icmp, icmp6 or tcp, not %s", ErrInvalidProbe, req.Protocol)
+	}
+	if req.Count < 0 || req.Count > maxProbeCount {
+		return fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidProbe, maxProbeCount)
+	}
+	return nil
+}
+
+// probeVerdict compares the logical trace with the probe; trace is nil when
+// it failed
+func probeVerdict(trace *ovn.FlowTraceResult, probe *ProbeResult) string {
+	answered := probe.Received > 0
+	switch {
+	case trace == nil:
+		return ProbeUnknown
+	case trace.ReachesDestination && answered && probe.Received < probe.Sent:
+		return ProbeDegraded
+	case trace.ReachesDestination && answered:
+		return ProbeReachable
+	case trace.ReachesDestination:
+		return ProbeDataplane
+	case answered:
+		return ProbeInconsistent
+	default:
+		return ProbeBlocked
+	}
+}
+
+func probeSummary(verdict string, probe *ProbeResult) string {
+	measured := fmt.Sprintf("%d/%d answered", probe.Received, probe.Sent)
+	if probe.Received > 0 {
+		measured += fmt.Sprintf(", avg RTT %.2fms", probe.RTTAvgMS)
+	}
+	switch verdict {
+	case ProbeReachable:
+		return "Allowed by the logical flows and reachable: " + measured
+	case ProbeDegraded:
+		return "Allowed by the logical flows, with loss in the dataplane: " + measured
+	case ProbeDataplane:
+		return "Allowed by the logical flows but unreachable: a dataplane issue"
+	case ProbeBlocked:
+		return "Dropped by the logical flows: a policy issue"
+	case ProbeInconsistent:
+		return "Dropped by the logical flows but reachable: the chassis' flows don't match the configuration (" + measured + ")"
+	default:
+		return "Logical trace failed: " + measured
+	}
+}
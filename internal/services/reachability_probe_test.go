@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+type fakeFlowTracer struct {
+	reaches bool
+	err     error
+}
+
+func (f *fakeFlowTracer) TraceFlow(ctx context.Context, req *ovn.FlowTraceRequest) (*ovn.FlowTraceResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ovn.FlowTraceResult{Request: req, Success: true, ReachesDestination: f.reaches}, nil
+}
+
+type fakePortBindings map[string]*models.PortBinding
+
+func (f fakePortBindings) PortBindings(ctx context.Context, ports []string) (map[string]*models.PortBinding, error) {
+	return f, nil
+}
+
+type fakeProbeAgent struct {
+	result *ProbeResult
+	err    error
+	spec   *ProbeSpec
+}
+
+func (f *fakeProbeAgent) Probe(ctx context.Context, spec *ProbeSpec) (*ProbeResult, error) {
+	f.spec = spec
+	return f.result, f.err
+}
+
+func probeRequest(protocol string, port int) *ReachabilityProbeRequest {
+	return &ReachabilityProbeRequest{FlowTraceRequest: ovn.FlowTraceRequest{
+		SourcePort: "web-1", SourceMAC: "00:00:00:00:00:01", SourceIP: "10.0.0.11",
+		DestinationIP: "10.0.1.21", Protocol: protocol, DestinationPort: port,
+	}}
+}
+
+func TestReachabilityProber_Verdicts(t *testing.T) {
+	bindings := fakePortBindings{"web-1": {Chassis: "chassis-1", ChassisHostname: "compute-1"}}
+	answered := &ProbeResult{Sent: 5, Received: 5, RTTAvgMS: 0.42}
+	lossy := &ProbeResult{Sent: 5, Received: 3, LossPercent: 40, RTTAvgMS: 0.5}
+	unanswered := &ProbeResult{Sent: 5, LossPercent: 100}
+
+	tests := []struct {
+		name    string
+		tracer  *fakeFlowTracer
+		probe   *ProbeResult
+		verdict string
+	}{
+		{"reachable", &fakeFlowTracer{reaches: true}, answered, ProbeReachable},
+		{"degraded", &fakeFlowTracer{reaches: true}, lossy, ProbeDegraded},
+		{"dataplane issue", &fakeFlowTracer{reaches: true}, unanswered, ProbeDataplane},
+		{"policy issue", &fakeFlowTracer{}, unanswered, ProbeBlocked},
+		{"flows out of sync", &fakeFlowTracer{}, answered, ProbeInconsistent},
+		{"trace failed", &fakeFlowTracer{err: errors.New("ovn-trace not found")}, answered, ProbeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &fakeProbeAgent{result: tt.probe}
+			prober := NewReachabilityProber(tt.tracer, bindings, agent, 10*time.Second, zap.NewNop())
+
+			result, err := prober.Probe(context.Background(), probeRequest("icmp", 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.verdict, result.Verdict)
+			assert.Equal(t, tt.probe, result.Probe)
+			assert.Equal(t, "chassis-1", result.Chassis)
+			assert.NotEmpty(t, result.Summary)
+			assert.Equal(t, tt.tracer.err != nil, result.TraceError != "")
+
+			assert.Equal(t, &ProbeSpec{
+				Chassis: "chassis-1", ChassisHostname: "compute-1", SourcePort: "web-1", SourceIP: "10.0.0.11",
+				Protocol: "icmp", DestinationIP: "10.0.1.21", Count: defaultProbeCount, TimeoutMS: 10000,
+			}, agent.spec)
+		})
+	}
+}
+
+func TestReachabilityProber_Chassis(t *testing.T) {
+	agent := &fakeProbeAgent{result: &ProbeResult{Sent: 3, Received: 3}}
+	prober := NewReachabilityProber(&fakeFlowTracer{reaches: true}, fakePortBindings{}, agent, time.Second, zap.NewNop())
+
+	// An unbound port can't be probed from its chassis
+	_, err := prober.Probe(context.Background(), probeRequest("icmp", 0))
+	assert.ErrorIs(t, err, ErrInvalidProbe)
+
+	// unless the chassis and namespace are given
+	req := probeRequest("tcp", 443)
+	req.Chassis, req.Namespace, req.Count = "chassis-2", "ns-web-1", 3
+	result, err := prober.Probe(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "chassis-2", result.Chassis)
+	assert.Equal(t, "ns-web-1", agent.spec.Namespace)
+	assert.Equal(t, 443, agent.spec.DestinationPort)
+	assert.Equal(t, 3, agent.spec.Count)
+}
+
+func TestReachabilityProber_Validation(t *testing.T) {
+	agent := &fakeProbeAgent{result: &ProbeResult{}}
+	prober := NewReachabilityProber(&fakeFlowTracer{}, fakePortBindings{}, agent, time.Second, zap.NewNop())
+
+	for _, req := range []*ReachabilityProbeRequest{
+		probeRequest("udp", 53),
+		probeRequest("tcp", 0),
+		{FlowTraceRequest: ovn.FlowTraceRequest{Protocol: "icmp"}, Count: maxProbeCount + 1},
+	} {
+		_, err := prober.Probe(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidProbe, req.Protocol)
+	}
+	assert.Nil(t, agent.spec, "invalid probes aren't sent")
+}
+
+func TestCommandProbeAgent(t *testing.T) {
+	spec := &ProbeSpec{Chassis: "chassis-1", Protocol: "icmp", DestinationIP: "10.0.1.21", Count: 2}
+
+	// The agent reads the spec and prints the result
+	agent := NewCommandProbeAgent([]string{"sh", "-c",
+		`grep -q '"chassis":"chassis-1"' && echo '{"sent": 2, "received": 2, "rtt_avg_ms": 0.3}'`}, 5*time.Second)
+	result, err := agent.Probe(context.Background(), spec)
+	require.NoError(t, err)
+	assert.Equal(t, &ProbeResult{Sent: 2, Received: 2, RTTAvgMS: 0.3}, result)
+
+	// Its errors are reported with what it printed on stderr
+	agent = NewCommandProbeAgent([]string{"sh", "-c", "echo 'no such namespace' >&2; exit 1"}, 5*time.Second)
+	_, err = agent.Probe(context.Background(), spec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such namespace")
+
+	agent = NewCommandProbeAgent([]string{"sh", "-c", "echo not json"}, 5*time.Second)
+	_, err = agent.Probe(context.Background(), spec)
+	assert.ErrorContains(t, err, "invalid probe agent output")
+}
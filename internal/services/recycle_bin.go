@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrTrashedResourceNotFound is returned for recycle bin entries that
+	// don't exist, have expired or belong to another tenant
+	ErrTrashedResourceNotFound = errors.New("trashed resource not found")
+	// ErrNotRecyclable is returned when a resource can't be kept in the
+	// recycle bin, e.g. an ACL applied to a port group
+	ErrNotRecyclable = errors.New("resource can't be kept in the recycle bin")
+)
+
+// recycleBinPurgeInterval bounds how often expired entries are looked for
+const recycleBinPurgeInterval = time.Hour
+
+// TrashedResource is a deleted switch, router, port or ACL kept in the
+// recycle bin until it expires, with the resources deleted along with it:
+// a switch's ports and ACLs, and a router's static routes and policies
+type TrashedResource struct {
+	ID         string    `json:"id"`
+	Resource   string    `json:"resource"` // models.ResourceSwitch, ...
+	ResourceID string    `json:"resource_id"`
+	Name       string    `json:"name,omitempty"`
+	ParentID   string    `json:"parent_id,omitempty"` // The switch of a port or ACL
+	TenantID   string    `json:"tenant_id,omitempty"`
+	Cluster    string    `json:"cluster,omitempty"` // The OVN cluster it's restored to, "" for the default
+	DeletedAt  time.Time `json:"deleted_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	Switch *models.LogicalSwitch     `json:"switch,omitempty"`
+	Router *models.LogicalRouter     `json:"router,omitempty"`
+	Port   *models.LogicalSwitchPort `json:"port,omitempty"`
+	ACL    *models.ACL               `json:"acl,omitempty"`
+
+	Ports          []*models.LogicalSwitchPort `json:"ports,omitempty"`
+	ACLs           []*models.ACL               `json:"acls,omitempty"`
+	StaticRoutes   []*models.StaticRoute       `json:"static_routes,omitempty"`
+	RouterPolicies []*models.RouterPolicy      `json:"router_policies,omitempty"`
+
+	// RestoredID is the UUID of the resource recreated by a restore
+	RestoredID string `json:"restored_id,omitempty"`
+}
+
+// RecycleBin soft-deletes switches, routers, ports and ACLs: their
+// definitions are archived before they are deleted from OVN, and can be
+// restored until the retention window passes
+type RecycleBin struct {
+	store     TrashStore
+	ovn       OVNServiceInterface
+	retention time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewRecycleBin creates a recycle bin keeping deleted resources for
+// retention
+func NewRecycleBin(store TrashStore, ovnService OVNServiceInterface, retention time.Duration, logger *zap.Logger) *RecycleBin {
+	return &RecycleBin{
+		store:     store,
+		ovn:       ovnService,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Delete archives a resource, with those deleted along with it, then deletes
+// it. A failed delete leaves nothing in the recycle bin.
+func (b *RecycleBin) Delete(ctx context.Context, resource, id string) (*TrashedResource, error) {
+	item := &TrashedResource{
+		ID:       uuid.New().String(),
+		Resource: resource,
+		TenantID: getTenantFromContext(ctx),
+		Cluster:  getOVNClusterFromContext(ctx),
+	}
+
+	var del func(context.Context, string) error
+	var err error
+	switch resource {
+	case models.ResourceSwitch:
+		del, err = b.ovn.DeleteLogicalSwitch, b.archiveSwitch(ctx, item, id)
+	case models.ResourceRouter:
+		del, err = b.ovn.DeleteLogicalRouter, b.archiveRouter(ctx, item, id)
+	case models.ResourcePort:
+		del, err = b.ovn.DeletePort, b.archivePort(ctx, item, id)
+	case models.ResourceACL:
+		del, err = b.ovn.DeleteACL, b.archiveACL(ctx, item, id)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNotRecyclable, resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item.DeletedAt = b.now().UTC()
+	item.ExpiresAt = item.DeletedAt.Add(b.retention)
+	if err := b.store.Save(item); err != nil {
+		return nil, fmt.Errorf("failed to archive %s: %w", resource, err)
+	}
+
+	if err := del(ctx, item.ResourceID); err != nil {
+		if removeErr := b.store.Delete(item.ID); removeErr != nil {
+			b.logger.Warn("Failed to remove the archive of a resource that wasn't deleted",
+				zap.String("trash_id", item.ID),
+				zap.Error(removeErr))
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+func (b *RecycleBin) archiveSwitch(ctx context.Context, item *TrashedResource, id string) error {
+	sw, err := b.ovn.GetLogicalSwitch(ctx, id)
+	if err != nil {
+		return err
+	}
+	ports, err := b.ovn.ListPorts(ctx, sw.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to list ports of switch %s: %w", sw.UUID, err)
+	}
+	acls, err := b.ovn.ListACLs(ctx, sw.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to list ACLs of switch %s: %w", sw.UUID, err)
+	}
+	item.ResourceID, item.Name = sw.UUID, sw.Name
+	item.Switch, item.Ports, item.ACLs = sw, ports, acls
+	return nil
+}
+
+func (b *RecycleBin) archiveRouter(ctx context.Context, item *TrashedResource, id string) error {
+	lr, err := b.ovn.GetLogicalRouter(ctx, id)
+	if err != nil {
+		return err
+	}
+	routes, err := b.ovn.ListStaticRoutes(ctx, lr.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to list static routes of router %s: %w", lr.UUID, err)
+	}
+	policies, err := b.ovn.ListRouterPolicies(ctx, lr.UUID)
+	if err != nil {
+		return fmt.Errorf("failed to list policies of router %s: %w", lr.UUID, err)
+	}
+	item.ResourceID, item.Name = lr.UUID, lr.Name
+	item.Router, item.StaticRoutes, item.RouterPolicies = lr, routes, policies
+	return nil
+}
+
+func (b *RecycleBin) archivePort(ctx context.Context, item *TrashedResource, id string) error {
+	port, err := b.ovn.GetPort(ctx, id)
+	if err != nil {
+		return err
+	}
+	sw, err := b.parentSwitch(ctx, func(sw *models.LogicalSwitch) []string { return sw.Ports }, port.UUID)
+	if err != nil {
+		return err
+	}
+	if sw == nil {
+		return fmt.Errorf("%w: port %s isn't on a logical switch", ErrNotRecyclable, port.UUID)
+	}
+	item.ResourceID, item.Name, item.ParentID = port.UUID, port.Name, sw.UUID
+	item.Port = port
+	return nil
+}
+
+func (b *RecycleBin) archiveACL(ctx context.Context, item *TrashedResource, id string) error {
+	acl, err := b.ovn.GetACL(ctx, id)
+	if err != nil {
+		return err
+	}
+	sw, err := b.parentSwitch(ctx, func(sw *models.LogicalSwitch) []string { return sw.ACLs }, acl.UUID)
+	if err != nil {
+		return err
+	}
+	if sw == nil {
+		return fmt.Errorf("%w: ACL %s isn't applied to a logical switch", ErrNotRecyclable, acl.UUID)
+	}
+	item.ResourceID, item.Name, item.ParentID = acl.UUID, acl.Name, sw.UUID
+	item.ACL = acl
+	return nil
+}
+
+// parentSwitch returns the switch whose column lists id, or nil
+func (b *RecycleBin) parentSwitch(ctx context.Context, column func(*models.LogicalSwitch) []string, id string) (*models.LogicalSwitch, error) {
+	switches, err := b.ovn.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logical switches: %w", err)
+	}
+	for _, sw := range switches {
+		if containsString(column(sw), id) {
+			return sw, nil
+		}
+	}
+	return nil, nil
+}
+
+// List lists the unexpired resources in the recycle bin, most recently
+// deleted first. Within a tenant's context, only its resources are listed.
+func (b *RecycleBin) List(ctx context.Context) ([]*TrashedResource, error) {
+	items, err := b.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := getTenantFromContext(ctx)
+	now := b.now()
+	result := make([]*TrashedResource, 0, len(items))
+	for _, item := range items {
+		if (tenantID == "" || item.TenantID == tenantID) && now.Before(item.ExpiresAt) {
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DeletedAt.After(result[j].DeletedAt)
+	})
+	return result, nil
+}
+
+// Get returns a resource in the recycle bin
+func (b *RecycleBin) Get(ctx context.Context, id string) (*TrashedResource, error) {
+	item, err := b.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	tenantID := getTenantFromContext(ctx)
+	if (tenantID != "" && item.TenantID != tenantID) || !b.now().Before(item.ExpiresAt) {
+		return nil, ErrTrashedResourceNotFound
+	}
+	return item, nil
+}
+
+// Restore recreates a resource, with those deleted along with it, and
+// removes it from the recycle bin. The resources get new UUIDs; a switch or
+// router whose dependents can't all be recreated is deleted again and kept
+// in the recycle bin.
+func (b *RecycleBin) Restore(ctx context.Context, id string) (*TrashedResource, error) {
+	item, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = ContextWithOVNCluster(ctx, item.Cluster)
+	switch {
+	case item.Switch != nil:
+		err = b.restoreSwitch(ctx, item)
+	case item.Router != nil:
+		err = b.restoreRouter(ctx, item)
+	case item.Port != nil:
+		port := *item.Port
+		clearPort(&port)
+		var created *models.LogicalSwitchPort
+		if created, err = b.ovn.CreatePort(ctx, item.ParentID, &port); err == nil {
+			item.RestoredID = created.UUID
+		}
+	case item.ACL != nil:
+		acl := *item.ACL
+		clearACL(&acl)
+		var created *models.ACL
+		if created, err = b.ovn.CreateACL(ctx, item.ParentID, &acl); err == nil {
+			item.RestoredID = created.UUID
+		}
+	default:
+		err = fmt.Errorf("%w: entry %s holds no resource", ErrNotRecyclable, item.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore %s %s: %w", item.Resource, item.ResourceID, err)
+	}
+
+	if err := b.store.Delete(item.ID); err != nil {
+		b.logger.Warn("Failed to remove a restored resource from the recycle bin",
+			zap.String("trash_id", item.ID),
+			zap.Error(err))
+	}
+	return item, nil
+}
+
+func (b *RecycleBin) restoreSwitch(ctx context.Context, item *TrashedResource) error {
+	sw := *item.Switch
+	sw.UUID, sw.Ports, sw.ACLs = "", nil, nil
+	sw.ExternalIDs = restoredExternalIDs(sw.ExternalIDs)
+	sw.Ownership = nil
+	created, err := b.ovn.CreateLogicalSwitch(ctx, &sw)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		for _, archived := range item.Ports {
+			port := *archived
+			clearPort(&port)
+			if _, err := b.ovn.CreatePort(ctx, created.UUID, &port); err != nil {
+				return fmt.Errorf("port %s: %w", archived.Name, err)
+			}
+		}
+		if len(item.ACLs) > 0 {
+			acls := make([]*models.ACL, len(item.ACLs))
+			for i, archived := range item.ACLs {
+				acl := *archived
+				clearACL(&acl)
+				acls[i] = &acl
+			}
+			if _, err := b.ovn.CreateACLs(ctx, created.UUID, acls); err != nil {
+				return fmt.Errorf("ACLs: %w", err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		// Deleting the switch deletes the ports and ACLs recreated so far
+		if deleteErr := b.ovn.DeleteLogicalSwitch(ctx, created.UUID); deleteErr != nil {
+			b.logger.Error("Failed to delete a partially restored switch",
+				zap.String("switch_id", created.UUID),
+				zap.Error(deleteErr))
+		}
+		return err
+	}
+	item.RestoredID = created.UUID
+	return nil
+}
+
+func (b *RecycleBin) restoreRouter(ctx context.Context, item *TrashedResource) error {
+	lr := *item.Router
+	lr.UUID, lr.Ports, lr.Policies, lr.StaticRoutes, lr.NAT = "", nil, nil, nil, nil
+	lr.ExternalIDs = restoredExternalIDs(lr.ExternalIDs)
+	lr.Ownership = nil
+	created, err := b.ovn.CreateLogicalRouter(ctx, &lr)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if len(item.StaticRoutes) > 0 {
+			routes := make([]*models.StaticRoute, len(item.StaticRoutes))
+			for i, archived := range item.StaticRoutes {
+				route := *archived
+				route.UUID, route.RouterID, route.BFDStatus = "", "", ""
+				routes[i] = &route
+			}
+			if _, err := b.ovn.CreateStaticRoutes(ctx, created.UUID, routes); err != nil {
+				return fmt.Errorf("static routes: %w", err)
+			}
+		}
+		for _, archived := range item.RouterPolicies {
+			policy := *archived
+			policy.UUID, policy.RouterID = "", ""
+			policy.ExternalIDs = restoredExternalIDs(policy.ExternalIDs)
+			if _, err := b.ovn.CreateRouterPolicy(ctx, created.UUID, &policy); err != nil {
+				return fmt.Errorf("policy %d %s: %w", archived.Priority, archived.Match, err)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		// The router's routes and policies go with it
+		if deleteErr := b.ovn.DeleteLogicalRouter(ctx, created.UUID); deleteErr != nil {
+			b.logger.Error("Failed to delete a partially restored router",
+				zap.String("router_id", created.UUID),
+				zap.Error(deleteErr))
+		}
+		return err
+	}
+	item.RestoredID = created.UUID
+	return nil
+}
+
+// clearPort drops the fields OVN sets from an archived port
+func clearPort(port *models.LogicalSwitchPort) {
+	port.UUID, port.SwitchID = "", ""
+	port.Up, port.Binding, port.Ownership = nil, nil, nil
+	port.ExternalIDs = restoredExternalIDs(port.ExternalIDs)
+}
+
+// clearACL drops the fields OVN sets from an archived ACL
+func clearACL(acl *models.ACL) {
+	acl.UUID = ""
+	acl.Ownership = nil
+	acl.ExternalIDs = restoredExternalIDs(acl.ExternalIDs)
+}
+
+// restoredExternalIDs copies the external IDs of an archived resource,
+// without the timestamps and ownership its recreation records anew
+func restoredExternalIDs(externalIDs map[string]string) map[string]string {
+	result := make(map[string]string, len(externalIDs))
+	for k, v := range externalIDs {
+		switch k {
+		case "created_at", "updated_at", models.CreatedByKey, models.CreatedSourceKey, models.UpdatedByKey, models.UpdatedSourceKey:
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// Purge permanently deletes a resource from the recycle bin
+func (b *RecycleBin) Purge(ctx context.Context, id string) error {
+	if _, err := b.Get(ctx, id); err != nil {
+		return err
+	}
+	return b.store.Delete(id)
+}
+
+// PurgeExpired permanently deletes the resources whose retention has
+// passed
+func (b *RecycleBin) PurgeExpired() error {
+	items, err := b.store.List()
+	if err != nil {
+		return err
+	}
+	now := b.now()
+	for _, item := range items {
+		if now.Before(item.ExpiresAt) {
+			continue
+		}
+		if err := b.store.Delete(item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run purges expired resources until ctx is done
+func (b *RecycleBin) Run(ctx context.Context) {
+	interval := recycleBinPurgeInterval
+	if b.retention < interval {
+		interval = b.retention
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.PurgeExpired(); err != nil {
+				b.logger.Error("Failed to purge expired resources from the recycle bin", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// TrashStore persists the resources in the recycle bin
+type TrashStore interface {
+	// Save creates or replaces an entry
+	Save(item *TrashedResource) error
+
+	// Get returns an entry, or ErrTrashedResourceNotFound
+	Get(id string) (*TrashedResource, error)
+
+	// List returns all entries, oldest first
+	List() ([]*TrashedResource, error)
+
+	// Delete removes an entry; removing a missing entry isn't an error
+	Delete(id string) error
+}
+
+// FileTrashStore stores each recycle bin entry as a JSON file in a directory
+type FileTrashStore struct {
+	basePath string
+	mu       sync.RWMutex
+}
+
+// NewFileTrashStore creates a recycle bin store in basePath
+func NewFileTrashStore(basePath string) (*FileTrashStore, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	return &FileTrashStore{
+		basePath: basePath,
+	}, nil
+}
+
+// Save writes the entry, replacing the file atomically
+func (s *FileTrashStore) Save(item *TrashedResource) error {
+	if !changesetIDPattern.MatchString(item.ID) {
+		return fmt.Errorf("invalid trash id: %q", item.ID)
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trashed resource: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp := s.path(item.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write trashed resource: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(item.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write trashed resource: %w", err)
+	}
+	return nil
+}
+
+// Get reads an entry
+func (s *FileTrashStore) Get(id string) (*TrashedResource, error) {
+	if !changesetIDPattern.MatchString(id) {
+		return nil, ErrTrashedResourceNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(s.path(id))
+}
+
+// List reads all entries
+func (s *FileTrashStore) List() ([]*TrashedResource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := filepath.Glob(filepath.Join(s.basePath, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trashed resources: %w", err)
+	}
+
+	items := make([]*TrashedResource, 0, len(files))
+	for _, file := range files {
+		item, err := s.read(file)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].DeletedAt.Before(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Delete removes an entry's file
+func (s *FileTrashStore) Delete(id string) error {
+	if !changesetIDPattern.MatchString(id) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete trashed resource: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTrashStore) path(id string) string {
+	return filepath.Join(s.basePath, id+".json")
+}
+
+func (s *FileTrashStore) read(path string) (*TrashedResource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTrashedResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to read trashed resource: %w", err)
+	}
+
+	var item TrashedResource
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse trashed resource %s: %w", filepath.Base(path), err)
+	}
+	return &item, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func newTestRecycleBin(t *testing.T, ovnService OVNServiceInterface) *RecycleBin {
+	store, err := NewFileTrashStore(t.TempDir())
+	require.NoError(t, err)
+	return NewRecycleBin(store, ovnService, 24*time.Hour, zap.NewNop())
+}
+
+func TestRecycleBin_DeleteAndRestoreSwitch(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	bin := newTestRecycleBin(t, mockOVN)
+	ctx := ContextWithOVNCluster(context.Background(), "east")
+
+	sw := &models.LogicalSwitch{
+		UUID: "sw-1", Name: "web", Ports: []string{"p-1"}, ACLs: []string{"acl-1"},
+		ExternalIDs: map[string]string{"env": "prod", "created_at": "2024-05-01T12:00:00Z", models.CreatedByKey: "alice"},
+	}
+	port := &models.LogicalSwitchPort{UUID: "p-1", Name: "web-1", SwitchID: "sw-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.1"}}
+	acl := &models.ACL{UUID: "acl-1", Name: "allow-web", Match: "tcp.dst == 80", Action: "allow", Direction: "to-lport", Priority: 1000}
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "web").Return(sw, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-1").Return([]*models.LogicalSwitchPort{port}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{acl}, nil)
+	mockOVN.On("DeleteLogicalSwitch", mock.Anything, "sw-1").Return(nil)
+
+	item, err := bin.Delete(ctx, models.ResourceSwitch, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "sw-1", item.ResourceID)
+	assert.Equal(t, "web", item.Name)
+	assert.Equal(t, "east", item.Cluster)
+	assert.Equal(t, item.DeletedAt.Add(24*time.Hour), item.ExpiresAt)
+
+	items, err := bin.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, item.ID, items[0].ID)
+	require.Len(t, items[0].Ports, 1)
+	require.Len(t, items[0].ACLs, 1)
+
+	// The switch is recreated, in the cluster it was deleted from, without
+	// the state OVN assigns, then its ports and ACLs
+	mockOVN.On("CreateLogicalSwitch", mock.Anything, mock.MatchedBy(func(ls *models.LogicalSwitch) bool {
+		return ls.UUID == "" && ls.Name == "web" && ls.Ports == nil && ls.ACLs == nil &&
+			len(ls.ExternalIDs) == 1 && ls.ExternalIDs["env"] == "prod"
+	})).Run(func(args mock.Arguments) {
+		assert.Equal(t, "east", getOVNClusterFromContext(args.Get(0).(context.Context)))
+	}).Return(&models.LogicalSwitch{UUID: "sw-2", Name: "web"}, nil)
+	mockOVN.On("CreatePort", mock.Anything, "sw-2", mock.MatchedBy(func(p *models.LogicalSwitchPort) bool {
+		return p.UUID == "" && p.SwitchID == "" && p.Name == "web-1"
+	})).Return(&models.LogicalSwitchPort{UUID: "p-2"}, nil)
+	mockOVN.On("CreateACLs", mock.Anything, "sw-2", mock.MatchedBy(func(acls []*models.ACL) bool {
+		return len(acls) == 1 && acls[0].UUID == "" && acls[0].Match == "tcp.dst == 80"
+	})).Return([]*models.ACL{{UUID: "acl-2"}}, nil)
+
+	restored, err := bin.Restore(context.Background(), item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "sw-2", restored.RestoredID)
+	mockOVN.AssertExpectations(t)
+
+	_, err = bin.Get(context.Background(), item.ID)
+	assert.ErrorIs(t, err, ErrTrashedResourceNotFound)
+}
+
+func TestRecycleBin_FailedDeleteLeavesNothing(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	bin := newTestRecycleBin(t, mockOVN)
+
+	mockOVN.On("GetLogicalRouter", mock.Anything, "lr-1").Return(&models.LogicalRouter{UUID: "lr-1", Name: "edge"}, nil)
+	mockOVN.On("ListStaticRoutes", mock.Anything, "lr-1").Return([]*models.StaticRoute{{UUID: "r-1", IPPrefix: "0.0.0.0/0", Nexthop: "192.0.2.1"}}, nil)
+	mockOVN.On("ListRouterPolicies", mock.Anything, "lr-1").Return([]*models.RouterPolicy{}, nil)
+	mockOVN.On("DeleteLogicalRouter", mock.Anything, "lr-1").Return(errors.New("router has 2 ports"))
+
+	_, err := bin.Delete(context.Background(), models.ResourceRouter, "lr-1")
+	assert.EqualError(t, err, "router has 2 ports")
+
+	items, err := bin.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestRecycleBin_PortAndACLParents(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	bin := newTestRecycleBin(t, mockOVN)
+	ctx := context.Background()
+
+	mockOVN.On("GetPort", mock.Anything, "p-1").Return(&models.LogicalSwitchPort{UUID: "p-1", Name: "web-1"}, nil)
+	mockOVN.On("GetACL", mock.Anything, "acl-1").Return(&models.ACL{UUID: "acl-1"}, nil)
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-0"},
+		{UUID: "sw-1", Ports: []string{"p-1"}},
+	}, nil)
+	mockOVN.On("DeletePort", mock.Anything, "p-1").Return(nil)
+
+	item, err := bin.Delete(ctx, models.ResourcePort, "p-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sw-1", item.ParentID)
+
+	// An ACL on no switch, e.g. one of a port group, can't be restored
+	_, err = bin.Delete(ctx, models.ResourceACL, "acl-1")
+	assert.ErrorIs(t, err, ErrNotRecyclable)
+	mockOVN.AssertNotCalled(t, "DeleteACL", mock.Anything, mock.Anything)
+
+	mockOVN.On("CreatePort", mock.Anything, "sw-1", mock.Anything).Return(&models.LogicalSwitchPort{UUID: "p-2"}, nil)
+	restored, err := bin.Restore(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "p-2", restored.RestoredID)
+}
+
+func TestRecycleBin_FailedRestoreKeepsEntry(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	bin := newTestRecycleBin(t, mockOVN)
+	ctx := context.Background()
+
+	mockOVN.On("GetLogicalSwitch", mock.Anything, "sw-1").Return(&models.LogicalSwitch{UUID: "sw-1", Name: "web"}, nil)
+	mockOVN.On("ListPorts", mock.Anything, "sw-1").Return([]*models.LogicalSwitchPort{{UUID: "p-1", Name: "web-1"}}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{}, nil)
+	mockOVN.On("DeleteLogicalSwitch", mock.Anything, "sw-1").Return(nil)
+	item, err := bin.Delete(ctx, models.ResourceSwitch, "sw-1")
+	require.NoError(t, err)
+
+	// The partially restored switch is deleted again
+	mockOVN.On("CreateLogicalSwitch", mock.Anything, mock.Anything).Return(&models.LogicalSwitch{UUID: "sw-2"}, nil)
+	mockOVN.On("CreatePort", mock.Anything, "sw-2", mock.Anything).Return((*models.LogicalSwitchPort)(nil), errors.New("address already in use"))
+	mockOVN.On("DeleteLogicalSwitch", mock.Anything, "sw-2").Return(nil)
+
+	_, err = bin.Restore(ctx, item.ID)
+	assert.ErrorContains(t, err, "address already in use")
+	mockOVN.AssertCalled(t, "DeleteLogicalSwitch", mock.Anything, "sw-2")
+
+	_, err = bin.Get(ctx, item.ID)
+	assert.NoError(t, err)
+}
+
+func TestRecycleBin_TenantsAndExpiry(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	bin := newTestRecycleBin(t, mockOVN)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bin.now = func() time.Time { return now }
+
+	mockOVN.On("GetLogicalRouter", mock.Anything, mock.Anything).Return(&models.LogicalRouter{UUID: "lr-1"}, nil)
+	mockOVN.On("ListStaticRoutes", mock.Anything, mock.Anything).Return([]*models.StaticRoute{}, nil)
+	mockOVN.On("ListRouterPolicies", mock.Anything, mock.Anything).Return([]*models.RouterPolicy{}, nil)
+	mockOVN.On("DeleteLogicalRouter", mock.Anything, mock.Anything).Return(nil)
+
+	acme, err := bin.Delete(ContextWithTenant(context.Background(), "acme"), models.ResourceRouter, "lr-1")
+	require.NoError(t, err)
+	now = now.Add(time.Hour)
+	other, err := bin.Delete(ContextWithTenant(context.Background(), "globex"), models.ResourceRouter, "lr-1")
+	require.NoError(t, err)
+
+	// Tenants only see their own deleted resources
+	items, err := bin.List(ContextWithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, acme.ID, items[0].ID)
+	_, err = bin.Get(ContextWithTenant(context.Background(), "acme"), other.ID)
+	assert.ErrorIs(t, err, ErrTrashedResourceNotFound)
+	assert.ErrorIs(t, bin.Purge(ContextWithTenant(context.Background(), "acme"), other.ID), ErrTrashedResourceNotFound)
+
+	items, err = bin.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, other.ID, items[0].ID, "most recently deleted first")
+
+	// Expired entries can't be restored, and are purged
+	now = now.Add(23*time.Hour + time.Minute)
+	_, err = bin.Restore(context.Background(), acme.ID)
+	assert.ErrorIs(t, err, ErrTrashedResourceNotFound)
+	require.NoError(t, bin.PurgeExpired())
+	stored, err := bin.store.List()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, other.ID, stored[0].ID)
+
+	require.NoError(t, bin.Purge(context.Background(), other.ID))
+	items, err = bin.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ResourceChangeStore records the changes made to resources. *db.DB
+// implements it.
+type ResourceChangeStore interface {
+	CreateResourceChange(ctx context.Context, change *models.ResourceChange) error
+
+	// ListResourceChanges lists the changes of a resource
+	ListResourceChanges(ctx context.Context, resourceID string) ([]*models.ResourceChange, error)
+}
+
+// ResourceHistory keeps the audit trail of the logical network: it records
+// the resource events published for writes through the API, with who made
+// them and through what client, and lists a resource's changes
+type ResourceHistory struct {
+	store  ResourceChangeStore
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewResourceHistory creates a history recorded in store
+func NewResourceHistory(store ResourceChangeStore, logger *zap.Logger) *ResourceHistory {
+	return &ResourceHistory{store: store, logger: logger, now: time.Now}
+}
+
+// Publish records a resource event. Other events, and those of writes that
+// don't identify a resource, e.g. transactions, are ignored.
+func (h *ResourceHistory) Publish(ctx context.Context, event *models.Event) {
+	switch event.Type {
+	case models.EventResourceCreated, models.EventResourceUpdated, models.EventResourceDeleted:
+	default:
+		return
+	}
+	resourceID, _ := event.Data["resource_id"].(string)
+	if resourceID == "" {
+		return
+	}
+
+	change := &models.ResourceChange{
+		ID:         uuid.New().String(),
+		ResourceID: resourceID,
+		Action:     strings.TrimPrefix(event.Type, "resource."),
+		TenantID:   event.TenantID,
+		OccurredAt: event.OccurredAt,
+	}
+	change.Resource, _ = event.Data["resource"].(string)
+	change.UserID, _ = event.Data["user_id"].(string)
+	change.Source, _ = event.Data["source"].(string)
+	change.Cluster, _ = event.Data["cluster"].(string)
+	change.Method, _ = event.Data["method"].(string)
+	change.Path, _ = event.Data["path"].(string)
+	if change.OccurredAt.IsZero() {
+		change.OccurredAt = h.now().UTC()
+	}
+
+	if err := h.store.CreateResourceChange(ctx, change); err != nil {
+		h.logger.Warn("Failed to record resource change",
+			zap.String("resource_id", resourceID),
+			zap.String("action", change.Action),
+			zap.Error(err))
+	}
+}
+
+// History lists the changes of a resource, oldest first. Within a tenant's
+// context, only the changes made on its behalf are listed.
+func (h *ResourceHistory) History(ctx context.Context, resourceID string) ([]*models.ResourceChange, error) {
+	tenantID := getTenantFromContext(ctx)
+	changes, err := h.store.ListResourceChanges(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource changes: %w", err)
+	}
+
+	result := make([]*models.ResourceChange, 0, len(changes))
+	for _, change := range changes {
+		if tenantID == "" || change.TenantID == tenantID {
+			result = append(result, change)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].OccurredAt.Before(result[j].OccurredAt)
+	})
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryResourceChangeStore keeps resource changes in memory
+type memoryResourceChangeStore struct {
+	changes []*models.ResourceChange
+	err     error
+}
+
+func (s *memoryResourceChangeStore) CreateResourceChange(ctx context.Context, change *models.ResourceChange) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.changes = append(s.changes, change)
+	return nil
+}
+
+func (s *memoryResourceChangeStore) ListResourceChanges(ctx context.Context, resourceID string) ([]*models.ResourceChange, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var changes []*models.ResourceChange
+	for _, change := range s.changes {
+		if change.ResourceID == resourceID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func TestResourceHistoryRecordsResourceEvents(t *testing.T) {
+	store := &memoryResourceChangeStore{}
+	history := NewResourceHistory(store, zap.NewNop())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	history.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	history.Publish(ctx, &models.Event{
+		Type:     models.EventResourceCreated,
+		TenantID: "acme",
+		Data: map[string]interface{}{
+			"resource": "switches", "resource_id": "sw-1", "method": "POST",
+			"path": "/api/v1/switches", "user_id": "alice", "source": "terraform",
+		},
+	})
+	// Events of other kinds, and writes not identifying a resource, aren't
+	// recorded
+	history.Publish(ctx, &models.Event{Type: models.EventBackupCompleted, Data: map[string]interface{}{"resource_id": "b-1"}})
+	history.Publish(ctx, &models.Event{Type: models.EventResourceUpdated, Data: map[string]interface{}{"resource": "transactions"}})
+
+	require.Len(t, store.changes, 1)
+	change := store.changes[0]
+	assert.NotEmpty(t, change.ID)
+	assert.Equal(t, &models.ResourceChange{
+		ID:         change.ID,
+		ResourceID: "sw-1",
+		Resource:   "switches",
+		Action:     "created",
+		UserID:     "alice",
+		TenantID:   "acme",
+		Source:     "terraform",
+		Method:     "POST",
+		Path:       "/api/v1/switches",
+		OccurredAt: now,
+	}, change)
+
+	// Failures to record are logged, not returned
+	store.err = errors.New("database down")
+	history.Publish(ctx, &models.Event{Type: models.EventResourceDeleted, Data: map[string]interface{}{"resource_id": "sw-1"}})
+}
+
+func TestResourceHistoryHistory(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &memoryResourceChangeStore{changes: []*models.ResourceChange{
+		{ID: "3", ResourceID: "sw-1", Action: "deleted", TenantID: "acme", OccurredAt: base.Add(2 * time.Hour)},
+		{ID: "1", ResourceID: "sw-1", Action: "created", TenantID: "acme", OccurredAt: base},
+		{ID: "2", ResourceID: "sw-1", Action: "updated", UserID: "admin", OccurredAt: base.Add(time.Hour)},
+		{ID: "4", ResourceID: "sw-2", Action: "created", TenantID: "acme", OccurredAt: base},
+	}}
+	history := NewResourceHistory(store, zap.NewNop())
+
+	changes, err := history.History(context.Background(), "sw-1")
+	require.NoError(t, err)
+	var ids []string
+	for _, change := range changes {
+		ids = append(ids, change.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+
+	// Tenants only see the changes made on their behalf
+	changes, err = history.History(ContextWithTenant(context.Background(), "acme"), "sw-1")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "1", changes[0].ID)
+	assert.Equal(t, "3", changes[1].ID)
+
+	changes, err = history.History(context.Background(), "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	store.err = errors.New("database down")
+	_, err = history.History(context.Background(), "sw-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrResourceLockNotFound is returned for resources that aren't locked
+	ErrResourceLockNotFound = errors.New("resource lock not found")
+
+	// ErrInvalidResourceLock is returned for locks of unknown resource
+	// types or with invalid TTLs
+	ErrInvalidResourceLock = errors.New("invalid resource lock")
+
+	// ErrResourceLockHolder is returned when releasing a lock held by
+	// someone else
+	ErrResourceLockHolder = errors.New("resource lock held by another user")
+)
+
+// ResourceLockedError is returned when a resource is locked by someone
+// else
+type ResourceLockedError struct {
+	Lock *models.ResourceLock
+}
+
+func (e *ResourceLockedError) Error() string {
+	return fmt.Sprintf("%s %s is locked by %s until %s", e.Lock.ResourceType, e.Lock.ResourceID,
+		e.Lock.Holder, e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+// ResourceLockStore keeps resource locks, shared by the API's replicas.
+// *db.DB implements it.
+type ResourceLockStore interface {
+	// CreateResourceLock records lock unless its resource has a lock that
+	// hasn't expired, reporting whether it did
+	CreateResourceLock(ctx context.Context, lock *models.ResourceLock) (bool, error)
+	// GetResourceLock returns the lock of a resource, nil when it has none
+	// that hasn't expired at now
+	GetResourceLock(ctx context.Context, resourceType, resourceID string, now time.Time) (*models.ResourceLock, error)
+	ListResourceLocks(ctx context.Context, tenantID string, now time.Time) ([]*models.ResourceLock, error)
+	ExtendResourceLock(ctx context.Context, id string, expiresAt time.Time, reason string, now time.Time) (bool, error)
+	DeleteResourceLock(ctx context.Context, id string) error
+}
+
+// resourceLockPollInterval is how often writes waiting for a resource
+// check whether it was unlocked
+const resourceLockPollInterval = 100 * time.Millisecond
+
+// ResourceLockService keeps writes to the same resource from interleaving.
+// Users lock resources for a while, e.g. to edit a switch's ACLs in
+// several requests, and every write locks the resource it changes for its
+// duration, waiting for another write's lock to be released.
+type ResourceLockService struct {
+	store      ResourceLockStore
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+	writeTTL   time.Duration
+	wait       time.Duration
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewResourceLockService creates a service keeping locks in store. Locks
+// last defaultTTL unless asked otherwise, and at most maxTTL; the locks of
+// writes last writeTTL, and writes wait up to wait for another write's
+// lock.
+func NewResourceLockService(store ResourceLockStore, defaultTTL, maxTTL, writeTTL, wait time.Duration, logger *zap.Logger) *ResourceLockService {
+	return &ResourceLockService{
+		store:      store,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+		writeTTL:   writeTTL,
+		wait:       wait,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Acquire locks a resource for holder for ttl, the default TTL when it's
+// 0, and reports whether the lock is new. A lock holder already holds is
+// extended.
+func (s *ResourceLockService) Acquire(ctx context.Context, resourceType, resourceID, holder, reason string, ttl time.Duration) (*models.ResourceLock, bool, error) {
+	if !isLockableResourceType(resourceType) {
+		return nil, false, fmt.Errorf("%w: resources of type %q can't be locked", ErrInvalidResourceLock, resourceType)
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, false, fmt.Errorf("%w: ttl must be positive and at most %s", ErrInvalidResourceLock, s.maxTTL)
+	}
+
+	now := s.now().UTC()
+	existing, err := s.store.GetResourceLock(ctx, resourceType, resourceID, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if existing.Holder != holder || existing.Automatic {
+			return nil, false, &ResourceLockedError{Lock: existing}
+		}
+		existing.ExpiresAt, existing.Reason = now.Add(ttl), reason
+		extended, err := s.store.ExtendResourceLock(ctx, existing.ID, existing.ExpiresAt, reason, now)
+		if err != nil {
+			return nil, false, err
+		}
+		if extended {
+			return existing, false, nil
+		}
+	}
+
+	lock := &models.ResourceLock{
+		ID:           uuid.New().String(),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TenantID:     getTenantFromContext(ctx),
+		Holder:       holder,
+		Reason:       reason,
+		AcquiredAt:   now,
+		ExpiresAt:    now.Add(ttl),
+	}
+	if err := s.create(ctx, lock); err != nil {
+		return nil, false, err
+	}
+	s.logger.Info("Resource locked",
+		zap.String("resource_type", resourceType),
+		zap.String("resource_id", resourceID),
+		zap.String("holder", holder),
+		zap.Time("expires_at", lock.ExpiresAt))
+	return lock, true, nil
+}
+
+// Get returns the lock of a resource
+func (s *ResourceLockService) Get(ctx context.Context, resourceType, resourceID string) (*models.ResourceLock, error) {
+	lock, err := s.store.GetResourceLock(ctx, resourceType, resourceID, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, ErrResourceLockNotFound
+	}
+	return lock, nil
+}
+
+// List lists the locks held, those of the context's tenant only within a
+// tenant's context
+func (s *ResourceLockService) List(ctx context.Context) ([]*models.ResourceLock, error) {
+	return s.store.ListResourceLocks(ctx, getTenantFromContext(ctx), s.now().UTC())
+}
+
+// Release unlocks a resource locked by holder, or by anyone with force
+func (s *ResourceLockService) Release(ctx context.Context, resourceType, resourceID, holder string, force bool) error {
+	lock, err := s.Get(ctx, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if lock.Holder != holder && !force {
+		return fmt.Errorf("%w: %s", ErrResourceLockHolder, lock.Holder)
+	}
+	if err := s.store.DeleteResourceLock(ctx, lock.ID); err != nil {
+		return err
+	}
+	s.logger.Info("Resource unlocked",
+		zap.String("resource_type", resourceType),
+		zap.String("resource_id", resourceID),
+		zap.String("holder", lock.Holder),
+		zap.String("released_by", holder))
+	return nil
+}
+
+// LockForWrite locks a resource for a write by holder, waiting for another
+// write's lock to be released. The write proceeds without a lock of its
+// own on a resource holder locked. The returned function releases the
+// lock once the write is done.
+func (s *ResourceLockService) LockForWrite(ctx context.Context, resourceType, resourceID, holder string) (func(), error) {
+	deadline := s.now().Add(s.wait)
+	for {
+		now := s.now().UTC()
+		existing, err := s.store.GetResourceLock(ctx, resourceType, resourceID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case existing == nil:
+			lock := &models.ResourceLock{
+				ID:           uuid.New().String(),
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				TenantID:     getTenantFromContext(ctx),
+				Holder:       holder,
+				Automatic:    true,
+				AcquiredAt:   now,
+				ExpiresAt:    now.Add(s.writeTTL),
+			}
+			err := s.create(ctx, lock)
+			var locked *ResourceLockedError
+			if errors.As(err, &locked) {
+				// Another write got there first
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return func() {
+				// The write's context may be done by the time it returns
+				if err := s.store.DeleteResourceLock(context.Background(), lock.ID); err != nil {
+					s.logger.Warn("Failed to release resource lock; it expires at "+lock.ExpiresAt.Format(time.RFC3339),
+						zap.String("resource_type", resourceType),
+						zap.String("resource_id", resourceID),
+						zap.Error(err))
+				}
+			}, nil
+		case !existing.Automatic && existing.Holder == holder:
+			return func() {}, nil
+		case !existing.Automatic || !now.Before(deadline):
+			return nil, &ResourceLockedError{Lock: existing}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(resourceLockPollInterval):
+		}
+	}
+}
+
+// create records lock, failing with a ResourceLockedError when another
+// lock of the resource was taken meanwhile
+func (s *ResourceLockService) create(ctx context.Context, lock *models.ResourceLock) error {
+	for {
+		created, err := s.store.CreateResourceLock(ctx, lock)
+		if err != nil {
+			return err
+		}
+		if created {
+			return nil
+		}
+		existing, err := s.store.GetResourceLock(ctx, lock.ResourceType, lock.ResourceID, lock.AcquiredAt)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return &ResourceLockedError{Lock: existing}
+		}
+		// Released meanwhile
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// isLockableResourceType reports whether resources of resourceType can be
+// locked
+func isLockableResourceType(resourceType string) bool {
+	for _, lockable := range models.ResourceLockTypes {
+		if resourceType == lockable {
+			return true
+		}
+	}
+	return false
+}
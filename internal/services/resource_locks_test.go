@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryResourceLockStore keeps resource locks in memory
+type memoryResourceLockStore struct {
+	mu    sync.Mutex
+	locks map[string]*models.ResourceLock
+}
+
+func newMemoryResourceLockStore() *memoryResourceLockStore {
+	return &memoryResourceLockStore{locks: make(map[string]*models.ResourceLock)}
+}
+
+func (s *memoryResourceLockStore) CreateResourceLock(ctx context.Context, lock *models.ResourceLock) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := lock.ResourceType + "/" + lock.ResourceID
+	if existing, ok := s.locks[key]; ok && existing.ExpiresAt.After(lock.AcquiredAt) {
+		return false, nil
+	}
+	copied := *lock
+	s.locks[key] = &copied
+	return true, nil
+}
+
+func (s *memoryResourceLockStore) GetResourceLock(ctx context.Context, resourceType, resourceID string, now time.Time) (*models.ResourceLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[resourceType+"/"+resourceID]
+	if !ok || !lock.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	copied := *lock
+	return &copied, nil
+}
+
+func (s *memoryResourceLockStore) ListResourceLocks(ctx context.Context, tenantID string, now time.Time) ([]*models.ResourceLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var locks []*models.ResourceLock
+	for _, lock := range s.locks {
+		if lock.ExpiresAt.After(now) && (tenantID == "" || lock.TenantID == tenantID) {
+			locks = append(locks, lock)
+		}
+	}
+	return locks, nil
+}
+
+func (s *memoryResourceLockStore) ExtendResourceLock(ctx context.Context, id string, expiresAt time.Time, reason string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lock := range s.locks {
+		if lock.ID == id && lock.ExpiresAt.After(now) {
+			lock.ExpiresAt, lock.Reason = expiresAt, reason
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryResourceLockStore) DeleteResourceLock(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, lock := range s.locks {
+		if lock.ID == id {
+			delete(s.locks, key)
+		}
+	}
+	return nil
+}
+
+func TestResourceLockServiceAcquire(t *testing.T) {
+	store := newMemoryResourceLockStore()
+	locks := NewResourceLockService(store, 5*time.Minute, time.Hour, time.Minute, 0, zap.NewNop())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	locks.now = func() time.Time { return now }
+	ctx := ContextWithTenant(context.Background(), "acme")
+
+	lock, created, err := locks.Acquire(ctx, models.ResourceSwitch, "sw-1", "alice", "editing ACLs", 0)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "alice", lock.Holder)
+	assert.Equal(t, "acme", lock.TenantID)
+	assert.Equal(t, now.Add(5*time.Minute), lock.ExpiresAt)
+
+	// The holder extends the lock
+	now = now.Add(time.Minute)
+	extended, created, err := locks.Acquire(ctx, models.ResourceSwitch, "sw-1", "alice", "still editing", 10*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, lock.ID, extended.ID)
+	assert.Equal(t, now.Add(10*time.Minute), extended.ExpiresAt)
+
+	// Others are refused, naming the holder
+	_, _, err = locks.Acquire(ctx, models.ResourceSwitch, "sw-1", "bob", "", 0)
+	var locked *ResourceLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "alice", locked.Lock.Holder)
+	assert.Equal(t, "still editing", locked.Lock.Reason)
+
+	_, _, err = locks.Acquire(ctx, "dns_record", "r-1", "bob", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidResourceLock)
+	_, _, err = locks.Acquire(ctx, models.ResourceRouter, "lr-1", "bob", "", 2*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidResourceLock)
+
+	// Expired locks free the resource
+	now = now.Add(11 * time.Minute)
+	_, err = locks.Get(ctx, models.ResourceSwitch, "sw-1")
+	assert.ErrorIs(t, err, ErrResourceLockNotFound)
+	lock, created, err = locks.Acquire(ctx, models.ResourceSwitch, "sw-1", "bob", "", 0)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "bob", lock.Holder)
+
+	list, err := locks.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	list, err = locks.List(ContextWithTenant(context.Background(), "globex"))
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestResourceLockServiceRelease(t *testing.T) {
+	locks := NewResourceLockService(newMemoryResourceLockStore(), 5*time.Minute, time.Hour, time.Minute, 0, zap.NewNop())
+	ctx := context.Background()
+
+	_, _, err := locks.Acquire(ctx, models.ResourcePort, "p-1", "alice", "", 0)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, locks.Release(ctx, models.ResourcePort, "p-1", "bob", false), ErrResourceLockHolder)
+	require.NoError(t, locks.Release(ctx, models.ResourcePort, "p-1", "bob", true))
+	assert.ErrorIs(t, locks.Release(ctx, models.ResourcePort, "p-1", "alice", false), ErrResourceLockNotFound)
+}
+
+func TestResourceLockServiceLockForWrite(t *testing.T) {
+	store := newMemoryResourceLockStore()
+	locks := NewResourceLockService(store, 5*time.Minute, time.Hour, time.Minute, time.Second, zap.NewNop())
+	ctx := context.Background()
+
+	// Writes lock the resource until they're done
+	release, err := locks.LockForWrite(ctx, models.ResourceSwitch, "sw-1", "alice")
+	require.NoError(t, err)
+	lock, err := locks.Get(ctx, models.ResourceSwitch, "sw-1")
+	require.NoError(t, err)
+	assert.True(t, lock.Automatic)
+
+	// Other writes wait for them
+	done := make(chan error)
+	go func() {
+		release, err := locks.LockForWrite(ctx, models.ResourceSwitch, "sw-1", "bob")
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	time.Sleep(2 * resourceLockPollInterval)
+	release()
+	require.NoError(t, <-done)
+	_, err = locks.Get(ctx, models.ResourceSwitch, "sw-1")
+	assert.ErrorIs(t, err, ErrResourceLockNotFound)
+
+	// Explicit locks let their holder's writes through and refuse others'
+	// at once
+	_, _, err = locks.Acquire(ctx, models.ResourceSwitch, "sw-1", "alice", "", 0)
+	require.NoError(t, err)
+	release, err = locks.LockForWrite(ctx, models.ResourceSwitch, "sw-1", "alice")
+	require.NoError(t, err)
+	release()
+	_, err = locks.Get(ctx, models.ResourceSwitch, "sw-1")
+	assert.NoError(t, err, "the explicit lock is kept")
+
+	start := time.Now()
+	_, err = locks.LockForWrite(ctx, models.ResourceSwitch, "sw-1", "bob")
+	var locked *ResourceLockedError
+	require.True(t, errors.As(err, &locked))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestResourceLockServiceLockForWriteTimesOut(t *testing.T) {
+	locks := NewResourceLockService(newMemoryResourceLockStore(), 5*time.Minute, time.Hour, time.Minute,
+		3*resourceLockPollInterval, zap.NewNop())
+	ctx := context.Background()
+
+	release, err := locks.LockForWrite(ctx, models.ResourceRouter, "lr-1", "alice")
+	require.NoError(t, err)
+	defer release()
+
+	_, err = locks.LockForWrite(ctx, models.ResourceRouter, "lr-1", "bob")
+	var locked *ResourceLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "alice", locked.Lock.Holder)
+	assert.True(t, locked.Lock.Automatic)
+}
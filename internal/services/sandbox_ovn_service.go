@@ -0,0 +1,1495 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// SandboxStateStore keeps the state of sandbox OVN backends by name.
+// *db.DB implements it.
+type SandboxStateStore interface {
+	// LoadSandboxState returns the state saved under name, or nil if there
+	// is none
+	LoadSandboxState(ctx context.Context, name string) ([]byte, error)
+	// SaveSandboxState replaces the state saved under name
+	SaveSandboxState(ctx context.Context, name string, state []byte) error
+}
+
+// SandboxOVNService implements OVNServiceInterface in memory, for demos and
+// CI without an OVN deployment. It validates, stamps and guards resources
+// the way OVNService and the OVN client do, and saves its state to a store
+// after every change, so that it survives restarts. Each change applies to
+// a copy of the state which replaces it once saved, so that failed changes,
+// including transactions, leave nothing behind.
+type SandboxOVNService struct {
+	store  SandboxStateStore
+	name   string
+	logger *zap.Logger
+
+	mu    sync.RWMutex
+	state *sandboxState
+}
+
+var _ OVNServiceInterface = (*SandboxOVNService)(nil)
+
+// sandboxState is the northbound database of a sandbox, its resources keyed
+// by UUID. Parents list their children as OVN rows do: switches their
+// ports, ACLs, QoS rules and load balancers, routers their ports, policies
+// and load balancers. The others name their parent.
+type sandboxState struct {
+	Switches        map[string]*models.LogicalSwitch     `json:"switches"`
+	Routers         map[string]*models.LogicalRouter     `json:"routers"`
+	Ports           map[string]*models.LogicalSwitchPort `json:"ports"`
+	RouterPorts     map[string]*sandboxRouterPort        `json:"router_ports"`
+	HAChassisGroups map[string][]models.GatewayChassis   `json:"ha_chassis_groups"` // By name
+	ACLs            map[string]*models.ACL               `json:"acls"`
+	ACLSampling     map[string]*models.ACLSampling       `json:"acl_sampling"` // By ACL UUID
+	LoadBalancers   map[string]*models.LoadBalancer      `json:"load_balancers"`
+	PortGroups      map[string]*models.PortGroup         `json:"port_groups"`
+	AddressSets     map[string]*models.AddressSet        `json:"address_sets"`
+	DNS             map[string]*models.DNS               `json:"dns"`
+	Mirrors         map[string]*models.Mirror            `json:"mirrors"`
+	Collectors      map[string]*models.SampleCollector   `json:"sample_collectors"`
+	SamplingApps    map[string]int                       `json:"sampling_apps"` // Observation domain IDs by type
+	Policies        map[string]*models.RouterPolicy      `json:"router_policies"`
+	Routes          map[string]*models.StaticRoute       `json:"static_routes"`
+	NATs            map[string]*sandboxNAT               `json:"nat"`
+	DHCPOptions     map[string]*models.DHCPOptions       `json:"dhcp_options"`
+	QoS             map[string]*models.QoS               `json:"qos"`
+}
+
+// sandboxRouterPort is a router port with its router and gateway binding
+type sandboxRouterPort struct {
+	models.LogicalRouterPort
+	RouterID       string                  `json:"router_id"`
+	GatewayChassis []models.GatewayChassis `json:"gateway_chassis,omitempty"`
+	HAChassisGroup string                  `json:"ha_chassis_group,omitempty"`
+}
+
+// sandboxNAT is a NAT rule with its router
+type sandboxNAT struct {
+	models.NAT
+	RouterID string `json:"router_id"`
+}
+
+func newSandboxState() *sandboxState {
+	st := &sandboxState{}
+	st.init()
+	return st
+}
+
+// init creates the maps missing from a loaded state
+func (st *sandboxState) init() {
+	if st.Switches == nil {
+		st.Switches = make(map[string]*models.LogicalSwitch)
+	}
+	if st.Routers == nil {
+		st.Routers = make(map[string]*models.LogicalRouter)
+	}
+	if st.Ports == nil {
+		st.Ports = make(map[string]*models.LogicalSwitchPort)
+	}
+	if st.RouterPorts == nil {
+		st.RouterPorts = make(map[string]*sandboxRouterPort)
+	}
+	if st.HAChassisGroups == nil {
+		st.HAChassisGroups = make(map[string][]models.GatewayChassis)
+	}
+	if st.ACLs == nil {
+		st.ACLs = make(map[string]*models.ACL)
+	}
+	if st.ACLSampling == nil {
+		st.ACLSampling = make(map[string]*models.ACLSampling)
+	}
+	if st.LoadBalancers == nil {
+		st.LoadBalancers = make(map[string]*models.LoadBalancer)
+	}
+	if st.PortGroups == nil {
+		st.PortGroups = make(map[string]*models.PortGroup)
+	}
+	if st.AddressSets == nil {
+		st.AddressSets = make(map[string]*models.AddressSet)
+	}
+	if st.DNS == nil {
+		st.DNS = make(map[string]*models.DNS)
+	}
+	if st.Mirrors == nil {
+		st.Mirrors = make(map[string]*models.Mirror)
+	}
+	if st.Collectors == nil {
+		st.Collectors = make(map[string]*models.SampleCollector)
+	}
+	if st.SamplingApps == nil {
+		st.SamplingApps = make(map[string]int)
+	}
+	if st.Policies == nil {
+		st.Policies = make(map[string]*models.RouterPolicy)
+	}
+	if st.Routes == nil {
+		st.Routes = make(map[string]*models.StaticRoute)
+	}
+	if st.NATs == nil {
+		st.NATs = make(map[string]*sandboxNAT)
+	}
+	if st.DHCPOptions == nil {
+		st.DHCPOptions = make(map[string]*models.DHCPOptions)
+	}
+	if st.QoS == nil {
+		st.QoS = make(map[string]*models.QoS)
+	}
+}
+
+// derive sets the fields OVN computes from the rows: timestamps and
+// ownership from external IDs, the switches' DNS records from the record
+// sets attached to them, and whether mirrors are active
+func (st *sandboxState) derive() {
+	for _, ls := range st.Switches {
+		ls.CreatedAt, ls.UpdatedAt = sandboxTimestamps(ls.ExternalIDs)
+		ls.Ownership = models.OwnershipFromExternalIDs(ls.ExternalIDs)
+		ls.DNSRecords = nil
+	}
+	for _, dns := range sortedValues(st.DNS, func(dns *models.DNS) string { return dns.UUID }) {
+		dns.CreatedAt, dns.UpdatedAt = sandboxTimestamps(dns.ExternalIDs)
+		for _, switchID := range dns.Switches {
+			if ls, ok := st.Switches[switchID]; ok {
+				ls.DNSRecords = append(ls.DNSRecords, dns.UUID)
+			}
+		}
+	}
+	for _, lr := range st.Routers {
+		lr.CreatedAt, lr.UpdatedAt = sandboxTimestamps(lr.ExternalIDs)
+		lr.Ownership = models.OwnershipFromExternalIDs(lr.ExternalIDs)
+	}
+	for _, port := range st.Ports {
+		port.CreatedAt, port.UpdatedAt = sandboxTimestamps(port.ExternalIDs)
+		port.Ownership = models.OwnershipFromExternalIDs(port.ExternalIDs)
+	}
+	for _, lrp := range st.RouterPorts {
+		lrp.CreatedAt, lrp.UpdatedAt = sandboxTimestamps(lrp.ExternalIDs)
+	}
+	for _, acl := range st.ACLs {
+		acl.CreatedAt, acl.UpdatedAt = sandboxTimestamps(acl.ExternalIDs)
+		acl.Owner = models.ACLOwnerFromExternalIDs(acl.ExternalIDs)
+		acl.Ownership = models.OwnershipFromExternalIDs(acl.ExternalIDs)
+		acl.Alert = acl.Severity == "alert" || acl.Severity == "warning"
+	}
+	for _, lb := range st.LoadBalancers {
+		lb.CreatedAt, lb.UpdatedAt = sandboxTimestamps(lb.ExternalIDs)
+	}
+	for _, pg := range st.PortGroups {
+		pg.CreatedAt, pg.UpdatedAt = sandboxTimestamps(pg.ExternalIDs)
+	}
+	for _, as := range st.AddressSets {
+		as.CreatedAt, as.UpdatedAt = sandboxTimestamps(as.ExternalIDs)
+	}
+	for _, mirror := range st.Mirrors {
+		mirror.CreatedAt, mirror.UpdatedAt = sandboxTimestamps(mirror.ExternalIDs)
+		mirror.Active = len(mirror.Ports) > 0
+	}
+	for _, policy := range st.Policies {
+		policy.CreatedAt, policy.UpdatedAt = sandboxTimestamps(policy.ExternalIDs)
+	}
+	for _, dhcp := range st.DHCPOptions {
+		dhcp.CreatedAt, dhcp.UpdatedAt = sandboxTimestamps(dhcp.ExternalIDs)
+	}
+	for _, qos := range st.QoS {
+		qos.CreatedAt, qos.UpdatedAt = sandboxTimestamps(qos.ExternalIDs)
+	}
+}
+
+// NewSandboxOVNService creates a sandbox keeping its state in store under
+// name, loading the state saved before. A sandbox without state starts
+// empty, or with a small demo topology if seed is set.
+func NewSandboxOVNService(ctx context.Context, store SandboxStateStore, name string, seed bool, logger *zap.Logger) (*SandboxOVNService, error) {
+	s := &SandboxOVNService{
+		store:  store,
+		name:   name,
+		logger: logger,
+	}
+
+	data, err := store.LoadSandboxState(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		state := &sandboxState{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("invalid sandbox state %s: %w", name, err)
+		}
+		state.init()
+		s.state = state
+		logger.Info("Loaded sandbox OVN state",
+			zap.String("name", name),
+			zap.Int("switches", len(state.Switches)),
+			zap.Int("routers", len(state.Routers)))
+		return s, nil
+	}
+
+	s.state = newSandboxState()
+	if seed {
+		if err := s.update(ctx, func(st *sandboxState) error {
+			return seedSandbox(ctx, st)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to seed sandbox: %w", err)
+		}
+		logger.Info("Seeded sandbox OVN with a demo topology", zap.String("name", name))
+	}
+	return s, nil
+}
+
+// ConnectionStatus reports the sandbox as connected, as it always is
+func (s *SandboxOVNService) ConnectionStatus() ovn.ConnectionStatus {
+	return ovn.ConnectionStatus{Connected: true, Endpoint: "sandbox"}
+}
+
+// ReadPoolStats returns nil, the sandbox having no read pool
+func (s *SandboxOVNService) ReadPoolStats() *ovn.PoolStats {
+	return nil
+}
+
+// view runs fn on the state for reading
+func (s *SandboxOVNService) view(fn func(st *sandboxState) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.state)
+}
+
+// update runs fn on a copy of the state and, if it succeeds, saves the copy
+// and makes it the state
+func (s *SandboxOVNService) update(ctx context.Context, fn func(st *sandboxState) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, err := cloneValue(s.state)
+	if err != nil {
+		return fmt.Errorf("failed to copy sandbox state: %w", err)
+	}
+	next.init()
+	if err := fn(next); err != nil {
+		return err
+	}
+	next.derive()
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("failed to encode sandbox state: %w", err)
+	}
+	if err := s.store.SaveSandboxState(ctx, s.name, data); err != nil {
+		return err
+	}
+	s.state = next
+	return nil
+}
+
+// sandboxTimestamps returns the creation and update times kept in external
+// IDs
+func sandboxTimestamps(externalIDs map[string]string) (created, updated time.Time) {
+	created, _ = time.Parse(time.RFC3339, externalIDs["created_at"])
+	updated, _ = time.Parse(time.RFC3339, externalIDs["updated_at"])
+	return created, updated
+}
+
+// createdExternalIDs copies externalIDs, adding the creation timestamps and
+// ownership
+func createdExternalIDs(ctx context.Context, externalIDs map[string]string) map[string]string {
+	now := time.Now().Format(time.RFC3339)
+	result := make(map[string]string, len(externalIDs)+2)
+	for k, v := range externalIDs {
+		if k != "created_at" && k != "updated_at" {
+			result[k] = v
+		}
+	}
+	result["created_at"] = now
+	result["updated_at"] = now
+	ovn.StampCreated(ctx, result)
+	return result
+}
+
+// mergedExternalIDs adds updates to the external IDs of a changed resource,
+// refreshing its update timestamp and updater, as ports and ACLs do
+func mergedExternalIDs(ctx context.Context, existing, updates map[string]string) map[string]string {
+	result := make(map[string]string, len(existing)+len(updates)+1)
+	for k, v := range existing {
+		result[k] = v
+	}
+	for k, v := range updates {
+		if k != "created_at" && k != "updated_at" {
+			result[k] = v
+		}
+	}
+	result["updated_at"] = time.Now().Format(time.RFC3339)
+	ovn.StampUpdated(ctx, result)
+	return result
+}
+
+// sortedValues returns the values of m ordered by key, then by map key
+func sortedValues[T any](m map[string]T, key func(T) string) []T {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := key(m[ids[i]]), key(m[ids[j]])
+		if a != b {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+	values := make([]T, len(ids))
+	for i, id := range ids {
+		values[i] = m[id]
+	}
+	return values
+}
+
+// sandboxSortKey is the value a resource is ordered by in a page; resources
+// compare by n, then by s
+type sandboxSortKey struct {
+	s string
+	n int
+}
+
+// timeSortKey orders by a timestamp kept in external IDs, resources without
+// one first
+func timeSortKey(externalIDs map[string]string, key string) sandboxSortKey {
+	t, err := time.Parse(time.RFC3339, externalIDs[key])
+	if err != nil {
+		return sandboxSortKey{}
+	}
+	return sandboxSortKey{n: int(t.Unix())}
+}
+
+// sandboxPage sorts items, already filtered, by the field opts asks for and
+// returns the page requested along with the number of items, as the OVN
+// client's paged listings do
+func sandboxPage[T any](opts *models.ListOptions, resource string, items []T, id func(T) string, key func(item T, field string) sandboxSortKey) ([]T, int, error) {
+	field, desc, err := ovn.ResolveSortField(opts, resource)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := key(items[i], field), key(items[j], field)
+		if a == b {
+			return id(items[i]) < id(items[j])
+		}
+		less := a.n < b.n || (a.n == b.n && a.s < b.s)
+		if desc {
+			return !less
+		}
+		return less
+	})
+	start, end := opts.Bounds(len(items))
+	return items[start:end], len(items), nil
+}
+
+// copyOf returns a deep copy of a resource, so that callers can't change
+// the state
+func copyOf[T any](value T) (T, error) {
+	clone, err := cloneValue(value)
+	if err != nil {
+		return clone, fmt.Errorf("failed to copy sandbox resource: %w", err)
+	}
+	return clone, nil
+}
+
+// removeID returns ids without id
+func removeID(ids []string, id string) []string {
+	result := make([]string, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			result = append(result, other)
+		}
+	}
+	return result
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []string, id string) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// errSandboxNotFound is the error of lookups by UUID the client makes
+// through libovsdb, which wraps its own
+var errSandboxNotFound = errors.New("object not found")
+
+// Logical Switch operations
+
+func (s *SandboxOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	var switches []*models.LogicalSwitch
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		switches, err = copyOf(sortedValues(st.Switches, func(ls *models.LogicalSwitch) string { return ls.Name }))
+		return err
+	})
+	return switches, err
+}
+
+func (s *SandboxOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	var switches []*models.LogicalSwitch
+	var total int
+	err := s.view(func(st *sandboxState) error {
+		var matching []*models.LogicalSwitch
+		for _, ls := range st.Switches {
+			if opts.Matches(ls.Name, ls.ExternalIDs) {
+				matching = append(matching, ls)
+			}
+		}
+		page, n, err := sandboxPage(opts, models.ResourceSwitch, matching,
+			func(ls *models.LogicalSwitch) string { return ls.UUID },
+			func(ls *models.LogicalSwitch, field string) sandboxSortKey {
+				if field == "name" {
+					return sandboxSortKey{s: ls.Name}
+				}
+				return timeSortKey(ls.ExternalIDs, field)
+			})
+		if err != nil {
+			return err
+		}
+		total = n
+		switches, err = copyOf(page)
+		return err
+	})
+	return switches, total, err
+}
+
+// switchByID returns a switch by UUID or name
+func (st *sandboxState) switchByID(id string) (*models.LogicalSwitch, error) {
+	if ls, ok := st.Switches[id]; ok {
+		return ls, nil
+	}
+	for _, ls := range sortedValues(st.Switches, func(ls *models.LogicalSwitch) string { return ls.UUID }) {
+		if ls.Name == id {
+			return ls, nil
+		}
+	}
+	return nil, fmt.Errorf("logical switch %s not found", id)
+}
+
+func (s *SandboxOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
+	var ls *models.LogicalSwitch
+	err := s.view(func(st *sandboxState) error {
+		existing, err := st.switchByID(id)
+		if err != nil {
+			return err
+		}
+		ls, err = copyOf(existing)
+		return err
+	})
+	return ls, err
+}
+
+func (s *SandboxOVNService) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	// Validate input
+	if ls.Name == "" {
+		return nil, fmt.Errorf("logical switch name is required")
+	}
+
+	var created *models.LogicalSwitch
+	err := s.update(ctx, func(st *sandboxState) error {
+		var err error
+		created, err = st.createSwitch(ctx, ls)
+		return err
+	})
+	return created, err
+}
+
+func (st *sandboxState) createSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	if ls.UUID == "" {
+		ls.UUID = uuid.New().String()
+	}
+	ls.ExternalIDs = createdExternalIDs(ctx, ls.ExternalIDs)
+	ls.CreatedAt, ls.UpdatedAt = sandboxTimestamps(ls.ExternalIDs)
+	ls.Ownership = models.OwnershipFromExternalIDs(ls.ExternalIDs)
+
+	row := &models.LogicalSwitch{
+		UUID:        ls.UUID,
+		Name:        ls.Name,
+		OtherConfig: ls.OtherConfig,
+		ExternalIDs: ls.ExternalIDs,
+	}
+	st.Switches[row.UUID] = row
+	return copyOf(ls)
+}
+
+func (s *SandboxOVNService) UpdateLogicalSwitch(ctx context.Context, id string, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("logical switch ID is required")
+	}
+
+	var updated *models.LogicalSwitch
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.switchByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.updateSwitch(ctx, existing, ls)
+		st.derive()
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (st *sandboxState) updateSwitch(ctx context.Context, existing, updates *models.LogicalSwitch) {
+	if updates.Name != "" {
+		existing.Name = updates.Name
+	}
+	if updates.OtherConfig != nil {
+		existing.OtherConfig = updates.OtherConfig
+	}
+	existing.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, updates.ExternalIDs)
+}
+
+func (s *SandboxOVNService) DeleteLogicalSwitch(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("logical switch ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.switchByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		if ovn.CascadeRequested(ctx) {
+			st.cascadeSwitch(existing)
+		}
+		st.deleteSwitch(existing)
+		return nil
+	})
+}
+
+// deleteSwitch deletes a switch along with the ports, ACLs and QoS rules
+// only it refers to, as OVSDB garbage collects them
+func (st *sandboxState) deleteSwitch(ls *models.LogicalSwitch) {
+	for _, portID := range ls.Ports {
+		st.deletePortRow(portID)
+	}
+	for _, aclID := range ls.ACLs {
+		st.deleteACLRow(aclID)
+	}
+	for _, qosID := range ls.QoSRules {
+		delete(st.QoS, qosID)
+	}
+	for _, dns := range st.DNS {
+		dns.Switches = removeID(dns.Switches, ls.UUID)
+	}
+	delete(st.Switches, ls.UUID)
+}
+
+// cascadeSwitch removes the IP addresses of a switch's ports from address
+// sets and from the backends of the load balancers applied to it, as
+// cascading deletes do
+func (st *sandboxState) cascadeSwitch(ls *models.LogicalSwitch) {
+	ips := make(map[string]bool)
+	for _, portID := range ls.Ports {
+		if port, ok := st.Ports[portID]; ok {
+			for _, ip := range ovn.PortIPs(port.Addresses) {
+				ips[ip] = true
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return
+	}
+	for _, as := range st.AddressSets {
+		addresses := make([]string, 0, len(as.Addresses))
+		for _, addr := range as.Addresses {
+			if !ips[ovn.NormalizeIP(addr)] {
+				addresses = append(addresses, addr)
+			}
+		}
+		as.Addresses = addresses
+	}
+	for _, lbID := range ls.LoadBalancer {
+		if lb, ok := st.LoadBalancers[lbID]; ok {
+			if vips, changed := ovn.WithoutBackends(lb.VIPs, ips); changed {
+				lb.VIPs = vips
+			}
+		}
+	}
+}
+
+// Port operations
+
+func (s *SandboxOVNService) ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+
+	var ports []*models.LogicalSwitchPort
+	err := s.view(func(st *sandboxState) error {
+		ls, err := st.switchByID(switchID)
+		if err != nil {
+			return err
+		}
+		ports, err = copyOf(st.switchPorts(ls))
+		return err
+	})
+	return ports, err
+}
+
+// switchPorts returns the ports of a switch
+func (st *sandboxState) switchPorts(ls *models.LogicalSwitch) []*models.LogicalSwitchPort {
+	ports := make([]*models.LogicalSwitchPort, 0, len(ls.Ports))
+	for _, portID := range ls.Ports {
+		if port, ok := st.Ports[portID]; ok {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func (s *SandboxOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, 0, fmt.Errorf("switch ID is required")
+	}
+
+	var ports []*models.LogicalSwitchPort
+	var total int
+	err := s.view(func(st *sandboxState) error {
+		ls, ok := st.Switches[switchID]
+		if !ok {
+			return fmt.Errorf("failed to get logical switch %s: %w", switchID, errSandboxNotFound)
+		}
+		var matching []*models.LogicalSwitchPort
+		for _, port := range st.switchPorts(ls) {
+			if opts.Matches(port.Name, port.ExternalIDs) {
+				matching = append(matching, port)
+			}
+		}
+		page, n, err := sandboxPage(opts, models.ResourcePort, matching,
+			func(port *models.LogicalSwitchPort) string { return port.UUID },
+			func(port *models.LogicalSwitchPort, field string) sandboxSortKey {
+				switch field {
+				case "name":
+					return sandboxSortKey{s: port.Name}
+				case "type":
+					return sandboxSortKey{s: port.Type}
+				}
+				return timeSortKey(port.ExternalIDs, field)
+			})
+		if err != nil {
+			return err
+		}
+		total = n
+		ports, err = copyOf(page)
+		return err
+	})
+	return ports, total, err
+}
+
+func (s *SandboxOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("port ID is required")
+	}
+
+	var port *models.LogicalSwitchPort
+	err := s.view(func(st *sandboxState) error {
+		existing, ok := st.Ports[id]
+		if !ok {
+			return fmt.Errorf("logical switch port %s not found", id)
+		}
+		var err error
+		port, err = copyOf(existing)
+		return err
+	})
+	return port, err
+}
+
+func (s *SandboxOVNService) CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+	if port.Name == "" {
+		return nil, fmt.Errorf("port name is required")
+	}
+
+	var created *models.LogicalSwitchPort
+	err := s.update(ctx, func(st *sandboxState) error {
+		if _, ok := st.Switches[switchID]; !ok {
+			return fmt.Errorf("failed to get logical switch %s: %w", switchID, errSandboxNotFound)
+		}
+		var err error
+		created, err = st.createPort(ctx, switchID, port)
+		return err
+	})
+	return created, err
+}
+
+func (st *sandboxState) createPort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	for _, existing := range st.Ports {
+		if existing.Name == port.Name {
+			return nil, fmt.Errorf("port %s already exists", port.Name)
+		}
+	}
+
+	row := &models.LogicalSwitchPort{
+		UUID:         uuid.New().String(),
+		Name:         port.Name,
+		Type:         port.Type,
+		SwitchID:     switchID,
+		Addresses:    port.Addresses,
+		PortSecurity: port.PortSecurity,
+		Enabled:      port.Enabled,
+		Options:      port.Options,
+		ExternalIDs:  createdExternalIDs(ctx, port.ExternalIDs),
+		ParentName:   port.ParentName,
+	}
+	if port.Tag > 0 {
+		row.Tag = port.Tag
+	}
+	st.Ports[row.UUID] = row
+	ls := st.Switches[switchID]
+	ls.Ports = append(ls.Ports, row.UUID)
+
+	port.UUID = row.UUID
+	port.SwitchID = switchID
+	port.CreatedAt, port.UpdatedAt = sandboxTimestamps(row.ExternalIDs)
+	port.Ownership = models.OwnershipFromExternalIDs(row.ExternalIDs)
+	return copyOf(port)
+}
+
+func (s *SandboxOVNService) UpdatePort(ctx context.Context, id string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("port ID is required")
+	}
+
+	var updated *models.LogicalSwitchPort
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Ports[id]
+		if !ok {
+			return fmt.Errorf("logical switch port %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.updatePort(ctx, existing, port)
+		st.derive()
+		var err error
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+// updatePort copies the fields set in port to existing
+func (st *sandboxState) updatePort(ctx context.Context, existing, port *models.LogicalSwitchPort) {
+	if port.Name != "" {
+		existing.Name = port.Name
+	}
+	if len(port.Addresses) > 0 {
+		existing.Addresses = port.Addresses
+	}
+	if len(port.PortSecurity) > 0 {
+		existing.PortSecurity = port.PortSecurity
+	}
+	if port.Type != "" {
+		existing.Type = port.Type
+	}
+	if port.Options != nil {
+		existing.Options = port.Options
+	}
+	if port.Enabled != nil {
+		existing.Enabled = port.Enabled
+	}
+	if port.Tag > 0 {
+		existing.Tag = port.Tag
+	}
+	existing.ExternalIDs = mergedExternalIDs(ctx, existing.ExternalIDs, port.ExternalIDs)
+}
+
+func (s *SandboxOVNService) DeletePort(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("port ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Ports[id]
+		if !ok {
+			return fmt.Errorf("logical switch port %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		ls, ok := st.Switches[existing.SwitchID]
+		if !ok || !containsID(ls.Ports, id) {
+			return fmt.Errorf("port %s is not attached to any switch", id)
+		}
+		ls.Ports = removeID(ls.Ports, id)
+		st.deletePortRow(id)
+		return nil
+	})
+}
+
+// deletePortRow deletes a port, which port groups and mirrors refer to
+// weakly
+func (st *sandboxState) deletePortRow(id string) {
+	for _, pg := range st.PortGroups {
+		pg.Ports = removeID(pg.Ports, id)
+	}
+	for _, mirror := range st.Mirrors {
+		mirror.Ports = removeID(mirror.Ports, id)
+	}
+	delete(st.Ports, id)
+}
+
+// ACL operations
+
+func (s *SandboxOVNService) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+
+	var acls []*models.ACL
+	err := s.view(func(st *sandboxState) error {
+		ls, err := st.switchByID(switchID)
+		if err != nil {
+			return err
+		}
+		acls, err = copyOf(st.switchACLs(ls))
+		return err
+	})
+	return acls, err
+}
+
+// switchACLs returns the ACLs of a switch
+func (st *sandboxState) switchACLs(ls *models.LogicalSwitch) []*models.ACL {
+	acls := make([]*models.ACL, 0, len(ls.ACLs))
+	for _, aclID := range ls.ACLs {
+		if acl, ok := st.ACLs[aclID]; ok {
+			acls = append(acls, acl)
+		}
+	}
+	return acls
+}
+
+func (s *SandboxOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, 0, fmt.Errorf("switch ID is required")
+	}
+
+	var acls []*models.ACL
+	var total int
+	err := s.view(func(st *sandboxState) error {
+		// Checked first, as the client does
+		if _, _, err := ovn.ResolveSortField(opts, models.ResourceACL); err != nil {
+			return err
+		}
+		ls, ok := st.Switches[switchID]
+		if !ok {
+			return fmt.Errorf("failed to get logical switch %s: %w", switchID, errSandboxNotFound)
+		}
+		var matching []*models.ACL
+		for _, acl := range st.switchACLs(ls) {
+			if opts.Matches(acl.Name, acl.ExternalIDs) {
+				matching = append(matching, acl)
+			}
+		}
+		page, n, err := sandboxPage(opts, models.ResourceACL, matching,
+			func(acl *models.ACL) string { return acl.UUID },
+			func(acl *models.ACL, field string) sandboxSortKey {
+				switch field {
+				case "priority":
+					return sandboxSortKey{n: acl.Priority}
+				case "name":
+					return sandboxSortKey{s: acl.Name}
+				case "direction":
+					return sandboxSortKey{s: acl.Direction}
+				case "action":
+					return sandboxSortKey{s: acl.Action}
+				}
+				return timeSortKey(acl.ExternalIDs, field)
+			})
+		if err != nil {
+			return err
+		}
+		total = n
+		acls, err = copyOf(page)
+		return err
+	})
+	return acls, total, err
+}
+
+func (s *SandboxOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	var acl *models.ACL
+	err := s.view(func(st *sandboxState) error {
+		existing, ok := st.ACLs[id]
+		if !ok {
+			return fmt.Errorf("ACL %s not found", id)
+		}
+		var err error
+		acl, err = copyOf(existing)
+		return err
+	})
+	return acl, err
+}
+
+func (s *SandboxOVNService) CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+	if acl.Match == "" {
+		return nil, fmt.Errorf("ACL match expression is required")
+	}
+	if acl.Action == "" {
+		return nil, fmt.Errorf("ACL action is required")
+	}
+	if acl.Direction == "" {
+		return nil, fmt.Errorf("ACL direction is required")
+	}
+
+	var created *models.ACL
+	err := s.update(ctx, func(st *sandboxState) error {
+		ls, ok := st.Switches[switchID]
+		if !ok {
+			return fmt.Errorf("failed to get logical switch %s: %w", switchID, errSandboxNotFound)
+		}
+		if err := ovn.ValidateACL(acl); err != nil {
+			return err
+		}
+		var err error
+		created, err = st.createACL(ctx, acl)
+		if err != nil {
+			return err
+		}
+		ls.ACLs = append(ls.ACLs, created.UUID)
+		return nil
+	})
+	return created, err
+}
+
+// CreateACLs creates acls on a switch at once, so either all of them are
+// created or none is
+func (s *SandboxOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+	for i, acl := range acls {
+		if acl.Match == "" {
+			return nil, fmt.Errorf("ACL %d: ACL match expression is required", i)
+		}
+		if acl.Action == "" {
+			return nil, fmt.Errorf("ACL %d: ACL action is required", i)
+		}
+		if acl.Direction == "" {
+			return nil, fmt.Errorf("ACL %d: ACL direction is required", i)
+		}
+	}
+	if len(acls) == 0 {
+		return nil, fmt.Errorf("no ACLs provided")
+	}
+
+	var created []*models.ACL
+	err := s.update(ctx, func(st *sandboxState) error {
+		ls, ok := st.Switches[switchID]
+		if !ok {
+			return fmt.Errorf("failed to get logical switch %s: %w", switchID, errSandboxNotFound)
+		}
+		for i, acl := range acls {
+			if err := ovn.ValidateACL(acl); err != nil {
+				return fmt.Errorf("ACL %d: %w", i, err)
+			}
+		}
+		created = make([]*models.ACL, 0, len(acls))
+		for _, acl := range acls {
+			result, err := st.createACL(ctx, acl)
+			if err != nil {
+				return err
+			}
+			ls.ACLs = append(ls.ACLs, result.UUID)
+			created = append(created, result)
+		}
+		return nil
+	})
+	return created, err
+}
+
+// createACL adds a validated ACL, which the caller attaches to its switch
+func (st *sandboxState) createACL(ctx context.Context, acl *models.ACL) (*models.ACL, error) {
+	externalIDs := createdExternalIDs(ctx, acl.ExternalIDs)
+	if acl.Owner != "" {
+		externalIDs[models.ACLOwnerKey] = acl.Owner
+	}
+	row := &models.ACL{
+		UUID:        uuid.New().String(),
+		Name:        acl.Name,
+		Priority:    acl.Priority,
+		Direction:   acl.Direction,
+		Match:       acl.Match,
+		Action:      acl.Action,
+		Log:         acl.Log,
+		Severity:    acl.Severity,
+		ExternalIDs: externalIDs,
+	}
+	st.ACLs[row.UUID] = row
+
+	acl.UUID = row.UUID
+	acl.CreatedAt, acl.UpdatedAt = sandboxTimestamps(row.ExternalIDs)
+	acl.Owner = models.ACLOwnerFromExternalIDs(row.ExternalIDs)
+	acl.Ownership = models.OwnershipFromExternalIDs(row.ExternalIDs)
+	return copyOf(acl)
+}
+
+func (s *SandboxOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	var updated *models.ACL
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.ACLs[id]
+		if !ok {
+			return fmt.Errorf("ACL %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.updateACL(ctx, existing, acl)
+		st.derive()
+		var err error
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+// updateACL copies the fields set in acl to existing
+func (st *sandboxState) updateACL(ctx context.Context, existing, acl *models.ACL) {
+	if acl.Action != "" {
+		existing.Action = acl.Action
+	}
+	if acl.Direction != "" {
+		existing.Direction = acl.Direction
+	}
+	if acl.Match != "" {
+		existing.Match = acl.Match
+	}
+	if acl.Priority > 0 {
+		existing.Priority = acl.Priority
+	}
+	existing.Log = acl.Log
+	if acl.Name != "" {
+		existing.Name = acl.Name
+	}
+	if acl.Severity != "" {
+		existing.Severity = acl.Severity
+	}
+	existing.ExternalIDs = mergedExternalIDs(ctx, existing.ExternalIDs, acl.ExternalIDs)
+	if acl.Owner != "" {
+		existing.ExternalIDs[models.ACLOwnerKey] = acl.Owner
+	}
+}
+
+func (s *SandboxOVNService) DeleteACL(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("ACL ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.ACLs[id]
+		if !ok {
+			return fmt.Errorf("ACL %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		attached := false
+		for _, ls := range st.Switches {
+			if containsID(ls.ACLs, id) {
+				ls.ACLs = removeID(ls.ACLs, id)
+				attached = true
+			}
+		}
+		if !attached {
+			return fmt.Errorf("ACL %s is not attached to any switch", id)
+		}
+		st.deleteACLRow(id)
+		return nil
+	})
+}
+
+// deleteACLRow deletes an ACL along with its sampling
+func (st *sandboxState) deleteACLRow(id string) {
+	delete(st.ACLs, id)
+	delete(st.ACLSampling, id)
+}
+
+// Load Balancer operations
+
+func (s *SandboxOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	var lbs []*models.LoadBalancer
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		lbs, err = copyOf(sortedValues(st.LoadBalancers, func(lb *models.LoadBalancer) string { return lb.Name }))
+		return err
+	})
+	return lbs, err
+}
+
+func (s *SandboxOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	var lbs []*models.LoadBalancer
+	var total int
+	err := s.view(func(st *sandboxState) error {
+		var matching []*models.LoadBalancer
+		for _, lb := range st.LoadBalancers {
+			if opts.Matches(lb.Name, lb.ExternalIDs) {
+				matching = append(matching, lb)
+			}
+		}
+		page, n, err := sandboxPage(opts, models.ResourceLoadBalancer, matching,
+			func(lb *models.LoadBalancer) string { return lb.UUID },
+			func(lb *models.LoadBalancer, field string) sandboxSortKey {
+				switch field {
+				case "name":
+					return sandboxSortKey{s: lb.Name}
+				case "protocol":
+					if lb.Protocol == nil {
+						return sandboxSortKey{}
+					}
+					return sandboxSortKey{s: *lb.Protocol}
+				}
+				return timeSortKey(lb.ExternalIDs, field)
+			})
+		if err != nil {
+			return err
+		}
+		total = n
+		lbs, err = copyOf(page)
+		return err
+	})
+	return lbs, total, err
+}
+
+// loadBalancerByID returns a load balancer by UUID or name
+func (st *sandboxState) loadBalancerByID(id string) (*models.LoadBalancer, error) {
+	if lb, ok := st.LoadBalancers[id]; ok {
+		return lb, nil
+	}
+	for _, lb := range sortedValues(st.LoadBalancers, func(lb *models.LoadBalancer) string { return lb.UUID }) {
+		if lb.Name == id {
+			return lb, nil
+		}
+	}
+	return nil, fmt.Errorf("load balancer %s not found", id)
+}
+
+func (s *SandboxOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("load balancer ID is required")
+	}
+
+	var lb *models.LoadBalancer
+	err := s.view(func(st *sandboxState) error {
+		existing, err := st.loadBalancerByID(id)
+		if err != nil {
+			return err
+		}
+		lb, err = copyOf(existing)
+		return err
+	})
+	return lb, err
+}
+
+func (s *SandboxOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	// Validate input
+	if lb.Name == "" {
+		return nil, fmt.Errorf("load balancer name is required")
+	}
+	if len(lb.VIPs) == 0 {
+		return nil, fmt.Errorf("at least one VIP is required")
+	}
+
+	var created *models.LoadBalancer
+	err := s.update(ctx, func(st *sandboxState) error {
+		var err error
+		created, err = st.createLoadBalancer(ctx, lb)
+		return err
+	})
+	return created, err
+}
+
+func (st *sandboxState) createLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	if lb.UUID == "" {
+		lb.UUID = uuid.New().String()
+	}
+	lb.ExternalIDs = createdExternalIDs(ctx, lb.ExternalIDs)
+	lb.CreatedAt, lb.UpdatedAt = sandboxTimestamps(lb.ExternalIDs)
+
+	row, err := copyOf(lb)
+	if err != nil {
+		return nil, err
+	}
+	row.HealthCheck = nil
+	st.LoadBalancers[row.UUID] = row
+	return copyOf(lb)
+}
+
+func (s *SandboxOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("load balancer ID is required")
+	}
+
+	var updated *models.LoadBalancer
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.loadBalancerByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.updateLoadBalancer(existing, lb)
+		st.derive()
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+// updateLoadBalancer copies the fields set in updates to existing
+func (st *sandboxState) updateLoadBalancer(existing, updates *models.LoadBalancer) {
+	if updates.Name != "" {
+		existing.Name = updates.Name
+	}
+	if updates.VIPs != nil {
+		existing.VIPs = updates.VIPs
+	}
+	if updates.Protocol != nil {
+		existing.Protocol = updates.Protocol
+	}
+	if updates.IPPortMappings != nil {
+		existing.IPPortMappings = updates.IPPortMappings
+	}
+	if updates.SelectionFields != nil {
+		existing.SelectionFields = updates.SelectionFields
+	}
+	if updates.Options != nil {
+		existing.Options = updates.Options
+	}
+	if updates.ExternalIDs != nil {
+		existing.ExternalIDs = updates.ExternalIDs
+	}
+	if existing.ExternalIDs == nil {
+		existing.ExternalIDs = make(map[string]string)
+	}
+	existing.ExternalIDs["updated_at"] = time.Now().Format(time.RFC3339)
+}
+
+func (s *SandboxOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("load balancer ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.loadBalancerByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.deleteLoadBalancer(existing.UUID)
+		return nil
+	})
+}
+
+// deleteLoadBalancer deletes a load balancer and detaches it from the
+// switches and routers it's applied to
+func (st *sandboxState) deleteLoadBalancer(id string) {
+	for _, ls := range st.Switches {
+		ls.LoadBalancer = removeID(ls.LoadBalancer, id)
+	}
+	for _, lr := range st.Routers {
+		lr.LoadBalancer = removeID(lr.LoadBalancer, id)
+	}
+	delete(st.LoadBalancers, id)
+}
+
+// Topology operations
+
+// GetTopology returns the sandbox's network topology
+func (s *SandboxOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+	topology := &Topology{Timestamp: time.Now()}
+	err := s.view(func(st *sandboxState) error {
+		switches, err := copyOf(sortedValues(st.Switches, func(ls *models.LogicalSwitch) string { return ls.Name }))
+		if err != nil {
+			return err
+		}
+		routers := make([]*models.LogicalRouter, 0, len(st.Routers))
+		for _, lr := range sortedValues(st.Routers, func(lr *models.LogicalRouter) string { return lr.Name }) {
+			router, err := st.routerModel(lr)
+			if err != nil {
+				return err
+			}
+			for _, nat := range st.routerNATs(lr.UUID) {
+				router.NAT = append(router.NAT, nat.NAT)
+			}
+			for _, policy := range st.routerPolicies(lr) {
+				router.PolicyRules = append(router.PolicyRules, *policy)
+			}
+			routers = append(routers, router)
+		}
+		var ports []*models.LogicalSwitchPort
+		for _, ls := range switches {
+			ports = append(ports, st.switchPorts(st.Switches[ls.UUID])...)
+		}
+		if ports, err = copyOf(ports); err != nil {
+			return err
+		}
+		routerPorts := make([]*models.LogicalRouterPort, 0, len(st.RouterPorts))
+		for _, lrp := range sortedValues(st.RouterPorts, func(lrp *sandboxRouterPort) string { return lrp.Name }) {
+			routerPort, err := copyOf(lrp.LogicalRouterPort)
+			if err != nil {
+				return err
+			}
+			routerPorts = append(routerPorts, &routerPort)
+		}
+
+		topology.Switches = switches
+		topology.Routers = routers
+		topology.Ports = ports
+		topology.RouterPorts = routerPorts
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// seedSandbox creates the demo topology of an empty sandbox: web and app
+// tier switches connected by an edge router, with a few ports, an ACL and
+// a load balancer
+func seedSandbox(ctx context.Context, st *sandboxState) error {
+	router, err := st.createRouter(ctx, &models.LogicalRouter{Name: "edge-router"})
+	if err != nil {
+		return err
+	}
+
+	tiers := []struct {
+		name    string
+		mac     string
+		network string
+		gateway string
+		hosts   []string
+	}{
+		{"web-tier", "00:00:00:00:01:01", "10.0.1.1/24", "10.0.1.1", []string{"10.0.1.10", "10.0.1.11"}},
+		{"app-tier", "00:00:00:00:02:01", "10.0.2.1/24", "10.0.2.1", []string{"10.0.2.10"}},
+	}
+	var backends []string
+	for i, tier := range tiers {
+		ls, err := st.createSwitch(ctx, &models.LogicalSwitch{Name: tier.name})
+		if err != nil {
+			return err
+		}
+		routerPort := tier.name + "-router"
+		if _, err := st.createRouterPort(ctx, router.UUID, &models.LogicalRouterPort{
+			Name:     routerPort,
+			MAC:      tier.mac,
+			Networks: []string{tier.network},
+		}); err != nil {
+			return err
+		}
+		if _, err := st.createPort(ctx, ls.UUID, &models.LogicalSwitchPort{
+			Name:      tier.name + "-to-router",
+			Type:      "router",
+			Addresses: []string{"router"},
+			Options:   map[string]string{"router-port": routerPort},
+		}); err != nil {
+			return err
+		}
+		for j, host := range tier.hosts {
+			mac := fmt.Sprintf("0a:00:00:00:%02x:%02x", i+1, j+10)
+			if _, err := st.createPort(ctx, ls.UUID, &models.LogicalSwitchPort{
+				Name:      fmt.Sprintf("%s-vm%d", strings.TrimSuffix(tier.name, "-tier"), j+1),
+				Addresses: []string{mac + " " + host},
+			}); err != nil {
+				return err
+			}
+			if tier.name == "web-tier" {
+				backends = append(backends, host+":80")
+			}
+		}
+	}
+
+	web, err := st.switchByID("web-tier")
+	if err != nil {
+		return err
+	}
+	acl, err := st.createACL(ctx, &models.ACL{
+		Name:      "allow-http",
+		Priority:  1000,
+		Direction: "to-lport",
+		Match:     "tcp.dst == 80",
+		Action:    "allow-related",
+	})
+	if err != nil {
+		return err
+	}
+	web.ACLs = append(web.ACLs, acl.UUID)
+
+	protocol := "tcp"
+	lb, err := st.createLoadBalancer(ctx, &models.LoadBalancer{
+		Name:     "web-lb",
+		VIPs:     map[string]string{"10.0.0.100:80": strings.Join(backends, ",")},
+		Protocol: &protocol,
+	})
+	if err != nil {
+		return err
+	}
+	web.LoadBalancer = append(web.LoadBalancer, lb.UUID)
+	return nil
+}
@@ -0,0 +1,990 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Sandbox limits, as OVN sets them
+const (
+	sandboxMaxSamplingID         = 255
+	sandboxMaxObservationPointID = 1<<32 - 1
+)
+
+// Port Group and Address Set operations
+
+func (s *SandboxOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	var pgs []*models.PortGroup
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		pgs, err = copyOf(sortedValues(st.PortGroups, func(pg *models.PortGroup) string { return pg.Name }))
+		return err
+	})
+	return pgs, err
+}
+
+func (s *SandboxOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	var ass []*models.AddressSet
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		ass, err = copyOf(sortedValues(st.AddressSets, func(as *models.AddressSet) string { return as.Name }))
+		return err
+	})
+	return ass, err
+}
+
+func (s *SandboxOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	// Validate input
+	if portGroupID == "" {
+		return nil, fmt.Errorf("port group ID is required")
+	}
+
+	var acls []*models.ACL
+	err := s.view(func(st *sandboxState) error {
+		pg, ok := st.PortGroups[portGroupID]
+		if !ok {
+			return fmt.Errorf("port group %s not found", portGroupID)
+		}
+		result := make([]*models.ACL, 0, len(pg.ACLs))
+		for _, aclID := range pg.ACLs {
+			if acl, ok := st.ACLs[aclID]; ok {
+				result = append(result, acl)
+			}
+		}
+		var err error
+		acls, err = copyOf(result)
+		return err
+	})
+	return acls, err
+}
+
+// ReplaceOwnedObjects replaces the port groups and address sets whose
+// external IDs include owner, along with the ACLs of the port groups
+func (s *SandboxOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	for _, as := range addressSets {
+		if as.Name == "" {
+			return nil, fmt.Errorf("address set name is required")
+		}
+	}
+	for _, spec := range portGroups {
+		if spec.PortGroup == nil || spec.PortGroup.Name == "" {
+			return nil, fmt.Errorf("port group name is required")
+		}
+	}
+	if len(owner) == 0 {
+		return nil, fmt.Errorf("owner is required")
+	}
+
+	var created *ovn.OwnedObjects
+	err := s.update(ctx, func(st *sandboxState) error {
+		for id, pg := range st.PortGroups {
+			if hasSandboxExternalIDs(pg.ExternalIDs, owner) {
+				for _, aclID := range pg.ACLs {
+					st.deleteACLRow(aclID)
+				}
+				delete(st.PortGroups, id)
+			}
+		}
+		for id, as := range st.AddressSets {
+			if hasSandboxExternalIDs(as.ExternalIDs, owner) {
+				delete(st.AddressSets, id)
+			}
+		}
+
+		now := time.Now()
+		created = &ovn.OwnedObjects{
+			PortGroups:  []*models.PortGroup{},
+			AddressSets: []*models.AddressSet{},
+		}
+
+		for _, as := range addressSets {
+			for _, existing := range st.AddressSets {
+				if existing.Name == as.Name {
+					return fmt.Errorf("address set %s already exists", as.Name)
+				}
+			}
+			as.UUID = uuid.New().String()
+			as.ExternalIDs = ownedSandboxExternalIDs(as.ExternalIDs, owner, now)
+			as.CreatedAt, as.UpdatedAt = sandboxTimestamps(as.ExternalIDs)
+			row, err := copyOf(as)
+			if err != nil {
+				return err
+			}
+			st.AddressSets[row.UUID] = row
+			created.AddressSets = append(created.AddressSets, as)
+		}
+
+		for _, spec := range portGroups {
+			pg := spec.PortGroup
+			for _, existing := range st.PortGroups {
+				if existing.Name == pg.Name {
+					return fmt.Errorf("port group %s already exists", pg.Name)
+				}
+			}
+			pg.UUID = uuid.New().String()
+			pg.ExternalIDs = ownedSandboxExternalIDs(pg.ExternalIDs, owner, now)
+			pg.ACLs = make([]string, 0, len(spec.ACLs))
+
+			for _, acl := range spec.ACLs {
+				if err := ovn.ValidateACL(acl); err != nil {
+					return fmt.Errorf("port group %s: %w", pg.Name, err)
+				}
+				acl.UUID = uuid.New().String()
+				acl.ExternalIDs = ownedSandboxExternalIDs(acl.ExternalIDs, owner, now)
+				st.ACLs[acl.UUID] = &models.ACL{
+					UUID:        acl.UUID,
+					Name:        acl.Name,
+					Priority:    acl.Priority,
+					Direction:   acl.Direction,
+					Match:       acl.Match,
+					Action:      acl.Action,
+					Log:         acl.Log,
+					Severity:    acl.Severity,
+					ExternalIDs: acl.ExternalIDs,
+				}
+				pg.ACLs = append(pg.ACLs, acl.UUID)
+			}
+
+			pg.CreatedAt, pg.UpdatedAt = sandboxTimestamps(pg.ExternalIDs)
+			row, err := copyOf(pg)
+			if err != nil {
+				return err
+			}
+			st.PortGroups[row.UUID] = row
+			created.PortGroups = append(created.PortGroups, pg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// hasSandboxExternalIDs reports whether externalIDs include every key and
+// value of want
+func hasSandboxExternalIDs(externalIDs, want map[string]string) bool {
+	for k, v := range want {
+		if externalIDs[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ownedSandboxExternalIDs copies externalIDs, adding the owner's keys and
+// the creation timestamps
+func ownedSandboxExternalIDs(externalIDs, owner map[string]string, now time.Time) map[string]string {
+	result := make(map[string]string, len(externalIDs)+len(owner)+2)
+	for k, v := range externalIDs {
+		result[k] = v
+	}
+	for k, v := range owner {
+		result[k] = v
+	}
+	result["created_at"] = now.Format(time.RFC3339)
+	result["updated_at"] = now.Format(time.RFC3339)
+	return result
+}
+
+// DNS operations
+
+func (s *SandboxOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	var records []*models.DNS
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		records, err = copyOf(sortedValues(st.DNS, func(dns *models.DNS) string { return dns.UUID }))
+		return err
+	})
+	return records, err
+}
+
+func (s *SandboxOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("DNS record set ID is required")
+	}
+
+	var dns *models.DNS
+	err := s.view(func(st *sandboxState) error {
+		existing, ok := st.DNS[id]
+		if !ok {
+			return fmt.Errorf("DNS record set %s not found", id)
+		}
+		var err error
+		dns, err = copyOf(existing)
+		return err
+	})
+	return dns, err
+}
+
+func (s *SandboxOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	var created *models.DNS
+	err := s.update(ctx, func(st *sandboxState) error {
+		if err := ovn.ValidateDNSRecords(dns.Records); err != nil {
+			return err
+		}
+		if err := st.checkSwitchesExist(dns.Switches); err != nil {
+			return err
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		externalIDs := make(map[string]string, len(dns.ExternalIDs)+2)
+		for k, v := range dns.ExternalIDs {
+			externalIDs[k] = v
+		}
+		externalIDs["created_at"] = now
+		externalIDs["updated_at"] = now
+
+		row := &models.DNS{
+			UUID:        uuid.New().String(),
+			Records:     dns.Records,
+			Options:     dns.Options,
+			Switches:    dns.Switches,
+			ExternalIDs: externalIDs,
+		}
+		st.DNS[row.UUID] = row
+		st.derive()
+		var err error
+		created, err = copyOf(row)
+		return err
+	})
+	return created, err
+}
+
+// checkSwitchesExist checks that switches, by UUID, exist
+func (st *sandboxState) checkSwitchesExist(switches []string) error {
+	for _, switchID := range switches {
+		if _, ok := st.Switches[switchID]; !ok {
+			return fmt.Errorf("logical switch %s not found", switchID)
+		}
+	}
+	return nil
+}
+
+func (s *SandboxOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("DNS record set ID is required")
+	}
+
+	var updated *models.DNS
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.DNS[id]
+		if !ok {
+			return fmt.Errorf("DNS record set %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		if dns.Records != nil {
+			if err := ovn.ValidateDNSRecords(dns.Records); err != nil {
+				return err
+			}
+			existing.Records = dns.Records
+		}
+		if dns.Options != nil {
+			existing.Options = dns.Options
+		}
+		if dns.Switches != nil {
+			if err := st.checkSwitchesExist(dns.Switches); err != nil {
+				return err
+			}
+			existing.Switches = dns.Switches
+		}
+		existing.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, dns.ExternalIDs)
+		st.derive()
+		var err error
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SandboxOVNService) DeleteDNS(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("DNS record set ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.DNS[id]
+		if !ok {
+			return fmt.Errorf("DNS record set %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		delete(st.DNS, id)
+		return nil
+	})
+}
+
+// Mirror operations
+
+func (s *SandboxOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	var mirrors []*models.Mirror
+	err := s.view(func(st *sandboxState) error {
+		var err error
+		mirrors, err = copyOf(sortedValues(st.Mirrors, func(mirror *models.Mirror) string { return mirror.Name }))
+		return err
+	})
+	return mirrors, err
+}
+
+// mirrorByID returns a mirror by UUID or name
+func (st *sandboxState) mirrorByID(id string) (*models.Mirror, error) {
+	if mirror, ok := st.Mirrors[id]; ok {
+		return mirror, nil
+	}
+	for _, mirror := range st.Mirrors {
+		if mirror.Name == id {
+			return mirror, nil
+		}
+	}
+	return nil, fmt.Errorf("mirror %s not found", id)
+}
+
+func (s *SandboxOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("mirror ID is required")
+	}
+
+	var mirror *models.Mirror
+	err := s.view(func(st *sandboxState) error {
+		existing, err := st.mirrorByID(id)
+		if err != nil {
+			return err
+		}
+		mirror, err = copyOf(existing)
+		return err
+	})
+	return mirror, err
+}
+
+func (s *SandboxOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	var created *models.Mirror
+	err := s.update(ctx, func(st *sandboxState) error {
+		if err := ovn.ValidateMirror(mirror); err != nil {
+			return err
+		}
+		for _, existing := range st.Mirrors {
+			if existing.Name == mirror.Name {
+				return fmt.Errorf("mirror %s already exists", mirror.Name)
+			}
+		}
+		if err := st.checkPortsExist(mirror.Ports); err != nil {
+			return err
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		externalIDs := make(map[string]string, len(mirror.ExternalIDs)+2)
+		for k, v := range mirror.ExternalIDs {
+			externalIDs[k] = v
+		}
+		externalIDs["created_at"] = now
+		externalIDs["updated_at"] = now
+
+		row := &models.Mirror{
+			UUID:        uuid.New().String(),
+			Name:        mirror.Name,
+			Type:        mirror.Type,
+			Filter:      mirror.Filter,
+			Sink:        mirror.Sink,
+			Index:       mirror.Index,
+			Ports:       mirror.Ports,
+			ExternalIDs: externalIDs,
+		}
+		st.Mirrors[row.UUID] = row
+		st.derive()
+		var err error
+		created, err = copyOf(row)
+		return err
+	})
+	return created, err
+}
+
+// checkPortsExist checks that switch ports, by UUID, exist
+func (st *sandboxState) checkPortsExist(ports []string) error {
+	for _, portID := range ports {
+		if _, ok := st.Ports[portID]; !ok {
+			return fmt.Errorf("logical switch port %s not found", portID)
+		}
+	}
+	return nil
+}
+
+func (s *SandboxOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("mirror ID is required")
+	}
+
+	var updated *models.Mirror
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.mirrorByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+
+		merged := *existing
+		if mirror.Type != "" {
+			merged.Type = mirror.Type
+		}
+		if mirror.Filter != "" {
+			merged.Filter = mirror.Filter
+		}
+		if mirror.Sink != "" {
+			merged.Sink = mirror.Sink
+		}
+		if mirror.Index != 0 {
+			merged.Index = mirror.Index
+		}
+		if merged.Type == "local" || merged.Type == "lport" {
+			merged.Index = 0
+		}
+		if err := ovn.ValidateMirror(&merged); err != nil {
+			return err
+		}
+		merged.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, mirror.ExternalIDs)
+		if mirror.Ports != nil {
+			if err := st.checkPortsExist(mirror.Ports); err != nil {
+				return err
+			}
+			merged.Ports = mirror.Ports
+		}
+		*existing = merged
+		st.derive()
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SandboxOVNService) DeleteMirror(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("mirror ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.mirrorByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		delete(st.Mirrors, existing.UUID)
+		return nil
+	})
+}
+
+// Sampling operations
+
+func (s *SandboxOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	var collectors []*models.SampleCollector
+	err := s.view(func(st *sandboxState) error {
+		result := sortedValues(st.Collectors, func(collector *models.SampleCollector) string { return collector.UUID })
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].ID < result[j].ID
+		})
+		var err error
+		collectors, err = copyOf(result)
+		return err
+	})
+	return collectors, err
+}
+
+// collectorByID returns a sample collector by UUID or name
+func (st *sandboxState) collectorByID(id string) (*models.SampleCollector, error) {
+	if collector, ok := st.Collectors[id]; ok {
+		return collector, nil
+	}
+	for _, collector := range st.Collectors {
+		if collector.Name == id {
+			return collector, nil
+		}
+	}
+	return nil, fmt.Errorf("sample collector %s not found", id)
+}
+
+func (s *SandboxOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("sample collector ID is required")
+	}
+
+	var collector *models.SampleCollector
+	err := s.view(func(st *sandboxState) error {
+		existing, err := st.collectorByID(id)
+		if err != nil {
+			return err
+		}
+		collector, err = copyOf(existing)
+		return err
+	})
+	return collector, err
+}
+
+func (s *SandboxOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	var created *models.SampleCollector
+	err := s.update(ctx, func(st *sandboxState) error {
+		used := make(map[int]bool, len(st.Collectors))
+		for _, existing := range st.Collectors {
+			if existing.Name == collector.Name {
+				return fmt.Errorf("sample collector %s already exists", collector.Name)
+			}
+			used[existing.ID] = true
+		}
+
+		row, err := copyOf(collector)
+		if err != nil {
+			return err
+		}
+		if row.ID == 0 {
+			for id := 1; id <= sandboxMaxSamplingID && row.ID == 0; id++ {
+				if !used[id] {
+					row.ID = id
+				}
+			}
+			if row.ID == 0 {
+				return fmt.Errorf("invalid sample collector: all %d collector IDs are in use", sandboxMaxSamplingID)
+			}
+		}
+		if err := ovn.ValidateSampleCollector(row); err != nil {
+			return err
+		}
+		if used[row.ID] {
+			return fmt.Errorf("sample collector ID %d already exists", row.ID)
+		}
+
+		row.UUID = uuid.New().String()
+		externalIDs := make(map[string]string, len(collector.ExternalIDs))
+		for k, v := range collector.ExternalIDs {
+			externalIDs[k] = v
+		}
+		row.ExternalIDs = externalIDs
+		st.Collectors[row.UUID] = row
+		created, err = copyOf(row)
+		return err
+	})
+	return created, err
+}
+
+func (s *SandboxOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("sample collector ID is required")
+	}
+
+	var updated *models.SampleCollector
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.collectorByID(id)
+		if err != nil {
+			return err
+		}
+		if collector.ID != 0 && collector.ID != existing.ID {
+			return fmt.Errorf("invalid sample collector: the ID can't be changed")
+		}
+		if collector.Name != "" && collector.Name != existing.Name {
+			if _, err := st.collectorByID(collector.Name); err == nil {
+				return fmt.Errorf("sample collector %s already exists", collector.Name)
+			}
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+
+		merged := *existing
+		if collector.Name != "" {
+			merged.Name = collector.Name
+		}
+		if collector.SetID != 0 {
+			merged.SetID = collector.SetID
+		}
+		if collector.Probability != nil {
+			merged.Probability = collector.Probability
+		}
+		if err := ovn.ValidateSampleCollector(&merged); err != nil {
+			return err
+		}
+		externalIDs := make(map[string]string, len(existing.ExternalIDs)+len(collector.ExternalIDs))
+		for k, v := range existing.ExternalIDs {
+			externalIDs[k] = v
+		}
+		for k, v := range collector.ExternalIDs {
+			externalIDs[k] = v
+		}
+		merged.ExternalIDs = externalIDs
+		*existing = merged
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SandboxOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("sample collector ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.collectorByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		inUse := 0
+		for _, sample := range st.samples() {
+			if containsID(sample.Collectors, existing.UUID) {
+				inUse++
+			}
+		}
+		if inUse > 0 {
+			return fmt.Errorf("sample collector %s is in use by %d observation points", existing.Name, inUse)
+		}
+		delete(st.Collectors, existing.UUID)
+		return nil
+	})
+}
+
+func (s *SandboxOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	var apps []*models.SamplingApp
+	err := s.view(func(st *sandboxState) error {
+		apps = make([]*models.SamplingApp, 0, len(st.SamplingApps))
+		for appType, id := range st.SamplingApps {
+			apps = append(apps, &models.SamplingApp{Type: appType, ID: id})
+		}
+		sort.Slice(apps, func(i, j int) bool {
+			return apps[i].Type < apps[j].Type
+		})
+		return nil
+	})
+	return apps, err
+}
+
+// SetSamplingApp sets the observation domain ID of the samples of a type.
+// An ID of 0 unsets it, and nil is returned.
+func (s *SandboxOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	switch appType {
+	case "drop", "acl-new", "acl-est":
+	default:
+		return nil, fmt.Errorf("invalid sampling app: type must be drop, acl-new or acl-est")
+	}
+	if id < 0 || id > sandboxMaxSamplingID {
+		return nil, fmt.Errorf("invalid sampling app: observation domain ID must be between 1 and %d", sandboxMaxSamplingID)
+	}
+
+	var app *models.SamplingApp
+	err := s.update(ctx, func(st *sandboxState) error {
+		if id == 0 {
+			delete(st.SamplingApps, appType)
+			return nil
+		}
+		st.SamplingApps[appType] = id
+		app = &models.SamplingApp{Type: appType, ID: id}
+		return nil
+	})
+	return app, err
+}
+
+// samples returns every observation point set on ACLs
+func (st *sandboxState) samples() []*models.FlowSample {
+	var samples []*models.FlowSample
+	for _, sampling := range st.ACLSampling {
+		for _, sample := range []*models.FlowSample{sampling.New, sampling.Established} {
+			if sample != nil {
+				samples = append(samples, sample)
+			}
+		}
+	}
+	return samples
+}
+
+// aclSampling returns the sampling of an ACL
+func (st *sandboxState) aclSampling(aclID string) (*models.ACLSampling, error) {
+	sampling, ok := st.ACLSampling[aclID]
+	if !ok {
+		return &models.ACLSampling{ACLID: aclID}, nil
+	}
+	return copyOf(sampling)
+}
+
+func (s *SandboxOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	// Validate input
+	if aclID == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	var sampling *models.ACLSampling
+	err := s.view(func(st *sandboxState) error {
+		if _, ok := st.ACLs[aclID]; !ok {
+			return fmt.Errorf("ACL %s not found", aclID)
+		}
+		var err error
+		sampling, err = st.aclSampling(aclID)
+		return err
+	})
+	return sampling, err
+}
+
+// SetACLSampling replaces the sampling of an ACL. Packets of new and of
+// established connections are sampled when given and no longer sampled
+// otherwise. Observation points not given an ID get the lowest one free.
+func (s *SandboxOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	// Validate input
+	if aclID == "" {
+		return nil, fmt.Errorf("ACL ID is required")
+	}
+
+	var result *models.ACLSampling
+	err := s.update(ctx, func(st *sandboxState) error {
+		acl, ok := st.ACLs[aclID]
+		if !ok {
+			return fmt.Errorf("ACL %s not found", aclID)
+		}
+		if sampling.Established != nil && !sandboxTracksConnections(acl) {
+			return fmt.Errorf("invalid sampling: established connections are only tracked for allow and allow-related ACLs")
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, acl); err != nil {
+			return err
+		}
+
+		plan := st.newSamplingPlan()
+		if err := plan.setACL(aclID, sampling); err != nil {
+			return err
+		}
+		var err error
+		result, err = st.aclSampling(aclID)
+		return err
+	})
+	return result, err
+}
+
+// SetSwitchSampling replaces the sampling of every ACL of a switch, as
+// SetACLSampling does. Each ACL gets its own observation points, so their
+// IDs can't be given. Established connections are only sampled for the ACLs
+// tracking them.
+func (s *SandboxOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	// Validate input
+	if switchID == "" {
+		return nil, fmt.Errorf("switch ID is required")
+	}
+
+	var result []*models.ACLSampling
+	err := s.update(ctx, func(st *sandboxState) error {
+		ls, ok := st.Switches[switchID]
+		if !ok {
+			return fmt.Errorf("logical switch %s not found", switchID)
+		}
+		for _, sample := range []*models.FlowSample{sampling.New, sampling.Established} {
+			if sample != nil && sample.ObservationPointID != 0 {
+				return fmt.Errorf("invalid sampling: observation point IDs are allocated per ACL when sampling a switch")
+			}
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, ls); err != nil {
+			return err
+		}
+
+		plan := st.newSamplingPlan()
+		for _, aclID := range ls.ACLs {
+			acl, ok := st.ACLs[aclID]
+			if !ok {
+				continue
+			}
+			aclSampling := &models.ACLSampling{New: sampling.New}
+			if sandboxTracksConnections(acl) {
+				aclSampling.Established = sampling.Established
+			}
+			if err := plan.setACL(aclID, aclSampling); err != nil {
+				return fmt.Errorf("ACL %s: %w", aclID, err)
+			}
+		}
+
+		result = make([]*models.ACLSampling, 0, len(ls.ACLs))
+		for _, aclID := range ls.ACLs {
+			aclSampling, err := st.aclSampling(aclID)
+			if err != nil {
+				return err
+			}
+			result = append(result, aclSampling)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// sandboxTracksConnections reports whether OVN tracks the connections acl
+// allows, so that it has established connections to sample
+func sandboxTracksConnections(acl *models.ACL) bool {
+	return acl.Action == "allow" || acl.Action == "allow-related"
+}
+
+// sandboxSamplingPlan sets the sampling of ACLs, keeping track of the
+// observation point IDs taken as it hands them out
+type sandboxSamplingPlan struct {
+	st   *sandboxState
+	used map[int]bool
+	next int
+}
+
+func (st *sandboxState) newSamplingPlan() *sandboxSamplingPlan {
+	plan := &sandboxSamplingPlan{st: st, used: make(map[int]bool), next: 1}
+	for _, sample := range st.samples() {
+		plan.used[sample.ObservationPointID] = true
+	}
+	return plan
+}
+
+// setACL sets the sampling of an ACL
+func (p *sandboxSamplingPlan) setACL(aclID string, sampling *models.ACLSampling) error {
+	current := p.st.ACLSampling[aclID]
+	if current == nil {
+		current = &models.ACLSampling{}
+	}
+	sampleNew, err := p.sample(current.New, sampling.New)
+	if err != nil {
+		return err
+	}
+	sampleEst, err := p.sample(current.Established, sampling.Established)
+	if err != nil {
+		return err
+	}
+
+	if sampleNew == nil && sampleEst == nil {
+		delete(p.st.ACLSampling, aclID)
+		return nil
+	}
+	p.st.ACLSampling[aclID] = &models.ACLSampling{ACLID: aclID, New: sampleNew, Established: sampleEst}
+	return nil
+}
+
+// sample returns the observation point replacing current for want. The
+// current ID is kept, with want's collectors, unless want asks for another.
+func (p *sandboxSamplingPlan) sample(current, want *models.FlowSample) (*models.FlowSample, error) {
+	if want == nil {
+		return nil, nil
+	}
+	if want.ObservationPointID < 0 || int64(want.ObservationPointID) > sandboxMaxObservationPointID {
+		return nil, fmt.Errorf("invalid sampling: observation point ID must be between 1 and %d", int64(sandboxMaxObservationPointID))
+	}
+	if len(want.Collectors) == 0 {
+		return nil, fmt.Errorf("invalid sampling: at least one collector is required")
+	}
+	collectors := make([]string, 0, len(want.Collectors))
+	seen := make(map[string]bool, len(want.Collectors))
+	for _, id := range want.Collectors {
+		collector, err := p.st.collectorByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[collector.UUID] {
+			seen[collector.UUID] = true
+			collectors = append(collectors, collector.UUID)
+		}
+	}
+	sort.Strings(collectors)
+
+	if current != nil && (want.ObservationPointID == 0 || want.ObservationPointID == current.ObservationPointID) {
+		return &models.FlowSample{ObservationPointID: current.ObservationPointID, Collectors: collectors}, nil
+	}
+
+	id := want.ObservationPointID
+	if id == 0 {
+		for p.used[p.next] {
+			p.next++
+		}
+		id = p.next
+	} else if p.used[id] {
+		return nil, fmt.Errorf("observation point ID %d already exists", id)
+	}
+	p.used[id] = true
+	return &models.FlowSample{ObservationPointID: id, Collectors: collectors}, nil
+}
+
+// Label operations
+
+// SetLabels sets and removes labels of a switch, router, port or ACL; see
+// models.LabelPrefix
+func (s *SandboxOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%s ID is required", resource)
+	}
+
+	var labels map[string]string
+	err := s.update(ctx, func(st *sandboxState) error {
+		var current interface{}
+		var externalIDs *map[string]string
+		var name string
+		var found bool
+		switch resource {
+		case models.ResourceSwitch:
+			name = "logical switch"
+			if ls, ok := st.Switches[id]; ok {
+				current, externalIDs, found = ls, &ls.ExternalIDs, true
+			}
+		case models.ResourceRouter:
+			name = "logical router"
+			if lr, ok := st.Routers[id]; ok {
+				current, externalIDs, found = lr, &lr.ExternalIDs, true
+			}
+		case models.ResourcePort:
+			name = "logical switch port"
+			if port, ok := st.Ports[id]; ok {
+				current, externalIDs, found = port, &port.ExternalIDs, true
+			}
+		case models.ResourceACL:
+			name = "ACL"
+			if acl, ok := st.ACLs[id]; ok {
+				current, externalIDs, found = acl, &acl.ExternalIDs, true
+			}
+		default:
+			return fmt.Errorf("resource %s has no labels", resource)
+		}
+		if !found {
+			return fmt.Errorf("%s %s not found", name, id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, current); err != nil {
+			return err
+		}
+
+		if *externalIDs == nil {
+			*externalIDs = make(map[string]string)
+		}
+		(*externalIDs)["updated_at"] = time.Now().Format(time.RFC3339)
+		ovn.StampUpdated(ctx, *externalIDs)
+		for _, key := range remove {
+			delete(*externalIDs, models.LabelPrefix+key)
+		}
+		for key, value := range set {
+			(*externalIDs)[models.LabelPrefix+key] = value
+		}
+		labels = models.LabelsFromExternalIDs(*externalIDs)
+		return nil
+	})
+	return labels, err
+}
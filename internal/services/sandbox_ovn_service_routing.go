@@ -0,0 +1,965 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Logical Router operations
+
+func (s *SandboxOVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
+	var routers []*models.LogicalRouter
+	err := s.view(func(st *sandboxState) error {
+		for _, lr := range sortedValues(st.Routers, func(lr *models.LogicalRouter) string { return lr.Name }) {
+			router, err := st.routerModel(lr)
+			if err != nil {
+				return err
+			}
+			routers = append(routers, router)
+		}
+		return nil
+	})
+	return routers, err
+}
+
+func (s *SandboxOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	var routers []*models.LogicalRouter
+	var total int
+	err := s.view(func(st *sandboxState) error {
+		var matching []*models.LogicalRouter
+		for _, lr := range st.Routers {
+			if opts.Matches(lr.Name, lr.ExternalIDs) {
+				matching = append(matching, lr)
+			}
+		}
+		page, n, err := sandboxPage(opts, models.ResourceRouter, matching,
+			func(lr *models.LogicalRouter) string { return lr.UUID },
+			func(lr *models.LogicalRouter, field string) sandboxSortKey {
+				if field == "name" {
+					return sandboxSortKey{s: lr.Name}
+				}
+				return timeSortKey(lr.ExternalIDs, field)
+			})
+		if err != nil {
+			return err
+		}
+		total = n
+		for _, lr := range page {
+			router, err := st.routerModel(lr)
+			if err != nil {
+				return err
+			}
+			routers = append(routers, router)
+		}
+		return nil
+	})
+	return routers, total, err
+}
+
+// routerModel returns a copy of a router as the client converts it, NAT
+// rules and static routes being listed separately
+func (st *sandboxState) routerModel(lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	router, err := copyOf(lr)
+	if err != nil {
+		return nil, err
+	}
+	router.StaticRoutes = []models.StaticRoute{}
+	router.NAT = []models.NAT{}
+	router.PolicyRules = nil
+	return router, nil
+}
+
+// routerByID returns a router by UUID or name
+func (st *sandboxState) routerByID(id string) (*models.LogicalRouter, error) {
+	if lr, ok := st.Routers[id]; ok {
+		return lr, nil
+	}
+	for _, lr := range sortedValues(st.Routers, func(lr *models.LogicalRouter) string { return lr.UUID }) {
+		if lr.Name == id {
+			return lr, nil
+		}
+	}
+	return nil, fmt.Errorf("logical router %s not found", id)
+}
+
+func (s *SandboxOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
+	var router *models.LogicalRouter
+	err := s.view(func(st *sandboxState) error {
+		lr, err := st.routerByID(id)
+		if err != nil {
+			return err
+		}
+		router, err = st.routerModel(lr)
+		return err
+	})
+	return router, err
+}
+
+func (s *SandboxOVNService) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	// Validate input
+	if lr.Name == "" {
+		return nil, fmt.Errorf("logical router name is required")
+	}
+
+	var created *models.LogicalRouter
+	err := s.update(ctx, func(st *sandboxState) error {
+		var err error
+		created, err = st.createRouter(ctx, lr)
+		return err
+	})
+	return created, err
+}
+
+// createRouter adds a router along with the static routes given in it
+func (st *sandboxState) createRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	if lr.UUID == "" {
+		lr.UUID = uuid.New().String()
+	}
+	lr.ExternalIDs = createdExternalIDs(ctx, lr.ExternalIDs)
+	lr.CreatedAt, lr.UpdatedAt = sandboxTimestamps(lr.ExternalIDs)
+	lr.Ownership = models.OwnershipFromExternalIDs(lr.ExternalIDs)
+
+	st.Routers[lr.UUID] = &models.LogicalRouter{
+		UUID:        lr.UUID,
+		Name:        lr.Name,
+		Options:     lr.Options,
+		ExternalIDs: lr.ExternalIDs,
+	}
+	for _, route := range lr.StaticRoutes {
+		row := &models.StaticRoute{
+			UUID:       uuid.New().String(),
+			RouterID:   lr.UUID,
+			IPPrefix:   route.IPPrefix,
+			Nexthop:    route.Nexthop,
+			OutputPort: route.OutputPort,
+			Policy:     route.Policy,
+		}
+		st.Routes[row.UUID] = row
+	}
+	return copyOf(lr)
+}
+
+func (s *SandboxOVNService) UpdateLogicalRouter(ctx context.Context, id string, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("logical router ID is required")
+	}
+
+	var updated *models.LogicalRouter
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.routerByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		st.updateRouter(ctx, existing, lr)
+		st.derive()
+		updated, err = st.routerModel(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (st *sandboxState) updateRouter(ctx context.Context, existing, updates *models.LogicalRouter) {
+	if updates.Name != "" {
+		existing.Name = updates.Name
+	}
+	if updates.Options != nil {
+		existing.Options = updates.Options
+	}
+	existing.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, updates.ExternalIDs)
+}
+
+func (s *SandboxOVNService) DeleteLogicalRouter(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("logical router ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, err := st.routerByID(id)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		// Check if router has ports, which are deleted with it on cascade
+		if len(existing.Ports) > 0 && !ovn.CascadeRequested(ctx) {
+			return fmt.Errorf("cannot delete router: router has %d ports attached", len(existing.Ports))
+		}
+		if ovn.CascadeRequested(ctx) {
+			st.cascadeRouter(existing)
+		}
+		st.deleteRouter(existing)
+		return nil
+	})
+}
+
+// deleteRouter deletes a router along with its ports, policies, static
+// routes and NAT rules
+func (st *sandboxState) deleteRouter(lr *models.LogicalRouter) {
+	for _, portID := range lr.Ports {
+		st.deleteRouterPortRow(portID)
+	}
+	for _, policyID := range lr.Policies {
+		delete(st.Policies, policyID)
+	}
+	for id, route := range st.Routes {
+		if route.RouterID == lr.UUID {
+			delete(st.Routes, id)
+		}
+	}
+	for id, nat := range st.NATs {
+		if nat.RouterID == lr.UUID {
+			delete(st.NATs, id)
+		}
+	}
+	delete(st.Routers, lr.UUID)
+}
+
+// cascadeRouter deletes the switch ports peered with a router's ports
+func (st *sandboxState) cascadeRouter(lr *models.LogicalRouter) {
+	names := make(map[string]bool, len(lr.Ports))
+	for _, portID := range lr.Ports {
+		if lrp, ok := st.RouterPorts[portID]; ok {
+			names[lrp.Name] = true
+		}
+	}
+	for _, port := range sortedValues(st.Ports, func(port *models.LogicalSwitchPort) string { return port.UUID }) {
+		if port.Type != "router" || !names[port.Options["router-port"]] {
+			continue
+		}
+		if ls, ok := st.Switches[port.SwitchID]; ok {
+			ls.Ports = removeID(ls.Ports, port.UUID)
+		}
+		st.deletePortRow(port.UUID)
+	}
+}
+
+// Router Policy operations
+
+// routerPolicies returns the policies of a router, highest priority first
+func (st *sandboxState) routerPolicies(lr *models.LogicalRouter) []*models.RouterPolicy {
+	policies := make([]*models.RouterPolicy, 0, len(lr.Policies))
+	for _, policyID := range lr.Policies {
+		if policy, ok := st.Policies[policyID]; ok {
+			policies = append(policies, policy)
+		}
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Priority != policies[j].Priority {
+			return policies[i].Priority > policies[j].Priority
+		}
+		return policies[i].Match < policies[j].Match
+	})
+	return policies
+}
+
+func (s *SandboxOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	var policies []*models.RouterPolicy
+	err := s.view(func(st *sandboxState) error {
+		lr, err := st.routerByID(routerID)
+		if err != nil {
+			return err
+		}
+		policies, err = copyOf(st.routerPolicies(lr))
+		return err
+	})
+	return policies, err
+}
+
+func (s *SandboxOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("router policy ID is required")
+	}
+
+	var policy *models.RouterPolicy
+	err := s.view(func(st *sandboxState) error {
+		existing, ok := st.Policies[id]
+		if !ok {
+			return fmt.Errorf("router policy %s not found", id)
+		}
+		var err error
+		policy, err = copyOf(existing)
+		return err
+	})
+	return policy, err
+}
+
+func (s *SandboxOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	var created *models.RouterPolicy
+	err := s.update(ctx, func(st *sandboxState) error {
+		lr, err := st.routerByID(routerID)
+		if err != nil {
+			return err
+		}
+		if err := ovn.ValidateRouterPolicy(policy); err != nil {
+			return err
+		}
+		if err := st.checkPolicyUnique(lr, "", policy); err != nil {
+			return err
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		externalIDs := make(map[string]string, len(policy.ExternalIDs)+2)
+		for k, v := range policy.ExternalIDs {
+			externalIDs[k] = v
+		}
+		externalIDs["created_at"] = now
+		externalIDs["updated_at"] = now
+
+		row := &models.RouterPolicy{
+			UUID:        uuid.New().String(),
+			RouterID:    lr.UUID,
+			Priority:    policy.Priority,
+			Match:       policy.Match,
+			Action:      policy.Action,
+			Nexthops:    policy.Nexthops,
+			Options:     policy.Options,
+			ExternalIDs: externalIDs,
+		}
+		row.CreatedAt, row.UpdatedAt = sandboxTimestamps(externalIDs)
+		st.Policies[row.UUID] = row
+		lr.Policies = append(lr.Policies, row.UUID)
+		created, err = copyOf(row)
+		return err
+	})
+	return created, err
+}
+
+// checkPolicyUnique refuses a policy with the priority and match of another
+// of the router's, which OVN couldn't tell apart
+func (st *sandboxState) checkPolicyUnique(lr *models.LogicalRouter, exclude string, policy *models.RouterPolicy) error {
+	for _, other := range st.routerPolicies(lr) {
+		if other.UUID != exclude && other.Priority == policy.Priority && other.Match == policy.Match {
+			return fmt.Errorf("router policy with priority %d and match %q already exists", policy.Priority, policy.Match)
+		}
+	}
+	return nil
+}
+
+func (s *SandboxOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("router policy ID is required")
+	}
+
+	var updated *models.RouterPolicy
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Policies[id]
+		if !ok {
+			return fmt.Errorf("router policy %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+
+		merged := *existing
+		if policy.Priority > 0 {
+			merged.Priority = policy.Priority
+		}
+		if policy.Match != "" {
+			merged.Match = policy.Match
+		}
+		if policy.Action != "" {
+			merged.Action = policy.Action
+		}
+		if policy.Nexthops != nil {
+			merged.Nexthops = policy.Nexthops
+		} else if merged.Action != "reroute" {
+			merged.Nexthops = nil
+		}
+		if policy.Options != nil {
+			merged.Options = policy.Options
+		}
+		if err := ovn.ValidateRouterPolicy(&merged); err != nil {
+			return err
+		}
+		if merged.Priority != existing.Priority || merged.Match != existing.Match {
+			lr, err := st.routerByID(existing.RouterID)
+			if err != nil {
+				return err
+			}
+			if err := st.checkPolicyUnique(lr, id, &merged); err != nil {
+				return err
+			}
+		}
+		merged.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, policy.ExternalIDs)
+		*existing = merged
+		st.derive()
+
+		var err error
+		updated, err = copyOf(existing)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SandboxOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("router policy ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Policies[id]
+		if !ok {
+			return fmt.Errorf("router policy %s not found", id)
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, existing); err != nil {
+			return err
+		}
+		lr, ok := st.Routers[existing.RouterID]
+		if !ok {
+			return fmt.Errorf("router policy %s is not attached to any router", id)
+		}
+		lr.Policies = removeID(lr.Policies, id)
+		delete(st.Policies, id)
+		return nil
+	})
+}
+
+// Static Route operations
+
+// routerRoutes returns the static routes of a router, ordered by route
+// table, prefix and nexthop
+func (st *sandboxState) routerRoutes(routerID string) []*models.StaticRoute {
+	var routes []*models.StaticRoute
+	for _, route := range st.Routes {
+		if route.RouterID == routerID {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.RouteTable != b.RouteTable {
+			return a.RouteTable < b.RouteTable
+		}
+		if a.IPPrefix != b.IPPrefix {
+			return a.IPPrefix < b.IPPrefix
+		}
+		if a.Nexthop != b.Nexthop {
+			return a.Nexthop < b.Nexthop
+		}
+		return a.UUID < b.UUID
+	})
+	return routes
+}
+
+// routeModel returns a copy of a static route with its BFD and ECMP
+// settings set, as the client converts routes
+func routeModel(route *models.StaticRoute) (*models.StaticRoute, error) {
+	result, err := copyOf(route)
+	if err != nil {
+		return nil, err
+	}
+	bfd := result.BFD != nil && *result.BFD
+	symmetric := result.ECMPSymmetricReply != nil && *result.ECMPSymmetricReply
+	result.BFD = &bfd
+	result.ECMPSymmetricReply = &symmetric
+	return result, nil
+}
+
+// staticRouteKey identifies the routes OVN can't tell apart: the same
+// prefix and nexthop in the same route table with the same policy
+func staticRouteKey(route *models.StaticRoute) string {
+	policy := "dst-ip"
+	if route.Policy != nil && *route.Policy != "" {
+		policy = *route.Policy
+	}
+	return route.RouteTable + "|" + policy + "|" + ovn.NormalizeRoutePrefix(route.IPPrefix) + "|" + route.Nexthop
+}
+
+// newStaticRoute returns the route stored for route on a router, with its
+// prefix normalized and empty output port and policy unset
+func newStaticRoute(routerID string, route *models.StaticRoute, externalIDs map[string]string) *models.StaticRoute {
+	row := &models.StaticRoute{
+		UUID:               uuid.New().String(),
+		RouterID:           routerID,
+		IPPrefix:           ovn.NormalizeRoutePrefix(route.IPPrefix),
+		Nexthop:            route.Nexthop,
+		RouteTable:         route.RouteTable,
+		BFD:                route.BFD,
+		ECMPSymmetricReply: route.ECMPSymmetricReply,
+		ExternalIDs:        externalIDs,
+	}
+	if route.OutputPort != nil && *route.OutputPort != "" {
+		outputPort := *route.OutputPort
+		row.OutputPort = &outputPort
+	}
+	if route.Policy != nil && *route.Policy != "" {
+		policy := *route.Policy
+		row.Policy = &policy
+	}
+	return row
+}
+
+func (s *SandboxOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+
+	var routes []*models.StaticRoute
+	err := s.view(func(st *sandboxState) error {
+		lr, err := st.routerByID(routerID)
+		if err != nil {
+			return err
+		}
+		routes = make([]*models.StaticRoute, 0)
+		for _, route := range st.routerRoutes(lr.UUID) {
+			result, err := routeModel(route)
+			if err != nil {
+				return err
+			}
+			routes = append(routes, result)
+		}
+		return nil
+	})
+	return routes, err
+}
+
+func (s *SandboxOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("static route ID is required")
+	}
+
+	var route *models.StaticRoute
+	err := s.view(func(st *sandboxState) error {
+		existing, ok := st.Routes[id]
+		if !ok {
+			return fmt.Errorf("static route %s not found", id)
+		}
+		var err error
+		route, err = routeModel(existing)
+		return err
+	})
+	return route, err
+}
+
+// CreateStaticRoutes adds routes to a router at once, so either all of them
+// are created or none is
+func (s *SandboxOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("at least one static route is required")
+	}
+
+	var created []*models.StaticRoute
+	err := s.update(ctx, func(st *sandboxState) error {
+		lr, err := st.routerByID(routerID)
+		if err != nil {
+			return err
+		}
+		keys := make(map[string]bool)
+		for _, existing := range st.routerRoutes(lr.UUID) {
+			keys[staticRouteKey(existing)] = true
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		created = make([]*models.StaticRoute, 0, len(routes))
+		for i, route := range routes {
+			if err := ovn.ValidateStaticRoute(route); err != nil {
+				return fmt.Errorf("route %d: %w", i, err)
+			}
+
+			externalIDs := make(map[string]string, len(route.ExternalIDs)+2)
+			for k, v := range route.ExternalIDs {
+				externalIDs[k] = v
+			}
+			externalIDs["created_at"] = now
+			externalIDs["updated_at"] = now
+			row := newStaticRoute(lr.UUID, route, externalIDs)
+			key := staticRouteKey(row)
+			if keys[key] {
+				return fmt.Errorf("static route %s via %s already exists", row.IPPrefix, row.Nexthop)
+			}
+			keys[key] = true
+
+			st.Routes[row.UUID] = row
+			result, err := routeModel(row)
+			if err != nil {
+				return err
+			}
+			created = append(created, result)
+		}
+		return nil
+	})
+	return created, err
+}
+
+func (s *SandboxOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	// Validate input
+	if id == "" {
+		return nil, fmt.Errorf("static route ID is required")
+	}
+
+	var updated *models.StaticRoute
+	err := s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Routes[id]
+		if !ok {
+			return fmt.Errorf("static route %s not found", id)
+		}
+		current, err := routeModel(existing)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, current); err != nil {
+			return err
+		}
+
+		merged := *current
+		if route.IPPrefix != "" {
+			merged.IPPrefix = route.IPPrefix
+		}
+		if route.Nexthop != "" {
+			merged.Nexthop = route.Nexthop
+		}
+		if route.OutputPort != nil {
+			merged.OutputPort = route.OutputPort
+			if *route.OutputPort == "" {
+				merged.OutputPort = nil
+			}
+		}
+		if route.Policy != nil {
+			merged.Policy = route.Policy
+			if *route.Policy == "" {
+				merged.Policy = nil
+			}
+		}
+		if route.RouteTable != "" {
+			merged.RouteTable = route.RouteTable
+		}
+		if route.BFD != nil {
+			merged.BFD = route.BFD
+		}
+		if route.ECMPSymmetricReply != nil {
+			merged.ECMPSymmetricReply = route.ECMPSymmetricReply
+		}
+		merged.ExternalIDs = ovn.UpdatedExternalIDs(ctx, existing.ExternalIDs, route.ExternalIDs)
+		if err := ovn.ValidateStaticRoute(&merged); err != nil {
+			return err
+		}
+
+		row := newStaticRoute(existing.RouterID, &merged, merged.ExternalIDs)
+		row.UUID = id
+		for _, sibling := range st.routerRoutes(existing.RouterID) {
+			if sibling.UUID != id && staticRouteKey(sibling) == staticRouteKey(row) {
+				return fmt.Errorf("static route %s via %s already exists", row.IPPrefix, row.Nexthop)
+			}
+		}
+		st.Routes[id] = row
+		updated, err = routeModel(row)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SandboxOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("static route ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		existing, ok := st.Routes[id]
+		if !ok {
+			return fmt.Errorf("static route %s not found", id)
+		}
+		current, err := routeModel(existing)
+		if err != nil {
+			return err
+		}
+		// Refuse the change if a caller's precondition no longer holds
+		if err := ovn.CheckPrecondition(ctx, current); err != nil {
+			return err
+		}
+		delete(st.Routes, id)
+		return nil
+	})
+}
+
+// routerNATs returns the NAT rules of a router
+func (st *sandboxState) routerNATs(routerID string) []*sandboxNAT {
+	var nats []*sandboxNAT
+	for _, nat := range sortedValues(st.NATs, func(nat *sandboxNAT) string { return nat.ExternalIP }) {
+		if nat.RouterID == routerID {
+			nats = append(nats, nat)
+		}
+	}
+	return nats
+}
+
+// Router Port operations
+
+// routerPortByID returns a router port by UUID or name
+func (st *sandboxState) routerPortByID(id string) (*sandboxRouterPort, error) {
+	if lrp, ok := st.RouterPorts[id]; ok {
+		return lrp, nil
+	}
+	for _, lrp := range sortedValues(st.RouterPorts, func(lrp *sandboxRouterPort) string { return lrp.UUID }) {
+		if lrp.Name == id {
+			return lrp, nil
+		}
+	}
+	return nil, fmt.Errorf("logical router port %s not found", id)
+}
+
+func (s *SandboxOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	// Validate input
+	if routerID == "" {
+		return nil, fmt.Errorf("router ID is required")
+	}
+	if port.Name == "" || port.MAC == "" || len(port.Networks) == 0 {
+		return nil, fmt.Errorf("router port name, MAC and networks are required")
+	}
+
+	var created *models.LogicalRouterPort
+	err := s.update(ctx, func(st *sandboxState) error {
+		var err error
+		created, err = st.createRouterPort(ctx, routerID, port)
+		return err
+	})
+	return created, err
+}
+
+func (st *sandboxState) createRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	lr, ok := st.Routers[routerID]
+	if !ok {
+		return nil, fmt.Errorf("logical router %s not found", routerID)
+	}
+	for _, existing := range st.RouterPorts {
+		if existing.Name == port.Name {
+			return nil, fmt.Errorf("router port %s already exists", port.Name)
+		}
+	}
+
+	row := &sandboxRouterPort{
+		LogicalRouterPort: models.LogicalRouterPort{
+			UUID:        uuid.New().String(),
+			Name:        port.Name,
+			MAC:         port.MAC,
+			Networks:    port.Networks,
+			Enabled:     port.Enabled,
+			PeerPort:    port.PeerPort,
+			Options:     port.Options,
+			ExternalIDs: createdExternalIDs(ctx, port.ExternalIDs),
+		},
+		RouterID: lr.UUID,
+	}
+	row.CreatedAt, row.UpdatedAt = sandboxTimestamps(row.ExternalIDs)
+	st.RouterPorts[row.UUID] = row
+	lr.Ports = append(lr.Ports, row.UUID)
+	return copyOf(&row.LogicalRouterPort)
+}
+
+func (s *SandboxOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	// Validate input
+	if id == "" {
+		return fmt.Errorf("router port ID is required")
+	}
+
+	return s.update(ctx, func(st *sandboxState) error {
+		lrp, err := st.routerPortByID(id)
+		if err != nil {
+			return err
+		}
+		if lr, ok := st.Routers[lrp.RouterID]; ok {
+			lr.Ports = removeID(lr.Ports, lrp.UUID)
+		}
+		st.deleteRouterPortRow(lrp.UUID)
+		return nil
+	})
+}
+
+// deleteRouterPortRow deletes a router port, releasing its HA chassis group
+// if no other port uses it
+func (st *sandboxState) deleteRouterPortRow(id string) {
+	lrp, ok := st.RouterPorts[id]
+	if !ok {
+		return
+	}
+	delete(st.RouterPorts, id)
+	st.releaseHAChassisGroup(lrp.HAChassisGroup)
+}
+
+// releaseHAChassisGroup deletes an HA chassis group no router port uses
+func (st *sandboxState) releaseHAChassisGroup(name string) {
+	if name == "" {
+		return
+	}
+	for _, lrp := range st.RouterPorts {
+		if lrp.HAChassisGroup == name {
+			return
+		}
+	}
+	delete(st.HAChassisGroups, name)
+}
+
+// Gateway operations
+
+func (s *SandboxOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	var gateways []*models.Gateway
+	err := s.view(func(st *sandboxState) error {
+		gateways = st.gateways()
+		return nil
+	})
+	return gateways, err
+}
+
+// gateways returns the l3 gateway routers, pinned to a chassis, and the
+// distributed gateway ports bound to chassis, ordered by router and port
+func (st *sandboxState) gateways() []*models.Gateway {
+	var result []*models.Gateway
+	for _, lr := range st.Routers {
+		if chassis := lr.Options["chassis"]; chassis != "" {
+			gateway := &models.Gateway{
+				Type:           ovn.GatewayTypeL3Gateway,
+				RouterID:       lr.UUID,
+				RouterName:     lr.Name,
+				Chassis:        []models.GatewayChassis{{Name: chassis}},
+				HostingChassis: chassis,
+			}
+			for _, portID := range lr.Ports {
+				if lrp, ok := st.RouterPorts[portID]; ok {
+					gateway.Networks = append(gateway.Networks, lrp.Networks...)
+				}
+			}
+			result = append(result, gateway)
+			continue
+		}
+
+		for _, portID := range lr.Ports {
+			lrp, ok := st.RouterPorts[portID]
+			if !ok || (len(lrp.GatewayChassis) == 0 && lrp.HAChassisGroup == "") {
+				continue
+			}
+
+			gateway := &models.Gateway{
+				Type:       ovn.GatewayTypeDistributed,
+				RouterID:   lr.UUID,
+				RouterName: lr.Name,
+				PortID:     lrp.UUID,
+				PortName:   lrp.Name,
+				Networks:   append([]string(nil), lrp.Networks...),
+				Chassis:    append([]models.GatewayChassis{}, lrp.GatewayChassis...),
+			}
+			// An HA chassis group takes precedence over gateway chassis
+			if lrp.HAChassisGroup != "" {
+				gateway.HAChassisGroup = lrp.HAChassisGroup
+				gateway.Chassis = append([]models.GatewayChassis{}, st.HAChassisGroups[lrp.HAChassisGroup]...)
+			}
+			sort.SliceStable(gateway.Chassis, func(i, j int) bool {
+				return gateway.Chassis[i].Priority > gateway.Chassis[j].Priority
+			})
+			// Without ovn-northd, the highest priority chassis hosts the port
+			if len(gateway.Chassis) > 0 {
+				gateway.HostingChassis = gateway.Chassis[0].Name
+			}
+			result = append(result, gateway)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].RouterName != result[j].RouterName {
+			return result[i].RouterName < result[j].RouterName
+		}
+		return result[i].PortName < result[j].PortName
+	})
+	return result
+}
+
+func (s *SandboxOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	// Validate input
+	if portID == "" {
+		return nil, fmt.Errorf("router port ID is required")
+	}
+
+	var gateway *models.Gateway
+	err := s.update(ctx, func(st *sandboxState) error {
+		lrp, err := st.routerPortByID(portID)
+		if err != nil {
+			return err
+		}
+
+		previous := lrp.HAChassisGroup
+		if binding.HAChassisGroup != "" {
+			st.HAChassisGroups[binding.HAChassisGroup] = append([]models.GatewayChassis{}, binding.Chassis...)
+			lrp.HAChassisGroup = binding.HAChassisGroup
+			lrp.GatewayChassis = nil
+		} else {
+			lrp.HAChassisGroup = ""
+			lrp.GatewayChassis = append([]models.GatewayChassis{}, binding.Chassis...)
+		}
+		if previous != binding.HAChassisGroup {
+			st.releaseHAChassisGroup(previous)
+		}
+
+		for _, g := range st.gateways() {
+			if g.PortID == lrp.UUID {
+				gateway = g
+				return nil
+			}
+		}
+		return fmt.Errorf("gateway port %s not found", portID)
+	})
+	return gateway, err
+}
+
+func (s *SandboxOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	return s.update(ctx, func(st *sandboxState) error {
+		for portID, chassis := range priorities {
+			lrp, err := st.routerPortByID(portID)
+			if err != nil {
+				return err
+			}
+			bound := lrp.GatewayChassis
+			if lrp.HAChassisGroup != "" {
+				bound = st.HAChassisGroups[lrp.HAChassisGroup]
+			}
+			for _, ch := range chassis {
+				found := false
+				for i := range bound {
+					if bound[i].Name == ch.Name {
+						bound[i].Priority = ch.Priority
+						found = true
+					}
+				}
+				if !found {
+					return fmt.Errorf("chassis %s is not bound to gateway port %s", ch.Name, lrp.Name)
+				}
+			}
+		}
+		return nil
+	})
+}
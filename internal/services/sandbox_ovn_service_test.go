@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// memorySandboxStateStore keeps sandbox states in memory
+type memorySandboxStateStore struct {
+	states  map[string][]byte
+	saves   int
+	failing bool
+}
+
+func newMemorySandboxStateStore() *memorySandboxStateStore {
+	return &memorySandboxStateStore{states: make(map[string][]byte)}
+}
+
+func (s *memorySandboxStateStore) LoadSandboxState(ctx context.Context, name string) ([]byte, error) {
+	return s.states[name], nil
+}
+
+func (s *memorySandboxStateStore) SaveSandboxState(ctx context.Context, name string, state []byte) error {
+	if s.failing {
+		return errors.New("database is unavailable")
+	}
+	s.states[name] = state
+	s.saves++
+	return nil
+}
+
+func newTestSandbox(t *testing.T, store *memorySandboxStateStore, seed bool) *SandboxOVNService {
+	sandbox, err := NewSandboxOVNService(context.Background(), store, "sandbox", seed, zap.NewNop())
+	require.NoError(t, err)
+	return sandbox
+}
+
+func TestSandboxOVNService_Seed(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), true)
+
+	topology, err := sandbox.GetTopology(ctx)
+	require.NoError(t, err)
+	assert.Len(t, topology.Switches, 2)
+	assert.Len(t, topology.Routers, 1)
+	assert.Len(t, topology.Ports, 5)
+	assert.Len(t, topology.RouterPorts, 2)
+
+	web, err := sandbox.GetLogicalSwitch(ctx, "web-tier")
+	require.NoError(t, err)
+	acls, err := sandbox.ListACLs(ctx, web.UUID)
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	assert.Equal(t, "allow-http", acls[0].Name)
+
+	lbs, err := sandbox.ListLoadBalancers(ctx)
+	require.NoError(t, err)
+	require.Len(t, lbs, 1)
+	assert.Equal(t, "10.0.1.10:80,10.0.1.11:80", lbs[0].VIPs["10.0.0.100:80"])
+}
+
+func TestSandboxOVNService_PersistsAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	store := newMemorySandboxStateStore()
+	sandbox := newTestSandbox(t, store, false)
+
+	ls, err := sandbox.CreateLogicalSwitch(ctx, &models.LogicalSwitch{Name: "ls1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ls.UUID)
+	assert.False(t, ls.CreatedAt.IsZero())
+
+	port, err := sandbox.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{
+		Name:      "vm1",
+		Addresses: []string{"0a:00:00:00:00:01 10.0.0.10"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ls.UUID, port.SwitchID)
+
+	_, err = sandbox.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: "vm1"})
+	assert.Error(t, err)
+
+	// A new sandbox over the same store picks up where the last one stopped
+	restarted := newTestSandbox(t, store, true)
+	switches, err := restarted.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	require.Len(t, switches, 1)
+	assert.Equal(t, "ls1", switches[0].Name)
+	assert.Equal(t, []string{port.UUID}, switches[0].Ports)
+
+	got, err := restarted.GetPort(ctx, port.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, port.Addresses, got.Addresses)
+}
+
+func TestSandboxOVNService_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), false)
+
+	ls, err := sandbox.CreateLogicalSwitch(ctx, &models.LogicalSwitch{Name: "ls1"})
+	require.NoError(t, err)
+
+	got, err := sandbox.GetLogicalSwitch(ctx, ls.UUID)
+	require.NoError(t, err)
+	got.Name = "changed"
+
+	again, err := sandbox.GetLogicalSwitch(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, "ls1", again.Name)
+}
+
+func TestSandboxOVNService_FailedSaveLeavesStateUnchanged(t *testing.T) {
+	ctx := context.Background()
+	store := newMemorySandboxStateStore()
+	sandbox := newTestSandbox(t, store, false)
+
+	store.failing = true
+	_, err := sandbox.CreateLogicalSwitch(ctx, &models.LogicalSwitch{Name: "ls1"})
+	assert.Error(t, err)
+
+	switches, err := sandbox.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, switches)
+}
+
+func TestSandboxOVNService_DeleteRouter(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), true)
+
+	routers, err := sandbox.ListLogicalRouters(ctx)
+	require.NoError(t, err)
+	require.Len(t, routers, 1)
+
+	err = sandbox.DeleteLogicalRouter(ctx, routers[0].UUID)
+	assert.ErrorContains(t, err, "router has 2 ports attached")
+
+	// Cascading deletes the switch ports peered with the router
+	require.NoError(t, sandbox.DeleteLogicalRouter(ovn.WithCascade(ctx), routers[0].UUID))
+
+	topology, err := sandbox.GetTopology(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, topology.Routers)
+	assert.Empty(t, topology.RouterPorts)
+	assert.Len(t, topology.Ports, 3)
+	for _, port := range topology.Ports {
+		assert.NotEqual(t, "router", port.Type)
+	}
+}
+
+func TestSandboxOVNService_DeleteSwitchCascade(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), true)
+
+	web, err := sandbox.GetLogicalSwitch(ctx, "web-tier")
+	require.NoError(t, err)
+	require.NoError(t, sandbox.DeleteLogicalSwitch(ovn.WithCascade(ctx), web.UUID))
+
+	// The backends on the switch's ports went with it, and so did the VIP
+	lbs, err := sandbox.ListLoadBalancers(ctx)
+	require.NoError(t, err)
+	require.Len(t, lbs, 1)
+	assert.Empty(t, lbs[0].VIPs)
+
+	_, err = sandbox.GetACL(ctx, web.ACLs[0])
+	assert.Error(t, err)
+	for _, portID := range web.Ports {
+		_, err := sandbox.GetPort(ctx, portID)
+		assert.Error(t, err)
+	}
+}
+
+func TestSandboxOVNService_ListPortsPage(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), false)
+
+	ls, err := sandbox.CreateLogicalSwitch(ctx, &models.LogicalSwitch{Name: "ls1"})
+	require.NoError(t, err)
+	for _, name := range []string{"c", "a", "d", "b"} {
+		_, err := sandbox.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: name})
+		require.NoError(t, err)
+	}
+
+	ports, total, err := sandbox.ListPortsPage(ctx, ls.UUID, &models.ListOptions{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, ports, 2)
+	assert.Equal(t, "b", ports[0].Name)
+	assert.Equal(t, "c", ports[1].Name)
+
+	ports, _, err = sandbox.ListPortsPage(ctx, ls.UUID, &models.ListOptions{SortBy: "name", SortDesc: true, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+	assert.Equal(t, "d", ports[0].Name)
+
+	_, _, err = sandbox.ListPortsPage(ctx, ls.UUID, &models.ListOptions{SortBy: "bogus"})
+	assert.Error(t, err)
+}
+
+func TestSandboxOVNService_ExecuteTransaction(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), false)
+
+	ops := []TransactionOp{
+		{Operation: "create", ResourceType: "logical_switch", Ref: "ls", Data: &models.LogicalSwitch{Name: "ls1"}},
+		{Operation: "create", ResourceType: "port", SwitchID: "$ls", Ref: "p1", Data: &models.LogicalSwitchPort{Name: "vm1"}},
+		{Operation: "create", ResourceType: "port_group", Data: &models.PortGroup{Name: "pg1", Ports: []string{"$p1"}}},
+	}
+	require.NoError(t, sandbox.ExecuteTransaction(ctx, ops))
+	require.NotEmpty(t, ops[0].ResourceID)
+	require.NotEmpty(t, ops[1].ResourceID)
+
+	ls, err := sandbox.GetLogicalSwitch(ctx, ops[0].ResourceID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ops[1].ResourceID}, ls.Ports)
+
+	groups, err := sandbox.ListPortGroups(ctx)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{ops[1].ResourceID}, groups[0].Ports)
+}
+
+func TestSandboxOVNService_ExecuteTransactionRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := newMemorySandboxStateStore()
+	sandbox := newTestSandbox(t, store, false)
+	saves := store.saves
+
+	ops := []TransactionOp{
+		{Operation: "create", ResourceType: "logical_switch", Ref: "ls", Data: &models.LogicalSwitch{Name: "ls1"}},
+		{Operation: "create", ResourceType: "port", SwitchID: "$missing", Data: &models.LogicalSwitchPort{Name: "vm1"}},
+	}
+	err := sandbox.ExecuteTransaction(ctx, ops)
+	var txErr *ovn.TxError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, 1, txErr.Index)
+
+	switches, err := sandbox.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, switches)
+	assert.Equal(t, saves, store.saves)
+}
+
+func TestSandboxOVNService_SetGatewayBinding(t *testing.T) {
+	ctx := context.Background()
+	sandbox := newTestSandbox(t, newMemorySandboxStateStore(), false)
+
+	lr, err := sandbox.CreateLogicalRouter(ctx, &models.LogicalRouter{Name: "lr1"})
+	require.NoError(t, err)
+	lrp, err := sandbox.CreateRouterPort(ctx, lr.UUID, &models.LogicalRouterPort{
+		Name:     "lr1-public",
+		MAC:      "00:00:00:00:ff:01",
+		Networks: []string{"172.16.0.1/24"},
+	})
+	require.NoError(t, err)
+
+	gateway, err := sandbox.SetGatewayBinding(ctx, lrp.UUID, &models.GatewayBinding{
+		HAChassisGroup: "lr1-ha",
+		Chassis:        []models.GatewayChassis{{Name: "gw1", Priority: 10}, {Name: "gw2", Priority: 20}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gw2", gateway.HostingChassis)
+
+	require.NoError(t, sandbox.SetGatewayPriorities(ctx, map[string][]models.GatewayChassis{
+		lrp.UUID: {{Name: "gw1", Priority: 30}},
+	}))
+	gateways, err := sandbox.ListGateways(ctx)
+	require.NoError(t, err)
+	require.Len(t, gateways, 1)
+	assert.Equal(t, "gw1", gateways[0].HostingChassis)
+
+	err = sandbox.SetGatewayPriorities(ctx, map[string][]models.GatewayChassis{
+		lrp.UUID: {{Name: "gw3", Priority: 40}},
+	})
+	assert.ErrorContains(t, err, "not bound")
+}
@@ -0,0 +1,710 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// ExecuteTransaction applies ops as a whole: either all of them take effect
+// or none does. Creates set the ResourceID of their operation, and Data is
+// updated with the resulting resource. Failures are reported as an
+// *ovn.TxError naming the operation.
+func (s *SandboxOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("no operations provided")
+	}
+
+	txOps := make([]ovn.TxOp, len(ops))
+	for i, op := range ops {
+		txOp, err := toTxOp(op)
+		if err != nil {
+			return &ovn.TxError{Index: i, Err: err}
+		}
+		txOps[i] = txOp
+	}
+
+	err := s.update(ctx, func(st *sandboxState) error {
+		tx := &sandboxTransaction{ctx: ctx, st: st, refs: make(map[string]sandboxTxRef)}
+		for i := range txOps {
+			if err := tx.add(&txOps[i]); err != nil {
+				return &ovn.TxError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range ops {
+		ops[i].ResourceID = txOps[i].ResourceID
+		if ops[i].Data != nil {
+			ops[i].Data = txOps[i].Data
+		}
+	}
+	return nil
+}
+
+// sandboxTxRef is a resource created earlier in a transaction
+type sandboxTxRef struct {
+	uuid     string
+	resource string
+}
+
+// sandboxTransaction applies the operations of ExecuteTransaction to a
+// copy of the state, as the OVN client builds them into one OVSDB
+// transaction
+type sandboxTransaction struct {
+	ctx  context.Context
+	st   *sandboxState
+	refs map[string]sandboxTxRef
+}
+
+// add applies op, setting its ResourceID if it creates a resource
+func (tx *sandboxTransaction) add(op *ovn.TxOp) error {
+	switch op.Operation {
+	case models.OperationCreate:
+	case models.OperationUpdate, models.OperationDelete:
+		if op.ResourceID == "" {
+			return fmt.Errorf("resource ID is required for %s", op.Operation)
+		}
+		if strings.HasPrefix(op.ResourceID, "$") {
+			return fmt.Errorf("cannot %s %s, resources created in the same transaction can only be referenced as parents", op.Operation, op.ResourceID)
+		}
+	default:
+		return fmt.Errorf("unknown operation: %s", op.Operation)
+	}
+
+	if op.Data == nil && op.Operation != models.OperationDelete {
+		return fmt.Errorf("data is required for %s", op.Operation)
+	}
+
+	var id string
+	var err error
+	switch op.Resource {
+	case models.ResourceSwitch:
+		id, err = tx.logicalSwitch(op)
+	case models.ResourceRouter:
+		id, err = tx.logicalRouter(op)
+	case models.ResourcePort:
+		id, err = tx.port(op)
+	case models.ResourceACL:
+		id, err = tx.acl(op)
+	case models.ResourceLoadBalancer:
+		id, err = tx.loadBalancer(op)
+	case models.ResourceNAT:
+		id, err = tx.nat(op)
+	case models.ResourcePortGroup:
+		id, err = tx.portGroup(op)
+	case models.ResourceAddressSet:
+		id, err = tx.addressSet(op)
+	case models.ResourceDHCPOptions:
+		id, err = tx.dhcpOptions(op)
+	case models.ResourceQoS:
+		id, err = tx.qos(op)
+	default:
+		return fmt.Errorf("unknown resource type: %s", op.Resource)
+	}
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		op.ResourceID = id
+		if op.Ref != "" {
+			tx.refs[op.Ref] = sandboxTxRef{uuid: id, resource: op.Resource}
+		}
+	}
+	return nil
+}
+
+// parent resolves the switch or router a resource is created on: either
+// the UUID of an existing one, or "$<ref>" of a create earlier in the
+// transaction
+func (tx *sandboxTransaction) parent(id, resource string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("%s ID is required", resource)
+	}
+	if strings.HasPrefix(id, "$") {
+		ref, ok := tx.refs[id[1:]]
+		if !ok || ref.resource != resource {
+			return "", fmt.Errorf("%s does not refer to a %s created earlier in the transaction", id, resource)
+		}
+		return ref.uuid, nil
+	}
+	exists := false
+	switch resource {
+	case models.ResourceSwitch:
+		_, exists = tx.st.Switches[id]
+	case models.ResourceRouter:
+		_, exists = tx.st.Routers[id]
+	}
+	if !exists {
+		return "", fmt.Errorf("%s %s not found", resource, id)
+	}
+	return id, nil
+}
+
+func (tx *sandboxTransaction) logicalSwitch(op *ovn.TxOp) (string, error) {
+	ls, ok := op.Data.(*models.LogicalSwitch)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid switch data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if ls.Name == "" {
+			return "", fmt.Errorf("logical switch name is required")
+		}
+		ls.UUID = ""
+		created, err := tx.st.createSwitch(tx.ctx, ls)
+		if err != nil {
+			return "", err
+		}
+		return created.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.Switches[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("logical switch %s not found", op.ResourceID)
+		}
+		tx.st.updateSwitch(tx.ctx, existing, ls)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*ls = *updated
+		return "", nil
+
+	default:
+		existing, ok := tx.st.Switches[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("logical switch %s not found", op.ResourceID)
+		}
+		tx.st.deleteSwitch(existing)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) logicalRouter(op *ovn.TxOp) (string, error) {
+	lr, ok := op.Data.(*models.LogicalRouter)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid router data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if lr.Name == "" {
+			return "", fmt.Errorf("logical router name is required")
+		}
+		lr.UUID = ""
+		created, err := tx.st.createRouter(tx.ctx, lr)
+		if err != nil {
+			return "", err
+		}
+		return created.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.Routers[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("logical router %s not found", op.ResourceID)
+		}
+		tx.st.updateRouter(tx.ctx, existing, lr)
+		tx.st.derive()
+		updated, err := tx.st.routerModel(existing)
+		if err != nil {
+			return "", err
+		}
+		*lr = *updated
+		return "", nil
+
+	default:
+		existing, ok := tx.st.Routers[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("logical router %s not found", op.ResourceID)
+		}
+		if len(existing.Ports) > 0 {
+			return "", fmt.Errorf("cannot delete router: router has %d ports attached", len(existing.Ports))
+		}
+		tx.st.deleteRouter(existing)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) port(op *ovn.TxOp) (string, error) {
+	port, ok := op.Data.(*models.LogicalSwitchPort)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid port data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch)
+		if err != nil {
+			return "", err
+		}
+		if port.Name == "" {
+			return "", fmt.Errorf("port name is required")
+		}
+		created, err := tx.st.createPort(tx.ctx, switchID, port)
+		if err != nil {
+			return "", err
+		}
+		return created.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.Ports[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("logical switch port %s not found", op.ResourceID)
+		}
+		tx.st.updatePort(tx.ctx, existing, port)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*port = *updated
+		return "", nil
+
+	default:
+		if _, ok := tx.st.Ports[op.ResourceID]; !ok {
+			return "", fmt.Errorf("logical switch port %s not found", op.ResourceID)
+		}
+		for _, ls := range tx.st.Switches {
+			ls.Ports = removeID(ls.Ports, op.ResourceID)
+		}
+		tx.st.deletePortRow(op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) acl(op *ovn.TxOp) (string, error) {
+	acl, ok := op.Data.(*models.ACL)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid ACL data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch)
+		if err != nil {
+			return "", err
+		}
+		if err := ovn.ValidateACL(acl); err != nil {
+			return "", err
+		}
+		created, err := tx.st.createACL(tx.ctx, acl)
+		if err != nil {
+			return "", err
+		}
+		ls := tx.st.Switches[switchID]
+		ls.ACLs = append(ls.ACLs, created.UUID)
+		return created.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.ACLs[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("ACL %s not found", op.ResourceID)
+		}
+		tx.st.updateACL(tx.ctx, existing, acl)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*acl = *updated
+		return "", nil
+
+	default:
+		if _, ok := tx.st.ACLs[op.ResourceID]; !ok {
+			return "", fmt.Errorf("ACL %s not found", op.ResourceID)
+		}
+		// ACLs are applied to switches or to port groups
+		for _, ls := range tx.st.Switches {
+			ls.ACLs = removeID(ls.ACLs, op.ResourceID)
+		}
+		for _, pg := range tx.st.PortGroups {
+			pg.ACLs = removeID(pg.ACLs, op.ResourceID)
+		}
+		tx.st.deleteACLRow(op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) loadBalancer(op *ovn.TxOp) (string, error) {
+	lb, ok := op.Data.(*models.LoadBalancer)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid load balancer data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if lb.Name == "" {
+			return "", fmt.Errorf("load balancer name is required")
+		}
+		lb.UUID = ""
+		created, err := tx.st.createLoadBalancer(tx.ctx, lb)
+		if err != nil {
+			return "", err
+		}
+		return created.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.LoadBalancers[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("load balancer %s not found", op.ResourceID)
+		}
+		tx.st.updateLoadBalancer(existing, lb)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*lb = *updated
+		return "", nil
+
+	default:
+		if _, ok := tx.st.LoadBalancers[op.ResourceID]; !ok {
+			return "", fmt.Errorf("load balancer %s not found", op.ResourceID)
+		}
+		tx.st.deleteLoadBalancer(op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) nat(op *ovn.TxOp) (string, error) {
+	nat, ok := op.Data.(*models.NAT)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid NAT data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		routerID, err := tx.parent(op.RouterID, models.ResourceRouter)
+		if err != nil {
+			return "", err
+		}
+		if err := ovn.ValidateNAT(nat); err != nil {
+			return "", err
+		}
+		nat.UUID = uuid.New().String()
+		nat.ExternalIDs = createdExternalIDs(tx.ctx, nat.ExternalIDs)
+		row, err := copyOf(*nat)
+		if err != nil {
+			return "", err
+		}
+		tx.st.NATs[nat.UUID] = &sandboxNAT{NAT: row, RouterID: routerID}
+		return nat.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.NATs[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("NAT rule %s not found", op.ResourceID)
+		}
+		merged := existing.NAT
+		if nat.Type != "" {
+			merged.Type = nat.Type
+		}
+		if nat.ExternalIP != "" {
+			merged.ExternalIP = nat.ExternalIP
+		}
+		if nat.LogicalIP != "" {
+			merged.LogicalIP = nat.LogicalIP
+		}
+		if nat.ExternalMAC != nil {
+			merged.ExternalMAC = nat.ExternalMAC
+		}
+		if nat.LogicalPort != nil {
+			merged.LogicalPort = nat.LogicalPort
+		}
+		merged.ExternalIDs = ovn.UpdatedExternalIDs(tx.ctx, existing.ExternalIDs, nat.ExternalIDs)
+		if err := ovn.ValidateNAT(&merged); err != nil {
+			return "", err
+		}
+		existing.NAT = merged
+		*nat = merged
+		return "", nil
+
+	default:
+		if _, ok := tx.st.NATs[op.ResourceID]; !ok {
+			return "", fmt.Errorf("NAT rule %s not found", op.ResourceID)
+		}
+		delete(tx.st.NATs, op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) portGroup(op *ovn.TxOp) (string, error) {
+	pg, ok := op.Data.(*models.PortGroup)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid port group data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if pg.Name == "" {
+			return "", fmt.Errorf("port group name is required")
+		}
+		ports, err := tx.portRefs(pg.Ports)
+		if err != nil {
+			return "", err
+		}
+		pg.UUID = uuid.New().String()
+		pg.Ports = ports
+		pg.ACLs = nil
+		pg.ExternalIDs = createdExternalIDs(tx.ctx, pg.ExternalIDs)
+		pg.CreatedAt, pg.UpdatedAt = sandboxTimestamps(pg.ExternalIDs)
+		row, err := copyOf(pg)
+		if err != nil {
+			return "", err
+		}
+		tx.st.PortGroups[pg.UUID] = row
+		return pg.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.PortGroups[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("port group %s not found", op.ResourceID)
+		}
+		if pg.Name != "" {
+			existing.Name = pg.Name
+		}
+		if pg.Ports != nil {
+			ports, err := tx.portRefs(pg.Ports)
+			if err != nil {
+				return "", err
+			}
+			existing.Ports = ports
+		}
+		existing.ExternalIDs = ovn.UpdatedExternalIDs(tx.ctx, existing.ExternalIDs, pg.ExternalIDs)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*pg = *updated
+		return "", nil
+
+	default:
+		existing, ok := tx.st.PortGroups[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("port group %s not found", op.ResourceID)
+		}
+		for _, aclID := range existing.ACLs {
+			tx.st.deleteACLRow(aclID)
+		}
+		delete(tx.st.PortGroups, op.ResourceID)
+		return "", nil
+	}
+}
+
+// portRefs resolves the ports of a port group, which may refer to ports
+// created earlier in the transaction
+func (tx *sandboxTransaction) portRefs(ports []string) ([]string, error) {
+	resolved := make([]string, len(ports))
+	for i, id := range ports {
+		if !strings.HasPrefix(id, "$") {
+			resolved[i] = id
+			continue
+		}
+		ref, ok := tx.refs[id[1:]]
+		if !ok || ref.resource != models.ResourcePort {
+			return nil, fmt.Errorf("%s does not refer to a port created earlier in the transaction", id)
+		}
+		resolved[i] = ref.uuid
+	}
+	return resolved, nil
+}
+
+func (tx *sandboxTransaction) addressSet(op *ovn.TxOp) (string, error) {
+	as, ok := op.Data.(*models.AddressSet)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid address set data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if as.Name == "" {
+			return "", fmt.Errorf("address set name is required")
+		}
+		as.UUID = uuid.New().String()
+		as.ExternalIDs = createdExternalIDs(tx.ctx, as.ExternalIDs)
+		as.CreatedAt, as.UpdatedAt = sandboxTimestamps(as.ExternalIDs)
+		row, err := copyOf(as)
+		if err != nil {
+			return "", err
+		}
+		tx.st.AddressSets[as.UUID] = row
+		return as.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.AddressSets[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("address set %s not found", op.ResourceID)
+		}
+		if as.Name != "" {
+			existing.Name = as.Name
+		}
+		if as.Addresses != nil {
+			existing.Addresses = as.Addresses
+		}
+		existing.ExternalIDs = ovn.UpdatedExternalIDs(tx.ctx, existing.ExternalIDs, as.ExternalIDs)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*as = *updated
+		return "", nil
+
+	default:
+		if _, ok := tx.st.AddressSets[op.ResourceID]; !ok {
+			return "", fmt.Errorf("address set %s not found", op.ResourceID)
+		}
+		delete(tx.st.AddressSets, op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) dhcpOptions(op *ovn.TxOp) (string, error) {
+	dhcp, ok := op.Data.(*models.DHCPOptions)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid DHCP options data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if _, _, err := net.ParseCIDR(dhcp.CIDR); err != nil {
+			return "", fmt.Errorf("invalid cidr: %s", dhcp.CIDR)
+		}
+		dhcp.UUID = uuid.New().String()
+		dhcp.ExternalIDs = createdExternalIDs(tx.ctx, dhcp.ExternalIDs)
+		dhcp.CreatedAt, dhcp.UpdatedAt = sandboxTimestamps(dhcp.ExternalIDs)
+		row, err := copyOf(dhcp)
+		if err != nil {
+			return "", err
+		}
+		tx.st.DHCPOptions[dhcp.UUID] = row
+		return dhcp.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.DHCPOptions[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("DHCP options %s not found", op.ResourceID)
+		}
+		if dhcp.CIDR != "" {
+			if _, _, err := net.ParseCIDR(dhcp.CIDR); err != nil {
+				return "", fmt.Errorf("invalid cidr: %s", dhcp.CIDR)
+			}
+			existing.CIDR = dhcp.CIDR
+		}
+		if dhcp.Options != nil {
+			existing.Options = dhcp.Options
+		}
+		existing.ExternalIDs = ovn.UpdatedExternalIDs(tx.ctx, existing.ExternalIDs, dhcp.ExternalIDs)
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*dhcp = *updated
+		return "", nil
+
+	default:
+		// Ports reference DHCP options weakly, so OVSDB clears them
+		if _, ok := tx.st.DHCPOptions[op.ResourceID]; !ok {
+			return "", fmt.Errorf("DHCP options %s not found", op.ResourceID)
+		}
+		for _, port := range tx.st.Ports {
+			if port.DHCPv4Options != nil && *port.DHCPv4Options == op.ResourceID {
+				port.DHCPv4Options = nil
+			}
+			if port.DHCPv6Options != nil && *port.DHCPv6Options == op.ResourceID {
+				port.DHCPv6Options = nil
+			}
+		}
+		delete(tx.st.DHCPOptions, op.ResourceID)
+		return "", nil
+	}
+}
+
+func (tx *sandboxTransaction) qos(op *ovn.TxOp) (string, error) {
+	qos, ok := op.Data.(*models.QoS)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid QoS data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch)
+		if err != nil {
+			return "", err
+		}
+		if err := ovn.ValidateQoS(qos); err != nil {
+			return "", err
+		}
+		qos.UUID = uuid.New().String()
+		qos.ExternalIDs = createdExternalIDs(tx.ctx, qos.ExternalIDs)
+		qos.CreatedAt, qos.UpdatedAt = sandboxTimestamps(qos.ExternalIDs)
+		row, err := copyOf(qos)
+		if err != nil {
+			return "", err
+		}
+		tx.st.QoS[qos.UUID] = row
+		ls := tx.st.Switches[switchID]
+		ls.QoSRules = append(ls.QoSRules, qos.UUID)
+		return qos.UUID, nil
+
+	case models.OperationUpdate:
+		existing, ok := tx.st.QoS[op.ResourceID]
+		if !ok {
+			return "", fmt.Errorf("QoS rule %s not found", op.ResourceID)
+		}
+		merged := *existing
+		if qos.Priority > 0 {
+			merged.Priority = qos.Priority
+		}
+		if qos.Direction != "" {
+			merged.Direction = qos.Direction
+		}
+		if qos.Match != "" {
+			merged.Match = qos.Match
+		}
+		if qos.Action != nil {
+			merged.Action = qos.Action
+		}
+		if qos.Bandwidth != nil {
+			merged.Bandwidth = qos.Bandwidth
+		}
+		if err := ovn.ValidateQoS(&merged); err != nil {
+			return "", err
+		}
+		merged.ExternalIDs = ovn.UpdatedExternalIDs(tx.ctx, existing.ExternalIDs, qos.ExternalIDs)
+		*existing = merged
+		tx.st.derive()
+		updated, err := copyOf(existing)
+		if err != nil {
+			return "", err
+		}
+		*qos = *updated
+		return "", nil
+
+	default:
+		if _, ok := tx.st.QoS[op.ResourceID]; !ok {
+			return "", fmt.Errorf("QoS rule %s not found", op.ResourceID)
+		}
+		for _, ls := range tx.st.Switches {
+			ls.QoSRules = removeID(ls.QoSRules, op.ResourceID)
+		}
+		delete(tx.st.QoS, op.ResourceID)
+		return "", nil
+	}
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Security posture checks
+const (
+	SecurityMissingDefaultDeny = "missing_default_deny"
+	SecurityWideOpenAllow      = "wide_open_allow"
+	SecurityExposedManagement  = "exposed_management_port"
+	SecurityUnloggedDeny       = "unlogged_deny"
+)
+
+// Severities of security findings, and the points each takes off a score
+// of 100
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+var severityPenalty = map[string]int{
+	SeverityHigh:   25,
+	SeverityMedium: 10,
+	SeverityLow:    3,
+}
+
+// securityRecommendations says how to fix the findings of each check
+var securityRecommendations = map[string]string{
+	SecurityMissingDefaultDeny: "Add a lowest-priority drop ACL matching \"ip\" in each direction, then allow the traffic workloads need above it",
+	SecurityWideOpenAllow:      "Restrict the ACL to the source addresses, e.g. an address set, and destination ports that need access",
+	SecurityExposedManagement:  "Don't expose cleartext management protocols to any source; use their encrypted alternatives (SSH, SFTP, SNMPv3, VNC over TLS) or restrict the source to a management network",
+	SecurityUnloggedDeny:       "Enable logging on drop and reject ACLs, with a meter to rate limit it, so that denied traffic can be audited at /acls/{id}/logs",
+}
+
+// cleartextManagementPorts are the ports of management protocols that
+// don't encrypt credentials, by transport protocol
+var cleartextManagementPorts = map[string]map[int]string{
+	"tcp": {
+		21:   "FTP",
+		23:   "Telnet",
+		512:  "rexec",
+		513:  "rlogin",
+		514:  "rsh",
+		2375: "Docker API",
+		5900: "VNC",
+	},
+	"udp": {
+		69:  "TFTP",
+		161: "SNMP",
+		623: "IPMI",
+	},
+}
+
+var (
+	// portEquals matches a destination port or set of ports, e.g.
+	// tcp.dst == 23 or tcp.dst == {22, 23}
+	portEquals = regexp.MustCompile(`(tcp|udp)\.dst\s*==\s*(\{[^}]*\}|\d+)`)
+	// portRange matches a range of destination ports, e.g.
+	// tcp.dst >= 20 && tcp.dst <= 25
+	portRange = regexp.MustCompile(`(tcp|udp)\.dst\s*>=\s*(\d+)\s*&&\s*(?:tcp|udp)\.dst\s*<=\s*(\d+)`)
+	// portGroupScope matches the port group conjunct of a port group ACL,
+	// e.g. outport == @web &&
+	portGroupScope = regexp.MustCompile(`(?:^|&&)\s*(?:inport|outport)\s*==\s*@[A-Za-z0-9_.]+\s*(?:&&|$)`)
+)
+
+// SecurityFinding is a weakness in the security posture of a switch
+type SecurityFinding struct {
+	Check          string `json:"check"`
+	Severity       string `json:"severity"`
+	Resource       string `json:"resource"` // switch or acl
+	UUID           string `json:"uuid"`
+	Name           string `json:"name,omitempty"`
+	Reason         string `json:"reason"`
+	Recommendation string `json:"recommendation"`
+}
+
+// SwitchSecurity is the security posture of a switch: a score out of 100
+// and the findings that took points off it
+type SwitchSecurity struct {
+	SwitchID   string            `json:"switch_id"`
+	SwitchName string            `json:"switch_name"`
+	TenantID   string            `json:"tenant_id,omitempty"`
+	Score      int               `json:"score"`
+	Findings   []SecurityFinding `json:"findings"`
+}
+
+// TenantSecurity is the security posture of a tenant, the average score of
+// its switches
+type TenantSecurity struct {
+	TenantID string `json:"tenant_id"`
+	Score    int    `json:"score"`
+	Switches int    `json:"switches"`
+	Findings int    `json:"findings"`
+}
+
+// SecurityReport scores the security posture of every switch, and of the
+// tenants owning them
+type SecurityReport struct {
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Score           int              `json:"score"` // Average of the switches
+	Switches        []SwitchSecurity `json:"switches"`
+	Tenants         []TenantSecurity `json:"tenants"`
+	Summary         map[string]int   `json:"summary"` // Findings by check
+	Recommendations []string         `json:"recommendations"`
+}
+
+// SecurityReporter scores switches on their ACLs: whether traffic is denied
+// by default, allowed from anywhere, allowed to cleartext management ports
+// from anywhere, and denied without being logged
+type SecurityReporter struct {
+	ovn OVNServiceInterface
+	now func() time.Time
+}
+
+// NewSecurityReporter creates a reporter
+func NewSecurityReporter(ovn OVNServiceInterface) *SecurityReporter {
+	return &SecurityReporter{
+		ovn: ovn,
+		now: time.Now,
+	}
+}
+
+// Report scores every switch, including the ACLs of the port groups its
+// ports are in
+func (r *SecurityReporter) Report(ctx context.Context) (*SecurityReport, error) {
+	switches, err := r.ovn.ListLogicalSwitches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list switches: %w", err)
+	}
+	portGroups, err := r.ovn.ListPortGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+	portGroupACLs := make(map[string][]*models.ACL, len(portGroups))
+	for _, pg := range portGroups {
+		acls, err := r.ovn.ListPortGroupACLs(ctx, pg.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of port group %s: %w", pg.UUID, err)
+		}
+		portGroupACLs[pg.UUID] = acls
+	}
+
+	report := &SecurityReport{
+		GeneratedAt:     r.now().UTC(),
+		Score:           100,
+		Switches:        []SwitchSecurity{},
+		Tenants:         []TenantSecurity{},
+		Summary:         map[string]int{},
+		Recommendations: []string{},
+	}
+	tenants := make(map[string]*TenantSecurity)
+	total := 0
+	for _, sw := range switches {
+		acls, err := r.ovn.ListACLs(ctx, sw.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs of switch %s: %w", sw.UUID, err)
+		}
+		result := scoreSwitch(sw, acls, portGroups, portGroupACLs)
+		report.Switches = append(report.Switches, result)
+		total += result.Score
+		for _, finding := range result.Findings {
+			report.Summary[finding.Check]++
+		}
+
+		if result.TenantID == "" {
+			continue
+		}
+		tenant, ok := tenants[result.TenantID]
+		if !ok {
+			tenant = &TenantSecurity{TenantID: result.TenantID}
+			tenants[result.TenantID] = tenant
+		}
+		// Summed here, averaged below
+		tenant.Score += result.Score
+		tenant.Switches++
+		tenant.Findings += len(result.Findings)
+	}
+	if len(report.Switches) > 0 {
+		report.Score = total / len(report.Switches)
+	}
+
+	for _, tenant := range tenants {
+		tenant.Score /= tenant.Switches
+		report.Tenants = append(report.Tenants, *tenant)
+	}
+	// Worst first
+	sort.SliceStable(report.Switches, func(i, j int) bool { return report.Switches[i].Score < report.Switches[j].Score })
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		if report.Tenants[i].Score != report.Tenants[j].Score {
+			return report.Tenants[i].Score < report.Tenants[j].Score
+		}
+		return report.Tenants[i].TenantID < report.Tenants[j].TenantID
+	})
+
+	for _, check := range []string{SecurityMissingDefaultDeny, SecurityExposedManagement, SecurityWideOpenAllow, SecurityUnloggedDeny} {
+		if report.Summary[check] > 0 {
+			report.Recommendations = append(report.Recommendations, securityRecommendations[check])
+		}
+	}
+	return report, nil
+}
+
+// scoreSwitch checks the ACLs of a switch and of the port groups its ports
+// are in
+func scoreSwitch(sw *models.LogicalSwitch, acls []*models.ACL, portGroups []*models.PortGroup, portGroupACLs map[string][]*models.ACL) SwitchSecurity {
+	result := SwitchSecurity{
+		SwitchID:   sw.UUID,
+		SwitchName: sw.Name,
+		TenantID:   sw.ExternalIDs["tenant_id"],
+		Score:      100,
+		Findings:   []SecurityFinding{},
+	}
+	add := func(finding SecurityFinding) {
+		finding.Recommendation = securityRecommendations[finding.Check]
+		result.Findings = append(result.Findings, finding)
+		result.Score -= severityPenalty[finding.Severity]
+	}
+
+	applied, denied := switchACLs(sw, acls, portGroups, portGroupACLs)
+	for _, direction := range []string{"to-lport", "from-lport"} {
+		if denied[direction] {
+			continue
+		}
+		finding := SecurityFinding{
+			Check:    SecurityMissingDefaultDeny,
+			Severity: SeverityHigh,
+			Resource: models.ResourceSwitch,
+			UUID:     sw.UUID,
+			Name:     sw.Name,
+			Reason:   "traffic to its ports is allowed unless an ACL drops it",
+		}
+		if direction == "from-lport" {
+			finding.Severity = SeverityMedium
+			finding.Reason = "traffic from its ports is allowed unless an ACL drops it"
+		}
+		add(finding)
+	}
+
+	for _, acl := range applied {
+		for _, finding := range checkACL(acl) {
+			add(finding)
+		}
+	}
+
+	if result.Score < 0 {
+		result.Score = 0
+	}
+	return result
+}
+
+// switchACLs returns the ACLs applied to a switch, its own and those of the
+// port groups its ports are in, and the directions in which they deny
+// traffic by default: with an ACL of the switch, or ACLs of port groups
+// covering all of its ports
+func switchACLs(sw *models.LogicalSwitch, acls []*models.ACL, portGroups []*models.PortGroup, portGroupACLs map[string][]*models.ACL) ([]*models.ACL, map[string]bool) {
+	onSwitch := make(map[string]bool, len(sw.Ports))
+	for _, port := range sw.Ports {
+		onSwitch[port] = true
+	}
+	// Ports covered by a port group default deny, by direction
+	deniedPorts := map[string]map[string]bool{"to-lport": {}, "from-lport": {}}
+	seen := make(map[string]bool)
+	applied := append([]*models.ACL{}, acls...)
+	for _, acl := range acls {
+		seen[acl.UUID] = true
+	}
+	for _, pg := range portGroups {
+		var members []string
+		for _, port := range pg.Ports {
+			if onSwitch[port] {
+				members = append(members, port)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		for _, acl := range portGroupACLs[pg.UUID] {
+			if isDefaultDeny(acl) && deniedPorts[acl.Direction] != nil {
+				for _, port := range members {
+					deniedPorts[acl.Direction][port] = true
+				}
+			}
+			if !seen[acl.UUID] {
+				seen[acl.UUID] = true
+				applied = append(applied, acl)
+			}
+		}
+	}
+
+	denied := make(map[string]bool, len(deniedPorts))
+	for direction, ports := range deniedPorts {
+		denied[direction] = len(sw.Ports) > 0 && len(ports) == len(sw.Ports)
+		for _, acl := range acls {
+			if acl.Direction == direction && isDefaultDeny(acl) {
+				denied[direction] = true
+			}
+		}
+	}
+	return applied, denied
+}
+
+// checkACL returns the findings of an ACL on its own
+func checkACL(acl *models.ACL) []SecurityFinding {
+	finding := func(check, severity, reason string) SecurityFinding {
+		return SecurityFinding{
+			Check:    check,
+			Severity: severity,
+			Resource: models.ResourceACL,
+			UUID:     acl.UUID,
+			Name:     acl.Name,
+			Reason:   reason + ": " + acl.Match,
+		}
+	}
+
+	switch acl.Action {
+	case "drop", "reject":
+		if !acl.Log {
+			return []SecurityFinding{finding(SecurityUnloggedDeny, SeverityLow, acl.Action+"s traffic without logging it")}
+		}
+		return nil
+	case "allow", "allow-related", "allow-stateless":
+	default:
+		return nil
+	}
+	// Only traffic to ports comes from outside the switch
+	if acl.Direction != "to-lport" {
+		return nil
+	}
+
+	explicit, anySource := sourceScope(acl.Match)
+	if !anySource {
+		return nil
+	}
+
+	var findings []SecurityFinding
+	if exposed := managementPorts(acl.Match); len(exposed) > 0 {
+		findings = append(findings, finding(SecurityExposedManagement, SeverityHigh,
+			"allows "+strings.Join(exposed, ", ")+" from any address"))
+	}
+	switch {
+	case !restrictsPorts(acl.Match):
+		findings = append(findings, finding(SecurityWideOpenAllow, SeverityHigh, "allows all traffic from any address"))
+	case explicit:
+		findings = append(findings, finding(SecurityWideOpenAllow, SeverityMedium, "allows traffic from 0.0.0.0/0"))
+	}
+	return findings
+}
+
+// isDefaultDeny reports whether an ACL drops or rejects all IP traffic, on
+// its own or for the ports of its port group
+func isDefaultDeny(acl *models.ACL) bool {
+	if acl.Action != "drop" && acl.Action != "reject" {
+		return false
+	}
+	match := portGroupScope.ReplaceAllString(acl.Match, "")
+	match = strings.NewReplacer(" ", "", "(", "", ")", "").Replace(match)
+	switch match {
+	case "", "1", "ip", "ip4||ip6", "ip6||ip4":
+		return true
+	}
+	return false
+}
+
+// sourceScope reports whether a match allows any source address: explicitly,
+// with 0.0.0.0/0 or ::/0, or by not restricting the source at all
+func sourceScope(match string) (explicit, anySource bool) {
+	compact := strings.ReplaceAll(match, " ", "")
+	if strings.Contains(compact, "src==0.0.0.0/0") || strings.Contains(compact, "src==::/0") {
+		return true, true
+	}
+	for _, field := range []string{"ip4.src", "ip6.src", "inport", "eth.src", "arp.spa"} {
+		if strings.Contains(match, field) {
+			return false, false
+		}
+	}
+	return false, true
+}
+
+// restrictsPorts reports whether a match restricts the destination port, or
+// only matches control traffic without ports such as ICMP and ARP
+func restrictsPorts(match string) bool {
+	for _, field := range []string{"tcp.dst", "udp.dst", "sctp.dst", "icmp", "arp", "nd.", "nd_"} {
+		if strings.Contains(match, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// managementPorts returns the cleartext management protocols whose ports a
+// match allows, e.g. "Telnet (tcp/23)"
+func managementPorts(match string) []string {
+	allowed := make(map[string]map[int]bool)
+	allow := func(protocol string, port int) {
+		if _, ok := cleartextManagementPorts[protocol][port]; !ok {
+			return
+		}
+		if allowed[protocol] == nil {
+			allowed[protocol] = make(map[int]bool)
+		}
+		allowed[protocol][port] = true
+	}
+
+	for _, m := range portEquals.FindAllStringSubmatch(match, -1) {
+		for _, value := range strings.Split(strings.Trim(m[2], "{}"), ",") {
+			if port, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				allow(m[1], port)
+			}
+		}
+	}
+	for _, m := range portRange.FindAllStringSubmatch(match, -1) {
+		low, _ := strconv.Atoi(m[2])
+		high, _ := strconv.Atoi(m[3])
+		for port := range cleartextManagementPorts[m[1]] {
+			if port >= low && port <= high {
+				allow(m[1], port)
+			}
+		}
+	}
+
+	var exposed []string
+	for _, protocol := range []string{"tcp", "udp"} {
+		ports := make([]int, 0, len(allowed[protocol]))
+		for port := range allowed[protocol] {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		for _, port := range ports {
+			exposed = append(exposed, fmt.Sprintf("%s (%s/%d)", cleartextManagementPorts[protocol][port], protocol, port))
+		}
+	}
+	return exposed
+}
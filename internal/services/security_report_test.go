@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestSecurityReporter_Report(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-web", Name: "web", Ports: []string{"p-1", "p-2"}, ExternalIDs: map[string]string{"tenant_id": "acme"}},
+		{UUID: "sw-db", Name: "db", Ports: []string{"p-3"}, ExternalIDs: map[string]string{"tenant_id": "acme"}},
+		{UUID: "sw-open", Name: "open", Ports: []string{"p-4"}},
+	}, nil)
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{
+		{UUID: "pg-db", Name: "db", Ports: []string{"p-3"}},
+	}, nil)
+	mockOVN.On("ListPortGroupACLs", mock.Anything, "pg-db").Return([]*models.ACL{
+		{UUID: "acl-db-in", Direction: "to-lport", Match: "outport == @db && ip", Action: "drop", Log: true},
+		{UUID: "acl-db-out", Direction: "from-lport", Match: "inport == @db && ip", Action: "drop", Log: true},
+		{UUID: "acl-db-sql", Direction: "to-lport", Match: "outport == @db && ip4.src == $web && tcp.dst == 5432", Action: "allow-related"},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-web").Return([]*models.ACL{
+		{UUID: "acl-web-in", Direction: "to-lport", Match: "ip", Action: "drop"},
+		{UUID: "acl-web-out", Direction: "from-lport", Match: "1", Action: "reject", Log: true},
+		{UUID: "acl-https", Direction: "to-lport", Match: "ip4.src == 0.0.0.0/0 && tcp.dst == 443", Action: "allow-related"},
+		{UUID: "acl-telnet", Direction: "to-lport", Match: "tcp.dst == {22, 23}", Action: "allow-related"},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-db").Return([]*models.ACL{}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-open").Return([]*models.ACL{
+		{UUID: "acl-any", Direction: "to-lport", Match: "ip4", Action: "allow"},
+		{UUID: "acl-snmp", Direction: "to-lport", Match: "udp.dst >= 160 && udp.dst <= 162", Action: "allow"},
+		{UUID: "acl-ftp", Direction: "to-lport", Match: "tcp.dst == 21", Action: "allow-related"},
+	}, nil)
+
+	report, err := NewSecurityReporter(mockOVN).Report(context.Background())
+	require.NoError(t, err)
+
+	findings := make(map[string][]string)
+	scores := make(map[string]int)
+	for _, sw := range report.Switches {
+		scores[sw.SwitchName] = sw.Score
+		for _, finding := range sw.Findings {
+			findings[sw.SwitchName] = append(findings[sw.SwitchName], finding.Check+":"+finding.UUID)
+			assert.NotEmpty(t, finding.Recommendation)
+		}
+	}
+
+	// Port group ACLs covering every port of the switch deny by default
+	assert.Empty(t, findings["db"])
+	assert.Equal(t, 100, scores["db"])
+	assert.Equal(t, []string{
+		"unlogged_deny:acl-web-in",
+		"wide_open_allow:acl-https",
+		"exposed_management_port:acl-telnet",
+	}, findings["web"])
+	assert.Equal(t, 100-3-10-25, scores["web"])
+	assert.Equal(t, []string{
+		"missing_default_deny:sw-open",
+		"missing_default_deny:sw-open",
+		"wide_open_allow:acl-any",
+		"exposed_management_port:acl-snmp",
+		"exposed_management_port:acl-ftp",
+	}, findings["open"])
+	assert.Equal(t, 0, scores["open"], "scores don't go below 0")
+	assert.Equal(t, "open", report.Switches[0].SwitchName, "the worst switches come first")
+
+	require.Len(t, report.Tenants, 1, "switches without a tenant aren't attributed to one")
+	assert.Equal(t, TenantSecurity{TenantID: "acme", Score: (62 + 100) / 2, Switches: 2, Findings: 3}, report.Tenants[0])
+	assert.Equal(t, (62+100+0)/3, report.Score)
+	assert.Equal(t, 3, report.Summary[SecurityExposedManagement])
+	assert.Len(t, report.Recommendations, 4)
+}
+
+func TestManagementPorts(t *testing.T) {
+	assert.Equal(t, []string{"Telnet (tcp/23)"}, managementPorts("tcp.dst == {22, 23}"))
+	assert.Equal(t, []string{"FTP (tcp/21)", "Telnet (tcp/23)"}, managementPorts("tcp.dst >= 20 && tcp.dst <= 25"))
+	assert.Equal(t, []string{"SNMP (udp/161)"}, managementPorts("udp.dst == 161"))
+	assert.Empty(t, managementPorts("tcp.dst == 161"))
+}
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrServiceEntryNotFound is returned for service names the caller's
+	// tenant can't see
+	ErrServiceEntryNotFound = errors.New("service entry not found")
+
+	// ErrInvalidServiceEntry is wrapped by validation errors of custom
+	// service entries
+	ErrInvalidServiceEntry = errors.New("invalid service entry")
+
+	// ErrServiceEntryConflict is returned for names taken by another entry
+	// the tenant can see, and for changes to built-in entries
+	ErrServiceEntryConflict = errors.New("service entry conflict")
+
+	// ErrUnknownService is wrapped by errors for matches referencing
+	// services that aren't in the catalog
+	ErrUnknownService = errors.New("unknown service")
+)
+
+// serviceNamePattern is what service names look like
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// serviceReference is a service reference in a match, service(name)
+var serviceReference = regexp.MustCompile(`\bservice\(\s*([^()\s]*)\s*\)`)
+
+// builtinServices are the entries of the catalog every tenant has
+var builtinServices = []*models.ServiceEntry{
+	{Name: "dns", Description: "DNS", Ports: []models.ServicePort{{Protocol: "udp", Port: 53}, {Protocol: "tcp", Port: 53}}},
+	{Name: "etcd", Description: "etcd client and peer", Ports: []models.ServicePort{{Protocol: "tcp", Port: 2379, EndPort: 2380}}},
+	{Name: "http", Description: "HTTP", Ports: []models.ServicePort{{Protocol: "tcp", Port: 80}}},
+	{Name: "http-alt", Description: "HTTP on its alternate port", Ports: []models.ServicePort{{Protocol: "tcp", Port: 8080}}},
+	{Name: "https", Description: "HTTPS", Ports: []models.ServicePort{{Protocol: "tcp", Port: 443}}},
+	{Name: "kubernetes-api", Description: "Kubernetes API server", Ports: []models.ServicePort{{Protocol: "tcp", Port: 6443}}},
+	{Name: "ldap", Description: "LDAP", Ports: []models.ServicePort{{Protocol: "tcp", Port: 389}}},
+	{Name: "ldaps", Description: "LDAP over TLS", Ports: []models.ServicePort{{Protocol: "tcp", Port: 636}}},
+	{Name: "mongodb", Description: "MongoDB", Ports: []models.ServicePort{{Protocol: "tcp", Port: 27017}}},
+	{Name: "mysql", Description: "MySQL", Ports: []models.ServicePort{{Protocol: "tcp", Port: 3306}}},
+	{Name: "ntp", Description: "NTP", Ports: []models.ServicePort{{Protocol: "udp", Port: 123}}},
+	{Name: "postgresql", Description: "PostgreSQL", Ports: []models.ServicePort{{Protocol: "tcp", Port: 5432}}},
+	{Name: "redis", Description: "Redis", Ports: []models.ServicePort{{Protocol: "tcp", Port: 6379}}},
+	{Name: "smtp", Description: "SMTP", Ports: []models.ServicePort{{Protocol: "tcp", Port: 25}}},
+	{Name: "ssh", Description: "SSH", Ports: []models.ServicePort{{Protocol: "tcp", Port: 22}}},
+}
+
+// ServiceEntryStore keeps the custom entries of the service catalog,
+// shared by the API's replicas. *db.DB implements it.
+type ServiceEntryStore interface {
+	// CreateServiceEntry records entry unless its tenant has one of the
+	// same name, reporting whether it did
+	CreateServiceEntry(ctx context.Context, entry *models.ServiceEntry) (bool, error)
+	// GetServiceEntry returns the entry of tenantID named name, nil when
+	// there's none
+	GetServiceEntry(ctx context.Context, tenantID, name string) (*models.ServiceEntry, error)
+	ListServiceEntries(ctx context.Context) ([]*models.ServiceEntry, error)
+	UpdateServiceEntry(ctx context.Context, entry *models.ServiceEntry) error
+	DeleteServiceEntry(ctx context.Context, id string) error
+}
+
+// ServiceEntryRequest defines a custom service entry
+type ServiceEntryRequest struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Ports       []models.ServicePort `json:"ports" binding:"required"`
+}
+
+// ServiceCatalog resolves named L4 services in ACL matches. A match
+// references a service as service(name), which is expanded into the
+// protocols and ports of the entry when rules are compiled, e.g.
+// service(dns) into (udp.dst == 53 || tcp.dst == 53). A tenant sees the
+// built-in entries, the entries created without a tenant and its own;
+// names are unique among them.
+type ServiceCatalog struct {
+	store  ServiceEntryStore
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewServiceCatalog creates a catalog keeping custom entries in store
+func NewServiceCatalog(store ServiceEntryStore, logger *zap.Logger) *ServiceCatalog {
+	return &ServiceCatalog{store: store, logger: logger, now: time.Now}
+}
+
+// List lists the entries the context's tenant sees, by name. Without a
+// tenant, it's every tenant's entries.
+func (s *ServiceCatalog) List(ctx context.Context) ([]*models.ServiceEntry, error) {
+	custom, err := s.store.ListServiceEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := getTenantFromContext(ctx)
+	entries := make([]*models.ServiceEntry, 0, len(builtinServices)+len(custom))
+	for _, entry := range builtinServices {
+		entries = append(entries, builtinEntry(entry))
+	}
+	for _, entry := range custom {
+		if tenantID == "" || entry.TenantID == "" || entry.TenantID == tenantID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Get returns the entry named name the context's tenant sees: its own,
+// one created without a tenant, or a built-in one
+func (s *ServiceCatalog) Get(ctx context.Context, name string) (*models.ServiceEntry, error) {
+	if tenantID := getTenantFromContext(ctx); tenantID != "" {
+		entry, err := s.store.GetServiceEntry(ctx, tenantID, name)
+		if err != nil || entry != nil {
+			return entry, err
+		}
+	}
+	entry, err := s.store.GetServiceEntry(ctx, "", name)
+	if err != nil || entry != nil {
+		return entry, err
+	}
+	for _, entry := range builtinServices {
+		if entry.Name == name {
+			return builtinEntry(entry), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServiceEntryNotFound, name)
+}
+
+// Create adds a custom entry for the context's tenant, or for every tenant
+// without one
+func (s *ServiceCatalog) Create(ctx context.Context, req *ServiceEntryRequest, user string) (*models.ServiceEntry, error) {
+	if !serviceNamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, digits, '.', '_' or '-'", ErrInvalidServiceEntry)
+	}
+	if err := validateServicePorts(req.Ports); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, req.Name); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	entry := &models.ServiceEntry{
+		ID:          uuid.New().String(),
+		TenantID:    getTenantFromContext(ctx),
+		Name:        req.Name,
+		Description: req.Description,
+		Ports:       req.Ports,
+		CreatedBy:   user,
+		CreatedAt:   &now,
+		UpdatedAt:   &now,
+	}
+	created, err := s.store.CreateServiceEntry(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: service %s exists already", ErrServiceEntryConflict, entry.Name)
+	}
+
+	s.logger.Info("Service entry created",
+		zap.String("service", entry.Name),
+		zap.String("tenant_id", entry.TenantID))
+	return entry, nil
+}
+
+// Update changes the description and ports of a custom entry of the
+// context's tenant. ACLs expanded from the entry keep its former ports.
+func (s *ServiceCatalog) Update(ctx context.Context, name string, req *ServiceEntryRequest) (*models.ServiceEntry, error) {
+	entry, err := s.owned(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateServicePorts(req.Ports); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	entry.Description = req.Description
+	entry.Ports = req.Ports
+	entry.UpdatedAt = &now
+	if err := s.store.UpdateServiceEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Delete deletes a custom entry of the context's tenant. ACLs expanded
+// from it are left as they are.
+func (s *ServiceCatalog) Delete(ctx context.Context, name string) error {
+	entry, err := s.owned(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := s.store.DeleteServiceEntry(ctx, entry.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Service entry deleted",
+		zap.String("service", entry.Name),
+		zap.String("tenant_id", entry.TenantID))
+	return nil
+}
+
+// ExpandMatch replaces the service references of an ACL match with the
+// protocols and ports of the entries the context's tenant sees
+func (s *ServiceCatalog) ExpandMatch(ctx context.Context, match string) (string, error) {
+	refs := serviceReference.FindAllStringSubmatchIndex(match, -1)
+	if len(refs) == 0 {
+		return match, nil
+	}
+
+	var expanded strings.Builder
+	last := 0
+	for _, ref := range refs {
+		name := match[ref[2]:ref[3]]
+		entry, err := s.Get(ctx, name)
+		if errors.Is(err, ErrServiceEntryNotFound) {
+			return "", fmt.Errorf("%w: %q", ErrUnknownService, name)
+		}
+		if err != nil {
+			return "", err
+		}
+		expanded.WriteString(match[last:ref[0]])
+		expanded.WriteString(servicePortsMatch(entry.Ports))
+		last = ref[1]
+	}
+	expanded.WriteString(match[last:])
+	return expanded.String(), nil
+}
+
+// owned returns the custom entry of the context's tenant named name
+func (s *ServiceCatalog) owned(ctx context.Context, name string) (*models.ServiceEntry, error) {
+	entry, err := s.store.GetServiceEntry(ctx, getTenantFromContext(ctx), name)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		return entry, nil
+	}
+	if isBuiltinService(name) {
+		return nil, fmt.Errorf("%w: built-in service %s can't be changed", ErrServiceEntryConflict, name)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServiceEntryNotFound, name)
+}
+
+// checkName checks no entry a tenant sees along with a new entry of the
+// context's tenant is named name. A new entry without a tenant is seen by
+// every tenant.
+func (s *ServiceCatalog) checkName(ctx context.Context, name string) error {
+	if isBuiltinService(name) {
+		return fmt.Errorf("%w: %s is a built-in service", ErrServiceEntryConflict, name)
+	}
+	entries, err := s.store.ListServiceEntries(ctx)
+	if err != nil {
+		return err
+	}
+	tenantID := getTenantFromContext(ctx)
+	for _, entry := range entries {
+		if entry.Name == name && (tenantID == "" || entry.TenantID == "" || entry.TenantID == tenantID) {
+			return fmt.Errorf("%w: service %s exists already", ErrServiceEntryConflict, name)
+		}
+	}
+	return nil
+}
+
+func isBuiltinService(name string) bool {
+	for _, entry := range builtinServices {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinEntry returns a copy of a built-in entry
+func builtinEntry(entry *models.ServiceEntry) *models.ServiceEntry {
+	copied := *entry
+	copied.BuiltIn = true
+	copied.Ports = append([]models.ServicePort(nil), entry.Ports...)
+	return &copied
+}
+
+// validateServicePorts checks the ports of a custom entry
+func validateServicePorts(ports []models.ServicePort) error {
+	if len(ports) == 0 {
+		return fmt.Errorf("%w: at least one port is required", ErrInvalidServiceEntry)
+	}
+	for _, port := range ports {
+		switch port.Protocol {
+		case "tcp", "udp", "sctp":
+		default:
+			return fmt.Errorf("%w: protocol must be tcp, udp or sctp, got %q", ErrInvalidServiceEntry, port.Protocol)
+		}
+		if port.Port < 1 || port.Port > 65535 {
+			return fmt.Errorf("%w: port must be between 1 and 65535, got %d", ErrInvalidServiceEntry, port.Port)
+		}
+		if port.EndPort != 0 && (port.EndPort <= port.Port || port.EndPort > 65535) {
+			return fmt.Errorf("%w: end_port %d must be above port %d and at most 65535", ErrInvalidServiceEntry, port.EndPort, port.Port)
+		}
+	}
+	return nil
+}
+
+// servicePortsMatch returns the match of traffic to ports, the single
+// ports of a protocol as one set, e.g. (tcp.dst == {80, 443} || udp.dst
+// == 53)
+func servicePortsMatch(ports []models.ServicePort) string {
+	var protocols []string
+	singles := make(map[string][]string)
+	var ranges []string
+	for _, port := range ports {
+		if _, ok := singles[port.Protocol]; !ok {
+			protocols = append(protocols, port.Protocol)
+			singles[port.Protocol] = nil
+		}
+		if port.EndPort == 0 {
+			singles[port.Protocol] = append(singles[port.Protocol], strconv.Itoa(port.Port))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("(%s.dst >= %d && %s.dst <= %d)", port.Protocol, port.Port, port.Protocol, port.EndPort))
+		}
+	}
+
+	var terms []string
+	for _, protocol := range protocols {
+		switch values := singles[protocol]; len(values) {
+		case 0:
+		case 1:
+			terms = append(terms, fmt.Sprintf("%s.dst == %s", protocol, values[0]))
+		default:
+			terms = append(terms, fmt.Sprintf("%s.dst == {%s}", protocol, strings.Join(values, ", ")))
+		}
+	}
+	terms = append(terms, ranges...)
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return "(" + strings.Join(terms, " || ") + ")"
+}
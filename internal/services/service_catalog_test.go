@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/templates"
+)
+
+// memoryServiceEntryStore keeps service entries in memory
+type memoryServiceEntryStore struct {
+	entries []*models.ServiceEntry
+}
+
+func (s *memoryServiceEntryStore) CreateServiceEntry(ctx context.Context, entry *models.ServiceEntry) (bool, error) {
+	for _, other := range s.entries {
+		if other.TenantID == entry.TenantID && other.Name == entry.Name {
+			return false, nil
+		}
+	}
+	stored := *entry
+	s.entries = append(s.entries, &stored)
+	return true, nil
+}
+
+func (s *memoryServiceEntryStore) GetServiceEntry(ctx context.Context, tenantID, name string) (*models.ServiceEntry, error) {
+	for _, entry := range s.entries {
+		if entry.TenantID == tenantID && entry.Name == name {
+			stored := *entry
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryServiceEntryStore) ListServiceEntries(ctx context.Context) ([]*models.ServiceEntry, error) {
+	entries := []*models.ServiceEntry{}
+	for _, entry := range s.entries {
+		stored := *entry
+		entries = append(entries, &stored)
+	}
+	return entries, nil
+}
+
+func (s *memoryServiceEntryStore) UpdateServiceEntry(ctx context.Context, entry *models.ServiceEntry) error {
+	for i, other := range s.entries {
+		if other.ID == entry.ID {
+			stored := *entry
+			s.entries[i] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryServiceEntryStore) DeleteServiceEntry(ctx context.Context, id string) error {
+	for i, entry := range s.entries {
+		if entry.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestServiceCatalog_ExpandMatch(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	catalog := NewServiceCatalog(&memoryServiceEntryStore{}, zap.NewNop())
+
+	for match, expanded := range map[string]string{
+		"ip4 && tcp.dst == 22":                    "ip4 && tcp.dst == 22",
+		"ip4 && service(https)":                   "ip4 && tcp.dst == 443",
+		"ip4.src == 10.0.0.0/8 && service( dns )": "ip4.src == 10.0.0.0/8 && (udp.dst == 53 || tcp.dst == 53)",
+		"service(etcd)":                           "(tcp.dst >= 2379 && tcp.dst <= 2380)",
+		"service(http) || service(ssh)":           "tcp.dst == 80 || tcp.dst == 22",
+	} {
+		got, err := catalog.ExpandMatch(ctx, match)
+		require.NoError(t, err, match)
+		assert.Equal(t, expanded, got, match)
+	}
+
+	_, err := catalog.ExpandMatch(ctx, "ip4 && service(gopher)")
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.ErrorContains(t, err, "gopher")
+}
+
+func TestServiceCatalog_CustomEntries(t *testing.T) {
+	acme := ContextWithTenant(context.Background(), "acme")
+	other := ContextWithTenant(context.Background(), "other")
+	store := &memoryServiceEntryStore{}
+	catalog := NewServiceCatalog(store, zap.NewNop())
+
+	web := &ServiceEntryRequest{Name: "web", Ports: []models.ServicePort{
+		{Protocol: "tcp", Port: 80}, {Protocol: "tcp", Port: 443}, {Protocol: "tcp", Port: 8000, EndPort: 8099},
+	}}
+	entry, err := catalog.Create(acme, web, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", entry.TenantID)
+	assert.Equal(t, "alice", entry.CreatedBy)
+
+	expanded, err := catalog.ExpandMatch(acme, "service(web)")
+	require.NoError(t, err)
+	assert.Equal(t, "(tcp.dst == {80, 443} || (tcp.dst >= 8000 && tcp.dst <= 8099))", expanded)
+
+	// Other tenants don't see it, and may have their own
+	_, err = catalog.ExpandMatch(other, "service(web)")
+	assert.ErrorIs(t, err, ErrUnknownService)
+	_, err = catalog.Create(other, &ServiceEntryRequest{Name: "web", Ports: []models.ServicePort{{Protocol: "tcp", Port: 8443}}}, "bob")
+	require.NoError(t, err)
+
+	// Names a tenant sees are unambiguous
+	_, err = catalog.Create(acme, web, "alice")
+	assert.ErrorIs(t, err, ErrServiceEntryConflict)
+	_, err = catalog.Create(acme, &ServiceEntryRequest{Name: "postgresql", Ports: web.Ports}, "alice")
+	assert.ErrorIs(t, err, ErrServiceEntryConflict)
+	_, err = catalog.Create(context.Background(), web, "admin")
+	assert.ErrorIs(t, err, ErrServiceEntryConflict, "a global entry would shadow the tenants'")
+
+	// Built-in entries can't be changed
+	_, err = catalog.Update(acme, "https", web)
+	assert.ErrorIs(t, err, ErrServiceEntryConflict)
+	assert.ErrorIs(t, catalog.Delete(acme, "https"), ErrServiceEntryConflict)
+
+	updated, err := catalog.Update(acme, "web", &ServiceEntryRequest{Ports: []models.ServicePort{{Protocol: "tcp", Port: 8080}}})
+	require.NoError(t, err)
+	assert.Equal(t, "web", updated.Name)
+	expanded, err = catalog.ExpandMatch(acme, "service(web)")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp.dst == 8080", expanded)
+
+	entries, err := catalog.List(acme)
+	require.NoError(t, err)
+	assert.Len(t, entries, len(builtinServices)+1)
+	for i := 1; i < len(entries); i++ {
+		assert.LessOrEqual(t, entries[i-1].Name, entries[i].Name)
+	}
+
+	assert.ErrorIs(t, catalog.Delete(other, "missing"), ErrServiceEntryNotFound)
+	require.NoError(t, catalog.Delete(acme, "web"))
+	assert.Len(t, store.entries, 1)
+}
+
+func TestServiceCatalog_Validation(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	catalog := NewServiceCatalog(&memoryServiceEntryStore{}, zap.NewNop())
+
+	for name, req := range map[string]*ServiceEntryRequest{
+		"invalid name":      {Name: "My Service", Ports: []models.ServicePort{{Protocol: "tcp", Port: 80}}},
+		"no ports":          {Name: "empty"},
+		"unknown protocol":  {Name: "gre", Ports: []models.ServicePort{{Protocol: "gre", Port: 1}}},
+		"port out of range": {Name: "big", Ports: []models.ServicePort{{Protocol: "tcp", Port: 70000}}},
+		"inverted range":    {Name: "range", Ports: []models.ServicePort{{Protocol: "tcp", Port: 9000, EndPort: 8000}}},
+	} {
+		_, err := catalog.Create(ctx, req, "alice")
+		assert.ErrorIs(t, err, ErrInvalidServiceEntry, name)
+	}
+}
+
+func TestTemplateService_ExpandsServices(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service := NewTemplateService(new(MockOVNService), zap.NewNop())
+	service.SetServiceCatalog(NewServiceCatalog(&memoryServiceEntryStore{}, zap.NewNop()))
+
+	template := &templates.PolicyTemplate{
+		ID: "allow-service",
+		Variables: []templates.TemplateVariable{
+			{Name: "service", Type: "service", Required: true},
+		},
+		Rules: []templates.TemplateRule{
+			{Name: "allow", Direction: "to-lport", Priority: 1000, Match: "ip4 && service({{.service}})", Action: "allow"},
+		},
+	}
+
+	rules, err := service.generateRules(ctx, template, map[string]interface{}{"service": "postgresql"})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "ip4 && tcp.dst == 5432", rules[0].Match)
+	assert.Equal(t, "ip4 && service(postgresql)", rules[0].ExternalIDs[models.ACLMatchSourceKey])
+
+	_, err = service.generateRules(ctx, template, map[string]interface{}{"service": "gopher"})
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.Error(t, validateTemplateVariable(template.Variables[0], "Not A Service"))
+}
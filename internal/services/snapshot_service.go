@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// maxSnapshots bounds the snapshots kept; the oldest are dropped first
+const maxSnapshots = 1024
+
+// staleReadsContextKey is the context key of the func told about reads
+// answered from a snapshot
+const staleReadsContextKey = "ovn_stale_reads"
+
+// ContextWithStaleReads returns a context in which reads answered from a
+// snapshot, rather than by OVN, report the time the snapshot was taken to
+// onStale
+func ContextWithStaleReads(ctx context.Context, onStale func(takenAt time.Time)) context.Context {
+	return context.WithValue(ctx, staleReadsContextKey, onStale)
+}
+
+// SnapshotOVNService keeps the last successful result of every list and
+// topology read and answers with it while OVN is unreachable, so the API
+// stays readable. Other operations go straight to the wrapped service.
+type SnapshotOVNService struct {
+	service OVNServiceInterface
+	now     func() time.Time
+
+	mu        sync.Mutex
+	snapshots map[string]snapshot
+}
+
+// snapshot is the result of a read and when it was taken
+type snapshot struct {
+	value   interface{}
+	takenAt time.Time
+}
+
+// snapshotPage is the result of a paged list
+type snapshotPage[T any] struct {
+	items T
+	total int
+}
+
+// NewSnapshotOVNService creates an OVN service serving reads from snapshots
+// while OVN is unreachable
+func NewSnapshotOVNService(service OVNServiceInterface) *SnapshotOVNService {
+	return &SnapshotOVNService{
+		service:   service,
+		now:       time.Now,
+		snapshots: make(map[string]snapshot),
+	}
+}
+
+var _ OVNServiceInterface = (*SnapshotOVNService)(nil)
+
+// snapshotRead runs read and keeps its result under key for the selected
+// cluster. When OVN is unreachable it answers with the kept result instead,
+// if there is one, and reports it as stale.
+func snapshotRead[T any](s *SnapshotOVNService, ctx context.Context, key string, read func() (T, error)) (T, error) {
+	key = getOVNClusterFromContext(ctx) + "/" + key
+
+	result, err := read()
+	if err == nil {
+		s.store(key, result)
+		return result, nil
+	}
+	if !isOVNUnavailable(err) {
+		return result, err
+	}
+
+	s.mu.Lock()
+	snap, ok := s.snapshots[key]
+	s.mu.Unlock()
+	if !ok {
+		return result, err
+	}
+
+	if onStale, ok := ctx.Value(staleReadsContextKey).(func(time.Time)); ok {
+		onStale(snap.takenAt)
+	}
+	return snap.value.(T), nil
+}
+
+func (s *SnapshotOVNService) store(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.snapshots[key]; !ok && len(s.snapshots) >= maxSnapshots {
+		var oldest string
+		for k, snap := range s.snapshots {
+			if oldest == "" || snap.takenAt.Before(s.snapshots[oldest].takenAt) {
+				oldest = k
+			}
+		}
+		delete(s.snapshots, oldest)
+	}
+	s.snapshots[key] = snapshot{value: value, takenAt: s.now()}
+}
+
+// snapshotKey identifies a read by its method and arguments
+func snapshotKey(method string, args ...interface{}) string {
+	if len(args) == 0 {
+		return method
+	}
+	encoded, _ := json.Marshal(args)
+	return method + string(encoded)
+}
+
+// isOVNUnavailable reports whether err means OVN couldn't be reached, as
+// opposed to the read itself failing
+func isOVNUnavailable(err error) bool {
+	return strings.Contains(err.Error(), "not connected")
+}
+
+func (s *SnapshotOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListLogicalSwitches"), func() ([]*models.LogicalSwitch, error) {
+		return s.service.ListLogicalSwitches(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	page, err := snapshotRead(s, ctx, snapshotKey("ListLogicalSwitchesPage", opts), func() (snapshotPage[[]*models.LogicalSwitch], error) {
+		items, total, err := s.service.ListLogicalSwitchesPage(ctx, opts)
+		return snapshotPage[[]*models.LogicalSwitch]{items, total}, err
+	})
+	return page.items, page.total, err
+}
+
+func (s *SnapshotOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
+	return s.service.GetLogicalSwitch(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	return s.service.CreateLogicalSwitch(ctx, ls)
+}
+
+func (s *SnapshotOVNService) UpdateLogicalSwitch(ctx context.Context, id string, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
+	return s.service.UpdateLogicalSwitch(ctx, id, ls)
+}
+
+func (s *SnapshotOVNService) DeleteLogicalSwitch(ctx context.Context, id string) error {
+	return s.service.DeleteLogicalSwitch(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListLogicalRouters"), func() ([]*models.LogicalRouter, error) {
+		return s.service.ListLogicalRouters(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	page, err := snapshotRead(s, ctx, snapshotKey("ListLogicalRoutersPage", opts), func() (snapshotPage[[]*models.LogicalRouter], error) {
+		items, total, err := s.service.ListLogicalRoutersPage(ctx, opts)
+		return snapshotPage[[]*models.LogicalRouter]{items, total}, err
+	})
+	return page.items, page.total, err
+}
+
+func (s *SnapshotOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
+	return s.service.GetLogicalRouter(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	return s.service.CreateLogicalRouter(ctx, lr)
+}
+
+func (s *SnapshotOVNService) UpdateLogicalRouter(ctx context.Context, id string, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
+	return s.service.UpdateLogicalRouter(ctx, id, lr)
+}
+
+func (s *SnapshotOVNService) DeleteLogicalRouter(ctx context.Context, id string) error {
+	return s.service.DeleteLogicalRouter(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListPorts", switchID), func() ([]*models.LogicalSwitchPort, error) {
+		return s.service.ListPorts(ctx, switchID)
+	})
+}
+
+func (s *SnapshotOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	page, err := snapshotRead(s, ctx, snapshotKey("ListPortsPage", switchID, opts), func() (snapshotPage[[]*models.LogicalSwitchPort], error) {
+		items, total, err := s.service.ListPortsPage(ctx, switchID, opts)
+		return snapshotPage[[]*models.LogicalSwitchPort]{items, total}, err
+	})
+	return page.items, page.total, err
+}
+
+func (s *SnapshotOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
+	return s.service.GetPort(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreatePort(ctx context.Context, switchID string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	return s.service.CreatePort(ctx, switchID, port)
+}
+
+func (s *SnapshotOVNService) UpdatePort(ctx context.Context, id string, port *models.LogicalSwitchPort) (*models.LogicalSwitchPort, error) {
+	return s.service.UpdatePort(ctx, id, port)
+}
+
+func (s *SnapshotOVNService) DeletePort(ctx context.Context, id string) error {
+	return s.service.DeletePort(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListACLs", switchID), func() ([]*models.ACL, error) {
+		return s.service.ListACLs(ctx, switchID)
+	})
+}
+
+func (s *SnapshotOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	page, err := snapshotRead(s, ctx, snapshotKey("ListACLsPage", switchID, opts), func() (snapshotPage[[]*models.ACL], error) {
+		items, total, err := s.service.ListACLsPage(ctx, switchID, opts)
+		return snapshotPage[[]*models.ACL]{items, total}, err
+	})
+	return page.items, page.total, err
+}
+
+func (s *SnapshotOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
+	return s.service.GetACL(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateACL(ctx context.Context, switchID string, acl *models.ACL) (*models.ACL, error) {
+	return s.service.CreateACL(ctx, switchID, acl)
+}
+
+func (s *SnapshotOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	return s.service.CreateACLs(ctx, switchID, acls)
+}
+
+func (s *SnapshotOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+	return s.service.UpdateACL(ctx, id, acl)
+}
+
+func (s *SnapshotOVNService) DeleteACL(ctx context.Context, id string) error {
+	return s.service.DeleteACL(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListLoadBalancers"), func() ([]*models.LoadBalancer, error) {
+		return s.service.ListLoadBalancers(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	page, err := snapshotRead(s, ctx, snapshotKey("ListLoadBalancersPage", opts), func() (snapshotPage[[]*models.LoadBalancer], error) {
+		items, total, err := s.service.ListLoadBalancersPage(ctx, opts)
+		return snapshotPage[[]*models.LoadBalancer]{items, total}, err
+	})
+	return page.items, page.total, err
+}
+
+func (s *SnapshotOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	return s.service.GetLoadBalancer(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	return s.service.CreateLoadBalancer(ctx, lb)
+}
+
+func (s *SnapshotOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	return s.service.UpdateLoadBalancer(ctx, id, lb)
+}
+
+func (s *SnapshotOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	return s.service.DeleteLoadBalancer(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListPortGroups"), func() ([]*models.PortGroup, error) {
+		return s.service.ListPortGroups(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListAddressSets"), func() ([]*models.AddressSet, error) {
+		return s.service.ListAddressSets(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListPortGroupACLs", portGroupID), func() ([]*models.ACL, error) {
+		return s.service.ListPortGroupACLs(ctx, portGroupID)
+	})
+}
+
+func (s *SnapshotOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	return s.service.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+}
+
+func (s *SnapshotOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListDNS"), func() ([]*models.DNS, error) {
+		return s.service.ListDNS(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	return s.service.GetDNS(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	return s.service.CreateDNS(ctx, dns)
+}
+
+func (s *SnapshotOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	return s.service.UpdateDNS(ctx, id, dns)
+}
+
+func (s *SnapshotOVNService) DeleteDNS(ctx context.Context, id string) error {
+	return s.service.DeleteDNS(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListMirrors"), func() ([]*models.Mirror, error) {
+		return s.service.ListMirrors(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	return s.service.GetMirror(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	return s.service.CreateMirror(ctx, mirror)
+}
+
+func (s *SnapshotOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	return s.service.UpdateMirror(ctx, id, mirror)
+}
+
+func (s *SnapshotOVNService) DeleteMirror(ctx context.Context, id string) error {
+	return s.service.DeleteMirror(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListSampleCollectors"), func() ([]*models.SampleCollector, error) {
+		return s.service.ListSampleCollectors(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	return s.service.GetSampleCollector(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.service.CreateSampleCollector(ctx, collector)
+}
+
+func (s *SnapshotOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.service.UpdateSampleCollector(ctx, id, collector)
+}
+
+func (s *SnapshotOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	return s.service.DeleteSampleCollector(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListSamplingApps"), func() ([]*models.SamplingApp, error) {
+		return s.service.ListSamplingApps(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	return s.service.SetSamplingApp(ctx, appType, id)
+}
+
+func (s *SnapshotOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	return s.service.GetACLSampling(ctx, aclID)
+}
+
+func (s *SnapshotOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	return s.service.SetACLSampling(ctx, aclID, sampling)
+}
+
+func (s *SnapshotOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	return s.service.SetSwitchSampling(ctx, switchID, sampling)
+}
+
+func (s *SnapshotOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListRouterPolicies", routerID), func() ([]*models.RouterPolicy, error) {
+		return s.service.ListRouterPolicies(ctx, routerID)
+	})
+}
+
+func (s *SnapshotOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	return s.service.GetRouterPolicy(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	return s.service.CreateRouterPolicy(ctx, routerID, policy)
+}
+
+func (s *SnapshotOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	return s.service.UpdateRouterPolicy(ctx, id, policy)
+}
+
+func (s *SnapshotOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	return s.service.DeleteRouterPolicy(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListStaticRoutes", routerID), func() ([]*models.StaticRoute, error) {
+		return s.service.ListStaticRoutes(ctx, routerID)
+	})
+}
+
+func (s *SnapshotOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	return s.service.GetStaticRoute(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	return s.service.CreateStaticRoutes(ctx, routerID, routes)
+}
+
+func (s *SnapshotOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	return s.service.UpdateStaticRoute(ctx, id, route)
+}
+
+func (s *SnapshotOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	return s.service.DeleteStaticRoute(ctx, id)
+}
+
+func (s *SnapshotOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	return s.service.CreateRouterPort(ctx, routerID, port)
+}
+
+func (s *SnapshotOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	return s.service.DeleteRouterPort(ctx, id)
+}
+
+func (s *SnapshotOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	return snapshotRead(s, ctx, snapshotKey("ListGateways"), func() ([]*models.Gateway, error) {
+		return s.service.ListGateways(ctx)
+	})
+}
+
+func (s *SnapshotOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	return s.service.SetGatewayBinding(ctx, portID, binding)
+}
+
+func (s *SnapshotOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	return s.service.SetGatewayPriorities(ctx, priorities)
+}
+
+func (s *SnapshotOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	return s.service.SetLabels(ctx, resource, id, set, remove)
+}
+
+func (s *SnapshotOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
+	return s.service.ExecuteTransaction(ctx, ops)
+}
+
+func (s *SnapshotOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+	return snapshotRead(s, ctx, snapshotKey("GetTopology"), func() (*Topology, error) {
+		return s.service.GetTopology(ctx)
+	})
+}
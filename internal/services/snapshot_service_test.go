@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestSnapshotOVNService_ServesSnapshotsWhileDisconnected(t *testing.T) {
+	mockOVN := new(MockOVNService)
+	svc := NewSnapshotOVNService(mockOVN)
+	takenAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return takenAt }
+
+	var staleAt time.Time
+	ctx := ContextWithStaleReads(context.Background(), func(t time.Time) { staleAt = t })
+
+	switches := []*models.LogicalSwitch{{UUID: "ls1", Name: "web"}}
+	mockOVN.On("ListLogicalSwitches", ctx).Return(switches, nil).Once()
+	result, err := svc.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, switches, result)
+	assert.True(t, staleAt.IsZero(), "fresh reads aren't stale")
+
+	mockOVN.On("ListLogicalSwitches", ctx).Return([]*models.LogicalSwitch(nil), fmt.Errorf("failed to list: %w", errors.New("client not connected"))).Once()
+	result, err = svc.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, switches, result)
+	assert.Equal(t, takenAt, staleAt)
+
+	mockOVN.AssertExpectations(t)
+}
+
+func TestSnapshotOVNService_KeysSnapshotsByArguments(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewSnapshotOVNService(mockOVN)
+	down := errors.New("client not connected")
+
+	ports := []*models.LogicalSwitchPort{{UUID: "p1"}}
+	mockOVN.On("ListPorts", ctx, "ls1").Return(ports, nil).Once()
+	_, err := svc.ListPorts(ctx, "ls1")
+	require.NoError(t, err)
+
+	mockOVN.On("ListPorts", mock.Anything, mock.Anything).Return([]*models.LogicalSwitchPort(nil), down)
+	result, err := svc.ListPorts(ctx, "ls1")
+	require.NoError(t, err)
+	assert.Equal(t, ports, result)
+
+	_, err = svc.ListPorts(ctx, "ls2")
+	assert.ErrorIs(t, err, down, "there is no snapshot of other switches' ports")
+
+	// Snapshots are kept per cluster
+	_, err = svc.ListPorts(ContextWithOVNCluster(ctx, "eu-west"), "ls1")
+	assert.ErrorIs(t, err, down)
+}
+
+func TestSnapshotOVNService_PassesThroughOtherErrors(t *testing.T) {
+	ctx := context.Background()
+	mockOVN := new(MockOVNService)
+	svc := NewSnapshotOVNService(mockOVN)
+
+	mockOVN.On("GetTopology", ctx).Return(&Topology{}, nil).Once()
+	_, err := svc.GetTopology(ctx)
+	require.NoError(t, err)
+
+	failure := errors.New("invalid topology")
+	mockOVN.On("GetTopology", ctx).Return(nil, failure).Once()
+	_, err = svc.GetTopology(ctx)
+	assert.ErrorIs(t, err, failure)
+}
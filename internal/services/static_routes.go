@@ -0,0 +1,303 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Formats of static route imports
+const (
+	RouteImportCSV  = "csv"
+	RouteImportJSON = "json"
+)
+
+// MaxImportedRoutes caps the routes a single import may add
+const MaxImportedRoutes = 500
+
+// routeImportColumns are the columns of a CSV route import, of which only
+// ip_prefix and nexthop are required
+var routeImportColumns = []string{"ip_prefix", "nexthop", "output_port", "policy", "route_table", "bfd", "ecmp_symmetric_reply"}
+
+// ECMPGroup is a set of routes for the same prefix, policy and route table
+// that OVN balances traffic across
+type ECMPGroup struct {
+	IPPrefix   string   `json:"ip_prefix"`
+	Policy     string   `json:"policy"`
+	RouteTable string   `json:"route_table,omitempty"`
+	Nexthops   []string `json:"nexthops"`
+	Routes     []string `json:"routes"`
+}
+
+// routeGroupKey identifies the routes OVN treats as one ECMP group
+type routeGroupKey struct {
+	routeTable string
+	policy     string
+	prefix     string
+}
+
+func groupKeyOf(route *models.StaticRoute) routeGroupKey {
+	policy := "dst-ip"
+	if route.Policy != nil && *route.Policy != "" {
+		policy = *route.Policy
+	}
+	prefix := route.IPPrefix
+	if network := routeNetwork(route.IPPrefix); network != nil {
+		prefix = network.String()
+	}
+	return routeGroupKey{routeTable: route.RouteTable, policy: policy, prefix: prefix}
+}
+
+// GroupECMPRoutes returns the ECMP groups among routes, in the order their
+// first route appears. Prefixes with a single route aren't groups.
+func GroupECMPRoutes(routes []*models.StaticRoute) []ECMPGroup {
+	var keys []routeGroupKey
+	members := make(map[routeGroupKey][]*models.StaticRoute)
+	for _, route := range routes {
+		key := groupKeyOf(route)
+		if _, ok := members[key]; !ok {
+			keys = append(keys, key)
+		}
+		members[key] = append(members[key], route)
+	}
+
+	groups := []ECMPGroup{}
+	for _, key := range keys {
+		if len(members[key]) < 2 {
+			continue
+		}
+		group := ECMPGroup{IPPrefix: key.prefix, Policy: key.policy, RouteTable: key.routeTable}
+		for _, route := range members[key] {
+			group.Nexthops = append(group.Nexthops, route.Nexthop)
+			group.Routes = append(group.Routes, route.UUID)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// StaticRouteWarnings describes the routing surprises added routes bring
+// along: routes joining an ECMP group, groups whose routes disagree on BFD
+// or symmetric replies, and prefixes overlapping the prefix of another
+// route. Only findings involving an added route are reported; pass all
+// routes as added to check a whole router.
+func StaticRouteWarnings(existing, added []*models.StaticRoute) []string {
+	warnings := []string{}
+	all := append(append([]*models.StaticRoute{}, existing...), added...)
+	isAdded := func(i int) bool { return i >= len(existing) }
+
+	// Routes joining an existing ECMP group
+	for i := len(existing); i < len(all); i++ {
+		peers := 0
+		for j := 0; j < len(existing); j++ {
+			if groupKeyOf(all[i]) == groupKeyOf(all[j]) {
+				peers++
+			}
+		}
+		if peers > 0 {
+			warnings = append(warnings, fmt.Sprintf("route %s via %s joins an ECMP group with %d existing route(s)",
+				all[i].IPPrefix, all[i].Nexthop, peers))
+		}
+	}
+
+	// ECMP groups whose routes are configured differently
+	seen := make(map[routeGroupKey]bool)
+	for i := len(existing); i < len(all); i++ {
+		key := groupKeyOf(all[i])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var group []*models.StaticRoute
+		for _, route := range all {
+			if groupKeyOf(route) == key {
+				group = append(group, route)
+			}
+		}
+		if len(group) < 2 {
+			continue
+		}
+		if !agree(group, func(r *models.StaticRoute) bool { return r.BFD != nil && *r.BFD }) {
+			warnings = append(warnings, fmt.Sprintf("ECMP group %s has routes with and without BFD; nexthops without BFD stay in use when they fail", key.prefix))
+		}
+		if !agree(group, func(r *models.StaticRoute) bool { return r.ECMPSymmetricReply != nil && *r.ECMPSymmetricReply }) {
+			warnings = append(warnings, fmt.Sprintf("ECMP group %s has routes with and without ecmp_symmetric_reply; replies may return through a different nexthop", key.prefix))
+		}
+	}
+
+	// Overlapping prefixes, where the longer one takes the traffic it covers
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			if !isAdded(i) && !isAdded(j) {
+				continue
+			}
+			a, b := groupKeyOf(all[i]), groupKeyOf(all[j])
+			if a.routeTable != b.routeTable || a.policy != b.policy || a.prefix == b.prefix {
+				continue
+			}
+			narrow, wide := all[i], all[j]
+			if !prefixWithin(narrow.IPPrefix, wide.IPPrefix) {
+				narrow, wide = wide, narrow
+				if !prefixWithin(narrow.IPPrefix, wide.IPPrefix) {
+					continue
+				}
+			}
+			warnings = append(warnings, fmt.Sprintf("route %s via %s overlaps route %s via %s; traffic to %s takes the more specific route",
+				narrow.IPPrefix, narrow.Nexthop, wide.IPPrefix, wide.Nexthop, narrow.IPPrefix))
+		}
+	}
+
+	return warnings
+}
+
+// agree reports whether fn holds for all routes or for none of them
+func agree(routes []*models.StaticRoute, fn func(*models.StaticRoute) bool) bool {
+	for _, route := range routes[1:] {
+		if fn(route) != fn(routes[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// routeNetwork parses a route prefix, where a bare address is a host route
+func routeNetwork(prefix string) *net.IPNet {
+	if ip := net.ParseIP(prefix); ip != nil {
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+	if _, network, err := net.ParseCIDR(prefix); err == nil {
+		return network
+	}
+	return nil
+}
+
+// prefixWithin reports whether narrow lies inside wide. Default routes
+// cover everything and aren't worth warning about.
+func prefixWithin(narrow, wide string) bool {
+	n, w := routeNetwork(narrow), routeNetwork(wide)
+	if n == nil || w == nil || len(n.IP) != len(w.IP) {
+		return false
+	}
+	nOnes, _ := n.Mask.Size()
+	wOnes, _ := w.Mask.Size()
+	return wOnes > 0 && nOnes > wOnes && w.Contains(n.IP)
+}
+
+// ParseStaticRouteImport reads the routes of an import, either a CSV file
+// with a header row naming its columns or a JSON document of the form
+// {"routes": [...]}
+func ParseStaticRouteImport(r io.Reader, format string) ([]*models.StaticRoute, error) {
+	var routes []*models.StaticRoute
+	switch format {
+	case RouteImportJSON:
+		var doc struct {
+			Routes []*models.StaticRoute `json:"routes"`
+		}
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON route import: %w", err)
+		}
+		routes = doc.Routes
+	case RouteImportCSV:
+		var err error
+		if routes, err = parseRouteCSV(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported route import format %q", format)
+	}
+
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("route import contains no routes")
+	}
+	if len(routes) > MaxImportedRoutes {
+		return nil, fmt.Errorf("route import contains %d routes, at most %d are allowed", len(routes), MaxImportedRoutes)
+	}
+	for i, route := range routes {
+		if route == nil || route.IPPrefix == "" || route.Nexthop == "" {
+			return nil, fmt.Errorf("route %d: ip_prefix and nexthop are required", i)
+		}
+	}
+	return routes, nil
+}
+
+func parseRouteCSV(r io.Reader) ([]*models.StaticRoute, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV route import: missing header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range routeImportColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid CSV route import: unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, required := range routeImportColumns[:2] {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid CSV route import: missing %s column", required)
+		}
+	}
+
+	var routes []*models.StaticRoute
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV route import: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		route := &models.StaticRoute{
+			IPPrefix:   field("ip_prefix"),
+			Nexthop:    field("nexthop"),
+			RouteTable: field("route_table"),
+		}
+		if v := field("output_port"); v != "" {
+			route.OutputPort = &v
+		}
+		if v := field("policy"); v != "" {
+			route.Policy = &v
+		}
+		for name, target := range map[string]**bool{"bfd": &route.BFD, "ecmp_symmetric_reply": &route.ECMPSymmetricReply} {
+			v := field(name)
+			if v == "" {
+				continue
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				line, _ := reader.FieldPos(columns[name])
+				return nil, fmt.Errorf("invalid CSV route import: line %d: %s must be true or false", line, name)
+			}
+			*target = &b
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestGroupECMPRoutes(t *testing.T) {
+	srcIP := "src-ip"
+	routes := []*models.StaticRoute{
+		{UUID: "r1", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.1"},
+		{UUID: "r2", IPPrefix: "10.1.0.0/16", Nexthop: "10.0.0.1"},
+		{UUID: "r3", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.2"},
+		{UUID: "r4", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.3", Policy: &srcIP},
+		{UUID: "r5", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.4", RouteTable: "rtb-1"},
+	}
+
+	groups := GroupECMPRoutes(routes)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "0.0.0.0/0", groups[0].IPPrefix)
+	assert.Equal(t, "dst-ip", groups[0].Policy)
+	assert.Equal(t, []string{"172.16.0.1", "172.16.0.2"}, groups[0].Nexthops)
+	assert.Equal(t, []string{"r1", "r3"}, groups[0].Routes)
+}
+
+func TestStaticRouteWarnings(t *testing.T) {
+	existing := []*models.StaticRoute{
+		{UUID: "r1", IPPrefix: "10.0.0.0/8", Nexthop: "172.16.0.1", BFD: boolPtr(true)},
+		{UUID: "r2", IPPrefix: "0.0.0.0/0", Nexthop: "172.16.0.254"},
+	}
+
+	t.Run("ECMP member without BFD", func(t *testing.T) {
+		added := []*models.StaticRoute{{IPPrefix: "10.0.0.0/8", Nexthop: "172.16.0.2"}}
+		warnings := StaticRouteWarnings(existing, added)
+		require.Len(t, warnings, 2)
+		assert.Contains(t, warnings[0], "joins an ECMP group with 1 existing route(s)")
+		assert.Contains(t, warnings[1], "with and without BFD")
+	})
+
+	t.Run("overlapping prefix", func(t *testing.T) {
+		added := []*models.StaticRoute{{IPPrefix: "10.1.2.3", Nexthop: "172.16.0.3"}}
+		warnings := StaticRouteWarnings(existing, added)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "route 10.1.2.3 via 172.16.0.3 overlaps route 10.0.0.0/8 via 172.16.0.1; traffic to 10.1.2.3 takes the more specific route", warnings[0])
+	})
+
+	t.Run("unrelated route", func(t *testing.T) {
+		added := []*models.StaticRoute{{IPPrefix: "192.168.0.0/16", Nexthop: "172.16.0.3"}}
+		assert.Empty(t, StaticRouteWarnings(existing, added))
+	})
+
+	t.Run("whole router", func(t *testing.T) {
+		routes := append(existing, &models.StaticRoute{IPPrefix: "10.0.0.0/8", Nexthop: "172.16.0.2", BFD: boolPtr(true), ECMPSymmetricReply: boolPtr(true)})
+		warnings := StaticRouteWarnings(nil, routes)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "ecmp_symmetric_reply")
+	})
+}
+
+func TestParseStaticRouteImport(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		input := "ip_prefix,nexthop,output_port,bfd\n" +
+			"# uplinks\n" +
+			"0.0.0.0/0, 172.16.0.1, lrp-uplink1, true\n" +
+			"10.1.0.0/16,10.0.0.1,,\n"
+		routes, err := ParseStaticRouteImport(strings.NewReader(input), RouteImportCSV)
+		require.NoError(t, err)
+		require.Len(t, routes, 2)
+		assert.Equal(t, "172.16.0.1", routes[0].Nexthop)
+		require.NotNil(t, routes[0].OutputPort)
+		assert.Equal(t, "lrp-uplink1", *routes[0].OutputPort)
+		assert.True(t, *routes[0].BFD)
+		assert.Nil(t, routes[1].OutputPort)
+		assert.Nil(t, routes[1].BFD)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		input := `{"routes":[{"ip_prefix":"0.0.0.0/0","nexthop":"172.16.0.1","ecmp_symmetric_reply":true}]}`
+		routes, err := ParseStaticRouteImport(strings.NewReader(input), RouteImportJSON)
+		require.NoError(t, err)
+		require.Len(t, routes, 1)
+		assert.True(t, *routes[0].ECMPSymmetricReply)
+	})
+
+	errorTests := []struct {
+		name   string
+		input  string
+		format string
+		want   string
+	}{
+		{"unknown column", "ip_prefix,nexthop,metric\n", RouteImportCSV, `unknown column "metric"`},
+		{"missing nexthop column", "ip_prefix\n10.0.0.0/8\n", RouteImportCSV, "missing nexthop column"},
+		{"bad boolean", "ip_prefix,nexthop,bfd\n10.0.0.0/8,10.0.0.1,yes\n", RouteImportCSV, "line 2: bfd must be true or false"},
+		{"missing nexthop", `{"routes":[{"ip_prefix":"10.0.0.0/8"}]}`, RouteImportJSON, "ip_prefix and nexthop are required"},
+		{"no routes", `{"routes":[]}`, RouteImportJSON, "contains no routes"},
+		{"unsupported format", "", "xml", "unsupported route import format"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStaticRouteImport(strings.NewReader(tt.input), tt.format)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
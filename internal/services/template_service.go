@@ -17,6 +17,7 @@ import (
 type TemplateService struct {
 	library    *templates.PolicyTemplateLibrary
 	ovnService OVNServiceInterface
+	catalog    *ServiceCatalog // nil leaves service references unexpanded
 	logger     *zap.Logger
 }
 
@@ -29,6 +30,12 @@ func NewTemplateService(ovnService OVNServiceInterface, logger *zap.Logger) *Tem
 	}
 }
 
+// SetServiceCatalog expands service(name) references in the rules
+// generated from templates with catalog
+func (s *TemplateService) SetServiceCatalog(catalog *ServiceCatalog) {
+	s.catalog = catalog
+}
+
 // TemplateInstance represents an instantiated template with variables filled
 type TemplateInstance struct {
 	TemplateID  string                 `json:"template_id"`
@@ -67,7 +74,7 @@ func (s *TemplateService) SearchTemplates(tags []string) []*templates.PolicyTemp
 }
 
 // ValidateTemplate validates template variables
-func (s *TemplateService) ValidateTemplate(templateID string, variables map[string]interface{}) (*TemplateValidationResult, error) {
+func (s *TemplateService) ValidateTemplate(ctx context.Context, templateID string, variables map[string]interface{}) (*TemplateValidationResult, error) {
 	template, err := s.library.GetTemplate(templateID)
 	if err != nil {
 		return nil, fmt.Errorf("template not found: %w", err)
@@ -101,7 +108,7 @@ func (s *TemplateService) ValidateTemplate(templateID string, variables map[stri
 
 	// Generate preview if validation passes
 	if result.Valid {
-		preview, err := s.generateRules(template, variables)
+		preview, err := s.generateRules(ctx, template, variables)
 		if err != nil {
 			result.Valid = false
 			result.Errors["_generation"] = err.Error()
@@ -121,7 +128,7 @@ func (s *TemplateService) InstantiateTemplate(ctx context.Context, templateID st
 	}
 
 	// Validate template
-	validation, err := s.ValidateTemplate(templateID, variables)
+	validation, err := s.ValidateTemplate(ctx, templateID, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +138,7 @@ func (s *TemplateService) InstantiateTemplate(ctx context.Context, templateID st
 	}
 
 	// Generate rules
-	rules, err := s.generateRules(template, variables)
+	rules, err := s.generateRules(ctx, template, variables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate rules: %w", err)
 	}
@@ -161,7 +168,7 @@ func (s *TemplateService) InstantiateTemplate(ctx context.Context, templateID st
 }
 
 // generateRules generates ACL rules from template
-func (s *TemplateService) generateRules(template *templates.PolicyTemplate, variables map[string]interface{}) ([]*models.ACL, error) {
+func (s *TemplateService) generateRules(ctx context.Context, template *templates.PolicyTemplate, variables map[string]interface{}) ([]*models.ACL, error) {
 	var rules []*models.ACL
 
 	for _, templateRule := range template.Rules {
@@ -183,12 +190,25 @@ func (s *TemplateService) generateRules(template *templates.PolicyTemplate, vari
 			Match:       match,
 			Action:      templateRule.Action,
 			Log:         templateRule.Log,
+			Owner:       models.ACLOwnerTemplate,
 			ExternalIDs: map[string]string{
 				"template":    template.ID,
 				"description": templateRule.Description,
 			},
 		}
 
+		// Expand service references, keeping the match they were written in
+		if s.catalog != nil {
+			expanded, err := s.catalog.ExpandMatch(ctx, match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to expand match for rule %s: %w", templateRule.Name, err)
+			}
+			if expanded != match {
+				acl.ExternalIDs[models.ACLMatchSourceKey] = match
+				acl.Match = expanded
+			}
+		}
+
 		rules = append(rules, acl)
 	}
 
@@ -257,6 +277,11 @@ func (s *TemplateService) processTemplate(templateStr string, variables map[stri
 
 // validateVariable validates a single variable
 func (s *TemplateService) validateVariable(varDef templates.TemplateVariable, value interface{}) error {
+	return validateTemplateVariable(varDef, value)
+}
+
+// validateTemplateVariable checks a value against its variable definition
+func validateTemplateVariable(varDef templates.TemplateVariable, value interface{}) error {
 	switch varDef.Type {
 	case "string":
 		if _, ok := value.(string); !ok {
@@ -327,6 +352,15 @@ func (s *TemplateService) validateVariable(varDef templates.TemplateVariable, va
 		if !isValidMAC(str) {
 			return fmt.Errorf("invalid MAC address: %s", str)
 		}
+
+	case "service":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string for service, got %T", value)
+		}
+		if !serviceNamePattern.MatchString(str) {
+			return fmt.Errorf("invalid service name: %s", str)
+		}
 	}
 
 	// Run custom validation if specified
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 	"github.com/lspecian/ovncp/internal/templates"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,6 +22,11 @@ func (m *MockOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.Log
 	return args.Get(0).([]*models.LogicalSwitch), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]*models.LogicalSwitch), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*models.LogicalSwitch), args.Error(1)
@@ -46,6 +52,11 @@ func (m *MockOVNService) ListLogicalRouters(ctx context.Context) ([]*models.Logi
 	return args.Get(0).([]*models.LogicalRouter), args.Error(1)
 }
 
+func (m *MockOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]*models.LogicalRouter), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*models.LogicalRouter), args.Error(1)
@@ -71,6 +82,11 @@ func (m *MockOVNService) ListPorts(ctx context.Context, switchID string) ([]*mod
 	return args.Get(0).([]*models.LogicalSwitchPort), args.Error(1)
 }
 
+func (m *MockOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	return args.Get(0).([]*models.LogicalSwitchPort), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*models.LogicalSwitchPort), args.Error(1)
@@ -96,6 +112,11 @@ func (m *MockOVNService) ListACLs(ctx context.Context, switchID string) ([]*mode
 	return args.Get(0).([]*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	args := m.Called(ctx, switchID, opts)
+	return args.Get(0).([]*models.ACL), args.Int(1), args.Error(2)
+}
+
 func (m *MockOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*models.ACL), args.Error(1)
@@ -109,6 +130,14 @@ func (m *MockOVNService) CreateACL(ctx context.Context, switchID string, acl *mo
 	return args.Get(0).(*models.ACL), args.Error(1)
 }
 
+func (m *MockOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	args := m.Called(ctx, switchID, acls)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
 func (m *MockOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
 	args := m.Called(ctx, id, acl)
 	return args.Get(0).(*models.ACL), args.Error(1)
@@ -119,6 +148,347 @@ func (m *MockOVNService) DeleteACL(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]*models.LoadBalancer), args.Int(1), args.Error(2)
+}
+
+func (m *MockOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	args := m.Called(ctx, id, lb)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoadBalancer), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PortGroup), args.Error(1)
+}
+
+func (m *MockOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AddressSet), args.Error(1)
+}
+
+func (m *MockOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	args := m.Called(ctx, portGroupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACL), args.Error(1)
+}
+
+func (m *MockOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	args := m.Called(ctx, owner, addressSets, portGroups)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ovn.OwnedObjects), args.Error(1)
+}
+
+func (m *MockOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	args := m.Called(ctx, id, dns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DNS), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteDNS(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	args := m.Called(ctx, id, mirror)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Mirror), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteMirror(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	args := m.Called(ctx, id, collector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SampleCollector), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	args := m.Called(ctx, appType, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SamplingApp), args.Error(1)
+}
+
+func (m *MockOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	args := m.Called(ctx, aclID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	args := m.Called(ctx, switchID, sampling)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ACLSampling), args.Error(1)
+}
+
+func (m *MockOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, routerID, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	args := m.Called(ctx, id, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RouterPolicy), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	args := m.Called(ctx, routerID, routes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	args := m.Called(ctx, id, route)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaticRoute), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	args := m.Called(ctx, routerID, port)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LogicalRouterPort), args.Error(1)
+}
+
+func (m *MockOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	args := m.Called(ctx, portID, binding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Gateway), args.Error(1)
+}
+
+func (m *MockOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	args := m.Called(ctx, priorities)
+	return args.Error(0)
+}
+
+func (m *MockOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	args := m.Called(ctx, resource, id, set, remove)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *MockOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
 	args := m.Called(ctx, ops)
 	return args.Error(0)
@@ -207,7 +577,7 @@ func TestTemplateService_ValidateTemplate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := service.ValidateTemplate(tt.templateID, tt.variables)
+			result, err := service.ValidateTemplate(context.Background(), tt.templateID, tt.variables)
 			
 			if tt.expectError {
 				assert.Error(t, err)
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrTenantChangeNotFound is returned for unknown change IDs and for
+	// changes of another tenant
+	ErrTenantChangeNotFound = errors.New("change not found")
+
+	// ErrInvalidTenantChange is wrapped by validation errors of the
+	// operations of a change
+	ErrInvalidTenantChange = errors.New("invalid change")
+
+	// ErrTenantChangeStatus is wrapped by errors for reviews the change's
+	// status doesn't allow
+	ErrTenantChangeStatus = errors.New("change status does not allow this action")
+
+	// ErrTenantChangeSelfReview is returned when the requester reviews a change
+	ErrTenantChangeSelfReview = errors.New("changes cannot be reviewed by their requester")
+)
+
+// TenantChangeStore persists tenant changes. *db.DB implements it.
+type TenantChangeStore interface {
+	CreateTenantChange(ctx context.Context, change *models.TenantChange) error
+
+	// GetTenantChange returns a change, nil when there's none with the ID
+	GetTenantChange(ctx context.Context, changeID string) (*models.TenantChange, error)
+
+	UpdateTenantChange(ctx context.Context, change *models.TenantChange) error
+
+	// ListTenantChanges returns a tenant's changes, all of them if status
+	// is empty
+	ListTenantChanges(ctx context.Context, tenantID string, status models.TenantChangeStatus) ([]*models.TenantChange, error)
+}
+
+// TenantLookup returns tenants and memberships. *TenantService implements it.
+type TenantLookup interface {
+	GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error)
+	GetMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error)
+}
+
+// TenantChangeService holds the writes of non-admin members of tenants that
+// require approval until a tenant admin approves or rejects them. Approved
+// changes run through the transaction engine, so a change applies in full
+// or not at all.
+type TenantChangeService struct {
+	store      TenantChangeStore
+	tenants    TenantLookup
+	ovnService OVNServiceInterface
+	logger     *zap.Logger
+
+	// mu serializes reviews so a change is executed at most once
+	mu sync.Mutex
+}
+
+// NewTenantChangeService creates a new tenant change service
+func NewTenantChangeService(store TenantChangeStore, tenants TenantLookup, ovnService OVNServiceInterface, logger *zap.Logger) *TenantChangeService {
+	return &TenantChangeService{
+		store:      store,
+		tenants:    tenants,
+		ovnService: ovnService,
+		logger:     logger,
+	}
+}
+
+// RequiresApproval reports whether writes by userID to the tenant's
+// resources must be approved first. Tenant admins never need approval.
+func (s *TenantChangeService) RequiresApproval(ctx context.Context, tenantID, userID string) (bool, error) {
+	required, err := s.TenantRequiresApproval(ctx, tenantID)
+	if err != nil || !required {
+		return false, err
+	}
+
+	membership, err := s.tenants.GetMembership(ctx, tenantID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return membership.Role != "admin", nil
+}
+
+// TenantRequiresApproval reports whether the tenant requires writes by
+// non-admins to be approved
+func (s *TenantChangeService) TenantRequiresApproval(ctx context.Context, tenantID string) (bool, error) {
+	tenant, err := s.tenants.GetTenant(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant.Settings.RequireApproval, nil
+}
+
+// RequestChange records a change pending approval. The change runs against
+// the OVN cluster selected in ctx when it is approved.
+func (s *TenantChangeService) RequestChange(ctx context.Context, change *models.TenantChange) (*models.TenantChange, error) {
+	if len(change.Operations) == 0 {
+		return nil, fmt.Errorf("%w: at least one operation is required", ErrInvalidTenantChange)
+	}
+	if len(change.Operations) > models.MaxTransactionOperations {
+		return nil, fmt.Errorf("%w: too many operations, maximum is %d", ErrInvalidTenantChange, models.MaxTransactionOperations)
+	}
+	if err := ValidateTransactionOperations(change.Operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTenantChange, err)
+	}
+
+	now := time.Now()
+	change.ID = uuid.New().String()
+	change.Status = models.TenantChangeStatusPending
+	change.Cluster = getOVNClusterFromContext(ctx)
+	change.CreatedAt = now
+	change.UpdatedAt = now
+
+	if err := s.store.CreateTenantChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to create change: %w", err)
+	}
+
+	s.logger.Info("Change requested",
+		zap.String("tenant_id", change.TenantID),
+		zap.String("change_id", change.ID),
+		zap.String("requested_by", change.RequestedBy),
+		zap.Int("operations", len(change.Operations)))
+
+	return change, nil
+}
+
+// GetChange returns one of a tenant's changes
+func (s *TenantChangeService) GetChange(ctx context.Context, tenantID, changeID string) (*models.TenantChange, error) {
+	change, err := s.store.GetTenantChange(ctx, changeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant change: %w", err)
+	}
+	if change == nil || change.TenantID != tenantID {
+		return nil, ErrTenantChangeNotFound
+	}
+	return change, nil
+}
+
+// ListChanges returns a tenant's changes, optionally only those with status
+func (s *TenantChangeService) ListChanges(ctx context.Context, tenantID string, status models.TenantChangeStatus) ([]*models.TenantChange, error) {
+	return s.store.ListTenantChanges(ctx, tenantID, status)
+}
+
+// ApproveChange approves a pending change and executes it. A change whose
+// execution failed before reaching OVN, for example because OVN was
+// unreachable, stays approved and is executed again by approving it again.
+func (s *TenantChangeService) ApproveChange(ctx context.Context, tenantID, changeID, reviewer, comment string) (*models.TenantChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change, err := s.reviewable(ctx, tenantID, changeID, reviewer, models.TenantChangeStatusPending, models.TenantChangeStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Status == models.TenantChangeStatusPending {
+		s.review(change, models.TenantChangeStatusApproved, reviewer, comment)
+		if err := s.store.UpdateTenantChange(ctx, change); err != nil {
+			return nil, fmt.Errorf("failed to update change: %w", err)
+		}
+	}
+
+	// Run as the tenant, on the cluster the change was requested for
+	execCtx := ContextWithTenant(ctx, change.TenantID)
+	if change.Cluster != "" {
+		execCtx = ContextWithOVNCluster(execCtx, change.Cluster)
+	}
+
+	response, err := RunTransaction(execCtx, s.ovnService, change.Operations)
+	if err != nil {
+		s.logger.Error("Failed to execute approved change",
+			zap.String("tenant_id", change.TenantID),
+			zap.String("change_id", change.ID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	change.Result = response
+	change.Status = models.TenantChangeStatusExecuted
+	if !response.Success {
+		change.Status = models.TenantChangeStatusFailed
+	}
+	change.UpdatedAt = time.Now()
+	if err := s.store.UpdateTenantChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to update change: %w", err)
+	}
+
+	s.logger.Info("Change approved",
+		zap.String("tenant_id", change.TenantID),
+		zap.String("change_id", change.ID),
+		zap.String("reviewed_by", reviewer),
+		zap.String("status", string(change.Status)))
+
+	return change, nil
+}
+
+// RejectChange rejects a pending change
+func (s *TenantChangeService) RejectChange(ctx context.Context, tenantID, changeID, reviewer, comment string) (*models.TenantChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change, err := s.reviewable(ctx, tenantID, changeID, reviewer, models.TenantChangeStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	s.review(change, models.TenantChangeStatusRejected, reviewer, comment)
+	if err := s.store.UpdateTenantChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to update change: %w", err)
+	}
+
+	s.logger.Info("Change rejected",
+		zap.String("tenant_id", change.TenantID),
+		zap.String("change_id", change.ID),
+		zap.String("reviewed_by", reviewer))
+
+	return change, nil
+}
+
+// reviewable returns the change if reviewer may review it in one of statuses
+func (s *TenantChangeService) reviewable(ctx context.Context, tenantID, changeID, reviewer string, statuses ...models.TenantChangeStatus) (*models.TenantChange, error) {
+	change, err := s.GetChange(ctx, tenantID, changeID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, status := range statuses {
+		if change.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: change is %s", ErrTenantChangeStatus, change.Status)
+	}
+
+	if reviewer != "" && reviewer == change.RequestedBy {
+		return nil, ErrTenantChangeSelfReview
+	}
+	return change, nil
+}
+
+func (s *TenantChangeService) review(change *models.TenantChange, status models.TenantChangeStatus, reviewer, comment string) {
+	now := time.Now()
+	change.Status = status
+	change.ReviewedBy = reviewer
+	change.ReviewComment = comment
+	change.ReviewedAt = &now
+	change.UpdatedAt = now
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// memoryTenantChangeStore keeps tenant changes in a map
+type memoryTenantChangeStore struct {
+	changes map[string]models.TenantChange
+}
+
+func (s *memoryTenantChangeStore) CreateTenantChange(ctx context.Context, change *models.TenantChange) error {
+	s.changes[change.ID] = *change
+	return nil
+}
+
+func (s *memoryTenantChangeStore) GetTenantChange(ctx context.Context, changeID string) (*models.TenantChange, error) {
+	change, ok := s.changes[changeID]
+	if !ok {
+		return nil, nil
+	}
+	return &change, nil
+}
+
+func (s *memoryTenantChangeStore) UpdateTenantChange(ctx context.Context, change *models.TenantChange) error {
+	s.changes[change.ID] = *change
+	return nil
+}
+
+func (s *memoryTenantChangeStore) ListTenantChanges(ctx context.Context, tenantID string, status models.TenantChangeStatus) ([]*models.TenantChange, error) {
+	var changes []*models.TenantChange
+	for _, change := range s.changes {
+		if change.TenantID == tenantID && (status == "" || change.Status == status) {
+			change := change
+			changes = append(changes, &change)
+		}
+	}
+	return changes, nil
+}
+
+// staticTenantLookup serves one tenant whose members are given by role
+type staticTenantLookup struct {
+	tenant *models.Tenant
+	roles  map[string]string
+}
+
+func (l *staticTenantLookup) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	if tenantID != l.tenant.ID {
+		return nil, errors.New("tenant not found")
+	}
+	return l.tenant, nil
+}
+
+func (l *staticTenantLookup) GetMembership(ctx context.Context, tenantID, userID string) (*models.TenantMembership, error) {
+	role, ok := l.roles[userID]
+	if !ok || tenantID != l.tenant.ID {
+		return nil, errors.New("membership not found")
+	}
+	return &models.TenantMembership{TenantID: tenantID, UserID: userID, Role: role}, nil
+}
+
+func newTestTenantChangeService(mockService *MockOVNService, requireApproval bool) *TenantChangeService {
+	lookup := &staticTenantLookup{
+		tenant: &models.Tenant{ID: "acme", Settings: models.TenantSettings{RequireApproval: requireApproval}},
+		roles:  map[string]string{"alice": "member", "bob": "admin"},
+	}
+	store := &memoryTenantChangeStore{changes: make(map[string]models.TenantChange)}
+	return NewTenantChangeService(store, lookup, mockService, zap.NewNop())
+}
+
+func switchChange(requestedBy string) *models.TenantChange {
+	return &models.TenantChange{
+		TenantID:    "acme",
+		RequestedBy: requestedBy,
+		Operations: []models.TransactionOperation{
+			{ID: "switch", Type: models.OperationCreate, Resource: models.ResourceSwitch, Data: map[string]interface{}{"name": "web"}},
+		},
+	}
+}
+
+func TestTenantChangeService_RequiresApproval(t *testing.T) {
+	ctx := context.Background()
+
+	service := newTestTenantChangeService(new(MockOVNService), true)
+	required, err := service.RequiresApproval(ctx, "acme", "alice")
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	required, err = service.RequiresApproval(ctx, "acme", "bob")
+	require.NoError(t, err)
+	assert.False(t, required, "tenant admins don't need approval")
+
+	_, err = service.RequiresApproval(ctx, "acme", "mallory")
+	assert.Error(t, err)
+
+	service = newTestTenantChangeService(new(MockOVNService), false)
+	required, err = service.RequiresApproval(ctx, "acme", "alice")
+	require.NoError(t, err)
+	assert.False(t, required)
+}
+
+func TestTenantChangeService_Approve(t *testing.T) {
+	ctx := ContextWithOVNCluster(context.Background(), "east")
+	mockService := new(MockOVNService)
+	service := newTestTenantChangeService(mockService, true)
+
+	change, err := service.RequestChange(ctx, switchChange("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantChangeStatusPending, change.Status)
+	assert.Equal(t, "east", change.Cluster)
+
+	_, err = service.ApproveChange(ctx, "acme", change.ID, "alice", "")
+	assert.ErrorIs(t, err, ErrTenantChangeSelfReview)
+
+	_, err = service.ApproveChange(ctx, "other", change.ID, "bob", "")
+	assert.ErrorIs(t, err, ErrTenantChangeNotFound)
+
+	// The change runs as the tenant on the cluster it was requested for
+	mockService.On("ExecuteTransaction", mock.MatchedBy(func(ctx context.Context) bool {
+		return getTenantFromContext(ctx) == "acme" && getOVNClusterFromContext(ctx) == "east"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		ops[0].ResourceID = "switch-uuid"
+	}).Return(nil).Once()
+
+	change, err = service.ApproveChange(context.Background(), "acme", change.ID, "bob", "ship it")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantChangeStatusExecuted, change.Status)
+	assert.Equal(t, "bob", change.ReviewedBy)
+	assert.Equal(t, "ship it", change.ReviewComment)
+	require.NotNil(t, change.Result)
+	assert.Equal(t, "switch-uuid", change.Result.Results[0].ResourceID)
+
+	// Executed changes can't be reviewed again
+	_, err = service.ApproveChange(ctx, "acme", change.ID, "bob", "")
+	assert.ErrorIs(t, err, ErrTenantChangeStatus)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTenantChangeService_ApproveRetriesUnreachableOVN(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockOVNService)
+	service := newTestTenantChangeService(mockService, true)
+
+	change, err := service.RequestChange(ctx, switchChange("alice"))
+	require.NoError(t, err)
+
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(errors.New("client not connected")).Once()
+	_, err = service.ApproveChange(ctx, "acme", change.ID, "bob", "")
+	assert.Error(t, err)
+
+	change, err = service.GetChange(ctx, "acme", change.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantChangeStatusApproved, change.Status)
+
+	// A failed operation fails the change
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(&ovn.TxError{Index: 0, Err: errors.New("switch web already exists")}).Once()
+	change, err = service.ApproveChange(ctx, "acme", change.ID, "bob", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantChangeStatusFailed, change.Status)
+	assert.False(t, change.Result.Success)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTenantChangeService_Reject(t *testing.T) {
+	ctx := context.Background()
+	service := newTestTenantChangeService(new(MockOVNService), true)
+
+	change, err := service.RequestChange(ctx, switchChange("alice"))
+	require.NoError(t, err)
+
+	change, err = service.RejectChange(ctx, "acme", change.ID, "bob", "use the shared switch")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantChangeStatusRejected, change.Status)
+
+	_, err = service.ApproveChange(ctx, "acme", change.ID, "bob", "")
+	assert.ErrorIs(t, err, ErrTenantChangeStatus)
+
+	pending, err := service.ListChanges(ctx, "acme", models.TenantChangeStatusPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rejected, err := service.ListChanges(ctx, "acme", models.TenantChangeStatusRejected)
+	require.NoError(t, err)
+	assert.Len(t, rejected, 1)
+}
+
+func TestTenantChangeService_RequestValidation(t *testing.T) {
+	service := newTestTenantChangeService(new(MockOVNService), true)
+
+	change := switchChange("alice")
+	change.Operations[0].Data = nil
+	_, err := service.RequestChange(context.Background(), change)
+	assert.ErrorIs(t, err, ErrInvalidTenantChange)
+	assert.Contains(t, err.Error(), "data is required")
+
+	change.Operations = nil
+	_, err = service.RequestChange(context.Background(), change)
+	assert.ErrorIs(t, err, ErrInvalidTenantChange)
+}
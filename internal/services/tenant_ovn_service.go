@@ -2,19 +2,37 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
 )
 
+// ErrResourceNotInTenant is returned for resources that aren't associated
+// with the caller's tenant. It reads as "not found" so that other tenants'
+// resources can't be told apart from missing ones.
+var ErrResourceNotInTenant = errors.New("resource not found in tenant")
+
+// TenantResources holds tenants' quotas and resource associations.
+// *TenantService implements it.
+type TenantResources interface {
+	GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error)
+	CheckQuota(ctx context.Context, tenantID, resourceType string, count int) error
+	AssociateResource(ctx context.Context, tenantID, resourceID, resourceType string) error
+	DissociateResource(ctx context.Context, resourceID string) error
+	GetResourceTenant(ctx context.Context, resourceID string) (string, error)
+}
+
 // TenantOVNService wraps OVNService with tenant filtering
 type TenantOVNService struct {
 	ovnService    OVNServiceInterface
-	tenantService *TenantService
+	tenantService TenantResources
 }
 
 // NewTenantOVNService creates a new tenant-aware OVN service
-func NewTenantOVNService(ovnService OVNServiceInterface, tenantService *TenantService) *TenantOVNService {
+func NewTenantOVNService(ovnService OVNServiceInterface, tenantService TenantResources) *TenantOVNService {
 	return &TenantOVNService{
 		ovnService:    ovnService,
 		tenantService: tenantService,
@@ -45,6 +63,30 @@ func (s *TenantOVNService) ListLogicalSwitches(ctx context.Context) ([]*models.L
 	return filtered, nil
 }
 
+func (s *TenantOVNService) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListLogicalSwitchesPage(ctx, opts)
+	}
+
+	// Tenant ownership isn't recorded in OVN, so filter and sort there and
+	// page after narrowing to the tenant's resources
+	switches, _, err := s.ovnService.ListLogicalSwitchesPage(ctx, unpaged(opts))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var filtered []*models.LogicalSwitch
+	for _, sw := range switches {
+		if s.belongsToTenant(ctx, sw.UUID, tenantID) {
+			filtered = append(filtered, sw)
+		}
+	}
+
+	start, end := opts.Bounds(len(filtered))
+	return filtered[start:end], len(filtered), nil
+}
+
 // GetLogicalSwitch checks tenant ownership before returning
 func (s *TenantOVNService) GetLogicalSwitch(ctx context.Context, id string) (*models.LogicalSwitch, error) {
 	sw, err := s.ovnService.GetLogicalSwitch(ctx, id)
@@ -63,7 +105,7 @@ func (s *TenantOVNService) GetLogicalSwitch(ctx context.Context, id string) (*mo
 func (s *TenantOVNService) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwitch) (*models.LogicalSwitch, error) {
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenant context required")
+		return s.ovnService.CreateLogicalSwitch(ctx, ls)
 	}
 
 	// Check quota
@@ -164,6 +206,30 @@ func (s *TenantOVNService) ListLogicalRouters(ctx context.Context) ([]*models.Lo
 	return filtered, nil
 }
 
+func (s *TenantOVNService) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListLogicalRoutersPage(ctx, opts)
+	}
+
+	// Tenant ownership isn't recorded in OVN, so filter and sort there and
+	// page after narrowing to the tenant's resources
+	routers, _, err := s.ovnService.ListLogicalRoutersPage(ctx, unpaged(opts))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var filtered []*models.LogicalRouter
+	for _, router := range routers {
+		if s.belongsToTenant(ctx, router.UUID, tenantID) {
+			filtered = append(filtered, router)
+		}
+	}
+
+	start, end := opts.Bounds(len(filtered))
+	return filtered[start:end], len(filtered), nil
+}
+
 func (s *TenantOVNService) GetLogicalRouter(ctx context.Context, id string) (*models.LogicalRouter, error) {
 	router, err := s.ovnService.GetLogicalRouter(ctx, id)
 	if err != nil {
@@ -180,7 +246,7 @@ func (s *TenantOVNService) GetLogicalRouter(ctx context.Context, id string) (*mo
 func (s *TenantOVNService) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRouter) (*models.LogicalRouter, error) {
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenant context required")
+		return s.ovnService.CreateLogicalRouter(ctx, lr)
 	}
 
 	// Check quota
@@ -259,15 +325,25 @@ func (s *TenantOVNService) ListPorts(ctx context.Context, switchID string) ([]*m
 	return s.ovnService.ListPorts(ctx, switchID)
 }
 
+func (s *TenantOVNService) ListPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
+	// Check switch ownership first
+	if err := s.checkTenantAccess(ctx, switchID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.ovnService.ListPortsPage(ctx, switchID, opts)
+}
+
 func (s *TenantOVNService) GetPort(ctx context.Context, id string) (*models.LogicalSwitchPort, error) {
 	port, err := s.ovnService.GetPort(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if the parent switch belongs to tenant
-	// In real implementation, would need to track switch-port relationships
-	
+	if err := s.checkTenantAccess(ctx, port.UUID); err != nil {
+		return nil, err
+	}
+
 	return port, nil
 }
 
@@ -279,7 +355,7 @@ func (s *TenantOVNService) CreatePort(ctx context.Context, switchID string, port
 
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenant context required")
+		return s.ovnService.CreatePort(ctx, switchID, port)
 	}
 
 	// Check quota
@@ -353,6 +429,15 @@ func (s *TenantOVNService) ListACLs(ctx context.Context, switchID string) ([]*mo
 	return s.ovnService.ListACLs(ctx, switchID)
 }
 
+func (s *TenantOVNService) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
+	// Check switch ownership first
+	if err := s.checkTenantAccess(ctx, switchID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.ovnService.ListACLsPage(ctx, switchID, opts)
+}
+
 func (s *TenantOVNService) GetACL(ctx context.Context, id string) (*models.ACL, error) {
 	acl, err := s.ovnService.GetACL(ctx, id)
 	if err != nil {
@@ -374,7 +459,7 @@ func (s *TenantOVNService) CreateACL(ctx context.Context, switchID string, acl *
 
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		return nil, fmt.Errorf("tenant context required")
+		return s.ovnService.CreateACL(ctx, switchID, acl)
 	}
 
 	// Check quota
@@ -401,6 +486,48 @@ func (s *TenantOVNService) CreateACL(ctx context.Context, switchID string, acl *
 	return created, nil
 }
 
+func (s *TenantOVNService) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
+	// Check switch ownership
+	if err := s.checkTenantAccess(ctx, switchID); err != nil {
+		return nil, err
+	}
+
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.CreateACLs(ctx, switchID, acls)
+	}
+
+	// Check quota for the whole batch
+	if err := s.tenantService.CheckQuota(ctx, tenantID, "acl", len(acls)); err != nil {
+		return nil, err
+	}
+
+	// Add tenant external ID
+	for _, acl := range acls {
+		if acl.ExternalIDs == nil {
+			acl.ExternalIDs = make(map[string]string)
+		}
+		acl.ExternalIDs["tenant_id"] = tenantID
+	}
+
+	created, err := s.ovnService.CreateACLs(ctx, switchID, acls)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, acl := range created {
+		if err := s.tenantService.AssociateResource(ctx, tenantID, acl.UUID, "acl"); err != nil {
+			// Roll back the whole batch, as it was created as a whole
+			for _, c := range created {
+				s.ovnService.DeleteACL(ctx, c.UUID)
+			}
+			return nil, fmt.Errorf("failed to associate ACL %d with tenant: %w", i, err)
+		}
+	}
+
+	return created, nil
+}
+
 func (s *TenantOVNService) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
 	if err := s.checkTenantAccess(ctx, id); err != nil {
 		return nil, err
@@ -439,89 +566,927 @@ func (s *TenantOVNService) DeleteACL(ctx context.Context, id string) error {
 
 // Helper functions
 
-func (s *TenantOVNService) checkTenantAccess(ctx context.Context, resourceID string) error {
+// Load Balancer operations
+
+func (s *TenantOVNService) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		// No tenant context, allow access (backward compatibility)
-		return nil
+		return s.ovnService.ListLoadBalancers(ctx)
 	}
 
-	resourceTenant, err := s.tenantService.GetResourceTenant(ctx, resourceID)
+	lbs, err := s.ovnService.ListLoadBalancers(ctx)
 	if err != nil {
-		// Resource not associated with any tenant
-		return nil
+		return nil, err
 	}
 
-	if resourceTenant != tenantID {
-		return fmt.Errorf("access denied: resource belongs to different tenant")
+	var filtered []*models.LoadBalancer
+	for _, lb := range lbs {
+		if s.belongsToTenant(ctx, lb.UUID, tenantID) {
+			filtered = append(filtered, lb)
+		}
 	}
 
-	return nil
+	return filtered, nil
 }
 
-func (s *TenantOVNService) belongsToTenant(ctx context.Context, resourceID, tenantID string) bool {
-	resourceTenant, err := s.tenantService.GetResourceTenant(ctx, resourceID)
+func (s *TenantOVNService) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListLoadBalancersPage(ctx, opts)
+	}
+
+	// Tenant ownership isn't recorded in OVN, so filter and sort there and
+	// page after narrowing to the tenant's resources
+	lbs, _, err := s.ovnService.ListLoadBalancersPage(ctx, unpaged(opts))
 	if err != nil {
-		// Resource not associated with any tenant
-		return false
+		return nil, 0, err
 	}
 
-	return resourceTenant == tenantID
+	var filtered []*models.LoadBalancer
+	for _, lb := range lbs {
+		if s.belongsToTenant(ctx, lb.UUID, tenantID) {
+			filtered = append(filtered, lb)
+		}
+	}
+
+	start, end := opts.Bounds(len(filtered))
+	return filtered[start:end], len(filtered), nil
 }
 
-func getTenantFromContext(ctx context.Context) string {
-	if tenant, ok := ctx.Value("tenant_id").(string); ok {
-		return tenant
+func (s *TenantOVNService) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	lb, err := s.ovnService.GetLoadBalancer(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	return ""
+
+	if err := s.checkTenantAccess(ctx, lb.UUID); err != nil {
+		return nil, err
+	}
+
+	return lb, nil
 }
 
-// ContextWithTenant returns a new context with tenant ID
-func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
-	return context.WithValue(ctx, "tenant_id", tenantID)
+func (s *TenantOVNService) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.CreateLoadBalancer(ctx, lb)
+	}
+
+	// Check quota
+	if err := s.tenantService.CheckQuota(ctx, tenantID, "load_balancer", 1); err != nil {
+		return nil, err
+	}
+
+	if lb.ExternalIDs == nil {
+		lb.ExternalIDs = make(map[string]string)
+	}
+	lb.ExternalIDs["tenant_id"] = tenantID
+
+	created, err := s.ovnService.CreateLoadBalancer(ctx, lb)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantService.AssociateResource(ctx, tenantID, created.UUID, "load_balancer"); err != nil {
+		s.ovnService.DeleteLoadBalancer(ctx, created.UUID)
+		return nil, fmt.Errorf("failed to associate load balancer with tenant: %w", err)
+	}
+
+	return created, nil
 }
 
-// ExecuteTransaction executes a transaction with tenant filtering
-func (s *TenantOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
-	// TODO: Add tenant validation for transaction operations
-	return s.ovnService.ExecuteTransaction(ctx, ops)
+func (s *TenantOVNService) UpdateLoadBalancer(ctx context.Context, id string, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	if err := s.checkTenantAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ovnService.GetLoadBalancer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID, ok := existing.ExternalIDs["tenant_id"]; ok {
+		if lb.ExternalIDs == nil {
+			lb.ExternalIDs = make(map[string]string)
+		}
+		lb.ExternalIDs["tenant_id"] = tenantID
+	}
+
+	return s.ovnService.UpdateLoadBalancer(ctx, id, lb)
 }
 
-// GetTopology returns the topology filtered by tenant
-func (s *TenantOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+func (s *TenantOVNService) DeleteLoadBalancer(ctx context.Context, id string) error {
+	if err := s.checkTenantAccess(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.ovnService.DeleteLoadBalancer(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.tenantService.DissociateResource(ctx, id); err != nil {
+		fmt.Printf("Failed to dissociate load balancer from tenant: %v\n", err)
+	}
+
+	return nil
+}
+
+func (s *TenantOVNService) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
 	tenantID := getTenantFromContext(ctx)
 	if tenantID == "" {
-		return s.ovnService.GetTopology(ctx)
+		return s.ovnService.ListPortGroups(ctx)
 	}
 
-	// Get full topology
-	topology, err := s.ovnService.GetTopology(ctx)
+	pgs, err := s.ovnService.ListPortGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter topology by tenant
-	filteredTopology := &Topology{
-		Switches:    []*models.LogicalSwitch{},
-		Routers:     []*models.LogicalRouter{},
-		Ports:       []*models.LogicalSwitchPort{},
-		RouterPorts: []*models.LogicalRouterPort{},
-		ACLs:        []*models.ACL{},
-		Connections: []Connection{},
-		Timestamp:   topology.Timestamp,
+	var filtered []*models.PortGroup
+	for _, pg := range pgs {
+		if s.belongsToTenant(ctx, pg.UUID, tenantID) {
+			filtered = append(filtered, pg)
+		}
 	}
 
-	// Filter switches
-	for _, sw := range topology.Switches {
-		if s.belongsToTenant(ctx, sw.UUID, tenantID) {
-			filteredTopology.Switches = append(filteredTopology.Switches, sw)
+	return filtered, nil
+}
+
+func (s *TenantOVNService) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListAddressSets(ctx)
+	}
+
+	sets, err := s.ovnService.ListAddressSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.AddressSet
+	for _, as := range sets {
+		if s.belongsToTenant(ctx, as.UUID, tenantID) {
+			filtered = append(filtered, as)
 		}
 	}
 
-	// Filter routers
-	for _, router := range topology.Routers {
-		if s.belongsToTenant(ctx, router.UUID, tenantID) {
-			filteredTopology.Routers = append(filteredTopology.Routers, router)
+	return filtered, nil
+}
+
+func (s *TenantOVNService) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	if err := s.checkTenantAccess(ctx, portGroupID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.ListPortGroupACLs(ctx, portGroupID)
+}
+
+// ReplaceOwnedObjects scopes the owner to the caller's tenant, so a tenant
+// can only replace objects it created, and moves the tenant's resource
+// associations from the replaced objects to the new ones
+func (s *TenantOVNService) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*ovn.PortGroupSpec) (*ovn.OwnedObjects, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ReplaceOwnedObjects(ctx, owner, addressSets, portGroups)
+	}
+
+	tenantOwner := map[string]string{"tenant_id": tenantID}
+	for k, v := range owner {
+		tenantOwner[k] = v
+	}
+
+	existingPGs, err := s.ovnService.ListPortGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingASs, err := s.ovnService.ListAddressSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var replaced []string
+	replacedPGs, replacedASs := 0, 0
+	for _, pg := range existingPGs {
+		if matchesExternalIDs(pg.ExternalIDs, tenantOwner) {
+			replaced = append(replaced, pg.UUID)
+			replacedPGs++
+		}
+	}
+	for _, as := range existingASs {
+		if matchesExternalIDs(as.ExternalIDs, tenantOwner) {
+			replaced = append(replaced, as.UUID)
+			replacedASs++
+		}
+	}
+
+	// Check quota for the objects this adds on top of the ones it replaces
+	if added := len(portGroups) - replacedPGs; added > 0 {
+		if err := s.tenantService.CheckQuota(ctx, tenantID, "port_group", added); err != nil {
+			return nil, err
+		}
+	}
+	if added := len(addressSets) - replacedASs; added > 0 {
+		if err := s.tenantService.CheckQuota(ctx, tenantID, "address_set", added); err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.ovnService.ReplaceOwnedObjects(ctx, tenantOwner, addressSets, portGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range replaced {
+		if err := s.tenantService.DissociateResource(ctx, id); err != nil {
+			fmt.Printf("Failed to dissociate resource from tenant: %v\n", err)
+		}
+	}
+	for _, pg := range created.PortGroups {
+		if err := s.tenantService.AssociateResource(ctx, tenantID, pg.UUID, "port_group"); err != nil {
+			return nil, fmt.Errorf("failed to associate port group with tenant: %w", err)
+		}
+	}
+	for _, as := range created.AddressSets {
+		if err := s.tenantService.AssociateResource(ctx, tenantID, as.UUID, "address_set"); err != nil {
+			return nil, fmt.Errorf("failed to associate address set with tenant: %w", err)
+		}
+	}
+
+	return created, nil
+}
+
+// DNS operations
+
+func (s *TenantOVNService) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListDNS(ctx)
+	}
+
+	records, err := s.ovnService.ListDNS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.DNS
+	for _, dns := range records {
+		if s.belongsToTenant(ctx, dns.UUID, tenantID) {
+			filtered = append(filtered, dns)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (s *TenantOVNService) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	dns, err := s.ovnService.GetDNS(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTenantAccess(ctx, dns.UUID); err != nil {
+		return nil, err
+	}
+
+	return dns, nil
+}
+
+// CreateDNS only attaches records to the tenant's own switches
+func (s *TenantOVNService) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.CreateDNS(ctx, dns)
+	}
+
+	for _, switchID := range dns.Switches {
+		if err := s.checkTenantAccess(ctx, switchID); err != nil {
+			return nil, err
+		}
+	}
+
+	if dns.ExternalIDs == nil {
+		dns.ExternalIDs = make(map[string]string)
+	}
+	dns.ExternalIDs["tenant_id"] = tenantID
+
+	created, err := s.ovnService.CreateDNS(ctx, dns)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantService.AssociateResource(ctx, tenantID, created.UUID, "dns"); err != nil {
+		s.ovnService.DeleteDNS(ctx, created.UUID)
+		return nil, fmt.Errorf("failed to associate DNS records with tenant: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *TenantOVNService) UpdateDNS(ctx context.Context, id string, dns *models.DNS) (*models.DNS, error) {
+	if err := s.checkTenantAccess(ctx, id); err != nil {
+		return nil, err
+	}
+	for _, switchID := range dns.Switches {
+		if err := s.checkTenantAccess(ctx, switchID); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.ovnService.GetDNS(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID, ok := existing.ExternalIDs["tenant_id"]; ok && dns.ExternalIDs != nil {
+		dns.ExternalIDs["tenant_id"] = tenantID
+	}
+
+	return s.ovnService.UpdateDNS(ctx, id, dns)
+}
+
+func (s *TenantOVNService) DeleteDNS(ctx context.Context, id string) error {
+	if err := s.checkTenantAccess(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.ovnService.DeleteDNS(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.tenantService.DissociateResource(ctx, id); err != nil {
+		fmt.Printf("Failed to dissociate DNS records from tenant: %v\n", err)
+	}
+
+	return nil
+}
+
+// Mirror operations. Tenants may only mirror their own ports.
+
+func (s *TenantOVNService) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListMirrors(ctx)
+	}
+
+	mirrors, err := s.ovnService.ListMirrors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.Mirror
+	for _, mirror := range mirrors {
+		if s.belongsToTenant(ctx, mirror.UUID, tenantID) {
+			filtered = append(filtered, mirror)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (s *TenantOVNService) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	mirror, err := s.ovnService.GetMirror(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTenantAccess(ctx, mirror.UUID); err != nil {
+		return nil, err
+	}
+
+	return mirror, nil
+}
+
+func (s *TenantOVNService) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.CreateMirror(ctx, mirror)
+	}
+
+	for _, portID := range mirror.Ports {
+		if err := s.checkTenantAccess(ctx, portID); err != nil {
+			return nil, err
+		}
+	}
+
+	if mirror.ExternalIDs == nil {
+		mirror.ExternalIDs = make(map[string]string)
+	}
+	mirror.ExternalIDs["tenant_id"] = tenantID
+
+	created, err := s.ovnService.CreateMirror(ctx, mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantService.AssociateResource(ctx, tenantID, created.UUID, "mirror"); err != nil {
+		s.ovnService.DeleteMirror(ctx, created.UUID)
+		return nil, fmt.Errorf("failed to associate mirror with tenant: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *TenantOVNService) UpdateMirror(ctx context.Context, id string, mirror *models.Mirror) (*models.Mirror, error) {
+	existing, err := s.GetMirror(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, portID := range mirror.Ports {
+		if err := s.checkTenantAccess(ctx, portID); err != nil {
+			return nil, err
+		}
+	}
+
+	if tenantID, ok := existing.ExternalIDs["tenant_id"]; ok && mirror.ExternalIDs != nil {
+		mirror.ExternalIDs["tenant_id"] = tenantID
+	}
+
+	return s.ovnService.UpdateMirror(ctx, existing.UUID, mirror)
+}
+
+func (s *TenantOVNService) DeleteMirror(ctx context.Context, id string) error {
+	existing, err := s.GetMirror(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ovnService.DeleteMirror(ctx, existing.UUID); err != nil {
+		return err
+	}
+
+	if err := s.tenantService.DissociateResource(ctx, existing.UUID); err != nil {
+		fmt.Printf("Failed to dissociate mirror from tenant: %v\n", err)
+	}
+
+	return nil
+}
+
+// Flow sampling operations. Collectors and observation domains are shared
+// by all tenants, so only RBAC limits who may change them; tenants may
+// only sample their own ACLs and switches.
+
+func (s *TenantOVNService) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	return s.ovnService.ListSampleCollectors(ctx)
+}
+
+func (s *TenantOVNService) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	return s.ovnService.GetSampleCollector(ctx, id)
+}
+
+func (s *TenantOVNService) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.ovnService.CreateSampleCollector(ctx, collector)
+}
+
+func (s *TenantOVNService) UpdateSampleCollector(ctx context.Context, id string, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	return s.ovnService.UpdateSampleCollector(ctx, id, collector)
+}
+
+func (s *TenantOVNService) DeleteSampleCollector(ctx context.Context, id string) error {
+	return s.ovnService.DeleteSampleCollector(ctx, id)
+}
+
+func (s *TenantOVNService) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	return s.ovnService.ListSamplingApps(ctx)
+}
+
+func (s *TenantOVNService) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	return s.ovnService.SetSamplingApp(ctx, appType, id)
+}
+
+func (s *TenantOVNService) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	if err := s.checkTenantAccess(ctx, aclID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.GetACLSampling(ctx, aclID)
+}
+
+func (s *TenantOVNService) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	if err := s.checkTenantAccess(ctx, aclID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.SetACLSampling(ctx, aclID, sampling)
+}
+
+func (s *TenantOVNService) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	if err := s.checkTenantAccess(ctx, switchID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.SetSwitchSampling(ctx, switchID, sampling)
+}
+
+// Router policy operations. Policies belong to the tenant of their router.
+
+func (s *TenantOVNService) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	// Check router ownership first
+	if _, err := s.GetLogicalRouter(ctx, routerID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.ListRouterPolicies(ctx, routerID)
+}
+
+func (s *TenantOVNService) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	policy, err := s.ovnService.GetRouterPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTenantAccess(ctx, policy.RouterID); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (s *TenantOVNService) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	// Check router ownership first
+	if _, err := s.GetLogicalRouter(ctx, routerID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.CreateRouterPolicy(ctx, routerID, policy)
+}
+
+func (s *TenantOVNService) UpdateRouterPolicy(ctx context.Context, id string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	if _, err := s.GetRouterPolicy(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.UpdateRouterPolicy(ctx, id, policy)
+}
+
+func (s *TenantOVNService) DeleteRouterPolicy(ctx context.Context, id string) error {
+	if _, err := s.GetRouterPolicy(ctx, id); err != nil {
+		return err
+	}
+
+	return s.ovnService.DeleteRouterPolicy(ctx, id)
+}
+
+func (s *TenantOVNService) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	// Check router ownership first
+	if _, err := s.GetLogicalRouter(ctx, routerID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.ListStaticRoutes(ctx, routerID)
+}
+
+func (s *TenantOVNService) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	route, err := s.ovnService.GetStaticRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTenantAccess(ctx, route.RouterID); err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+func (s *TenantOVNService) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	// Check router ownership first
+	if _, err := s.GetLogicalRouter(ctx, routerID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.CreateStaticRoutes(ctx, routerID, routes)
+}
+
+func (s *TenantOVNService) UpdateStaticRoute(ctx context.Context, id string, route *models.StaticRoute) (*models.StaticRoute, error) {
+	if _, err := s.GetStaticRoute(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.UpdateStaticRoute(ctx, id, route)
+}
+
+func (s *TenantOVNService) DeleteStaticRoute(ctx context.Context, id string) error {
+	if _, err := s.GetStaticRoute(ctx, id); err != nil {
+		return err
+	}
+
+	return s.ovnService.DeleteStaticRoute(ctx, id)
+}
+
+func (s *TenantOVNService) CreateRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	// Check router ownership first
+	if _, err := s.GetLogicalRouter(ctx, routerID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.CreateRouterPort(ctx, routerID, port)
+}
+
+func (s *TenantOVNService) DeleteRouterPort(ctx context.Context, id string) error {
+	if err := s.checkRouterPortAccess(ctx, id); err != nil {
+		return err
+	}
+
+	return s.ovnService.DeleteRouterPort(ctx, id)
+}
+
+func (s *TenantOVNService) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ListGateways(ctx)
+	}
+
+	gateways, err := s.ovnService.ListGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gateways belong to the tenant of their router
+	var filtered []*models.Gateway
+	for _, gateway := range gateways {
+		if s.belongsToTenant(ctx, gateway.RouterID, tenantID) {
+			filtered = append(filtered, gateway)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (s *TenantOVNService) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	if err := s.checkRouterPortAccess(ctx, portID); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.SetGatewayBinding(ctx, portID, binding)
+}
+
+func (s *TenantOVNService) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	for portID := range priorities {
+		if err := s.checkRouterPortAccess(ctx, portID); err != nil {
+			return err
+		}
+	}
+
+	return s.ovnService.SetGatewayPriorities(ctx, priorities)
+}
+
+// SetLabels checks tenant ownership before changing labels
+func (s *TenantOVNService) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	if err := s.checkTenantAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.ovnService.SetLabels(ctx, resource, id, set, remove)
+}
+
+// checkRouterPortAccess refuses router ports, given by UUID, of routers
+// outside the caller's tenant
+func (s *TenantOVNService) checkRouterPortAccess(ctx context.Context, portID string) error {
+	if getTenantFromContext(ctx) == "" {
+		return nil
+	}
+
+	routers, err := s.ovnService.ListLogicalRouters(ctx)
+	if err != nil {
+		return err
+	}
+	for _, router := range routers {
+		for _, port := range router.Ports {
+			if port == portID {
+				return s.checkTenantAccess(ctx, router.UUID)
+			}
+		}
+	}
+	return fmt.Errorf("%s: %w", portID, ErrResourceNotInTenant)
+}
+
+// checkTenantAccess refuses resources that aren't associated with the
+// caller's tenant, including ones not associated with any tenant
+func (s *TenantOVNService) checkTenantAccess(ctx context.Context, resourceID string) error {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		// No tenant context: unscoped deployments and the admin override
+		return nil
+	}
+
+	if !s.belongsToTenant(ctx, resourceID, tenantID) {
+		return fmt.Errorf("%s: %w", resourceID, ErrResourceNotInTenant)
+	}
+
+	return nil
+}
+
+func (s *TenantOVNService) belongsToTenant(ctx context.Context, resourceID, tenantID string) bool {
+	resourceTenant, err := s.tenantService.GetResourceTenant(ctx, resourceID)
+	if err != nil {
+		// Resource not associated with any tenant
+		return false
+	}
+
+	return resourceTenant == tenantID
+}
+
+// matchesExternalIDs reports whether externalIDs contains every key and
+// value in selector
+func matchesExternalIDs(externalIDs, selector map[string]string) bool {
+	for k, v := range selector {
+		if externalIDs[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// unpaged copies opts without its limit and offset, for listings that have
+// to be filtered further before paging
+func unpaged(opts *models.ListOptions) *models.ListOptions {
+	if opts == nil {
+		return nil
+	}
+	copied := *opts
+	copied.Limit = 0
+	copied.Offset = 0
+	return &copied
+}
+
+func getTenantFromContext(ctx context.Context) string {
+	if tenant, ok := ctx.Value("tenant_id").(string); ok {
+		return tenant
+	}
+	return ""
+}
+
+// ContextWithTenant returns a new context with tenant ID
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, "tenant_id", tenantID)
+}
+
+// ExecuteTransaction checks the tenant's quota for the resources the
+// transaction creates and associates them with the tenant once it commits
+func (s *TenantOVNService) ExecuteTransaction(ctx context.Context, ops []TransactionOp) error {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.ExecuteTransaction(ctx, ops)
+	}
+
+	if err := s.checkTransactionAccess(ctx, ops); err != nil {
+		return err
+	}
+
+	// Check quota for each type of resource created, in a stable order
+	creates := make(map[string]int)
+	var resourceTypes []string
+	for _, op := range ops {
+		if op.Operation != models.OperationCreate {
+			continue
+		}
+		resourceType := transactionResourceType(op)
+		if creates[resourceType] == 0 {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+		creates[resourceType]++
+	}
+	for _, resourceType := range resourceTypes {
+		if !isQuotaResourceType(resourceType) {
+			continue
+		}
+		if err := s.tenantService.CheckQuota(ctx, tenantID, resourceType, creates[resourceType]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.ovnService.ExecuteTransaction(ctx, ops); err != nil {
+		return err
+	}
+
+	var associated []string
+	for _, op := range ops {
+		if op.Operation != models.OperationCreate || op.ResourceID == "" {
+			continue
+		}
+		if err := s.tenantService.AssociateResource(ctx, tenantID, op.ResourceID, transactionResourceType(op)); err != nil {
+			// Roll back the whole transaction, as it was committed as a whole
+			for _, id := range associated {
+				s.tenantService.DissociateResource(ctx, id)
+			}
+			s.ovnService.ExecuteTransaction(ctx, rollbackCreates(ops))
+			return fmt.Errorf("failed to associate %s with tenant: %w", transactionResourceType(op), err)
+		}
+		associated = append(associated, op.ResourceID)
+	}
+
+	return nil
+}
+
+// checkTransactionAccess checks the caller's tenant owns the resources a
+// transaction updates or deletes, and the switches and routers it attaches
+// new resources to. References to resources created earlier in the
+// transaction are left to it.
+func (s *TenantOVNService) checkTransactionAccess(ctx context.Context, ops []TransactionOp) error {
+	for i, op := range ops {
+		ids := []string{op.SwitchID, op.RouterID}
+		if op.Operation != models.OperationCreate {
+			ids = append(ids, op.ResourceID)
+		}
+		for _, id := range ids {
+			if id == "" || strings.HasPrefix(id, "$") {
+				continue
+			}
+			if err := s.checkTenantAccess(ctx, id); err != nil {
+				return &ovn.TxError{Index: i, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// transactionResourceType returns the resource an operation acts on,
+// resolving the table names older clients use
+func transactionResourceType(op TransactionOp) string {
+	resourceType := op.ResourceType
+	if resourceType == "" {
+		resourceType = op.Table
+	}
+	if resource, ok := transactionResourceTypes[resourceType]; ok {
+		return resource
+	}
+	return resourceType
+}
+
+// rollbackCreates returns operations deleting the resources ops created,
+// newest first
+func rollbackCreates(ops []TransactionOp) []TransactionOp {
+	var rollback []TransactionOp
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].Operation == models.OperationCreate && ops[i].ResourceID != "" {
+			rollback = append(rollback, TransactionOp{
+				Operation:    models.OperationDelete,
+				ResourceType: transactionResourceType(ops[i]),
+				ResourceID:   ops[i].ResourceID,
+			})
+		}
+	}
+	return rollback
+}
+
+// GetTopology returns the topology filtered by tenant
+func (s *TenantOVNService) GetTopology(ctx context.Context) (*Topology, error) {
+	tenantID := getTenantFromContext(ctx)
+	if tenantID == "" {
+		return s.ovnService.GetTopology(ctx)
+	}
+
+	// Get full topology
+	topology, err := s.ovnService.GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter topology by tenant
+	filteredTopology := &Topology{
+		Switches:    []*models.LogicalSwitch{},
+		Routers:     []*models.LogicalRouter{},
+		Ports:       []*models.LogicalSwitchPort{},
+		RouterPorts: []*models.LogicalRouterPort{},
+		ACLs:        []*models.ACL{},
+		Connections: []Connection{},
+		Timestamp:   topology.Timestamp,
+	}
+
+	// Filter switches
+	for _, sw := range topology.Switches {
+		if s.belongsToTenant(ctx, sw.UUID, tenantID) {
+			filteredTopology.Switches = append(filteredTopology.Switches, sw)
+		}
+	}
+
+	// Filter routers
+	for _, router := range topology.Routers {
+		if s.belongsToTenant(ctx, router.UUID, tenantID) {
+			filteredTopology.Routers = append(filteredTopology.Routers, router)
+		}
+	}
+
+	// Keep the ports of the tenant's switches and routers
+	kept := make(map[string]bool)
+	for _, sw := range filteredTopology.Switches {
+		for _, port := range sw.Ports {
+			kept[port] = true
+		}
+	}
+	for _, router := range filteredTopology.Routers {
+		for _, port := range router.Ports {
+			kept[port] = true
+		}
+	}
+	for _, port := range topology.Ports {
+		if kept[port.UUID] {
+			filteredTopology.Ports = append(filteredTopology.Ports, port)
+		}
+	}
+	for _, port := range topology.RouterPorts {
+		if kept[port.UUID] {
+			filteredTopology.RouterPorts = append(filteredTopology.RouterPorts, port)
 		}
 	}
 
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// memoryTenantResources keeps resource associations in a map, with no quotas
+type memoryTenantResources struct {
+	owners map[string]string
+}
+
+func (r *memoryTenantResources) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	return &models.Tenant{ID: tenantID}, nil
+}
+
+func (r *memoryTenantResources) CheckQuota(ctx context.Context, tenantID, resourceType string, count int) error {
+	return nil
+}
+
+func (r *memoryTenantResources) AssociateResource(ctx context.Context, tenantID, resourceID, resourceType string) error {
+	r.owners[resourceID] = tenantID
+	return nil
+}
+
+func (r *memoryTenantResources) DissociateResource(ctx context.Context, resourceID string) error {
+	delete(r.owners, resourceID)
+	return nil
+}
+
+func (r *memoryTenantResources) GetResourceTenant(ctx context.Context, resourceID string) (string, error) {
+	tenantID, ok := r.owners[resourceID]
+	if !ok {
+		return "", errors.New("resource not associated")
+	}
+	return tenantID, nil
+}
+
+func newTestTenantOVNService(mockService *MockOVNService) *TenantOVNService {
+	resources := &memoryTenantResources{owners: map[string]string{
+		"sw-acme":  "acme",
+		"sw-other": "other",
+	}}
+	return NewTenantOVNService(mockService, resources)
+}
+
+func TestTenantOVNService_ListOnlyReturnsTenantResources(t *testing.T) {
+	mockService := new(MockOVNService)
+	service := newTestTenantOVNService(mockService)
+
+	mockService.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{
+		{UUID: "sw-acme"}, {UUID: "sw-other"}, {UUID: "sw-unowned"},
+	}, nil)
+
+	switches, err := service.ListLogicalSwitches(ContextWithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	require.Len(t, switches, 1)
+	assert.Equal(t, "sw-acme", switches[0].UUID)
+
+	// Without a tenant, as for the admin override, everything is listed
+	switches, err = service.ListLogicalSwitches(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, switches, 3)
+}
+
+func TestTenantOVNService_GetChecksTenant(t *testing.T) {
+	mockService := new(MockOVNService)
+	service := newTestTenantOVNService(mockService)
+	ctx := ContextWithTenant(context.Background(), "acme")
+
+	for _, id := range []string{"sw-acme", "sw-other", "sw-unowned"} {
+		mockService.On("GetLogicalSwitch", mock.Anything, id).Return(&models.LogicalSwitch{UUID: id}, nil)
+	}
+
+	sw, err := service.GetLogicalSwitch(ctx, "sw-acme")
+	require.NoError(t, err)
+	assert.Equal(t, "sw-acme", sw.UUID)
+
+	for _, id := range []string{"sw-other", "sw-unowned"} {
+		_, err = service.GetLogicalSwitch(ctx, id)
+		assert.ErrorIs(t, err, ErrResourceNotInTenant)
+		assert.Contains(t, err.Error(), "not found", "other tenants' resources look missing")
+	}
+
+	// Deleting another tenant's switch never reaches OVN
+	err = service.DeleteLogicalSwitch(ctx, "sw-other")
+	assert.ErrorIs(t, err, ErrResourceNotInTenant)
+	mockService.AssertNotCalled(t, "DeleteLogicalSwitch", mock.Anything, mock.Anything)
+
+	_, err = service.GetLogicalSwitch(context.Background(), "sw-other")
+	assert.NoError(t, err)
+}
+
+func TestTenantOVNService_TransactionChecksTenant(t *testing.T) {
+	mockService := new(MockOVNService)
+	service := newTestTenantOVNService(mockService)
+	ctx := ContextWithTenant(context.Background(), "acme")
+
+	// Attaching a port to another tenant's switch is refused
+	err := service.ExecuteTransaction(ctx, []TransactionOp{
+		{Operation: models.OperationDelete, ResourceType: models.ResourceSwitch, ResourceID: "sw-acme"},
+		{Operation: models.OperationCreate, ResourceType: models.ResourcePort, SwitchID: "sw-other", Data: map[string]interface{}{"name": "p1"}},
+	})
+	var txErr *ovn.TxError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, 1, txErr.Index)
+	assert.ErrorIs(t, err, ErrResourceNotInTenant)
+	mockService.AssertNotCalled(t, "ExecuteTransaction", mock.Anything, mock.Anything)
+
+	// Resources created in the transaction may be referred to
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		ops[0].ResourceID = "sw-new"
+		ops[1].ResourceID = "p-new"
+	}).Return(nil).Once()
+
+	err = service.ExecuteTransaction(ctx, []TransactionOp{
+		{Operation: models.OperationCreate, ResourceType: models.ResourceSwitch, Ref: "web", Data: map[string]interface{}{"name": "web"}},
+		{Operation: models.OperationCreate, ResourceType: models.ResourcePort, SwitchID: "$web", Data: map[string]interface{}{"name": "p1"}},
+	})
+	require.NoError(t, err)
+
+	owner, err := service.tenantService.GetResourceTenant(ctx, "p-new")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", owner)
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrTenantHasResources is returned when deleting a tenant that still
+	// owns resources without forcing it
+	ErrTenantHasResources = errors.New("tenant has active resources, cannot delete")
+
+	// ErrTenantDeletionNotFound is returned for tenants not being deleted
+	ErrTenantDeletionNotFound = errors.New("tenant deletion not found")
+)
+
+// TenantReclaimStore holds tenants, their resource associations and the
+// progress of their deletions. *db.DB implements it.
+type TenantReclaimStore interface {
+	GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error)
+	UpdateTenant(ctx context.Context, tenantID string, tenant *models.Tenant) error
+	DeleteTenant(ctx context.Context, tenantID string) error
+	ListTenantResources(ctx context.Context, tenantID string) ([]*models.TenantResource, error)
+	DeleteTenantResource(ctx context.Context, resourceID string) error
+
+	SaveTenantDeletion(ctx context.Context, deletion *models.TenantDeletion) error
+	// GetTenantDeletion returns nil for tenants never deleted
+	GetTenantDeletion(ctx context.Context, tenantID string) (*models.TenantDeletion, error)
+	ListTenantDeletions(ctx context.Context, status models.TenantDeletionStatus) ([]*models.TenantDeletion, error)
+}
+
+// tenantDeletionOrder lists resource types so that each is deleted before
+// the resources it refers to or is attached to. Types not listed aren't OVN
+// objects; their associations are removed last.
+var tenantDeletionOrder = []string{
+	models.ResourceACL,
+	models.ResourceQoS,
+	models.ResourceNAT,
+	models.ResourcePortGroup,
+	models.ResourceAddressSet,
+	"mirror",
+	models.ResourcePort,
+	models.ResourceDHCPOptions,
+	"dns",
+	models.ResourceLoadBalancer,
+	models.ResourceRouter,
+	models.ResourceSwitch,
+}
+
+// TenantReclaimer deletes tenants in the background, first deleting the OVN
+// objects associated with them. Deletions are recorded in the store and
+// carried out by the leader, so they survive restarts and failovers.
+type TenantReclaimer struct {
+	store      TenantReclaimStore
+	ovnService OVNServiceInterface
+	interval   time.Duration
+	logger     *zap.Logger
+
+	// wake starts the deletions recorded without waiting for the interval
+	wake chan struct{}
+}
+
+// NewTenantReclaimer creates a reclaimer looking for deletions to carry out
+// every interval
+func NewTenantReclaimer(store TenantReclaimStore, ovnService OVNServiceInterface, interval time.Duration, logger *zap.Logger) *TenantReclaimer {
+	return &TenantReclaimer{
+		store:      store,
+		ovnService: ovnService,
+		interval:   interval,
+		logger:     logger,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// DeleteTenant marks a tenant for deletion and records the deletion, which
+// the leader carries out. Unless force is set, tenants that still own
+// resources are refused. A failed deletion is resumed by deleting the
+// tenant again; deleting a tenant that is already being deleted returns its
+// progress.
+func (r *TenantReclaimer) DeleteTenant(ctx context.Context, tenantID string, force bool) (*models.TenantDeletion, error) {
+	deletion, err := r.store.GetTenantDeletion(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant deletion: %w", err)
+	}
+	if deletion != nil && deletion.Status == models.TenantDeletionStatusRunning {
+		return deletion, nil
+	}
+
+	tenant, err := r.store.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	resources, err := r.store.ListTenantResources(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant resources: %w", err)
+	}
+	if len(resources) > 0 && !force {
+		return nil, ErrTenantHasResources
+	}
+
+	tenant.Status = models.TenantStatusDeleting
+	tenant.UpdatedAt = time.Now()
+	if err := r.store.UpdateTenant(ctx, tenantID, tenant); err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	deletion = &models.TenantDeletion{
+		TenantID:  tenantID,
+		Status:    models.TenantDeletionStatusRunning,
+		Total:     len(resources),
+		Remaining: remainingTenantResources(resources),
+		StartedAt: time.Now(),
+	}
+	if err := r.store.SaveTenantDeletion(ctx, deletion); err != nil {
+		return nil, fmt.Errorf("failed to record tenant deletion: %w", err)
+	}
+
+	r.logger.Info("Tenant deletion started",
+		zap.String("tenant_id", tenantID),
+		zap.Int("resources", len(resources)),
+		zap.Bool("force", force))
+
+	// On the leader, the deletion starts right away; other replicas leave
+	// it to the leader's next look
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+
+	return deletion, nil
+}
+
+// GetDeletion returns the progress of a tenant's deletion
+func (r *TenantReclaimer) GetDeletion(ctx context.Context, tenantID string) (*models.TenantDeletion, error) {
+	deletion, err := r.store.GetTenantDeletion(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant deletion: %w", err)
+	}
+	if deletion == nil {
+		return nil, ErrTenantDeletionNotFound
+	}
+	return deletion, nil
+}
+
+// Run carries out the recorded deletions, those a restart or failover
+// interrupted included, one at a time, until ctx is done. It looks for them
+// when started, every interval, and whenever a deletion is started through
+// this replica. Deletions interrupted by ctx are left running for the next
+// leader to resume.
+func (r *TenantReclaimer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reclaimAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// reclaimAll carries out the deletions running
+func (r *TenantReclaimer) reclaimAll(ctx context.Context) {
+	deletions, err := r.store.ListTenantDeletions(ctx, models.TenantDeletionStatusRunning)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("Failed to list tenant deletions", zap.Error(err))
+		}
+		return
+	}
+	for _, deletion := range deletions {
+		if ctx.Err() != nil {
+			return
+		}
+		r.reclaim(ctx, deletion)
+	}
+}
+
+// reclaim deletes the resources left of a tenant, in deletion order, and
+// then the tenant, recording progress in deletion
+func (r *TenantReclaimer) reclaim(ctx context.Context, deletion *models.TenantDeletion) {
+	resources, err := r.store.ListTenantResources(ctx, deletion.TenantID)
+	if err != nil {
+		r.finish(ctx, deletion, fmt.Errorf("failed to list tenant resources: %w", err))
+		return
+	}
+	sortTenantResources(resources)
+	deletion.Remaining = remainingTenantResources(resources)
+
+	for _, resource := range resources {
+		if err := r.deleteResource(ctx, resource); err != nil {
+			r.finish(ctx, deletion, fmt.Errorf("failed to delete %s %s: %w", resource.ResourceType, resource.ResourceID, err))
+			return
+		}
+
+		deletion.Deleted++
+		deletion.Remaining[resource.ResourceType]--
+		if deletion.Remaining[resource.ResourceType] == 0 {
+			delete(deletion.Remaining, resource.ResourceType)
+		}
+		if err := r.store.SaveTenantDeletion(ctx, deletion); err != nil && ctx.Err() == nil {
+			r.logger.Warn("Failed to record tenant deletion progress",
+				zap.String("tenant_id", deletion.TenantID), zap.Error(err))
+		}
+	}
+
+	if err := r.store.DeleteTenant(ctx, deletion.TenantID); err != nil {
+		r.finish(ctx, deletion, fmt.Errorf("failed to delete tenant: %w", err))
+		return
+	}
+	r.finish(ctx, deletion, nil)
+}
+
+// deleteResource deletes a resource's OVN object and its association.
+// Objects already gone from OVN count as deleted.
+func (r *TenantReclaimer) deleteResource(ctx context.Context, resource *models.TenantResource) error {
+	if isTenantOVNResource(resource.ResourceType) {
+		if err := r.deleteObject(ctx, resource); err != nil && !strings.Contains(err.Error(), "not found") {
+			return err
+		}
+	}
+
+	return r.store.DeleteTenantResource(ctx, resource.ResourceID)
+}
+
+// deleteObject deletes a resource's OVN object, in a transaction unless
+// transactions don't handle its type
+func (r *TenantReclaimer) deleteObject(ctx context.Context, resource *models.TenantResource) error {
+	switch resource.ResourceType {
+	case "dns":
+		return r.ovnService.DeleteDNS(ctx, resource.ResourceID)
+	case "mirror":
+		return r.ovnService.DeleteMirror(ctx, resource.ResourceID)
+	}
+	return r.ovnService.ExecuteTransaction(ctx, []TransactionOp{{
+		Operation:    models.OperationDelete,
+		ResourceType: resource.ResourceType,
+		ResourceID:   resource.ResourceID,
+	}})
+}
+
+// finish records the outcome of a deletion. Deletions interrupted because
+// ctx is done are left running.
+func (r *TenantReclaimer) finish(ctx context.Context, deletion *models.TenantDeletion, err error) {
+	if ctx.Err() != nil {
+		r.logger.Info("Tenant deletion interrupted",
+			zap.String("tenant_id", deletion.TenantID),
+			zap.Int("deleted", deletion.Deleted),
+			zap.Int("total", deletion.Total))
+		return
+	}
+
+	now := time.Now()
+	deletion.CompletedAt = &now
+	if err != nil {
+		deletion.Status = models.TenantDeletionStatusFailed
+		deletion.Error = err.Error()
+		r.logger.Error("Tenant deletion failed",
+			zap.String("tenant_id", deletion.TenantID),
+			zap.Int("deleted", deletion.Deleted),
+			zap.Int("total", deletion.Total),
+			zap.Error(err))
+	} else {
+		deletion.Status = models.TenantDeletionStatusCompleted
+		r.logger.Info("Tenant deleted",
+			zap.String("tenant_id", deletion.TenantID),
+			zap.Int("resources", deletion.Total))
+	}
+
+	if err := r.store.SaveTenantDeletion(ctx, deletion); err != nil {
+		r.logger.Error("Failed to record tenant deletion outcome",
+			zap.String("tenant_id", deletion.TenantID), zap.Error(err))
+	}
+}
+
+// sortTenantResources puts resources in deletion order
+func sortTenantResources(resources []*models.TenantResource) {
+	rank := func(resourceType string) int {
+		for i, t := range tenantDeletionOrder {
+			if t == resourceType {
+				return i
+			}
+		}
+		return len(tenantDeletionOrder)
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		return rank(resources[i].ResourceType) < rank(resources[j].ResourceType)
+	})
+}
+
+func isTenantOVNResource(resourceType string) bool {
+	for _, t := range tenantDeletionOrder {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// remainingTenantResources counts resources by type
+func remainingTenantResources(resources []*models.TenantResource) map[string]int {
+	remaining := make(map[string]int)
+	for _, resource := range resources {
+		remaining[resource.ResourceType]++
+	}
+	return remaining
+}
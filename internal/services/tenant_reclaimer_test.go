@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryTenantReclaimStore keeps one tenant, its resources and deletions
+// in memory
+type memoryTenantReclaimStore struct {
+	mu        sync.Mutex
+	tenant    *models.Tenant
+	resources []*models.TenantResource
+	deleted   bool
+	deletions map[string]*models.TenantDeletion
+}
+
+func (s *memoryTenantReclaimStore) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deleted || tenantID != s.tenant.ID {
+		return nil, errors.New("tenant not found")
+	}
+	tenant := *s.tenant
+	return &tenant, nil
+}
+
+func (s *memoryTenantReclaimStore) UpdateTenant(ctx context.Context, tenantID string, tenant *models.Tenant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenant = tenant
+	return nil
+}
+
+func (s *memoryTenantReclaimStore) DeleteTenant(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = true
+	return nil
+}
+
+func (s *memoryTenantReclaimStore) ListTenantResources(ctx context.Context, tenantID string) ([]*models.TenantResource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*models.TenantResource(nil), s.resources...), nil
+}
+
+func (s *memoryTenantReclaimStore) DeleteTenantResource(ctx context.Context, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, resource := range s.resources {
+		if resource.ResourceID == resourceID {
+			s.resources = append(s.resources[:i], s.resources[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryTenantReclaimStore) SaveTenantDeletion(ctx context.Context, deletion *models.TenantDeletion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletions[deletion.TenantID] = copyTenantDeletion(deletion)
+	return nil
+}
+
+func (s *memoryTenantReclaimStore) GetTenantDeletion(ctx context.Context, tenantID string) (*models.TenantDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if deletion, ok := s.deletions[tenantID]; ok {
+		return copyTenantDeletion(deletion), nil
+	}
+	return nil, nil
+}
+
+func (s *memoryTenantReclaimStore) ListTenantDeletions(ctx context.Context, status models.TenantDeletionStatus) ([]*models.TenantDeletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deletions []*models.TenantDeletion
+	for _, deletion := range s.deletions {
+		if status == "" || deletion.Status == status {
+			deletions = append(deletions, copyTenantDeletion(deletion))
+		}
+	}
+	return deletions, nil
+}
+
+func copyTenantDeletion(deletion *models.TenantDeletion) *models.TenantDeletion {
+	c := *deletion
+	c.Remaining = make(map[string]int, len(deletion.Remaining))
+	for k, v := range deletion.Remaining {
+		c.Remaining[k] = v
+	}
+	return &c
+}
+
+func newTestReclaimStore() *memoryTenantReclaimStore {
+	return &memoryTenantReclaimStore{
+		tenant: &models.Tenant{ID: "acme", Status: models.TenantStatusActive},
+		resources: []*models.TenantResource{
+			{ResourceID: "sw1", ResourceType: models.ResourceSwitch, TenantID: "acme"},
+			{ResourceID: "lr1", ResourceType: models.ResourceRouter, TenantID: "acme"},
+			{ResourceID: "p1", ResourceType: models.ResourcePort, TenantID: "acme"},
+			{ResourceID: "acl1", ResourceType: models.ResourceACL, TenantID: "acme"},
+			{ResourceID: "bk1", ResourceType: "backup", TenantID: "acme"},
+		},
+		deletions: make(map[string]*models.TenantDeletion),
+	}
+}
+
+// newTestReclaimer returns a reclaimer running as the leader until the test
+// ends
+func newTestReclaimer(t *testing.T, store TenantReclaimStore, ovnService OVNServiceInterface) *TenantReclaimer {
+	reclaimer := NewTenantReclaimer(store, ovnService, time.Hour, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reclaimer.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return reclaimer
+}
+
+// waitForDeletion waits until the tenant's deletion finishes
+func waitForDeletion(t *testing.T, reclaimer *TenantReclaimer, tenantID string) *models.TenantDeletion {
+	var deletion *models.TenantDeletion
+	require.Eventually(t, func() bool {
+		var err error
+		deletion, err = reclaimer.GetDeletion(context.Background(), tenantID)
+		require.NoError(t, err)
+		return deletion.Status != models.TenantDeletionStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return deletion
+}
+
+func TestTenantReclaimer_RefusesTenantWithResources(t *testing.T) {
+	store := newTestReclaimStore()
+	reclaimer := newTestReclaimer(t, store, new(MockOVNService))
+
+	_, err := reclaimer.DeleteTenant(context.Background(), "acme", false)
+	assert.ErrorIs(t, err, ErrTenantHasResources)
+	assert.Equal(t, models.TenantStatusActive, store.tenant.Status)
+
+	_, err = reclaimer.GetDeletion(context.Background(), "acme")
+	assert.ErrorIs(t, err, ErrTenantDeletionNotFound)
+}
+
+func TestTenantReclaimer_ForceDeletesInDependencyOrder(t *testing.T) {
+	store := newTestReclaimStore()
+	mockService := new(MockOVNService)
+	reclaimer := newTestReclaimer(t, store, mockService)
+
+	var mu sync.Mutex
+	var order []string
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		mu.Lock()
+		order = append(order, ops[0].ResourceType+"/"+ops[0].ResourceID)
+		mu.Unlock()
+	}).Return(nil)
+
+	deletion, err := reclaimer.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+	assert.Equal(t, 5, deletion.Total)
+	assert.Equal(t, models.TenantStatusDeleting, store.tenant.Status)
+
+	deletion = waitForDeletion(t, reclaimer, "acme")
+	assert.Equal(t, models.TenantDeletionStatusCompleted, deletion.Status)
+	assert.Equal(t, 5, deletion.Deleted)
+	assert.Empty(t, deletion.Remaining)
+	assert.True(t, store.deleted)
+	assert.Empty(t, store.resources)
+
+	// Backups aren't OVN objects, so only their association is removed
+	assert.Equal(t, []string{"acl/acl1", "port/p1", "router/lr1", "switch/sw1"}, order)
+}
+
+func TestTenantReclaimer_DeletesObjectsOutsideTransactions(t *testing.T) {
+	store := newTestReclaimStore()
+	store.resources = append(store.resources,
+		&models.TenantResource{ResourceID: "dns1", ResourceType: "dns", TenantID: "acme"},
+		&models.TenantResource{ResourceID: "mirror1", ResourceType: "mirror", TenantID: "acme"})
+	mockService := new(MockOVNService)
+	reclaimer := newTestReclaimer(t, store, mockService)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(resource string) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			mu.Lock()
+			order = append(order, resource+"/"+args.String(1))
+			mu.Unlock()
+		}
+	}
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ops := args.Get(1).([]TransactionOp)
+		mu.Lock()
+		order = append(order, ops[0].ResourceType+"/"+ops[0].ResourceID)
+		mu.Unlock()
+	}).Return(nil)
+	mockService.On("DeleteDNS", mock.Anything, "dns1").Run(record("dns")).Return(nil)
+	mockService.On("DeleteMirror", mock.Anything, "mirror1").Run(record("mirror")).Return(nil)
+
+	_, err := reclaimer.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+
+	deletion := waitForDeletion(t, reclaimer, "acme")
+	assert.Equal(t, models.TenantDeletionStatusCompleted, deletion.Status)
+	assert.Empty(t, store.resources)
+	mockService.AssertExpectations(t)
+
+	// Mirrors go before the ports they mirror, and DNS records before the
+	// switches they're attached to
+	assert.Equal(t, []string{"acl/acl1", "mirror/mirror1", "port/p1", "dns/dns1", "router/lr1", "switch/sw1"}, order)
+}
+
+func TestTenantReclaimer_FailureIsResumable(t *testing.T) {
+	store := newTestReclaimStore()
+	mockService := new(MockOVNService)
+	reclaimer := newTestReclaimer(t, store, mockService)
+
+	// The ACL is already gone; the port can't be deleted yet
+	mockService.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		return ops[0].ResourceID == "acl1"
+	})).Return(errors.New("acl acl1 not found")).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		return ops[0].ResourceID == "p1"
+	})).Return(errors.New("client not connected")).Once()
+
+	_, err := reclaimer.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+
+	deletion := waitForDeletion(t, reclaimer, "acme")
+	assert.Equal(t, models.TenantDeletionStatusFailed, deletion.Status)
+	assert.Equal(t, 1, deletion.Deleted)
+	assert.Contains(t, deletion.Error, "failed to delete port p1")
+	assert.Equal(t, map[string]int{"port": 1, "router": 1, "switch": 1, "backup": 1}, deletion.Remaining)
+	assert.False(t, store.deleted)
+
+	// Deleting again picks up the remaining resources
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(nil)
+	deletion, err = reclaimer.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, deletion.Total)
+
+	deletion = waitForDeletion(t, reclaimer, "acme")
+	assert.Equal(t, models.TenantDeletionStatusCompleted, deletion.Status)
+	assert.True(t, store.deleted)
+}
+
+func TestTenantReclaimer_LeaderCarriesOutDeletions(t *testing.T) {
+	store := newTestReclaimStore()
+	mockService := new(MockOVNService)
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(nil)
+
+	// A replica that isn't the leader only records the deletion
+	replica := NewTenantReclaimer(store, mockService, time.Hour, zap.NewNop())
+	_, err := replica.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+	deletion, err := replica.GetDeletion(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantDeletionStatusRunning, deletion.Status)
+	mockService.AssertNotCalled(t, "ExecuteTransaction", mock.Anything, mock.Anything)
+
+	// which the leader carries out, and every replica reports
+	newTestReclaimer(t, store, mockService)
+	deletion = waitForDeletion(t, replica, "acme")
+	assert.Equal(t, models.TenantDeletionStatusCompleted, deletion.Status)
+	assert.Equal(t, 5, deletion.Deleted)
+	assert.True(t, store.deleted)
+}
+
+func TestTenantReclaimer_ResumesInterruptedDeletion(t *testing.T) {
+	store := newTestReclaimStore()
+	mockService := new(MockOVNService)
+	reclaimer := NewTenantReclaimer(store, mockService, time.Hour, zap.NewNop())
+
+	// Shutting down while the port is deleted interrupts the deletion
+	ctx, cancel := context.WithCancel(context.Background())
+	mockService.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		return ops[0].ResourceID == "acl1"
+	})).Return(nil).Once()
+	mockService.On("ExecuteTransaction", mock.Anything, mock.MatchedBy(func(ops []TransactionOp) bool {
+		return ops[0].ResourceID == "p1"
+	})).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(context.Canceled).Once()
+
+	_, err := reclaimer.DeleteTenant(context.Background(), "acme", true)
+	require.NoError(t, err)
+	reclaimer.Run(ctx)
+
+	deletion, err := reclaimer.GetDeletion(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, models.TenantDeletionStatusRunning, deletion.Status, "interrupted deletions aren't failed")
+	assert.Equal(t, 1, deletion.Deleted)
+	assert.False(t, store.deleted)
+
+	// The next leader resumes it
+	mockService.On("ExecuteTransaction", mock.Anything, mock.Anything).Return(nil)
+	leader := newTestReclaimer(t, store, mockService)
+	deletion = waitForDeletion(t, leader, "acme")
+	assert.Equal(t, models.TenantDeletionStatusCompleted, deletion.Status)
+	assert.Equal(t, 5, deletion.Deleted)
+	assert.True(t, store.deleted)
+}
@@ -14,14 +14,36 @@ import (
 	"go.uber.org/zap"
 )
 
+// QuotaExceededError is returned when creating resources would take a
+// tenant over its quota
+type QuotaExceededError struct {
+	ResourceType string `json:"resource_type"`
+	Current      int    `json:"current"`
+	Requested    int    `json:"requested"`
+	Limit        int    `json:"limit"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s (current: %d, limit: %d)", e.ResourceType, e.Current, e.Limit)
+}
+
+// isQuotaResourceType reports whether tenant quotas limit resourceType
+func isQuotaResourceType(resourceType string) bool {
+	switch resourceType {
+	case "switch", "router", "port", "acl", "load_balancer", "address_set", "port_group", "backup":
+		return true
+	}
+	return false
+}
+
 // TenantService handles tenant operations
 type TenantService struct {
-	db     *db.DB
+	db     db.TenantRepository
 	logger *zap.Logger
 }
 
 // NewTenantService creates a new tenant service
-func NewTenantService(database *db.DB, logger *zap.Logger) *TenantService {
+func NewTenantService(database db.TenantRepository, logger *zap.Logger) *TenantService {
 	return &TenantService{
 		db:     database,
 		logger: logger,
@@ -130,35 +152,6 @@ func (s *TenantService) UpdateTenant(ctx context.Context, tenantID string, updat
 	return existing, nil
 }
 
-// DeleteTenant marks a tenant for deletion
-func (s *TenantService) DeleteTenant(ctx context.Context, tenantID string) error {
-	// Check if tenant has resources
-	usage, err := s.GetResourceUsage(ctx, tenantID)
-	if err != nil {
-		return fmt.Errorf("failed to check resource usage: %w", err)
-	}
-
-	if s.hasActiveResources(usage) {
-		return fmt.Errorf("tenant has active resources, cannot delete")
-	}
-
-	// Mark tenant as deleting
-	updates := &models.Tenant{
-		Status: models.TenantStatusDeleting,
-	}
-
-	if _, err := s.UpdateTenant(ctx, tenantID, updates); err != nil {
-		return err
-	}
-
-	// TODO: Implement async deletion of tenant data
-
-	s.logger.Info("Tenant marked for deletion",
-		zap.String("tenant_id", tenantID))
-
-	return nil
-}
-
 // AddMember adds a user to a tenant
 func (s *TenantService) AddMember(ctx context.Context, tenantID, userID, role, addedBy string) error {
 	// Check if membership already exists
@@ -307,8 +300,12 @@ func (s *TenantService) CheckQuota(ctx context.Context, tenantID, resourceType s
 	}
 
 	if !tenant.Quotas.IsWithinQuota(resourceType, current, count) {
-		limit := s.getQuotaLimit(tenant.Quotas, resourceType)
-		return fmt.Errorf("quota exceeded: %s (current: %d, limit: %d)", resourceType, current, limit)
+		return &QuotaExceededError{
+			ResourceType: resourceType,
+			Current:      current,
+			Requested:    count,
+			Limit:        s.getQuotaLimit(tenant.Quotas, resourceType),
+		}
 	}
 
 	return nil
@@ -428,19 +425,23 @@ func (s *TenantService) AcceptInvitation(ctx context.Context, token, userID stri
 
 // CreateAPIKey creates a new API key for a tenant
 func (s *TenantService) CreateAPIKey(ctx context.Context, tenantID string, key *models.TenantAPIKey) (string, error) {
-	// Generate API key
-	keyBytes := make([]byte, 32)
-	if _, err := rand.Read(keyBytes); err != nil {
-		return "", fmt.Errorf("failed to generate key: %w", err)
+	if err := normalizeAPIKeySettings(key); err != nil {
+		return "", err
+	}
+
+	apiKey, secret, err := newAPIKey(tenantID)
+	if err != nil {
+		return "", err
+	}
+	keyHash, err := hashAPIKey(secret)
+	if err != nil {
+		return "", err
 	}
-	
-	apiKey := base64.URLEncoding.EncodeToString(keyBytes)
-	prefix := apiKey[:8]
 
 	key.ID = uuid.New().String()
 	key.TenantID = tenantID
-	key.Prefix = prefix
-	key.KeyHash = s.hashAPIKey(apiKey)
+	key.Prefix = secret[:apiKeyPrefixLen]
+	key.KeyHash = keyHash
 	key.CreatedAt = time.Now()
 
 	if err := s.db.CreateTenantAPIKey(ctx, key); err != nil {
@@ -448,37 +449,115 @@ func (s *TenantService) CreateAPIKey(ctx context.Context, tenantID string, key *
 	}
 
 	// Return the full key only once
-	return fmt.Sprintf("ovncp_%s_%s", tenantID[:8], apiKey), nil
+	return apiKey, nil
+}
+
+// RotateAPIKey issues a new secret for an API key. The old secret stays
+// valid for the grace period, so clients can switch over without downtime;
+// a zero grace period revokes it at once.
+func (s *TenantService) RotateAPIKey(ctx context.Context, tenantID, keyID string, grace time.Duration) (string, *models.TenantAPIKey, error) {
+	key, err := s.db.GetTenantAPIKey(ctx, keyID)
+	if err != nil || key.TenantID != tenantID {
+		return "", nil, ErrAPIKeyNotFound
+	}
+
+	apiKey, secret, err := newAPIKey(tenantID)
+	if err != nil {
+		return "", nil, err
+	}
+	keyHash, err := hashAPIKey(secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	key.PreviousKeyHash = ""
+	key.PreviousPrefix = ""
+	key.PreviousExpiresAt = nil
+	if grace > 0 {
+		previousExpiresAt := now.Add(grace)
+		key.PreviousKeyHash = key.KeyHash
+		key.PreviousPrefix = key.Prefix
+		key.PreviousExpiresAt = &previousExpiresAt
+	}
+	key.KeyHash = keyHash
+	key.Prefix = secret[:apiKeyPrefixLen]
+	key.RotatedAt = &now
+
+	if err := s.db.UpdateTenantAPIKey(ctx, key); err != nil {
+		return "", nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	s.logger.Info("API key rotated",
+		zap.String("tenant_id", tenantID),
+		zap.String("key_id", keyID),
+		zap.Duration("grace_period", grace))
+
+	return apiKey, key, nil
 }
 
 // ValidateAPIKey validates an API key and returns the associated tenant
 func (s *TenantService) ValidateAPIKey(ctx context.Context, apiKey string) (*models.TenantAPIKey, error) {
-	// Parse key format: ovncp_<tenant_prefix>_<key>
-	parts := strings.Split(apiKey, "_")
-	if len(parts) != 3 || parts[0] != "ovncp" {
+	// Parse key format: ovncp_<tenant_prefix>_<key>; the key itself may
+	// contain underscores
+	parts := strings.SplitN(apiKey, "_", 3)
+	if len(parts) != 3 || parts[0] != "ovncp" || len(parts[2]) < apiKeyPrefixLen {
 		return nil, fmt.Errorf("invalid API key format")
 	}
+	secret := parts[2]
 
-	keyHash := s.hashAPIKey(parts[2])
-	
-	key, err := s.db.GetTenantAPIKeyByHash(ctx, keyHash)
+	candidates, err := s.db.ListTenantAPIKeysByPrefix(ctx, secret[:apiKeyPrefixLen])
 	if err != nil {
 		return nil, fmt.Errorf("invalid API key")
 	}
 
+	now := time.Now()
+	var key *models.TenantAPIKey
+	var previous bool
+	for _, candidate := range candidates {
+		if !strings.HasPrefix(candidate.TenantID, parts[1]) {
+			continue
+		}
+		if matched, prev := matchAPIKey(candidate, secret, now); matched {
+			key, previous = candidate, prev
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
 	// Check expiration
-	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
 		return nil, fmt.Errorf("API key expired")
 	}
 
-	// Update last used
-	now := time.Now()
-	key.LastUsedAt = &now
-	if err := s.db.UpdateTenantAPIKey(ctx, key); err != nil {
-		s.logger.Error("Failed to update API key last used",
+	// Upgrade hashes stored before argon2id was used
+	if !previous && strings.HasPrefix(key.KeyHash, legacyAPIKeyHashPrefix) {
+		if keyHash, err := hashAPIKey(secret); err == nil {
+			key.KeyHash = keyHash
+			if err := s.db.UpdateTenantAPIKey(ctx, key); err != nil {
+				s.logger.Error("Failed to rehash API key",
+					zap.String("key_id", key.ID),
+					zap.Error(err))
+			}
+		}
+	}
+
+	// Count the use
+	if err := s.db.RecordTenantAPIKeyUse(ctx, key.ID, now); err != nil {
+		s.logger.Error("Failed to record API key use",
 			zap.String("key_id", key.ID),
 			zap.Error(err))
 	}
+	key.UsageCount++
+	key.LastUsedAt = &now
+
+	if previous {
+		s.logger.Warn("API key used with its rotated secret",
+			zap.String("key_id", key.ID),
+			zap.Timep("previous_expires_at", key.PreviousExpiresAt))
+	}
 
 	return key, nil
 }
@@ -522,16 +601,6 @@ func (s *TenantService) validateTenant(tenant *models.Tenant) error {
 	return nil
 }
 
-func (s *TenantService) hasActiveResources(usage *models.ResourceUsage) bool {
-	return usage.Switches > 0 ||
-		usage.Routers > 0 ||
-		usage.Ports > 0 ||
-		usage.ACLs > 0 ||
-		usage.LoadBalancers > 0 ||
-		usage.AddressSets > 0 ||
-		usage.PortGroups > 0
-}
-
 func (s *TenantService) getQuotaLimit(quotas models.TenantQuotas, resourceType string) int {
 	switch resourceType {
 	case "switch":
@@ -555,12 +624,6 @@ func (s *TenantService) getQuotaLimit(quotas models.TenantQuotas, resourceType s
 	}
 }
 
-func (s *TenantService) hashAPIKey(key string) string {
-	// In production, use bcrypt or similar
-	// This is simplified for demonstration
-	return fmt.Sprintf("hash_%s", key)
-}
-
 func isValidTenantName(name string) bool {
 	// Simple validation: alphanumeric, dash, underscore, 3-63 chars
 	if len(name) < 3 || len(name) > 63 {
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ErrInvalidUsageQuery is returned for usage history queries with an unknown
+// interval or an empty time range
+var ErrInvalidUsageQuery = errors.New("invalid usage query")
+
+// TenantUsageStore holds tenants' current usage and its recorded snapshots.
+// *db.DB implements it.
+type TenantUsageStore interface {
+	ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error)
+	GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error)
+	CreateUsageSnapshot(ctx context.Context, snapshot *models.TenantUsageSnapshot) error
+	ListUsageSnapshots(ctx context.Context, tenantID string, from, to time.Time) ([]*models.TenantUsageSnapshot, error)
+}
+
+// TenantUsageRecorder periodically snapshots the usage of active tenants and
+// reports it over time
+type TenantUsageRecorder struct {
+	store    TenantUsageStore
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	// Events are published when usage crosses quotaThreshold percent of a
+	// quota, and when it reaches the quota; overQuota and exhaustedQuota
+	// hold the tenant/resource pairs past each
+	events         EventPublisher
+	quotaThreshold int
+	overQuota      map[string]bool
+	exhaustedQuota map[string]bool
+}
+
+// NewTenantUsageRecorder creates a recorder taking a snapshot every interval
+func NewTenantUsageRecorder(store TenantUsageStore, interval time.Duration, logger *zap.Logger) *TenantUsageRecorder {
+	return &TenantUsageRecorder{
+		store:    store,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetEvents publishes an event when a snapshot finds a tenant's usage of a
+// resource crossing thresholdPercent of its quota upward, and another when
+// it reaches the quota
+func (r *TenantUsageRecorder) SetEvents(events EventPublisher, thresholdPercent int) {
+	r.events = events
+	r.quotaThreshold = thresholdPercent
+	r.overQuota = make(map[string]bool)
+	r.exhaustedQuota = make(map[string]bool)
+}
+
+// Run records snapshots until ctx is done. A zero interval disables it.
+func (r *TenantUsageRecorder) Run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RecordSnapshots(ctx); err != nil {
+				r.logger.Error("Failed to record tenant usage", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RecordSnapshots records the current usage of every active tenant. A tenant
+// whose usage can't be read is skipped.
+func (r *TenantUsageRecorder) RecordSnapshots(ctx context.Context) error {
+	tenants, err := r.store.ListTenants(ctx, &models.TenantFilter{Status: models.TenantStatusActive})
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	recordedAt := r.now().UTC()
+	for _, tenant := range tenants {
+		usage, err := r.store.GetResourceUsage(ctx, tenant.ID)
+		if err == nil {
+			err = r.store.CreateUsageSnapshot(ctx, &models.TenantUsageSnapshot{
+				TenantID:   tenant.ID,
+				Usage:      *usage,
+				RecordedAt: recordedAt,
+			})
+		}
+		if err != nil {
+			r.logger.Warn("Failed to record tenant usage",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+			continue
+		}
+		if r.events != nil {
+			r.checkQuotas(ctx, tenant, usage)
+		}
+	}
+
+	return nil
+}
+
+// checkQuotas publishes an event for each resource whose usage reached the
+// threshold, or the quota itself, since the last snapshot. Unlimited
+// resources are skipped.
+func (r *TenantUsageRecorder) checkQuotas(ctx context.Context, tenant *models.Tenant, usage *models.ResourceUsage) {
+	limits := tenant.Quotas.Limits()
+	for resource, count := range usage.Counts() {
+		limit := limits[resource]
+		if limit <= 0 {
+			continue
+		}
+		key := tenant.ID + "/" + resource
+		data := map[string]interface{}{
+			"resource": resource,
+			"usage":    count,
+			"limit":    limit,
+		}
+
+		over := count*100 >= limit*r.quotaThreshold
+		if over && !r.overQuota[key] {
+			thresholdData := map[string]interface{}{"threshold_percent": r.quotaThreshold}
+			for k, v := range data {
+				thresholdData[k] = v
+			}
+			r.events.Publish(ctx, &models.Event{
+				Type:     models.EventQuotaThreshold,
+				TenantID: tenant.ID,
+				Data:     thresholdData,
+			})
+		}
+		r.overQuota[key] = over
+
+		exhausted := count >= limit
+		if exhausted && !r.exhaustedQuota[key] {
+			r.events.Publish(ctx, &models.Event{
+				Type:     models.EventQuotaExhausted,
+				TenantID: tenant.ID,
+				Data:     data,
+			})
+		}
+		r.exhaustedQuota[key] = exhausted
+	}
+}
+
+// GetHistory buckets a tenant's snapshots taken in [from, to) by interval.
+// Periods without snapshots are left out.
+func (r *TenantUsageRecorder) GetHistory(ctx context.Context, tenantID string, interval models.UsageInterval, from, to time.Time) (*models.TenantUsageHistory, error) {
+	switch interval {
+	case models.UsageIntervalHour, models.UsageIntervalDay, models.UsageIntervalMonth:
+	default:
+		return nil, fmt.Errorf("%w: interval must be hour, day or month", ErrInvalidUsageQuery)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidUsageQuery)
+	}
+
+	snapshots, err := r.store.ListUsageSnapshots(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage snapshots: %w", err)
+	}
+
+	history := &models.TenantUsageHistory{
+		TenantID: tenantID,
+		Interval: interval,
+		From:     from,
+		To:       to,
+		Buckets:  []*models.UsageBucket{},
+	}
+
+	var bucket *models.UsageBucket
+	for _, snapshot := range snapshots {
+		start := usageBucketStart(snapshot.RecordedAt, interval)
+		if bucket == nil || !bucket.Start.Equal(start) {
+			bucket = &models.UsageBucket{
+				Start:   start,
+				End:     usageBucketEnd(start, interval),
+				Average: make(map[string]float64),
+				Peak:    make(map[string]int),
+			}
+			history.Buckets = append(history.Buckets, bucket)
+		}
+
+		bucket.Samples++
+		for resource, count := range snapshot.Usage.Counts() {
+			// Keep a running mean so buckets never hold their snapshots
+			bucket.Average[resource] += (float64(count) - bucket.Average[resource]) / float64(bucket.Samples)
+			if count > bucket.Peak[resource] {
+				bucket.Peak[resource] = count
+			}
+		}
+	}
+
+	return history, nil
+}
+
+func usageBucketStart(t time.Time, interval models.UsageInterval) time.Time {
+	t = t.UTC()
+	switch interval {
+	case models.UsageIntervalHour:
+		return t.Truncate(time.Hour)
+	case models.UsageIntervalDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func usageBucketEnd(start time.Time, interval models.UsageInterval) time.Time {
+	switch interval {
+	case models.UsageIntervalHour:
+		return start.Add(time.Hour)
+	case models.UsageIntervalDay:
+		return start.AddDate(0, 0, 1)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// WriteUsageHistoryCSV writes one row per bucket, with the average and peak
+// of each resource
+func WriteUsageHistoryCSV(w io.Writer, history *models.TenantUsageHistory) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"tenant_id", "start", "end", "samples"}
+	for _, resource := range models.UsageResourceTypes {
+		header = append(header, "avg_"+resource, "peak_"+resource)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, bucket := range history.Buckets {
+		row := []string{
+			history.TenantID,
+			bucket.Start.Format(time.RFC3339),
+			bucket.End.Format(time.RFC3339),
+			strconv.Itoa(bucket.Samples),
+		}
+		for _, resource := range models.UsageResourceTypes {
+			row = append(row,
+				strconv.FormatFloat(bucket.Average[resource], 'f', 2, 64),
+				strconv.Itoa(bucket.Peak[resource]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryTenantUsageStore serves fixed current usage and keeps snapshots
+type memoryTenantUsageStore struct {
+	tenants   []*models.Tenant // By default acme, and broken without usage
+	usage     map[string]*models.ResourceUsage
+	snapshots []*models.TenantUsageSnapshot
+}
+
+func (s *memoryTenantUsageStore) ListTenants(ctx context.Context, filter *models.TenantFilter) ([]*models.Tenant, error) {
+	if s.tenants != nil {
+		return s.tenants, nil
+	}
+	tenants := []*models.Tenant{{ID: "acme"}, {ID: "broken"}}
+	return tenants, nil
+}
+
+func (s *memoryTenantUsageStore) GetResourceUsage(ctx context.Context, tenantID string) (*models.ResourceUsage, error) {
+	usage, ok := s.usage[tenantID]
+	if !ok {
+		return nil, errors.New("usage not found")
+	}
+	return usage, nil
+}
+
+func (s *memoryTenantUsageStore) CreateUsageSnapshot(ctx context.Context, snapshot *models.TenantUsageSnapshot) error {
+	s.snapshots = append(s.snapshots, snapshot)
+	return nil
+}
+
+func (s *memoryTenantUsageStore) ListUsageSnapshots(ctx context.Context, tenantID string, from, to time.Time) ([]*models.TenantUsageSnapshot, error) {
+	var snapshots []*models.TenantUsageSnapshot
+	for _, snapshot := range s.snapshots {
+		if snapshot.TenantID == tenantID && !snapshot.RecordedAt.Before(from) && snapshot.RecordedAt.Before(to) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func TestTenantUsageRecorder_RecordSnapshots(t *testing.T) {
+	store := &memoryTenantUsageStore{usage: map[string]*models.ResourceUsage{
+		"acme": {TenantID: "acme", Switches: 2},
+	}}
+	recorder := NewTenantUsageRecorder(store, time.Hour, zap.NewNop())
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	// A tenant whose usage can't be read doesn't stop the others
+	require.NoError(t, recorder.RecordSnapshots(context.Background()))
+	require.Len(t, store.snapshots, 1)
+	assert.Equal(t, "acme", store.snapshots[0].TenantID)
+	assert.Equal(t, 2, store.snapshots[0].Usage.Switches)
+	assert.Equal(t, now, store.snapshots[0].RecordedAt)
+}
+
+// recordingPublisher keeps the events published
+type recordingPublisher struct {
+	events []*models.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *models.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestTenantUsageRecorder_QuotaThreshold(t *testing.T) {
+	quotas := models.UnlimitedQuotas()
+	quotas.MaxSwitches = 10
+	quotas.MaxPorts = 100
+	store := &memoryTenantUsageStore{
+		tenants: []*models.Tenant{{ID: "acme", Quotas: quotas}},
+		usage:   map[string]*models.ResourceUsage{"acme": {TenantID: "acme", Switches: 7, Ports: 10, ACLs: 5000}},
+	}
+	events := &recordingPublisher{}
+	recorder := NewTenantUsageRecorder(store, time.Hour, zap.NewNop())
+	recorder.SetEvents(events, 80)
+	ctx := context.Background()
+
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	assert.Empty(t, events.events, "unlimited resources are never over quota")
+
+	// Crossing the threshold is published once, until usage drops below it
+	store.usage["acme"].Switches = 8
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	store.usage["acme"].Switches = 9
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.EventQuotaThreshold, events.events[0].Type)
+	assert.Equal(t, "acme", events.events[0].TenantID)
+	assert.Equal(t, map[string]interface{}{
+		"resource": "switches", "usage": 8, "limit": 10, "threshold_percent": 80,
+	}, events.events[0].Data)
+
+	// Reaching the quota is published too
+	store.usage["acme"].Switches = 10
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	require.Len(t, events.events, 2)
+	assert.Equal(t, models.EventQuotaExhausted, events.events[1].Type)
+	assert.Equal(t, map[string]interface{}{
+		"resource": "switches", "usage": 10, "limit": 10,
+	}, events.events[1].Data)
+
+	store.usage["acme"].Switches = 5
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	store.usage["acme"].Switches = 10
+	require.NoError(t, recorder.RecordSnapshots(ctx))
+	require.Len(t, events.events, 4)
+	assert.Equal(t, models.EventQuotaThreshold, events.events[2].Type)
+	assert.Equal(t, models.EventQuotaExhausted, events.events[3].Type)
+}
+
+func TestTenantUsageRecorder_GetHistory(t *testing.T) {
+	store := &memoryTenantUsageStore{}
+	at := func(day, hour, switches int) *models.TenantUsageSnapshot {
+		return &models.TenantUsageSnapshot{
+			TenantID:   "acme",
+			Usage:      models.ResourceUsage{Switches: switches, Ports: switches * 10},
+			RecordedAt: time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC),
+		}
+	}
+	store.snapshots = []*models.TenantUsageSnapshot{at(1, 0, 1), at(1, 12, 3), at(3, 6, 4)}
+	recorder := NewTenantUsageRecorder(store, time.Hour, zap.NewNop())
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	history, err := recorder.GetHistory(context.Background(), "acme", models.UsageIntervalDay, from, to)
+	require.NoError(t, err)
+	require.Len(t, history.Buckets, 2, "days without snapshots are left out")
+
+	day := history.Buckets[0]
+	assert.Equal(t, from, day.Start)
+	assert.Equal(t, from.AddDate(0, 0, 1), day.End)
+	assert.Equal(t, 2, day.Samples)
+	assert.Equal(t, 2.0, day.Average["switches"])
+	assert.Equal(t, 3, day.Peak["switches"])
+	assert.Equal(t, 30, day.Peak["ports"])
+	assert.Equal(t, 4, history.Buckets[1].Peak["switches"])
+
+	history, err = recorder.GetHistory(context.Background(), "acme", models.UsageIntervalMonth, from, to)
+	require.NoError(t, err)
+	require.Len(t, history.Buckets, 1)
+	assert.Equal(t, to, history.Buckets[0].End)
+	assert.InDelta(t, 8.0/3, history.Buckets[0].Average["switches"], 1e-9)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteUsageHistoryCSV(&buf, history))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "tenant_id,start,end,samples,avg_switches,peak_switches,avg_routers"))
+	assert.True(t, strings.HasPrefix(lines[1], "acme,2024-03-01T00:00:00Z,2024-04-01T00:00:00Z,3,2.67,4,0.00,0"))
+}
+
+func TestTenantUsageRecorder_GetHistoryValidation(t *testing.T) {
+	recorder := NewTenantUsageRecorder(&memoryTenantUsageStore{}, time.Hour, zap.NewNop())
+	now := time.Now()
+
+	_, err := recorder.GetHistory(context.Background(), "acme", "week", now.Add(-time.Hour), now)
+	assert.ErrorIs(t, err, ErrInvalidUsageQuery)
+
+	_, err = recorder.GetHistory(context.Background(), "acme", models.UsageIntervalHour, now, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrInvalidUsageQuery)
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Node types of a topology diff
+const (
+	TopologyNodeSwitch     = "switch"
+	TopologyNodeRouter     = "router"
+	TopologyNodePort       = "port"
+	TopologyNodeRouterPort = "router_port"
+	TopologyNodeACL        = "acl"
+)
+
+// Edge types of a topology diff besides the topology's own connections
+const (
+	TopologyEdgeSwitchPort = "switch_port"
+	TopologyEdgeRouterPort = "router_port"
+	TopologyEdgeACL        = "acl"
+	TopologyEdgePatch      = "patch" // A router-type switch port to its router port
+)
+
+// TopologyDiff lists what changed between two topology states
+type TopologyDiff struct {
+	From    TopologyState    `json:"from"`
+	To      TopologyState    `json:"to"`
+	Nodes   TopologyNodeDiff `json:"nodes"`
+	Edges   TopologyEdgeDiff `json:"edges"`
+	Summary map[string]int   `json:"summary"`
+}
+
+// TopologyState identifies one side of a diff
+type TopologyState struct {
+	Source string    `json:"source"` // now, snapshot or backup
+	ID     string    `json:"id,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// TopologyNodeDiff lists the nodes added, removed and changed
+type TopologyNodeDiff struct {
+	Added   []TopologyNode       `json:"added"`
+	Removed []TopologyNode       `json:"removed"`
+	Changed []TopologyNodeChange `json:"changed"`
+}
+
+// TopologyNode is a switch, router, port, router port or ACL
+type TopologyNode struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// TopologyNodeChange is a node present in both states whose fields differ
+type TopologyNodeChange struct {
+	TopologyNode
+	Fields []string    `json:"fields"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TopologyEdgeDiff lists the edges added and removed. Edges have no fields
+// of their own, so they don't change.
+type TopologyEdgeDiff struct {
+	Added   []Connection `json:"added"`
+	Removed []Connection `json:"removed"`
+}
+
+// topologyGraph indexes a topology's nodes and edges
+type topologyGraph struct {
+	nodes map[string]graphNode
+	edges map[Connection]bool
+}
+
+type graphNode struct {
+	TopologyNode
+	resource interface{}
+}
+
+// DiffTopologies compares two topologies. Nodes are matched by UUID; a node
+// changed when any of its fields but its volatile ones differ.
+func DiffTopologies(from, to *Topology) *TopologyDiff {
+	before, after := newTopologyGraph(from), newTopologyGraph(to)
+	diff := &TopologyDiff{
+		Nodes: TopologyNodeDiff{
+			Added:   []TopologyNode{},
+			Removed: []TopologyNode{},
+			Changed: []TopologyNodeChange{},
+		},
+		Edges: TopologyEdgeDiff{
+			Added:   []Connection{},
+			Removed: []Connection{},
+		},
+	}
+
+	for id, node := range after.nodes {
+		old, ok := before.nodes[id]
+		if !ok {
+			diff.Nodes.Added = append(diff.Nodes.Added, node.TopologyNode)
+			continue
+		}
+		if fields := changedFields(old.resource, node.resource); len(fields) > 0 {
+			diff.Nodes.Changed = append(diff.Nodes.Changed, TopologyNodeChange{
+				TopologyNode: node.TopologyNode,
+				Fields:       fields,
+				Before:       old.resource,
+				After:        node.resource,
+			})
+		}
+	}
+	for id, node := range before.nodes {
+		if _, ok := after.nodes[id]; !ok {
+			diff.Nodes.Removed = append(diff.Nodes.Removed, node.TopologyNode)
+		}
+	}
+
+	for edge := range after.edges {
+		if !before.edges[edge] {
+			diff.Edges.Added = append(diff.Edges.Added, edge)
+		}
+	}
+	for edge := range before.edges {
+		if !after.edges[edge] {
+			diff.Edges.Removed = append(diff.Edges.Removed, edge)
+		}
+	}
+
+	sortNodes(diff.Nodes.Added)
+	sortNodes(diff.Nodes.Removed)
+	sort.Slice(diff.Nodes.Changed, func(i, j int) bool {
+		return nodeLess(diff.Nodes.Changed[i].TopologyNode, diff.Nodes.Changed[j].TopologyNode)
+	})
+	sortEdges(diff.Edges.Added)
+	sortEdges(diff.Edges.Removed)
+
+	diff.Summary = map[string]int{
+		"nodes_added":   len(diff.Nodes.Added),
+		"nodes_removed": len(diff.Nodes.Removed),
+		"nodes_changed": len(diff.Nodes.Changed),
+		"edges_added":   len(diff.Edges.Added),
+		"edges_removed": len(diff.Edges.Removed),
+	}
+	return diff
+}
+
+func newTopologyGraph(t *Topology) *topologyGraph {
+	g := &topologyGraph{
+		nodes: make(map[string]graphNode),
+		edges: make(map[Connection]bool),
+	}
+	if t == nil {
+		return g
+	}
+
+	add := func(id, nodeType, name string, resource interface{}) {
+		g.nodes[id] = graphNode{TopologyNode{ID: id, Type: nodeType, Name: name}, resource}
+	}
+	link := func(from, to, edgeType string) {
+		g.edges[Connection{From: from, To: to, Type: edgeType}] = true
+	}
+
+	for _, sw := range t.Switches {
+		add(sw.UUID, TopologyNodeSwitch, sw.Name, sw)
+		for _, port := range sw.Ports {
+			link(sw.UUID, port, TopologyEdgeSwitchPort)
+		}
+		for _, acl := range sw.ACLs {
+			link(sw.UUID, acl, TopologyEdgeACL)
+		}
+	}
+	for _, router := range t.Routers {
+		add(router.UUID, TopologyNodeRouter, router.Name, router)
+		for _, port := range router.Ports {
+			link(router.UUID, port, TopologyEdgeRouterPort)
+		}
+	}
+	routerPorts := make(map[string]string, len(t.RouterPorts))
+	for _, port := range t.RouterPorts {
+		add(port.UUID, TopologyNodeRouterPort, port.Name, port)
+		routerPorts[port.Name] = port.UUID
+	}
+	for _, port := range t.Ports {
+		add(port.UUID, TopologyNodePort, port.Name, port)
+		if peer, ok := routerPorts[port.Options["router-port"]]; ok && port.Type == "router" {
+			link(port.UUID, peer, TopologyEdgePatch)
+		}
+	}
+	for _, acl := range t.ACLs {
+		add(acl.UUID, TopologyNodeACL, acl.Name, acl)
+	}
+	for _, conn := range t.Connections {
+		g.edges[conn] = true
+	}
+	return g
+}
+
+// changedFields returns the JSON fields that differ between two resources,
+// ignoring volatile fields as ETags do
+func changedFields(before, after interface{}) []string {
+	var fields []string
+	for _, change := range DiffFields(before, after) {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
+
+// FieldChange is a JSON field of a resource whose value differs between
+// two versions of it; a field one version lacks is null
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffFields returns the JSON fields that differ between two versions of a
+// resource, by name, ignoring volatile fields as ETags do
+func DiffFields(before, after interface{}) []FieldChange {
+	return DiffFieldValues(ResourceFields(before), ResourceFields(after))
+}
+
+// DiffFieldValues returns the fields that differ between two versions of a
+// resource's fields, as ResourceFields returns them
+func DiffFieldValues(b, a map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	for name, value := range a {
+		if !reflect.DeepEqual(b[name], value) {
+			changes = append(changes, FieldChange{Field: name, Before: b[name], After: value})
+		}
+	}
+	for name, value := range b {
+		if _, ok := a[name]; !ok {
+			changes = append(changes, FieldChange{Field: name, Before: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// ResourceFields returns the JSON fields of a resource without its volatile
+// ones, which DiffFields compares
+func ResourceFields(resource interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if data, err := json.Marshal(resource); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	externalIDs, _ := fields["external_ids"].(map[string]interface{})
+	for _, name := range volatileFields {
+		delete(fields, name)
+		delete(externalIDs, name)
+	}
+	// External IDs that were all volatile are no external IDs
+	if externalIDs != nil && len(externalIDs) == 0 {
+		delete(fields, "external_ids")
+	}
+	return fields
+}
+
+func nodeLess(a, b TopologyNode) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ID < b.ID
+}
+
+func sortNodes(nodes []TopologyNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodeLess(nodes[i], nodes[j]) })
+}
+
+func sortEdges(edges []Connection) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Type != edges[j].Type {
+			return edges[i].Type < edges[j].Type
+		}
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
+
+// filterTopologyByTenant keeps the resources labeled with the tenant.
+// Labels are read from the resources themselves, so resources deleted since
+// are still attributed to their tenant.
+func filterTopologyByTenant(t *Topology, tenantID string) *Topology {
+	return filterTopology(t, func(id string, externalIDs map[string]string) bool {
+		return externalIDs["tenant_id"] == tenantID
+	})
+}
+
+// filterTopology keeps the resources keep accepts, and the connections
+// between them
+func filterTopology(t *Topology, keep func(id string, externalIDs map[string]string) bool) *Topology {
+	kept := make(map[string]bool)
+
+	filtered := &Topology{Timestamp: t.Timestamp}
+	for _, sw := range t.Switches {
+		if keep(sw.UUID, sw.ExternalIDs) {
+			filtered.Switches = append(filtered.Switches, sw)
+			kept[sw.UUID] = true
+		}
+	}
+	for _, router := range t.Routers {
+		if keep(router.UUID, router.ExternalIDs) {
+			filtered.Routers = append(filtered.Routers, router)
+			kept[router.UUID] = true
+		}
+	}
+	for _, port := range t.Ports {
+		if keep(port.UUID, port.ExternalIDs) {
+			filtered.Ports = append(filtered.Ports, port)
+			kept[port.UUID] = true
+		}
+	}
+	for _, port := range t.RouterPorts {
+		if keep(port.UUID, port.ExternalIDs) {
+			filtered.RouterPorts = append(filtered.RouterPorts, port)
+			kept[port.UUID] = true
+		}
+	}
+	for _, acl := range t.ACLs {
+		if keep(acl.UUID, acl.ExternalIDs) {
+			filtered.ACLs = append(filtered.ACLs, acl)
+			kept[acl.UUID] = true
+		}
+	}
+	for _, conn := range t.Connections {
+		if kept[conn.From] && kept[conn.To] {
+			filtered.Connections = append(filtered.Connections, conn)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+func TestDiffTopologies(t *testing.T) {
+	earlier := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	from := &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Name: "web", Ports: []string{"port-1"}, CreatedAt: earlier},
+			{UUID: "sw-2", Name: "db"},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "port-1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.1"}},
+		},
+		Connections: []Connection{{From: "sw-2", To: "lr-1", Type: "router"}},
+	}
+	to := &Topology{
+		Switches: []*models.LogicalSwitch{
+			// Only the timestamps changed
+			{UUID: "sw-1", Name: "web", Ports: []string{"port-1", "port-2"}, CreatedAt: later, UpdatedAt: later},
+		},
+		Routers: []*models.LogicalRouter{{UUID: "lr-1", Name: "edge"}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "port-1", Name: "web-1", Addresses: []string{"00:00:00:00:00:01 10.0.0.2"}},
+			{UUID: "port-2", Name: "web-2"},
+		},
+	}
+
+	diff := DiffTopologies(from, to)
+
+	assert.Equal(t, []TopologyNode{
+		{ID: "port-2", Type: TopologyNodePort, Name: "web-2"},
+		{ID: "lr-1", Type: TopologyNodeRouter, Name: "edge"},
+	}, diff.Nodes.Added)
+	assert.Equal(t, []TopologyNode{{ID: "sw-2", Type: TopologyNodeSwitch, Name: "db"}}, diff.Nodes.Removed)
+
+	// sw-1 changed through its ports only
+	if assert.Len(t, diff.Nodes.Changed, 2) {
+		assert.Equal(t, "port-1", diff.Nodes.Changed[0].ID)
+		assert.Equal(t, []string{"addresses"}, diff.Nodes.Changed[0].Fields)
+		assert.Equal(t, "sw-1", diff.Nodes.Changed[1].ID)
+		assert.Equal(t, []string{"ports"}, diff.Nodes.Changed[1].Fields)
+	}
+
+	assert.Equal(t, []Connection{{From: "sw-1", To: "port-2", Type: TopologyEdgeSwitchPort}}, diff.Edges.Added)
+	assert.Equal(t, []Connection{{From: "sw-2", To: "lr-1", Type: "router"}}, diff.Edges.Removed)
+	assert.Equal(t, map[string]int{
+		"nodes_added":   2,
+		"nodes_removed": 1,
+		"nodes_changed": 2,
+		"edges_added":   1,
+		"edges_removed": 1,
+	}, diff.Summary)
+
+	// Identical states differ in nothing
+	diff = DiffTopologies(to, to)
+	assert.Empty(t, diff.Nodes.Added)
+	assert.Empty(t, diff.Nodes.Removed)
+	assert.Empty(t, diff.Nodes.Changed)
+	assert.NotNil(t, diff.Edges.Added, "empty lists encode as []")
+}
+
+func TestFilterTopologyByTenant(t *testing.T) {
+	acme := map[string]string{"tenant_id": "acme"}
+	topology := &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", ExternalIDs: acme},
+			{UUID: "sw-2", ExternalIDs: map[string]string{"tenant_id": "other"}},
+		},
+		Routers: []*models.LogicalRouter{{UUID: "lr-1", ExternalIDs: acme}},
+		Ports:   []*models.LogicalSwitchPort{{UUID: "port-1"}},
+		ACLs:    []*models.ACL{{UUID: "acl-1", ExternalIDs: acme}},
+		Connections: []Connection{
+			{From: "sw-1", To: "lr-1", Type: "router"},
+			{From: "sw-2", To: "lr-1", Type: "router"},
+		},
+	}
+
+	filtered := filterTopologyByTenant(topology, "acme")
+	assert.Len(t, filtered.Switches, 1)
+	assert.Equal(t, "sw-1", filtered.Switches[0].UUID)
+	assert.Len(t, filtered.Routers, 1)
+	assert.Empty(t, filtered.Ports)
+	assert.Len(t, filtered.ACLs, 1)
+	assert.Equal(t, []Connection{{From: "sw-1", To: "lr-1", Type: "router"}}, filtered.Connections)
+}
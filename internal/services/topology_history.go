@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrInvalidTopologyRef is returned for topology references that can't
+	// be parsed or resolved
+	ErrInvalidTopologyRef = errors.New("invalid topology reference")
+	// ErrTopologySnapshotNotFound is returned when a referenced snapshot or
+	// backup doesn't exist
+	ErrTopologySnapshotNotFound = errors.New("topology snapshot not found")
+)
+
+// Sources of a topology state
+const (
+	TopologySourceNow      = "now"
+	TopologySourceSnapshot = "snapshot"
+	TopologySourceBackup   = "backup"
+)
+
+// TopologySnapshotStore holds recorded topology snapshots. *db.DB implements
+// it.
+type TopologySnapshotStore interface {
+	CreateTopologySnapshot(ctx context.Context, snapshot *models.TopologySnapshot) error
+	GetTopologySnapshot(ctx context.Context, id string) (*models.TopologySnapshot, error)
+	FindTopologySnapshot(ctx context.Context, cluster string, at time.Time) (*models.TopologySnapshot, error)
+	ListTopologySnapshots(ctx context.Context, cluster string, from, to time.Time) ([]*models.TopologySnapshot, error)
+	DeleteTopologySnapshotsBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// TopologyBackupSource returns the topology recorded in a backup
+type TopologyBackupSource func(id string) (*Topology, error)
+
+// TopologyRef references a topology state: the live topology, a snapshot, a
+// backup, or the latest snapshot taken at or before a time
+type TopologyRef struct {
+	Source string
+	ID     string
+	At     time.Time
+}
+
+// ParseTopologyRef parses "now", "snapshot:<id>", "backup:<id>" or an
+// RFC 3339 timestamp
+func ParseTopologyRef(ref string) (TopologyRef, error) {
+	if ref == TopologySourceNow {
+		return TopologyRef{Source: TopologySourceNow}, nil
+	}
+	if source, id, ok := strings.Cut(ref, ":"); ok && (source == TopologySourceSnapshot || source == TopologySourceBackup) {
+		if id == "" {
+			return TopologyRef{}, fmt.Errorf("%w: %q has no ID", ErrInvalidTopologyRef, ref)
+		}
+		return TopologyRef{Source: source, ID: id}, nil
+	}
+	at, err := time.Parse(time.RFC3339, ref)
+	if err != nil {
+		return TopologyRef{}, fmt.Errorf("%w: %q is not now, snapshot:<id>, backup:<id> or an RFC 3339 time", ErrInvalidTopologyRef, ref)
+	}
+	return TopologyRef{Source: TopologySourceSnapshot, At: at}, nil
+}
+
+// TopologyHistory periodically records each cluster's topology and diffs
+// topology states
+type TopologyHistory struct {
+	store     TopologySnapshotStore
+	ovn       OVNServiceInterface
+	clusters  *OVNClusterManager
+	backups   TopologyBackupSource
+	interval  time.Duration
+	retention time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewTopologyHistory creates a history recording the topology of every
+// cluster each interval and keeping snapshots for retention. ovn must not
+// filter by tenant: diffs filter both states themselves.
+func NewTopologyHistory(store TopologySnapshotStore, ovn OVNServiceInterface, clusters *OVNClusterManager, interval, retention time.Duration, logger *zap.Logger) *TopologyHistory {
+	return &TopologyHistory{
+		store:     store,
+		ovn:       ovn,
+		clusters:  clusters,
+		interval:  interval,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetBackupSource lets diffs reference backups
+func (h *TopologyHistory) SetBackupSource(backups TopologyBackupSource) {
+	h.backups = backups
+}
+
+// Run records snapshots until ctx is done. A zero interval disables it.
+func (h *TopologyHistory) Run(ctx context.Context) {
+	if h.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.RecordSnapshots(ctx); err != nil {
+				h.logger.Error("Failed to record topology snapshots", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RecordSnapshots records the topology of every cluster and deletes the
+// snapshots past retention. A cluster whose topology can't be read is
+// skipped. Without clusters, as with the sandbox backend, the topology of
+// ovn is recorded under the empty cluster name.
+func (h *TopologyHistory) RecordSnapshots(ctx context.Context) error {
+	takenAt := h.now().UTC()
+	names := []string{""}
+	if infos := h.clusters.List(); len(infos) > 0 {
+		names = names[:0]
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+	}
+	for _, name := range names {
+		if err := h.recordSnapshot(ContextWithOVNCluster(ctx, name), name, takenAt); err != nil {
+			h.logger.Warn("Failed to record topology snapshot",
+				zap.String("cluster", name),
+				zap.Error(err))
+		}
+	}
+
+	if h.retention > 0 {
+		if err := h.store.DeleteTopologySnapshotsBefore(ctx, takenAt.Add(-h.retention)); err != nil {
+			return fmt.Errorf("failed to delete old topology snapshots: %w", err)
+		}
+	}
+	return nil
+}
+
+func (h *TopologyHistory) recordSnapshot(ctx context.Context, cluster string, takenAt time.Time) error {
+	topology, err := h.liveTopology(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(topology)
+	if err != nil {
+		return fmt.Errorf("failed to encode topology: %w", err)
+	}
+	return h.store.CreateTopologySnapshot(ctx, &models.TopologySnapshot{
+		ID:      uuid.New().String(),
+		Cluster: cluster,
+		TakenAt: takenAt,
+		Data:    data,
+	})
+}
+
+// Diff compares two topology states of the cluster selected in ctx. When
+// ctx carries a tenant, only the tenant's resources are compared.
+func (h *TopologyHistory) Diff(ctx context.Context, from, to TopologyRef) (*TopologyDiff, error) {
+	before, fromState, err := h.resolve(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, toState, err := h.resolve(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffTopologies(forTenant(ctx, before), forTenant(ctx, after))
+	diff.From = fromState
+	diff.To = toState
+	return diff, nil
+}
+
+// TopologyAt returns the topology of the cluster selected in ctx as recorded
+// by the latest snapshot taken at or before at. Its timestamp is the time the
+// snapshot was taken. When ctx carries a tenant, only the tenant's resources
+// are returned.
+func (h *TopologyHistory) TopologyAt(ctx context.Context, at time.Time) (*Topology, error) {
+	topology, state, err := h.resolve(ctx, TopologyRef{Source: TopologySourceSnapshot, At: at})
+	if err != nil {
+		return nil, err
+	}
+	topology.Timestamp = state.Time
+	return forTenant(ctx, topology), nil
+}
+
+// ListSnapshots lists the snapshots of the cluster selected in ctx taken in
+// [from, to], oldest first, without their data
+func (h *TopologyHistory) ListSnapshots(ctx context.Context, from, to time.Time) ([]*models.TopologySnapshot, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidTopologyRef)
+	}
+
+	snapshots, err := h.store.ListTopologySnapshots(ctx, h.clusterName(ctx), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topology snapshots: %w", err)
+	}
+
+	listed := make([]*models.TopologySnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		listed = append(listed, &models.TopologySnapshot{
+			ID:      snapshot.ID,
+			Cluster: snapshot.Cluster,
+			TakenAt: snapshot.TakenAt,
+		})
+	}
+	return listed, nil
+}
+
+// forTenant keeps the resources of the tenant in ctx, if any
+func forTenant(ctx context.Context, topology *Topology) *Topology {
+	if tenantID := getTenantFromContext(ctx); tenantID != "" {
+		return filterTopologyByTenant(topology, tenantID)
+	}
+	return topology
+}
+
+// resolve returns the topology a reference points to
+func (h *TopologyHistory) resolve(ctx context.Context, ref TopologyRef) (*Topology, TopologyState, error) {
+	state := TopologyState{Source: ref.Source, ID: ref.ID}
+
+	switch ref.Source {
+	case TopologySourceNow:
+		topology, err := h.liveTopology(ctx)
+		if err != nil {
+			return nil, state, err
+		}
+		state.Time = topology.Timestamp
+		return topology, state, nil
+
+	case TopologySourceBackup:
+		if h.backups == nil {
+			return nil, state, fmt.Errorf("%w: backups are not available", ErrInvalidTopologyRef)
+		}
+		topology, err := h.backups(ref.ID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				return nil, state, fmt.Errorf("%w: backup %s", ErrTopologySnapshotNotFound, ref.ID)
+			}
+			return nil, state, fmt.Errorf("failed to read backup %s: %w", ref.ID, err)
+		}
+		state.Time = topology.Timestamp
+		return topology, state, nil
+	}
+
+	cluster := h.clusterName(ctx)
+	var snapshot *models.TopologySnapshot
+	var err error
+	if ref.ID != "" {
+		snapshot, err = h.store.GetTopologySnapshot(ctx, ref.ID)
+	} else {
+		snapshot, err = h.store.FindTopologySnapshot(ctx, cluster, ref.At)
+	}
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, state, fmt.Errorf("failed to get topology snapshot: %w", err)
+	}
+	if err != nil || snapshot == nil || snapshot.Cluster != cluster {
+		if ref.ID != "" {
+			return nil, state, fmt.Errorf("%w: %s", ErrTopologySnapshotNotFound, ref.ID)
+		}
+		return nil, state, fmt.Errorf("%w: none taken at or before %s", ErrTopologySnapshotNotFound, ref.At.Format(time.RFC3339))
+	}
+
+	var topology Topology
+	if err := json.Unmarshal(snapshot.Data, &topology); err != nil {
+		return nil, state, fmt.Errorf("failed to decode topology snapshot %s: %w", snapshot.ID, err)
+	}
+	state.ID = snapshot.ID
+	state.Time = snapshot.TakenAt
+	return &topology, state, nil
+}
+
+// liveTopology returns the current topology with each switch's ACLs, which
+// the topology itself leaves out
+func (h *TopologyHistory) liveTopology(ctx context.Context) (*Topology, error) {
+	current, err := h.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The topology may be shared with a cache, so extend a copy
+	topology := *current
+	topology.ACLs = append([]*models.ACL(nil), current.ACLs...)
+	for _, sw := range topology.Switches {
+		acls, err := h.ovn.ListACLs(ctx, sw.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ACLs for switch %s: %w", sw.UUID, err)
+		}
+		topology.ACLs = append(topology.ACLs, acls...)
+	}
+	return &topology, nil
+}
+
+// clusterName returns the cluster selected in ctx, or the default one
+func (h *TopologyHistory) clusterName(ctx context.Context) string {
+	if name := getOVNClusterFromContext(ctx); name != "" {
+		return name
+	}
+	if cluster := h.clusters.Default(); cluster != nil {
+		return cluster.Name
+	}
+	return ""
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryTopologySnapshotStore keeps snapshots in memory
+type memoryTopologySnapshotStore struct {
+	snapshots []*models.TopologySnapshot
+}
+
+func (s *memoryTopologySnapshotStore) CreateTopologySnapshot(ctx context.Context, snapshot *models.TopologySnapshot) error {
+	s.snapshots = append(s.snapshots, snapshot)
+	return nil
+}
+
+func (s *memoryTopologySnapshotStore) GetTopologySnapshot(ctx context.Context, id string) (*models.TopologySnapshot, error) {
+	for _, snapshot := range s.snapshots {
+		if snapshot.ID == id {
+			return snapshot, nil
+		}
+	}
+	return nil, errors.New("topology snapshot not found")
+}
+
+func (s *memoryTopologySnapshotStore) FindTopologySnapshot(ctx context.Context, cluster string, at time.Time) (*models.TopologySnapshot, error) {
+	var found *models.TopologySnapshot
+	for _, snapshot := range s.snapshots {
+		if snapshot.Cluster == cluster && !snapshot.TakenAt.After(at) && (found == nil || snapshot.TakenAt.After(found.TakenAt)) {
+			found = snapshot
+		}
+	}
+	return found, nil
+}
+
+func (s *memoryTopologySnapshotStore) ListTopologySnapshots(ctx context.Context, cluster string, from, to time.Time) ([]*models.TopologySnapshot, error) {
+	var snapshots []*models.TopologySnapshot
+	for _, snapshot := range s.snapshots {
+		if snapshot.Cluster == cluster && !snapshot.TakenAt.Before(from) && !snapshot.TakenAt.After(to) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *memoryTopologySnapshotStore) DeleteTopologySnapshotsBefore(ctx context.Context, cutoff time.Time) error {
+	kept := s.snapshots[:0]
+	for _, snapshot := range s.snapshots {
+		if !snapshot.TakenAt.Before(cutoff) {
+			kept = append(kept, snapshot)
+		}
+	}
+	s.snapshots = kept
+	return nil
+}
+
+func newTestTopologyClusters(t *testing.T) *OVNClusterManager {
+	manager := NewOVNClusterManager(zap.NewNop())
+	t.Cleanup(manager.Close)
+
+	for _, name := range []string{"us-east", "eu-west"} {
+		require.NoError(t, manager.Add(&config.OVNConfig{
+			ClusterName:  name,
+			NorthboundDB: "tcp:127.0.0.1:1",
+			Timeout:      100 * time.Millisecond,
+		}))
+	}
+	return manager
+}
+
+func storeTopologySnapshot(t *testing.T, store *memoryTopologySnapshotStore, id, cluster string, takenAt time.Time, topology *Topology) {
+	data, err := json.Marshal(topology)
+	require.NoError(t, err)
+	store.snapshots = append(store.snapshots, &models.TopologySnapshot{ID: id, Cluster: cluster, TakenAt: takenAt, Data: data})
+}
+
+func TestParseTopologyRef(t *testing.T) {
+	ref, err := ParseTopologyRef("now")
+	require.NoError(t, err)
+	assert.Equal(t, TopologyRef{Source: TopologySourceNow}, ref)
+
+	ref, err = ParseTopologyRef("snapshot:abc")
+	require.NoError(t, err)
+	assert.Equal(t, TopologyRef{Source: TopologySourceSnapshot, ID: "abc"}, ref)
+
+	ref, err = ParseTopologyRef("backup:backup-123")
+	require.NoError(t, err)
+	assert.Equal(t, TopologyRef{Source: TopologySourceBackup, ID: "backup-123"}, ref)
+
+	ref, err = ParseTopologyRef("2024-03-01T10:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, TopologySourceSnapshot, ref.Source)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), ref.At)
+
+	for _, invalid := range []string{"yesterday", "snapshot:", "tag:v1", "2024-03-01"} {
+		_, err := ParseTopologyRef(invalid)
+		assert.ErrorIs(t, err, ErrInvalidTopologyRef, invalid)
+	}
+}
+
+func TestTopologyHistory_RecordSnapshots(t *testing.T) {
+	store := &memoryTopologySnapshotStore{}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	storeTopologySnapshot(t, store, "old", "us-east", now.Add(-48*time.Hour), &Topology{})
+
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetTopology", mock.Anything).Return(&Topology{
+		Switches: []*models.LogicalSwitch{{UUID: "sw-1", Name: "web"}},
+	}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-1").Return([]*models.ACL{{UUID: "acl-1"}}, nil)
+
+	history := NewTopologyHistory(store, mockOVN, newTestTopologyClusters(t), time.Hour, 24*time.Hour, zap.NewNop())
+	history.now = func() time.Time { return now }
+
+	require.NoError(t, history.RecordSnapshots(context.Background()))
+
+	// The old snapshot is past retention
+	require.Len(t, store.snapshots, 2)
+	assert.Equal(t, "us-east", store.snapshots[0].Cluster)
+	assert.Equal(t, "eu-west", store.snapshots[1].Cluster)
+	assert.Equal(t, now, store.snapshots[0].TakenAt)
+
+	var recorded Topology
+	require.NoError(t, json.Unmarshal(store.snapshots[0].Data, &recorded))
+	assert.Len(t, recorded.Switches, 1)
+	assert.Len(t, recorded.ACLs, 1, "snapshots include ACLs")
+}
+
+func TestTopologyHistory_RecordSnapshotsWithoutClusters(t *testing.T) {
+	store := &memoryTopologySnapshotStore{}
+	sandbox, err := NewSandboxOVNService(context.Background(), newMemorySandboxStateStore(), "sandbox", true, zap.NewNop())
+	require.NoError(t, err)
+
+	history := NewTopologyHistory(store, sandbox, NewOVNClusterManager(zap.NewNop()), time.Hour, 0, zap.NewNop())
+	require.NoError(t, history.RecordSnapshots(context.Background()))
+
+	require.Len(t, store.snapshots, 1)
+	assert.Equal(t, "", store.snapshots[0].Cluster)
+
+	snapshots, err := history.ListSnapshots(context.Background(), time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+}
+
+func TestTopologyHistory_Diff(t *testing.T) {
+	store := &memoryTopologySnapshotStore{}
+	earlier := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	acme := map[string]string{"tenant_id": "acme"}
+	storeTopologySnapshot(t, store, "snap-1", "us-east", earlier, &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Name: "web", ExternalIDs: acme},
+			{UUID: "sw-2", Name: "db", ExternalIDs: map[string]string{"tenant_id": "other"}},
+		},
+	})
+	storeTopologySnapshot(t, store, "snap-eu", "eu-west", earlier, &Topology{})
+
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetTopology", mock.Anything).Return(&Topology{
+		Routers:   []*models.LogicalRouter{{UUID: "lr-1", Name: "edge", ExternalIDs: acme}},
+		Timestamp: earlier.Add(time.Hour),
+	}, nil)
+
+	history := NewTopologyHistory(store, mockOVN, newTestTopologyClusters(t), 0, 0, zap.NewNop())
+	history.SetBackupSource(func(id string) (*Topology, error) {
+		if id != "backup-123" {
+			return nil, errors.New("backup not found: " + id)
+		}
+		return &Topology{Switches: []*models.LogicalSwitch{{UUID: "sw-1", Name: "web", ExternalIDs: acme}}}, nil
+	})
+	ctx := context.Background()
+	now := TopologyRef{Source: TopologySourceNow}
+
+	diff, err := history.Diff(ctx, TopologyRef{Source: TopologySourceSnapshot, ID: "snap-1"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, TopologyState{Source: TopologySourceSnapshot, ID: "snap-1", Time: earlier}, diff.From)
+	assert.Equal(t, TopologyState{Source: TopologySourceNow, Time: earlier.Add(time.Hour)}, diff.To)
+	assert.Equal(t, 1, diff.Summary["nodes_added"])
+	assert.Equal(t, 2, diff.Summary["nodes_removed"])
+
+	// A time selects the latest snapshot taken by then
+	diff, err = history.Diff(ctx, TopologyRef{Source: TopologySourceSnapshot, At: earlier.Add(time.Minute)}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "snap-1", diff.From.ID)
+
+	// Tenants only see their own resources
+	diff, err = history.Diff(ContextWithTenant(ctx, "acme"), TopologyRef{Source: TopologySourceBackup, ID: "backup-123"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []TopologyNode{{ID: "lr-1", Type: TopologyNodeRouter, Name: "edge"}}, diff.Nodes.Added)
+	assert.Equal(t, []TopologyNode{{ID: "sw-1", Type: TopologyNodeSwitch, Name: "web"}}, diff.Nodes.Removed)
+
+	_, err = history.Diff(ctx, TopologyRef{Source: TopologySourceBackup, ID: "missing"}, now)
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+
+	_, err = history.Diff(ctx, TopologyRef{Source: TopologySourceSnapshot, ID: "missing"}, now)
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+
+	_, err = history.Diff(ctx, TopologyRef{Source: TopologySourceSnapshot, At: earlier.Add(-time.Minute)}, now)
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+
+	// Snapshots of another cluster aren't compared
+	_, err = history.Diff(ctx, TopologyRef{Source: TopologySourceSnapshot, ID: "snap-eu"}, now)
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+
+	diff, err = history.Diff(ContextWithOVNCluster(ctx, "eu-west"), TopologyRef{Source: TopologySourceSnapshot, ID: "snap-eu"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "snap-eu", diff.From.ID)
+}
+
+func TestTopologyHistory_TopologyAt(t *testing.T) {
+	store := &memoryTopologySnapshotStore{}
+	earlier := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	acme := map[string]string{"tenant_id": "acme"}
+	storeTopologySnapshot(t, store, "snap-1", "us-east", earlier, &Topology{
+		Switches: []*models.LogicalSwitch{{UUID: "sw-1", Name: "web", ExternalIDs: acme}},
+	})
+	storeTopologySnapshot(t, store, "snap-2", "us-east", earlier.Add(time.Hour), &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-1", Name: "web", ExternalIDs: acme},
+			{UUID: "sw-2", Name: "db", ExternalIDs: map[string]string{"tenant_id": "other"}},
+		},
+	})
+
+	history := NewTopologyHistory(store, new(MockOVNService), newTestTopologyClusters(t), 0, 0, zap.NewNop())
+	ctx := context.Background()
+
+	topology, err := history.TopologyAt(ctx, earlier.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, topology.Switches, 1)
+	assert.Equal(t, earlier, topology.Timestamp, "the timestamp is when the snapshot was taken")
+
+	topology, err = history.TopologyAt(ctx, earlier.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, topology.Switches, 2)
+
+	// Tenants only see their own resources
+	topology, err = history.TopologyAt(ContextWithTenant(ctx, "acme"), earlier.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, topology.Switches, 1)
+
+	_, err = history.TopologyAt(ctx, earlier.Add(-time.Minute))
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+
+	_, err = history.TopologyAt(ContextWithOVNCluster(ctx, "eu-west"), earlier.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrTopologySnapshotNotFound)
+}
+
+func TestTopologyHistory_ListSnapshots(t *testing.T) {
+	store := &memoryTopologySnapshotStore{}
+	earlier := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	storeTopologySnapshot(t, store, "snap-1", "us-east", earlier, &Topology{})
+	storeTopologySnapshot(t, store, "snap-2", "us-east", earlier.Add(time.Hour), &Topology{})
+	storeTopologySnapshot(t, store, "snap-eu", "eu-west", earlier, &Topology{})
+
+	history := NewTopologyHistory(store, new(MockOVNService), newTestTopologyClusters(t), 0, 0, zap.NewNop())
+	ctx := context.Background()
+
+	snapshots, err := history.ListSnapshots(ctx, time.Time{}, earlier.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "snap-1", snapshots[0].ID)
+	assert.Equal(t, "snap-2", snapshots[1].ID)
+	assert.Nil(t, snapshots[0].Data, "listed snapshots leave out their data")
+	assert.NotNil(t, store.snapshots[0].Data, "the stored snapshot is not modified")
+
+	snapshots, err = history.ListSnapshots(ContextWithOVNCluster(ctx, "eu-west"), earlier, earlier)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "snap-eu", snapshots[0].ID)
+
+	_, err = history.ListSnapshots(ctx, earlier, earlier.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrInvalidTopologyRef)
+}
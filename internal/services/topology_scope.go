@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ErrTopologyNodeNotFound is returned when a scope's root node isn't in the
+// topology
+var ErrTopologyNodeNotFound = errors.New("topology node not found")
+
+// TopologyScope narrows a topology. Each set field narrows it further.
+type TopologyScope struct {
+	// TenantID keeps the resources labeled with the tenant
+	TenantID string
+	// Selector keeps the resources with these external IDs; an empty value
+	// only requires the key
+	Selector map[string]string
+	// Labels keeps the resources whose labels match it
+	Labels *models.LabelSelector
+	// Root keeps the switch, router, port, router port or ACL with this UUID
+	// and the nodes up to Depth edges away from it
+	Root  string
+	Depth int
+}
+
+// IsZero reports whether the scope keeps the whole topology
+func (s TopologyScope) IsZero() bool {
+	return s.TenantID == "" && len(s.Selector) == 0 && s.Labels == nil && s.Root == ""
+}
+
+// ScopeTopology returns the part of the topology within scope. Resources are
+// filtered by tenant, selector and labels before the neighborhood is searched, so it
+// only spans matching resources.
+func ScopeTopology(t *Topology, scope TopologyScope) (*Topology, error) {
+	if scope.TenantID != "" {
+		t = filterTopologyByTenant(t, scope.TenantID)
+	}
+	if len(scope.Selector) > 0 {
+		t = filterTopology(t, func(id string, externalIDs map[string]string) bool {
+			return matchesSelector(externalIDs, scope.Selector)
+		})
+	}
+	if scope.Labels != nil {
+		t = filterTopology(t, func(id string, externalIDs map[string]string) bool {
+			return scope.Labels.Matches(models.LabelsFromExternalIDs(externalIDs))
+		})
+	}
+	if scope.Root == "" {
+		return t, nil
+	}
+
+	g := newTopologyGraph(t)
+	if _, ok := g.nodes[scope.Root]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTopologyNodeNotFound, scope.Root)
+	}
+	neighborhood := g.neighborhood(scope.Root, scope.Depth)
+	return filterTopology(t, func(id string, externalIDs map[string]string) bool {
+		return neighborhood[id]
+	}), nil
+}
+
+// neighborhood returns the nodes at most depth edges away from root,
+// following edges in both directions
+func (g *topologyGraph) neighborhood(root string, depth int) map[string]bool {
+	adjacent := make(map[string][]string)
+	for edge := range g.edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+		adjacent[edge.To] = append(adjacent[edge.To], edge.From)
+	}
+
+	visited := map[string]bool{root: true}
+	frontier := []string{root}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range adjacent[id] {
+				// Edges may reference resources the topology leaves out
+				if _, ok := g.nodes[neighbor]; ok && !visited[neighbor] {
+					visited[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return visited
+}
+
+// matchesSelector reports whether externalIDs has every key of selector,
+// with its value unless that is empty
+func matchesSelector(externalIDs, selector map[string]string) bool {
+	for k, v := range selector {
+		actual, ok := externalIDs[k]
+		if !ok || (v != "" && actual != v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// scopeTestTopology is web - port-1 - lrp-1 - edge - lrp-2 - port-2 - db,
+// with web owned by acme
+func scopeTestTopology() *Topology {
+	acme := map[string]string{"tenant_id": "acme", "tier": "web"}
+	return &Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-web", Name: "web", Ports: []string{"port-1"}, ACLs: []string{"acl-1"}, ExternalIDs: acme},
+			{UUID: "sw-db", Name: "db", Ports: []string{"port-2"}, ExternalIDs: map[string]string{"tier": "db"}},
+		},
+		Routers: []*models.LogicalRouter{{UUID: "lr-edge", Name: "edge", Ports: []string{"lrp-1", "lrp-2"}}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "port-1", Name: "web-rtr", Type: "router", Options: map[string]string{"router-port": "edge-web"}, ExternalIDs: acme},
+			{UUID: "port-2", Name: "db-rtr", Type: "router", Options: map[string]string{"router-port": "edge-db"}},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			{UUID: "lrp-1", Name: "edge-web"},
+			{UUID: "lrp-2", Name: "edge-db"},
+		},
+		ACLs: []*models.ACL{{UUID: "acl-1", ExternalIDs: acme}},
+	}
+}
+
+func topologyNodeIDs(t *Topology) []string {
+	var ids []string
+	for id := range newTopologyGraph(t).nodes {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestScopeTopology_Neighborhood(t *testing.T) {
+	scoped, err := ScopeTopology(scopeTestTopology(), TopologyScope{Root: "sw-web", Depth: 1})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sw-web", "port-1", "acl-1"}, topologyNodeIDs(scoped))
+
+	// Router-type ports lead to their router port and on to the router
+	scoped, err = ScopeTopology(scopeTestTopology(), TopologyScope{Root: "sw-web", Depth: 3})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sw-web", "port-1", "acl-1", "lrp-1", "lr-edge"}, topologyNodeIDs(scoped))
+
+	scoped, err = ScopeTopology(scopeTestTopology(), TopologyScope{Root: "lr-edge", Depth: 0})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr-edge"}, topologyNodeIDs(scoped))
+
+	_, err = ScopeTopology(scopeTestTopology(), TopologyScope{Root: "missing", Depth: 1})
+	assert.ErrorIs(t, err, ErrTopologyNodeNotFound)
+}
+
+func TestScopeTopology_Filters(t *testing.T) {
+	scoped, err := ScopeTopology(scopeTestTopology(), TopologyScope{TenantID: "acme"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sw-web", "port-1", "acl-1"}, topologyNodeIDs(scoped))
+
+	scoped, err = ScopeTopology(scopeTestTopology(), TopologyScope{Selector: map[string]string{"tier": ""}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sw-web", "sw-db", "port-1", "acl-1"}, topologyNodeIDs(scoped))
+
+	scoped, err = ScopeTopology(scopeTestTopology(), TopologyScope{Selector: map[string]string{"tier": "db"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sw-db"}, topologyNodeIDs(scoped))
+
+	// The neighborhood only spans the filtered resources
+	_, err = ScopeTopology(scopeTestTopology(), TopologyScope{TenantID: "acme", Root: "lr-edge", Depth: 2})
+	assert.ErrorIs(t, err, ErrTopologyNodeNotFound)
+
+	assert.True(t, TopologyScope{Depth: 1}.IsZero())
+}
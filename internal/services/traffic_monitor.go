@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// InterfaceStatsSource reads the counters of the OVS interfaces bound to
+// logical ports. *ovn.OVSStatsClient implements it.
+type InterfaceStatsSource interface {
+	InterfaceStats(ctx context.Context) ([]ovn.InterfaceStats, error)
+}
+
+// PortTraffic is the traffic of a logical switch port: its interface's
+// counters and their rates since the previous poll. Rx and Tx are seen from
+// the switch.
+type PortTraffic struct {
+	RxBytes   uint64    `json:"rxBytes"`
+	TxBytes   uint64    `json:"txBytes"`
+	RxPackets uint64    `json:"rxPackets"`
+	TxPackets uint64    `json:"txPackets"`
+	RxBps     float64   `json:"rxBps"` // Bits per second
+	TxBps     float64   `json:"txBps"`
+	RxPps     float64   `json:"rxPps"` // Packets per second
+	TxPps     float64   `json:"txPps"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Bps returns the port's total rate in bits per second
+func (t PortTraffic) Bps() float64 {
+	return t.RxBps + t.TxBps
+}
+
+// TrafficMonitor periodically polls interface statistics and keeps the
+// traffic of each logical port
+type TrafficMonitor struct {
+	source   InterfaceStatsSource
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	traffic map[string]PortTraffic
+}
+
+// NewTrafficMonitor creates a monitor polling source each interval
+func NewTrafficMonitor(source InterfaceStatsSource, interval time.Duration, logger *zap.Logger) *TrafficMonitor {
+	return &TrafficMonitor{
+		source:   source,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+		traffic:  make(map[string]PortTraffic),
+	}
+}
+
+// Run polls until ctx is done. A zero interval disables it.
+func (m *TrafficMonitor) Run(ctx context.Context) {
+	if m.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll reads the interface statistics and updates each port's traffic.
+// Ports whose interface is gone are dropped. A failed read keeps the
+// previous traffic of the ports it missed.
+func (m *TrafficMonitor) Poll(ctx context.Context) {
+	stats, err := m.source.InterfaceStats(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to read interface statistics", zap.Error(err))
+	}
+	if stats == nil && err != nil {
+		return
+	}
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	traffic := make(map[string]PortTraffic, len(stats))
+	for _, s := range stats {
+		current := PortTraffic{
+			RxBytes:   s.RxBytes,
+			TxBytes:   s.TxBytes,
+			RxPackets: s.RxPackets,
+			TxPackets: s.TxPackets,
+			UpdatedAt: now,
+		}
+		if previous, ok := m.traffic[s.LogicalPort]; ok {
+			elapsed := now.Sub(previous.UpdatedAt).Seconds()
+			current.RxBps = 8 * rate(previous.RxBytes, current.RxBytes, elapsed)
+			current.TxBps = 8 * rate(previous.TxBytes, current.TxBytes, elapsed)
+			current.RxPps = rate(previous.RxPackets, current.RxPackets, elapsed)
+			current.TxPps = rate(previous.TxPackets, current.TxPackets, elapsed)
+		}
+		traffic[s.LogicalPort] = current
+	}
+
+	// Chassis that failed to answer keep their ports' last traffic
+	if err != nil {
+		for port, t := range m.traffic {
+			if _, ok := traffic[port]; !ok {
+				traffic[port] = t
+			}
+		}
+	}
+	m.traffic = traffic
+}
+
+// Traffic returns the traffic of each logical port by name
+func (m *TrafficMonitor) Traffic() map[string]PortTraffic {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	traffic := make(map[string]PortTraffic, len(m.traffic))
+	for port, t := range m.traffic {
+		traffic[port] = t
+	}
+	return traffic
+}
+
+// rate is the per-second increase of a counter. A counter that went back,
+// e.g. when its interface was recreated, has no rate.
+func rate(previous, current uint64, seconds float64) float64 {
+	if current < previous || seconds <= 0 {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// fakeStatsSource returns its stats and error on each read
+type fakeStatsSource struct {
+	stats []ovn.InterfaceStats
+	err   error
+}
+
+func (s *fakeStatsSource) InterfaceStats(ctx context.Context) ([]ovn.InterfaceStats, error) {
+	return s.stats, s.err
+}
+
+func TestTrafficMonitor_Poll(t *testing.T) {
+	source := &fakeStatsSource{stats: []ovn.InterfaceStats{
+		{LogicalPort: "web-1", RxBytes: 1000, TxBytes: 500, RxPackets: 10, TxPackets: 5},
+		{LogicalPort: "web-2", RxBytes: 9000},
+	}}
+	monitor := NewTrafficMonitor(source, time.Second, zap.NewNop())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	monitor.now = func() time.Time { return now }
+
+	monitor.Poll(context.Background())
+	traffic := monitor.Traffic()
+	assert.Equal(t, uint64(1000), traffic["web-1"].RxBytes)
+	assert.Zero(t, traffic["web-1"].Bps(), "the first poll has no rate")
+
+	now = now.Add(10 * time.Second)
+	source.stats = []ovn.InterfaceStats{
+		{LogicalPort: "web-1", RxBytes: 11000, TxBytes: 500, RxPackets: 110, TxPackets: 5},
+		{LogicalPort: "web-2", RxBytes: 100},
+	}
+	monitor.Poll(context.Background())
+	traffic = monitor.Traffic()
+	assert.Equal(t, 8000.0, traffic["web-1"].RxBps)
+	assert.Zero(t, traffic["web-1"].TxBps)
+	assert.Equal(t, 10.0, traffic["web-1"].RxPps)
+	assert.Equal(t, now, traffic["web-1"].UpdatedAt)
+	assert.Zero(t, traffic["web-2"].RxBps, "a reset counter has no rate")
+
+	// A chassis failing keeps its ports' last traffic; a gone port is dropped
+	source.stats = []ovn.InterfaceStats{{LogicalPort: "web-2", RxBytes: 200}}
+	source.err = errors.New("chassis-2 unreachable")
+	monitor.Poll(context.Background())
+	assert.Contains(t, monitor.Traffic(), "web-1")
+
+	source.err = nil
+	monitor.Poll(context.Background())
+	assert.NotContains(t, monitor.Traffic(), "web-1")
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// ValidateTransactionOperations checks the operations of a transaction
+// request, including that "$<id>" references name earlier creates
+func ValidateTransactionOperations(operations []models.TransactionOperation) error {
+	// Track operation IDs for uniqueness, and the resource each create
+	// makes so that later operations can refer to it as "$<id>"
+	operationIDs := make(map[string]bool)
+	created := make(map[string]string)
+
+	// Validate all operations
+	for i, op := range operations {
+		// Validate operation ID
+		if op.ID == "" {
+			return fmt.Errorf("operation %d: id is required", i)
+		}
+
+		if operationIDs[op.ID] {
+			return fmt.Errorf("operation %d: duplicate operation id '%s'", i, op.ID)
+		}
+		operationIDs[op.ID] = true
+
+		// Validate operation type
+		if !models.IsValidOperationType(op.Type) {
+			return fmt.Errorf("operation %s: invalid type '%s'", op.ID, op.Type)
+		}
+
+		// Validate resource type
+		if !models.IsValidResourceType(op.Resource) {
+			return fmt.Errorf("operation %s: invalid resource '%s'", op.ID, op.Resource)
+		}
+
+		// Validate operation-specific requirements
+		if err := validateTransactionOperation(&op, created); err != nil {
+			return fmt.Errorf("operation %s: %v", op.ID, err)
+		}
+
+		if op.Type == models.OperationCreate {
+			created[op.ID] = op.Resource
+		}
+	}
+
+	return nil
+}
+
+// validateTransactionOperation checks op. created maps the ids of the create
+// operations before it to their resources, which op may refer to as "$<id>".
+func validateTransactionOperation(op *models.TransactionOperation, created map[string]string) error {
+	switch op.Type {
+	case models.OperationCreate:
+		if op.Data == nil || len(op.Data) == 0 {
+			return fmt.Errorf("data is required for create operation")
+		}
+		if op.ResourceID != "" {
+			return fmt.Errorf("resource_id should not be provided for create operation")
+		}
+		// Validate switch_id for port/acl/qos creation
+		switch op.Resource {
+		case models.ResourcePort, models.ResourceACL, models.ResourceQoS:
+			if op.SwitchID == "" {
+				return fmt.Errorf("switch_id is required for %s creation", op.Resource)
+			}
+			if err := validateReference(op.SwitchID, models.ResourceSwitch, created); err != nil {
+				return fmt.Errorf("switch_id: %v", err)
+			}
+		case models.ResourceNAT:
+			if op.RouterID == "" {
+				return fmt.Errorf("router_id is required for %s creation", op.Resource)
+			}
+			if err := validateReference(op.RouterID, models.ResourceRouter, created); err != nil {
+				return fmt.Errorf("router_id: %v", err)
+			}
+		}
+
+	case models.OperationUpdate:
+		if op.ResourceID == "" {
+			return fmt.Errorf("resource_id is required for update operation")
+		}
+		if strings.HasPrefix(op.ResourceID, "$") {
+			return fmt.Errorf("resources created in the transaction cannot be updated by it")
+		}
+		if op.Data == nil || len(op.Data) == 0 {
+			return fmt.Errorf("data is required for update operation")
+		}
+
+	case models.OperationDelete:
+		if op.ResourceID == "" {
+			return fmt.Errorf("resource_id is required for delete operation")
+		}
+		if strings.HasPrefix(op.ResourceID, "$") {
+			return fmt.Errorf("resources created in the transaction cannot be deleted by it")
+		}
+		if op.Data != nil && len(op.Data) > 0 {
+			return fmt.Errorf("data should not be provided for delete operation")
+		}
+	}
+
+	return nil
+}
+
+// validateReference checks that id, if it is a "$<id>" reference, names an
+// earlier create of resource
+func validateReference(id, resource string, created map[string]string) error {
+	if !strings.HasPrefix(id, "$") {
+		return nil
+	}
+	if created[id[1:]] != resource {
+		return fmt.Errorf("%s does not refer to an earlier %s create operation", id, resource)
+	}
+	return nil
+}
+
+// errNotExecuted is the error of the operations of a failed transaction
+// other than the one that failed
+const errNotExecuted = "not executed due to transaction failure"
+
+// RunTransaction applies validated operations in one OVSDB transaction, so
+// that a failure leaves nothing behind. The response reports the operation
+// that failed; errors that aren't caused by an operation, such as a lost
+// connection, are returned.
+func RunTransaction(ctx context.Context, ovnService OVNServiceInterface, operations []models.TransactionOperation) (*models.TransactionResponse, error) {
+	response := &models.TransactionResponse{
+		TransactionID: uuid.New().String(),
+		Success:       true,
+		Results:       make([]models.TransactionOperationResult, 0, len(operations)),
+		ExecutedAt:    time.Now(),
+	}
+
+	ops := make([]TransactionOp, len(operations))
+	for i, op := range operations {
+		ops[i] = TransactionOp{
+			Operation:    op.Type,
+			ResourceType: op.Resource,
+			ResourceID:   op.ResourceID,
+			SwitchID:     op.SwitchID,
+			RouterID:     op.RouterID,
+			Ref:          op.ID,
+		}
+		if len(op.Data) > 0 {
+			ops[i].Data = op.Data
+		}
+	}
+
+	err := ovnService.ExecuteTransaction(ctx, ops)
+
+	var txErr *ovn.TxError
+	if err != nil && (!errors.As(err, &txErr) || txErr.Index < 0) {
+		return nil, err
+	}
+
+	for i, op := range operations {
+		result := models.TransactionOperationResult{
+			ID:         op.ID,
+			Type:       op.Type,
+			Resource:   op.Resource,
+			ResourceID: op.ResourceID,
+			Success:    err == nil,
+		}
+
+		switch {
+		case err == nil:
+			result.ResourceID = ops[i].ResourceID
+			if op.Type != models.OperationDelete {
+				result.Data = structToMap(ops[i].Data)
+			}
+		case i == txErr.Index:
+			result.Error = txErr.Err.Error()
+			response.Success = false
+			response.Error = fmt.Sprintf("operation %s failed: %s", op.ID, result.Error)
+		default:
+			result.Error = errNotExecuted
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// structToMap converts a resource to its JSON object form
+func structToMap(v interface{}) map[string]interface{} {
+	data, _ := json.Marshal(v)
+	var m map[string]interface{}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
@@ -38,9 +38,12 @@ type Connection struct {
 // TransactionOp represents a single operation in a transaction
 type TransactionOp struct {
 	Operation    string      `json:"operation"`      // create, update, delete
-	Table        string      `json:"table"`          // switch, router, port, acl
+	Table        string      `json:"table"`          // switch, router, port, acl, nat, ...
 	ID           string      `json:"id,omitempty"`
 	Data         interface{} `json:"data"`
 	ResourceType string      `json:"resource_type,omitempty"` // For batch operations
 	ResourceID   string      `json:"resource_id,omitempty"`   // For batch operations
+	SwitchID     string      `json:"switch_id,omitempty"`     // Switch of a new port, ACL or QoS rule
+	RouterID     string      `json:"router_id,omitempty"`     // Router of a new NAT rule
+	Ref          string      `json:"ref,omitempty"`           // Name later operations use as "$ref"
 }
\ No newline at end of file
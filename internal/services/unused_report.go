@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ErrInvalidReportQuery is returned for report parameters that are out of
+// range
+var ErrInvalidReportQuery = errors.New("invalid report query")
+
+// Types of unused resources
+const (
+	UnusedEmptySwitch      = "empty_switch"
+	UnusedUnboundPort      = "unbound_port"
+	UnusedIdleACL          = "idle_acl"
+	UnusedOrphanAddressSet = "orphaned_address_set"
+)
+
+// idleACLCoverage is the share of the idle period an ACL's samples must span
+// for it to be flagged, so that ACLs aren't flagged before statistics were
+// collected for long enough
+const idleACLCoverage = 0.9
+
+// addressSetRef matches the address sets referenced in a match, e.g.
+// $web_servers
+var addressSetRef = regexp.MustCompile(`\$([A-Za-z0-9_.]+)`)
+
+// PastTopologySource returns the topology recorded at a time.
+// TopologyHistory implements it.
+type PastTopologySource interface {
+	TopologyAt(ctx context.Context, at time.Time) (*Topology, error)
+}
+
+// ACLHitSource returns the traffic an ACL matched. ACLStatsRecorder
+// implements it.
+type ACLHitSource interface {
+	Stats(ctx context.Context, aclID string, from, to time.Time) (*ACLStats, error)
+}
+
+// UnusedResource is a resource that looks unused
+type UnusedResource struct {
+	Kind     string `json:"kind"`     // empty_switch, unbound_port, idle_acl or orphaned_address_set
+	Resource string `json:"resource"` // The transaction resource type, e.g. switch
+	UUID     string `json:"uuid"`
+	Name     string `json:"name,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// UnusedResourceReport lists the resources that look unused. Checks that
+// can't run, e.g. for lack of history, are listed as skipped with why.
+type UnusedResourceReport struct {
+	GeneratedAt time.Time                   `json:"generated_at"`
+	IdleDays    int                         `json:"idle_days"`
+	Resources   []UnusedResource            `json:"resources"`
+	Summary     map[string]int              `json:"summary"`
+	Skipped     map[string]string           `json:"skipped,omitempty"`
+	Cleanup     []models.TransactionRequest `json:"cleanup,omitempty"`
+}
+
+// UnusedResourceReporter finds switches without ports, ports without a
+// chassis binding, ACLs matching no traffic and address sets no ACL refers
+// to
+type UnusedResourceReporter struct {
+	ovn     OVNServiceInterface
+	history PastTopologySource
+	acls    ACLHitSource
+	now     func() time.Time
+}
+
+// NewUnusedResourceReporter creates a reporter. history and acls are nil
+// when topology snapshots or ACL statistics aren't recorded, which skips the
+// port and ACL checks.
+func NewUnusedResourceReporter(ovn OVNServiceInterface, history PastTopologySource, acls ACLHitSource) *UnusedResourceReporter {
+	return &UnusedResourceReporter{
+		ovn:     ovn,
+		history: history,
+		acls:    acls,
+		now:     time.Now,
+	}
+}
+
+// Report lists the resources unused for idleDays. With cleanup, it also
+// returns transactions deleting them, for review before they're submitted
+// to /transactions; they're never executed here.
+func (r *UnusedResourceReporter) Report(ctx context.Context, idleDays int, cleanup bool) (*UnusedResourceReport, error) {
+	if idleDays < 1 {
+		return nil, fmt.Errorf("%w: days must be at least 1", ErrInvalidReportQuery)
+	}
+
+	now := r.now().UTC()
+	since := now.AddDate(0, 0, -idleDays)
+	report := &UnusedResourceReport{
+		GeneratedAt: now,
+		IdleDays:    idleDays,
+		Resources:   []UnusedResource{},
+		Summary:     map[string]int{},
+		Skipped:     map[string]string{},
+	}
+
+	topology, err := r.ovn.GetTopology(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology: %w", err)
+	}
+	acls, err := listAllACLs(ctx, r.ovn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACLs: %w", err)
+	}
+	addressSets, err := r.ovn.ListAddressSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address sets: %w", err)
+	}
+
+	// ACLs go before address sets so that deleting both drops references
+	// before what they refer to
+	found := emptySwitches(topology)
+
+	if r.history == nil {
+		report.Skipped[UnusedUnboundPort] = "topology snapshots are not recorded"
+	} else {
+		ports, skipped, err := r.unboundPorts(ctx, topology, since)
+		if err != nil {
+			return nil, err
+		}
+		if skipped != "" {
+			report.Skipped[UnusedUnboundPort] = skipped
+		}
+		found = append(found, ports...)
+	}
+
+	if r.acls == nil {
+		report.Skipped[UnusedIdleACL] = "ACL statistics are not collected"
+	} else {
+		idle, err := r.idleACLs(ctx, acls, since, now)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, idle...)
+	}
+
+	found = append(found, orphanedAddressSets(addressSets, acls)...)
+
+	for _, resource := range found {
+		report.Resources = append(report.Resources, resource)
+		report.Summary[resource.Kind]++
+	}
+	if cleanup {
+		report.Cleanup = cleanupTransactions(found)
+	}
+	return report, nil
+}
+
+// emptySwitches returns the switches without ports
+func emptySwitches(topology *Topology) []UnusedResource {
+	var unused []UnusedResource
+	for _, sw := range topology.Switches {
+		if len(sw.Ports) == 0 {
+			unused = append(unused, UnusedResource{
+				Kind:     UnusedEmptySwitch,
+				Resource: models.ResourceSwitch,
+				UUID:     sw.UUID,
+				Name:     sw.Name,
+				Reason:   "has no ports",
+			})
+		}
+	}
+	return unused
+}
+
+// unboundPorts returns the VIF ports that aren't up now and weren't in the
+// latest snapshot taken by since. Without such a snapshot, the check is
+// skipped with why.
+func (r *UnusedResourceReporter) unboundPorts(ctx context.Context, topology *Topology, since time.Time) ([]UnusedResource, string, error) {
+	past, err := r.history.TopologyAt(ctx, since)
+	if errors.Is(err, ErrTopologySnapshotNotFound) {
+		return nil, "no topology snapshot was taken by " + since.Format(time.RFC3339), nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get past topology: %w", err)
+	}
+
+	downThen := make(map[string]bool, len(past.Ports))
+	for _, port := range past.Ports {
+		downThen[port.UUID] = !isPortUp(port)
+	}
+
+	var unused []UnusedResource
+	for _, port := range topology.Ports {
+		// Only VIF ports are bound to chassis
+		if port.Type != "" || isPortUp(port) || !downThen[port.UUID] {
+			continue
+		}
+		unused = append(unused, UnusedResource{
+			Kind:     UnusedUnboundPort,
+			Resource: models.ResourcePort,
+			UUID:     port.UUID,
+			Name:     port.Name,
+			Reason:   "not bound to a chassis now or at " + past.Timestamp.Format(time.RFC3339),
+		})
+	}
+	return unused, "", nil
+}
+
+// idleACLs returns the ACLs that matched no traffic since since, among those
+// whose statistics cover the period
+func (r *UnusedResourceReporter) idleACLs(ctx context.Context, acls []*models.ACL, since, now time.Time) ([]UnusedResource, error) {
+	minSpan := time.Duration(float64(now.Sub(since)) * idleACLCoverage)
+
+	var unused []UnusedResource
+	for _, acl := range acls {
+		stats, err := r.acls.Stats(ctx, acl.UUID, since, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statistics of ACL %s: %w", acl.UUID, err)
+		}
+		samples := stats.Samples
+		if len(samples) < 2 || samples[len(samples)-1].RecordedAt.Sub(samples[0].RecordedAt) < minSpan {
+			continue
+		}
+		if stats.PacketsInRange > 0 {
+			continue
+		}
+		unused = append(unused, UnusedResource{
+			Kind:     UnusedIdleACL,
+			Resource: models.ResourceACL,
+			UUID:     acl.UUID,
+			Name:     acl.Name,
+			Reason:   fmt.Sprintf("matched no traffic since %s: %s %s", since.Format(time.RFC3339), acl.Action, acl.Match),
+		})
+	}
+	return unused, nil
+}
+
+// orphanedAddressSets returns the address sets no ACL match refers to
+func orphanedAddressSets(addressSets []*models.AddressSet, acls []*models.ACL) []UnusedResource {
+	referenced := make(map[string]bool)
+	for _, acl := range acls {
+		for _, ref := range addressSetRef.FindAllStringSubmatch(acl.Match, -1) {
+			referenced[ref[1]] = true
+		}
+	}
+
+	var unused []UnusedResource
+	for _, as := range addressSets {
+		if referenced[as.Name] {
+			continue
+		}
+		unused = append(unused, UnusedResource{
+			Kind:     UnusedOrphanAddressSet,
+			Resource: models.ResourceAddressSet,
+			UUID:     as.UUID,
+			Name:     as.Name,
+			Reason:   "not referenced by any ACL",
+		})
+	}
+	return unused
+}
+
+// cleanupTransactions returns transactions deleting resources, in order and
+// split to the operation limit of a transaction
+func cleanupTransactions(resources []UnusedResource) []models.TransactionRequest {
+	var transactions []models.TransactionRequest
+	for i, resource := range resources {
+		if i%models.MaxTransactionOperations == 0 {
+			transactions = append(transactions, models.TransactionRequest{})
+		}
+		last := &transactions[len(transactions)-1]
+		last.Operations = append(last.Operations, models.TransactionOperation{
+			ID:         fmt.Sprintf("delete-%s-%d", resource.Resource, i+1),
+			Type:       models.OperationDelete,
+			Resource:   resource.Resource,
+			ResourceID: resource.UUID,
+		})
+	}
+	return transactions
+}
+
+// isPortUp reports whether a port is bound to a chassis
+func isPortUp(port *models.LogicalSwitchPort) bool {
+	return port.Up != nil && *port.Up
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// fakePastTopology returns its topology, or that no snapshot was taken
+type fakePastTopology struct {
+	topology *Topology
+}
+
+func (f *fakePastTopology) TopologyAt(ctx context.Context, at time.Time) (*Topology, error) {
+	if f.topology == nil {
+		return nil, ErrTopologySnapshotNotFound
+	}
+	return f.topology, nil
+}
+
+// fakeACLHits returns two samples spanning the requested range with the
+// given packets matched in between
+type fakeACLHits map[string]uint64
+
+func (f fakeACLHits) Stats(ctx context.Context, aclID string, from, to time.Time) (*ACLStats, error) {
+	return &ACLStats{
+		ACLID:          aclID,
+		PacketsInRange: f[aclID],
+		Samples: []*models.ACLStatsSample{
+			{ACLID: aclID, RecordedAt: from},
+			{ACLID: aclID, RecordedAt: to},
+		},
+	}, nil
+}
+
+func newUnusedReportMock() *MockOVNService {
+	up, down := true, false
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetTopology", mock.Anything).Return(&Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-web", Name: "web", Ports: []string{"p-1", "p-2", "p-3", "p-rtr"}},
+			{UUID: "sw-empty", Name: "empty"},
+		},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "p-1", Name: "vm-1", Up: &up},
+			{UUID: "p-2", Name: "vm-2", Up: &down},
+			{UUID: "p-3", Name: "vm-3"},
+			{UUID: "p-rtr", Name: "rtr", Type: "router"},
+		},
+	}, nil)
+	mockOVN.On("ListLogicalSwitches", mock.Anything).Return([]*models.LogicalSwitch{{UUID: "sw-web"}}, nil)
+	mockOVN.On("ListPortGroups", mock.Anything).Return([]*models.PortGroup{}, nil)
+	mockOVN.On("ListACLs", mock.Anything, "sw-web").Return([]*models.ACL{
+		{UUID: "acl-hot", Match: "ip4.src == $web_clients", Action: "allow"},
+		{UUID: "acl-idle", Match: "ip4.src == {$old_clients, $web_clients}", Action: "drop"},
+	}, nil)
+	mockOVN.On("ListAddressSets", mock.Anything).Return([]*models.AddressSet{
+		{UUID: "as-web", Name: "web_clients"},
+		{UUID: "as-old", Name: "old_clients"},
+		{UUID: "as-orphan", Name: "legacy"},
+	}, nil)
+	return mockOVN
+}
+
+func TestUnusedResourceReporter_Report(t *testing.T) {
+	up, down := true, false
+	history := &fakePastTopology{topology: &Topology{Ports: []*models.LogicalSwitchPort{
+		{UUID: "p-1", Up: &down},
+		{UUID: "p-2", Up: &down},
+		{UUID: "p-3", Up: &up},
+	}}}
+	reporter := NewUnusedResourceReporter(newUnusedReportMock(), history, fakeACLHits{"acl-hot": 42})
+
+	report, err := reporter.Report(context.Background(), 30, true)
+	require.NoError(t, err)
+	assert.Equal(t, 30, report.IdleDays)
+	assert.Empty(t, report.Skipped)
+
+	var flagged []string
+	for _, resource := range report.Resources {
+		flagged = append(flagged, resource.Kind+":"+resource.UUID)
+	}
+	assert.Equal(t, []string{
+		"empty_switch:sw-empty",
+		"unbound_port:p-2", // p-3 was bound 30 days ago, p-1 is bound now
+		"idle_acl:acl-idle",
+		"orphaned_address_set:as-orphan",
+	}, flagged)
+	assert.Equal(t, 1, report.Summary[UnusedIdleACL])
+
+	require.Len(t, report.Cleanup, 1)
+	ops := report.Cleanup[0].Operations
+	require.Len(t, ops, 4)
+	assert.Equal(t, models.TransactionOperation{
+		ID: "delete-switch-1", Type: models.OperationDelete, Resource: models.ResourceSwitch, ResourceID: "sw-empty",
+	}, ops[0])
+	assert.Equal(t, models.ResourceAddressSet, ops[3].Resource)
+	assert.NoError(t, ValidateTransactionOperations(ops))
+}
+
+func TestUnusedResourceReporter_SkippedChecks(t *testing.T) {
+	reporter := NewUnusedResourceReporter(newUnusedReportMock(), nil, nil)
+	report, err := reporter.Report(context.Background(), 7, false)
+	require.NoError(t, err)
+	assert.Contains(t, report.Skipped, UnusedUnboundPort)
+	assert.Contains(t, report.Skipped, UnusedIdleACL)
+	assert.Nil(t, report.Cleanup)
+	assert.Equal(t, map[string]int{UnusedEmptySwitch: 1, UnusedOrphanAddressSet: 1}, report.Summary)
+
+	// Without a snapshot old enough, ports can't be shown to be unbound
+	reporter = NewUnusedResourceReporter(newUnusedReportMock(), &fakePastTopology{}, nil)
+	report, err = reporter.Report(context.Background(), 7, false)
+	require.NoError(t, err)
+	assert.Contains(t, report.Skipped[UnusedUnboundPort], "no topology snapshot")
+
+	_, err = reporter.Report(context.Background(), 0, false)
+	assert.True(t, errors.Is(err, ErrInvalidReportQuery))
+}
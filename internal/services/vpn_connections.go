@@ -0,0 +1,617 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/secrets"
+)
+
+// Router policy priorities of VPN connections: traffic between the local
+// and remote subnets is allowed above the drop of any other traffic to the
+// remote subnets
+const (
+	vpnDropPriority  = 30000
+	vpnAllowPriority = 30001
+)
+
+var (
+	// ErrVPNConnectionNotFound is returned for VPN connections that don't
+	// belong to the caller's tenant
+	ErrVPNConnectionNotFound = errors.New("VPN connection not found")
+
+	// ErrInvalidVPNConnection is wrapped by validation errors of VPN
+	// connections
+	ErrInvalidVPNConnection = errors.New("invalid VPN connection")
+
+	// ErrVPNConnectionConflict is returned for names taken within the
+	// tenant, and remote subnets another connection of the router routes
+	ErrVPNConnectionConflict = errors.New("VPN connection conflict")
+)
+
+// VPNConnectionStore keeps the VPN connections of tenant routers, shared by
+// the API's replicas. *db.DB implements it.
+type VPNConnectionStore interface {
+	// CreateVPNConnection records conn unless its tenant has a connection
+	// of the same name, reporting whether it did
+	CreateVPNConnection(ctx context.Context, conn *models.VPNConnection) (bool, error)
+	// GetVPNConnection returns a VPN connection, nil when there's none
+	// with the ID
+	GetVPNConnection(ctx context.Context, id string) (*models.VPNConnection, error)
+	ListVPNConnections(ctx context.Context, tenantID string) ([]*models.VPNConnection, error)
+	UpdateVPNConnection(ctx context.Context, conn *models.VPNConnection) error
+	UpdateVPNConnectionStatus(ctx context.Context, id string, status *models.VPNTunnelStatus) error
+	DeleteVPNConnection(ctx context.Context, id string) error
+}
+
+// VPNTunnelReport is the state of a connection's tunnel as a status
+// collector reports it
+type VPNTunnelReport struct {
+	ConnectionID  string     `json:"connection_id"`
+	State         string     `json:"state"`
+	EstablishedAt *time.Time `json:"established_at,omitempty"`
+	BytesIn       uint64     `json:"bytes_in,omitempty"`
+	BytesOut      uint64     `json:"bytes_out,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+}
+
+// VPNStatusCollector reports the state of the tunnels of VPN connections,
+// from the IPsec gateways terminating them
+type VPNStatusCollector interface {
+	CollectVPNStatus(ctx context.Context, conns []*models.VPNConnection) ([]VPNTunnelReport, error)
+}
+
+// CommandVPNStatusCollector runs a command reading the connections as JSON
+// of the form {"connections": [...]} on its standard input and printing
+// their tunnels' state as {"tunnels": [{"connection_id": "...", "state":
+// "up", ...}]}, e.g. a script running "swanctl --list-sas" on the gateways
+type CommandVPNStatusCollector struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewCommandVPNStatusCollector creates a collector running command, which
+// is killed after timeout
+func NewCommandVPNStatusCollector(command []string, timeout time.Duration) *CommandVPNStatusCollector {
+	return &CommandVPNStatusCollector{command: command, timeout: timeout}
+}
+
+// CollectVPNStatus runs the command with conns and parses its output
+func (c *CommandVPNStatusCollector) CollectVPNStatus(ctx context.Context, conns []*models.VPNConnection) ([]VPNTunnelReport, error) {
+	if len(c.command) == 0 {
+		return nil, errors.New("no VPN status command")
+	}
+	input, err := json.Marshal(map[string]interface{}{"connections": conns})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode VPN connections: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("VPN status command failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("VPN status command failed: %w", err)
+	}
+
+	var doc struct {
+		Tunnels []VPNTunnelReport `json:"tunnels"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("invalid VPN status command output: %w", err)
+	}
+	return doc.Tunnels, nil
+}
+
+// CreateVPNConnectionRequest describes a VPN connection to create
+type CreateVPNConnectionRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Description   string   `json:"description"`
+	RouterID      string   `json:"router_id" binding:"required"`
+	GatewayIP     string   `json:"gateway_ip" binding:"required"`
+	PeerAddress   string   `json:"peer_address" binding:"required"`
+	PSKRef        string   `json:"psk_ref" binding:"required"`
+	LocalSubnets  []string `json:"local_subnets" binding:"required"`
+	RemoteSubnets []string `json:"remote_subnets" binding:"required"`
+}
+
+// UpdateVPNConnectionRequest changes a VPN connection; nil fields are kept
+type UpdateVPNConnectionRequest struct {
+	Description   *string  `json:"description,omitempty"`
+	GatewayIP     *string  `json:"gateway_ip,omitempty"`
+	PeerAddress   *string  `json:"peer_address,omitempty"`
+	PSKRef        *string  `json:"psk_ref,omitempty"`
+	LocalSubnets  []string `json:"local_subnets,omitempty"`
+	RemoteSubnets []string `json:"remote_subnets,omitempty"`
+}
+
+// VPNConnectionService manages IPsec tunnels from tenant routers to
+// on-premises peers. The tunnels are terminated by gateways outside OVN;
+// on the router, a connection is rendered as static routes of its remote
+// subnets via the gateway and router policies allowing only traffic
+// between its local and remote subnets through it, all tagged with the
+// connection's ID.
+type VPNConnectionService struct {
+	store     VPNConnectionStore
+	ovn       OVNServiceInterface
+	collector VPNStatusCollector
+	events    EventPublisher
+	interval  time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+
+	// mu keeps concurrent changes from rendering a router's connections
+	// over each other
+	mu sync.Mutex
+}
+
+// NewVPNConnectionService creates a service rendering connections with
+// ovn, which checks the routers belong to the caller's tenant. collector
+// may be nil, in which case tunnel status isn't tracked; it's collected
+// every interval otherwise.
+func NewVPNConnectionService(store VPNConnectionStore, ovn OVNServiceInterface, collector VPNStatusCollector,
+	interval time.Duration, logger *zap.Logger) *VPNConnectionService {
+	return &VPNConnectionService{
+		store:     store,
+		ovn:       ovn,
+		collector: collector,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetEvents publishes tunnels going up and down to events
+func (s *VPNConnectionService) SetEvents(events EventPublisher) {
+	s.events = events
+}
+
+// Create validates a connection, records it for the context's tenant and
+// renders it on its router
+func (s *VPNConnectionService) Create(ctx context.Context, req *CreateVPNConnectionRequest, user string) (*models.VPNConnection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	conn := &models.VPNConnection{
+		ID:            uuid.New().String(),
+		TenantID:      getTenantFromContext(ctx),
+		Name:          req.Name,
+		Description:   req.Description,
+		RouterID:      req.RouterID,
+		GatewayIP:     req.GatewayIP,
+		PeerAddress:   req.PeerAddress,
+		PSKRef:        req.PSKRef,
+		LocalSubnets:  req.LocalSubnets,
+		RemoteSubnets: req.RemoteSubnets,
+		CreatedBy:     user,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := validateVPNConnection(conn); err != nil {
+		return nil, err
+	}
+	router, err := s.ovn.GetLogicalRouter(ctx, req.RouterID)
+	if err != nil {
+		return nil, err
+	}
+	conn.RouterID = router.UUID
+	if err := s.checkRemoteSubnets(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	created, err := s.store.CreateVPNConnection(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: a VPN connection named %q exists already", ErrVPNConnectionConflict, conn.Name)
+	}
+	if err := s.render(ctx, conn); err != nil {
+		if err := s.store.DeleteVPNConnection(ctx, conn.ID); err != nil {
+			s.logger.Error("Failed to delete VPN connection that could not be rendered",
+				zap.String("vpn_connection_id", conn.ID),
+				zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("VPN connection created",
+		zap.String("vpn_connection_id", conn.ID),
+		zap.String("router_id", conn.RouterID),
+		zap.String("peer_address", conn.PeerAddress),
+		zap.String("tenant_id", conn.TenantID))
+	return conn, nil
+}
+
+// Get returns a connection of the context's tenant, or any connection
+// without a tenant
+func (s *VPNConnectionService) Get(ctx context.Context, id string) (*models.VPNConnection, error) {
+	conn, err := s.store.GetVPNConnection(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return nil, ErrVPNConnectionNotFound
+	}
+	if tenantID := getTenantFromContext(ctx); tenantID != "" && conn.TenantID != tenantID {
+		return nil, ErrVPNConnectionNotFound
+	}
+	return conn, nil
+}
+
+// List lists the connections of the context's tenant, or all of them
+// without a tenant
+func (s *VPNConnectionService) List(ctx context.Context) ([]*models.VPNConnection, error) {
+	return s.store.ListVPNConnections(ctx, getTenantFromContext(ctx))
+}
+
+// Update changes a connection and renders it again. When the new
+// configuration can't be rendered, the previous one is restored.
+func (s *VPNConnectionService) Update(ctx context.Context, id string, req *UpdateVPNConnectionRequest) (*models.VPNConnection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	conn := *previous
+	if req.Description != nil {
+		conn.Description = *req.Description
+	}
+	if req.GatewayIP != nil {
+		conn.GatewayIP = *req.GatewayIP
+	}
+	if req.PeerAddress != nil {
+		conn.PeerAddress = *req.PeerAddress
+	}
+	if req.PSKRef != nil {
+		conn.PSKRef = *req.PSKRef
+	}
+	if req.LocalSubnets != nil {
+		conn.LocalSubnets = req.LocalSubnets
+	}
+	if req.RemoteSubnets != nil {
+		conn.RemoteSubnets = req.RemoteSubnets
+	}
+	conn.UpdatedAt = s.now().UTC()
+	if err := validateVPNConnection(&conn); err != nil {
+		return nil, err
+	}
+	if err := s.checkRemoteSubnets(ctx, &conn); err != nil {
+		return nil, err
+	}
+
+	if err := s.unrender(ctx, previous); err != nil {
+		return nil, err
+	}
+	if err := s.render(ctx, &conn); err != nil {
+		if restoreErr := s.render(ctx, previous); restoreErr != nil {
+			s.logger.Error("Failed to restore VPN connection",
+				zap.String("vpn_connection_id", id),
+				zap.Error(restoreErr))
+		}
+		return nil, err
+	}
+	if err := s.store.UpdateVPNConnection(ctx, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// Delete removes a connection's routes and policies from its router and
+// deletes it
+func (s *VPNConnectionService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.unrender(ctx, conn); err != nil {
+		return err
+	}
+	if err := s.store.DeleteVPNConnection(ctx, conn.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("VPN connection deleted",
+		zap.String("vpn_connection_id", conn.ID),
+		zap.String("tenant_id", conn.TenantID))
+	return nil
+}
+
+// Run collects the status of every tunnel every interval until ctx is
+// done. It returns at once without a collector or with a zero interval.
+func (s *VPNConnectionService) Run(ctx context.Context) {
+	if s.collector == nil || s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckAll(ctx); err != nil {
+				s.logger.Error("Failed to collect VPN tunnel status", zap.Error(err))
+			}
+		}
+	}
+}
+
+// CheckAll collects the status of every connection's tunnel and records
+// it; connections the collector doesn't report are unknown. Tunnels coming
+// up or going down are published.
+func (s *VPNConnectionService) CheckAll(ctx context.Context) error {
+	if s.collector == nil {
+		return nil
+	}
+	conns, err := s.store.ListVPNConnections(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(conns) == 0 {
+		return nil
+	}
+
+	// The collector isn't told the status collected last time
+	input := make([]*models.VPNConnection, len(conns))
+	for i, conn := range conns {
+		copied := *conn
+		copied.Status = nil
+		input[i] = &copied
+	}
+	reports, err := s.collector.CollectVPNStatus(ctx, input)
+	if err != nil {
+		return err
+	}
+	byConnection := make(map[string]VPNTunnelReport, len(reports))
+	for _, report := range reports {
+		byConnection[report.ConnectionID] = report
+	}
+
+	checkedAt := s.now().UTC()
+	for _, conn := range conns {
+		status := &models.VPNTunnelStatus{State: models.VPNTunnelUnknown, CheckedAt: checkedAt}
+		if report, ok := byConnection[conn.ID]; ok {
+			status.State = tunnelState(report.State)
+			status.EstablishedAt = report.EstablishedAt
+			status.BytesIn = report.BytesIn
+			status.BytesOut = report.BytesOut
+			status.Detail = report.Detail
+		}
+		if err := s.store.UpdateVPNConnectionStatus(ctx, conn.ID, status); err != nil {
+			s.logger.Error("Failed to record VPN tunnel status",
+				zap.String("vpn_connection_id", conn.ID),
+				zap.Error(err))
+			continue
+		}
+
+		previous := models.VPNTunnelUnknown
+		if conn.Status != nil {
+			previous = conn.Status.State
+		}
+		switch {
+		case status.State == previous:
+		case status.State == models.VPNTunnelUp:
+			s.publish(ctx, models.EventVPNTunnelUp, conn, status)
+		case status.State == models.VPNTunnelDown:
+			s.publish(ctx, models.EventVPNTunnelDown, conn, status)
+		}
+	}
+	return nil
+}
+
+// tunnelState returns a reported state, unknown when it isn't one of the
+// tunnel states
+func tunnelState(state string) string {
+	switch state {
+	case models.VPNTunnelUp, models.VPNTunnelConnecting, models.VPNTunnelDown:
+		return state
+	default:
+		return models.VPNTunnelUnknown
+	}
+}
+
+func (s *VPNConnectionService) publish(ctx context.Context, eventType string, conn *models.VPNConnection, status *models.VPNTunnelStatus) {
+	s.logger.Info("VPN tunnel state changed",
+		zap.String("vpn_connection_id", conn.ID),
+		zap.String("state", status.State))
+	if s.events == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"connection_id": conn.ID,
+		"name":          conn.Name,
+		"router_id":     conn.RouterID,
+		"peer_address":  conn.PeerAddress,
+		"state":         status.State,
+	}
+	if status.Detail != "" {
+		data["detail"] = status.Detail
+	}
+	s.events.Publish(ctx, &models.Event{
+		Type:     eventType,
+		TenantID: conn.TenantID,
+		Data:     data,
+	})
+}
+
+// validateVPNConnection checks the addresses of a connection. Its subnets
+// are routed via the gateway, so they must be of the gateway's family.
+func validateVPNConnection(conn *models.VPNConnection) error {
+	if strings.TrimSpace(conn.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidVPNConnection)
+	}
+	if conn.PeerAddress == "" {
+		return fmt.Errorf("%w: peer_address is required", ErrInvalidVPNConnection)
+	}
+	if !secrets.IsReference(conn.PSKRef) {
+		return fmt.Errorf("%w: psk_ref must reference a secret, e.g. vault:secret/data/vpn#psk, not contain it", ErrInvalidVPNConnection)
+	}
+	gateway, err := netip.ParseAddr(conn.GatewayIP)
+	if err != nil {
+		return fmt.Errorf("%w: invalid gateway_ip %q", ErrInvalidVPNConnection, conn.GatewayIP)
+	}
+	if len(conn.LocalSubnets) == 0 || len(conn.RemoteSubnets) == 0 {
+		return fmt.Errorf("%w: local_subnets and remote_subnets are required", ErrInvalidVPNConnection)
+	}
+	for field, subnets := range map[string][]string{"local_subnets": conn.LocalSubnets, "remote_subnets": conn.RemoteSubnets} {
+		for i, subnet := range subnets {
+			prefix, err := netip.ParsePrefix(subnet)
+			if err != nil {
+				return fmt.Errorf("%w: invalid subnet %q in %s", ErrInvalidVPNConnection, subnet, field)
+			}
+			if prefix.Addr().Is4() != gateway.Is4() {
+				return fmt.Errorf("%w: subnet %s in %s isn't of the family of gateway_ip %s", ErrInvalidVPNConnection, subnet, field, conn.GatewayIP)
+			}
+			subnets[i] = prefix.Masked().String()
+		}
+	}
+	for _, local := range conn.LocalSubnets {
+		for _, remote := range conn.RemoteSubnets {
+			if netip.MustParsePrefix(local).Overlaps(netip.MustParsePrefix(remote)) {
+				return fmt.Errorf("%w: local subnet %s overlaps remote subnet %s", ErrInvalidVPNConnection, local, remote)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRemoteSubnets checks no other connection of conn's router routes
+// its remote subnets
+func (s *VPNConnectionService) checkRemoteSubnets(ctx context.Context, conn *models.VPNConnection) error {
+	others, err := s.store.ListVPNConnections(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == conn.ID || other.RouterID != conn.RouterID {
+			continue
+		}
+		for _, remote := range conn.RemoteSubnets {
+			for _, taken := range other.RemoteSubnets {
+				a, errA := netip.ParsePrefix(remote)
+				b, errB := netip.ParsePrefix(taken)
+				if errA == nil && errB == nil && a.Overlaps(b) {
+					return fmt.Errorf("%w: remote subnet %s overlaps %s of VPN connection %s", ErrVPNConnectionConflict, remote, taken, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// render creates the static routes and router policies of a connection.
+// What was created is removed again when it fails.
+func (s *VPNConnectionService) render(ctx context.Context, conn *models.VPNConnection) error {
+	routes := make([]*models.StaticRoute, len(conn.RemoteSubnets))
+	for i, remote := range conn.RemoteSubnets {
+		routes[i] = &models.StaticRoute{
+			IPPrefix:    remote,
+			Nexthop:     conn.GatewayIP,
+			ExternalIDs: map[string]string{models.VPNConnectionKey: conn.ID},
+		}
+	}
+	if _, err := s.ovn.CreateStaticRoutes(ctx, conn.RouterID, routes); err != nil {
+		return fmt.Errorf("failed to create routes of VPN connection %s: %w", conn.Name, err)
+	}
+
+	for _, policy := range vpnPolicies(conn) {
+		if _, err := s.ovn.CreateRouterPolicy(ctx, conn.RouterID, policy); err != nil {
+			if err := s.unrender(ctx, conn); err != nil {
+				s.logger.Error("Failed to remove partially rendered VPN connection",
+					zap.String("vpn_connection_id", conn.ID),
+					zap.Error(err))
+			}
+			return fmt.Errorf("failed to create policies of VPN connection %s: %w", conn.Name, err)
+		}
+	}
+	return nil
+}
+
+// vpnPolicies returns the router policies of a connection: traffic from
+// its local to its remote subnets is allowed, other traffic to its remote
+// subnets is dropped
+func vpnPolicies(conn *models.VPNConnection) []*models.RouterPolicy {
+	ip := "ip4"
+	if gateway, err := netip.ParseAddr(conn.GatewayIP); err == nil && gateway.Is6() {
+		ip = "ip6"
+	}
+	remote := "{" + strings.Join(conn.RemoteSubnets, ", ") + "}"
+	local := "{" + strings.Join(conn.LocalSubnets, ", ") + "}"
+	externalIDs := func() map[string]string {
+		return map[string]string{models.VPNConnectionKey: conn.ID}
+	}
+
+	return []*models.RouterPolicy{
+		{
+			Priority:    vpnAllowPriority,
+			Match:       fmt.Sprintf("%s.src == %s && %s.dst == %s", ip, local, ip, remote),
+			Action:      "allow",
+			ExternalIDs: externalIDs(),
+		},
+		{
+			Priority:    vpnDropPriority,
+			Match:       fmt.Sprintf("%s.dst == %s", ip, remote),
+			Action:      "drop",
+			ExternalIDs: externalIDs(),
+		},
+	}
+}
+
+// unrender deletes the static routes and router policies tagged with a
+// connection's ID from its router
+func (s *VPNConnectionService) unrender(ctx context.Context, conn *models.VPNConnection) error {
+	routes, err := s.ovn.ListStaticRoutes(ctx, conn.RouterID)
+	if err != nil {
+		return fmt.Errorf("failed to list routes of router %s: %w", conn.RouterID, err)
+	}
+	for _, route := range routes {
+		if route.ExternalIDs[models.VPNConnectionKey] != conn.ID {
+			continue
+		}
+		if err := s.ovn.DeleteStaticRoute(ctx, route.UUID); err != nil {
+			return fmt.Errorf("failed to delete route %s of VPN connection %s: %w", route.IPPrefix, conn.Name, err)
+		}
+	}
+
+	policies, err := s.ovn.ListRouterPolicies(ctx, conn.RouterID)
+	if err != nil {
+		return fmt.Errorf("failed to list policies of router %s: %w", conn.RouterID, err)
+	}
+	for _, policy := range policies {
+		if policy.ExternalIDs[models.VPNConnectionKey] != conn.ID {
+			continue
+		}
+		if err := s.ovn.DeleteRouterPolicy(ctx, policy.UUID); err != nil {
+			return fmt.Errorf("failed to delete policy %q of VPN connection %s: %w", policy.Match, conn.Name, err)
+		}
+	}
+	return nil
+}
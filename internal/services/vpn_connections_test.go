@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryVPNConnectionStore keeps VPN connections in memory, in the order
+// they were created
+type memoryVPNConnectionStore struct {
+	conns []*models.VPNConnection
+}
+
+func (s *memoryVPNConnectionStore) CreateVPNConnection(ctx context.Context, conn *models.VPNConnection) (bool, error) {
+	for _, other := range s.conns {
+		if other.TenantID == conn.TenantID && other.Name == conn.Name {
+			return false, nil
+		}
+	}
+	stored := *conn
+	s.conns = append(s.conns, &stored)
+	return true, nil
+}
+
+func (s *memoryVPNConnectionStore) GetVPNConnection(ctx context.Context, id string) (*models.VPNConnection, error) {
+	for _, conn := range s.conns {
+		if conn.ID == id {
+			stored := *conn
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryVPNConnectionStore) ListVPNConnections(ctx context.Context, tenantID string) ([]*models.VPNConnection, error) {
+	conns := []*models.VPNConnection{}
+	for _, conn := range s.conns {
+		if tenantID == "" || conn.TenantID == tenantID {
+			stored := *conn
+			conns = append(conns, &stored)
+		}
+	}
+	return conns, nil
+}
+
+func (s *memoryVPNConnectionStore) UpdateVPNConnection(ctx context.Context, conn *models.VPNConnection) error {
+	for i, other := range s.conns {
+		if other.ID == conn.ID {
+			stored := *conn
+			stored.Status = other.Status
+			s.conns[i] = &stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryVPNConnectionStore) UpdateVPNConnectionStatus(ctx context.Context, id string, status *models.VPNTunnelStatus) error {
+	for _, conn := range s.conns {
+		if conn.ID == id {
+			conn.Status = status
+		}
+	}
+	return nil
+}
+
+func (s *memoryVPNConnectionStore) DeleteVPNConnection(ctx context.Context, id string) error {
+	for i, conn := range s.conns {
+		if conn.ID == id {
+			s.conns = append(s.conns[:i], s.conns[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// fakeVPNCollector reports the tunnels it's given
+type fakeVPNCollector struct {
+	tunnels []VPNTunnelReport
+	seen    []*models.VPNConnection
+}
+
+func (c *fakeVPNCollector) CollectVPNStatus(ctx context.Context, conns []*models.VPNConnection) ([]VPNTunnelReport, error) {
+	c.seen = conns
+	return c.tunnels, nil
+}
+
+func vpnRequest() *CreateVPNConnectionRequest {
+	return &CreateVPNConnectionRequest{
+		Name:          "dc1",
+		RouterID:      "tenant-router",
+		GatewayIP:     "10.0.0.254",
+		PeerAddress:   "198.51.100.7",
+		PSKRef:        "vault:secret/data/vpn#dc1",
+		LocalSubnets:  []string{"10.0.0.0/24", "10.0.1.0/24"},
+		RemoteSubnets: []string{"192.168.0.0/16"},
+	}
+}
+
+func TestVPNConnectionService_Create(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	mockOVN := new(MockOVNService)
+	mockOVN.On("GetLogicalRouter", mock.Anything, "tenant-router").Return(&models.LogicalRouter{UUID: "lr-1", Name: "tenant-router"}, nil)
+	store := &memoryVPNConnectionStore{}
+	service := NewVPNConnectionService(store, mockOVN, nil, 0, zap.NewNop())
+
+	var routes []*models.StaticRoute
+	mockOVN.On("CreateStaticRoutes", mock.Anything, "lr-1", mock.Anything).Run(func(args mock.Arguments) {
+		routes = args.Get(2).([]*models.StaticRoute)
+	}).Return([]*models.StaticRoute{}, nil).Once()
+	var policies []*models.RouterPolicy
+	mockOVN.On("CreateRouterPolicy", mock.Anything, "lr-1", mock.Anything).Run(func(args mock.Arguments) {
+		policies = append(policies, args.Get(2).(*models.RouterPolicy))
+	}).Return(&models.RouterPolicy{}, nil).Twice()
+
+	conn, err := service.Create(ctx, vpnRequest(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", conn.TenantID)
+	assert.Equal(t, "lr-1", conn.RouterID)
+	mockOVN.AssertExpectations(t)
+
+	require.Len(t, routes, 1)
+	assert.Equal(t, "192.168.0.0/16", routes[0].IPPrefix)
+	assert.Equal(t, "10.0.0.254", routes[0].Nexthop)
+	assert.Equal(t, conn.ID, routes[0].ExternalIDs[models.VPNConnectionKey])
+	require.Len(t, policies, 2)
+	assert.Equal(t, "ip4.src == {10.0.0.0/24, 10.0.1.0/24} && ip4.dst == {192.168.0.0/16}", policies[0].Match)
+	assert.Equal(t, "allow", policies[0].Action)
+	assert.Equal(t, "ip4.dst == {192.168.0.0/16}", policies[1].Match)
+	assert.Equal(t, "drop", policies[1].Action)
+	assert.Greater(t, policies[0].Priority, policies[1].Priority)
+
+	// Names are unique within the tenant, remote subnets within the router
+	_, err = service.Create(ctx, vpnRequest(), "alice")
+	assert.ErrorIs(t, err, ErrVPNConnectionConflict)
+	other := vpnRequest()
+	other.Name = "dc2"
+	other.RemoteSubnets = []string{"192.168.10.0/24"}
+	_, err = service.Create(ctx, other, "alice")
+	assert.ErrorIs(t, err, ErrVPNConnectionConflict)
+
+	_, err = service.Get(ContextWithTenant(context.Background(), "other"), conn.ID)
+	assert.ErrorIs(t, err, ErrVPNConnectionNotFound)
+
+	// Deleting removes what's tagged with the connection only
+	mockOVN.On("ListStaticRoutes", mock.Anything, "lr-1").Return([]*models.StaticRoute{
+		{UUID: "route-1", IPPrefix: "192.168.0.0/16", ExternalIDs: map[string]string{models.VPNConnectionKey: conn.ID}},
+		{UUID: "route-default", IPPrefix: "0.0.0.0/0"},
+	}, nil)
+	mockOVN.On("ListRouterPolicies", mock.Anything, "lr-1").Return([]*models.RouterPolicy{
+		{UUID: "policy-1", ExternalIDs: map[string]string{models.VPNConnectionKey: conn.ID}},
+		{UUID: "policy-2", ExternalIDs: map[string]string{models.VPNConnectionKey: "other"}},
+	}, nil)
+	mockOVN.On("DeleteStaticRoute", mock.Anything, "route-1").Return(nil).Once()
+	mockOVN.On("DeleteRouterPolicy", mock.Anything, "policy-1").Return(nil).Once()
+	require.NoError(t, service.Delete(ctx, conn.ID))
+	assert.Empty(t, store.conns)
+	mockOVN.AssertExpectations(t)
+}
+
+func TestVPNConnectionService_Validation(t *testing.T) {
+	ctx := ContextWithTenant(context.Background(), "acme")
+	service := NewVPNConnectionService(&memoryVPNConnectionStore{}, new(MockOVNService), nil, 0, zap.NewNop())
+
+	for name, change := range map[string]func(req *CreateVPNConnectionRequest){
+		"plain PSK":         func(req *CreateVPNConnectionRequest) { req.PSKRef = "s3cret" },
+		"invalid gateway":   func(req *CreateVPNConnectionRequest) { req.GatewayIP = "gateway" },
+		"invalid subnet":    func(req *CreateVPNConnectionRequest) { req.RemoteSubnets = []string{"192.168.0.0"} },
+		"mixed families":    func(req *CreateVPNConnectionRequest) { req.RemoteSubnets = []string{"fd00:1::/64"} },
+		"overlapping local": func(req *CreateVPNConnectionRequest) { req.RemoteSubnets = []string{"10.0.0.0/16"} },
+		"no remote subnets": func(req *CreateVPNConnectionRequest) { req.RemoteSubnets = nil },
+		"missing peer":      func(req *CreateVPNConnectionRequest) { req.PeerAddress = "" },
+	} {
+		req := vpnRequest()
+		change(req)
+		_, err := service.Create(ctx, req, "alice")
+		assert.ErrorIs(t, err, ErrInvalidVPNConnection, name)
+	}
+}
+
+func TestVPNConnectionService_CheckAll(t *testing.T) {
+	ctx := context.Background()
+	established := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	store := &memoryVPNConnectionStore{conns: []*models.VPNConnection{
+		{ID: "vpn-1", TenantID: "acme", Name: "dc1", PSKRef: "env:DC1_PSK"},
+		{ID: "vpn-2", TenantID: "acme", Name: "dc2", Status: &models.VPNTunnelStatus{State: models.VPNTunnelUp}},
+		{ID: "vpn-3", TenantID: "acme", Name: "dc3", Status: &models.VPNTunnelStatus{State: models.VPNTunnelConnecting}},
+	}}
+	collector := &fakeVPNCollector{tunnels: []VPNTunnelReport{
+		{ConnectionID: "vpn-1", State: "up", EstablishedAt: &established, BytesIn: 100},
+		{ConnectionID: "vpn-2", State: "down", Detail: "peer unreachable"},
+	}}
+	events := &recordingPublisher{}
+	service := NewVPNConnectionService(store, new(MockOVNService), collector, time.Minute, zap.NewNop())
+	service.SetEvents(events)
+
+	require.NoError(t, service.CheckAll(ctx))
+	assert.Equal(t, models.VPNTunnelUp, store.conns[0].Status.State)
+	assert.Equal(t, uint64(100), store.conns[0].Status.BytesIn)
+	assert.Equal(t, &established, store.conns[0].Status.EstablishedAt)
+	assert.Equal(t, "peer unreachable", store.conns[1].Status.Detail)
+	assert.Equal(t, models.VPNTunnelUnknown, store.conns[2].Status.State, "not reported")
+	require.Len(t, collector.seen, 3)
+	assert.Nil(t, collector.seen[1].Status)
+
+	require.Len(t, events.events, 2)
+	assert.Equal(t, models.EventVPNTunnelUp, events.events[0].Type)
+	assert.Equal(t, "acme", events.events[0].TenantID)
+	assert.Equal(t, models.EventVPNTunnelDown, events.events[1].Type)
+	assert.Equal(t, "dc2", events.events[1].Data["name"])
+
+	// Unchanged states aren't published again
+	require.NoError(t, service.CheckAll(ctx))
+	assert.Len(t, events.events, 2)
+}
+
+func TestCommandVPNStatusCollector(t *testing.T) {
+	conns := []*models.VPNConnection{{ID: "vpn-1", Name: "dc1"}}
+	collector := NewCommandVPNStatusCollector([]string{"sh", "-c",
+		`grep -q '"id":"vpn-1"' && echo '{"tunnels": [{"connection_id": "vpn-1", "state": "up", "bytes_out": 42}]}'`}, 5*time.Second)
+	tunnels, err := collector.CollectVPNStatus(context.Background(), conns)
+	require.NoError(t, err)
+	assert.Equal(t, []VPNTunnelReport{{ConnectionID: "vpn-1", State: "up", BytesOut: 42}}, tunnels)
+
+	collector = NewCommandVPNStatusCollector([]string{"sh", "-c", "echo 'swanctl: connection refused' >&2; exit 1"}, 5*time.Second)
+	_, err = collector.CollectVPNStatus(context.Background(), conns)
+	assert.ErrorContains(t, err, "connection refused")
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/metering"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+var (
+	// ErrWebhookNotFound is returned for unknown webhook IDs and for
+	// webhooks of another tenant
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidWebhook is wrapped by validation errors of webhooks
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// Headers of webhook requests, besides the signature
+const (
+	WebhookEventHeader    = "X-OVNCP-Event"
+	WebhookDeliveryHeader = "X-OVNCP-Delivery"
+)
+
+// defaultDeliveryLimit is how many deliveries are listed by default
+const defaultDeliveryLimit = 50
+
+// EventPublisher publishes events. *WebhookService, *NotificationService and
+// *EventBus implement it.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *models.Event)
+}
+
+// WebhookStore persists webhooks and their deliveries. *db.DB implements it.
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+
+	// GetWebhook returns a webhook, nil when there's none with the ID
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+
+	// DeleteWebhook deletes a webhook and its deliveries
+	DeleteWebhook(ctx context.Context, webhookID string) error
+
+	// ListWebhooks lists the global webhooks and those of every tenant
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+
+	// ListWebhookDeliveries lists a webhook's deliveries, most recent
+	// first, up to limit
+	ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error)
+
+	// ListDueWebhookDeliveries lists the pending deliveries whose next
+	// attempt is due at now
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time) ([]*models.WebhookDelivery, error)
+}
+
+// WebhookService manages webhooks and delivers events to them. Events are
+// recorded as deliveries and POSTed in the background, retried with
+// exponential backoff until they succeed or run out of attempts.
+type WebhookService struct {
+	store        WebhookStore
+	client       *http.Client
+	interval     time.Duration // How often due retries are looked for
+	mu           sync.RWMutex  // Guards maxAttempts and retryBackoff, which SetRetries changes
+	maxAttempts  int
+	retryBackoff time.Duration // Delay before the first retry, doubled for each next one
+	logger       *zap.Logger
+	now          func() time.Time
+	wake         chan struct{}
+}
+
+// NewWebhookService creates a service making up to maxAttempts attempts per
+// delivery
+func NewWebhookService(store WebhookStore, interval time.Duration, maxAttempts int, retryBackoff time.Duration, logger *zap.Logger) *WebhookService {
+	return &WebhookService{
+		store:        store,
+		client:       &http.Client{Timeout: 30 * time.Second},
+		interval:     interval,
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
+		logger:       logger,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// SetRetries changes the attempts per delivery and the delay before the
+// first retry, for deliveries in progress too
+func (s *WebhookService) SetRetries(maxAttempts int, retryBackoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxAttempts, s.retryBackoff = maxAttempts, retryBackoff
+}
+
+// Create creates a webhook in a tenant, or a global one when tenantID is
+// empty. It returns the secret payloads are signed with, generated unless
+// the webhook has one.
+func (s *WebhookService) Create(ctx context.Context, tenantID string, webhook *models.Webhook) (*models.Webhook, string, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, "", err
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, "", fmt.Errorf("failed to generate secret: %w", err)
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	webhook.ID = uuid.New().String()
+	webhook.TenantID = tenantID
+	webhook.CreatedAt = s.now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+	if err := s.store.CreateWebhook(ctx, webhook); err != nil {
+		return nil, "", fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, webhook.Secret, nil
+}
+
+// Get returns a webhook of a tenant, or a global one when tenantID is empty
+func (s *WebhookService) Get(ctx context.Context, tenantID, webhookID string) (*models.Webhook, error) {
+	webhook, err := s.store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if webhook == nil || webhook.TenantID != tenantID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// List returns the webhooks of a tenant, or the global ones when tenantID
+// is empty
+func (s *WebhookService) List(ctx context.Context, tenantID string) ([]*models.Webhook, error) {
+	webhooks, err := s.store.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	result := []*models.Webhook{}
+	for _, webhook := range webhooks {
+		if webhook.TenantID == tenantID {
+			result = append(result, webhook)
+		}
+	}
+	return result, nil
+}
+
+// Update replaces the URL, description, events and enabled state of a
+// webhook, and its secret when updates has one
+func (s *WebhookService) Update(ctx context.Context, tenantID, webhookID string, updates *models.Webhook) (*models.Webhook, error) {
+	if err := validateWebhook(updates); err != nil {
+		return nil, err
+	}
+	webhook, err := s.Get(ctx, tenantID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.URL = updates.URL
+	webhook.Description = updates.Description
+	webhook.Events = updates.Events
+	webhook.Enabled = updates.Enabled
+	if updates.Secret != "" {
+		webhook.Secret = updates.Secret
+	}
+	webhook.UpdatedAt = s.now().UTC()
+	if err := s.store.UpdateWebhook(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Delete deletes a webhook and its delivery history
+func (s *WebhookService) Delete(ctx context.Context, tenantID, webhookID string) error {
+	if _, err := s.Get(ctx, tenantID, webhookID); err != nil {
+		return err
+	}
+	if err := s.store.DeleteWebhook(ctx, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the most recent deliveries of a webhook, up to limit
+func (s *WebhookService) Deliveries(ctx context.Context, tenantID, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, tenantID, webhookID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func validateWebhook(webhook *models.Webhook) error {
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, eventType := range webhook.Events {
+		if !containsString(models.EventTypes, eventType) {
+			return fmt.Errorf("%w: unknown event type %s", ErrInvalidWebhook, eventType)
+		}
+	}
+	return nil
+}
+
+// Publish records a delivery of event for each enabled webhook subscribed
+// to it: the global ones and those of the event's tenant. Failures are
+// logged, so publishing never fails what caused the event.
+func (s *WebhookService) Publish(ctx context.Context, event *models.Event) {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.now().UTC()
+	}
+	logger := s.logger.With(zap.String("event_id", event.ID), zap.String("event_type", event.Type))
+
+	webhooks, err := s.store.ListWebhooks(ctx)
+	if err != nil {
+		logger.Warn("Failed to list webhooks for event", zap.Error(err))
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("Failed to encode event", zap.Error(err))
+		return
+	}
+
+	queued := false
+	for _, webhook := range webhooks {
+		if !webhook.Enabled || !webhook.Subscribes(event.Type) ||
+			(webhook.TenantID != "" && webhook.TenantID != event.TenantID) {
+			continue
+		}
+		nextAttempt := event.OccurredAt
+		delivery := &models.WebhookDelivery{
+			ID:            uuid.New().String(),
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &nextAttempt,
+			CreatedAt:     event.OccurredAt,
+		}
+		if err := s.store.CreateWebhookDelivery(ctx, delivery); err != nil {
+			logger.Warn("Failed to record webhook delivery",
+				zap.String("webhook_id", webhook.ID),
+				zap.Error(err))
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run delivers events as they're published, and retries failed deliveries
+// as they come due, until ctx is done
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		if err := s.DeliverDue(ctx); err != nil {
+			s.logger.Error("Failed to deliver webhooks", zap.Error(err))
+		}
+	}
+}
+
+// DeliverDue attempts the deliveries that are due
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	deliveries, err := s.store.ListDueWebhookDeliveries(ctx, s.now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to list due deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		webhook, err := s.store.GetWebhook(ctx, delivery.WebhookID)
+		switch {
+		case err != nil:
+			return fmt.Errorf("failed to get webhook %s: %w", delivery.WebhookID, err)
+		case webhook == nil:
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.LastError = "webhook deleted"
+			delivery.NextAttemptAt = nil
+		default:
+			s.attempt(ctx, webhook, delivery)
+		}
+		if err := s.store.UpdateWebhookDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to update delivery %s: %w", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// attempt POSTs a delivery's payload and records the outcome, scheduling
+// the next attempt of failed ones
+func (s *WebhookService) attempt(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+	delivery.ResponseCode, delivery.LastError = 0, ""
+
+	code, err := s.post(ctx, webhook, delivery)
+	delivery.ResponseCode = code
+	now := s.now().UTC()
+	s.mu.RLock()
+	maxAttempts, retryBackoff := s.maxAttempts, s.retryBackoff
+	s.mu.RUnlock()
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(retryBackoff << (delivery.Attempts - 1))
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+}
+
+// post sends a payload, failing on responses other than 2xx
+func (s *WebhookService) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write(delivery.Payload)
+	req.Header.Set(metering.WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/metering"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// memoryWebhookStore keeps webhooks and deliveries in memory
+type memoryWebhookStore struct {
+	webhooks   map[string]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func newMemoryWebhookStore() *memoryWebhookStore {
+	return &memoryWebhookStore{webhooks: make(map[string]*models.Webhook)}
+}
+
+func (s *memoryWebhookStore) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	s.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (s *memoryWebhookStore) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	return s.webhooks[webhookID], nil
+}
+
+func (s *memoryWebhookStore) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	s.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (s *memoryWebhookStore) DeleteWebhook(ctx context.Context, webhookID string) error {
+	delete(s.webhooks, webhookID)
+	return nil
+}
+
+func (s *memoryWebhookStore) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	for _, webhook := range s.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].URL < webhooks[j].URL })
+	return webhooks, nil
+}
+
+func (s *memoryWebhookStore) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *memoryWebhookStore) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return nil
+}
+
+func (s *memoryWebhookStore) ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if s.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, s.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (s *memoryWebhookStore) ListDueWebhookDeliveries(ctx context.Context, now time.Time) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func TestWebhookService_Manage(t *testing.T) {
+	store := newMemoryWebhookStore()
+	service := NewWebhookService(store, time.Second, 3, time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	_, _, err := service.Create(ctx, "acme", &models.Webhook{URL: "ftp://example.com"})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, _, err = service.Create(ctx, "acme", &models.Webhook{URL: "https://example.com", Events: []string{"switch.created"}})
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	webhook, secret, err := service.Create(ctx, "acme", &models.Webhook{URL: "https://example.com/acme", Enabled: true})
+	require.NoError(t, err)
+	assert.Len(t, secret, 64, "a secret is generated")
+	assert.Equal(t, "acme", webhook.TenantID)
+	_, _, err = service.Create(ctx, "", &models.Webhook{URL: "https://example.com/global", Secret: "s3cret"})
+	require.NoError(t, err)
+
+	// Tenants and global webhooks are kept apart
+	listed, err := service.List(ctx, "acme")
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+	_, err = service.Get(ctx, "other", webhook.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+	_, err = service.Get(ctx, "", webhook.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+	assert.ErrorIs(t, service.Delete(ctx, "other", webhook.ID), ErrWebhookNotFound)
+
+	updated, err := service.Update(ctx, "acme", webhook.ID, &models.Webhook{
+		URL: "https://example.com/acme/v2", Events: []string{models.EventBackupFailed},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/acme/v2", updated.URL)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, secret, updated.Secret, "the secret is kept unless replaced")
+
+	require.NoError(t, service.Delete(ctx, "acme", webhook.ID))
+	_, err = service.Get(ctx, "acme", webhook.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	store := newMemoryWebhookStore()
+	for _, webhook := range []*models.Webhook{
+		{ID: "global", URL: "https://a.example.com", Enabled: true},
+		{ID: "acme", URL: "https://b.example.com", TenantID: "acme", Enabled: true},
+		{ID: "acme-backups", URL: "https://c.example.com", TenantID: "acme", Enabled: true, Events: []string{models.EventBackupCompleted}},
+		{ID: "disabled", URL: "https://d.example.com", Enabled: false},
+		{ID: "other", URL: "https://e.example.com", TenantID: "other", Enabled: true},
+	} {
+		store.webhooks[webhook.ID] = webhook
+	}
+	service := NewWebhookService(store, time.Second, 3, time.Minute, zap.NewNop())
+
+	service.Publish(context.Background(), &models.Event{Type: models.EventResourceCreated, TenantID: "acme"})
+	service.Publish(context.Background(), &models.Event{Type: models.EventOVNDisconnected})
+
+	var routed []string
+	for _, delivery := range store.deliveries {
+		routed = append(routed, delivery.EventType+":"+delivery.WebhookID)
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		assert.NotNil(t, delivery.NextAttemptAt)
+	}
+	assert.Equal(t, []string{
+		"resource.created:global",
+		"resource.created:acme",
+		"ovn.disconnected:global",
+	}, routed)
+
+	var event models.Event
+	require.NoError(t, json.Unmarshal(store.deliveries[0].Payload, &event))
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "acme", event.TenantID)
+}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	var status = http.StatusInternalServerError
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhookStore()
+	store.webhooks["hook"] = &models.Webhook{ID: "hook", URL: server.URL, Secret: "s3cret", Enabled: true}
+	store.webhooks["gone"] = &models.Webhook{ID: "gone", URL: server.URL, Enabled: true}
+	service := NewWebhookService(store, time.Second, 3, time.Minute, zap.NewNop())
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	service.Publish(ctx, &models.Event{Type: models.EventBackupFailed})
+	delete(store.webhooks, "gone")
+	require.Len(t, store.deliveries, 2)
+	delivery, orphan := store.deliveries[0], store.deliveries[1]
+	if delivery.WebhookID == "gone" {
+		delivery, orphan = orphan, delivery
+	}
+
+	// Failed attempts are retried after a backoff doubling each time
+	require.NoError(t, service.DeliverDue(ctx))
+	assert.Equal(t, models.WebhookDeliveryFailed, orphan.Status, "deliveries of deleted webhooks fail")
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+	assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+
+	require.NoError(t, service.DeliverDue(ctx))
+	assert.Equal(t, 1, delivery.Attempts, "retries wait for their time")
+	now = now.Add(time.Minute)
+	require.NoError(t, service.DeliverDue(ctx))
+	assert.Equal(t, now.Add(2*time.Minute), *delivery.NextAttemptAt)
+
+	status = http.StatusNoContent
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, service.DeliverDue(ctx))
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, now, *delivery.DeliveredAt)
+	assert.Nil(t, delivery.NextAttemptAt)
+
+	require.Len(t, received, 3)
+	last := received[2]
+	assert.Equal(t, models.EventBackupFailed, last.Header.Get(WebhookEventHeader))
+	assert.Equal(t, delivery.ID, last.Header.Get(WebhookDeliveryHeader))
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(bodies[2])
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), last.Header.Get(metering.WebhookSignatureHeader))
+
+	// Deliveries are given up on after the last attempt
+	status = http.StatusBadGateway
+	service.Publish(ctx, &models.Event{Type: models.EventBackupFailed})
+	retried := store.deliveries[2]
+	for i := 0; i < 3; i++ {
+		require.NoError(t, service.DeliverDue(ctx))
+		now = now.Add(time.Hour)
+	}
+	assert.Equal(t, models.WebhookDeliveryFailed, retried.Status)
+	assert.Equal(t, 3, retried.Attempts)
+	assert.Contains(t, retried.LastError, "502")
+
+	history, err := service.Deliveries(ctx, "", "hook", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.WebhookDelivery{retried, delivery}, history)
+}
@@ -21,7 +21,7 @@ type PolicyTemplate struct {
 type TemplateVariable struct {
 	Name         string      `json:"name"`
 	Description  string      `json:"description"`
-	Type         string      `json:"type"` // string, number, ipv4, ipv6, cidr, port, mac
+	Type         string      `json:"type"` // string, number, ipv4, ipv6, cidr, port, mac, service
 	Required     bool        `json:"required"`
 	Default      interface{} `json:"default,omitempty"`
 	Validation   string      `json:"validation,omitempty"`
@@ -0,0 +1,8 @@
+#!/bin/sh
+set -e
+
+/usr/share/ovn/scripts/ovn-ctl start_northd \
+    --db-nb-create-insecure-remote=yes \
+    --db-sb-create-insecure-remote=yes
+
+exec tail -F /var/log/ovn/ovn-northd.log
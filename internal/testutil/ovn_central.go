@@ -0,0 +1,391 @@
+// Package testutil holds the fixtures of tests running against real OVN: an
+// ovn-central container to connect clients and services to, and the
+// contract every OVNServiceInterface implementation must honour.
+//
+// A package's tests share one container, started by Main from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testutil.Main(m))
+//	}
+//
+//	func TestSomething(t *testing.T) {
+//		svc := testutil.SharedOVNCentral(t).NewOVNService(t)
+//		...
+//	}
+//
+// Tests that change OVN in ways others mustn't see start their own with
+// NewOVNCentral, removed when the test ends.
+//
+// Containers are run through testcontainers-go, whose reaper also removes
+// those of test binaries that didn't get to stop them. They run the image
+// built from ovn-central/Dockerfile, or the one named by
+// OVNCP_TEST_OVN_IMAGE. Setting OVNCP_TEST_OVN_NB (and optionally
+// OVNCP_TEST_OVN_SB) to the databases of a running, disposable OVN
+// deployment tests against it instead. Without either docker or such a
+// deployment, tests asking for ovn-central are skipped, unless
+// OVNCP_TEST_OVN_REQUIRED is true.
+package testutil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+const (
+	// OVNNorthboundEnv names the northbound database of an OVN deployment
+	// to test against instead of starting a container
+	OVNNorthboundEnv = "OVNCP_TEST_OVN_NB"
+	// OVNSouthboundEnv names the southbound database of that deployment
+	OVNSouthboundEnv = "OVNCP_TEST_OVN_SB"
+	// OVNImageEnv names an ovn-central image to run instead of building
+	// ovn-central/Dockerfile. It must serve the northbound database on
+	// 6641 and the southbound one on 6642 without TLS.
+	OVNImageEnv = "OVNCP_TEST_OVN_IMAGE"
+	// OVNRequiredEnv, set to true, makes Main fail rather than skip the
+	// tests needing ovn-central when it can't be started, as CI does
+	OVNRequiredEnv = "OVNCP_TEST_OVN_REQUIRED"
+
+	ovnCentralRepo  = "ovncp-test/ovn-central"
+	ovnCentralTag   = "latest"
+	ovnStartTimeout = 2 * time.Minute
+
+	northboundPort = "6641/tcp"
+	southboundPort = "6642/tcp"
+)
+
+// ErrNoContainerRuntime is returned by StartOVNCentral when docker isn't
+// reachable and no deployment is named by OVNNorthboundEnv
+var ErrNoContainerRuntime = errors.New("docker is not available and " + OVNNorthboundEnv + " is not set")
+
+// OVNCentral is a running ovn-central: its northbound and southbound
+// databases and ovn-northd
+type OVNCentral struct {
+	NorthboundDB string
+	SouthboundDB string
+
+	container testcontainers.Container // Nil for deployments named by OVNNorthboundEnv
+}
+
+// StartOVNCentral starts an ovn-central container, or picks the deployment
+// named by OVNNorthboundEnv, and waits until its northbound database
+// accepts clients. The caller stops it.
+func StartOVNCentral(ctx context.Context) (*OVNCentral, error) {
+	central, err := startOVNCentral(ctx)
+	if err != nil {
+		if central != nil {
+			central.Stop()
+		}
+		return nil, err
+	}
+	return central, nil
+}
+
+// NewOVNCentral starts an ovn-central of the test's own, removed when the
+// test ends, or picks the deployment named by OVNNorthboundEnv. The test is
+// skipped when docker isn't reachable, unless OVNRequiredEnv is set.
+func NewOVNCentral(t testing.TB) *OVNCentral {
+	t.Helper()
+
+	central, err := startOVNCentral(context.Background())
+	if central != nil && central.container != nil {
+		testcontainers.CleanupContainer(t, central.container)
+	}
+	if errors.Is(err, ErrNoContainerRuntime) && !ovnRequired() {
+		t.Skipf("ovn-central unavailable: %v", err)
+	}
+	require.NoError(t, err, "ovn-central unavailable")
+	return central
+}
+
+// startOVNCentral is StartOVNCentral, also returning the ovn-central that
+// failed to start so that its container can be removed
+func startOVNCentral(ctx context.Context) (*OVNCentral, error) {
+	if nb := os.Getenv(OVNNorthboundEnv); nb != "" {
+		central := &OVNCentral{NorthboundDB: nb, SouthboundDB: os.Getenv(OVNSouthboundEnv)}
+		if err := central.wait(ctx); err != nil {
+			return nil, err
+		}
+		return central, nil
+	}
+
+	if err := dockerHealth(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContainerRuntime, err)
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        os.Getenv(OVNImageEnv),
+		ExposedPorts: []string{northboundPort, southboundPort},
+		WaitingFor:   northboundReady{},
+	}
+	if req.Image == "" {
+		req.FromDockerfile = testcontainers.FromDockerfile{
+			Context:   ovnCentralDir(),
+			Repo:      ovnCentralRepo,
+			Tag:       ovnCentralTag,
+			KeepImage: true,
+		}
+	}
+
+	ctr, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if ctr == nil {
+		return nil, fmt.Errorf("failed to start ovn-central: %w", err)
+	}
+	central := &OVNCentral{container: ctr}
+	if err == nil {
+		central.NorthboundDB, err = containerDB(ctx, ctr, northboundPort)
+	}
+	if err == nil {
+		central.SouthboundDB, err = containerDB(ctx, ctr, southboundPort)
+	}
+	if err != nil {
+		if logs := central.logTail(ctx, 20); logs != "" {
+			err = fmt.Errorf("%w\n%s", err, logs)
+		}
+		return central, err
+	}
+	return central, nil
+}
+
+// Stop removes the container of an ovn-central started by StartOVNCentral.
+// Deployments named by OVNNorthboundEnv are left running.
+func (c *OVNCentral) Stop() error {
+	if c.container == nil {
+		return nil
+	}
+	return testcontainers.TerminateContainer(c.container)
+}
+
+// Config returns the configuration of clients of the deployment
+func (c *OVNCentral) Config() *config.OVNConfig {
+	return &config.OVNConfig{
+		ClusterName:  "test",
+		NorthboundDB: c.NorthboundDB,
+		SouthboundDB: c.SouthboundDB,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// NewClient returns a client connected to the northbound database, closed
+// when the test ends
+func (c *OVNCentral) NewClient(t testing.TB) *ovn.Client {
+	t.Helper()
+
+	client, err := ovn.NewClient(c.Config())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	require.NoError(t, client.Connect(ctx))
+	return client
+}
+
+// NewOVNService returns the service the API serves over a client of the
+// northbound database
+func (c *OVNCentral) NewOVNService(t testing.TB) *services.OVNService {
+	t.Helper()
+	return services.NewOVNService(c.NewClient(t))
+}
+
+// Nbctl runs ovn-nbctl in the container, for tests to check what OVN stored
+// without going through the client under test
+func (c *OVNCentral) Nbctl(ctx context.Context, args ...string) (string, error) {
+	if c.container == nil {
+		return "", fmt.Errorf("ovn-nbctl is only available in containers started by StartOVNCentral")
+	}
+	code, reader, err := c.container.Exec(ctx, append([]string{"ovn-nbctl"}, args...), tcexec.Multiplexed())
+	if err != nil {
+		return "", fmt.Errorf("failed to run ovn-nbctl: %w", err)
+	}
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the output of ovn-nbctl: %w", err)
+	}
+	if code != 0 {
+		return "", fmt.Errorf("ovn-nbctl exited with %d: %s", code, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// wait connects to the northbound database until it succeeds or the start
+// timeout expires
+func (c *OVNCentral) wait(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ovnStartTimeout)
+	defer cancel()
+
+	for {
+		err := c.ping(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("OVN northbound database %s not ready: %w", c.NorthboundDB, err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (c *OVNCentral) ping(ctx context.Context) error {
+	client, err := ovn.NewClient(c.Config())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return client.Connect(ctx)
+}
+
+// dockerHealth checks that testcontainers-go reaches docker. Without a
+// docker host to find, it panics rather than returning an error.
+func dockerHealth(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return err
+	}
+	// Health closes the provider
+	return provider.Health(ctx)
+}
+
+// logTail returns the last lines of the container's logs, or nothing if
+// they can't be read
+func (c *OVNCentral) logTail(ctx context.Context, lines int) string {
+	logs, err := c.container.Logs(ctx)
+	if err != nil {
+		return ""
+	}
+	defer logs.Close()
+
+	var tail []string
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		tail = append(tail, scanner.Text())
+		if len(tail) > lines {
+			tail = tail[1:]
+		}
+	}
+	return strings.Join(tail, "\n")
+}
+
+// containerDB returns the address clients reach the database a container
+// serves on port at
+func containerDB(ctx context.Context, target wait.StrategyTarget, port string) (string, error) {
+	host, err := target.Host(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the host of ovn-central: %w", err)
+	}
+	mapped, err := target.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the address of port %s: %w", port, err)
+	}
+	return "tcp:" + net.JoinHostPort(host, mapped.Port()), nil
+}
+
+// northboundReady waits for the northbound database of a container to
+// accept clients, which it only does once ovsdb-server has loaded it
+type northboundReady struct{}
+
+func (northboundReady) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	nb, err := containerDB(ctx, target, northboundPort)
+	if err != nil {
+		return err
+	}
+	return (&OVNCentral{NorthboundDB: nb}).wait(ctx)
+}
+
+var shared struct {
+	central *OVNCentral
+	err     error
+}
+
+// Main starts the ovn-central shared by the tests of a package, runs them
+// and stops it, returning the exit code. It is meant to be called from
+// TestMain. When ovn-central can't be started, the tests still run, and
+// those calling SharedOVNCentral are skipped, unless OVNRequiredEnv is
+// set.
+func Main(m *testing.M) int {
+	shared.central, shared.err = StartOVNCentral(context.Background())
+	if shared.err != nil {
+		if ovnRequired() {
+			fmt.Fprintf(os.Stderr, "ovn-central unavailable: %v\n", shared.err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "ovn-central unavailable, skipping the tests that need it: %v\n", shared.err)
+	}
+
+	code := m.Run()
+
+	if shared.central != nil {
+		if err := shared.central.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to stop ovn-central: %v\n", err)
+		}
+	}
+	return code
+}
+
+// SharedOVNCentral returns the ovn-central started by Main, skipping the
+// test if there is none
+func SharedOVNCentral(t testing.TB) *OVNCentral {
+	t.Helper()
+	if shared.central == nil {
+		reason := "testutil.Main was not called from TestMain"
+		if shared.err != nil {
+			reason = shared.err.Error()
+		}
+		t.Skipf("ovn-central unavailable: %s", reason)
+	}
+	return shared.central
+}
+
+// ovnRequired reports whether tests needing ovn-central fail rather than
+// skip when it can't be started
+func ovnRequired() bool {
+	required, _ := strconv.ParseBool(os.Getenv(OVNRequiredEnv))
+	return required
+}
+
+// UniqueName returns prefix with a random suffix, for resources of tests
+// sharing a deployment
+func UniqueName(prefix string) string {
+	return prefix + "-" + uuid.New().String()[:8]
+}
+
+// ovnCentralDir returns the directory of the ovn-central Dockerfile, next
+// to this file
+func ovnCentralDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "ovn-central")
+}
@@ -0,0 +1,791 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// contractLabel is the external ID marking the resources of one contract
+// test, so that tests sharing a deployment only see their own
+const contractLabel = "ovncp-contract"
+
+// RunOVNServiceContract checks that an OVNServiceInterface implementation
+// behaves as the API expects of OVN: the service over a real ovn-central,
+// and stand-ins such as the sandbox backend. newService returns the
+// implementation for a subtest; it may be shared between subtests, each
+// creating resources with unique names and deleting them when it ends.
+func RunOVNServiceContract(t *testing.T, newService func(t *testing.T) services.OVNServiceInterface) {
+	t.Run("LogicalSwitches", func(t *testing.T) { testSwitchContract(t, newService(t)) })
+	t.Run("LogicalSwitchesPage", func(t *testing.T) { testSwitchPageContract(t, newService(t)) })
+	t.Run("Ports", func(t *testing.T) { testPortContract(t, newService(t)) })
+	t.Run("ACLs", func(t *testing.T) { testACLContract(t, newService(t)) })
+	t.Run("LogicalRouters", func(t *testing.T) { testRouterContract(t, newService(t)) })
+	t.Run("StaticRoutes", func(t *testing.T) { testStaticRouteContract(t, newService(t)) })
+	t.Run("LoadBalancers", func(t *testing.T) { testLoadBalancerContract(t, newService(t)) })
+	t.Run("Transactions", func(t *testing.T) { testTransactionContract(t, newService(t)) })
+	t.Run("Topology", func(t *testing.T) { testTopologyContract(t, newService(t)) })
+	t.Run("NAT", func(t *testing.T) { testNATContract(t, newService(t)) })
+	t.Run("PortGroups", func(t *testing.T) { testPortGroupContract(t, newService(t)) })
+	t.Run("AddressSets", func(t *testing.T) { testAddressSetContract(t, newService(t)) })
+	t.Run("DHCPOptions", func(t *testing.T) { testDHCPContract(t, newService(t)) })
+	t.Run("QoS", func(t *testing.T) { testQoSContract(t, newService(t)) })
+	t.Run("DNS", func(t *testing.T) { testDNSContract(t, newService(t)) })
+	t.Run("RouterPolicies", func(t *testing.T) { testRouterPolicyContract(t, newService(t)) })
+	t.Run("Mirrors", func(t *testing.T) { testMirrorContract(t, newService(t)) })
+	t.Run("Sampling", func(t *testing.T) { testSamplingContract(t, newService(t)) })
+}
+
+// matchName returns a unique name that OVN match expressions can refer to,
+// as @name or $name, which hyphens can't be part of
+func matchName(prefix string) string {
+	return strings.ReplaceAll(UniqueName(prefix), "-", "_")
+}
+
+// skipUnlessSupported skips the test when listing the resources of a
+// feature failed, as it does on OVN releases whose schema lacks them
+func skipUnlessSupported(t *testing.T, feature string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Skipf("%s not supported by the OVN under test: %v", feature, err)
+	}
+}
+
+// transact runs a transaction of one operation, returning the ID of the
+// resource it created
+func transact(ctx context.Context, svc services.OVNServiceInterface, op services.TransactionOp) (string, error) {
+	ops := []services.TransactionOp{op}
+	if err := svc.ExecuteTransaction(ctx, ops); err != nil {
+		return "", err
+	}
+	return ops[0].ResourceID, nil
+}
+
+// createSwitch creates a switch labelled for the test, deleted with its
+// dependents when the test ends
+func createSwitch(t *testing.T, svc services.OVNServiceInterface, label string) *models.LogicalSwitch {
+	t.Helper()
+	ls, err := svc.CreateLogicalSwitch(context.Background(), &models.LogicalSwitch{
+		Name:        UniqueName("ls"),
+		ExternalIDs: map[string]string{contractLabel: label},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteLogicalSwitch(ovn.WithCascade(context.Background()), ls.UUID) })
+	return ls
+}
+
+// createRouter creates a router, deleted with its ports when the test ends
+func createRouter(t *testing.T, svc services.OVNServiceInterface) *models.LogicalRouter {
+	t.Helper()
+	lr, err := svc.CreateLogicalRouter(context.Background(), &models.LogicalRouter{Name: UniqueName("lr")})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteLogicalRouter(ovn.WithCascade(context.Background()), lr.UUID) })
+	return lr
+}
+
+func testSwitchContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	ls := createSwitch(t, svc, UniqueName("switches"))
+	assert.NotEmpty(t, ls.UUID)
+	assert.False(t, ls.CreatedAt.IsZero())
+
+	// Switches are found by UUID or by name
+	got, err := svc.GetLogicalSwitch(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, ls.Name, got.Name)
+	got, err = svc.GetLogicalSwitch(ctx, ls.Name)
+	require.NoError(t, err)
+	assert.Equal(t, ls.UUID, got.UUID)
+
+	switches, err := svc.ListLogicalSwitches(ctx)
+	require.NoError(t, err)
+	assert.True(t, containsSwitch(switches, ls.UUID), "listed switches include the new one")
+
+	renamed := UniqueName("ls")
+	updated, err := svc.UpdateLogicalSwitch(ctx, ls.UUID, &models.LogicalSwitch{
+		Name:        renamed,
+		OtherConfig: map[string]string{"mcast_snoop": "true"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, renamed, updated.Name)
+	assert.Equal(t, "true", updated.OtherConfig["mcast_snoop"])
+	assert.Equal(t, ls.ExternalIDs["created_at"], updated.ExternalIDs["created_at"], "updates keep the creation time")
+
+	require.NoError(t, svc.DeleteLogicalSwitch(ctx, ls.UUID))
+	_, err = svc.GetLogicalSwitch(ctx, ls.UUID)
+	assert.Error(t, err)
+}
+
+func testSwitchPageContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	label := UniqueName("page")
+	var names []string
+	for i := 0; i < 3; i++ {
+		names = append(names, createSwitch(t, svc, label).Name)
+	}
+	createSwitch(t, svc, UniqueName("other"))
+
+	opts := &models.ListOptions{ExternalIDs: map[string]string{contractLabel: label}, Limit: 2}
+	page, total, err := svc.ListLogicalSwitchesPage(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 2)
+	assert.Less(t, page[0].Name, page[1].Name, "pages are sorted by name")
+
+	opts.Offset = 2
+	page, total, err = svc.ListLogicalSwitchesPage(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 1)
+	assert.Contains(t, names, page[0].Name)
+
+	_, _, err = svc.ListLogicalSwitchesPage(ctx, &models.ListOptions{SortBy: "bogus"})
+	assert.Error(t, err, "unknown sort fields are refused")
+}
+
+func testPortContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	ls := createSwitch(t, svc, UniqueName("ports"))
+
+	name := UniqueName("vm")
+	port, err := svc.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{
+		Name:      name,
+		Addresses: []string{"0a:00:00:00:00:01 10.0.0.10"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, port.UUID)
+	assert.Equal(t, ls.UUID, port.SwitchID)
+
+	_, err = svc.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: name})
+	assert.Error(t, err, "port names are unique")
+
+	other, err := svc.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: UniqueName("vm")})
+	require.NoError(t, err)
+
+	ports, err := svc.ListPorts(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Len(t, ports, 2)
+
+	updated, err := svc.UpdatePort(ctx, port.UUID, &models.LogicalSwitchPort{
+		Addresses: []string{"0a:00:00:00:00:01 10.0.0.11"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0a:00:00:00:00:01 10.0.0.11"}, updated.Addresses)
+
+	got, err := svc.GetPort(ctx, port.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, name, got.Name)
+	assert.Equal(t, updated.Addresses, got.Addresses)
+
+	require.NoError(t, svc.DeletePort(ctx, other.UUID))
+	ports, err = svc.ListPorts(ctx, ls.UUID)
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+	assert.Equal(t, port.UUID, ports[0].UUID)
+}
+
+func testACLContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	ls := createSwitch(t, svc, UniqueName("acls"))
+
+	acl, err := svc.CreateACL(ctx, ls.UUID, &models.ACL{
+		Priority:  1000,
+		Direction: "to-lport",
+		Match:     "tcp.dst == 80",
+		Action:    "allow-related",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, acl.UUID)
+
+	_, err = svc.CreateACL(ctx, ls.UUID, &models.ACL{
+		Priority:  1000,
+		Direction: "to-lport",
+		Match:     "tcp.dst == 80",
+		Action:    "bogus",
+	})
+	assert.Error(t, err, "invalid actions are refused")
+
+	acls, err := svc.ListACLs(ctx, ls.UUID)
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	assert.Equal(t, acl.UUID, acls[0].UUID)
+
+	updated, err := svc.UpdateACL(ctx, acl.UUID, &models.ACL{Priority: 2000})
+	require.NoError(t, err)
+	assert.Equal(t, 2000, updated.Priority)
+	assert.Equal(t, "tcp.dst == 80", updated.Match, "updates keep the fields left unset")
+
+	require.NoError(t, svc.DeleteACL(ctx, acl.UUID))
+	acls, err = svc.ListACLs(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, acls)
+}
+
+func testRouterContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	lr := createRouter(t, svc)
+
+	got, err := svc.GetLogicalRouter(ctx, lr.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, lr.Name, got.Name)
+
+	_, err = svc.CreateRouterPort(ctx, lr.UUID, &models.LogicalRouterPort{
+		Name:     UniqueName("lrp"),
+		MAC:      "00:00:00:00:ff:01",
+		Networks: []string{"10.0.0.1/24"},
+	})
+	require.NoError(t, err)
+
+	err = svc.DeleteLogicalRouter(ctx, lr.UUID)
+	assert.Error(t, err, "routers with ports are only deleted on cascade")
+
+	require.NoError(t, svc.DeleteLogicalRouter(ovn.WithCascade(ctx), lr.UUID))
+	_, err = svc.GetLogicalRouter(ctx, lr.UUID)
+	assert.Error(t, err)
+}
+
+func testStaticRouteContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	lr := createRouter(t, svc)
+
+	routes, err := svc.CreateStaticRoutes(ctx, lr.UUID, []*models.StaticRoute{
+		{IPPrefix: "10.2.0.0/16", Nexthop: "10.0.0.254"},
+		{IPPrefix: "10.1.0.0/16", Nexthop: "10.0.0.254"},
+	})
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+
+	listed, err := svc.ListStaticRoutes(ctx, lr.UUID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, "10.1.0.0/16", listed[0].IPPrefix, "routes are sorted by prefix")
+
+	require.NoError(t, svc.DeleteStaticRoute(ctx, listed[0].UUID))
+	listed, err = svc.ListStaticRoutes(ctx, lr.UUID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "10.2.0.0/16", listed[0].IPPrefix)
+}
+
+func testLoadBalancerContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	protocol := "tcp"
+	lb, err := svc.CreateLoadBalancer(ctx, &models.LoadBalancer{
+		Name:     UniqueName("lb"),
+		VIPs:     map[string]string{"10.0.0.100:80": "10.0.1.10:80,10.0.1.11:80"},
+		Protocol: &protocol,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteLoadBalancer(context.Background(), lb.UUID) })
+
+	_, err = svc.CreateLoadBalancer(ctx, &models.LoadBalancer{Name: UniqueName("lb")})
+	assert.Error(t, err, "load balancers need a VIP")
+
+	updated, err := svc.UpdateLoadBalancer(ctx, lb.UUID, &models.LoadBalancer{
+		VIPs: map[string]string{"10.0.0.100:80": "10.0.1.10:80"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.1.10:80", updated.VIPs["10.0.0.100:80"])
+
+	got, err := svc.GetLoadBalancer(ctx, lb.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, lb.Name, got.Name)
+	assert.Equal(t, updated.VIPs, got.VIPs)
+
+	require.NoError(t, svc.DeleteLoadBalancer(ctx, lb.UUID))
+	_, err = svc.GetLoadBalancer(ctx, lb.UUID)
+	assert.Error(t, err)
+}
+
+func testTransactionContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	label := UniqueName("tx")
+
+	// Operations refer to resources created earlier in the transaction
+	ops := []services.TransactionOp{
+		{Operation: models.OperationCreate, ResourceType: "switch", Ref: "ls", Data: &models.LogicalSwitch{
+			Name:        UniqueName("ls"),
+			ExternalIDs: map[string]string{contractLabel: label},
+		}},
+		{Operation: models.OperationCreate, ResourceType: "port", SwitchID: "$ls", Data: &models.LogicalSwitchPort{
+			Name: UniqueName("vm"),
+		}},
+	}
+	require.NoError(t, svc.ExecuteTransaction(ctx, ops))
+	require.NotEmpty(t, ops[0].ResourceID)
+	t.Cleanup(func() { svc.DeleteLogicalSwitch(ovn.WithCascade(context.Background()), ops[0].ResourceID) })
+
+	ports, err := svc.ListPorts(ctx, ops[0].ResourceID)
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+	assert.Equal(t, ops[1].ResourceID, ports[0].UUID)
+
+	// A failing operation leaves nothing of the transaction behind
+	failedLabel := UniqueName("tx")
+	failing := []services.TransactionOp{
+		{Operation: models.OperationCreate, ResourceType: "switch", Ref: "ls", Data: &models.LogicalSwitch{
+			Name:        UniqueName("ls"),
+			ExternalIDs: map[string]string{contractLabel: failedLabel},
+		}},
+		{Operation: models.OperationCreate, ResourceType: "port", SwitchID: "$missing", Data: &models.LogicalSwitchPort{
+			Name: UniqueName("vm"),
+		}},
+	}
+	err = svc.ExecuteTransaction(ctx, failing)
+	var txErr *ovn.TxError
+	require.ErrorAs(t, err, &txErr)
+	assert.Equal(t, 1, txErr.Index)
+
+	_, total, err := svc.ListLogicalSwitchesPage(ctx, &models.ListOptions{
+		ExternalIDs: map[string]string{contractLabel: failedLabel},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func testTopologyContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	ls := createSwitch(t, svc, UniqueName("topology"))
+	lr := createRouter(t, svc)
+
+	topology, err := svc.GetTopology(ctx)
+	require.NoError(t, err)
+	assert.True(t, containsSwitch(topology.Switches, ls.UUID), "the topology includes the switch")
+
+	found := false
+	for _, router := range topology.Routers {
+		found = found || router.UUID == lr.UUID
+	}
+	assert.True(t, found, "the topology includes the router")
+}
+
+func containsSwitch(switches []*models.LogicalSwitch, id string) bool {
+	for _, ls := range switches {
+		if ls.UUID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// routerNATs returns the NAT rules of a router, which the topology lists
+func routerNATs(t *testing.T, svc services.OVNServiceInterface, routerID string) []models.NAT {
+	t.Helper()
+	topology, err := svc.GetTopology(context.Background())
+	require.NoError(t, err)
+	for _, router := range topology.Routers {
+		if router.UUID == routerID {
+			return router.NAT
+		}
+	}
+	t.Fatalf("router %s missing from the topology", routerID)
+	return nil
+}
+
+func testNATContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	lr := createRouter(t, svc)
+
+	natID, err := transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "nat", RouterID: lr.UUID,
+		Data: &models.NAT{Type: "dnat_and_snat", ExternalIP: "172.16.0.10", LogicalIP: "10.0.0.10"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, natID)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "nat", RouterID: lr.UUID,
+		Data: &models.NAT{Type: "dnat", ExternalIP: "172.16.0.11", LogicalIP: "10.0.0.0/24"},
+	})
+	assert.Error(t, err, "only SNAT rules translate subnets")
+
+	nats := routerNATs(t, svc, lr.UUID)
+	require.Len(t, nats, 1)
+	assert.Equal(t, natID, nats[0].UUID)
+	assert.Equal(t, "10.0.0.10", nats[0].LogicalIP)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationUpdate, ResourceType: "nat", ResourceID: natID,
+		Data: &models.NAT{ExternalIP: "172.16.0.20"},
+	})
+	require.NoError(t, err)
+	nats = routerNATs(t, svc, lr.UUID)
+	require.Len(t, nats, 1)
+	assert.Equal(t, "172.16.0.20", nats[0].ExternalIP)
+	assert.Equal(t, "dnat_and_snat", nats[0].Type, "updates keep the fields left unset")
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationDelete, ResourceType: "nat", ResourceID: natID,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, routerNATs(t, svc, lr.UUID))
+}
+
+func testPortGroupContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	owner := map[string]string{contractLabel: UniqueName("port-groups")}
+	t.Cleanup(func() { svc.ReplaceOwnedObjects(context.Background(), owner, nil, nil) })
+	ls := createSwitch(t, svc, owner[contractLabel])
+	port, err := svc.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: UniqueName("vm")})
+	require.NoError(t, err)
+
+	name := matchName("pg")
+	owned, err := svc.ReplaceOwnedObjects(ctx, owner, nil, []*ovn.PortGroupSpec{{
+		PortGroup: &models.PortGroup{Name: name, Ports: []string{port.UUID}},
+		ACLs: []*models.ACL{{
+			Priority:  1000,
+			Direction: "to-lport",
+			Match:     "outport == @" + name + " && tcp.dst == 22",
+			Action:    "allow-related",
+		}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, owned.PortGroups, 1)
+	pg := owned.PortGroups[0]
+	assert.NotEmpty(t, pg.UUID)
+	assert.Equal(t, owner[contractLabel], pg.ExternalIDs[contractLabel], "owned port groups carry the owner")
+
+	pgs, err := svc.ListPortGroups(ctx)
+	require.NoError(t, err)
+	var listed *models.PortGroup
+	for _, candidate := range pgs {
+		if candidate.UUID == pg.UUID {
+			listed = candidate
+		}
+	}
+	require.NotNil(t, listed, "listed port groups include the new one")
+	assert.Equal(t, []string{port.UUID}, listed.Ports)
+
+	acls, err := svc.ListPortGroupACLs(ctx, pg.UUID)
+	require.NoError(t, err)
+	require.Len(t, acls, 1)
+	assert.Equal(t, "allow-related", acls[0].Action)
+
+	// Replacing the owner's objects with none deletes them and their ACLs
+	owned, err = svc.ReplaceOwnedObjects(ctx, owner, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, owned.PortGroups)
+	pgs, err = svc.ListPortGroups(ctx)
+	require.NoError(t, err)
+	for _, candidate := range pgs {
+		assert.NotEqual(t, pg.UUID, candidate.UUID, "replaced port groups are deleted")
+	}
+	_, err = svc.ListPortGroupACLs(ctx, pg.UUID)
+	assert.Error(t, err)
+}
+
+func testAddressSetContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	name := matchName("as")
+	asID, err := transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "address_set",
+		Data: &models.AddressSet{Name: name, Addresses: []string{"10.0.0.10", "10.0.0.11"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		transact(context.Background(), svc, services.TransactionOp{
+			Operation: models.OperationDelete, ResourceType: "address_set", ResourceID: asID,
+		})
+	})
+
+	listed := findAddressSet(t, svc, asID)
+	require.NotNil(t, listed, "listed address sets include the new one")
+	assert.Equal(t, name, listed.Name)
+	assert.ElementsMatch(t, []string{"10.0.0.10", "10.0.0.11"}, listed.Addresses)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationUpdate, ResourceType: "address_set", ResourceID: asID,
+		Data: &models.AddressSet{Addresses: []string{"10.0.0.12"}},
+	})
+	require.NoError(t, err)
+	listed = findAddressSet(t, svc, asID)
+	require.NotNil(t, listed)
+	assert.Equal(t, []string{"10.0.0.12"}, listed.Addresses)
+	assert.Equal(t, name, listed.Name, "updates keep the fields left unset")
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationDelete, ResourceType: "address_set", ResourceID: asID,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, findAddressSet(t, svc, asID))
+}
+
+// findAddressSet returns the listed address set with an ID, nil when
+// there's none
+func findAddressSet(t *testing.T, svc services.OVNServiceInterface, id string) *models.AddressSet {
+	t.Helper()
+	sets, err := svc.ListAddressSets(context.Background())
+	require.NoError(t, err)
+	for _, as := range sets {
+		if as.UUID == id {
+			return as
+		}
+	}
+	return nil
+}
+
+func testDHCPContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	dhcpID, err := transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "dhcp_options",
+		Data: &models.DHCPOptions{
+			CIDR: "10.0.0.0/24",
+			Options: map[string]string{
+				"lease_time": "3600",
+				"router":     "10.0.0.1",
+				"server_id":  "10.0.0.1",
+				"server_mac": "0a:00:00:00:00:fe",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, dhcpID)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "dhcp_options",
+		Data: &models.DHCPOptions{CIDR: "10.0.0.0"},
+	})
+	assert.Error(t, err, "DHCP options need a CIDR")
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationUpdate, ResourceType: "dhcp_options", ResourceID: dhcpID,
+		Data: &models.DHCPOptions{Options: map[string]string{"lease_time": "7200"}},
+	})
+	require.NoError(t, err)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationDelete, ResourceType: "dhcp_options", ResourceID: dhcpID,
+	})
+	require.NoError(t, err)
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationDelete, ResourceType: "dhcp_options", ResourceID: dhcpID,
+	})
+	assert.Error(t, err, "deleted DHCP options are gone")
+}
+
+func testQoSContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	ls := createSwitch(t, svc, UniqueName("qos"))
+
+	qosID, err := transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "qos", SwitchID: ls.UUID,
+		Data: &models.QoS{
+			Priority:  100,
+			Direction: "from-lport",
+			Match:     "inport == \"vm1\"",
+			Bandwidth: map[string]int{"rate": 10000, "burst": 1000},
+		},
+	})
+	if errors.Is(err, ovn.ErrCapabilityUnavailable) {
+		t.Skipf("QoS not supported by the OVN under test: %v", err)
+	}
+	require.NoError(t, err)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationCreate, ResourceType: "qos", SwitchID: ls.UUID,
+		Data: &models.QoS{Priority: 100, Direction: "sideways", Match: "ip4"},
+	})
+	assert.Error(t, err, "invalid directions are refused")
+
+	got, err := svc.GetLogicalSwitch(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{qosID}, got.QoSRules)
+
+	_, err = transact(ctx, svc, services.TransactionOp{
+		Operation: models.OperationDelete, ResourceType: "qos", ResourceID: qosID,
+	})
+	require.NoError(t, err)
+	got, err = svc.GetLogicalSwitch(ctx, ls.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, got.QoSRules)
+}
+
+func testDNSContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	_, err := svc.ListDNS(ctx)
+	skipUnlessSupported(t, "DNS", err)
+	ls := createSwitch(t, svc, UniqueName("dns"))
+
+	dns, err := svc.CreateDNS(ctx, &models.DNS{
+		Records:  map[string]string{"web.example.org": "10.0.0.10"},
+		Switches: []string{ls.UUID},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteDNS(context.Background(), dns.UUID) })
+	assert.NotEmpty(t, dns.UUID)
+
+	_, err = svc.CreateDNS(ctx, &models.DNS{Records: map[string]string{"web.example.org": "bogus"}})
+	assert.Error(t, err, "records must resolve to IP addresses")
+
+	updated, err := svc.UpdateDNS(ctx, dns.UUID, &models.DNS{
+		Records: map[string]string{"web.example.org": "10.0.0.10 10.0.0.11"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.10 10.0.0.11", updated.Records["web.example.org"])
+	assert.Equal(t, []string{ls.UUID}, updated.Switches, "updates keep the fields left unset")
+
+	got, err := svc.GetDNS(ctx, dns.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Records, got.Records)
+
+	require.NoError(t, svc.DeleteDNS(ctx, dns.UUID))
+	_, err = svc.GetDNS(ctx, dns.UUID)
+	assert.Error(t, err)
+}
+
+func testRouterPolicyContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	lr := createRouter(t, svc)
+	_, err := svc.ListRouterPolicies(ctx, lr.UUID)
+	skipUnlessSupported(t, "router policies", err)
+
+	policy, err := svc.CreateRouterPolicy(ctx, lr.UUID, &models.RouterPolicy{
+		Priority: 100,
+		Match:    "ip4.src == 10.0.0.0/24",
+		Action:   "reroute",
+		Nexthops: []string{"10.0.0.254"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, policy.UUID)
+
+	_, err = svc.CreateRouterPolicy(ctx, lr.UUID, &models.RouterPolicy{
+		Priority: 100,
+		Match:    "ip4.src == 10.0.0.0/24",
+		Action:   "drop",
+	})
+	assert.Error(t, err, "policies with the same priority and match are refused")
+
+	_, err = svc.CreateRouterPolicy(ctx, lr.UUID, &models.RouterPolicy{
+		Priority: 200,
+		Match:    "ip4.src == 10.0.1.0/24",
+		Action:   "reroute",
+	})
+	assert.Error(t, err, "reroute needs a nexthop")
+
+	updated, err := svc.UpdateRouterPolicy(ctx, policy.UUID, &models.RouterPolicy{Action: "drop"})
+	require.NoError(t, err)
+	assert.Equal(t, "drop", updated.Action)
+	assert.Empty(t, updated.Nexthops, "only reroute policies have nexthops")
+	assert.Equal(t, 100, updated.Priority, "updates keep the fields left unset")
+
+	policies, err := svc.ListRouterPolicies(ctx, lr.UUID)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, policy.UUID, policies[0].UUID)
+
+	require.NoError(t, svc.DeleteRouterPolicy(ctx, policy.UUID))
+	_, err = svc.GetRouterPolicy(ctx, policy.UUID)
+	assert.Error(t, err)
+	policies, err = svc.ListRouterPolicies(ctx, lr.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func testMirrorContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	_, err := svc.ListMirrors(ctx)
+	skipUnlessSupported(t, "mirrors", err)
+	ls := createSwitch(t, svc, UniqueName("mirrors"))
+	port, err := svc.CreatePort(ctx, ls.UUID, &models.LogicalSwitchPort{Name: UniqueName("vm")})
+	require.NoError(t, err)
+
+	mirror, err := svc.CreateMirror(ctx, &models.Mirror{
+		Name:   UniqueName("mirror"),
+		Type:   "gre",
+		Filter: "both",
+		Sink:   "192.0.2.10",
+		Index:  10,
+		Ports:  []string{port.UUID},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteMirror(context.Background(), mirror.UUID) })
+	assert.NotEmpty(t, mirror.UUID)
+
+	_, err = svc.CreateMirror(ctx, &models.Mirror{Name: mirror.Name, Type: "gre", Filter: "both", Sink: "192.0.2.11"})
+	assert.Error(t, err, "mirror names are unique")
+	_, err = svc.CreateMirror(ctx, &models.Mirror{Name: UniqueName("mirror"), Type: "gre", Filter: "both", Sink: "collector"})
+	assert.Error(t, err, "gre mirrors send to an IP address")
+
+	updated, err := svc.UpdateMirror(ctx, mirror.UUID, &models.Mirror{Filter: "to-lport"})
+	require.NoError(t, err)
+	assert.Equal(t, "to-lport", updated.Filter)
+	assert.Equal(t, "192.0.2.10", updated.Sink, "updates keep the fields left unset")
+
+	// Mirrors are found by UUID or by name
+	got, err := svc.GetMirror(ctx, mirror.Name)
+	require.NoError(t, err)
+	assert.Equal(t, mirror.UUID, got.UUID)
+	assert.Equal(t, []string{port.UUID}, got.Ports)
+	assert.True(t, got.Active, "mirrors of a port are active")
+
+	require.NoError(t, svc.DeleteMirror(ctx, mirror.UUID))
+	_, err = svc.GetMirror(ctx, mirror.UUID)
+	assert.Error(t, err)
+}
+
+func testSamplingContract(t *testing.T, svc services.OVNServiceInterface) {
+	ctx := context.Background()
+	_, err := svc.ListSampleCollectors(ctx)
+	skipUnlessSupported(t, "sampling", err)
+
+	probability := 65535
+	collector, err := svc.CreateSampleCollector(ctx, &models.SampleCollector{
+		Name:        UniqueName("collector"),
+		SetID:       100,
+		Probability: &probability,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.DeleteSampleCollector(context.Background(), collector.UUID) })
+	assert.NotZero(t, collector.ID, "collectors get the lowest ID free")
+
+	_, err = svc.CreateSampleCollector(ctx, &models.SampleCollector{
+		Name:        collector.Name,
+		SetID:       100,
+		Probability: &probability,
+	})
+	assert.Error(t, err, "collector names are unique")
+
+	ls := createSwitch(t, svc, UniqueName("sampling"))
+	acl, err := svc.CreateACL(ctx, ls.UUID, &models.ACL{
+		Priority:  1000,
+		Direction: "to-lport",
+		Match:     "tcp.dst == 443",
+		Action:    "allow-related",
+	})
+	require.NoError(t, err)
+
+	// Collectors are given by UUID or by name
+	sampling, err := svc.SetACLSampling(ctx, acl.UUID, &models.ACLSampling{
+		New: &models.FlowSample{Collectors: []string{collector.Name}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sampling.New)
+	assert.NotZero(t, sampling.New.ObservationPointID)
+	assert.Equal(t, []string{collector.UUID}, sampling.New.Collectors)
+	assert.Nil(t, sampling.Established)
+
+	got, err := svc.GetACLSampling(ctx, acl.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, sampling.New, got.New)
+
+	err = svc.DeleteSampleCollector(ctx, collector.UUID)
+	assert.Error(t, err, "collectors in use aren't deleted")
+
+	sampling, err = svc.SetACLSampling(ctx, acl.UUID, &models.ACLSampling{})
+	require.NoError(t, err)
+	assert.Nil(t, sampling.New)
+	require.NoError(t, svc.DeleteSampleCollector(ctx, collector.UUID))
+	_, err = svc.GetSampleCollector(ctx, collector.UUID)
+	assert.Error(t, err)
+
+	_, err = svc.SetSamplingApp(ctx, "bogus", 1)
+	assert.Error(t, err, "unknown sampling apps are refused")
+}
@@ -0,0 +1,32 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// memorySandboxStore keeps sandbox states in memory
+type memorySandboxStore map[string][]byte
+
+func (s memorySandboxStore) LoadSandboxState(ctx context.Context, name string) ([]byte, error) {
+	return s[name], nil
+}
+
+func (s memorySandboxStore) SaveSandboxState(ctx context.Context, name string, state []byte) error {
+	s[name] = state
+	return nil
+}
+
+// The sandbox backend stands in for OVN, so it honours the same contract
+func TestRunOVNServiceContract_Sandbox(t *testing.T) {
+	RunOVNServiceContract(t, func(t *testing.T) services.OVNServiceInterface {
+		sandbox, err := services.NewSandboxOVNService(context.Background(), memorySandboxStore{}, "contract", false, zap.NewNop())
+		require.NoError(t, err)
+		return sandbox
+	})
+}
@@ -0,0 +1,140 @@
+package visualization
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/cache"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// GraphCache keeps generated graphs, so that dashboards refreshing an
+// unchanged topology don't have it styled and laid out again. Graphs are
+// keyed by the topology they were generated from, their options and the
+// traffic they overlay; resource and OVN connection events, published to
+// the cache, drop every graph.
+//
+// Cached graphs are shared, so they must not be modified.
+type GraphCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedGraph
+	stats   cache.CacheStats
+}
+
+type cachedGraph struct {
+	graph     *TopologyGraph
+	expiresAt time.Time
+}
+
+// NewGraphCache creates a cache keeping up to maxEntries graphs for ttl
+// each. A zero ttl disables it.
+func NewGraphCache(ttl time.Duration, maxEntries int) *GraphCache {
+	return &GraphCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*cachedGraph),
+	}
+}
+
+// Graph returns the graph of topology with options and traffic overlaid,
+// calling generate when it isn't cached
+func (c *GraphCache) Graph(topology *services.Topology, traffic map[string]services.PortTraffic, options *VisualizationOptions, generate func() (*TopologyGraph, error)) (*TopologyGraph, error) {
+	if c.ttl <= 0 {
+		return generate()
+	}
+
+	key, err := graphKey(topology, traffic, options)
+	if err != nil {
+		return generate()
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expiresAt) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return entry.graph, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	graph, err := generate()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(now)
+	c.entries[key] = &cachedGraph{graph: graph, expiresAt: now.Add(c.ttl)}
+	c.stats.Sets++
+	return graph, nil
+}
+
+// evictLocked drops expired graphs and, while the cache is full, those
+// expiring first
+func (c *GraphCache) evictLocked(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			c.stats.Evictions++
+		}
+	}
+	for c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		var oldest string
+		for key, entry := range c.entries {
+			if oldest == "" || entry.expiresAt.Before(c.entries[oldest].expiresAt) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+		c.stats.Evictions++
+	}
+}
+
+// Publish drops every graph on events that may change the topology
+func (c *GraphCache) Publish(ctx context.Context, event *models.Event) {
+	if !strings.HasPrefix(event.Type, "resource.") && event.Type != models.EventOVNReconnected {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Deletes += int64(len(c.entries))
+	c.entries = make(map[string]*cachedGraph)
+}
+
+// Stats returns the cache's cumulative statistics
+func (c *GraphCache) Stats() cache.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// graphKey identifies a graph by a hash of the topology, without the time
+// it was read, of the traffic and of the options
+func graphKey(topology *services.Topology, traffic map[string]services.PortTraffic, options *VisualizationOptions) (string, error) {
+	unstamped := *topology
+	unstamped.Timestamp = time.Time{}
+	data, err := json.Marshal(struct {
+		Topology services.Topology               `json:"topology"`
+		Traffic  map[string]services.PortTraffic `json:"traffic"`
+		Options  *VisualizationOptions           `json:"options"`
+	}{unstamped, traffic, options})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode graph key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
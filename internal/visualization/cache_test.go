@@ -0,0 +1,125 @@
+package visualization
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// countingGenerator generates the graph of topology, counting the calls
+func countingGenerator(topology *services.Topology, options *VisualizationOptions, calls *int) func() (*TopologyGraph, error) {
+	return func() (*TopologyGraph, error) {
+		*calls++
+		return NewTopologyVisualizer(topology).GenerateGraph(options)
+	}
+}
+
+func TestGraphCache(t *testing.T) {
+	graphs := NewGraphCache(time.Minute, 8)
+	options := DefaultVisualizationOptions()
+	calls := 0
+
+	topology := pathTopology()
+	first, err := graphs.Graph(topology, nil, options, countingGenerator(topology, options, &calls))
+	require.NoError(t, err)
+
+	// Reading the topology again doesn't change it
+	reread := pathTopology()
+	reread.Timestamp = time.Now()
+	second, err := graphs.Graph(reread, nil, options, countingGenerator(reread, options, &calls))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, calls)
+
+	// Other options, traffic or topologies are other graphs
+	minimal := MinimalOptions()
+	_, err = graphs.Graph(topology, nil, minimal, countingGenerator(topology, minimal, &calls))
+	require.NoError(t, err)
+	traffic := map[string]services.PortTraffic{"web-1": {RxBps: 1000}}
+	_, err = graphs.Graph(topology, traffic, options, countingGenerator(topology, options, &calls))
+	require.NoError(t, err)
+	changed := pathTopology()
+	changed.Switches[2].Name = "renamed"
+	_, err = graphs.Graph(changed, nil, options, countingGenerator(changed, options, &calls))
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	stats := graphs.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(4), stats.Misses)
+	assert.Equal(t, int64(4), stats.Sets)
+}
+
+func TestGraphCacheExpiry(t *testing.T) {
+	now := time.Now()
+	graphs := NewGraphCache(time.Minute, 2)
+	graphs.now = func() time.Time { return now }
+	topology := pathTopology()
+	calls := 0
+
+	generate := func(options *VisualizationOptions) {
+		_, err := graphs.Graph(topology, nil, options, countingGenerator(topology, options, &calls))
+		require.NoError(t, err)
+	}
+
+	generate(DefaultVisualizationOptions())
+	now = now.Add(2 * time.Minute)
+	generate(DefaultVisualizationOptions())
+	assert.Equal(t, 2, calls, "expired graphs are generated again")
+
+	// The graph expiring first makes room
+	now = now.Add(time.Second)
+	generate(MinimalOptions())
+	now = now.Add(time.Second)
+	generate(FullOptions())
+	generate(DefaultVisualizationOptions())
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, int64(3), graphs.Stats().Evictions)
+}
+
+func TestGraphCacheEvents(t *testing.T) {
+	graphs := NewGraphCache(time.Minute, 8)
+	options := DefaultVisualizationOptions()
+	topology := pathTopology()
+	calls := 0
+
+	generate := func() {
+		_, err := graphs.Graph(topology, nil, options, countingGenerator(topology, options, &calls))
+		require.NoError(t, err)
+	}
+
+	generate()
+	graphs.Publish(context.Background(), &models.Event{Type: models.EventOVNDisconnected})
+	generate()
+	assert.Equal(t, 1, calls, "events not changing the topology keep graphs")
+
+	graphs.Publish(context.Background(), &models.Event{Type: models.EventResourceUpdated})
+	generate()
+	graphs.Publish(context.Background(), &models.Event{Type: models.EventOVNReconnected})
+	generate()
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, int64(2), graphs.Stats().Deletes)
+}
+
+func TestGraphCacheDisabled(t *testing.T) {
+	graphs := NewGraphCache(0, 8)
+	failure := errors.New("generation failed")
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		_, err := graphs.Graph(pathTopology(), nil, DefaultVisualizationOptions(), func() (*TopologyGraph, error) {
+			calls++
+			return nil, failure
+		})
+		assert.ErrorIs(t, err, failure)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Zero(t, graphs.Stats())
+}
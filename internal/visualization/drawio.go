@@ -0,0 +1,250 @@
+package visualization
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+)
+
+// drawioGroupPadding pads group containers around their members; the top
+// leaves room for the group's title
+const (
+	drawioGroupPadding = 20.0
+	drawioGroupTitle   = 30.0
+)
+
+type drawioFile struct {
+	XMLName xml.Name      `xml:"mxfile"`
+	Host    string        `xml:"host,attr"`
+	Diagram drawioDiagram `xml:"diagram"`
+}
+
+type drawioDiagram struct {
+	ID    string           `xml:"id,attr"`
+	Name  string           `xml:"name,attr"`
+	Model drawioGraphModel `xml:"mxGraphModel"`
+}
+
+type drawioGraphModel struct {
+	Grid int        `xml:"grid,attr"`
+	Root drawioRoot `xml:"root"`
+}
+
+// drawioRoot holds cells and objects, each named by its XMLName
+type drawioRoot struct {
+	Items []interface{}
+}
+
+// drawioObject carries a cell's custom attributes, e.g. the node type
+type drawioObject struct {
+	XMLName xml.Name   `xml:"object"`
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Cell    drawioCell `xml:"mxCell"`
+}
+
+type drawioCell struct {
+	XMLName     xml.Name        `xml:"mxCell"`
+	ID          string          `xml:"id,attr,omitempty"`
+	Value       string          `xml:"value,attr,omitempty"`
+	Style       string          `xml:"style,attr,omitempty"`
+	Vertex      string          `xml:"vertex,attr,omitempty"`
+	Edge        string          `xml:"edge,attr,omitempty"`
+	Connectable string          `xml:"connectable,attr,omitempty"`
+	Parent      string          `xml:"parent,attr,omitempty"`
+	Source      string          `xml:"source,attr,omitempty"`
+	Target      string          `xml:"target,attr,omitempty"`
+	Geometry    *drawioGeometry `xml:"mxGeometry,omitempty"`
+}
+
+type drawioGeometry struct {
+	X        float64 `xml:"x,attr,omitempty"`
+	Y        float64 `xml:"y,attr,omitempty"`
+	Width    float64 `xml:"width,attr,omitempty"`
+	Height   float64 `xml:"height,attr,omitempty"`
+	Relative string  `xml:"relative,attr,omitempty"`
+	As       string  `xml:"as,attr"`
+}
+
+// exportDrawio exports to the diagrams.net (draw.io) XML format. Groups
+// become containers holding their members; node types and UUIDs are kept
+// as cell attributes.
+func (e *Exporter) exportDrawio() ([]byte, error) {
+	positions := e.nodePositions()
+	groupOf := e.nodeGroups()
+
+	// Node geometry is absolute, so shift it clear of negative coordinates
+	minX, minY := 0.0, 0.0
+	for _, p := range positions {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+	}
+	offsetX := drawioGroupPadding - minX + 50
+	offsetY := drawioGroupTitle - minY + 50
+
+	bounds := make(map[string]*drawioGeometry, len(e.graph.Nodes))
+	for _, node := range e.graph.Nodes {
+		style := nodeStyle(node)
+		width, height := float64(style.Size), float64(style.Size)
+		if style.Shape == "rectangle" || style.Shape == "box" {
+			height = math.Round(height * 0.6)
+		}
+		p := positions[node.ID]
+		bounds[node.ID] = &drawioGeometry{
+			X:      p.X + offsetX - width/2,
+			Y:      p.Y + offsetY - height/2,
+			Width:  width,
+			Height: height,
+			As:     "geometry",
+		}
+	}
+
+	items := []interface{}{
+		drawioCell{ID: "0"},
+		drawioCell{ID: "1", Parent: "0"},
+	}
+
+	// Containers span their members, whose geometry becomes relative to them
+	containers := make(map[string]*drawioGeometry, len(e.graph.Groups))
+	for _, group := range e.graph.Groups {
+		var container *drawioGeometry
+		for _, id := range group.Nodes {
+			b, ok := bounds[id]
+			if !ok || groupOf[id] != group.ID {
+				continue
+			}
+			if container == nil {
+				container = &drawioGeometry{X: b.X, Y: b.Y, Width: b.X + b.Width, Height: b.Y + b.Height, As: "geometry"}
+				continue
+			}
+			container.X, container.Y = math.Min(container.X, b.X), math.Min(container.Y, b.Y)
+			container.Width, container.Height = math.Max(container.Width, b.X+b.Width), math.Max(container.Height, b.Y+b.Height)
+		}
+		if container == nil {
+			continue
+		}
+		container.X -= drawioGroupPadding
+		container.Y -= drawioGroupTitle
+		container.Width += drawioGroupPadding - container.X
+		container.Height += drawioGroupPadding - container.Y
+		containers[group.ID] = container
+
+		items = append(items, drawioObject{
+			Attrs: []xml.Attr{
+				{Name: xml.Name{Local: "id"}, Value: group.ID},
+				{Name: xml.Name{Local: "label"}, Value: group.Label},
+				{Name: xml.Name{Local: "type"}, Value: "group"},
+			},
+			Cell: drawioCell{
+				Style:       drawioGroupStyle(group.Style),
+				Vertex:      "1",
+				Connectable: "0",
+				Parent:      "1",
+				Geometry:    container,
+			},
+		})
+	}
+
+	for _, node := range e.graph.Nodes {
+		geometry := bounds[node.ID]
+		parent := "1"
+		if container, ok := containers[groupOf[node.ID]]; ok {
+			parent = groupOf[node.ID]
+			geometry.X -= container.X
+			geometry.Y -= container.Y
+		}
+
+		attrs := []xml.Attr{
+			{Name: xml.Name{Local: "id"}, Value: node.ID},
+			{Name: xml.Name{Local: "label"}, Value: node.Label},
+			{Name: xml.Name{Local: "type"}, Value: string(node.Type)},
+		}
+		if uuid, ok := node.Properties["uuid"].(string); ok {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "uuid"}, Value: uuid})
+		}
+		items = append(items, drawioObject{
+			Attrs: attrs,
+			Cell: drawioCell{
+				Style:    drawioNodeStyle(nodeStyle(node)),
+				Vertex:   "1",
+				Parent:   parent,
+				Geometry: geometry,
+			},
+		})
+	}
+
+	for _, edge := range e.graph.Edges {
+		if bounds[edge.Source] == nil || bounds[edge.Target] == nil {
+			continue
+		}
+		style := EdgeStyle{Color: "#9E9E9E", Width: 1, Style: "solid"}
+		if edge.Style != nil {
+			style = *edge.Style
+		}
+		items = append(items, drawioCell{
+			ID:       edge.ID,
+			Value:    edge.Label,
+			Style:    drawioEdgeStyle(style),
+			Edge:     "1",
+			Parent:   "1",
+			Source:   edge.Source,
+			Target:   edge.Target,
+			Geometry: &drawioGeometry{Relative: "1", As: "geometry"},
+		})
+	}
+
+	file := drawioFile{
+		Host: "ovncp",
+		Diagram: drawioDiagram{
+			ID:    "topology",
+			Name:  "OVN Topology",
+			Model: drawioGraphModel{Grid: 1, Root: drawioRoot{Items: items}},
+		},
+	}
+	out, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// drawioNodeStyle converts a node style to a draw.io cell style
+func drawioNodeStyle(style NodeStyle) string {
+	var shape string
+	switch style.Shape {
+	case "rectangle", "box":
+		shape = "rounded=1;"
+	case "diamond", "shield":
+		shape = "rhombus;verticalLabelPosition=bottom;verticalAlign=top;"
+	case "hexagon":
+		shape = "shape=hexagon;perimeter=hexagonPerimeter2;verticalLabelPosition=bottom;verticalAlign=top;"
+	default:
+		shape = "ellipse;aspect=fixed;verticalLabelPosition=bottom;verticalAlign=top;"
+	}
+	return fmt.Sprintf("%swhiteSpace=wrap;html=1;fillColor=%s;strokeColor=%s;", shape, style.Color, style.BorderColor)
+}
+
+// drawioEdgeStyle converts an edge style to a draw.io cell style
+func drawioEdgeStyle(style EdgeStyle) string {
+	s := fmt.Sprintf("endArrow=classic;html=1;strokeColor=%s;strokeWidth=%d;", style.Color, max(style.Width, 1))
+	switch style.Style {
+	case "dashed":
+		s += "dashed=1;"
+	case "dotted":
+		s += "dashed=1;dashPattern=1 4;"
+	}
+	return s
+}
+
+// drawioGroupStyle converts a group style to a draw.io container style
+func drawioGroupStyle(style GroupStyle) string {
+	s := fmt.Sprintf("swimlane;startSize=%g;container=1;collapsible=1;html=1;", drawioGroupTitle)
+	if style.BackgroundColor != "" {
+		s += "fillColor=" + style.BackgroundColor + ";swimlaneFillColor=" + style.BackgroundColor + ";"
+	}
+	if style.BorderColor != "" {
+		s += "strokeColor=" + style.BorderColor + ";"
+	}
+	if style.BorderStyle == "dashed" || style.BorderStyle == "dotted" {
+		s += "dashed=1;"
+	}
+	return s
+}
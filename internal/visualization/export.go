@@ -31,6 +31,12 @@ func (e *Exporter) Export(format string) ([]byte, error) {
 		return e.exportD3()
 	case "mermaid":
 		return e.exportMermaid()
+	case "svg":
+		return e.exportSVG()
+	case "graphml":
+		return e.exportGraphML()
+	case "drawio":
+		return e.exportDrawio()
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
@@ -82,6 +88,9 @@ func (e *Exporter) exportDOT() ([]byte, error) {
 	if len(e.graph.Groups) > 0 {
 		buf.WriteString("\n  /* Groups */\n")
 		for i, group := range e.graph.Groups {
+			if group.Collapsed {
+				continue
+			}
 			buf.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
 			buf.WriteString(fmt.Sprintf("    label=\"%s\";\n", group.Label))
 			buf.WriteString("    style=dotted;\n")
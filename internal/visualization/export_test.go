@@ -0,0 +1,132 @@
+package visualization
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// groupedGraph is the path topology's graph with the web switch and its VM
+// ports grouped
+func groupedGraph(t *testing.T) *TopologyGraph {
+	options := FullOptions()
+	options.Layout = "hierarchical"
+	graph, err := NewTopologyVisualizer(pathTopology()).GenerateGraph(options)
+	require.NoError(t, err)
+	graph.Groups = []Group{{
+		ID:    "group:web",
+		Label: "Web tier",
+		Nodes: []string{"switch:sw-web", "port:p-web-1", "port:p-web-2"},
+		Style: GroupStyle{BackgroundColor: "#E3F2FD", BorderStyle: "dashed"},
+	}}
+	return graph
+}
+
+func TestExportGraphML(t *testing.T) {
+	graph := groupedGraph(t)
+	data, err := NewExporter(graph).Export("graphml")
+	require.NoError(t, err)
+
+	var doc graphML
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, graphMLNamespace, doc.XMLNS)
+	assert.Len(t, doc.Graph.Edges, len(graph.Edges))
+
+	// The group holds its members in a nested graph
+	var group *graphMLNode
+	topLevel := map[string]bool{}
+	for i, node := range doc.Graph.Nodes {
+		topLevel[node.ID] = true
+		if node.ID == "group:web" {
+			group = &doc.Graph.Nodes[i]
+		}
+	}
+	require.NotNil(t, group)
+	require.NotNil(t, group.Graph)
+	assert.Len(t, group.Graph.Nodes, 3)
+	assert.False(t, topLevel["switch:sw-web"])
+	assert.True(t, topLevel["router:r-1"])
+	assert.Equal(t, len(graph.Nodes)-3+1, len(doc.Graph.Nodes))
+
+	// Nodes keep their type and label, edges their label
+	fields := map[string]string{}
+	for _, d := range group.Graph.Nodes[0].Data {
+		fields[d.Key] = d.Value
+	}
+	assert.Equal(t, "switch", fields["type"])
+	assert.Equal(t, "web", fields["label"])
+	assert.Equal(t, "sw-web", fields["uuid"])
+
+	labeled := false
+	for _, edge := range doc.Graph.Edges {
+		for _, d := range edge.Data {
+			labeled = labeled || (d.Key == "edge_label" && d.Value == "L3")
+		}
+	}
+	assert.True(t, labeled)
+}
+
+func TestExportDrawio(t *testing.T) {
+	graph := groupedGraph(t)
+	data, err := NewExporter(graph).Export("drawio")
+	require.NoError(t, err)
+
+	var file struct {
+		Objects []struct {
+			ID    string `xml:"id,attr"`
+			Label string `xml:"label,attr"`
+			Type  string `xml:"type,attr"`
+			Cell  struct {
+				Style    string `xml:"style,attr"`
+				Parent   string `xml:"parent,attr"`
+				Geometry struct {
+					X float64 `xml:"x,attr"`
+					Y float64 `xml:"y,attr"`
+				} `xml:"mxGeometry"`
+			} `xml:"mxCell"`
+		} `xml:"diagram>mxGraphModel>root>object"`
+		Cells []struct {
+			ID     string `xml:"id,attr"`
+			Value  string `xml:"value,attr"`
+			Edge   string `xml:"edge,attr"`
+			Source string `xml:"source,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"diagram>mxGraphModel>root>mxCell"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &file))
+
+	objects := map[string]int{}
+	for i, object := range file.Objects {
+		objects[object.ID] = i
+	}
+	require.Contains(t, objects, "group:web")
+	require.Len(t, file.Objects, len(graph.Nodes)+1)
+
+	group := file.Objects[objects["group:web"]]
+	assert.Equal(t, "Web tier", group.Label)
+	assert.Contains(t, group.Cell.Style, "swimlane")
+	assert.Contains(t, group.Cell.Style, "fillColor=#E3F2FD")
+
+	sw := file.Objects[objects["switch:sw-web"]]
+	assert.Equal(t, "switch", sw.Type)
+	assert.Equal(t, "group:web", sw.Cell.Parent)
+	assert.Contains(t, sw.Cell.Style, "fillColor=#4FC3F7")
+	assert.Positive(t, sw.Cell.Geometry.X, "members are placed inside their group")
+	assert.Positive(t, sw.Cell.Geometry.Y)
+	assert.Equal(t, "1", file.Objects[objects["router:r-1"]].Cell.Parent)
+
+	edges := 0
+	labeled := false
+	for _, cell := range file.Cells {
+		if cell.Edge == "1" {
+			edges++
+			labeled = labeled || cell.Value == "L3"
+			assert.Contains(t, objects, cell.Source)
+			assert.Contains(t, objects, cell.Target)
+		}
+	}
+	assert.Equal(t, len(graph.Edges), edges)
+	assert.True(t, labeled)
+}
@@ -0,0 +1,150 @@
+package visualization
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// graphMLNamespace is the GraphML XML namespace
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID    string        `xml:"id,attr"`
+	Data  []graphMLData `xml:"data"`
+	Graph *graphMLGraph `xml:"graph,omitempty"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys declare the attributes of nodes and edges
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "type", For: "node", Name: "type", Type: "string"},
+	{ID: "group", For: "node", Name: "group", Type: "string"},
+	{ID: "uuid", For: "node", Name: "uuid", Type: "string"},
+	{ID: "color", For: "node", Name: "color", Type: "string"},
+	{ID: "x", For: "node", Name: "x", Type: "double"},
+	{ID: "y", For: "node", Name: "y", Type: "double"},
+	{ID: "edge_label", For: "edge", Name: "label", Type: "string"},
+	{ID: "edge_type", For: "edge", Name: "type", Type: "string"},
+}
+
+// exportGraphML exports to GraphML, which yEd, Gephi and most graph tools
+// import. Groups become nodes holding a nested graph of their members.
+func (e *Exporter) exportGraphML() ([]byte, error) {
+	doc := graphML{
+		XMLNS: graphMLNamespace,
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "topology", EdgeDefault: "directed"},
+	}
+
+	positions := e.nodePositions()
+	groupOf := e.nodeGroups()
+
+	groups := make(map[string]*graphMLGraph, len(e.graph.Groups))
+	for _, group := range e.graph.Groups {
+		// A collapsed group is already a node
+		if group.Collapsed {
+			continue
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: group.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: group.Label},
+				{Key: "type", Value: "group"},
+			},
+			Graph: &graphMLGraph{ID: group.ID + ":", EdgeDefault: "directed"},
+		})
+	}
+	for i := range doc.Graph.Nodes {
+		groups[doc.Graph.Nodes[i].ID] = doc.Graph.Nodes[i].Graph
+	}
+
+	for _, node := range e.graph.Nodes {
+		position := positions[node.ID]
+		data := []graphMLData{
+			{Key: "label", Value: node.Label},
+			{Key: "type", Value: string(node.Type)},
+		}
+		if node.Group != "" {
+			data = append(data, graphMLData{Key: "group", Value: node.Group})
+		}
+		if uuid, ok := node.Properties["uuid"].(string); ok {
+			data = append(data, graphMLData{Key: "uuid", Value: uuid})
+		}
+		data = append(data,
+			graphMLData{Key: "color", Value: nodeStyle(node).Color},
+			graphMLData{Key: "x", Value: fmt.Sprintf("%g", position.X)},
+			graphMLData{Key: "y", Value: fmt.Sprintf("%g", position.Y)},
+		)
+
+		graph := &doc.Graph
+		if group, ok := groups[groupOf[node.ID]]; ok {
+			graph = group
+		}
+		graph.Nodes = append(graph.Nodes, graphMLNode{ID: node.ID, Data: data})
+	}
+
+	for _, edge := range e.graph.Edges {
+		data := []graphMLData{{Key: "edge_type", Value: edge.Type}}
+		if edge.Label != "" {
+			data = append(data, graphMLData{Key: "edge_label", Value: edge.Label})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     edge.ID,
+			Source: edge.Source,
+			Target: edge.Target,
+			Data:   data,
+		})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// nodeGroups maps each node to the first group listing it
+func (e *Exporter) nodeGroups() map[string]string {
+	groupOf := make(map[string]string)
+	for _, group := range e.graph.Groups {
+		for _, id := range group.Nodes {
+			if _, ok := groupOf[id]; !ok {
+				groupOf[id] = group.ID
+			}
+		}
+	}
+	return groupOf
+}
@@ -0,0 +1,211 @@
+package visualization
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidOptions is returned for visualization options that can't be
+// applied
+var ErrInvalidOptions = errors.New("invalid visualization options")
+
+// Values of VisualizationOptions.GroupBy besides label:<key>
+const (
+	GroupByTenant = "tenant"
+	GroupByZone   = "zone"
+)
+
+const groupByLabelPrefix = "label:"
+
+// External IDs nodes are grouped by
+const (
+	tenantKey = "tenant_id"
+	zoneKey   = "availability_zone"
+)
+
+// groupStyles are cycled through by groups
+var groupStyles = []GroupStyle{
+	{BackgroundColor: "#E3F2FD", BorderColor: "#90CAF9", BorderStyle: "dashed"},
+	{BackgroundColor: "#E8F5E9", BorderColor: "#A5D6A7", BorderStyle: "dashed"},
+	{BackgroundColor: "#FFF3E0", BorderColor: "#FFCC80", BorderStyle: "dashed"},
+	{BackgroundColor: "#F3E5F5", BorderColor: "#CE93D8", BorderStyle: "dashed"},
+	{BackgroundColor: "#FBE9E7", BorderColor: "#FFAB91", BorderStyle: "dashed"},
+}
+
+// Validate checks the name filter and grouping
+func (o *VisualizationOptions) Validate() error {
+	if o.FilterByName != "" {
+		if _, err := regexp.Compile(o.FilterByName); err != nil {
+			return fmt.Errorf("%w: name filter: %v", ErrInvalidOptions, err)
+		}
+	}
+	_, err := o.groupKey()
+	return err
+}
+
+// groupKey returns the external ID nodes are grouped by, if any
+func (o *VisualizationOptions) groupKey() (string, error) {
+	switch {
+	case o.GroupBy == "":
+		return "", nil
+	case o.GroupBy == GroupByTenant:
+		return tenantKey, nil
+	case o.GroupBy == GroupByZone:
+		return zoneKey, nil
+	case strings.HasPrefix(o.GroupBy, groupByLabelPrefix) && len(o.GroupBy) > len(groupByLabelPrefix):
+		return strings.TrimPrefix(o.GroupBy, groupByLabelPrefix), nil
+	}
+	return "", fmt.Errorf("%w: groupBy must be %s, %s or %s<key>, got %q",
+		ErrInvalidOptions, GroupByTenant, GroupByZone, groupByLabelPrefix, o.GroupBy)
+}
+
+// nodeExternalIDs returns the external IDs of each switch, router and port
+// node
+func (v *TopologyVisualizer) nodeExternalIDs() map[string]map[string]string {
+	ids := make(map[string]map[string]string)
+	for _, sw := range v.topology.Switches {
+		ids["switch:"+sw.UUID] = sw.ExternalIDs
+	}
+	for _, router := range v.topology.Routers {
+		ids["router:"+router.UUID] = router.ExternalIDs
+	}
+	for _, port := range v.topology.Ports {
+		ids["port:"+port.UUID] = port.ExternalIDs
+	}
+	for _, rp := range v.topology.RouterPorts {
+		ids["port:"+rp.UUID] = rp.ExternalIDs
+	}
+	return ids
+}
+
+// filterByName keeps the nodes whose label matches pattern, the ports of
+// kept switches and routers, and the edges between kept nodes
+func filterByName(graph *TopologyGraph, pattern *regexp.Regexp) {
+	kept := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if pattern.MatchString(node.Label) {
+			kept[node.ID] = true
+		}
+	}
+	for _, edge := range graph.Edges {
+		if edge.Type == "contains" && kept[edge.Source] {
+			kept[edge.Target] = true
+		}
+	}
+
+	nodes := graph.Nodes[:0]
+	for _, node := range graph.Nodes {
+		if kept[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+	graph.Nodes = nodes
+
+	edges := graph.Edges[:0]
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			edges = append(edges, edge)
+		}
+	}
+	graph.Edges = edges
+}
+
+// groupNodes groups nodes by the value of an external ID. Ports without it
+// join their switch's or router's group; nodes without either stay
+// ungrouped. Groups are sorted by value.
+func groupNodes(graph *TopologyGraph, key string, externalIDs map[string]map[string]string, collapse bool) {
+	values := make(map[string]string)
+	for _, node := range graph.Nodes {
+		if value := externalIDs[node.ID][key]; value != "" {
+			values[node.ID] = value
+		}
+	}
+	for _, edge := range graph.Edges {
+		if _, ok := values[edge.Target]; !ok && edge.Type == "contains" && values[edge.Source] != "" {
+			values[edge.Target] = values[edge.Source]
+		}
+	}
+
+	members := make(map[string][]string)
+	for _, node := range graph.Nodes {
+		if value, ok := values[node.ID]; ok {
+			members[value] = append(members[value], node.ID)
+		}
+	}
+	sorted := make([]string, 0, len(members))
+	for value := range members {
+		sorted = append(sorted, value)
+	}
+	sort.Strings(sorted)
+
+	groupOf := make(map[string]string)
+	for i, value := range sorted {
+		group := Group{
+			ID:        "group:" + key + "=" + value,
+			Label:     key + "=" + value,
+			Nodes:     members[value],
+			Style:     groupStyles[i%len(groupStyles)],
+			Collapsed: collapse,
+		}
+		graph.Groups = append(graph.Groups, group)
+		for _, id := range group.Nodes {
+			groupOf[id] = group.ID
+		}
+	}
+
+	if collapse {
+		collapseGroups(graph, groupOf)
+	}
+}
+
+// collapseGroups replaces each group's members with a node standing for the
+// group. Edges to members go to their group's node; edges within a group are
+// dropped.
+func collapseGroups(graph *TopologyGraph, groupOf map[string]string) {
+	nodes := make([]GraphNode, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		if _, ok := groupOf[node.ID]; !ok {
+			nodes = append(nodes, node)
+		}
+	}
+	for _, group := range graph.Groups {
+		nodes = append(nodes, GraphNode{
+			ID:    group.ID,
+			Label: group.Label,
+			Type:  NodeTypeGroup,
+			Group: "groups",
+			Properties: map[string]interface{}{
+				"nodeCount": len(group.Nodes),
+			},
+			Style: &NodeStyle{
+				Shape:       "rectangle",
+				Color:       group.Style.BackgroundColor,
+				BorderColor: group.Style.BorderColor,
+				Size:        80,
+			},
+		})
+	}
+	graph.Nodes = nodes
+
+	endpoint := func(id string) string {
+		if group, ok := groupOf[id]; ok {
+			return group
+		}
+		return id
+	}
+	seen := make(map[string]bool)
+	edges := make([]GraphEdge, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edge.Source, edge.Target = endpoint(edge.Source), endpoint(edge.Target)
+		key := edge.Source + "|" + edge.Target + "|" + edge.Type
+		if edge.Source == edge.Target || seen[key] {
+			continue
+		}
+		seen[key] = true
+		edges = append(edges, edge)
+	}
+	graph.Edges = edges
+}
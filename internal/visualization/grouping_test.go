@@ -0,0 +1,106 @@
+package visualization
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labeledGraph is the graph of the path topology with the web switch and
+// one of its ports labeled for tenant acme, and the db switch for globex
+func labeledGraph(t *testing.T, configure func(*VisualizationOptions)) *TopologyGraph {
+	topology := pathTopology()
+	topology.Switches[0].ExternalIDs = map[string]string{"tenant_id": "acme", "availability_zone": "az1", "tier": "web"}
+	topology.Switches[1].ExternalIDs = map[string]string{"tenant_id": "globex", "availability_zone": "az1"}
+	topology.Ports[0].ExternalIDs = map[string]string{"tenant_id": "acme"}
+
+	options := FullOptions()
+	options.IncludeACLs = false
+	configure(options)
+	graph, err := NewTopologyVisualizer(topology).GenerateGraph(options)
+	require.NoError(t, err)
+	return graph
+}
+
+func nodeIDs(graph *TopologyGraph) []string {
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestGenerateGraph_GroupBy(t *testing.T) {
+	graph := labeledGraph(t, func(o *VisualizationOptions) { o.GroupBy = GroupByTenant })
+	require.Len(t, graph.Groups, 2)
+
+	acme := graph.Groups[0]
+	assert.Equal(t, "group:tenant_id=acme", acme.ID)
+	assert.Equal(t, "tenant_id=acme", acme.Label)
+	assert.False(t, acme.Collapsed)
+	assert.ElementsMatch(t, []string{"switch:sw-web", "port:p-web-1", "port:p-web-2", "port:p-web-rtr"}, acme.Nodes,
+		"ports without the label join their switch's group")
+	assert.Equal(t, "group:tenant_id=globex", graph.Groups[1].ID)
+	assert.NotEqual(t, acme.Style, graph.Groups[1].Style)
+
+	graph = labeledGraph(t, func(o *VisualizationOptions) { o.GroupBy = GroupByZone })
+	require.Len(t, graph.Groups, 1)
+	assert.Len(t, graph.Groups[0].Nodes, 7)
+
+	graph = labeledGraph(t, func(o *VisualizationOptions) { o.GroupBy = "label:tier" })
+	require.Len(t, graph.Groups, 1)
+	assert.Equal(t, "tier=web", graph.Groups[0].Label)
+}
+
+func TestGenerateGraph_CollapseGroups(t *testing.T) {
+	graph := labeledGraph(t, func(o *VisualizationOptions) {
+		o.GroupBy = GroupByTenant
+		o.CollapseGroups = true
+	})
+
+	assert.Equal(t, []string{
+		"group:tenant_id=acme", "group:tenant_id=globex",
+		"port:lrp-db", "port:lrp-web", "port:p-iso",
+		"router:r-1", "switch:sw-iso",
+	}, nodeIDs(graph))
+	for _, group := range graph.Groups {
+		assert.True(t, group.Collapsed)
+	}
+
+	edges := map[string]bool{}
+	for _, edge := range graph.Edges {
+		assert.NotEqual(t, edge.Source, edge.Target)
+		key := edge.Source + "->" + edge.Target
+		assert.False(t, edges[key], "edges are deduplicated")
+		edges[key] = true
+	}
+	assert.True(t, edges["group:tenant_id=acme->port:lrp-web"], "edges to members go to their group")
+}
+
+func TestGenerateGraph_FilterByName(t *testing.T) {
+	graph := labeledGraph(t, func(o *VisualizationOptions) { o.FilterByName = "^(web|edge)$" })
+
+	assert.Equal(t, []string{
+		"port:lrp-db", "port:lrp-web",
+		"port:p-web-1", "port:p-web-2", "port:p-web-rtr",
+		"router:r-1", "switch:sw-web",
+	}, nodeIDs(graph), "the ports of matching switches and routers are kept")
+	for _, edge := range graph.Edges {
+		assert.NotContains(t, []string{edge.Source, edge.Target}, "switch:sw-db")
+	}
+}
+
+func TestGenerateGraph_InvalidOptions(t *testing.T) {
+	for _, options := range []*VisualizationOptions{
+		{FilterByName: "web("},
+		{GroupBy: "rack"},
+		{GroupBy: "label:"},
+	} {
+		_, err := NewTopologyVisualizer(pathTopology()).GenerateGraph(options)
+		assert.True(t, errors.Is(err, ErrInvalidOptions), "%+v", options)
+	}
+}
@@ -31,11 +31,17 @@ type VisualizationOptions struct {
 	AnimateTraffic   bool `json:"animateTraffic"`
 	GroupNodes       bool `json:"groupNodes"`
 	
-	// Filtering
+	// Filtering. FilterByName is a regular expression node labels must
+	// match; the ports of matching switches and routers are kept too.
 	FilterByName     string   `json:"filterByName,omitempty"`
 	FilterByType     []string `json:"filterByType,omitempty"`
 	FilterByProperty map[string]string `json:"filterByProperty,omitempty"`
 	
+	// Grouping: tenant, zone or label:<external ID key>. CollapseGroups
+	// replaces each group's members with a single node.
+	GroupBy          string `json:"groupBy,omitempty"`
+	CollapseGroups   bool   `json:"collapseGroups"`
+	
 	// Performance
 	MaxNodes         int  `json:"maxNodes"`
 	SimplifyPorts    bool `json:"simplifyPorts"`
@@ -0,0 +1,296 @@
+package visualization
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+var (
+	// ErrPathEndpointNotFound is returned when a path endpoint isn't a port
+	// of the topology
+	ErrPathEndpointNotFound = errors.New("path endpoint not found")
+	// ErrNoPath is returned when no switches and routers connect two ports
+	ErrNoPath = errors.New("no path between endpoints")
+)
+
+// Hop types of a path
+const (
+	HopPort       = "port"
+	HopSwitch     = "switch"
+	HopRouterPort = "router_port"
+	HopRouter     = "router"
+)
+
+// NAT effects on the traffic of a path
+const (
+	NATEffectSNAT = "snat"
+	NATEffectDNAT = "dnat"
+)
+
+// Path is the way traffic takes from one port to another
+type Path struct {
+	From  string    `json:"from"`
+	To    string    `json:"to"`
+	Layer string    `json:"layer"` // L2 within a switch, L3 across routers
+	Hops  []PathHop `json:"hops"`
+}
+
+// PathHop is a port, switch, router port or router on a path. Switch hops
+// list the ACLs that may apply to the traffic, in evaluation order; router
+// hops list the NAT rules translating it.
+type PathHop struct {
+	ID   string        `json:"id"`
+	Type string        `json:"type"`
+	Name string        `json:"name"`
+	ACLs []*models.ACL `json:"acls,omitempty"`
+	NAT  []PathNAT     `json:"nat,omitempty"`
+}
+
+// PathNAT is a NAT rule translating the source or destination of a path's
+// traffic
+type PathNAT struct {
+	models.NAT
+	Effect string `json:"effect"`
+}
+
+// ACLLookup returns the ACLs of a switch
+type ACLLookup func(switchID string) ([]*models.ACL, error)
+
+// portMatch finds the inport and outport conditions of an ACL match
+var portMatch = regexp.MustCompile(`\b(inport|outport)\s*==\s*(?:"([^"]*)"|([^\s&|()]+))`)
+
+// FindPath returns the shortest path from one port to another. Ports are
+// referenced by UUID or name and may be switch or router ports. acls looks
+// up the ACLs of the switches on the path.
+func FindPath(topology *services.Topology, from, to string, acls ACLLookup) (*Path, error) {
+	source, err := findEndpoint(topology, from)
+	if err != nil {
+		return nil, err
+	}
+	target, err := findEndpoint(topology, to)
+	if err != nil {
+		return nil, err
+	}
+
+	options := FullOptions()
+	options.Layout = "none"
+	options.IncludeACLs = false
+	graph, err := NewTopologyVisualizer(topology).GenerateGraph(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := shortestPath(graph, "port:"+source.ID, "port:"+target.ID)
+	if ids == nil {
+		return nil, fmt.Errorf("%w: %s and %s", ErrNoPath, from, to)
+	}
+
+	hops := make([]PathHop, 0, len(ids))
+	for _, id := range ids {
+		hops = append(hops, newPathHop(topology, id))
+	}
+
+	path := &Path{From: source.ID, To: target.ID, Layer: "L2", Hops: hops}
+	for i := range path.Hops {
+		hop := &path.Hops[i]
+		switch hop.Type {
+		case HopSwitch:
+			// The previous and next hops are the ports traffic enters and
+			// leaves the switch by
+			switchACLs, err := acls(hop.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list ACLs for switch %s: %w", hop.ID, err)
+			}
+			hop.ACLs = pathACLs(switchACLs, path.Hops[i-1].Name, path.Hops[i+1].Name)
+		case HopRouter:
+			path.Layer = "L3"
+			hop.NAT = pathNAT(findRouter(topology, hop.ID), source.IPs, target.IPs)
+		}
+	}
+	return path, nil
+}
+
+// pathEndpoint is a port a path starts or ends at
+type pathEndpoint struct {
+	ID  string
+	IPs []net.IP
+}
+
+func findEndpoint(topology *services.Topology, ref string) (*pathEndpoint, error) {
+	for _, port := range topology.Ports {
+		if port.UUID == ref || port.Name == ref {
+			endpoint := &pathEndpoint{ID: port.UUID}
+			for _, address := range port.Addresses {
+				for _, field := range strings.Fields(address) {
+					if ip := net.ParseIP(field); ip != nil {
+						endpoint.IPs = append(endpoint.IPs, ip)
+					}
+				}
+			}
+			return endpoint, nil
+		}
+	}
+	for _, rp := range topology.RouterPorts {
+		if rp.UUID == ref || rp.Name == ref {
+			endpoint := &pathEndpoint{ID: rp.UUID}
+			for _, network := range rp.Networks {
+				if ip, _, err := net.ParseCIDR(network); err == nil {
+					endpoint.IPs = append(endpoint.IPs, ip)
+				}
+			}
+			return endpoint, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPathEndpointNotFound, ref)
+}
+
+// shortestPath returns the node IDs from source to target, following edges
+// in both directions, or nil when they aren't connected
+func shortestPath(graph *TopologyGraph, source, target string) []string {
+	adjacent := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		adjacent[edge.Source] = append(adjacent[edge.Source], edge.Target)
+		adjacent[edge.Target] = append(adjacent[edge.Target], edge.Source)
+	}
+
+	parent := map[string]string{source: ""}
+	queue := []string{source}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			var ids []string
+			for id := target; id != ""; id = parent[id] {
+				ids = append([]string{id}, ids...)
+			}
+			return ids
+		}
+		for _, neighbor := range adjacent[current] {
+			if _, seen := parent[neighbor]; !seen {
+				parent[neighbor] = current
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return nil
+}
+
+func newPathHop(topology *services.Topology, nodeID string) PathHop {
+	kind, id, _ := strings.Cut(nodeID, ":")
+	hop := PathHop{ID: id}
+	switch kind {
+	case "switch":
+		hop.Type = HopSwitch
+		for _, sw := range topology.Switches {
+			if sw.UUID == id {
+				hop.Name = sw.Name
+			}
+		}
+	case "router":
+		hop.Type = HopRouter
+		if router := findRouter(topology, id); router != nil {
+			hop.Name = router.Name
+		}
+	default:
+		hop.Type = HopPort
+		for _, port := range topology.Ports {
+			if port.UUID == id {
+				hop.Name = port.Name
+			}
+		}
+		for _, rp := range topology.RouterPorts {
+			if rp.UUID == id {
+				hop.Type = HopRouterPort
+				hop.Name = rp.Name
+			}
+		}
+	}
+	return hop
+}
+
+func findRouter(topology *services.Topology, id string) *models.LogicalRouter {
+	for _, router := range topology.Routers {
+		if router.UUID == id {
+			return router
+		}
+	}
+	return nil
+}
+
+// pathACLs returns the ACLs that may apply to traffic entering a switch by
+// the ingress port and leaving it by the egress port: those whose match
+// doesn't name other ports. Port groups and sets aren't resolved, so ACLs
+// matching them are kept.
+func pathACLs(acls []*models.ACL, ingress, egress string) []*models.ACL {
+	var applied []*models.ACL
+	for _, acl := range acls {
+		if aclMatchesPorts(acl.Match, ingress, egress) {
+			applied = append(applied, acl)
+		}
+	}
+
+	// Ingress ACLs are evaluated before egress ones, each by priority
+	sort.SliceStable(applied, func(i, j int) bool {
+		if applied[i].Direction != applied[j].Direction {
+			return applied[i].Direction == "from-lport"
+		}
+		return applied[i].Priority > applied[j].Priority
+	})
+	return applied
+}
+
+func aclMatchesPorts(match, ingress, egress string) bool {
+	for _, m := range portMatch.FindAllStringSubmatch(match, -1) {
+		name := m[2]
+		if name == "" {
+			name = m[3]
+		}
+		if strings.HasPrefix(name, "@") || strings.HasPrefix(name, "$") || strings.HasPrefix(name, "{") {
+			continue
+		}
+		if (m[1] == "inport" && name != ingress) || (m[1] == "outport" && name != egress) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathNAT returns the NAT rules of a router translating traffic from the
+// source IPs to the target IPs: SNAT of a source and DNAT of a target
+func pathNAT(router *models.LogicalRouter, sources, targets []net.IP) []PathNAT {
+	if router == nil {
+		return nil
+	}
+
+	var applied []PathNAT
+	for _, nat := range router.NAT {
+		if (nat.Type == "snat" || nat.Type == "dnat_and_snat") && containsAny(nat.LogicalIP, sources) {
+			applied = append(applied, PathNAT{NAT: nat, Effect: NATEffectSNAT})
+		}
+		if (nat.Type == "dnat" || nat.Type == "dnat_and_snat") && containsAny(nat.ExternalIP, targets) {
+			applied = append(applied, PathNAT{NAT: nat, Effect: NATEffectDNAT})
+		}
+	}
+	return applied
+}
+
+// containsAny reports whether an IP or CIDR covers any of ips
+func containsAny(ipOrCIDR string, ips []net.IP) bool {
+	for _, ip := range ips {
+		if _, network, err := net.ParseCIDR(ipOrCIDR); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if parsed := net.ParseIP(ipOrCIDR); parsed != nil && parsed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
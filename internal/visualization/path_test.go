@@ -0,0 +1,117 @@
+package visualization
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// pathTopology has web and db switches joined by a router, and an isolated
+// switch
+func pathTopology() *services.Topology {
+	return &services.Topology{
+		Switches: []*models.LogicalSwitch{
+			{UUID: "sw-web", Name: "web", Ports: []string{"p-web-1", "p-web-2", "p-web-rtr"}},
+			{UUID: "sw-db", Name: "db", Ports: []string{"p-db-1", "p-db-rtr"}},
+			{UUID: "sw-iso", Name: "isolated", Ports: []string{"p-iso"}},
+		},
+		Routers: []*models.LogicalRouter{{
+			UUID:  "r-1",
+			Name:  "edge",
+			Ports: []string{"lrp-web", "lrp-db"},
+			NAT: []models.NAT{
+				{UUID: "nat-web", Type: "snat", LogicalIP: "10.0.1.0/24", ExternalIP: "172.16.0.1"},
+				{UUID: "nat-other", Type: "snat", LogicalIP: "10.0.9.0/24", ExternalIP: "172.16.0.9"},
+				{UUID: "nat-db", Type: "dnat_and_snat", LogicalIP: "10.0.2.99", ExternalIP: "10.0.2.20"},
+			},
+		}},
+		Ports: []*models.LogicalSwitchPort{
+			{UUID: "p-web-1", Name: "web-1", Addresses: []string{"00:00:00:00:01:01 10.0.1.10"}},
+			{UUID: "p-web-2", Name: "web-2", Addresses: []string{"00:00:00:00:01:02 10.0.1.11"}},
+			{UUID: "p-web-rtr", Name: "web-rtr", Type: "router", Options: map[string]string{"router-port": "rp-web"}},
+			{UUID: "p-db-1", Name: "db-1", Addresses: []string{"00:00:00:00:02:01 10.0.2.20"}},
+			{UUID: "p-db-rtr", Name: "db-rtr", Type: "router", Options: map[string]string{"router-port": "rp-db"}},
+			{UUID: "p-iso", Name: "iso-1"},
+		},
+		RouterPorts: []*models.LogicalRouterPort{
+			{UUID: "lrp-web", Name: "rp-web", Networks: []string{"10.0.1.1/24"}},
+			{UUID: "lrp-db", Name: "rp-db", Networks: []string{"10.0.2.1/24"}},
+		},
+	}
+}
+
+func pathACLLookup(acls map[string][]*models.ACL) ACLLookup {
+	return func(switchID string) ([]*models.ACL, error) {
+		return acls[switchID], nil
+	}
+}
+
+func hopIDs(path *Path) []string {
+	var ids []string
+	for _, hop := range path.Hops {
+		ids = append(ids, hop.Type+":"+hop.ID)
+	}
+	return ids
+}
+
+func TestFindPath_SameSwitch(t *testing.T) {
+	acls := map[string][]*models.ACL{"sw-web": {
+		{UUID: "acl-any", Priority: 100, Direction: "from-lport", Match: "ip4", Action: "allow"},
+		{UUID: "acl-egress", Priority: 500, Direction: "to-lport", Match: "outport == @pg_web", Action: "allow"},
+		{UUID: "acl-web-1", Priority: 1000, Direction: "from-lport", Match: `inport == "web-1" && ip4`, Action: "allow-related"},
+		{UUID: "acl-web-rtr", Priority: 900, Direction: "to-lport", Match: `outport == "web-rtr"`, Action: "drop"},
+	}}
+
+	path, err := FindPath(pathTopology(), "web-1", "p-web-2", pathACLLookup(acls))
+	require.NoError(t, err)
+
+	assert.Equal(t, "p-web-1", path.From)
+	assert.Equal(t, "p-web-2", path.To)
+	assert.Equal(t, "L2", path.Layer)
+	assert.Equal(t, []string{"port:p-web-1", "switch:sw-web", "port:p-web-2"}, hopIDs(path))
+
+	var applied []string
+	for _, acl := range path.Hops[1].ACLs {
+		applied = append(applied, acl.UUID)
+	}
+	assert.Equal(t, []string{"acl-web-1", "acl-any", "acl-egress"}, applied,
+		"ingress ACLs first by priority, without ACLs naming other ports")
+}
+
+func TestFindPath_AcrossRouter(t *testing.T) {
+	path, err := FindPath(pathTopology(), "p-web-1", "db-1", pathACLLookup(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, "L3", path.Layer)
+	assert.Equal(t, []string{
+		"port:p-web-1", "switch:sw-web", "port:p-web-rtr",
+		"router_port:lrp-web", "router:r-1", "router_port:lrp-db",
+		"port:p-db-rtr", "switch:sw-db", "port:p-db-1",
+	}, hopIDs(path))
+
+	router := path.Hops[4]
+	assert.Equal(t, "edge", router.Name)
+	require.Len(t, router.NAT, 2)
+	assert.Equal(t, "nat-web", router.NAT[0].UUID)
+	assert.Equal(t, NATEffectSNAT, router.NAT[0].Effect)
+	assert.Equal(t, "nat-db", router.NAT[1].UUID)
+	assert.Equal(t, NATEffectDNAT, router.NAT[1].Effect)
+}
+
+func TestFindPath_Errors(t *testing.T) {
+	_, err := FindPath(pathTopology(), "web-1", "missing", pathACLLookup(nil))
+	assert.True(t, errors.Is(err, ErrPathEndpointNotFound))
+
+	_, err = FindPath(pathTopology(), "web-1", "iso-1", pathACLLookup(nil))
+	assert.True(t, errors.Is(err, ErrNoPath))
+
+	_, err = FindPath(pathTopology(), "web-1", "web-2", func(string) ([]*models.ACL, error) {
+		return nil, errors.New("client not connected")
+	})
+	assert.ErrorContains(t, err, "not connected")
+}
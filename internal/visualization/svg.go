@@ -0,0 +1,182 @@
+package visualization
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+)
+
+// svgMargin pads the drawing so nodes and labels at the edges aren't clipped
+const svgMargin = 60.0
+
+// defaultNodeStyles style nodes that carry no style of their own, matching
+// the DOT export
+var defaultNodeStyles = map[NodeType]NodeStyle{
+	NodeTypeRouter:       {Shape: "circle", Color: "#66BB6A", BorderColor: "#4CAF50", Size: 70},
+	NodeTypeSwitch:       {Shape: "rectangle", Color: "#4FC3F7", BorderColor: "#29B6F6", Size: 60},
+	NodeTypePort:         {Shape: "dot", Color: "#FFB74D", BorderColor: "#FFA726", Size: 20},
+	NodeTypeLoadBalancer: {Shape: "hexagon", Color: "#BA68C8", BorderColor: "#AB47BC", Size: 50},
+	NodeTypeACL:          {Shape: "shield", Color: "#FF7043", BorderColor: "#FF5722", Size: 30},
+	NodeTypeNAT:          {Shape: "rectangle", Color: "#9CCC65", BorderColor: "#8BC34A", Size: 40},
+}
+
+// exportSVG renders the graph as an SVG image with the nodes' and edges'
+// styles. Nodes the layout didn't position are laid out in a grid below
+// the others.
+func (e *Exporter) exportSVG() ([]byte, error) {
+	positions := e.nodePositions()
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range positions {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	if len(positions) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+	width := maxX - minX + 2*svgMargin
+	height := maxY - minY + 2*svgMargin
+	// Shift the drawing so it starts at the margin
+	at := func(p Position) (float64, float64) {
+		return p.X - minX + svgMargin, p.Y - minY + svgMargin
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Arial, sans-serif" font-size="12">`+"\n",
+		width, height, width, height)
+	buf.WriteString(`  <rect width="100%" height="100%" fill="#FFFFFF"/>` + "\n")
+
+	// Edges go first so nodes are drawn over them
+	buf.WriteString("  <g class=\"edges\">\n")
+	for _, edge := range e.graph.Edges {
+		source, ok := positions[edge.Source]
+		if !ok {
+			continue
+		}
+		target, ok := positions[edge.Target]
+		if !ok {
+			continue
+		}
+		x1, y1 := at(source)
+		x2, y2 := at(target)
+
+		style := EdgeStyle{Color: "#9E9E9E", Width: 1, Style: "solid"}
+		if edge.Style != nil {
+			style = *edge.Style
+		}
+		fmt.Fprintf(&buf, `    <line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%d"%s/>`+"\n",
+			x1, y1, x2, y2, html.EscapeString(style.Color), max(style.Width, 1), svgDashArray(style.Style))
+		if edge.Label != "" {
+			fmt.Fprintf(&buf, `    <text x="%.1f" y="%.1f" text-anchor="middle" fill="#616161">%s</text>`+"\n",
+				(x1+x2)/2, (y1+y2)/2-4, html.EscapeString(edge.Label))
+		}
+	}
+	buf.WriteString("  </g>\n")
+
+	buf.WriteString("  <g class=\"nodes\">\n")
+	for _, node := range e.graph.Nodes {
+		style := nodeStyle(node)
+		x, y := at(positions[node.ID])
+		fmt.Fprintf(&buf, "    <g class=\"node %s\">\n      <title>%s</title>\n",
+			html.EscapeString(string(node.Type)), html.EscapeString(node.ID))
+		fmt.Fprintf(&buf, "      %s\n", svgShape(style, x, y))
+		fmt.Fprintf(&buf, `      <text x="%.1f" y="%.1f" text-anchor="middle" fill="#212121">%s</text>`+"\n",
+			x, y+float64(style.Size)/2+14, html.EscapeString(node.Label))
+		buf.WriteString("    </g>\n")
+	}
+	buf.WriteString("  </g>\n")
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
+
+// nodePositions returns the position of every node. The layout's positions
+// are kept; the rest are placed on a grid below them.
+func (e *Exporter) nodePositions() map[string]Position {
+	positions := make(map[string]Position, len(e.graph.Nodes))
+	var unplaced []string
+	bottom := 0.0
+	for _, node := range e.graph.Nodes {
+		if node.Position == nil {
+			unplaced = append(unplaced, node.ID)
+			continue
+		}
+		positions[node.ID] = *node.Position
+		bottom = math.Max(bottom, node.Position.Y)
+	}
+	if len(unplaced) == 0 {
+		return positions
+	}
+
+	top := 0.0
+	if len(positions) > 0 {
+		top = bottom + 200
+	}
+	cols := int(sqrt(float64(len(unplaced)))) + 1
+	spacing := 150.0
+	for i, id := range unplaced {
+		positions[id] = Position{
+			X: float64(i%cols) * spacing,
+			Y: top + float64(i/cols)*spacing,
+		}
+	}
+	return positions
+}
+
+// nodeStyle returns a node's style, or its type's, with defaults for unset
+// fields
+func nodeStyle(node GraphNode) NodeStyle {
+	style := defaultNodeStyles[node.Type]
+	if node.Style != nil {
+		style = *node.Style
+	}
+	if style.Size <= 0 {
+		style.Size = 30
+	}
+	if style.Color == "" {
+		style.Color = "#E0E0E0"
+	}
+	if style.BorderColor == "" {
+		style.BorderColor = "#9E9E9E"
+	}
+	return style
+}
+
+// svgShape draws a node's shape centered at x, y
+func svgShape(style NodeStyle, x, y float64) string {
+	r := float64(style.Size) / 2
+	fill := fmt.Sprintf(`fill="%s" stroke="%s" stroke-width="2"`,
+		html.EscapeString(style.Color), html.EscapeString(style.BorderColor))
+
+	switch style.Shape {
+	case "rectangle", "box":
+		return fmt.Sprintf(`<rect x="%.1f" y="%.1f" width="%d" height="%.1f" rx="6" %s/>`,
+			x-r, y-r*0.6, style.Size, r*1.2, fill)
+	case "diamond", "shield":
+		return fmt.Sprintf(`<polygon points="%.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f" %s/>`,
+			x, y-r, x+r, y, x, y+r, x-r, y, fill)
+	case "hexagon":
+		points := ""
+		for i := 0; i < 6; i++ {
+			angle := math.Pi / 3 * float64(i)
+			points += fmt.Sprintf("%.1f,%.1f ", x+r*cos(angle), y+r*sin(angle))
+		}
+		return fmt.Sprintf(`<polygon points="%s" %s/>`, points[:len(points)-1], fill)
+	default:
+		return fmt.Sprintf(`<circle cx="%.1f" cy="%.1f" r="%.1f" %s/>`, x, y, r, fill)
+	}
+}
+
+// svgDashArray returns the stroke-dasharray attribute of an edge line style
+func svgDashArray(lineStyle string) string {
+	switch lineStyle {
+	case "dashed":
+		return ` stroke-dasharray="8,4"`
+	case "dotted":
+		return ` stroke-dasharray="2,4"`
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,58 @@
+package visualization
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSVG(t *testing.T) {
+	topology := pathTopology()
+	topology.Switches[0].Name = `web & "frontend"`
+
+	for _, layout := range []string{"hierarchical", "none"} {
+		options := FullOptions()
+		options.Layout = layout
+		graph, err := NewTopologyVisualizer(topology).GenerateGraph(options)
+		require.NoError(t, err)
+
+		data, err := NewExporter(graph).Export("svg")
+		require.NoError(t, err, layout)
+
+		// The image is well-formed XML with one group per node
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		nodes, lines := 0, 0
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, layout)
+			if start, ok := token.(xml.StartElement); ok {
+				switch start.Name.Local {
+				case "g":
+					for _, attr := range start.Attr {
+						if attr.Name.Local == "class" && strings.HasPrefix(attr.Value, "node ") {
+							nodes++
+						}
+					}
+				case "line":
+					lines++
+				}
+			}
+		}
+		assert.Equal(t, len(graph.Nodes), nodes, layout)
+		assert.Equal(t, len(graph.Edges), lines, layout)
+
+		svg := string(data)
+		assert.Contains(t, svg, "web &amp; &#34;frontend&#34;", layout)
+		assert.Contains(t, svg, `fill="#4FC3F7"`, "switches keep their style")
+		assert.Contains(t, svg, "<circle", layout)
+		assert.Contains(t, svg, "<rect x=", layout)
+	}
+}
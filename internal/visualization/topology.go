@@ -3,6 +3,7 @@ package visualization
 import (
 	"fmt"
 	"math"
+	"regexp"
 	"strings"
 
 	"github.com/lspecian/ovncp/internal/models"
@@ -19,6 +20,7 @@ const (
 	NodeTypeLoadBalancer NodeType = "loadbalancer"
 	NodeTypeNAT          NodeType = "nat"
 	NodeTypeACL          NodeType = "acl"
+	NodeTypeGroup        NodeType = "group" // A collapsed group
 )
 
 // GraphNode represents a node in the topology graph
@@ -76,12 +78,14 @@ type TopologyGraph struct {
 	Properties map[string]interface{} `json:"properties"`
 }
 
-// Group represents a group of nodes
+// Group represents a group of nodes. A collapsed group's members are
+// replaced by a node with the group's ID.
 type Group struct {
-	ID    string     `json:"id"`
-	Label string     `json:"label"`
-	Nodes []string   `json:"nodes"`
-	Style GroupStyle `json:"style"`
+	ID        string     `json:"id"`
+	Label     string     `json:"label"`
+	Nodes     []string   `json:"nodes"`
+	Style     GroupStyle `json:"style"`
+	Collapsed bool       `json:"collapsed"`
 }
 
 // GroupStyle represents visual styling for groups
@@ -94,6 +98,7 @@ type GroupStyle struct {
 // TopologyVisualizer generates visual representations of network topology
 type TopologyVisualizer struct {
 	topology *services.Topology
+	traffic  map[string]services.PortTraffic
 }
 
 // NewTopologyVisualizer creates a new topology visualizer
@@ -108,6 +113,9 @@ func (v *TopologyVisualizer) GenerateGraph(options *VisualizationOptions) (*Topo
 	if options == nil {
 		options = DefaultVisualizationOptions()
 	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
 
 	graph := &TopologyGraph{
 		Nodes:      []GraphNode{},
@@ -143,6 +151,17 @@ func (v *TopologyVisualizer) GenerateGraph(options *VisualizationOptions) (*Topo
 		v.addACLs(graph, options)
 	}
 
+	// Overlay traffic before groups collapse the edges it's shown on
+	v.annotateTraffic(graph, options)
+
+	// Filter and group before the layout so it only places what remains
+	if options.FilterByName != "" {
+		filterByName(graph, regexp.MustCompile(options.FilterByName))
+	}
+	if key, _ := options.groupKey(); key != "" {
+		groupNodes(graph, key, v.nodeExternalIDs(), options.CollapseGroups)
+	}
+
 	// Apply layout
 	v.applyLayout(graph, options)
 
@@ -221,6 +240,25 @@ func (v *TopologyVisualizer) addRouters(graph *TopologyGraph, options *Visualiza
 
 // addPorts adds port nodes and connections to the graph
 func (v *TopologyVisualizer) addPorts(graph *TopologyGraph, options *VisualizationOptions) {
+	// Listed ports don't carry their switch, so map ports to their switch and
+	// router, and router port names to UUIDs
+	portSwitch := make(map[string]string)
+	for _, sw := range v.topology.Switches {
+		for _, portUUID := range sw.Ports {
+			portSwitch[portUUID] = sw.UUID
+		}
+	}
+	portRouter := make(map[string]string)
+	for _, router := range v.topology.Routers {
+		for _, portUUID := range router.Ports {
+			portRouter[portUUID] = router.UUID
+		}
+	}
+	routerPortIDs := make(map[string]string, len(v.topology.RouterPorts))
+	for _, rp := range v.topology.RouterPorts {
+		routerPortIDs[rp.Name] = rp.UUID
+	}
+
 	// Process all ports from topology
 	for _, port := range v.topology.Ports {
 		if options.DetailLevel < DetailLevelFull && !v.isSignificantPort(port) {
@@ -248,11 +286,15 @@ func (v *TopologyVisualizer) addPorts(graph *TopologyGraph, options *Visualizati
 
 		graph.Nodes = append(graph.Nodes, portNode)
 
-		// Add edge from switch to port if we have a switch ID
-		if port.SwitchID != "" {
+		// Add edge from switch to port if we know its switch
+		switchID := port.SwitchID
+		if switchID == "" {
+			switchID = portSwitch[port.UUID]
+		}
+		if switchID != "" {
 			edge := GraphEdge{
-				ID:     fmt.Sprintf("edge:%s-%s", port.SwitchID, port.UUID),
-				Source: "switch:" + port.SwitchID,
+				ID:     fmt.Sprintf("edge:%s-%s", switchID, port.UUID),
+				Source: "switch:" + switchID,
 				Target: "port:" + port.UUID,
 				Type:   "contains",
 				Style: &EdgeStyle{
@@ -267,6 +309,9 @@ func (v *TopologyVisualizer) addPorts(graph *TopologyGraph, options *Visualizati
 		// Add router connections
 		if port.Type == "router" && port.Options["router-port"] != "" {
 			routerPortID := port.Options["router-port"]
+			if id, ok := routerPortIDs[routerPortID]; ok {
+				routerPortID = id
+			}
 			routerEdge := GraphEdge{
 				ID:     fmt.Sprintf("edge:router-%s-%s", port.UUID, routerPortID),
 				Source: "port:" + port.UUID,
@@ -311,8 +356,36 @@ func (v *TopologyVisualizer) addPorts(graph *TopologyGraph, options *Visualizati
 
 		graph.Nodes = append(graph.Nodes, portNode)
 
-		// Note: We'd need to determine which router owns this port
-		// This is a simplified version - in real implementation you'd need proper mapping
+		if routerID, ok := portRouter[rp.UUID]; ok {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				ID:     fmt.Sprintf("edge:%s-%s", routerID, rp.UUID),
+				Source: "router:" + routerID,
+				Target: "port:" + rp.UUID,
+				Type:   "contains",
+				Style: &EdgeStyle{
+					Color: "#757575",
+					Width: 2,
+					Style: "solid",
+				},
+			})
+		}
+
+		// Peered router ports link routers directly; add each link once
+		if peerID, ok := routerPortIDs[rp.PeerPort]; ok && rp.Name < rp.PeerPort {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				ID:     fmt.Sprintf("edge:peer-%s-%s", rp.UUID, peerID),
+				Source: "port:" + rp.UUID,
+				Target: "port:" + peerID,
+				Type:   "connected",
+				Label:  "L3",
+				Style: &EdgeStyle{
+					Color:    "#4CAF50",
+					Width:    3,
+					Style:    "solid",
+					Animated: true,
+				},
+			})
+		}
 	}
 }
 
@@ -462,10 +535,10 @@ func (v *TopologyVisualizer) applyHierarchicalLayout(graph *TopologyGraph) {
 		}
 	}
 
-	// Layer 2: Switches
+	// Layer 2: Switches and collapsed groups
 	switchCount := 0
 	for i, node := range graph.Nodes {
-		if node.Type == NodeTypeSwitch {
+		if node.Type == NodeTypeSwitch || node.Type == NodeTypeGroup {
 			graph.Nodes[i].Position = &Position{
 				X: float64(switchCount * 150),
 				Y: 200,
@@ -0,0 +1,62 @@
+package visualization
+
+import (
+	"math"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+// Widths of edges carrying traffic, scaled by their share of the busiest
+// edge's rate
+const (
+	minTrafficWidth = 2
+	maxTrafficWidth = 8
+)
+
+// SetTraffic overlays the traffic of logical ports, keyed by port name, on
+// the edges to their ports
+func (v *TopologyVisualizer) SetTraffic(traffic map[string]services.PortTraffic) {
+	v.traffic = traffic
+}
+
+// annotateTraffic adds each port's counters and rates to the edges to it.
+// With AnimateTraffic, edges are animated while carrying traffic and widen
+// with their rate.
+func (v *TopologyVisualizer) annotateTraffic(graph *TopologyGraph, options *VisualizationOptions) {
+	if len(v.traffic) == 0 {
+		return
+	}
+
+	portTraffic := make(map[string]services.PortTraffic)
+	for _, port := range v.topology.Ports {
+		if t, ok := v.traffic[port.Name]; ok {
+			portTraffic["port:"+port.UUID] = t
+		}
+	}
+
+	maxBps := 0.0
+	for _, t := range portTraffic {
+		maxBps = math.Max(maxBps, t.Bps())
+	}
+
+	for i := range graph.Edges {
+		edge := &graph.Edges[i]
+		t, ok := portTraffic[edge.Target]
+		if !ok || edge.Type != "contains" {
+			continue
+		}
+
+		if edge.Properties == nil {
+			edge.Properties = make(map[string]interface{})
+		}
+		edge.Properties["traffic"] = t
+
+		if !options.AnimateTraffic || edge.Style == nil {
+			continue
+		}
+		edge.Style.Animated = t.Bps() > 0
+		if maxBps > 0 {
+			edge.Style.Width = minTrafficWidth + int(math.Round(t.Bps()/maxBps*(maxTrafficWidth-minTrafficWidth)))
+		}
+	}
+}
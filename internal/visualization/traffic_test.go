@@ -0,0 +1,56 @@
+package visualization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/services"
+)
+
+func TestGenerateGraph_Traffic(t *testing.T) {
+	traffic := map[string]services.PortTraffic{
+		"web-1": {RxBytes: 5000, RxBps: 8000, TxBps: 2000},
+		"web-2": {RxBytes: 100},
+		"db-1":  {RxBps: 1000},
+	}
+
+	edges := func(animate bool) map[string]GraphEdge {
+		options := FullOptions()
+		options.AnimateTraffic = animate
+		visualizer := NewTopologyVisualizer(pathTopology())
+		visualizer.SetTraffic(traffic)
+		graph, err := visualizer.GenerateGraph(options)
+		require.NoError(t, err)
+
+		byTarget := map[string]GraphEdge{}
+		for _, edge := range graph.Edges {
+			if edge.Type == "contains" {
+				byTarget[edge.Target] = edge
+			}
+		}
+		return byTarget
+	}
+
+	byTarget := edges(true)
+	web1 := byTarget["port:p-web-1"]
+	assert.Equal(t, traffic["web-1"], web1.Properties["traffic"])
+	assert.True(t, web1.Style.Animated)
+	assert.Equal(t, maxTrafficWidth, web1.Style.Width, "the busiest edge is widest")
+
+	db1 := byTarget["port:p-db-1"]
+	assert.True(t, db1.Style.Animated)
+	assert.Equal(t, 3, db1.Style.Width)
+
+	web2 := byTarget["port:p-web-2"]
+	assert.False(t, web2.Style.Animated, "idle ports aren't animated")
+	assert.Equal(t, minTrafficWidth, web2.Style.Width)
+	assert.Nil(t, byTarget["port:p-iso"].Properties)
+
+	// Without animation, edges only carry the counters
+	byTarget = edges(false)
+	assert.Equal(t, traffic["web-1"], byTarget["port:p-web-1"].Properties["traffic"])
+	assert.False(t, byTarget["port:p-web-1"].Style.Animated)
+	assert.Equal(t, 2, byTarget["port:p-web-1"].Style.Width)
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// ACLRolloutThresholds roll a rollout back automatically when its rules
+// match more traffic than they allow. Nil thresholds aren't checked.
+type ACLRolloutThresholds struct {
+	MaxHits        *uint64 `json:"max_hits,omitempty" yaml:"max_hits,omitempty"`
+	MaxLoggedFlows *int    `json:"max_logged_flows,omitempty" yaml:"max_logged_flows,omitempty"`
+}
+
+// ACLRolloutObservation is the traffic the rules of a rollout matched
+// since it started
+type ACLRolloutObservation struct {
+	Hits         uint64                `json:"hits" yaml:"hits"`
+	HitsRecorded bool                  `json:"hits_recorded" yaml:"hits_recorded"`
+	LoggedFlows  int                   `json:"logged_flows" yaml:"logged_flows"`
+	LogsIngested bool                  `json:"logs_ingested" yaml:"logs_ingested"`
+	Samples      []*models.ACLLogEntry `json:"samples,omitempty" yaml:"samples,omitempty"`
+	ObservedAt   time.Time             `json:"observed_at" yaml:"observed_at"`
+}
+
+// ACLRollout is a set of ACLs observed on a switch before they're enforced
+type ACLRollout struct {
+	ID          string                 `json:"id" yaml:"id"`
+	TenantID    string                 `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	SwitchID    string                 `json:"switch_id" yaml:"switch_id"`
+	Rules       []*ACL                 `json:"rules" yaml:"rules"`
+	Thresholds  ACLRolloutThresholds   `json:"thresholds" yaml:"thresholds"`
+	Status      string                 `json:"status" yaml:"status"`
+	Observation *ACLRolloutObservation `json:"observation,omitempty" yaml:"observation,omitempty"`
+	Reason      string                 `json:"reason,omitempty" yaml:"reason,omitempty"`
+	CreatedBy   string                 `json:"created_by,omitempty" yaml:"created_by,omitempty"`
+	StartedAt   time.Time              `json:"started_at" yaml:"started_at"`
+	BakeUntil   time.Time              `json:"bake_until" yaml:"bake_until"`
+	EndedAt     *time.Time             `json:"ended_at,omitempty" yaml:"ended_at,omitempty"`
+	EndedBy     string                 `json:"ended_by,omitempty" yaml:"ended_by,omitempty"`
+}
+
+// StartACLRolloutRequest rolls ACLs out to a switch. BakePeriod is a
+// duration such as "2h", the server's default when empty.
+type StartACLRolloutRequest struct {
+	SwitchID   string               `json:"switch_id"`
+	ACLs       []*ACL               `json:"acls"`
+	BakePeriod string               `json:"bake_period,omitempty"`
+	Thresholds ACLRolloutThresholds `json:"thresholds"`
+}
+
+// ListACLRollouts lists the rollouts, latest first, with the given status
+// or all of them when status is empty
+func (c *Client) ListACLRollouts(ctx context.Context, status string) ([]*ACLRollout, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	return listAll[*ACLRollout](ctx, c, "/api/v1/acl-rollouts", query, "rollouts")
+}
+
+func (c *Client) GetACLRollout(ctx context.Context, id string) (*ACLRollout, error) {
+	var rollout ACLRollout
+	if err := c.do(ctx, "GET", "/api/v1/acl-rollouts/"+url.PathEscape(id), nil, nil, &rollout); err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func (c *Client) StartACLRollout(ctx context.Context, req *StartACLRolloutRequest) (*ACLRollout, error) {
+	var rollout ACLRollout
+	if err := c.do(ctx, "POST", "/api/v1/acl-rollouts", nil, req, &rollout); err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func (c *Client) PromoteACLRollout(ctx context.Context, id string) (*ACLRollout, error) {
+	var rollout ACLRollout
+	if err := c.do(ctx, "POST", "/api/v1/acl-rollouts/"+url.PathEscape(id)+"/promote", nil, nil, &rollout); err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func (c *Client) RollbackACLRollout(ctx context.Context, id, reason string) (*ACLRollout, error) {
+	var rollout ACLRollout
+	body := map[string]string{"reason": reason}
+	if err := c.do(ctx, "POST", "/api/v1/acl-rollouts/"+url.PathEscape(id)+"/rollback", nil, body, &rollout); err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ACLScheduleWindow is a daily window an ACL is active in, from Start to
+// End as HH:MM. A window ending before it starts spans midnight; Days are
+// the days it starts on, mon to sun, every day when empty.
+type ACLScheduleWindow struct {
+	Start string   `json:"start" yaml:"start"`
+	End   string   `json:"end" yaml:"end"`
+	Days  []string `json:"days,omitempty" yaml:"days,omitempty"`
+}
+
+// ACLSchedule activates an ACL within time windows only; outside them its
+// match is replaced with one that never matches
+type ACLSchedule struct {
+	ACLID     string              `json:"acl_id" yaml:"acl_id"`
+	TenantID  string              `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Windows   []ACLScheduleWindow `json:"windows" yaml:"windows"`
+	Timezone  string              `json:"timezone" yaml:"timezone"`
+	Match     string              `json:"match" yaml:"match"`
+	Active    bool                `json:"active" yaml:"active"`
+	CreatedBy string              `json:"created_by,omitempty" yaml:"created_by,omitempty"`
+	CreatedAt time.Time           `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at" yaml:"updated_at"`
+}
+
+// ACLScheduleToggle records a scheduled ACL being enabled or disabled, by
+// the scheduler or by a change of its schedule
+type ACLScheduleToggle struct {
+	ID         string    `json:"id" yaml:"id"`
+	ACLID      string    `json:"acl_id" yaml:"acl_id"`
+	Action     string    `json:"action" yaml:"action"`
+	Reason     string    `json:"reason" yaml:"reason"`
+	UserID     string    `json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at" yaml:"occurred_at"`
+}
+
+// SetACLScheduleRequest sets the windows of an ACL; Timezone is an IANA
+// name, UTC when empty
+type SetACLScheduleRequest struct {
+	Windows  []ACLScheduleWindow `json:"windows"`
+	Timezone string              `json:"timezone,omitempty"`
+}
+
+// ListACLSchedules lists the scheduled ACLs, those enabled or disabled
+// when active is set
+func (c *Client) ListACLSchedules(ctx context.Context, active *bool) ([]*ACLSchedule, error) {
+	query := url.Values{}
+	if active != nil {
+		query.Set("active", strconv.FormatBool(*active))
+	}
+	return listAll[*ACLSchedule](ctx, c, "/api/v1/acl-schedules", query, "schedules")
+}
+
+func (c *Client) GetACLSchedule(ctx context.Context, aclID string) (*ACLSchedule, error) {
+	var schedule ACLSchedule
+	if err := c.do(ctx, "GET", "/api/v1/acls/"+url.PathEscape(aclID)+"/schedule", nil, nil, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (c *Client) SetACLSchedule(ctx context.Context, aclID string, req *SetACLScheduleRequest) (*ACLSchedule, error) {
+	var schedule ACLSchedule
+	if err := c.do(ctx, "PUT", "/api/v1/acls/"+url.PathEscape(aclID)+"/schedule", nil, req, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// DeleteACLSchedule removes the schedule of an ACL, leaving it enabled
+func (c *Client) DeleteACLSchedule(ctx context.Context, aclID string) error {
+	return c.do(ctx, "DELETE", "/api/v1/acls/"+url.PathEscape(aclID)+"/schedule", nil, nil, nil)
+}
+
+// ListACLScheduleToggles lists when an ACL was enabled and disabled,
+// oldest first
+func (c *Client) ListACLScheduleToggles(ctx context.Context, aclID string) ([]*ACLScheduleToggle, error) {
+	var resp struct {
+		Toggles []*ACLScheduleToggle `json:"toggles"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/acls/"+url.PathEscape(aclID)+"/schedule/toggles", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Toggles, nil
+}
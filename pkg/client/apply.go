@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ApplyChange is a single step of an apply plan
+type ApplyChange struct {
+	Action       string   `json:"action"`
+	ResourceType string   `json:"resource_type"`
+	Name         string   `json:"name"`
+	Switch       string   `json:"switch,omitempty"`
+	ID           string   `json:"id,omitempty"`
+	Fields       []string `json:"fields,omitempty"`
+}
+
+// ApplySummary counts the changes in a plan
+type ApplySummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// ApplyPlan is the ordered set of changes an apply makes
+type ApplyPlan struct {
+	Changes []*ApplyChange `json:"changes"`
+	Summary ApplySummary   `json:"summary"`
+}
+
+// ApplyResult reports the outcome of an apply
+type ApplyResult struct {
+	DryRun  bool       `json:"dry_run"`
+	Plan    *ApplyPlan `json:"plan"`
+	Applied int        `json:"applied"`
+}
+
+// ApplyOptions controls an apply
+type ApplyOptions struct {
+	// DryRun returns the plan without applying it
+	DryRun bool
+	// Prune deletes resources that are missing from the document
+	Prune bool
+}
+
+// Apply sends a YAML or JSON desired state document and converges the
+// network to it
+func (c *Client) Apply(ctx context.Context, document []byte, opts ApplyOptions) (*ApplyResult, error) {
+	query := url.Values{}
+	query.Set("dry_run", strconv.FormatBool(opts.DryRun))
+	query.Set("prune", strconv.FormatBool(opts.Prune))
+
+	var result ApplyResult
+	body := rawBody{contentType: "application/yaml", data: document}
+	if err := c.do(ctx, "POST", "/api/v1/apply", query, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Backup describes a stored backup
+type Backup struct {
+	ID          string            `json:"id" yaml:"id"`
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        string            `json:"type" yaml:"type"`
+	Format      string            `json:"format" yaml:"format"`
+	Version     string            `json:"version" yaml:"version"`
+	CreatedAt   time.Time         `json:"created_at" yaml:"created_at"`
+	CreatedBy   string            `json:"created_by" yaml:"created_by"`
+	Size        int64             `json:"size" yaml:"size"`
+	Checksum    string            `json:"checksum" yaml:"checksum"`
+	Tags        []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty" yaml:"extra,omitempty"`
+}
+
+// CreateBackupRequest are the options for a new backup
+type CreateBackupRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Compress    bool     `json:"compress"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// RestoreBackupRequest are the options for restoring a backup
+type RestoreBackupRequest struct {
+	DryRun         bool   `json:"dry_run"`
+	Force          bool   `json:"force"`
+	SkipValidation bool   `json:"skip_validation"`
+	ConflictPolicy string `json:"conflict_policy,omitempty"`
+	// Parallelism is how many resources are restored at a time, 0 for the
+	// server's default
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// RestoreResult is the outcome of a restore
+type RestoreResult struct {
+	Success       bool     `json:"success" yaml:"success"`
+	RestoredCount int      `json:"restored_count" yaml:"restored_count"`
+	SkippedCount  int      `json:"skipped_count" yaml:"skipped_count"`
+	ErrorCount    int      `json:"error_count" yaml:"error_count"`
+	Errors        []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Warnings      []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+func (c *Client) ListBackups(ctx context.Context) ([]*Backup, error) {
+	var result struct {
+		Backups []*Backup `json:"backups"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/backups", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Backups, nil
+}
+
+func (c *Client) GetBackup(ctx context.Context, id string) (*Backup, error) {
+	var b Backup
+	if err := c.do(ctx, "GET", "/api/v1/backups/"+url.PathEscape(id), nil, nil, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (c *Client) CreateBackup(ctx context.Context, req *CreateBackupRequest) (*Backup, error) {
+	var result struct {
+		Backup *Backup `json:"backup"`
+	}
+	if err := c.do(ctx, "POST", "/api/v1/backups", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return result.Backup, nil
+}
+
+// RestoreBackup restores a backup. A partially failed restore returns the
+// result with Success set to false rather than an error.
+func (c *Client) RestoreBackup(ctx context.Context, id string, req *RestoreBackupRequest) (*RestoreResult, error) {
+	var result RestoreResult
+	if err := c.do(ctx, "POST", "/api/v1/backups/"+url.PathEscape(id)+"/restore", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BackupDiff lists the objects added, removed and modified between two
+// backups, or a backup and the live configuration
+type BackupDiff struct {
+	Added    []BackupDiffObject   `json:"added" yaml:"added"`
+	Removed  []BackupDiffObject   `json:"removed" yaml:"removed"`
+	Modified []BackupObjectChange `json:"modified" yaml:"modified"`
+	Summary  map[string]int       `json:"summary" yaml:"summary"`
+}
+
+// BackupDiffObject is a switch, router, port, ACL or router policy
+type BackupDiffObject struct {
+	Type string `json:"type" yaml:"type"`
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// BackupObjectChange is an object whose fields differ
+type BackupObjectChange struct {
+	BackupDiffObject `yaml:",inline"`
+	Fields           []BackupFieldChange `json:"fields" yaml:"fields"`
+}
+
+// BackupFieldChange is a field's value before and after
+type BackupFieldChange struct {
+	Field  string      `json:"field" yaml:"field"`
+	Before interface{} `json:"before" yaml:"before"`
+	After  interface{} `json:"after" yaml:"after"`
+}
+
+// DiffBackups compares a backup with another, or with the live
+// configuration when other is "live"
+func (c *Client) DiffBackups(ctx context.Context, id, other string) (*BackupDiff, error) {
+	var diff BackupDiff
+	if err := c.do(ctx, "GET", "/api/v1/backups/"+url.PathEscape(id)+"/diff/"+url.PathEscape(other), nil, nil, &diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// VerificationReport is the signed outcome of replaying a backup against
+// the DR staging deployment
+type VerificationReport struct {
+	ID             string              `json:"id" yaml:"id"`
+	BackupID       string              `json:"backup_id" yaml:"backup_id"`
+	BackupName     string              `json:"backup_name" yaml:"backup_name"`
+	BackupChecksum string              `json:"backup_checksum,omitempty" yaml:"backup_checksum,omitempty"`
+	Staging        string              `json:"staging" yaml:"staging"`
+	StartedAt      time.Time           `json:"started_at" yaml:"started_at"`
+	CompletedAt    time.Time           `json:"completed_at" yaml:"completed_at"`
+	Passed         bool                `json:"passed" yaml:"passed"`
+	Restore        *RestoreResult      `json:"restore" yaml:"restore"`
+	Checks         []VerificationCheck `json:"checks" yaml:"checks"`
+	Signature      string              `json:"signature" yaml:"signature"`
+	// Raw is the report as the server signed it, to be archived; the
+	// fields above don't cover all of it
+	Raw []byte `json:"-" yaml:"-"`
+}
+
+// VerificationCheck compares the count and checksum of a kind of resource
+// in a backup with those restored from it
+type VerificationCheck struct {
+	Kind             string `json:"kind" yaml:"kind"`
+	ExpectedCount    int    `json:"expected_count" yaml:"expected_count"`
+	ActualCount      int    `json:"actual_count" yaml:"actual_count"`
+	ExpectedChecksum string `json:"expected_checksum" yaml:"expected_checksum"`
+	ActualChecksum   string `json:"actual_checksum" yaml:"actual_checksum"`
+	Passed           bool   `json:"passed" yaml:"passed"`
+}
+
+// VerifyRestore replays a backup against the DR staging deployment. A
+// backup failing verification returns the report with Passed set to false
+// rather than an error.
+func (c *Client) VerifyRestore(ctx context.Context, id string) (*VerificationReport, error) {
+	data, err := c.doRaw(ctx, "POST", "/api/v1/backups/"+url.PathEscape(id)+"/verify", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var report VerificationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	report.Raw = data
+	return &report, nil
+}
+
+func (c *Client) DeleteBackup(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/backups/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ExportBackup downloads a backup in the given format (json or yaml)
+func (c *Client) ExportBackup(ctx context.Context, id, format string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+	return c.doRaw(ctx, "GET", "/api/v1/backups/"+url.PathEscape(id)+"/export", query, nil)
+}
+
+// GoldenBackup marks a backup as the configuration of a tenant, or of the
+// cluster when TenantID is empty
+type GoldenBackup struct {
+	TenantID   string    `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	BackupID   string    `json:"backup_id" yaml:"backup_id"`
+	BackupName string    `json:"backup_name,omitempty" yaml:"backup_name,omitempty"`
+	MarkedBy   string    `json:"marked_by,omitempty" yaml:"marked_by,omitempty"`
+	MarkedAt   time.Time `json:"marked_at" yaml:"marked_at"`
+}
+
+// DriftReport compares the live configuration of a scope with its golden
+// backup; added objects exist live only
+type DriftReport struct {
+	TenantID  string               `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	CheckedAt time.Time            `json:"checked_at" yaml:"checked_at"`
+	Drifted   bool                 `json:"drifted" yaml:"drifted"`
+	Added     []BackupDiffObject   `json:"added" yaml:"added"`
+	Removed   []BackupDiffObject   `json:"removed" yaml:"removed"`
+	Modified  []BackupObjectChange `json:"modified" yaml:"modified"`
+	Summary   map[string]int       `json:"summary" yaml:"summary"`
+}
+
+// ReconcilePlan lists the steps bringing a scope back to its golden backup
+type ReconcilePlan struct {
+	TenantID string          `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Steps    []ReconcileStep `json:"steps" yaml:"steps"`
+	Summary  map[string]int  `json:"summary" yaml:"summary"`
+}
+
+// ReconcileStep creates, updates or deletes an object
+type ReconcileStep struct {
+	Action           string `json:"action" yaml:"action"`
+	BackupDiffObject `yaml:",inline"`
+	Parent           string              `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Fields           []BackupFieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// ReconcileResult is the outcome of a reconciliation, with the drift left
+type ReconcileResult struct {
+	Plan    *ReconcilePlan `json:"plan" yaml:"plan"`
+	Applied int            `json:"applied" yaml:"applied"`
+	Failed  int            `json:"failed" yaml:"failed"`
+	Errors  []string       `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Drift   *DriftReport   `json:"drift,omitempty" yaml:"drift,omitempty"`
+	Success bool           `json:"success" yaml:"success"`
+}
+
+// driftScope is the query selecting a tenant, or the cluster when tenantID
+// is empty
+func driftScope(tenantID string) url.Values {
+	query := url.Values{}
+	if tenantID != "" {
+		query.Set("tenant_id", tenantID)
+	}
+	return query
+}
+
+func (c *Client) ListGoldenBackups(ctx context.Context) ([]*GoldenBackup, error) {
+	var result struct {
+		Golden []*GoldenBackup `json:"golden"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/drift/golden", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Golden, nil
+}
+
+// MarkGoldenBackup marks a backup as the golden configuration of a tenant,
+// or of the cluster when tenantID is empty
+func (c *Client) MarkGoldenBackup(ctx context.Context, tenantID, backupID string) (*GoldenBackup, error) {
+	req := map[string]string{"backup_id": backupID, "tenant_id": tenantID}
+	var golden GoldenBackup
+	if err := c.do(ctx, "PUT", "/api/v1/drift/golden", nil, req, &golden); err != nil {
+		return nil, err
+	}
+	return &golden, nil
+}
+
+func (c *Client) UnmarkGoldenBackup(ctx context.Context, tenantID string) error {
+	return c.do(ctx, "DELETE", "/api/v1/drift/golden", driftScope(tenantID), nil, nil)
+}
+
+// CheckDrift compares the live configuration of a scope with its golden
+// backup
+func (c *Client) CheckDrift(ctx context.Context, tenantID string) (*DriftReport, error) {
+	var report DriftReport
+	if err := c.do(ctx, "POST", "/api/v1/drift/check", driftScope(tenantID), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// PlanReconcile returns the steps reconciling a scope with its golden
+// backup, without applying them
+func (c *Client) PlanReconcile(ctx context.Context, tenantID string) (*ReconcilePlan, error) {
+	var plan ReconcilePlan
+	if err := c.do(ctx, "GET", "/api/v1/drift/plan", driftScope(tenantID), nil, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Reconcile applies the reconcile plan of a scope. Failed steps return the
+// result with Success set to false rather than an error.
+func (c *Client) Reconcile(ctx context.Context, tenantID string) (*ReconcileResult, error) {
+	var result ReconcileResult
+	if err := c.do(ctx, "POST", "/api/v1/drift/reconcile", driftScope(tenantID), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
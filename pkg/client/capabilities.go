@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Capability matrix types
+type (
+	Capabilities = ovn.Capabilities
+	Capability   = ovn.Capability
+)
+
+// GetCapabilities returns which features the northbound schema of the
+// cluster supports
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	var capabilities Capabilities
+	if err := c.do(ctx, "GET", "/api/v1/capabilities", nil, nil, &capabilities); err != nil {
+		return nil, err
+	}
+	return &capabilities, nil
+}
@@ -0,0 +1,235 @@
+// Package client is a Go client for the OVN Control Platform REST API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ClusterHeader selects the OVN cluster a request is served by
+	ClusterHeader = "X-OVN-Cluster"
+	// TenantHeader selects the tenant a request is scoped to
+	TenantHeader = "X-Tenant-ID"
+)
+
+// Client talks to the ovncp API
+type Client struct {
+	baseURL    string
+	token      string
+	cluster    string
+	tenant     string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sets the bearer token sent with every request
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithCluster sends requests to the named OVN cluster instead of the default
+func WithCluster(cluster string) Option {
+	return func(c *Client) {
+		c.cluster = cluster
+	}
+}
+
+// WithTenant scopes requests to the given tenant
+func WithTenant(tenant string) Option {
+	return func(c *Client) {
+		c.tenant = tenant
+	}
+}
+
+// New creates a client for the API served at baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned for responses with a 4xx or 5xx status
+type APIError struct {
+	StatusCode int
+	Code       string // Machine-readable error code, see docs/errors.md
+	Message    string
+	Details    string
+	Fields     []FieldError // What's wrong with each field of an invalid request
+	Body       string
+}
+
+// FieldError is what's wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Body)
+	}
+	details := e.Details
+	if len(e.Fields) > 0 {
+		fields := make([]string, 0, len(e.Fields))
+		for _, field := range e.Fields {
+			fields = append(fields, field.Field+" "+field.Message)
+		}
+		details = strings.Join(fields, "; ")
+	}
+	if details != "" {
+		return fmt.Sprintf("API error (%d): %s: %s", e.StatusCode, e.Message, details)
+	}
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an API error with status 404
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// rawBody is a request body sent as-is instead of being JSON encoded
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+// do sends a JSON request and decodes the response into out, if non-nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	data, err := c.doRaw(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doRaw sends a JSON request and returns the raw response body
+func (c *Client) doRaw(ctx context.Context, method, path string, query url.Values, body interface{}) ([]byte, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp.StatusCode, data)
+	}
+
+	return data, nil
+}
+
+// newRequest creates a request accepting JSON, with the client's
+// credentials, cluster and tenant
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	var reqBody io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case rawBody:
+		reqBody = bytes.NewReader(b.data)
+		contentType = b.contentType
+	default:
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.cluster != "" {
+		req.Header.Set(ClusterHeader, c.cluster)
+	}
+	if c.tenant != "" {
+		req.Header.Set(TenantHeader, c.tenant)
+	}
+	return req, nil
+}
+
+// newAPIError returns the error of a response with status, whose body is
+// data
+func newAPIError(status int, data []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Body:       strings.TrimSpace(string(data)),
+	}
+	// Errors are problem details; servers of earlier versions only send
+	// error and details
+	var errBody struct {
+		Title   string       `json:"title"`
+		Detail  string       `json:"detail"`
+		Code    string       `json:"code"`
+		Errors  []FieldError `json:"errors"`
+		Error   string       `json:"error"`
+		Details string       `json:"details"`
+	}
+	if json.Unmarshal(data, &errBody) == nil {
+		apiErr.Code = errBody.Code
+		apiErr.Message = errBody.Title
+		apiErr.Details = errBody.Detail
+		apiErr.Fields = errBody.Errors
+		if apiErr.Message == "" {
+			apiErr.Message = errBody.Error
+			apiErr.Details = errBody.Details
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "eu-west", r.Header.Get(ClusterHeader))
+		assert.Equal(t, "tenant-1", r.Header.Get(TenantHeader))
+		assert.Equal(t, "/api/v1/switches", r.URL.Path)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"switches": []map[string]string{{"uuid": "uuid1", "name": "ls1"}},
+			"count":    1,
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/", WithToken("secret"), WithCluster("eu-west"), WithTenant("tenant-1"))
+
+	switches, err := c.ListSwitches(context.Background())
+	require.NoError(t, err)
+	require.Len(t, switches, 1)
+	assert.Equal(t, "ls1", switches[0].Name)
+}
+
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "load balancer lb1 not found"})
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).GetLoadBalancer(context.Background(), "lb1")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "API error (404): load balancer lb1 not found", err.Error())
+}
+
+func TestClient_ProblemDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"type":   "https://github.com/lspecian/ovncp/blob/main/docs/errors.md#validation_failed",
+			"title":  "invalid request body",
+			"status": 400,
+			"detail": "Key: 'LogicalSwitch.name' Error:Field validation for 'name' failed on the 'required' tag",
+			"code":   "validation_failed",
+			"errors": []map[string]string{{"field": "name", "code": "required", "message": "is required"}},
+		})
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).GetLoadBalancer(context.Background(), "lb1")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+	assert.Equal(t, []FieldError{{Field: "name", Code: "required", Message: "is required"}}, apiErr.Fields)
+	assert.Equal(t, "API error (400): invalid request body: name is required", err.Error())
+}
+
+func TestClient_ListACLsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "ls1", r.URL.Query().Get("switch_id"))
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+
+		// The first page links to the second, which is the last
+		pagination := map[string]string{"next_cursor": "c2"}
+		page := "1"
+		if r.URL.Query().Get("cursor") == "c2" {
+			pagination = map[string]string{}
+			page = "2"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"acls":       []map[string]string{{"uuid": "acl-" + page}},
+			"pagination": pagination,
+		})
+	}))
+	defer server.Close()
+
+	acls, err := New(server.URL).ListACLs(context.Background(), "ls1")
+	require.NoError(t, err)
+	require.Len(t, acls, 2)
+	assert.Equal(t, "acl-1", acls[0].UUID)
+	assert.Equal(t, "acl-2", acls[1].UUID)
+}
+
+func TestClient_StreamSwitches(t *testing.T) {
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, NDJSONContentType, r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", NDJSONContentType)
+		fmt.Fprintln(w, `{"uuid":"ls1","name":"web"}`)
+		fmt.Fprintln(w, `{"uuid":"ls2","name":"db"}`)
+		if fail {
+			fmt.Fprintln(w, `{"error":{"title":"failed to read the list","status":500,"detail":"connection lost"}}`)
+		}
+	}))
+	defer server.Close()
+
+	var names []string
+	err := New(server.URL).StreamSwitches(context.Background(), func(ls *LogicalSwitch) error {
+		names = append(names, ls.Name)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web", "db"}, names)
+
+	fail = true
+	err = New(server.URL).StreamSwitches(context.Background(), func(ls *LogicalSwitch) error { return nil })
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, "connection lost", apiErr.Details)
+}
+
+func TestClient_StreamWithoutNDJSON(t *testing.T) {
+	// Servers that don't stream lists answer with a page of JSON
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"load_balancers": []map[string]string{{"uuid": "lb1"}},
+			"pagination":     map[string]string{},
+		})
+	}))
+	defer server.Close()
+
+	var uuids []string
+	err := New(server.URL).StreamLoadBalancers(context.Background(), func(lb *LoadBalancer) error {
+		uuids = append(uuids, lb.UUID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lb1"}, uuids)
+}
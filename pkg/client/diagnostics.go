@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Diagnostics downloads the diagnostics bundle attached to support cases,
+// as JSON or, with format "tar", as a gzipped tarball that also holds the
+// configuration in effect and a goroutine dump
+func (c *Client) Diagnostics(ctx context.Context, format string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+	return c.doRaw(ctx, "GET", "/api/v1/admin/diagnostics", query, nil)
+}
+
+// NorthdStatus is how far ovn-northd and the chassis have caught up with
+// the northbound configuration
+type NorthdStatus = ovn.NorthdStatus
+
+// NorthdConsistencyReport lists the switches, routers and ports ovn-northd
+// hasn't realized in the southbound database yet; its status is
+// consistent, pending or stuck
+type NorthdConsistencyReport struct {
+	CheckedAt        time.Time             `json:"checked_at" yaml:"checked_at"`
+	Status           string                `json:"status" yaml:"status"`
+	Summary          string                `json:"summary" yaml:"summary"`
+	ThresholdSeconds float64               `json:"threshold_seconds" yaml:"threshold_seconds"`
+	Northd           *NorthdStatus         `json:"northd,omitempty" yaml:"northd,omitempty"`
+	NorthdError      string                `json:"northd_error,omitempty" yaml:"northd_error,omitempty"`
+	SbLagSeconds     float64               `json:"sb_lag_seconds" yaml:"sb_lag_seconds"`
+	Checked          int                   `json:"checked" yaml:"checked"`
+	Pending          []NorthdPendingObject `json:"pending" yaml:"pending"`
+	Stuck            int                   `json:"stuck" yaml:"stuck"`
+}
+
+// NorthdPendingObject is a northbound object without its southbound
+// datapath or port binding
+type NorthdPendingObject struct {
+	Type           string    `json:"type" yaml:"type"`
+	UUID           string    `json:"uuid" yaml:"uuid"`
+	Name           string    `json:"name" yaml:"name"`
+	PendingSince   time.Time `json:"pending_since" yaml:"pending_since"`
+	PendingSeconds float64   `json:"pending_seconds" yaml:"pending_seconds"`
+	Stuck          bool      `json:"stuck" yaml:"stuck"`
+}
+
+// CheckNorthdConsistency compares the northbound database to what
+// ovn-northd realized in the southbound one. Objects pending for longer
+// than threshold, 30s when zero, report ovn-northd stuck.
+func (c *Client) CheckNorthdConsistency(ctx context.Context, threshold time.Duration) (*NorthdConsistencyReport, error) {
+	query := url.Values{}
+	if threshold > 0 {
+		query.Set("threshold", threshold.String())
+	}
+	var report NorthdConsistencyReport
+	if err := c.do(ctx, "GET", "/api/v1/admin/northd-consistency", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
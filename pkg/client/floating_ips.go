@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// FloatingIPPool is a range of external addresses NATed by a router
+type FloatingIPPool struct {
+	Name      string `json:"name" yaml:"name"`
+	CIDR      string `json:"cidr" yaml:"cidr"`
+	First     string `json:"first" yaml:"first"`
+	Last      string `json:"last" yaml:"last"`
+	Router    string `json:"router" yaml:"router"`
+	Allocated int    `json:"allocated" yaml:"allocated"`
+}
+
+// FloatingIP is an external address allocated from a pool, translated to
+// the address of a port once bound
+type FloatingIP struct {
+	ID            string     `json:"id" yaml:"id"`
+	TenantID      string     `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Pool          string     `json:"pool" yaml:"pool"`
+	IP            string     `json:"ip" yaml:"ip"`
+	Description   string     `json:"description,omitempty" yaml:"description,omitempty"`
+	RouterID      string     `json:"router_id" yaml:"router_id"`
+	PortID        string     `json:"port_id,omitempty" yaml:"port_id,omitempty"`
+	LogicalIP     string     `json:"logical_ip,omitempty" yaml:"logical_ip,omitempty"`
+	NATID         string     `json:"nat_id,omitempty" yaml:"nat_id,omitempty"`
+	Distributed   bool       `json:"distributed,omitempty" yaml:"distributed,omitempty"`
+	GratuitousARP bool       `json:"gratuitous_arp,omitempty" yaml:"gratuitous_arp,omitempty"`
+	AllocatedBy   string     `json:"allocated_by,omitempty" yaml:"allocated_by,omitempty"`
+	AllocatedAt   time.Time  `json:"allocated_at" yaml:"allocated_at"`
+	BoundAt       *time.Time `json:"bound_at,omitempty" yaml:"bound_at,omitempty"`
+}
+
+// BindFloatingIPRequest binds a floating IP to a port. LogicalIP picks
+// one of the port's addresses, the first of the floating IP's family when
+// empty.
+type BindFloatingIPRequest struct {
+	PortID        string `json:"port_id"`
+	LogicalIP     string `json:"logical_ip,omitempty"`
+	Distributed   bool   `json:"distributed,omitempty"`
+	GratuitousARP bool   `json:"gratuitous_arp,omitempty"`
+}
+
+func (c *Client) ListFloatingIPPools(ctx context.Context) ([]*FloatingIPPool, error) {
+	var resp struct {
+		Pools []*FloatingIPPool `json:"pools"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/floating-ips/pools", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Pools, nil
+}
+
+// ListFloatingIPs lists the floating IPs of the tenant, only the bound or
+// unbound ones when bound isn't nil
+func (c *Client) ListFloatingIPs(ctx context.Context, bound *bool) ([]*FloatingIP, error) {
+	query := url.Values{}
+	if bound != nil {
+		if *bound {
+			query.Set("bound", "true")
+		} else {
+			query.Set("bound", "false")
+		}
+	}
+	return listAll[*FloatingIP](ctx, c, "/api/v1/floating-ips", query, "floating_ips")
+}
+
+func (c *Client) GetFloatingIP(ctx context.Context, id string) (*FloatingIP, error) {
+	var fip FloatingIP
+	if err := c.do(ctx, "GET", "/api/v1/floating-ips/"+url.PathEscape(id), nil, nil, &fip); err != nil {
+		return nil, err
+	}
+	return &fip, nil
+}
+
+// AllocateFloatingIP allocates a floating IP from a pool, the first one
+// when pool is empty
+func (c *Client) AllocateFloatingIP(ctx context.Context, pool, description string) (*FloatingIP, error) {
+	var fip FloatingIP
+	body := map[string]string{"pool": pool, "description": description}
+	if err := c.do(ctx, "POST", "/api/v1/floating-ips", nil, body, &fip); err != nil {
+		return nil, err
+	}
+	return &fip, nil
+}
+
+func (c *Client) BindFloatingIP(ctx context.Context, id string, req *BindFloatingIPRequest) (*FloatingIP, error) {
+	var fip FloatingIP
+	if err := c.do(ctx, "POST", "/api/v1/floating-ips/"+url.PathEscape(id)+"/bind", nil, req, &fip); err != nil {
+		return nil, err
+	}
+	return &fip, nil
+}
+
+func (c *Client) UnbindFloatingIP(ctx context.Context, id string) (*FloatingIP, error) {
+	var fip FloatingIP
+	if err := c.do(ctx, "POST", "/api/v1/floating-ips/"+url.PathEscape(id)+"/unbind", nil, nil, &fip); err != nil {
+		return nil, err
+	}
+	return &fip, nil
+}
+
+// ReleaseFloatingIP returns a floating IP to its pool, unbinding it first
+func (c *Client) ReleaseFloatingIP(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/floating-ips/"+url.PathEscape(id), nil, nil, nil)
+}
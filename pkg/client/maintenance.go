@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// MaintenanceMode freezes writes to the API for a tenant, or for the whole
+// cluster when TenantID is empty
+type MaintenanceMode struct {
+	TenantID string     `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Frozen   bool       `json:"frozen" yaml:"frozen"`
+	Reason   string     `json:"reason,omitempty" yaml:"reason,omitempty"`
+	ETA      *time.Time `json:"eta,omitempty" yaml:"eta,omitempty"`
+	SetBy    string     `json:"set_by,omitempty" yaml:"set_by,omitempty"`
+	SetAt    time.Time  `json:"set_at" yaml:"set_at"`
+}
+
+// SetMaintenanceRequest sets a maintenance mode. Frozen false exempts a
+// tenant from a cluster-wide freeze.
+type SetMaintenanceRequest struct {
+	TenantID string     `json:"tenant_id,omitempty"`
+	Frozen   bool       `json:"frozen"`
+	Reason   string     `json:"reason,omitempty"`
+	ETA      *time.Time `json:"eta,omitempty"`
+}
+
+// ListMaintenanceModes lists the maintenance modes set, the cluster's
+// first
+func (c *Client) ListMaintenanceModes(ctx context.Context) ([]*MaintenanceMode, error) {
+	var result struct {
+		Modes []*MaintenanceMode `json:"modes"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/admin/maintenance", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Modes, nil
+}
+
+func (c *Client) SetMaintenance(ctx context.Context, req *SetMaintenanceRequest) (*MaintenanceMode, error) {
+	var mode MaintenanceMode
+	if err := c.do(ctx, "PUT", "/api/v1/admin/maintenance", nil, req, &mode); err != nil {
+		return nil, err
+	}
+	return &mode, nil
+}
+
+// ClearMaintenance leaves the maintenance mode of a tenant, or of the
+// cluster when tenantID is empty
+func (c *Client) ClearMaintenance(ctx context.Context, tenantID string) error {
+	query := url.Values{}
+	if tenantID != "" {
+		query.Set("tenant_id", tenantID)
+	}
+	return c.do(ctx, "DELETE", "/api/v1/admin/maintenance", query, nil, nil)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// GatewayChassis is a chassis a gateway port may be active on
+type GatewayChassis struct {
+	Name     string `json:"name" yaml:"name"`
+	Priority int    `json:"priority" yaml:"priority"`
+}
+
+// ProviderNetworkRouter is a router attached to a provider network
+type ProviderNetworkRouter struct {
+	RouterID       string           `json:"router_id" yaml:"router_id"`
+	RouterName     string           `json:"router_name" yaml:"router_name"`
+	RouterPortID   string           `json:"router_port_id" yaml:"router_port_id"`
+	RouterPortName string           `json:"router_port_name" yaml:"router_port_name"`
+	SwitchPortID   string           `json:"switch_port_id,omitempty" yaml:"switch_port_id,omitempty"`
+	MAC            string           `json:"mac" yaml:"mac"`
+	Networks       []string         `json:"networks" yaml:"networks"`
+	HAChassisGroup string           `json:"ha_chassis_group,omitempty" yaml:"ha_chassis_group,omitempty"`
+	Chassis        []GatewayChassis `json:"chassis,omitempty" yaml:"chassis,omitempty"`
+}
+
+// ProviderNetwork is a switch bridged to a physical network by a localnet
+// port
+type ProviderNetwork struct {
+	ID              string                   `json:"id" yaml:"id"`
+	Name            string                   `json:"name" yaml:"name"`
+	PhysicalNetwork string                   `json:"physical_network" yaml:"physical_network"`
+	VLAN            int                      `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	CIDR            string                   `json:"cidr,omitempty" yaml:"cidr,omitempty"`
+	LocalnetPortID  string                   `json:"localnet_port_id" yaml:"localnet_port_id"`
+	Chassis         []string                 `json:"chassis" yaml:"chassis"`
+	Routers         []*ProviderNetworkRouter `json:"routers" yaml:"routers"`
+}
+
+// CreateProviderNetworkRequest defines a provider network on a physical
+// network, untagged when VLAN is zero
+type CreateProviderNetworkRequest struct {
+	Name            string `json:"name"`
+	PhysicalNetwork string `json:"physical_network"`
+	VLAN            int    `json:"vlan,omitempty"`
+	CIDR            string `json:"cidr,omitempty"`
+}
+
+// AttachRouterRequest attaches a router to a provider network. The server
+// generates the MAC when it's empty and places the gateway port on every
+// gateway chassis mapping the physical network when Chassis is empty.
+type AttachRouterRequest struct {
+	RouterID       string   `json:"router_id"`
+	Networks       []string `json:"networks"`
+	MAC            string   `json:"mac,omitempty"`
+	Chassis        []string `json:"chassis,omitempty"`
+	HAChassisGroup string   `json:"ha_chassis_group,omitempty"`
+}
+
+func (c *Client) ListProviderNetworks(ctx context.Context) ([]*ProviderNetwork, error) {
+	var resp struct {
+		Networks []*ProviderNetwork `json:"provider_networks"`
+	}
+	if err := c.do(ctx, "GET", "/api/v1/provider-networks", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Networks, nil
+}
+
+func (c *Client) GetProviderNetwork(ctx context.Context, id string) (*ProviderNetwork, error) {
+	var network ProviderNetwork
+	if err := c.do(ctx, "GET", "/api/v1/provider-networks/"+url.PathEscape(id), nil, nil, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+func (c *Client) CreateProviderNetwork(ctx context.Context, req *CreateProviderNetworkRequest) (*ProviderNetwork, error) {
+	var network ProviderNetwork
+	if err := c.do(ctx, "POST", "/api/v1/provider-networks", nil, req, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+func (c *Client) DeleteProviderNetwork(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/provider-networks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (c *Client) AttachRouterToProviderNetwork(ctx context.Context, id string, req *AttachRouterRequest) (*ProviderNetwork, error) {
+	var network ProviderNetwork
+	if err := c.do(ctx, "POST", "/api/v1/provider-networks/"+url.PathEscape(id)+"/routers", nil, req, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+func (c *Client) DetachRouterFromProviderNetwork(ctx context.Context, id, routerID string) (*ProviderNetwork, error) {
+	var network ProviderNetwork
+	path := "/api/v1/provider-networks/" + url.PathEscape(id) + "/routers/" + url.PathEscape(routerID)
+	if err := c.do(ctx, "DELETE", path, nil, nil, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Resource types returned by the API
+type (
+	LogicalSwitch     = models.LogicalSwitch
+	LogicalRouter     = models.LogicalRouter
+	LogicalSwitchPort = models.LogicalSwitchPort
+	LogicalRouterPort = models.LogicalRouterPort
+	ACL               = models.ACL
+	LoadBalancer      = models.LoadBalancer
+)
+
+// Topology is the full logical network as returned by GET /topology
+type Topology struct {
+	Switches    []*LogicalSwitch
+	Routers     []*LogicalRouter
+	Ports       []*LogicalSwitchPort
+	RouterPorts []*LogicalRouterPort
+	ACLs        []*ACL
+	Connections []Connection
+	Timestamp   time.Time
+}
+
+// Connection is a link between two topology elements
+type Connection struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Type   string `json:"type"`
+	PortID string `json:"port_id,omitempty"`
+}
+
+// listPageSize is the page size lists are fetched with, the largest the API
+// allows
+const listPageSize = 1000
+
+// listAll returns every item of a paged list, following the next_cursor of
+// each page. member names the list in the response body.
+func listAll[T any](ctx context.Context, c *Client, path string, query url.Values, member string) ([]T, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(listPageSize))
+
+	var items []T
+	for {
+		var result map[string]json.RawMessage
+		if err := c.do(ctx, "GET", path, query, nil, &result); err != nil {
+			return nil, err
+		}
+
+		var page []T
+		if data, ok := result[member]; ok {
+			if err := json.Unmarshal(data, &page); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		items = append(items, page...)
+
+		var pagination struct {
+			NextCursor string `json:"next_cursor"`
+		}
+		if data, ok := result["pagination"]; ok {
+			if err := json.Unmarshal(data, &pagination); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		if pagination.NextCursor == "" {
+			return items, nil
+		}
+		query.Set("cursor", pagination.NextCursor)
+	}
+}
+
+// Switches
+
+func (c *Client) ListSwitches(ctx context.Context) ([]*LogicalSwitch, error) {
+	return listAll[*LogicalSwitch](ctx, c, "/api/v1/switches", nil, "switches")
+}
+
+func (c *Client) GetSwitch(ctx context.Context, id string) (*LogicalSwitch, error) {
+	var ls LogicalSwitch
+	if err := c.do(ctx, "GET", "/api/v1/switches/"+url.PathEscape(id), nil, nil, &ls); err != nil {
+		return nil, err
+	}
+	return &ls, nil
+}
+
+func (c *Client) CreateSwitch(ctx context.Context, ls *LogicalSwitch) (*LogicalSwitch, error) {
+	var created LogicalSwitch
+	if err := c.do(ctx, "POST", "/api/v1/switches", nil, ls, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateSwitch(ctx context.Context, id string, ls *LogicalSwitch) (*LogicalSwitch, error) {
+	var updated LogicalSwitch
+	if err := c.do(ctx, "PUT", "/api/v1/switches/"+url.PathEscape(id), nil, ls, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteSwitch(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/switches/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Routers
+
+func (c *Client) ListRouters(ctx context.Context) ([]*LogicalRouter, error) {
+	return listAll[*LogicalRouter](ctx, c, "/api/v1/routers", nil, "routers")
+}
+
+func (c *Client) GetRouter(ctx context.Context, id string) (*LogicalRouter, error) {
+	var lr LogicalRouter
+	if err := c.do(ctx, "GET", "/api/v1/routers/"+url.PathEscape(id), nil, nil, &lr); err != nil {
+		return nil, err
+	}
+	return &lr, nil
+}
+
+func (c *Client) CreateRouter(ctx context.Context, lr *LogicalRouter) (*LogicalRouter, error) {
+	var created LogicalRouter
+	if err := c.do(ctx, "POST", "/api/v1/routers", nil, lr, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateRouter(ctx context.Context, id string, lr *LogicalRouter) (*LogicalRouter, error) {
+	var updated LogicalRouter
+	if err := c.do(ctx, "PUT", "/api/v1/routers/"+url.PathEscape(id), nil, lr, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteRouter(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/routers/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Ports
+
+func (c *Client) ListPorts(ctx context.Context, switchID string) ([]*LogicalSwitchPort, error) {
+	return listAll[*LogicalSwitchPort](ctx, c, "/api/v1/switches/"+url.PathEscape(switchID)+"/ports", nil, "ports")
+}
+
+func (c *Client) GetPort(ctx context.Context, id string) (*LogicalSwitchPort, error) {
+	var port LogicalSwitchPort
+	if err := c.do(ctx, "GET", "/api/v1/ports/"+url.PathEscape(id), nil, nil, &port); err != nil {
+		return nil, err
+	}
+	return &port, nil
+}
+
+func (c *Client) CreatePort(ctx context.Context, switchID string, port *LogicalSwitchPort) (*LogicalSwitchPort, error) {
+	var created LogicalSwitchPort
+	if err := c.do(ctx, "POST", "/api/v1/switches/"+url.PathEscape(switchID)+"/ports", nil, port, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdatePort(ctx context.Context, id string, port *LogicalSwitchPort) (*LogicalSwitchPort, error) {
+	var updated LogicalSwitchPort
+	if err := c.do(ctx, "PUT", "/api/v1/ports/"+url.PathEscape(id), nil, port, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeletePort(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/ports/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ACLs
+
+func (c *Client) ListACLs(ctx context.Context, switchID string) ([]*ACL, error) {
+	query := url.Values{}
+	query.Set("switch_id", switchID)
+	return listAll[*ACL](ctx, c, "/api/v1/acls", query, "acls")
+}
+
+func (c *Client) GetACL(ctx context.Context, id string) (*ACL, error) {
+	var acl ACL
+	if err := c.do(ctx, "GET", "/api/v1/acls/"+url.PathEscape(id), nil, nil, &acl); err != nil {
+		return nil, err
+	}
+	return &acl, nil
+}
+
+func (c *Client) CreateACL(ctx context.Context, switchID string, acl *ACL) (*ACL, error) {
+	query := url.Values{}
+	query.Set("switch_id", switchID)
+
+	var created ACL
+	if err := c.do(ctx, "POST", "/api/v1/acls", query, acl, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateACL(ctx context.Context, id string, acl *ACL) (*ACL, error) {
+	return c.updateACL(ctx, id, acl, nil)
+}
+
+// ForceUpdateACL updates an ACL even when it's owned by a template or an
+// operator, which may overwrite the change
+func (c *Client) ForceUpdateACL(ctx context.Context, id string, acl *ACL) (*ACL, error) {
+	return c.updateACL(ctx, id, acl, url.Values{"force": {"true"}})
+}
+
+func (c *Client) updateACL(ctx context.Context, id string, acl *ACL, query url.Values) (*ACL, error) {
+	var updated ACL
+	if err := c.do(ctx, "PUT", "/api/v1/acls/"+url.PathEscape(id), query, acl, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteACL(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/acls/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ForceDeleteACL deletes an ACL even when it's owned by a template or an
+// operator, which may create it again
+func (c *Client) ForceDeleteACL(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/acls/"+url.PathEscape(id), url.Values{"force": {"true"}}, nil, nil)
+}
+
+// Load Balancers
+
+func (c *Client) ListLoadBalancers(ctx context.Context) ([]*LoadBalancer, error) {
+	return listAll[*LoadBalancer](ctx, c, "/api/v1/load-balancers", nil, "load_balancers")
+}
+
+func (c *Client) GetLoadBalancer(ctx context.Context, id string) (*LoadBalancer, error) {
+	var lb LoadBalancer
+	if err := c.do(ctx, "GET", "/api/v1/load-balancers/"+url.PathEscape(id), nil, nil, &lb); err != nil {
+		return nil, err
+	}
+	return &lb, nil
+}
+
+func (c *Client) CreateLoadBalancer(ctx context.Context, lb *LoadBalancer) (*LoadBalancer, error) {
+	var created LoadBalancer
+	if err := c.do(ctx, "POST", "/api/v1/load-balancers", nil, lb, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateLoadBalancer(ctx context.Context, id string, lb *LoadBalancer) (*LoadBalancer, error) {
+	var updated LoadBalancer
+	if err := c.do(ctx, "PUT", "/api/v1/load-balancers/"+url.PathEscape(id), nil, lb, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteLoadBalancer(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/load-balancers/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Topology
+
+func (c *Client) GetTopology(ctx context.Context) (*Topology, error) {
+	var topology Topology
+	if err := c.do(ctx, "GET", "/api/v1/topology", nil, nil, &topology); err != nil {
+		return nil, err
+	}
+	return &topology, nil
+}
+
+// ExportTopology renders the topology in the given format (json, dot,
+// cytoscape, d3, mermaid)
+func (c *Client) ExportTopology(ctx context.Context, format string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+	return c.doRaw(ctx, "GET", "/api/v1/visualization/topology/export", query, nil)
+}
+
+// ExportResources returns the ID-annotated export of all resources in the
+// given format (json, yaml, hcl)
+func (c *Client) ExportResources(ctx context.Context, format string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+	return c.doRaw(ctx, "GET", "/api/v1/export", query, nil)
+}
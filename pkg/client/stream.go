@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+)
+
+// NDJSONContentType is the content type of lists streamed one JSON object
+// per line
+const NDJSONContentType = "application/x-ndjson"
+
+// streamAll calls fn with each item of a list, which the server streams as
+// NDJSON, so that only one item is held at a time. Servers that don't
+// stream lists are read a page at a time with listAll. member names the
+// list in their response body. An error returned by fn stops the stream.
+func streamAll[T any](ctx context.Context, c *Client, path string, query url.Values, member string, fn func(T) error) error {
+	req, err := c.newRequest(ctx, "GET", path, query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", NDJSONContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return newAPIError(resp.StatusCode, data)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != NDJSONContentType {
+		resp.Body.Close()
+		items, err := listAll[T](ctx, c, path, query, member)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var line json.RawMessage
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// The server ends a stream it can't complete with the problem
+		var failure map[string]json.RawMessage
+		if json.Unmarshal(line, &failure) == nil && len(failure) == 1 && failure["error"] != nil {
+			var problem struct {
+				Status int `json:"status"`
+			}
+			if err := json.Unmarshal(failure["error"], &problem); err == nil && problem.Status != 0 {
+				return newAPIError(problem.Status, failure["error"])
+			}
+		}
+
+		var item T
+		if err := json.Unmarshal(line, &item); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamSwitches calls fn with each logical switch in turn
+func (c *Client) StreamSwitches(ctx context.Context, fn func(*LogicalSwitch) error) error {
+	return streamAll(ctx, c, "/api/v1/switches", nil, "switches", fn)
+}
+
+// StreamRouters calls fn with each logical router in turn
+func (c *Client) StreamRouters(ctx context.Context, fn func(*LogicalRouter) error) error {
+	return streamAll(ctx, c, "/api/v1/routers", nil, "routers", fn)
+}
+
+// StreamPorts calls fn with each port of a logical switch in turn
+func (c *Client) StreamPorts(ctx context.Context, switchID string, fn func(*LogicalSwitchPort) error) error {
+	return streamAll(ctx, c, "/api/v1/switches/"+url.PathEscape(switchID)+"/ports", nil, "ports", fn)
+}
+
+// StreamACLs calls fn with each ACL of a logical switch in turn
+func (c *Client) StreamACLs(ctx context.Context, switchID string, fn func(*ACL) error) error {
+	query := url.Values{}
+	query.Set("switch_id", switchID)
+	return streamAll(ctx, c, "/api/v1/acls", query, "acls", fn)
+}
+
+// StreamLoadBalancers calls fn with each load balancer in turn
+func (c *Client) StreamLoadBalancers(ctx context.Context, fn func(*LoadBalancer) error) error {
+	return streamAll(ctx, c, "/api/v1/load-balancers", nil, "load_balancers", fn)
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lspecian/ovncp/pkg/ovn"
+)
+
+// Flow trace types
+type (
+	FlowTraceRequest = ovn.FlowTraceRequest
+	FlowTraceResult  = ovn.FlowTraceResult
+)
+
+// TraceFlow traces a packet through the logical network with ovn-trace
+func (c *Client) TraceFlow(ctx context.Context, req *FlowTraceRequest) (*FlowTraceResult, error) {
+	var result FlowTraceResult
+	if err := c.do(ctx, "POST", "/api/v1/trace/flow", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SimulateFlowTrace runs a simulated trace that does not need ovn-trace on
+// the server
+func (c *Client) SimulateFlowTrace(ctx context.Context, req *FlowTraceRequest) (*FlowTraceResult, error) {
+	var result FlowTraceResult
+	if err := c.do(ctx, "POST", "/api/v1/trace/simulate", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReachabilityProbeRequest is a flow to trace and to probe from a chassis,
+// by default the one the source port is bound to
+type ReachabilityProbeRequest struct {
+	FlowTraceRequest
+	Chassis   string `json:"chassis,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Count     int    `json:"count,omitempty"`
+}
+
+// ProbeResult is the loss and round-trip times of the probes
+type ProbeResult struct {
+	Sent        int     `json:"sent" yaml:"sent"`
+	Received    int     `json:"received" yaml:"received"`
+	LossPercent float64 `json:"loss_percent" yaml:"loss_percent"`
+	RTTMinMS    float64 `json:"rtt_min_ms,omitempty" yaml:"rtt_min_ms,omitempty"`
+	RTTAvgMS    float64 `json:"rtt_avg_ms,omitempty" yaml:"rtt_avg_ms,omitempty"`
+	RTTMaxMS    float64 `json:"rtt_max_ms,omitempty" yaml:"rtt_max_ms,omitempty"`
+}
+
+// ReachabilityProbeResult is the logical trace and the probe of a flow.
+// The verdict is reachable, degraded, dataplane (allowed but unanswered),
+// blocked (dropped by policy), inconsistent (dropped but answered) or
+// unknown when the trace failed.
+type ReachabilityProbeResult struct {
+	Chassis    string           `json:"chassis" yaml:"chassis"`
+	Trace      *FlowTraceResult `json:"trace,omitempty" yaml:"trace,omitempty"`
+	TraceError string           `json:"trace_error,omitempty" yaml:"trace_error,omitempty"`
+	Probe      *ProbeResult     `json:"probe" yaml:"probe"`
+	Verdict    string           `json:"verdict" yaml:"verdict"`
+	Summary    string           `json:"summary" yaml:"summary"`
+}
+
+// ProbeReachability traces a flow and probes it through the dataplane, to
+// tell policy issues from dataplane ones
+func (c *Client) ProbeReachability(ctx context.Context, req *ReachabilityProbeRequest) (*ReachabilityProbeResult, error) {
+	var result ReachabilityProbeResult
+	if err := c.do(ctx, "POST", "/api/v1/trace/probe", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
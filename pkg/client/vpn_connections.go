@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// VPNTunnelStatus is the state of a VPN connection's tunnel when it was
+// last collected: up, connecting, down or unknown
+type VPNTunnelStatus struct {
+	State         string     `json:"state" yaml:"state"`
+	EstablishedAt *time.Time `json:"established_at,omitempty" yaml:"established_at,omitempty"`
+	BytesIn       uint64     `json:"bytes_in,omitempty" yaml:"bytes_in,omitempty"`
+	BytesOut      uint64     `json:"bytes_out,omitempty" yaml:"bytes_out,omitempty"`
+	Detail        string     `json:"detail,omitempty" yaml:"detail,omitempty"`
+	CheckedAt     time.Time  `json:"checked_at" yaml:"checked_at"`
+}
+
+// VPNConnection is an IPsec tunnel from a tenant router to an on-premises
+// peer
+type VPNConnection struct {
+	ID            string           `json:"id" yaml:"id"`
+	TenantID      string           `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Name          string           `json:"name" yaml:"name"`
+	Description   string           `json:"description,omitempty" yaml:"description,omitempty"`
+	RouterID      string           `json:"router_id" yaml:"router_id"`
+	GatewayIP     string           `json:"gateway_ip" yaml:"gateway_ip"`
+	PeerAddress   string           `json:"peer_address" yaml:"peer_address"`
+	PSKRef        string           `json:"psk_ref" yaml:"psk_ref"`
+	LocalSubnets  []string         `json:"local_subnets" yaml:"local_subnets"`
+	RemoteSubnets []string         `json:"remote_subnets" yaml:"remote_subnets"`
+	Status        *VPNTunnelStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	CreatedBy     string           `json:"created_by,omitempty" yaml:"created_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at" yaml:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" yaml:"updated_at"`
+}
+
+// CreateVPNConnectionRequest defines a VPN connection. PSKRef references
+// the pre-shared key, e.g. vault:secret/data/vpn#dc1, rather than
+// containing it.
+type CreateVPNConnectionRequest struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	RouterID      string   `json:"router_id"`
+	GatewayIP     string   `json:"gateway_ip"`
+	PeerAddress   string   `json:"peer_address"`
+	PSKRef        string   `json:"psk_ref"`
+	LocalSubnets  []string `json:"local_subnets"`
+	RemoteSubnets []string `json:"remote_subnets"`
+}
+
+// UpdateVPNConnectionRequest changes a VPN connection; nil fields are kept
+type UpdateVPNConnectionRequest struct {
+	Description   *string  `json:"description,omitempty"`
+	GatewayIP     *string  `json:"gateway_ip,omitempty"`
+	PeerAddress   *string  `json:"peer_address,omitempty"`
+	PSKRef        *string  `json:"psk_ref,omitempty"`
+	LocalSubnets  []string `json:"local_subnets,omitempty"`
+	RemoteSubnets []string `json:"remote_subnets,omitempty"`
+}
+
+// ListVPNConnections lists the VPN connections of the tenant, those of a
+// router or whose tunnel is in a state when they're set
+func (c *Client) ListVPNConnections(ctx context.Context, routerID, state string) ([]*VPNConnection, error) {
+	query := url.Values{}
+	if routerID != "" {
+		query.Set("router_id", routerID)
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	return listAll[*VPNConnection](ctx, c, "/api/v1/vpn-connections", query, "vpn_connections")
+}
+
+func (c *Client) GetVPNConnection(ctx context.Context, id string) (*VPNConnection, error) {
+	var conn VPNConnection
+	if err := c.do(ctx, "GET", "/api/v1/vpn-connections/"+url.PathEscape(id), nil, nil, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+func (c *Client) CreateVPNConnection(ctx context.Context, req *CreateVPNConnectionRequest) (*VPNConnection, error) {
+	var conn VPNConnection
+	if err := c.do(ctx, "POST", "/api/v1/vpn-connections", nil, req, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+func (c *Client) UpdateVPNConnection(ctx context.Context, id string, req *UpdateVPNConnectionRequest) (*VPNConnection, error) {
+	var conn VPNConnection
+	if err := c.do(ctx, "PUT", "/api/v1/vpn-connections/"+url.PathEscape(id), nil, req, &conn); err != nil {
+		return nil, err
+	}
+	return &conn, nil
+}
+
+func (c *Client) DeleteVPNConnection(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/api/v1/vpn-connections/"+url.PathEscape(id), nil, nil, nil)
+}
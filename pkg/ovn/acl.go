@@ -11,43 +11,83 @@ import (
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
 
+// aclSortFields are the fields ACLs can be sorted by
+var aclSortFields = []string{"priority", "name", "direction", "action", "created_at", "updated_at"}
+
 // ListACLs returns all ACLs for a given switch
 func (c *Client) ListACLs(ctx context.Context, switchID string) ([]*models.ACL, error) {
+	acls, _, err := c.ListACLsPage(ctx, switchID, nil)
+	return acls, err
+}
+
+// ListACLsPage returns the ACLs of a switch matching opts, sorted and paged,
+// along with the number of matches before paging. By default ACLs are in
+// evaluation order, highest priority first.
+func (c *Client) ListACLsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.ACL, int, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, 0, fmt.Errorf("client not connected")
+	}
+
+	field, desc, err := sortField(opts, aclSortFields, "priority")
+	if err != nil {
+		return nil, 0, err
+	}
+	if opts == nil || opts.SortBy == "" {
+		desc = true
 	}
 
 	// First get the switch to ensure it exists
 	sw := &nbdb.LogicalSwitch{UUID: switchID}
-	err := c.nbClient.Get(ctx, sw)
+	err = c.nbClient.Get(ctx, sw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get logical switch %s: %w", switchID, err)
+		return nil, 0, fmt.Errorf("failed to get logical switch %s: %w", switchID, err)
 	}
 
-	// Get all ACLs for this switch
+	switchACLs := make(map[string]bool, len(sw.ACLs))
+	for _, aclUUID := range sw.ACLs {
+		switchACLs[aclUUID] = true
+	}
+
+	// Get the matching ACLs of this switch
 	aclList := []nbdb.ACL{}
 	err = c.nbClient.WhereCache(func(acl *nbdb.ACL) bool {
-		// Check if this ACL belongs to the switch
-		for _, aclUUID := range sw.ACLs {
-			if acl.UUID == aclUUID {
-				return true
-			}
+		name := ""
+		if acl.Name != nil {
+			name = *acl.Name
 		}
-		return false
+		return switchACLs[acl.UUID] && opts.Matches(name, acl.ExternalIDs)
 	}).List(ctx, &aclList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list ACLs: %w", err)
+		return nil, 0, fmt.Errorf("failed to list ACLs: %w", err)
 	}
 
-	acls := make([]*models.ACL, len(aclList))
-	for i, acl := range aclList {
-		acls[i] = c.nbdbACLToModel(&acl)
+	sortRows(aclList, func(i int) sortValue {
+		switch field {
+		case "name":
+			if aclList[i].Name != nil {
+				return sortValue{s: *aclList[i].Name}
+			}
+			return sortValue{}
+		case "direction":
+			return sortValue{s: aclList[i].Direction}
+		case "action":
+			return sortValue{s: aclList[i].Action}
+		case "created_at", "updated_at":
+			return timeSortValue(aclList[i].ExternalIDs, field)
+		}
+		return sortValue{n: aclList[i].Priority}
+	}, func(i int) string { return aclList[i].UUID }, desc)
+
+	start, end := opts.Bounds(len(aclList))
+	acls := make([]*models.ACL, 0, end-start)
+	for i := start; i < end; i++ {
+		acls = append(acls, c.nbdbACLToModel(&aclList[i]))
 	}
 
-	return acls, nil
+	return acls, len(aclList), nil
 }
 
 // GetACL returns a specific ACL by ID
@@ -92,35 +132,8 @@ func (c *Client) CreateACL(ctx context.Context, switchID string, acl *models.ACL
 	// Create the ACL
 	aclUUID := uuid.New().String()
 	now := time.Now().Format(time.RFC3339)
-	
-	nbdbACL := &nbdb.ACL{
-		UUID:      aclUUID,
-		Action:    nbdb.ACLAction(acl.Action),
-		Direction: nbdb.ACLDirection(acl.Direction),
-		Match:     acl.Match,
-		Priority:  acl.Priority,
-		Log:       acl.Log,
-		ExternalIDs: map[string]string{
-			"created_at": now,
-			"updated_at": now,
-		},
-	}
-
-	// Set optional fields
-	if acl.Name != "" {
-		nbdbACL.Name = &acl.Name
-	}
-	if acl.Severity != "" {
-		severity := nbdb.ACLSeverity(acl.Severity)
-		nbdbACL.Severity = &severity
-	}
-
-	// Copy additional external IDs
-	for k, v := range acl.ExternalIDs {
-		if k != "created_at" && k != "updated_at" {
-			nbdbACL.ExternalIDs[k] = v
-		}
-	}
+	nbdbACL := newNBDBACL(aclUUID, acl, now)
+	stampCreated(ctx, nbdbACL.ExternalIDs)
 
 	// Start transaction
 	ops := []ovsdb.Operation{}
@@ -141,7 +154,7 @@ func (c *Client) CreateACL(ctx context.Context, switchID string, acl *models.ACL
 	ops = append(ops, updateOp...)
 
 	// Execute transaction
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, ops...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute transaction: %w", err)
 	}
@@ -154,12 +167,15 @@ func (c *Client) CreateACL(ctx context.Context, switchID string, acl *models.ACL
 	acl.UUID = aclUUID
 	acl.CreatedAt = parseTime(now)
 	acl.UpdatedAt = parseTime(now)
+	acl.Owner = models.ACLOwnerFromExternalIDs(nbdbACL.ExternalIDs)
+	acl.Ownership = models.OwnershipFromExternalIDs(nbdbACL.ExternalIDs)
 
 	return acl, nil
 }
 
-// UpdateACL updates an existing ACL
-func (c *Client) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+// CreateACLs creates acls on a switch in a single transaction, so either all
+// of them are created or none is
+func (c *Client) CreateACLs(ctx context.Context, switchID string, acls []*models.ACL) ([]*models.ACL, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -167,13 +183,78 @@ func (c *Client) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*mo
 		return nil, fmt.Errorf("client not connected")
 	}
 
-	// Get existing ACL
-	existing := &nbdb.ACL{UUID: id}
-	err := c.nbClient.Get(ctx, existing)
+	if len(acls) == 0 {
+		return nil, fmt.Errorf("no ACLs provided")
+	}
+
+	// First get the switch to ensure it exists
+	sw := &nbdb.LogicalSwitch{UUID: switchID}
+	err := c.nbClient.Get(ctx, sw)
 	if err != nil {
-		return nil, fmt.Errorf("ACL %s not found", id)
+		return nil, fmt.Errorf("failed to get logical switch %s: %w", switchID, err)
+	}
+
+	for i, acl := range acls {
+		if err := validateACL(acl); err != nil {
+			return nil, fmt.Errorf("ACL %d: %w", i, err)
+		}
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	ops := []ovsdb.Operation{}
+	aclUUIDs := make([]string, len(acls))
+	rows := make([]*nbdb.ACL, len(acls))
+
+	// One insert per ACL, so that operation i creates acls[i]
+	for i, acl := range acls {
+		aclUUIDs[i] = uuid.New().String()
+		rows[i] = newNBDBACL(aclUUIDs[i], acl, now)
+		stampCreated(ctx, rows[i].ExternalIDs)
+		createOp, err := c.nbClient.Create(rows[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ACL operation: %w", err)
+		}
+		ops = append(ops, createOp...)
+	}
+
+	// Update the switch to include all the new ACLs
+	sw.ACLs = append(sw.ACLs, aclUUIDs...)
+	updateOp, err := c.nbClient.Where(sw).Update(sw, &sw.ACLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create switch update operation: %w", err)
+	}
+	ops = append(ops, updateOp...)
+
+	result, err := c.transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute transaction: %w", err)
 	}
 
+	for i, r := range result {
+		if r.Error == "" {
+			continue
+		}
+		if i < len(acls) {
+			return nil, fmt.Errorf("transaction failed on ACL %d: %s", i, r.Error)
+		}
+		return nil, fmt.Errorf("transaction failed: %s", r.Error)
+	}
+
+	created := make([]*models.ACL, len(acls))
+	for i, acl := range acls {
+		acl.UUID = aclUUIDs[i]
+		acl.CreatedAt = parseTime(now)
+		acl.UpdatedAt = parseTime(now)
+		acl.Owner = models.ACLOwnerFromExternalIDs(rows[i].ExternalIDs)
+		acl.Ownership = models.OwnershipFromExternalIDs(rows[i].ExternalIDs)
+		created[i] = acl
+	}
+
+	return created, nil
+}
+
+// applyACLUpdates copies the fields set in acl to existing
+func applyACLUpdates(existing *nbdb.ACL, acl *models.ACL) {
 	// Update fields if provided
 	if acl.Action != "" {
 		existing.Action = nbdb.ACLAction(acl.Action)
@@ -209,6 +290,73 @@ func (c *Client) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*mo
 			existing.ExternalIDs[k] = v
 		}
 	}
+	if acl.Owner != "" {
+		existing.ExternalIDs[models.ACLOwnerKey] = acl.Owner
+	}
+}
+
+// newNBDBACL builds the row for a new ACL created at now
+func newNBDBACL(aclUUID string, acl *models.ACL, now string) *nbdb.ACL {
+	nbdbACL := &nbdb.ACL{
+		UUID:      aclUUID,
+		Action:    nbdb.ACLAction(acl.Action),
+		Direction: nbdb.ACLDirection(acl.Direction),
+		Match:     acl.Match,
+		Priority:  acl.Priority,
+		Log:       acl.Log,
+		ExternalIDs: map[string]string{
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+
+	// Set optional fields
+	if acl.Name != "" {
+		name := acl.Name
+		nbdbACL.Name = &name
+	}
+	if acl.Severity != "" {
+		severity := nbdb.ACLSeverity(acl.Severity)
+		nbdbACL.Severity = &severity
+	}
+
+	// Copy additional external IDs
+	for k, v := range acl.ExternalIDs {
+		if k != "created_at" && k != "updated_at" {
+			nbdbACL.ExternalIDs[k] = v
+		}
+	}
+	if acl.Owner != "" {
+		nbdbACL.ExternalIDs[models.ACLOwnerKey] = acl.Owner
+	}
+
+	return nbdbACL
+}
+
+// UpdateACL updates an existing ACL
+func (c *Client) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*models.ACL, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	// Get existing ACL
+	existing := &nbdb.ACL{UUID: id}
+	err := c.nbClient.Get(ctx, existing)
+	if err != nil {
+		return nil, fmt.Errorf("ACL %s not found", id)
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardACL(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyACLUpdates(existing, acl)
+	stampUpdated(ctx, existing.ExternalIDs)
 
 	// Update the ACL
 	ops, err := c.nbClient.Where(existing).Update(existing)
@@ -216,10 +364,13 @@ func (c *Client) UpdateACL(ctx context.Context, id string, acl *models.ACL) (*mo
 		return nil, fmt.Errorf("failed to create update operation: %w", err)
 	}
 
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update ACL: %w", err)
 	}
+	if err := guardResult(result, guard); err != nil {
+		return nil, err
+	}
 
 	if len(result) > 0 && result[0].Error != "" {
 		return nil, fmt.Errorf("update failed: %s", result[0].Error)
@@ -244,6 +395,12 @@ func (c *Client) DeleteACL(ctx context.Context, id string) error {
 		return fmt.Errorf("ACL %s not found", id)
 	}
 
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardACL(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	// Find the switch that contains this ACL
 	switches := []nbdb.LogicalSwitch{}
 	err = c.nbClient.WhereCache(func(sw *nbdb.LogicalSwitch) bool {
@@ -290,10 +447,13 @@ func (c *Client) DeleteACL(ctx context.Context, id string) error {
 	ops = append(ops, deleteOp...)
 
 	// Execute transaction
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete ACL: %w", err)
 	}
+	if err := guardResult(result, guard); err != nil {
+		return err
+	}
 
 	if len(result) > 0 && result[0].Error != "" {
 		return fmt.Errorf("delete failed: %s", result[0].Error)
@@ -331,6 +491,8 @@ func (c *Client) nbdbACLToModel(acl *nbdb.ACL) *models.ACL {
 	if updated, ok := acl.ExternalIDs["updated_at"]; ok {
 		m.UpdatedAt = parseTime(updated)
 	}
+	m.Owner = models.ACLOwnerFromExternalIDs(acl.ExternalIDs)
+	m.Ownership = models.OwnershipFromExternalIDs(acl.ExternalIDs)
 
 	return m
 }
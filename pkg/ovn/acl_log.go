@@ -0,0 +1,249 @@
+package ovn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// unnamedACL is the name ovn-controller logs ACLs without a name under
+const unnamedACL = "<unnamed>"
+
+// ParseACLLogLine parses an ACL log line ovn-controller wrote, either to its
+// log file or through syslog, e.g.
+//
+//	2024-01-02T15:04:05.123Z|00005|acl_log(ovn_pinctrl0)|INFO|name="allow-ssh", verdict=allow, severity=info, direction=to-lport: tcp,vlan_tci=0x0000,dl_src=...,nw_src=10.0.0.1,nw_dst=10.0.0.2,...,tp_src=43512,tp_dst=22,tcp_flags=syn
+//
+// It returns false for lines that aren't ACL logs. The time is zero if the
+// line has none.
+func ParseACLLogLine(line string) (*models.ACLLogEntry, bool) {
+	start := strings.Index(line, "acl_log")
+	if start < 0 {
+		return nil, false
+	}
+	rest := line[start:]
+	i := strings.Index(rest, "name=")
+	if i < 0 {
+		i = strings.Index(rest, "verdict=")
+	}
+	if i < 0 {
+		return nil, false
+	}
+	rest = rest[i:]
+
+	entry := &models.ACLLogEntry{}
+	// The name is quoted and may hold any character, including ", " and ": "
+	if strings.HasPrefix(rest, "name=") {
+		quoted, tail, ok := cutQuoted(rest[len("name="):])
+		if !ok {
+			return nil, false
+		}
+		name, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, false
+		}
+		if name != unnamedACL {
+			entry.ACLName = name
+		}
+		rest = strings.TrimPrefix(tail, ", ")
+	}
+
+	header, flow, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return nil, false
+	}
+	for _, field := range strings.Split(header, ", ") {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "verdict":
+			entry.Verdict = value
+		case "severity":
+			entry.Severity = value
+		case "direction":
+			entry.Direction = value
+		}
+	}
+	if entry.Verdict == "" {
+		return nil, false
+	}
+
+	for _, field := range strings.Split(strings.TrimSpace(flow), ",") {
+		key, value, hasValue := strings.Cut(field, "=")
+		if !hasValue {
+			// The protocol is the only field without a value
+			if entry.Protocol == "" {
+				entry.Protocol = key
+			}
+			continue
+		}
+		switch key {
+		case "nw_src", "ipv6_src":
+			entry.SrcIP = value
+		case "nw_dst", "ipv6_dst":
+			entry.DstIP = value
+		case "tp_src":
+			entry.SrcPort, _ = strconv.Atoi(value)
+		case "tp_dst":
+			entry.DstPort, _ = strconv.Atoi(value)
+		}
+	}
+
+	entry.LoggedAt = aclLogTime(line[:start])
+	return entry, true
+}
+
+// cutQuoted cuts the double-quoted string s starts with from the rest of s
+func cutQuoted(s string) (quoted, rest string, ok bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1], s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// aclLogTime returns the time in the prefix of an ACL log line: the first
+// field of an ovn-controller log line, or the timestamp of an RFC 5424
+// syslog message
+func aclLogTime(prefix string) time.Time {
+	if first, _, ok := strings.Cut(prefix, "|"); ok {
+		if t, err := time.Parse(time.RFC3339Nano, first); err == nil {
+			return t
+		}
+	}
+	for _, field := range strings.Fields(prefix) {
+		if t, err := time.Parse(time.RFC3339Nano, field); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// ACLLogFile follows an ovn-controller log file, such as
+// /var/log/ovn/ovn-controller.log, from its end. A file rotated or
+// truncated is reopened and read from the start.
+type ACLLogFile struct {
+	path         string
+	pollInterval time.Duration
+}
+
+// NewACLLogFile creates a source following path, checking it for new lines
+// each pollInterval
+func NewACLLogFile(path string, pollInterval time.Duration) *ACLLogFile {
+	return &ACLLogFile{path: path, pollInterval: pollInterval}
+}
+
+// Follow sends the lines appended to the file to lines until ctx is done
+func (f *ACLLogFile) Follow(ctx context.Context, lines chan<- string) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to open ACL log file: %w", err)
+	}
+	defer func() { file.Close() }()
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek ACL log file: %w", err)
+	}
+
+	reader := bufio.NewReader(file)
+	var partial strings.Builder
+	for {
+		chunk, err := reader.ReadString('\n')
+		offset += int64(len(chunk))
+		partial.WriteString(chunk)
+		if err == nil {
+			select {
+			case lines <- strings.TrimRight(partial.String(), "\r\n"):
+			case <-ctx.Done():
+				return nil
+			}
+			partial.Reset()
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read ACL log file: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(f.pollInterval):
+		}
+
+		// Reopen the file if it was rotated away or truncated
+		current, statErr := os.Stat(f.path)
+		opened, openedErr := file.Stat()
+		if statErr != nil || openedErr != nil || (os.SameFile(current, opened) && current.Size() >= offset) {
+			continue
+		}
+		reopened, err := os.Open(f.path)
+		if err != nil {
+			continue
+		}
+		file.Close()
+		file, offset = reopened, 0
+		reader.Reset(file)
+		partial.Reset()
+	}
+}
+
+// ACLLogSyslog receives the ACL logs of ovn-controllers configured with a
+// syslog target, e.g. --syslog-target=ovncp.example.com:5514, as UDP
+// syslog messages
+type ACLLogSyslog struct {
+	addr string
+}
+
+// NewACLLogSyslog creates a source listening on the UDP address addr
+func NewACLLogSyslog(addr string) *ACLLogSyslog {
+	return &ACLLogSyslog{addr: addr}
+}
+
+// Follow sends the messages received to lines until ctx is done
+func (s *ACLLogSyslog) Follow(ctx context.Context, lines chan<- string) error {
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for syslog messages: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to receive syslog message: %w", err)
+		}
+		// A message may hold several lines
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package ovn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// sbLogicalFlow is the part of a southbound Logical_Flow row ACL counters
+// are attributed with
+type sbLogicalFlow struct {
+	UUID        string            `ovsdb:"_uuid"`
+	ExternalIDs map[string]string `ovsdb:"external_ids"`
+}
+
+// stageHintKey is the external ID of a logical flow holding the first 32
+// bits of the northbound row it was generated from, e.g. an ACL
+const stageHintKey = "stage-hint"
+
+// FlowCounter are the counters of an OpenFlow flow. ovn-controller sets the
+// cookie of the flows it installs to the first 32 bits of their logical
+// flow's UUID.
+type FlowCounter struct {
+	Cookie  uint32
+	Packets uint64
+	Bytes   uint64
+}
+
+// ACLCounter are the counters of the flows generated from an ACL
+type ACLCounter struct {
+	Packets uint64
+	Bytes   uint64
+}
+
+// FlowDumper returns OpenFlow flows in ovs-ofctl dump-flows format, e.g.
+// of the integration bridge of every chassis
+type FlowDumper func(ctx context.Context) (string, error)
+
+// CommandFlowDumper runs a command printing flows in ovs-ofctl dump-flows
+// format, e.g. "ovs-ofctl dump-flows br-int" or a script collecting the
+// flows of every chassis
+func CommandFlowDumper(command []string) FlowDumper {
+	return func(ctx context.Context) (string, error) {
+		if len(command) == 0 {
+			return "", errors.New("no flow dump command")
+		}
+		out, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to dump flows: %w", err)
+		}
+		return string(out), nil
+	}
+}
+
+// ParseFlowDump parses the counters of flows in ovs-ofctl dump-flows
+// format. Flows without a cookie aren't OVN's and are skipped.
+func ParseFlowDump(output string) []FlowCounter {
+	var counters []FlowCounter
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var counter FlowCounter
+		var hasCookie bool
+		for _, field := range strings.Split(scanner.Text(), ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "cookie":
+				// Cookies are 64 bits; OVN only sets the low 32
+				cookie, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+				if err == nil && cookie != 0 {
+					counter.Cookie, hasCookie = uint32(cookie), true
+				}
+			case "n_packets":
+				counter.Packets, _ = strconv.ParseUint(value, 10, 64)
+			case "n_bytes":
+				counter.Bytes, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+		if hasCookie {
+			counters = append(counters, counter)
+		}
+	}
+	return counters
+}
+
+// ACLStatsCollector attributes OpenFlow flow counters to the ACLs their
+// logical flows were generated from, read from the southbound database
+type ACLStatsCollector struct {
+	cfg  *config.OVNConfig
+	dump FlowDumper
+
+	mu sync.Mutex
+	sb client.Client
+}
+
+// NewACLStatsCollector creates a collector reading logical flows from the
+// southbound database of cfg and flows from dump
+func NewACLStatsCollector(cfg *config.OVNConfig, dump FlowDumper) *ACLStatsCollector {
+	return &ACLStatsCollector{cfg: cfg, dump: dump}
+}
+
+// ACLCounters returns the counters of each ACL, keyed by the first 8 hex
+// digits of its UUID, summed over the flows generated from it
+func (c *ACLStatsCollector) ACLCounters(ctx context.Context) (map[string]ACLCounter, error) {
+	sb, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var flows []sbLogicalFlow
+	if err := sb.List(ctx, &flows); err != nil {
+		return nil, fmt.Errorf("failed to list logical flows: %w", err)
+	}
+
+	output, err := c.dump(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return aclCounters(flows, ParseFlowDump(output)), nil
+}
+
+// Close closes the southbound connection
+func (c *ACLStatsCollector) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sb != nil {
+		c.sb.Close()
+		c.sb = nil
+	}
+}
+
+// client returns the southbound connection, connecting and monitoring the
+// logical flows' external IDs if it isn't connected
+func (c *ACLStatsCollector) client(ctx context.Context) (client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sb != nil && c.sb.Connected() {
+		return c.sb, nil
+	} else if c.sb != nil {
+		c.sb.Close()
+		c.sb = nil
+	}
+
+	dbModel, _ := model.NewClientDBModel("OVN_Southbound", map[string]model.Model{
+		"Logical_Flow": &sbLogicalFlow{},
+	})
+	opts := []client.Option{client.WithEndpoint(c.cfg.SouthboundDB), client.WithLogger(newOVSDBLogger(c.cfg.SouthboundDB))}
+	if c.cfg.TLS.Enabled {
+		reloader, err := newTLSReloader(&c.cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLSConfig(reloader.TLSConfig()))
+	}
+	sb, err := client.NewOVSDBClient(dbModel, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OVSDB client: %w", err)
+	}
+
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+	if err := sb.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to OVN southbound DB: %w", err)
+	}
+	flow := &sbLogicalFlow{}
+	if _, err := sb.Monitor(ctx, sb.NewMonitor(client.WithTable(flow, &flow.ExternalIDs))); err != nil {
+		sb.Close()
+		return nil, fmt.Errorf("failed to monitor logical flows: %w", err)
+	}
+
+	c.sb = sb
+	return sb, nil
+}
+
+// aclCounters sums the counters of the flows of each logical flow with a
+// stage hint by the hint
+func aclCounters(flows []sbLogicalFlow, counters []FlowCounter) map[string]ACLCounter {
+	hints := make(map[uint32]string, len(flows))
+	for _, flow := range flows {
+		hint := flow.ExternalIDs[stageHintKey]
+		if hint == "" || len(flow.UUID) < 8 {
+			continue
+		}
+		cookie, err := strconv.ParseUint(flow.UUID[:8], 16, 32)
+		if err != nil {
+			continue
+		}
+		hints[uint32(cookie)] = hint
+	}
+
+	acls := make(map[string]ACLCounter)
+	for _, counter := range counters {
+		hint, ok := hints[counter.Cookie]
+		if !ok {
+			continue
+		}
+		acl := acls[hint]
+		acl.Packets += counter.Packets
+		acl.Bytes += counter.Bytes
+		acls[hint] = acl
+	}
+	return acls
+}
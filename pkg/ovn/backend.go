@@ -0,0 +1,153 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// The functions below expose what Client applies to every change to other
+// backends serving the same models, such as the in-memory sandbox, so that
+// they validate, stamp and guard resources the way Client does.
+
+// StampCreated records the actor of ctx as the creator and last updater in
+// the external IDs of a new resource
+func StampCreated(ctx context.Context, externalIDs map[string]string) {
+	stampCreated(ctx, externalIDs)
+}
+
+// StampUpdated records the actor of ctx as the last updater in the external
+// IDs of a changed resource
+func StampUpdated(ctx context.Context, externalIDs map[string]string) {
+	stampUpdated(ctx, externalIDs)
+}
+
+// UpdatedExternalIDs returns the external IDs of a resource updated now:
+// updates if given, otherwise the existing ones, keeping the creation
+// timestamp and creator and recording the actor of ctx as the updater
+func UpdatedExternalIDs(ctx context.Context, existing, updates map[string]string) map[string]string {
+	result := updatedExternalIDs(existing, updates, time.Now())
+	stampUpdated(ctx, result)
+	return result
+}
+
+// CheckPrecondition checks the precondition ctx carries, if any, against
+// current, the model of the resource about to be updated or deleted
+func CheckPrecondition(ctx context.Context, current interface{}) error {
+	if check := preconditionFrom(ctx); check != nil {
+		return check(current)
+	}
+	return nil
+}
+
+// CascadeRequested reports whether ctx asks for the dependents of a deleted
+// resource to be deleted with it
+func CascadeRequested(ctx context.Context) bool {
+	return cascadeFrom(ctx)
+}
+
+// ValidateACL checks the action, direction, priority, match and severity of
+// an ACL
+func ValidateACL(acl *models.ACL) error {
+	return validateACL(acl)
+}
+
+// ValidateDNSRecords checks the hostnames and addresses of DNS records
+func ValidateDNSRecords(records map[string]string) error {
+	return validateDNSRecords(records)
+}
+
+// ValidateMirror checks a mirror's name, filter, sink, type and index
+func ValidateMirror(mirror *models.Mirror) error {
+	return validateMirror(mirror)
+}
+
+// ValidateRouterPolicy checks a router policy's priority, match, action and
+// nexthops
+func ValidateRouterPolicy(policy *models.RouterPolicy) error {
+	return validateRouterPolicy(policy)
+}
+
+// ValidateSampleCollector checks a sample collector's name, ID, set ID and
+// probability
+func ValidateSampleCollector(collector *models.SampleCollector) error {
+	return validateSampleCollector(collector)
+}
+
+// ValidateStaticRoute checks a static route's prefix, nexthop, policy and
+// BFD settings
+func ValidateStaticRoute(route *models.StaticRoute) error {
+	return validateStaticRoute(route)
+}
+
+// NormalizeRoutePrefix clears the host bits of a route prefix, so that
+// "10.0.0.1/24" and "10.0.0.0/24" are the same route
+func NormalizeRoutePrefix(prefix string) string {
+	return normalizePrefix(prefix)
+}
+
+// ValidateNAT checks the type and addresses of a NAT rule
+func ValidateNAT(nat *models.NAT) error {
+	return validateNAT(nat)
+}
+
+// ValidateQoS checks a QoS rule's priority, direction, match, action and
+// bandwidth
+func ValidateQoS(qos *models.QoS) error {
+	action, err := qosAction(qos.Action)
+	if err != nil {
+		return err
+	}
+	return validateQoS(&nbdb.QoS{
+		Priority:  qos.Priority,
+		Direction: nbdb.QoSDirection(qos.Direction),
+		Match:     qos.Match,
+		Action:    action,
+		Bandwidth: qos.Bandwidth,
+	})
+}
+
+// PortIPs returns the IP addresses of a port with addresses, in normalized
+// form
+func PortIPs(addresses []string) []string {
+	return portIPs(&nbdb.LogicalSwitchPort{Addresses: addresses})
+}
+
+// NormalizeIP returns the canonical form of an IP address, with an optional
+// prefix length, or "" if addr isn't one
+func NormalizeIP(addr string) string {
+	return normalizeIP(addr)
+}
+
+// WithoutBackends removes the backends at ips from load balancer VIPs,
+// dropping VIPs left without backends, and reports whether any changed
+func WithoutBackends(vips map[string]string, ips map[string]bool) (map[string]string, bool) {
+	return withoutBackends(vips, ips)
+}
+
+// ResolveSortField resolves the sort field requested in opts against the
+// fields pages of resource, one of the models.Resource types, can be sorted
+// by, as Client's paged listings do. ACLs are listed highest priority first
+// unless asked otherwise.
+func ResolveSortField(opts *models.ListOptions, resource string) (field string, desc bool, err error) {
+	switch resource {
+	case models.ResourceSwitch:
+		return sortField(opts, logicalSwitchSortFields, "name")
+	case models.ResourceRouter:
+		return sortField(opts, logicalRouterSortFields, "name")
+	case models.ResourcePort:
+		return sortField(opts, logicalSwitchPortSortFields, "name")
+	case models.ResourceLoadBalancer:
+		return sortField(opts, loadBalancerSortFields, "name")
+	case models.ResourceACL:
+		field, desc, err = sortField(opts, aclSortFields, "priority")
+		if opts == nil || opts.SortBy == "" {
+			desc = true
+		}
+		return field, desc, err
+	}
+	return "", false, fmt.Errorf("resource %s can't be listed in pages", resource)
+}
@@ -0,0 +1,116 @@
+package ovn
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/metrics"
+)
+
+// Reads are served from the cache the client's monitors keep of the
+// northbound database. Updates to it are pushed by the server and may
+// arrive after the reply to the transaction that caused them, so
+// transactions wait for the cache to reflect them before returning.
+const (
+	// cacheSyncTimeout bounds the wait for the cache to reflect a
+	// transaction; the transaction has committed either way
+	cacheSyncTimeout = 5 * time.Second
+	// cacheSyncInterval is how often the cache is checked meanwhile
+	cacheSyncInterval = 2 * time.Millisecond
+)
+
+// cacheTracker records when the monitors last updated the cache
+type cacheTracker struct {
+	lastUpdate atomic.Int64 // Unix nanoseconds
+}
+
+func (t *cacheTracker) touch() {
+	t.lastUpdate.Store(time.Now().UnixNano())
+}
+
+// handler returns the cache event handler recording updates
+func (t *cacheTracker) handler() cache.EventHandler {
+	return &cache.EventHandlerFuncs{
+		AddFunc:    func(string, model.Model) { t.touch() },
+		UpdateFunc: func(string, model.Model, model.Model) { t.touch() },
+		DeleteFunc: func(string, model.Model) { t.touch() },
+	}
+}
+
+// CacheStats returns the state of the client's cache of the northbound
+// database
+func (c *Client) CacheStats() metrics.OVSDBCacheStats {
+	stats := metrics.OVSDBCacheStats{
+		Synced: c.IsConnected(),
+		Rows:   make(map[string]int),
+	}
+	if last := c.cacheUpdates.lastUpdate.Load(); last > 0 {
+		stats.LastUpdate = time.Unix(0, last)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return stats
+	}
+	tableCache := c.nbClient.Cache()
+	if tableCache == nil {
+		return stats
+	}
+	for _, table := range tableCache.Tables() {
+		if rows := tableCache.Table(table); rows != nil {
+			stats.Rows[table] = rows.Len()
+		}
+	}
+	return stats
+}
+
+// committedRows returns the rows a committed transaction inserted, by the
+// UUIDs the server assigned them, and deleted
+func committedRows(ops []ovsdb.Operation, results []ovsdb.OperationResult) *txAttempt {
+	attempt := &txAttempt{}
+	for i, op := range ops {
+		switch {
+		case op.Op == ovsdb.OperationInsert && op.UUID != "":
+			attempt.inserted = append(attempt.inserted, txRow{table: op.Table, uuid: op.UUID})
+		case op.Op == ovsdb.OperationInsert && i < len(results) && results[i].UUID.GoUUID != "":
+			attempt.inserted = append(attempt.inserted, txRow{table: op.Table, uuid: results[i].UUID.GoUUID})
+		}
+	}
+	_, attempt.deleted = txRows(ops)
+	return attempt
+}
+
+// awaitCache waits until the cache reflects the rows a committed attempt
+// inserted and deleted, so that reads and transactions following it see
+// them. Callers hold c.mu.
+func (c *Client) awaitCache(ctx context.Context, attempt *txAttempt) {
+	if len(attempt.inserted) == 0 && len(attempt.deleted) == 0 {
+		return
+	}
+
+	start := time.Now()
+	timeout := time.NewTimer(cacheSyncTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(cacheSyncInterval)
+	defer ticker.Stop()
+	for !c.committed(attempt) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timeout.C:
+			log.Printf("OVSDB cache doesn't reflect the committed transaction after %s", cacheSyncTimeout)
+			metrics.RecordOVSDBCacheSync("timeout", time.Since(start).Seconds())
+			return
+		case <-ticker.C:
+		}
+	}
+	metrics.RecordOVSDBCacheSync("synced", time.Since(start).Seconds())
+}
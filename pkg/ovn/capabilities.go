@@ -0,0 +1,229 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/mapper"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// ErrCapabilityUnavailable is wrapped by the errors of operations the
+// northbound schema of the server doesn't support
+var ErrCapabilityUnavailable = errors.New("not supported by the OVN northbound schema")
+
+// Features whose tables or columns not every OVN release has
+const (
+	CapabilityDNS            = "dns"
+	CapabilityQoS            = "qos"
+	CapabilityMirrors        = "mirrors"
+	CapabilitySampling       = "sampling"
+	CapabilityBFD            = "bfd"
+	CapabilityGatewayChassis = "gateway_chassis"
+	CapabilityRouterPolicies = "router_policies"
+)
+
+// capabilityRequirements lists the tables, and the columns as
+// Table.column, that each feature needs
+var capabilityRequirements = []struct {
+	name     string
+	requires []string
+}{
+	{CapabilityDNS, []string{"DNS", "Logical_Switch.dns_records"}},
+	{CapabilityQoS, []string{"QoS", "Logical_Switch.qos_rules"}},
+	{CapabilityMirrors, []string{"Mirror", "Logical_Switch_Port.mirror_rules"}},
+	{CapabilitySampling, []string{"Sample", "Sample_Collector", "Sampling_App", "ACL.sample_new", "ACL.sample_est"}},
+	{CapabilityBFD, []string{"BFD", "Logical_Router_Static_Route.bfd"}},
+	{CapabilityGatewayChassis, []string{"Gateway_Chassis", "HA_Chassis_Group", "HA_Chassis", "Logical_Router_Port.ha_chassis_group"}},
+	{CapabilityRouterPolicies, []string{"Logical_Router_Policy", "Logical_Router.policies"}},
+}
+
+// coreTables are the tables ovncp can't work without. A server whose
+// schema lacks them, or columns of them, isn't supported.
+var coreTables = map[string]bool{
+	"Logical_Switch":              true,
+	"Logical_Switch_Port":         true,
+	"Logical_Router":              true,
+	"Logical_Router_Port":         true,
+	"Logical_Router_Static_Route": true,
+	"ACL":                         true,
+	"Address_Set":                 true,
+	"Port_Group":                  true,
+	"Load_Balancer":               true,
+	"NAT":                         true,
+	"NB_Global":                   true,
+}
+
+// Capability tells whether a feature is supported by the northbound schema
+type Capability struct {
+	Name      string   `json:"name"`
+	Available bool     `json:"available"`
+	Requires  []string `json:"requires"`
+	Missing   []string `json:"missing,omitempty"`
+}
+
+// Capabilities is the capability matrix of a northbound schema
+type Capabilities struct {
+	SchemaVersion string       `json:"schema_version"`
+	Features      []Capability `json:"features"`
+	// UnsupportedTables lists the tables of ovncp's model the schema
+	// lacks, or lacks columns of, which are left out of the client's model
+	UnsupportedTables []string `json:"unsupported_tables,omitempty"`
+}
+
+// Available returns whether the named feature is supported. Features that
+// don't depend on the schema always are.
+func (c *Capabilities) Available(name string) bool {
+	for _, feature := range c.Features {
+		if feature.Name == name {
+			return feature.Available
+		}
+	}
+	return true
+}
+
+// supports returns whether table is part of the client's model
+func (c *Capabilities) supports(table string) bool {
+	for _, unsupported := range c.UnsupportedTables {
+		if unsupported == table {
+			return false
+		}
+	}
+	return true
+}
+
+// Require returns an error wrapping ErrCapabilityUnavailable when the
+// named feature isn't supported
+func (c *Capabilities) Require(name string) error {
+	for _, feature := range c.Features {
+		if feature.Name == name && !feature.Available {
+			return fmt.Errorf("%s: %w (schema %s lacks %s)", name, ErrCapabilityUnavailable,
+				c.SchemaVersion, strings.Join(feature.Missing, ", "))
+		}
+	}
+	return nil
+}
+
+// DetectCapabilities checks the tables of ovncp's model against schema. It
+// returns the model of the tables the schema supports, and fails when a
+// core table isn't.
+func DetectCapabilities(schema ovsdb.DatabaseSchema) (model.ClientDBModel, *Capabilities, error) {
+	caps := &Capabilities{SchemaVersion: schema.Version}
+
+	// Columns missing from the schema, and tables whose model doesn't map
+	// onto it
+	missing := map[string]bool{}
+	supported := map[string]model.Model{}
+	var unsupportedCore []string
+	for table, m := range nbTables() {
+		problems := tableProblems(schema, table, m)
+		if len(problems) == 0 {
+			supported[table] = m
+			continue
+		}
+		for _, problem := range problems {
+			missing[problem] = true
+		}
+		missing[table] = true
+		caps.UnsupportedTables = append(caps.UnsupportedTables, table)
+		if coreTables[table] {
+			unsupportedCore = append(unsupportedCore, problems...)
+		}
+	}
+	sort.Strings(caps.UnsupportedTables)
+	if len(unsupportedCore) > 0 {
+		sort.Strings(unsupportedCore)
+		return model.ClientDBModel{}, nil, fmt.Errorf("OVN northbound schema %s is not supported: it lacks %s",
+			schema.Version, strings.Join(unsupportedCore, ", "))
+	}
+
+	for _, requirement := range capabilityRequirements {
+		feature := Capability{Name: requirement.name, Requires: requirement.requires}
+		for _, required := range requirement.requires {
+			if missing[required] {
+				feature.Missing = append(feature.Missing, required)
+			}
+		}
+		feature.Available = len(feature.Missing) == 0
+		caps.Features = append(caps.Features, feature)
+	}
+
+	dbModel, err := model.NewClientDBModel("OVN_Northbound", supported)
+	if err != nil {
+		return model.ClientDBModel{}, nil, err
+	}
+	return dbModel, caps, nil
+}
+
+// tableProblems returns what keeps the model of table from mapping onto
+// schema: the table or its columns, as Table.column, when missing, or the
+// mapping error
+func tableProblems(schema ovsdb.DatabaseSchema, table string, m model.Model) []string {
+	tableSchema := schema.Table(table)
+	if tableSchema == nil {
+		return []string{table}
+	}
+
+	var problems []string
+	modelType := reflect.TypeOf(m).Elem()
+	for i := 0; i < modelType.NumField(); i++ {
+		column := modelType.Field(i).Tag.Get("ovsdb")
+		if column != "" && tableSchema.Column(column) == nil {
+			problems = append(problems, table+"."+column)
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	if _, err := mapper.NewInfo(table, tableSchema, m); err != nil {
+		return []string{fmt.Sprintf("%s (%v)", table, err)}
+	}
+	return nil
+}
+
+// fetchSchema reads the northbound schema of the server with a connection
+// of its own, whose empty model can't fail validation
+func (c *Client) fetchSchema(ctx context.Context) (ovsdb.DatabaseSchema, error) {
+	emptyModel, err := model.NewClientDBModel("OVN_Northbound", map[string]model.Model{})
+	if err != nil {
+		return ovsdb.DatabaseSchema{}, err
+	}
+	opts := []client.Option{client.WithEndpoint(c.config.NorthboundDB), client.WithLogger(c.logger)}
+	if c.tls != nil {
+		opts = append(opts, client.WithTLSConfig(c.tls.TLSConfig()))
+	}
+	probe, err := client.NewOVSDBClient(emptyModel, opts...)
+	if err != nil {
+		return ovsdb.DatabaseSchema{}, fmt.Errorf("failed to create OVSDB client: %w", err)
+	}
+	if err := probe.Connect(ctx); err != nil {
+		return ovsdb.DatabaseSchema{}, fmt.Errorf("failed to connect to OVN northbound DB: %w", err)
+	}
+	defer probe.Close()
+
+	return probe.Schema(), nil
+}
+
+// Capabilities returns the capability matrix of the server's northbound
+// schema, nil until the client first connected
+func (c *Client) Capabilities() *Capabilities {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.capabilities
+}
+
+// requireCapability returns an error wrapping ErrCapabilityUnavailable when
+// the server's schema doesn't support the named feature. Callers hold c.mu.
+func (c *Client) requireCapability(name string) error {
+	if c.capabilities == nil {
+		return nil
+	}
+	return c.capabilities.Require(name)
+}
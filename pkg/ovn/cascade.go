@@ -0,0 +1,292 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+type cascadeKey struct{}
+
+// WithCascade makes the switch and router deletes run under ctx delete their
+// dependents in the same transaction, rather than leaving them dangling or
+// refusing to delete routers with ports
+func WithCascade(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cascadeKey{}, true)
+}
+
+func cascadeFrom(ctx context.Context) bool {
+	cascade, _ := ctx.Value(cascadeKey{}).(bool)
+	return cascade
+}
+
+// cascadeSwitchOps returns the operations deleting the dependents of a
+// switch ahead of the switch itself, in dependency order:
+//
+//  1. the ports' IP addresses are removed from address sets
+//  2. backends at those addresses are removed from the VIPs of the load
+//     balancers applied to the switch, and VIPs left without backends go
+//  3. the ports are removed from port groups, whose ACLs apply to them
+//  4. the switch's ACLs and ports are detached from it and deleted
+func (c *Client) cascadeSwitchOps(ctx context.Context, switchID string) ([]ovsdb.Operation, error) {
+	sw := &nbdb.LogicalSwitch{UUID: switchID}
+	if err := c.nbClient.Get(ctx, sw); err != nil {
+		return nil, fmt.Errorf("logical switch %s not found", switchID)
+	}
+
+	portIDs := make(map[string]bool, len(sw.Ports))
+	ips := make(map[string]bool)
+	for _, id := range sw.Ports {
+		portIDs[id] = true
+		port := &nbdb.LogicalSwitchPort{UUID: id}
+		if err := c.nbClient.Get(ctx, port); err != nil {
+			continue
+		}
+		for _, ip := range portIPs(port) {
+			ips[ip] = true
+		}
+	}
+
+	var ops []ovsdb.Operation
+	appendOps := func(newOps []ovsdb.Operation, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to create cascade operations: %w", err)
+		}
+		ops = append(ops, newOps...)
+		return nil
+	}
+
+	if len(ips) > 0 {
+		var sets []nbdb.AddressSet
+		if err := c.nbClient.List(ctx, &sets); err != nil {
+			return nil, fmt.Errorf("failed to list address sets: %w", err)
+		}
+		for i := range sets {
+			var members []string
+			for _, addr := range sets[i].Addresses {
+				if ips[normalizeIP(addr)] {
+					members = append(members, addr)
+				}
+			}
+			if len(members) == 0 {
+				continue
+			}
+			as := &nbdb.AddressSet{UUID: sets[i].UUID}
+			if err := appendOps(c.nbClient.Where(as).Mutate(as, model.Mutation{
+				Field:   &as.Addresses,
+				Mutator: ovsdb.MutateOperationDelete,
+				Value:   members,
+			})); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, lbID := range sw.LoadBalancer {
+			lb := &nbdb.LoadBalancer{UUID: lbID}
+			if err := c.nbClient.Get(ctx, lb); err != nil {
+				continue
+			}
+			vips, changed := withoutBackends(lb.Vips, ips)
+			if !changed {
+				continue
+			}
+			lb.Vips = vips
+			if err := appendOps(c.nbClient.Where(lb).Update(lb, &lb.Vips)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(portIDs) > 0 {
+		var groups []nbdb.PortGroup
+		if err := c.nbClient.List(ctx, &groups); err != nil {
+			return nil, fmt.Errorf("failed to list port groups: %w", err)
+		}
+		for i := range groups {
+			var members []string
+			for _, id := range groups[i].Ports {
+				if portIDs[id] {
+					members = append(members, id)
+				}
+			}
+			if len(members) == 0 {
+				continue
+			}
+			pg := &nbdb.PortGroup{UUID: groups[i].UUID}
+			if err := appendOps(c.nbClient.Where(pg).Mutate(pg, model.Mutation{
+				Field:   &pg.Ports,
+				Mutator: ovsdb.MutateOperationDelete,
+				Value:   members,
+			})); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(sw.ACLs) > 0 || len(sw.Ports) > 0 {
+		detach := &nbdb.LogicalSwitch{UUID: sw.UUID}
+		var mutations []model.Mutation
+		if len(sw.ACLs) > 0 {
+			mutations = append(mutations, model.Mutation{Field: &detach.ACLs, Mutator: ovsdb.MutateOperationDelete, Value: sw.ACLs})
+		}
+		if len(sw.Ports) > 0 {
+			mutations = append(mutations, model.Mutation{Field: &detach.Ports, Mutator: ovsdb.MutateOperationDelete, Value: sw.Ports})
+		}
+		if err := appendOps(c.nbClient.Where(detach).Mutate(detach, mutations...)); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range sw.ACLs {
+		if err := appendOps(c.nbClient.Where(&nbdb.ACL{UUID: id}).Delete()); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range sw.Ports {
+		if err := appendOps(c.nbClient.Where(&nbdb.LogicalSwitchPort{UUID: id}).Delete()); err != nil {
+			return nil, err
+		}
+	}
+
+	return ops, nil
+}
+
+// cascadeRouterOps returns the operations deleting the switch ports peered
+// with a router's ports, which would otherwise be left pointing at router
+// ports that no longer exist. The router ports themselves go with the
+// router.
+func (c *Client) cascadeRouterOps(ctx context.Context, routerID string) ([]ovsdb.Operation, error) {
+	lr := &nbdb.LogicalRouter{UUID: routerID}
+	if err := c.nbClient.Get(ctx, lr); err != nil {
+		return nil, fmt.Errorf("logical router %s not found", routerID)
+	}
+
+	names := make(map[string]bool, len(lr.Ports))
+	for _, id := range lr.Ports {
+		lrp := &nbdb.LogicalRouterPort{UUID: id}
+		if err := c.nbClient.Get(ctx, lrp); err == nil {
+			names[lrp.Name] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var peers []nbdb.LogicalSwitchPort
+	err := c.nbClient.WhereCache(func(port *nbdb.LogicalSwitchPort) bool {
+		return port.Type == "router" && names[port.Options["router-port"]]
+	}).List(ctx, &peers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list peer switch ports: %w", err)
+	}
+	peerIDs := make(map[string]bool, len(peers))
+	for i := range peers {
+		peerIDs[peers[i].UUID] = true
+	}
+
+	var switches []nbdb.LogicalSwitch
+	err = c.nbClient.WhereCache(func(sw *nbdb.LogicalSwitch) bool {
+		for _, id := range sw.Ports {
+			if peerIDs[id] {
+				return true
+			}
+		}
+		return false
+	}).List(ctx, &switches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connected switches: %w", err)
+	}
+
+	var ops []ovsdb.Operation
+	for i := range switches {
+		var detached []string
+		for _, id := range switches[i].Ports {
+			if peerIDs[id] {
+				detached = append(detached, id)
+			}
+		}
+		sw := &nbdb.LogicalSwitch{UUID: switches[i].UUID}
+		mutateOps, err := c.nbClient.Where(sw).Mutate(sw, model.Mutation{
+			Field:   &sw.Ports,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   detached,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create switch mutate operations: %w", err)
+		}
+		ops = append(ops, mutateOps...)
+	}
+	for i := range peers {
+		deleteOps, err := c.nbClient.Where(&nbdb.LogicalSwitchPort{UUID: peers[i].UUID}).Delete()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create delete operations: %w", err)
+		}
+		ops = append(ops, deleteOps...)
+	}
+	return ops, nil
+}
+
+// portIPs returns the IP addresses of a port, static and dynamically
+// assigned, in normalized form
+func portIPs(port *nbdb.LogicalSwitchPort) []string {
+	addresses := append([]string(nil), port.Addresses...)
+	if port.DynamicAddresses != nil {
+		addresses = append(addresses, *port.DynamicAddresses)
+	}
+
+	var ips []string
+	for _, address := range addresses {
+		for _, field := range strings.Fields(address) {
+			if ip := normalizeIP(field); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// normalizeIP returns the canonical form of an IP address, with an optional
+// prefix length, or "" if addr isn't one
+func normalizeIP(addr string) string {
+	if ip, _, err := net.ParseCIDR(addr); err == nil {
+		addr = ip.String()
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// withoutBackends removes the backends at ips from load balancer VIPs,
+// dropping VIPs left without backends, and reports whether any changed
+func withoutBackends(vips map[string]string, ips map[string]bool) (map[string]string, bool) {
+	result := make(map[string]string, len(vips))
+	changed := false
+	for vip, backends := range vips {
+		var kept []string
+		for _, backend := range strings.Split(backends, ",") {
+			backend = strings.TrimSpace(backend)
+			if backend == "" {
+				continue
+			}
+			host := backend
+			if h, _, err := net.SplitHostPort(backend); err == nil {
+				host = h
+			}
+			if ips[normalizeIP(host)] {
+				changed = true
+				continue
+			}
+			kept = append(kept, backend)
+		}
+		if len(kept) > 0 {
+			result[vip] = strings.Join(kept, ",")
+		}
+	}
+	return result, changed
+}
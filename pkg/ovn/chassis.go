@@ -0,0 +1,299 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+
+	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// sbChassis is the part of a southbound Chassis row the inventory reads
+type sbChassis struct {
+	UUID           string            `ovsdb:"_uuid"`
+	Name           string            `ovsdb:"name"`
+	Hostname       string            `ovsdb:"hostname"`
+	Encaps         []string          `ovsdb:"encaps"`
+	OtherConfig    map[string]string `ovsdb:"other_config"`
+	ExternalIDs    map[string]string `ovsdb:"external_ids"`
+	TransportZones []string          `ovsdb:"transport_zones"`
+}
+
+// sbEncap is the part of a southbound Encap row the inventory reads
+type sbEncap struct {
+	UUID    string            `ovsdb:"_uuid"`
+	Type    string            `ovsdb:"type"`
+	IP      string            `ovsdb:"ip"`
+	Options map[string]string `ovsdb:"options"`
+}
+
+// sbPortBinding is the part of a southbound Port_Binding row the inventory
+// reads
+type sbPortBinding struct {
+	UUID        string  `ovsdb:"_uuid"`
+	LogicalPort string  `ovsdb:"logical_port"`
+	Type        string  `ovsdb:"type"`
+	Chassis     *string `ovsdb:"chassis"`
+	TunnelKey   int     `ovsdb:"tunnel_key"`
+	Up          *bool   `ovsdb:"up"`
+}
+
+// Chassis other_config keys ovn-controller copies from the Open_vSwitch
+// database; the other keys set to "true" are the features it supports
+const (
+	chassisCMSOptionsKey    = "ovn-cms-options"
+	chassisDatapathTypeKey  = "datapath-type"
+	chassisIfaceTypesKey    = "iface-types"
+	chassisBridgeMappingKey = "ovn-bridge-mappings"
+	chassisInterconnKey     = "is-interconn"
+	chassisRemoteKey        = "is-remote"
+)
+
+// chassisAsGatewayOption is the CMS option marking a chassis as eligible to
+// host gateways
+const chassisAsGatewayOption = "enable-chassis-as-gw"
+
+// ChassisInventory reads the chassis registered in the southbound database
+// and the ports bound to them. The connection is opened on first use and
+// kept.
+type ChassisInventory struct {
+	cfg *config.OVNConfig
+
+	mu sync.Mutex
+	sb client.Client
+}
+
+// NewChassisInventory creates an inventory reading the southbound database
+// of cfg
+func NewChassisInventory(cfg *config.OVNConfig) *ChassisInventory {
+	return &ChassisInventory{cfg: cfg}
+}
+
+// ListChassis returns every chassis, sorted by name, with the number of
+// ports bound to it
+func (c *ChassisInventory) ListChassis(ctx context.Context) ([]*models.Chassis, error) {
+	sb, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chassis []sbChassis
+	if err := sb.List(ctx, &chassis); err != nil {
+		return nil, fmt.Errorf("failed to list chassis: %w", err)
+	}
+	var encaps []sbEncap
+	if err := sb.List(ctx, &encaps); err != nil {
+		return nil, fmt.Errorf("failed to list encapsulations: %w", err)
+	}
+	var bindings []sbPortBinding
+	if err := sb.List(ctx, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to list port bindings: %w", err)
+	}
+	return convertChassis(chassis, encaps, bindings), nil
+}
+
+// PortBindings returns the southbound binding of each of the logical ports,
+// keyed by port name. Ports ovn-northd hasn't created a binding for yet are
+// reported as pending.
+func (c *ChassisInventory) PortBindings(ctx context.Context, ports []string) (map[string]*models.PortBinding, error) {
+	sb, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chassis []sbChassis
+	if err := sb.List(ctx, &chassis); err != nil {
+		return nil, fmt.Errorf("failed to list chassis: %w", err)
+	}
+	var bindings []sbPortBinding
+	if err := sb.List(ctx, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to list port bindings: %w", err)
+	}
+	return convertPortBindings(ports, chassis, bindings), nil
+}
+
+// Close closes the southbound connection
+func (c *ChassisInventory) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sb != nil {
+		c.sb.Close()
+		c.sb = nil
+	}
+}
+
+// client returns the southbound connection, connecting and monitoring the
+// chassis, their encapsulations, the datapath and port bindings if it isn't
+// connected
+func (c *ChassisInventory) client(ctx context.Context) (client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sb != nil && c.sb.Connected() {
+		return c.sb, nil
+	} else if c.sb != nil {
+		c.sb.Close()
+		c.sb = nil
+	}
+
+	dbModel, _ := model.NewClientDBModel("OVN_Southbound", map[string]model.Model{
+		"Chassis":          &sbChassis{},
+		"Encap":            &sbEncap{},
+		"Datapath_Binding": &sbDatapathBinding{},
+		"Port_Binding":     &sbPortBinding{},
+	})
+	opts := []client.Option{client.WithEndpoint(c.cfg.SouthboundDB), client.WithLogger(newOVSDBLogger(c.cfg.SouthboundDB))}
+	if c.cfg.TLS.Enabled {
+		reloader, err := newTLSReloader(&c.cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLSConfig(reloader.TLSConfig()))
+	}
+	sb, err := client.NewOVSDBClient(dbModel, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OVSDB client: %w", err)
+	}
+
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+	if err := sb.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to OVN southbound DB: %w", err)
+	}
+	binding := &sbPortBinding{}
+	monitor := sb.NewMonitor(
+		client.WithTable(&sbChassis{}),
+		client.WithTable(&sbEncap{}),
+		client.WithTable(&sbDatapathBinding{}),
+		client.WithTable(binding, &binding.LogicalPort, &binding.Type, &binding.Chassis, &binding.TunnelKey, &binding.Up),
+	)
+	if _, err := sb.Monitor(ctx, monitor); err != nil {
+		sb.Close()
+		return nil, fmt.Errorf("failed to monitor chassis: %w", err)
+	}
+
+	c.sb = sb
+	return sb, nil
+}
+
+// convertChassis builds the inventory from southbound rows. Gateway ports
+// are the chassis-redirect ports of distributed gateways and the ports of
+// gateway routers.
+func convertChassis(chassis []sbChassis, encaps []sbEncap, bindings []sbPortBinding) []*models.Chassis {
+	encapsByUUID := make(map[string]sbEncap, len(encaps))
+	for _, encap := range encaps {
+		encapsByUUID[encap.UUID] = encap
+	}
+
+	result := make([]*models.Chassis, 0, len(chassis))
+	byUUID := make(map[string]*models.Chassis, len(chassis))
+	for _, ch := range chassis {
+		item := &models.Chassis{
+			UUID:           ch.UUID,
+			Name:           ch.Name,
+			Hostname:       ch.Hostname,
+			Encaps:         []models.ChassisEncap{},
+			DatapathType:   ch.OtherConfig[chassisDatapathTypeKey],
+			TransportZones: ch.TransportZones,
+			Interconnect:   ch.OtherConfig[chassisInterconnKey] == "true" || ch.OtherConfig[chassisRemoteKey] == "true",
+			Features:       []string{},
+			ExternalIDs:    ch.ExternalIDs,
+		}
+		for _, encapUUID := range ch.Encaps {
+			if encap, ok := encapsByUUID[encapUUID]; ok {
+				item.Encaps = append(item.Encaps, models.ChassisEncap{Type: encap.Type, IP: encap.IP, Options: encap.Options})
+			}
+		}
+		sort.Slice(item.Encaps, func(i, j int) bool {
+			return item.Encaps[i].Type < item.Encaps[j].Type
+		})
+		for _, option := range strings.Split(ch.OtherConfig[chassisCMSOptionsKey], ",") {
+			item.Gateway = item.Gateway || strings.TrimSpace(option) == chassisAsGatewayOption
+		}
+		if types := ch.OtherConfig[chassisIfaceTypesKey]; types != "" {
+			item.InterfaceTypes = strings.Split(types, ",")
+		}
+		if mappings := ch.OtherConfig[chassisBridgeMappingKey]; mappings != "" {
+			item.BridgeMappings = strings.Split(mappings, ",")
+		}
+		for key, value := range ch.OtherConfig {
+			switch key {
+			case chassisInterconnKey, chassisRemoteKey:
+				continue
+			}
+			if value == "true" {
+				item.Features = append(item.Features, key)
+			}
+		}
+		sort.Strings(item.Features)
+
+		result = append(result, item)
+		byUUID[ch.UUID] = item
+	}
+
+	for _, binding := range bindings {
+		if binding.Chassis == nil {
+			continue
+		}
+		item, ok := byUUID[*binding.Chassis]
+		if !ok {
+			continue
+		}
+		item.Ports++
+		if binding.Type == "chassisredirect" || binding.Type == "l3gateway" {
+			item.GatewayPorts++
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// convertPortBindings reports the bindings of the ports. A port with a
+// chassis is up once ovn-controller has installed its flows; versions of
+// ovn-controller that don't report it leave up unset, and a port bound to
+// them counts as up.
+func convertPortBindings(ports []string, chassis []sbChassis, bindings []sbPortBinding) map[string]*models.PortBinding {
+	chassisByUUID := make(map[string]sbChassis, len(chassis))
+	for _, ch := range chassis {
+		chassisByUUID[ch.UUID] = ch
+	}
+	bindingsByPort := make(map[string]sbPortBinding, len(bindings))
+	for _, binding := range bindings {
+		bindingsByPort[binding.LogicalPort] = binding
+	}
+
+	result := make(map[string]*models.PortBinding, len(ports))
+	for _, port := range ports {
+		binding, ok := bindingsByPort[port]
+		if !ok {
+			result[port] = &models.PortBinding{Status: models.PortBindingPending}
+			continue
+		}
+
+		item := &models.PortBinding{TunnelKey: binding.TunnelKey, Status: models.PortBindingUnbound}
+		if binding.Chassis != nil {
+			ch := chassisByUUID[*binding.Chassis]
+			item.Chassis, item.ChassisHostname = ch.Name, ch.Hostname
+			item.Up = binding.Up == nil || *binding.Up
+			item.Status = models.PortBindingDown
+			if item.Up {
+				item.Status = models.PortBindingUp
+			}
+		}
+		result[port] = item
+	}
+	return result
+}
@@ -2,16 +2,22 @@ package ovn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/stdr"
 	"github.com/ovn-org/libovsdb/client"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
 	
 	"github.com/lspecian/ovncp/internal/config"
+	"github.com/lspecian/ovncp/internal/metrics"
 	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
 )
 
@@ -22,16 +28,32 @@ type Client struct {
 	connected  bool
 	closed     bool
 	lastPing   time.Time
+	tls        *tlsReloader
+	state      *connState
+	logger     *logr.Logger
+	txCounters txCounters
+
+	// opts create the OVSDB client again once the server's schema is
+	// known, with the model of the tables it supports
+	opts         []client.Option
+	capabilities *Capabilities
+
+	cacheUpdates cacheTracker
 }
 
-// DatabaseModel returns the OVN Northbound database model
-func DatabaseModel() model.ClientDBModel {
-	dbModel, _ := model.NewClientDBModel("OVN_Northbound", map[string]model.Model{
+// ErrClientClosed is returned when connecting a client that has been closed
+var ErrClientClosed = errors.New("OVN client is closed")
+
+// nbTables returns the model of each table of the OVN Northbound database
+// ovncp uses
+func nbTables() map[string]model.Model {
+	return map[string]model.Model{
 		"Logical_Switch":              &nbdb.LogicalSwitch{},
 		"Logical_Switch_Port":         &nbdb.LogicalSwitchPort{},
 		"Logical_Router":              &nbdb.LogicalRouter{},
 		"Logical_Router_Port":         &nbdb.LogicalRouterPort{},
 		"Logical_Router_Static_Route": &nbdb.LogicalRouterStaticRoute{},
+		"Logical_Router_Policy":       &nbdb.LogicalRouterPolicy{},
 		"ACL":                         &nbdb.ACL{},
 		"Address_Set":                 &nbdb.AddressSet{},
 		"Port_Group":                  &nbdb.PortGroup{},
@@ -43,18 +65,66 @@ func DatabaseModel() model.ClientDBModel {
 		"Meter":                       &nbdb.Meter{},
 		"Meter_Band":                  &nbdb.MeterBand{},
 		"DNS":                         &nbdb.DNS{},
+		"Mirror":                      &nbdb.Mirror{},
+		"Sample":                      &nbdb.Sample{},
+		"Sample_Collector":            &nbdb.SampleCollector{},
+		"Sampling_App":                &nbdb.SamplingApp{},
+		"BFD":                         &nbdb.BFD{},
+		"Gateway_Chassis":             &nbdb.GatewayChassis{},
+		"HA_Chassis_Group":            &nbdb.HAChassisGroup{},
+		"HA_Chassis":                  &nbdb.HAChassis{},
 		"Connection":                  &nbdb.Connection{},
 		"SSL":                         &nbdb.SSL{},
 		"NB_Global":                   &nbdb.NBGlobal{},
-	})
+	}
+}
+
+// DatabaseModel returns the OVN Northbound database model
+func DatabaseModel() model.ClientDBModel {
+	dbModel, _ := model.NewClientDBModel("OVN_Northbound", nbTables())
 	return dbModel
 }
 
+// newOVSDBLogger returns the logger of the OVSDB clients of a database.
+// Given none, libovsdb makes its own and sets the verbosity of every stdr
+// logger, which races when clients are created concurrently.
+func newOVSDBLogger(endpoint string) *logr.Logger {
+	l := stdr.NewWithOptions(log.New(os.Stderr, "", log.LstdFlags), stdr.Options{LogCaller: stdr.All}).
+		WithName("libovsdb").WithValues("endpoint", endpoint)
+	return &l
+}
+
 func NewClient(cfg *config.OVNConfig) (*Client, error) {
 	dbModel := DatabaseModel()
 
+	state := &connState{}
+	logger := newOVSDBLogger(cfg.NorthboundDB)
+	opts := []client.Option{
+		client.WithEndpoint(cfg.NorthboundDB),
+		client.WithLogger(logger),
+	}
+
+	// Re-establish dropped connections and their monitors automatically. The
+	// inactivity check also catches peers that vanish without closing the socket.
+	reconnect := newReconnectBackoff(cfg.ReconnectInterval, cfg.ReconnectMaxDelay, state)
+	if cfg.InactivityProbe > 0 {
+		opts = append(opts, client.WithInactivityCheck(cfg.InactivityProbe, cfg.Timeout, reconnect))
+	} else {
+		opts = append(opts, client.WithReconnect(cfg.Timeout, reconnect))
+	}
+
+	var reloader *tlsReloader
+	if cfg.TLS.Enabled {
+		var err error
+		reloader, err = newTLSReloader(&cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLSConfig(reloader.TLSConfig()))
+	}
+
 	// Create the OVSDB client
-	ovnClient, err := client.NewOVSDBClient(dbModel)
+	ovnClient, err := client.NewOVSDBClient(dbModel, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OVSDB client: %w", err)
 	}
@@ -62,41 +132,71 @@ func NewClient(cfg *config.OVNConfig) (*Client, error) {
 	c := &Client{
 		config:   cfg,
 		nbClient: ovnClient,
+		tls:      reloader,
+		state:    state,
+		logger:   logger,
+		opts:     opts,
 	}
 
 	return c, nil
 }
 
+// ReloadTLS re-reads the configured certificate, key and CA files. Existing
+// connections are unaffected; new handshakes use the reloaded material.
+func (c *Client) ReloadTLS() error {
+	if c.tls == nil {
+		return nil
+	}
+	return c.tls.Reload()
+}
+
 func (c *Client) Connect(ctx context.Context) error {
+	err := c.connect(ctx)
+	c.state.recordResult(err)
+	return err
+}
+
+func (c *Client) connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return ErrClientClosed
+	}
 	if c.connected {
 		return nil
 	}
 
-	// Connect to the database
-	if err := c.nbClient.Connect(ctx); err != nil {
+	// Features of OVN releases whose schema lacks tables or columns are
+	// left out of the model, rather than failing at runtime
+	if c.capabilities == nil {
+		if err := c.detectCapabilities(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Connect to the database. A previous attempt may have connected before
+	// the monitor failed, in which case the session is reused.
+	if err := c.nbClient.Connect(ctx); err != nil && !errors.Is(err, client.ErrAlreadyConnected) {
 		return fmt.Errorf("failed to connect to OVN northbound DB: %w", err)
 	}
 
-	// Monitor all tables we're interested in
-	monitor := c.nbClient.NewMonitor(
-		client.WithTable(&nbdb.LogicalSwitch{}),
-		client.WithTable(&nbdb.LogicalSwitchPort{}),
-		client.WithTable(&nbdb.LogicalRouter{}),
-		client.WithTable(&nbdb.LogicalRouterPort{}),
-		client.WithTable(&nbdb.ACL{}),
-		client.WithTable(&nbdb.LoadBalancer{}),
-		client.WithTable(&nbdb.NAT{}),
-		client.WithTable(&nbdb.PortGroup{}),
-		client.WithTable(&nbdb.AddressSet{}),
-	)
-	
+	// Monitor every table of the model the server has, so that all reads
+	// are served from the cache
+	var tables []client.MonitorOption
+	for table, m := range nbTables() {
+		if c.capabilities.supports(table) {
+			tables = append(tables, client.WithTable(m))
+		}
+	}
+	monitor := c.nbClient.NewMonitor(tables...)
+
 	_, err := c.nbClient.Monitor(ctx, monitor)
 	if err != nil {
 		return fmt.Errorf("failed to start monitoring: %w", err)
 	}
+	c.nbClient.Cache().AddEventHandler(c.cacheUpdates.handler())
+	c.cacheUpdates.touch()
 
 	c.connected = true
 	log.Println("Successfully connected to OVN northbound database")
@@ -104,11 +204,37 @@ func (c *Client) Connect(ctx context.Context) error {
 	return nil
 }
 
+// detectCapabilities reads the server's schema and, when it lacks tables
+// of the model, creates the OVSDB client again without them
+func (c *Client) detectCapabilities(ctx context.Context) error {
+	schema, err := c.fetchSchema(ctx)
+	if err != nil {
+		return err
+	}
+	dbModel, capabilities, err := DetectCapabilities(schema)
+	if err != nil {
+		return err
+	}
+
+	if len(capabilities.UnsupportedTables) > 0 {
+		nbClient, err := client.NewOVSDBClient(dbModel, c.opts...)
+		if err != nil {
+			return fmt.Errorf("failed to create OVSDB client: %w", err)
+		}
+		c.nbClient.Close()
+		c.nbClient = nbClient
+		log.Printf("OVN northbound schema %s lacks tables or columns of %s; features using them are disabled",
+			schema.Version, strings.Join(capabilities.UnsupportedTables, ", "))
+	}
+	c.capabilities = capabilities
+	return nil
+}
+
 func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.connected || c.closed {
+	if c.closed {
 		return nil
 	}
 
@@ -123,7 +249,8 @@ func (c *Client) IsConnected() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.connected && !c.closed
+	// The OVSDB client reports false while it is reconnecting
+	return c.connected && !c.closed && c.nbClient.Connected()
 }
 
 // IsClosed returns true if the client has been closed
@@ -136,25 +263,36 @@ func (c *Client) IsClosed() bool {
 
 // Ping checks if the connection is alive
 func (c *Client) Ping(ctx context.Context) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if !c.connected || c.closed {
+	if !c.IsConnected() {
 		return fmt.Errorf("client not connected")
 	}
 
 	// Use a simple list operation to check connectivity
 	// List NB_Global which should always exist and have minimal data
-	nbGlobal := &nbdb.NBGlobal{}
-	err := c.nbClient.List(ctx, nbGlobal)
+	var nbGlobal []nbdb.NBGlobal
+	err := c.nbClient.List(ctx, &nbGlobal)
 	if err != nil {
 		return fmt.Errorf("ping failed: %w", err)
 	}
 
+	c.mu.Lock()
 	c.lastPing = time.Now()
+	c.mu.Unlock()
 	return nil
 }
 
+// Echo sends an OVSDB echo request, checking the server answers rather than
+// only that the connection is up; Ping reads the local cache
+func (c *Client) Echo(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return fmt.Errorf("client not connected")
+	}
+	return c.nbClient.Echo(ctx)
+}
+
 func (c *Client) ExecuteWithRetry(ctx context.Context, fn func() error) error {
 	var lastErr error
 	for i := 0; i < c.config.MaxRetries; i++ {
@@ -179,7 +317,38 @@ func (c *Client) Transact(ctx context.Context, ops ...ovsdb.Operation) ([]ovsdb.
 		return nil, fmt.Errorf("client not connected")
 	}
 
-	return c.nbClient.Transact(ctx, ops...)
+	return c.transact(ctx, ops...)
+}
+
+// transact commits ops and records the transaction in the OVSDB metrics. A
+// transaction fails when any of its operations reports an error. Once it
+// succeeded, the cache reflects the rows it inserted and deleted.
+func (c *Client) transact(ctx context.Context, ops ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	start := time.Now()
+	c.txCounters.inFlight.Add(1)
+	results, err := c.nbClient.Transact(ctx, ops...)
+	c.txCounters.inFlight.Add(-1)
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	} else {
+		for _, result := range results {
+			if result.Error != "" {
+				status = "failure"
+				break
+			}
+		}
+	}
+	metrics.RecordOVSDBTransaction(status, time.Since(start).Seconds())
+	if status == "success" {
+		c.txCounters.succeeded.Add(1)
+		c.awaitCache(ctx, committedRows(ops, results))
+	} else {
+		c.txCounters.failed.Add(1)
+	}
+
+	return results, err
 }
 
 // GetClient returns the underlying OVSDB client
@@ -197,9 +366,10 @@ func (c *Client) GetConnectionInfo() map[string]interface{} {
 	defer c.mu.RUnlock()
 
 	info := map[string]interface{}{
-		"connected": c.connected,
+		"connected": c.connected && c.nbClient.Connected(),
 		"address":   c.config.NorthboundDB,
 		"timeout":   c.config.Timeout.String(),
+		"tls":       c.tls != nil,
 	}
 
 	if c.connected && !c.lastPing.IsZero() {
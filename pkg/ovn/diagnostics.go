@@ -0,0 +1,143 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// NorthdStatus is how far ovn-northd and the chassis have caught up with the
+// northbound configuration, from the sequence numbers in NB_Global. nb_cfg
+// is bumped by clients; ovn-northd copies it to sb_cfg once the southbound
+// database reflects it, and to hv_cfg once every chassis has applied it.
+type NorthdStatus struct {
+	NbCfg          int        `json:"nb_cfg"`
+	SbCfg          int        `json:"sb_cfg"`
+	HvCfg          int        `json:"hv_cfg"`
+	NbCfgTimestamp *time.Time `json:"nb_cfg_timestamp,omitempty"`
+	SbCfgTimestamp *time.Time `json:"sb_cfg_timestamp,omitempty"`
+	HvCfgTimestamp *time.Time `json:"hv_cfg_timestamp,omitempty"`
+	// SbLag and HvLag are the sequence numbers the southbound database and
+	// the chassis are behind
+	SbLag int `json:"sb_lag"`
+	HvLag int `json:"hv_lag"`
+}
+
+// TransactionStats counts the transactions a client has committed
+type TransactionStats struct {
+	InFlight  int64 `json:"in_flight"` // Sent and not answered yet
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"` // Rejected by the server or not answered
+	Retried   int64 `json:"retried"`
+}
+
+// txCounters are the counters behind TransactionStats
+type txCounters struct {
+	inFlight  atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+}
+
+// NorthdStatus reads the configuration sequence numbers from NB_Global
+func (c *Client) NorthdStatus(ctx context.Context) (*NorthdStatus, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	var globals []nbdb.NBGlobal
+	if err := c.nbClient.List(ctx, &globals); err != nil {
+		return nil, fmt.Errorf("failed to read NB_Global: %w", err)
+	}
+	if len(globals) == 0 {
+		return nil, fmt.Errorf("NB_Global not found")
+	}
+
+	global := globals[0]
+	return &NorthdStatus{
+		NbCfg:          global.NbCfg,
+		SbCfg:          global.SbCfg,
+		HvCfg:          global.HvCfg,
+		NbCfgTimestamp: cfgTimestamp(global.NbCfgTimestamp),
+		SbCfgTimestamp: cfgTimestamp(global.SbCfgTimestamp),
+		HvCfgTimestamp: cfgTimestamp(global.HvCfgTimestamp),
+		SbLag:          global.NbCfg - global.SbCfg,
+		HvLag:          global.NbCfg - global.HvCfg,
+	}, nil
+}
+
+// cfgTimestamp converts an NB_Global timestamp, in milliseconds since the
+// epoch and 0 until set
+func cfgTimestamp(ms int) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := time.UnixMilli(int64(ms)).UTC()
+	return &t
+}
+
+// TableSizes returns the number of rows of each northbound table in the
+// client's cache, which mirrors the database
+func (c *Client) TableSizes() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	sizes := make(map[string]int)
+	tableCache := c.nbClient.Cache()
+	if tableCache == nil {
+		return sizes
+	}
+	for _, table := range tableCache.Tables() {
+		if rows := tableCache.Table(table); rows != nil {
+			sizes[table] = rows.Len()
+		}
+	}
+	return sizes
+}
+
+// TransactionStats returns the transactions committed since the client was
+// created
+func (c *Client) TransactionStats() TransactionStats {
+	return TransactionStats{
+		InFlight:  c.txCounters.inFlight.Load(),
+		Succeeded: c.txCounters.succeeded.Load(),
+		Failed:    c.txCounters.failed.Load(),
+		Retried:   c.txCounters.retried.Load(),
+	}
+}
+
+// SouthboundStatus is the state of the southbound connection and the number
+// of rows of the tables read from it
+type SouthboundStatus struct {
+	Connected  bool           `json:"connected"`
+	Endpoint   string         `json:"endpoint"`
+	Error      string         `json:"error,omitempty"`
+	TableSizes map[string]int `json:"table_sizes,omitempty"`
+}
+
+// Status connects to the southbound database if it isn't connected, and
+// reports the connection and the chassis, encapsulations and port bindings
+func (c *ChassisInventory) Status(ctx context.Context) SouthboundStatus {
+	status := SouthboundStatus{Endpoint: c.cfg.SouthboundDB}
+	sb, err := c.client(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Connected = sb.Connected()
+	status.TableSizes = make(map[string]int)
+	tableCache := sb.Cache()
+	if tableCache == nil {
+		return status
+	}
+	for _, table := range tableCache.Tables() {
+		if rows := tableCache.Table(table); rows != nil {
+			status.TableSizes[table] = rows.Len()
+		}
+	}
+	return status
+}
@@ -0,0 +1,326 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// ListDNS returns all DNS record sets along with the switches they're
+// attached to
+func (c *Client) ListDNS(ctx context.Context) ([]*models.DNS, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	dnsList := []nbdb.DNS{}
+	if err := c.nbClient.List(ctx, &dnsList); err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+	attached, err := c.dnsSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.DNS, 0, len(dnsList))
+	for i := range dnsList {
+		result = append(result, convertDNS(&dnsList[i], attached[dnsList[i].UUID]))
+	}
+	return result, nil
+}
+
+// GetDNS returns a DNS record set by UUID
+func (c *Client) GetDNS(ctx context.Context, id string) (*models.DNS, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row := &nbdb.DNS{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("DNS record set %s not found", id)
+	}
+	attached, err := c.dnsSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertDNS(row, attached[id]), nil
+}
+
+// CreateDNS creates a DNS record set and attaches it to its switches
+func (c *Client) CreateDNS(ctx context.Context, dns *models.DNS) (*models.DNS, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	if err := validateDNSRecords(dns.Records); err != nil {
+		return nil, err
+	}
+	if err := c.checkSwitchesExist(ctx, dns.Switches); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	externalIDs := make(map[string]string, len(dns.ExternalIDs)+2)
+	for k, v := range dns.ExternalIDs {
+		externalIDs[k] = v
+	}
+	externalIDs["created_at"] = now.Format(time.RFC3339)
+	externalIDs["updated_at"] = now.Format(time.RFC3339)
+
+	row := &nbdb.DNS{
+		UUID:        uuid.New().String(),
+		Records:     dns.Records,
+		Options:     dns.Options,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DNS operations: %w", err)
+	}
+	attachOps, err := c.attachDNS(row.UUID, dns.Switches, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.Transact(ctx, append(ops, attachOps...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DNS record set: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertDNS(row, dns.Switches), nil
+}
+
+// UpdateDNS updates a DNS record set. Records, options and external IDs
+// given replace the current ones, and switches given replace the switches
+// it's attached to.
+func (c *Client) UpdateDNS(ctx context.Context, id string, updates *models.DNS) (*models.DNS, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetDNS(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardDNS(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &nbdb.DNS{
+		UUID:        existing.UUID,
+		Records:     existing.Records,
+		Options:     existing.Options,
+		ExternalIDs: existing.ExternalIDs,
+	}
+	if updates.Records != nil {
+		if err := validateDNSRecords(updates.Records); err != nil {
+			return nil, err
+		}
+		row.Records = updates.Records
+	}
+	if updates.Options != nil {
+		row.Options = updates.Options
+	}
+	row.ExternalIDs = updatedExternalIDs(existing.ExternalIDs, updates.ExternalIDs, time.Now())
+
+	ops, err := c.nbClient.Where(row).Update(row, &row.Records, &row.Options, &row.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+	if updates.Switches != nil {
+		if err := c.checkSwitchesExist(ctx, updates.Switches); err != nil {
+			return nil, err
+		}
+		attachOps, err := c.attachDNS(id, updates.Switches, existing.Switches)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, attachOps...)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update DNS record set: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetDNS(ctx, id)
+}
+
+// DeleteDNS detaches a DNS record set from its switches and deletes it
+func (c *Client) DeleteDNS(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetDNS(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardDNS(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ops, err := c.attachDNS(id, nil, existing.Switches)
+	if err != nil {
+		return err
+	}
+	deleteOps, err := c.nbClient.Where(&nbdb.DNS{UUID: id}).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+	ops = append(ops, deleteOps...)
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete DNS record set: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// dnsSwitches returns the switches each DNS record set is attached to, keyed
+// by DNS UUID
+func (c *Client) dnsSwitches(ctx context.Context) (map[string][]string, error) {
+	switches := []nbdb.LogicalSwitch{}
+	if err := c.nbClient.List(ctx, &switches); err != nil {
+		return nil, fmt.Errorf("failed to list logical switches: %w", err)
+	}
+
+	attached := make(map[string][]string)
+	for _, sw := range switches {
+		for _, dnsUUID := range sw.DNSRecords {
+			attached[dnsUUID] = append(attached[dnsUUID], sw.UUID)
+		}
+	}
+	return attached, nil
+}
+
+// checkSwitchesExist returns an error naming the first switch that doesn't
+// exist
+func (c *Client) checkSwitchesExist(ctx context.Context, switchIDs []string) error {
+	for _, switchID := range switchIDs {
+		if err := c.nbClient.Get(ctx, &nbdb.LogicalSwitch{UUID: switchID}); err != nil {
+			return fmt.Errorf("logical switch %s not found", switchID)
+		}
+	}
+	return nil
+}
+
+// attachDNS returns the operations attaching a DNS record set to the
+// switches it should be attached to and detaching it from the others it is
+func (c *Client) attachDNS(dnsUUID string, want, current []string) ([]ovsdb.Operation, error) {
+	wanted := make(map[string]bool, len(want))
+	for _, switchID := range want {
+		wanted[switchID] = true
+	}
+	attached := make(map[string]bool, len(current))
+	for _, switchID := range current {
+		attached[switchID] = true
+	}
+
+	var ops []ovsdb.Operation
+	mutate := func(switchID string, mutator ovsdb.Mutator) error {
+		sw := &nbdb.LogicalSwitch{UUID: switchID}
+		mutateOps, err := c.nbClient.Where(sw).Mutate(sw, model.Mutation{
+			Field:   &sw.DNSRecords,
+			Mutator: mutator,
+			Value:   []string{dnsUUID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create switch mutate operations: %w", err)
+		}
+		ops = append(ops, mutateOps...)
+		return nil
+	}
+	for _, switchID := range want {
+		if !attached[switchID] {
+			attached[switchID] = true
+			if err := mutate(switchID, ovsdb.MutateOperationInsert); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, switchID := range current {
+		if !wanted[switchID] {
+			if err := mutate(switchID, ovsdb.MutateOperationDelete); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ops, nil
+}
+
+// validateDNSRecords checks that each record maps a hostname to IP
+// addresses, or, for a reverse lookup name under .arpa, to a hostname
+func validateDNSRecords(records map[string]string) error {
+	if len(records) == 0 {
+		return fmt.Errorf("at least one DNS record is required")
+	}
+	for name, value := range records {
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("invalid DNS record name %q", name)
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return fmt.Errorf("DNS record %s has no value", name)
+		}
+		if strings.HasSuffix(strings.ToLower(name), ".arpa") {
+			continue
+		}
+		for _, field := range fields {
+			if net.ParseIP(field) == nil {
+				return fmt.Errorf("DNS record %s has invalid IP address %q", name, field)
+			}
+		}
+	}
+	return nil
+}
+
+// convertDNS converts an OVN DNS row to our model
+func convertDNS(row *nbdb.DNS, switches []string) *models.DNS {
+	return &models.DNS{
+		UUID:        row.UUID,
+		Records:     row.Records,
+		Options:     row.Options,
+		Switches:    switches,
+		ExternalIDs: row.ExternalIDs,
+		CreatedAt:   parseTime(row.ExternalIDs["created_at"]),
+		UpdatedAt:   parseTime(row.ExternalIDs["updated_at"]),
+	}
+}
@@ -0,0 +1,458 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// Types of gateways
+const (
+	GatewayTypeDistributed = "distributed"
+	GatewayTypeL3Gateway   = "l3_gateway"
+)
+
+// hostingChassisKey is the status key of a distributed gateway port naming
+// the chassis it's active on. ovn-northd sets it from the southbound port
+// binding.
+const hostingChassisKey = "hosting-chassis"
+
+// ListGateways returns the gateways of all logical routers: their
+// distributed gateway ports, with the gateway chassis or HA chassis group
+// they're bound to, and gateway routers pinned to a chassis with the
+// chassis option
+func (c *Client) ListGateways(ctx context.Context) ([]*models.Gateway, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lrList := []nbdb.LogicalRouter{}
+	if err := c.nbClient.List(ctx, &lrList); err != nil {
+		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	lrpList := []nbdb.LogicalRouterPort{}
+	if err := c.nbClient.List(ctx, &lrpList); err != nil {
+		return nil, fmt.Errorf("failed to list logical router ports: %w", err)
+	}
+	gcList := []nbdb.GatewayChassis{}
+	if err := c.nbClient.List(ctx, &gcList); err != nil {
+		return nil, fmt.Errorf("failed to list gateway chassis: %w", err)
+	}
+	groupList := []nbdb.HAChassisGroup{}
+	if err := c.nbClient.List(ctx, &groupList); err != nil {
+		return nil, fmt.Errorf("failed to list HA chassis groups: %w", err)
+	}
+	haList := []nbdb.HAChassis{}
+	if err := c.nbClient.List(ctx, &haList); err != nil {
+		return nil, fmt.Errorf("failed to list HA chassis: %w", err)
+	}
+
+	ports := make(map[string]*nbdb.LogicalRouterPort, len(lrpList))
+	for i := range lrpList {
+		ports[lrpList[i].UUID] = &lrpList[i]
+	}
+	gatewayChassis := make(map[string]*nbdb.GatewayChassis, len(gcList))
+	for i := range gcList {
+		gatewayChassis[gcList[i].UUID] = &gcList[i]
+	}
+	groups := make(map[string]*nbdb.HAChassisGroup, len(groupList))
+	for i := range groupList {
+		groups[groupList[i].UUID] = &groupList[i]
+	}
+	haChassis := make(map[string]*nbdb.HAChassis, len(haList))
+	for i := range haList {
+		haChassis[haList[i].UUID] = &haList[i]
+	}
+
+	var result []*models.Gateway
+	for _, lr := range lrList {
+		if chassis := lr.Options["chassis"]; chassis != "" {
+			gateway := &models.Gateway{
+				Type:           GatewayTypeL3Gateway,
+				RouterID:       lr.UUID,
+				RouterName:     lr.Name,
+				Chassis:        []models.GatewayChassis{{Name: chassis}},
+				HostingChassis: chassis,
+			}
+			for _, portUUID := range lr.Ports {
+				if lrp, ok := ports[portUUID]; ok {
+					gateway.Networks = append(gateway.Networks, lrp.Networks...)
+				}
+			}
+			result = append(result, gateway)
+			continue
+		}
+
+		for _, portUUID := range lr.Ports {
+			lrp, ok := ports[portUUID]
+			if !ok || (len(lrp.GatewayChassis) == 0 && lrp.HaChassisGroup == nil) {
+				continue
+			}
+
+			gateway := &models.Gateway{
+				Type:           GatewayTypeDistributed,
+				RouterID:       lr.UUID,
+				RouterName:     lr.Name,
+				PortID:         lrp.UUID,
+				PortName:       lrp.Name,
+				Networks:       lrp.Networks,
+				Chassis:        []models.GatewayChassis{},
+				HostingChassis: lrp.Status[hostingChassisKey],
+			}
+			for _, gcUUID := range lrp.GatewayChassis {
+				if gc, ok := gatewayChassis[gcUUID]; ok {
+					gateway.Chassis = append(gateway.Chassis, models.GatewayChassis{Name: gc.ChassisName, Priority: gc.Priority})
+				}
+			}
+			// An HA chassis group takes precedence over gateway chassis
+			if lrp.HaChassisGroup != nil {
+				if group, ok := groups[*lrp.HaChassisGroup]; ok {
+					gateway.HAChassisGroup = group.Name
+					gateway.Chassis = gateway.Chassis[:0]
+					for _, haUUID := range group.HaChassis {
+						if ha, ok := haChassis[haUUID]; ok {
+							gateway.Chassis = append(gateway.Chassis, models.GatewayChassis{Name: ha.ChassisName, Priority: ha.Priority})
+						}
+					}
+				}
+			}
+			sort.SliceStable(gateway.Chassis, func(i, j int) bool {
+				return gateway.Chassis[i].Priority > gateway.Chassis[j].Priority
+			})
+			result = append(result, gateway)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].RouterName != result[j].RouterName {
+			return result[i].RouterName < result[j].RouterName
+		}
+		return result[i].PortName < result[j].PortName
+	})
+	return result, nil
+}
+
+// SetGatewayBinding binds a router port, by UUID or name, to gateway
+// chassis or an HA chassis group with the given priorities, replacing its
+// previous binding. Chassis bound before keep their rows. An HA chassis
+// group the port leaves is deleted when nothing else uses it.
+func (c *Client) SetGatewayBinding(ctx context.Context, portID string, binding *models.GatewayBinding) (*models.Gateway, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lrp, err := c.logicalRouterPortRow(ctx, portID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []ovsdb.Operation
+	if binding.HAChassisGroup != "" {
+		groupOps, groupUUID, err := c.haChassisGroupOps(ctx, binding.HAChassisGroup, binding.Chassis)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, groupOps...)
+
+		row := &nbdb.LogicalRouterPort{UUID: lrp.UUID, HaChassisGroup: &groupUUID, GatewayChassis: []string{}}
+		updateOps, err := c.nbClient.Where(row).Update(row, &row.HaChassisGroup, &row.GatewayChassis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create router port update operations: %w", err)
+		}
+		ops = append(ops, updateOps...)
+	} else {
+		chassisOps, chassisUUIDs, err := c.gatewayChassisOps(ctx, lrp, binding.Chassis)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, chassisOps...)
+
+		row := &nbdb.LogicalRouterPort{UUID: lrp.UUID, GatewayChassis: chassisUUIDs}
+		updateOps, err := c.nbClient.Where(row).Update(row, &row.HaChassisGroup, &row.GatewayChassis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create router port update operations: %w", err)
+		}
+		ops = append(ops, updateOps...)
+	}
+
+	if lrp.HaChassisGroup != nil {
+		releaseOps, err := c.releaseHAChassisGroup(ctx, *lrp.HaChassisGroup, lrp.UUID, binding.HAChassisGroup)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, releaseOps...)
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set gateway binding: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	gateways, err := c.ListGateways(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, gateway := range gateways {
+		if gateway.PortID == lrp.UUID {
+			return gateway, nil
+		}
+	}
+	return nil, fmt.Errorf("gateway port %s not found", portID)
+}
+
+// SetGatewayPriorities changes the priorities of the chassis gateway ports,
+// keyed by UUID, are bound to, in a single transaction. Ports bound through
+// an HA chassis group change the group, and with it the other ports sharing
+// it. Chassis the ports aren't bound to can't be added this way.
+func (c *Client) SetGatewayPriorities(ctx context.Context, priorities map[string][]models.GatewayChassis) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	var ops []ovsdb.Operation
+	for portID, chassis := range priorities {
+		lrp, err := c.logicalRouterPortRow(ctx, portID)
+		if err != nil {
+			return err
+		}
+		wanted := make(map[string]int, len(chassis))
+		for _, ch := range chassis {
+			wanted[ch.Name] = ch.Priority
+		}
+
+		var bound []string
+		if lrp.HaChassisGroup != nil {
+			group := &nbdb.HAChassisGroup{UUID: *lrp.HaChassisGroup}
+			if err := c.nbClient.Get(ctx, group); err != nil {
+				return fmt.Errorf("HA chassis group of gateway port %s not found", lrp.Name)
+			}
+			for _, haUUID := range group.HaChassis {
+				ha := &nbdb.HAChassis{UUID: haUUID}
+				if err := c.nbClient.Get(ctx, ha); err != nil {
+					continue
+				}
+				bound = append(bound, ha.ChassisName)
+				if priority, ok := wanted[ha.ChassisName]; ok && priority != ha.Priority {
+					ha.Priority = priority
+					updateOps, err := c.nbClient.Where(ha).Update(ha, &ha.Priority)
+					if err != nil {
+						return fmt.Errorf("failed to create HA chassis update operations: %w", err)
+					}
+					ops = append(ops, updateOps...)
+				}
+			}
+		} else {
+			for _, gcUUID := range lrp.GatewayChassis {
+				gc := &nbdb.GatewayChassis{UUID: gcUUID}
+				if err := c.nbClient.Get(ctx, gc); err != nil {
+					continue
+				}
+				bound = append(bound, gc.ChassisName)
+				if priority, ok := wanted[gc.ChassisName]; ok && priority != gc.Priority {
+					gc.Priority = priority
+					updateOps, err := c.nbClient.Where(gc).Update(gc, &gc.Priority)
+					if err != nil {
+						return fmt.Errorf("failed to create gateway chassis update operations: %w", err)
+					}
+					ops = append(ops, updateOps...)
+				}
+			}
+		}
+
+		for name := range wanted {
+			found := false
+			for _, b := range bound {
+				found = found || b == name
+			}
+			if !found {
+				return fmt.Errorf("chassis %s is not bound to gateway port %s", name, lrp.Name)
+			}
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return fmt.Errorf("failed to set gateway priorities: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+// logicalRouterPortRow returns a logical router port row by UUID or name
+func (c *Client) logicalRouterPortRow(ctx context.Context, id string) (*nbdb.LogicalRouterPort, error) {
+	lrpList := []nbdb.LogicalRouterPort{}
+	if err := c.nbClient.List(ctx, &lrpList); err != nil {
+		return nil, fmt.Errorf("failed to list logical router ports: %w", err)
+	}
+	for i := range lrpList {
+		if lrpList[i].UUID == id || lrpList[i].Name == id {
+			return &lrpList[i], nil
+		}
+	}
+	return nil, fmt.Errorf("logical router port %s not found", id)
+}
+
+// gatewayChassisOps returns the operations binding a router port to
+// chassis, updating the priority of the chassis it's already bound to, and
+// the UUIDs of its gateway chassis afterwards. Gateway chassis it leaves are
+// garbage collected once the port no longer references them.
+func (c *Client) gatewayChassisOps(ctx context.Context, lrp *nbdb.LogicalRouterPort, chassis []models.GatewayChassis) ([]ovsdb.Operation, []string, error) {
+	existing := make(map[string]*nbdb.GatewayChassis, len(lrp.GatewayChassis))
+	for _, gcUUID := range lrp.GatewayChassis {
+		gc := &nbdb.GatewayChassis{UUID: gcUUID}
+		if err := c.nbClient.Get(ctx, gc); err == nil {
+			existing[gc.ChassisName] = gc
+		}
+	}
+
+	var ops []ovsdb.Operation
+	uuids := make([]string, 0, len(chassis))
+	for _, ch := range chassis {
+		if gc, ok := existing[ch.Name]; ok {
+			uuids = append(uuids, gc.UUID)
+			if gc.Priority == ch.Priority {
+				continue
+			}
+			gc.Priority = ch.Priority
+			updateOps, err := c.nbClient.Where(gc).Update(gc, &gc.Priority)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create gateway chassis update operations: %w", err)
+			}
+			ops = append(ops, updateOps...)
+			continue
+		}
+
+		// Named as ovn-nbctl lrp-set-gateway-chassis names them
+		gc := &nbdb.GatewayChassis{
+			UUID:        uuid.New().String(),
+			Name:        lrp.Name + "-" + ch.Name,
+			ChassisName: ch.Name,
+			Priority:    ch.Priority,
+		}
+		createOps, err := c.nbClient.Create(gc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create gateway chassis operations: %w", err)
+		}
+		ops = append(ops, createOps...)
+		uuids = append(uuids, gc.UUID)
+	}
+	return ops, uuids, nil
+}
+
+// haChassisGroupOps returns the operations setting the chassis of the HA
+// chassis group with the given name, creating it if needed, and its UUID
+func (c *Client) haChassisGroupOps(ctx context.Context, name string, chassis []models.GatewayChassis) ([]ovsdb.Operation, string, error) {
+	groups := []nbdb.HAChassisGroup{}
+	err := c.nbClient.WhereCache(func(g *nbdb.HAChassisGroup) bool {
+		return g.Name == name
+	}).List(ctx, &groups)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list HA chassis groups: %w", err)
+	}
+
+	group := &nbdb.HAChassisGroup{UUID: uuid.New().String(), Name: name}
+	isNew := len(groups) == 0
+	if !isNew {
+		group = &groups[0]
+	}
+
+	existing := make(map[string]*nbdb.HAChassis, len(group.HaChassis))
+	for _, haUUID := range group.HaChassis {
+		ha := &nbdb.HAChassis{UUID: haUUID}
+		if err := c.nbClient.Get(ctx, ha); err == nil {
+			existing[ha.ChassisName] = ha
+		}
+	}
+
+	var ops []ovsdb.Operation
+	haUUIDs := make([]string, 0, len(chassis))
+	for _, ch := range chassis {
+		if ha, ok := existing[ch.Name]; ok {
+			haUUIDs = append(haUUIDs, ha.UUID)
+			if ha.Priority == ch.Priority {
+				continue
+			}
+			ha.Priority = ch.Priority
+			updateOps, err := c.nbClient.Where(ha).Update(ha, &ha.Priority)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to create HA chassis update operations: %w", err)
+			}
+			ops = append(ops, updateOps...)
+			continue
+		}
+
+		ha := &nbdb.HAChassis{UUID: uuid.New().String(), ChassisName: ch.Name, Priority: ch.Priority}
+		createOps, err := c.nbClient.Create(ha)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create HA chassis operations: %w", err)
+		}
+		ops = append(ops, createOps...)
+		haUUIDs = append(haUUIDs, ha.UUID)
+	}
+
+	group.HaChassis = haUUIDs
+	if isNew {
+		createOps, err := c.nbClient.Create(group)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create HA chassis group operations: %w", err)
+		}
+		return append(ops, createOps...), group.UUID, nil
+	}
+	updateOps, err := c.nbClient.Where(group).Update(group, &group.HaChassis)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create HA chassis group update operations: %w", err)
+	}
+	return append(ops, updateOps...), group.UUID, nil
+}
+
+// releaseHAChassisGroup returns the operations deleting an HA chassis group
+// a router port leaves, unless it stays with the group named keep or other
+// router or switch ports use it
+func (c *Client) releaseHAChassisGroup(ctx context.Context, groupUUID, portUUID, keep string) ([]ovsdb.Operation, error) {
+	group := &nbdb.HAChassisGroup{UUID: groupUUID}
+	if err := c.nbClient.Get(ctx, group); err != nil || group.Name == keep {
+		return nil, nil
+	}
+
+	routerPorts := []nbdb.LogicalRouterPort{}
+	err := c.nbClient.WhereCache(func(lrp *nbdb.LogicalRouterPort) bool {
+		return lrp.UUID != portUUID && lrp.HaChassisGroup != nil && *lrp.HaChassisGroup == groupUUID
+	}).List(ctx, &routerPorts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logical router ports: %w", err)
+	}
+	switchPorts := []nbdb.LogicalSwitchPort{}
+	err = c.nbClient.WhereCache(func(lsp *nbdb.LogicalSwitchPort) bool {
+		return lsp.HaChassisGroup != nil && *lsp.HaChassisGroup == groupUUID
+	}).List(ctx, &switchPorts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logical switch ports: %w", err)
+	}
+	if len(routerPorts) > 0 || len(switchPorts) > 0 {
+		return nil, nil
+	}
+
+	ops, err := c.nbClient.Where(group).Delete()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HA chassis group delete operations: %w", err)
+	}
+	return ops, nil
+}
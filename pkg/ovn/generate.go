@@ -0,0 +1,12 @@
+package ovn
+
+// The nbdb package holds the models of the OVN Northbound tables, generated
+// by libovsdb's modelgen from schema/ovn-nb.ovsschema. The client uses them
+// with libovsdb's typed API and monitor cache; don't edit them by hand.
+//
+// "go generate ./pkg/ovn" downloads the schema when it's missing and
+// regenerates the models. To move to a newer schema, run
+// "go run modelgen/main.go -update" first.
+
+//go:generate go run modelgen/main.go
+//go:generate go run github.com/ovn-org/libovsdb/cmd/modelgen -p nbdb -o nbdb schema/ovn-nb.ovsschema
@@ -0,0 +1,95 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// SetLabels sets and removes labels of a switch, router, port or ACL,
+// keeping its other labels, and returns the resulting labels. Labels are
+// stored in external_ids under models.LabelPrefix, which are mutated in
+// place so that concurrent changes to other keys aren't lost.
+func (c *Client) SetLabels(ctx context.Context, resource, id string, set map[string]string, remove []string) (map[string]string, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	var row model.Model
+	var externalIDs *map[string]string
+	var guard func(context.Context, string) ([]ovsdb.Operation, error)
+	var name string
+	switch resource {
+	case models.ResourceSwitch:
+		ls := &nbdb.LogicalSwitch{UUID: id}
+		row, externalIDs, guard, name = ls, &ls.ExternalIDs, c.guardLogicalSwitch, "logical switch"
+	case models.ResourceRouter:
+		lr := &nbdb.LogicalRouter{UUID: id}
+		row, externalIDs, guard, name = lr, &lr.ExternalIDs, c.guardLogicalRouter, "logical router"
+	case models.ResourcePort:
+		lsp := &nbdb.LogicalSwitchPort{UUID: id}
+		row, externalIDs, guard, name = lsp, &lsp.ExternalIDs, c.guardLogicalSwitchPort, "logical switch port"
+	case models.ResourceACL:
+		acl := &nbdb.ACL{UUID: id}
+		row, externalIDs, guard, name = acl, &acl.ExternalIDs, c.guardACL, "ACL"
+	default:
+		return nil, fmt.Errorf("resource %s has no labels", resource)
+	}
+
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("%s %s not found", name, id)
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	preconditions, err := guard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// A map insert keeps the value of a present key, so the keys being set
+	// are deleted first
+	labels := models.LabelsFromExternalIDs(*externalIDs)
+	deleted := []string{"updated_at"}
+	inserted := map[string]string{"updated_at": time.Now().Format(time.RFC3339)}
+	if _, ok := actorFrom(ctx); ok {
+		deleted = append(deleted, models.UpdatedByKey, models.UpdatedSourceKey)
+		stampUpdated(ctx, inserted)
+	}
+	for _, key := range remove {
+		deleted = append(deleted, models.LabelPrefix+key)
+		delete(labels, key)
+	}
+	for key, value := range set {
+		deleted = append(deleted, models.LabelPrefix+key)
+		inserted[models.LabelPrefix+key] = value
+		labels[key] = value
+	}
+
+	ops, err := c.nbClient.Where(row).Mutate(row,
+		model.Mutation{Field: externalIDs, Mutator: ovsdb.MutateOperationDelete, Value: deleted},
+		model.Mutation{Field: externalIDs, Mutator: ovsdb.MutateOperationInsert, Value: inserted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mutate operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(preconditions, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set labels of %s: %w", name, err)
+	}
+	if err := guardResult(results, preconditions); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return labels, nil
+}
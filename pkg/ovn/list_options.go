@@ -0,0 +1,65 @@
+package ovn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// sortValue is the value a row is ordered by; rows compare by n when the
+// field is numeric and by s otherwise
+type sortValue struct {
+	s string
+	n int
+}
+
+func compareSortValues(a, b sortValue) int {
+	if a.n != b.n {
+		if a.n < b.n {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.s, b.s)
+}
+
+// sortField resolves the sort field requested in opts against the fields a
+// resource supports
+func sortField(opts *models.ListOptions, fields []string, defaultField string) (field string, desc bool, err error) {
+	if opts == nil || opts.SortBy == "" {
+		return defaultField, false, nil
+	}
+	for _, f := range fields {
+		if f == opts.SortBy {
+			return f, opts.SortDesc, nil
+		}
+	}
+	return "", false, fmt.Errorf("unsupported sort field %q, expected one of: %s", opts.SortBy, strings.Join(fields, ", "))
+}
+
+// sortRows orders rows, a slice, by key. Rows with equal keys are ordered by
+// UUID so that pages are stable between requests.
+func sortRows(rows interface{}, key func(i int) sortValue, uuid func(i int) string, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		cmp := compareSortValues(key(i), key(j))
+		if cmp == 0 {
+			return uuid(i) < uuid(j)
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// timeSortValue orders by a timestamp kept in external_ids. Rows without
+// one sort first.
+func timeSortValue(externalIDs map[string]string, key string) sortValue {
+	t := parseTime(externalIDs[key])
+	if t.IsZero() {
+		return sortValue{}
+	}
+	return sortValue{n: int(t.Unix())}
+}
@@ -0,0 +1,290 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// loadBalancerSortFields are the fields load balancers can be sorted by
+var loadBalancerSortFields = []string{"name", "protocol", "created_at", "updated_at"}
+
+// ListLoadBalancers returns all load balancers
+func (c *Client) ListLoadBalancers(ctx context.Context) ([]*models.LoadBalancer, error) {
+	lbs, _, err := c.ListLoadBalancersPage(ctx, nil)
+	return lbs, err
+}
+
+// ListLoadBalancersPage returns the load balancers matching opts, sorted and
+// paged, along with the number of matches before paging
+func (c *Client) ListLoadBalancersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LoadBalancer, int, error) {
+	if !c.IsConnected() {
+		return nil, 0, fmt.Errorf("client not connected")
+	}
+
+	field, desc, err := sortField(opts, loadBalancerSortFields, "name")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lbList := []nbdb.LoadBalancer{}
+	err = c.nbClient.WhereCache(func(lb *nbdb.LoadBalancer) bool {
+		return opts.Matches(lb.Name, lb.ExternalIDs)
+	}).List(ctx, &lbList)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list load balancers: %w", err)
+	}
+
+	sortRows(lbList, func(i int) sortValue {
+		switch field {
+		case "protocol":
+			if lbList[i].Protocol != nil {
+				return sortValue{s: *lbList[i].Protocol}
+			}
+			return sortValue{}
+		case "created_at", "updated_at":
+			return timeSortValue(lbList[i].ExternalIDs, field)
+		}
+		return sortValue{s: lbList[i].Name}
+	}, func(i int) string { return lbList[i].UUID }, desc)
+
+	start, end := opts.Bounds(len(lbList))
+	result := make([]*models.LoadBalancer, 0, end-start)
+	for i := start; i < end; i++ {
+		result = append(result, convertLoadBalancer(&lbList[i]))
+	}
+
+	return result, len(lbList), nil
+}
+
+// GetLoadBalancer returns a specific load balancer by UUID or name
+func (c *Client) GetLoadBalancer(ctx context.Context, id string) (*models.LoadBalancer, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lbList := []nbdb.LoadBalancer{}
+	err := c.nbClient.List(ctx, &lbList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list load balancers: %w", err)
+	}
+
+	for i := range lbList {
+		if lbList[i].UUID == id || lbList[i].Name == id {
+			return convertLoadBalancer(&lbList[i]), nil
+		}
+	}
+
+	return nil, fmt.Errorf("load balancer %s not found", id)
+}
+
+// CreateLoadBalancer creates a new load balancer
+func (c *Client) CreateLoadBalancer(ctx context.Context, lb *models.LoadBalancer) (*models.LoadBalancer, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	if lb.UUID == "" {
+		lb.UUID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if lb.ExternalIDs == nil {
+		lb.ExternalIDs = make(map[string]string)
+	}
+	lb.ExternalIDs["created_at"] = now.Format(time.RFC3339)
+	lb.ExternalIDs["updated_at"] = now.Format(time.RFC3339)
+
+	ovnLB := &nbdb.LoadBalancer{
+		UUID:            lb.UUID,
+		Name:            lb.Name,
+		Vips:            lb.VIPs,
+		Protocol:        lb.Protocol,
+		IPPortMappings:  lb.IPPortMappings,
+		SelectionFields: lb.SelectionFields,
+		Options:         lb.Options,
+		ExternalIDs:     lb.ExternalIDs,
+	}
+
+	ops, err := c.nbClient.Create(ovnLB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create load balancer operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create load balancer: %w", err)
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	lb.CreatedAt = now
+	lb.UpdatedAt = now
+
+	return lb, nil
+}
+
+// UpdateLoadBalancer updates an existing load balancer. Fields left empty in
+// updates keep their current values.
+func (c *Client) UpdateLoadBalancer(ctx context.Context, id string, updates *models.LoadBalancer) (*models.LoadBalancer, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetLoadBalancer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLoadBalancer(ctx, existing.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	ovnLB := &nbdb.LoadBalancer{
+		UUID:            existing.UUID,
+		Name:            existing.Name,
+		Vips:            existing.VIPs,
+		Protocol:        existing.Protocol,
+		IPPortMappings:  existing.IPPortMappings,
+		SelectionFields: existing.SelectionFields,
+		Options:         existing.Options,
+		ExternalIDs:     existing.ExternalIDs,
+	}
+
+	applyLoadBalancerUpdates(ovnLB, updates)
+
+	ops, err := c.nbClient.Where(ovnLB).Update(ovnLB,
+		&ovnLB.Name, &ovnLB.Vips, &ovnLB.Protocol, &ovnLB.IPPortMappings,
+		&ovnLB.SelectionFields, &ovnLB.Options, &ovnLB.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update load balancer: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetLoadBalancer(ctx, existing.UUID)
+}
+
+// DeleteLoadBalancer deletes a load balancer
+func (c *Client) DeleteLoadBalancer(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetLoadBalancer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLoadBalancer(ctx, existing.UUID)
+	if err != nil {
+		return err
+	}
+
+	ovnLB := &nbdb.LoadBalancer{
+		UUID: existing.UUID,
+	}
+
+	ops, err := c.nbClient.Where(ovnLB).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete load balancer: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// applyLoadBalancerUpdates copies the fields set in updates to ovnLB
+func applyLoadBalancerUpdates(ovnLB *nbdb.LoadBalancer, updates *models.LoadBalancer) {
+	if updates.Name != "" {
+		ovnLB.Name = updates.Name
+	}
+	if updates.VIPs != nil {
+		ovnLB.Vips = updates.VIPs
+	}
+	if updates.Protocol != nil {
+		ovnLB.Protocol = updates.Protocol
+	}
+	if updates.IPPortMappings != nil {
+		ovnLB.IPPortMappings = updates.IPPortMappings
+	}
+	if updates.SelectionFields != nil {
+		ovnLB.SelectionFields = updates.SelectionFields
+	}
+	if updates.Options != nil {
+		ovnLB.Options = updates.Options
+	}
+	if updates.ExternalIDs != nil {
+		ovnLB.ExternalIDs = updates.ExternalIDs
+	}
+	if ovnLB.ExternalIDs == nil {
+		ovnLB.ExternalIDs = make(map[string]string)
+	}
+	ovnLB.ExternalIDs["updated_at"] = time.Now().Format(time.RFC3339)
+}
+
+// convertLoadBalancer converts an OVN load balancer to our model
+func convertLoadBalancer(ovnLB *nbdb.LoadBalancer) *models.LoadBalancer {
+	lb := &models.LoadBalancer{
+		UUID:            ovnLB.UUID,
+		Name:            ovnLB.Name,
+		VIPs:            ovnLB.Vips,
+		Protocol:        ovnLB.Protocol,
+		IPPortMappings:  ovnLB.IPPortMappings,
+		SelectionFields: ovnLB.SelectionFields,
+		Options:         ovnLB.Options,
+		ExternalIDs:     ovnLB.ExternalIDs,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	if created, ok := ovnLB.ExternalIDs["created_at"]; ok {
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			lb.CreatedAt = t
+		}
+	}
+	if updated, ok := ovnLB.ExternalIDs["updated_at"]; ok {
+		if t, err := time.Parse(time.RFC3339, updated); err == nil {
+			lb.UpdatedAt = t
+		}
+	}
+
+	return lb
+}
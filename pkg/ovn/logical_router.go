@@ -11,26 +11,51 @@ import (
 	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
 )
 
+// logicalRouterSortFields are the fields logical routers can be sorted by
+var logicalRouterSortFields = []string{"name", "created_at", "updated_at"}
+
 // ListLogicalRouters returns all logical routers
 func (c *Client) ListLogicalRouters(ctx context.Context) ([]*models.LogicalRouter, error) {
+	routers, _, err := c.ListLogicalRoutersPage(ctx, nil)
+	return routers, err
+}
+
+// ListLogicalRoutersPage returns the logical routers matching opts, sorted
+// and paged, along with the number of matches before paging
+func (c *Client) ListLogicalRoutersPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalRouter, int, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+		return nil, 0, fmt.Errorf("client not connected")
+	}
+
+	field, desc, err := sortField(opts, logicalRouterSortFields, "name")
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Get all logical routers from database
 	lrList := []nbdb.LogicalRouter{}
-	err := c.nbClient.List(ctx, &lrList)
+	err = c.nbClient.WhereCache(func(lr *nbdb.LogicalRouter) bool {
+		return opts.Matches(lr.Name, lr.ExternalIDs)
+	}).List(ctx, &lrList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+		return nil, 0, fmt.Errorf("failed to list logical routers: %w", err)
 	}
 
-	// Convert to our model
-	result := make([]*models.LogicalRouter, 0, len(lrList))
-	for i := range lrList {
+	sortRows(lrList, func(i int) sortValue {
+		switch field {
+		case "created_at", "updated_at":
+			return timeSortValue(lrList[i].ExternalIDs, field)
+		}
+		return sortValue{s: lrList[i].Name}
+	}, func(i int) string { return lrList[i].UUID }, desc)
+
+	// Convert only the requested page to our model
+	start, end := opts.Bounds(len(lrList))
+	result := make([]*models.LogicalRouter, 0, end-start)
+	for i := start; i < end; i++ {
 		result = append(result, convertLogicalRouter(&lrList[i]))
 	}
 
-	return result, nil
+	return result, len(lrList), nil
 }
 
 // GetLogicalRouter returns a specific logical router by UUID or name
@@ -74,6 +99,7 @@ func (c *Client) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRout
 	}
 	lr.ExternalIDs["created_at"] = now.Format(time.RFC3339)
 	lr.ExternalIDs["updated_at"] = now.Format(time.RFC3339)
+	stampCreated(ctx, lr.ExternalIDs)
 
 	// Create the OVN logical router
 	ovnLR := &nbdb.LogicalRouter{
@@ -142,6 +168,7 @@ func (c *Client) CreateLogicalRouter(ctx context.Context, lr *models.LogicalRout
 	// Set timestamps
 	lr.CreatedAt = now
 	lr.UpdatedAt = now
+	lr.Ownership = models.OwnershipFromExternalIDs(lr.ExternalIDs)
 
 	return lr, nil
 }
@@ -158,6 +185,12 @@ func (c *Client) UpdateLogicalRouter(ctx context.Context, id string, updates *mo
 		return nil, err
 	}
 
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalRouter(ctx, existing.UUID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update timestamp
 	now := time.Now()
 	if updates.ExternalIDs == nil {
@@ -167,6 +200,8 @@ func (c *Client) UpdateLogicalRouter(ctx context.Context, id string, updates *mo
 		updates.ExternalIDs = make(map[string]string)
 	}
 	updates.ExternalIDs["updated_at"] = now.Format(time.RFC3339)
+	keepCreation(existing.ExternalIDs, updates.ExternalIDs)
+	stampUpdated(ctx, updates.ExternalIDs)
 
 	// Create the OVN logical router with updates
 	ovnLR := &nbdb.LogicalRouter{
@@ -194,10 +229,13 @@ func (c *Client) UpdateLogicalRouter(ctx context.Context, id string, updates *mo
 	}
 
 	// Execute the transaction
-	results, err := c.Transact(ctx, ops...)
+	results, err := c.Transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update logical router: %w", err)
 	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
 
 	// Check results
 	for _, result := range results {
@@ -222,8 +260,14 @@ func (c *Client) DeleteLogicalRouter(ctx context.Context, id string) error {
 		return err
 	}
 
-	// Check if router has ports
-	if len(existing.Ports) > 0 {
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalRouter(ctx, existing.UUID)
+	if err != nil {
+		return err
+	}
+
+	// Check if router has ports, which are deleted with it on cascade
+	if len(existing.Ports) > 0 && !cascadeFrom(ctx) {
 		return fmt.Errorf("cannot delete router: router has %d ports attached", len(existing.Ports))
 	}
 
@@ -238,11 +282,24 @@ func (c *Client) DeleteLogicalRouter(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to create delete operations: %w", err)
 	}
 
+	// On cascade, the switch ports peered with the router's ports go in the
+	// same transaction
+	if cascadeFrom(ctx) {
+		cascadeOps, err := c.cascadeRouterOps(ctx, existing.UUID)
+		if err != nil {
+			return err
+		}
+		ops = append(cascadeOps, ops...)
+	}
+
 	// Execute the transaction
-	results, err := c.Transact(ctx, ops...)
+	results, err := c.Transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete logical router: %w", err)
 	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
 
 	// Check results
 	for _, result := range results {
@@ -286,6 +343,7 @@ func convertLogicalRouter(ovnLR *nbdb.LogicalRouter) *models.LogicalRouter {
 			lr.UpdatedAt = t
 		}
 	}
+	lr.Ownership = models.OwnershipFromExternalIDs(ovnLR.ExternalIDs)
 
 	return lr
 }
\ No newline at end of file
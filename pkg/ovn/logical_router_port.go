@@ -0,0 +1,206 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// ListLogicalRouterPorts returns the ports of all logical routers
+func (c *Client) ListLogicalRouterPorts(ctx context.Context) ([]*models.LogicalRouterPort, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lrpList := []nbdb.LogicalRouterPort{}
+	if err := c.nbClient.List(ctx, &lrpList); err != nil {
+		return nil, fmt.Errorf("failed to list logical router ports: %w", err)
+	}
+
+	result := make([]*models.LogicalRouterPort, 0, len(lrpList))
+	for i := range lrpList {
+		result = append(result, convertLogicalRouterPort(&lrpList[i]))
+	}
+	return result, nil
+}
+
+// CreateLogicalRouterPort adds a port to a logical router
+func (c *Client) CreateLogicalRouterPort(ctx context.Context, routerID string, port *models.LogicalRouterPort) (*models.LogicalRouterPort, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lr := &nbdb.LogicalRouter{UUID: routerID}
+	if err := c.nbClient.Get(ctx, lr); err != nil {
+		return nil, fmt.Errorf("logical router %s not found", routerID)
+	}
+
+	existing := []nbdb.LogicalRouterPort{}
+	err := c.nbClient.WhereCache(func(lrp *nbdb.LogicalRouterPort) bool {
+		return lrp.Name == port.Name
+	}).List(ctx, &existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing router ports: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("router port %s already exists", port.Name)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	row := &nbdb.LogicalRouterPort{
+		UUID:     uuid.New().String(),
+		Name:     port.Name,
+		MAC:      port.MAC,
+		Networks: port.Networks,
+		Enabled:  port.Enabled,
+		Options:  port.Options,
+		ExternalIDs: map[string]string{
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+	for k, v := range port.ExternalIDs {
+		row.ExternalIDs[k] = v
+	}
+	stampCreated(ctx, row.ExternalIDs)
+
+	ops, err := c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router port operation: %w", err)
+	}
+	mutateOps, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{row.UUID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router update operation: %w", err)
+	}
+	ops = append(ops, mutateOps...)
+
+	results, err := c.transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router port: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertLogicalRouterPort(row), nil
+}
+
+// DeleteLogicalRouterPort removes a port, by UUID or name, from its router.
+// Its gateway chassis go with it.
+func (c *Client) DeleteLogicalRouterPort(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	lrp, err := c.logicalRouterPortRow(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	routers := []nbdb.LogicalRouter{}
+	err = c.nbClient.WhereCache(func(lr *nbdb.LogicalRouter) bool {
+		for _, portUUID := range lr.Ports {
+			if portUUID == lrp.UUID {
+				return true
+			}
+		}
+		return false
+	}).List(ctx, &routers)
+	if err != nil {
+		return fmt.Errorf("failed to find router of port: %w", err)
+	}
+
+	var ops []ovsdb.Operation
+	for i := range routers {
+		lr := &routers[i]
+		mutateOps, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+			Field:   &lr.Ports,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   []string{lrp.UUID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create router update operation: %w", err)
+		}
+		ops = append(ops, mutateOps...)
+	}
+	deleteOps, err := c.nbClient.Where(lrp).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operation: %w", err)
+	}
+	ops = append(ops, deleteOps...)
+
+	results, err := c.transact(ctx, ops...)
+	if err != nil {
+		return fmt.Errorf("failed to delete router port: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+// ListLogicalRouterNATs returns the NAT rules of every logical router, keyed
+// by router UUID
+func (c *Client) ListLogicalRouterNATs(ctx context.Context) (map[string][]models.NAT, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lrList := []nbdb.LogicalRouter{}
+	if err := c.nbClient.List(ctx, &lrList); err != nil {
+		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	natList := []nbdb.NAT{}
+	if err := c.nbClient.List(ctx, &natList); err != nil {
+		return nil, fmt.Errorf("failed to list NAT rules: %w", err)
+	}
+
+	nats := make(map[string]*nbdb.NAT, len(natList))
+	for i := range natList {
+		nats[natList[i].UUID] = &natList[i]
+	}
+
+	result := make(map[string][]models.NAT, len(lrList))
+	for _, lr := range lrList {
+		for _, natUUID := range lr.Nat {
+			if nat, ok := nats[natUUID]; ok {
+				result[lr.UUID] = append(result[lr.UUID], *convertNAT(nat))
+			}
+		}
+	}
+	return result, nil
+}
+
+// convertLogicalRouterPort converts an OVN logical router port to our model
+func convertLogicalRouterPort(ovnLRP *nbdb.LogicalRouterPort) *models.LogicalRouterPort {
+	lrp := &models.LogicalRouterPort{
+		UUID:        ovnLRP.UUID,
+		Name:        ovnLRP.Name,
+		MAC:         ovnLRP.MAC,
+		Networks:    ovnLRP.Networks,
+		Enabled:     ovnLRP.Enabled,
+		Options:     ovnLRP.Options,
+		ExternalIDs: ovnLRP.ExternalIDs,
+		CreatedAt:   parseTime(ovnLRP.ExternalIDs["created_at"]),
+		UpdatedAt:   parseTime(ovnLRP.ExternalIDs["updated_at"]),
+	}
+	if ovnLRP.Peer != nil {
+		lrp.PeerPort = *ovnLRP.Peer
+	}
+	return lrp
+}
@@ -11,26 +11,52 @@ import (
 	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
 )
 
+// logicalSwitchSortFields are the fields logical switches can be sorted by
+var logicalSwitchSortFields = []string{"name", "created_at", "updated_at"}
+
 // ListLogicalSwitches returns all logical switches
 func (c *Client) ListLogicalSwitches(ctx context.Context) ([]*models.LogicalSwitch, error) {
+	switches, _, err := c.ListLogicalSwitchesPage(ctx, nil)
+	return switches, err
+}
+
+// ListLogicalSwitchesPage returns the logical switches matching opts, sorted
+// and paged, along with the number of matches before paging
+func (c *Client) ListLogicalSwitchesPage(ctx context.Context, opts *models.ListOptions) ([]*models.LogicalSwitch, int, error) {
 	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+		return nil, 0, fmt.Errorf("client not connected")
 	}
 
-	// Get all logical switches from database
+	field, desc, err := sortField(opts, logicalSwitchSortFields, "name")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter in the cache so only matching rows are copied
 	lsList := []nbdb.LogicalSwitch{}
-	err := c.nbClient.List(ctx, &lsList)
+	err = c.nbClient.WhereCache(func(ls *nbdb.LogicalSwitch) bool {
+		return opts.Matches(ls.Name, ls.ExternalIDs)
+	}).List(ctx, &lsList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list logical switches: %w", err)
+		return nil, 0, fmt.Errorf("failed to list logical switches: %w", err)
 	}
 
-	// Convert to our model
-	result := make([]*models.LogicalSwitch, 0, len(lsList))
-	for i := range lsList {
+	sortRows(lsList, func(i int) sortValue {
+		switch field {
+		case "created_at", "updated_at":
+			return timeSortValue(lsList[i].ExternalIDs, field)
+		}
+		return sortValue{s: lsList[i].Name}
+	}, func(i int) string { return lsList[i].UUID }, desc)
+
+	// Convert only the requested page to our model
+	start, end := opts.Bounds(len(lsList))
+	result := make([]*models.LogicalSwitch, 0, end-start)
+	for i := start; i < end; i++ {
 		result = append(result, convertLogicalSwitch(&lsList[i]))
 	}
 
-	return result, nil
+	return result, len(lsList), nil
 }
 
 // GetLogicalSwitch returns a specific logical switch by UUID or name
@@ -74,6 +100,7 @@ func (c *Client) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwit
 	}
 	ls.ExternalIDs["created_at"] = now.Format(time.RFC3339)
 	ls.ExternalIDs["updated_at"] = now.Format(time.RFC3339)
+	stampCreated(ctx, ls.ExternalIDs)
 
 	// Create the OVN logical switch
 	ovnLS := &nbdb.LogicalSwitch{
@@ -105,6 +132,7 @@ func (c *Client) CreateLogicalSwitch(ctx context.Context, ls *models.LogicalSwit
 	// Set timestamps
 	ls.CreatedAt = now
 	ls.UpdatedAt = now
+	ls.Ownership = models.OwnershipFromExternalIDs(ls.ExternalIDs)
 
 	return ls, nil
 }
@@ -121,6 +149,12 @@ func (c *Client) UpdateLogicalSwitch(ctx context.Context, id string, updates *mo
 		return nil, err
 	}
 
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalSwitch(ctx, existing.UUID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update timestamp
 	now := time.Now()
 	if updates.ExternalIDs == nil {
@@ -130,6 +164,8 @@ func (c *Client) UpdateLogicalSwitch(ctx context.Context, id string, updates *mo
 		updates.ExternalIDs = make(map[string]string)
 	}
 	updates.ExternalIDs["updated_at"] = now.Format(time.RFC3339)
+	keepCreation(existing.ExternalIDs, updates.ExternalIDs)
+	stampUpdated(ctx, updates.ExternalIDs)
 
 	// Create the OVN logical switch with updates
 	ovnLS := &nbdb.LogicalSwitch{
@@ -157,10 +193,13 @@ func (c *Client) UpdateLogicalSwitch(ctx context.Context, id string, updates *mo
 	}
 
 	// Execute the transaction
-	results, err := c.Transact(ctx, ops...)
+	results, err := c.Transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update logical switch: %w", err)
 	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
 
 	// Check results
 	for _, result := range results {
@@ -185,6 +224,12 @@ func (c *Client) DeleteLogicalSwitch(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalSwitch(ctx, existing.UUID)
+	if err != nil {
+		return err
+	}
+
 	// Create the OVN logical switch for deletion
 	ovnLS := &nbdb.LogicalSwitch{
 		UUID: existing.UUID,
@@ -196,11 +241,23 @@ func (c *Client) DeleteLogicalSwitch(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to create delete operations: %w", err)
 	}
 
+	// On cascade, the switch's dependents go first in the same transaction
+	if cascadeFrom(ctx) {
+		cascadeOps, err := c.cascadeSwitchOps(ctx, existing.UUID)
+		if err != nil {
+			return err
+		}
+		ops = append(cascadeOps, ops...)
+	}
+
 	// Execute the transaction
-	results, err := c.Transact(ctx, ops...)
+	results, err := c.Transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete logical switch: %w", err)
 	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
 
 	// Check results
 	for _, result := range results {
@@ -240,6 +297,7 @@ func convertLogicalSwitch(ovnLS *nbdb.LogicalSwitch) *models.LogicalSwitch {
 			ls.UpdatedAt = t
 		}
 	}
+	ls.Ownership = models.OwnershipFromExternalIDs(ovnLS.ExternalIDs)
 
 	return ls
 }
\ No newline at end of file
@@ -25,41 +25,66 @@ func parseTime(timeStr string) time.Time {
 
 // ListLogicalSwitchPorts returns all logical switch ports for a given switch
 func (c *Client) ListLogicalSwitchPorts(ctx context.Context, switchID string) ([]*models.LogicalSwitchPort, error) {
+	ports, _, err := c.ListLogicalSwitchPortsPage(ctx, switchID, nil)
+	return ports, err
+}
+
+// logicalSwitchPortSortFields are the fields switch ports can be sorted by
+var logicalSwitchPortSortFields = []string{"name", "type", "created_at", "updated_at"}
+
+// ListLogicalSwitchPortsPage returns the ports of a switch matching opts,
+// sorted and paged, along with the number of matches before paging
+func (c *Client) ListLogicalSwitchPortsPage(ctx context.Context, switchID string, opts *models.ListOptions) ([]*models.LogicalSwitchPort, int, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, 0, fmt.Errorf("client not connected")
+	}
+
+	field, desc, err := sortField(opts, logicalSwitchPortSortFields, "name")
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// First get the switch to ensure it exists
 	sw := &nbdb.LogicalSwitch{UUID: switchID}
-	err := c.nbClient.Get(ctx, sw)
+	err = c.nbClient.Get(ctx, sw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get logical switch %s: %w", switchID, err)
+		return nil, 0, fmt.Errorf("failed to get logical switch %s: %w", switchID, err)
+	}
+
+	switchPorts := make(map[string]bool, len(sw.Ports))
+	for _, portUUID := range sw.Ports {
+		switchPorts[portUUID] = true
 	}
 
-	// Get all ports for this switch
+	// Get the matching ports of this switch
 	portList := []nbdb.LogicalSwitchPort{}
 	err = c.nbClient.WhereCache(func(port *nbdb.LogicalSwitchPort) bool {
-		// Check if this port belongs to the switch
-		for _, portUUID := range sw.Ports {
-			if port.UUID == portUUID {
-				return true
-			}
-		}
-		return false
+		return switchPorts[port.UUID] && opts.Matches(port.Name, port.ExternalIDs)
 	}).List(ctx, &portList)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list logical switch ports: %w", err)
+		return nil, 0, fmt.Errorf("failed to list logical switch ports: %w", err)
 	}
 
-	ports := make([]*models.LogicalSwitchPort, len(portList))
-	for i, port := range portList {
-		ports[i] = c.nbdbPortToModel(&port)
+	sortRows(portList, func(i int) sortValue {
+		switch field {
+		case "type":
+			return sortValue{s: portList[i].Type}
+		case "created_at", "updated_at":
+			return timeSortValue(portList[i].ExternalIDs, field)
+		}
+		return sortValue{s: portList[i].Name}
+	}, func(i int) string { return portList[i].UUID }, desc)
+
+	start, end := opts.Bounds(len(portList))
+	ports := make([]*models.LogicalSwitchPort, 0, end-start)
+	for i := start; i < end; i++ {
+		ports = append(ports, c.nbdbPortToModel(&portList[i]))
 	}
 
-	return ports, nil
+	return ports, len(portList), nil
 }
 
 // GetLogicalSwitchPort returns a specific logical switch port by ID
@@ -113,36 +138,8 @@ func (c *Client) CreateLogicalSwitchPort(ctx context.Context, switchID string, p
 	portUUID := uuid.New().String()
 	now := time.Now().Format(time.RFC3339)
 	
-	nbdbPort := &nbdb.LogicalSwitchPort{
-		UUID:         portUUID,
-		Name:         port.Name,
-		Addresses:    port.Addresses,
-		PortSecurity: port.PortSecurity,
-		Type:         port.Type,
-		Options:      port.Options,
-		ExternalIDs: map[string]string{
-			"created_at": now,
-			"updated_at": now,
-		},
-	}
-
-	// Set optional fields
-	if port.Enabled != nil {
-		nbdbPort.Enabled = port.Enabled
-	}
-	if port.Tag > 0 {
-		nbdbPort.Tag = &port.Tag
-	}
-	if port.ParentName != "" {
-		nbdbPort.ParentName = &port.ParentName
-	}
-
-	// Copy additional external IDs
-	for k, v := range port.ExternalIDs {
-		if k != "created_at" && k != "updated_at" {
-			nbdbPort.ExternalIDs[k] = v
-		}
-	}
+	nbdbPort := newNBDBPort(portUUID, port, now)
+	stampCreated(ctx, nbdbPort.ExternalIDs)
 
 	// Start transaction
 	ops := []ovsdb.Operation{}
@@ -163,7 +160,7 @@ func (c *Client) CreateLogicalSwitchPort(ctx context.Context, switchID string, p
 	ops = append(ops, updateOp...)
 
 	// Execute transaction
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, ops...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute transaction: %w", err)
 	}
@@ -177,6 +174,7 @@ func (c *Client) CreateLogicalSwitchPort(ctx context.Context, switchID string, p
 	port.SwitchID = switchID
 	port.CreatedAt = parseTime(now)
 	port.UpdatedAt = parseTime(now)
+	port.Ownership = models.OwnershipFromExternalIDs(nbdbPort.ExternalIDs)
 
 	return port, nil
 }
@@ -197,41 +195,14 @@ func (c *Client) UpdateLogicalSwitchPort(ctx context.Context, id string, port *m
 		return nil, fmt.Errorf("logical switch port %s not found", id)
 	}
 
-	// Update fields if provided
-	if port.Name != "" && port.Name != existing.Name {
-		existing.Name = port.Name
-	}
-	if len(port.Addresses) > 0 {
-		existing.Addresses = port.Addresses
-	}
-	if len(port.PortSecurity) > 0 {
-		existing.PortSecurity = port.PortSecurity
-	}
-	if port.Type != "" {
-		existing.Type = port.Type
-	}
-	if port.Options != nil {
-		existing.Options = port.Options
-	}
-	if port.Enabled != nil {
-		existing.Enabled = port.Enabled
-	}
-	if port.Tag > 0 {
-		existing.Tag = &port.Tag
-	}
-
-	// Update timestamp
-	if existing.ExternalIDs == nil {
-		existing.ExternalIDs = make(map[string]string)
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalSwitchPort(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	existing.ExternalIDs["updated_at"] = time.Now().Format(time.RFC3339)
 
-	// Copy additional external IDs
-	for k, v := range port.ExternalIDs {
-		if k != "created_at" && k != "updated_at" {
-			existing.ExternalIDs[k] = v
-		}
-	}
+	applyPortUpdates(existing, port)
+	stampUpdated(ctx, existing.ExternalIDs)
 
 	// Update the port
 	ops, err := c.nbClient.Where(existing).Update(existing)
@@ -239,10 +210,13 @@ func (c *Client) UpdateLogicalSwitchPort(ctx context.Context, id string, port *m
 		return nil, fmt.Errorf("failed to create update operation: %w", err)
 	}
 
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update port: %w", err)
 	}
+	if err := guardResult(result, guard); err != nil {
+		return nil, err
+	}
 
 	if len(result) > 0 && result[0].Error != "" {
 		return nil, fmt.Errorf("update failed: %s", result[0].Error)
@@ -267,6 +241,12 @@ func (c *Client) DeleteLogicalSwitchPort(ctx context.Context, id string) error {
 		return fmt.Errorf("logical switch port %s not found", id)
 	}
 
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalSwitchPort(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	// Find the switch that contains this port
 	switches := []nbdb.LogicalSwitch{}
 	err = c.nbClient.WhereCache(func(sw *nbdb.LogicalSwitch) bool {
@@ -313,10 +293,13 @@ func (c *Client) DeleteLogicalSwitchPort(ctx context.Context, id string) error {
 	ops = append(ops, deleteOp...)
 
 	// Execute transaction
-	result, err := c.nbClient.Transact(ctx, ops...)
+	result, err := c.transact(ctx, append(guard, ops...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete port: %w", err)
 	}
+	if err := guardResult(result, guard); err != nil {
+		return err
+	}
 
 	if len(result) > 0 && result[0].Error != "" {
 		return fmt.Errorf("delete failed: %s", result[0].Error)
@@ -325,6 +308,81 @@ func (c *Client) DeleteLogicalSwitchPort(ctx context.Context, id string) error {
 	return nil
 }
 
+// newNBDBPort builds the row for a new port created at now
+func newNBDBPort(portUUID string, port *models.LogicalSwitchPort, now string) *nbdb.LogicalSwitchPort {
+	nbdbPort := &nbdb.LogicalSwitchPort{
+		UUID:         portUUID,
+		Name:         port.Name,
+		Addresses:    port.Addresses,
+		PortSecurity: port.PortSecurity,
+		Type:         port.Type,
+		Options:      port.Options,
+		ExternalIDs: map[string]string{
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+
+	// Set optional fields
+	if port.Enabled != nil {
+		nbdbPort.Enabled = port.Enabled
+	}
+	if port.Tag > 0 {
+		nbdbPort.Tag = &port.Tag
+	}
+	if port.ParentName != "" {
+		nbdbPort.ParentName = &port.ParentName
+	}
+
+	// Copy additional external IDs
+	for k, v := range port.ExternalIDs {
+		if k != "created_at" && k != "updated_at" {
+			nbdbPort.ExternalIDs[k] = v
+		}
+	}
+
+	return nbdbPort
+}
+
+// applyPortUpdates copies the fields set in port to existing
+func applyPortUpdates(existing *nbdb.LogicalSwitchPort, port *models.LogicalSwitchPort) {
+	// Update fields if provided
+	if port.Name != "" && port.Name != existing.Name {
+		existing.Name = port.Name
+	}
+	if len(port.Addresses) > 0 {
+		existing.Addresses = port.Addresses
+	}
+	if len(port.PortSecurity) > 0 {
+		existing.PortSecurity = port.PortSecurity
+	}
+	if port.Type != "" {
+		existing.Type = port.Type
+	}
+	if port.Options != nil {
+		existing.Options = port.Options
+	}
+	if port.Enabled != nil {
+		existing.Enabled = port.Enabled
+	}
+	if port.Tag > 0 {
+		existing.Tag = &port.Tag
+	}
+
+	// Update timestamp
+	if existing.ExternalIDs == nil {
+		existing.ExternalIDs = make(map[string]string)
+	}
+	existing.ExternalIDs["updated_at"] = time.Now().Format(time.RFC3339)
+
+	// Copy additional external IDs
+	for k, v := range port.ExternalIDs {
+		if k != "created_at" && k != "updated_at" {
+			existing.ExternalIDs[k] = v
+		}
+	}
+}
+
 // nbdbPortToModel converts an nbdb.LogicalSwitchPort to a models.LogicalSwitchPort
 func (c *Client) nbdbPortToModel(port *nbdb.LogicalSwitchPort) *models.LogicalSwitchPort {
 	m := &models.LogicalSwitchPort{
@@ -358,6 +416,7 @@ func (c *Client) nbdbPortToModel(port *nbdb.LogicalSwitchPort) *models.LogicalSw
 	if updated, ok := port.ExternalIDs["updated_at"]; ok {
 		m.UpdatedAt = parseTime(updated)
 	}
+	m.Ownership = models.OwnershipFromExternalIDs(port.ExternalIDs)
 
 	return m
 }
\ No newline at end of file
@@ -0,0 +1,195 @@
+package ovn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// matchToken is a lexical element of an OVN match expression
+type matchToken struct {
+	kind   string // "word", "string", or the operator or punctuation itself
+	text   string
+	offset int
+}
+
+// matchOperators are the operators of OVN match expressions, longest first
+var matchOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "{", "}", ","}
+
+// ValidateMatch checks the syntax of an OVN match expression, such as
+// "ip4.src == 10.0.0.0/24 && tcp.dst == {80, 443}". Fields and constants
+// aren't checked against the ones OVN knows; ovn-northd does that.
+func ValidateMatch(match string) error {
+	if strings.TrimSpace(match) == "" {
+		return fmt.Errorf("match expression is required")
+	}
+
+	tokens, err := tokenizeMatch(match)
+	if err != nil {
+		return fmt.Errorf("invalid match expression: %w", err)
+	}
+	p := &matchParser{tokens: tokens}
+	if err := p.expression(); err != nil {
+		return fmt.Errorf("invalid match expression: %w", err)
+	}
+	if tok := p.peek(); tok != nil {
+		return fmt.Errorf("invalid match expression: unexpected %q at offset %d", tok.text, tok.offset)
+	}
+	return nil
+}
+
+// tokenizeMatch splits a match expression into words, quoted strings and
+// operators
+func tokenizeMatch(match string) ([]matchToken, error) {
+	var tokens []matchToken
+	for i := 0; i < len(match); {
+		ch := match[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+		case ch == '"':
+			end := strings.IndexByte(match[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, matchToken{kind: "string", text: match[i : i+end+2], offset: i})
+			i += end + 2
+		default:
+			op := ""
+			for _, candidate := range matchOperators {
+				if strings.HasPrefix(match[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op != "" {
+				tokens = append(tokens, matchToken{kind: op, text: op, offset: i})
+				i += len(op)
+				continue
+			}
+			if ch == '&' || ch == '|' || ch == '=' {
+				return nil, fmt.Errorf("unexpected %q at offset %d", string(ch), i)
+			}
+
+			start := i
+			for i < len(match) && !strings.ContainsRune(" \t\n\"=!<>&|(){},", rune(match[i])) {
+				i++
+			}
+			tokens = append(tokens, matchToken{kind: "word", text: match[start:i], offset: start})
+		}
+	}
+	return tokens, nil
+}
+
+// matchParser checks tokens against the grammar of match expressions:
+//
+//	expression := conjunction { "||" conjunction }
+//	conjunction := negation { "&&" negation }
+//	negation := "!" negation | "(" expression ")" | comparison
+//	comparison := word [ relop value ]
+//	value := constant | "{" constant { "," constant } "}"
+type matchParser struct {
+	tokens []matchToken
+	pos    int
+}
+
+func (p *matchParser) peek() *matchToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// accept consumes the next token if it is of kind
+func (p *matchParser) accept(kind string) bool {
+	if tok := p.peek(); tok != nil && tok.kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// unexpected describes the next token, or the end of the expression
+func (p *matchParser) unexpected(want string) error {
+	tok := p.peek()
+	if tok == nil {
+		return fmt.Errorf("expected %s at end of expression", want)
+	}
+	return fmt.Errorf("expected %s, got %q at offset %d", want, tok.text, tok.offset)
+}
+
+func (p *matchParser) expression() error {
+	if err := p.conjunction(); err != nil {
+		return err
+	}
+	for p.accept("||") {
+		if err := p.conjunction(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *matchParser) conjunction() error {
+	if err := p.negation(); err != nil {
+		return err
+	}
+	for p.accept("&&") {
+		if err := p.negation(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *matchParser) negation() error {
+	switch {
+	case p.accept("!"):
+		return p.negation()
+	case p.accept("("):
+		if err := p.expression(); err != nil {
+			return err
+		}
+		if !p.accept(")") {
+			return p.unexpected(`")"`)
+		}
+		return nil
+	}
+	return p.comparison()
+}
+
+func (p *matchParser) comparison() error {
+	if !p.accept("word") {
+		return p.unexpected("a field")
+	}
+	for _, relop := range []string{"==", "!=", "<", "<=", ">", ">="} {
+		if p.accept(relop) {
+			return p.value()
+		}
+	}
+	// A bare field, such as "ip4" or "tcp", tests for the protocol
+	return nil
+}
+
+func (p *matchParser) value() error {
+	if !p.accept("{") {
+		return p.constant()
+	}
+	for {
+		if err := p.constant(); err != nil {
+			return err
+		}
+		if p.accept("}") {
+			return nil
+		}
+		if !p.accept(",") {
+			return p.unexpected(`"," or "}"`)
+		}
+	}
+}
+
+func (p *matchParser) constant() error {
+	if p.accept("word") || p.accept("string") {
+		return nil
+	}
+	return p.unexpected("a constant")
+}
@@ -0,0 +1,356 @@
+package ovn
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrMatchUnknown is returned when a match depends on a field the packet
+// doesn't determine, such as a register or an ICMP type
+var ErrMatchUnknown = errors.New("match can't be evaluated")
+
+// MatchPacket is a packet a match expression is evaluated against: the
+// first IP packet of a connection, so ct.new is true. Fields left zero are
+// unknown.
+type MatchPacket struct {
+	InPort   string // Name of the port the packet enters the switch from
+	OutPort  string // Name of the port the packet leaves the switch by
+	EthSrc   string
+	EthDst   string
+	SrcIP    net.IP
+	DstIP    net.IP
+	Protocol string // tcp, udp, sctp or icmp; empty for other IP traffic
+	SrcPort  int
+	DstPort  int
+}
+
+// MatchSets resolves the address sets ($name) and port groups (@name) of
+// match expressions
+type MatchSets struct {
+	AddressSets map[string][]string // Addresses or CIDRs, by name
+	PortGroups  map[string][]string // Port names, by name
+}
+
+// tri is the value of a match expression over a packet that may not
+// determine it
+type tri int
+
+const (
+	triFalse tri = iota
+	triTrue
+	triUnknown
+)
+
+func triOf(b bool) tri {
+	if b {
+		return triTrue
+	}
+	return triFalse
+}
+
+// protocolNumbers are the IP protocol numbers of MatchPacket protocols
+var protocolNumbers = map[string]int{"tcp": 6, "udp": 17, "sctp": 132}
+
+// EvaluateMatch reports whether packet matches an OVN match expression.
+// Matches depending on fields the packet doesn't determine return an error
+// wrapping ErrMatchUnknown, unless the rest of the expression decides them.
+func EvaluateMatch(match string, packet *MatchPacket, sets *MatchSets) (bool, error) {
+	if err := ValidateMatch(match); err != nil {
+		return false, err
+	}
+	tokens, err := tokenizeMatch(match)
+	if err != nil {
+		return false, err
+	}
+	if sets == nil {
+		sets = &MatchSets{}
+	}
+
+	e := &matchEvaluator{matchParser: matchParser{tokens: tokens}, packet: packet, sets: sets}
+	switch e.expression() {
+	case triTrue:
+		return true, nil
+	case triFalse:
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %s is unknown", ErrMatchUnknown, e.unknown)
+}
+
+// matchEvaluator evaluates a valid match expression over a packet, following
+// the grammar of matchParser
+type matchEvaluator struct {
+	matchParser
+	packet  *MatchPacket
+	sets    *MatchSets
+	unknown string // The first field that couldn't be evaluated
+}
+
+func (e *matchEvaluator) expression() tri {
+	result := e.conjunction()
+	for e.accept("||") {
+		switch next := e.conjunction(); {
+		case result == triTrue || next == triTrue:
+			result = triTrue
+		case result == triUnknown || next == triUnknown:
+			result = triUnknown
+		}
+	}
+	return result
+}
+
+func (e *matchEvaluator) conjunction() tri {
+	result := e.negation()
+	for e.accept("&&") {
+		switch next := e.negation(); {
+		case result == triFalse || next == triFalse:
+			result = triFalse
+		case result == triUnknown || next == triUnknown:
+			result = triUnknown
+		}
+	}
+	return result
+}
+
+func (e *matchEvaluator) negation() tri {
+	switch {
+	case e.accept("!"):
+		switch e.negation() {
+		case triTrue:
+			return triFalse
+		case triFalse:
+			return triTrue
+		}
+		return triUnknown
+	case e.accept("("):
+		result := e.expression()
+		e.accept(")")
+		return result
+	}
+
+	field := e.peek().text
+	e.pos++
+	for _, relop := range []string{"==", "!=", "<", "<=", ">", ">="} {
+		if e.accept(relop) {
+			return e.unknownAs(field, e.compare(field, relop, e.values()))
+		}
+	}
+	return e.unknownAs(field, e.protocol(field))
+}
+
+// unknownAs records field as the reason for an unknown result
+func (e *matchEvaluator) unknownAs(field string, result tri) tri {
+	if result == triUnknown && e.unknown == "" {
+		e.unknown = field
+	}
+	return result
+}
+
+// values consumes a constant or set of constants
+func (e *matchEvaluator) values() []string {
+	if !e.accept("{") {
+		value := e.peek().text
+		e.pos++
+		return []string{value}
+	}
+	var values []string
+	for !e.accept("}") {
+		values = append(values, e.peek().text)
+		e.pos++
+		e.accept(",")
+	}
+	return values
+}
+
+// protocol evaluates a bare field, which tests for a protocol or
+// connection tracking state
+func (e *matchEvaluator) protocol(name string) tri {
+	p := e.packet
+	v4 := p.SrcIP.To4() != nil || p.DstIP.To4() != nil
+	v6 := !v4 && (p.SrcIP != nil || p.DstIP != nil)
+	family := func(v4Wanted bool) tri {
+		if !v4 && !v6 {
+			return triUnknown
+		}
+		return triOf(v4 == v4Wanted)
+	}
+
+	switch name {
+	case "1", "ip", "eth", "ct.trk", "ct.new":
+		return triTrue
+	case "0", "arp", "rarp", "lldp", "vlan.present", "ct.est", "ct.rel", "ct.rpl", "ct.inv":
+		return triFalse
+	case "ip4":
+		return family(true)
+	case "ip6":
+		return family(false)
+	case "tcp", "udp", "sctp", "icmp":
+		if p.Protocol == "" {
+			return triUnknown
+		}
+		return triOf(p.Protocol == name)
+	case "icmp4", "icmp6":
+		if p.Protocol != "icmp" {
+			return e.protocol("icmp")
+		}
+		return family(name == "icmp4")
+	case "nd", "nd_ns", "nd_na", "nd_rs", "nd_ra", "mldv1", "mldv2":
+		// ICMPv6 messages, whose type isn't known
+		if e.protocol("icmp6") == triFalse {
+			return triFalse
+		}
+		return triUnknown
+	}
+	return triUnknown
+}
+
+// compare evaluates a comparison of field with values: == holds if the
+// field equals any of them, != if it equals none
+func (e *matchEvaluator) compare(field, relop string, values []string) tri {
+	// A field of a protocol the packet isn't never matches, whatever the
+	// relation
+	if prefix, _, ok := strings.Cut(field, "."); ok && prefix != "ct" && prefix != "eth" {
+		if prerequisite := e.protocol(prefix); prerequisite != triTrue {
+			return prerequisite
+		}
+	}
+
+	p := e.packet
+	var equal func(value string) tri
+	var number int
+	switch field {
+	case "inport", "outport":
+		port := p.InPort
+		if field == "outport" {
+			port = p.OutPort
+		}
+		if port == "" {
+			return triUnknown
+		}
+		equal = func(value string) tri {
+			if strings.HasPrefix(value, "@") {
+				members, ok := e.sets.PortGroups[value[1:]]
+				if !ok {
+					return triUnknown
+				}
+				for _, member := range members {
+					if member == port {
+						return triTrue
+					}
+				}
+				return triFalse
+			}
+			return triOf(strings.Trim(value, `"`) == port)
+		}
+	case "eth.src", "eth.dst":
+		mac := p.EthSrc
+		if field == "eth.dst" {
+			mac = p.EthDst
+		}
+		if mac == "" {
+			return triUnknown
+		}
+		equal = func(value string) tri { return triOf(strings.EqualFold(value, mac)) }
+	case "ip4.src", "ip4.dst", "ip6.src", "ip6.dst":
+		ip := p.SrcIP
+		if strings.HasSuffix(field, ".dst") {
+			ip = p.DstIP
+		}
+		if ip == nil {
+			return triUnknown
+		}
+		equal = func(value string) tri {
+			if strings.HasPrefix(value, "$") {
+				addresses, ok := e.sets.AddressSets[value[1:]]
+				if !ok {
+					return triUnknown
+				}
+				for _, address := range addresses {
+					if containsIP(address, ip) == triTrue {
+						return triTrue
+					}
+				}
+				return triFalse
+			}
+			return containsIP(value, ip)
+		}
+	case "tcp.src", "udp.src", "sctp.src":
+		number = p.SrcPort
+	case "tcp.dst", "udp.dst", "sctp.dst":
+		number = p.DstPort
+	case "ip.proto":
+		switch {
+		case p.Protocol == "icmp" && p.SrcIP.To4() == nil && p.DstIP.To4() == nil:
+			number = 58
+		case p.Protocol == "icmp":
+			number = 1
+		default:
+			number = protocolNumbers[p.Protocol]
+		}
+	default:
+		return triUnknown
+	}
+
+	if equal == nil {
+		if number == 0 {
+			return triUnknown
+		}
+		equal = func(value string) tri {
+			n, err := strconv.ParseInt(value, 0, 64)
+			if err != nil {
+				return triUnknown
+			}
+			switch relop {
+			case "<":
+				return triOf(number < int(n))
+			case "<=":
+				return triOf(number <= int(n))
+			case ">":
+				return triOf(number > int(n))
+			case ">=":
+				return triOf(number >= int(n))
+			}
+			return triOf(number == int(n))
+		}
+	} else if relop != "==" && relop != "!=" {
+		return triUnknown
+	}
+
+	result := triFalse
+	for _, value := range values {
+		switch equal(value) {
+		case triTrue:
+			result = triTrue
+		case triUnknown:
+			if result == triFalse {
+				result = triUnknown
+			}
+		}
+		if result == triTrue {
+			break
+		}
+	}
+	if relop == "!=" {
+		switch result {
+		case triTrue:
+			return triFalse
+		case triFalse:
+			return triTrue
+		}
+	}
+	return result
+}
+
+// containsIP reports whether an address or CIDR covers ip
+func containsIP(address string, ip net.IP) tri {
+	if _, network, err := net.ParseCIDR(address); err == nil {
+		return triOf(network.Contains(ip))
+	}
+	if parsed := net.ParseIP(address); parsed != nil {
+		return triOf(parsed.Equal(ip))
+	}
+	// e.g. a netmask rather than a prefix length
+	return triUnknown
+}
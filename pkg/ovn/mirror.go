@@ -0,0 +1,382 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// maxMirrorIndex is the largest tunnel key of a GRE mirror; ERSPAN session
+// IDs are 10 bits and checked separately
+const maxMirrorIndex = 1<<32 - 1
+
+// maxERSPANSessionID is the largest ERSPAN session ID
+const maxERSPANSessionID = 1<<10 - 1
+
+// ListMirrors returns all mirrors along with the ports they mirror
+func (c *Client) ListMirrors(ctx context.Context) ([]*models.Mirror, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	mirrors := []nbdb.Mirror{}
+	if err := c.nbClient.List(ctx, &mirrors); err != nil {
+		return nil, fmt.Errorf("failed to list mirrors: %w", err)
+	}
+	sources, err := c.mirrorPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.Mirror, 0, len(mirrors))
+	for i := range mirrors {
+		result = append(result, convertMirror(&mirrors[i], sources[mirrors[i].UUID]))
+	}
+	return result, nil
+}
+
+// GetMirror returns a mirror by UUID or name
+func (c *Client) GetMirror(ctx context.Context, id string) (*models.Mirror, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row, err := c.mirrorRow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.mirrorPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertMirror(row, sources[row.UUID]), nil
+}
+
+// CreateMirror creates a mirror and attaches it to its source ports
+func (c *Client) CreateMirror(ctx context.Context, mirror *models.Mirror) (*models.Mirror, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	if err := validateMirror(mirror); err != nil {
+		return nil, err
+	}
+	existing := []nbdb.Mirror{}
+	err := c.nbClient.WhereCache(func(m *nbdb.Mirror) bool {
+		return m.Name == mirror.Name
+	}).List(ctx, &existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing mirrors: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("mirror %s already exists", mirror.Name)
+	}
+	if err := c.checkPortsExist(ctx, mirror.Ports); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	externalIDs := make(map[string]string, len(mirror.ExternalIDs)+2)
+	for k, v := range mirror.ExternalIDs {
+		externalIDs[k] = v
+	}
+	externalIDs["created_at"] = now.Format(time.RFC3339)
+	externalIDs["updated_at"] = now.Format(time.RFC3339)
+
+	row := &nbdb.Mirror{
+		UUID:        uuid.New().String(),
+		Name:        mirror.Name,
+		Type:        mirror.Type,
+		Filter:      mirror.Filter,
+		Sink:        mirror.Sink,
+		Index:       mirror.Index,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror operations: %w", err)
+	}
+	attachOps, err := c.attachMirror(row.UUID, mirror.Ports, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.Transact(ctx, append(ops, attachOps...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mirror: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertMirror(row, mirror.Ports), nil
+}
+
+// UpdateMirror updates a mirror. Its type, filter, sink and index are
+// changed when given, the index being cleared for local and lport mirrors,
+// and ports given replace the ports it mirrors. The name can't be changed.
+func (c *Client) UpdateMirror(ctx context.Context, id string, updates *models.Mirror) (*models.Mirror, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetMirror(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardMirror(ctx, existing.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if updates.Type != "" {
+		merged.Type = updates.Type
+	}
+	if updates.Filter != "" {
+		merged.Filter = updates.Filter
+	}
+	if updates.Sink != "" {
+		merged.Sink = updates.Sink
+	}
+	if updates.Index != 0 {
+		merged.Index = updates.Index
+	}
+	if merged.Type == nbdb.MirrorTypeLocal || merged.Type == nbdb.MirrorTypeLport {
+		merged.Index = 0
+	}
+	if err := validateMirror(&merged); err != nil {
+		return nil, err
+	}
+
+	row := &nbdb.Mirror{
+		UUID:        existing.UUID,
+		Type:        merged.Type,
+		Filter:      merged.Filter,
+		Sink:        merged.Sink,
+		Index:       merged.Index,
+		ExternalIDs: updatedExternalIDs(existing.ExternalIDs, updates.ExternalIDs, time.Now()),
+	}
+	ops, err := c.nbClient.Where(row).Update(row, &row.Type, &row.Filter, &row.Sink, &row.Index, &row.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+	if updates.Ports != nil {
+		if err := c.checkPortsExist(ctx, updates.Ports); err != nil {
+			return nil, err
+		}
+		attachOps, err := c.attachMirror(existing.UUID, updates.Ports, existing.Ports)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, attachOps...)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update mirror: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetMirror(ctx, existing.UUID)
+}
+
+// DeleteMirror deletes a mirror. The ports' references to it are weak, so
+// OVSDB detaches it from them.
+func (c *Client) DeleteMirror(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	row, err := c.mirrorRow(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardMirror(ctx, row.UUID)
+	if err != nil {
+		return err
+	}
+
+	ops, err := c.nbClient.Where(&nbdb.Mirror{UUID: row.UUID}).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete mirror: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// mirrorRow returns a mirror row by UUID or name
+func (c *Client) mirrorRow(ctx context.Context, id string) (*nbdb.Mirror, error) {
+	row := &nbdb.Mirror{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err == nil {
+		return row, nil
+	}
+
+	mirrors := []nbdb.Mirror{}
+	err := c.nbClient.WhereCache(func(m *nbdb.Mirror) bool {
+		return m.Name == id
+	}).List(ctx, &mirrors)
+	if err != nil || len(mirrors) == 0 {
+		return nil, fmt.Errorf("mirror %s not found", id)
+	}
+	return &mirrors[0], nil
+}
+
+// mirrorPorts returns the ports each mirror mirrors, keyed by mirror UUID
+func (c *Client) mirrorPorts(ctx context.Context) (map[string][]string, error) {
+	ports := []nbdb.LogicalSwitchPort{}
+	if err := c.nbClient.List(ctx, &ports); err != nil {
+		return nil, fmt.Errorf("failed to list logical switch ports: %w", err)
+	}
+
+	sources := make(map[string][]string)
+	for _, port := range ports {
+		for _, mirrorUUID := range port.MirrorRules {
+			sources[mirrorUUID] = append(sources[mirrorUUID], port.UUID)
+		}
+	}
+	return sources, nil
+}
+
+// checkPortsExist returns an error naming the first port that doesn't exist
+func (c *Client) checkPortsExist(ctx context.Context, portIDs []string) error {
+	for _, portID := range portIDs {
+		if err := c.nbClient.Get(ctx, &nbdb.LogicalSwitchPort{UUID: portID}); err != nil {
+			return fmt.Errorf("logical switch port %s not found", portID)
+		}
+	}
+	return nil
+}
+
+// attachMirror returns the operations attaching a mirror to the ports it
+// should mirror and detaching it from the others it does
+func (c *Client) attachMirror(mirrorUUID string, want, current []string) ([]ovsdb.Operation, error) {
+	wanted := make(map[string]bool, len(want))
+	for _, portID := range want {
+		wanted[portID] = true
+	}
+	attached := make(map[string]bool, len(current))
+	for _, portID := range current {
+		attached[portID] = true
+	}
+
+	var ops []ovsdb.Operation
+	mutate := func(portID string, mutator ovsdb.Mutator) error {
+		port := &nbdb.LogicalSwitchPort{UUID: portID}
+		mutateOps, err := c.nbClient.Where(port).Mutate(port, model.Mutation{
+			Field:   &port.MirrorRules,
+			Mutator: mutator,
+			Value:   []string{mirrorUUID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create port mutate operations: %w", err)
+		}
+		ops = append(ops, mutateOps...)
+		return nil
+	}
+	for _, portID := range want {
+		if !attached[portID] {
+			attached[portID] = true
+			if err := mutate(portID, ovsdb.MutateOperationInsert); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, portID := range current {
+		if !wanted[portID] {
+			if err := mutate(portID, ovsdb.MutateOperationDelete); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ops, nil
+}
+
+// validateMirror checks a mirror is one OVN can set up: GRE and ERSPAN
+// mirrors tunnel to a remote IP, with the index as GRE key or ERSPAN session
+// ID, local mirrors copy to an OVS interface and lport mirrors to a logical
+// port
+func validateMirror(mirror *models.Mirror) error {
+	if mirror.Name == "" {
+		return fmt.Errorf("invalid mirror: name is required")
+	}
+	switch mirror.Filter {
+	case nbdb.MirrorFilterFromLport, nbdb.MirrorFilterToLport, nbdb.MirrorFilterBoth:
+	default:
+		return fmt.Errorf("invalid mirror: filter must be from-lport, to-lport or both")
+	}
+	if mirror.Sink == "" {
+		return fmt.Errorf("invalid mirror: sink is required")
+	}
+
+	switch mirror.Type {
+	case nbdb.MirrorTypeGre, nbdb.MirrorTypeErspan:
+		if net.ParseIP(mirror.Sink) == nil {
+			return fmt.Errorf("invalid mirror: sink of a %s mirror must be an IP address", mirror.Type)
+		}
+		if mirror.Index < 0 || int64(mirror.Index) > maxMirrorIndex {
+			return fmt.Errorf("invalid mirror: index must be between 0 and %d", maxMirrorIndex)
+		}
+		if mirror.Type == nbdb.MirrorTypeErspan && mirror.Index > maxERSPANSessionID {
+			return fmt.Errorf("invalid mirror: ERSPAN session ID must be between 0 and %d", maxERSPANSessionID)
+		}
+	case nbdb.MirrorTypeLocal, nbdb.MirrorTypeLport:
+		if mirror.Index != 0 {
+			return fmt.Errorf("invalid mirror: index only applies to gre and erspan mirrors")
+		}
+	default:
+		return fmt.Errorf("invalid mirror: type must be gre, erspan, local or lport")
+	}
+	return nil
+}
+
+// convertMirror converts an OVN Mirror row to our model
+func convertMirror(row *nbdb.Mirror, ports []string) *models.Mirror {
+	return &models.Mirror{
+		UUID:        row.UUID,
+		Name:        row.Name,
+		Type:        row.Type,
+		Filter:      row.Filter,
+		Sink:        row.Sink,
+		Index:       row.Index,
+		Ports:       ports,
+		Active:      len(ports) > 0,
+		ExternalIDs: row.ExternalIDs,
+		CreatedAt:   parseTime(row.ExternalIDs["created_at"]),
+		UpdatedAt:   parseTime(row.ExternalIDs["updated_at"]),
+	}
+}
@@ -1,56 +1,85 @@
-// +build ignore
+//go:build ignore
 
+// Command modelgen fetches the OVN Northbound schema the nbdb models are
+// generated from. It runs from pkg/ovn as the first step of go generate:
+//
+//	go run modelgen/main.go [-update] [-url URL]
+//
+// The schema is only downloaded when it's missing, so generating the models
+// doesn't need the network; -update replaces it with the one at -url.
 package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
+const defaultSchemaURL = "https://raw.githubusercontent.com/ovn-org/ovn/main/ovn-nb.ovsschema"
+
 func main() {
-	// This is a helper to download OVN schema if needed
-	schemaPath := "../schema/ovn-nb.ovsschema"
-	
-	// Check if schema exists
-	if _, err := os.Stat(schemaPath); os.IsNotExist(err) {
-		fmt.Println("OVN schema not found, downloading...")
-		
-		// Create schema directory
-		schemaDir := filepath.Dir(schemaPath)
-		if err := os.MkdirAll(schemaDir, 0755); err != nil {
-			log.Fatal("Failed to create schema directory:", err)
-		}
-		
-		// Download schema from OVN repository
-		cmd := exec.Command("curl", "-L", "-o", schemaPath,
-			"https://raw.githubusercontent.com/ovn-org/ovn/main/ovn-nb.ovsschema")
-		
-		if output, err := cmd.CombinedOutput(); err != nil {
-			log.Fatalf("Failed to download schema: %v\nOutput: %s", err, output)
+	schemaPath := flag.String("schema", "schema/ovn-nb.ovsschema", "path of the schema file")
+	url := flag.String("url", defaultSchemaURL, "URL to download the schema from")
+	update := flag.Bool("update", false, "download the schema even if it exists")
+	flag.Parse()
+
+	if _, err := os.Stat(*schemaPath); *update || os.IsNotExist(err) {
+		fmt.Printf("Downloading OVN schema from %s...\n", *url)
+		if err := download(*url, *schemaPath); err != nil {
+			log.Fatal("Failed to download schema: ", err)
 		}
-		
-		fmt.Println("Schema downloaded successfully")
 	}
-	
+
 	// Verify schema is valid JSON
-	data, err := os.ReadFile(schemaPath)
+	data, err := os.ReadFile(*schemaPath)
 	if err != nil {
-		log.Fatal("Failed to read schema:", err)
+		log.Fatal("Failed to read schema: ", err)
+	}
+	var schema struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
 	}
-	
-	var schema map[string]interface{}
 	if err := json.Unmarshal(data, &schema); err != nil {
-		log.Fatal("Invalid schema JSON:", err)
-	}
-	
-	fmt.Printf("Schema loaded successfully. Version: %v\n", schema["version"])
-	fmt.Println("\nTo generate models, run:")
-	fmt.Println("go run github.com/ovn-org/libovsdb/cmd/modelgen \\")
-	fmt.Println("  -p nbdb \\")
-	fmt.Println("  -o ../nbdb \\")
-	fmt.Println("  ../schema/ovn-nb.ovsschema")
-}
\ No newline at end of file
+		log.Fatal("Invalid schema JSON: ", err)
+	}
+	if schema.Name != "OVN_Northbound" {
+		log.Fatalf("%s is the schema of %q, not OVN_Northbound", *schemaPath, schema.Name)
+	}
+
+	fmt.Printf("OVN_Northbound schema %s\n", schema.Version)
+}
+
+// download writes the file at url to path, leaving path untouched when it
+// fails
+func download(url, path string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ovn-nb-*.ovsschema")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,176 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// ovsInterface is the part of an Open_vSwitch Interface row traffic is read
+// from
+type ovsInterface struct {
+	UUID        string            `ovsdb:"_uuid"`
+	Name        string            `ovsdb:"name"`
+	ExternalIDs map[string]string `ovsdb:"external_ids"`
+	Statistics  map[string]int    `ovsdb:"statistics"`
+}
+
+// ifaceIDKey is the external ID binding an OVS interface to a logical port
+const ifaceIDKey = "iface-id"
+
+// InterfaceStats are the counters of an OVS interface bound to a logical
+// switch port. They are seen from the switch: Rx is traffic the port sent.
+type InterfaceStats struct {
+	LogicalPort string
+	Interface   string
+	Chassis     string // OVSDB endpoint the interface was read from
+	RxBytes     uint64
+	TxBytes     uint64
+	RxPackets   uint64
+	TxPackets   uint64
+}
+
+// ovsDatabaseModel returns the Open_vSwitch database model, limited to the
+// Interface table
+func ovsDatabaseModel() model.ClientDBModel {
+	dbModel, _ := model.NewClientDBModel("Open_vSwitch", map[string]model.Model{
+		"Interface": &ovsInterface{},
+	})
+	return dbModel
+}
+
+// OVSStatsClient reads interface statistics from the Open_vSwitch database
+// of each chassis. Connections are opened on first use and kept.
+type OVSStatsClient struct {
+	endpoints []string
+	tls       *config.OVNTLSConfig
+	timeout   time.Duration
+
+	mu      sync.Mutex
+	clients map[string]client.Client
+}
+
+// NewOVSStatsClient creates a client for the given chassis endpoints, e.g.
+// tcp:10.0.0.5:6640. tlsCfg applies to ssl: endpoints.
+func NewOVSStatsClient(endpoints []string, tlsCfg *config.OVNTLSConfig, timeout time.Duration) *OVSStatsClient {
+	return &OVSStatsClient{
+		endpoints: endpoints,
+		tls:       tlsCfg,
+		timeout:   timeout,
+		clients:   make(map[string]client.Client),
+	}
+}
+
+// InterfaceStats returns the counters of every interface bound to a logical
+// port. Chassis that can't be read are skipped and their errors returned
+// alongside the stats of the others.
+func (c *OVSStatsClient) InterfaceStats(ctx context.Context) ([]InterfaceStats, error) {
+	var stats []InterfaceStats
+	var errs []error
+	for _, endpoint := range c.endpoints {
+		ovs, err := c.client(ctx, endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			continue
+		}
+
+		var ifaces []ovsInterface
+		if err := ovs.List(ctx, &ifaces); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to list interfaces: %w", endpoint, err))
+			continue
+		}
+		for i := range ifaces {
+			if s, ok := convertInterfaceStats(&ifaces[i]); ok {
+				s.Chassis = endpoint
+				stats = append(stats, s)
+			}
+		}
+	}
+	return stats, errors.Join(errs...)
+}
+
+// Close closes the connections to every chassis
+func (c *OVSStatsClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for endpoint, ovs := range c.clients {
+		ovs.Close()
+		delete(c.clients, endpoint)
+	}
+}
+
+// client returns the connection to endpoint, connecting and monitoring the
+// Interface table if it isn't connected
+func (c *OVSStatsClient) client(ctx context.Context, endpoint string) (client.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ovs, ok := c.clients[endpoint]; ok && ovs.Connected() {
+		return ovs, nil
+	} else if ok {
+		ovs.Close()
+		delete(c.clients, endpoint)
+	}
+
+	opts := []client.Option{client.WithEndpoint(endpoint), client.WithLogger(newOVSDBLogger(endpoint))}
+	if c.tls != nil && c.tls.Enabled && strings.HasPrefix(endpoint, "ssl:") {
+		reloader, err := newTLSReloader(c.tls)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLSConfig(reloader.TLSConfig()))
+	}
+	ovs, err := client.NewOVSDBClient(ovsDatabaseModel(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OVSDB client: %w", err)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	if err := ovs.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to Open_vSwitch DB: %w", err)
+	}
+	iface := &ovsInterface{}
+	monitor := ovs.NewMonitor(client.WithTable(iface, &iface.Name, &iface.ExternalIDs, &iface.Statistics))
+	if _, err := ovs.Monitor(ctx, monitor); err != nil {
+		ovs.Close()
+		return nil, fmt.Errorf("failed to monitor interfaces: %w", err)
+	}
+
+	c.clients[endpoint] = ovs
+	return ovs, nil
+}
+
+// convertInterfaceStats converts an interface bound to a logical port
+func convertInterfaceStats(iface *ovsInterface) (InterfaceStats, bool) {
+	port := iface.ExternalIDs[ifaceIDKey]
+	if port == "" {
+		return InterfaceStats{}, false
+	}
+	counter := func(key string) uint64 {
+		if v := iface.Statistics[key]; v > 0 {
+			return uint64(v)
+		}
+		return 0
+	}
+	return InterfaceStats{
+		LogicalPort: port,
+		Interface:   iface.Name,
+		RxBytes:     counter("rx_bytes"),
+		TxBytes:     counter("tx_bytes"),
+		RxPackets:   counter("rx_packets"),
+		TxPackets:   counter("tx_packets"),
+	}, true
+}
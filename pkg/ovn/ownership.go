@@ -0,0 +1,72 @@
+package ovn
+
+import (
+	"context"
+
+	"github.com/lspecian/ovncp/internal/models"
+)
+
+// Actor is who makes the changes run under a context, and through what
+// client, e.g. a user through the API or Terraform
+type Actor struct {
+	UserID string
+	Source string
+}
+
+type actorKey struct{}
+
+// WithActor attributes the creates and updates run under ctx to actor. Their
+// resources record it in external_ids; changes made without an actor, e.g.
+// by ovncp's background jobs, leave the recorded ownership as it was.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// stampCreated records the actor of ctx as the creator and last updater in
+// the external IDs of a new row
+func stampCreated(ctx context.Context, externalIDs map[string]string) {
+	actor, ok := actorFrom(ctx)
+	if !ok {
+		return
+	}
+	setOwnership(externalIDs, models.CreatedByKey, actor.UserID)
+	setOwnership(externalIDs, models.CreatedSourceKey, actor.Source)
+	setOwnership(externalIDs, models.UpdatedByKey, actor.UserID)
+	setOwnership(externalIDs, models.UpdatedSourceKey, actor.Source)
+}
+
+// stampUpdated records the actor of ctx as the last updater in the external
+// IDs of a changed row
+func stampUpdated(ctx context.Context, externalIDs map[string]string) {
+	actor, ok := actorFrom(ctx)
+	if !ok {
+		return
+	}
+	setOwnership(externalIDs, models.UpdatedByKey, actor.UserID)
+	setOwnership(externalIDs, models.UpdatedSourceKey, actor.Source)
+}
+
+// setOwnership sets an ownership key, or removes it for an unknown value,
+// e.g. the user of a request made without authentication
+func setOwnership(externalIDs map[string]string, key, value string) {
+	if value == "" {
+		delete(externalIDs, key)
+		return
+	}
+	externalIDs[key] = value
+}
+
+// keepCreation copies the creation timestamp and creator of existing into
+// updates, external IDs replacing them
+func keepCreation(existing, updates map[string]string) {
+	for _, key := range []string{"created_at", models.CreatedByKey, models.CreatedSourceKey} {
+		if value, ok := existing[key]; ok {
+			updates[key] = value
+		}
+	}
+}
@@ -15,6 +15,10 @@ var (
 	ErrPoolClosed    = errors.New("connection pool is closed")
 	ErrPoolExhausted = errors.New("connection pool exhausted")
 	ErrInvalidConn   = errors.New("invalid connection")
+
+	// ErrPoolUnavailable wraps failures to obtain a connection, as opposed
+	// to errors returned by the function run on it
+	ErrPoolUnavailable = errors.New("failed to get connection from pool")
 )
 
 // ConnectionPool manages a pool of OVN client connections
@@ -24,6 +28,7 @@ type ConnectionPool struct {
 	factory  ConnectionFactory
 	mu       sync.RWMutex
 	closed   bool
+	active   map[*Client]*poolConn // connections handed out by Get
 	logger   *zap.Logger
 	stats    *PoolStats
 }
@@ -91,24 +96,25 @@ func NewConnectionPool(cfg *PoolConfig, factory ConnectionFactory, logger *zap.L
 		config:  cfg,
 		conns:   make(chan *poolConn, cfg.MaxSize),
 		factory: factory,
+		active:  make(map[*Client]*poolConn),
 		logger:  logger,
 		stats:   &PoolStats{},
 	}
 
-	// Initialize minimum connections
+	// Initialize minimum connections. OVN may not be reachable yet, so
+	// failures are left to the maintenance loop to retry.
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	for i := 0; i < cfg.MinSize; i++ {
 		conn, err := pool.createConn(ctx)
 		if err != nil {
-			pool.Close()
-			return nil, fmt.Errorf("failed to create initial connection: %w", err)
+			logger.Warn("Failed to create initial pool connection", zap.Error(err))
+			break
 		}
 		pool.conns <- conn
 		pool.stats.IdleConns++
 	}
-	pool.stats.TotalConns = int64(cfg.MinSize)
 
 	// Start maintenance goroutine
 	go pool.maintain()
@@ -154,6 +160,7 @@ func (p *ConnectionPool) Get(ctx context.Context) (*Client, error) {
 			p.stats.ActiveConns++
 			p.stats.mu.Unlock()
 
+			p.markActive(conn)
 			return conn.client, nil
 		}
 
@@ -163,8 +170,8 @@ func (p *ConnectionPool) Get(ctx context.Context) (*Client, error) {
 		p.stats.BadConns++
 		p.stats.mu.Unlock()
 
-	case <-time.After(100 * time.Millisecond):
-		// No immediate connection available
+	default:
+		// No idle connection available
 	}
 
 	// Check if we can create a new connection
@@ -189,10 +196,10 @@ func (p *ConnectionPool) Get(ctx context.Context) (*Client, error) {
 		p.updateWaitStats(waitTime)
 
 		p.stats.mu.Lock()
-		p.stats.TotalConns++
 		p.stats.ActiveConns++
 		p.stats.mu.Unlock()
 
+		p.markActive(conn)
 		return conn.client, nil
 	}
 
@@ -218,6 +225,7 @@ func (p *ConnectionPool) Get(ctx context.Context) (*Client, error) {
 			p.stats.ActiveConns++
 			p.stats.mu.Unlock()
 
+			p.markActive(conn)
 			return conn.client, nil
 		}
 
@@ -246,31 +254,16 @@ func (p *ConnectionPool) Put(client *Client) error {
 	p.mu.RUnlock()
 
 	// Find the pool connection
-	var conn *poolConn
-	for {
-		select {
-		case c := <-p.conns:
-			if c.client == client {
-				conn = c
-				break
-			}
-			// Put it back
-			p.conns <- c
-		default:
-			// Connection not found in pool
-			p.logger.Warn("Connection not found in pool")
-			client.Close()
-			return nil
-		}
-		if conn != nil {
-			break
-		}
-	}
+	p.mu.Lock()
+	conn, ok := p.active[client]
+	delete(p.active, client)
+	p.mu.Unlock()
 
-	conn.mu.Lock()
-	conn.lastUsedAt = time.Now()
-	conn.inUse = false
-	conn.mu.Unlock()
+	if !ok {
+		p.logger.Warn("Connection not found in pool")
+		client.Close()
+		return nil
+	}
 
 	// Check if connection should be closed
 	if !p.isConnValid(conn) {
@@ -278,6 +271,11 @@ func (p *ConnectionPool) Put(client *Client) error {
 		return nil
 	}
 
+	conn.mu.Lock()
+	conn.lastUsedAt = time.Now()
+	conn.inUse = false
+	conn.mu.Unlock()
+
 	// Return to pool
 	select {
 	case p.conns <- conn:
@@ -303,7 +301,8 @@ func (p *ConnectionPool) Close() error {
 	p.closed = true
 	p.mu.Unlock()
 
-	// Close all connections
+	// Close all connections. Connections still in use are closed when
+	// they are returned.
 	close(p.conns)
 	for conn := range p.conns {
 		p.closeConn(conn)
@@ -315,11 +314,29 @@ func (p *ConnectionPool) Close() error {
 	return nil
 }
 
-// Stats returns pool statistics
+// Stats returns a snapshot of the pool statistics
 func (p *ConnectionPool) Stats() PoolStats {
 	p.stats.mu.RLock()
 	defer p.stats.mu.RUnlock()
-	return *p.stats
+	return PoolStats{
+		TotalConns:   p.stats.TotalConns,
+		ActiveConns:  p.stats.ActiveConns,
+		IdleConns:    p.stats.IdleConns,
+		WaitCount:    p.stats.WaitCount,
+		WaitDuration: p.stats.WaitDuration,
+		MaxWait:      p.stats.MaxWait,
+		Hits:         p.stats.Hits,
+		Misses:       p.stats.Misses,
+		Timeouts:     p.stats.Timeouts,
+		BadConns:     p.stats.BadConns,
+	}
+}
+
+// markActive records a connection as handed out so Put can find it
+func (p *ConnectionPool) markActive(conn *poolConn) {
+	p.mu.Lock()
+	p.active[conn.client] = conn
+	p.mu.Unlock()
 }
 
 // maintain performs periodic maintenance on the pool
@@ -402,6 +419,10 @@ func (p *ConnectionPool) createConn(ctx context.Context) (*poolConn, error) {
 		return nil, err
 	}
 
+	p.stats.mu.Lock()
+	p.stats.TotalConns++
+	p.stats.mu.Unlock()
+
 	return &poolConn{
 		client:     client,
 		createdAt:  time.Now(),
@@ -438,8 +459,8 @@ func (p *ConnectionPool) isConnValid(conn *poolConn) bool {
 		return false
 	}
 
-	// Check if connection is closed
-	if conn.client.IsClosed() {
+	// Check if connection is closed or has dropped
+	if !conn.client.IsConnected() {
 		return false
 	}
 
@@ -495,6 +516,7 @@ func NewPooledClient(cfg *config.OVNConfig, logger *zap.Logger) (*PooledClient,
 			return nil, err
 		}
 		if err := client.Connect(ctx); err != nil {
+			client.Close()
 			return nil, err
 		}
 		return client, nil
@@ -514,7 +536,7 @@ func NewPooledClient(cfg *config.OVNConfig, logger *zap.Logger) (*PooledClient,
 func (pc *PooledClient) Execute(ctx context.Context, fn func(*Client) error) error {
 	client, err := pc.pool.Get(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get connection from pool: %w", err)
+		return fmt.Errorf("%w: %w", ErrPoolUnavailable, err)
 	}
 	defer pc.pool.Put(client)
 
@@ -0,0 +1,299 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// PortGroupSpec is a port group to create together with the ACLs applied to it
+type PortGroupSpec struct {
+	PortGroup *models.PortGroup
+	ACLs      []*models.ACL
+}
+
+// OwnedObjects are the port groups and address sets that belong to one owner
+type OwnedObjects struct {
+	PortGroups  []*models.PortGroup  `json:"port_groups"`
+	AddressSets []*models.AddressSet `json:"address_sets"`
+}
+
+// ListPortGroups returns all port groups
+func (c *Client) ListPortGroups(ctx context.Context) ([]*models.PortGroup, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	pgList := []nbdb.PortGroup{}
+	if err := c.nbClient.List(ctx, &pgList); err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+
+	result := make([]*models.PortGroup, 0, len(pgList))
+	for i := range pgList {
+		result = append(result, convertPortGroup(&pgList[i]))
+	}
+
+	return result, nil
+}
+
+// ListAddressSets returns all address sets
+func (c *Client) ListAddressSets(ctx context.Context) ([]*models.AddressSet, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	asList := []nbdb.AddressSet{}
+	if err := c.nbClient.List(ctx, &asList); err != nil {
+		return nil, fmt.Errorf("failed to list address sets: %w", err)
+	}
+
+	result := make([]*models.AddressSet, 0, len(asList))
+	for i := range asList {
+		result = append(result, convertAddressSet(&asList[i]))
+	}
+
+	return result, nil
+}
+
+// ListPortGroupACLs returns the ACLs applied to a port group
+func (c *Client) ListPortGroupACLs(ctx context.Context, portGroupID string) ([]*models.ACL, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	pg := &nbdb.PortGroup{UUID: portGroupID}
+	if err := c.nbClient.Get(ctx, pg); err != nil {
+		return nil, fmt.Errorf("port group %s not found", portGroupID)
+	}
+
+	aclUUIDs := make(map[string]bool, len(pg.ACLs))
+	for _, id := range pg.ACLs {
+		aclUUIDs[id] = true
+	}
+
+	aclList := []nbdb.ACL{}
+	err := c.nbClient.WhereCache(func(acl *nbdb.ACL) bool {
+		return aclUUIDs[acl.UUID]
+	}).List(ctx, &aclList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ACLs: %w", err)
+	}
+
+	acls := make([]*models.ACL, len(aclList))
+	for i := range aclList {
+		acls[i] = c.nbdbACLToModel(&aclList[i])
+	}
+
+	return acls, nil
+}
+
+// ReplaceOwnedObjects deletes the port groups and address sets whose
+// external_ids contain every owner key and value, and creates the given ones
+// in their place, in a single transaction. ACLs are only referenced by their
+// port group, so OVSDB garbage collects the old ones. Passing no objects
+// deletes everything the owner has.
+func (c *Client) ReplaceOwnedObjects(ctx context.Context, owner map[string]string, addressSets []*models.AddressSet, portGroups []*PortGroupSpec) (*OwnedObjects, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+	if len(owner) == 0 {
+		return nil, fmt.Errorf("owner is required")
+	}
+
+	ops := []ovsdb.Operation{}
+
+	existingPGs := []nbdb.PortGroup{}
+	err := c.nbClient.WhereCache(func(pg *nbdb.PortGroup) bool {
+		return hasExternalIDs(pg.ExternalIDs, owner)
+	}).List(ctx, &existingPGs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list port groups: %w", err)
+	}
+	for i := range existingPGs {
+		deleteOps, err := c.nbClient.Where(&nbdb.PortGroup{UUID: existingPGs[i].UUID}).Delete()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create delete operations: %w", err)
+		}
+		ops = append(ops, deleteOps...)
+	}
+
+	existingASs := []nbdb.AddressSet{}
+	err = c.nbClient.WhereCache(func(as *nbdb.AddressSet) bool {
+		return hasExternalIDs(as.ExternalIDs, owner)
+	}).List(ctx, &existingASs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address sets: %w", err)
+	}
+	for i := range existingASs {
+		deleteOps, err := c.nbClient.Where(&nbdb.AddressSet{UUID: existingASs[i].UUID}).Delete()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create delete operations: %w", err)
+		}
+		ops = append(ops, deleteOps...)
+	}
+
+	now := time.Now()
+	created := &OwnedObjects{
+		PortGroups:  []*models.PortGroup{},
+		AddressSets: []*models.AddressSet{},
+	}
+
+	for _, as := range addressSets {
+		as.UUID = uuid.New().String()
+		as.ExternalIDs = ownedExternalIDs(as.ExternalIDs, owner, now)
+
+		createOps, err := c.nbClient.Create(&nbdb.AddressSet{
+			UUID:        as.UUID,
+			Name:        as.Name,
+			Addresses:   as.Addresses,
+			ExternalIDs: as.ExternalIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create address set operations: %w", err)
+		}
+		ops = append(ops, createOps...)
+
+		as.CreatedAt = now
+		as.UpdatedAt = now
+		created.AddressSets = append(created.AddressSets, as)
+	}
+
+	for _, spec := range portGroups {
+		pg := spec.PortGroup
+		pg.UUID = uuid.New().String()
+		pg.ExternalIDs = ownedExternalIDs(pg.ExternalIDs, owner, now)
+		pg.ACLs = make([]string, 0, len(spec.ACLs))
+
+		for _, acl := range spec.ACLs {
+			if err := validateACL(acl); err != nil {
+				return nil, fmt.Errorf("port group %s: %w", pg.Name, err)
+			}
+			acl.UUID = uuid.New().String()
+			acl.ExternalIDs = ownedExternalIDs(acl.ExternalIDs, owner, now)
+
+			nbdbACL := &nbdb.ACL{
+				UUID:        acl.UUID,
+				Action:      nbdb.ACLAction(acl.Action),
+				Direction:   nbdb.ACLDirection(acl.Direction),
+				Match:       acl.Match,
+				Priority:    acl.Priority,
+				Log:         acl.Log,
+				ExternalIDs: acl.ExternalIDs,
+			}
+			if acl.Name != "" {
+				nbdbACL.Name = &acl.Name
+			}
+			if acl.Severity != "" {
+				severity := nbdb.ACLSeverity(acl.Severity)
+				nbdbACL.Severity = &severity
+			}
+
+			createOps, err := c.nbClient.Create(nbdbACL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create ACL operations: %w", err)
+			}
+			ops = append(ops, createOps...)
+			pg.ACLs = append(pg.ACLs, acl.UUID)
+		}
+
+		createOps, err := c.nbClient.Create(&nbdb.PortGroup{
+			UUID:        pg.UUID,
+			Name:        pg.Name,
+			Ports:       pg.Ports,
+			ACLs:        pg.ACLs,
+			ExternalIDs: pg.ExternalIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create port group operations: %w", err)
+		}
+		ops = append(ops, createOps...)
+
+		pg.CreatedAt = now
+		pg.UpdatedAt = now
+		created.PortGroups = append(created.PortGroups, pg)
+	}
+
+	if len(ops) == 0 {
+		return created, nil
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace port groups: %w", err)
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return created, nil
+}
+
+// hasExternalIDs reports whether externalIDs contains every key and value
+// in selector
+func hasExternalIDs(externalIDs, selector map[string]string) bool {
+	for k, v := range selector {
+		if externalIDs[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ownedExternalIDs adds the owner keys and timestamps to external IDs
+func ownedExternalIDs(externalIDs, owner map[string]string, now time.Time) map[string]string {
+	result := make(map[string]string, len(externalIDs)+len(owner)+2)
+	for k, v := range externalIDs {
+		result[k] = v
+	}
+	for k, v := range owner {
+		result[k] = v
+	}
+	result["created_at"] = now.Format(time.RFC3339)
+	result["updated_at"] = now.Format(time.RFC3339)
+	return result
+}
+
+// convertPortGroup converts an OVN port group to our model
+func convertPortGroup(ovnPG *nbdb.PortGroup) *models.PortGroup {
+	pg := &models.PortGroup{
+		UUID:        ovnPG.UUID,
+		Name:        ovnPG.Name,
+		Ports:       ovnPG.Ports,
+		ACLs:        ovnPG.ACLs,
+		ExternalIDs: ovnPG.ExternalIDs,
+	}
+	if created, ok := ovnPG.ExternalIDs["created_at"]; ok {
+		pg.CreatedAt = parseTime(created)
+	}
+	if updated, ok := ovnPG.ExternalIDs["updated_at"]; ok {
+		pg.UpdatedAt = parseTime(updated)
+	}
+	return pg
+}
+
+// convertAddressSet converts an OVN address set to our model
+func convertAddressSet(ovnAS *nbdb.AddressSet) *models.AddressSet {
+	as := &models.AddressSet{
+		UUID:        ovnAS.UUID,
+		Name:        ovnAS.Name,
+		Addresses:   ovnAS.Addresses,
+		ExternalIDs: ovnAS.ExternalIDs,
+	}
+	if created, ok := ovnAS.ExternalIDs["created_at"]; ok {
+		as.CreatedAt = parseTime(created)
+	}
+	if updated, ok := ovnAS.ExternalIDs["updated_at"]; ok {
+		as.UpdatedAt = parseTime(updated)
+	}
+	return as
+}
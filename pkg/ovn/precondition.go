@@ -0,0 +1,225 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// ErrPreconditionFailed is returned by conditional updates and deletes when
+// the resource no longer satisfies the caller's precondition
+var ErrPreconditionFailed = errors.New("precondition failed: resource has been modified")
+
+// Precondition decides whether a conditional update or delete may proceed.
+// It is passed the current resource, converted to its model, and returns
+// ErrPreconditionFailed to refuse.
+type Precondition func(current interface{}) error
+
+type preconditionKey struct{}
+
+// WithPrecondition makes the updates and deletes run under ctx conditional.
+// The precondition is checked against the cached row, and the transaction
+// waits on that row's columns so that it fails, with ErrPreconditionFailed,
+// if the database changed in the meantime.
+func WithPrecondition(ctx context.Context, check Precondition) context.Context {
+	return context.WithValue(ctx, preconditionKey{}, check)
+}
+
+func preconditionFrom(ctx context.Context) Precondition {
+	check, _ := ctx.Value(preconditionKey{}).(Precondition)
+	return check
+}
+
+// guardRow checks the precondition in ctx against current and returns a
+// wait operation asserting that the row still has the cached values of
+// fields. It returns no operations when ctx carries no precondition.
+func (c *Client) guardRow(ctx context.Context, current interface{}, row model.Model, fields ...interface{}) ([]ovsdb.Operation, error) {
+	check := preconditionFrom(ctx)
+	if check == nil {
+		return nil, nil
+	}
+
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	timeout := 0
+	ops, err := c.nbClient.Where(row).Wait(ovsdb.WaitConditionEqual, &timeout, row, fields...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wait operation: %w", err)
+	}
+	return ops, nil
+}
+
+// guardResult maps a failed wait from guardRow, which comes first in the
+// transaction, to ErrPreconditionFailed
+func guardResult(results []ovsdb.OperationResult, guard []ovsdb.Operation) error {
+	if len(guard) > 0 && len(results) > 0 && results[0].Error != "" {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+func (c *Client) guardLogicalSwitch(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LogicalSwitch{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get logical switch %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, convertLogicalSwitch(row), row,
+		&row.Name, &row.Ports, &row.ACLs, &row.QOSRules, &row.LoadBalancer,
+		&row.DNSRecords, &row.OtherConfig, &row.ExternalIDs)
+}
+
+func (c *Client) guardLogicalRouter(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LogicalRouter{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get logical router %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, convertLogicalRouter(row), row,
+		&row.Name, &row.Ports, &row.StaticRoutes, &row.Policies, &row.Nat,
+		&row.LoadBalancer, &row.Enabled, &row.Options, &row.ExternalIDs)
+}
+
+func (c *Client) guardLoadBalancer(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LoadBalancer{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get load balancer %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, convertLoadBalancer(row), row,
+		&row.Name, &row.Vips, &row.Protocol, &row.HealthCheck, &row.IPPortMappings,
+		&row.SelectionFields, &row.Options, &row.ExternalIDs)
+}
+
+// guardLogicalSwitchPort leaves out the columns OVN maintains itself, such
+// as up and dynamic_addresses, which don't make a port modified
+func (c *Client) guardLogicalSwitchPort(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LogicalSwitchPort{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get logical switch port %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, c.nbdbPortToModel(row), row,
+		&row.Name, &row.Type, &row.Addresses, &row.PortSecurity, &row.Options,
+		&row.ParentName, &row.TagRequest, &row.Enabled, &row.ExternalIDs)
+}
+
+func (c *Client) guardACL(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.ACL{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get ACL %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, c.nbdbACLToModel(row), row,
+		&row.Priority, &row.Direction, &row.Match, &row.Action, &row.Log,
+		&row.Name, &row.Severity, &row.Meter, &row.ExternalIDs)
+}
+
+func (c *Client) guardDNS(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.DNS{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get DNS record set %s: %w", uuid, err)
+	}
+	attached, err := c.dnsSwitches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.guardRow(ctx, convertDNS(row, attached[uuid]), row,
+		&row.Records, &row.Options, &row.ExternalIDs)
+}
+
+func (c *Client) guardMirror(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.Mirror{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get mirror %s: %w", uuid, err)
+	}
+	sources, err := c.mirrorPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.guardRow(ctx, convertMirror(row, sources[uuid]), row,
+		&row.Type, &row.Filter, &row.Sink, &row.Index, &row.ExternalIDs)
+}
+
+func (c *Client) guardSampleCollector(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.SampleCollector{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get sample collector %s: %w", uuid, err)
+	}
+	return c.guardRow(ctx, convertSampleCollector(row), row,
+		&row.Name, &row.SetID, &row.Probability, &row.ExternalIDs)
+}
+
+func (c *Client) guardRouterPolicy(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LogicalRouterPolicy{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get router policy %s: %w", uuid, err)
+	}
+	router, err := c.policyRouter(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.guardRow(ctx, convertRouterPolicy(row, router.UUID), row,
+		&row.Priority, &row.Match, &row.Action, &row.Nexthop, &row.Nexthops,
+		&row.Options, &row.ExternalIDs)
+}
+
+func (c *Client) guardStaticRoute(ctx context.Context, uuid string) ([]ovsdb.Operation, error) {
+	if preconditionFrom(ctx) == nil {
+		return nil, nil
+	}
+
+	row := &nbdb.LogicalRouterStaticRoute{UUID: uuid}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to get static route %s: %w", uuid, err)
+	}
+	router, err := c.staticRouteRouter(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := c.bfdSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.guardRow(ctx, convertStaticRoute(row, router.UUID, sessions), row,
+		&row.IPPrefix, &row.Nexthop, &row.OutputPort, &row.Policy, &row.RouteTable,
+		&row.BFD, &row.Options, &row.ExternalIDs)
+}
@@ -0,0 +1,49 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ProbeEndpoint checks an OVSDB endpoint accepts connections. endpoint is in
+// OVSDB format, e.g. tcp:10.0.0.1:6642 or unix:/var/run/ovn/ovnsb_db.sock;
+// comma-separated endpoints of a clustered database succeed when any of
+// them accepts. TLS endpoints are only dialed, without a handshake.
+func ProbeEndpoint(ctx context.Context, endpoint string) error {
+	var errs []error
+	for _, ep := range strings.Split(endpoint, ",") {
+		network, address, err := parseEndpoint(strings.TrimSpace(ep))
+		if err != nil {
+			return err
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		conn.Close()
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// parseEndpoint splits an OVSDB endpoint into a network and address to dial
+func parseEndpoint(endpoint string) (string, string, error) {
+	scheme, address, ok := strings.Cut(endpoint, ":")
+	if !ok || address == "" {
+		return "", "", fmt.Errorf("invalid OVSDB endpoint %q", endpoint)
+	}
+
+	switch scheme {
+	case "tcp", "ssl":
+		return "tcp", address, nil
+	case "unix":
+		return "unix", address, nil
+	}
+	return "", "", fmt.Errorf("unsupported OVSDB endpoint %q", endpoint)
+}
@@ -0,0 +1,132 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// Types of the northbound objects ovn-northd realizes in the southbound
+// database
+const (
+	NorthboundLogicalSwitch     = "logical_switch"
+	NorthboundLogicalRouter     = "logical_router"
+	NorthboundLogicalSwitchPort = "logical_switch_port"
+	NorthboundLogicalRouterPort = "logical_router_port"
+)
+
+// NorthboundObject is a northbound object ovn-northd realizes in the
+// southbound database: a datapath for switches and routers, a port binding
+// for their ports
+type NorthboundObject struct {
+	Type string `json:"type"`
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// CreatedAt is set for objects created through ovncp
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// NorthboundObjects returns the switches, routers and their ports, whose
+// southbound realization can be checked
+func (c *Client) NorthboundObjects(ctx context.Context) ([]NorthboundObject, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	var switches []nbdb.LogicalSwitch
+	if err := c.nbClient.List(ctx, &switches); err != nil {
+		return nil, fmt.Errorf("failed to list logical switches: %w", err)
+	}
+	var routers []nbdb.LogicalRouter
+	if err := c.nbClient.List(ctx, &routers); err != nil {
+		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	var switchPorts []nbdb.LogicalSwitchPort
+	if err := c.nbClient.List(ctx, &switchPorts); err != nil {
+		return nil, fmt.Errorf("failed to list logical switch ports: %w", err)
+	}
+	var routerPorts []nbdb.LogicalRouterPort
+	if err := c.nbClient.List(ctx, &routerPorts); err != nil {
+		return nil, fmt.Errorf("failed to list logical router ports: %w", err)
+	}
+
+	objects := make([]NorthboundObject, 0, len(switches)+len(routers)+len(switchPorts)+len(routerPorts))
+	add := func(objectType, uuid, name string, externalIDs map[string]string) {
+		object := NorthboundObject{Type: objectType, UUID: uuid, Name: name}
+		if created := parseTime(externalIDs["created_at"]); !created.IsZero() {
+			object.CreatedAt = &created
+		}
+		objects = append(objects, object)
+	}
+	for _, ls := range switches {
+		add(NorthboundLogicalSwitch, ls.UUID, ls.Name, ls.ExternalIDs)
+	}
+	for _, lr := range routers {
+		add(NorthboundLogicalRouter, lr.UUID, lr.Name, lr.ExternalIDs)
+	}
+	for _, lsp := range switchPorts {
+		add(NorthboundLogicalSwitchPort, lsp.UUID, lsp.Name, lsp.ExternalIDs)
+	}
+	for _, lrp := range routerPorts {
+		add(NorthboundLogicalRouterPort, lrp.UUID, lrp.Name, lrp.ExternalIDs)
+	}
+	return objects, nil
+}
+
+// sbDatapathBinding is the part of a southbound Datapath_Binding row the
+// realization check reads. ovn-northd records the northbound switch or
+// router in its external IDs.
+type sbDatapathBinding struct {
+	UUID        string            `ovsdb:"_uuid"`
+	TunnelKey   int               `ovsdb:"tunnel_key"`
+	ExternalIDs map[string]string `ovsdb:"external_ids"`
+}
+
+// SouthboundRealization is what ovn-northd realized in the southbound
+// database, keyed by the northbound objects
+type SouthboundRealization struct {
+	// Datapaths holds the UUIDs of the northbound switches and routers
+	// that have a datapath
+	Datapaths map[string]bool
+	// Ports holds the names of the logical ports that have a port binding
+	Ports map[string]bool
+}
+
+// Realization returns the switches, routers and ports ovn-northd created
+// datapaths and port bindings for
+func (c *ChassisInventory) Realization(ctx context.Context) (*SouthboundRealization, error) {
+	sb, err := c.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var datapaths []sbDatapathBinding
+	if err := sb.List(ctx, &datapaths); err != nil {
+		return nil, fmt.Errorf("failed to list datapath bindings: %w", err)
+	}
+	var bindings []sbPortBinding
+	if err := sb.List(ctx, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to list port bindings: %w", err)
+	}
+
+	realization := &SouthboundRealization{
+		Datapaths: make(map[string]bool, len(datapaths)),
+		Ports:     make(map[string]bool, len(bindings)),
+	}
+	for _, datapath := range datapaths {
+		for _, key := range []string{"logical-switch", "logical-router"} {
+			if uuid := datapath.ExternalIDs[key]; uuid != "" {
+				realization.Datapaths[uuid] = true
+			}
+		}
+	}
+	for _, binding := range bindings {
+		realization.Ports[binding.LogicalPort] = true
+	}
+	return realization, nil
+}
@@ -0,0 +1,121 @@
+package ovn
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// ConnectionStatus describes the state of the northbound connection
+type ConnectionStatus struct {
+	Connected         bool       `json:"connected"`
+	Endpoint          string     `json:"endpoint"`
+	LastConnected     *time.Time `json:"last_connected,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	Reconnects        int64      `json:"reconnects"`
+	ReconnectAttempts int64      `json:"reconnect_attempts"`
+}
+
+// connState tracks connection history for health reporting
+type connState struct {
+	mu            sync.Mutex
+	lastConnected time.Time
+	lastError     error
+	reconnects    int64 // reconnect cycles started
+	attempts      int64 // failed attempts in the current or last cycle
+}
+
+func (s *connState) recordResult(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.lastError = err
+		return
+	}
+	s.lastConnected = time.Now()
+	s.lastError = nil
+}
+
+// reconnectBackoff is an exponential backoff that records its progress in
+// the client's connection state
+type reconnectBackoff struct {
+	*backoff.ExponentialBackOff
+	state *connState
+}
+
+func (b *reconnectBackoff) Reset() {
+	b.state.mu.Lock()
+	b.state.reconnects++
+	b.state.attempts = 0
+	b.state.mu.Unlock()
+
+	b.ExponentialBackOff.Reset()
+}
+
+func (b *reconnectBackoff) NextBackOff() time.Duration {
+	b.state.mu.Lock()
+	b.state.attempts++
+	b.state.mu.Unlock()
+
+	return b.ExponentialBackOff.NextBackOff()
+}
+
+// newReconnectBackoff returns a backoff that never gives up. libovsdb panics
+// when its reconnect loop runs out of retries, so MaxElapsedTime must be 0.
+func newReconnectBackoff(initial, max time.Duration, state *connState) *reconnectBackoff {
+	bo := backoff.NewExponentialBackOff()
+	if initial > 0 {
+		bo.InitialInterval = initial
+	}
+	if max > 0 {
+		bo.MaxInterval = max
+	}
+	bo.MaxElapsedTime = 0
+
+	return &reconnectBackoff{ExponentialBackOff: bo, state: state}
+}
+
+// ConnectWithBackoff connects to the northbound database, retrying with
+// exponential backoff until it succeeds, the context is cancelled or the
+// client is closed. Once connected, dropped connections are re-established
+// by the underlying OVSDB client.
+func (c *Client) ConnectWithBackoff(ctx context.Context) error {
+	bo := newReconnectBackoff(c.config.ReconnectInterval, c.config.ReconnectMaxDelay, c.state)
+
+	return backoff.Retry(func() error {
+		if c.IsClosed() {
+			return backoff.Permanent(ErrClientClosed)
+		}
+
+		connectCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+
+		return c.Connect(connectCtx)
+	}, backoff.WithContext(bo, ctx))
+}
+
+// Status returns the current connection status
+func (c *Client) Status() ConnectionStatus {
+	status := ConnectionStatus{
+		Connected: c.IsConnected(),
+		Endpoint:  c.config.NorthboundDB,
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if !c.state.lastConnected.IsZero() {
+		lastConnected := c.state.lastConnected
+		status.LastConnected = &lastConnected
+	}
+	if c.state.lastError != nil {
+		status.LastError = c.state.lastError.Error()
+	}
+	status.Reconnects = c.state.reconnects
+	status.ReconnectAttempts = c.state.attempts
+
+	return status
+}
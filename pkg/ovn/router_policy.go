@@ -0,0 +1,396 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// maxRouterPolicyPriority is the highest priority OVN accepts for a logical
+// router policy
+const maxRouterPolicyPriority = 32767
+
+// ListRouterPolicies returns the policies of a logical router, highest
+// priority first
+func (c *Client) ListRouterPolicies(ctx context.Context, routerID string) ([]*models.RouterPolicy, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	router, err := c.GetLogicalRouter(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.RouterPolicy, 0, len(router.Policies))
+	for _, policyUUID := range router.Policies {
+		row := &nbdb.LogicalRouterPolicy{UUID: policyUUID}
+		if err := c.nbClient.Get(ctx, row); err != nil {
+			continue
+		}
+		result = append(result, convertRouterPolicy(row, router.UUID))
+	}
+	sortRouterPolicies(result)
+	return result, nil
+}
+
+// ListLogicalRouterPolicies returns the policies of every logical router,
+// keyed by router UUID
+func (c *Client) ListLogicalRouterPolicies(ctx context.Context) (map[string][]models.RouterPolicy, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	lrList := []nbdb.LogicalRouter{}
+	if err := c.nbClient.List(ctx, &lrList); err != nil {
+		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	policyList := []nbdb.LogicalRouterPolicy{}
+	if err := c.nbClient.List(ctx, &policyList); err != nil {
+		return nil, fmt.Errorf("failed to list router policies: %w", err)
+	}
+
+	policies := make(map[string]*nbdb.LogicalRouterPolicy, len(policyList))
+	for i := range policyList {
+		policies[policyList[i].UUID] = &policyList[i]
+	}
+
+	result := make(map[string][]models.RouterPolicy, len(lrList))
+	for _, lr := range lrList {
+		var routerPolicies []*models.RouterPolicy
+		for _, policyUUID := range lr.Policies {
+			if policy, ok := policies[policyUUID]; ok {
+				routerPolicies = append(routerPolicies, convertRouterPolicy(policy, lr.UUID))
+			}
+		}
+		sortRouterPolicies(routerPolicies)
+		for _, policy := range routerPolicies {
+			result[lr.UUID] = append(result[lr.UUID], *policy)
+		}
+	}
+	return result, nil
+}
+
+// GetRouterPolicy returns a logical router policy by UUID
+func (c *Client) GetRouterPolicy(ctx context.Context, id string) (*models.RouterPolicy, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row := &nbdb.LogicalRouterPolicy{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("router policy %s not found", id)
+	}
+	router, err := c.policyRouter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertRouterPolicy(row, router.UUID), nil
+}
+
+// CreateRouterPolicy adds a policy to a logical router
+func (c *Client) CreateRouterPolicy(ctx context.Context, routerID string, policy *models.RouterPolicy) (*models.RouterPolicy, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	router, err := c.GetLogicalRouter(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRouterPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := c.checkPolicyUnique(ctx, router, "", policy); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	externalIDs := make(map[string]string, len(policy.ExternalIDs)+2)
+	for k, v := range policy.ExternalIDs {
+		externalIDs[k] = v
+	}
+	externalIDs["created_at"] = now.Format(time.RFC3339)
+	externalIDs["updated_at"] = now.Format(time.RFC3339)
+
+	row := &nbdb.LogicalRouterPolicy{
+		UUID:        uuid.New().String(),
+		Priority:    policy.Priority,
+		Match:       policy.Match,
+		Action:      nbdb.LogicalRouterPolicyAction(policy.Action),
+		Nexthops:    policy.Nexthops,
+		Options:     policy.Options,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router policy operations: %w", err)
+	}
+
+	lr := &nbdb.LogicalRouter{UUID: router.UUID}
+	mutateOps, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Policies,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{row.UUID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router mutate operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(ops, mutateOps...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router policy: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertRouterPolicy(row, router.UUID), nil
+}
+
+// UpdateRouterPolicy updates a logical router policy. Fields left empty keep
+// their value, except that nexthops are dropped when the action no longer
+// reroutes.
+func (c *Client) UpdateRouterPolicy(ctx context.Context, id string, updates *models.RouterPolicy) (*models.RouterPolicy, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetRouterPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardRouterPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if updates.Priority > 0 {
+		merged.Priority = updates.Priority
+	}
+	if updates.Match != "" {
+		merged.Match = updates.Match
+	}
+	if updates.Action != "" {
+		merged.Action = updates.Action
+	}
+	if updates.Nexthops != nil {
+		merged.Nexthops = updates.Nexthops
+	} else if merged.Action != string(nbdb.LogicalRouterPolicyActionReroute) {
+		merged.Nexthops = nil
+	}
+	if updates.Options != nil {
+		merged.Options = updates.Options
+	}
+	if err := validateRouterPolicy(&merged); err != nil {
+		return nil, err
+	}
+	if merged.Priority != existing.Priority || merged.Match != existing.Match {
+		router, err := c.GetLogicalRouter(ctx, existing.RouterID)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.checkPolicyUnique(ctx, router, id, &merged); err != nil {
+			return nil, err
+		}
+	}
+
+	row := &nbdb.LogicalRouterPolicy{
+		UUID:        id,
+		Priority:    merged.Priority,
+		Match:       merged.Match,
+		Action:      nbdb.LogicalRouterPolicyAction(merged.Action),
+		Nexthops:    merged.Nexthops,
+		Options:     merged.Options,
+		ExternalIDs: updatedExternalIDs(existing.ExternalIDs, updates.ExternalIDs, time.Now()),
+	}
+	ops, err := c.nbClient.Where(row).Update(row, &row.Priority, &row.Match, &row.Action,
+		&row.Nexthops, &row.Options, &row.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update router policy: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetRouterPolicy(ctx, id)
+}
+
+// DeleteRouterPolicy removes a policy from its logical router
+func (c *Client) DeleteRouterPolicy(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetRouterPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardRouterPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	lr := &nbdb.LogicalRouter{UUID: existing.RouterID}
+	ops, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.Policies,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{id},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create router mutate operations: %w", err)
+	}
+	deleteOps, err := c.nbClient.Where(&nbdb.LogicalRouterPolicy{UUID: id}).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+	ops = append(ops, deleteOps...)
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete router policy: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// policyRouter returns the logical router a policy belongs to
+func (c *Client) policyRouter(ctx context.Context, policyUUID string) (*nbdb.LogicalRouter, error) {
+	routers := []nbdb.LogicalRouter{}
+	err := c.nbClient.WhereCache(func(lr *nbdb.LogicalRouter) bool {
+		for _, id := range lr.Policies {
+			if id == policyUUID {
+				return true
+			}
+		}
+		return false
+	}).List(ctx, &routers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find router for policy: %w", err)
+	}
+	if len(routers) == 0 {
+		return nil, fmt.Errorf("router policy %s is not attached to any router", policyUUID)
+	}
+	return &routers[0], nil
+}
+
+// checkPolicyUnique refuses a policy whose priority and match another policy
+// of the router, other than exclude, already has, as OVN couldn't tell which
+// one applies
+func (c *Client) checkPolicyUnique(ctx context.Context, router *models.LogicalRouter, exclude string, policy *models.RouterPolicy) error {
+	for _, policyUUID := range router.Policies {
+		if policyUUID == exclude {
+			continue
+		}
+		row := &nbdb.LogicalRouterPolicy{UUID: policyUUID}
+		if err := c.nbClient.Get(ctx, row); err != nil {
+			continue
+		}
+		if row.Priority == policy.Priority && row.Match == policy.Match {
+			return fmt.Errorf("router policy with priority %d and match %q already exists", policy.Priority, policy.Match)
+		}
+	}
+	return nil
+}
+
+// validateRouterPolicy checks a policy's priority, match and action, and
+// that exactly the reroute policies have nexthops of one address family
+func validateRouterPolicy(policy *models.RouterPolicy) error {
+	if policy.Priority < 0 || policy.Priority > maxRouterPolicyPriority {
+		return fmt.Errorf("invalid router policy priority %d: must be between 0 and %d", policy.Priority, maxRouterPolicyPriority)
+	}
+	if err := ValidateMatch(policy.Match); err != nil {
+		return err
+	}
+
+	switch policy.Action {
+	case nbdb.LogicalRouterPolicyActionAllow, nbdb.LogicalRouterPolicyActionDrop:
+		if len(policy.Nexthops) > 0 {
+			return fmt.Errorf("invalid router policy: nexthops only apply to the reroute action")
+		}
+	case nbdb.LogicalRouterPolicyActionReroute:
+		if len(policy.Nexthops) == 0 {
+			return fmt.Errorf("invalid router policy: reroute requires at least one nexthop")
+		}
+		var ipv4 bool
+		for i, nexthop := range policy.Nexthops {
+			ip := net.ParseIP(nexthop)
+			if ip == nil {
+				return fmt.Errorf("invalid router policy nexthop %q", nexthop)
+			}
+			if i > 0 && (ip.To4() != nil) != ipv4 {
+				return fmt.Errorf("invalid router policy: nexthops mix IPv4 and IPv6 addresses")
+			}
+			ipv4 = ip.To4() != nil
+		}
+	default:
+		return fmt.Errorf("invalid router policy action %q: must be allow, drop or reroute", policy.Action)
+	}
+	return nil
+}
+
+// sortRouterPolicies orders policies the way OVN evaluates them, highest
+// priority first
+func sortRouterPolicies(policies []*models.RouterPolicy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Priority != policies[j].Priority {
+			return policies[i].Priority > policies[j].Priority
+		}
+		return policies[i].Match < policies[j].Match
+	})
+}
+
+// convertRouterPolicy converts an OVN logical router policy to our model
+func convertRouterPolicy(row *nbdb.LogicalRouterPolicy, routerID string) *models.RouterPolicy {
+	policy := &models.RouterPolicy{
+		UUID:        row.UUID,
+		RouterID:    routerID,
+		Priority:    row.Priority,
+		Match:       row.Match,
+		Action:      row.Action,
+		Nexthops:    row.Nexthops,
+		Options:     row.Options,
+		ExternalIDs: row.ExternalIDs,
+		CreatedAt:   parseTime(row.ExternalIDs["created_at"]),
+		UpdatedAt:   parseTime(row.ExternalIDs["updated_at"]),
+	}
+	// Policies created by older tools set the single nexthop column
+	if len(policy.Nexthops) == 0 && row.Nexthop != nil {
+		policy.Nexthops = []string{*row.Nexthop}
+	}
+	return policy
+}
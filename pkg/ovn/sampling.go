@@ -0,0 +1,680 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// maxSamplingID is the largest ID of a sample collector and the largest
+// observation domain ID of a sampling app
+const maxSamplingID = 255
+
+// maxSampleProbability is the probability of sampling every packet
+const maxSampleProbability = 65535
+
+// maxObservationPointID is the largest observation point ID and collector
+// set ID, both 32 bits
+const maxObservationPointID = 1<<32 - 1
+
+// ListSampleCollectors returns all sample collectors, sorted by ID
+func (c *Client) ListSampleCollectors(ctx context.Context) ([]*models.SampleCollector, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	rows := []nbdb.SampleCollector{}
+	if err := c.nbClient.List(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to list sample collectors: %w", err)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+
+	result := make([]*models.SampleCollector, 0, len(rows))
+	for i := range rows {
+		result = append(result, convertSampleCollector(&rows[i]))
+	}
+	return result, nil
+}
+
+// GetSampleCollector returns a sample collector by UUID or name
+func (c *Client) GetSampleCollector(ctx context.Context, id string) (*models.SampleCollector, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row, err := c.sampleCollectorRow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertSampleCollector(row), nil
+}
+
+// CreateSampleCollector creates a sample collector. Without an ID it gets
+// the lowest one free.
+func (c *Client) CreateSampleCollector(ctx context.Context, collector *models.SampleCollector) (*models.SampleCollector, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing := []nbdb.SampleCollector{}
+	if err := c.nbClient.List(ctx, &existing); err != nil {
+		return nil, fmt.Errorf("failed to list sample collectors: %w", err)
+	}
+	used := make(map[int]bool, len(existing))
+	for _, row := range existing {
+		used[row.ID] = true
+		if row.Name == collector.Name {
+			return nil, fmt.Errorf("sample collector %s already exists", collector.Name)
+		}
+	}
+	if collector.ID == 0 {
+		for id := 1; id <= maxSamplingID && collector.ID == 0; id++ {
+			if !used[id] {
+				collector.ID = id
+			}
+		}
+		if collector.ID == 0 {
+			return nil, fmt.Errorf("invalid sample collector: all %d collector IDs are in use", maxSamplingID)
+		}
+	}
+	if err := validateSampleCollector(collector); err != nil {
+		return nil, err
+	}
+	if used[collector.ID] {
+		return nil, fmt.Errorf("sample collector ID %d already exists", collector.ID)
+	}
+
+	externalIDs := make(map[string]string, len(collector.ExternalIDs))
+	for k, v := range collector.ExternalIDs {
+		externalIDs[k] = v
+	}
+	row := &nbdb.SampleCollector{
+		UUID:        uuid.New().String(),
+		ID:          collector.ID,
+		Name:        collector.Name,
+		SetID:       collector.SetID,
+		Probability: *collector.Probability,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sample collector operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sample collector: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertSampleCollector(row), nil
+}
+
+// UpdateSampleCollector updates a sample collector. Its name, set ID and
+// probability are changed when given and external IDs given are added. The
+// ID can't be changed, as samples refer to collectors by ID.
+func (c *Client) UpdateSampleCollector(ctx context.Context, id string, updates *models.SampleCollector) (*models.SampleCollector, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row, err := c.sampleCollectorRow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if updates.ID != 0 && updates.ID != row.ID {
+		return nil, fmt.Errorf("invalid sample collector: the ID can't be changed")
+	}
+	if updates.Name != "" && updates.Name != row.Name {
+		if _, err := c.sampleCollectorRow(ctx, updates.Name); err == nil {
+			return nil, fmt.Errorf("sample collector %s already exists", updates.Name)
+		}
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardSampleCollector(ctx, row.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := convertSampleCollector(row)
+	if updates.Name != "" {
+		merged.Name = updates.Name
+	}
+	if updates.SetID != 0 {
+		merged.SetID = updates.SetID
+	}
+	if updates.Probability != nil {
+		merged.Probability = updates.Probability
+	}
+	if err := validateSampleCollector(merged); err != nil {
+		return nil, err
+	}
+
+	externalIDs := make(map[string]string, len(row.ExternalIDs)+len(updates.ExternalIDs))
+	for k, v := range row.ExternalIDs {
+		externalIDs[k] = v
+	}
+	for k, v := range updates.ExternalIDs {
+		externalIDs[k] = v
+	}
+	updated := &nbdb.SampleCollector{
+		UUID:        row.UUID,
+		ID:          row.ID,
+		Name:        merged.Name,
+		SetID:       merged.SetID,
+		Probability: *merged.Probability,
+		ExternalIDs: externalIDs,
+	}
+	ops, err := c.nbClient.Where(updated).Update(updated, &updated.Name, &updated.SetID, &updated.Probability, &updated.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sample collector: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return convertSampleCollector(updated), nil
+}
+
+// DeleteSampleCollector deletes a sample collector no ACL samples to
+func (c *Client) DeleteSampleCollector(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	row, err := c.sampleCollectorRow(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Samples refer to their collectors strongly, so OVSDB would refuse the
+	// delete anyway; say which samples are in the way
+	samples := []nbdb.Sample{}
+	err = c.nbClient.WhereCache(func(s *nbdb.Sample) bool {
+		for _, collector := range s.Collectors {
+			if collector == row.UUID {
+				return true
+			}
+		}
+		return false
+	}).List(ctx, &samples)
+	if err != nil {
+		return fmt.Errorf("failed to list samples: %w", err)
+	}
+	if len(samples) > 0 {
+		return fmt.Errorf("sample collector %s is in use by %d observation points", row.Name, len(samples))
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardSampleCollector(ctx, row.UUID)
+	if err != nil {
+		return err
+	}
+
+	ops, err := c.nbClient.Where(&nbdb.SampleCollector{UUID: row.UUID}).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete sample collector: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// ListSamplingApps returns the observation domains set, sorted by type
+func (c *Client) ListSamplingApps(ctx context.Context) ([]*models.SamplingApp, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	rows := []nbdb.SamplingApp{}
+	if err := c.nbClient.List(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to list sampling apps: %w", err)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Type < rows[j].Type
+	})
+
+	result := make([]*models.SamplingApp, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, &models.SamplingApp{Type: row.Type, ID: row.ID})
+	}
+	return result, nil
+}
+
+// SetSamplingApp sets the observation domain ID of the samples of a type.
+// An ID of 0 unsets it, and nil is returned.
+func (c *Client) SetSamplingApp(ctx context.Context, appType string, id int) (*models.SamplingApp, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	switch appType {
+	case nbdb.SamplingAppTypeDrop, nbdb.SamplingAppTypeACLNew, nbdb.SamplingAppTypeACLEst:
+	default:
+		return nil, fmt.Errorf("invalid sampling app: type must be drop, acl-new or acl-est")
+	}
+	if id < 0 || id > maxSamplingID {
+		return nil, fmt.Errorf("invalid sampling app: observation domain ID must be between 1 and %d", maxSamplingID)
+	}
+
+	rows := []nbdb.SamplingApp{}
+	err := c.nbClient.WhereCache(func(app *nbdb.SamplingApp) bool {
+		return app.Type == appType
+	}).List(ctx, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sampling apps: %w", err)
+	}
+
+	var ops []ovsdb.Operation
+	switch {
+	case id == 0 && len(rows) == 0:
+		return nil, nil
+	case id == 0:
+		ops, err = c.nbClient.Where(&rows[0]).Delete()
+	case len(rows) == 0:
+		ops, err = c.nbClient.Create(&nbdb.SamplingApp{UUID: uuid.New().String(), Type: appType, ID: id})
+	default:
+		row := &rows[0]
+		row.ID = id
+		ops, err = c.nbClient.Where(row).Update(row, &row.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampling app operations: %w", err)
+	}
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set sampling app: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	if id == 0 {
+		return nil, nil
+	}
+	return &models.SamplingApp{Type: appType, ID: id}, nil
+}
+
+// GetACLSampling returns the sampling of an ACL
+func (c *Client) GetACLSampling(ctx context.Context, aclID string) (*models.ACLSampling, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	acl := &nbdb.ACL{UUID: aclID}
+	if err := c.nbClient.Get(ctx, acl); err != nil {
+		return nil, fmt.Errorf("ACL %s not found", aclID)
+	}
+	samples, err := c.sampleRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertACLSampling(acl, samples), nil
+}
+
+// SetACLSampling replaces the sampling of an ACL. Packets of new and of
+// established connections are sampled when given and no longer sampled
+// otherwise. Observation points not given an ID get the lowest one free.
+func (c *Client) SetACLSampling(ctx context.Context, aclID string, sampling *models.ACLSampling) (*models.ACLSampling, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	acl := &nbdb.ACL{UUID: aclID}
+	if err := c.nbClient.Get(ctx, acl); err != nil {
+		return nil, fmt.Errorf("ACL %s not found", aclID)
+	}
+	if sampling.Established != nil && !tracksConnections(acl) {
+		return nil, fmt.Errorf("invalid sampling: established connections are only tracked for allow and allow-related ACLs")
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardACL(ctx, aclID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := c.newSamplingPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.setACL(acl, sampling); err != nil {
+		return nil, err
+	}
+
+	results, err := c.Transact(ctx, append(guard, plan.ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set ACL sampling: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetACLSampling(ctx, aclID)
+}
+
+// SetSwitchSampling replaces the sampling of every ACL of a switch, as
+// SetACLSampling does, in one transaction. Each ACL gets its own
+// observation points, so their IDs can't be given. Established connections
+// are only sampled for the ACLs tracking them.
+func (c *Client) SetSwitchSampling(ctx context.Context, switchID string, sampling *models.ACLSampling) ([]*models.ACLSampling, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	sw := &nbdb.LogicalSwitch{UUID: switchID}
+	if err := c.nbClient.Get(ctx, sw); err != nil {
+		return nil, fmt.Errorf("logical switch %s not found", switchID)
+	}
+	for _, sample := range []*models.FlowSample{sampling.New, sampling.Established} {
+		if sample != nil && sample.ObservationPointID != 0 {
+			return nil, fmt.Errorf("invalid sampling: observation point IDs are allocated per ACL when sampling a switch")
+		}
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardLogicalSwitch(ctx, switchID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := c.newSamplingPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, aclUUID := range sw.ACLs {
+		acl := &nbdb.ACL{UUID: aclUUID}
+		if err := c.nbClient.Get(ctx, acl); err != nil {
+			return nil, fmt.Errorf("failed to get ACL %s: %w", aclUUID, err)
+		}
+		aclSampling := &models.ACLSampling{New: sampling.New}
+		if tracksConnections(acl) {
+			aclSampling.Established = sampling.Established
+		}
+		if err := plan.setACL(acl, aclSampling); err != nil {
+			return nil, fmt.Errorf("ACL %s: %w", aclUUID, err)
+		}
+	}
+
+	if len(plan.ops) > 0 {
+		results, err := c.Transact(ctx, append(guard, plan.ops...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set switch sampling: %w", err)
+		}
+		if err := guardResult(results, guard); err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				return nil, fmt.Errorf("transaction error: %s", result.Error)
+			}
+		}
+	}
+
+	result := make([]*models.ACLSampling, 0, len(sw.ACLs))
+	for _, aclUUID := range sw.ACLs {
+		aclSampling, err := c.GetACLSampling(ctx, aclUUID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, aclSampling)
+	}
+	return result, nil
+}
+
+// samplingPlan builds the operations setting the sampling of ACLs, keeping
+// track of the observation point IDs taken as it hands them out
+type samplingPlan struct {
+	c          *Client
+	collectors map[string]string // UUID or name to UUID
+	samples    map[string]nbdb.Sample
+	used       map[int]bool // Observation point IDs
+	updated    map[string]bool
+	next       int
+	ops        []ovsdb.Operation
+}
+
+func (c *Client) newSamplingPlan(ctx context.Context) (*samplingPlan, error) {
+	collectors := []nbdb.SampleCollector{}
+	if err := c.nbClient.List(ctx, &collectors); err != nil {
+		return nil, fmt.Errorf("failed to list sample collectors: %w", err)
+	}
+	samples, err := c.sampleRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &samplingPlan{
+		c:          c,
+		collectors: make(map[string]string, 2*len(collectors)),
+		samples:    samples,
+		used:       make(map[int]bool, len(samples)),
+		updated:    make(map[string]bool),
+		next:       1,
+	}
+	for _, collector := range collectors {
+		plan.collectors[collector.UUID] = collector.UUID
+		plan.collectors[collector.Name] = collector.UUID
+	}
+	for _, sample := range samples {
+		plan.used[sample.Metadata] = true
+	}
+	return plan, nil
+}
+
+// setACL adds the operations setting the sampling of acl
+func (p *samplingPlan) setACL(acl *nbdb.ACL, sampling *models.ACLSampling) error {
+	sampleNew, err := p.sample(acl.SampleNew, sampling.New)
+	if err != nil {
+		return err
+	}
+	sampleEst, err := p.sample(acl.SampleEst, sampling.Established)
+	if err != nil {
+		return err
+	}
+
+	row := &nbdb.ACL{UUID: acl.UUID, SampleNew: sampleNew, SampleEst: sampleEst}
+	ops, err := p.c.nbClient.Where(row).Update(row, &row.SampleNew, &row.SampleEst)
+	if err != nil {
+		return fmt.Errorf("failed to create ACL update operations: %w", err)
+	}
+	p.ops = append(p.ops, ops...)
+	return nil
+}
+
+// sample returns the sample the ACL column holding current should refer to
+// for want. The current sample is kept, with want's collectors, unless want
+// asks for another observation point ID. Samples no longer referred to are
+// garbage collected by OVSDB.
+func (p *samplingPlan) sample(current *string, want *models.FlowSample) (*string, error) {
+	if want == nil {
+		return nil, nil
+	}
+	if want.ObservationPointID < 0 || int64(want.ObservationPointID) > maxObservationPointID {
+		return nil, fmt.Errorf("invalid sampling: observation point ID must be between 1 and %d", int64(maxObservationPointID))
+	}
+	if len(want.Collectors) == 0 {
+		return nil, fmt.Errorf("invalid sampling: at least one collector is required")
+	}
+	collectors := make([]string, 0, len(want.Collectors))
+	seen := make(map[string]bool, len(want.Collectors))
+	for _, id := range want.Collectors {
+		collectorUUID, ok := p.collectors[id]
+		if !ok {
+			return nil, fmt.Errorf("sample collector %s not found", id)
+		}
+		if !seen[collectorUUID] {
+			seen[collectorUUID] = true
+			collectors = append(collectors, collectorUUID)
+		}
+	}
+	sort.Strings(collectors)
+
+	// A sample shared with another column already updated in this plan
+	// can't take different collectors, so it's replaced instead
+	if current != nil && !p.updated[*current] {
+		if existing, ok := p.samples[*current]; ok && (want.ObservationPointID == 0 || want.ObservationPointID == existing.Metadata) {
+			row := &nbdb.Sample{UUID: existing.UUID, Collectors: collectors}
+			ops, err := p.c.nbClient.Where(row).Update(row, &row.Collectors)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create sample update operations: %w", err)
+			}
+			p.ops = append(p.ops, ops...)
+			p.updated[existing.UUID] = true
+			return current, nil
+		}
+	}
+
+	metadata := want.ObservationPointID
+	if metadata == 0 {
+		for p.used[p.next] {
+			p.next++
+		}
+		metadata = p.next
+	} else if p.used[metadata] {
+		return nil, fmt.Errorf("observation point ID %d already exists", metadata)
+	}
+	p.used[metadata] = true
+
+	row := &nbdb.Sample{UUID: uuid.New().String(), Collectors: collectors, Metadata: metadata}
+	ops, err := p.c.nbClient.Create(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sample operations: %w", err)
+	}
+	p.ops = append(p.ops, ops...)
+	p.updated[row.UUID] = true
+	return &row.UUID, nil
+}
+
+// sampleCollectorRow returns a sample collector by UUID or name
+func (c *Client) sampleCollectorRow(ctx context.Context, id string) (*nbdb.SampleCollector, error) {
+	row := &nbdb.SampleCollector{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err == nil {
+		return row, nil
+	}
+
+	rows := []nbdb.SampleCollector{}
+	err := c.nbClient.WhereCache(func(collector *nbdb.SampleCollector) bool {
+		return collector.Name == id
+	}).List(ctx, &rows)
+	if err != nil || len(rows) == 0 {
+		return nil, fmt.Errorf("sample collector %s not found", id)
+	}
+	return &rows[0], nil
+}
+
+// sampleRows returns all samples keyed by UUID
+func (c *Client) sampleRows(ctx context.Context) (map[string]nbdb.Sample, error) {
+	rows := []nbdb.Sample{}
+	if err := c.nbClient.List(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to list samples: %w", err)
+	}
+	samples := make(map[string]nbdb.Sample, len(rows))
+	for _, row := range rows {
+		samples[row.UUID] = row
+	}
+	return samples, nil
+}
+
+// tracksConnections reports whether OVN tracks the connections acl allows,
+// so that it has established connections to sample
+func tracksConnections(acl *nbdb.ACL) bool {
+	return acl.Action == nbdb.ACLActionAllow || acl.Action == nbdb.ACLActionAllowRelated
+}
+
+// validateSampleCollector validates a sample collector with its ID set
+func validateSampleCollector(collector *models.SampleCollector) error {
+	if collector.Name == "" {
+		return fmt.Errorf("invalid sample collector: name is required")
+	}
+	if collector.ID < 1 || collector.ID > maxSamplingID {
+		return fmt.Errorf("invalid sample collector: ID must be between 1 and %d", maxSamplingID)
+	}
+	if collector.SetID < 1 || int64(collector.SetID) > maxObservationPointID {
+		return fmt.Errorf("invalid sample collector: set ID must be between 1 and %d", int64(maxObservationPointID))
+	}
+	if collector.Probability == nil {
+		return fmt.Errorf("invalid sample collector: probability is required")
+	}
+	if *collector.Probability < 0 || *collector.Probability > maxSampleProbability {
+		return fmt.Errorf("invalid sample collector: probability must be between 0 and %d", maxSampleProbability)
+	}
+	return nil
+}
+
+// convertSampleCollector converts an OVN Sample_Collector row to our model
+func convertSampleCollector(row *nbdb.SampleCollector) *models.SampleCollector {
+	probability := row.Probability
+	return &models.SampleCollector{
+		UUID:        row.UUID,
+		ID:          row.ID,
+		Name:        row.Name,
+		SetID:       row.SetID,
+		Probability: &probability,
+		ExternalIDs: row.ExternalIDs,
+	}
+}
+
+// convertACLSampling reports the samples acl refers to
+func convertACLSampling(acl *nbdb.ACL, samples map[string]nbdb.Sample) *models.ACLSampling {
+	convert := func(ref *string) *models.FlowSample {
+		if ref == nil {
+			return nil
+		}
+		sample, ok := samples[*ref]
+		if !ok {
+			return nil
+		}
+		collectors := append([]string{}, sample.Collectors...)
+		sort.Strings(collectors)
+		return &models.FlowSample{ObservationPointID: sample.Metadata, Collectors: collectors}
+	}
+	return &models.ACLSampling{
+		ACLID:       acl.UUID,
+		New:         convert(acl.SampleNew),
+		Established: convert(acl.SampleEst),
+	}
+}
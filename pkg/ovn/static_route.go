@@ -0,0 +1,585 @@
+package ovn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// discardNexthop is the nexthop of routes dropping the traffic they match
+const discardNexthop = "discard"
+
+// ecmpSymmetricReplyOption is the static route option sending replies of an
+// ECMP route's connections back through the nexthop the request came from
+const ecmpSymmetricReplyOption = "ecmp_symmetric_reply"
+
+// ListStaticRoutes returns the static routes of a logical router, ordered by
+// route table, prefix and nexthop
+func (c *Client) ListStaticRoutes(ctx context.Context, routerID string) ([]*models.StaticRoute, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	router, err := c.logicalRouterRow(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+	bfd, err := c.bfdSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.StaticRoute, 0, len(router.StaticRoutes))
+	for _, routeUUID := range router.StaticRoutes {
+		row := &nbdb.LogicalRouterStaticRoute{UUID: routeUUID}
+		if err := c.nbClient.Get(ctx, row); err != nil {
+			continue
+		}
+		result = append(result, convertStaticRoute(row, router.UUID, bfd))
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.RouteTable != b.RouteTable {
+			return a.RouteTable < b.RouteTable
+		}
+		if a.IPPrefix != b.IPPrefix {
+			return a.IPPrefix < b.IPPrefix
+		}
+		return a.Nexthop < b.Nexthop
+	})
+	return result, nil
+}
+
+// GetStaticRoute returns a static route by UUID
+func (c *Client) GetStaticRoute(ctx context.Context, id string) (*models.StaticRoute, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	row := &nbdb.LogicalRouterStaticRoute{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return nil, fmt.Errorf("static route %s not found", id)
+	}
+	router, err := c.staticRouteRouter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	bfd, err := c.bfdSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertStaticRoute(row, router.UUID, bfd), nil
+}
+
+// CreateStaticRoutes adds routes to a logical router in a single
+// transaction, so either all of them are added or none is. Routes with BFD
+// enabled share the BFD session of their output port and nexthop.
+func (c *Client) CreateStaticRoutes(ctx context.Context, routerID string, routes []*models.StaticRoute) ([]*models.StaticRoute, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	router, err := c.logicalRouterRow(ctx, routerID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.staticRouteRows(ctx, router)
+	if err != nil {
+		return nil, err
+	}
+	bfd, err := c.bfdSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(existing)+len(routes))
+	for _, row := range existing {
+		keys[staticRouteKey(row)] = true
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	var ops []ovsdb.Operation
+	rows := make([]*nbdb.LogicalRouterStaticRoute, 0, len(routes))
+	for i, route := range routes {
+		if err := validateStaticRoute(route); err != nil {
+			return nil, fmt.Errorf("route %d: %w", i, err)
+		}
+
+		row := newStaticRouteRow(route, now)
+		key := staticRouteKey(row)
+		if keys[key] {
+			return nil, fmt.Errorf("static route %s via %s already exists", row.IPPrefix, row.Nexthop)
+		}
+		keys[key] = true
+
+		if route.BFD != nil && *route.BFD {
+			bfdOps, err := c.useBFDSession(row, bfd)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, bfdOps...)
+		}
+
+		createOps, err := c.nbClient.Create(row)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create static route operations: %w", err)
+		}
+		ops = append(ops, createOps...)
+		rows = append(rows, row)
+	}
+
+	routeUUIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		routeUUIDs = append(routeUUIDs, row.UUID)
+	}
+	lr := &nbdb.LogicalRouter{UUID: router.UUID}
+	mutateOps, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.StaticRoutes,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   routeUUIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router mutate operations: %w", err)
+	}
+	ops = append(ops, mutateOps...)
+
+	results, err := c.Transact(ctx, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create static routes: %w", err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	created := make([]*models.StaticRoute, 0, len(rows))
+	for _, row := range rows {
+		created = append(created, convertStaticRoute(row, router.UUID, bfd))
+	}
+	return created, nil
+}
+
+// UpdateStaticRoute updates a static route. Fields left out keep their
+// value; an empty output port or policy clears it.
+func (c *Client) UpdateStaticRoute(ctx context.Context, id string, updates *models.StaticRoute) (*models.StaticRoute, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	existing, err := c.GetStaticRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardStaticRoute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *existing
+	if updates.IPPrefix != "" {
+		merged.IPPrefix = updates.IPPrefix
+	}
+	if updates.Nexthop != "" {
+		merged.Nexthop = updates.Nexthop
+	}
+	if updates.OutputPort != nil {
+		merged.OutputPort = updates.OutputPort
+		if *updates.OutputPort == "" {
+			merged.OutputPort = nil
+		}
+	}
+	if updates.Policy != nil {
+		merged.Policy = updates.Policy
+		if *updates.Policy == "" {
+			merged.Policy = nil
+		}
+	}
+	if updates.RouteTable != "" {
+		merged.RouteTable = updates.RouteTable
+	}
+	if updates.BFD != nil {
+		merged.BFD = updates.BFD
+	}
+	if updates.ECMPSymmetricReply != nil {
+		merged.ECMPSymmetricReply = updates.ECMPSymmetricReply
+	}
+	merged.ExternalIDs = updatedExternalIDs(existing.ExternalIDs, updates.ExternalIDs, time.Now())
+	if err := validateStaticRoute(&merged); err != nil {
+		return nil, err
+	}
+
+	current := &nbdb.LogicalRouterStaticRoute{UUID: id}
+	if err := c.nbClient.Get(ctx, current); err != nil {
+		return nil, fmt.Errorf("static route %s not found", id)
+	}
+	router, err := c.staticRouteRouter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := c.staticRouteRows(ctx, router)
+	if err != nil {
+		return nil, err
+	}
+
+	row := newStaticRouteRow(&merged, "")
+	row.UUID = id
+	row.Options = current.Options
+	if row.Options == nil {
+		row.Options = make(map[string]string)
+	}
+	if merged.ECMPSymmetricReply != nil && *merged.ECMPSymmetricReply {
+		row.Options[ecmpSymmetricReplyOption] = "true"
+	} else {
+		delete(row.Options, ecmpSymmetricReplyOption)
+	}
+	for _, sibling := range siblings {
+		if sibling.UUID != id && staticRouteKey(sibling) == staticRouteKey(row) {
+			return nil, fmt.Errorf("static route %s via %s already exists", row.IPPrefix, row.Nexthop)
+		}
+	}
+
+	bfd, err := c.bfdSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ops []ovsdb.Operation
+	if merged.BFD != nil && *merged.BFD {
+		bfdOps, err := c.useBFDSession(row, bfd)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, bfdOps...)
+	}
+	updateOps, err := c.nbClient.Where(row).Update(row, &row.IPPrefix, &row.Nexthop, &row.OutputPort,
+		&row.Policy, &row.RouteTable, &row.BFD, &row.Options, &row.ExternalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create update operations: %w", err)
+	}
+	ops = append(ops, updateOps...)
+	if current.BFD != nil && (row.BFD == nil || *row.BFD != *current.BFD) {
+		cleanupOps, err := c.releaseBFDSession(ctx, *current.BFD, id)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, cleanupOps...)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update static route: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return c.GetStaticRoute(ctx, id)
+}
+
+// DeleteStaticRoute removes a static route from its logical router, along
+// with its BFD session unless another route uses it
+func (c *Client) DeleteStaticRoute(ctx context.Context, id string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	row := &nbdb.LogicalRouterStaticRoute{UUID: id}
+	if err := c.nbClient.Get(ctx, row); err != nil {
+		return fmt.Errorf("static route %s not found", id)
+	}
+	router, err := c.staticRouteRouter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the change if a caller's precondition no longer holds
+	guard, err := c.guardStaticRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	lr := &nbdb.LogicalRouter{UUID: router.UUID}
+	ops, err := c.nbClient.Where(lr).Mutate(lr, model.Mutation{
+		Field:   &lr.StaticRoutes,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{id},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create router mutate operations: %w", err)
+	}
+	deleteOps, err := c.nbClient.Where(&nbdb.LogicalRouterStaticRoute{UUID: id}).Delete()
+	if err != nil {
+		return fmt.Errorf("failed to create delete operations: %w", err)
+	}
+	ops = append(ops, deleteOps...)
+	if row.BFD != nil {
+		cleanupOps, err := c.releaseBFDSession(ctx, *row.BFD, id)
+		if err != nil {
+			return err
+		}
+		ops = append(ops, cleanupOps...)
+	}
+
+	results, err := c.Transact(ctx, append(guard, ops...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete static route: %w", err)
+	}
+	if err := guardResult(results, guard); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("transaction error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// logicalRouterRow returns a logical router row by UUID or name
+func (c *Client) logicalRouterRow(ctx context.Context, id string) (*nbdb.LogicalRouter, error) {
+	lrList := []nbdb.LogicalRouter{}
+	if err := c.nbClient.List(ctx, &lrList); err != nil {
+		return nil, fmt.Errorf("failed to list logical routers: %w", err)
+	}
+	for i := range lrList {
+		if lrList[i].UUID == id || lrList[i].Name == id {
+			return &lrList[i], nil
+		}
+	}
+	return nil, fmt.Errorf("logical router %s not found", id)
+}
+
+// staticRouteRouter returns the logical router a static route belongs to
+func (c *Client) staticRouteRouter(ctx context.Context, routeUUID string) (*nbdb.LogicalRouter, error) {
+	routers := []nbdb.LogicalRouter{}
+	err := c.nbClient.WhereCache(func(lr *nbdb.LogicalRouter) bool {
+		for _, id := range lr.StaticRoutes {
+			if id == routeUUID {
+				return true
+			}
+		}
+		return false
+	}).List(ctx, &routers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find router for static route: %w", err)
+	}
+	if len(routers) == 0 {
+		return nil, fmt.Errorf("static route %s is not attached to any router", routeUUID)
+	}
+	return &routers[0], nil
+}
+
+// staticRouteRows returns the static route rows of a router
+func (c *Client) staticRouteRows(ctx context.Context, router *nbdb.LogicalRouter) ([]*nbdb.LogicalRouterStaticRoute, error) {
+	rows := make([]*nbdb.LogicalRouterStaticRoute, 0, len(router.StaticRoutes))
+	for _, routeUUID := range router.StaticRoutes {
+		row := &nbdb.LogicalRouterStaticRoute{UUID: routeUUID}
+		if err := c.nbClient.Get(ctx, row); err != nil {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// bfdSessions returns the BFD sessions, keyed by UUID
+func (c *Client) bfdSessions(ctx context.Context) (map[string]*nbdb.BFD, error) {
+	bfdList := []nbdb.BFD{}
+	if err := c.nbClient.List(ctx, &bfdList); err != nil {
+		return nil, fmt.Errorf("failed to list BFD sessions: %w", err)
+	}
+	sessions := make(map[string]*nbdb.BFD, len(bfdList))
+	for i := range bfdList {
+		sessions[bfdList[i].UUID] = &bfdList[i]
+	}
+	return sessions, nil
+}
+
+// useBFDSession points a route at the BFD session of its output port and
+// nexthop, returning the operations creating the session if there is none
+// yet. New sessions are added to sessions, so routes created together share
+// them.
+func (c *Client) useBFDSession(row *nbdb.LogicalRouterStaticRoute, sessions map[string]*nbdb.BFD) ([]ovsdb.Operation, error) {
+	for _, session := range sessions {
+		if session.LogicalPort == *row.OutputPort && session.DstIP == row.Nexthop {
+			row.BFD = &session.UUID
+			return nil, nil
+		}
+	}
+
+	session := &nbdb.BFD{
+		UUID:        uuid.New().String(),
+		LogicalPort: *row.OutputPort,
+		DstIP:       row.Nexthop,
+	}
+	ops, err := c.nbClient.Create(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BFD session operations: %w", err)
+	}
+	sessions[session.UUID] = session
+	row.BFD = &session.UUID
+	return ops, nil
+}
+
+// releaseBFDSession returns the operations deleting a BFD session that no
+// static route other than routeUUID uses
+func (c *Client) releaseBFDSession(ctx context.Context, bfdUUID, routeUUID string) ([]ovsdb.Operation, error) {
+	users := []nbdb.LogicalRouterStaticRoute{}
+	err := c.nbClient.WhereCache(func(route *nbdb.LogicalRouterStaticRoute) bool {
+		return route.UUID != routeUUID && route.BFD != nil && *route.BFD == bfdUUID
+	}).List(ctx, &users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list static routes: %w", err)
+	}
+	if len(users) > 0 {
+		return nil, nil
+	}
+
+	ops, err := c.nbClient.Where(&nbdb.BFD{UUID: bfdUUID}).Delete()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BFD session delete operations: %w", err)
+	}
+	return ops, nil
+}
+
+// validateStaticRoute checks a route's prefix, nexthop and policy, and that
+// BFD has an output port and nexthop to monitor
+func validateStaticRoute(route *models.StaticRoute) error {
+	prefixIP, err := prefixAddress(route.IPPrefix)
+	if err != nil {
+		return err
+	}
+
+	if route.Nexthop != discardNexthop {
+		nexthop := net.ParseIP(route.Nexthop)
+		if nexthop == nil {
+			return fmt.Errorf("invalid static route nexthop %q", route.Nexthop)
+		}
+		if (nexthop.To4() != nil) != (prefixIP.To4() != nil) {
+			return fmt.Errorf("invalid static route: nexthop %s and prefix %s are of different address families", route.Nexthop, route.IPPrefix)
+		}
+	}
+
+	if route.Policy != nil && *route.Policy != "" &&
+		*route.Policy != nbdb.LogicalRouterStaticRoutePolicyDstIP && *route.Policy != nbdb.LogicalRouterStaticRoutePolicySrcIP {
+		return fmt.Errorf("invalid static route policy %q: must be dst-ip or src-ip", *route.Policy)
+	}
+
+	if route.BFD != nil && *route.BFD {
+		if route.OutputPort == nil || *route.OutputPort == "" {
+			return fmt.Errorf("invalid static route: BFD requires an output port")
+		}
+		if route.Nexthop == discardNexthop {
+			return fmt.Errorf("invalid static route: BFD requires a nexthop address")
+		}
+	}
+	return nil
+}
+
+// prefixAddress parses a route prefix, an IP address or a network
+func prefixAddress(prefix string) (net.IP, error) {
+	if ip := net.ParseIP(prefix); ip != nil {
+		return ip, nil
+	}
+	ip, _, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid static route prefix %q", prefix)
+	}
+	return ip, nil
+}
+
+// normalizePrefix clears the host bits of a network prefix, as ovn-nbctl
+// does, so "10.0.0.1/24" and "10.0.0.0/24" are the same route
+func normalizePrefix(prefix string) string {
+	if ip := net.ParseIP(prefix); ip != nil {
+		return ip.String()
+	}
+	if _, network, err := net.ParseCIDR(prefix); err == nil {
+		return network.String()
+	}
+	return prefix
+}
+
+// newStaticRouteRow builds the row of a validated route. now, if set, is
+// recorded as its creation time.
+func newStaticRouteRow(route *models.StaticRoute, now string) *nbdb.LogicalRouterStaticRoute {
+	row := &nbdb.LogicalRouterStaticRoute{
+		UUID:        uuid.New().String(),
+		IPPrefix:    normalizePrefix(route.IPPrefix),
+		Nexthop:     route.Nexthop,
+		RouteTable:  route.RouteTable,
+		ExternalIDs: make(map[string]string, len(route.ExternalIDs)+2),
+		Options:     make(map[string]string),
+	}
+	if route.OutputPort != nil && *route.OutputPort != "" {
+		outputPort := *route.OutputPort
+		row.OutputPort = &outputPort
+	}
+	if route.Policy != nil && *route.Policy != "" {
+		policy := *route.Policy
+		row.Policy = &policy
+	}
+	if route.ECMPSymmetricReply != nil && *route.ECMPSymmetricReply {
+		row.Options[ecmpSymmetricReplyOption] = "true"
+	}
+	for k, v := range route.ExternalIDs {
+		row.ExternalIDs[k] = v
+	}
+	if now != "" {
+		row.ExternalIDs["created_at"] = now
+		row.ExternalIDs["updated_at"] = now
+	}
+	return row
+}
+
+// staticRouteKey identifies the routes OVN can't tell apart: the same
+// prefix, nexthop and policy in the same route table
+func staticRouteKey(row *nbdb.LogicalRouterStaticRoute) string {
+	policy := nbdb.LogicalRouterStaticRoutePolicyDstIP
+	if row.Policy != nil {
+		policy = *row.Policy
+	}
+	return row.RouteTable + "|" + policy + "|" + normalizePrefix(row.IPPrefix) + "|" + row.Nexthop
+}
+
+// convertStaticRoute converts an OVN static route to our model
+func convertStaticRoute(row *nbdb.LogicalRouterStaticRoute, routerID string, sessions map[string]*nbdb.BFD) *models.StaticRoute {
+	bfd := row.BFD != nil
+	symmetric, _ := strconv.ParseBool(row.Options[ecmpSymmetricReplyOption])
+	route := &models.StaticRoute{
+		UUID:               row.UUID,
+		RouterID:           routerID,
+		IPPrefix:           row.IPPrefix,
+		Nexthop:            row.Nexthop,
+		OutputPort:         row.OutputPort,
+		Policy:             row.Policy,
+		RouteTable:         row.RouteTable,
+		BFD:                &bfd,
+		ECMPSymmetricReply: &symmetric,
+		ExternalIDs:        row.ExternalIDs,
+	}
+	if bfd {
+		if session, ok := sessions[*row.BFD]; ok && session.Status != nil {
+			route.BFDStatus = *session.Status
+		}
+	}
+	return route
+}
@@ -0,0 +1,118 @@
+package ovn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/lspecian/ovncp/internal/config"
+)
+
+// tlsReloader holds the client certificate and CA pool used for OVSDB
+// connections so they can be swapped without recreating the client
+type tlsReloader struct {
+	cfg *config.OVNTLSConfig
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	rootCAs *x509.CertPool
+}
+
+func newTLSReloader(cfg *config.OVNTLSConfig) (*tlsReloader, error) {
+	r := &tlsReloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads the certificate, key and CA files from disk. The previous
+// material stays in use if any file fails to load.
+func (r *tlsReloader) Reload() error {
+	var cert *tls.Certificate
+	if r.cfg.CertFile != "" {
+		loaded, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load OVN client certificate: %w", err)
+		}
+		cert = &loaded
+	}
+
+	var rootCAs *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read OVN CA file: %w", err)
+		}
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in OVN CA file %s", r.cfg.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.rootCAs = rootCAs
+	r.mu.Unlock()
+
+	return nil
+}
+
+// TLSConfig builds a tls.Config whose certificate and CA lookups go through
+// the reloader, so new handshakes pick up reloaded material
+func (r *tlsReloader) TLSConfig() *tls.Config {
+	tlsCfg := &tls.Config{
+		ServerName: r.cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			if r.cert == nil {
+				// An empty certificate tells the server we have none
+				return &tls.Certificate{}, nil
+			}
+			return r.cert, nil
+		},
+	}
+
+	if r.cfg.MinVersion == "1.3" {
+		tlsCfg.MinVersion = tls.VersionTLS13
+	}
+
+	// Built-in verification uses a fixed RootCAs pool, so verification is
+	// done in VerifyConnection against the current pool instead
+	tlsCfg.InsecureSkipVerify = true
+	if !r.cfg.InsecureSkipVerify {
+		tlsCfg.VerifyConnection = r.verifyConnection
+	}
+
+	return tlsCfg
+}
+
+func (r *tlsReloader) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("OVSDB server presented no certificate")
+	}
+
+	r.mu.RLock()
+	rootCAs := r.rootCAs
+	r.mu.RUnlock()
+
+	opts := x509.VerifyOptions{
+		Roots:         rootCAs,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify OVSDB server certificate: %w", err)
+	}
+
+	return nil
+}
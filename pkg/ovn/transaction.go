@@ -0,0 +1,1328 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+
+	"github.com/lspecian/ovncp/internal/metrics"
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// TxOp is one operation of a transaction run by ExecuteTransaction
+type TxOp struct {
+	// Ref names the operation, so that later operations of the same
+	// transaction can refer to the resource it creates as "$<ref>"
+	Ref        string
+	Operation  string // models.OperationCreate, OperationUpdate or OperationDelete
+	Resource   string // one of the models.Resource types
+	ResourceID string // resource to update or delete; set to the new UUID by creates
+	SwitchID   string // switch of a new port, ACL or QoS rule
+	RouterID   string // router of a new NAT rule
+
+	// Data is the resource to create, or the fields to update, as the
+	// model of Resource: *models.LogicalSwitch, *models.NAT, *models.QoS and
+	// so on. It is updated in place with the resulting resource.
+	Data interface{}
+}
+
+// TxError reports the operation that made a transaction fail
+type TxError struct {
+	Index int // index of the operation, or -1 if the commit as a whole failed
+	Err   error
+}
+
+func (e *TxError) Error() string {
+	if e.Index < 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteTransaction applies ops in a single OVSDB transaction, so that
+// either all of them take effect or none does. Failures are reported as a
+// *TxError naming the operation responsible.
+//
+// Transactions failing with a retryable error are retried with exponential
+// backoff, up to config.TxMaxAttempts attempts. Each attempt rebuilds the
+// OVSDB operations from the current cache, so that changes committed
+// concurrently are taken into account rather than overwritten. When an
+// attempt may have been committed despite failing, e.g. when the connection
+// dropped before the reply, the next one first checks whether its rows
+// were inserted and deleted, and succeeds without committing them twice.
+func (c *Client) ExecuteTransaction(ctx context.Context, ops []TxOp) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("no operations provided")
+	}
+
+	// Building operations updates their data in place; it is restored
+	// before each retry
+	saved := make([]interface{}, len(ops))
+	for i := range ops {
+		saved[i] = copyTxData(ops[i].Data)
+	}
+
+	maxAttempts := c.config.TxMaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	delay := c.config.TxRetryBackoff
+	var previous *txAttempt
+	for attempt := 1; ; attempt++ {
+		result, err := c.executeTransaction(ctx, ops, saved, previous)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("OVSDB transaction succeeded on attempt %d", attempt)
+			}
+			return nil
+		}
+		reason := retryableTxError(ctx, err)
+		if reason == "" {
+			return err
+		}
+		if attempt >= maxAttempts {
+			if maxAttempts > 1 {
+				return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+
+		metrics.RecordOVSDBTransactionRetry(reason)
+		c.txCounters.retried.Add(1)
+		log.Printf("OVSDB transaction attempt %d of %d failed (%s), retrying in %s: %v",
+			attempt, maxAttempts, reason, delay, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction retry aborted: %w", err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if c.config.TxRetryMaxDelay > 0 && delay > c.config.TxRetryMaxDelay {
+			delay = c.config.TxRetryMaxDelay
+		}
+		previous = result
+	}
+}
+
+// txAttempt is an attempt of ExecuteTransaction: the rows it inserts and
+// deletes, and the UUIDs of the resources created by each operation
+type txAttempt struct {
+	inserted []txRow
+	deleted  []txRow
+	created  []string
+}
+
+// txRow is a row of a table
+type txRow struct {
+	table string
+	uuid  string
+}
+
+// executeTransaction makes an attempt of ExecuteTransaction. If a previous
+// attempt failed, it is looked for in the cache first, and the data of ops
+// restored from saved otherwise.
+func (c *Client) executeTransaction(ctx context.Context, ops []TxOp, saved []interface{}, previous *txAttempt) (*txAttempt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	if previous != nil {
+		if c.committed(previous) {
+			log.Printf("OVSDB transaction found committed by a previous attempt")
+			metrics.RecordOVSDBTransactionRetry("committed")
+			setCreated(ops, previous.created)
+			return previous, nil
+		}
+		for i := range ops {
+			restoreTxData(ops[i].Data, saved[i])
+		}
+	}
+
+	tx := &transaction{
+		c:    c,
+		ctx:  ctx,
+		now:  time.Now(),
+		refs: make(map[string]txRef),
+	}
+
+	// first[i] is the index of the first OVSDB operation of ops[i]
+	first := make([]int, len(ops)+1)
+	attempt := &txAttempt{created: make([]string, len(ops))}
+	for i := range ops {
+		first[i] = len(tx.ops)
+		id, err := tx.add(&ops[i])
+		if err != nil {
+			return nil, &TxError{Index: i, Err: err}
+		}
+		attempt.created[i] = id
+	}
+	first[len(ops)] = len(tx.ops)
+	attempt.inserted, attempt.deleted = txRows(tx.ops)
+
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+	results, err := c.transact(ctx, tx.ops...)
+	if err != nil {
+		return attempt, fmt.Errorf("failed to execute transaction: %w", err)
+	}
+
+	for j, result := range results {
+		if result.Error == "" {
+			continue
+		}
+		// Results past the operations report errors of the commit itself
+		index := -1
+		for i := range ops {
+			if j >= first[i] && j < first[i+1] {
+				index = i
+				break
+			}
+		}
+		return attempt, &TxError{Index: index, Err: &ovsdbResultError{result: result}}
+	}
+
+	setCreated(ops, attempt.created)
+	return attempt, nil
+}
+
+// setCreated sets the resource IDs of the creates among ops
+func setCreated(ops []TxOp, created []string) {
+	for i := range ops {
+		if created[i] != "" {
+			ops[i].ResourceID = created[i]
+		}
+	}
+}
+
+// committed reports whether the rows an attempt inserts are in the cache
+// and those it deletes are not. Attempts only updating rows are never
+// found committed; they are idempotent and simply retried.
+func (c *Client) committed(attempt *txAttempt) bool {
+	if len(attempt.inserted) == 0 && len(attempt.deleted) == 0 {
+		return false
+	}
+	cache := c.nbClient.Cache()
+	if cache == nil {
+		return false
+	}
+	exists := func(row txRow) bool {
+		table := cache.Table(row.table)
+		return table != nil && table.Row(row.uuid) != nil
+	}
+	for _, row := range attempt.inserted {
+		if !exists(row) {
+			return false
+		}
+	}
+	for _, row := range attempt.deleted {
+		if exists(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// txRows lists the rows inserted and deleted by OVSDB operations, by their
+// UUID
+func txRows(ops []ovsdb.Operation) (inserted, deleted []txRow) {
+	for _, op := range ops {
+		switch op.Op {
+		case ovsdb.OperationInsert:
+			if op.UUID != "" {
+				inserted = append(inserted, txRow{table: op.Table, uuid: op.UUID})
+			}
+		case ovsdb.OperationDelete:
+			for _, cond := range op.Where {
+				if id, ok := cond.Value.(ovsdb.UUID); ok && cond.Column == "_uuid" && cond.Function == ovsdb.ConditionEqual {
+					deleted = append(deleted, txRow{table: op.Table, uuid: id.GoUUID})
+				}
+			}
+		}
+	}
+	return inserted, deleted
+}
+
+// ovsdbResultError is an error reported in the result of an OVSDB
+// operation, or of the commit
+type ovsdbResultError struct {
+	result ovsdb.OperationResult
+}
+
+func (e *ovsdbResultError) Error() string {
+	return fmt.Sprintf("transaction error: %s: %s", e.result.Error, e.result.Details)
+}
+
+// retryableTxError returns why a failed transaction may be retried: the
+// connection dropped ("disconnected"), e.g. on a change of the cluster
+// leader, the attempt timed out ("timeout"), or the clustered database
+// could not commit it ("cluster_error"), e.g. for losing the leadership or
+// conflicting with another transaction. It returns "" for errors a retry
+// can't fix, and once ctx is done.
+func retryableTxError(ctx context.Context, err error) string {
+	if ctx.Err() != nil {
+		return ""
+	}
+	var resultErr *ovsdbResultError
+	if errors.As(err, &resultErr) {
+		switch resultErr.result.Error {
+		case "cluster error":
+			return "cluster_error"
+		case "timed out":
+			return "timeout"
+		}
+		return ""
+	}
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		// Operations that could not be built fail the same way again
+		return ""
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, client.ErrNotConnected), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, net.ErrClosed), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return "disconnected"
+	}
+	return ""
+}
+
+// copyTxData returns a copy of the struct data points to, or nil
+func copyTxData(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	saved := reflect.New(v.Elem().Type())
+	saved.Elem().Set(v.Elem())
+	return saved.Interface()
+}
+
+// restoreTxData copies the struct saved by copyTxData back into data
+func restoreTxData(data, saved interface{}) {
+	if saved == nil {
+		return
+	}
+	reflect.ValueOf(data).Elem().Set(reflect.ValueOf(saved).Elem())
+}
+
+// txRef is a resource created earlier in a transaction
+type txRef struct {
+	uuid     string
+	resource string
+}
+
+// transaction collects the OVSDB operations of ExecuteTransaction
+type transaction struct {
+	c    *Client
+	ctx  context.Context
+	now  time.Time
+	ops  []ovsdb.Operation
+	refs map[string]txRef
+}
+
+// add appends the OVSDB operations of op, returning the UUID of the
+// resource it creates
+func (tx *transaction) add(op *TxOp) (string, error) {
+	switch op.Operation {
+	case models.OperationCreate:
+	case models.OperationUpdate, models.OperationDelete:
+		if op.ResourceID == "" {
+			return "", fmt.Errorf("resource ID is required for %s", op.Operation)
+		}
+		if strings.HasPrefix(op.ResourceID, "$") {
+			return "", fmt.Errorf("cannot %s %s, resources created in the same transaction can only be referenced as parents", op.Operation, op.ResourceID)
+		}
+	default:
+		return "", fmt.Errorf("unknown operation: %s", op.Operation)
+	}
+
+	if op.Data == nil && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("data is required for %s", op.Operation)
+	}
+
+	var id string
+	var err error
+	switch op.Resource {
+	case models.ResourceSwitch:
+		id, err = tx.logicalSwitch(op)
+	case models.ResourceRouter:
+		id, err = tx.logicalRouter(op)
+	case models.ResourcePort:
+		id, err = tx.port(op)
+	case models.ResourceACL:
+		id, err = tx.acl(op)
+	case models.ResourceLoadBalancer:
+		id, err = tx.loadBalancer(op)
+	case models.ResourceNAT:
+		id, err = tx.nat(op)
+	case models.ResourcePortGroup:
+		id, err = tx.portGroup(op)
+	case models.ResourceAddressSet:
+		id, err = tx.addressSet(op)
+	case models.ResourceDHCPOptions:
+		id, err = tx.dhcpOptions(op)
+	case models.ResourceQoS:
+		id, err = tx.qos(op)
+	default:
+		return "", fmt.Errorf("unknown resource type: %s", op.Resource)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if id != "" && op.Ref != "" {
+		tx.refs[op.Ref] = txRef{uuid: id, resource: op.Resource}
+	}
+	return id, nil
+}
+
+// append adds the operations built by a libovsdb call to the transaction
+func (tx *transaction) append(ops []ovsdb.Operation, err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to create operations: %w", err)
+	}
+	tx.ops = append(tx.ops, ops...)
+	return nil
+}
+
+// get loads row, which has its UUID set, from the cache
+func (tx *transaction) get(row model.Model, resource, id string) error {
+	if err := tx.c.nbClient.Get(tx.ctx, row); err != nil {
+		return fmt.Errorf("%s %s not found", resource, id)
+	}
+	return nil
+}
+
+// parent resolves the switch or router a resource is created on. id is
+// either the UUID of an existing row, loaded into row to check it exists,
+// or "$<ref>" of a create earlier in the transaction.
+func (tx *transaction) parent(id, resource string, row model.Model) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("%s ID is required", resource)
+	}
+	if strings.HasPrefix(id, "$") {
+		ref, ok := tx.refs[id[1:]]
+		if !ok || ref.resource != resource {
+			return "", fmt.Errorf("%s does not refer to a %s created earlier in the transaction", id, resource)
+		}
+		return ref.uuid, nil
+	}
+	if err := tx.get(row, resource, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// createdExternalIDs copies externalIDs, adding the creation timestamps and
+// ownership
+func (tx *transaction) createdExternalIDs(externalIDs map[string]string) map[string]string {
+	result := make(map[string]string, len(externalIDs)+2)
+	for k, v := range externalIDs {
+		result[k] = v
+	}
+	result["created_at"] = tx.now.Format(time.RFC3339)
+	result["updated_at"] = tx.now.Format(time.RFC3339)
+	stampCreated(tx.ctx, result)
+	return result
+}
+
+// updatedExternalIDs returns the external IDs of an updated row: updates if
+// given, otherwise the existing ones, keeping the creation timestamp and
+// creator
+func (tx *transaction) updatedExternalIDs(existing, updates map[string]string) map[string]string {
+	result := updatedExternalIDs(existing, updates, tx.now)
+	stampUpdated(tx.ctx, result)
+	return result
+}
+
+// updatedExternalIDs returns the external IDs of a row updated at now:
+// updates if given, otherwise the existing ones, keeping the creation
+// timestamp and creator
+func updatedExternalIDs(existing, updates map[string]string, now time.Time) map[string]string {
+	source := existing
+	if updates != nil {
+		source = updates
+	}
+	result := make(map[string]string, len(source)+2)
+	for k, v := range source {
+		result[k] = v
+	}
+	keepCreation(existing, result)
+	result["updated_at"] = now.Format(time.RFC3339)
+	return result
+}
+
+// detach removes uuid from the column of every row that references it.
+// rows lists the referencing rows of a table and column returns a pointer
+// to the column of row i.
+func (tx *transaction) detach(uuid string, rows int, row func(i int) model.Model, column func(i int) *[]string) error {
+	for i := 0; i < rows; i++ {
+		for _, ref := range *column(i) {
+			if ref != uuid {
+				continue
+			}
+			err := tx.append(tx.c.nbClient.Where(row(i)).Mutate(row(i), model.Mutation{
+				Field:   column(i),
+				Mutator: ovsdb.MutateOperationDelete,
+				Value:   []string{uuid},
+			}))
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// attach inserts uuid into a column of the row
+func (tx *transaction) attach(row model.Model, column *[]string, uuid string) error {
+	return tx.append(tx.c.nbClient.Where(row).Mutate(row, model.Mutation{
+		Field:   column,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{uuid},
+	}))
+}
+
+func (tx *transaction) logicalSwitch(op *TxOp) (string, error) {
+	ls, ok := op.Data.(*models.LogicalSwitch)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid switch data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if ls.Name == "" {
+			return "", fmt.Errorf("logical switch name is required")
+		}
+		ls.UUID = uuid.New().String()
+		ls.ExternalIDs = tx.createdExternalIDs(ls.ExternalIDs)
+		ls.CreatedAt, ls.UpdatedAt = tx.now, tx.now
+
+		return ls.UUID, tx.append(tx.c.nbClient.Create(&nbdb.LogicalSwitch{
+			UUID:        ls.UUID,
+			Name:        ls.Name,
+			OtherConfig: ls.OtherConfig,
+			ExternalIDs: ls.ExternalIDs,
+		}))
+
+	case models.OperationUpdate:
+		existing := &nbdb.LogicalSwitch{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical switch", op.ResourceID); err != nil {
+			return "", err
+		}
+		if ls.Name != "" {
+			existing.Name = ls.Name
+		}
+		if ls.OtherConfig != nil {
+			existing.OtherConfig = ls.OtherConfig
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, ls.ExternalIDs)
+		*ls = *convertLogicalSwitch(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Name, &existing.OtherConfig, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.LogicalSwitch{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical switch", op.ResourceID); err != nil {
+			return "", err
+		}
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) logicalRouter(op *TxOp) (string, error) {
+	lr, ok := op.Data.(*models.LogicalRouter)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid router data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if lr.Name == "" {
+			return "", fmt.Errorf("logical router name is required")
+		}
+		lr.UUID = uuid.New().String()
+		lr.ExternalIDs = tx.createdExternalIDs(lr.ExternalIDs)
+		lr.CreatedAt, lr.UpdatedAt = tx.now, tx.now
+
+		row := &nbdb.LogicalRouter{
+			UUID:        lr.UUID,
+			Name:        lr.Name,
+			Options:     lr.Options,
+			ExternalIDs: lr.ExternalIDs,
+		}
+
+		// Static routes are created with the router
+		for _, route := range lr.StaticRoutes {
+			sr := &nbdb.LogicalRouterStaticRoute{
+				UUID:       uuid.New().String(),
+				IPPrefix:   route.IPPrefix,
+				Nexthop:    route.Nexthop,
+				OutputPort: route.OutputPort,
+				Policy:     route.Policy,
+			}
+			if err := tx.append(tx.c.nbClient.Create(sr)); err != nil {
+				return "", err
+			}
+			row.StaticRoutes = append(row.StaticRoutes, sr.UUID)
+		}
+
+		return lr.UUID, tx.append(tx.c.nbClient.Create(row))
+
+	case models.OperationUpdate:
+		existing := &nbdb.LogicalRouter{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical router", op.ResourceID); err != nil {
+			return "", err
+		}
+		if lr.Name != "" {
+			existing.Name = lr.Name
+		}
+		if lr.Options != nil {
+			existing.Options = lr.Options
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, lr.ExternalIDs)
+		*lr = *convertLogicalRouter(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Name, &existing.Options, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.LogicalRouter{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical router", op.ResourceID); err != nil {
+			return "", err
+		}
+		if len(existing.Ports) > 0 {
+			return "", fmt.Errorf("cannot delete router: router has %d ports attached", len(existing.Ports))
+		}
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) port(op *TxOp) (string, error) {
+	port, ok := op.Data.(*models.LogicalSwitchPort)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid port data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch, &nbdb.LogicalSwitch{UUID: op.SwitchID})
+		if err != nil {
+			return "", err
+		}
+		if port.Name == "" {
+			return "", fmt.Errorf("port name is required")
+		}
+
+		existingPorts := []nbdb.LogicalSwitchPort{}
+		err = tx.c.nbClient.WhereCache(func(p *nbdb.LogicalSwitchPort) bool {
+			return p.Name == port.Name
+		}).List(tx.ctx, &existingPorts)
+		if err != nil {
+			return "", fmt.Errorf("failed to check existing ports: %w", err)
+		}
+		if len(existingPorts) > 0 {
+			return "", fmt.Errorf("port %s already exists", port.Name)
+		}
+
+		now := tx.now.Format(time.RFC3339)
+		row := newNBDBPort(uuid.New().String(), port, now)
+		stampCreated(tx.ctx, row.ExternalIDs)
+		if err := tx.append(tx.c.nbClient.Create(row)); err != nil {
+			return "", err
+		}
+
+		sw := &nbdb.LogicalSwitch{UUID: switchID}
+		if err := tx.attach(sw, &sw.Ports, row.UUID); err != nil {
+			return "", err
+		}
+
+		port.UUID = row.UUID
+		port.SwitchID = switchID
+		port.CreatedAt = parseTime(now)
+		port.UpdatedAt = parseTime(now)
+		port.Ownership = models.OwnershipFromExternalIDs(row.ExternalIDs)
+		return port.UUID, nil
+
+	case models.OperationUpdate:
+		existing := &nbdb.LogicalSwitchPort{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical switch port", op.ResourceID); err != nil {
+			return "", err
+		}
+		applyPortUpdates(existing, port)
+		stampUpdated(tx.ctx, existing.ExternalIDs)
+		*port = *tx.c.nbdbPortToModel(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing))
+
+	default:
+		existing := &nbdb.LogicalSwitchPort{UUID: op.ResourceID}
+		if err := tx.get(existing, "logical switch port", op.ResourceID); err != nil {
+			return "", err
+		}
+
+		switches := []nbdb.LogicalSwitch{}
+		if err := tx.c.nbClient.List(tx.ctx, &switches); err != nil {
+			return "", fmt.Errorf("failed to list logical switches: %w", err)
+		}
+		err := tx.detach(existing.UUID, len(switches),
+			func(i int) model.Model { return &switches[i] },
+			func(i int) *[]string { return &switches[i].Ports })
+		if err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) acl(op *TxOp) (string, error) {
+	acl, ok := op.Data.(*models.ACL)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid ACL data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch, &nbdb.LogicalSwitch{UUID: op.SwitchID})
+		if err != nil {
+			return "", err
+		}
+		if err := validateACL(acl); err != nil {
+			return "", err
+		}
+
+		now := tx.now.Format(time.RFC3339)
+		row := newNBDBACL(uuid.New().String(), acl, now)
+		stampCreated(tx.ctx, row.ExternalIDs)
+		if err := tx.append(tx.c.nbClient.Create(row)); err != nil {
+			return "", err
+		}
+
+		sw := &nbdb.LogicalSwitch{UUID: switchID}
+		if err := tx.attach(sw, &sw.ACLs, row.UUID); err != nil {
+			return "", err
+		}
+
+		acl.UUID = row.UUID
+		acl.CreatedAt = parseTime(now)
+		acl.UpdatedAt = parseTime(now)
+		acl.Ownership = models.OwnershipFromExternalIDs(row.ExternalIDs)
+		return acl.UUID, nil
+
+	case models.OperationUpdate:
+		existing := &nbdb.ACL{UUID: op.ResourceID}
+		if err := tx.get(existing, "ACL", op.ResourceID); err != nil {
+			return "", err
+		}
+		applyACLUpdates(existing, acl)
+		stampUpdated(tx.ctx, existing.ExternalIDs)
+		*acl = *tx.c.nbdbACLToModel(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing))
+
+	default:
+		existing := &nbdb.ACL{UUID: op.ResourceID}
+		if err := tx.get(existing, "ACL", op.ResourceID); err != nil {
+			return "", err
+		}
+
+		// ACLs are applied to switches or to port groups
+		switches := []nbdb.LogicalSwitch{}
+		if err := tx.c.nbClient.List(tx.ctx, &switches); err != nil {
+			return "", fmt.Errorf("failed to list logical switches: %w", err)
+		}
+		err := tx.detach(existing.UUID, len(switches),
+			func(i int) model.Model { return &switches[i] },
+			func(i int) *[]string { return &switches[i].ACLs })
+		if err != nil {
+			return "", err
+		}
+
+		portGroups := []nbdb.PortGroup{}
+		if err := tx.c.nbClient.List(tx.ctx, &portGroups); err != nil {
+			return "", fmt.Errorf("failed to list port groups: %w", err)
+		}
+		err = tx.detach(existing.UUID, len(portGroups),
+			func(i int) model.Model { return &portGroups[i] },
+			func(i int) *[]string { return &portGroups[i].ACLs })
+		if err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) loadBalancer(op *TxOp) (string, error) {
+	lb, ok := op.Data.(*models.LoadBalancer)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid load balancer data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if lb.Name == "" {
+			return "", fmt.Errorf("load balancer name is required")
+		}
+		lb.UUID = uuid.New().String()
+		lb.ExternalIDs = tx.createdExternalIDs(lb.ExternalIDs)
+		lb.CreatedAt, lb.UpdatedAt = tx.now, tx.now
+
+		return lb.UUID, tx.append(tx.c.nbClient.Create(&nbdb.LoadBalancer{
+			UUID:            lb.UUID,
+			Name:            lb.Name,
+			Vips:            lb.VIPs,
+			Protocol:        lb.Protocol,
+			IPPortMappings:  lb.IPPortMappings,
+			SelectionFields: lb.SelectionFields,
+			Options:         lb.Options,
+			ExternalIDs:     lb.ExternalIDs,
+		}))
+
+	case models.OperationUpdate:
+		existing := &nbdb.LoadBalancer{UUID: op.ResourceID}
+		if err := tx.get(existing, "load balancer", op.ResourceID); err != nil {
+			return "", err
+		}
+		applyLoadBalancerUpdates(existing, lb)
+		*lb = *convertLoadBalancer(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Name, &existing.Vips, &existing.Protocol, &existing.IPPortMappings,
+			&existing.SelectionFields, &existing.Options, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.LoadBalancer{UUID: op.ResourceID}
+		if err := tx.get(existing, "load balancer", op.ResourceID); err != nil {
+			return "", err
+		}
+
+		// Switches and routers hold strong references to their load balancers
+		switches := []nbdb.LogicalSwitch{}
+		if err := tx.c.nbClient.List(tx.ctx, &switches); err != nil {
+			return "", fmt.Errorf("failed to list logical switches: %w", err)
+		}
+		err := tx.detach(existing.UUID, len(switches),
+			func(i int) model.Model { return &switches[i] },
+			func(i int) *[]string { return &switches[i].LoadBalancer })
+		if err != nil {
+			return "", err
+		}
+
+		routers := []nbdb.LogicalRouter{}
+		if err := tx.c.nbClient.List(tx.ctx, &routers); err != nil {
+			return "", fmt.Errorf("failed to list logical routers: %w", err)
+		}
+		err = tx.detach(existing.UUID, len(routers),
+			func(i int) model.Model { return &routers[i] },
+			func(i int) *[]string { return &routers[i].LoadBalancer })
+		if err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) nat(op *TxOp) (string, error) {
+	nat, ok := op.Data.(*models.NAT)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid NAT data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		routerID, err := tx.parent(op.RouterID, models.ResourceRouter, &nbdb.LogicalRouter{UUID: op.RouterID})
+		if err != nil {
+			return "", err
+		}
+		if err := validateNAT(nat); err != nil {
+			return "", err
+		}
+
+		nat.UUID = uuid.New().String()
+		nat.ExternalIDs = tx.createdExternalIDs(nat.ExternalIDs)
+		row := &nbdb.NAT{
+			UUID:        nat.UUID,
+			Type:        nbdb.NATType(nat.Type),
+			ExternalIP:  nat.ExternalIP,
+			ExternalMAC: nat.ExternalMAC,
+			LogicalIP:   nat.LogicalIP,
+			LogicalPort: nat.LogicalPort,
+			ExternalIDs: nat.ExternalIDs,
+		}
+		if err := tx.append(tx.c.nbClient.Create(row)); err != nil {
+			return "", err
+		}
+
+		lr := &nbdb.LogicalRouter{UUID: routerID}
+		return nat.UUID, tx.attach(lr, &lr.Nat, nat.UUID)
+
+	case models.OperationUpdate:
+		existing := &nbdb.NAT{UUID: op.ResourceID}
+		if err := tx.get(existing, "NAT rule", op.ResourceID); err != nil {
+			return "", err
+		}
+		if nat.Type != "" {
+			existing.Type = nbdb.NATType(nat.Type)
+		}
+		if nat.ExternalIP != "" {
+			existing.ExternalIP = nat.ExternalIP
+		}
+		if nat.LogicalIP != "" {
+			existing.LogicalIP = nat.LogicalIP
+		}
+		if nat.ExternalMAC != nil {
+			existing.ExternalMAC = nat.ExternalMAC
+		}
+		if nat.LogicalPort != nil {
+			existing.LogicalPort = nat.LogicalPort
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, nat.ExternalIDs)
+		*nat = *convertNAT(existing)
+		if err := validateNAT(nat); err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Type, &existing.ExternalIP, &existing.LogicalIP, &existing.ExternalMAC,
+			&existing.LogicalPort, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.NAT{UUID: op.ResourceID}
+		if err := tx.get(existing, "NAT rule", op.ResourceID); err != nil {
+			return "", err
+		}
+
+		routers := []nbdb.LogicalRouter{}
+		if err := tx.c.nbClient.List(tx.ctx, &routers); err != nil {
+			return "", fmt.Errorf("failed to list logical routers: %w", err)
+		}
+		err := tx.detach(existing.UUID, len(routers),
+			func(i int) model.Model { return &routers[i] },
+			func(i int) *[]string { return &routers[i].Nat })
+		if err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) portGroup(op *TxOp) (string, error) {
+	pg, ok := op.Data.(*models.PortGroup)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid port group data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if pg.Name == "" {
+			return "", fmt.Errorf("port group name is required")
+		}
+		ports, err := tx.portRefs(pg.Ports)
+		if err != nil {
+			return "", err
+		}
+
+		pg.UUID = uuid.New().String()
+		pg.Ports = ports
+		pg.ExternalIDs = tx.createdExternalIDs(pg.ExternalIDs)
+		pg.CreatedAt, pg.UpdatedAt = tx.now, tx.now
+
+		return pg.UUID, tx.append(tx.c.nbClient.Create(&nbdb.PortGroup{
+			UUID:        pg.UUID,
+			Name:        pg.Name,
+			Ports:       pg.Ports,
+			ExternalIDs: pg.ExternalIDs,
+		}))
+
+	case models.OperationUpdate:
+		existing := &nbdb.PortGroup{UUID: op.ResourceID}
+		if err := tx.get(existing, "port group", op.ResourceID); err != nil {
+			return "", err
+		}
+		if pg.Name != "" {
+			existing.Name = pg.Name
+		}
+		if pg.Ports != nil {
+			ports, err := tx.portRefs(pg.Ports)
+			if err != nil {
+				return "", err
+			}
+			existing.Ports = ports
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, pg.ExternalIDs)
+		*pg = *convertPortGroup(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Name, &existing.Ports, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.PortGroup{UUID: op.ResourceID}
+		if err := tx.get(existing, "port group", op.ResourceID); err != nil {
+			return "", err
+		}
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+// portRefs resolves the ports of a port group, which may refer to ports
+// created earlier in the transaction
+func (tx *transaction) portRefs(ports []string) ([]string, error) {
+	resolved := make([]string, len(ports))
+	for i, id := range ports {
+		if !strings.HasPrefix(id, "$") {
+			resolved[i] = id
+			continue
+		}
+		ref, ok := tx.refs[id[1:]]
+		if !ok || ref.resource != models.ResourcePort {
+			return nil, fmt.Errorf("%s does not refer to a port created earlier in the transaction", id)
+		}
+		resolved[i] = ref.uuid
+	}
+	return resolved, nil
+}
+
+func (tx *transaction) addressSet(op *TxOp) (string, error) {
+	as, ok := op.Data.(*models.AddressSet)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid address set data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if as.Name == "" {
+			return "", fmt.Errorf("address set name is required")
+		}
+		as.UUID = uuid.New().String()
+		as.ExternalIDs = tx.createdExternalIDs(as.ExternalIDs)
+		as.CreatedAt, as.UpdatedAt = tx.now, tx.now
+
+		return as.UUID, tx.append(tx.c.nbClient.Create(&nbdb.AddressSet{
+			UUID:        as.UUID,
+			Name:        as.Name,
+			Addresses:   as.Addresses,
+			ExternalIDs: as.ExternalIDs,
+		}))
+
+	case models.OperationUpdate:
+		existing := &nbdb.AddressSet{UUID: op.ResourceID}
+		if err := tx.get(existing, "address set", op.ResourceID); err != nil {
+			return "", err
+		}
+		if as.Name != "" {
+			existing.Name = as.Name
+		}
+		if as.Addresses != nil {
+			existing.Addresses = as.Addresses
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, as.ExternalIDs)
+		*as = *convertAddressSet(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Name, &existing.Addresses, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.AddressSet{UUID: op.ResourceID}
+		if err := tx.get(existing, "address set", op.ResourceID); err != nil {
+			return "", err
+		}
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) dhcpOptions(op *TxOp) (string, error) {
+	dhcp, ok := op.Data.(*models.DHCPOptions)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid DHCP options data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		if _, _, err := net.ParseCIDR(dhcp.CIDR); err != nil {
+			return "", fmt.Errorf("invalid cidr: %s", dhcp.CIDR)
+		}
+		dhcp.UUID = uuid.New().String()
+		dhcp.ExternalIDs = tx.createdExternalIDs(dhcp.ExternalIDs)
+		dhcp.CreatedAt, dhcp.UpdatedAt = tx.now, tx.now
+
+		return dhcp.UUID, tx.append(tx.c.nbClient.Create(&nbdb.DHCPOptions{
+			UUID:        dhcp.UUID,
+			Cidr:        dhcp.CIDR,
+			Options:     dhcp.Options,
+			ExternalIDs: dhcp.ExternalIDs,
+		}))
+
+	case models.OperationUpdate:
+		existing := &nbdb.DHCPOptions{UUID: op.ResourceID}
+		if err := tx.get(existing, "DHCP options", op.ResourceID); err != nil {
+			return "", err
+		}
+		if dhcp.CIDR != "" {
+			if _, _, err := net.ParseCIDR(dhcp.CIDR); err != nil {
+				return "", fmt.Errorf("invalid cidr: %s", dhcp.CIDR)
+			}
+			existing.Cidr = dhcp.CIDR
+		}
+		if dhcp.Options != nil {
+			existing.Options = dhcp.Options
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, dhcp.ExternalIDs)
+		*dhcp = *convertDHCPOptions(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Cidr, &existing.Options, &existing.ExternalIDs))
+
+	default:
+		// Ports reference DHCP options weakly, so OVSDB clears them
+		existing := &nbdb.DHCPOptions{UUID: op.ResourceID}
+		if err := tx.get(existing, "DHCP options", op.ResourceID); err != nil {
+			return "", err
+		}
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+func (tx *transaction) qos(op *TxOp) (string, error) {
+	if err := tx.c.requireCapability(CapabilityQoS); err != nil {
+		return "", err
+	}
+	qos, ok := op.Data.(*models.QoS)
+	if !ok && op.Operation != models.OperationDelete {
+		return "", fmt.Errorf("invalid QoS data")
+	}
+
+	switch op.Operation {
+	case models.OperationCreate:
+		switchID, err := tx.parent(op.SwitchID, models.ResourceSwitch, &nbdb.LogicalSwitch{UUID: op.SwitchID})
+		if err != nil {
+			return "", err
+		}
+		action, err := qosAction(qos.Action)
+		if err != nil {
+			return "", err
+		}
+
+		qos.UUID = uuid.New().String()
+		qos.ExternalIDs = tx.createdExternalIDs(qos.ExternalIDs)
+		qos.CreatedAt, qos.UpdatedAt = tx.now, tx.now
+		row := &nbdb.QoS{
+			UUID:        qos.UUID,
+			Priority:    qos.Priority,
+			Direction:   nbdb.QoSDirection(qos.Direction),
+			Match:       qos.Match,
+			Action:      action,
+			Bandwidth:   qos.Bandwidth,
+			ExternalIDs: qos.ExternalIDs,
+		}
+		if err := validateQoS(row); err != nil {
+			return "", err
+		}
+		if err := tx.append(tx.c.nbClient.Create(row)); err != nil {
+			return "", err
+		}
+
+		sw := &nbdb.LogicalSwitch{UUID: switchID}
+		return qos.UUID, tx.attach(sw, &sw.QOSRules, qos.UUID)
+
+	case models.OperationUpdate:
+		existing := &nbdb.QoS{UUID: op.ResourceID}
+		if err := tx.get(existing, "QoS rule", op.ResourceID); err != nil {
+			return "", err
+		}
+		if qos.Priority > 0 {
+			existing.Priority = qos.Priority
+		}
+		if qos.Direction != "" {
+			existing.Direction = nbdb.QoSDirection(qos.Direction)
+		}
+		if qos.Match != "" {
+			existing.Match = qos.Match
+		}
+		if qos.Action != nil {
+			action, err := qosAction(qos.Action)
+			if err != nil {
+				return "", err
+			}
+			existing.Action = action
+		}
+		if qos.Bandwidth != nil {
+			existing.Bandwidth = qos.Bandwidth
+		}
+		if err := validateQoS(existing); err != nil {
+			return "", err
+		}
+		existing.ExternalIDs = tx.updatedExternalIDs(existing.ExternalIDs, qos.ExternalIDs)
+		*qos = *convertQoS(existing)
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Update(existing,
+			&existing.Priority, &existing.Direction, &existing.Match, &existing.Action,
+			&existing.Bandwidth, &existing.ExternalIDs))
+
+	default:
+		existing := &nbdb.QoS{UUID: op.ResourceID}
+		if err := tx.get(existing, "QoS rule", op.ResourceID); err != nil {
+			return "", err
+		}
+
+		switches := []nbdb.LogicalSwitch{}
+		if err := tx.c.nbClient.List(tx.ctx, &switches); err != nil {
+			return "", fmt.Errorf("failed to list logical switches: %w", err)
+		}
+		err := tx.detach(existing.UUID, len(switches),
+			func(i int) model.Model { return &switches[i] },
+			func(i int) *[]string { return &switches[i].QOSRules })
+		if err != nil {
+			return "", err
+		}
+
+		return "", tx.append(tx.c.nbClient.Where(existing).Delete())
+	}
+}
+
+// validateNAT checks the fields of a NAT rule
+func validateNAT(nat *models.NAT) error {
+	switch nat.Type {
+	case nbdb.NATTypeDNAT, nbdb.NATTypeSNAT, nbdb.NATTypeDNATAndSNAT:
+	default:
+		return fmt.Errorf("invalid NAT type: %s", nat.Type)
+	}
+	if net.ParseIP(nat.ExternalIP) == nil {
+		return fmt.Errorf("invalid external IP: %s", nat.ExternalIP)
+	}
+	// SNAT rules may translate a whole subnet
+	if net.ParseIP(nat.LogicalIP) == nil {
+		if _, _, err := net.ParseCIDR(nat.LogicalIP); err != nil || nat.Type != nbdb.NATTypeSNAT {
+			return fmt.Errorf("invalid logical IP: %s", nat.LogicalIP)
+		}
+	}
+	return nil
+}
+
+// validateQoS checks the fields of a QoS rule
+func validateQoS(qos *nbdb.QoS) error {
+	if qos.Priority < 0 || qos.Priority > 32767 {
+		return fmt.Errorf("priority must be between 0 and 32767")
+	}
+	if qos.Direction != nbdb.QoSDirectionFromLport && qos.Direction != nbdb.QoSDirectionToLport {
+		return fmt.Errorf("invalid direction: %s", qos.Direction)
+	}
+	if qos.Match == "" {
+		return fmt.Errorf("match expression is required")
+	}
+	for key := range qos.Action {
+		if key != nbdb.QoSActionDSCP && key != nbdb.QoSActionMark {
+			return fmt.Errorf("invalid action: %s", key)
+		}
+	}
+	for key := range qos.Bandwidth {
+		if key != nbdb.QoSBandwidthRate && key != nbdb.QoSBandwidthBurst {
+			return fmt.Errorf("invalid bandwidth: %s", key)
+		}
+	}
+	return nil
+}
+
+// qosAction converts the action of a QoS model, such as {"dscp": "10"},
+// to the integers OVN stores
+func qosAction(action map[string]string) (map[string]int, error) {
+	if action == nil {
+		return nil, nil
+	}
+	result := make(map[string]int, len(action))
+	for key, value := range action {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s action value: %s", key, value)
+		}
+		result[key] = n
+	}
+	return result, nil
+}
+
+// convertNAT converts an OVN NAT rule to our model
+func convertNAT(ovnNAT *nbdb.NAT) *models.NAT {
+	return &models.NAT{
+		UUID:        ovnNAT.UUID,
+		Type:        ovnNAT.Type,
+		ExternalIP:  ovnNAT.ExternalIP,
+		ExternalMAC: ovnNAT.ExternalMAC,
+		LogicalIP:   ovnNAT.LogicalIP,
+		LogicalPort: ovnNAT.LogicalPort,
+		ExternalIDs: ovnNAT.ExternalIDs,
+	}
+}
+
+// convertDHCPOptions converts OVN DHCP options to our model
+func convertDHCPOptions(ovnDHCP *nbdb.DHCPOptions) *models.DHCPOptions {
+	dhcp := &models.DHCPOptions{
+		UUID:        ovnDHCP.UUID,
+		CIDR:        ovnDHCP.Cidr,
+		Options:     ovnDHCP.Options,
+		ExternalIDs: ovnDHCP.ExternalIDs,
+	}
+	if created, ok := ovnDHCP.ExternalIDs["created_at"]; ok {
+		dhcp.CreatedAt = parseTime(created)
+	}
+	if updated, ok := ovnDHCP.ExternalIDs["updated_at"]; ok {
+		dhcp.UpdatedAt = parseTime(updated)
+	}
+	return dhcp
+}
+
+// convertQoS converts an OVN QoS rule to our model
+func convertQoS(ovnQoS *nbdb.QoS) *models.QoS {
+	qos := &models.QoS{
+		UUID:        ovnQoS.UUID,
+		Priority:    ovnQoS.Priority,
+		Direction:   ovnQoS.Direction,
+		Match:       ovnQoS.Match,
+		Bandwidth:   ovnQoS.Bandwidth,
+		ExternalIDs: ovnQoS.ExternalIDs,
+	}
+	if ovnQoS.Action != nil {
+		qos.Action = make(map[string]string, len(ovnQoS.Action))
+		for key, value := range ovnQoS.Action {
+			qos.Action[key] = strconv.Itoa(value)
+		}
+	}
+	if created, ok := ovnQoS.ExternalIDs["created_at"]; ok {
+		qos.CreatedAt = parseTime(created)
+	}
+	if updated, ok := ovnQoS.ExternalIDs["updated_at"]; ok {
+		qos.UpdatedAt = parseTime(updated)
+	}
+	return qos
+}
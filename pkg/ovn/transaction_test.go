@@ -0,0 +1,220 @@
+package ovn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/client"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/pkg/ovn/nbdb"
+)
+
+// timeoutError is a network error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableTxError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{"cluster error", context.Background(), &ovsdbResultError{result: ovsdb.OperationResult{Error: "cluster error"}}, "cluster_error"},
+		{"commit timed out", context.Background(), &ovsdbResultError{result: ovsdb.OperationResult{Error: "timed out"}}, "timeout"},
+		{"constraint violation", context.Background(), &ovsdbResultError{result: ovsdb.OperationResult{Error: "constraint violation"}}, ""},
+		{"operation cluster error", context.Background(), &TxError{Index: 1, Err: &ovsdbResultError{result: ovsdb.OperationResult{Error: "cluster error"}}}, "cluster_error"},
+		{"operation not built", context.Background(), &TxError{Index: 0, Err: errors.New("logical switch ls1 not found")}, ""},
+		{"deadline exceeded", context.Background(), fmt.Errorf("failed to execute transaction: %w", context.DeadlineExceeded), "timeout"},
+		{"network timeout", context.Background(), fmt.Errorf("failed to execute transaction: %w", timeoutError{}), "timeout"},
+		{"not connected", context.Background(), fmt.Errorf("failed to execute transaction: %w", client.ErrNotConnected), "disconnected"},
+		{"EOF", context.Background(), fmt.Errorf("failed to execute transaction: %w", io.EOF), "disconnected"},
+		{"unexpected EOF", context.Background(), io.ErrUnexpectedEOF, "disconnected"},
+		{"closed connection", context.Background(), net.ErrClosed, "disconnected"},
+		{"connection reset", context.Background(), &net.OpError{Op: "read", Err: syscall.ECONNRESET}, "disconnected"},
+		{"broken pipe", context.Background(), &net.OpError{Op: "write", Err: syscall.EPIPE}, "disconnected"},
+		{"other error", context.Background(), errors.New("failed to execute transaction: bogus"), ""},
+		{"context done", canceled, io.EOF, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryableTxError(tt.ctx, tt.err))
+		})
+	}
+}
+
+func TestTxRows(t *testing.T) {
+	ops := []ovsdb.Operation{
+		{Op: ovsdb.OperationInsert, Table: "Logical_Switch", UUID: "ls-1"},
+		{Op: ovsdb.OperationInsert, Table: "ACL", UUIDName: "acl"},
+		{Op: ovsdb.OperationUpdate, Table: "Logical_Switch", Where: []ovsdb.Condition{
+			{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: "ls-2"}},
+		}},
+		{Op: ovsdb.OperationDelete, Table: "Logical_Switch_Port", Where: []ovsdb.Condition{
+			{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: "lsp-1"}},
+		}},
+		{Op: ovsdb.OperationDelete, Table: "Logical_Switch_Port", Where: []ovsdb.Condition{
+			{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp-2"},
+			{Column: "_uuid", Function: ovsdb.ConditionNotEqual, Value: ovsdb.UUID{GoUUID: "lsp-3"}},
+		}},
+	}
+
+	inserted, deleted := txRows(ops)
+	assert.Equal(t, []txRow{{table: "Logical_Switch", uuid: "ls-1"}}, inserted,
+		"inserts without a UUID can't be looked for")
+	assert.Equal(t, []txRow{{table: "Logical_Switch_Port", uuid: "lsp-1"}}, deleted,
+		"only deletes by UUID are listed")
+
+	inserted, deleted = txRows(nil)
+	assert.Empty(t, inserted)
+	assert.Empty(t, deleted)
+}
+
+// cacheClient is an OVSDB client whose cache holds fixed rows
+type cacheClient struct {
+	client.Client
+	cache *cache.TableCache
+}
+
+func (c *cacheClient) Cache() *cache.TableCache {
+	return c.cache
+}
+
+// newCacheClient returns a client whose cache holds rows, by table and UUID
+func newCacheClient(t *testing.T, rows cache.Data) *Client {
+	t.Helper()
+	clientModel, err := nbdb.FullDatabaseModel()
+	require.NoError(t, err)
+	dbModel, errs := model.NewDatabaseModel(nbdb.Schema(), clientModel)
+	require.Empty(t, errs)
+	tableCache, err := cache.NewTableCache(dbModel, rows, nil)
+	require.NoError(t, err)
+	return &Client{nbClient: &cacheClient{cache: tableCache}}
+}
+
+func TestCommitted(t *testing.T) {
+	c := newCacheClient(t, cache.Data{
+		"Logical_Switch": {"ls-1": &nbdb.LogicalSwitch{UUID: "ls-1", Name: "ls1"}},
+		"ACL": {"acl-1": &nbdb.ACL{
+			UUID: "acl-1", Priority: 1000, Direction: nbdb.ACLDirectionToLport, Match: "ip4", Action: nbdb.ACLActionAllow,
+		}},
+	})
+
+	tests := []struct {
+		name    string
+		attempt *txAttempt
+		want    bool
+	}{
+		{"inserted and deleted", &txAttempt{
+			inserted: []txRow{{"Logical_Switch", "ls-1"}},
+			deleted:  []txRow{{"Logical_Switch_Port", "lsp-1"}},
+		}, true},
+		{"inserted rows", &txAttempt{inserted: []txRow{{"Logical_Switch", "ls-1"}, {"ACL", "acl-1"}}}, true},
+		{"deleted rows", &txAttempt{deleted: []txRow{{"Logical_Switch", "ls-2"}}}, true},
+		{"insert missing", &txAttempt{inserted: []txRow{{"Logical_Switch", "ls-1"}, {"ACL", "acl-2"}}}, false},
+		{"insert in another table", &txAttempt{inserted: []txRow{{"Logical_Router", "ls-1"}}}, false},
+		{"insert in an unknown table", &txAttempt{inserted: []txRow{{"Bogus", "ls-1"}}}, false},
+		{"delete still present", &txAttempt{
+			inserted: []txRow{{"Logical_Switch", "ls-1"}},
+			deleted:  []txRow{{"ACL", "acl-1"}},
+		}, false},
+		{"updates only", &txAttempt{created: []string{""}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.committed(tt.attempt))
+		})
+	}
+
+	t.Run("no cache", func(t *testing.T) {
+		c := &Client{nbClient: &cacheClient{}}
+		assert.False(t, c.committed(&txAttempt{deleted: []txRow{{"Logical_Switch", "ls-2"}}}))
+	})
+}
+
+func TestCopyTxData(t *testing.T) {
+	name := "ls1"
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"nil", nil},
+		{"nil pointer", (*models.LogicalSwitch)(nil)},
+		{"struct", models.LogicalSwitch{Name: name}},
+		{"pointer to a map", &map[string]string{"k": "v"}},
+		{"pointer to a string", &name},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Nil(t, copyTxData(tt.data), "only pointers to structs are copied")
+		})
+	}
+
+	ls := &models.LogicalSwitch{Name: "ls1", ExternalIDs: map[string]string{"owner": "alice"}}
+	saved, ok := copyTxData(ls).(*models.LogicalSwitch)
+	require.True(t, ok)
+	assert.NotSame(t, ls, saved)
+	assert.Equal(t, ls, saved)
+
+	ls.Name = "ls2"
+	assert.Equal(t, "ls1", saved.Name, "the copy doesn't follow changes of the data")
+}
+
+func TestRestoreTxData(t *testing.T) {
+	protocol := "tcp"
+	tests := []struct {
+		name   string
+		data   interface{}
+		change func(data interface{})
+	}{
+		{"switch", &models.LogicalSwitch{Name: "ls1"}, func(data interface{}) {
+			ls := data.(*models.LogicalSwitch)
+			ls.UUID = "ls-1"
+			ls.ExternalIDs = map[string]string{"created_at": "2024-01-01T00:00:00Z"}
+		}},
+		{"NAT", &models.NAT{Type: "snat", ExternalIP: "172.16.0.1", LogicalIP: "10.0.0.0/24"}, func(data interface{}) {
+			data.(*models.NAT).UUID = "nat-1"
+		}},
+		{"load balancer", &models.LoadBalancer{Name: "lb1", Protocol: &protocol}, func(data interface{}) {
+			lb := data.(*models.LoadBalancer)
+			lb.UUID = "lb-1"
+			lb.Protocol = nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := copyTxData(tt.data)
+			want := copyTxData(tt.data)
+
+			// An attempt fills in the data; the next starts from the original
+			tt.change(tt.data)
+			assert.NotEqual(t, want, tt.data)
+			restoreTxData(tt.data, saved)
+			assert.Equal(t, want, tt.data)
+		})
+	}
+
+	t.Run("nothing saved", func(t *testing.T) {
+		ls := &models.LogicalSwitch{Name: "ls1"}
+		restoreTxData(ls, nil)
+		assert.Equal(t, "ls1", ls.Name)
+		restoreTxData(nil, nil)
+	})
+}
@@ -0,0 +1,21 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lspecian/ovncp/internal/testutil"
+)
+
+// TestMain runs the tests against an ovn-central container, or against the
+// deployment OVN_NB_ADDR names
+func TestMain(m *testing.M) {
+	if addr := os.Getenv("OVN_NB_ADDR"); addr != "" && os.Getenv(testutil.OVNNorthboundEnv) == "" {
+		os.Setenv(testutil.OVNNorthboundEnv, addr)
+		os.Setenv(testutil.OVNSouthboundEnv, os.Getenv("OVN_SB_ADDR"))
+	}
+	os.Exit(testutil.Main(m))
+}
@@ -12,8 +12,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/lspecian/ovncp/internal/config"
 	"github.com/lspecian/ovncp/internal/models"
+	"github.com/lspecian/ovncp/internal/testutil"
 	"github.com/lspecian/ovncp/pkg/ovn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,25 +32,10 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// setupOVNClient connects a client to the ovn-central shared by the tests,
+// skipping the test without one
 func setupOVNClient(t *testing.T) *ovn.Client {
-	cfg := &config.OVNConfig{
-		NorthboundDB: ovnNBAddr,
-		SouthboundDB: ovnSBAddr,
-		Timeout:      30 * time.Second,
-	}
-
-	client, err := ovn.NewClient(cfg)
-	require.NoError(t, err)
-
-	ctx := context.Background()
-	err = client.Connect(ctx)
-	require.NoError(t, err)
-
-	t.Cleanup(func() {
-		// client.Disconnect() // Method doesn't exist
-	})
-
-	return client
+	return testutil.SharedOVNCentral(t).NewClient(t)
 }
 
 // Logical Switch Integration Tests
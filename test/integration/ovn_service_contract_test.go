@@ -0,0 +1,22 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/lspecian/ovncp/internal/services"
+	"github.com/lspecian/ovncp/internal/testutil"
+)
+
+// TestOVNService_Contract runs the OVNServiceInterface contract against the
+// service the API serves over a real ovn-central
+func TestOVNService_Contract(t *testing.T) {
+	central := testutil.SharedOVNCentral(t)
+	svc := central.NewOVNService(t)
+
+	testutil.RunOVNServiceContract(t, func(t *testing.T) services.OVNServiceInterface {
+		return svc
+	})
+}
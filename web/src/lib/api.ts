@@ -47,7 +47,8 @@ class ApiClient {
 
     if (!response.ok) {
       const error = await response.json().catch(() => ({ error: 'Unknown error' }));
-      throw new Error(error.error || `HTTP ${response.status}`);
+      // Errors are problem details; error is the deprecated name of title
+      throw new Error(error.title || error.error || `HTTP ${response.status}`);
     }
 
     return response.json();